@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+)
+
+// secretEnvVar lets the secret be passed without appearing in the process list
+const secretEnvVar = "ISX_ANONYMIZE_SECRET"
+
+func main() {
+	inDir := flag.String("in", "", "reports directory to anonymize (defaults to data/reports relative to executable)")
+	outDir := flag.String("out", "", "output directory for the anonymized copy (required, must be outside --in)")
+	secret := flag.String("secret", "", "secret used to derive fake symbols and scale factors (or set "+secretEnvVar+")")
+	mappingOut := flag.String("mapping-out", "", "optional JSON file for the original → fake symbol mapping (keep it private)")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	flag.Parse()
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	if *inDir == "" {
+		*inDir = paths.ReportsDir
+	}
+	if *outDir == "" {
+		fmt.Println("Error: --out is required")
+		os.Exit(1)
+	}
+	if *secret == "" {
+		*secret = os.Getenv(secretEnvVar)
+	}
+
+	anonymizer, err := dataprocessing.NewAnonymizer(*secret)
+	if err != nil {
+		fmt.Printf("Error: %v (use --secret or %s)\n", err, secretEnvVar)
+		os.Exit(1)
+	}
+
+	report, err := anonymizer.AnonymizeDir(*inDir, *outDir)
+	if err != nil {
+		slog.Error("Anonymization failed", "error", err)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *mappingOut != "" {
+		data, err := json.MarshalIndent(anonymizer.SymbolMapping(), "", "  ")
+		if err == nil {
+			err = os.WriteFile(*mappingOut, data, 0600)
+		}
+		if err != nil {
+			fmt.Printf("Error: Failed to write symbol mapping: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Anonymized %d CSV files (%d symbols) into %s\n", len(report.FilesWritten), report.Symbols, *outDir)
+	if len(report.FilesSkipped) > 0 {
+		fmt.Printf("Skipped %d non-CSV files (not copied):\n", len(report.FilesSkipped))
+		for _, f := range report.FilesSkipped {
+			fmt.Printf("  %s\n", f)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"isxcli/internal/backup"
+	"isxcli/internal/config"
+	"isxcli/internal/infrastructure"
+)
+
+const usage = `Usage: backup [flags] COMMAND
+
+Commands:
+  create         write a snapshot of the data directory
+  list           list the snapshots, newest first
+  verify NAME    check a snapshot against its manifest
+  restore NAME   verify a snapshot, then restore its files over the data directory
+
+Flags:
+`
+
+func main() {
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	jsonOut := flag.Bool("json", false, "print the result as JSON")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	switch {
+	case command == "create" || command == "list":
+	case (command == "verify" || command == "restore") && flag.NArg() == 2:
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Warn("Failed to load config, using defaults", "error", err)
+		cfg = config.Default()
+		cfg.Logging = config.LoggingConfig{
+			Level:       "info",
+			Format:      "json",
+			Output:      "both",
+			FilePath:    paths.GetLogPath("backup.log"),
+			Development: false,
+		}
+	}
+
+	logger, err := infrastructure.InitializeLogger(cfg.Logging)
+	if err != nil {
+		slog.Warn("Failed to initialize logger, using default", "error", err)
+		logger = slog.Default()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	manager := backup.NewManager(paths, cfg.Backup, logger)
+	var result interface{}
+	switch command {
+	case "create":
+		result, err = manager.Create(ctx)
+	case "list":
+		result, err = manager.List()
+	case "verify":
+		result, err = manager.Verify(ctx, flag.Arg(1))
+	case "restore":
+		result, err = manager.Restore(ctx, flag.Arg(1))
+	}
+	if err != nil {
+		logger.Error("Backup command failed", slog.String("command", command), slog.String("error", err.Error()))
+		fmt.Printf("Error: %v\n", err)
+		stop()
+		os.Exit(1)
+	}
+
+	if *jsonOut {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	printResult(command, result)
+}
+
+// printResult describes the outcome of a command for the console
+func printResult(command string, result interface{}) {
+	switch r := result.(type) {
+	case *backup.Snapshot:
+		fmt.Printf("Created %s (%d files, %d bytes, encrypted: %t)\n", r.Name, r.Files, r.Size, r.Encrypted)
+	case []backup.Snapshot:
+		if len(r) == 0 {
+			fmt.Println("No backups")
+		}
+		for _, s := range r {
+			fmt.Printf("%s  %s  %10d bytes  encrypted: %t\n", s.Name, s.CreatedAt.Format("2006-01-02 15:04:05"), s.Size, s.Encrypted)
+		}
+	case *backup.Manifest:
+		verb := "Verified"
+		if command == "restore" {
+			verb = "Restored"
+		}
+		fmt.Printf("%s %d files from the %s profile snapshot of %s\n", verb, len(r.Files), r.Profile, r.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
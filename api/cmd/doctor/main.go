@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/services"
+)
+
+func main() {
+	jsonOut := flag.Bool("json", false, "print the diagnostics as JSON")
+	timeout := flag.Duration("timeout", 30*time.Second, "time limit for all checks")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	flag.Parse()
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	// Checks report a broken configuration themselves, so defaults are fine
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Warning: failed to load config, using defaults: %v\n", err)
+		cfg = nil
+	}
+
+	opts, err := services.DoctorOptionsFromConfig(cfg, paths)
+	if err != nil {
+		fmt.Printf("Warning: %v; checking without the scraper proxy\n", err)
+		opts, _ = services.DoctorOptionsFromConfig(nil, paths)
+		opts.Thresholds = cfg.Health
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	report := services.NewDoctor(opts).Run(ctx)
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printReport(report)
+	}
+
+	if report.Status == services.DoctorFail {
+		os.Exit(1)
+	}
+}
+
+// printReport writes one line per check, with the fix indented below checks
+// that did not pass
+func printReport(report services.DoctorReport) {
+	for _, check := range report.Checks {
+		fmt.Printf("[%s] %-17s %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+		if check.Fix != "" {
+			fmt.Printf("       %-17s Fix: %s\n", "", check.Fix)
+		}
+	}
+	fmt.Println()
+	switch report.Status {
+	case services.DoctorPass:
+		fmt.Println("All checks passed")
+	case services.DoctorWarn:
+		fmt.Println("Ready, with warnings")
+	default:
+		fmt.Println("Some checks failed; fix them before running operations")
+	}
+}
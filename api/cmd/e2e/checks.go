@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"isxcli/internal/dataprocessing"
+	"isxcli/internal/exporter"
+	"isxcli/pkg/contracts/schema"
+)
+
+// check is the outcome of one assertion on the pipeline outputs
+type check struct {
+	name string
+	err  error
+}
+
+// verifyOutputs asserts the row counts and schemas of everything the
+// pipeline wrote under reportsDir for the given fixtures
+func verifyOutputs(reportsDir string, fixtures []fixtureDay) []check {
+	days := len(fixtures)
+	tickers := len(fixtureTickers)
+	traded := 0
+	for _, day := range fixtures {
+		traded += day.traded
+	}
+
+	return []check{
+		{"combined CSV", verifyCombined(filepath.Join(reportsDir, "combined", "isx_combined_data.csv"), days*tickers, traded)},
+		{"daily CSVs", verifyDaily(filepath.Join(reportsDir, "daily"), fixtures)},
+		{"ticker CSVs", verifyTickers(filepath.Join(reportsDir, "ticker"), days)},
+		{"indexes CSV", verifyIndexes(filepath.Join(reportsDir, "indexes", "indexes.csv"), days)},
+		{"liquidity report", verifyLiquidity(filepath.Join(reportsDir, "liquidity", "reports"), (days-liquidityWindow+1)*tickers)},
+	}
+}
+
+// verifyCombined expects every ticker on every session, the traded ones
+// marked as such and the rest filled
+func verifyCombined(path string, rows, traded int) error {
+	header, records, err := readCSV(path)
+	if err != nil {
+		return err
+	}
+	if want := exporter.CurrentCombinedSchema().Columns; !slices.Equal(header, want) {
+		return fmt.Errorf("header %v, want %v", header, want)
+	}
+	if len(records) != rows {
+		return fmt.Errorf("%d rows, want %d", len(records), rows)
+	}
+
+	cols := schema.DefaultMapping().Resolve(header)
+	actual := 0
+	for _, rec := range records {
+		if cols.Value(rec, schema.ColumnTradingStatus) == "true" {
+			actual++
+		}
+	}
+	if actual != traded {
+		return fmt.Errorf("%d traded rows, want %d", actual, traded)
+	}
+	return nil
+}
+
+// verifyDaily expects one file per session listing every ticker
+func verifyDaily(dir string, fixtures []fixtureDay) error {
+	for _, day := range fixtures {
+		path := filepath.Join(dir, "isx_daily_"+day.date.Format("2006_01_02")+".csv")
+		header, records, err := readCSV(path)
+		if err != nil {
+			return err
+		}
+		if len(header) < len(schema.TradeColumns) || !slices.Equal(header[:len(schema.TradeColumns)], schema.TradeColumns) {
+			return fmt.Errorf("%s: header %v does not start with the trade columns", filepath.Base(path), header)
+		}
+		if len(records) != len(fixtureTickers) {
+			return fmt.Errorf("%s: %d rows, want %d", filepath.Base(path), len(records), len(fixtureTickers))
+		}
+	}
+	return nil
+}
+
+// verifyTickers expects one history per ticker covering every session
+func verifyTickers(dir string, days int) error {
+	for _, t := range fixtureTickers {
+		path := filepath.Join(dir, t.symbol+"_trading_history.csv")
+		_, records, err := readCSV(path)
+		if err != nil {
+			return err
+		}
+		if len(records) != days {
+			return fmt.Errorf("%s: %d rows, want %d", filepath.Base(path), len(records), days)
+		}
+	}
+	return nil
+}
+
+// verifyIndexes expects one row per session with the fixture's last levels
+func verifyIndexes(path string, days int) error {
+	header, records, err := readCSV(path)
+	if err != nil {
+		return err
+	}
+	if want := dataprocessing.IndexCSVHeader(); !slices.Equal(header, want) {
+		return fmt.Errorf("header %v, want %v", header, want)
+	}
+	if len(records) != days {
+		return fmt.Errorf("%d rows, want %d", len(records), days)
+	}
+
+	last := records[len(records)-1]
+	isx60, _ := strconv.ParseFloat(last[1], 64)
+	if want := 900 + 2.5*float64(days-1); isx60 != want {
+		return fmt.Errorf("last ISX60 %v, want %v", isx60, want)
+	}
+	return nil
+}
+
+// verifyLiquidity expects one report scoring every fixture ticker on each
+// session from the first full window on
+func verifyLiquidity(dir string, rows int) error {
+	reports, err := filepath.Glob(filepath.Join(dir, "liquidity_report_*.csv"))
+	if err != nil {
+		return err
+	}
+	if len(reports) != 1 {
+		return fmt.Errorf("%d liquidity reports in %s, want 1", len(reports), dir)
+	}
+
+	header, records, err := readCSV(reports[0])
+	if err != nil {
+		return err
+	}
+	if len(header) < 2 || header[0] != "Date" || header[1] != "Symbol" {
+		return fmt.Errorf("header %v does not start with Date,Symbol", header)
+	}
+	if len(records) != rows {
+		return fmt.Errorf("%d rows, want %d", len(records), rows)
+	}
+	for _, rec := range records {
+		if !slices.ContainsFunc(fixtureTickers, func(t fixtureTicker) bool { return t.symbol == rec[1] }) {
+			return fmt.Errorf("unexpected ticker %q", rec[1])
+		}
+	}
+	return nil
+}
+
+// readCSV returns the header and data rows of path, ignoring a UTF-8 BOM
+func readCSV(path string) ([]string, [][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%s is empty", filepath.Base(path))
+	}
+	for i := range records[0] {
+		records[0][i] = strings.TrimSpace(records[0][i])
+	}
+	return records[0], records[1:], nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/internal/dataprocessing"
+)
+
+// fixtureTicker is a synthetic listed company. A ticker with skipEvery set
+// does not trade on every skipEvery-th session, so the processor has to fill
+// those days.
+type fixtureTicker struct {
+	name      string
+	symbol    string
+	basePrice float64
+	skipEvery int
+}
+
+// fixtureTickers are the companies of every synthetic report
+var fixtureTickers = []fixtureTicker{
+	{name: "Bank of Baghdad", symbol: "BBOB", basePrice: 1.25},
+	{name: "Asiacell", symbol: "TASC", basePrice: 8.10},
+	{name: "Baghdad Soft Drinks", symbol: "IBSD", basePrice: 4.50, skipEvery: 3},
+	{name: "Iraqi Islamic Bank", symbol: "BIIB", basePrice: 0.65},
+	{name: "Al-Mansour Hotel", symbol: "HMAN", basePrice: 12.00, skipEvery: 4},
+}
+
+// fixtureDay is one synthetic daily report
+type fixtureDay struct {
+	date   time.Time
+	traded int // tickers trading that session
+}
+
+// tradingDays returns the first n ISX sessions from start, skipping the
+// Friday and Saturday weekend
+func tradingDays(start time.Time, n int) []time.Time {
+	var days []time.Time
+	for day := start; len(days) < n; day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Friday || day.Weekday() == time.Saturday {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// writeFixtures stands in for the scraper: it writes one daily report per
+// session into dir, named like the portal's downloads. Prices follow a fixed
+// saw-tooth so every run produces the same files.
+func writeFixtures(dir string, days []time.Time) ([]fixtureDay, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+
+	fixtures := make([]fixtureDay, 0, len(days))
+	for i, date := range days {
+		name := date.Format("2006 01 02") + dataprocessing.DailyReportFileSuffix
+		traded, err := writeFixtureReport(filepath.Join(dir, name), i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		fixtures = append(fixtures, fixtureDay{date: date, traded: traded})
+	}
+	return fixtures, nil
+}
+
+// writeFixtureReport writes the session-th report: a trading sheet in the
+// portal's layout and an index sheet for indexcsv
+func writeFixtureReport(path string, session int) (int, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	rows := [][]interface{}{
+		{"Iraq Stock Exchange"},
+		{"Company Name", "Code", "Opening Price", "Highest Price", "Lowest Price", "Closing Price", "Prev Closing Price", "No. of Trades", "Traded Volume", "Traded Value"},
+	}
+	for _, t := range fixtureTickers {
+		// Every ticker trades in the first session so it has a price to fill from
+		if t.skipEvery > 0 && session > 0 && session%t.skipEvery == 0 {
+			continue
+		}
+		prev := fixturePrice(t, session-1)
+		closing := fixturePrice(t, session)
+		trades := 10 + session%7
+		volume := 1000 * (trades + len(t.symbol))
+		rows = append(rows, []interface{}{
+			t.name, t.symbol, prev, max(prev, closing) * 1.01, min(prev, closing) * 0.99, closing, prev,
+			trades, volume, float64(volume) * closing,
+		})
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := f.NewSheet("Indices"); err != nil {
+		return 0, err
+	}
+	indexRow := []interface{}{"ISX Index 60", 900 + 2.5*float64(session), "ISX Index 15", 450 + 1.25*float64(session)}
+	if err := f.SetSheetRow("Indices", "A1", &indexRow); err != nil {
+		return 0, err
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return 0, err
+	}
+	return len(rows) - 2, nil
+}
+
+// fixturePrice is the close of t in a session: a 5% saw-tooth around its
+// base price, rounded to the ISX's three decimals
+func fixturePrice(t fixtureTicker, session int) float64 {
+	if session < 0 {
+		session = 0
+	}
+	step := float64(session%5) - 2
+	return float64(int(t.basePrice*(1+0.01*step)*1000+0.5)) / 1000
+}
@@ -0,0 +1,200 @@
+// Command e2e runs the data pipeline end to end on synthetic reports, so CI
+// can check that processing, index extraction and liquidity scoring still fit
+// together without touching the ISX portal.
+//
+// It builds the processor, indexcsv and liquidity-report commands into a
+// temporary executable directory, writes synthetic daily reports into its
+// data/downloads in place of the scraper, runs the commands in pipeline order
+// and asserts the row counts and schemas of their outputs. Run it from the
+// api module:
+//
+//	go run ./cmd/e2e
+//	go run ./cmd/e2e -days 60 -keep
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/license"
+	"isxcli/internal/operations"
+)
+
+// liquidityWindow is the scoring window of the liquidity report, in sessions
+const liquidityWindow = 20
+
+// commands are built and run in pipeline order, with their arguments
+var commands = []struct {
+	pkg  string
+	name string
+	args []string
+}{
+	{pkg: "./cmd/processor", name: operations.ProcessorExecutable, args: []string{"--full"}},
+	{pkg: "./cmd/indexcsv", name: operations.IndexCSVExecutable, args: []string{"--mode", "initial"}},
+	{pkg: "./cmd/liquidity-report", name: "liquidity-report", args: []string{"--window", strconv.Itoa(liquidityWindow)}},
+}
+
+func main() {
+	root := flag.String("root", "", "executable directory to run in (defaults to a new temporary directory)")
+	bin := flag.String("bin", "", "directory with prebuilt commands to copy instead of building them")
+	days := flag.Int("days", 30, "trading sessions of synthetic reports to generate")
+	keep := flag.Bool("keep", false, "keep the temporary directory for inspection")
+	flag.Parse()
+
+	if *days < liquidityWindow {
+		fmt.Fprintf(os.Stderr, "Error: -days must be at least %d to fill the liquidity window\n", liquidityWindow)
+		os.Exit(2)
+	}
+
+	if *root == "" {
+		dir, err := os.MkdirTemp("", "isx-e2e-")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		*root = dir
+		if !*keep {
+			defer os.RemoveAll(dir)
+		}
+	}
+
+	ok := run(*root, *bin, *days)
+	if *keep {
+		fmt.Println("Kept", *root)
+	}
+	if !ok {
+		// Deferred cleanup does not run on os.Exit
+		if !*keep {
+			os.RemoveAll(*root)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes the smoke pipeline in root and reports whether every step
+// and check passed
+func run(root, bin string, days int) bool {
+	fmt.Printf("Smoke pipeline in %s (%d sessions, %d tickers)\n", root, days, len(fixtureTickers))
+
+	for _, c := range commands {
+		name := "build " + c.name
+		if bin != "" {
+			name = "copy " + c.name
+		}
+		if !step(name, func() error { return installCommand(root, bin, c.pkg, c.name) }) {
+			return false
+		}
+	}
+	if !step("write license", func() error { return writeLicense(filepath.Join(root, "license.dat")) }) {
+		return false
+	}
+
+	var fixtures []fixtureDay
+	if !step("scrape (synthetic reports)", func() error {
+		var err error
+		start := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+		fixtures, err = writeFixtures(filepath.Join(root, "data", "downloads"), tradingDays(start, days))
+		return err
+	}) {
+		return false
+	}
+
+	for _, c := range commands {
+		if !step("run "+c.name, func() error { return runCommand(root, c.name, c.args) }) {
+			return false
+		}
+	}
+
+	passed := true
+	for _, c := range verifyOutputs(filepath.Join(root, "data", "reports"), fixtures) {
+		if c.err != nil {
+			fmt.Printf("FAIL check %s: %v\n", c.name, c.err)
+			passed = false
+			continue
+		}
+		fmt.Printf("ok   check %s\n", c.name)
+	}
+	return passed
+}
+
+// step runs fn and prints its outcome and duration
+func step(name string, fn func() error) bool {
+	started := time.Now()
+	if err := fn(); err != nil {
+		fmt.Printf("FAIL %s: %v\n", name, err)
+		return false
+	}
+	fmt.Printf("ok   %s (%s)\n", name, time.Since(started).Round(time.Millisecond))
+	return true
+}
+
+// installCommand puts the named command into root, built from pkg or copied
+// from bin
+func installCommand(root, bin, pkg, name string) error {
+	target := filepath.Join(root, operations.ExecutableName(name))
+	if bin == "" {
+		out, err := exec.Command("go", "build", "-o", target, pkg).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w\n%s", err, out)
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(bin, operations.ExecutableName(name)))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0755)
+}
+
+// runCommand runs a command from root, which it treats as its executable
+// directory, with output shown only on failure
+func runCommand(root, name string, args []string) error {
+	cmd := exec.Command(filepath.Join(root, operations.ExecutableName(name)), args...)
+	cmd.Dir = root
+	// The default profile keeps all data under root
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "ISX_PROFILE=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, tail(string(out), 40))
+	}
+	return nil
+}
+
+// writeLicense writes a local license the commands accept offline: valid
+// for a day and checked just now, so no remote validation is attempted
+func writeLicense(path string) error {
+	now := time.Now()
+	data, err := json.MarshalIndent(license.LicenseInfo{
+		LicenseKey:  "ISX-E2E-SMOKE",
+		UserEmail:   "e2e@localhost",
+		ExpiryDate:  now.Add(24 * time.Hour),
+		Duration:    "1d",
+		IssuedDate:  now,
+		Status:      "active",
+		LastChecked: now,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"isxcli/internal/dataprocessing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradingDays(t *testing.T) {
+	// 2025-01-09 is a Thursday
+	days := tradingDays(time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC), 3)
+	require.Len(t, days, 3)
+	assert.Equal(t, "2025-01-09", days[0].Format("2006-01-02"))
+	assert.Equal(t, "2025-01-12", days[1].Format("2006-01-02"), "Friday and Saturday are skipped")
+	assert.Equal(t, "2025-01-13", days[2].Format("2006-01-02"))
+}
+
+func TestWriteFixtures_ParsedByProcessor(t *testing.T) {
+	dir := t.TempDir()
+	days := tradingDays(time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), 13)
+	fixtures, err := writeFixtures(dir, days)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 13)
+
+	assert.Equal(t, len(fixtureTickers), fixtures[0].traded)
+	// Session 12 skips both IBSD (every 3rd) and HMAN (every 4th)
+	assert.Equal(t, len(fixtureTickers)-2, fixtures[12].traded)
+
+	name := days[12].Format("2006 01 02") + dataprocessing.DailyReportFileSuffix
+	report, err := dataprocessing.ParseFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	require.Len(t, report.Records, fixtures[12].traded)
+
+	for _, rec := range report.Records {
+		assert.NotEqual(t, "IBSD", rec.CompanySymbol)
+		assert.NotEqual(t, "HMAN", rec.CompanySymbol)
+		if rec.CompanySymbol == "BBOB" {
+			assert.InDelta(t, fixturePrice(fixtureTickers[0], 12), rec.ClosePrice, 0.0001)
+		}
+	}
+}
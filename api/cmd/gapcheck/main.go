@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+	"isxcli/internal/infrastructure"
+	"isxcli/internal/operations"
+)
+
+func main() {
+	fromStr := flag.String("from", "", "start date (YYYY-MM-DD); defaults to the earliest downloaded report")
+	toStr := flag.String("to", "", "end date (YYYY-MM-DD); defaults to the latest downloaded report")
+	dir := flag.String("dir", "", "directory containing xlsx reports (defaults to data/downloads relative to executable)")
+	combined := flag.String("combined", "", "combined CSV to check (defaults to data/reports/combined/isx_combined_data.csv)")
+	holidays := flag.String("holidays", "", "holiday calendar file, one YYYY-MM-DD per line (defaults to data/holidays.txt)")
+	rescrape := flag.Bool("rescrape", false, "run the scraper for each missing date range")
+	headless := flag.Bool("headless", true, "run browser headless when re-scraping")
+	jsonOut := flag.Bool("json", false, "print the gap report as JSON")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	flag.Parse()
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	if *dir == "" {
+		*dir = paths.DownloadsDir
+	}
+	if *combined == "" {
+		*combined = paths.CombinedDataCSV
+	}
+	if *holidays == "" {
+		*holidays = paths.HolidaysFile
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Warn("Failed to load config, using defaults", "error", err)
+		cfg = &config.Config{
+			Logging: config.LoggingConfig{
+				Level:       "info",
+				Format:      "json",
+				Output:      "both",
+				FilePath:    paths.GetLogPath("gapcheck.log"),
+				Development: false,
+			},
+		}
+	}
+
+	logger, err := infrastructure.InitializeLogger(cfg.Logging)
+	if err != nil {
+		slog.Warn("Failed to initialize logger, using default", "error", err)
+		logger = slog.Default()
+	}
+
+	calendar, err := files.LoadHolidayCalendar(*holidays)
+	if err != nil {
+		logger.Error("Failed to load holiday calendar", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	opts := files.GapOptions{
+		DownloadsDir: *dir,
+		ArchiveDir:   paths.ArchiveDir,
+		CombinedCSV:  *combined,
+		Calendar:     calendar,
+	}
+	if *fromStr != "" {
+		if opts.From, err = time.Parse("2006-01-02", *fromStr); err != nil {
+			fmt.Printf("Error: Invalid --from date: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *toStr != "" {
+		if opts.To, err = time.Parse("2006-01-02", *toStr); err != nil {
+			fmt.Printf("Error: Invalid --to date: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	report, err := files.FindGaps(opts)
+	if err != nil {
+		logger.Error("Gap analysis failed", slog.String("error", err.Error()))
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Gap analysis complete",
+		slog.String("from", report.From.Format("2006-01-02")),
+		slog.String("to", report.To.Format("2006-01-02")),
+		slog.Int("expected_days", report.ExpectedDays),
+		slog.Int("missing_downloads", len(report.MissingDownloads)),
+		slog.Int("missing_in_combined", len(report.MissingInCombined)))
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			logger.Error("Failed to encode report", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	} else {
+		printReport(report)
+	}
+
+	if !*rescrape || len(report.Ranges) == 0 {
+		return
+	}
+
+	scraperPath, err := operations.ResolveExecutable(paths.ExecutableDir, operations.ScraperExecutable)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	failed := 0
+	for _, r := range report.Ranges {
+		if err := runScraper(scraperPath, r, *dir, *headless, logger); err != nil {
+			logger.Error("Re-scrape failed",
+				slog.String("from", r.From.Format("2006-01-02")),
+				slog.String("to", r.To.Format("2006-01-02")),
+				slog.String("error", err.Error()))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d re-scrape ranges failed\n", failed, len(report.Ranges))
+		os.Exit(1)
+	}
+	fmt.Println("Re-scrape complete. Run processor.exe to rebuild the combined data.")
+}
+
+// printReport writes a human-readable gap summary to stdout
+func printReport(report *files.GapReport) {
+	fmt.Printf("Checked %s to %s (%d trading days)\n",
+		report.From.Format("2006-01-02"), report.To.Format("2006-01-02"), report.ExpectedDays)
+
+	if !report.HasGaps() {
+		fmt.Println("No gaps found")
+		return
+	}
+
+	fmt.Printf("Missing downloads: %d\n", len(report.MissingDownloads))
+	for _, r := range report.Ranges {
+		if r.Days == 1 {
+			fmt.Printf("  %s\n", r.From.Format("2006-01-02"))
+			continue
+		}
+		fmt.Printf("  %s to %s (%d days)\n", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"), r.Days)
+	}
+
+	fmt.Printf("Missing in combined data: %d\n", len(report.MissingInCombined))
+	for _, d := range report.MissingInCombined {
+		fmt.Printf("  %s\n", d.Format("2006-01-02"))
+	}
+}
+
+// runScraper downloads a single missing range. Initial mode keeps existing
+// files, so only the missing reports are fetched.
+func runScraper(scraperPath string, r files.DateRange, outDir string, headless bool, logger *slog.Logger) error {
+	args := []string{
+		"--mode=initial",
+		"--from=" + r.From.Format("2006-01-02"),
+		"--to=" + r.To.Format("2006-01-02"),
+		"--out=" + outDir,
+		fmt.Sprintf("--headless=%t", headless),
+	}
+
+	logger.Info("Re-scraping missing range",
+		slog.String("from", r.From.Format("2006-01-02")),
+		slog.String("to", r.To.Format("2006-01-02")),
+		slog.Int("days", r.Days))
+
+	cmd := exec.Command(scraperPath, args...)
+	cmd.Dir = filepath.Dir(scraperPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
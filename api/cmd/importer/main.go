@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+	"isxcli/internal/infrastructure"
+)
+
+// maxIssuesShown limits the skipped rows listed per file
+const maxIssuesShown = 20
+
+func main() {
+	mappingFile := flag.String("mapping", "", "JSON column mapping for the source files (optional; common headers are matched automatically)")
+	onConflict := flag.String("on-conflict", "keep_existing", "when an imported row overlaps an existing traded day: keep_existing, prefer_import or fail")
+	fill := flag.String("fill", "", "how days a ticker did not trade are filled (defaults to $ISX_PROCESSING_FILL_STRATEGY or carry_forward)")
+	outDir := flag.String("out", "", "reports directory to merge into (defaults to data/reports relative to executable)")
+	dryRun := flag.Bool("dry-run", false, "validate and report the merge without writing anything")
+	jsonOut := flag.Bool("json", false, "print the import summary as JSON")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: importer [flags] FILE.csv|FILE.xlsx ...\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Warn("Failed to load config, using defaults", "error", err)
+		cfg = &config.Config{
+			Logging: config.LoggingConfig{
+				Level:       "info",
+				Format:      "json",
+				Output:      "both",
+				FilePath:    paths.GetLogPath("importer.log"),
+				Development: false,
+			},
+			Processing: config.Default().Processing,
+		}
+	}
+
+	logger, err := infrastructure.InitializeLogger(cfg.Logging)
+	if err != nil {
+		slog.Warn("Failed to initialize logger, using default", "error", err)
+		logger = slog.Default()
+	}
+
+	var mapping dataprocessing.ImportMapping
+	if *mappingFile != "" {
+		if mapping, err = dataprocessing.LoadImportMapping(*mappingFile); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	policy, err := dataprocessing.ParseConflictPolicy(*onConflict)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *fill == "" {
+		*fill = cfg.Processing.FillStrategy
+	}
+	fillStrategy, err := dataprocessing.ParseFillStrategy(*fill)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	importer := dataprocessing.NewHistoryImporter(paths, dataprocessing.HistoryImportOptions{
+		Mapping:      mapping,
+		Conflict:     policy,
+		FillStrategy: fillStrategy,
+		ReportsDir:   *outDir,
+		DryRun:       *dryRun,
+	}, logger)
+
+	summary, err := importer.Import(ctx, flag.Args())
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(summary); encErr != nil {
+			logger.Error("Failed to encode summary", slog.String("error", encErr.Error()))
+		}
+	} else {
+		printSummary(summary)
+	}
+	if err != nil {
+		logger.Error("Import failed", slog.String("error", err.Error()))
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printSummary writes a human-readable import summary to stdout
+func printSummary(summary *dataprocessing.HistoryImportSummary) {
+	for _, f := range summary.Files {
+		fmt.Printf("%s: %d rows, %d imported, %d skipped\n", f.Source, f.Rows, len(f.Records), len(f.Skipped))
+		for i, issue := range f.Skipped {
+			if i == maxIssuesShown {
+				fmt.Printf("  ... %d more\n", len(f.Skipped)-maxIssuesShown)
+				break
+			}
+			fmt.Printf("  row %d: %s\n", issue.Row, issue.Message)
+		}
+	}
+	if summary.Records == 0 {
+		fmt.Println("Nothing to import")
+		return
+	}
+
+	suffix := ""
+	if summary.DryRun {
+		suffix = " (dry run, nothing changed)"
+	}
+	fmt.Printf("Import complete%s\n", suffix)
+	fmt.Printf("Records: %d for %d tickers from %s to %s\n", summary.Records, len(summary.Tickers), summary.From, summary.To)
+	fmt.Printf("Added: %d (%d replacing forward-filled days)\n", summary.Merge.Added+summary.Merge.FillsReplaced, summary.Merge.FillsReplaced)
+	fmt.Printf("Replaced existing: %d, kept existing: %d, unchanged: %d\n", summary.Merge.Replaced, summary.Merge.Kept, summary.Merge.Unchanged)
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -15,7 +16,10 @@ import (
 	"time"
 
 	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+	"isxcli/internal/dataprocessing/analytics"
 	"isxcli/internal/infrastructure"
+	"isxcli/pkg/contracts/events"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -27,8 +31,17 @@ func main() {
 	mode := flag.String("mode", "initial", "initial | accumulative")
 	dir := flag.String("dir", "", "directory containing xlsx reports (defaults to data/downloads relative to executable)")
 	out := flag.String("out", "", "output csv file path (defaults to data/reports/indexes.csv)")
+	longOut := flag.String("long-out", "", "normalized Date,Index,Value csv path (defaults to data/reports/indexes/indexes_long.csv)")
+	analyticsOut := flag.String("analytics-out", "", "index analytics csv path (defaults to data/reports/indexes/index_analytics.csv)")
+	metricsOut := flag.String("metrics-out", "", "index returns, volatility, drawdown and moving averages csv path (defaults to data/reports/indexes/indexes_analytics.csv)")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
 	flag.Parse()
 
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize paths first to get default directories
 	paths, err := config.GetPaths()
 	if err != nil {
@@ -43,6 +56,15 @@ func main() {
 	if *out == "" {
 		*out = paths.IndexCSV
 	}
+	if *longOut == "" {
+		*longOut = paths.IndexLongCSV
+	}
+	if *analyticsOut == "" {
+		*analyticsOut = paths.IndexAnalyticsCSV
+	}
+	if *metricsOut == "" {
+		*metricsOut = paths.IndexMetricsCSV
+	}
 	
 	// Ensure all required directories exist
 	if err := paths.EnsureDirectories(); err != nil {
@@ -88,32 +110,40 @@ func main() {
 	}
 	logger.Info("Ensured output directory exists", slog.String("path", outDir))
 
-	var lastDate time.Time
 	if *mode == "accumulative" {
 		if d, err := loadLastDate(*out); err == nil {
-			lastDate = d
-			logger.Info("Existing CSV last date", slog.String("last_date", lastDate.Format("2006-01-02")))
+			logger.Info("Existing CSV last date", slog.String("last_date", d.Format("2006-01-02")))
 		} else {
-					logger.Warn("No existing CSV found, switching to initial mode", slog.String("error", err.Error()))
+			logger.Warn("No existing CSV found, switching to initial mode", slog.String("error", err.Error()))
+			*mode = "initial"
+		}
+	}
+	if *mode == "accumulative" {
+		// Rows can only be kept when both files have the current columns
+		if err := checkIndexSchema(*out, *longOut); err != nil {
+			logger.Warn("Index CSV schema is outdated, switching to initial mode", slog.String("error", err.Error()))
 			*mode = "initial"
 		}
 	}
 
-	if *mode == "initial" {
-		// initial mode: create/truncate csv with header
-		f, err := os.Create(*out)
+	// Rows are kept by date and written back through temp files, so a failed
+	// run leaves the previous files intact and re-running never duplicates a
+	// date
+	var store *indexStore
+	if *mode == "accumulative" {
+		store, err = loadIndexStore(*out, *longOut)
 		if err != nil {
-				logger.Error("Cannot create output file", 
-				slog.String("path", *out),
-				slog.String("error", err.Error()))
-			slog.Error("Cannot create output file", "path", *out, "error", err)
-			os.Exit(1)
+			logger.Warn("Cannot read existing index files, switching to initial mode", slog.String("error", err.Error()))
+			*mode = "initial"
+		} else {
+			logger.Info("Loaded existing index data",
+				slog.Int("dates", len(store.wide)),
+				slog.Int("tracked_files", len(store.manifest.Files)))
 		}
-		w := csv.NewWriter(f)
-		w.Write([]string{"Date", "ISX60", "ISX15"})
-		w.Flush()
-		_ = f.Close()
-		logger.Info("Created new CSV file", slog.String("path", *out))
+	}
+	if *mode == "initial" {
+		// initial mode: rebuild both csv files from every report
+		store = newIndexStore(*out, *longOut)
 	}
 
 	entries, err := os.ReadDir(*dir)
@@ -128,6 +158,7 @@ func main() {
 	type fileInfo struct {
 		path string
 		date time.Time
+		info fs.FileInfo
 	}
 	var files []fileInfo
 	for _, e := range entries {
@@ -139,33 +170,39 @@ func main() {
 			continue
 		}
 		t, _ := time.Parse("2006 01 02", strings.Join(m[1:4], " "))
-		if !lastDate.IsZero() && !t.After(lastDate) {
+		info, err := e.Info()
+		if err != nil {
+			logger.Warn("Cannot stat report", slog.String("filename", e.Name()), slog.String("error", err.Error()))
+			continue
+		}
+		if !store.needsExtraction(e.Name(), t, info) {
 			logger.Debug("Skipping already processed file",
 				slog.String("filename", e.Name()),
-				slog.String("file_date", t.Format("2006-01-02")),
-				slog.String("last_processed_date", lastDate.Format("2006-01-02")))
+				slog.String("file_date", t.Format("2006-01-02")))
 			continue // already processed
 		}
-		files = append(files, fileInfo{path: filepath.Join(*dir, e.Name()), date: t})
+		files = append(files, fileInfo{path: filepath.Join(*dir, e.Name()), date: t, info: info})
 	}
 
 	sort.Slice(files, func(i, j int) bool { return files[i].date.Before(files[j].date) })
 
 	logger.Info("Excel files found", slog.Int("count", len(files)))
 	
-	// Output progress message for stages.go to parse
+	// Human-readable progress; the operations stage reads the structured events
+	progress := events.NewProgressEmitter(os.Stdout, "indices")
 	fmt.Printf("Found %d Excel files\n", len(files))
 	if len(files) == 0 {
 		logger.Info("No new files to process")
 		
-		// Create empty indices CSV if it doesn't exist (for consistency)
-		if *mode == "initial" {
-			// CSV was already created with headers above
-			logger.Info("Created empty indices CSV with headers", slog.String("path", *out))
+		// Initial mode still writes both files with headers, and
+		// accumulative mode drops duplicate rows left by older versions
+		if err := store.commit(); err != nil {
+			logger.Error("Cannot write output files", slog.String("error", err.Error()))
+			os.Exit(1)
 		}
 		
-		// Signal completion to stages.go
 		fmt.Println("Index extraction complete: 0 files")
+		progress.Complete(0, 0, "No new files to process")
 		return
 	}
 	
@@ -176,19 +213,9 @@ func main() {
 			fileNames = append(fileNames, filepath.Base(f.path))
 		}
 		fmt.Printf("Files to process: %s\n", strings.Join(fileNames, "|"))
+		progress.Start(len(fileNames), fileNames)
 	}
 
-	outF, err := os.OpenFile(*out, os.O_APPEND|os.O_WRONLY, 0o644)
-	if err != nil {
-			logger.Error("Failed to open output file",
-			slog.String("path", *out),
-			slog.String("error", err.Error()))
-		slog.Error("Failed to open output file", "path", *out, "error", err)
-		os.Exit(1)
-	}
-	defer outF.Close()
-	writer := csv.NewWriter(outF)
-
 	processedCount := 0
 	for i, fi := range files {
 		logger.Info("Processing file",
@@ -196,57 +223,36 @@ func main() {
 			slog.Int("total", len(files)),
 			slog.String("filename", filepath.Base(fi.path)))
 		
-		// Output progress message for stages.go to parse
 		fmt.Printf("Processing file %d of %d: %s\n", i+1, len(files), filepath.Base(fi.path))
+		progress.Progress(i+1, len(files), filepath.Base(fi.path), events.ItemStatusProcessing)
 
-		isx60, isx15, err := extractIndices(fi.path)
+		values, err := extractIndices(fi.path)
 		if err != nil {
 				logger.Error("Error processing file",
 				slog.String("filename", filepath.Base(fi.path)),
 				slog.String("error", err.Error()))
 			slog.Warn("Error processing file", "filename", filepath.Base(fi.path), "error", err)
-			continue
+			continue // not recorded, so the next run retries it
 		}
 
-		rec := []string{fi.date.Format("2006-01-02"), formatFloat(isx60)}
-		if isx15 > 0 {
-			rec = append(rec, formatFloat(isx15))
-		} else {
-			rec = append(rec, "")
-		}
-		
-		// Write and immediately check for errors
-		if err := writer.Write(rec); err != nil {
-			logger.Error("Failed to write CSV record",
-				slog.String("date", fi.date.Format("2006-01-02")),
-				slog.String("error", err.Error()))
-			os.Exit(1)
-		}
-		
-		// Flush after each write to catch disk errors immediately
-		writer.Flush()
-		if err := writer.Error(); err != nil {
-			logger.Error("CSV flush error",
-				slog.String("date", fi.date.Format("2006-01-02")),
-				slog.String("error", err.Error()))
-			os.Exit(1)
-		}
-		
+		store.add(filepath.Base(fi.path), fi.date, fi.info, values)
 		processedCount++
 
-		if isx15 > 0 {
-			logger.Info("Added index data",
-				slog.String("date", fi.date.Format("2006-01-02")),
-				slog.Float64("ISX60", isx60),
-				slog.Float64("ISX15", isx15))
-		} else {
-			logger.Info("Added index data (ISX15 N/A)",
-				slog.String("date", fi.date.Format("2006-01-02")),
-				slog.Float64("ISX60", isx60))
+		logger.Info("Added index data",
+			slog.String("date", fi.date.Format("2006-01-02")),
+			slog.Float64("ISX60", values[dataprocessing.IndexISX60]),
+			slog.Float64("ISX15", values[dataprocessing.IndexISX15]),
+			slog.Int("sector_indices", sectorCount(values)))
+
+		if processedCount%checkpointEvery == 0 {
+			if err := store.commit(); err != nil {
+				logger.Error("Failed to write index checkpoint", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			logger.Info("Index checkpoint written", slog.Int("processed_files", processedCount))
 		}
 	}
-	writer.Flush()
-	if err := writer.Error(); err != nil {
+	if err := store.commit(); err != nil {
 			logger.Error("CSV write error", slog.String("error", err.Error()))
 		slog.Error("Failed to write CSV", "error", err)
 		os.Exit(1)
@@ -256,9 +262,110 @@ func main() {
 	logger.Info("Index extraction completed",
 		slog.Int("processed_files", processedCount),
 		slog.String("output_path", *out))
+
+	// Analytics are a best-effort extra; a failure must not fail the extraction
+	if err := writeIndexAnalytics(*out, paths.CombinedDataCSV, *analyticsOut, *metricsOut, logger); err != nil {
+		logger.Warn("Index analytics skipped", slog.String("error", err.Error()))
+	}
+	if cfg.Processing.Correlations.Enabled {
+		if err := writeCorrelations(*analyticsOut, paths.CombinedDataCSV, filepath.Join(paths.ReportsDir, analytics.CorrelationsDirName), cfg.Processing.Correlations, logger); err != nil {
+			logger.Warn("Ticker correlations skipped", slog.String("error", err.Error()))
+		}
+	}
 	
-	// Output completion message for stages.go to parse
 	fmt.Printf("Index extraction complete: %d files\n", processedCount)
+	progress.Complete(processedCount, len(files), "Index extraction complete")
+}
+
+// writeIndexAnalytics computes daily index returns, flags probable divisor
+// changes and writes the result next to the index CSV, together with the
+// performance metrics derived from the adjusted returns
+func writeIndexAnalytics(indexPath, combinedPath, outPath, metricsPath string, logger *slog.Logger) error {
+	points, err := dataprocessing.ReadIndexCSV(indexPath)
+	if err != nil {
+		return err
+	}
+
+	constituentReturns, err := dataprocessing.ConstituentReturnsFromCSV(combinedPath)
+	if err != nil {
+		// Without constituent data only very large jumps can be flagged
+		logger.Warn("Constituent returns unavailable, using jump threshold only",
+			slog.String("combined_csv", combinedPath),
+			slog.String("error", err.Error()))
+		constituentReturns = map[string]float64{}
+	}
+
+	rows := dataprocessing.AnalyzeIndexReturns(points, constituentReturns, dataprocessing.DefaultDivisorCheckConfig())
+	if err := dataprocessing.WriteIndexAnalyticsCSV(outPath, rows); err != nil {
+		return err
+	}
+
+	alerts := dataprocessing.IndexAlerts(rows)
+	for _, alert := range alerts {
+		logger.Warn("Index divisor change suspected",
+			slog.String("date", alert.Date.Format("2006-01-02")),
+			slog.String("index", alert.Index),
+			slog.String("alert", alert.Alert),
+			slog.Float64("index_return", alert.Return),
+			slog.Float64("constituent_return", alert.ConstituentReturn))
+	}
+
+	metrics := dataprocessing.ComputeIndexPerformance(rows)
+	if err := dataprocessing.WriteIndexPerformanceCSV(metricsPath, metrics); err != nil {
+		return err
+	}
+
+	logger.Info("Index analytics written",
+		slog.String("path", outPath),
+		slog.String("metrics_path", metricsPath),
+		slog.Int("rows", len(rows)),
+		slog.Int("alerts", len(alerts)))
+	return nil
+}
+
+// writeCorrelations correlates the daily returns of every pair of tickers in
+// the combined CSV and computes each ticker's beta against the divisor-adjusted
+// ISX60 returns of the index analytics, for every configured lookback window
+func writeCorrelations(indexAnalyticsPath, combinedPath, outDir string, cfg config.CorrelationConfig, logger *slog.Logger) error {
+	rows, err := dataprocessing.ReadIndexAnalyticsCSV(indexAnalyticsPath)
+	if err != nil {
+		return err
+	}
+	records, err := dataprocessing.ReadTradeRecordsCSV(combinedPath)
+	if err != nil {
+		return err
+	}
+
+	// The first published level has no return to regress on
+	benchmark := make(map[time.Time]float64)
+	first := true
+	for _, row := range rows {
+		if row.Index != dataprocessing.IndexISX60 {
+			continue
+		}
+		if !first {
+			benchmark[row.Date] = row.AdjustedReturn / 100
+		}
+		first = false
+	}
+
+	report := analytics.ComputeCorrelations(records, dataprocessing.IndexISX60, benchmark, analytics.CorrelationOptions{
+		Windows:         cfg.Windows,
+		MinObservations: cfg.MinObservations,
+	}, time.Now())
+	if err := analytics.WriteCorrelations(outDir, report); err != nil {
+		return err
+	}
+
+	for _, w := range report.Windows {
+		logger.Info("Ticker correlations written",
+			slog.String("dir", outDir),
+			slog.Int("window", w.Days),
+			slog.Int("tickers", len(w.Tickers)),
+			slog.Int("pairs", len(w.Correlations)),
+			slog.Int("betas", len(w.Betas)))
+	}
+	return nil
 }
 
 func loadLastDate(csvPath string) (time.Time, error) {
@@ -319,10 +426,13 @@ func loadLastDate(csvPath string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("no valid data rows found")
 }
 
-func extractIndices(path string) (isx60, isx15 float64, err error) {
+// extractIndices reads the index levels of a daily report keyed by their
+// indexes.csv column: ISX60, and ISX15 and the sector indices when the report
+// published them
+func extractIndices(path string) (map[string]float64, error) {
 	f, err := excelize.OpenFile(path)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 	defer f.Close()
 
@@ -341,10 +451,16 @@ func extractIndices(path string) (isx60, isx15 float64, err error) {
 		sheets = f.GetSheetList()
 	}
 
+	values := make(map[string]float64)
 	joinRe := regexp.MustCompile(`\s+`)
 	for _, sheet := range sheets {
 		rows, _ := f.GetRows(sheet)
 		for _, row := range rows {
+			collectSectorIndices(row, values)
+			if _, found := values[dataprocessing.IndexISX60]; found {
+				continue
+			}
+
 			line := strings.TrimSpace(joinRe.ReplaceAllString(strings.Join(row, " "), " "))
 			if line == "" {
 				continue
@@ -353,9 +469,11 @@ func extractIndices(path string) (isx60, isx15 float64, err error) {
 			if strings.Contains(line, "ISX Index 60") && strings.Contains(line, "ISX Index 15") {
 				numRe := regexp.MustCompile(`ISX Index 60\s+([0-9.,]+).*?ISX Index 15\s+([0-9.,]+)`) // non-greedy
 				if m := numRe.FindStringSubmatch(line); m != nil {
-					isx60, _ = parseFloat(m[1])
-					isx15, _ = parseFloat(m[2])
-					return isx60, isx15, nil
+					values[dataprocessing.IndexISX60], _ = parseFloat(m[1])
+					if isx15, _ := parseFloat(m[2]); isx15 > 0 {
+						values[dataprocessing.IndexISX15] = isx15
+					}
+					continue
 				}
 			}
 
@@ -363,8 +481,8 @@ func extractIndices(path string) (isx60, isx15 float64, err error) {
 			if strings.Contains(line, "ISX Index 60") {
 				numRe := regexp.MustCompile(`ISX Index 60\s+([0-9.,]+)`)
 				if m := numRe.FindStringSubmatch(line); m != nil {
-					isx60, _ = parseFloat(m[1])
-					return isx60, 0, nil
+					values[dataprocessing.IndexISX60], _ = parseFloat(m[1])
+					continue
 				}
 			}
 
@@ -372,13 +490,102 @@ func extractIndices(path string) (isx60, isx15 float64, err error) {
 			if strings.Contains(line, "ISX Price Index") {
 				numRe := regexp.MustCompile(`ISX Price Index\s+([0-9.,]+)`)
 				if m := numRe.FindStringSubmatch(line); m != nil {
-					isx60, _ = parseFloat(m[1]) // treat as 60 index
-					return isx60, 0, nil
+					values[dataprocessing.IndexISX60], _ = parseFloat(m[1]) // treat as 60 index
+					continue
 				}
 			}
 		}
+
+		// Sector indices are published on the same sheet as the market indices
+		if _, found := values[dataprocessing.IndexISX60]; found {
+			return values, nil
+		}
+	}
+	return nil, fmt.Errorf("indices not found in %s", filepath.Base(path))
+}
+
+// collectSectorIndices records sector index levels found in a row: a label
+// cell such as "Banking Sector Index" followed by its value
+func collectSectorIndices(row []string, values map[string]float64) {
+	for i, cell := range row {
+		name, ok := dataprocessing.MatchSectorIndex(cell)
+		if !ok {
+			continue
+		}
+		if _, seen := values[name]; seen {
+			continue
+		}
+		for _, next := range row[i+1:] {
+			next = strings.TrimSpace(next)
+			if next == "" {
+				continue
+			}
+			if v, err := parseFloat(next); err == nil && v > 0 {
+				values[name] = v
+			}
+			break
+		}
+	}
+}
+
+// sectorCount returns how many sector indices were extracted
+func sectorCount(values map[string]float64) int {
+	n := 0
+	for name := range values {
+		if name != dataprocessing.IndexISX60 && name != dataprocessing.IndexISX15 {
+			n++
+		}
+	}
+	return n
+}
+
+// wideRecord lays out one indexes.csv row; unpublished indices are empty
+func wideRecord(date time.Time, values map[string]float64) []string {
+	rec := []string{date.Format("2006-01-02")}
+	for _, def := range dataprocessing.IndexDefinitions() {
+		v := values[def.Name]
+		if def.Name == dataprocessing.IndexISX60 || v > 0 {
+			rec = append(rec, formatFloat(v))
+		} else {
+			rec = append(rec, "")
+		}
+	}
+	return rec
+}
+
+// longRecords lays out the indexes_long.csv rows of one date, one per
+// published index
+func longRecords(date time.Time, values map[string]float64) [][]string {
+	var recs [][]string
+	for _, def := range dataprocessing.IndexDefinitions() {
+		if v := values[def.Name]; v > 0 {
+			recs = append(recs, []string{date.Format("2006-01-02"), def.Name, formatFloat(v)})
+		}
+	}
+	return recs
+}
+
+// checkIndexSchema returns an error unless indexes.csv has the current
+// columns and indexes_long.csv exists, which appending requires
+func checkIndexSchema(widePath, longPath string) error {
+	f, err := os.Open(widePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header, err := csv.NewReader(f).Read()
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if strings.Join(header, ",") != strings.Join(dataprocessing.IndexCSVHeader(), ",") {
+		return fmt.Errorf("header %q does not match the current index columns", strings.Join(header, ","))
+	}
+
+	if _, err := os.Stat(longPath); err != nil {
+		return err
 	}
-	return 0, 0, fmt.Errorf("indices not found in %s", filepath.Base(path))
+	return nil
 }
 
 func parseFloat(s string) (float64, error) {
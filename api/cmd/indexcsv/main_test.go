@@ -13,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xuri/excelize/v2"
+
+	"isxcli/internal/dataprocessing"
 )
 
 // TestMain removed - flag parsing is handled in main.go
@@ -240,7 +242,8 @@ func TestExtractIndices(t *testing.T) {
 			require.NoError(t, err)
 			
 			// Test the function
-			isx60, isx15, err := extractIndices(excelPath)
+			values, err := extractIndices(excelPath)
+			isx60, isx15 := values[dataprocessing.IndexISX60], values[dataprocessing.IndexISX15]
 			
 			if tt.expectError {
 				assert.Error(t, err, tt.description)
@@ -253,6 +256,70 @@ func TestExtractIndices(t *testing.T) {
 	}
 }
 
+func TestExtractIndices_SectorIndices(t *testing.T) {
+	excelPath := filepath.Join(t.TempDir(), "test.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+	_, err := f.NewSheet("Indices")
+	require.NoError(t, err)
+	rows := [][]string{
+		{"ISX Index 60", "1234.56", "ISX Index 15", "567.89"},
+		{"Sector Indices", "", ""},
+		{"Banking Sector Index", "", "75.25"},
+		{"Telecommunication", "1,020.50"},
+		{"Bank of Baghdad", "0.45"},
+		{"Industry", "-"},
+	}
+	for rowIdx, row := range rows {
+		for colIdx, cellValue := range row {
+			f.SetCellValue("Indices", getCellName(rowIdx+1, colIdx+1), cellValue)
+		}
+	}
+	require.NoError(t, f.SaveAs(excelPath))
+
+	values, err := extractIndices(excelPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		dataprocessing.IndexISX60: 1234.56,
+		dataprocessing.IndexISX15: 567.89,
+		"Banking":                 75.25,
+		"Telecom":                 1020.50,
+	}, values)
+	assert.Equal(t, 2, sectorCount(values))
+}
+
+func TestIndexRecords(t *testing.T) {
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	values := map[string]float64{dataprocessing.IndexISX60: 1234.56, "Banking": 75.25}
+
+	wide := wideRecord(date, values)
+	require.Len(t, wide, len(dataprocessing.IndexCSVHeader()))
+	assert.Equal(t, []string{"2025-01-15", "1234.56", ""}, wide[:3])
+	assert.Equal(t, "75.25", wide[3])
+
+	assert.Equal(t, [][]string{
+		{"2025-01-15", dataprocessing.IndexISX60, "1234.56"},
+		{"2025-01-15", "Banking", "75.25"},
+	}, longRecords(date, values))
+}
+
+func TestCheckIndexSchema(t *testing.T) {
+	dir := t.TempDir()
+	widePath := filepath.Join(dir, "indexes.csv")
+	longPath := filepath.Join(dir, "indexes_long.csv")
+
+	require.NoError(t, writeCSVAtomic(widePath, dataprocessing.IndexCSVHeader(), nil))
+	assert.Error(t, checkIndexSchema(widePath, longPath), "long file missing")
+
+	require.NoError(t, writeCSVAtomic(longPath, dataprocessing.IndexLongCSVHeader(), nil))
+	assert.NoError(t, checkIndexSchema(widePath, longPath))
+
+	// Files written before sector indices were extracted are rebuilt
+	require.NoError(t, os.WriteFile(widePath, []byte("Date,ISX60,ISX15\n"), 0644))
+	assert.Error(t, checkIndexSchema(widePath, longPath))
+}
+
 func TestParseFloat(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -612,7 +679,7 @@ func BenchmarkExtractIndices(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _, _ = extractIndices(excelPath)
+		_, _ = extractIndices(excelPath)
 	}
 }
 
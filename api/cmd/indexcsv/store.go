@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/dataprocessing"
+)
+
+// checkpointEvery is how many extracted reports are committed at a time, so
+// a failure late in a long run keeps the work done before it
+const checkpointEvery = 50
+
+// manifestVersion is the layout version of the extraction manifest
+const manifestVersion = 1
+
+// extractionManifest records the reports whose indices are in indexes.csv,
+// keyed by file name. It is kept next to indexes.csv and replaced together
+// with it, so a report is extracted again only when it is new or changed.
+type extractionManifest struct {
+	Version   int                      `json:"version"`
+	UpdatedAt time.Time                `json:"updated_at"`
+	Files     map[string]manifestEntry `json:"files"`
+}
+
+// manifestEntry identifies the version of a report that was extracted
+type manifestEntry struct {
+	Date        string    `json:"date"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	ExtractedAt time.Time `json:"extracted_at"`
+}
+
+// manifestPath returns the sidecar manifest of an indexes.csv file
+func manifestPath(widePath string) string {
+	return strings.TrimSuffix(widePath, ".csv") + ".files.json"
+}
+
+// indexStore holds the rows of indexes.csv and indexes_long.csv keyed by
+// date. Re-extracting a date replaces its rows instead of appending
+// duplicates, and commit rewrites both files through temp files and renames,
+// so an interrupted run leaves the previous files intact.
+type indexStore struct {
+	widePath string
+	longPath string
+	wide     map[string][]string   // date → indexes.csv record
+	long     map[string][][]string // date → indexes_long.csv records
+	manifest extractionManifest
+	dirty    bool
+}
+
+// newIndexStore starts empty output files, as in initial mode
+func newIndexStore(widePath, longPath string) *indexStore {
+	return &indexStore{
+		widePath: widePath,
+		longPath: longPath,
+		wide:     make(map[string][]string),
+		long:     make(map[string][][]string),
+		manifest: extractionManifest{Version: manifestVersion, Files: make(map[string]manifestEntry)},
+		dirty:    true,
+	}
+}
+
+// loadIndexStore reads the existing output files and manifest. Rows repeated
+// for a date by earlier versions are collapsed to the last one. A missing
+// manifest is not an error; dates already in indexes.csv then count as
+// extracted.
+func loadIndexStore(widePath, longPath string) (*indexStore, error) {
+	s := newIndexStore(widePath, longPath)
+	s.dirty = false
+
+	wideRows, err := readCSVRows(widePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range wideRows {
+		if len(rec) == 0 || rec[0] == "" {
+			continue
+		}
+		if _, dup := s.wide[rec[0]]; dup {
+			s.dirty = true
+		}
+		s.wide[rec[0]] = rec
+	}
+
+	longRows, err := readCSVRows(longPath)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, rec := range longRows {
+		if len(rec) < 3 {
+			continue
+		}
+		key := rec[0] + "," + rec[1]
+		if seen[key] {
+			// Duplicate index level for a date; keep the first
+			s.dirty = true
+			continue
+		}
+		seen[key] = true
+		s.long[rec[0]] = append(s.long[rec[0]], rec)
+	}
+
+	data, err := os.ReadFile(manifestPath(widePath))
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+	var manifest extractionManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestPath(widePath), err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]manifestEntry)
+	}
+	s.manifest = manifest
+	return s, nil
+}
+
+// readCSVRows returns the rows of a CSV file after its header
+func readCSVRows(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[1:], nil
+}
+
+// needsExtraction reports whether a report is new, changed since it was
+// extracted or missing from either CSV file. A date in both files but not in
+// the manifest was written before the manifest existed, or by a run stopped
+// before saving it, and counts as extracted.
+func (s *indexStore) needsExtraction(name string, date time.Time, info fs.FileInfo) bool {
+	day := date.Format("2006-01-02")
+	if _, ok := s.wide[day]; !ok || len(s.long[day]) == 0 {
+		return true
+	}
+	entry, ok := s.manifest.Files[name]
+	if !ok {
+		return false
+	}
+	return entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime())
+}
+
+// add stores the index levels of one report, replacing any rows of its date
+func (s *indexStore) add(name string, date time.Time, info fs.FileInfo, values map[string]float64) {
+	day := date.Format("2006-01-02")
+	s.wide[day] = wideRecord(date, values)
+	s.long[day] = longRecords(date, values)
+	entry := manifestEntry{Date: day, ExtractedAt: time.Now().UTC()}
+	if info != nil {
+		entry.Size = info.Size()
+		entry.ModTime = info.ModTime()
+	}
+	s.manifest.Files[name] = entry
+	s.dirty = true
+}
+
+// commit writes both CSV files and then the manifest, each to a temp file
+// renamed over the original. It does nothing when nothing changed.
+func (s *indexStore) commit() error {
+	if !s.dirty {
+		return nil
+	}
+
+	dates := make([]string, 0, len(s.wide))
+	for day := range s.wide {
+		dates = append(dates, day)
+	}
+	sort.Strings(dates)
+
+	wide := make([][]string, 0, len(dates))
+	var long [][]string
+	for _, day := range dates {
+		wide = append(wide, s.wide[day])
+		long = append(long, s.long[day]...)
+	}
+
+	if err := writeCSVAtomic(s.widePath, dataprocessing.IndexCSVHeader(), wide); err != nil {
+		return err
+	}
+	if err := writeCSVAtomic(s.longPath, dataprocessing.IndexLongCSVHeader(), long); err != nil {
+		return err
+	}
+
+	s.manifest.Version = manifestVersion
+	s.manifest.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := manifestPath(s.widePath)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace manifest: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// writeCSVAtomic writes a CSV file through a temp file in the same directory
+func writeCSVAtomic(path string, header []string, rows [][]string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	w := csv.NewWriter(tmp)
+	if err := w.Write(header); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/dataprocessing"
+)
+
+func TestIndexStoreCommitIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	widePath := filepath.Join(dir, "indexes.csv")
+	longPath := filepath.Join(dir, "indexes_long.csv")
+	report := filepath.Join(dir, "2025 01 12 ISX Daily Report.xlsx")
+	require.NoError(t, os.WriteFile(report, []byte("report"), 0644))
+	info, err := os.Stat(report)
+	require.NoError(t, err)
+
+	day1 := time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	store := newIndexStore(widePath, longPath)
+	store.add("2025 01 12 ISX Daily Report.xlsx", day2, info, map[string]float64{dataprocessing.IndexISX60: 900})
+	store.add("2025 01 11 ISX Daily Report.xlsx", day1, nil, map[string]float64{dataprocessing.IndexISX60: 880})
+	require.NoError(t, store.commit())
+
+	rows, err := readCSVRows(widePath)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "2025-01-11", rows[0][0], "rows are ordered by date")
+	assert.FileExists(t, manifestPath(widePath))
+
+	// A second run re-extracting a date replaces its rows
+	store, err = loadIndexStore(widePath, longPath)
+	require.NoError(t, err)
+	assert.False(t, store.needsExtraction("2025 01 12 ISX Daily Report.xlsx", day2, info), "unchanged report")
+	store.add("2025 01 12 ISX Daily Report.xlsx", day2, info, map[string]float64{dataprocessing.IndexISX60: 905})
+	require.NoError(t, store.commit())
+
+	rows, err = readCSVRows(widePath)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "905.00", rows[1][1])
+	longRows, err := readCSVRows(longPath)
+	require.NoError(t, err)
+	assert.Len(t, longRows, 2)
+
+	// A changed report is extracted again
+	later := info.ModTime().Add(time.Hour)
+	require.NoError(t, os.Chtimes(report, later, later))
+	changed, err := os.Stat(report)
+	require.NoError(t, err)
+	assert.True(t, store.needsExtraction("2025 01 12 ISX Daily Report.xlsx", day2, changed))
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches, "temp files are renamed or removed")
+}
+
+func TestLoadIndexStoreDropsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	widePath := filepath.Join(dir, "indexes.csv")
+	longPath := filepath.Join(dir, "indexes_long.csv")
+
+	// An interrupted append of older versions left 2025-01-11 twice
+	day := time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC)
+	values := map[string]float64{dataprocessing.IndexISX60: 880}
+	wide := wideRecord(day, values)
+	long := longRecords(day, values)
+	require.NoError(t, writeCSVAtomic(widePath, dataprocessing.IndexCSVHeader(), [][]string{wide, wide}))
+	require.NoError(t, writeCSVAtomic(longPath, dataprocessing.IndexLongCSVHeader(), append(long, long...)))
+
+	store, err := loadIndexStore(widePath, longPath)
+	require.NoError(t, err)
+	assert.True(t, store.dirty)
+
+	// Without a manifest, dates already in both files count as extracted
+	info, err := os.Stat(widePath)
+	require.NoError(t, err)
+	assert.False(t, store.needsExtraction("2025 01 11 ISX Daily Report.xlsx", day, info))
+	assert.True(t, store.needsExtraction("2025 01 12 ISX Daily Report.xlsx", day.AddDate(0, 0, 1), info))
+
+	require.NoError(t, store.commit())
+	rows, err := readCSVRows(widePath)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+	longRows, err := readCSVRows(longPath)
+	require.NoError(t, err)
+	assert.Len(t, longRows, 1)
+}
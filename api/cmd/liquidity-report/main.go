@@ -8,19 +8,62 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"isxcli/internal/config"
+	"isxcli/internal/exporter"
+	"isxcli/internal/i18n"
 	"isxcli/internal/license"
 	"isxcli/internal/liquidity"
+	"isxcli/pkg/contracts/schema"
 )
 
 func main() {
 	outputDir := flag.String("out", "", "output directory for liquidity report (defaults to data/reports)")
 	windowSize := flag.Int("window", 60, "window size for liquidity calculation (20, 60, or 120 days)")
+	windowSet := flag.String("windows", "", "comma-separated windows calculated in one run, e.g. 20,60,120; adds a wide report with per-window and blended scores")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	workers := flag.Int("workers", 0, "tickers calculated concurrently (defaults to $ISX_LIQUIDITY_WORKERS or GOMAXPROCS)")
+	calibrate := flag.Bool("calibrate", false, "calibrate penalty parameters and weights instead of writing a report")
+	seed := flag.Int64("seed", 0, "random seed of the calibration k-fold split (0 picks one and records it in the result)")
+	penaltyName := flag.String("penalty", "", "penalty function for inactive tickers: "+strings.Join(liquidity.PenaltyFuncNames(), ", ")+" (defaults to the calibration's, else "+liquidity.DefaultPenaltyFunc+")")
+	calibrationPath := flag.String("calibration", "", "calibration results file whose penalty function, parameters and weights the report uses")
 	flag.Parse()
 
+	if *penaltyName != "" {
+		if _, err := liquidity.LookupPenaltyFunc(*penaltyName); err != nil {
+			slog.Error("Invalid penalty function", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Set up liquidity calculation parameters
+	window, err := liquidity.ParseWindow(strconv.Itoa(*windowSize))
+	if err != nil {
+		slog.Error("Invalid window", "error", err)
+		os.Exit(1)
+	}
+	var windows []liquidity.Window
+	if *windowSet != "" {
+		if windows, err = liquidity.ParseWindows(*windowSet); err != nil {
+			slog.Error("Invalid window set", "error", err)
+			os.Exit(1)
+		}
+		// The single-window reports come from -window, so it is always calculated
+		if !containsWindow(windows, window) {
+			windows = append(windows, window)
+			sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+		}
+	}
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize paths
 	paths, err := config.GetPaths()
 	if err != nil {
@@ -28,6 +71,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// External combined CSVs may name their columns differently
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Warn("Failed to load config, using defaults", "error", err)
+		cfg = config.Default()
+	}
+	mapping, err := schema.NewMapping(cfg.Processing.ColumnMap)
+	if err != nil {
+		slog.Error("Invalid column mapping", "error", err)
+		os.Exit(1)
+	}
+
 	// License validation
 	slog.Info("Validating license...")
 	licensePath, err := config.GetLicensePath()
@@ -60,6 +115,10 @@ func main() {
 
 	// Load trading data from combined CSV
 	combinedPath := filepath.Join(*outputDir, "isx_combined_data.csv")
+	if _, err := os.Stat(combinedPath); os.IsNotExist(err) {
+		// The processor writes it to the combined subdirectory
+		combinedPath = filepath.Join(*outputDir, "combined", "isx_combined_data.csv")
+	}
 	slog.Info("Loading trading data", "path", combinedPath)
 	
 	// Check if combined CSV exists
@@ -70,7 +129,7 @@ func main() {
 		os.Exit(1)
 	}
 	
-	tradingData, err := loadTradingData(combinedPath)
+	tradingData, err := loadTradingData(combinedPath, mapping)
 	if err != nil {
 		slog.Error("Failed to load trading data", "error", err)
 		os.Exit(1)
@@ -86,9 +145,11 @@ func main() {
 	
 	slog.Info("Loaded trading data", "records", len(tradingData))
 
-	// Set up liquidity calculation parameters
-	window := liquidity.Window(*windowSize)
-	
+	if *calibrate {
+		runCalibration(tradingData, *seed, *penaltyName, *outputDir)
+		return
+	}
+
 	// Default parameters
 	penaltyParams := liquidity.PenaltyParams{
 		PiecewiseP0:       1.0,
@@ -107,14 +168,47 @@ func main() {
 		Continuity: 0.20,
 		Spread:     0.10,
 	}
+	weights.Normalize() // Spread is no longer scored; rescale the rest to sum to 1, as the liquidity stage does
+	
+	// Calibrated parameters replace the defaults; -penalty still wins
+	penaltyFunction := *penaltyName
+	if *calibrationPath != "" {
+		calibration, err := liquidity.LoadCalibrationResults(*calibrationPath)
+		if err != nil {
+			slog.Error("Failed to load calibration results", "error", err)
+			os.Exit(1)
+		}
+		penaltyParams = calibration.OptimalParams
+		weights = calibration.OptimalWeights
+		if penaltyFunction == "" {
+			penaltyFunction = calibration.PenaltyFunction
+		}
+		slog.Info("Using calibration results", "path", *calibrationPath, "calibrated", calibration.CalibrationDate)
+	}
+	penalty, err := liquidity.LookupPenaltyFunc(penaltyFunction)
+	if err != nil {
+		slog.Error("Invalid penalty function", "error", err)
+		os.Exit(1)
+	}
 	
 	// Create calculator
 	calc := liquidity.NewCalculator(window, penaltyParams, weights, slog.Default())
+	calc.SetMaxConcurrency(*workers)
+	calc.SetPenaltyFunc(penalty)
+	slog.Info("Penalty function", "name", penalty.Name())
 	
 	// Calculate liquidity metrics
 	slog.Info("Calculating liquidity metrics...")
 	ctx := context.Background()
-	metrics, err := calc.Calculate(ctx, tradingData)
+	var metrics []liquidity.TickerMetrics
+	var results map[liquidity.Window][]liquidity.TickerMetrics
+	if len(windows) > 0 {
+		// One load of the trading data serves every window
+		results, err = calc.CalculateWindows(ctx, tradingData, windows)
+		metrics = results[window]
+	} else {
+		metrics, err = calc.Calculate(ctx, tradingData)
+	}
 	if err != nil {
 		slog.Error("Failed to calculate liquidity metrics", "error", err)
 		os.Exit(1)
@@ -139,6 +233,42 @@ func main() {
 		os.Exit(1)
 	}
 	
+	// Excel version of the report with rankings, color scales and sparklines,
+	// its headers in the configured export language
+	xlsxPath := strings.TrimSuffix(outputPath, ".csv") + ".xlsx"
+	locale, err := i18n.NewLocale(cfg.Export.Language, cfg.Export.Digits)
+	if err != nil {
+		slog.Warn("Invalid export locale, using English", "error", err)
+	}
+	if err := liquidity.SaveLocalizedXLSX(metrics, xlsxPath, locale); err != nil {
+		// The CSV is the primary output; the workbook is a convenience
+		slog.Warn("Failed to save XLSX liquidity report", "path", xlsxPath, "error", err)
+	}
+	
+	// Wide report of a multi-window run: per-window scores and their blend
+	windowsPath := ""
+	if len(windows) > 0 {
+		windowsPath = filepath.Join(reportDir, fmt.Sprintf("liquidity_windows_%s.csv", timestamp))
+		if err := liquidity.SaveMultiWindowCSV(liquidity.BlendWindows(results, windows), windows, windowsPath); err != nil {
+			slog.Error("Failed to save multi-window liquidity report", "error", err)
+			os.Exit(1)
+		}
+	}
+	
+	// Keep per-window metrics across runs so score history can be queried
+	historyMetrics := metrics
+	if len(windows) > 0 {
+		historyMetrics = nil
+		for _, w := range windows {
+			historyMetrics = append(historyMetrics, results[w]...)
+		}
+	}
+	historyPath := liquidity.HistoryPath(*outputDir)
+	if err := liquidity.AppendHistory(historyMetrics, historyPath, time.Now()); err != nil {
+		// History is supplementary; the report itself was written
+		slog.Warn("Failed to update liquidity history", "path", historyPath, "error", err)
+	}
+	
 	// Also save summary report
 	summaryDir := filepath.Join(*outputDir, "liquidity", "summaries")
 	if err := os.MkdirAll(summaryDir, 0755); err != nil {
@@ -154,6 +284,8 @@ func main() {
 	
 	slog.Info("Liquidity report generated successfully",
 		"report", outputPath,
+		"xlsx", xlsxPath,
+		"windows", windowsPath,
 		"summary", summaryPath,
 		"metrics", len(metrics))
 	
@@ -161,7 +293,61 @@ func main() {
 	printSummaryStats(metrics)
 }
 
-func loadTradingData(csvPath string) ([]liquidity.TradingDay, error) {
+// containsWindow reports whether windows includes w
+func containsWindow(windows []liquidity.Window, w liquidity.Window) bool {
+	for _, candidate := range windows {
+		if candidate == w {
+			return true
+		}
+	}
+	return false
+}
+
+// runCalibration calibrates penalty parameters and component weights for a
+// penalty function and saves the result, including its seed and penalty name,
+// under liquidity/calibration
+func runCalibration(tradingData []liquidity.TradingDay, seed int64, penalty, outputDir string) {
+	data := make(map[string][]liquidity.TradingDay)
+	for _, td := range tradingData {
+		data[td.Symbol] = append(data[td.Symbol], td)
+	}
+	for symbol := range data {
+		days := data[symbol]
+		sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	}
+
+	config := liquidity.DefaultCalibrationConfig()
+	config.RandomSeed = seed
+	config.PenaltyFunction = penalty
+
+	slog.Info("Calibrating liquidity parameters...", "tickers", len(data), "seed", seed, "penalty", penalty)
+	result, err := liquidity.Calibrate(context.Background(), data, config)
+	if err != nil {
+		slog.Error("Calibration failed", "error", err)
+		os.Exit(1)
+	}
+
+	outputPath := filepath.Join(outputDir, "liquidity", "calibration",
+		fmt.Sprintf("calibration_%s.json", time.Now().Format("20060102_150405")))
+	if err := liquidity.ExportCalibrationResults(result, outputPath); err != nil {
+		slog.Error("Failed to save calibration results", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Calibration completed",
+		"path", outputPath,
+		"seed", result.RandomSeed,
+		"penalty", result.PenaltyFunction,
+		"cv_r2", result.CrossValidationR2,
+		"spread_corr", result.SpreadCorrelation)
+}
+
+// loadTradingData reads the combined CSV, matching its columns by name
+// through mapping
+func loadTradingData(csvPath string, mapping schema.Mapping) ([]liquidity.TradingDay, error) {
+	if err := exporter.CheckSchemaManifest(csvPath); err != nil {
+		return nil, err
+	}
 	file, err := os.Open(csvPath)
 	if err != nil {
 		return nil, fmt.Errorf("open CSV file: %w", err)
@@ -175,44 +361,26 @@ func loadTradingData(csvPath string) ([]liquidity.TradingDay, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read header: %w", err)
 	}
-	
-	// Find column indices
-	dateIdx := -1
-	symbolIdx := -1
-	openIdx := -1
-	highIdx := -1
-	lowIdx := -1
-	closeIdx := -1
-	volumeIdx := -1
-	valueIdx := -1
-	numTradesIdx := -1
-	statusIdx := -1
-	
-	for i, col := range header {
-		switch col {
-		case "Date":
-			dateIdx = i
-		case "Symbol", "Ticker":  // Support both column names
-			symbolIdx = i
-		case "OpenPrice", "Open":
-			openIdx = i
-		case "HighPrice", "High":
-			highIdx = i
-		case "LowPrice", "Low":
-			lowIdx = i
-		case "ClosePrice", "Close":
-			closeIdx = i
-		case "Volume":
-			volumeIdx = i
-		case "Value":
-			valueIdx = i
-		case "NumTrades", "NumOfTrades":
-			numTradesIdx = i
-		case "TradingStatus", "Status":
-			statusIdx = i
-		}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	// Columns are looked up by name, so every known schema version reads the
+	// same way; other layouts are read if they have the columns needed
+	if combined, err := exporter.DetectCombinedSchema(header); err != nil {
+		slog.Warn("Combined CSV has an unrecognised schema, reading columns by name", "error", err)
+	} else {
+		slog.Info("Combined CSV schema", "version", combined.Version)
 	}
 	
+	cols := mapping.Resolve(header)
+	if missing := cols.Missing(
+		schema.ColumnDate, schema.ColumnSymbol, schema.ColumnOpenPrice, schema.ColumnHighPrice,
+		schema.ColumnLowPrice, schema.ColumnClosePrice, schema.ColumnVolume, schema.ColumnValue,
+		schema.ColumnNumTrades, schema.ColumnTradingStatus,
+	); len(missing) > 0 {
+		return nil, fmt.Errorf("combined CSV is missing columns: %s (map them under processing.column_map)", strings.Join(missing, ", "))
+	}
+
 	// Read data
 	var tradingData []liquidity.TradingDay
 	
@@ -223,25 +391,25 @@ func loadTradingData(csvPath string) ([]liquidity.TradingDay, error) {
 		}
 		
 		// Parse date
-		dateStr := record[dateIdx]
+		dateStr := cols.Value(record, schema.ColumnDate)
 		date, err := time.Parse("2006-01-02", dateStr)
 		if err != nil {
 			continue // Skip invalid dates
 		}
 		
 		// Parse numeric fields
-		open, _ := strconv.ParseFloat(record[openIdx], 64)
-		high, _ := strconv.ParseFloat(record[highIdx], 64)
-		low, _ := strconv.ParseFloat(record[lowIdx], 64)
-		close, _ := strconv.ParseFloat(record[closeIdx], 64)
-		volume, _ := strconv.ParseFloat(record[volumeIdx], 64)
-		value, _ := strconv.ParseFloat(record[valueIdx], 64)
-		numTrades, _ := strconv.Atoi(record[numTradesIdx])
+		open, _ := strconv.ParseFloat(cols.Value(record, schema.ColumnOpenPrice), 64)
+		high, _ := strconv.ParseFloat(cols.Value(record, schema.ColumnHighPrice), 64)
+		low, _ := strconv.ParseFloat(cols.Value(record, schema.ColumnLowPrice), 64)
+		close, _ := strconv.ParseFloat(cols.Value(record, schema.ColumnClosePrice), 64)
+		volume, _ := strconv.ParseFloat(cols.Value(record, schema.ColumnVolume), 64)
+		value, _ := strconv.ParseFloat(cols.Value(record, schema.ColumnValue), 64)
+		numTrades, _ := strconv.Atoi(cols.Value(record, schema.ColumnNumTrades))
 		
 		// Create trading day
 		td := liquidity.TradingDay{
 			Date:          date,
-			Symbol:        record[symbolIdx],
+			Symbol:        cols.Value(record, schema.ColumnSymbol),
 			Open:          open,
 			High:          high,
 			Low:           low,
@@ -250,7 +418,7 @@ func loadTradingData(csvPath string) ([]liquidity.TradingDay, error) {
 			ShareVolume:   volume, // Same as Volume
 			Value:         value,  // Value in IQD
 			NumTrades:     numTrades,
-			TradingStatus: record[statusIdx],
+			TradingStatus: cols.Value(record, schema.ColumnTradingStatus),
 		}
 		
 		tradingData = append(tradingData, td)
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"isxcli/internal/config"
+	"isxcli/internal/exporter"
+	"isxcli/internal/files"
+	"isxcli/internal/infrastructure"
+	"isxcli/internal/migrations"
+)
+
+func main() {
+	file := flag.String("file", "", "upgrade a single combined CSV (e.g. from a backup) to the current schema instead of the data directory")
+	dryRun := flag.Bool("dry-run", false, "list the pending migrations without applying them")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	flag.Parse()
+
+	if *file != "" {
+		migrateFile(*file, *dryRun)
+		return
+	}
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Warn("Failed to load config, using defaults", "error", err)
+		cfg = &config.Config{
+			Logging: config.LoggingConfig{
+				Level:       "info",
+				Format:      "json",
+				Output:      "both",
+				FilePath:    paths.GetLogPath("migrate.log"),
+				Development: false,
+			},
+		}
+	}
+
+	logger, err := infrastructure.InitializeLogger(cfg.Logging)
+	if err != nil {
+		slog.Warn("Failed to initialize logger, using default", "error", err)
+		logger = slog.Default()
+	}
+
+	pending, err := migrations.Pending(paths)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(pending) == 0 {
+		fmt.Println("Data is up to date, nothing to migrate")
+		return
+	}
+	for _, m := range pending {
+		fmt.Printf("Pending: %s (%s) - %s\n", m.ID, m.Version, m.Description)
+	}
+	if *dryRun {
+		return
+	}
+
+	lock, err := files.AcquireDirLock(paths.ReportsDir, "migrate", files.LockOptions{Logger: logger})
+	if err != nil {
+		fmt.Printf("Error: reports directory busy: %v\n", err)
+		os.Exit(1)
+	}
+	defer lock.Release()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	applied, err := migrations.Run(ctx, paths, logger)
+	for _, a := range applied {
+		fmt.Printf("Applied: %s - %s\n", a.ID, a.Result)
+	}
+	if err != nil {
+		logger.Error("Data migration failed", slog.String("error", err.Error()))
+		fmt.Printf("Error: %v\n", err)
+		lock.Release()
+		os.Exit(1)
+	}
+}
+
+// migrateFile upgrades one combined CSV to the current schema
+func migrateFile(path string, dryRun bool) {
+	if dryRun {
+		schema, err := exporter.ReadCombinedSchema(path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s has schema v%d; current is v%d\n", path, schema.Version, exporter.CombinedSchemaVersion)
+		return
+	}
+
+	from, err := exporter.MigrateCombinedCSV(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if from == exporter.CombinedSchemaVersion {
+		fmt.Printf("%s is already at schema v%d; manifest written\n", path, from)
+		return
+	}
+	fmt.Printf("Upgraded %s from schema v%d to v%d\n", path, from, exporter.CombinedSchemaVersion)
+}
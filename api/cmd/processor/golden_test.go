@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// Run with -update to rewrite testdata/golden after an intended format change
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+// goldenRecords is three sessions of two tickers, one of them forward-filled
+// for a day
+func goldenRecords() []domain.TradeRecord {
+	d := func(day int) time.Time { return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC) }
+	return []domain.TradeRecord{
+		{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: d(5), OpenPrice: 1.25, HighPrice: 1.3, LowPrice: 1.24, AveragePrice: 1.27, ClosePrice: 1.28, PrevClosePrice: 1.25, Change: 0.03, ChangePercent: 2.4, NumTrades: 12, Volume: 150000, Value: 190500, TradingStatus: true},
+		{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: d(6), OpenPrice: 1.28, HighPrice: 1.28, LowPrice: 1.28, AveragePrice: 1.28, ClosePrice: 1.28, PrevClosePrice: 1.28, TradingStatus: false, FillMethod: domain.FillMethodCarryForward},
+		{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: d(7), OpenPrice: 1.29, HighPrice: 1.33, LowPrice: 1.29, AveragePrice: 1.31, ClosePrice: 1.32, PrevClosePrice: 1.28, Change: 0.04, ChangePercent: 3.13, NumTrades: 8, Volume: 90000, Value: 117900, TradingStatus: true},
+		{CompanyName: "Asiacell", CompanySymbol: "TASC", Date: d(5), OpenPrice: 8.1, HighPrice: 8.2, LowPrice: 8.05, AveragePrice: 8.12, ClosePrice: 8.15, PrevClosePrice: 8.1, Change: 0.05, ChangePercent: 0.62, NumTrades: 30, Volume: 20000, Value: 162400, TradingStatus: true},
+		{CompanyName: "Asiacell", CompanySymbol: "TASC", Date: d(6), OpenPrice: 8.15, HighPrice: 8.3, LowPrice: 8.1, AveragePrice: 8.2, ClosePrice: 8.25, PrevClosePrice: 8.15, Change: 0.1, ChangePercent: 1.23, NumTrades: 25, Volume: 18000, Value: 147600, TradingStatus: true},
+		{CompanyName: "Asiacell", CompanySymbol: "TASC", Date: d(7), OpenPrice: 8.25, HighPrice: 8.25, LowPrice: 8.0, AveragePrice: 8.1, ClosePrice: 8.05, PrevClosePrice: 8.25, Change: -0.2, ChangePercent: -2.42, NumTrades: 40, Volume: 30000, Value: 243000, TradingStatus: true},
+	}
+}
+
+// assertGoldenDir compares every file under dir with testdata/golden/name,
+// or rewrites the golden files when -update is set
+func assertGoldenDir(t *testing.T, dir, name string) {
+	t.Helper()
+	goldenDir := filepath.Join("testdata", "golden", name)
+	list := func(dir string) []string {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if *updateGolden {
+		require.NoError(t, os.RemoveAll(goldenDir))
+		require.NoError(t, os.MkdirAll(goldenDir, 0755))
+		for _, file := range list(dir) {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(goldenDir, file), data, 0644))
+		}
+		return
+	}
+
+	require.Equal(t, list(goldenDir), list(dir), "written files")
+	for _, file := range list(dir) {
+		want, err := os.ReadFile(filepath.Join(goldenDir, file))
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s differs from its golden file", file)
+	}
+}
+
+// TestGoldenOutputs checks the processor's CSVs are byte-identical whatever
+// order the records arrive in
+func TestGoldenOutputs(t *testing.T) {
+	for _, seed := range []int64{0, 1, 7, 42} {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			records := goldenRecords()
+			if seed != 0 {
+				rand.New(rand.NewSource(seed)).Shuffle(len(records), func(i, j int) {
+					records[i], records[j] = records[j], records[i]
+				})
+			}
+
+			combinedDir := t.TempDir()
+			require.NoError(t, saveCombinedCSV(filepath.Join(combinedDir, "isx_combined_data.csv"), records))
+			assertGoldenDir(t, combinedDir, "combined")
+
+			dailyDir := t.TempDir()
+			require.NoError(t, generateDailyFiles(records, dailyDir))
+			assertGoldenDir(t, dailyDir, "daily")
+
+			tickerDir := t.TempDir()
+			require.NoError(t, generateTickerFiles(records, tickerDir))
+			assertGoldenDir(t, tickerDir, "ticker")
+		})
+	}
+}
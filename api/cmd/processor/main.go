@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -17,8 +19,13 @@ import (
 	"isxcli/internal/config"
 	"isxcli/internal/infrastructure"
 	"isxcli/internal/dataprocessing"
+	"isxcli/internal/dataprocessing/analytics"
+	"isxcli/internal/exporter"
+	"isxcli/internal/files"
 	"isxcli/internal/license"
 	"isxcli/pkg/contracts/domain"
+	"isxcli/pkg/contracts/events"
+	"isxcli/pkg/contracts/schema"
 )
 
 // ExcelFileInfo holds information about an Excel file
@@ -27,12 +34,41 @@ type ExcelFileInfo struct {
 	Date time.Time
 }
 
+// exportOptions is the canonical formatting of the CSVs the processor writes.
+// The pipeline reads these files back, so the configurable export formatting
+// only applies to user-facing exports.
+var exportOptions = exporter.DefaultExportOptions()
+
+// columnMapping matches the columns of combined CSVs in other layouts; set
+// from processing.column_map
+var columnMapping = schema.DefaultMapping()
+
 func main() {
 	inDir := flag.String("in", "", "input directory for .xlsx files (defaults to data/downloads relative to executable)")
 	outDir := flag.String("out", "", "output directory for CSV files (defaults to data/reports relative to executable)")
 	fullRework := flag.Bool("full", false, "force full rework of all files")
+	fromDate := flag.String("from", "", "reprocess only reports on or after this date (YYYY-MM-DD), keeping other dates of the existing outputs")
+	toDate := flag.String("to", "", "reprocess only reports on or before this date (YYYY-MM-DD), keeping other dates of the existing outputs")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	snapshots := flag.Bool("snapshots", true, "ingest mid-session snapshot bulletins into the preliminary snapshot store")
+	fill := flag.String("fill", "", "how days a ticker did not trade are filled: carry_forward, none, nan or interpolate (defaults to $ISX_PROCESSING_FILL_STRATEGY or carry_forward)")
 	flag.Parse()
 
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	reprocess, err := parseReprocessRange(*fromDate, *toDate)
+	if err != nil {
+		slog.Error("Invalid date range", "error", err)
+		os.Exit(1)
+	}
+	if reprocess != nil && *fullRework {
+		slog.Error("--full cannot be combined with --from or --to")
+		os.Exit(1)
+	}
+
 	// Initialize paths first to get default directories
 	paths, err := config.GetPaths()
 	if err != nil {
@@ -95,8 +131,22 @@ func main() {
 				FilePath:    paths.GetLogPath("process.log"),
 				Development: false,
 			},
+			Processing: config.Default().Processing,
 		}
 	}
+	if columnMapping, err = schema.NewMapping(cfg.Processing.ColumnMap); err != nil {
+		slog.Error("Invalid column mapping", "error", err)
+		os.Exit(1)
+	}
+
+	if *fill == "" {
+		*fill = cfg.Processing.FillStrategy
+	}
+	fillStrategy, err := dataprocessing.ParseFillStrategy(*fill)
+	if err != nil {
+		slog.Error("Invalid fill strategy", "error", err)
+		os.Exit(1)
+	}
 
 	logger, err := infrastructure.InitializeLogger(cfg.Logging)
 	if err != nil {
@@ -117,6 +167,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Hold the reports directory so a pipeline run and a manual run cannot
+	// write it at the same time. Exits below leave the lock behind; it is
+	// taken over once this process is gone.
+	dirLock, err := files.AcquireDirLock(*outDir, "processor", files.LockOptions{Logger: logger})
+	if err != nil {
+		logger.Error("Output directory is locked", slog.String("error", err.Error()))
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer dirLock.Release()
+
 	slog.Info("Starting ISX Daily Reports processing...")
 	logger.Info("Processing directories",
 		slog.String("input_dir", *inDir),
@@ -125,7 +186,7 @@ func main() {
 	slog.Info("Full rework mode", "enabled", *fullRework)
 
 	// Get all available Excel files
-	files, err := ioutil.ReadDir(*inDir)
+	entries, err := ioutil.ReadDir(*inDir)
 	if err != nil {
 		logger.Error("Failed to read input directory", slog.String("error", err.Error()))
 		slog.Error("Failed to read input directory", "error", err)
@@ -134,11 +195,27 @@ func main() {
 
 	// Parse and sort all available files by date
 	var excelFiles []ExcelFileInfo
-	for _, file := range files {
+	intradayFiles := make(map[string]string) // date (YYYY-MM-DD) -> intraday bulletin filename
+	var snapshotFiles []string
+	for _, file := range entries {
 		if !strings.HasSuffix(file.Name(), ".xlsx") || strings.HasPrefix(file.Name(), "~$") {
 			continue
 		}
 
+		// Intraday bulletins are optional companions of the daily report, not reports themselves
+		if dataprocessing.IsIntradayFile(file.Name()) {
+			if date, err := dataprocessing.IntradayFileDate(file.Name()); err == nil {
+				intradayFiles[date.Format("2006-01-02")] = file.Name()
+			}
+			continue
+		}
+
+		// Mid-session snapshots are preliminary and never enter the end-of-day records
+		if dataprocessing.IsSnapshotFile(file.Name()) {
+			snapshotFiles = append(snapshotFiles, file.Name())
+			continue
+		}
+
 		// Extract date from filename (e.g., "YYYY MM DD ISX Daily Report.xlsx")
 		parts := strings.Split(file.Name(), " ")
 		if len(parts) < 4 {
@@ -165,13 +242,30 @@ func main() {
 		return excelFiles[i].Date.Before(excelFiles[j].Date)
 	})
 
-	logger.Info("Excel files discovered", slog.Int("count", len(excelFiles)))
+	logger.Info("Excel files discovered",
+		slog.Int("count", len(excelFiles)),
+		slog.Int("intraday_count", len(intradayFiles)),
+		slog.Int("snapshot_count", len(snapshotFiles)))
+
+	if *snapshots {
+		for _, name := range snapshotFiles {
+			if err := processSnapshotFile(filepath.Join(*inDir, name), paths, logger); err != nil {
+				logger.Warn("Error processing snapshot bulletin",
+					slog.String("filename", name),
+					slog.String("error", err.Error()))
+			}
+		}
+	}
 	
-	// Output progress message for stages.go to parse
+	// Human-readable progress; the operations stage reads the structured events
+	progress := events.NewProgressEmitter(os.Stdout, "processing")
 	fmt.Printf("Found %d Excel files\n", len(excelFiles))
 	
+	// History seeded by the importer is merged into every run
+	importedHistory := loadImportedHistory(paths, logger)
+
 	// Graceful exit if no Excel files found
-	if len(excelFiles) == 0 {
+	if len(excelFiles) == 0 && len(importedHistory) == 0 {
 		logger.Warn("No Excel files found in input directory",
 			slog.String("input_dir", *inDir),
 			slog.String("pattern", "*.xlsx"))
@@ -196,10 +290,14 @@ func main() {
 			logger.Error("Failed to create empty combined CSV", slog.String("error", err.Error()))
 			os.Exit(1)
 		}
+		if err := exporter.WriteSchemaManifest(combinedCSVPath, 0); err != nil {
+			logger.Warn("Failed to write combined CSV schema manifest", slog.String("error", err.Error()))
+		}
 		
 		logger.Info("Created empty output files", slog.String("combined_csv", combinedCSVPath))
 		fmt.Println("Processing complete: 0 files")
 		fmt.Println("All files processed")
+		progress.Complete(0, 0, "No files to process")
 		return
 	}
 	
@@ -210,6 +308,7 @@ func main() {
 			fileNames = append(fileNames, f.Name)
 		}
 		fmt.Printf("Files to process: %s\n", strings.Join(fileNames, "|"))
+		progress.Start(len(fileNames), fileNames)
 	}
 
 	// Check what needs to be processed
@@ -219,6 +318,17 @@ func main() {
 	if *fullRework {
 		slog.Info("Full rework requested - processing all files")
 		filesToProcess = excelFiles
+	} else if reprocess != nil {
+		filesToProcess, existingRecords, err = selectRangeFiles(excelFiles, *outDir, reprocess, logger)
+		if err != nil {
+			logger.Error("Cannot reprocess date range", slog.String("error", err.Error()))
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		logger.Info("Date range reprocessing",
+			slog.String("range", reprocess.String()),
+			slog.Int("files_to_process", len(filesToProcess)),
+			slog.Int("kept_records", len(existingRecords)))
 	} else {
 		// Smart update: check what's already processed
 		filesToProcess, existingRecords = determineFilesToProcess(excelFiles, *outDir, logger)
@@ -235,8 +345,8 @@ func main() {
 			slog.Int("total", totalFiles),
 			slog.String("filename", fileInfo.Name))
 		
-		// Output progress message for stages.go to parse
 		fmt.Printf("Processing file %d of %d: %s\n", i+1, totalFiles, fileInfo.Name)
+		progress.Progress(i+1, totalFiles, fileInfo.Name, events.ItemStatusProcessing)
 
 		report, err := dataprocessing.ParseFile(filepath.Join(*inDir, fileInfo.Name))
 		if err != nil {
@@ -251,6 +361,15 @@ func main() {
 			report.Records[i].Date = fileInfo.Date
 		}
 
+		// Attach intraday bars when an intraday bulletin exists for the same day
+		if intradayName, ok := intradayFiles[fileInfo.Date.Format("2006-01-02")]; ok {
+			if err := processIntradayFile(filepath.Join(*inDir, intradayName), paths, report, logger); err != nil {
+				logger.Warn("Error processing intraday bulletin",
+					slog.String("filename", intradayName),
+					slog.String("error", err.Error()))
+			}
+		}
+
 		logger.Info("Records processed from file",
 			slog.Int("record_count", len(report.Records)),
 			slog.String("filename", fileInfo.Name))
@@ -275,18 +394,26 @@ func main() {
 		}
 	}
 
+	// Hash the outputs first so the delta manifest lists only what this run changed
+	outputsBefore, err := files.SnapshotFiles(*outDir, files.DeltaTrackedOutputs...)
+	if err != nil {
+		logger.Warn("Failed to snapshot outputs, no delta manifest will be written", slog.String("error", err.Error()))
+	}
+
 	// Combine existing and new records
 	allRecords := append(existingRecords, newRecords...)
+	allRecords = mergeImportedHistory(allRecords, importedHistory, logger)
 
 	// Apply forward-fill and generate all output files
 	if len(allRecords) > 0 {
-		slog.Info("Generating dataset with forward-fill...")
-		filledRecords := forwardFillMissingData(allRecords)
+		slog.Info("Generating dataset with forward-fill...", "strategy", fillStrategy)
+		filledRecords, fillStats := dataprocessing.NewForwardFillProcessorWithStrategy(fillStrategy).FillMissingDataWithStats(allRecords)
 
 		logger.Info("Record processing summary",
-			slog.Int("total_records", len(filledRecords)),
-			slog.Int("active_trading_records", len(allRecords)),
-			slog.Int("forward_filled_records", len(filledRecords)-len(allRecords)))
+			slog.String("fill_strategy", string(fillStrategy)),
+			slog.Int("total_records", fillStats.TotalRecords),
+			slog.Int("active_trading_records", fillStats.ActiveRecords),
+			slog.Int("forward_filled_records", fillStats.ForwardFilledCount))
 
 		// Save combined CSV with forward-fill in proper subdirectory
 		combinedDir := filepath.Join(*outDir, "combined")
@@ -300,6 +427,10 @@ func main() {
 			slog.Error("Error saving combined CSV", "error", err)
 		} else {
 			logger.Info("Saved combined report", slog.String("path", combinedCSVPath))
+			if err := exporter.WriteSchemaManifest(combinedCSVPath, len(filledRecords)); err != nil {
+				logger.Warn("Failed to write combined CSV schema manifest", slog.String("error", err.Error()))
+			}
+			writeDataProfile(combinedCSVPath, *inDir, *outDir, filesToProcess, *fullRework, paths, logger)
 		}
 
 		// Generate daily CSV files with forward-fill in proper subdirectory
@@ -316,6 +447,9 @@ func main() {
 			logger.Info("Daily files generated successfully")
 			slog.Info("Daily files generated successfully")
 		}
+		if reprocess != nil {
+			removeStaleDailyFiles(dailyDir, reprocess, filledRecords, logger)
+		}
 
 		// Generate individual ticker CSV files with forward-fill in proper subdirectory
 		slog.Info("Generating individual ticker CSV files with forward-fill...")
@@ -331,17 +465,32 @@ func main() {
 			logger.Info("Ticker files generated successfully")
 			slog.Info("Ticker files generated successfully")
 		}
+
+		// Generate weekly and monthly OHLCV bars from the actual trading days
+		slog.Info("Generating weekly and monthly aggregates...")
+		aggregatesDir := filepath.Join(*outDir, "aggregates")
+		if written, err := analytics.WriteAggregates(aggregatesDir, filledRecords, exportOptions); err != nil {
+			logger.Error("Error generating aggregates", slog.String("error", err.Error()))
+			slog.Error("Error generating aggregates", "error", err)
+		} else {
+			logger.Info("Aggregates generated successfully", slog.Int("files", written))
+			slog.Info("Aggregates generated successfully", "files", written)
+		}
+
+		if cfg.Processing.Anomalies.Enabled {
+			writeAnomalies(*outDir, filledRecords, filesToProcess, *fullRework, cfg.Processing.Anomalies, logger)
+		}
 	}
 
 	logger.Info("Processing complete")
 	
-	// Output completion message for stages.go to parse
 	fmt.Printf("Processing complete: %d files\n", len(filesToProcess))
+	progress.Complete(len(filesToProcess), totalFiles, "Processing complete")
 
 	// Generate ticker summary using SSOT Summarizer
 	logger.Info("Generating ticker summary using SSOT implementation")
 	ctx := context.Background()
-	integrator := dataprocessing.NewIntegrationExample(logger)
+	integrator := dataprocessing.NewIntegrationExampleWithOptions(logger, exportOptions)
 	combinedCSVPath := filepath.Join(*outDir, "combined", "isx_combined_data.csv")
 	
 	if err := integrator.GenerateTickerSummaryFromCombinedCSV(ctx, combinedCSVPath, *outDir); err != nil {
@@ -351,10 +500,150 @@ func main() {
 		logger.Info("Ticker summary generated successfully using SSOT")
 	}
 	
+	if outputsBefore != nil {
+		writeDataDelta(*outDir, outputsBefore, filesToProcess, *fullRework, logger)
+	}
+	
 	// Output completion message for stages.go to parse
 	fmt.Println("All files processed")
 }
 
+// loadImportedHistory reads the rows seeded by the importer command, if any
+func loadImportedHistory(paths *config.Paths, logger *slog.Logger) []domain.TradeRecord {
+	records, err := dataprocessing.ReadTradeRecordsCSV(paths.ImportedDataCSV)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Could not load imported history", slog.String("error", err.Error()))
+		}
+		return nil
+	}
+	logger.Info("Loaded imported history", slog.Int("count", len(records)))
+	return records
+}
+
+// mergeImportedHistory adds imported rows for days no bulletin covers, so a
+// full rework keeps the history seeded by the importer. Bulletin rows win.
+func mergeImportedHistory(records, imported []domain.TradeRecord, logger *slog.Logger) []domain.TradeRecord {
+	if len(imported) == 0 {
+		return records
+	}
+	merged, stats, err := dataprocessing.MergeImported(records, imported, dataprocessing.ConflictKeepExisting)
+	if err != nil {
+		logger.Warn("Could not merge imported history", slog.String("error", err.Error()))
+		return records
+	}
+	logger.Info("Merged imported history",
+		slog.Int("added", stats.Added+stats.FillsReplaced),
+		slog.Int("superseded_by_bulletins", stats.Kept))
+	return merged
+}
+
+// writeDataDelta records the files, dates and tickers changed by this run in
+// reports/deltas so clients can refresh incrementally. Runs that changed
+// nothing write no manifest.
+func writeDataDelta(outDir string, before files.FileSnapshot, processed []ExcelFileInfo, fullRework bool, logger *slog.Logger) {
+	after, err := files.SnapshotFiles(outDir, files.DeltaTrackedOutputs...)
+	if err != nil {
+		logger.Warn("Failed to snapshot outputs", slog.String("error", err.Error()))
+		return
+	}
+
+	dates := make([]time.Time, 0, len(processed))
+	for _, f := range processed {
+		dates = append(dates, f.Date)
+	}
+
+	delta := files.NewDataDelta(files.ChangedFiles(before, after), dates, fullRework, time.Now())
+	if delta.Empty() {
+		logger.Info("No outputs changed, skipping delta manifest")
+		return
+	}
+
+	deltaPath, err := files.WriteDataDelta(filepath.Join(outDir, files.DeltaDirName), delta)
+	if err != nil {
+		logger.Warn("Failed to write delta manifest", slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info("Delta manifest written",
+		slog.String("path", deltaPath),
+		slog.Int("files", len(delta.Files)),
+		slog.Int("dates", len(delta.Dates)),
+		slog.Int("tickers", len(delta.Tickers)))
+}
+
+// writeAnomalies flags unusual trading days in reports/anomalies and
+// summarizes those on the dates this run processed, or on the latest of them
+// after a full rework, for the processing stage to announce
+func writeAnomalies(outDir string, records []domain.TradeRecord, processed []ExcelFileInfo, fullRework bool, cfg config.AnomalyConfig, logger *slog.Logger) {
+	anomalies := analytics.DetectAnomalies(records, analytics.AnomalyOptions{
+		Lookback:       cfg.Lookback,
+		ZScore:         cfg.ZScore,
+		VolumeMultiple: cfg.VolumeMultiple,
+	})
+
+	dates := make([]time.Time, 0, len(processed))
+	for _, f := range processed {
+		dates = append(dates, f.Date)
+	}
+	if fullRework && len(dates) > 1 {
+		latest := dates[0]
+		for _, d := range dates[1:] {
+			if d.After(latest) {
+				latest = d
+			}
+		}
+		dates = []time.Time{latest}
+	}
+
+	summary := analytics.SummarizeAnomalies(anomalies, dates, time.Now())
+	if err := analytics.WriteAnomalies(filepath.Join(outDir, analytics.AnomaliesDirName), anomalies, summary); err != nil {
+		logger.Warn("Failed to write anomalies report", slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info("Anomalies report written",
+		slog.Int("anomalies", len(anomalies)),
+		slog.Int("this_run", summary.Total),
+		slog.Int("tickers", len(summary.Tickers)))
+}
+
+// writeDataProfile profiles the combined CSV and stores the artifact with lineage
+// in reports/profiling. Profiling failures never fail the processing run.
+func writeDataProfile(combinedCSVPath, inDir, outDir string, processed []ExcelFileInfo, fullRework bool, paths *config.Paths, logger *slog.Logger) {
+	profile, err := dataprocessing.ProfileCSV(combinedCSVPath)
+	if err != nil {
+		logger.Warn("Failed to profile combined CSV", slog.String("error", err.Error()))
+		return
+	}
+
+	sources := make([]string, 0, len(processed))
+	for _, f := range processed {
+		sources = append(sources, filepath.Join(inDir, f.Name))
+	}
+
+	dataset, err := filepath.Rel(outDir, combinedCSVPath)
+	if err != nil {
+		dataset = combinedCSVPath
+	}
+	profile.Lineage.Dataset = filepath.ToSlash(dataset)
+	profile.Lineage.DataProfile = paths.Profile
+	profile.Lineage.FullRework = fullRework
+	profile.Lineage.SourceFiles = dataprocessing.SourceFilesFromPaths(sources)
+
+	profilingDir := filepath.Join(outDir, "profiling")
+	profilePath, err := dataprocessing.WriteDataProfile(profilingDir, profile, time.Now())
+	if err != nil {
+		logger.Warn("Failed to write data profile", slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info("Data profile written",
+		slog.String("path", profilePath),
+		slog.Int("rows", profile.RowCount),
+		slog.Int("tickers", len(profile.Tickers)))
+}
+
 // determineFilesToProcess checks which files need to be processed based on existing CSV files
 func determineFilesToProcess(excelFiles []ExcelFileInfo, outDir string, logger *slog.Logger) ([]ExcelFileInfo, []domain.TradeRecord) {
 	var filesToProcess []ExcelFileInfo
@@ -435,63 +724,201 @@ func determineFilesToProcess(excelFiles []ExcelFileInfo, outDir string, logger *
 	return filesToProcess, existingRecords
 }
 
-// loadExistingRecords loads records from an existing combined CSV file
+// reprocessRange is the inclusive date range of a --from/--to run; a zero
+// bound is open
+type reprocessRange struct {
+	from, to time.Time
+}
+
+// parseReprocessRange parses the --from and --to flags. It returns nil when
+// neither is set.
+func parseReprocessRange(from, to string) (*reprocessRange, error) {
+	if from == "" && to == "" {
+		return nil, nil
+	}
+	var r reprocessRange
+	var err error
+	if from != "" {
+		if r.from, err = time.Parse("2006-01-02", from); err != nil {
+			return nil, fmt.Errorf("invalid --from date %q: want YYYY-MM-DD", from)
+		}
+	}
+	if to != "" {
+		if r.to, err = time.Parse("2006-01-02", to); err != nil {
+			return nil, fmt.Errorf("invalid --to date %q: want YYYY-MM-DD", to)
+		}
+	}
+	if !r.from.IsZero() && !r.to.IsZero() && r.to.Before(r.from) {
+		return nil, fmt.Errorf("--to %s is before --from %s", to, from)
+	}
+	return &r, nil
+}
+
+// contains reports whether date falls in the range
+func (r *reprocessRange) contains(date time.Time) bool {
+	return (r.from.IsZero() || !date.Before(r.from)) && (r.to.IsZero() || !date.After(r.to))
+}
+
+func (r *reprocessRange) String() string {
+	bound := func(t time.Time) string {
+		if t.IsZero() {
+			return "..."
+		}
+		return t.Format("2006-01-02")
+	}
+	return bound(r.from) + " to " + bound(r.to)
+}
+
+// selectRangeFiles returns the reports inside the range and the records of
+// the existing combined CSV outside it, so the reprocessed dates are spliced
+// into the outputs in place of the old ones. Dates in the range without a
+// report are dropped. Filled records after the range are refilled from the
+// new data by the forward fill.
+func selectRangeFiles(excelFiles []ExcelFileInfo, outDir string, r *reprocessRange, logger *slog.Logger) ([]ExcelFileInfo, []domain.TradeRecord, error) {
+	combinedCSVPath := filepath.Join(outDir, "combined", "isx_combined_data.csv")
+	records, err := loadExistingRecords(combinedCSVPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("no combined CSV to reprocess into, run without --from/--to first")
+		}
+		return nil, nil, fmt.Errorf("failed to load existing combined CSV: %w", err)
+	}
+
+	var filesToProcess []ExcelFileInfo
+	for _, fileInfo := range excelFiles {
+		if r.contains(fileInfo.Date) {
+			filesToProcess = append(filesToProcess, fileInfo)
+		}
+	}
+
+	var kept []domain.TradeRecord
+	for _, record := range records {
+		if !r.contains(record.Date) {
+			kept = append(kept, record)
+		}
+	}
+
+	logger.Info("Selected date range for reprocessing",
+		slog.String("range", r.String()),
+		slog.Int("files", len(filesToProcess)),
+		slog.Int("removed_records", len(records)-len(kept)))
+	return filesToProcess, kept, nil
+}
+
+// removeStaleDailyFiles deletes the daily CSVs in the range of dates that no
+// longer have records, such as a day whose report was removed
+func removeStaleDailyFiles(dailyDir string, r *reprocessRange, records []domain.TradeRecord, logger *slog.Logger) {
+	current := make(map[string]bool)
+	for _, record := range records {
+		current[record.Date.Format("2006_01_02")] = true
+	}
+
+	entries, err := os.ReadDir(dailyDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "isx_daily_") || !strings.HasSuffix(name, ".csv") {
+			continue
+		}
+		dateStr := strings.TrimSuffix(strings.TrimPrefix(name, "isx_daily_"), ".csv")
+		date, err := time.Parse("2006_01_02", dateStr)
+		if err != nil || !r.contains(date) || current[dateStr] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dailyDir, name)); err != nil {
+			logger.Warn("Failed to remove stale daily CSV", slog.String("file", name), slog.String("error", err.Error()))
+			continue
+		}
+		logger.Info("Removed stale daily CSV", slog.String("file", name))
+	}
+}
+
+// loadExistingRecords loads records from an existing combined CSV file. Files
+// written with an earlier schema version are upgraded row by row as they are
+// read; a file written by a newer release is an error. Other layouts, such as
+// external CSVs, are read by column name through columnMapping.
 func loadExistingRecords(filePath string) ([]domain.TradeRecord, error) {
-	file, err := os.Open(filePath)
+	if err := exporter.CheckSchemaManifest(filePath); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
 	}
+	if len(records) == 0 {
+		return nil, nil
+	}
 
-	var tradeRecords []domain.TradeRecord
-	for i, record := range records {
-		if i == 0 { // Skip header
-			continue
-		}
+	// A known layout is upgraded to the current one; anything else is mapped
+	// as it is and must at least name the day, ticker and close
+	upgrade := func(row []string) ([]string, error) { return row, nil }
+	cols := columnMapping.Resolve(records[0])
+	if combined, err := exporter.DetectCombinedSchema(records[0]); err == nil {
+		upgrade = combined.Upgrade
+		cols = schema.DefaultMapping().Resolve(exporter.CurrentCombinedSchema().Columns)
+	} else if missing := cols.Missing(schema.ColumnDate, schema.ColumnSymbol, schema.ColumnClosePrice); len(missing) > 0 {
+		return nil, fmt.Errorf("%w; missing columns %s (map them under processing.column_map)", err, strings.Join(missing, ", "))
+	}
 
-		if len(record) < 16 {
+	var tradeRecords []domain.TradeRecord
+	for _, row := range records[1:] {
+		record, err := upgrade(row)
+		if err != nil {
 			continue // Skip malformed records
 		}
+		value := func(column string) string { return cols.Value(record, column) }
+		float := func(column string) float64 {
+			v, _ := strconv.ParseFloat(value(column), 64)
+			return v
+		}
+		integer := func(column string) int64 {
+			v, _ := strconv.ParseInt(value(column), 10, 64)
+			return v
+		}
 
-		// Parse the record
-		date, _ := time.Parse("2006-01-02", record[0])
-		openPrice, _ := strconv.ParseFloat(record[3], 64)
-		highPrice, _ := strconv.ParseFloat(record[4], 64)
-		lowPrice, _ := strconv.ParseFloat(record[5], 64)
-		avgPrice, _ := strconv.ParseFloat(record[6], 64)
-		prevAvgPrice, _ := strconv.ParseFloat(record[7], 64)
-		closePrice, _ := strconv.ParseFloat(record[8], 64)
-		prevClosePrice, _ := strconv.ParseFloat(record[9], 64)
-		change, _ := strconv.ParseFloat(record[10], 64)
-		changePct, _ := strconv.ParseFloat(record[11], 64)
-		numTrades, _ := strconv.ParseInt(record[12], 10, 64)
-		volume, _ := strconv.ParseInt(record[13], 10, 64)
-		value, _ := strconv.ParseFloat(record[14], 64)
-		tradingStatus, _ := strconv.ParseBool(record[15])
+		date, err := time.Parse("2006-01-02", value(schema.ColumnDate))
+		if err != nil || value(schema.ColumnSymbol) == "" {
+			continue
+		}
 
 		tradeRecord := domain.TradeRecord{
-			CompanyName:      record[1],
-			CompanySymbol:    record[2],
+			CompanyName:      value(schema.ColumnCompanyName),
+			CompanySymbol:    value(schema.ColumnSymbol),
 			Date:             date,
-			OpenPrice:        openPrice,
-			HighPrice:        highPrice,
-			LowPrice:         lowPrice,
-			AveragePrice:     avgPrice,
-			PrevAveragePrice: prevAvgPrice,
-			ClosePrice:       closePrice,
-			PrevClosePrice:   prevClosePrice,
-			Change:           change,
-			ChangePercent:    changePct,
-			NumTrades:        numTrades,
-			Volume:           volume,
-			Value:            value,
-			TradingStatus:    tradingStatus,
+			OpenPrice:        float(schema.ColumnOpenPrice),
+			HighPrice:        float(schema.ColumnHighPrice),
+			LowPrice:         float(schema.ColumnLowPrice),
+			AveragePrice:     float(schema.ColumnAveragePrice),
+			PrevAveragePrice: float(schema.ColumnPrevAveragePrice),
+			ClosePrice:       float(schema.ColumnClosePrice),
+			PrevClosePrice:   float(schema.ColumnPrevClosePrice),
+			Change:           float(schema.ColumnChange),
+			ChangePercent:    float(schema.ColumnChangePercent),
+			NumTrades:        integer(schema.ColumnNumTrades),
+			Volume:           integer(schema.ColumnVolume),
+			Value:            float(schema.ColumnValue),
+			FillMethod:       value(schema.ColumnFillMethod),
+		}
+		if cols.Has(schema.ColumnTradingStatus) {
+			tradeRecord.TradingStatus, _ = strconv.ParseBool(value(schema.ColumnTradingStatus))
+		} else {
+			// External files rarely say; a day with trades or volume traded
+			tradeRecord.TradingStatus = tradeRecord.NumTrades > 0 || tradeRecord.Volume > 0
+		}
+		if tradeRecord.FillMethod == "" {
+			tradeRecord.FillMethod = domain.FillMethodCarryForward
+			if tradeRecord.TradingStatus {
+				tradeRecord.FillMethod = domain.FillMethodActual
+			}
 		}
 		tradeRecords = append(tradeRecords, tradeRecord)
 	}
@@ -506,39 +933,23 @@ func saveDailyCSV(filePath string, records []domain.TradeRecord) error {
 	}
 	defer file.Close()
 
+	if exportOptions.BOM {
+		if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return err
+		}
+	}
+
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
 	// Write header with all fields
-	header := []string{
-		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
-		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
-	}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(exporter.TradeRecordHeaders); err != nil {
 		return err
 	}
 
-	// Write records
-	for _, record := range records {
-		row := []string{
-			record.Date.Format("2006-01-02"),
-			record.CompanyName,
-			record.CompanySymbol,
-			fmt.Sprintf("%.3f", record.OpenPrice),
-			fmt.Sprintf("%.3f", record.HighPrice),
-			fmt.Sprintf("%.3f", record.LowPrice),
-			fmt.Sprintf("%.3f", record.AveragePrice),
-			fmt.Sprintf("%.3f", record.PrevAveragePrice),
-			fmt.Sprintf("%.3f", record.ClosePrice),
-			fmt.Sprintf("%.3f", record.PrevClosePrice),
-			fmt.Sprintf("%.3f", record.Change),
-			fmt.Sprintf("%.2f", record.ChangePercent),
-			fmt.Sprintf("%d", record.NumTrades),
-			fmt.Sprintf("%d", record.Volume),
-			fmt.Sprintf("%.2f", record.Value),
-			fmt.Sprintf("%t", record.TradingStatus),
-		}
+	// Write records in date, then symbol order
+	for _, record := range exporter.SortedTradeRecords(records) {
+		row := exportOptions.FormatTradeRecord(record)
 		if err := writer.Write(row); err != nil {
 			return err
 		}
@@ -547,84 +958,42 @@ func saveDailyCSV(filePath string, records []domain.TradeRecord) error {
 	return nil
 }
 
-// forwardFillMissingData fills in missing trading data for symbols that don't trade on certain days
-func forwardFillMissingData(records []domain.TradeRecord) []domain.TradeRecord {
-	if len(records) == 0 {
-		return records
+// saveDailyReportCSV writes a day's records with their traded-value bands
+func saveDailyReportCSV(filePath string, records []domain.TradeRecord, bands exporter.ValueBands) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	// Group records by symbol and date
-	symbolsByDate := make(map[string]map[string]domain.TradeRecord) // date -> symbol -> record
-	allSymbols := make(map[string]bool)
-	allDates := make(map[string]bool)
+	if exportOptions.BOM {
+		if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return err
+		}
+	}
 
-	for _, record := range records {
-		dateStr := record.Date.Format("2006-01-02")
-		symbol := record.CompanySymbol
-
-		if symbolsByDate[dateStr] == nil {
-			symbolsByDate[dateStr] = make(map[string]domain.TradeRecord)
-		}
-		symbolsByDate[dateStr][symbol] = record
-		allSymbols[symbol] = true
-		allDates[dateStr] = true
-	}
-
-	// Convert to sorted slices
-	var dates []string
-	for date := range allDates {
-		dates = append(dates, date)
-	}
-	sort.Strings(dates)
-
-	var symbols []string
-	for symbol := range allSymbols {
-		symbols = append(symbols, symbol)
-	}
-	sort.Strings(symbols)
-
-	// Keep track of last known data for each symbol
-	lastKnownData := make(map[string]domain.TradeRecord)
-
-	var result []domain.TradeRecord
-
-	for _, dateStr := range dates {
-		date, _ := time.Parse("2006-01-02", dateStr)
-		dayRecords := symbolsByDate[dateStr]
-
-		for _, symbol := range symbols {
-			if record, exists := dayRecords[symbol]; exists {
-				// Symbol traded on this day - use actual data
-				result = append(result, record)
-				lastKnownData[symbol] = record
-			} else if lastRecord, hasHistory := lastKnownData[symbol]; hasHistory {
-				// Symbol didn't trade - forward fill from last known data
-				filledRecord := domain.TradeRecord{
-					CompanyName:      lastRecord.CompanyName,
-					CompanySymbol:    symbol,
-					Date:             date,
-					OpenPrice:        lastRecord.ClosePrice,   // Open = previous close
-					HighPrice:        lastRecord.ClosePrice,   // High = previous close
-					LowPrice:         lastRecord.ClosePrice,   // Low = previous close
-					AveragePrice:     lastRecord.ClosePrice,   // Average = previous close
-					PrevAveragePrice: lastRecord.AveragePrice, // Keep previous average
-					ClosePrice:       lastRecord.ClosePrice,   // Close = previous close
-					PrevClosePrice:   lastRecord.ClosePrice,   // Prev close = previous close
-					Change:           0.0,                     // No change
-					ChangePercent:    0.0,                     // No change %
-					NumTrades:        0,                       // No trades
-					Volume:           0,                       // No volume
-					Value:            0.0,                     // No value
-					TradingStatus:    false,                   // Forward-filled data
-				}
-				result = append(result, filledRecord)
-				// Don't update lastKnownData since this is filled data
-			}
-			// If no history exists, skip this symbol for this date
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(exporter.DailyReportHeaders()); err != nil {
+		return err
+	}
+
+	for _, record := range exporter.SortedTradeRecords(records) {
+		band, ok := bands.Get(record.CompanySymbol, record.Date)
+		row := append(exportOptions.FormatTradeRecord(record), exportOptions.FormatValueBand(band, ok)...)
+		if err := writer.Write(row); err != nil {
+			return err
 		}
 	}
 
-	return result
+	return nil
+}
+
+// forwardFillMissingData fills in missing trading data for symbols that don't trade on certain days,
+// carrying the last close forward
+func forwardFillMissingData(records []domain.TradeRecord) []domain.TradeRecord {
+	return dataprocessing.NewForwardFillProcessor().FillMissingData(records)
 }
 
 func saveCombinedCSV(filePath string, records []domain.TradeRecord) error {
@@ -634,39 +1003,23 @@ func saveCombinedCSV(filePath string, records []domain.TradeRecord) error {
 	}
 	defer file.Close()
 
+	if exportOptions.BOM {
+		if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return err
+		}
+	}
+
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
 	// Write header with all fields
-	header := []string{
-		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
-		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
-	}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(exporter.TradeRecordHeaders); err != nil {
 		return err
 	}
 
-	// Write records
-	for _, record := range records {
-		row := []string{
-			record.Date.Format("2006-01-02"),
-			record.CompanyName,
-			record.CompanySymbol,
-			fmt.Sprintf("%.3f", record.OpenPrice),
-			fmt.Sprintf("%.3f", record.HighPrice),
-			fmt.Sprintf("%.3f", record.LowPrice),
-			fmt.Sprintf("%.3f", record.AveragePrice),
-			fmt.Sprintf("%.3f", record.PrevAveragePrice),
-			fmt.Sprintf("%.3f", record.ClosePrice),
-			fmt.Sprintf("%.3f", record.PrevClosePrice),
-			fmt.Sprintf("%.3f", record.Change),
-			fmt.Sprintf("%.2f", record.ChangePercent),
-			fmt.Sprintf("%d", record.NumTrades),
-			fmt.Sprintf("%d", record.Volume),
-			fmt.Sprintf("%.2f", record.Value),
-			fmt.Sprintf("%t", record.TradingStatus),
-		}
+	// Write records in date, then symbol order
+	for _, record := range exporter.SortedTradeRecords(records) {
+		row := exportOptions.FormatTradeRecord(record)
 		if err := writer.Write(row); err != nil {
 			return err
 		}
@@ -689,13 +1042,17 @@ func generateDailyFiles(records []domain.TradeRecord, outDir string) error {
 		return err
 	}
 
-	// Generate CSV files for each date
-	for dateStr, dailyRecords := range recordsByDate {
+	// Rank each day's traded value against the ticker's own recent history
+	bands := dataprocessing.TradedValueBands(records, dataprocessing.ValueBandWindow, dataprocessing.ValueBandMinDays)
+
+	// Generate CSV files for each date, oldest first
+	for _, dateStr := range exporter.SortedKeys(recordsByDate) {
+		dailyRecords := recordsByDate[dateStr]
 		slog.Debug("Generating daily CSV for date", slog.String("date", dateStr))
 
 		// Save CSV directly to reports directory (no subdirectories)
 		dailyCSVPath := filepath.Join(outDir, fmt.Sprintf("isx_daily_%s.csv", dateStr))
-		if err := saveDailyCSV(dailyCSVPath, dailyRecords); err != nil {
+		if err := saveDailyReportCSV(dailyCSVPath, dailyRecords, bands); err != nil {
 			slog.Error("Error saving daily CSV",
 				slog.String("path", dailyCSVPath),
 				slog.String("error", err.Error()))
@@ -722,8 +1079,8 @@ func generateTickerFiles(records []domain.TradeRecord, outDir string) error {
 		return err
 	}
 
-	// Generate CSV files for each ticker
-	for ticker := range tickers {
+	// Generate CSV files for each ticker in symbol order
+	for _, ticker := range exporter.SortedKeys(tickers) {
 		slog.Debug("Generating CSV for ticker", slog.String("ticker", ticker))
 
 		// Filter records for the current ticker
@@ -751,5 +1108,60 @@ func generateTickerFiles(records []domain.TradeRecord, outDir string) error {
 	return nil
 }
 
+// processSnapshotFile stores a mid-session bulletin under
+// data/snapshots/{date}/{HHMM}.csv, separate from the end-of-day records.
+// Snapshots already stored are skipped since published bulletins do not change.
+func processSnapshotFile(filePath string, paths *config.Paths, logger *slog.Logger) error {
+	asOf, err := dataprocessing.SnapshotFileTime(filePath)
+	if err != nil {
+		return err
+	}
+
+	snapshotPath := paths.GetSnapshotPath(asOf)
+	if _, err := os.Stat(snapshotPath); err == nil {
+		return nil
+	}
+
+	snapshot, err := dataprocessing.ParseSnapshotFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := dataprocessing.WriteSnapshotCSV(snapshotPath, snapshot); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+
+	logger.Info("Intraday snapshot stored",
+		slog.String("as_of", asOf.Format("2006-01-02 15:04")),
+		slog.Int("records", len(snapshot.Records)),
+		slog.String("path", snapshotPath))
+	return nil
+}
+
+// processIntradayFile parses an intraday bulletin, attaches the bars to the daily
+// report and stores them under data/intraday/{TICKER}/{date}.csv
+func processIntradayFile(filePath string, paths *config.Paths, report *domain.DailyReport, logger *slog.Logger) error {
+	date, err := dataprocessing.IntradayFileDate(filePath)
+	if err != nil {
+		return err
+	}
 
+	bars, err := dataprocessing.ParseIntradayFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	dataprocessing.AttachIntraday(report, bars)
 
+	for symbol, symbolBars := range dataprocessing.GroupIntradayBySymbol(bars) {
+		if err := dataprocessing.WriteIntradayCSV(paths.GetIntradayPath(symbol, date), symbolBars); err != nil {
+			return fmt.Errorf("save intraday bars for %s: %w", symbol, err)
+		}
+	}
+
+	logger.Info("Intraday bars stored",
+		slog.String("date", date.Format("2006-01-02")),
+		slog.Int("bars", len(bars)),
+		slog.String("intraday_dir", paths.IntradayDir))
+	return nil
+}
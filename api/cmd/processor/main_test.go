@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"isxcli/internal/exporter"
 	"isxcli/pkg/contracts/domain"
+	"isxcli/pkg/contracts/schema"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -183,6 +185,112 @@ func TestLoadExistingRecords(t *testing.T) {
 	}
 }
 
+func TestLoadExistingRecordsSchemaVersions(t *testing.T) {
+	dir := t.TempDir()
+	v1 := filepath.Join(dir, "v1.csv")
+	require.NoError(t, os.WriteFile(v1, []byte("\ufeffDate,CompanyName,Symbol,OpenPrice,HighPrice,LowPrice,AveragePrice,PrevAveragePrice,ClosePrice,PrevClosePrice,Change,ChangePercent,NumTrades,Volume,Value,TradingStatus\n"+
+		"2025-01-10,Test Company,TEST,100,105,95,102,101,103,101,2,1.98,10,1000,102000,true\n"+
+		"2025-01-11,Test Company,TEST,103,103,103,103,102,103,103,0,0,0,0,0,false\n"), 0644))
+
+	records, err := loadExistingRecords(v1)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, domain.FillMethodActual, records[0].FillMethod, "v1 rows are upgraded as they are read")
+	assert.Equal(t, domain.FillMethodCarryForward, records[1].FillMethod)
+
+	// A combined CSV written by a newer release is not read
+	require.NoError(t, os.WriteFile(exporter.SchemaManifestPath(v1), []byte(`{"schema_version": 99}`), 0644))
+	_, err = loadExistingRecords(v1)
+	assert.ErrorIs(t, err, exporter.ErrNewerSchema)
+}
+
+func TestParseReprocessRange(t *testing.T) {
+	r, err := parseReprocessRange("", "")
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	r, err = parseReprocessRange("2025-01-11", "")
+	require.NoError(t, err)
+	assert.False(t, r.contains(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, r.contains(time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, r.contains(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)), "open upper bound")
+	assert.Equal(t, "2025-01-11 to ...", r.String())
+
+	_, err = parseReprocessRange("2025-01-12", "2025-01-11")
+	assert.Error(t, err)
+	_, err = parseReprocessRange("11/01/2025", "")
+	assert.Error(t, err)
+}
+
+func TestSelectRangeFiles(t *testing.T) {
+	outDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	excelFiles := []ExcelFileInfo{
+		{Name: "2025 01 10 ISX Daily Report.xlsx", Date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{Name: "2025 01 11 ISX Daily Report.xlsx", Date: time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{Name: "2025 01 13 ISX Daily Report.xlsx", Date: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+	}
+	r, err := parseReprocessRange("2025-01-11", "2025-01-12")
+	require.NoError(t, err)
+
+	_, _, err = selectRangeFiles(excelFiles, outDir, r, logger)
+	assert.ErrorContains(t, err, "no combined CSV")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(outDir, "combined"), 0755))
+	createTestCombinedCSV(t, filepath.Join(outDir, "combined", "isx_combined_data.csv"), 4) // 2025-01-10 to 2025-01-13
+
+	filesToProcess, kept, err := selectRangeFiles(excelFiles, outDir, r, logger)
+	require.NoError(t, err)
+	require.Len(t, filesToProcess, 1)
+	assert.Equal(t, excelFiles[1].Name, filesToProcess[0].Name)
+	require.Len(t, kept, 2, "records inside the range are replaced")
+	assert.Equal(t, "2025-01-10", kept[0].Date.Format("2006-01-02"))
+	assert.Equal(t, "2025-01-13", kept[1].Date.Format("2006-01-02"))
+}
+
+func TestRemoveStaleDailyFiles(t *testing.T) {
+	dailyDir := t.TempDir()
+	for _, name := range []string{"isx_daily_2025_01_10.csv", "isx_daily_2025_01_11.csv", "isx_daily_2025_01_12.csv", "notes.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dailyDir, name), nil, 0644))
+	}
+	r, err := parseReprocessRange("2025-01-11", "2025-01-12")
+	require.NoError(t, err)
+
+	records := []domain.TradeRecord{{CompanySymbol: "TEST", Date: time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC)}}
+	removeStaleDailyFiles(dailyDir, r, records, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	assert.FileExists(t, filepath.Join(dailyDir, "isx_daily_2025_01_10.csv"), "outside the range")
+	assert.FileExists(t, filepath.Join(dailyDir, "isx_daily_2025_01_11.csv"))
+	assert.NoFileExists(t, filepath.Join(dailyDir, "isx_daily_2025_01_12.csv"))
+	assert.FileExists(t, filepath.Join(dailyDir, "notes.csv"))
+}
+
+func TestLoadExistingRecordsColumnMapping(t *testing.T) {
+	defer func(m schema.Mapping) { columnMapping = m }(columnMapping)
+
+	path := filepath.Join(t.TempDir(), "external.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Ticker,Trade Date,Last Trade,Volume\n"+
+		"TEST,2025-01-10,103,1000\n"+
+		"TEST,2025-01-11,103,0\n"), 0644))
+
+	_, err := loadExistingRecords(path)
+	assert.ErrorIs(t, err, exporter.ErrUnknownSchema)
+	assert.ErrorContains(t, err, "ClosePrice")
+
+	columnMapping, err = schema.NewMapping(map[string]string{schema.ColumnClosePrice: "Last Trade"})
+	require.NoError(t, err)
+	records, err := loadExistingRecords(path)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "TEST", records[0].CompanySymbol)
+	assert.Equal(t, 103.0, records[0].ClosePrice)
+	assert.Equal(t, int64(1000), records[0].Volume)
+	assert.True(t, records[0].TradingStatus, "derived from volume")
+	assert.Equal(t, domain.FillMethodActual, records[0].FillMethod)
+	assert.False(t, records[1].TradingStatus)
+	assert.Equal(t, domain.FillMethodCarryForward, records[1].FillMethod)
+}
+
 func TestForwardFillMissingData(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -377,7 +485,7 @@ func TestSaveCombinedCSV(t *testing.T) {
 				expectedHeaders := []string{
 					"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
 					"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-					"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
+					"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus", "FillMethod",
 				}
 				assert.Equal(t, expectedHeaders, header)
 			}
@@ -649,4 +757,26 @@ func TestConcurrentFileOperations(t *testing.T) {
 	files, err := os.ReadDir(tmpDir)
 	assert.NoError(t, err)
 	assert.Equal(t, numGoroutines, len(files))
-}
\ No newline at end of file
+}
+func TestCombinedCSVRoundTrip(t *testing.T) {
+	// The combined CSV is read back on the next run, so it must keep the
+	// canonical format whatever the export settings are
+	path := filepath.Join(t.TempDir(), "isx_combined_data.csv")
+	records := []domain.TradeRecord{{
+		CompanyName:   "Bank of Baghdad",
+		CompanySymbol: "BBOB",
+		Date:          time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		ClosePrice:    1.25,
+		Volume:        2500000,
+		Value:         3125000.5,
+		TradingStatus: true,
+	}}
+	require.NoError(t, saveCombinedCSV(path, records))
+
+	loaded, err := loadExistingRecords(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.True(t, records[0].Date.Equal(loaded[0].Date))
+	assert.Equal(t, records[0].Volume, loaded[0].Volume)
+	assert.InDelta(t, records[0].Value, loaded[0].Value, 0.001)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+	"isxcli/internal/infrastructure"
+)
+
+func main() {
+	archiveMonths := flag.Int("archive-xlsx-months", 0, "zip daily xlsx reports once their month is older than N months (0 = keep)")
+	deleteDays := flag.Int("delete-daily-csv-days", 0, "delete isx_daily_*.csv files older than N days (0 = keep)")
+	dryRun := flag.Bool("dry-run", false, "report what would be archived or deleted without changing anything")
+	jsonOut := flag.Bool("json", false, "print the retention result as JSON")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	flag.Parse()
+
+	if err := config.SetActiveProfile(*profile); err != nil {
+		slog.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	paths, err := config.GetPaths()
+	if err != nil {
+		slog.Error("Failed to initialize paths", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Warn("Failed to load config, using defaults", "error", err)
+		cfg = &config.Config{
+			Logging: config.LoggingConfig{
+				Level:       "info",
+				Format:      "json",
+				Output:      "both",
+				FilePath:    paths.GetLogPath("retention.log"),
+				Development: false,
+			},
+		}
+	}
+
+	logger, err := infrastructure.InitializeLogger(cfg.Logging)
+	if err != nil {
+		slog.Warn("Failed to initialize logger, using default", "error", err)
+		logger = slog.Default()
+	}
+
+	policy := files.RetentionPolicy{
+		ArchiveXLSXAfterMonths:  *archiveMonths,
+		DeleteDailyCSVAfterDays: *deleteDays,
+	}
+	if err := policy.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !policy.Enabled() {
+		fmt.Println("Nothing to do: set --archive-xlsx-months and/or --delete-daily-csv-days")
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	manager := files.NewRetentionManager(policy, files.RetentionTargets{
+		DownloadsDir:    paths.DownloadsDir,
+		DailyReportsDir: paths.DailyReportsDir,
+		ArchiveDir:      paths.ArchiveDir,
+	}, logger)
+
+	result, err := manager.Run(ctx, *dryRun)
+	if err != nil {
+		logger.Error("Retention run failed", slog.String("error", err.Error()))
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			logger.Error("Failed to encode result", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+	printResult(result)
+}
+
+// printResult writes a human-readable retention summary to stdout
+func printResult(result *files.RetentionResult) {
+	suffix := ""
+	if result.DryRun {
+		suffix = " (dry run, nothing changed)"
+	}
+	fmt.Printf("Retention complete%s\n", suffix)
+	fmt.Printf("Archived reports: %d into %d archive(s)\n", result.ArchivedFiles, len(result.Archives))
+	for _, a := range result.Archives {
+		fmt.Printf("  %s\n", a)
+	}
+	fmt.Printf("Deleted daily CSVs: %d\n", len(result.DeletedCSVs))
+	if result.KeptCSVs > 0 {
+		fmt.Printf("Kept daily CSVs whose report is still downloaded: %d\n", result.KeptCSVs)
+	}
+	fmt.Printf("Space freed: %.1f MB\n", float64(result.BytesFreed)/(1024*1024))
+}
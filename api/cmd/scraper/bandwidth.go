@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// downloadLimiter caps the combined download rate of a scrape run. Every
+// download reads through the same limiter, so concurrent transfers share the
+// cap instead of each getting their own. Nil means unlimited.
+var downloadLimiter *rate.Limiter
+
+// newBandwidthLimiter returns a token bucket refilled at kbps KB/s with one
+// second of burst, or nil when kbps is not positive
+func newBandwidthLimiter(kbps int) *rate.Limiter {
+	if kbps <= 0 {
+		return nil
+	}
+	bytesPerSecond := kbps * 1024
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond)
+}
+
+// throttledReader takes one token per byte read from the shared limiter
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+// newThrottledReader wraps r so reads are paced by limiter. A nil limiter
+// returns r unchanged.
+func newThrottledReader(ctx context.Context, r io.Reader, limiter *rate.Limiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, limiter: limiter}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	// WaitN fails for requests larger than the burst, so read at most that much
+	if burst := t.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if waitErr := t.limiter.WaitN(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
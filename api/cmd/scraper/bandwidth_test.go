@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestThrottledReader(t *testing.T) {
+	assert.Nil(t, newBandwidthLimiter(0), "zero means unlimited")
+	assert.Equal(t, 2048, newBandwidthLimiter(2).Burst())
+
+	// 4 KB/s with a 1 KB burst: 3 KB beyond the burst takes ~750ms, whether read
+	// by one reader or split across two sharing the limiter
+	limiter := rate.NewLimiter(4096, 1024)
+	data := bytes.Repeat([]byte("x"), 2048)
+
+	start := time.Now()
+	done := make(chan int64, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			n, _ := io.Copy(io.Discard, newThrottledReader(context.Background(), bytes.NewReader(data), limiter))
+			done <- n
+		}()
+	}
+	total := <-done + <-done
+	elapsed := time.Since(start)
+
+	assert.Equal(t, int64(4096), total)
+	assert.GreaterOrEqual(t, elapsed, 600*time.Millisecond)
+
+	plain := bytes.NewReader(data)
+	assert.Same(t, plain, newThrottledReader(context.Background(), plain, nil))
+}
+
+func TestThrottledReader_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := newThrottledReader(ctx, bytes.NewReader(make([]byte, 4096)), rate.NewLimiter(1, 1024))
+	_, err := io.ReadAll(r)
+	require.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// checkpointFileName is the backfill checkpoint file in data/cache
+	checkpointFileName = "isx_scrape_checkpoint.json"
+
+	// checkpointMaxAge is how long an interrupted run can be resumed; older
+	// checkpoints are ignored so late uploads to finished batches are found
+	checkpointMaxAge = 7 * 24 * time.Hour
+)
+
+// checkpoint records the finished batches of a split run, set at startup
+// unless --checkpoint=false. A nil checkpoint disables it.
+var checkpoint *rangeCheckpoint
+
+// checkpointState is the checkpoint file: the run it belongs to, the
+// batches it finished and the counters after the last of them
+type checkpointState struct {
+	From        string             `json:"from"`
+	To          string             `json:"to,omitempty"`
+	BatchMonths int                `json:"batch_months"`
+	Done        []siteRange        `json:"done"`
+	Counters    checkpointCounters `json:"counters"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// checkpointCounters are the scrapeCounters carried over to a resumed run
+type checkpointCounters struct {
+	TotalDownloaded   int        `json:"total_downloaded"`
+	TotalExisting     int        `json:"total_existing"`
+	FilesInRange      int        `json:"files_in_range"`
+	HolidaysInRange   int        `json:"holidays_in_range"`
+	LastProcessedDate *time.Time `json:"last_processed_date,omitempty"`
+}
+
+// rangeCheckpoint saves progress after every batch of a split run, so a run
+// interrupted by a crash, a cancellation or exhausted retries resumes with
+// the first unfinished batch when started again with the same range
+type rangeCheckpoint struct {
+	mu      sync.Mutex
+	path    string
+	logger  *slog.Logger
+	state   checkpointState
+	resumed bool // state holds finished batches of an earlier run
+}
+
+// openCheckpoint reads the checkpoint at path for a run over fromSite to
+// toSite. A missing, unreadable, stale or different run's checkpoint starts
+// afresh.
+func openCheckpoint(path, fromSite, toSite string, months int, now time.Time, logger *slog.Logger) *rangeCheckpoint {
+	c := &rangeCheckpoint{
+		path:   path,
+		logger: logger,
+		state:  checkpointState{From: fromSite, To: toSite, BatchMonths: months},
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read scrape checkpoint, starting afresh", slog.String("path", path), slog.String("error", err.Error()))
+		}
+		return c
+	}
+	var saved checkpointState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		logger.Warn("Scrape checkpoint is corrupt, starting afresh", slog.String("path", path), slog.String("error", err.Error()))
+		return c
+	}
+	switch {
+	case saved.From != fromSite || saved.To != toSite || saved.BatchMonths != months:
+		logger.Info("Scrape checkpoint belongs to another range, starting afresh",
+			slog.String("checkpoint_from", saved.From),
+			slog.String("checkpoint_to", saved.To))
+	case now.Sub(saved.UpdatedAt) > checkpointMaxAge:
+		logger.Info("Scrape checkpoint is too old to resume, starting afresh",
+			slog.Time("updated_at", saved.UpdatedAt))
+	case len(saved.Done) > 0:
+		c.state = saved
+		c.resumed = true
+	}
+	return c
+}
+
+// resume restores the counters of the interrupted run and returns how many
+// of its batches are finished
+func (c *rangeCheckpoint) resume(counters *scrapeCounters) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.resumed {
+		return 0
+	}
+	saved := c.state.Counters
+	counters.totalDownloaded = saved.TotalDownloaded
+	counters.totalExisting = saved.TotalExisting
+	counters.filesInRange = saved.FilesInRange
+	counters.holidaysInRange = saved.HolidaysInRange
+	counters.lastProcessedDate = saved.LastProcessedDate
+	return len(c.state.Done)
+}
+
+// done reports whether an earlier run finished batch r
+func (c *rangeCheckpoint) done(r siteRange) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, finished := range c.state.Done {
+		if finished == r {
+			return true
+		}
+	}
+	return false
+}
+
+// markDone records that batch r finished with the given counters
+func (c *rangeCheckpoint) markDone(r siteRange, counters *scrapeCounters) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.state.Done = append(c.state.Done, r)
+	c.state.Counters = checkpointCounters{
+		TotalDownloaded:   counters.totalDownloaded,
+		TotalExisting:     counters.totalExisting,
+		FilesInRange:      counters.filesInRange,
+		HolidaysInRange:   counters.holidaysInRange,
+		LastProcessedDate: counters.lastProcessedDate,
+	}
+	c.state.UpdatedAt = time.Now().UTC()
+	c.mu.Unlock()
+
+	if err := c.save(); err != nil {
+		c.logger.Warn("Failed to save scrape checkpoint", slog.String("path", c.path), slog.String("error", err.Error()))
+	}
+}
+
+// clear removes the checkpoint once a run got through all its batches
+func (c *rangeCheckpoint) clear() {
+	if c == nil {
+		return
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		c.logger.Warn("Failed to remove scrape checkpoint", slog.String("path", c.path), slog.String("error", err.Error()))
+	}
+}
+
+// save writes the checkpoint atomically
+func (c *rangeCheckpoint) save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
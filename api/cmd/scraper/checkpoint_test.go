@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/events"
+)
+
+func TestScrapeRangesResumesFromCheckpoint(t *testing.T) {
+	chunkRetryDelay = time.Millisecond
+	defer func() { chunkRetryDelay = 5 * time.Second }()
+	defer func(c *rangeCheckpoint) { checkpoint = c }(checkpoint)
+	defer func(p *events.ProgressEmitter) { progress = p }(progress)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), checkpointFileName)
+	ranges := []siteRange{
+		{From: "01/03/2025", To: "31/03/2025"},
+		{From: "01/02/2025", To: "28/02/2025"},
+		{From: "01/01/2025", To: "31/01/2025"},
+	}
+
+	// The first run finishes March and February, then January keeps failing
+	checkpoint = openCheckpoint(path, "01/01/2025", "31/03/2025", 1, time.Now(), logger)
+	progress = events.NewProgressEmitter(io.Discard, "scraping")
+	first := &scrapeCounters{}
+	err := scrapeRanges(context.Background(), ranges, first, logger, func(ctx context.Context, r siteRange) (bool, error) {
+		if r.From == "01/01/2025" {
+			return false, errors.New("timeout")
+		}
+		first.filesInRange += 20
+		first.totalDownloaded += 20
+		return false, nil
+	})
+	require.Error(t, err)
+	_, err = os.Stat(path)
+	require.NoError(t, err, "the checkpoint survives a failed run")
+
+	// The second run only searches January and starts from the saved counters
+	var buf bytes.Buffer
+	progress = events.NewProgressEmitter(&buf, "scraping")
+	checkpoint = openCheckpoint(path, "01/01/2025", "31/03/2025", 1, time.Now(), logger)
+	second := &scrapeCounters{}
+	var searched []string
+	require.NoError(t, scrapeRanges(context.Background(), ranges, second, logger, func(ctx context.Context, r siteRange) (bool, error) {
+		searched = append(searched, r.From)
+		second.filesInRange += 22
+		return false, nil
+	}))
+	assert.Equal(t, []string{"01/01/2025"}, searched)
+	assert.Equal(t, 62, second.filesInRange)
+	assert.Equal(t, 40, second.totalDownloaded)
+
+	var statuses []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var ev events.StepProgressEvent
+		if line, ok := strings.CutPrefix(line, events.StepProgressPrefix); ok &&
+			json.Unmarshal([]byte(line), &ev) == nil && ev.Event == events.StepEventBatch {
+			statuses = append(statuses, ev.Status)
+			assert.Equal(t, 3, ev.Total)
+		}
+	}
+	assert.Equal(t, []string{events.BatchStatusSkipped, events.BatchStatusSkipped, events.BatchStatusStarted, events.BatchStatusDone}, statuses)
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "a finished run removes its checkpoint")
+}
+
+func TestOpenCheckpoint(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "cache", checkpointFileName)
+	now := time.Date(2025, 8, 26, 10, 0, 0, 0, time.UTC)
+
+	saved := openCheckpoint(path, "01/01/2015", "31/12/2024", 3, now, logger)
+	saved.markDone(siteRange{From: "01/10/2024", To: "31/12/2024"}, &scrapeCounters{filesInRange: 60})
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		months  int
+		now     time.Time
+		resumed int
+	}{
+		{"same run", "01/01/2015", "31/12/2024", 3, time.Now(), 1},
+		{"another range", "01/01/2016", "31/12/2024", 3, time.Now(), 0},
+		{"another batch size", "01/01/2015", "31/12/2024", 1, time.Now(), 0},
+		{"stale", "01/01/2015", "31/12/2024", 3, time.Now().Add(checkpointMaxAge + time.Hour), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var counters scrapeCounters
+			c := openCheckpoint(path, tt.from, tt.to, tt.months, tt.now, logger)
+			assert.Equal(t, tt.resumed, c.resume(&counters))
+			if tt.resumed > 0 {
+				assert.Equal(t, 60, counters.filesInRange)
+				assert.True(t, c.done(siteRange{From: "01/10/2024", To: "31/12/2024"}))
+			}
+		})
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o644))
+	assert.Zero(t, openCheckpoint(path, "01/01/2015", "31/12/2024", 3, now, logger).resume(&scrapeCounters{}))
+
+	var disabled *rangeCheckpoint
+	assert.False(t, disabled.done(siteRange{}))
+	disabled.markDone(siteRange{}, &scrapeCounters{})
+	disabled.clear()
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"isxcli/internal/dataprocessing"
+	"isxcli/pkg/contracts/events"
+)
+
+// modeCompanies scrapes company profile pages instead of daily reports
+const modeCompanies = "companies"
+
+// companyProfileURL is the ISX portal page for one listed company
+const companyProfileURL = baseURL + "/isxportal/portal/companyprofilecontainer.html?currLanguage=en&companyCode="
+
+// companyRequestDelay spaces out profile requests so a full run does not
+// hammer the portal
+const companyRequestDelay = 500 * time.Millisecond
+
+// companyScraper fetches and parses company profile pages over HTTP
+type companyScraper struct {
+	engine     *httpEngine
+	profileURL string
+	delay      time.Duration
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// newCompanyScraper creates a scraper for profile pages at profileURL+symbol
+func newCompanyScraper(profileURL string, logger *slog.Logger) *companyScraper {
+	return &companyScraper{
+		engine:     newHTTPEngine(profileURL, logger),
+		profileURL: profileURL,
+		delay:      companyRequestDelay,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// run scrapes the given symbols and merges the results into the companies
+// CSV at outPath. Symbols that fail keep their previous profile. It returns
+// the number of profiles updated and an error only when nothing could be
+// scraped.
+func (s *companyScraper) run(ctx context.Context, symbols []string, outPath string) (int, error) {
+	existing := make(map[string]dataprocessing.CompanyProfile)
+	if profiles, err := dataprocessing.ReadCompaniesCSV(outPath); err == nil {
+		for _, p := range profiles {
+			existing[p.Symbol] = p
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Warn("Failed to read existing company profiles, starting fresh",
+			slog.String("path", outPath),
+			slog.String("error", err.Error()))
+	}
+
+	progress.Start(len(symbols), symbols)
+	updated, failed := 0, 0
+	for i, symbol := range symbols {
+		if i > 0 && s.delay > 0 {
+			select {
+			case <-ctx.Done():
+				return updated, ctx.Err()
+			case <-time.After(s.delay):
+			}
+		}
+
+		profile, err := s.scrape(ctx, symbol)
+		if err != nil {
+			failed++
+			s.logger.Warn("Failed to scrape company profile",
+				slog.String("symbol", symbol),
+				slog.String("error", err.Error()))
+			progress.Skip(symbol, "failed")
+			continue
+		}
+
+		existing[symbol] = mergeCompanyProfile(existing[symbol], profile)
+		updated++
+		slog.Info("Company profile scraped", "symbol", symbol, "sector", profile.Sector)
+		progress.Progress(i+1, len(symbols), symbol, events.ItemStatusDownloading)
+	}
+
+	if updated == 0 && failed > 0 {
+		return 0, fmt.Errorf("no company profiles could be scraped (%d failed)", failed)
+	}
+
+	profiles := make([]dataprocessing.CompanyProfile, 0, len(existing))
+	for _, p := range existing {
+		profiles = append(profiles, p)
+	}
+	if err := dataprocessing.WriteCompaniesCSV(outPath, profiles); err != nil {
+		return updated, err
+	}
+
+	s.logger.Info("Company profiles saved",
+		slog.String("path", outPath),
+		slog.Int("updated", updated),
+		slog.Int("failed", failed),
+		slog.Int("total", len(profiles)))
+	progress.Complete(updated, len(symbols), fmt.Sprintf("Updated %d company profiles", updated))
+	return updated, nil
+}
+
+// scrape fetches one company page and extracts its profile
+func (s *companyScraper) scrape(ctx context.Context, symbol string) (dataprocessing.CompanyProfile, error) {
+	doc, _, err := s.engine.fetch(ctx, http.MethodGet, s.profileURL+url.QueryEscape(symbol), nil)
+	if err != nil {
+		return dataprocessing.CompanyProfile{}, err
+	}
+
+	profile := parseCompanyProfile(doc)
+	if profile.Name == "" && profile.Sector == "" && profile.ListedShares == 0 {
+		return dataprocessing.CompanyProfile{}, fmt.Errorf("no profile data found for %s", symbol)
+	}
+	profile.Symbol = symbol
+	profile.UpdatedAt = s.now().UTC()
+	return profile, nil
+}
+
+// parseCompanyProfile reads the label/value tables of a company page. The
+// portal lays out each fact as a row with the label cell followed by the
+// value cell, sometimes two pairs per row.
+func parseCompanyProfile(doc *html.Node) dataprocessing.CompanyProfile {
+	var profile dataprocessing.CompanyProfile
+	for _, tr := range findAll(doc, func(n *html.Node) bool { return n.Data == "tr" }) {
+		cells := findAll(tr, func(n *html.Node) bool { return n.Data == "td" || n.Data == "th" })
+		for i := 0; i+1 < len(cells); i += 2 {
+			applyProfileField(&profile, textContent(cells[i]), textContent(cells[i+1]))
+		}
+	}
+	return profile
+}
+
+// applyProfileField stores value in the profile field named by label.
+// Unknown labels are ignored; the first value found for a field wins.
+func applyProfileField(p *dataprocessing.CompanyProfile, label, value string) {
+	label = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(label), ":"))
+	if label == "" || value == "" {
+		return
+	}
+
+	setAmount := func(dst *float64) {
+		if *dst == 0 {
+			if v, ok := parseAmount(value); ok {
+				*dst = v
+			}
+		}
+	}
+
+	switch {
+	case strings.Contains(label, "company name") || label == "name":
+		if p.Name == "" {
+			p.Name = value
+		}
+	case strings.Contains(label, "sector"):
+		if p.Sector == "" {
+			p.Sector = value
+		}
+	case strings.Contains(label, "shares") && !strings.Contains(label, "equity"):
+		if p.ListedShares == 0 {
+			if v, ok := parseAmount(value); ok {
+				p.ListedShares = int64(v)
+			}
+		}
+	case strings.Contains(label, "paid") && strings.Contains(label, "capital"):
+		setAmount(&p.PaidUpCapital)
+	case strings.Contains(label, "net profit") || strings.Contains(label, "net income"):
+		setAmount(&p.NetIncome)
+	case strings.Contains(label, "revenue"):
+		setAmount(&p.Revenue)
+	case strings.Contains(label, "total assets"):
+		setAmount(&p.TotalAssets)
+	case strings.Contains(label, "equity"):
+		setAmount(&p.ShareholderEquity)
+	case strings.Contains(label, "financial year") || strings.Contains(label, "fiscal year"):
+		if p.FinancialYear == "" {
+			p.FinancialYear = value
+		}
+	}
+}
+
+// parseAmount parses portal figures like "1,250,000,000", "250 IQD" or
+// "(3,400)" for negatives
+func parseAmount(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "IQD"))
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.Trim(s, "()")
+	s = strings.NewReplacer(",", "", " ", "").Replace(s)
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		v = -v
+	}
+	return v, true
+}
+
+// mergeCompanyProfile overlays a freshly scraped profile on the stored one,
+// keeping stored values the page did not publish this time
+func mergeCompanyProfile(old, fresh dataprocessing.CompanyProfile) dataprocessing.CompanyProfile {
+	if fresh.Name == "" {
+		fresh.Name = old.Name
+	}
+	if fresh.Sector == "" {
+		fresh.Sector = old.Sector
+	}
+	if fresh.ListedShares == 0 {
+		fresh.ListedShares = old.ListedShares
+	}
+	if fresh.PaidUpCapital == 0 {
+		fresh.PaidUpCapital = old.PaidUpCapital
+	}
+	if fresh.FinancialYear == "" {
+		fresh.FinancialYear = old.FinancialYear
+		fresh.Revenue = old.Revenue
+		fresh.NetIncome = old.NetIncome
+		fresh.TotalAssets = old.TotalAssets
+		fresh.ShareholderEquity = old.ShareholderEquity
+	}
+	return fresh
+}
+
+// companySymbols returns the symbols to scrape: the --symbols list when given,
+// otherwise every ticker in the ticker summary
+func companySymbols(list, tickerSummaryPath string) ([]string, error) {
+	seen := make(map[string]bool)
+	var symbols []string
+	add := func(s string) {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" && !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+
+	if list != "" {
+		for _, s := range strings.Split(list, ",") {
+			add(s)
+		}
+		return symbols, nil
+	}
+
+	data, err := os.ReadFile(tickerSummaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("read ticker summary (run the processor first or pass --symbols): %w", err)
+	}
+	var summary struct {
+		Tickers []struct {
+			Ticker string `json:"ticker"`
+		} `json:"tickers"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("parse ticker summary: %w", err)
+	}
+	for _, t := range summary.Tickers {
+		add(t.Ticker)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+
+	"isxcli/internal/dataprocessing"
+)
+
+const companyPage = `<html><body>
+<table class="profile">
+	<tr><td>Company Name:</td><td>Bank of Baghdad</td></tr>
+	<tr><td>Sector</td><td>Banks</td><td>Listed Shares</td><td>250,000,000,000</td></tr>
+	<tr><td>Paid Up Capital</td><td>250,000,000,000 IQD</td></tr>
+</table>
+<table class="financials">
+	<tr><th>Financial Year</th><th>2024</th></tr>
+	<tr><td>Total Revenues</td><td>31,200,000,000</td></tr>
+	<tr><td>Net Profit</td><td>(1,500,000)</td></tr>
+	<tr><td>Total Assets</td><td>1,100,000,000,000</td></tr>
+	<tr><td>Shareholders' Equity</td><td>290,000,000,000</td></tr>
+</table>
+</body></html>`
+
+func TestParseCompanyProfile(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(companyPage))
+	require.NoError(t, err)
+
+	p := parseCompanyProfile(doc)
+	assert.Equal(t, "Bank of Baghdad", p.Name)
+	assert.Equal(t, "Banks", p.Sector)
+	assert.Equal(t, int64(250_000_000_000), p.ListedShares)
+	assert.Equal(t, 250_000_000_000.0, p.PaidUpCapital)
+	assert.Equal(t, "2024", p.FinancialYear)
+	assert.Equal(t, 31_200_000_000.0, p.Revenue)
+	assert.Equal(t, -1_500_000.0, p.NetIncome)
+	assert.Equal(t, 1_100_000_000_000.0, p.TotalAssets)
+	assert.Equal(t, 290_000_000_000.0, p.ShareholderEquity)
+}
+
+func TestCompanyScraper_Run(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("companyCode") {
+		case "BBOB":
+			fmt.Fprint(w, companyPage)
+		case "TASC":
+			fmt.Fprint(w, `<table><tr><td>Company Name</td><td>Asia Cell</td></tr><tr><td>Sector</td><td>Telecom</td></tr></table>`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	outPath := filepath.Join(t.TempDir(), "reference", "companies.csv")
+	// A stored profile for TASC keeps its share count when the page omits it
+	require.NoError(t, dataprocessing.WriteCompaniesCSV(outPath, []dataprocessing.CompanyProfile{
+		{Symbol: "TASC", Name: "Asia Cell (old)", ListedShares: 310_000_000_000},
+		{Symbol: "IBSD", Name: "Baghdad Soft Drinks", Sector: "Industry"},
+	}))
+
+	scraper := newCompanyScraper(server.URL+"/profile?companyCode=", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	scraper.delay = 0
+	scraper.now = func() time.Time { return time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC) }
+
+	updated, err := scraper.run(context.Background(), []string{"BBOB", "TASC", "MISSING"}, outPath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	profiles, err := dataprocessing.ReadCompaniesCSV(outPath)
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	bySymbol := make(map[string]dataprocessing.CompanyProfile)
+	for _, p := range profiles {
+		bySymbol[p.Symbol] = p
+	}
+	assert.Equal(t, "Banks", bySymbol["BBOB"].Sector)
+	assert.Equal(t, "Asia Cell", bySymbol["TASC"].Name)
+	assert.Equal(t, int64(310_000_000_000), bySymbol["TASC"].ListedShares)
+	assert.Equal(t, "Baghdad Soft Drinks", bySymbol["IBSD"].Name, "profiles not scraped this run are kept")
+	assert.Equal(t, time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC), bySymbol["BBOB"].UpdatedAt)
+}
+
+func TestCompanyScraper_AllFailed(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	outPath := filepath.Join(t.TempDir(), "companies.csv")
+	scraper := newCompanyScraper(server.URL+"/profile?companyCode=", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	scraper.delay = 0
+
+	_, err := scraper.run(context.Background(), []string{"BBOB"}, outPath)
+	assert.Error(t, err)
+	assert.NoFileExists(t, outPath)
+}
+
+func TestCompanySymbols(t *testing.T) {
+	symbols, err := companySymbols(" bbob, TASC,bbob ", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BBOB", "TASC"}, symbols)
+
+	summary := filepath.Join(t.TempDir(), "ticker_summary.json")
+	require.NoError(t, os.WriteFile(summary, []byte(`{"tickers":[{"ticker":"TASC"},{"ticker":"BBOB"}]}`), 0644))
+	symbols, err = companySymbols("", summary)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BBOB", "TASC"}, symbols)
+
+	_, err = companySymbols("", filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
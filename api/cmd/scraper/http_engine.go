@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Scraping engines selectable with --engine
+const (
+	engineAuto   = "auto"
+	engineChrome = "chrome"
+	engineHTTP   = "http"
+)
+
+// httpEngine scrapes the uploadedFilesList pages with plain HTTP requests and
+// HTML parsing. It is used on hosts where Chrome is not installed.
+type httpEngine struct {
+	client  *http.Client
+	listURL string
+	logger  *slog.Logger
+}
+
+// newHTTPEngine creates an HTTP engine for the given list page URL
+func newHTTPEngine(listURL string, logger *slog.Logger) *httpEngine {
+	// The portal keeps the search in the session, so cookies must survive
+	// between the form submission and the pagination requests
+	jar, _ := cookiejar.New(nil)
+	return &httpEngine{
+		client:  &http.Client{Jar: jar, Timeout: 60 * time.Second, Transport: scraperTransport},
+		listURL: listURL,
+		logger:  logger,
+	}
+}
+
+// run searches the range batch by batch and walks every results page, mirroring runScraper
+func (e *httpEngine) run(ctx context.Context, fromSite, toSite, outDir string, expectedFiles int, actualFromStr, actualToStr string) error {
+	ranges, err := batchRanges(fromSite, toSite, batchMonths, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var counters scrapeCounters
+	return scrapeRanges(ctx, ranges, &counters, e.logger, func(ctx context.Context, r siteRange) (bool, error) {
+		if handled, complete, err := replayCachedRange(ctx, r, outDir, e.logger, &counters, expectedFiles, actualFromStr, actualToStr); handled {
+			return complete, err
+		}
+		return e.search(ctx, r, outDir, &counters, expectedFiles, actualFromStr, actualToStr)
+	})
+}
+
+// search submits the search form for one range and walks its results pages
+func (e *httpEngine) search(ctx context.Context, r siteRange, outDir string, c *scrapeCounters, expectedFiles int, actualFromStr, actualToStr string) (bool, error) {
+	start := time.Now()
+	formPage, pageURL, err := e.fetch(ctx, http.MethodGet, e.listURL, nil)
+	if err != nil {
+		return false, err
+	}
+	e.logger.Debug("Action completed", slog.String("action", "Navigate"), slog.Duration("duration", time.Since(start)))
+
+	form := findSearchForm(formPage)
+	if form == nil {
+		return false, fmt.Errorf("search form not found on %s", pageURL)
+	}
+	values := formValues(form)
+	setFieldByID(form, values, "date", r.From)
+	if r.To != "" {
+		setFieldByID(form, values, "toDate", r.To)
+	}
+	setFieldByID(form, values, "reporttype", "40")
+
+	action, err := pageURL.Parse(attr(form, "action"))
+	if err != nil {
+		return false, fmt.Errorf("invalid search form action: %w", err)
+	}
+	method := strings.ToUpper(attr(form, "method"))
+	if method != http.MethodPost {
+		method = http.MethodGet
+	}
+
+	start = time.Now()
+	doc, pageURL, err := e.fetch(ctx, method, action.String(), values)
+	if err != nil {
+		return false, err
+	}
+	e.logger.Debug("Action completed", slog.String("action", "ExecuteSearch"), slog.Duration("duration", time.Since(start)))
+
+	// entry is the cache record of the page being read; the first page is
+	// a form submission and is always fetched in full
+	var entry cachedListPage
+	var cached *cachedListPage
+	for page := 1; ; page++ {
+		slog.Info("Scraping page", "page", page)
+		e.logger.Info("Scraping page", slog.Int("page", page), slog.String("engine", engineHTTP))
+		progress.Status(fmt.Sprintf("Scanning page %d", page))
+
+		if doc == nil {
+			// 304 Not Modified: the cached copy is current
+			entry.Rows, entry.Next = cached.Rows, cached.Next
+		} else {
+			report := findByID(doc, "report")
+			if report == nil {
+				return false, fmt.Errorf("report table not found on page %d", page)
+			}
+			entry.Rows = parseReportRows(report, pageURL)
+			entry.Next = nextPageURL(doc, pageURL)
+		}
+		entry.Last = entry.Next == ""
+		listCache.store(r, page, entry)
+
+		_, _, shouldContinue, err := processRows(ctx, entry.Rows, outDir, e.logger, &c.totalDownloaded, &c.totalExisting, &c.filesInRange, &c.holidaysInRange, expectedFiles, actualFromStr, actualToStr, &c.lastProcessedDate, &c.stopReason)
+		if err != nil {
+			return false, err
+		}
+		if !shouldContinue {
+			slog.Info("Found existing files, stopping scraping process", "page", page)
+			e.logger.Info("Found existing files, stopping scraping", slog.Int("page", page))
+			return c.complete(expectedFiles), nil
+		}
+		if c.complete(expectedFiles) {
+			e.logger.Info("Completion criteria met",
+				slog.Int("files_in_range", c.filesInRange),
+				slog.Int("holidays_in_range", c.holidaysInRange),
+				slog.Int("total_accounted", c.filesInRange+c.holidaysInRange),
+				slog.Int("expected_files", expectedFiles))
+			slog.Info("SCRAPER_COMPLETE: All required dates processed")
+			progress.Complete(c.filesInRange+c.holidaysInRange, expectedFiles, "All required dates processed")
+			return true, nil
+		}
+
+		if entry.Last {
+			return false, nil
+		}
+		cached = listCache.lookup(r, page+1)
+		if doc, pageURL, entry, err = e.fetchListPage(ctx, entry.Next, cached); err != nil {
+			return false, err
+		}
+	}
+}
+
+// fetchListPage requests a results page, sending the validators of its
+// cached copy so an unchanged page costs the portal a 304 instead of a full
+// render. The returned document is nil when the portal answered 304; the
+// returned entry carries the page's URL and validators.
+func (e *httpEngine) fetchListPage(ctx context.Context, target string, cached *cachedListPage) (*html.Node, *url.URL, cachedListPage, error) {
+	entry := cachedListPage{URL: target}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, entry, fmt.Errorf("create request for %s: %w", target, err)
+	}
+	conditional := cached != nil && cached.URL == target && (cached.ETag != "" || cached.LastModified != "")
+	if conditional {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, entry, fmt.Errorf("request %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	entry.ETag = resp.Header.Get("ETag")
+	entry.LastModified = resp.Header.Get("Last-Modified")
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		if entry.ETag == "" {
+			entry.ETag = cached.ETag
+		}
+		if entry.LastModified == "" {
+			entry.LastModified = cached.LastModified
+		}
+		return nil, resp.Request.URL, entry, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, entry, fmt.Errorf("bad status for %s: %s", target, resp.Status)
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		return nil, nil, entry, fmt.Errorf("parse %s: %w", target, err)
+	}
+	return doc, resp.Request.URL, entry, nil
+}
+
+// fetch requests a page and parses it, returning the document and its final URL
+func (e *httpEngine) fetch(ctx context.Context, method, target string, values url.Values) (*html.Node, *url.URL, error) {
+	var body io.Reader
+	if values != nil && method == http.MethodPost {
+		body = strings.NewReader(values.Encode())
+	} else if values != nil {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid url %s: %w", target, err)
+		}
+		u.RawQuery = values.Encode()
+		target = u.String()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request for %s: %w", target, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("bad status for %s: %s", target, resp.Status)
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", target, err)
+	}
+	return doc, resp.Request.URL, nil
+}
+
+// parseReportRows extracts the rows of the #report table, the equivalent of the
+// selector used by the Chrome engine. Links are resolved against the page URL.
+func parseReportRows(report *html.Node, pageURL *url.URL) []reportRow {
+	var rows []reportRow
+	for _, tr := range findAll(report, func(n *html.Node) bool { return n.Data == "tr" }) {
+		var row reportRow
+		var hasLink bool
+		for _, td := range findAll(tr, func(n *html.Node) bool { return n.Data == "td" }) {
+			switch {
+			case hasClass(td, "report-download"):
+				if links := findAll(td, func(n *html.Node) bool { return n.Data == "a" }); len(links) > 0 {
+					row.Href = attr(links[0], "href")
+					hasLink = true
+				}
+			case hasClass(td, "report-titledata1"):
+				row.Date = textContent(td)
+			case hasClass(td, "report-titledata3"):
+				row.Typ = textContent(td)
+			}
+		}
+		if !hasLink {
+			continue
+		}
+		if u, err := pageURL.Parse(row.Href); err == nil {
+			row.Href = u.String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// nextPageURL returns the link wrapping the next.gif arrow, or "" on the last page
+func nextPageURL(doc *html.Node, pageURL *url.URL) string {
+	for _, a := range findAll(doc, func(n *html.Node) bool { return n.Data == "a" }) {
+		imgs := findAll(a, func(n *html.Node) bool {
+			return n.Data == "img" && strings.Contains(attr(n, "src"), "next.gif")
+		})
+		if len(imgs) == 0 {
+			continue
+		}
+		href := attr(a, "href")
+		if href == "" || href == "#" || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return ""
+		}
+		u, err := pageURL.Parse(href)
+		if err != nil {
+			return ""
+		}
+		return u.String()
+	}
+	return ""
+}
+
+// findSearchForm returns the form holding the #date input
+func findSearchForm(doc *html.Node) *html.Node {
+	for _, form := range findAll(doc, func(n *html.Node) bool { return n.Data == "form" }) {
+		if findByID(form, "date") != nil {
+			return form
+		}
+	}
+	return nil
+}
+
+// formValues collects the values a browser would submit for the form,
+// including the first submit button as if it had been clicked
+func formValues(form *html.Node) url.Values {
+	values := url.Values{}
+	submitted := false
+	for _, n := range findAll(form, func(n *html.Node) bool {
+		return n.Data == "input" || n.Data == "select" || n.Data == "textarea"
+	}) {
+		name := attr(n, "name")
+		if name == "" {
+			continue
+		}
+		switch n.Data {
+		case "input":
+			switch strings.ToLower(attr(n, "type")) {
+			case "submit", "image":
+				if !submitted {
+					values.Set(name, attr(n, "value"))
+					submitted = true
+				}
+			case "button", "reset", "file":
+			case "checkbox", "radio":
+				if hasAttr(n, "checked") {
+					values.Add(name, valueOr(n, "on"))
+				}
+			default:
+				values.Add(name, attr(n, "value"))
+			}
+		case "select":
+			options := findAll(n, func(o *html.Node) bool { return o.Data == "option" })
+			var chosen *html.Node
+			for _, o := range options {
+				if hasAttr(o, "selected") {
+					chosen = o
+					break
+				}
+			}
+			if chosen == nil && len(options) > 0 {
+				chosen = options[0]
+			}
+			if chosen != nil {
+				values.Set(name, valueOr(chosen, textContent(chosen)))
+			}
+		case "textarea":
+			values.Add(name, textContent(n))
+		}
+	}
+	return values
+}
+
+// setFieldByID sets the value of the form field with the given id
+func setFieldByID(form *html.Node, values url.Values, id, value string) {
+	field := findByID(form, id)
+	if field == nil {
+		return
+	}
+	name := attr(field, "name")
+	if name == "" {
+		name = id
+	}
+	values.Set(name, value)
+}
+
+func findByID(root *html.Node, id string) *html.Node {
+	if matches := findAll(root, func(n *html.Node) bool { return attr(n, "id") == id }); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
+
+// findAll returns the element descendants of root matching the predicate, in document order
+func findAll(root *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && match(c) {
+				found = append(found, c)
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func valueOr(n *html.Node, fallback string) string {
+	if hasAttr(n, "value") {
+		return attr(n, "value")
+	}
+	return fallback
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the whitespace-collapsed text of a node, like innerText.trim()
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
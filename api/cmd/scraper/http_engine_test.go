@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+const searchFormPage = `<html><body>
+<form action="uploadedFilesList.html" method="get">
+	<input type="hidden" name="currLanguage" value="en">
+	<input type="text" id="date" name="fromDate" value="">
+	<input type="text" id="toDate" name="toDate" value="">
+	<select id="reporttype" name="reportType"><option value="0">All</option><option value="40">Daily</option></select>
+	<input type="submit" name="search" value="Search">
+</form>
+</body></html>`
+
+const resultsPage = `<html><body>
+<table id="report"><tbody>%s</tbody></table>
+%s
+</body></html>`
+
+func reportRowHTML(href, date, typ string) string {
+	return fmt.Sprintf(`<tr><td class="report-titledata1"> %s </td><td class="report-titledata3">%s</td><td class="report-download"><a href="%s"><img src="xls.gif"></a></td></tr>`, date, typ, href)
+}
+
+func TestHTTPEngine_Run(t *testing.T) {
+	var search url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("/isxportal/portal/uploadedFilesList.html", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("page") == "2":
+			fmt.Fprintf(w, resultsPage, reportRowHTML("/files/20250102.xlsx", "02/01/2025", "Daily"), "")
+		case q.Get("fromDate") != "":
+			search = q
+			rows := reportRowHTML("/files/20250105.xlsx", "05/01/2025", "Daily") +
+				reportRowHTML("/files/weekly.xlsx", "05/01/2025", "Weekly")
+			fmt.Fprintf(w, resultsPage, rows, `<a href="uploadedFilesList.html?page=2"><img src="/images/next.gif"></a>`)
+		default:
+			fmt.Fprint(w, searchFormPage)
+		}
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testReportXLSX(t, "xlsx "+r.URL.Path))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	outDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	engine := newHTTPEngine(server.URL+"/isxportal/portal/uploadedFilesList.html?currLanguage=en", logger)
+
+	err := engine.run(context.Background(), "01/01/2025", "06/01/2025", outDir, 10, "", "")
+	require.NoError(t, err)
+
+	require.NotNil(t, search, "search form was submitted")
+	assert.Equal(t, "01/01/2025", search.Get("fromDate"))
+	assert.Equal(t, "06/01/2025", search.Get("toDate"))
+	assert.Equal(t, "40", search.Get("reportType"))
+	assert.Equal(t, "en", search.Get("currLanguage"))
+	assert.Equal(t, "Search", search.Get("search"))
+
+	files, err := filepath.Glob(filepath.Join(outDir, "*.xlsx"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "weekly reports are skipped")
+
+	assert.Equal(t, "xlsx /files/20250102.xlsx", reportLabel(t, filepath.Join(outDir, "2025 01 02 ISX Daily Report.xlsx")),
+		"second page is reached through the next arrow")
+	assert.FileExists(t, filepath.Join(outDir, "2025 01 05 ISX Daily Report.xlsx"))
+}
+
+func TestHTTPEngine_RunSplitsMonths(t *testing.T) {
+	chunkRetryDelay = time.Millisecond
+	defer func() { chunkRetryDelay = 5 * time.Second }()
+
+	var searches []string
+	failed := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/isxportal/portal/uploadedFilesList.html", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch q.Get("fromDate") {
+		case "":
+			fmt.Fprint(w, searchFormPage)
+		case "01/01/2025":
+			searches = append(searches, q.Get("fromDate")+"-"+q.Get("toDate"))
+			if !failed {
+				failed = true
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			fmt.Fprintf(w, resultsPage, reportRowHTML("/files/20250105.xlsx", "05/01/2025", "Daily"), "")
+		default:
+			searches = append(searches, q.Get("fromDate")+"-"+q.Get("toDate"))
+			fmt.Fprintf(w, resultsPage, reportRowHTML("/files/20241230.xlsx", "30/12/2024", "Daily"), "")
+		}
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testReportXLSX(t, "xlsx "+r.URL.Path))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	outDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	engine := newHTTPEngine(server.URL+"/isxportal/portal/uploadedFilesList.html?currLanguage=en", logger)
+
+	err := engine.run(context.Background(), "29/12/2024", "06/01/2025", outDir, 10, "", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"01/01/2025-06/01/2025", "01/01/2025-06/01/2025", "29/12/2024-31/12/2024"}, searches,
+		"months are searched newest first and a failed month is retried")
+	assert.FileExists(t, filepath.Join(outDir, "2025 01 05 ISX Daily Report.xlsx"))
+	assert.FileExists(t, filepath.Join(outDir, "2024 12 30 ISX Daily Report.xlsx"))
+}
+
+func TestParseReportRows(t *testing.T) {
+	page := fmt.Sprintf(resultsPage,
+		reportRowHTML("/files/a.xlsx", "05/01/2025", "Daily")+`<tr><td class="report-titledata1">no link</td></tr>`,
+		`<a href="javascript:void(0)"><img src="next.gif"></a>`)
+	doc, err := html.Parse(strings.NewReader(page))
+	require.NoError(t, err)
+
+	pageURL, _ := url.Parse("http://www.isx-iq.net/isxportal/portal/uploadedFilesList.html")
+	rows := parseReportRows(findByID(doc, "report"), pageURL)
+	require.Len(t, rows, 1)
+	assert.Equal(t, reportRow{Href: "http://www.isx-iq.net/files/a.xlsx", Date: "05/01/2025", Typ: "Daily"}, rows[0])
+	assert.Empty(t, nextPageURL(doc, pageURL), "script links end pagination")
+}
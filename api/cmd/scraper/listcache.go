@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// listCacheFileName is the list cache file in data/cache
+	listCacheFileName = "isx_list_pages.json"
+
+	// listSettleDays is how long after a range ends before late uploads are
+	// no longer expected and its cached pages can stand in for the portal
+	listSettleDays = 7
+
+	// listCacheTTL is how long the cached pages of a settled range are trusted
+	listCacheTTL = 30 * 24 * time.Hour
+)
+
+// listCache remembers the parsed results pages of earlier runs, set at
+// startup unless --list-cache=false. A nil cache disables it.
+var listCache *listPageCache
+
+// cachedListPage is one parsed results page of a search
+type cachedListPage struct {
+	Rows []reportRow `json:"rows"`
+	// URL is the address the page was fetched from; the first page of a
+	// search is a form submission and has none
+	URL string `json:"url,omitempty"`
+	// Next is the next page's address, used by the HTTP engine
+	Next string `json:"next,omitempty"`
+	// Last marks the final page of the search
+	Last         bool      `json:"last"`
+	Hash         string    `json:"hash"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// listPageCache stores results pages per search range and page number so
+// later runs can skip settled months and fetch unchanged pages cheaply
+type listPageCache struct {
+	mu     sync.Mutex
+	path   string
+	logger *slog.Logger
+	pages  map[string]*cachedListPage
+}
+
+// loadListPageCache reads the cache at path. A missing or unreadable file
+// starts an empty cache.
+func loadListPageCache(path string, logger *slog.Logger) *listPageCache {
+	c := &listPageCache{path: path, logger: logger, pages: make(map[string]*cachedListPage)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read list cache, starting empty", slog.String("path", path), slog.String("error", err.Error()))
+		}
+		return c
+	}
+	if err := json.Unmarshal(data, &c.pages); err != nil {
+		logger.Warn("List cache is corrupt, starting empty", slog.String("path", path), slog.String("error", err.Error()))
+		c.pages = make(map[string]*cachedListPage)
+	}
+	return c
+}
+
+// listPageKey identifies a results page of a search
+func listPageKey(r siteRange, page int) string {
+	return fmt.Sprintf("%s-%s#%d", r.From, r.To, page)
+}
+
+// hashRows fingerprints a page's rows to tell whether it changed
+func hashRows(rows []reportRow) string {
+	h := sha256.New()
+	for _, row := range rows {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\n", row.Href, row.Date, row.Typ)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup returns the cached copy of a page, or nil
+func (c *listPageCache) lookup(r siteRange, page int) *cachedListPage {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pages[listPageKey(r, page)]
+}
+
+// store records a freshly read page and reports whether it is unchanged
+// since the last run. Storing the last page drops any later pages a longer
+// earlier result left behind.
+func (c *listPageCache) store(r siteRange, page int, entry cachedListPage) (unchanged bool) {
+	if c == nil {
+		return false
+	}
+	entry.Hash = hashRows(entry.Rows)
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+
+	c.mu.Lock()
+	key := listPageKey(r, page)
+	if previous := c.pages[key]; previous != nil {
+		unchanged = previous.Hash == entry.Hash
+	}
+	c.pages[key] = &entry
+	if entry.Last {
+		for p := page + 1; c.pages[listPageKey(r, p)] != nil; p++ {
+			delete(c.pages, listPageKey(r, p))
+		}
+	}
+	c.mu.Unlock()
+
+	if unchanged {
+		c.logger.Debug("Results page unchanged since last run",
+			slog.String("from", r.From),
+			slog.String("to", r.To),
+			slog.Int("page", page))
+	}
+	if err := c.save(); err != nil {
+		c.logger.Warn("Failed to save list cache", slog.String("path", c.path), slog.String("error", err.Error()))
+	}
+	return unchanged
+}
+
+// markLast records that page was the final page of a search
+func (c *listPageCache) markLast(r siteRange, page int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	entry := c.pages[listPageKey(r, page)]
+	c.mu.Unlock()
+	if entry == nil || entry.Last {
+		return
+	}
+	updated := *entry
+	updated.Last = true
+	c.store(r, page, updated)
+}
+
+// settledPages returns every cached page of r when the range ended long
+// enough ago that the portal will not change it and the cache holds all of
+// its pages, fetched recently. It returns nil when the portal must be asked.
+func (c *listPageCache) settledPages(r siteRange, now time.Time) []*cachedListPage {
+	if c == nil || r.To == "" {
+		return nil
+	}
+	end, err := time.Parse(siteDateLayout, r.To)
+	if err != nil || !end.Before(now.AddDate(0, 0, -listSettleDays)) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var pages []*cachedListPage
+	for page := 1; ; page++ {
+		entry := c.pages[listPageKey(r, page)]
+		if entry == nil || now.Sub(entry.FetchedAt) > listCacheTTL {
+			return nil
+		}
+		pages = append(pages, entry)
+		if entry.Last {
+			return pages
+		}
+	}
+}
+
+// save writes the cache atomically
+func (c *listPageCache) save() error {
+	c.mu.Lock()
+	data, err := json.Marshal(c.pages)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// replayCachedRange walks the cached pages of a settled range instead of
+// searching the portal, downloading any report that is missing locally.
+// handled is false when the cache cannot stand in for the search.
+func replayCachedRange(ctx context.Context, r siteRange, outDir string, logger *slog.Logger, c *scrapeCounters, expectedFiles int, actualFromStr, actualToStr string) (handled, complete bool, err error) {
+	pages := listCache.settledPages(r, time.Now())
+	if pages == nil {
+		return false, false, nil
+	}
+	logger.Info("Using cached report list for settled range",
+		slog.String("from", r.From),
+		slog.String("to", r.To),
+		slog.Int("pages", len(pages)))
+
+	for i, page := range pages {
+		progress.Status(fmt.Sprintf("Scanning cached page %d", i+1))
+		_, _, shouldContinue, err := processRows(ctx, page.Rows, outDir, logger, &c.totalDownloaded, &c.totalExisting, &c.filesInRange, &c.holidaysInRange, expectedFiles, actualFromStr, actualToStr, &c.lastProcessedDate, &c.stopReason)
+		if err != nil {
+			return true, false, err
+		}
+		if !shouldContinue {
+			logger.Info("Found existing files, stopping scraping", slog.Int("page", i+1), slog.Bool("cached", true))
+			return true, c.complete(expectedFiles), nil
+		}
+		if c.complete(expectedFiles) {
+			logger.Info("Completion criteria met",
+				slog.Int("files_in_range", c.filesInRange),
+				slog.Int("holidays_in_range", c.holidaysInRange),
+				slog.Int("expected_files", expectedFiles))
+			slog.Info("SCRAPER_COMPLETE: All required dates processed")
+			progress.Complete(c.filesInRange+c.holidaysInRange, expectedFiles, "All required dates processed")
+			return true, true, nil
+		}
+	}
+	return true, false, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPageCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", listCacheFileName)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cache := loadListPageCache(path, logger)
+	r := siteRange{From: "01/01/2025", To: "31/01/2025"}
+	rows := []reportRow{{Href: "/files/20250105.xlsx", Date: "05/01/2025", Typ: "Daily"}}
+
+	assert.False(t, cache.store(r, 1, cachedListPage{Rows: rows}), "a new page is not unchanged")
+	assert.True(t, cache.store(r, 1, cachedListPage{Rows: rows}))
+	assert.False(t, cache.store(r, 1, cachedListPage{Rows: append(rows, reportRow{Href: "/files/x.xlsx"})}))
+	cache.store(r, 2, cachedListPage{Rows: rows})
+	cache.store(r, 3, cachedListPage{Rows: rows, Last: true})
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Len(t, cache.settledPages(r, now), 3)
+	assert.Nil(t, cache.settledPages(r, time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)), "late uploads are still possible")
+	assert.Nil(t, cache.settledPages(r, time.Now().AddDate(0, 0, 31)), "cached pages expire")
+	assert.Nil(t, cache.settledPages(siteRange{From: "01/02/2025", To: "28/02/2025"}, now))
+
+	// The search shrank: storing the new last page drops the stale third page
+	cache.store(r, 2, cachedListPage{Rows: rows, Last: true})
+	assert.Nil(t, cache.lookup(r, 3))
+
+	reloaded := loadListPageCache(path, logger)
+	assert.Len(t, reloaded.settledPages(r, now), 2, "the cache survives restarts")
+
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o644))
+	assert.Nil(t, loadListPageCache(path, logger).lookup(r, 1), "a corrupt cache starts empty")
+
+	var disabled *listPageCache
+	assert.False(t, disabled.store(r, 1, cachedListPage{Rows: rows}))
+	assert.Nil(t, disabled.settledPages(r, now))
+}
+
+func TestHTTPEngine_ListCache(t *testing.T) {
+	today := time.Now().Format(siteDateLayout)
+	var searches, conditional, notModified int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/isxportal/portal/uploadedFilesList.html", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("page") == "2":
+			if r.Header.Get("If-None-Match") != "" {
+				conditional++
+			}
+			if r.Header.Get("If-None-Match") == `"page-2"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"page-2"`)
+			fmt.Fprintf(w, resultsPage, reportRowHTML("/files/20250102.xlsx", "02/01/2025", "Daily"), "")
+		case q.Get("fromDate") != "":
+			searches++
+			fmt.Fprintf(w, resultsPage, reportRowHTML("/files/20250105.xlsx", "05/01/2025", "Daily"),
+				`<a href="uploadedFilesList.html?page=2"><img src="/images/next.gif"></a>`)
+		default:
+			fmt.Fprint(w, searchFormPage)
+		}
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testReportXLSX(t, "xlsx "+r.URL.Path))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	listCache = loadListPageCache(filepath.Join(t.TempDir(), listCacheFileName), logger)
+	defer func() { listCache = nil }()
+	engine := newHTTPEngine(server.URL+"/isxportal/portal/uploadedFilesList.html?currLanguage=en", logger)
+
+	scrape := func(from, to string) string {
+		outDir := t.TempDir()
+		require.NoError(t, engine.run(context.Background(), from, to, outDir, 10, "", ""))
+		files, err := filepath.Glob(filepath.Join(outDir, "*.xlsx"))
+		require.NoError(t, err)
+		assert.Len(t, files, 2)
+		return outDir
+	}
+
+	t.Run("recent range revalidates pages", func(t *testing.T) {
+		scrape(today, today)
+		scrape(today, today)
+		assert.Equal(t, 2, searches, "recent ranges are always searched")
+		assert.Equal(t, 1, conditional, "the second run sends the cached ETag")
+		assert.Equal(t, 1, notModified)
+	})
+
+	t.Run("settled range is replayed", func(t *testing.T) {
+		searches = 0
+		scrape("01/01/2025", "06/01/2025")
+		outDir := scrape("01/01/2025", "06/01/2025")
+		assert.Equal(t, 1, searches, "the settled month is served from the cache")
+		assert.FileExists(t, filepath.Join(outDir, "2025 01 02 ISX Daily Report.xlsx"),
+			"reports listed on cached pages are still downloaded")
+	})
+}
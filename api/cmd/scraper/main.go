@@ -18,13 +18,18 @@ import (
 	"strings"
 	"time"
 
+	"isxcli/internal/browser"
 	"isxcli/internal/config"
 	"isxcli/internal/infrastructure"
 	"isxcli/internal/license"
+	"isxcli/pkg/contracts/events"
 
 	"github.com/chromedp/chromedp"
 )
 
+// progress reports structured progress events to the operations stage
+var progress = events.NewProgressEmitter(os.Stdout, "scraping")
+
 const (
 	baseURL  = "http://www.isx-iq.net"
 	startURL = "http://www.isx-iq.net/isxportal/portal/uploadedFilesList.html?currLanguage=en"
@@ -49,7 +54,7 @@ func main() {
 		}
 	}()
 	
-	mode := flag.String("mode", "initial", "scrape mode: initial | accumulative")
+	mode := flag.String("mode", "initial", "scrape mode: initial | accumulative | companies")
 	fromStr := flag.String("from", "2025-01-01", "start date (YYYY-MM-DD) (used in initial mode if provided)")
 	toStr := flag.String("to", "", "optional end date (YYYY-MM-DD); leave blank to keep site default")
 	// Actual dates for progress tracking (not for scraper logic)
@@ -58,8 +63,21 @@ func main() {
 	outDir := flag.String("out", "", "directory to save reports (defaults to data/downloads relative to executable)")
 	headless := flag.Bool("headless", true, "run browser headless")
 	stateFile := flag.String("state-file", "", "path to license state file (for validation bypass)")
+	profile := flag.String("profile", "", "data profile to use (defaults to $ISX_PROFILE or the default profile)")
+	bandwidthKBps := flag.Int("bandwidth-kbps", 0, "cap on the combined download rate in KB/s (0 = unlimited)")
+	engine := flag.String("engine", engineAuto, "scraping engine: auto | chrome | http (auto falls back to http when Chrome cannot be launched)")
+	symbols := flag.String("symbols", "", "companies mode: comma-separated tickers to scrape (defaults to every ticker in the ticker summary)")
+	flag.IntVar(&chunkRetries, "chunk-retries", defaultChunkRetries, "attempts per month-sized search before the scrape fails")
+	useListCache := flag.Bool("list-cache", true, "cache the report list pages between runs so settled months are not searched again")
+	batch := flag.String("batch", batchMonth, "size of the searches a long range is split into: month | quarter")
+	useCheckpoint := flag.Bool("checkpoint", true, "save progress after every batch so an interrupted run over the same range resumes where it stopped")
 	flag.Parse()
 
+	if err := config.SetActiveProfile(*profile); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize paths first to get default directories
 	paths, err := config.GetPaths()
 	if err != nil {
@@ -101,6 +119,26 @@ func main() {
 		logger = slog.Default()
 	}
 
+	if politeness, err = newPoliteness(cfg.Scraper); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	scraperTransport = politeness.transport()
+	if politeness.proxy != nil || politeness.userAgent != "" || politeness.maxDelay > 0 {
+		proxy := ""
+		if politeness.proxy != nil {
+			proxy = politeness.proxy.Redacted()
+		}
+		logger.Info("Scraper politeness settings",
+			slog.Duration("delay_min", politeness.minDelay),
+			slog.Duration("delay_max", politeness.maxDelay),
+			slog.String("user_agent", politeness.userAgent),
+			slog.String("proxy", proxy))
+	}
+	if *useListCache {
+		listCache = loadListPageCache(paths.GetCachePath(listCacheFileName), logger)
+	}
+
 	// Start resource monitoring in background
 	go func() {
 		ticker := time.NewTicker(30 * time.Second)
@@ -146,6 +184,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *mode == modeCompanies {
+		runCompanyProfiles(*symbols, paths.GetTickerSummaryJSONPath(), paths.GetCompaniesCSVPath(), logger)
+		return
+	}
+
 	// determine fromSite depending on mode
 	var fromSite string
 	if *mode == "accumulative" {
@@ -210,6 +253,7 @@ func main() {
 
 	// Output for parsing by stages.go
 	slog.Info("Total expected files", "count", expectedFiles, "from", *fromStr, "to", *toStr)
+	progress.Start(expectedFiles, nil)
 
 	// Parse dates for scanning existing files
 	fromDateForScan, err := time.Parse("2006-01-02", expectedFromStr)
@@ -243,11 +287,48 @@ func main() {
 		
 		// Signal completion to stages.go
 		slog.Info("SCRAPER_COMPLETE: All required dates processed")
+		progress.Complete(existingFiles+existingHolidays, expectedFiles, "All required dates processed")
+		progress.Stop(events.StopReasonAlreadyPresent, fmt.Sprintf("%d reports and %d holidays already on disk", existingFiles, existingHolidays))
 		
 		// Exit successfully without launching browser
 		return
 	}
 
+	if batchMonths, err = parseBatch(*batch); err != nil {
+		logger.Error("invalid --batch", slog.String("batch", *batch))
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *useCheckpoint {
+		checkpoint = openCheckpoint(paths.GetCachePath(checkpointFileName), fromSite, toSite, batchMonths, time.Now(), logger)
+	}
+
+	if downloadLimiter = newBandwidthLimiter(*bandwidthKBps); downloadLimiter != nil {
+		logger.Info("Download bandwidth capped", slog.Int("kbps", *bandwidthKBps))
+	}
+
+	switch *engine {
+	case engineAuto, engineChrome, engineHTTP:
+	default:
+		logger.Error("invalid --engine", slog.String("engine", *engine))
+		fmt.Printf("Error: Invalid --engine %q (expected auto, chrome or http)\n", *engine)
+		os.Exit(1)
+	}
+
+	// Pass actual dates for progress tracking (if provided)
+	// These are only for progress calculation, not for stopping logic
+	if *actualFromStr != "" {
+		logger.Info("Actual from date for progress", slog.String("actual_from", *actualFromStr))
+	}
+	if *actualToStr != "" {
+		logger.Info("Actual to date for progress", slog.String("actual_to", *actualToStr))
+	}
+
+	if *engine == engineHTTP {
+		runHTTPEngine(fromSite, toSite, *outDir, logger, expectedFiles, *actualFromStr, *actualToStr)
+		return
+	}
+
 	// setup ChromeDP
 	opts := chromedp.DefaultExecAllocatorOptions[:]
 	if *headless {
@@ -255,6 +336,10 @@ func main() {
 	} else {
 		opts = append(opts, chromedp.Flag("headless", false))
 	}
+	opts = append(opts, politeness.chromeOptions()...)
+	if execPath := managedBrowser(paths, cfg.Scraper.Browser, logger); execPath != "" {
+		opts = append(opts, chromedp.ExecPath(execPath))
+	}
 
 	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
 	defer cancel()
@@ -262,13 +347,19 @@ func main() {
 	ctx, cancelCtx := chromedp.NewContext(allocCtx)
 	defer cancelCtx()
 
-	// Pass actual dates for progress tracking (if provided)
-	// These are only for progress calculation, not for stopping logic
-	if *actualFromStr != "" {
-		logger.Info("Actual from date for progress", slog.String("actual_from", *actualFromStr))
-	}
-	if *actualToStr != "" {
-		logger.Info("Actual to date for progress", slog.String("actual_to", *actualToStr))
+	// Running no actions only launches the browser, which tells a missing
+	// Chrome apart from a scraping failure
+	if err := chromedp.Run(ctx); err != nil {
+		if *engine == engineChrome {
+			logger.Error("failed to launch Chrome", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		logger.Warn("Chrome unavailable, falling back to HTTP engine", slog.String("error", err.Error()))
+		slog.Info("Chrome unavailable, using HTTP engine")
+		cancelCtx()
+		cancel()
+		runHTTPEngine(fromSite, toSite, *outDir, logger, expectedFiles, *actualFromStr, *actualToStr)
+		return
 	}
 
 	if err := chromedp.Run(ctx, runScraper(fromSite, toSite, *outDir, logger, expectedFiles, *actualFromStr, *actualToStr)); err != nil {
@@ -280,6 +371,62 @@ func main() {
 	logger.Info("Scraper finished")
 }
 
+// managedBrowser returns the managed Chromium to launch when no Chrome is
+// installed, downloading it on first use. It returns "" when an installed
+// Chrome is found, leaving the choice to chromedp, or when the managed build
+// is unavailable, which the launch check then handles.
+func managedBrowser(paths *config.Paths, cfg config.BrowserConfig, logger *slog.Logger) string {
+	if _, err := browser.FindSystem(); err == nil {
+		return ""
+	}
+	fetcher := browser.NewFetcher(paths.BrowserDir, cfg, logger)
+	if _, installed := fetcher.Installed(); !installed && fetcher.Enabled() {
+		progress.Status("Downloading Chromium " + fetcher.Version() + " for the scraper")
+	}
+	execPath, err := fetcher.Ensure(context.Background())
+	if err != nil {
+		logger.Warn("Managed browser unavailable", slog.String("error", err.Error()))
+		return ""
+	}
+	logger.Info("Using managed browser", slog.String("path", execPath))
+	return execPath
+}
+
+// runHTTPEngine scrapes with the HTTP engine and exits on failure
+func runHTTPEngine(fromSite, toSite, outDir string, logger *slog.Logger, expectedFiles int, actualFromStr, actualToStr string) {
+	logger.Info("Using HTTP scraping engine", slog.String("url", startURL))
+	engine := newHTTPEngine(startURL, logger)
+	if err := engine.run(context.Background(), fromSite, toSite, outDir, expectedFiles, actualFromStr, actualToStr); err != nil {
+		logger.Error("scraping failed", slog.String("engine", engineHTTP), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	logger.Info("Scraper finished")
+}
+
+// runCompanyProfiles scrapes company profile pages into the companies
+// reference CSV and exits on failure
+func runCompanyProfiles(symbolList, tickerSummaryPath, outPath string, logger *slog.Logger) {
+	symbols, err := companySymbols(symbolList, tickerSummaryPath)
+	if err != nil {
+		logger.Error("failed to determine company symbols", slog.String("error", err.Error()))
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(symbols) == 0 {
+		fmt.Println("No tickers to scrape: pass --symbols or run the processor first")
+		return
+	}
+
+	logger.Info("Scraping company profiles",
+		slog.Int("symbols", len(symbols)),
+		slog.String("output", outPath))
+	if _, err := newCompanyScraper(companyProfileURL, logger).run(context.Background(), symbols, outPath); err != nil {
+		logger.Error("company profile scraping failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	logger.Info("Scraper finished")
+}
+
 // scanExistingFiles scans the output directory for existing Excel files within the date range
 func scanExistingFiles(outDir string, fromDate, toDate time.Time, logger *slog.Logger) (filesFound int, holidaysDetected int) {
 	pattern := filepath.Join(outDir, "*.xlsx")
@@ -318,87 +465,106 @@ func scanExistingFiles(outDir string, fromDate, toDate time.Time, logger *slog.L
 			}
 			lastDate = &fileDate
 			
-			// Log for stages.go to parse
 			slog.Info("Already exists", "file", fname)
+			progress.Progress(filesFound, 0, fname, events.ItemStatusExists)
 		}
 	}
 	
 	return filesFound, holidaysDetected
 }
 
+// runScraper searches the range batch by batch in Chrome and walks every results page
 func runScraper(fromSite, toSite, outDir string, logger *slog.Logger, expectedFiles int, actualFromStr, actualToStr string) chromedp.Tasks {
-	// Track progress
-	totalDownloaded := 0
-	totalExisting := 0
-	filesInRange := 0      // Files within actual date range
-	holidaysInRange := 0   // Holidays within actual date range
-	var lastProcessedDate *time.Time // Track for holiday detection
+	return chromedp.Tasks{
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			ranges, err := batchRanges(fromSite, toSite, batchMonths, time.Now())
+			if err != nil {
+				return err
+			}
+
+			// Track progress across the searches
+			var counters scrapeCounters
+			return scrapeRanges(ctx, ranges, &counters, logger, func(ctx context.Context, r siteRange) (bool, error) {
+				if handled, complete, err := replayCachedRange(ctx, r, outDir, logger, &counters, expectedFiles, actualFromStr, actualToStr); handled {
+					return complete, err
+				}
+				return scrapeRange(ctx, r, outDir, logger, &counters, expectedFiles, actualFromStr, actualToStr)
+			})
+		}),
+	}
+}
+
+// scrapeRange submits the search form for one range and walks its results pages
+func scrapeRange(ctx context.Context, r siteRange, outDir string, logger *slog.Logger, c *scrapeCounters, expectedFiles int, actualFromStr, actualToStr string) (bool, error) {
 	actions := []chromedp.Action{
 		timedAction("Navigate", chromedp.Navigate(startURL)),
 		chromedp.WaitVisible(`#date`, chromedp.ByID),
-		chromedp.SetValue(`#date`, fromSite, chromedp.ByID),
+		chromedp.SetValue(`#date`, r.From, chromedp.ByID),
 	}
-	if toSite != "" {
-		actions = append(actions, chromedp.SetValue(`#toDate`, toSite, chromedp.ByID))
+	if r.To != "" {
+		actions = append(actions, chromedp.SetValue(`#toDate`, r.To, chromedp.ByID))
 	}
 	actions = append(actions,
 		chromedp.SetValue(`#reporttype`, "40", chromedp.ByID),
 		timedAction("ExecuteSearch", chromedp.Click(`/html/body/div[2]/div/div[3]/div[3]/div[2]/div[4]/div/div[1]/form/div[8]/input`, chromedp.BySearch)),
 		chromedp.WaitVisible(`#report`, chromedp.ByID),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			page := 1
-			for {
-				slog.Info("Scraping page", "page", page)
-				logger.Info("Scraping page", slog.Int("page", page))
-				_, _, shouldContinue, err := scrapePage(ctx, outDir, logger, &totalDownloaded, &totalExisting, &filesInRange, &holidaysInRange, expectedFiles, actualFromStr, actualToStr, &lastProcessedDate)
-				if err != nil {
-					return err
-				}
-				if !shouldContinue {
-					slog.Info("Found existing files, stopping scraping process", "page", page)
-					logger.Info("Found existing files, stopping scraping", slog.Int("page", page))
-					return nil
-				}
-				// Check if we've accounted for all expected files
-				if (filesInRange + holidaysInRange) >= expectedFiles {
-					logger.Info("Completion criteria met",
-						slog.Int("files_in_range", filesInRange),
-						slog.Int("holidays_in_range", holidaysInRange),
-						slog.Int("total_accounted", filesInRange + holidaysInRange),
-						slog.Int("expected_files", expectedFiles))
-					// Signal completion
-					slog.Info("SCRAPER_COMPLETE: All required dates processed")
-					return nil
-				}
-				
-				// check if next arrow exists
-				var nextHref string
-				var ok bool
-				err = chromedp.Run(ctx, chromedp.AttributeValue(`a img[src*='next.gif']`, "src", &nextHref, &ok))
-				if err != nil || !ok {
-					// No next arrow or not clickable
-					return nil
-				}
-				// Click the parent anchor of the img
-				if err := chromedp.Click(`a img[src*='next.gif']`, chromedp.ByQuery).Do(ctx); err != nil {
-					return nil // assume finished when can't click
-				}
-				// wait for table refresh
-				if err := chromedp.WaitVisible(`#report`, chromedp.ByID).Do(ctx); err != nil {
-					return err
-				}
-				logger.Debug("Page processed", 
-					slog.Int("page", page),
-					slog.Duration("duration", time.Since(time.Now())))
-				page++
-			}
-		}),
 	)
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return false, err
+	}
 
-	return chromedp.Tasks(actions)
+	page := 1
+	for {
+		slog.Info("Scraping page", "page", page)
+		logger.Info("Scraping page", slog.Int("page", page))
+		progress.Status(fmt.Sprintf("Scanning page %d", page))
+		_, _, shouldContinue, err := scrapePage(ctx, r, page, outDir, logger, &c.totalDownloaded, &c.totalExisting, &c.filesInRange, &c.holidaysInRange, expectedFiles, actualFromStr, actualToStr, &c.lastProcessedDate, &c.stopReason)
+		if err != nil {
+			return false, err
+		}
+		if !shouldContinue {
+			slog.Info("Found existing files, stopping scraping process", "page", page)
+			logger.Info("Found existing files, stopping scraping", slog.Int("page", page))
+			return c.complete(expectedFiles), nil
+		}
+		// Check if we've accounted for all expected files
+		if c.complete(expectedFiles) {
+			logger.Info("Completion criteria met",
+				slog.Int("files_in_range", c.filesInRange),
+				slog.Int("holidays_in_range", c.holidaysInRange),
+				slog.Int("total_accounted", c.filesInRange+c.holidaysInRange),
+				slog.Int("expected_files", expectedFiles))
+			// Signal completion
+			slog.Info("SCRAPER_COMPLETE: All required dates processed")
+			progress.Complete(c.filesInRange+c.holidaysInRange, expectedFiles, "All required dates processed")
+			return true, nil
+		}
+
+		// check if next arrow exists
+		var nextHref string
+		var ok bool
+		err = chromedp.Run(ctx, chromedp.AttributeValue(`a img[src*='next.gif']`, "src", &nextHref, &ok))
+		if err != nil || !ok {
+			// No next arrow or not clickable
+			listCache.markLast(r, page)
+			return false, nil
+		}
+		// Click the parent anchor of the img
+		if err := chromedp.Click(`a img[src*='next.gif']`, chromedp.ByQuery).Do(ctx); err != nil {
+			return false, nil // assume finished when can't click
+		}
+		// wait for table refresh
+		if err := chromedp.WaitVisible(`#report`, chromedp.ByID).Do(ctx); err != nil {
+			return false, err
+		}
+		logger.Debug("Page processed",
+			slog.Int("page", page),
+			slog.Duration("duration", time.Since(time.Now())))
+		page++
+	}
 }
 
-func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDownloaded, totalExisting, filesInRange, holidaysInRange *int, expectedFiles int, actualFromStr, actualToStr string, lastProcessedDate **time.Time) (int, int, bool, error) {
+func scrapePage(ctx context.Context, r siteRange, page int, outDir string, logger *slog.Logger, totalDownloaded, totalExisting, filesInRange, holidaysInRange *int, expectedFiles int, actualFromStr, actualToStr string, lastProcessedDate **time.Time, stopReason *string) (int, int, bool, error) {
 	// Add panic recovery for this function
 	defer func() {
 		if r := recover(); r != nil {
@@ -411,6 +577,35 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 		}
 	}()
 	
+	// Retrieve rows data: href, date text, type text
+	var rows []reportRow
+
+	js := `Array.from(document.querySelectorAll('#report tbody tr')).map(tr => {
+		const link = tr.querySelector('td.report-download a');
+		if (!link) return null;
+		const dateCell = tr.querySelector('td.report-titledata1');
+		const typeCell = tr.querySelector('td.report-titledata3');
+		return {href: link.getAttribute('href'), date: dateCell ? dateCell.innerText.trim() : '', typ: typeCell ? typeCell.innerText.trim() : ''};
+	}).filter(Boolean)`
+
+	if err := chromedp.Run(ctx, chromedp.Evaluate(js, &rows)); err != nil {
+		return 0, 0, false, err
+	}
+	listCache.store(r, page, cachedListPage{Rows: rows})
+
+	return processRows(ctx, rows, outDir, logger, totalDownloaded, totalExisting, filesInRange, holidaysInRange, expectedFiles, actualFromStr, actualToStr, lastProcessedDate, stopReason)
+}
+
+// reportRow is one row of the uploadedFilesList report table
+type reportRow struct {
+	Href string `json:"href"`
+	Date string `json:"date"`
+	Typ  string `json:"typ"`
+}
+
+// processRows downloads the daily reports listed on one results page and
+// updates the running counters. It is shared by the Chrome and HTTP engines.
+func processRows(ctx context.Context, rows []reportRow, outDir string, logger *slog.Logger, totalDownloaded, totalExisting, filesInRange, holidaysInRange *int, expectedFiles int, actualFromStr, actualToStr string, lastProcessedDate **time.Time, stopReason *string) (int, int, bool, error) {
 	// Parse actual dates for boundary and range checking
 	var actualFromDate *time.Time
 	var actualToDate *time.Time
@@ -424,7 +619,7 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 			actualToDate = &parsedDate
 		}
 	}
-	
+
 	// Helper to check if a date is within the actual range
 	isDateInRange := func(t time.Time) bool {
 		if actualFromDate != nil && t.Before(*actualFromDate) {
@@ -435,7 +630,7 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 		}
 		return true
 	}
-	
+
 	// Add progress checkpoint every 5 files
 	totalProcessed := *totalDownloaded + *totalExisting
 	if totalProcessed > 0 && totalProcessed%5 == 0 {
@@ -447,25 +642,6 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 			slog.Int("expected", expectedFiles),
 			slog.Float64("percentage", progressPct))
 	}
-	
-	// Retrieve rows data: href, date text, type text
-	var rows []struct {
-		Href string `json:"href"`
-		Date string `json:"date"`
-		Typ  string `json:"typ"`
-	}
-
-	js := `Array.from(document.querySelectorAll('#report tbody tr')).map(tr => {
-		const link = tr.querySelector('td.report-download a');
-		if (!link) return null;
-		const dateCell = tr.querySelector('td.report-titledata1');
-		const typeCell = tr.querySelector('td.report-titledata3');
-		return {href: link.getAttribute('href'), date: dateCell ? dateCell.innerText.trim() : '', typ: typeCell ? typeCell.innerText.trim() : ''};
-	}).filter(Boolean)`
-
-	if err := chromedp.Run(ctx, chromedp.Evaluate(js, &rows)); err != nil {
-		return 0, 0, false, err
-	}
 
 	foundExistingFiles := 0
 	newDownloads := 0
@@ -507,6 +683,7 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 						// Check if this holiday is in our actual date range
 						if isDateInRange(d) {
 							*holidaysInRange++
+							progress.Holiday(d)
 							logger.Info("Detected holiday in range",
 								slog.String("date", d.Format("2006-01-02")),
 								slog.Int("holidays_in_range", *holidaysInRange))
@@ -526,7 +703,7 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 		}
 
 		destPath := filepath.Join(outDir, fname)
-		if _, err := os.Stat(destPath); err == nil {
+		if _, statErr := os.Stat(destPath); statErr == nil && verifyExisting(destPath, logger) {
 			foundExistingFiles++
 			*totalExisting++
 			// Check if this existing file is in range
@@ -536,6 +713,7 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 			totalFiles := *totalDownloaded + *totalExisting
 			progressMsg := fmt.Sprintf("File %d of %d already exists, skipping", totalFiles, expectedFiles)
 			slog.Info(progressMsg, "file", fname)
+			reportProgress(totalFiles, expectedFiles, fname, events.ItemStatusExists, t, err)
 			logger.Debug("File already exists", 
 				slog.String("file", fname),
 				slog.Int("total_processed", totalFiles),
@@ -549,12 +727,13 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 		totalFiles := *totalDownloaded + *totalExisting
 		progressMsg := fmt.Sprintf("Downloading file %d of %d", totalFiles, expectedFiles)
 		slog.Info(progressMsg, "file", fname)
+		reportProgress(totalFiles, expectedFiles, fname, events.ItemStatusDownloading, t, err)
 		logger.Info("Downloading file", 
 			slog.String("file", fname),
 			slog.Int("file_number", totalFiles),
 			slog.Int("expected_files", expectedFiles))
 		
-		if err := downloadFile(fullURL, destPath); err != nil {
+		if err := downloadVerified(ctx, fullURL, destPath, logger); err != nil {
 			slog.Error("Failed to download file", "file", fname, "error", err)
 			logger.Error("Failed to download file", 
 				slog.String("file", fname),
@@ -592,6 +771,8 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 				slog.Int("files_existing", foundExistingFiles),
 				slog.Int("files_in_range", *filesInRange),
 				slog.Int("holidays_in_range", *holidaysInRange))
+			progress.BufferZone(t, fmt.Sprintf("Reached reports before %s", actualFromDate.Format("2006-01-02")))
+			*stopReason = events.StopReasonBufferZone
 			
 			// Check if we have accounted for all expected files
 			if (*filesInRange + *holidaysInRange) >= expectedFiles {
@@ -602,6 +783,7 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 					slog.Int("expected_files", expectedFiles))
 				// Signal completion
 				slog.Info("SCRAPER_COMPLETE: All required dates processed")
+				progress.Complete(*filesInRange+*holidaysInRange, expectedFiles, "All required dates processed")
 			}
 			
 			return newDownloads, foundExistingFiles, false, nil // Stop scraping
@@ -634,12 +816,23 @@ func scrapePage(ctx context.Context, outDir string, logger *slog.Logger, totalDo
 		logger.Info("Found mostly existing files, considering stopping",
 			slog.Int("existing", foundExistingFiles),
 			slog.Int("new", newDownloads))
+		*stopReason = events.StopReasonExistingFiles
 		return newDownloads, foundExistingFiles, false, nil // Stop scraping
 	}
 
 	return newDownloads, foundExistingFiles, true, nil // Continue scraping
 }
 
+// reportProgress emits a progress event for a report, dated when its date
+// could be parsed (dateErr is nil)
+func reportProgress(current, total int, file, status string, date time.Time, dateErr error) {
+	if dateErr != nil {
+		progress.Progress(current, total, file, status)
+		return
+	}
+	progress.ProgressDay(current, total, file, status, date)
+}
+
 func downloadFile(url, dest string) error {
 	// Get default logger for detailed logging
 	logger := slog.Default()
@@ -648,7 +841,8 @@ func downloadFile(url, dest string) error {
 		slog.String("url", url),
 		slog.String("destination", dest))
 	
-	resp, err := http.Get(url)
+	client := &http.Client{Transport: scraperTransport}
+	resp, err := client.Get(url)
 	if err != nil {
 		logger.Error("HTTP GET failed",
 			slog.String("url", url),
@@ -679,7 +873,7 @@ func downloadFile(url, dest string) error {
 	}
 	defer out.Close()
 
-	written, err := io.Copy(out, resp.Body)
+	written, err := io.Copy(out, newThrottledReader(context.Background(), resp.Body, downloadLimiter))
 	if err != nil {
 		logger.Error("Failed to write file content",
 			slog.String("path", dest),
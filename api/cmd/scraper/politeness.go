@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"isxcli/internal/config"
+)
+
+// politeness is how the scraper paces downloads and identifies itself,
+// set from the scraper config section at startup. The zero value adds no
+// delay and keeps the default User-Agent and proxy settings.
+var politeness politenessSettings
+
+// scraperTransport carries every direct HTTP request of the scraper: report
+// downloads and the HTTP engine's page requests
+var scraperTransport http.RoundTripper = http.DefaultTransport
+
+type politenessSettings struct {
+	minDelay  time.Duration
+	maxDelay  time.Duration
+	userAgent string
+	proxy     *url.URL
+}
+
+// newPoliteness validates the scraper config. A delay_max below delay_min
+// (including an unset one) makes the delay fixed at delay_min.
+func newPoliteness(cfg config.ScraperConfig) (politenessSettings, error) {
+	proxy, err := cfg.ProxyURL()
+	if err != nil {
+		return politenessSettings{}, err
+	}
+	p := politenessSettings{
+		minDelay:  cfg.DelayMin,
+		maxDelay:  cfg.DelayMax,
+		userAgent: cfg.UserAgent,
+		proxy:     proxy,
+	}
+	if p.maxDelay < p.minDelay {
+		p.maxDelay = p.minDelay
+	}
+	return p, nil
+}
+
+// delay returns a random wait in [minDelay, maxDelay]
+func (p politenessSettings) delay() time.Duration {
+	if p.maxDelay <= p.minDelay {
+		return p.minDelay
+	}
+	return p.minDelay + time.Duration(rand.Int63n(int64(p.maxDelay-p.minDelay)+1))
+}
+
+// pause waits a jittered delay before the next download
+func (p politenessSettings) pause(ctx context.Context) error {
+	d := p.delay()
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// transport returns the round tripper for direct requests: the default
+// transport routed through the configured proxy, setting the User-Agent
+func (p politenessSettings) transport() http.RoundTripper {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if p.proxy != nil {
+		base.Proxy = http.ProxyURL(p.proxy)
+	}
+	if p.userAgent == "" {
+		return base
+	}
+	return &userAgentTransport{base: base, userAgent: p.userAgent}
+}
+
+// chromeOptions returns the allocator flags that give Chrome the same
+// User-Agent and proxy as the direct requests
+func (p politenessSettings) chromeOptions() []chromedp.ExecAllocatorOption {
+	var opts []chromedp.ExecAllocatorOption
+	if p.userAgent != "" {
+		opts = append(opts, chromedp.UserAgent(p.userAgent))
+	}
+	if p.proxy != nil {
+		// Chrome takes no credentials in --proxy-server
+		server := url.URL{Scheme: p.proxy.Scheme, Host: p.proxy.Host}
+		opts = append(opts, chromedp.ProxyServer(server.String()))
+	}
+	return opts
+}
+
+// userAgentTransport sets the User-Agent on requests that do not carry one
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"isxcli/pkg/contracts/events"
+)
+
+// siteDateLayout is the dd/mm/yyyy format of the portal's search form
+const siteDateLayout = "02/01/2006"
+
+// defaultChunkRetries is how often a month's search is attempted before the run fails
+const defaultChunkRetries = 3
+
+// chunkRetries is the number of attempts per searched range, set by --chunk-retries
+var chunkRetries = defaultChunkRetries
+
+// Batch sizes accepted by --batch
+const (
+	batchMonth   = "month"
+	batchQuarter = "quarter"
+)
+
+// batchMonths is the number of calendar months each search covers, set by --batch
+var batchMonths = 1
+
+// parseBatch returns the months a --batch size covers
+func parseBatch(size string) (int, error) {
+	switch size {
+	case batchMonth:
+		return 1, nil
+	case batchQuarter:
+		return 3, nil
+	}
+	return 0, fmt.Errorf("invalid batch size %q (expected month or quarter)", size)
+}
+
+// chunkRetryDelay is the wait before the first retry of a range; later
+// retries wait proportionally longer
+var chunkRetryDelay = 5 * time.Second
+
+// siteRange is one search of the report list, in the site's date format. An
+// empty To keeps the site's default end date.
+type siteRange struct {
+	From string `json:"from"`
+	To   string `json:"to,omitempty"`
+}
+
+// dates returns the first and last day of the range; an open end is today
+func (r siteRange) dates(now time.Time) (from, to time.Time) {
+	from, _ = time.Parse(siteDateLayout, r.From)
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if r.To != "" {
+		to, _ = time.Parse(siteDateLayout, r.To)
+	}
+	return from, to
+}
+
+// scrapeCounters is the progress of a scrape run shared across its searches
+type scrapeCounters struct {
+	totalDownloaded   int
+	totalExisting     int
+	filesInRange      int // Files within the actual date range
+	holidaysInRange   int // Holidays within the actual date range
+	lastProcessedDate *time.Time
+	stopReason        string // Why the last search stopped early, an events.StopReason*
+}
+
+// complete reports whether every expected file has been accounted for
+func (c *scrapeCounters) complete(expectedFiles int) bool {
+	return c.filesInRange+c.holidaysInRange >= expectedFiles
+}
+
+// monthRanges splits a search into calendar-month searches, newest first so
+// results keep the site's newest-to-oldest order across searches. Long ranges
+// produce result sets with hundreds of pages that the portal times out on.
+// A range within a single month is returned unchanged.
+func monthRanges(fromSite, toSite string, now time.Time) ([]siteRange, error) {
+	return batchRanges(fromSite, toSite, 1, now)
+}
+
+// batchRanges splits a search into batches of months calendar months
+// (aligned to quarters for 3), newest first. A range within a single batch
+// is returned unchanged.
+func batchRanges(fromSite, toSite string, months int, now time.Time) ([]siteRange, error) {
+	if months < 1 {
+		months = 1
+	}
+	from, err := time.Parse(siteDateLayout, fromSite)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %q: %w", fromSite, err)
+	}
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toSite != "" {
+		if to, err = time.Parse(siteDateLayout, toSite); err != nil {
+			return nil, fmt.Errorf("invalid to date %q: %w", toSite, err)
+		}
+	}
+
+	batchStart := func(d time.Time) time.Time {
+		month := time.Month((int(d.Month())-1)/months*months + 1)
+		return time.Date(d.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if !to.After(from) || batchStart(from).Equal(batchStart(to)) {
+		return []siteRange{{From: fromSite, To: toSite}}, nil
+	}
+
+	var ranges []siteRange
+	for end := to; !end.Before(from); {
+		start := batchStart(end)
+		if start.Before(from) {
+			start = from
+		}
+		ranges = append(ranges, siteRange{From: start.Format(siteDateLayout), To: end.Format(siteDateLayout)})
+		end = start.AddDate(0, 0, -1)
+	}
+	return ranges, nil
+}
+
+// scrapeRangeFunc searches one range and walks its result pages, reporting
+// whether every expected file has been accounted for
+type scrapeRangeFunc func(ctx context.Context, r siteRange) (complete bool, err error)
+
+// scrapeRanges runs the searches in order until the expected files are
+// accounted for. A failed search is retried from its first page with the
+// counters it started with, so rows seen in the failed attempt are not
+// counted twice. Each finished search is checkpointed; a run over the same
+// range skips the searches an interrupted run finished and continues with
+// its counters. Why the run stopped is reported as a stop event.
+func scrapeRanges(ctx context.Context, ranges []siteRange, counters *scrapeCounters, logger *slog.Logger, scrape scrapeRangeFunc) (err error) {
+	defer func() { reportStop(ctx, counters, err) }()
+
+	attempts := chunkRetries
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	// A single search has nothing to resume
+	batched := len(ranges) > 1
+	cp := checkpoint
+	if !batched {
+		cp = nil
+	}
+	if resumed := cp.resume(counters); resumed > 0 {
+		slog.Info("Resuming from checkpoint", "batches_done", resumed, "batches", len(ranges))
+		logger.Info("Resuming scrape from checkpoint",
+			slog.Int("batches_done", resumed),
+			slog.Int("batches", len(ranges)),
+			slog.Int("files_in_range", counters.filesInRange))
+	}
+
+	started := time.Now()
+	searched := 0
+	for i, r := range ranges {
+		from, to := r.dates(time.Now())
+		if cp.done(r) {
+			logger.Info("Skipping batch finished by an earlier run",
+				slog.String("from", r.From),
+				slog.String("to", r.To))
+			progress.Batch(i+1, len(ranges), events.BatchStatusSkipped, from, to)
+			continue
+		}
+		if batched {
+			slog.Info("Searching date range", "from", r.From, "to", r.To, "range", i+1, "ranges", len(ranges))
+			logger.Info("Searching date range",
+				slog.String("from", r.From),
+				slog.String("to", r.To),
+				slog.Int("range", i+1),
+				slog.Int("ranges", len(ranges)))
+			progress.Batch(i+1, len(ranges), events.BatchStatusStarted, from, to)
+		}
+
+		start := *counters
+		var complete bool
+		for attempt := 1; attempt <= attempts; attempt++ {
+			*counters = start
+			if complete, err = scrape(ctx, r); err == nil || ctx.Err() != nil {
+				break
+			}
+
+			logger.Warn("Date range search failed",
+				slog.String("from", r.From),
+				slog.String("to", r.To),
+				slog.Int("attempt", attempt),
+				slog.Int("attempts", attempts),
+				slog.String("error", err.Error()))
+			if attempt == attempts {
+				break
+			}
+
+			timer := time.NewTimer(chunkRetryDelay * time.Duration(attempt))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("search %s - %s failed after %d attempts: %w", r.From, r.To, attempts, err)
+		}
+		if complete {
+			counters.stopReason = events.StopReasonComplete
+			cp.clear()
+			return nil
+		}
+
+		if batched {
+			cp.markDone(r, counters)
+			searched++
+			reportBatchDone(logger, i+1, len(ranges), searched, time.Since(started), counters)
+			progress.Batch(i+1, len(ranges), events.BatchStatusDone, from, to)
+		}
+	}
+	cp.clear()
+	return nil
+}
+
+// reportBatchDone logs the combined progress after batch of batches, with
+// an estimate of the time left from the batches searched in this run
+func reportBatchDone(logger *slog.Logger, batch, batches, searched int, elapsed time.Duration, counters *scrapeCounters) {
+	remaining := time.Duration(0)
+	if searched > 0 {
+		remaining = elapsed / time.Duration(searched) * time.Duration(batches-batch)
+	}
+	slog.Info("Batch finished", "batch", batch, "batches", batches,
+		"files_in_range", counters.filesInRange, "holidays_in_range", counters.holidaysInRange,
+		"remaining", remaining.Round(time.Second).String())
+	logger.Info("Batch finished",
+		slog.Int("batch", batch),
+		slog.Int("batches", batches),
+		slog.Int("files_in_range", counters.filesInRange),
+		slog.Int("holidays_in_range", counters.holidaysInRange),
+		slog.Int("downloaded", counters.totalDownloaded),
+		slog.Duration("elapsed", elapsed),
+		slog.Duration("estimated_remaining", remaining))
+}
+
+// reportStop emits why a run stopped: cancellation, an error, the last early
+// stop of a search, or the end of the portal's results
+func reportStop(ctx context.Context, counters *scrapeCounters, err error) {
+	accounted := fmt.Sprintf("%d reports and %d holidays in range", counters.filesInRange, counters.holidaysInRange)
+	switch {
+	case ctx.Err() != nil:
+		progress.Stop(events.StopReasonCancelled, ctx.Err().Error())
+	case err != nil:
+		progress.Stop(events.StopReasonError, err.Error())
+	case counters.stopReason != "":
+		progress.Stop(counters.stopReason, accounted)
+	default:
+		progress.Stop(events.StopReasonEndOfResults, accounted)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/events"
+)
+
+func TestMonthRanges(t *testing.T) {
+	now := time.Date(2025, 8, 26, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []siteRange
+	}{
+		{
+			name: "within a month",
+			from: "01/01/2025", to: "06/01/2025",
+			want: []siteRange{{From: "01/01/2025", To: "06/01/2025"}},
+		},
+		{
+			name: "open end within the current month",
+			from: "10/08/2025",
+			want: []siteRange{{From: "10/08/2025"}},
+		},
+		{
+			name: "split newest first",
+			from: "15/03/2024", to: "10/06/2024",
+			want: []siteRange{
+				{From: "01/06/2024", To: "10/06/2024"},
+				{From: "01/05/2024", To: "31/05/2024"},
+				{From: "01/04/2024", To: "30/04/2024"},
+				{From: "15/03/2024", To: "31/03/2024"},
+			},
+		},
+		{
+			name: "open end runs to today",
+			from: "20/07/2025",
+			want: []siteRange{
+				{From: "01/08/2025", To: "26/08/2025"},
+				{From: "20/07/2025", To: "31/07/2025"},
+			},
+		},
+		{
+			name: "across a year",
+			from: "31/12/2024", to: "01/01/2025",
+			want: []siteRange{
+				{From: "01/01/2025", To: "01/01/2025"},
+				{From: "31/12/2024", To: "31/12/2024"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := monthRanges(tt.from, tt.to, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	ranges, err := monthRanges("01/01/2015", "31/12/2024", now)
+	require.NoError(t, err)
+	assert.Len(t, ranges, 120, "a decade is searched month by month")
+
+	_, err = monthRanges("2025-01-01", "", now)
+	assert.Error(t, err)
+}
+
+func TestBatchRangesByQuarter(t *testing.T) {
+	now := time.Date(2025, 8, 26, 10, 0, 0, 0, time.UTC)
+
+	got, err := batchRanges("15/02/2024", "10/11/2024", 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, []siteRange{
+		{From: "01/10/2024", To: "10/11/2024"},
+		{From: "01/07/2024", To: "30/09/2024"},
+		{From: "01/04/2024", To: "30/06/2024"},
+		{From: "15/02/2024", To: "31/03/2024"},
+	}, got)
+
+	got, err = batchRanges("01/07/2025", "", 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, []siteRange{{From: "01/07/2025"}}, got, "a range within one quarter is one search")
+
+	ranges, err := batchRanges("01/01/2010", "31/12/2025", 3, now)
+	require.NoError(t, err)
+	assert.Len(t, ranges, 64, "2010 to 2025 in quarters")
+
+	months, err := parseBatch(batchQuarter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, months)
+	_, err = parseBatch("year")
+	assert.Error(t, err)
+}
+
+func TestScrapeRanges(t *testing.T) {
+	chunkRetryDelay = time.Millisecond
+	defer func() { chunkRetryDelay = 5 * time.Second }()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ranges := []siteRange{{From: "01/02/2025", To: "28/02/2025"}, {From: "01/01/2025", To: "31/01/2025"}}
+
+	t.Run("retries a failed range from its starting counters", func(t *testing.T) {
+		var counters scrapeCounters
+		var searched []string
+		err := scrapeRanges(context.Background(), ranges, &counters, logger, func(ctx context.Context, r siteRange) (bool, error) {
+			searched = append(searched, r.From)
+			counters.filesInRange += 10
+			if r.From == "01/01/2025" && len(searched) == 2 {
+				return false, errors.New("timeout")
+			}
+			return false, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"01/02/2025", "01/01/2025", "01/01/2025"}, searched)
+		assert.Equal(t, 20, counters.filesInRange, "the failed attempt's rows are not counted")
+	})
+
+	t.Run("stops once complete", func(t *testing.T) {
+		var searched int
+		err := scrapeRanges(context.Background(), ranges, &scrapeCounters{}, logger, func(ctx context.Context, r siteRange) (bool, error) {
+			searched++
+			return true, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, searched)
+	})
+
+	t.Run("fails after the last attempt", func(t *testing.T) {
+		var attempts int
+		err := scrapeRanges(context.Background(), ranges, &scrapeCounters{}, logger, func(ctx context.Context, r siteRange) (bool, error) {
+			attempts++
+			return false, errors.New("timeout")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "search 01/02/2025 - 28/02/2025 failed after 3 attempts")
+		assert.Equal(t, defaultChunkRetries, attempts)
+	})
+}
+
+func TestScrapeRangesReportsStopReason(t *testing.T) {
+	chunkRetryDelay = time.Millisecond
+	defer func() { chunkRetryDelay = 5 * time.Second }()
+	defer func(p *events.ProgressEmitter) { progress = p }(progress)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ranges := []siteRange{{From: "01/02/2025", To: "28/02/2025"}, {From: "01/01/2025", To: "31/01/2025"}}
+
+	stopEvent := func(t *testing.T, out string) events.StepProgressEvent {
+		t.Helper()
+		var stops []events.StepProgressEvent
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			var ev events.StepProgressEvent
+			if line, ok := strings.CutPrefix(line, events.StepProgressPrefix); ok &&
+				json.Unmarshal([]byte(line), &ev) == nil && ev.Event == events.StepEventStop {
+				stops = append(stops, ev)
+			}
+		}
+		require.Len(t, stops, 1, "one stop event per run")
+		return stops[0]
+	}
+
+	tests := []struct {
+		name   string
+		scrape scrapeRangeFunc
+		reason string
+	}{
+		{"complete", func(ctx context.Context, r siteRange) (bool, error) { return true, nil }, events.StopReasonComplete},
+		{"end of results", func(ctx context.Context, r siteRange) (bool, error) { return false, nil }, events.StopReasonEndOfResults},
+		{"error", func(ctx context.Context, r siteRange) (bool, error) { return false, errors.New("timeout") }, events.StopReasonError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			progress = events.NewProgressEmitter(&buf, "scraping")
+			_ = scrapeRanges(context.Background(), ranges, &scrapeCounters{}, logger, tt.scrape)
+			assert.Equal(t, tt.reason, stopEvent(t, buf.String()).Reason)
+		})
+	}
+
+	t.Run("early stop of the last search", func(t *testing.T) {
+		var buf bytes.Buffer
+		progress = events.NewProgressEmitter(&buf, "scraping")
+		counters := &scrapeCounters{}
+		require.NoError(t, scrapeRanges(context.Background(), ranges, counters, logger, func(ctx context.Context, r siteRange) (bool, error) {
+			counters.filesInRange += 3
+			counters.stopReason = events.StopReasonBufferZone
+			return false, nil
+		}))
+		ev := stopEvent(t, buf.String())
+		assert.Equal(t, events.StopReasonBufferZone, ev.Reason)
+		assert.Equal(t, "6 reports and 0 holidays in range", ev.Message)
+	})
+}
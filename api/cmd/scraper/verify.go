@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// minReportSize is the smallest plausible daily report. Real reports are
+	// tens of KB; anything below this is an error page or a truncated transfer.
+	minReportSize = 4 * 1024
+	// maxDownloadAttempts is how many times a report is fetched before giving up
+	maxDownloadAttempts = 3
+	// corruptDirName is the folder under the downloads directory that holds
+	// reports that failed verification
+	corruptDirName = "corrupt"
+)
+
+// xlsxMagic is the ZIP local file header every xlsx file starts with
+var xlsxMagic = []byte("PK\x03\x04")
+
+// errCorruptReport is wrapped by every verification failure
+var errCorruptReport = errors.New("corrupt report")
+
+// retryDelay is the wait before the second download attempt; it doubles for
+// each further attempt. A variable so tests can shorten it.
+var retryDelay = 2 * time.Second
+
+// verifyReport checks that path is a readable xlsx workbook: large enough,
+// starting with the ZIP signature, openable by excelize and containing at
+// least one sheet with data
+func verifyReport(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() < minReportSize {
+		return fmt.Errorf("%w: %d bytes, expected at least %d", errCorruptReport, info.Size(), minReportSize)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, len(xlsxMagic))
+	_, err = io.ReadFull(f, header)
+	f.Close()
+	if err != nil || !bytes.Equal(header, xlsxMagic) {
+		return fmt.Errorf("%w: not an xlsx file", errCorruptReport)
+	}
+
+	wb, err := excelize.OpenFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCorruptReport, err)
+	}
+	defer wb.Close()
+
+	sheets := wb.GetSheetList()
+	if len(sheets) == 0 {
+		return fmt.Errorf("%w: workbook has no sheets", errCorruptReport)
+	}
+	for _, sheet := range sheets {
+		if rows, err := wb.GetRows(sheet); err == nil && len(rows) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: all sheets are empty", errCorruptReport)
+}
+
+// quarantineReport moves a report that failed verification into the corrupt
+// folder next to it, adding a timestamp so repeated failures are all kept.
+// It returns the new path.
+func quarantineReport(path string) (string, error) {
+	dir := filepath.Join(filepath.Dir(path), corruptDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create quarantine dir: %w", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".part")
+	ext := filepath.Ext(name)
+	dest := filepath.Join(dir, fmt.Sprintf("%s.%s%s", strings.TrimSuffix(name, ext), time.Now().Format("20060102T150405.000"), ext))
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("quarantine %s: %w", path, err)
+	}
+	return dest, nil
+}
+
+// downloadVerified waits the configured politeness delay, downloads url to
+// dest through a temporary file, verifies it and only then moves it into place. A download that fails verification is
+// quarantined and fetched again, up to maxDownloadAttempts times.
+func downloadVerified(ctx context.Context, url, dest string, logger *slog.Logger) error {
+	if err := politeness.pause(ctx); err != nil {
+		return err
+	}
+
+	tmp := dest + ".part"
+	delay := retryDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := downloadFile(url, tmp); err != nil {
+			os.Remove(tmp)
+			lastErr = err
+			logger.Warn("Download attempt failed",
+				slog.String("file", filepath.Base(dest)),
+				slog.Int("attempt", attempt),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		if err := verifyReport(tmp); err != nil {
+			lastErr = err
+			quarantined, qErr := quarantineReport(tmp)
+			if qErr != nil {
+				os.Remove(tmp)
+				logger.Error("Failed to quarantine corrupt download",
+					slog.String("file", filepath.Base(dest)),
+					slog.String("error", qErr.Error()))
+			}
+			progress.Status(fmt.Sprintf("Corrupt download quarantined: %s (attempt %d of %d)", filepath.Base(dest), attempt, maxDownloadAttempts))
+			logger.Warn("Downloaded report failed verification",
+				slog.String("file", filepath.Base(dest)),
+				slog.Int("attempt", attempt),
+				slog.String("quarantined_to", quarantined),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		if err := os.Rename(tmp, dest); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("move %s into place: %w", dest, err)
+		}
+		if other, ok := downloadHashes.record(dest); ok {
+			logger.Warn("Downloaded report is identical to another date's report",
+				slog.String("file", filepath.Base(dest)),
+				slog.String("duplicate_of", filepath.Base(other)))
+		}
+		return nil
+	}
+	return fmt.Errorf("giving up on %s after %d attempts: %w", filepath.Base(dest), maxDownloadAttempts, lastErr)
+}
+
+// verifyExisting checks a report already on disk. A corrupt report is
+// quarantined so the caller downloads it again; it reports whether the file
+// is still usable.
+func verifyExisting(path string, logger *slog.Logger) bool {
+	err := verifyReport(path)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, errCorruptReport) {
+		logger.Warn("Unable to verify existing report",
+			slog.String("file", filepath.Base(path)),
+			slog.String("error", err.Error()))
+		return true
+	}
+	quarantined, qErr := quarantineReport(path)
+	if qErr != nil {
+		logger.Error("Failed to quarantine corrupt report",
+			slog.String("file", filepath.Base(path)),
+			slog.String("error", qErr.Error()))
+		return true
+	}
+	progress.Status(fmt.Sprintf("Existing report is corrupt, downloading again: %s", filepath.Base(path)))
+	logger.Warn("Existing report failed verification, downloading again",
+		slog.String("file", filepath.Base(path)),
+		slog.String("quarantined_to", quarantined),
+		slog.String("error", err.Error()))
+	return false
+}
+
+// reportHashes remembers the content hash of each report downloaded in this
+// run. ISX occasionally serves the same workbook under two dates; those
+// duplicates are logged so they can be checked by hand.
+type reportHashes struct {
+	mu     sync.Mutex
+	byHash map[string]string
+}
+
+var downloadHashes = &reportHashes{byHash: make(map[string]string)}
+
+// record hashes path and returns the earlier file with identical content, if any
+func (h *reportHashes) record(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if other, ok := h.byHash[key]; ok && other != path {
+		return other, true
+	}
+	h.byHash[key] = path
+	return "", false
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// testReportXLSX builds a small but valid daily report with label in A1
+func testReportXLSX(t *testing.T, label string) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	require.NoError(t, f.SetCellValue("Sheet1", "A1", label))
+	for row := 2; row <= 200; row++ {
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow("Sheet1", cell, &[]interface{}{"TICKER", row, float64(row) * 1.25}))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+	return buf.Bytes()
+}
+
+// reportLabel returns A1 of the workbook at path
+func reportLabel(t *testing.T, path string) string {
+	t.Helper()
+	f, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	defer f.Close()
+	v, err := f.GetCellValue("Sheet1", "A1")
+	require.NoError(t, err)
+	return v
+}
+
+func TestVerifyReport(t *testing.T) {
+	dir := t.TempDir()
+	valid := testReportXLSX(t, "ok")
+
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{"valid workbook", valid, false},
+		{"too small", []byte("PK\x03\x04"), true},
+		{"html error page", append([]byte("<html>"), bytes.Repeat([]byte(" "), minReportSize)...), true},
+		{"truncated", valid[:len(valid)/2], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".xlsx")
+			require.NoError(t, os.WriteFile(path, tt.content, 0644))
+			err := verifyReport(path)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errCorruptReport)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDownloadVerifiedRetriesCorruptDownloads(t *testing.T) {
+	retryDelay = time.Millisecond
+	defer func() { retryDelay = 2 * time.Second }()
+
+	valid := testReportXLSX(t, "good")
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Write(valid[:len(valid)/3])
+			return
+		}
+		w.Write(valid)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "2025 01 05 ISX Daily Report.xlsx")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	require.NoError(t, downloadVerified(context.Background(), server.URL, dest, logger))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, "good", reportLabel(t, dest))
+	assert.NoFileExists(t, dest+".part")
+
+	quarantined, err := filepath.Glob(filepath.Join(dir, corruptDirName, "2025 01 05 ISX Daily Report.*.xlsx"))
+	require.NoError(t, err)
+	assert.Len(t, quarantined, 1)
+}
+
+func TestDownloadVerifiedGivesUp(t *testing.T) {
+	retryDelay = time.Millisecond
+	defer func() { retryDelay = 2 * time.Second }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>Service Unavailable</html>"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "2025 01 05 ISX Daily Report.xlsx")
+	err := downloadVerified(context.Background(), server.URL, dest, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.ErrorIs(t, err, errCorruptReport)
+	assert.NoFileExists(t, dest)
+
+	quarantined, _ := filepath.Glob(filepath.Join(dir, corruptDirName, "*.xlsx"))
+	assert.Len(t, quarantined, maxDownloadAttempts)
+}
+
+func TestVerifyExistingQuarantinesCorruptReports(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	good := filepath.Join(dir, "2025 01 05 ISX Daily Report.xlsx")
+	require.NoError(t, os.WriteFile(good, testReportXLSX(t, "good"), 0644))
+	assert.True(t, verifyExisting(good, logger))
+	assert.FileExists(t, good)
+
+	bad := filepath.Join(dir, "2025 01 06 ISX Daily Report.xlsx")
+	require.NoError(t, os.WriteFile(bad, []byte("truncated"), 0644))
+	assert.False(t, verifyExisting(bad, logger))
+	assert.NoFileExists(t, bad)
+}
+
+func TestReportHashesDetectsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.xlsx")
+	b := filepath.Join(dir, "b.xlsx")
+	require.NoError(t, os.WriteFile(a, []byte("same"), 0644))
+	require.NoError(t, os.WriteFile(b, []byte("same"), 0644))
+
+	hashes := &reportHashes{byHash: make(map[string]string)}
+	_, dup := hashes.record(a)
+	assert.False(t, dup)
+	other, dup := hashes.record(b)
+	assert.True(t, dup)
+	assert.Equal(t, a, other)
+}
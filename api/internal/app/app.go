@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -18,14 +19,20 @@ import (
 	"syscall"
 	"time"
 
+	"isxcli/internal/audit"
+	"isxcli/internal/auth"
+	"isxcli/internal/backup"
 	"isxcli/internal/config"
 	"isxcli/internal/errors"
+	grpctransport "isxcli/internal/transport/grpc"
 	handlers "isxcli/internal/transport/http"
 	"isxcli/internal/infrastructure"
 	"isxcli/internal/license"
 	customMiddleware "isxcli/internal/middleware"
+	"isxcli/internal/migrations"
 	"isxcli/internal/operations"
 	"isxcli/internal/services"
+	"isxcli/internal/telemetry"
 	"isxcli/internal/updater"
 	ws "isxcli/internal/websocket"
 
@@ -68,22 +75,14 @@ type Application struct {
 	DataService     *services.DataService
 	HealthService   *services.HealthService
 	UpdateChecker   *updater.AutoUpdateChecker
+	LicenseBroadcaster *services.LicenseStatusBroadcaster // Pushes license status to WebSocket clients
 	Logger         *slog.Logger // Single slog instance per CLAUDE.md
 	Services        *ServiceContainer
 	OTelProviders   *infrastructure.OTelProviders // OpenTelemetry providers
 	FrontendFS      fs.FS // Embedded frontend filesystem
 	JobQueue        *operations.JobQueue // Async job queue for operations
-}
-
-// ServiceContainer holds all application services
-type ServiceContainer struct {
-	License  *license.Manager
-	LicenseService services.LicenseService
-	operation *services.OperationService
-	Data     *services.DataService
-	Health   *services.HealthService
-	WebSocket *ws.Hub
-	Liquidity *services.LiquidityService
+	AccessControl   *customMiddleware.AccessControl // Role enforcement from security.users_file
+	GRPCServer      *grpctransport.Server // nil unless grpc.enabled is set
 }
 
 // NewApplication creates a new application instance with dependency injection
@@ -100,10 +99,15 @@ func NewApplication(frontendFS fs.FS) (*Application, error) {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
 	
+	channel := updateChannel(cfg.Update.Channel, logger)
+	cfg.Update.Channel = string(channel)
+
 	// Log startup information
 	logger.Info("Application starting",
 		slog.String("name", AppName),
 		slog.String("version", VERSION),
+		slog.String("channel", cfg.Update.Channel),
+		slog.String("build_hash", buildHash()),
 		slog.String("executable", Executable))
 	
 	// Validate and log all paths at startup for debugging
@@ -128,8 +132,24 @@ func NewApplication(frontendFS fs.FS) (*Application, error) {
 			slog.String("action", "License activation will be required"))
 	}
 
+	// Data written by an older release is converted by the migration step,
+	// which a full pipeline runs first; until then readers may see old layouts
+	if pending, err := migrations.Pending(paths); err != nil {
+		logger.Warn("Failed to check data migrations", slog.String("error", err.Error()))
+	} else if len(pending) > 0 {
+		ids := make([]string, 0, len(pending))
+		for _, m := range pending {
+			ids = append(ids, m.ID)
+		}
+		logger.Warn("Data migration required",
+			slog.Any("migrations", ids),
+			slog.String("action", "Run the pipeline or the \"migration\" operation step"))
+	}
+
 	// Initialize OpenTelemetry
-	otelProviders, err := infrastructure.InitializeOTel(infrastructure.DefaultOTelConfig(), logger)
+	otelConfig := infrastructure.DefaultOTelConfig()
+	otelConfig.UpdateChannel = cfg.Update.Channel
+	otelProviders, err := infrastructure.InitializeOTel(otelConfig, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize OpenTelemetry: %w", err)
 	}
@@ -171,6 +191,20 @@ func NewApplication(frontendFS fs.FS) (*Application, error) {
 
 // initializeServices initializes all application services
 func (a *Application) initializeServices() error {
+	// User roles are enforced only when a users file is configured
+	var users *customMiddleware.Users
+	if a.Config.Security.UsersFile != "" {
+		loaded, err := customMiddleware.LoadUsers(a.Config.Security.UsersFile)
+		if err != nil {
+			return fmt.Errorf("failed to load users: %w", err)
+		}
+		users = loaded
+		a.Logger.Info("Role-based access control enabled",
+			slog.String("users_file", a.Config.Security.UsersFile),
+			slog.Int("users", users.Len()))
+	}
+	a.AccessControl = customMiddleware.NewAccessControl(users, a.Logger, errors.NewErrorHandler(a.Logger, false))
+
 	// Initialize license manager
 	licensePath := a.Config.GetLicenseFile()
 	licenseManager, err := license.NewManager(licensePath)
@@ -195,11 +229,46 @@ func (a *Application) initializeServices() error {
 	// Initialize job queue for async operations
 	jobStore := operations.NewMemoryJobStore()
 	manager := a.OperationService.GetManager()
+	// Operations sharing data directories queue behind each other
+	manager.GetConfig().MaxConcurrentOperations = a.Config.Operations.MaxConcurrent
+	manager.GetConfig().ConflictPolicy = operations.ConflictPolicy(strings.ToLower(a.Config.Operations.OnConflict))
 	a.JobQueue = operations.NewJobQueue(4, jobStore, manager, a.Logger) // 4 workers by default
-	
-	// Start the job queue
-	ctx := context.Background()
-	a.JobQueue.Start(ctx)
+	var usage *telemetry.Collector
+	if paths, err := config.GetPaths(); err == nil {
+		// Operations still running at shutdown are checkpointed here for resume
+		a.JobQueue.SetCheckpointDir(paths.OperationsDir)
+		// Each run lists the files it produced in operations/{id}/manifest.json
+		manager.SetArtifactRecorder(operations.NewArtifactRecorder(paths.OperationsDir, paths.DataDir, a.Logger))
+		// Finished runs are kept in operations/history.json for comparison
+		manager.SetHistory(operations.NewOperationHistory(filepath.Join(paths.OperationsDir, operations.HistoryFile), a.Config.Operations.HistoryRuns))
+		// Anonymous usage counters, sent with license validation only when
+		// telemetry.enabled opts in
+		usage = telemetry.NewCollector(filepath.Join(paths.DataDir, telemetry.FileName), a.Config.Telemetry.Enabled, a.Logger)
+		licenseManager.SetUsageReporter(usage)
+	}
+	a.JobQueue.OnJobFinished(func(job *operations.Job) {
+		switch job.Status {
+		case operations.JobStatusCompleted:
+			usage.Add(telemetry.CounterOperationsCompleted, 1)
+		case operations.JobStatusFailed:
+			usage.Add(telemetry.CounterOperationsFailed, 1)
+		case operations.JobStatusCancelled:
+			usage.Add(telemetry.CounterOperationsCancelled, 1)
+		}
+		if artifacts, err := manager.GetArtifacts(job.OperationID); err == nil {
+			usage.Add(telemetry.CounterFilesProcessed, int64(len(artifacts.Artifacts)))
+		}
+	})
+	manager.OnStepCompleted(func(stepID string) {
+		usage.Add(telemetry.CounterStepPrefix+stepID, 1)
+	})
+
+	// Operators are notified by mail or Telegram when operations finish or fail
+	notificationService := services.NewNotificationService(a.Config.Notifications, a.Logger)
+	a.JobQueue.OnJobFinished(func(job *operations.Job) {
+		finished := *job
+		go notificationService.NotifyJob(context.Background(), &finished)
+	})
 
 	// Initialize data service with injected logger
 	dataService, err := services.NewDataServiceWithLogger(a.Config, a.Logger)
@@ -208,6 +277,14 @@ func (a *Application) initializeServices() error {
 	}
 	a.DataService = dataService
 
+	// Processing, index and liquidity steps rewrite the reports the data
+	// service caches; drop the cache when one of them completes
+	manager.OnStepCompleted(func(stepID string) {
+		if stepID != operations.StageIDScraping {
+			dataService.InvalidateCache()
+		}
+	})
+
 	// Initialize health service with injected logger
 	healthService := services.NewHealthServiceWithBuildInfo(
 		VERSION,
@@ -220,10 +297,15 @@ func (a *Application) initializeServices() error {
 		a.WebSocketHub,
 		a.Logger,
 	)
+	healthService.SetReleaseInfo(services.ReleaseInfo{
+		Channel:         a.Config.Update.Channel,
+		BuildHash:       buildHash(),
+		FrontendVersion: frontendVersion(a.FrontendFS),
+	})
 	a.HealthService = healthService
 
 	// Initialize update checker
-	upd, err := updater.NewUpdater(VERSION, REPO_URL)
+	upd, err := updater.NewUpdaterWithChannel(VERSION, REPO_URL, updater.Channel(a.Config.Update.Channel))
 	if err != nil {
 		return fmt.Errorf("failed to initialize updater: %w", err)
 	}
@@ -231,13 +313,20 @@ func (a *Application) initializeServices() error {
 	updateChecker := updater.NewAutoUpdateChecker(upd, 24*time.Hour, func(info *updater.UpdateInfo) bool {
 		a.Logger.Info("Update available", 
 			slog.String("current", info.CurrentVersion), 
-			slog.String("latest", info.LatestVersion))
+			slog.String("latest", info.LatestVersion),
+			slog.String("channel", string(info.Channel)),
+			slog.Bool("prerelease", info.Prerelease))
 		return false // Don't auto-install
 	})
 	a.UpdateChecker = updateChecker
 
 	// Initialize license service
 	licenseService := services.NewLicenseService(licenseManager, a.Logger)
+	a.LicenseBroadcaster = services.NewLicenseStatusBroadcaster(licenseManager, hub, services.DefaultLicenseBroadcastInterval, a.Logger)
+	a.LicenseBroadcaster.OnTransition(func(ctx context.Context, event services.LicenseStatusEvent) {
+		_ = licenseService.InvalidateCache(ctx)
+		go notificationService.NotifyLicense(context.WithoutCancel(ctx), event)
+	})
 
 	// Get paths for liquidity service
 	paths, err := config.GetPaths()
@@ -248,18 +337,365 @@ func (a *Application) initializeServices() error {
 	// Initialize liquidity service
 	liquidityService := services.NewLiquidityService(paths.ReportsDir, a.Logger)
 
+	// Portfolios are valued against ticker prices and liquidity safe-trade sizes
+	portfolioService := services.NewPortfolioService(paths, liquidityService, a.Logger)
+
+	// Company profiles scraped in the scraper's companies mode
+	companyService := services.NewCompanyService(paths, a.Logger)
+
+	// Values in USD use the CBI rates held by the data service
+	exchangeRates := dataService.ExchangeRates()
+	liquidityService.SetExchangeRates(exchangeRates)
+	companyService.SetExchangeRates(exchangeRates)
+
+	// Ticker event feed merging reference events, suspensions and regime changes
+	timelineService := services.NewTimelineService(paths, liquidityService, a.Logger)
+
+	// Watchlist alert rules are checked against the reports the processing
+	// and liquidity steps rewrite
+	watchlistService := services.NewWatchlistService(paths, dataService, liquidityService, hub, notificationService, a.Logger)
+	manager.OnStepCompleted(func(stepID string) {
+		if stepID != operations.StageIDProcessing && stepID != operations.StageIDLiquidity {
+			return
+		}
+		go func() {
+			if _, err := watchlistService.Evaluate(context.Background()); err != nil {
+				a.Logger.Error("Watchlist evaluation failed", slog.String("error", err.Error()))
+			}
+		}()
+	})
+
+	// Export subscriptions run on trading days resolved from data/holidays.txt
+	calendarService := services.NewTradingCalendarService(paths, a.Logger)
+	healthService.ConfigureDetailedChecks(services.DetailedHealthOptions{
+		Paths:      paths,
+		Calendar:   calendarService,
+		Thresholds: a.Config.Health,
+	})
+	exportService := services.NewExportSubscriptionService(paths, calendarService, time.Minute, a.Logger)
+
+	// Pre-flight environment checks, also run by the doctor command
+	doctorOptions, err := services.DoctorOptionsFromConfig(a.Config, paths)
+	if err != nil {
+		return fmt.Errorf("failed to configure doctor checks: %w", err)
+	}
+	doctor := services.NewDoctor(doctorOptions)
+
+	// Market and sector index history extracted by indexcsv
+	indexService := services.NewIndexService(paths, a.Logger)
+
+	// Ticker return correlations and betas against ISX60 written by indexcsv
+	correlationService := services.NewCorrelationService(paths, a.Logger)
+
+	// Delta manifests written by the processor for incremental refreshes
+	changesService := services.NewChangesService(paths, a.Logger)
+
+	// Embedded release changelog and the data migrations still pending
+	changelogService := services.NewChangelogService(paths, VERSION, a.Logger)
+
+	// Named operation presets run through the job queue
+	templateService := services.NewOperationTemplateService(paths, a.Logger)
+
+	// Daily reports obtained outside the scraper, e.g. by email
+	uploadService := services.NewDailyReportUploadService(paths, a.Logger)
+
+	// Snapshots of the data directory, restored with the backup command
+	backups := backup.NewManager(paths, a.Config.Backup, a.Logger)
+
+	// Append-only record of the API calls that change state
+	var auditLog *audit.Log
+	if a.Config.Audit.Enabled {
+		auditLog = audit.NewLog(filepath.Join(paths.DataDir, audit.FileName), a.Logger)
+	}
+
+	// Web UI sign-in; sessions resolve to users like API tokens do
+	var accounts *auth.Accounts
+	var sessions *auth.Sessions
+	var oidc *auth.OIDC
+	if ac := a.Config.Auth; ac.Enabled {
+		sessions = auth.NewSessions(ac.SessionTTL, ac.SecureCookie)
+		if ac.Local.Enabled {
+			accounts = auth.NewAccounts(filepath.Join(paths.DataDir, auth.DirName, auth.AccountsFile), ac.Local.MinPasswordLength)
+			if tokenPath, err := accounts.SetupToken(); err != nil {
+				a.Logger.Error("Failed to prepare first account setup", slog.String("error", err.Error()))
+			} else if tokenPath != "" {
+				a.Logger.Warn("No local account yet; create the first admin with the setup token",
+					slog.String("setup_token_file", tokenPath))
+			}
+		}
+		if ac.OIDC.Enabled {
+			roles := make(map[string]customMiddleware.Role, len(ac.OIDC.Roles))
+			for email, role := range ac.OIDC.Roles {
+				roles[email] = customMiddleware.Role(role)
+			}
+			oidc = auth.NewOIDC(auth.OIDCConfig{
+				Issuer:         ac.OIDC.Issuer,
+				ClientID:       ac.OIDC.ClientID,
+				ClientSecret:   ac.OIDC.ClientSecret,
+				RedirectURL:    ac.OIDC.RedirectURL,
+				Scopes:         ac.OIDC.Scopes,
+				DefaultRole:    customMiddleware.Role(ac.OIDC.DefaultRole),
+				Roles:          roles,
+				AllowedDomains: ac.OIDC.AllowedDomains,
+			}, &http.Client{Timeout: 30 * time.Second})
+		}
+		a.AccessControl.SetSessions(sessions, ac.Required)
+		a.Logger.Info("Web UI sign-in enabled",
+			slog.Bool("local", accounts != nil),
+			slog.Bool("oidc", oidc != nil),
+			slog.Bool("required", ac.Required))
+	}
+
+	// Central license activation for organizations running many devices
+	fleetService := services.NewLicenseFleetService(paths.DataDir, licenseManager, a.Logger)
+
+	// Reports copied into data/downloads by hand start processing on their own
+	var downloadsWatcher *services.DownloadsWatcher
+	if a.Config.Processing.Watch {
+		downloadsWatcher = services.NewDownloadsWatcher(paths.DownloadsDir, a.Config.Processing.WatchInterval, OperationService, a.Logger)
+		downloadsWatcher.SetJobQueue(a.JobQueue)
+	}
+
 
 	// Create service container
-	a.Services = &ServiceContainer{
-		License:   licenseManager,
-		LicenseService: licenseService,
-		operation:  OperationService,
-		Data:      dataService,
-		Health:    healthService,
-		WebSocket: hub,
-		Liquidity: liquidityService,
+	a.Services = NewServiceContainer(a.Logger)
+	a.Services.License = licenseManager
+	a.Services.LicenseService = licenseService
+	a.Services.operation = OperationService
+	a.Services.Data = dataService
+	a.Services.Health = healthService
+	a.Services.WebSocket = hub
+	a.Services.Liquidity = liquidityService
+	a.Services.Portfolio = portfolioService
+	a.Services.Company = companyService
+	a.Services.Timeline = timelineService
+	a.Services.Calendar = calendarService
+	a.Services.Exports = exportService
+	a.Services.Watchlists = watchlistService
+	a.Services.Indices = indexService
+	a.Services.Changes = changesService
+	a.Services.Correlations = correlationService
+	a.Services.Changelog = changelogService
+	a.Services.Templates = templateService
+	a.Services.Uploads = uploadService
+	a.Services.Backups = backups
+	a.Services.Audit = auditLog
+	a.Services.Telemetry = usage
+	a.Services.Fleet = fleetService
+	a.Services.Accounts = accounts
+	a.Services.Sessions = sessions
+	a.Services.OIDC = oidc
+	a.Services.Watcher = downloadsWatcher
+	a.Services.Notifications = notificationService
+	a.Services.ExchangeRates = exchangeRates
+	a.Services.Doctor = doctor
+
+	// The optional gRPC API calls the same services as the REST routes
+	if a.Config.GRPC.Enabled {
+		grpcServer, err := grpctransport.NewServer(grpctransport.Options{
+			Data:       dataService,
+			Liquidity:  liquidityService,
+			Operations: OperationService,
+			Jobs:       a.JobQueue,
+			License:    licenseManager,
+			Users:      users,
+			Reflection: a.Config.GRPC.Reflection,
+		}, a.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to initialize gRPC server: %w", err)
+		}
+		a.GRPCServer = grpcServer
+	}
+
+	// Background subsystems start and stop through the container
+	if err := a.registerComponents(); err != nil {
+		return err
+	}
+	if err := a.Services.Validate(); err != nil {
+		return err
+	}
+	for _, check := range a.Services.HealthChecks() {
+		healthService.AddDetailedCheck(check)
+	}
+
+	return nil
+}
+
+// registerComponents registers the background subsystems with the service
+// container. Start runs their hooks in dependency order and Stop reverses it.
+func (a *Application) registerComponents() error {
+	components := []Component{
+		{
+			// The hub runs from construction so operations can broadcast early
+			Name: "websocket",
+			Stop: func(ctx context.Context) error {
+				a.WebSocketHub.Stop()
+				return nil
+			},
+			Health: func(ctx context.Context) services.HealthCheckResult {
+				clients := a.WebSocketHub.ClientCount()
+				return services.HealthCheckResult{
+					Name:     "websocket",
+					Severity: services.HealthSeverityOK,
+					Message:  fmt.Sprintf("%d clients connected", clients),
+					Details:  map[string]interface{}{"clients": clients},
+				}
+			},
+		},
+		{
+			// Stopped after the job queue, cancelling whatever is still running
+			Name:      "operations",
+			DependsOn: []string{"websocket"},
+			Stop: func(ctx context.Context) error {
+				return a.OperationService.CancelAll(ctx)
+			},
+		},
+		{
+			Name:      "job_queue",
+			DependsOn: []string{"operations"},
+			Start: func(ctx context.Context) error {
+				// Workers stop through Stop, which checkpoints running operations
+				a.JobQueue.Start(context.WithoutCancel(ctx))
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				a.Logger.InfoContext(ctx, "Stopping job queue")
+				return a.JobQueue.Stop(30 * time.Second)
+			},
+			Health: func(ctx context.Context) services.HealthCheckResult {
+				stats := a.JobQueue.GetQueueStats()
+				return services.HealthCheckResult{
+					Name:     "job_queue",
+					Severity: services.HealthSeverityOK,
+					Message:  fmt.Sprintf("%v active, %v queued", stats["active_jobs"], stats["queue_size"]),
+					Details:  stats,
+				}
+			},
+		},
+		{
+			Name: "update_checker",
+			Start: func(ctx context.Context) error {
+				go a.UpdateChecker.Start()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				a.UpdateChecker.Stop()
+				return nil
+			},
+		},
+		{
+			// Stopped before the hub, which would block its broadcasts
+			Name:      "license_broadcaster",
+			DependsOn: []string{"websocket"},
+			Start: func(ctx context.Context) error {
+				a.LicenseBroadcaster.Start(ctx)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				a.LicenseBroadcaster.Stop()
+				return nil
+			},
+		},
+		{
+			Name: "export_subscriptions",
+			Start: func(ctx context.Context) error {
+				a.Services.Exports.Start(ctx)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				a.Services.Exports.Stop()
+				return nil
+			},
+		},
+		{
+			Name: "exchange_rates",
+			Start: func(ctx context.Context) error {
+				a.Services.ExchangeRates.Start(ctx)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				a.Services.ExchangeRates.Stop()
+				return nil
+			},
+		},
+		{
+			Name:      "notifications",
+			DependsOn: []string{"job_queue", "license_broadcaster"},
+			Health: func(ctx context.Context) services.HealthCheckResult {
+				channels := a.Services.Notifications.Channels()
+				result := services.HealthCheckResult{
+					Name:     "notifications",
+					Severity: services.HealthSeverityOK,
+					Message:  "No notification channels configured",
+					Details:  map[string]interface{}{"channels": channels},
+				}
+				if len(channels) > 0 {
+					result.Message = "Sending to " + strings.Join(channels, ", ")
+				}
+				return result
+			},
+		},
+	}
+
+	if a.GRPCServer != nil {
+		components = append(components, Component{
+			// Stopped before the job queue its StartOperation calls feed
+			Name:      "grpc",
+			DependsOn: []string{"job_queue"},
+			Start: func(ctx context.Context) error {
+				lis, err := net.Listen("tcp", fmt.Sprintf(":%d", a.Config.GRPC.Port))
+				if err != nil {
+					return fmt.Errorf("failed to listen for gRPC: %w", err)
+				}
+				go func() {
+					if err := a.GRPCServer.Serve(lis); err != nil {
+						a.Logger.Error("gRPC server error", slog.String("error", err.Error()))
+					}
+				}()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				a.GRPCServer.Stop(ctx)
+				return nil
+			},
+		})
+	}
+
+	if a.Services.Telemetry != nil {
+		// Registered first so it starts first and stops last, saving the
+		// counts of operations that end on shutdown
+		components = append([]Component{{
+			Name: "telemetry",
+			Start: func(ctx context.Context) error {
+				a.Services.Telemetry.Start(context.WithoutCancel(ctx), a.Config.Telemetry.FlushInterval)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				return a.Services.Telemetry.Stop()
+			},
+		}}, components...)
 	}
 
+	if a.Services.Watcher != nil {
+		components = append(components, Component{
+			// Stopped before the operations it starts are cancelled
+			Name:      "downloads_watcher",
+			DependsOn: []string{"operations", "job_queue"},
+			Start: func(ctx context.Context) error {
+				return a.Services.Watcher.Start(ctx)
+			},
+			Stop: func(ctx context.Context) error {
+				a.Services.Watcher.Stop()
+				return nil
+			},
+		})
+	}
+
+	for _, component := range components {
+		if err := a.Services.Register(component); err != nil {
+			return fmt.Errorf("failed to register %s: %w", component.Name, err)
+		}
+	}
 	return nil
 }
 
@@ -274,7 +710,14 @@ func (a *Application) setupRouter() {
 
 	// WebSocket route with minimal middleware and tracing
 	// MUST be registered after minimal middleware but before the group
-	r.With(customMiddleware.WebSocketTraceMiddleware(a.Logger)).HandleFunc("/ws", a.handleWebSocket)
+	r.With(customMiddleware.WebSocketTraceMiddleware(a.Logger), a.AccessControl.Authenticate, a.AccessControl.RequireLogin()).HandleFunc("/ws", a.handleWebSocket)
+
+	// Public status for uptime monitors - outside the middleware group so it
+	// needs neither a license nor credentials
+	if a.Config.Server.EnableStatusJSON {
+		statusHandler := handlers.NewHealthHandler(a.HealthService, a.Logger)
+		r.With(customMiddleware.Recoverer(a.Logger)).Get("/status.json", statusHandler.PublicStatus)
+	}
 
 	// Serve static assets OUTSIDE middleware group to avoid license validation
 	if a.FrontendFS != nil {
@@ -318,7 +761,13 @@ func (a *Application) setupRouter() {
 		
 		// License validation
 		licenseValidator := customMiddleware.NewLicenseValidator(a.LicenseManager, a.Logger)
+		licenseValidator.SetSnapshotProvider(a.Services.LicenseService)
+		// Signing in comes before activating a license
+		licenseValidator.AddExcludePrefix("/api/auth/")
 		r.Use(licenseValidator.Handler)
+
+		// Callers are identified by API token; roles guard state changes below
+		r.Use(a.AccessControl.Authenticate)
 		
 		// Now register all other routes within this group
 		a.setupAPIRoutes(r)
@@ -342,6 +791,16 @@ func (a *Application) setupAPIRoutes(r chi.Router) {
 	// API routes with common middleware
 	r.Route("/api", func(r chi.Router) {
 		r.Use(render.SetContentType(render.ContentTypeJSON))
+		r.Use(customMiddleware.Profile)
+		r.Use(customMiddleware.FeatureUsage(a.Services.Telemetry))
+		// Cap every request body; endpoints taking JSON or files check
+		// tighter limits through the upload guard
+		bodyLimit := customMiddleware.NewUploadGuard(nil, a.Logger, errors.NewErrorHandler(a.Logger, false))
+		r.Use(bodyLimit.MaxBody(a.Config.Security.MaxBodyBytes))
+		// Record every state change with its caller and outcome
+		r.Use(customMiddleware.Audit(a.Services.Audit, a.Logger))
+		// With auth.required, only sign-in and liveness answer anonymous callers
+		r.Use(a.AccessControl.RequireLogin("/api/auth/", "/api/health", "/api/version", "/api/v1/system/version", "/api/license/status"))
 
 		// Apply standard timeout to most API endpoints
 		r.Group(func(r chi.Router) {
@@ -367,13 +826,90 @@ func (a *Application) setupAPIRoutes(r chi.Router) {
 			// Create error handler
 			errorHandler := errors.NewErrorHandler(a.Logger, false)
 
+			// Web UI sign-in
+			var authHandler *handlers.AuthHandler
+			if a.Services.Sessions != nil {
+				authHandler = handlers.NewAuthHandler(a.Services.Accounts, a.Services.Sessions, a.Services.OIDC, a.Logger, errorHandler)
+				r.Mount("/auth", authHandler.Routes())
+			}
+
 			// Data handler
-			dataHandler := handlers.NewDataHandler(a.DataService, a.Logger, errorHandler)
+			dataHandler := handlers.NewDataHandler(a.DataService, a.Logger, errorHandler).WithTimeline(a.Services.Timeline)
 			r.Mount("/data", dataHandler.Routes())
-			
+
 			// Liquidity handler
-			liquidityHandler := handlers.NewLiquidityHandler(a.Services.Liquidity, a.Logger)
+			liquidityHandler := handlers.NewLiquidityHandler(a.Services.Liquidity, a.Logger).WithCompanies(a.Services.Company)
 			liquidityHandler.RegisterRoutes(r)
+
+			// Upload guard shared by every endpoint that accepts a body or file;
+			// rejected content is kept in the request profile's quarantine
+			// directory for inspection
+			paths, err := config.GetPaths()
+			if err != nil {
+				a.Logger.Warn("Upload quarantine disabled", slog.String("error", err.Error()))
+			}
+			uploadGuard := customMiddleware.NewUploadGuard(paths, a.Logger, errorHandler)
+			maxJSON := a.Config.Security.MaxJSONBodyBytes
+			if maxJSON <= 0 {
+				maxJSON = 64 * 1024
+			}
+			jsonBody := uploadGuard.Limit(customMiddleware.UploadPolicy{
+				MaxSize: maxJSON,
+				Kinds:   []customMiddleware.UploadKind{customMiddleware.UploadKindJSON},
+			})
+
+			// Resuming only queues a job, so it lives with the standard-timeout routes
+			resumeHandler := handlers.NewOperationsHandler(a.OperationService, a.WebSocketHub, a.Logger)
+			resumeHandler.SetJobQueue(a.JobQueue)
+			templateHandler := handlers.NewOperationTemplateHandler(a.Services.Templates, a.WebSocketHub, a.Logger, errorHandler)
+			templateHandler.SetJobQueue(a.JobQueue)
+			uploadHandler := handlers.NewUploadHandler(a.Services.Uploads, a.WebSocketHub, a.Logger, errorHandler)
+			uploadHandler.SetJobQueue(a.JobQueue)
+			// Daily reports are small workbooks; the request body cap bounds them
+			maxReport := a.Config.Security.MaxBodyBytes
+			if maxReport <= 0 {
+				maxReport = 16 << 20
+			}
+			dailyReportFile := uploadGuard.Limit(customMiddleware.UploadPolicy{
+				MaxSize:   maxReport,
+				Kinds:     []customMiddleware.UploadKind{customMiddleware.UploadKindXLSX},
+				FormField: handlers.UploadFormField,
+			})
+
+			// Viewers only read; running operations needs an operator and
+			// changing system settings an admin
+			operatorWrites := a.AccessControl.RequireRole(customMiddleware.RoleOperator)
+			adminWrites := a.AccessControl.RequireRole(customMiddleware.RoleAdmin)
+
+			// Versioned resource routes
+			r.Route("/v1", func(r chi.Router) {
+				r.Mount("/tickers", dataHandler.TickerRoutes())
+				r.Get("/system/version", healthHandler.SystemVersion)
+				r.Get("/system/doctor", handlers.NewDoctorHandler(a.Services.Doctor, a.Logger).Run)
+				r.Get("/health/detailed", healthHandler.DetailedHealth)
+				r.Mount("/system/changelog", handlers.NewChangelogHandler(a.Services.Changelog, a.Logger, errorHandler).Routes())
+				r.With(jsonBody).Mount("/liquidity", liquidityHandler.HistoryRoutes())
+				r.Mount("/market", dataHandler.MarketRoutes())
+				r.Mount("/indices", handlers.NewIndexHandler(a.Services.Indices, a.Logger, errorHandler).Routes())
+				r.Mount("/changes", handlers.NewChangesHandler(a.Services.Changes, a.Logger, errorHandler).Routes())
+				r.Mount("/analytics", handlers.NewAnalyticsHandler(a.Services.Correlations, a.Logger, errorHandler).Routes())
+				if authHandler != nil {
+					r.With(jsonBody, a.AccessControl.RestrictTo(customMiddleware.RoleAdmin)).Mount("/auth/accounts", authHandler.AccountRoutes())
+				}
+				r.Mount("/companies", handlers.NewCompanyHandler(a.Services.Company, a.Logger, errorHandler).Routes())
+				r.With(operatorWrites).Mount("/operations", resumeHandler.ResumeRoutes())
+				r.With(jsonBody, operatorWrites).Mount("/operation-templates", templateHandler.Routes())
+				r.With(operatorWrites, dailyReportFile).Mount("/uploads", uploadHandler.Routes())
+				r.With(adminWrites).Mount("/backups", handlers.NewBackupHandler(a.Services.Backups, a.Logger, errorHandler).Routes())
+				r.With(a.AccessControl.RestrictTo(customMiddleware.RoleAdmin)).Mount("/audit", handlers.NewAuditHandler(a.Services.Audit, a.Logger, errorHandler).Routes())
+				r.With(jsonBody, a.AccessControl.RequireEnabled, a.AccessControl.RestrictTo(customMiddleware.RoleAdmin)).Mount("/admin/query", handlers.NewQueryHandler(a.DataService, a.Logger, errorHandler).Routes())
+				r.With(adminWrites).Mount("/telemetry", handlers.NewTelemetryHandler(a.Services.Telemetry, a.Logger, errorHandler).Routes())
+				r.With(adminWrites).Mount("/notifications", handlers.NewNotificationHandler(a.Services.Notifications, a.Logger, errorHandler).Routes())
+				r.With(jsonBody).Mount("/portfolios", handlers.NewPortfolioHandler(a.Services.Portfolio, a.Logger, errorHandler).Routes())
+				r.With(jsonBody).Mount("/subscriptions", handlers.NewSubscriptionHandler(a.Services.Exports, a.Logger, errorHandler).Routes())
+				r.With(jsonBody).Mount("/watchlists", handlers.NewWatchlistHandler(a.Services.Watchlists, a.Logger, errorHandler).Routes())
+				r.With(jsonBody).Mount("/exports", handlers.NewExportBundleHandler(a.DataService, a.Logger, errorHandler).Routes())
+			})
 			
 		})
 
@@ -381,11 +917,26 @@ func (a *Application) setupAPIRoutes(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			// Use operation-specific timeout (2 hours by default)
 			r.Use(customMiddleware.Timeout(a.Config.Server.OperationTimeout, a.Logger))
+			r.Use(a.AccessControl.RequireRole(customMiddleware.RoleOperator))
 			
 			OperationHandler := handlers.NewOperationsHandler(a.OperationService, a.WebSocketHub, a.Logger)
 			// Set the job queue for async operations
 			OperationHandler.SetJobQueue(a.JobQueue)
 			r.Mount("/operations", OperationHandler.Routes())
+
+			// Server-sent events alternative to the WebSocket for operation progress
+			OperationHandler.SetEventSource(a.WebSocketHub.Events())
+			r.Get("/v1/operations/{id}/events", OperationHandler.StreamOperationEvents)
+
+			// Batch license activation calls the license server once per
+			// device, so it outlives the standard request timeout too. Reads
+			// list keys and devices, so they need an admin as well.
+			fleetHandler := handlers.NewLicenseFleetHandler(a.Services.Fleet, a.Logger, errors.NewErrorHandler(a.Logger, false))
+			r.With(a.AccessControl.RestrictTo(customMiddleware.RoleAdmin)).Mount("/v1/license/fleet", fleetHandler.Routes())
+
+			// Large dataset streaming (Range + gzip) outlives the standard request timeout
+			streamHandler := handlers.NewDataHandler(a.DataService, a.Logger, errors.NewErrorHandler(a.Logger, false))
+			r.Mount("/data/stream", streamHandler.StreamRoutes())
 			
 			// Operation shortcuts with tracing - also need longer timeout
 			r.Post("/scrape", customMiddleware.PipelineTraceHandler("scrape", func(w http.ResponseWriter, r *http.Request) {
@@ -787,6 +1338,7 @@ func (a *Application) getCORSConfig() customMiddleware.CORSConfig {
 			"Accept",
 			"Authorization", 
 			"Content-Type",
+			"X-API-Key",
 			"X-CSRF-Token",
 			"X-Request-ID",
 			"X-Requested-With",
@@ -884,8 +1436,9 @@ func (a *Application) Start(ctx context.Context, cancel context.CancelFunc) erro
 		slog.String("license_file", paths.LicenseFile))
 
 	// Start background services
-	go a.WebSocketHub.Run()
-	go a.UpdateChecker.Start()
+	if err := a.Services.StartAll(ctx); err != nil {
+		return fmt.Errorf("failed to start services: %w", err)
+	}
 
 	// Start server
 	go func() {
@@ -985,22 +1538,9 @@ func (a *Application) Stop(ctx context.Context) error {
 		return fmt.Errorf("server shutdown error: %w", err)
 	}
 
-	// Stop background services
-	a.UpdateChecker.Stop()
-	a.WebSocketHub.Stop()
-	
-	// Stop job queue with timeout
-	if a.JobQueue != nil {
-		a.Logger.InfoContext(ctx, "Stopping job queue")
-		if err := a.JobQueue.Stop(30 * time.Second); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to stop job queue gracefully", slog.String("error", err.Error()))
-		}
-	}
-
-	// Cancel running operations
-	if err := a.OperationService.CancelAll(ctx); err != nil {
-		a.Logger.ErrorContext(ctx, "Error cancelling operations", slog.String("error", err.Error()))
-	}
+	// Stop background services in reverse start order; failures are logged
+	// by the container and do not block the remaining hooks
+	_ = a.Services.StopAll(ctx)
 
 	// Shutdown OpenTelemetry providers
 	if a.OTelProviders != nil {
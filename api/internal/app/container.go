@@ -0,0 +1,279 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/audit"
+	"isxcli/internal/auth"
+	"isxcli/internal/backup"
+	"isxcli/internal/license"
+	"isxcli/internal/services"
+	"isxcli/internal/telemetry"
+	ws "isxcli/internal/websocket"
+)
+
+// ServiceContainer holds all application services and the lifecycle of the
+// background subsystems among them.
+//
+// Services are still reached through the typed fields. Subsystems that run in
+// the background (the WebSocket hub, job queue, schedulers, broadcasters)
+// additionally Register a Component with start and stop hooks, the components
+// they depend on and an optional health check. Validate checks the
+// dependencies at boot; StartAll and StopAll then run the hooks in dependency
+// order, so a new subsystem only needs its own Register call.
+type ServiceContainer struct {
+	License        *license.Manager
+	LicenseService services.LicenseService
+	operation      *services.OperationService
+	Data           *services.DataService
+	Health         *services.HealthService
+	WebSocket      *ws.Hub
+	Liquidity      *services.LiquidityService
+	Portfolio      *services.PortfolioService
+	Company        *services.CompanyService
+	Timeline       *services.TimelineService
+	Calendar       *services.TradingCalendarService
+	Exports        *services.ExportSubscriptionService
+	Watchlists     *services.WatchlistService
+	Indices        *services.IndexService
+	Changes        *services.ChangesService
+	Correlations   *services.CorrelationService
+	Changelog      *services.ChangelogService
+	Templates      *services.OperationTemplateService
+	Uploads        *services.DailyReportUploadService
+	Backups        *backup.Manager
+	Audit          *audit.Log           // nil when audit.enabled is off
+	Telemetry      *telemetry.Collector // Usage counters; reported only when telemetry.enabled is set
+	Fleet          *services.LicenseFleetService
+	Accounts       *auth.Accounts             // nil unless auth.local is enabled
+	Sessions       *auth.Sessions             // nil unless auth.enabled is set
+	OIDC           *auth.OIDC                 // nil unless auth.oidc is enabled
+	Watcher        *services.DownloadsWatcher // nil unless processing.watch is set
+	Notifications  *services.NotificationService
+	ExchangeRates  *services.ExchangeRateService
+	Doctor         *services.Doctor
+
+	mu         sync.Mutex
+	components []*Component
+	order      []*Component // Start order, set by Validate
+	started    []*Component // Components whose Start hook succeeded
+	logger     *slog.Logger
+}
+
+// Component is a subsystem with a lifecycle managed by the ServiceContainer
+type Component struct {
+	// Name identifies the component in logs, errors and health checks
+	Name string
+	// DependsOn lists components that must start before and stop after this one
+	DependsOn []string
+	// Start launches the component; nil for components running from construction
+	Start func(ctx context.Context) error
+	// Stop shuts the component down; nil when there is nothing to release
+	Stop func(ctx context.Context) error
+	// Health contributes a check to /api/v1/health/detailed; optional
+	Health services.DetailedHealthCheckFunc
+}
+
+// NewServiceContainer creates an empty container
+func NewServiceContainer(logger *slog.Logger) *ServiceContainer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ServiceContainer{logger: logger.With(slog.String("component", "service_container"))}
+}
+
+// Register adds a component. Names must be unique; dependencies may be
+// registered later and are checked by Validate.
+func (c *ServiceContainer) Register(component Component) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if component.Name == "" {
+		return fmt.Errorf("component name is required")
+	}
+	for _, existing := range c.components {
+		if existing.Name == component.Name {
+			return fmt.Errorf("component %q is already registered", component.Name)
+		}
+	}
+	c.components = append(c.components, &component)
+	c.order = nil
+	return nil
+}
+
+// Components returns the registered component names in start order, or in
+// registration order before Validate has run
+func (c *ServiceContainer) Components() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list := c.order
+	if list == nil {
+		list = c.components
+	}
+	names := make([]string, len(list))
+	for i, component := range list {
+		names[i] = component.Name
+	}
+	return names
+}
+
+// Validate checks that every dependency is registered and that there are no
+// cycles, and fixes the start order. Components without a dependency between
+// them start in registration order.
+func (c *ServiceContainer) Validate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byName := make(map[string]*Component, len(c.components))
+	for _, component := range c.components {
+		byName[component.Name] = component
+	}
+
+	var problems []string
+	for _, component := range c.components {
+		for _, dep := range component.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				problems = append(problems, fmt.Sprintf("%s depends on unregistered component %s", component.Name, dep))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid service dependencies: %s", strings.Join(problems, "; "))
+	}
+
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[string]int, len(c.components))
+	order := make([]*Component, 0, len(c.components))
+	var visit func(component *Component, path []string) error
+	visit = func(component *Component, path []string) error {
+		switch state[component.Name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, component.Name), " -> "))
+		}
+		state[component.Name] = visiting
+		for _, dep := range component.DependsOn {
+			if err := visit(byName[dep], append(path, component.Name)); err != nil {
+				return err
+			}
+		}
+		state[component.Name] = done
+		order = append(order, component)
+		return nil
+	}
+	for _, component := range c.components {
+		if err := visit(component, nil); err != nil {
+			return fmt.Errorf("invalid service dependencies: %w", err)
+		}
+	}
+
+	c.order = order
+	return nil
+}
+
+// StartAll runs the start hooks in dependency order. If one fails, the
+// components already started are stopped again and the error is returned.
+func (c *ServiceContainer) StartAll(ctx context.Context) error {
+	if err := c.ensureOrder(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	order := c.order
+	c.mu.Unlock()
+
+	for _, component := range order {
+		if component.Start != nil {
+			begin := time.Now()
+			if err := component.Start(ctx); err != nil {
+				startErr := fmt.Errorf("start %s: %w", component.Name, err)
+				if stopErr := c.StopAll(ctx); stopErr != nil {
+					return errors.Join(startErr, stopErr)
+				}
+				return startErr
+			}
+			c.log().DebugContext(ctx, "Component started",
+				slog.String("name", component.Name),
+				slog.Duration("duration", time.Since(begin)))
+		}
+		c.mu.Lock()
+		c.started = append(c.started, component)
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// StopAll runs the stop hooks of started components in reverse start order.
+// Every hook runs even if an earlier one fails; the failures are returned
+// together.
+func (c *ServiceContainer) StopAll(ctx context.Context) error {
+	c.mu.Lock()
+	started := c.started
+	c.started = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		component := started[i]
+		if component.Stop == nil {
+			continue
+		}
+		if err := component.Stop(ctx); err != nil {
+			c.log().ErrorContext(ctx, "Component failed to stop",
+				slog.String("name", component.Name),
+				slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("stop %s: %w", component.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthChecks returns the health checks contributed by components, in start
+// order
+func (c *ServiceContainer) HealthChecks() []services.DetailedHealthCheckFunc {
+	if err := c.ensureOrder(); err != nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var checks []services.DetailedHealthCheckFunc
+	for _, component := range c.order {
+		if component.Health != nil {
+			checks = append(checks, component.Health)
+		}
+	}
+	return checks
+}
+
+// log returns the container's logger; containers built as literals use the default
+func (c *ServiceContainer) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
+
+// ensureOrder validates the container if components changed since the last
+// Validate
+func (c *ServiceContainer) ensureOrder() error {
+	c.mu.Lock()
+	validated := c.order != nil || len(c.components) == 0
+	c.mu.Unlock()
+	if validated {
+		return nil
+	}
+	return c.Validate()
+}
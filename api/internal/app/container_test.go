@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/services"
+)
+
+// recordingComponent registers a component that appends its start and stop to calls
+func recordingComponent(name string, calls *[]string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(ctx context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestServiceContainerLifecycleOrder(t *testing.T) {
+	var calls []string
+	c := NewServiceContainer(nil)
+	require.NoError(t, c.Register(recordingComponent("queue", &calls, "operations")))
+	require.NoError(t, c.Register(recordingComponent("hub", &calls)))
+	require.NoError(t, c.Register(recordingComponent("operations", &calls, "hub")))
+	require.NoError(t, c.Register(recordingComponent("updater", &calls)))
+
+	require.NoError(t, c.Validate())
+	assert.Equal(t, []string{"hub", "operations", "queue", "updater"}, c.Components())
+
+	require.NoError(t, c.StartAll(context.Background()))
+	require.NoError(t, c.StopAll(context.Background()))
+	assert.Equal(t, []string{
+		"start hub", "start operations", "start queue", "start updater",
+		"stop updater", "stop queue", "stop operations", "stop hub",
+	}, calls)
+
+	calls = nil
+	require.NoError(t, c.StopAll(context.Background()))
+	assert.Empty(t, calls, "stopped components are not stopped twice")
+}
+
+func TestServiceContainerValidate(t *testing.T) {
+	t.Run("duplicate name", func(t *testing.T) {
+		c := NewServiceContainer(nil)
+		require.NoError(t, c.Register(Component{Name: "hub"}))
+		assert.Error(t, c.Register(Component{Name: "hub"}))
+		assert.Error(t, c.Register(Component{}))
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		c := NewServiceContainer(nil)
+		require.NoError(t, c.Register(Component{Name: "queue", DependsOn: []string{"operations"}}))
+		err := c.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "queue depends on unregistered component operations")
+		assert.Error(t, c.StartAll(context.Background()))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		c := NewServiceContainer(nil)
+		require.NoError(t, c.Register(Component{Name: "a", DependsOn: []string{"b"}}))
+		require.NoError(t, c.Register(Component{Name: "b", DependsOn: []string{"c"}}))
+		require.NoError(t, c.Register(Component{Name: "c", DependsOn: []string{"a"}}))
+		err := c.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a -> b -> c -> a")
+	})
+}
+
+func TestServiceContainerStartFailureRollsBack(t *testing.T) {
+	var calls []string
+	c := NewServiceContainer(nil)
+	require.NoError(t, c.Register(recordingComponent("hub", &calls)))
+	require.NoError(t, c.Register(Component{
+		Name:      "scheduler",
+		DependsOn: []string{"hub"},
+		Start:     func(ctx context.Context) error { return errors.New("port in use") },
+	}))
+	require.NoError(t, c.Register(recordingComponent("store", &calls, "scheduler")))
+
+	err := c.StartAll(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start scheduler: port in use")
+	assert.Equal(t, []string{"start hub", "stop hub"}, calls)
+}
+
+func TestServiceContainerStopCollectsErrors(t *testing.T) {
+	var calls []string
+	c := NewServiceContainer(nil)
+	require.NoError(t, c.Register(recordingComponent("hub", &calls)))
+	require.NoError(t, c.Register(Component{
+		Name: "queue",
+		Stop: func(ctx context.Context) error { return errors.New("timeout") },
+	}))
+	require.NoError(t, c.StartAll(context.Background()))
+
+	err := c.StopAll(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stop queue: timeout")
+	assert.Equal(t, []string{"start hub", "stop hub"}, calls, "later hooks still run")
+}
+
+func TestServiceContainerHealthChecks(t *testing.T) {
+	c := NewServiceContainer(nil)
+	require.NoError(t, c.Register(Component{Name: "hub"}))
+	require.NoError(t, c.Register(Component{
+		Name: "queue",
+		Health: func(ctx context.Context) services.HealthCheckResult {
+			return services.HealthCheckResult{Name: "queue", Severity: services.HealthSeverityWarning}
+		},
+	}))
+
+	checks := c.HealthChecks()
+	require.Len(t, checks, 1)
+	assert.Equal(t, "queue", checks[0](context.Background()).Name)
+
+	hs := services.NewHealthServiceWithLogger("test", "", nil)
+	hs.ConfigureDetailedChecks(services.DetailedHealthOptions{})
+	hs.AddDetailedCheck(checks[0])
+	health := hs.DetailedHealthCheck(context.Background())
+	assert.Equal(t, "queue", health.Checks[len(health.Checks)-1].Name)
+	assert.NotEqual(t, services.HealthSeverityOK, health.Status)
+}
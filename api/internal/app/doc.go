@@ -28,6 +28,16 @@
 //	    log.Fatal(err)
 //	}
 //
+// # Service Container
+//
+// Background subsystems (the WebSocket hub, job queue, update checker,
+// license broadcaster, export scheduler) register a Component with the
+// ServiceContainer: start and stop hooks, the components they depend on and
+// an optional check for /api/v1/health/detailed. Dependencies are validated
+// when the application is built, Start runs the hooks in dependency order and
+// Stop runs them in reverse. A new subsystem adds its Register call in
+// registerComponents rather than editing Start and Stop.
+//
 // # Graceful Shutdown
 //
 // The package handles SIGINT and SIGTERM signals to ensure:
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"io/fs"
+	"log/slog"
+	"path"
+	"runtime/debug"
+
+	"isxcli/internal/updater"
+)
+
+// BuildHash is the VCS revision of the build, set at compile time with
+// -X isxcli/internal/app.BuildHash=<rev>. When empty the revision Go records
+// in the binary is used.
+var BuildHash = ""
+
+// buildHash returns the revision the binary was built from, marked -dirty
+// when built from a modified tree
+func buildHash() string {
+	if BuildHash != "" {
+		return BuildHash
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if revision != "" && modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// frontendVersion returns the Next.js build ID of the embedded frontend, the
+// directory under _next/static holding the build manifest
+func frontendVersion(frontendFS fs.FS) string {
+	if frontendFS == nil {
+		return ""
+	}
+	matches, err := fs.Glob(frontendFS, "_next/static/*/_buildManifest.js")
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	return path.Base(path.Dir(matches[0]))
+}
+
+// updateChannel parses the configured update channel, falling back to
+// stable so a typo never stops the server
+func updateChannel(name string, logger *slog.Logger) updater.Channel {
+	channel, err := updater.ParseChannel(name)
+	if err != nil {
+		logger.Warn("Invalid update channel, using stable", slog.String("error", err.Error()))
+		return updater.ChannelStable
+	}
+	return channel
+}
@@ -0,0 +1,16 @@
+// Package audit keeps an append-only record of the API calls that change
+// state.
+//
+// Every POST, PUT, PATCH and DELETE under /api is written as one JSON line to
+// data/audit.jsonl: who made it (user, role and a fingerprint of the API
+// token), what it was (action, route, path and a SHA-256 of the payload),
+// when, and how it ended. Payloads themselves are never stored. The log is
+// only appended to; it can be read, filtered, through /api/v1/audit by
+// admins.
+//
+// Example usage:
+//
+//	log := audit.NewLog(filepath.Join(paths.DataDir, audit.FileName), logger)
+//	r.Use(middleware.Audit(log, logger))
+//	entries, err := log.Query(audit.Filter{Action: audit.ActionLicense, Limit: 50})
+package audit
@@ -0,0 +1,278 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the audit log kept in the data directory
+const FileName = "audit.jsonl"
+
+// Query limits
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 1000
+)
+
+// Actions group audited routes by what they change
+const (
+	ActionOperations = "operations" // Starting, resuming and cancelling operations, uploads
+	ActionConfig     = "config"     // Backups, telemetry, notifications and other settings
+	ActionExports    = "exports"    // Export bundles and scheduled export subscriptions
+	ActionLicense    = "license"    // Activation, transfer and fleet license actions
+	ActionAuth       = "auth"       // Sign-in, sign-out and account changes
+	ActionOther      = "other"      // Portfolios, watchlists and the rest
+)
+
+// Results of an audited call
+const (
+	ResultSuccess = "success" // 1xx to 3xx
+	ResultDenied  = "denied"  // 401 and 403
+	ResultFailure = "failure" // Any other error status
+)
+
+// actionRoutes maps the first segment of a route below /api or /api/v1 to
+// its action
+var actionRoutes = map[string]string{
+	"operations":          ActionOperations,
+	"operation-templates": ActionOperations,
+	"uploads":             ActionOperations,
+	"scrape":              ActionOperations,
+	"process":             ActionOperations,
+	"indexcsv":            ActionOperations,
+	"backups":             ActionConfig,
+	"telemetry":           ActionConfig,
+	"notifications":       ActionConfig,
+	"config":              ActionConfig,
+	"exports":             ActionExports,
+	"subscriptions":       ActionExports,
+	"license":             ActionLicense,
+	"auth":                ActionAuth,
+}
+
+// Entry is one audited API call
+type Entry struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	User       string    `json:"user"`
+	Role       string    `json:"role"`
+	KeyID      string    `json:"key_id,omitempty"` // First 12 hex digits of the API token's SHA-256
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	Profile    string    `json:"profile,omitempty"`
+
+	Action        string `json:"action"`
+	Method        string `json:"method"`
+	Route         string `json:"route"` // Route pattern, e.g. /api/operations/{id}/cancel
+	Path          string `json:"path"`
+	PayloadSHA256 string `json:"payload_sha256,omitempty"` // Empty for calls without a body
+	PayloadBytes  int64  `json:"payload_bytes"`
+
+	Status     int    `json:"status"`
+	Result     string `json:"result"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Filter selects audit entries; zero fields match everything
+type Filter struct {
+	From   time.Time
+	To     time.Time
+	User   string
+	Action string
+	Method string
+	Result string
+	// Route matches entries whose route pattern starts with it
+	Route string
+	// Limit caps the newest entries returned: DefaultQueryLimit when zero,
+	// at most MaxQueryLimit
+	Limit int
+}
+
+// Log appends audit entries to a JSON lines file. A nil Log records nothing.
+type Log struct {
+	path   string
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewLog creates an audit log appending to path
+func NewLog(path string, logger *slog.Logger) *Log {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Log{
+		path:   path,
+		logger: logger.With(slog.String("component", "audit")),
+		now:    time.Now,
+	}
+}
+
+// Path returns the file entries are appended to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends entry, stamping it with the current time when unset
+func (l *Log) Record(entry Entry) error {
+	if l == nil {
+		return nil
+	}
+	if entry.Time.IsZero() {
+		entry.Time = l.now()
+	}
+	entry.Time = entry.Time.UTC()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+	// Opened per entry so a log moved away by the operator is started afresh
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Query returns the newest entries matching filter, newest first. Lines that
+// are not valid entries are skipped.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	entries := []Entry{}
+	if l == nil {
+		return entries, nil
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	limit = min(limit, MaxQueryLimit)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	skipped := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			skipped++
+			continue
+		}
+		if !filter.matches(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		// Only the newest limit entries are kept
+		if len(entries) >= 2*limit {
+			entries = append(entries[:0], entries[len(entries)-limit:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	if skipped > 0 {
+		l.logger.Warn("Skipped unreadable audit log lines", slog.Int("count", skipped))
+	}
+
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func (f Filter) matches(e Entry) bool {
+	switch {
+	case !f.From.IsZero() && e.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && !e.Time.Before(f.To):
+		return false
+	case f.User != "" && e.User != f.User:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.Method != "" && !strings.EqualFold(e.Method, f.Method):
+		return false
+	case f.Result != "" && e.Result != f.Result:
+		return false
+	case f.Route != "" && !strings.HasPrefix(e.Route, f.Route):
+		return false
+	}
+	return true
+}
+
+// ActionFor returns the action of a route pattern such as
+// /api/v1/license/fleet/activate
+func ActionFor(route string) string {
+	rest := strings.TrimPrefix(route, "/api/")
+	rest = strings.TrimPrefix(rest, "v1/")
+	segment, _, _ := strings.Cut(rest, "/")
+	if action, ok := actionRoutes[segment]; ok {
+		return action
+	}
+	return ActionOther
+}
+
+// ResultFor returns the result of a call answered with status
+func ResultFor(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ResultDenied
+	case status >= 400:
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// ValidAction reports whether action is one of the audit actions
+func ValidAction(action string) bool {
+	switch action {
+	case ActionOperations, ActionConfig, ActionExports, ActionLicense, ActionAuth, ActionOther:
+		return true
+	}
+	return false
+}
+
+// ValidResult reports whether result is one of the audit results
+func ValidResult(result string) bool {
+	switch result {
+	case ResultSuccess, ResultDenied, ResultFailure:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRecordAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", FileName)
+	log := NewLog(path, nil)
+	start := time.Date(2025, 8, 26, 9, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{User: "alice", Role: "admin", Action: ActionLicense, Method: http.MethodPost, Route: "/api/license/activate", Status: 200, Result: ResultSuccess},
+		{User: "bob", Role: "operator", Action: ActionOperations, Method: http.MethodPost, Route: "/api/operations/start", Status: 202, Result: ResultSuccess},
+		{User: "bob", Role: "operator", Action: ActionConfig, Method: http.MethodDelete, Route: "/api/v1/telemetry", Status: 403, Result: ResultDenied},
+		{User: "alice", Role: "admin", Action: ActionOperations, Method: http.MethodPost, Route: "/api/operations/{id}/cancel", Status: 404, Result: ResultFailure},
+	}
+	for i, entry := range entries {
+		entry.Time = start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, log.Record(entry))
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	all, err := log.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, "/api/operations/{id}/cancel", all[0].Route, "newest first")
+	assert.Equal(t, start, all[3].Time)
+
+	ops, err := log.Query(Filter{Action: ActionOperations})
+	require.NoError(t, err)
+	assert.Len(t, ops, 2)
+
+	bob, err := log.Query(Filter{User: "bob", Result: ResultDenied})
+	require.NoError(t, err)
+	require.Len(t, bob, 1)
+	assert.Equal(t, "/api/v1/telemetry", bob[0].Route)
+
+	window, err := log.Query(Filter{From: start.Add(time.Minute), To: start.Add(3 * time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, window, 2, "from is inclusive, to exclusive")
+
+	routes, err := log.Query(Filter{Route: "/api/operations", Method: "post"})
+	require.NoError(t, err)
+	assert.Len(t, routes, 2)
+}
+
+func TestLogQueryLimitKeepsNewest(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), FileName), nil)
+	for i := 0; i < 25; i++ {
+		require.NoError(t, log.Record(Entry{User: "alice", Path: fmt.Sprintf("/api/v1/backups/%d", i)}))
+	}
+
+	got, err := log.Query(Filter{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "/api/v1/backups/24", got[0].Path)
+	assert.Equal(t, "/api/v1/backups/22", got[2].Path)
+}
+
+func TestLogSkipsBadLinesAndMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	log := NewLog(path, nil)
+
+	got, err := log.Query(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	require.NoError(t, log.Record(Entry{User: "alice"}))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("{truncated\n\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, log.Record(Entry{User: "bob"}))
+
+	got, err = log.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "bob", got[0].User)
+
+	var none *Log
+	assert.NoError(t, none.Record(Entry{}))
+}
+
+func TestActionFor(t *testing.T) {
+	tests := map[string]string{
+		"/api/operations/start":          ActionOperations,
+		"/api/operations/{id}/cancel":    ActionOperations,
+		"/api/v1/operations/{id}/resume": ActionOperations,
+		"/api/scrape":                    ActionOperations,
+		"/api/v1/backups":                ActionConfig,
+		"/api/v1/notifications/test":     ActionConfig,
+		"/api/v1/exports":                ActionExports,
+		"/api/v1/subscriptions/{id}":     ActionExports,
+		"/api/license/activate":          ActionLicense,
+		"/api/v1/license/fleet/activate": ActionLicense,
+		"/api/auth/login":                ActionAuth,
+		"/api/v1/auth/accounts/{name}":   ActionAuth,
+		"/api/v1/watchlists":             ActionOther,
+		"/api/v1":                        ActionOther,
+	}
+	for route, want := range tests {
+		assert.Equal(t, want, ActionFor(route), route)
+	}
+}
+
+func TestResultFor(t *testing.T) {
+	assert.Equal(t, ResultSuccess, ResultFor(http.StatusAccepted))
+	assert.Equal(t, ResultSuccess, ResultFor(http.StatusFound))
+	assert.Equal(t, ResultDenied, ResultFor(http.StatusUnauthorized))
+	assert.Equal(t, ResultDenied, ResultFor(http.StatusForbidden))
+	assert.Equal(t, ResultFailure, ResultFor(http.StatusConflict))
+	assert.Equal(t, ResultFailure, ResultFor(http.StatusInternalServerError))
+}
@@ -0,0 +1,387 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"isxcli/internal/middleware"
+)
+
+// Locations in the data directory
+const (
+	DirName        = "auth"
+	AccountsFile   = "accounts.json"
+	SetupTokenFile = "setup_token"
+)
+
+// DefaultMinPasswordLength is the shortest password accepted when none is
+// configured
+const DefaultMinPasswordLength = 10
+
+// maxPasswordLength is bcrypt's input limit; longer passwords would be
+// silently truncated
+const maxPasswordLength = 72
+
+var (
+	// ErrInvalidCredentials is returned for an unknown user or a wrong password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrAccountExists is returned when creating an account whose name is taken
+	ErrAccountExists = errors.New("account already exists")
+	// ErrAccountNotFound is returned for an account that does not exist
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrLastAdmin is returned when a change would leave no admin account
+	ErrLastAdmin = errors.New("the last admin account cannot be removed")
+	// ErrInvalidAccount is returned for a malformed name, role or password
+	ErrInvalidAccount = errors.New("invalid account")
+	// ErrInvalidSetupToken is returned when creating the first admin without
+	// the token from the setup token file
+	ErrInvalidSetupToken = errors.New("invalid setup token")
+)
+
+// accountName allows e-mail style names as well as plain ones
+var accountName = regexp.MustCompile(`^[a-z0-9][a-z0-9._@-]{0,63}$`)
+
+// Account is a local account as shown to admins
+type Account struct {
+	Name        string          `json:"name"`
+	Role        middleware.Role `json:"role"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	LastLoginAt *time.Time      `json:"last_login_at,omitempty"`
+}
+
+// User returns the caller an account signs in as
+func (a Account) User() middleware.User {
+	return middleware.User{Name: a.Name, Role: a.Role}
+}
+
+// storedAccount is an account with its password hash, as written to the file
+type storedAccount struct {
+	Account
+	PasswordHash string `json:"password_hash"`
+}
+
+// Accounts stores local accounts in a JSON file, written through a temp file
+// readable only by its owner
+type Accounts struct {
+	path              string
+	minPasswordLength int
+	now               func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewAccounts creates an account store at path. Passwords must have at least
+// minPasswordLength characters (DefaultMinPasswordLength when not positive).
+func NewAccounts(path string, minPasswordLength int) *Accounts {
+	if minPasswordLength <= 0 {
+		minPasswordLength = DefaultMinPasswordLength
+	}
+	return &Accounts{path: path, minPasswordLength: minPasswordLength, now: time.Now}
+}
+
+// List returns every account ordered by name
+func (s *Accounts) List() ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]Account, 0, len(stored))
+	for _, a := range stored {
+		accounts = append(accounts, a.Account)
+	}
+	return accounts, nil
+}
+
+// Empty reports whether no account exists yet
+func (s *Accounts) Empty() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+	return len(stored) == 0, err
+}
+
+// Create adds an account. Names are case-insensitive and stored lowercase.
+func (s *Accounts) Create(name, password string, role middleware.Role) (Account, error) {
+	return s.add(name, password, role, false)
+}
+
+// CreateFirst adds an admin account only while no account exists, so the
+// first administrator can set up sign-in without credentials. It requires the
+// token written by SetupToken, which only someone with access to the data
+// directory can read, and removes the token afterwards.
+func (s *Accounts) CreateFirst(name, password, setupToken string) (Account, error) {
+	if empty, err := s.Empty(); err != nil {
+		return Account{}, err
+	} else if !empty {
+		return Account{}, fmt.Errorf("%w: accounts are already set up", ErrAccountExists)
+	}
+	want, err := os.ReadFile(s.setupTokenPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Account{}, fmt.Errorf("failed to read setup token: %w", err)
+	}
+	want = []byte(strings.TrimSpace(string(want)))
+	if len(want) == 0 || subtle.ConstantTimeCompare(want, []byte(strings.TrimSpace(setupToken))) != 1 {
+		return Account{}, ErrInvalidSetupToken
+	}
+
+	account, err := s.add(name, password, middleware.RoleAdmin, true)
+	if err != nil {
+		return Account{}, err
+	}
+	if err := os.Remove(s.setupTokenPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return account, fmt.Errorf("failed to remove setup token: %w", err)
+	}
+	return account, nil
+}
+
+// SetupToken writes the one-time token that creating the first admin
+// requires, unless one is already waiting, and returns the file's path. It
+// returns "" once accounts exist.
+func (s *Accounts) SetupToken() (string, error) {
+	empty, err := s.Empty()
+	if err != nil || !empty {
+		return "", err
+	}
+	path := s.setupTokenPath()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	token, err := randomString()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate setup token: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create accounts directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write setup token: %w", err)
+	}
+	return path, nil
+}
+
+func (s *Accounts) setupTokenPath() string {
+	return filepath.Join(filepath.Dir(s.path), SetupTokenFile)
+}
+
+func (s *Accounts) add(name, password string, role middleware.Role, first bool) (Account, error) {
+	name = NormalizeName(name)
+	if err := s.validate(name, password, role); err != nil {
+		return Account{}, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+	if err != nil {
+		return Account{}, err
+	}
+	if first && len(stored) > 0 {
+		return Account{}, fmt.Errorf("%w: accounts are already set up", ErrAccountExists)
+	}
+	if _, ok := find(stored, name); ok {
+		return Account{}, fmt.Errorf("%w: %s", ErrAccountExists, name)
+	}
+	now := s.now().UTC()
+	account := storedAccount{
+		Account:      Account{Name: name, Role: role, CreatedAt: now, UpdatedAt: now},
+		PasswordHash: string(hash),
+	}
+	if err := s.save(append(stored, account)); err != nil {
+		return Account{}, err
+	}
+	return account.Account, nil
+}
+
+// Authenticate checks a password and records the sign-in
+func (s *Accounts) Authenticate(name, password string) (Account, error) {
+	name = NormalizeName(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+	if err != nil {
+		return Account{}, err
+	}
+	i, ok := find(stored, name)
+	if !ok {
+		// Spend the same time as for a wrong password, so names cannot be
+		// probed by timing
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return Account{}, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored[i].PasswordHash), []byte(password)) != nil {
+		return Account{}, ErrInvalidCredentials
+	}
+
+	now := s.now().UTC()
+	stored[i].LastLoginAt = &now
+	if err := s.save(stored); err != nil {
+		return Account{}, err
+	}
+	return stored[i].Account, nil
+}
+
+// SetPassword replaces an account's password
+func (s *Accounts) SetPassword(name, password string) error {
+	name = NormalizeName(name)
+	if err := s.validatePassword(password); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+	if err != nil {
+		return err
+	}
+	i, ok := find(stored, name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, name)
+	}
+	stored[i].PasswordHash = string(hash)
+	stored[i].UpdatedAt = s.now().UTC()
+	return s.save(stored)
+}
+
+// Delete removes an account; the last admin cannot be removed
+func (s *Accounts) Delete(name string) error {
+	name = NormalizeName(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.load()
+	if err != nil {
+		return err
+	}
+	i, ok := find(stored, name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, name)
+	}
+	if stored[i].Role == middleware.RoleAdmin {
+		admins := 0
+		for _, a := range stored {
+			if a.Role == middleware.RoleAdmin {
+				admins++
+			}
+		}
+		if admins == 1 {
+			return ErrLastAdmin
+		}
+	}
+	return s.save(append(stored[:i], stored[i+1:]...))
+}
+
+// NormalizeName returns the stored form of an account name
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (s *Accounts) validate(name, password string, role middleware.Role) error {
+	if !accountName.MatchString(name) {
+		return fmt.Errorf("%w: name must be 1 to 64 letters, digits or . _ @ -", ErrInvalidAccount)
+	}
+	if !role.Valid() {
+		return fmt.Errorf("%w: unknown role %q (want admin, operator or viewer)", ErrInvalidAccount, role)
+	}
+	return s.validatePassword(password)
+}
+
+func (s *Accounts) validatePassword(password string) error {
+	if len([]rune(password)) < s.minPasswordLength {
+		return fmt.Errorf("%w: password must have at least %d characters", ErrInvalidAccount, s.minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("%w: password must not exceed %d bytes", ErrInvalidAccount, maxPasswordLength)
+	}
+	return nil
+}
+
+// load reads the accounts; callers hold s.mu
+func (s *Accounts) load() ([]storedAccount, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts: %w", err)
+	}
+	var stored []storedAccount
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts: %w", err)
+	}
+	return stored, nil
+}
+
+// save writes the accounts ordered by name; callers hold s.mu
+func (s *Accounts) save(stored []storedAccount) error {
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Name < stored[j].Name })
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create accounts directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save accounts: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save accounts: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save accounts: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save accounts: %w", err)
+	}
+	return nil
+}
+
+func find(stored []storedAccount, name string) (int, bool) {
+	for i, a := range stored {
+		if a.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// dummyHash is compared against when an account does not exist
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("isx-pulse-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/middleware"
+)
+
+func TestAccountsLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", DirName, AccountsFile)
+	accounts := NewAccounts(path, 0)
+
+	empty, err := accounts.Empty()
+	require.NoError(t, err)
+	assert.True(t, empty)
+
+	tokenPath, err := accounts.SetupToken()
+	require.NoError(t, err)
+	token, err := os.ReadFile(tokenPath)
+	require.NoError(t, err)
+	_, err = accounts.CreateFirst("mallory", "correct horse", "guess")
+	assert.ErrorIs(t, err, ErrInvalidSetupToken, "setup needs the token from the data directory")
+
+	admin, err := accounts.CreateFirst(" Alice ", "correct horse", string(token))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", admin.Name)
+	assert.Equal(t, middleware.RoleAdmin, admin.Role)
+	assert.NoFileExists(t, tokenPath, "the setup token is used once")
+
+	_, err = accounts.CreateFirst("mallory", "correct horse", string(token))
+	assert.ErrorIs(t, err, ErrAccountExists, "setup only works once")
+	tokenPath, err = accounts.SetupToken()
+	require.NoError(t, err)
+	assert.Empty(t, tokenPath)
+
+	_, err = accounts.Create("bob", "battery staple", middleware.RoleOperator)
+	require.NoError(t, err)
+	_, err = accounts.Create("BOB", "battery staple", middleware.RoleViewer)
+	assert.ErrorIs(t, err, ErrAccountExists)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "battery staple", "only hashes are stored")
+
+	signedIn, err := accounts.Authenticate("Bob", "battery staple")
+	require.NoError(t, err)
+	assert.Equal(t, middleware.User{Name: "bob", Role: middleware.RoleOperator}, signedIn.User())
+	assert.NotNil(t, signedIn.LastLoginAt)
+
+	_, err = accounts.Authenticate("bob", "wrong password")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = accounts.Authenticate("nobody", "battery staple")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	require.NoError(t, accounts.SetPassword("bob", "a new passphrase"))
+	_, err = accounts.Authenticate("bob", "battery staple")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = accounts.Authenticate("bob", "a new passphrase")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, accounts.SetPassword("nobody", "a new passphrase"), ErrAccountNotFound)
+
+	assert.ErrorIs(t, accounts.Delete("alice"), ErrLastAdmin)
+	require.NoError(t, accounts.Delete("bob"))
+	assert.ErrorIs(t, accounts.Delete("bob"), ErrAccountNotFound)
+
+	list, err := accounts.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "alice", list[0].Name)
+}
+
+func TestAccountsValidation(t *testing.T) {
+	accounts := NewAccounts(filepath.Join(t.TempDir(), AccountsFile), 12)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		role     middleware.Role
+	}{
+		{"short password", "carol", "too short", middleware.RoleViewer},
+		{"password over bcrypt limit", "carol", string(make([]byte, 73)), middleware.RoleViewer},
+		{"name with spaces", "carol smith", "long enough password", middleware.RoleViewer},
+		{"empty name", "", "long enough password", middleware.RoleViewer},
+		{"unknown role", "carol", "long enough password", middleware.Role("owner")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := accounts.Create(tt.username, tt.password, tt.role)
+			assert.ErrorIs(t, err, ErrInvalidAccount)
+		})
+	}
+
+	_, err := accounts.Create("carol@example.com", "long enough password", middleware.RoleViewer)
+	assert.NoError(t, err, "e-mail style names are allowed")
+}
@@ -0,0 +1,19 @@
+// Package auth signs users in to the web UI with local accounts or an
+// OpenID Connect provider such as Azure AD or Google.
+//
+// Local accounts are kept in data/auth/accounts.json with bcrypt password
+// hashes. A successful sign-in, local or through OIDC, starts a session held
+// in memory and identified by an HttpOnly cookie; AccessControl resolves the
+// cookie to the session's user, so roles are enforced exactly as for API
+// tokens. Sessions end on logout, when they expire, when the account changes
+// and when the server restarts.
+//
+// Example usage:
+//
+//	accounts := auth.NewAccounts(filepath.Join(paths.DataDir, auth.DirName, auth.AccountsFile), 10)
+//	sessions := auth.NewSessions(12*time.Hour, false)
+//	accessControl.SetSessions(sessions, true)
+//
+//	account, err := accounts.Authenticate("alice", password)
+//	session, err := sessions.Create(w, r, account.User(), auth.ProviderLocal)
+package auth
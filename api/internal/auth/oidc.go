@@ -0,0 +1,409 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"isxcli/internal/middleware"
+)
+
+var (
+	// ErrLoginExpired is returned for a callback whose login was not started
+	// here, was already completed or took too long
+	ErrLoginExpired = errors.New("sign-in expired or was not started here; please sign in again")
+	// ErrNotAllowed is returned for a provider account that may not sign in
+	ErrNotAllowed = errors.New("this account is not allowed to sign in")
+	// ErrInvalidIDToken is returned for an ID token that fails validation
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// pendingLoginTTL bounds the time between starting a login and the callback
+const pendingLoginTTL = 10 * time.Minute
+
+// maxPendingLogins caps the logins awaiting their callback; the oldest is
+// dropped when an unauthenticated caller starts more
+const maxPendingLogins = 1000
+
+// StateCookieName is the cookie binding a login's state to the browser that
+// started it, so a callback URL sent to someone else does not sign them in
+const StateCookieName = "isx_oidc_state"
+
+// clockSkew is the tolerance for ID token times
+const clockSkew = time.Minute
+
+// OIDCConfig configures sign-in with an OpenID Connect provider
+type OIDCConfig struct {
+	// Issuer is the provider's issuer URL, e.g. https://accounts.google.com or
+	// https://login.microsoftonline.com/{tenant}/v2.0
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is this server's callback, ending in /api/auth/oidc/callback
+	RedirectURL string
+	Scopes      []string
+	// DefaultRole is the role of users not listed in Roles; empty admits
+	// listed users only
+	DefaultRole middleware.Role
+	// Roles maps e-mail addresses to roles
+	Roles map[string]middleware.Role
+	// AllowedDomains limits sign-in to e-mail addresses of these domains
+	AllowedDomains []string
+}
+
+// OIDC signs users in with an OpenID Connect provider through the
+// authorization code flow with PKCE. The provider is discovered on first use.
+// The ID token comes straight from the token endpoint over TLS, so its
+// issuer, audience, expiry and nonce are checked but not its signature
+// (OpenID Connect Core 3.1.3.7).
+type OIDC struct {
+	cfg    OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu       sync.Mutex
+	provider *providerMetadata
+	pending  map[string]pendingLogin
+}
+
+// providerMetadata is the part of the discovery document the flow needs
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// pendingLogin is a login started by AuthCodeURL, keyed by its state
+type pendingLogin struct {
+	nonce    string
+	verifier string
+	redirect string
+	expires  time.Time
+}
+
+// idTokenClaims are the ID token claims the flow reads
+type idTokenClaims struct {
+	Issuer        string    `json:"iss"`
+	Subject       string    `json:"sub"`
+	Audience      audience  `json:"aud"`
+	Expiry        int64     `json:"exp"`
+	Nonce         string    `json:"nonce"`
+	Email         string    `json:"email"`
+	EmailVerified claimBool `json:"email_verified"`
+	// DomainOwnerVerified is Azure AD's xms_edov optional claim, which it
+	// sends instead of email_verified
+	DomainOwnerVerified claimBool `json:"xms_edov"`
+}
+
+// claimBool is a boolean claim, which some providers send as a string
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = claimBool(v)
+	case string:
+		*b = claimBool(strings.EqualFold(v, "true"))
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// audience is the aud claim, a string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// NewOIDC creates an OIDC sign-in using client for provider requests
+// (http.DefaultClient when nil)
+func NewOIDC(cfg OIDCConfig, client *http.Client) *OIDC {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDC{
+		cfg:     cfg,
+		client:  client,
+		now:     time.Now,
+		pending: make(map[string]pendingLogin),
+	}
+}
+
+// AuthCodeURL starts a login, binds it to the browser with the state cookie
+// and returns the provider URL to send the browser to. redirect is the local
+// path to return to afterwards.
+func (o *OIDC) AuthCodeURL(w http.ResponseWriter, r *http.Request, redirect string) (string, error) {
+	provider, err := o.discover(r.Context())
+	if err != nil {
+		return "", err
+	}
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	o.mu.Lock()
+	now := o.now()
+	oldest := ""
+	for key, p := range o.pending {
+		if now.After(p.expires) {
+			delete(o.pending, key)
+		} else if oldest == "" || p.expires.Before(o.pending[oldest].expires) {
+			oldest = key
+		}
+	}
+	if len(o.pending) >= maxPendingLogins {
+		delete(o.pending, oldest)
+	}
+	o.pending[state] = pendingLogin{
+		nonce:    nonce,
+		verifier: verifier,
+		redirect: SafeRedirect(redirect),
+		expires:  now.Add(pendingLoginTTL),
+	}
+	o.mu.Unlock()
+
+	http.SetCookie(w, o.stateCookie(r, state, int(pendingLoginTTL/time.Second)))
+	return o.oauthConfig(provider).AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
+
+// Exchange completes a login from the provider's callback and returns the
+// signed-in user and the local path to return to. The state must match the
+// state cookie of the browser that started the login, which is cleared.
+func (o *OIDC) Exchange(w http.ResponseWriter, r *http.Request, state, code string) (middleware.User, string, error) {
+	ctx := r.Context()
+	cookie, err := r.Cookie(StateCookieName)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		return middleware.User{}, "", ErrLoginExpired
+	}
+	http.SetCookie(w, o.stateCookie(r, "", -1))
+
+	o.mu.Lock()
+	login, ok := o.pending[state]
+	delete(o.pending, state)
+	o.mu.Unlock()
+	if !ok || o.now().After(login.expires) {
+		return middleware.User{}, "", ErrLoginExpired
+	}
+
+	provider, err := o.discover(ctx)
+	if err != nil {
+		return middleware.User{}, "", err
+	}
+	token, err := o.oauthConfig(provider).Exchange(context.WithValue(ctx, oauth2.HTTPClient, o.client), code,
+		oauth2.VerifierOption(login.verifier))
+	if err != nil {
+		return middleware.User{}, "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return middleware.User{}, "", fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	claims, err := o.validate(rawIDToken, provider.Issuer, login.nonce)
+	if err != nil {
+		return middleware.User{}, "", err
+	}
+	user, err := o.user(claims)
+	if err != nil {
+		return middleware.User{}, "", err
+	}
+	return user, login.redirect, nil
+}
+
+// validate decodes an ID token and checks its issuer, audience, expiry and nonce
+func (o *OIDC) validate(rawIDToken, issuer, nonce string) (*idTokenClaims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidIDToken)
+	}
+	var claims idTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidIDToken)
+	}
+
+	switch {
+	case claims.Issuer != issuer:
+		return nil, fmt.Errorf("%w: issuer %q, want %q", ErrInvalidIDToken, claims.Issuer, issuer)
+	case !contains(claims.Audience, o.cfg.ClientID):
+		return nil, fmt.Errorf("%w: not issued for this client", ErrInvalidIDToken)
+	case o.now().After(time.Unix(claims.Expiry, 0).Add(clockSkew)):
+		return nil, fmt.Errorf("%w: expired", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	return &claims, nil
+}
+
+// user maps validated claims to a user: the e-mail address names the user
+// and selects the role. Only addresses the provider vouches for are trusted,
+// as many providers let users enter any address on their profile; Azure AD
+// tenants must enable the xms_edov optional claim.
+func (o *OIDC) user(claims *idTokenClaims) (middleware.User, error) {
+	name := NormalizeName(claims.Email)
+	if name == "" {
+		return middleware.User{}, fmt.Errorf("%w: the provider sent no e-mail address", ErrNotAllowed)
+	}
+	if !claims.EmailVerified && !claims.DomainOwnerVerified {
+		return middleware.User{}, fmt.Errorf("%w: e-mail address %s is not verified", ErrNotAllowed, name)
+	}
+
+	if len(o.cfg.AllowedDomains) > 0 {
+		_, domain, _ := strings.Cut(name, "@")
+		allowed := false
+		for _, d := range o.cfg.AllowedDomains {
+			if strings.EqualFold(domain, strings.TrimPrefix(d, "@")) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return middleware.User{}, fmt.Errorf("%w: %s is outside the allowed domains", ErrNotAllowed, name)
+		}
+	}
+
+	role := o.cfg.DefaultRole
+	for email, r := range o.cfg.Roles {
+		if NormalizeName(email) == name {
+			role = r
+			break
+		}
+	}
+	if !role.Valid() {
+		return middleware.User{}, fmt.Errorf("%w: %s has no role", ErrNotAllowed, name)
+	}
+	return middleware.User{Name: name, Role: role}, nil
+}
+
+// discover fetches and caches the provider's discovery document
+func (o *OIDC) discover(ctx context.Context) (*providerMetadata, error) {
+	o.mu.Lock()
+	cached := o.provider
+	o.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	url := strings.TrimSuffix(o.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC issuer: %w", err)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %s returned %s", url, resp.Status)
+	}
+
+	var provider providerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&provider); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC discovery document: %w", err)
+	}
+	if provider.Issuer != strings.TrimSuffix(o.cfg.Issuer, "/") && provider.Issuer != o.cfg.Issuer {
+		return nil, fmt.Errorf("OIDC discovery document is for issuer %q, not %q", provider.Issuer, o.cfg.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document lacks the authorization or token endpoint")
+	}
+
+	o.mu.Lock()
+	o.provider = &provider
+	o.mu.Unlock()
+	return &provider, nil
+}
+
+func (o *OIDC) oauthConfig(provider *providerMetadata) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.cfg.ClientID,
+		ClientSecret: o.cfg.ClientSecret,
+		RedirectURL:  o.cfg.RedirectURL,
+		Scopes:       o.cfg.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  provider.AuthorizationEndpoint,
+			TokenURL: provider.TokenEndpoint,
+		},
+	}
+}
+
+// stateCookie builds the state cookie, limited to the OIDC endpoints. It is
+// SameSite=Lax so the browser sends it on the provider's redirect back.
+func (o *OIDC) stateCookie(r *http.Request, state string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     StateCookieName,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   IsHTTPS(r) || strings.HasPrefix(o.cfg.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// SafeRedirect returns path when it stays on this server, and "/" otherwise,
+// so a login link cannot send users to another site afterwards
+func SafeRedirect(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
+
+func randomString() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
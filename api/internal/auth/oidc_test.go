@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/middleware"
+)
+
+// testProvider is an OpenID Connect provider answering discovery and token
+// requests; claims returns the ID token claims for a login's nonce
+type testProvider struct {
+	*httptest.Server
+	claims   func(nonce string) map[string]interface{}
+	nonce    string
+	verifier string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	p := &testProvider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		p.verifier = r.PostForm.Get("code_verifier")
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256"}`))
+		payload, _ := json.Marshal(p.claims(p.nonce))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature",
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// browserLogin is a login started by a browser: its state and state cookie
+type browserLogin struct {
+	state  string
+	cookie *http.Cookie
+}
+
+// login starts a login from a browser
+func (p *testProvider) login(t *testing.T, o *OIDC, redirect string) browserLogin {
+	rec := httptest.NewRecorder()
+	authURL, err := o.AuthCodeURL(rec, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil), redirect)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/authorize", u.Path)
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	p.nonce = u.Query().Get("nonce")
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, StateCookieName, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+	return browserLogin{state: u.Query().Get("state"), cookie: cookies[0]}
+}
+
+// exchange sends the provider's callback for login from a browser holding
+// cookie, nil for none
+func exchange(o *OIDC, login browserLogin, cookie *http.Cookie) (middleware.User, string, error) {
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	return o.Exchange(httptest.NewRecorder(), req, login.state, "code")
+}
+
+func TestOIDCLogin(t *testing.T) {
+	provider := newTestProvider(t)
+	o := NewOIDC(OIDCConfig{
+		Issuer:         provider.URL,
+		ClientID:       "isx-pulse",
+		RedirectURL:    "http://localhost:8080/api/auth/oidc/callback",
+		DefaultRole:    middleware.RoleViewer,
+		Roles:          map[string]middleware.Role{"Ops@Example.com": middleware.RoleOperator},
+		AllowedDomains: []string{"example.com"},
+	}, provider.Client())
+
+	claims := func(email string) func(string) map[string]interface{} {
+		return func(nonce string) map[string]interface{} {
+			return map[string]interface{}{
+				"iss":            provider.URL,
+				"aud":            []string{"isx-pulse"},
+				"exp":            time.Now().Add(time.Hour).Unix(),
+				"nonce":          nonce,
+				"email":          email,
+				"email_verified": true,
+			}
+		}
+	}
+
+	provider.claims = claims("ops@example.com")
+	login := provider.login(t, o, "/reports")
+	user, redirect, err := exchange(o, login, login.cookie)
+	require.NoError(t, err)
+	assert.Equal(t, middleware.User{Name: "ops@example.com", Role: middleware.RoleOperator}, user)
+	assert.Equal(t, "/reports", redirect)
+	assert.NotEmpty(t, provider.verifier, "PKCE verifier is sent with the code")
+
+	_, _, err = exchange(o, login, login.cookie)
+	assert.ErrorIs(t, err, ErrLoginExpired, "a state is only used once")
+
+	provider.claims = claims("analyst@example.com")
+	login = provider.login(t, o, "https://evil.example/")
+	user, redirect, err = exchange(o, login, login.cookie)
+	require.NoError(t, err)
+	assert.Equal(t, middleware.RoleViewer, user.Role)
+	assert.Equal(t, "/", redirect, "redirects stay on this server")
+
+	// Azure AD vouches for the address with xms_edov instead
+	provider.claims = func(nonce string) map[string]interface{} {
+		c := claims("analyst@example.com")(nonce)
+		delete(c, "email_verified")
+		c["xms_edov"] = "true"
+		return c
+	}
+	login = provider.login(t, o, "/")
+	_, _, err = exchange(o, login, login.cookie)
+	require.NoError(t, err)
+
+	provider.claims = claims("someone@other.org")
+	login = provider.login(t, o, "")
+	_, _, err = exchange(o, login, login.cookie)
+	assert.ErrorIs(t, err, ErrNotAllowed)
+}
+
+func TestOIDCBindsLoginToBrowser(t *testing.T) {
+	provider := newTestProvider(t)
+	o := NewOIDC(OIDCConfig{Issuer: provider.URL, ClientID: "isx-pulse", DefaultRole: middleware.RoleViewer}, provider.Client())
+	provider.claims = func(nonce string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss":            provider.URL,
+			"aud":            "isx-pulse",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"nonce":          nonce,
+			"email":          "mallory@example.com",
+			"email_verified": true,
+		}
+	}
+
+	// The attacker's own callback URL opened by someone else's browser
+	attacker := provider.login(t, o, "/")
+	victim := provider.login(t, o, "/")
+	_, _, err := exchange(o, attacker, nil)
+	assert.ErrorIs(t, err, ErrLoginExpired)
+	_, _, err = exchange(o, attacker, victim.cookie)
+	assert.ErrorIs(t, err, ErrLoginExpired)
+}
+
+func TestOIDCCapsPendingLogins(t *testing.T) {
+	provider := newTestProvider(t)
+	o := NewOIDC(OIDCConfig{Issuer: provider.URL, ClientID: "isx-pulse", DefaultRole: middleware.RoleViewer}, provider.Client())
+
+	for i := 0; i < maxPendingLogins+10; i++ {
+		provider.login(t, o, "/")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Len(t, o.pending, maxPendingLogins)
+}
+
+func TestOIDCRejectsBadIDTokens(t *testing.T) {
+	provider := newTestProvider(t)
+	o := NewOIDC(OIDCConfig{Issuer: provider.URL, ClientID: "isx-pulse", DefaultRole: middleware.RoleViewer}, provider.Client())
+
+	tests := []struct {
+		name   string
+		mutate func(claims map[string]interface{})
+		want   error
+	}{
+		{"wrong audience", func(c map[string]interface{}) { c["aud"] = "other-app" }, ErrInvalidIDToken},
+		{"wrong issuer", func(c map[string]interface{}) { c["iss"] = "https://attacker.example" }, ErrInvalidIDToken},
+		{"expired", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, ErrInvalidIDToken},
+		{"replayed nonce", func(c map[string]interface{}) { c["nonce"] = "old" }, ErrInvalidIDToken},
+		{"unverified e-mail", func(c map[string]interface{}) { c["email_verified"] = false }, ErrNotAllowed},
+		{"unvouched e-mail", func(c map[string]interface{}) { delete(c, "email_verified") }, ErrNotAllowed},
+		{"username only", func(c map[string]interface{}) { delete(c, "email"); c["preferred_username"] = "alice@example.com" }, ErrNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.claims = func(nonce string) map[string]interface{} {
+				c := map[string]interface{}{
+					"iss":            provider.URL,
+					"aud":            "isx-pulse",
+					"exp":            time.Now().Add(time.Hour).Unix(),
+					"nonce":          nonce,
+					"email":          "alice@example.com",
+					"email_verified": true,
+				}
+				tt.mutate(c)
+				return c
+			}
+			login := provider.login(t, o, "/")
+			_, _, err := exchange(o, login, login.cookie)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	tests := map[string]string{
+		"":                     "/",
+		"/":                    "/",
+		"/reports?day=1":       "/reports?day=1",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+		"https://evil.example": "/",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, SafeRedirect(path), path)
+	}
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/middleware"
+)
+
+// CookieName is the session cookie set after signing in
+const CookieName = "isx_session"
+
+// DefaultSessionTTL is how long a session lasts when no lifetime is configured
+const DefaultSessionTTL = 12 * time.Hour
+
+// Providers a session can come from
+const (
+	ProviderLocal = "local"
+	ProviderOIDC  = "oidc"
+)
+
+// Session is a signed-in user of the web UI
+type Session struct {
+	User      middleware.User `json:"user"`
+	Provider  string          `json:"provider"` // local or oidc
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// Sessions keeps the sessions of signed-in users in memory. Only a SHA-256
+// of each session ID is held, so the IDs in cookies never sit in memory
+// longer than a request.
+type Sessions struct {
+	ttl    time.Duration
+	secure bool
+	now    func() time.Time
+
+	mu   sync.Mutex
+	byID map[[sha256.Size]byte]Session
+}
+
+// NewSessions creates a session store whose sessions last ttl
+// (DefaultSessionTTL when not positive). With secure set, the cookie is
+// marked Secure even on plain HTTP requests, e.g. behind a TLS proxy that
+// does not send X-Forwarded-Proto.
+func NewSessions(ttl time.Duration, secure bool) *Sessions {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Sessions{
+		ttl:    ttl,
+		secure: secure,
+		now:    time.Now,
+		byID:   make(map[[sha256.Size]byte]Session),
+	}
+}
+
+// Create starts a session for user and sets its cookie on w
+func (s *Sessions) Create(w http.ResponseWriter, r *http.Request, user middleware.User, provider string) (Session, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return Session{}, err
+	}
+	id := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := s.now()
+	session := Session{User: user, Provider: provider, CreatedAt: now.UTC(), ExpiresAt: now.Add(s.ttl).UTC()}
+
+	s.mu.Lock()
+	for key, existing := range s.byID {
+		if !now.Before(existing.ExpiresAt) {
+			delete(s.byID, key)
+		}
+	}
+	s.byID[sha256.Sum256([]byte(id))] = session
+	s.mu.Unlock()
+
+	http.SetCookie(w, s.cookie(r, id, session.ExpiresAt))
+	return session, nil
+}
+
+// Lookup returns the live session of a request's cookie
+func (s *Sessions) Lookup(r *http.Request) (Session, bool) {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return Session{}, false
+	}
+	key := sha256.Sum256([]byte(cookie.Value))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.byID[key]
+	if !ok {
+		return Session{}, false
+	}
+	if !s.now().Before(session.ExpiresAt) {
+		delete(s.byID, key)
+		return Session{}, false
+	}
+	return session, true
+}
+
+// ResolveSession returns the user signed in with a request's cookie; it lets
+// AccessControl accept sessions alongside API tokens
+func (s *Sessions) ResolveSession(r *http.Request) (middleware.User, bool) {
+	session, ok := s.Lookup(r)
+	return session.User, ok
+}
+
+// Destroy ends the session of a request's cookie and clears the cookie
+func (s *Sessions) Destroy(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		s.mu.Lock()
+		delete(s.byID, sha256.Sum256([]byte(cookie.Value)))
+		s.mu.Unlock()
+	}
+	expired := s.cookie(r, "", time.Unix(0, 0))
+	expired.MaxAge = -1
+	http.SetCookie(w, expired)
+}
+
+// Revoke ends every session of a user, e.g. after their password changed or
+// their account was deleted
+func (s *Sessions) Revoke(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	for key, session := range s.byID {
+		if session.User.Name == name {
+			delete(s.byID, key)
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// cookie builds the session cookie. It is HttpOnly and SameSite=Lax, so
+// scripts cannot read it and other sites cannot send state changes with it.
+func (s *Sessions) cookie(r *http.Request, value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secure || IsHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// IsHTTPS reports whether the client reached the server over TLS, directly
+// or through a proxy setting X-Forwarded-Proto
+func IsHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
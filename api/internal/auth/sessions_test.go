@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/middleware"
+)
+
+// signIn creates a session and returns a request carrying its cookie
+func signIn(t *testing.T, sessions *Sessions, user middleware.User) (*http.Request, *http.Cookie) {
+	rec := httptest.NewRecorder()
+	_, err := sessions.Create(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", nil), user, ProviderLocal)
+	require.NoError(t, err)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/data/reports", nil)
+	req.AddCookie(cookies[0])
+	return req, cookies[0]
+}
+
+func TestSessionsLifecycle(t *testing.T) {
+	sessions := NewSessions(time.Hour, false)
+	now := time.Date(2025, 8, 26, 9, 0, 0, 0, time.UTC)
+	sessions.now = func() time.Time { return now }
+	alice := middleware.User{Name: "alice", Role: middleware.RoleAdmin}
+
+	req, cookie := signIn(t, sessions, alice)
+	assert.Equal(t, CookieName, cookie.Name)
+	assert.True(t, cookie.HttpOnly)
+	assert.False(t, cookie.Secure, "plain HTTP without secure_cookie")
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+
+	user, ok := sessions.ResolveSession(req)
+	require.True(t, ok)
+	assert.Equal(t, alice, user)
+
+	forged := httptest.NewRequest(http.MethodGet, "/", nil)
+	forged.AddCookie(&http.Cookie{Name: CookieName, Value: "forged"})
+	_, ok = sessions.ResolveSession(forged)
+	assert.False(t, ok)
+
+	now = now.Add(time.Hour)
+	_, ok = sessions.Lookup(req)
+	assert.False(t, ok, "sessions expire after their TTL")
+
+	req, _ = signIn(t, sessions, alice)
+	rec := httptest.NewRecorder()
+	sessions.Destroy(rec, req)
+	_, ok = sessions.Lookup(req)
+	assert.False(t, ok)
+	cleared := rec.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, -1, cleared[0].MaxAge)
+}
+
+func TestSessionsRevoke(t *testing.T) {
+	sessions := NewSessions(0, false)
+	bob := middleware.User{Name: "bob", Role: middleware.RoleOperator}
+
+	first, _ := signIn(t, sessions, bob)
+	second, _ := signIn(t, sessions, bob)
+	other, _ := signIn(t, sessions, middleware.User{Name: "carol", Role: middleware.RoleViewer})
+
+	assert.Equal(t, 2, sessions.Revoke("bob"))
+	_, ok := sessions.Lookup(first)
+	assert.False(t, ok)
+	_, ok = sessions.Lookup(second)
+	assert.False(t, ok)
+	_, ok = sessions.Lookup(other)
+	assert.True(t, ok)
+}
+
+func TestSessionCookieSecureBehindProxy(t *testing.T) {
+	sessions := NewSessions(time.Hour, false)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+
+	_, err := sessions.Create(rec, req, middleware.User{Name: "alice", Role: middleware.RoleAdmin}, ProviderLocal)
+	require.NoError(t, err)
+	assert.True(t, rec.Result().Cookies()[0].Secure)
+}
@@ -0,0 +1,579 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+)
+
+// ManifestName is the last entry of every snapshot
+const ManifestName = "manifest.json"
+
+// ManifestVersion is the snapshot layout written by this release
+const ManifestVersion = 1
+
+// Snapshot file names: isx-backup-20250826-150405.tar.gz, with .enc appended
+// when encrypted
+const (
+	namePrefix    = "isx-backup-"
+	nameLayout    = "20060102-150405"
+	archiveExt    = ".tar.gz"
+	encryptedExt  = archiveExt + ".enc"
+	licenseEntry  = "license.dat"
+	dataEntryRoot = "data"
+)
+
+var (
+	// ErrNotFound is returned for a snapshot name that is not in the backups directory
+	ErrNotFound = errors.New("backup not found")
+	// ErrPassphraseRequired is returned when reading an encrypted snapshot without a passphrase
+	ErrPassphraseRequired = errors.New("backup is encrypted; set backup.passphrase or ISX_BACKUP_PASSPHRASE")
+	// ErrCorrupt is returned when a snapshot fails its integrity check
+	ErrCorrupt = errors.New("backup failed integrity check")
+)
+
+// Snapshot describes a snapshot file in the backups directory
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	Encrypted bool      `json:"encrypted"`
+	Files     int       `json:"files,omitempty"` // Set for a snapshot just created
+}
+
+// Manifest lists the files of a snapshot with their checksums
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Profile   string         `json:"profile"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is one file of a snapshot. Path is slash-separated: data/...
+// for the data directory, license.dat for the license.
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manager creates, lists and restores snapshots of a profile's data
+// directory. Creating and restoring hold the reports directory lock, so they
+// never overlap a pipeline run.
+type Manager struct {
+	paths  *config.Paths
+	cfg    config.BackupConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewManager creates a snapshot manager for paths
+func NewManager(paths *config.Paths, cfg config.BackupConfig, logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{paths: paths, cfg: cfg, logger: logger, now: time.Now}
+}
+
+// Create writes a snapshot of the data directory and prunes the oldest
+// snapshots beyond backup.keep. The cache directory and lock files are never
+// included.
+func (m *Manager) Create(ctx context.Context) (*Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock, err := files.AcquireDirLock(m.paths.ReportsDir, "backup", files.LockOptions{Logger: m.logger})
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	sources, err := m.sources()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := m.now().UTC().Truncate(time.Second)
+	encrypted := m.cfg.Passphrase != ""
+	name := namePrefix + createdAt.Format(nameLayout) + archiveExt
+	if encrypted {
+		name = namePrefix + createdAt.Format(nameLayout) + encryptedExt
+	}
+	target := filepath.Join(m.paths.BackupsDir, name)
+	if _, err := os.Stat(target); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", name)
+	}
+	if err := os.MkdirAll(m.paths.BackupsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backups directory: %w", err)
+	}
+
+	tmp := target + ".tmp"
+	manifest, err := m.write(ctx, tmp, sources, createdAt)
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.InfoContext(ctx, "backup created",
+		slog.String("name", name),
+		slog.Int("files", len(manifest.Files)),
+		slog.Int64("size", info.Size()),
+		slog.Bool("encrypted", encrypted))
+
+	m.prune(ctx)
+	return &Snapshot{Name: name, Size: info.Size(), CreatedAt: createdAt, Encrypted: encrypted, Files: len(manifest.Files)}, nil
+}
+
+// source is a file to back up and its path in the snapshot
+type source struct {
+	path  string
+	entry string
+}
+
+// sources lists the files a snapshot includes under the configuration
+func (m *Manager) sources() ([]source, error) {
+	skip := map[string]bool{m.paths.CacheDir: true, m.paths.BackupsDir: true}
+	if !m.cfg.IncludeDownloads {
+		skip[m.paths.DownloadsDir] = true
+	}
+	if !m.cfg.IncludeReports {
+		skip[m.paths.ReportsDir] = true
+	}
+
+	var sources []source
+	err := filepath.WalkDir(m.paths.DataDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if skip[p] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || d.Name() == files.LockFileName {
+			return nil
+		}
+		rel, err := filepath.Rel(m.paths.DataDir, p)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source{path: p, entry: path.Join(dataEntryRoot, filepath.ToSlash(rel))})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data files: %w", err)
+	}
+
+	if m.cfg.IncludeLicense {
+		if _, err := os.Stat(m.paths.LicenseFile); err == nil {
+			sources = append(sources, source{path: m.paths.LicenseFile, entry: licenseEntry})
+		}
+	}
+	return sources, nil
+}
+
+// write archives sources to path, encrypting when a passphrase is set
+func (m *Manager) write(ctx context.Context, target string, sources []source, createdAt time.Time) (*Manifest, error) {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer out.Close()
+
+	var sink io.Writer = out
+	var enc *encryptWriter
+	if m.cfg.Passphrase != "" {
+		if enc, err = newEncryptWriter(out, m.cfg.Passphrase); err != nil {
+			return nil, err
+		}
+		sink = enc
+	}
+	gz := gzip.NewWriter(sink)
+	tw := tar.NewWriter(gz)
+
+	manifest := &Manifest{Version: ManifestVersion, CreatedAt: createdAt, Profile: m.paths.Profile}
+	for _, src := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		file, err := addFile(tw, src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", src.entry, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	hdr := &tar.Header{Name: ManifestName, Mode: 0644, Size: int64(len(data)), ModTime: createdAt}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := out.Sync(); err != nil {
+		return nil, err
+	}
+	return manifest, out.Close()
+}
+
+// addFile copies one source into the archive and returns its manifest entry
+func addFile(tw *tar.Writer, src source) (ManifestFile, error) {
+	f, err := os.Open(src.path)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	hdr := &tar.Header{Name: src.entry, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return ManifestFile{}, err
+	}
+
+	// The size is fixed in the header; a file growing meanwhile is cut there
+	hash := sha256.New()
+	if _, err := io.CopyN(tw, io.TeeReader(f, hash), info.Size()); err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{Path: src.entry, Size: info.Size(), SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// List returns the snapshots in the backups directory, newest first
+func (m *Manager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.paths.BackupsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+
+	snapshots := []Snapshot{}
+	for _, entry := range entries {
+		snap, ok := parseName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snap.Size = info.Size()
+		snapshots = append(snapshots, snap)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// parseName recognizes a snapshot file name
+func parseName(name string) (Snapshot, bool) {
+	if !strings.HasPrefix(name, namePrefix) {
+		return Snapshot{}, false
+	}
+	stamp, encrypted := strings.CutSuffix(strings.TrimPrefix(name, namePrefix), encryptedExt)
+	if !encrypted {
+		var ok bool
+		if stamp, ok = strings.CutSuffix(stamp, archiveExt); !ok {
+			return Snapshot{}, false
+		}
+	}
+	createdAt, err := time.Parse(nameLayout, stamp)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{Name: name, CreatedAt: createdAt, Encrypted: encrypted}, true
+}
+
+// prune deletes the oldest snapshots beyond backup.keep
+func (m *Manager) prune(ctx context.Context) {
+	if m.cfg.Keep <= 0 {
+		return
+	}
+	snapshots, err := m.List()
+	if err != nil || len(snapshots) <= m.cfg.Keep {
+		return
+	}
+	for _, snap := range snapshots[m.cfg.Keep:] {
+		if err := os.Remove(filepath.Join(m.paths.BackupsDir, snap.Name)); err != nil {
+			m.logger.WarnContext(ctx, "failed to delete old backup",
+				slog.String("name", snap.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		m.logger.InfoContext(ctx, "old backup deleted", slog.String("name", snap.Name))
+	}
+}
+
+// Verify reads a snapshot through and checks every file against its manifest
+func (m *Manager) Verify(ctx context.Context, name string) (*Manifest, error) {
+	return m.read(ctx, name, func(string, io.Reader) error { return nil })
+}
+
+// Restore verifies a snapshot in a staging directory, then moves its files
+// into place over the current ones. Files the snapshot does not contain are
+// left alone; nothing is touched when the snapshot fails verification.
+func (m *Manager) Restore(ctx context.Context, name string) (*Manifest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock, err := files.AcquireDirLock(m.paths.ReportsDir, "backup-restore", files.LockOptions{Logger: m.logger})
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	// Staged next to the backups so the final moves stay on one filesystem
+	staging, err := os.MkdirTemp(filepath.Dir(m.paths.BackupsDir), ".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest, err := m.read(ctx, name, func(entry string, r io.Reader) error {
+		if _, err := m.target(entry); err != nil {
+			return err
+		}
+		dst := filepath.Join(staging, filepath.FromSlash(entry))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range manifest.Files {
+		dst, _ := m.target(file.Path)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		if err := moveFile(filepath.Join(staging, filepath.FromSlash(file.Path)), dst); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "backup restored",
+		slog.String("name", name),
+		slog.Int("files", len(manifest.Files)))
+	return manifest, nil
+}
+
+// target maps a snapshot entry to its place in this profile
+func (m *Manager) target(entry string) (string, error) {
+	if entry != path.Clean(entry) || path.IsAbs(entry) || strings.HasPrefix(entry, "../") {
+		return "", fmt.Errorf("%w: invalid path %q", ErrCorrupt, entry)
+	}
+	if entry == licenseEntry {
+		return m.paths.LicenseFile, nil
+	}
+	if rel, ok := strings.CutPrefix(entry, dataEntryRoot+"/"); ok {
+		return filepath.Join(m.paths.DataDir, filepath.FromSlash(rel)), nil
+	}
+	return "", fmt.Errorf("%w: unexpected entry %q", ErrCorrupt, entry)
+}
+
+// read passes every file of a snapshot to fn, then checks the files read
+// against the manifest. fn sees unverified data; callers keep it aside until
+// read returns without error.
+func (m *Manager) read(ctx context.Context, name string, fn func(entry string, r io.Reader) error) (*Manifest, error) {
+	snap, ok := parseName(name)
+	if !ok || filepath.Base(name) != name {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	f, err := os.Open(filepath.Join(m.paths.BackupsDir, snap.Name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	src, err := m.decrypt(bufio.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, archiveError(err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	seen := map[string]ManifestFile{}
+	tr := tar.NewReader(gz)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, archiveError(err)
+		}
+		if manifest != nil {
+			return nil, fmt.Errorf("%w: entries after the manifest", ErrCorrupt)
+		}
+
+		if hdr.Name == ManifestName {
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("%w: invalid manifest: %v", ErrCorrupt, err)
+			}
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: %s is not a regular file", ErrCorrupt, hdr.Name)
+		}
+		if _, dup := seen[hdr.Name]; dup {
+			return nil, fmt.Errorf("%w: %s appears twice", ErrCorrupt, hdr.Name)
+		}
+
+		hash := sha256.New()
+		counter := &countingReader{r: io.TeeReader(tr, hash)}
+		if err := fn(hdr.Name, counter); err != nil {
+			return nil, err
+		}
+		// Drain what fn left unread so the hash covers the whole file
+		if _, err := io.Copy(io.Discard, counter); err != nil {
+			return nil, archiveError(err)
+		}
+		seen[hdr.Name] = ManifestFile{Path: hdr.Name, Size: counter.n, SHA256: hex.EncodeToString(hash.Sum(nil))}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: no manifest", ErrCorrupt)
+	}
+	if manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("backup has layout v%d; this release reads up to v%d", manifest.Version, ManifestVersion)
+	}
+	if len(manifest.Files) != len(seen) {
+		return nil, fmt.Errorf("%w: manifest lists %d files, archive has %d", ErrCorrupt, len(manifest.Files), len(seen))
+	}
+	for _, want := range manifest.Files {
+		if got, ok := seen[want.Path]; !ok || got != want {
+			return nil, fmt.Errorf("%w: %s does not match its checksum", ErrCorrupt, want.Path)
+		}
+	}
+	return manifest, nil
+}
+
+// decrypt returns the archive stream of a snapshot file, decrypting it when
+// it starts with the encrypted magic
+func (m *Manager) decrypt(r *bufio.Reader) (io.Reader, error) {
+	magic, err := r.Peek(len(encryptedMagic))
+	if err != nil || string(magic) != encryptedMagic {
+		return r, nil
+	}
+	if m.cfg.Passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+	r.Discard(len(encryptedMagic))
+	return newDecryptReader(r, m.cfg.Passphrase)
+}
+
+// archiveError keeps decryption errors and marks the rest as corruption
+func archiveError(err error) error {
+	if errors.Is(err, ErrWrongPassphrase) || errors.Is(err, ErrCorrupt) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrCorrupt, err)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// moveFile renames src to dst, copying when they are on different filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
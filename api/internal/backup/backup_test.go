@@ -0,0 +1,166 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPaths(t *testing.T) *config.Paths {
+	root := t.TempDir()
+	data := filepath.Join(root, "data")
+	return &config.Paths{
+		Profile:      config.DefaultProfile,
+		DataDir:      data,
+		DownloadsDir: filepath.Join(data, "downloads"),
+		ReportsDir:   filepath.Join(data, "reports"),
+		CacheDir:     filepath.Join(data, "cache"),
+		BackupsDir:   filepath.Join(root, "backups"),
+		LicenseFile:  filepath.Join(root, "license.dat"),
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func seedData(t *testing.T, paths *config.Paths) {
+	writeFile(t, filepath.Join(paths.DownloadsDir, "2025 08 25 ISX Daily Report.xlsx"), "xlsx")
+	writeFile(t, filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv"), "Date,Symbol\n2025-08-25,BBOB\n")
+	writeFile(t, filepath.Join(paths.CacheDir, "tmp.bin"), "cache")
+	writeFile(t, filepath.Join(paths.DataDir, "holidays.txt"), "2025-03-31\n")
+	writeFile(t, paths.LicenseFile, "license")
+}
+
+func TestManager_CreateAndRestore(t *testing.T) {
+	for _, passphrase := range []string{"", "correct horse"} {
+		t.Run("passphrase="+passphrase, func(t *testing.T) {
+			paths := testPaths(t)
+			seedData(t, paths)
+			cfg := config.BackupConfig{Passphrase: passphrase, IncludeDownloads: true, IncludeReports: true}
+			m := NewManager(paths, cfg, nil)
+
+			snap, err := m.Create(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, passphrase != "", snap.Encrypted)
+			assert.Equal(t, 3, snap.Files, "downloads, combined CSV and holidays; not the cache, the backup's own lock or the license")
+
+			manifest, err := m.Verify(context.Background(), snap.Name)
+			require.NoError(t, err)
+			var entries []string
+			for _, f := range manifest.Files {
+				entries = append(entries, f.Path)
+			}
+			assert.ElementsMatch(t, []string{
+				"data/downloads/2025 08 25 ISX Daily Report.xlsx",
+				"data/reports/combined/isx_combined_data.csv",
+				"data/holidays.txt",
+			}, entries)
+
+			// Damage the data, then restore it
+			combined := filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")
+			require.NoError(t, os.Remove(combined))
+			writeFile(t, filepath.Join(paths.DataDir, "holidays.txt"), "changed")
+
+			_, err = m.Restore(context.Background(), snap.Name)
+			require.NoError(t, err)
+			data, err := os.ReadFile(combined)
+			require.NoError(t, err)
+			assert.Equal(t, "Date,Symbol\n2025-08-25,BBOB\n", string(data))
+			data, err = os.ReadFile(filepath.Join(paths.DataDir, "holidays.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, "2025-03-31\n", string(data))
+			assert.FileExists(t, filepath.Join(paths.CacheDir, "tmp.bin"), "files outside the snapshot are left alone")
+		})
+	}
+}
+
+func TestManager_IncludeOptions(t *testing.T) {
+	paths := testPaths(t)
+	seedData(t, paths)
+	m := NewManager(paths, config.BackupConfig{IncludeLicense: true}, nil)
+
+	snap, err := m.Create(context.Background())
+	require.NoError(t, err)
+	manifest, err := m.Verify(context.Background(), snap.Name)
+	require.NoError(t, err)
+
+	var entries []string
+	for _, f := range manifest.Files {
+		entries = append(entries, f.Path)
+	}
+	assert.ElementsMatch(t, []string{"data/holidays.txt", "license.dat"}, entries)
+}
+
+func TestManager_RejectsDamagedSnapshots(t *testing.T) {
+	paths := testPaths(t)
+	seedData(t, paths)
+	m := NewManager(paths, config.BackupConfig{Passphrase: "secret", IncludeReports: true}, nil)
+	snap, err := m.Create(context.Background())
+	require.NoError(t, err)
+	path := filepath.Join(paths.BackupsDir, snap.Name)
+
+	_, err = NewManager(paths, config.BackupConfig{}, nil).Verify(context.Background(), snap.Name)
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+	_, err = NewManager(paths, config.BackupConfig{Passphrase: "wrong"}, nil).Verify(context.Background(), snap.Name)
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data[:len(data)-10], 0600))
+	writeFile(t, filepath.Join(paths.DataDir, "holidays.txt"), "current")
+
+	_, err = m.Restore(context.Background(), snap.Name)
+	require.Error(t, err)
+	current, err := os.ReadFile(filepath.Join(paths.DataDir, "holidays.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(current), "a damaged snapshot changes nothing")
+
+	_, err = m.Verify(context.Background(), "../license.dat")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = m.Verify(context.Background(), "isx-backup-20200101-000000.tar.gz")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestManager_ListAndPrune(t *testing.T) {
+	paths := testPaths(t)
+	seedData(t, paths)
+	m := NewManager(paths, config.BackupConfig{Keep: 2}, nil)
+
+	start := time.Date(2025, 8, 26, 15, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		m.now = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+		_, err := m.Create(context.Background())
+		require.NoError(t, err)
+	}
+	writeFile(t, filepath.Join(paths.BackupsDir, "notes.txt"), "not a snapshot")
+
+	snapshots, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "isx-backup-20250826-150200.tar.gz", snapshots[0].Name)
+	assert.Equal(t, "isx-backup-20250826-150100.tar.gz", snapshots[1].Name)
+	assert.False(t, snapshots[0].Encrypted)
+	assert.Positive(t, snapshots[0].Size)
+}
+
+func TestManager_CreateWaitsForPipeline(t *testing.T) {
+	paths := testPaths(t)
+	seedData(t, paths)
+
+	lock, err := files.AcquireDirLock(paths.ReportsDir, "processor", files.LockOptions{})
+	require.NoError(t, err)
+	defer lock.Release()
+
+	_, err = NewManager(paths, config.BackupConfig{}, nil).Create(context.Background())
+	assert.ErrorIs(t, err, files.ErrLocked)
+}
@@ -0,0 +1,205 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Layout of an encrypted snapshot: the magic line, the scrypt salt and the
+// base nonce, then length-prefixed AES-256-GCM chunks. Each chunk's nonce is
+// the base nonce XORed with its sequence number, and its additional data
+// marks the final chunk, so reordered, dropped or truncated chunks fail to
+// decrypt instead of yielding a shorter archive.
+const (
+	encryptedMagic = "ISXBAK1\n"
+	saltSize       = 16
+	chunkSize      = 64 << 10
+
+	// scrypt cost of the snapshot key: about 100ms per snapshot
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrWrongPassphrase is returned when an encrypted snapshot does not decrypt
+var ErrWrongPassphrase = errors.New("backup could not be decrypted: wrong passphrase or modified file")
+
+// newAEAD derives the AES-256-GCM cipher of a snapshot from the passphrase
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive backup key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce is the nonce of the seq-th chunk
+func chunkNonce(base []byte, seq uint64) []byte {
+	nonce := bytes.Clone(base)
+	tail := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^seq)
+	return nonce
+}
+
+// chunkAD marks the final chunk of a snapshot
+func chunkAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptWriter seals everything written to it in chunks. Close writes the
+// final chunk; without it the snapshot does not decrypt.
+type encryptWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	nonce []byte
+	seq   uint64
+	buf   []byte
+}
+
+// newEncryptWriter writes the snapshot header to w
+func newEncryptWriter(w io.Writer, passphrase string) (*encryptWriter, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := append([]byte(encryptedMagic), salt...)
+	if _, err := w.Write(append(header, nonce...)); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, nonce: nonce, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := min(len(p), chunkSize-len(e.buf))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		// A full buffer is only sealed once more data follows, so the final
+		// chunk sealed by Close is never a lost full one
+		if len(e.buf) == chunkSize && len(p) > 0 {
+			if err := e.seal(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close seals the buffered data as the final chunk
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(final bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.nonce, e.seq), e.buf, chunkAD(final))
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(sealed)))
+	if _, err := e.w.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.seq++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader opens the chunks written by encryptWriter
+type decryptReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	seq   uint64
+	buf   []byte
+	final bool
+}
+
+// newDecryptReader reads the snapshot header from r, whose magic line has
+// already been consumed
+func newDecryptReader(r io.Reader, passphrase string) (*decryptReader, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, fmt.Errorf("%w: truncated header", ErrCorrupt)
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, fmt.Errorf("%w: truncated header", ErrCorrupt)
+	}
+	return &decryptReader{r: r, aead: aead, nonce: nonce}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.final {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// open decrypts the next chunk into buf
+func (d *decryptReader) open() error {
+	var length [4]byte
+	if _, err := io.ReadFull(d.r, length[:]); err != nil {
+		return fmt.Errorf("%w: truncated after %d chunks", ErrCorrupt, d.seq)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > chunkSize+uint32(d.aead.Overhead()) {
+		return fmt.Errorf("%w: chunk %d is %d bytes", ErrCorrupt, d.seq, size)
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("%w: truncated in chunk %d", ErrCorrupt, d.seq)
+	}
+
+	nonce := chunkNonce(d.nonce, d.seq)
+	plain, err := d.aead.Open(nil, nonce, sealed, chunkAD(false))
+	if err != nil {
+		if plain, err = d.aead.Open(nil, nonce, sealed, chunkAD(true)); err != nil {
+			return ErrWrongPassphrase
+		}
+		d.final = true
+		var extra [1]byte
+		if n, _ := io.ReadFull(d.r, extra[:]); n > 0 {
+			return fmt.Errorf("%w: data after the final chunk", ErrCorrupt)
+		}
+	}
+	d.seq++
+	d.buf = plain
+	return nil
+}
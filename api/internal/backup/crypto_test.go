@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, plain []byte, passphrase string) []byte {
+	var buf bytes.Buffer
+	w, err := newEncryptWriter(&buf, passphrase)
+	require.NoError(t, err)
+	_, err = w.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	r, err := newDecryptReader(bytes.NewReader(data[len(encryptedMagic):]), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 10, chunkSize, 2*chunkSize + 7} {
+		plain := bytes.Repeat([]byte("isx"), size/3+1)[:size]
+		sealed := encrypt(t, plain, "pass")
+		assert.True(t, bytes.HasPrefix(sealed, []byte(encryptedMagic)))
+
+		got, err := decrypt(sealed, "pass")
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, plain, got, "size %d", size)
+	}
+}
+
+func TestDecryptDetectsTampering(t *testing.T) {
+	plain := bytes.Repeat([]byte{7}, 2*chunkSize+100)
+	sealed := encrypt(t, plain, "pass")
+	header := len(encryptedMagic) + saltSize + 12
+	firstChunk := 4 + chunkSize + 16
+
+	_, err := decrypt(sealed, "other")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// Dropping the final chunk leaves a stream that ends on a full chunk
+	_, err = decrypt(sealed[:header+2*firstChunk], "pass")
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	// Swapping two chunks breaks their nonces
+	swapped := append([]byte{}, sealed[:header]...)
+	swapped = append(swapped, sealed[header+firstChunk:header+2*firstChunk]...)
+	swapped = append(swapped, sealed[header:header+firstChunk]...)
+	swapped = append(swapped, sealed[header+2*firstChunk:]...)
+	_, err = decrypt(swapped, "pass")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	_, err = decrypt(append(bytes.Clone(sealed), 0), "pass")
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
@@ -0,0 +1,18 @@
+// Package backup snapshots a profile's data directory and restores it.
+//
+// A snapshot is a gzipped tar of data/ (without the cache), optionally with
+// data/downloads, data/reports and license.dat left out or added per the
+// backup configuration. Its last entry is a manifest with the SHA-256 of
+// every file. With backup.passphrase set the archive is encrypted with
+// AES-256-GCM under a scrypt-derived key, in authenticated chunks so a
+// truncated or modified snapshot is detected.
+//
+// Restoring reads the whole snapshot into a staging directory and checks it
+// against the manifest before any file in data/ is replaced.
+//
+// Example usage:
+//
+//	manager := backup.NewManager(paths, cfg.Backup, logger)
+//	snap, err := manager.Create(ctx)
+//	manifest, err := manager.Restore(ctx, snap.Name)
+package backup
@@ -0,0 +1,13 @@
+// Package browser finds the Chrome the scraper drives and, when none is
+// installed, downloads a pinned headless Chromium build.
+//
+// The managed build is Chrome for Testing's chrome-headless-shell. Its
+// archive is downloaded once per version to data/cache/browser, checked
+// against the SHA-256 pinned for the platform and only then unpacked. A
+// partly downloaded or mismatching archive never becomes an installed build.
+//
+// Example usage:
+//
+//	fetcher := browser.NewFetcher(paths.BrowserDir, cfg.Scraper.Browser, logger)
+//	execPath, managed, err := browser.Resolve(ctx, fetcher)
+package browser
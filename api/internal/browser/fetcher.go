@@ -0,0 +1,323 @@
+package browser
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+)
+
+// DefaultDownloadURL is the Chrome for Testing archive of a build
+const DefaultDownloadURL = "https://storage.googleapis.com/chrome-for-testing-public/{version}/{platform}/chrome-headless-shell-{platform}.zip"
+
+// maxArchiveSize bounds a download; the archives are around 100 MB
+const maxArchiveSize = 512 << 20
+
+var (
+	// ErrDisabled is returned when no Chrome is installed and the managed
+	// browser is turned off
+	ErrDisabled = errors.New("managed browser disabled")
+	// ErrUnsupportedPlatform is returned where Chrome for Testing has no build
+	ErrUnsupportedPlatform = errors.New("no managed browser build for this platform")
+	// ErrNotPinned is returned when the build's checksum is unknown
+	ErrNotPinned = errors.New("no checksum pinned for the managed browser")
+	// ErrChecksumMismatch is returned when a downloaded archive does not
+	// match its pinned checksum
+	ErrChecksumMismatch = errors.New("managed browser checksum mismatch")
+)
+
+// Fetcher downloads and unpacks the managed browser build
+type Fetcher struct {
+	dir      string
+	cfg      config.BrowserConfig
+	logger   *slog.Logger
+	client   *http.Client
+	platform string
+}
+
+// NewFetcher creates a fetcher keeping builds under dir
+func NewFetcher(dir string, cfg config.BrowserConfig, logger *slog.Logger) *Fetcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Fetcher{
+		dir:      dir,
+		cfg:      cfg,
+		logger:   logger,
+		client:   &http.Client{Timeout: 15 * time.Minute},
+		platform: Platform(),
+	}
+}
+
+// Enabled reports whether the fetcher may download a build
+func (f *Fetcher) Enabled() bool {
+	return f.cfg.Managed
+}
+
+// Version returns the build the fetcher installs
+func (f *Fetcher) Version() string {
+	if f.cfg.Version != "" {
+		return f.cfg.Version
+	}
+	return PinnedVersion
+}
+
+// ExecPath returns where the build's executable is once installed
+func (f *Fetcher) ExecPath() string {
+	name := "chrome-headless-shell"
+	if strings.HasPrefix(f.platform, "win") {
+		name += ".exe"
+	}
+	return filepath.Join(f.dir, f.Version(), "chrome-headless-shell-"+f.platform, name)
+}
+
+// Installed returns the executable of the build when it is already unpacked
+func (f *Fetcher) Installed() (string, bool) {
+	path := f.ExecPath()
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path, true
+	}
+	return "", false
+}
+
+// Ensure returns the executable of the build, downloading and unpacking it
+// first when it is not installed yet
+func (f *Fetcher) Ensure(ctx context.Context) (string, error) {
+	if path, ok := f.Installed(); ok {
+		return path, nil
+	}
+	if !f.Enabled() {
+		return "", ErrDisabled
+	}
+	if f.platform == "" {
+		return "", fmt.Errorf("%w: %s/%s", ErrUnsupportedPlatform, runtime.GOOS, runtime.GOARCH)
+	}
+	checksum := f.checksum()
+	if checksum == "" {
+		return "", fmt.Errorf("%w: %s %s; set scraper.browser.version and scraper.browser.sha256", ErrNotPinned, f.Version(), f.platform)
+	}
+
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return "", fmt.Errorf("create browser directory: %w", err)
+	}
+	url := f.downloadURL()
+	f.logger.InfoContext(ctx, "Downloading managed browser",
+		slog.String("version", f.Version()),
+		slog.String("platform", f.platform),
+		slog.String("url", url))
+
+	archive, err := os.CreateTemp(f.dir, ".download-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("create browser download: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := f.download(ctx, url, archive, checksum); err != nil {
+		return "", err
+	}
+
+	// Unpack next to the final directory and rename it into place, so an
+	// interrupted unpack is never mistaken for an installed build
+	staging, err := os.MkdirTemp(f.dir, ".unpack-*")
+	if err != nil {
+		return "", fmt.Errorf("create browser staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := unzip(archive.Name(), staging); err != nil {
+		return "", fmt.Errorf("unpack managed browser: %w", err)
+	}
+	versionDir := filepath.Join(f.dir, f.Version())
+	rel, _ := filepath.Rel(versionDir, f.ExecPath())
+	if _, err := os.Stat(filepath.Join(staging, rel)); err != nil {
+		return "", fmt.Errorf("managed browser archive has no %s", filepath.ToSlash(rel))
+	}
+	if err := os.Rename(staging, versionDir); err != nil {
+		if path, ok := f.Installed(); ok {
+			return path, nil // Another scraper installed it meanwhile
+		}
+		return "", fmt.Errorf("install managed browser: %w", err)
+	}
+
+	path := f.ExecPath()
+	f.logger.InfoContext(ctx, "Managed browser installed", slog.String("path", path))
+	return path, nil
+}
+
+// checksum returns the expected SHA-256 of the build's archive
+func (f *Fetcher) checksum() string {
+	if f.cfg.SHA256 != "" || f.cfg.Version != "" {
+		return strings.ToLower(f.cfg.SHA256)
+	}
+	return pinnedSHA256[f.platform]
+}
+
+func (f *Fetcher) downloadURL() string {
+	url := f.cfg.DownloadURL
+	if url == "" {
+		url = DefaultDownloadURL
+	}
+	return strings.NewReplacer("{version}", f.Version(), "{platform}", f.platform).Replace(url)
+}
+
+// download writes the archive at url to out and checks its SHA-256
+func (f *Fetcher) download(ctx context.Context, url string, out io.Writer, checksum string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("download managed browser: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download managed browser: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download managed browser: %s returned %s", url, resp.Status)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, hash), io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return fmt.Errorf("download managed browser: %w", err)
+	}
+	if n > maxArchiveSize {
+		return fmt.Errorf("download managed browser: archive larger than %d bytes", maxArchiveSize)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != checksum {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, got, checksum)
+	}
+	return nil
+}
+
+// unzip extracts an archive under dir, refusing entries that escape it
+func unzip(archive, dir string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, entry := range r.File {
+		target := filepath.Join(dir, filepath.FromSlash(entry.Name))
+		if rel, err := filepath.Rel(dir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q escapes the browser directory", entry.Name)
+		}
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractFile(entry, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(entry *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	in, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	mode := entry.Mode().Perm() | 0600
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Platform returns the Chrome for Testing platform name of this machine, or
+// "" when there is no build for it
+func Platform() string {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux64"
+	case "darwin/amd64":
+		return "mac-x64"
+	case "darwin/arm64":
+		return "mac-arm64"
+	case "windows/386":
+		return "win32"
+	case "windows/amd64":
+		return "win64"
+	}
+	return ""
+}
+
+// FindSystem looks for an installed Chrome or Chromium where chromedp does:
+// on PATH and in the default install locations
+func FindSystem() (string, error) {
+	var candidates []string
+	switch runtime.GOOS {
+	case "windows":
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LocalAppData"} {
+			if dir := os.Getenv(env); dir != "" {
+				candidates = append(candidates,
+					filepath.Join(dir, "Google", "Chrome", "Application", "chrome.exe"),
+					filepath.Join(dir, "Chromium", "Application", "chrome.exe"))
+			}
+		}
+		candidates = append(candidates, "chrome.exe", "chrome")
+	case "darwin":
+		candidates = []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"google-chrome", "chromium",
+		}
+	default:
+		candidates = []string{"headless_shell", "headless-shell", "chromium", "chromium-browser",
+			"google-chrome", "google-chrome-stable", "google-chrome-beta", "google-chrome-unstable",
+			"/usr/bin/google-chrome"}
+	}
+
+	for _, candidate := range candidates {
+		if filepath.IsAbs(candidate) {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+			continue
+		}
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("chrome not found: %w", os.ErrNotExist)
+}
+
+// Resolve returns the browser the scraper should launch: an installed Chrome
+// first, then the managed build, downloaded if needed. managed is true for
+// the managed build.
+func Resolve(ctx context.Context, f *Fetcher) (path string, managed bool, err error) {
+	if path, err := FindSystem(); err == nil {
+		return path, false, nil
+	}
+	path, err = f.Ensure(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
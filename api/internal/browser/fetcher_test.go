@@ -0,0 +1,138 @@
+package browser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"isxcli/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildArchive zips files, name to content, the way Chrome for Testing lays
+// out its archives
+func buildArchive(t *testing.T, files map[string]string) ([]byte, string) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(0755)
+		entry, err := w.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+// serveArchive serves data for every request and counts them
+func serveArchive(t *testing.T, data []byte) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/130.0.0.0/linux64/chrome-headless-shell-linux64.zip", r.URL.Path)
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func testFetcher(dir string, cfg config.BrowserConfig) *Fetcher {
+	f := NewFetcher(dir, cfg, nil)
+	f.platform = "linux64"
+	return f
+}
+
+func TestFetcher_DownloadsOnce(t *testing.T) {
+	data, sum := buildArchive(t, map[string]string{
+		"chrome-headless-shell-linux64/chrome-headless-shell": "#!/bin/sh\n",
+		"chrome-headless-shell-linux64/icudtl.dat":            "icu",
+	})
+	server, requests := serveArchive(t, data)
+	dir := t.TempDir()
+	cfg := config.BrowserConfig{
+		Managed:     true,
+		DownloadURL: server.URL + "/{version}/{platform}/chrome-headless-shell-{platform}.zip",
+		Version:     "130.0.0.0",
+		SHA256:      sum,
+	}
+
+	path, err := testFetcher(dir, cfg).Ensure(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "130.0.0.0", "chrome-headless-shell-linux64", "chrome-headless-shell"), path)
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode().Perm()&0100, "the executable keeps its mode")
+	}
+
+	again, err := testFetcher(dir, cfg).Ensure(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, path, again)
+	assert.Equal(t, int32(1), requests.Load(), "an installed build is not downloaded again")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no download or staging leftovers")
+}
+
+func TestFetcher_RejectsBadArchives(t *testing.T) {
+	data, _ := buildArchive(t, map[string]string{"chrome-headless-shell-linux64/chrome-headless-shell": "x"})
+	server, _ := serveArchive(t, data)
+	url := server.URL + "/{version}/{platform}/chrome-headless-shell-{platform}.zip"
+
+	dir := t.TempDir()
+	f := testFetcher(dir, config.BrowserConfig{Managed: true, DownloadURL: url, Version: "130.0.0.0", SHA256: "00"})
+	_, err := f.Ensure(context.Background())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, installed := f.Installed()
+	assert.False(t, installed)
+
+	escaping, sum := buildArchive(t, map[string]string{"../evil": "x"})
+	server, _ = serveArchive(t, escaping)
+	f = testFetcher(dir, config.BrowserConfig{Managed: true, DownloadURL: server.URL + "/{version}/{platform}/chrome-headless-shell-{platform}.zip", Version: "130.0.0.0", SHA256: sum})
+	_, err = f.Ensure(context.Background())
+	assert.ErrorContains(t, err, "escapes")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), "evil"))
+
+	empty, sum := buildArchive(t, map[string]string{"README": "no browser"})
+	server, _ = serveArchive(t, empty)
+	f = testFetcher(dir, config.BrowserConfig{Managed: true, DownloadURL: server.URL + "/{version}/{platform}/chrome-headless-shell-{platform}.zip", Version: "130.0.0.0", SHA256: sum})
+	_, err = f.Ensure(context.Background())
+	assert.ErrorContains(t, err, "has no chrome-headless-shell-linux64/chrome-headless-shell")
+	assert.NoDirExists(t, filepath.Join(dir, "130.0.0.0"), "an archive without the browser is not installed")
+}
+
+func TestFetcher_DisabledAndUnpinned(t *testing.T) {
+	f := testFetcher(t.TempDir(), config.BrowserConfig{})
+	_, err := f.Ensure(context.Background())
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	f = testFetcher(t.TempDir(), config.BrowserConfig{Managed: true})
+	f.platform = "no-such-platform"
+	_, err = f.Ensure(context.Background())
+	assert.ErrorIs(t, err, ErrNotPinned)
+
+	f.platform = ""
+	_, err = f.Ensure(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupportedPlatform)
+}
+
+func TestFetcher_DownloadURL(t *testing.T) {
+	f := testFetcher(t.TempDir(), config.BrowserConfig{Managed: true})
+	assert.Equal(t, PinnedVersion, f.Version())
+	assert.Equal(t, "https://storage.googleapis.com/chrome-for-testing-public/"+PinnedVersion+"/linux64/chrome-headless-shell-linux64.zip", f.downloadURL())
+}
@@ -0,0 +1,11 @@
+package browser
+
+// PinnedVersion is the Chrome for Testing build downloaded when the
+// configuration does not name another one
+const PinnedVersion = "139.0.7258.66"
+
+// pinnedSHA256 holds the SHA-256 of the chrome-headless-shell archive of
+// PinnedVersion per platform. Run go run build.go -target=browser-pins after
+// changing PinnedVersion to record them; a platform without a checksum needs
+// scraper.browser.version and scraper.browser.sha256 to be configured.
+var pinnedSHA256 = map[string]string{}
@@ -2,12 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
 	"gopkg.in/yaml.v2"
+
+	"isxcli/internal/i18n"
+	"isxcli/pkg/contracts/schema"
 )
 
 // Config represents the complete application configuration
@@ -17,6 +22,20 @@ type Config struct {
 	Logging  LoggingConfig  `yaml:"logging" envconfig:"LOGGING"`
 	Paths    PathsConfig    `yaml:"paths" envconfig:"PATHS"`
 	WebSocket WebSocketConfig `yaml:"websocket" envconfig:"WEBSOCKET"`
+	Export   ExportConfig   `yaml:"export" envconfig:"EXPORT"`
+	Processing ProcessingConfig `yaml:"processing" envconfig:"PROCESSING"`
+	Update   UpdateConfig   `yaml:"update" envconfig:"UPDATE"`
+	Notifications NotificationsConfig `yaml:"notifications" envconfig:"NOTIFICATIONS"`
+	Health   HealthConfig   `yaml:"health" envconfig:"HEALTH"`
+	Cache    CacheConfig    `yaml:"cache" envconfig:"CACHE"`
+	Scraper  ScraperConfig  `yaml:"scraper" envconfig:"SCRAPER"`
+	Currency CurrencyConfig `yaml:"currency" envconfig:"CURRENCY"`
+	GRPC     GRPCConfig     `yaml:"grpc" envconfig:"GRPC"`
+	Operations OperationsConfig `yaml:"operations" envconfig:"OPERATIONS"`
+	Telemetry TelemetryConfig `yaml:"telemetry" envconfig:"TELEMETRY"`
+	Backup   BackupConfig   `yaml:"backup" envconfig:"BACKUP"`
+	Audit    AuditConfig    `yaml:"audit" envconfig:"AUDIT"`
+	Auth     AuthConfig     `yaml:"auth" envconfig:"AUTH"`
 }
 
 // ServerConfig contains HTTP server configuration
@@ -28,6 +47,8 @@ type ServerConfig struct {
 	MaxHeaderBytes   int           `yaml:"max_header_bytes" envconfig:"MAX_HEADER_BYTES" default:"1048576"`
 	ShutdownTimeout  time.Duration `yaml:"shutdown_timeout" envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
 	OperationTimeout time.Duration `yaml:"operation_timeout" envconfig:"OPERATION_TIMEOUT" default:"2h"`
+	// EnableStatusJSON exposes the unauthenticated /status.json endpoint for uptime monitors
+	EnableStatusJSON bool          `yaml:"enable_status_json" envconfig:"ENABLE_STATUS_JSON" default:"false"`
 }
 
 // SecurityConfig contains security-related configuration
@@ -36,6 +57,18 @@ type SecurityConfig struct {
 	EnableCORS     bool     `yaml:"enable_cors" envconfig:"ENABLE_CORS" default:"true"`
 	EnableCSRF     bool     `yaml:"enable_csrf" envconfig:"ENABLE_CSRF" default:"false"`
 	RateLimit      RateLimitConfig `yaml:"rate_limit" envconfig:"RATE_LIMIT"`
+	// UsersFile lists users with their role and API token. Roles are only
+	// enforced when it is set.
+	UsersFile string `yaml:"users_file" envconfig:"USERS_FILE"`
+	// LicenseStore selects how the local license is stored: file (plain
+	// JSON), encrypted (AES-GCM keyed from the device fingerprint) or
+	// keychain (Windows DPAPI)
+	LicenseStore string `yaml:"license_store" envconfig:"LICENSE_STORE" default:"file"`
+	// MaxBodyBytes caps the body of any API request; larger requests are
+	// refused with 413 before the handler reads them
+	MaxBodyBytes int64 `yaml:"max_body_bytes" envconfig:"MAX_BODY_BYTES" default:"1048576"`
+	// MaxJSONBodyBytes caps the JSON bodies checked by the upload guard
+	MaxJSONBodyBytes int64 `yaml:"max_json_body_bytes" envconfig:"MAX_JSON_BODY_BYTES" default:"65536"`
 }
 
 // RateLimitConfig contains rate limiting configuration
@@ -71,6 +104,300 @@ type WebSocketConfig struct {
 	PongWait        time.Duration `yaml:"pong_wait" envconfig:"PONG_WAIT" default:"60s"`
 }
 
+// ExportConfig contains number and date formatting for user-facing exports.
+// The pipeline's own CSVs under data/reports keep the canonical format their
+// readers expect.
+type ExportConfig struct {
+	PriceDecimals      int            `yaml:"price_decimals" envconfig:"PRICE_DECIMALS" default:"3"`
+	ValueDecimals      int            `yaml:"value_decimals" envconfig:"VALUE_DECIMALS" default:"2"`
+	PercentDecimals    int            `yaml:"percent_decimals" envconfig:"PERCENT_DECIMALS" default:"2"`
+	ColumnDecimals     map[string]int `yaml:"column_decimals" envconfig:"COLUMN_DECIMALS"` // Per-column overrides, e.g. ClosePrice:4
+	ThousandsSeparator string         `yaml:"thousands_separator" envconfig:"THOUSANDS_SEPARATOR"`
+	DateFormat         string         `yaml:"date_format" envconfig:"DATE_FORMAT" default:"2006-01-02"`
+	BOM                bool           `yaml:"bom" envconfig:"BOM" default:"false"`
+	// Currency is the default currency of bundle exports: IQD or USD
+	Currency string `yaml:"currency" envconfig:"CURRENCY" default:"IQD"`
+	// Language is the default column header language of bundle exports and
+	// the liquidity workbook: en or ar. Stored reports stay in English.
+	Language string `yaml:"language" envconfig:"LANGUAGE" default:"en"`
+	// Digits is the default numbering system of bundle exports: latin or
+	// arabic (Arabic-Indic digits, which spreadsheets read as text)
+	Digits string `yaml:"digits" envconfig:"DIGITS" default:"latin"`
+}
+
+// ProcessingConfig contains processor settings
+type ProcessingConfig struct {
+	// FillStrategy is how days a symbol did not trade are filled:
+	// carry_forward, none, nan or interpolate
+	FillStrategy string `yaml:"fill_strategy" envconfig:"FILL_STRATEGY" default:"carry_forward"`
+	// Watch runs processing, index extraction and liquidity whenever new
+	// reports appear in data/downloads, e.g. copied there by hand
+	Watch bool `yaml:"watch" envconfig:"WATCH"`
+	// WatchInterval is how often the downloads directory is scanned. A new
+	// file is picked up once it is unchanged across two scans.
+	WatchInterval time.Duration `yaml:"watch_interval" envconfig:"WATCH_INTERVAL" default:"30s"`
+	// Anomalies configures the checks flagging unusual trading days after
+	// each processing run
+	Anomalies AnomalyConfig `yaml:"anomalies" envconfig:"ANOMALIES"`
+	// Correlations configures the pairwise return correlations and betas
+	// against ISX60 written after each index extraction
+	Correlations CorrelationConfig `yaml:"correlations" envconfig:"CORRELATIONS"`
+	// ColumnMap names the header an external combined CSV uses for a trade
+	// record column, e.g. ClosePrice:Last Trade. Columns left out are matched
+	// by their own name and common aliases.
+	ColumnMap map[string]string `yaml:"column_map" envconfig:"COLUMN_MAP"`
+}
+
+// AnomalyConfig contains the anomaly detection thresholds
+type AnomalyConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"ENABLED" default:"true"`
+	// Lookback is the number of earlier sessions a ticker traded that its
+	// returns and volume are compared with
+	Lookback int `yaml:"lookback" envconfig:"LOOKBACK" default:"30"`
+	// ZScore is the return z-score above which a price move is flagged
+	ZScore float64 `yaml:"z_score" envconfig:"Z_SCORE" default:"4"`
+	// VolumeMultiple is the multiple of average volume above which volume is
+	// flagged
+	VolumeMultiple float64 `yaml:"volume_multiple" envconfig:"VOLUME_MULTIPLE" default:"5"`
+}
+
+// CorrelationConfig contains the ticker correlation and beta settings
+type CorrelationConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"ENABLED" default:"true"`
+	// Windows are the lookback windows in trading sessions
+	Windows []int `yaml:"windows" envconfig:"WINDOWS" default:"60,120,250"`
+	// MinObservations is the fewest daily returns two tickers, or a ticker
+	// and the index, must share in a window to be reported
+	MinObservations int `yaml:"min_observations" envconfig:"MIN_OBSERVATIONS" default:"20"`
+}
+
+// UpdateConfig contains self-update settings
+type UpdateConfig struct {
+	// Channel is the release channel to follow: stable or beta
+	Channel string `yaml:"channel" envconfig:"CHANNEL" default:"stable"`
+}
+
+// HealthConfig contains the thresholds of the detailed health checks. Zero
+// values fall back to the defaults.
+type HealthConfig struct {
+	// Trading days the latest downloaded report may lag the expected one
+	StaleWarningDays  int `yaml:"stale_warning_days" envconfig:"STALE_WARNING_DAYS" default:"1"`
+	StaleCriticalDays int `yaml:"stale_critical_days" envconfig:"STALE_CRITICAL_DAYS" default:"3"`
+	// Days before license expiry
+	LicenseWarningDays  int `yaml:"license_warning_days" envconfig:"LICENSE_WARNING_DAYS" default:"30"`
+	LicenseCriticalDays int `yaml:"license_critical_days" envconfig:"LICENSE_CRITICAL_DAYS" default:"7"`
+	// Free space left on the data directory's disk, in percent
+	DiskWarningPercent  float64 `yaml:"disk_warning_percent" envconfig:"DISK_WARNING_PERCENT" default:"10"`
+	DiskCriticalPercent float64 `yaml:"disk_critical_percent" envconfig:"DISK_CRITICAL_PERCENT" default:"5"`
+}
+
+// CacheConfig contains the in-memory report data cache settings. Zero values
+// fall back to the defaults.
+type CacheConfig struct {
+	// ReportTTL is how long a parsed report file is served before it is re-read
+	ReportTTL time.Duration `yaml:"report_ttl" envconfig:"REPORT_TTL" default:"5m"`
+	// ReportMaxEntries bounds the number of cached report files
+	ReportMaxEntries int `yaml:"report_max_entries" envconfig:"REPORT_MAX_ENTRIES" default:"256"`
+}
+
+// ScraperConfig contains how the scraper paces its downloads and identifies
+// itself to isx-iq.net. Zero values keep the defaults: no delay, the
+// browser's and Go's own User-Agent, and proxies from HTTP_PROXY/HTTPS_PROXY.
+type ScraperConfig struct {
+	// Each report download waits a random delay between DelayMin and DelayMax
+	DelayMin time.Duration `yaml:"delay_min" envconfig:"DELAY_MIN"`
+	DelayMax time.Duration `yaml:"delay_max" envconfig:"DELAY_MAX"`
+	// UserAgent is sent by Chrome and by the direct HTTP requests
+	UserAgent string `yaml:"user_agent" envconfig:"USER_AGENT"`
+	// Proxy is an http://, https://, socks5:// or socks5h:// URL every scraper
+	// request goes through, Chrome's included
+	Proxy string `yaml:"proxy" envconfig:"PROXY"`
+	// Browser is the Chromium downloaded when no Chrome is installed
+	Browser BrowserConfig `yaml:"browser" envconfig:"BROWSER"`
+}
+
+// BrowserConfig controls the managed headless Chromium the scraper downloads
+// to data/cache/browser on first use when no Chrome or Chromium is
+// installed. Disable it where downloads are not allowed; the scraper then
+// falls back to its HTTP engine.
+type BrowserConfig struct {
+	Managed bool `yaml:"managed" envconfig:"MANAGED" default:"true"`
+	// DownloadURL is the archive URL, with {version} and {platform}
+	// placeholders, e.g. an internal mirror; empty uses Chrome for Testing
+	DownloadURL string `yaml:"download_url" envconfig:"DOWNLOAD_URL"`
+	// Version replaces the pinned build; SHA256, the archive's checksum for
+	// this platform, must then be set too
+	Version string `yaml:"version" envconfig:"VERSION"`
+	SHA256  string `yaml:"sha256" envconfig:"SHA256"`
+}
+
+// CurrencyConfig contains where USD conversion rates come from. The CBI
+// official rates shipped with the application and data/reference/
+// exchange_rates.csv are always used; RatesURL adds rates fetched from a file
+// in the same Date,IQDPerUSD layout.
+type CurrencyConfig struct {
+	RatesURL string `yaml:"rates_url" envconfig:"RATES_URL"`
+	// FetchInterval is how often RatesURL is fetched
+	FetchInterval time.Duration `yaml:"fetch_interval" envconfig:"FETCH_INTERVAL" default:"24h"`
+}
+
+// GRPCConfig contains the optional gRPC server settings. The gRPC API
+// mirrors the data, liquidity and operations endpoints and applies the same
+// license check and API tokens as the HTTP API.
+type GRPCConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"ENABLED"`
+	Port    int  `yaml:"port" envconfig:"PORT" default:"9090"`
+	// Reflection lets tools such as grpcurl list the services
+	Reflection bool `yaml:"reflection" envconfig:"REFLECTION" default:"true"`
+}
+
+// OperationsConfig controls how many operations run at once. Operations
+// sharing data/downloads or data/reports never run together; the rest wait in
+// a queue or are refused with 409, per OnConflict.
+type OperationsConfig struct {
+	// MaxConcurrent is the most operations running at once; 0 leaves only
+	// the directory locks
+	MaxConcurrent int `yaml:"max_concurrent" envconfig:"MAX_CONCURRENT" default:"1"`
+	// OnConflict is queue (wait for the conflicting run) or reject
+	OnConflict string `yaml:"on_conflict" envconfig:"ON_CONFLICT" default:"queue"`
+	// HistoryRuns is how many finished runs operations/history.json keeps
+	HistoryRuns int `yaml:"history_runs" envconfig:"HISTORY_RUNS" default:"500"`
+}
+
+// TelemetryConfig contains the anonymous usage counter settings. Counters
+// are always kept locally in data/telemetry.json.
+type TelemetryConfig struct {
+	// Enabled opts in to sending the counters with the periodic remote
+	// license validation
+	Enabled bool `yaml:"enabled" envconfig:"ENABLED"`
+	// FlushInterval is how often changed counters are saved
+	FlushInterval time.Duration `yaml:"flush_interval" envconfig:"FLUSH_INTERVAL" default:"1m"`
+}
+
+// AuditConfig contains the audit log settings. State-changing API calls are
+// appended to data/audit.jsonl.
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"ENABLED" default:"true"`
+}
+
+// AuthConfig contains the web UI sign-in settings. Signed-in users get the
+// role of their account, as API tokens from security.users_file do.
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"ENABLED"`
+	// Required refuses every API call without a session or API token;
+	// otherwise anonymous callers keep viewer access
+	Required bool `yaml:"required" envconfig:"REQUIRED"`
+	// SessionTTL is how long a sign-in lasts
+	SessionTTL time.Duration `yaml:"session_ttl" envconfig:"SESSION_TTL" default:"12h"`
+	// SecureCookie marks the session cookie Secure even on plain HTTP, for
+	// TLS proxies that do not send X-Forwarded-Proto
+	SecureCookie bool `yaml:"secure_cookie" envconfig:"SECURE_COOKIE"`
+
+	Local LocalAuthConfig `yaml:"local" envconfig:"LOCAL"`
+	OIDC  OIDCConfig      `yaml:"oidc" envconfig:"OIDC"`
+}
+
+// LocalAuthConfig contains the settings of accounts kept in data/auth
+type LocalAuthConfig struct {
+	Enabled           bool `yaml:"enabled" envconfig:"ENABLED" default:"true"`
+	MinPasswordLength int  `yaml:"min_password_length" envconfig:"MIN_PASSWORD_LENGTH" default:"10"`
+}
+
+// OIDCConfig contains the OpenID Connect provider settings, e.g. Azure AD or
+// Google
+type OIDCConfig struct {
+	Enabled bool   `yaml:"enabled" envconfig:"ENABLED"`
+	Issuer  string `yaml:"issuer" envconfig:"ISSUER"`
+	ClientID string `yaml:"client_id" envconfig:"CLIENT_ID"`
+	// ClientSecret is best set through ISX_AUTH_OIDC_CLIENT_SECRET
+	ClientSecret string `yaml:"client_secret" envconfig:"CLIENT_SECRET"`
+	// RedirectURL is this server's callback, ending in /api/auth/oidc/callback
+	RedirectURL string   `yaml:"redirect_url" envconfig:"REDIRECT_URL"`
+	Scopes      []string `yaml:"scopes" envconfig:"SCOPES" default:"openid,email,profile"`
+	// DefaultRole is the role of users not listed in Roles; empty admits
+	// listed users only
+	DefaultRole string `yaml:"default_role" envconfig:"DEFAULT_ROLE" default:"viewer"`
+	// Roles maps e-mail addresses to admin, operator or viewer
+	Roles map[string]string `yaml:"roles" envconfig:"ROLES"`
+	// AllowedDomains limits sign-in to e-mail addresses of these domains
+	AllowedDomains []string `yaml:"allowed_domains" envconfig:"ALLOWED_DOMAINS"`
+}
+
+// BackupConfig contains the data directory snapshot settings. Snapshots are
+// written to the backups directory next to data/.
+type BackupConfig struct {
+	// Passphrase encrypts snapshots with AES-256-GCM; empty writes them
+	// unencrypted. Prefer ISX_BACKUP_PASSPHRASE over the config file.
+	Passphrase string `yaml:"passphrase" envconfig:"PASSPHRASE"`
+	// IncludeDownloads and IncludeReports add data/downloads and
+	// data/reports; reports can be regenerated from the downloads
+	IncludeDownloads bool `yaml:"include_downloads" envconfig:"INCLUDE_DOWNLOADS" default:"true"`
+	IncludeReports   bool `yaml:"include_reports" envconfig:"INCLUDE_REPORTS" default:"true"`
+	// IncludeLicense adds license.dat, which is usually bound to this device
+	IncludeLicense bool `yaml:"include_license" envconfig:"INCLUDE_LICENSE"`
+	// Keep is how many snapshots are kept; older ones are deleted after a
+	// new one is written. 0 keeps all.
+	Keep int `yaml:"keep" envconfig:"KEEP" default:"10"`
+}
+
+// ProxyURL parses Proxy, returning nil when no proxy is configured
+func (c ScraperConfig) ProxyURL() (*url.URL, error) {
+	if c.Proxy == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(c.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scraper proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("scraper proxy scheme must be http, https, socks5 or socks5h: %q", c.Proxy)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("scraper proxy has no host: %q", c.Proxy)
+	}
+	return u, nil
+}
+
+// NotificationsConfig contains operator notification settings. A channel is
+// used when its credentials are set; the toggles pick which events are sent.
+type NotificationsConfig struct {
+	OperationCompleted bool `yaml:"operation_completed" envconfig:"OPERATION_COMPLETED" default:"true"`
+	OperationFailed    bool `yaml:"operation_failed" envconfig:"OPERATION_FAILED" default:"true"`
+	LicenseCritical    bool `yaml:"license_critical" envconfig:"LICENSE_CRITICAL" default:"true"`
+	WatchlistAlerts    bool `yaml:"watchlist_alerts" envconfig:"WATCHLIST_ALERTS" default:"true"`
+
+	SMTP     SMTPConfig     `yaml:"smtp" envconfig:"SMTP"`
+	Telegram TelegramConfig `yaml:"telegram" envconfig:"TELEGRAM"`
+}
+
+// SMTPConfig contains the mail server notifications are sent through
+type SMTPConfig struct {
+	Host     string   `yaml:"host" envconfig:"HOST"`
+	Port     int      `yaml:"port" envconfig:"PORT" default:"587"`
+	Username string   `yaml:"username" envconfig:"USERNAME"`
+	Password string   `yaml:"password" envconfig:"PASSWORD"`
+	From     string   `yaml:"from" envconfig:"FROM"`
+	To       []string `yaml:"to" envconfig:"TO"`
+}
+
+// Enabled reports whether mail notifications are configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != "" && len(c.To) > 0
+}
+
+// TelegramConfig contains the bot notifications are sent through
+type TelegramConfig struct {
+	BotToken string `yaml:"bot_token" envconfig:"BOT_TOKEN"`
+	ChatID   string `yaml:"chat_id" envconfig:"CHAT_ID"`
+}
+
+// Enabled reports whether Telegram notifications are configured
+func (c TelegramConfig) Enabled() bool {
+	return c.BotToken != "" && c.ChatID != ""
+}
+
 // Load loads configuration from environment variables and config file
 func Load() (*Config, error) {
 	var cfg Config
@@ -214,6 +541,21 @@ func (c *Config) GetLogsDir() string {
 	return paths.LogsDir
 }
 
+// LicenseStoreKind returns the configured license store without loading and
+// validating the whole configuration, so every binary opens the license the
+// same way. The environment takes precedence over the config file, as in Load.
+func LicenseStoreKind() string {
+	if kind := os.Getenv("ISX_SECURITY_LICENSE_STORE"); kind != "" {
+		return kind
+	}
+	if configFile := getConfigFilePath(); configFile != "" {
+		if fileConfig, err := loadFromFile(configFile); err == nil && fileConfig.Security.LicenseStore != "" {
+			return fileConfig.Security.LicenseStore
+		}
+	}
+	return "file"
+}
+
 // GetLicenseFile returns the resolved license file path
 func (c *Config) GetLicenseFile() string {
 	// Use GetLicensePath as the single source of truth
@@ -233,6 +575,15 @@ func (c *Config) validate() error {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
 
+	if c.GRPC.Enabled {
+		if c.GRPC.Port <= 0 || c.GRPC.Port > 65535 {
+			return fmt.Errorf("invalid grpc port: %d", c.GRPC.Port)
+		}
+		if c.GRPC.Port == c.Server.Port {
+			return fmt.Errorf("grpc port must differ from the server port")
+		}
+	}
+
 	if c.Server.ReadTimeout <= 0 {
 		return fmt.Errorf("server read timeout must be positive")
 	}
@@ -260,9 +611,174 @@ func (c *Config) validate() error {
 		c.Logging.FilePath = "logs/app.log"
 	}
 
+	for name, decimals := range map[string]int{
+		"price_decimals":   c.Export.PriceDecimals,
+		"value_decimals":   c.Export.ValueDecimals,
+		"percent_decimals": c.Export.PercentDecimals,
+	} {
+		if decimals < 0 || decimals > 10 {
+			return fmt.Errorf("export %s must be between 0 and 10: %d", name, decimals)
+		}
+	}
+
+	if c.Export.DateFormat == "" {
+		c.Export.DateFormat = "2006-01-02"
+	}
+
+	if smtp := c.Notifications.SMTP; smtp.Host != "" {
+		if smtp.Port <= 0 || smtp.Port > 65535 {
+			return fmt.Errorf("invalid notifications smtp port: %d", smtp.Port)
+		}
+		if len(smtp.To) == 0 {
+			return fmt.Errorf("notifications smtp requires at least one recipient")
+		}
+	}
+
+	h := c.Health
+	if h.StaleWarningDays < 0 || h.StaleCriticalDays < 0 || h.LicenseWarningDays < 0 || h.LicenseCriticalDays < 0 {
+		return fmt.Errorf("health thresholds must not be negative")
+	}
+	if h.StaleWarningDays > 0 && h.StaleCriticalDays > 0 && h.StaleWarningDays > h.StaleCriticalDays {
+		return fmt.Errorf("health stale_warning_days (%d) must not exceed stale_critical_days (%d)", h.StaleWarningDays, h.StaleCriticalDays)
+	}
+	if h.DiskWarningPercent < 0 || h.DiskWarningPercent > 100 || h.DiskCriticalPercent < 0 || h.DiskCriticalPercent > 100 {
+		return fmt.Errorf("health disk thresholds must be between 0 and 100 percent")
+	}
+
+	if c.Security.MaxBodyBytes < 0 || c.Security.MaxJSONBodyBytes < 0 {
+		return fmt.Errorf("security body size limits must not be negative")
+	}
+	if c.Security.MaxBodyBytes > 0 && c.Security.MaxJSONBodyBytes > c.Security.MaxBodyBytes {
+		return fmt.Errorf("security max_json_body_bytes (%d) must not exceed max_body_bytes (%d)", c.Security.MaxJSONBodyBytes, c.Security.MaxBodyBytes)
+	}
+
+	switch c.Security.LicenseStore {
+	case "", "file", "encrypted", "keychain":
+	default:
+		return fmt.Errorf("invalid security license_store %q: must be file, encrypted or keychain", c.Security.LicenseStore)
+	}
+
+	switch strings.ToUpper(c.Export.Currency) {
+	case "", "IQD", "USD":
+	default:
+		return fmt.Errorf("invalid export currency %q: must be IQD or USD", c.Export.Currency)
+	}
+	if _, err := i18n.NewLocale(c.Export.Language, c.Export.Digits); err != nil {
+		return fmt.Errorf("invalid export locale: %w", err)
+	}
+	if c.Currency.RatesURL != "" && c.Currency.FetchInterval <= 0 {
+		return fmt.Errorf("currency fetch_interval must be positive")
+	}
+
+	if c.Operations.MaxConcurrent < 0 {
+		return fmt.Errorf("operations max_concurrent must not be negative")
+	}
+	switch strings.ToLower(c.Operations.OnConflict) {
+	case "", "queue", "reject":
+	default:
+		return fmt.Errorf("invalid operations on_conflict %q: must be queue or reject", c.Operations.OnConflict)
+	}
+	if c.Operations.HistoryRuns < 0 {
+		return fmt.Errorf("operations history_runs must not be negative")
+	}
+
+	if c.Telemetry.FlushInterval < 0 {
+		return fmt.Errorf("telemetry flush_interval must not be negative")
+	}
+
+	if c.Backup.Keep < 0 {
+		return fmt.Errorf("backup keep must not be negative")
+	}
+
+	if err := c.Auth.validate(); err != nil {
+		return err
+	}
+
+	if c.Processing.Watch && c.Processing.WatchInterval <= 0 {
+		return fmt.Errorf("processing watch_interval must be positive")
+	}
+	if err := schema.ValidateOverrides(c.Processing.ColumnMap); err != nil {
+		return fmt.Errorf("processing column_map: %w", err)
+	}
+	if a := c.Processing.Anomalies; a.Enabled && (a.Lookback <= 0 || a.ZScore <= 0 || a.VolumeMultiple <= 0) {
+		return fmt.Errorf("processing anomalies lookback, z_score and volume_multiple must be positive")
+	}
+	if c := c.Processing.Correlations; c.Enabled {
+		if c.MinObservations < 3 {
+			return fmt.Errorf("processing correlations min_observations must be at least 3")
+		}
+		if len(c.Windows) == 0 {
+			return fmt.Errorf("processing correlations windows must not be empty")
+		}
+		for _, w := range c.Windows {
+			if w < c.MinObservations {
+				return fmt.Errorf("processing correlations window %d is shorter than min_observations (%d)", w, c.MinObservations)
+			}
+		}
+	}
+
+	if sc := c.Scraper; sc.DelayMin < 0 || sc.DelayMax < 0 {
+		return fmt.Errorf("scraper delays must not be negative")
+	} else if sc.DelayMax > 0 && sc.DelayMax < sc.DelayMin {
+		return fmt.Errorf("scraper delay_max (%s) must not be less than delay_min (%s)", sc.DelayMax, sc.DelayMin)
+	}
+	if _, err := c.Scraper.ProxyURL(); err != nil {
+		return err
+	}
+	if b := c.Scraper.Browser; b.Version != "" && b.SHA256 == "" {
+		return fmt.Errorf("scraper browser sha256 is required with browser version %q", b.Version)
+	}
+	if u := c.Scraper.Browser.DownloadURL; u != "" && !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		return fmt.Errorf("invalid scraper browser download_url %q: must be an http or https URL", u)
+	}
+
 	return nil
 }
 
+// validate checks the sign-in settings; they are only checked when enabled
+func (c AuthConfig) validate() error {
+	if !c.Enabled {
+		if c.Required {
+			return fmt.Errorf("auth required needs auth enabled")
+		}
+		return nil
+	}
+	if c.SessionTTL <= 0 {
+		return fmt.Errorf("auth session_ttl must be positive")
+	}
+	if !c.Local.Enabled && !c.OIDC.Enabled {
+		return fmt.Errorf("auth needs local or oidc sign-in enabled")
+	}
+	if c.Local.Enabled && c.Local.MinPasswordLength < 8 {
+		return fmt.Errorf("auth local min_password_length must be at least 8")
+	}
+	if o := c.OIDC; o.Enabled {
+		if o.Issuer == "" || o.ClientID == "" || o.RedirectURL == "" {
+			return fmt.Errorf("auth oidc requires issuer, client_id and redirect_url")
+		}
+		if !strings.HasPrefix(o.Issuer, "https://") && !strings.HasPrefix(o.Issuer, "http://") {
+			return fmt.Errorf("invalid auth oidc issuer %q: must be an http or https URL", o.Issuer)
+		}
+		if o.DefaultRole != "" && !validRole(o.DefaultRole) {
+			return fmt.Errorf("invalid auth oidc default_role %q: must be admin, operator or viewer", o.DefaultRole)
+		}
+		for email, role := range o.Roles {
+			if !validRole(role) {
+				return fmt.Errorf("invalid auth oidc role %q for %s: must be admin, operator or viewer", role, email)
+			}
+		}
+	}
+	return nil
+}
+
+func validRole(role string) bool {
+	switch role {
+	case "admin", "operator", "viewer":
+		return true
+	}
+	return false
+}
+
 // getConfigFilePath returns the path to the config file
 func getConfigFilePath() string {
 	// Check for config file in common locations
@@ -292,6 +808,7 @@ func Default() *Config {
 			IdleTimeout:     60 * time.Second,
 			MaxHeaderBytes:  1 << 20, // 1MB
 			ShutdownTimeout: 30 * time.Second,
+			EnableStatusJSON: false,
 		},
 		Security: SecurityConfig{
 			AllowedOrigins: []string{"http://localhost:8080"},
@@ -302,6 +819,9 @@ func Default() *Config {
 				RPS:     100,
 				Burst:   50,
 			},
+			LicenseStore:     "file",
+			MaxBodyBytes:     1 << 20,
+			MaxJSONBodyBytes: 64 << 10,
 		},
 		Logging: LoggingConfig{
 			Level:       "info",
@@ -322,5 +842,92 @@ func Default() *Config {
 			PingPeriod:      30 * time.Second,
 			PongWait:        60 * time.Second,
 		},
+		Export: ExportConfig{
+			PriceDecimals:   3,
+			ValueDecimals:   2,
+			PercentDecimals: 2,
+			DateFormat:      "2006-01-02",
+			Currency:        "IQD",
+			Language:        "en",
+			Digits:          "latin",
+		},
+		Processing: ProcessingConfig{
+			FillStrategy:  "carry_forward",
+			WatchInterval: 30 * time.Second,
+			Anomalies: AnomalyConfig{
+				Enabled:        true,
+				Lookback:       30,
+				ZScore:         4,
+				VolumeMultiple: 5,
+			},
+			Correlations: CorrelationConfig{
+				Enabled:         true,
+				Windows:         []int{60, 120, 250},
+				MinObservations: 20,
+			},
+		},
+		Update: UpdateConfig{
+			Channel: "stable",
+		},
+		Notifications: NotificationsConfig{
+			OperationCompleted: true,
+			OperationFailed:    true,
+			LicenseCritical:    true,
+			WatchlistAlerts:    true,
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+		Health: HealthConfig{
+			StaleWarningDays:    1,
+			StaleCriticalDays:   3,
+			LicenseWarningDays:  30,
+			LicenseCriticalDays: 7,
+			DiskWarningPercent:  10,
+			DiskCriticalPercent: 5,
+		},
+		Cache: CacheConfig{
+			ReportTTL:        5 * time.Minute,
+			ReportMaxEntries: 256,
+		},
+		Currency: CurrencyConfig{
+			FetchInterval: 24 * time.Hour,
+		},
+		GRPC: GRPCConfig{
+			Port:       9090,
+			Reflection: true,
+		},
+		Operations: OperationsConfig{
+			MaxConcurrent: 1,
+			OnConflict:    "queue",
+			HistoryRuns:   500,
+		},
+		Telemetry: TelemetryConfig{
+			FlushInterval: time.Minute,
+		},
+		Scraper: ScraperConfig{
+			Browser: BrowserConfig{
+				Managed: true,
+			},
+		},
+		Backup: BackupConfig{
+			IncludeDownloads: true,
+			IncludeReports:   true,
+			Keep:             10,
+		},
+		Audit: AuditConfig{
+			Enabled: true,
+		},
+		Auth: AuthConfig{
+			SessionTTL: 12 * time.Hour,
+			Local: LocalAuthConfig{
+				Enabled:           true,
+				MinPasswordLength: 10,
+			},
+			OIDC: OIDCConfig{
+				Scopes:      []string{"openid", "email", "profile"},
+				DefaultRole: "viewer",
+			},
+		},
 	}
 }
\ No newline at end of file
@@ -62,6 +62,7 @@ func TestLoad(t *testing.T) {
 				assert.Equal(t, 60*time.Second, cfg.Server.IdleTimeout)
 				assert.Equal(t, 1048576, cfg.Server.MaxHeaderBytes)
 				assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout)
+				assert.False(t, cfg.Server.EnableStatusJSON, "/status.json is opt-in")
 				
 				assert.Equal(t, []string{"http://localhost:8080"}, cfg.Security.AllowedOrigins)
 				assert.True(t, cfg.Security.EnableCORS)
@@ -69,6 +70,10 @@ func TestLoad(t *testing.T) {
 				assert.True(t, cfg.Security.RateLimit.Enabled)
 				assert.Equal(t, 100.0, cfg.Security.RateLimit.RPS)
 				assert.Equal(t, 50, cfg.Security.RateLimit.Burst)
+				assert.Equal(t, int64(1048576), cfg.Security.MaxBodyBytes)
+				assert.Equal(t, int64(65536), cfg.Security.MaxJSONBodyBytes)
+				assert.Equal(t, 1, cfg.Operations.MaxConcurrent)
+				assert.Equal(t, "queue", cfg.Operations.OnConflict)
 				
 				assert.Equal(t, "info", cfg.Logging.Level)
 				assert.Equal(t, "json", cfg.Logging.Format)
@@ -428,6 +433,252 @@ func TestValidate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "smtp notifications without recipients",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Notifications.SMTP.Host = "smtp.example.com"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "notifications smtp requires at least one recipient",
+		},
+		{
+			name: "health stale warning after critical",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Health.StaleWarningDays = 5
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "stale_warning_days (5) must not exceed stale_critical_days (3)",
+		},
+		{
+			name: "health disk threshold above 100 percent",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Health.DiskWarningPercent = 150
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "health disk thresholds must be between 0 and 100 percent",
+		},
+		{
+			name: "unknown license store",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Security.LicenseStore = "registry"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "invalid security license_store \"registry\"",
+		},
+		{
+			name: "unknown operations conflict policy",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Operations.OnConflict = "wait"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "invalid operations on_conflict \"wait\"",
+		},
+		{
+			name: "negative operation history",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Operations.HistoryRuns = -1
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "operations history_runs must not be negative",
+		},
+		{
+			name: "unknown export currency",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Export.Currency = "EUR"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "invalid export currency \"EUR\"",
+		},
+		{
+			name: "unknown export language",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Export.Language = "fr"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "invalid export locale: unknown language \"fr\"",
+		},
+		{
+			name: "unknown export digits",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Export.Digits = "hindi"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "invalid export locale: unknown digits \"hindi\"",
+		},
+		{
+			name: "processing watch without interval",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Processing.Watch = true
+				cfg.Processing.WatchInterval = 0
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "processing watch_interval must be positive",
+		},
+		{
+			name: "grpc on the server port",
+			config: func() Config {
+				cfg := *Default()
+				cfg.GRPC.Enabled = true
+				cfg.GRPC.Port = cfg.Server.Port
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "grpc port must differ from the server port",
+		},
+		{
+			name: "anomaly detection without z-score",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Processing.Anomalies.ZScore = 0
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "processing anomalies lookback, z_score and volume_multiple must be positive",
+		},
+		{
+			name: "correlation window shorter than min observations",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Processing.Correlations.Windows = []int{10, 60}
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "processing correlations window 10 is shorter than min_observations (20)",
+		},
+		{
+			name: "auth with oidc",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Auth.Enabled = true
+				cfg.Auth.OIDC = OIDCConfig{
+					Enabled:     true,
+					Issuer:      "https://login.microsoftonline.com/tenant/v2.0",
+					ClientID:    "isx-pulse",
+					RedirectURL: "https://pulse.example.com/api/auth/oidc/callback",
+					Roles:       map[string]string{"ops@example.com": "operator"},
+				}
+				return cfg
+			}(),
+		},
+		{
+			name: "auth oidc without client id",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Auth.Enabled = true
+				cfg.Auth.OIDC.Enabled = true
+				cfg.Auth.OIDC.Issuer = "https://accounts.google.com"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "auth oidc requires issuer, client_id and redirect_url",
+		},
+		{
+			name: "auth oidc with unknown role",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Auth.Enabled = true
+				cfg.Auth.OIDC = OIDCConfig{
+					Enabled:     true,
+					Issuer:      "https://accounts.google.com",
+					ClientID:    "isx-pulse",
+					RedirectURL: "http://localhost:8080/api/auth/oidc/callback",
+					Roles:       map[string]string{"cfo@example.com": "owner"},
+				}
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  `invalid auth oidc role "owner" for cfo@example.com`,
+		},
+		{
+			name: "auth required while disabled",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Auth.Required = true
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "auth required needs auth enabled",
+		},
+		{
+			name: "auth with a short minimum password",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Auth.Enabled = true
+				cfg.Auth.Local.MinPasswordLength = 4
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "auth local min_password_length must be at least 8",
+		},
+		{
+			name: "scraper delay max below min",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Scraper.DelayMin = 5 * time.Second
+				cfg.Scraper.DelayMax = 2 * time.Second
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "scraper delay_max (2s) must not be less than delay_min (5s)",
+		},
+		{
+			name: "scraper socks proxy",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Scraper.Proxy = "socks5://127.0.0.1:1080"
+				return cfg
+			}(),
+		},
+		{
+			name: "scraper proxy with unsupported scheme",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Scraper.Proxy = "ftp://proxy.example.com:21"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "scraper proxy scheme must be http, https, socks5 or socks5h",
+		},
+		{
+			name: "scraper browser version without checksum",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Scraper.Browser.Version = "130.0.6723.58"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "scraper browser sha256 is required",
+		},
+		{
+			name: "scraper browser mirror",
+			config: func() Config {
+				cfg := *Default()
+				cfg.Scraper.Browser.DownloadURL = "file:///srv/chromium.zip"
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "invalid scraper browser download_url",
+		},
 	}
 
 	for _, tt := range tests {
@@ -863,6 +1114,7 @@ func TestDefault(t *testing.T) {
 	assert.Equal(t, 60*time.Second, cfg.Server.IdleTimeout)
 	assert.Equal(t, 1<<20, cfg.Server.MaxHeaderBytes) // 1MB
 	assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout)
+	assert.False(t, cfg.Server.EnableStatusJSON)
 
 	assert.Equal(t, []string{"http://localhost:8080"}, cfg.Security.AllowedOrigins)
 	assert.True(t, cfg.Security.EnableCORS)
@@ -870,6 +1122,10 @@ func TestDefault(t *testing.T) {
 	assert.True(t, cfg.Security.RateLimit.Enabled)
 	assert.Equal(t, 100.0, cfg.Security.RateLimit.RPS)
 	assert.Equal(t, 50, cfg.Security.RateLimit.Burst)
+	assert.Equal(t, int64(1<<20), cfg.Security.MaxBodyBytes)
+	assert.Equal(t, int64(64<<10), cfg.Security.MaxJSONBodyBytes)
+	assert.Equal(t, 1, cfg.Operations.MaxConcurrent)
+	assert.Equal(t, "queue", cfg.Operations.OnConflict)
 
 	assert.Equal(t, "info", cfg.Logging.Level)
 	assert.Equal(t, "json", cfg.Logging.Format)
@@ -886,6 +1142,15 @@ func TestDefault(t *testing.T) {
 	assert.Equal(t, 1024, cfg.WebSocket.WriteBufferSize)
 	assert.Equal(t, 30*time.Second, cfg.WebSocket.PingPeriod)
 	assert.Equal(t, 60*time.Second, cfg.WebSocket.PongWait)
+
+	assert.True(t, cfg.Notifications.OperationFailed)
+	assert.Equal(t, 587, cfg.Notifications.SMTP.Port)
+	assert.False(t, cfg.Notifications.SMTP.Enabled())
+	assert.False(t, cfg.Notifications.Telegram.Enabled())
+
+	assert.Equal(t, 3, cfg.Health.StaleCriticalDays)
+	assert.Equal(t, 7, cfg.Health.LicenseCriticalDays)
+	assert.Equal(t, 10.0, cfg.Health.DiskWarningPercent)
 }
 
 // TestConfigStructures tests all config structures for completeness
@@ -12,13 +12,26 @@ import (
 // Paths contains all the application paths
 // This is the single source of truth for ALL file paths in the application
 type Paths struct {
+	// Profile is the dataset profile these paths belong to (DefaultProfile if unset)
+	Profile string
+	
 	ExecutableDir string
 	WebDir        string
 	StaticDir     string
 	DataDir       string
 	DownloadsDir  string
+	ArchiveDir    string
+	QuarantineDir string
+	ReferenceDir  string
+	OperationsDir string
+	ExportsDir    string
+	BackupsDir    string
 	ReportsDir    string
+	IntradayDir   string
+	SnapshotsDir  string
+	ImportsDir    string
 	CacheDir      string
+	BrowserDir    string
 	LogsDir       string
 	LicenseFile   string
 	
@@ -27,18 +40,27 @@ type Paths struct {
 	SheetsConfigFile  string
 	
 	// Report subdirectories for organized structure (legacy support)
-	DailyReportsDir     string
-	TickerReportsDir    string
-	LiquidityReportsDir string
-	SummaryReportsDir   string
-	CombinedReportsDir  string
-	IndexesReportsDir   string
+	DailyReportsDir      string
+	TickerReportsDir     string
+	LiquidityReportsDir  string
+	SummaryReportsDir    string
+	CombinedReportsDir   string
+	IndexesReportsDir    string
+	AggregatesReportsDir string
 	
 	// Well-known report files (simplified paths in output directory)
 	IndexCSV          string
+	IndexLongCSV      string
+	IndexAnalyticsCSV string
+	IndexMetricsCSV   string
 	TickerSummaryJSON string
 	TickerSummaryCSV  string
 	CombinedDataCSV   string
+	CompaniesCSV      string
+	TickerEventsCSV   string
+	ExchangeRatesCSV  string
+	HolidaysFile      string
+	ImportedDataCSV   string
 }
 
 // GetPaths returns the application paths relative to the executable location
@@ -58,13 +80,34 @@ func GetPaths() (*Paths, error) {
 	// Get the directory containing the executable
 	exeDir := filepath.Dir(exe)
 	
+	profile := ActiveProfile()
+	if err := ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+	
 	// Log the resolved executable directory for debugging
 	if logger := slog.Default(); logger != nil {
 		logger.Info("Resolved executable directory",
 			slog.String("exe_path", exe),
-			slog.String("exe_dir", exeDir))
+			slog.String("exe_dir", exeDir),
+			slog.String("profile", profile))
 	}
 	
+	return buildPaths(exeDir, profile), nil
+}
+
+// PathsForDir returns the paths of the active profile rooted at exeDir, for
+// code that is handed an executable directory rather than resolving its own
+func PathsForDir(exeDir string) (*Paths, error) {
+	profile := ActiveProfile()
+	if err := ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+	return buildPaths(exeDir, profile), nil
+}
+
+// buildPaths lays out all application paths for a profile under exeDir
+func buildPaths(exeDir, profile string) *Paths {
 	// All paths are relative to the executable directory
 	// This ensures the application works correctly whether run from dev/ or dist/
 	// Directory structure:
@@ -75,11 +118,25 @@ func GetPaths() (*Paths, error) {
 	//   ├── data/
 	//   │   ├── downloads/     (Excel files from scraper)
 	//   │   ├── reports/       (Generated CSV reports)
+	//   │   ├── intraday/      (Intraday bars, one folder per ticker)
+	//   │   ├── snapshots/     (Preliminary mid-session snapshots, one folder per day)
+	//   │   ├── reference/     (Company profiles and curated ticker events)
+	//   │   ├── operations/    (Checkpoints of operations interrupted by a shutdown)
+	//   │   ├── exports/       (Reports delivered by export subscriptions)
+	//   │   ├── holidays.txt   (Trading calendar, one YYYY-MM-DD holiday per line)
 	//   │   └── cache/         (Temporary files)
+	//   ├── backups/           (Data directory snapshots)
 	//   ├── logs/              (Application logs)
+	//   ├── profiles/          (Named profiles, each with its own data/ and logs/)
 	//   └── web/               (Frontend assets)
 	
-	dataDir := filepath.Join(exeDir, "data")
+	// Named profiles get isolated data and logs; configuration files stay shared
+	profileRoot := exeDir
+	if profile != DefaultProfile {
+		profileRoot = filepath.Join(exeDir, profilesDirName, profile)
+	}
+	
+	dataDir := filepath.Join(profileRoot, "data")
 	reportsDir := filepath.Join(dataDir, "reports")
 	
 	// Define report subdirectories (kept for legacy compatibility)
@@ -89,16 +146,28 @@ func GetPaths() (*Paths, error) {
 	summaryReportsDir := filepath.Join(reportsDir, "summary")
 	combinedReportsDir := filepath.Join(reportsDir, "combined")
 	indexesReportsDir := filepath.Join(reportsDir, "indexes")
+	aggregatesReportsDir := filepath.Join(reportsDir, "aggregates")
 	
-	paths := &Paths{
+	return &Paths{
+		Profile:       profile,
 		ExecutableDir: exeDir,
 		DataDir:       dataDir,
 		WebDir:        filepath.Join(exeDir, "web"),
 		StaticDir:     filepath.Join(exeDir, "web", "static"),
 		DownloadsDir:  filepath.Join(dataDir, "downloads"),
+		ArchiveDir:    filepath.Join(dataDir, "archive"),
+		QuarantineDir: filepath.Join(dataDir, "quarantine"),
+		ReferenceDir:  filepath.Join(dataDir, "reference"),
+		OperationsDir: filepath.Join(dataDir, "operations"),
+		ExportsDir:    filepath.Join(dataDir, "exports"),
+		BackupsDir:    filepath.Join(profileRoot, "backups"),
 		ReportsDir:    reportsDir,
+		IntradayDir:   filepath.Join(dataDir, "intraday"),
+		SnapshotsDir:  filepath.Join(dataDir, "snapshots"),
+		ImportsDir:    filepath.Join(dataDir, "imports"),
 		CacheDir:      filepath.Join(dataDir, "cache"),
-		LogsDir:       filepath.Join(exeDir, "logs"),
+		BrowserDir:    filepath.Join(dataDir, "cache", "browser"),
+		LogsDir:       filepath.Join(profileRoot, "logs"),
 		
 		// Configuration files (root of executable directory)
 		LicenseFile:      filepath.Join(exeDir, "license.dat"),
@@ -106,21 +175,28 @@ func GetPaths() (*Paths, error) {
 		SheetsConfigFile: filepath.Join(exeDir, "sheets-config.json"),
 		
 		// Report subdirectories (legacy compatibility)
-		DailyReportsDir:     dailyReportsDir,
-		TickerReportsDir:    tickerReportsDir,
-		LiquidityReportsDir: liquidityReportsDir,
-		SummaryReportsDir:   summaryReportsDir,
-		CombinedReportsDir:  combinedReportsDir,
-		IndexesReportsDir:   indexesReportsDir,
+		DailyReportsDir:      dailyReportsDir,
+		TickerReportsDir:     tickerReportsDir,
+		LiquidityReportsDir:  liquidityReportsDir,
+		SummaryReportsDir:    summaryReportsDir,
+		CombinedReportsDir:   combinedReportsDir,
+		IndexesReportsDir:    indexesReportsDir,
+		AggregatesReportsDir: aggregatesReportsDir,
 		
 		// Well-known report files (in proper subdirectories)
 		IndexCSV:          filepath.Join(indexesReportsDir, "indexes.csv"),
+		IndexLongCSV:      filepath.Join(indexesReportsDir, "indexes_long.csv"),
+		IndexAnalyticsCSV: filepath.Join(indexesReportsDir, "index_analytics.csv"),
+		IndexMetricsCSV:   filepath.Join(indexesReportsDir, "indexes_analytics.csv"),
 		TickerSummaryJSON: filepath.Join(summaryReportsDir, "ticker_summary.json"),
 		TickerSummaryCSV:  filepath.Join(summaryReportsDir, "ticker_summary.csv"),
 		CombinedDataCSV:   filepath.Join(combinedReportsDir, "isx_combined_data.csv"),
+		CompaniesCSV:      filepath.Join(dataDir, "reference", "companies.csv"),
+		TickerEventsCSV:   filepath.Join(dataDir, "reference", "ticker_events.csv"),
+		ExchangeRatesCSV:  filepath.Join(dataDir, "reference", "exchange_rates.csv"),
+		HolidaysFile:      filepath.Join(dataDir, "holidays.txt"),
+		ImportedDataCSV:   filepath.Join(dataDir, "imports", "isx_imported_data.csv"),
 	}
-	
-	return paths, nil
 }
 
 // EnsureDirectories creates all required directories if they don't exist
@@ -137,7 +213,7 @@ func (p *Paths) EnsureDirectories() error {
 		p.WebDir,
 		p.StaticDir,
 		// Report subdirectories are created by their respective processes:
-		// - processor.exe creates: combined/, daily/, ticker/
+		// - processor.exe creates: combined/, daily/, ticker/, deltas/
 		// - indexcsv.exe creates: indexes/
 		// - Other processes create their own directories as needed
 	}
@@ -267,6 +343,20 @@ func (p *Paths) GetReportPath(filename string) string {
 	return filepath.Join(p.ReportsDir, filename)
 }
 
+// GetIntradayPath returns the path for a ticker's intraday bars on a given day
+// (e.g., data/intraday/BBOB/2024-01-15.csv)
+func (p *Paths) GetIntradayPath(ticker string, date time.Time) string {
+	filename := fmt.Sprintf("%s.csv", date.Format("2006-01-02"))
+	return filepath.Join(p.IntradayDir, strings.ToUpper(ticker), filename)
+}
+
+// GetSnapshotPath returns the path for a mid-session snapshot published at asOf
+// (e.g., data/snapshots/2024-01-15/1130.csv)
+func (p *Paths) GetSnapshotPath(asOf time.Time) string {
+	filename := fmt.Sprintf("%s.csv", asOf.Format("1504"))
+	return filepath.Join(p.SnapshotsDir, asOf.Format("2006-01-02"), filename)
+}
+
 // GetLogPath returns the path for a log file
 func (p *Paths) GetLogPath(filename string) string {
 	return filepath.Join(p.LogsDir, filename)
@@ -306,6 +396,16 @@ func (p *Paths) GetIndexCSVPath() string {
 	return p.IndexCSV
 }
 
+// GetIndexAnalyticsCSVPath returns the path for the index_analytics.csv file
+func (p *Paths) GetIndexAnalyticsCSVPath() string {
+	return p.IndexAnalyticsCSV
+}
+
+// GetIndexMetricsCSVPath returns the path for the indexes_analytics.csv file
+func (p *Paths) GetIndexMetricsCSVPath() string {
+	return p.IndexMetricsCSV
+}
+
 // GetTickerSummaryJSONPath returns the path for the ticker_summary.json file
 func (p *Paths) GetTickerSummaryJSONPath() string {
 	return p.TickerSummaryJSON
@@ -321,6 +421,35 @@ func (p *Paths) GetCombinedDataCSVPath() string {
 	return p.CombinedDataCSV
 }
 
+// GetCompaniesCSVPath returns the path for the company profiles reference file
+func (p *Paths) GetCompaniesCSVPath() string {
+	return p.CompaniesCSV
+}
+
+// GetTickerEventsCSVPath returns the path for the curated ticker events file
+// (corporate actions, symbol changes and other dated notes per ticker)
+func (p *Paths) GetTickerEventsCSVPath() string {
+	return p.TickerEventsCSV
+}
+
+// GetExchangeRatesCSVPath returns the path for the IQD per USD rates used
+// to convert values to USD, added to the CBI rates built into the application
+func (p *Paths) GetExchangeRatesCSVPath() string {
+	return p.ExchangeRatesCSV
+}
+
+// GetTickerHistoryCSVPath returns the forward-filled trading history of a
+// ticker written by the processor (e.g., ticker/BBOB_trading_history.csv)
+func (p *Paths) GetTickerHistoryCSVPath(ticker string) string {
+	return filepath.Join(p.TickerReportsDir, fmt.Sprintf("%s_trading_history.csv", strings.ToUpper(ticker)))
+}
+
+// GetTickerBarsCSVPath returns the weekly or monthly OHLCV bars of a ticker
+// written by the processor (e.g., aggregates/BBOB_1w.csv)
+func (p *Paths) GetTickerBarsCSVPath(ticker, interval string) string {
+	return filepath.Join(p.AggregatesReportsDir, fmt.Sprintf("%s_%s.csv", strings.ToUpper(ticker), interval))
+}
+
 // GetDailyCSVPath returns the path for a daily CSV file (e.g., isx_daily_20240115.csv)
 func (p *Paths) GetDailyCSVPath(date time.Time) string {
 	filename := fmt.Sprintf("isx_daily_%s.csv", date.Format("20060102"))
@@ -354,10 +483,14 @@ func (p *Paths) LogPathResolution() {
 	
 	logger.Info("Path resolution summary",
 		slog.Group("directories",
+			slog.String("profile", NormalizeProfile(p.Profile)),
 			slog.String("executable", p.ExecutableDir),
 			slog.String("data", p.DataDir),
 			slog.String("downloads", p.DownloadsDir),
 			slog.String("reports", p.ReportsDir),
+			slog.String("intraday", p.IntradayDir),
+			slog.String("snapshots", p.SnapshotsDir),
+			slog.String("imports", p.ImportsDir),
 			slog.String("cache", p.CacheDir),
 			slog.String("logs", p.LogsDir),
 			slog.String("web", p.WebDir),
@@ -78,6 +78,7 @@ func TestGetPaths(t *testing.T) {
 		assert.Equal(t, "ticker_summary.json", filepath.Base(paths.TickerSummaryJSON))
 		assert.Equal(t, "ticker_summary.csv", filepath.Base(paths.TickerSummaryCSV))
 		assert.Equal(t, "isx_combined_data.csv", filepath.Base(paths.CombinedDataCSV))
+		assert.Equal(t, filepath.Join(paths.DataDir, "reference", "companies.csv"), paths.CompaniesCSV)
 	})
 }
 
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Profiles give each dataset (e.g. "research", "prod") its own downloads,
+// reports and logs while sharing the license, credentials and web assets.
+//
+//	dist/
+//	  ├── data/                   (default profile)
+//	  ├── logs/
+//	  └── profiles/
+//	      └── research/
+//	          ├── data/
+//	          └── logs/
+
+const (
+	// ProfileEnvVar selects the active profile for a process and is inherited
+	// by the scraper/processor/indexcsv child processes
+	ProfileEnvVar = "ISX_PROFILE"
+
+	// ProfileHeader selects a profile for a single web request
+	ProfileHeader = "X-ISX-Profile"
+
+	// DefaultProfile uses the original data/ and logs/ directories
+	DefaultProfile = "default"
+
+	// profilesDirName is the directory under the executable holding named profiles
+	profilesDirName = "profiles"
+)
+
+var profileNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+type profileContextKey struct{}
+
+// ValidateProfileName checks that a profile name is safe to use as a directory name
+func ValidateProfileName(name string) error {
+	if name == "" || name == DefaultProfile {
+		return nil
+	}
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' or '_' (max 64 characters)", name)
+	}
+	return nil
+}
+
+// NormalizeProfile maps the empty name to DefaultProfile
+func NormalizeProfile(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultProfile
+	}
+	return name
+}
+
+// ActiveProfile returns the process-wide profile from ISX_PROFILE
+func ActiveProfile() string {
+	return NormalizeProfile(os.Getenv(ProfileEnvVar))
+}
+
+// SetActiveProfile selects the profile used by GetPaths for this process and
+// any child processes it starts. It is meant to be called from a --profile flag;
+// an empty name leaves ISX_PROFILE untouched.
+func SetActiveProfile(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+	name = NormalizeProfile(name)
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		return os.Unsetenv(ProfileEnvVar)
+	}
+	return os.Setenv(ProfileEnvVar, name)
+}
+
+// WithProfile stores a per-request profile in the context
+func WithProfile(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, profileContextKey{}, NormalizeProfile(name))
+}
+
+// ProfileFromContext returns the per-request profile, or "" if none was set
+func ProfileFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if name, ok := ctx.Value(profileContextKey{}).(string); ok {
+		return name
+	}
+	return ""
+}
+
+// ForProfile returns the paths for another profile rooted at the same executable directory
+func (p *Paths) ForProfile(name string) (*Paths, error) {
+	name = NormalizeProfile(name)
+	if name == NormalizeProfile(p.Profile) {
+		return p, nil
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+	return buildPaths(p.ExecutableDir, name), nil
+}
+
+// ForContext returns the paths for the profile in ctx, falling back to p
+func (p *Paths) ForContext(ctx context.Context) *Paths {
+	name := ProfileFromContext(ctx)
+	if name == "" {
+		return p
+	}
+	profilePaths, err := p.ForProfile(name)
+	if err != nil {
+		return p
+	}
+	return profilePaths
+}
+
+// ListProfiles returns DefaultProfile plus every named profile directory
+func (p *Paths) ListProfiles() ([]string, error) {
+	profiles := []string{DefaultProfile}
+
+	entries, err := os.ReadDir(p.GetRelativePath(profilesDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profiles, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateProfileName(entry.Name()) == nil && entry.Name() != DefaultProfile {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	return profiles, nil
+}
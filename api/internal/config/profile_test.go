@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	valid := []string{"", "default", "research", "prod-2025", "client_a"}
+	for _, name := range valid {
+		assert.NoError(t, ValidateProfileName(name), name)
+	}
+
+	invalid := []string{"../etc", "a/b", "-leading", "has space", "x.y"}
+	for _, name := range invalid {
+		assert.Error(t, ValidateProfileName(name), name)
+	}
+}
+
+func TestGetPaths_Profile(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "research")
+
+	paths, err := GetPaths()
+	require.NoError(t, err)
+
+	root := filepath.Join(paths.ExecutableDir, "profiles", "research")
+	assert.Equal(t, "research", paths.Profile)
+	assert.Equal(t, filepath.Join(root, "data"), paths.DataDir)
+	assert.Equal(t, filepath.Join(root, "data", "downloads"), paths.DownloadsDir)
+	assert.Equal(t, filepath.Join(root, "logs"), paths.LogsDir)
+
+	// Configuration stays shared between profiles
+	assert.Equal(t, filepath.Join(paths.ExecutableDir, "license.dat"), paths.LicenseFile)
+	assert.Equal(t, filepath.Join(paths.ExecutableDir, "web"), paths.WebDir)
+
+	t.Setenv(ProfileEnvVar, "../escape")
+	_, err = GetPaths()
+	assert.Error(t, err)
+}
+
+func TestSetActiveProfile(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "prod")
+
+	require.NoError(t, SetActiveProfile(""))
+	assert.Equal(t, "prod", ActiveProfile(), "empty flag keeps the environment profile")
+
+	require.NoError(t, SetActiveProfile("research"))
+	assert.Equal(t, "research", ActiveProfile())
+
+	require.NoError(t, SetActiveProfile(DefaultProfile))
+	assert.Equal(t, DefaultProfile, ActiveProfile())
+
+	assert.Error(t, SetActiveProfile("bad/name"))
+}
+
+func TestPaths_ForContext(t *testing.T) {
+	base := buildPaths(t.TempDir(), DefaultProfile)
+
+	assert.Same(t, base, base.ForContext(context.Background()))
+
+	ctx := WithProfile(context.Background(), "prod")
+	prod := base.ForContext(ctx)
+	assert.Equal(t, "prod", prod.Profile)
+	assert.Equal(t, filepath.Join(base.ExecutableDir, "profiles", "prod", "data", "reports"), prod.ReportsDir)
+
+	// Invalid names in the context fall back to the base paths
+	ctx = context.WithValue(context.Background(), profileContextKey{}, "../x")
+	assert.Same(t, base, base.ForContext(ctx))
+}
+
+func TestPaths_ListProfiles(t *testing.T) {
+	base := buildPaths(t.TempDir(), DefaultProfile)
+
+	profiles, err := base.ListProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile}, profiles)
+
+	for _, name := range []string{"prod", "research"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(base.ExecutableDir, "profiles", name), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(base.ExecutableDir, "profiles", "notes.txt"), nil, 0644))
+
+	profiles, err = base.ListProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile, "prod", "research"}, profiles)
+}
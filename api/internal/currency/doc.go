@@ -0,0 +1,17 @@
+// Package currency converts Iraqi dinar amounts to US dollars at the Central
+// Bank of Iraq (CBI) official rate in effect on the trading day.
+//
+// ISX reports price every ticker in IQD. A Table holds the dated official
+// rates, each effective from its date until the next one. DefaultRates covers
+// the CBI rate changes since 2016; an exchange_rates.csv reference file with
+// Date,IQDPerUSD rows adds to or overrides it, and Fetch reads the same layout
+// from a URL so the table can be kept current without a release.
+//
+// Example usage:
+//
+//	table, err := currency.LoadTable("data/reference/exchange_rates.csv")
+//	if err != nil {
+//	    return err
+//	}
+//	usd := table.Convert(valueIQD, currency.USD, tradeDate)
+package currency
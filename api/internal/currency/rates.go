@@ -0,0 +1,200 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Code is an ISO 4217 currency code
+type Code string
+
+// Supported currencies
+const (
+	IQD Code = "IQD"
+	USD Code = "USD"
+)
+
+// ParseCode parses a currency code case-insensitively; empty means IQD
+func ParseCode(s string) (Code, error) {
+	switch Code(strings.ToUpper(strings.TrimSpace(s))) {
+	case "", IQD:
+		return IQD, nil
+	case USD:
+		return USD, nil
+	default:
+		return "", fmt.Errorf("unsupported currency %q (use IQD or USD)", s)
+	}
+}
+
+// RatesHeader is the column layout of exchange rate files
+var RatesHeader = []string{"Date", "IQDPerUSD"}
+
+// Rate is an official rate effective from Date until the next rate
+type Rate struct {
+	Date      time.Time `json:"date"`
+	IQDPerUSD float64   `json:"iqd_per_usd"`
+}
+
+// DefaultRates returns the CBI official rates the application ships with
+func DefaultRates() []Rate {
+	return []Rate{
+		{Date: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), IQDPerUSD: 1182},
+		{Date: time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC), IQDPerUSD: 1450},
+		{Date: time.Date(2023, 2, 8, 0, 0, 0, 0, time.UTC), IQDPerUSD: 1300},
+	}
+}
+
+// Table is a set of dated rates ordered by date
+type Table struct {
+	rates []Rate
+}
+
+// NewTable builds a table from rates. Later rates for the same date replace
+// earlier ones.
+func NewTable(rates ...[]Rate) *Table {
+	byDate := make(map[string]Rate)
+	for _, set := range rates {
+		for _, rate := range set {
+			byDate[rate.Date.Format("2006-01-02")] = rate
+		}
+	}
+	t := &Table{rates: make([]Rate, 0, len(byDate))}
+	for _, rate := range byDate {
+		t.rates = append(t.rates, rate)
+	}
+	sort.Slice(t.rates, func(i, j int) bool { return t.rates[i].Date.Before(t.rates[j].Date) })
+	return t
+}
+
+// Rates returns the table's rates, oldest first
+func (t *Table) Rates() []Rate {
+	return append([]Rate(nil), t.rates...)
+}
+
+// At returns the IQD per USD rate in effect on date. Dates before the first
+// rate use the first rate; ok is false only for an empty table.
+func (t *Table) At(date time.Time) (rate float64, ok bool) {
+	if len(t.rates) == 0 {
+		return 0, false
+	}
+	day := date.Format("2006-01-02")
+	i := sort.Search(len(t.rates), func(i int) bool { return t.rates[i].Date.Format("2006-01-02") > day })
+	if i == 0 {
+		return t.rates[0].IQDPerUSD, true
+	}
+	return t.rates[i-1].IQDPerUSD, true
+}
+
+// Convert converts an IQD amount on date to the given currency
+func (t *Table) Convert(iqd float64, to Code, date time.Time) float64 {
+	if to != USD {
+		return iqd
+	}
+	rate, ok := t.At(date)
+	if !ok || rate <= 0 {
+		return iqd
+	}
+	return iqd / rate
+}
+
+// ToIQD converts an amount in the given currency on date back to IQD
+func (t *Table) ToIQD(amount float64, from Code, date time.Time) float64 {
+	if from != USD {
+		return amount
+	}
+	rate, ok := t.At(date)
+	if !ok {
+		return amount
+	}
+	return amount * rate
+}
+
+// ReadRates parses Date,IQDPerUSD rows. The header row is optional.
+func ReadRates(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read exchange rates: %w", err)
+	}
+
+	var rates []Rate
+	for i, record := range records {
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if i == 0 && strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(record[0]), "\ufeff"), RatesHeader[0]) {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("exchange rates line %d: expected Date,IQDPerUSD", i+1)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("exchange rates line %d: invalid date %q", i+1, record[0])
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("exchange rates line %d: invalid rate %q", i+1, record[1])
+		}
+		rates = append(rates, Rate{Date: date, IQDPerUSD: rate})
+	}
+	return rates, nil
+}
+
+// LoadTable reads the rates file at path over DefaultRates. A missing file
+// leaves the defaults.
+func LoadTable(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewTable(DefaultRates()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rates, err := ReadRates(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return NewTable(DefaultRates(), rates), nil
+}
+
+// WriteRates writes rates to path atomically in the rates file layout
+func WriteRates(path string, rates []Rate) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	_ = writer.Write(RatesHeader)
+	for _, rate := range NewTable(rates).rates {
+		_ = writer.Write([]string{rate.Date.Format("2006-01-02"), strconv.FormatFloat(rate.IQDPerUSD, 'f', -1, 64)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
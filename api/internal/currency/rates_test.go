@@ -0,0 +1,107 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTableAt(t *testing.T) {
+	table := NewTable(DefaultRates())
+
+	tests := []struct {
+		date time.Time
+		want float64
+	}{
+		{day(2012, 5, 1), 1182},
+		{day(2020, 12, 19), 1182},
+		{day(2020, 12, 20), 1450},
+		{day(2023, 2, 7), 1450},
+		{day(2023, 2, 8), 1300},
+		{day(2025, 8, 26), 1300},
+	}
+	for _, tt := range tests {
+		rate, ok := table.At(tt.date)
+		require.True(t, ok)
+		assert.Equal(t, tt.want, rate, tt.date.Format("2006-01-02"))
+	}
+
+	_, ok := NewTable().At(day(2025, 1, 1))
+	assert.False(t, ok)
+}
+
+func TestTableConvert(t *testing.T) {
+	table := NewTable(DefaultRates())
+	date := day(2025, 1, 5)
+
+	assert.Equal(t, 100.0, table.Convert(130000, USD, date))
+	assert.Equal(t, 130000.0, table.Convert(130000, IQD, date))
+	assert.Equal(t, 130000.0, table.ToIQD(100, USD, date))
+	assert.Equal(t, 500.0, NewTable().Convert(500, USD, date), "without rates amounts stay in IQD")
+}
+
+func TestParseCode(t *testing.T) {
+	for in, want := range map[string]Code{"": IQD, "iqd": IQD, " USD ": USD} {
+		got, err := ParseCode(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseCode("EUR")
+	assert.Error(t, err)
+}
+
+func TestLoadTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exchange_rates.csv")
+
+	table, err := LoadTable(path)
+	require.NoError(t, err)
+	assert.Len(t, table.Rates(), len(DefaultRates()), "a missing file keeps the defaults")
+
+	require.NoError(t, os.WriteFile(path, []byte("Date,IQDPerUSD\n2023-02-08,1310\n2025-09-01,1250\n"), 0644))
+	table, err = LoadTable(path)
+	require.NoError(t, err)
+	rate, _ := table.At(day(2024, 1, 1))
+	assert.Equal(t, 1310.0, rate, "the file overrides a default rate")
+	rate, _ = table.At(day(2025, 9, 2))
+	assert.Equal(t, 1250.0, rate)
+
+	require.NoError(t, WriteRates(path, table.Rates()))
+	reloaded, err := LoadTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, table.Rates(), reloaded.Rates())
+
+	require.NoError(t, os.WriteFile(path, []byte("2025-09-01,abc\n"), 0644))
+	_, err = LoadTable(path)
+	assert.ErrorContains(t, err, "invalid rate")
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rates.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "Date,IQDPerUSD\n2025-09-01,1250\n")
+	}))
+	defer server.Close()
+
+	rates, err := Fetch(context.Background(), server.Client(), server.URL+"/rates.csv")
+	require.NoError(t, err)
+	assert.Equal(t, []Rate{{Date: day(2025, 9, 1), IQDPerUSD: 1250}}, rates)
+
+	_, err = Fetch(context.Background(), server.Client(), server.URL+"/missing.csv")
+	assert.True(t, err != nil && strings.Contains(err.Error(), "404"))
+}
@@ -0,0 +1,298 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// AnomalyKind names the check that flagged a trading day
+type AnomalyKind string
+
+// Anomaly kinds
+const (
+	// AnomalyPriceJump is a daily close-to-close return whose z-score
+	// against the ticker's recent returns exceeds the threshold
+	AnomalyPriceJump AnomalyKind = "price_jump"
+	// AnomalyVolumeSpike is a volume above a multiple of the ticker's recent
+	// average volume
+	AnomalyVolumeSpike AnomalyKind = "volume_spike"
+	// AnomalyValueWithoutVolume is a day reporting traded value but no volume,
+	// usually a bulletin error
+	AnomalyValueWithoutVolume AnomalyKind = "value_without_volume"
+)
+
+// Anomaly report locations under the reports directory
+const (
+	AnomaliesDirName    = "anomalies"
+	AnomaliesReportName = "isx_anomalies.csv"
+	AnomalySummaryName  = "anomalies_summary.json"
+)
+
+// maxSummaryAnomalies bounds the anomalies listed in a run summary
+const maxSummaryAnomalies = 50
+
+// minBaselineSessions is the fewest earlier sessions a price or volume check
+// needs; longer lookbacks need a third of the lookback
+const minBaselineSessions = 5
+
+// AnomalyOptions are the detection thresholds
+type AnomalyOptions struct {
+	// Lookback is the number of earlier sessions the ticker traded that form
+	// its baseline
+	Lookback int
+	// ZScore is the return z-score above which a price move is a jump
+	ZScore float64
+	// VolumeMultiple is the multiple of average volume that is a spike
+	VolumeMultiple float64
+}
+
+// DefaultAnomalyOptions returns the default thresholds: a 30-session
+// baseline, 4 standard deviations and 5x average volume
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{Lookback: 30, ZScore: 4, VolumeMultiple: 5}
+}
+
+// Anomaly is one flagged trading day of a ticker
+type Anomaly struct {
+	Date   time.Time   `json:"date"`
+	Symbol string      `json:"symbol"`
+	Kind   AnomalyKind `json:"kind"`
+	// Observed is the day's return in percent, volume or value
+	Observed float64 `json:"observed"`
+	// Baseline is the mean return in percent or average volume it is
+	// compared with; zero for value_without_volume
+	Baseline float64 `json:"baseline"`
+	// Score is the return z-score or the multiple of average volume
+	Score float64 `json:"score"`
+}
+
+// AnomalyHeaders are the columns of the anomalies report
+var AnomalyHeaders = []string{"Date", "Symbol", "Kind", "Observed", "Baseline", "Score"}
+
+// DetectAnomalies checks every day a ticker actually traded against the
+// sessions before it, ordered by date then symbol. Forward-filled records
+// are neither checked nor part of a baseline, and price and volume checks
+// wait until a ticker has enough earlier sessions.
+func DetectAnomalies(records []domain.TradeRecord, opts AnomalyOptions) []Anomaly {
+	if opts.Lookback <= 0 {
+		opts.Lookback = DefaultAnomalyOptions().Lookback
+	}
+	minSessions := max(opts.Lookback/3, minBaselineSessions)
+
+	bySymbol := make(map[string][]domain.TradeRecord)
+	for _, r := range records {
+		if r.TradingStatus {
+			bySymbol[r.CompanySymbol] = append(bySymbol[r.CompanySymbol], r)
+		}
+	}
+
+	var anomalies []Anomaly
+	for symbol, days := range bySymbol {
+		sort.SliceStable(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+
+		var returns []float64 // returns[i] is the return into days[i+1]
+		for i, r := range days {
+			if r.Volume == 0 && r.Value != 0 {
+				anomalies = append(anomalies, Anomaly{
+					Date: r.Date, Symbol: symbol, Kind: AnomalyValueWithoutVolume, Observed: r.Value,
+				})
+			}
+
+			if i >= minSessions {
+				start := max(0, i-opts.Lookback)
+				var total float64
+				for _, prev := range days[start:i] {
+					total += float64(prev.Volume)
+				}
+				avg := total / float64(i-start)
+				if opts.VolumeMultiple > 0 && avg > 0 && float64(r.Volume) >= opts.VolumeMultiple*avg {
+					anomalies = append(anomalies, Anomaly{
+						Date: r.Date, Symbol: symbol, Kind: AnomalyVolumeSpike,
+						Observed: float64(r.Volume), Baseline: avg, Score: float64(r.Volume) / avg,
+					})
+				}
+			}
+
+			if i == 0 {
+				continue
+			}
+			ret := math.NaN()
+			if prev := days[i-1].ClosePrice; prev > 0 && r.ClosePrice > 0 {
+				ret = r.ClosePrice/prev - 1
+			}
+			if !math.IsNaN(ret) && opts.ZScore > 0 {
+				if mean, std, n := meanStd(returns, opts.Lookback); n >= minSessions && std > 0 {
+					if z := (ret - mean) / std; math.Abs(z) >= opts.ZScore {
+						anomalies = append(anomalies, Anomaly{
+							Date: r.Date, Symbol: symbol, Kind: AnomalyPriceJump,
+							Observed: ret * 100, Baseline: mean * 100, Score: z,
+						})
+					}
+				}
+			}
+			returns = append(returns, ret)
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		a, b := anomalies[i], anomalies[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Kind < b.Kind
+	})
+	return anomalies
+}
+
+// meanStd returns the mean and sample standard deviation of the last
+// lookback valid returns and how many there were
+func meanStd(returns []float64, lookback int) (mean, std float64, n int) {
+	var sum, sumSq float64
+	for i := len(returns) - 1; i >= 0 && n < lookback; i-- {
+		if math.IsNaN(returns[i]) {
+			continue
+		}
+		sum += returns[i]
+		sumSq += returns[i] * returns[i]
+		n++
+	}
+	if n < 2 {
+		return 0, 0, n
+	}
+	mean = sum / float64(n)
+	variance := (sumSq - float64(n)*mean*mean) / float64(n-1)
+	if variance <= 0 {
+		return mean, 0, n
+	}
+	return mean, math.Sqrt(variance), n
+}
+
+// AnomalySummary describes the anomalies found on the dates of one
+// processing run
+type AnomalySummary struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Dates       []string            `json:"dates"`
+	Total       int                 `json:"total"`
+	ByKind      map[AnomalyKind]int `json:"by_kind"`
+	Tickers     []string            `json:"tickers"`
+	// Anomalies lists the strongest findings, at most 50
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+// SummarizeAnomalies summarizes the anomalies falling on dates. The listed
+// anomalies are ordered by strength: value_without_volume first, then by
+// absolute score.
+func SummarizeAnomalies(anomalies []Anomaly, dates []time.Time, now time.Time) AnomalySummary {
+	summary := AnomalySummary{
+		GeneratedAt: now.UTC(),
+		Dates:       []string{},
+		ByKind:      make(map[AnomalyKind]int),
+		Tickers:     []string{},
+		Anomalies:   []Anomaly{},
+	}
+	wanted := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		day := d.Format("2006-01-02")
+		if !wanted[day] {
+			wanted[day] = true
+			summary.Dates = append(summary.Dates, day)
+		}
+	}
+	sort.Strings(summary.Dates)
+
+	tickers := make(map[string]bool)
+	for _, a := range anomalies {
+		if !wanted[a.Date.Format("2006-01-02")] {
+			continue
+		}
+		summary.Total++
+		summary.ByKind[a.Kind]++
+		if !tickers[a.Symbol] {
+			tickers[a.Symbol] = true
+			summary.Tickers = append(summary.Tickers, a.Symbol)
+		}
+		summary.Anomalies = append(summary.Anomalies, a)
+	}
+	sort.Strings(summary.Tickers)
+
+	sort.SliceStable(summary.Anomalies, func(i, j int) bool {
+		a, b := summary.Anomalies[i], summary.Anomalies[j]
+		if (a.Kind == AnomalyValueWithoutVolume) != (b.Kind == AnomalyValueWithoutVolume) {
+			return a.Kind == AnomalyValueWithoutVolume
+		}
+		return math.Abs(a.Score) > math.Abs(b.Score)
+	})
+	if len(summary.Anomalies) > maxSummaryAnomalies {
+		summary.Anomalies = summary.Anomalies[:maxSummaryAnomalies]
+	}
+	return summary
+}
+
+// WriteAnomalies writes the anomalies report and the run summary into dir
+func WriteAnomalies(dir string, anomalies []Anomaly, summary AnomalySummary) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, AnomaliesReportName))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(AnomalyHeaders); err != nil {
+		return err
+	}
+	for _, a := range anomalies {
+		row := []string{
+			a.Date.Format("2006-01-02"),
+			a.Symbol,
+			string(a.Kind),
+			strconv.FormatFloat(a.Observed, 'f', 4, 64),
+			strconv.FormatFloat(a.Baseline, 'f', 4, 64),
+			strconv.FormatFloat(a.Score, 'f', 2, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, AnomalySummaryName), data, 0644)
+}
+
+// ReadAnomalySummary reads the summary of the latest run from dir
+func ReadAnomalySummary(dir string) (*AnomalySummary, error) {
+	data, err := os.ReadFile(filepath.Join(dir, AnomalySummaryName))
+	if err != nil {
+		return nil, err
+	}
+	var summary AnomalySummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", AnomalySummaryName, err)
+	}
+	return &summary, nil
+}
@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// anomalyRecords gives BBOB 20 quiet sessions closing at 1.00 and 1.01 in
+// turn, then a jump to 1.30 on eight times the usual volume
+func anomalyRecords() []domain.TradeRecord {
+	var records []domain.TradeRecord
+	for d := 1; d <= 20; d++ {
+		price := 1.00
+		if d%2 == 0 {
+			price = 1.01
+		}
+		records = append(records, domain.TradeRecord{CompanySymbol: "BBOB", Date: day(d), ClosePrice: price, Volume: 1000, Value: 1000 * price, TradingStatus: true})
+	}
+	return append(records,
+		domain.TradeRecord{CompanySymbol: "BBOB", Date: day(21), ClosePrice: 1.01, TradingStatus: false},
+		domain.TradeRecord{CompanySymbol: "BBOB", Date: day(22), ClosePrice: 1.30, Volume: 8000, Value: 10400, TradingStatus: true},
+		domain.TradeRecord{CompanySymbol: "TASC", Date: day(22), ClosePrice: 8.00, Volume: 0, Value: 500, TradingStatus: true},
+	)
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	anomalies := DetectAnomalies(anomalyRecords(), AnomalyOptions{Lookback: 15, ZScore: 4, VolumeMultiple: 5})
+	require.Len(t, anomalies, 3)
+
+	jump, spike, noVolume := anomalies[0], anomalies[1], anomalies[2]
+	assert.Equal(t, AnomalyPriceJump, jump.Kind)
+	assert.Equal(t, "BBOB", jump.Symbol)
+	assert.Equal(t, day(22), jump.Date, "the forward-filled day is skipped")
+	assert.InDelta(t, 28.71, jump.Observed, 0.01)
+	assert.Greater(t, jump.Score, 4.0)
+
+	assert.Equal(t, AnomalyVolumeSpike, spike.Kind)
+	assert.Equal(t, 1000.0, spike.Baseline)
+	assert.Equal(t, 8.0, spike.Score)
+
+	assert.Equal(t, AnomalyValueWithoutVolume, noVolume.Kind)
+	assert.Equal(t, "TASC", noVolume.Symbol)
+	assert.Equal(t, 500.0, noVolume.Observed)
+}
+
+func TestDetectAnomaliesNeedsBaseline(t *testing.T) {
+	records := anomalyRecords()[16:] // Four quiet sessions before the jump
+	anomalies := DetectAnomalies(records, DefaultAnomalyOptions())
+
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, AnomalyValueWithoutVolume, anomalies[0].Kind)
+}
+
+func TestSummarizeAnomalies(t *testing.T) {
+	anomalies := DetectAnomalies(anomalyRecords(), AnomalyOptions{Lookback: 15, ZScore: 4, VolumeMultiple: 5})
+	now := time.Date(2025, 1, 22, 18, 0, 0, 0, time.UTC)
+
+	summary := SummarizeAnomalies(anomalies, []time.Time{day(22), day(22)}, now)
+	assert.Equal(t, []string{"2025-01-22"}, summary.Dates)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, map[AnomalyKind]int{AnomalyPriceJump: 1, AnomalyVolumeSpike: 1, AnomalyValueWithoutVolume: 1}, summary.ByKind)
+	assert.Equal(t, []string{"BBOB", "TASC"}, summary.Tickers)
+	require.Len(t, summary.Anomalies, 3)
+	assert.Equal(t, AnomalyValueWithoutVolume, summary.Anomalies[0].Kind, "bulletin errors come first")
+	assert.Equal(t, AnomalyPriceJump, summary.Anomalies[1].Kind, "then the strongest score")
+
+	empty := SummarizeAnomalies(anomalies, []time.Time{day(5)}, now)
+	assert.Zero(t, empty.Total)
+	assert.Empty(t, empty.Anomalies)
+}
+
+func TestWriteAnomalies(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), AnomaliesDirName)
+	anomalies := DetectAnomalies(anomalyRecords(), AnomalyOptions{Lookback: 15, ZScore: 4, VolumeMultiple: 5})
+	summary := SummarizeAnomalies(anomalies, []time.Time{day(22)}, time.Date(2025, 1, 22, 18, 0, 0, 0, time.UTC))
+
+	require.NoError(t, WriteAnomalies(dir, anomalies, summary))
+
+	data, err := os.ReadFile(filepath.Join(dir, AnomaliesReportName))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Date,Symbol,Kind,Observed,Baseline,Score\n")
+	assert.Contains(t, string(data), "2025-01-22,BBOB,volume_spike,8000.0000,1000.0000,8.00\n")
+
+	read, err := ReadAnomalySummary(dir)
+	require.NoError(t, err)
+	assert.Equal(t, summary.Total, read.Total)
+	assert.True(t, summary.GeneratedAt.Equal(read.GeneratedAt))
+	assert.Equal(t, summary.Tickers, read.Tickers)
+}
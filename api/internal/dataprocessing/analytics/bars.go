@@ -0,0 +1,267 @@
+// Package analytics resamples daily trade records into chart-ready bars and
+// flags anomalous trading days.
+//
+// Daily records produced by the processor are forward-filled, so a ticker has
+// a row for every trading day even when it did not trade. Only actual trading
+// days contribute to a bar; a week or month without trades has no bar rather
+// than a flat bar made of carried-forward prices.
+package analytics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/exporter"
+	"isxcli/pkg/contracts/domain"
+)
+
+// Interval is the period covered by one bar
+type Interval string
+
+// Supported bar intervals
+const (
+	IntervalWeek  Interval = "1w"
+	IntervalMonth Interval = "1mo"
+)
+
+// Intervals lists the intervals the processor writes aggregates for
+var Intervals = []Interval{IntervalWeek, IntervalMonth}
+
+// ParseInterval parses an interval name such as 1w, weekly, 1mo or monthly
+func ParseInterval(s string) (Interval, error) {
+	switch strings.TrimSpace(s) {
+	case "1w", "w", "W", "1W", "week", "weekly":
+		return IntervalWeek, nil
+	case "1mo", "mo", "1M", "M", "month", "monthly":
+		return IntervalMonth, nil
+	}
+	return "", fmt.Errorf("unsupported interval %q (use 1w or 1mo)", s)
+}
+
+// PeriodStart returns the first day of the period containing t. ISX trades
+// Sunday to Thursday, so weeks start on Sunday.
+func (i Interval) PeriodStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if i == IntervalMonth {
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// Bar is the OHLCV summary of one ticker over one period
+type Bar struct {
+	Symbol      string    `json:"symbol"`
+	Start       time.Time `json:"start"`      // First day of the period
+	LastTrade   time.Time `json:"last_trade"` // Last day in the period the ticker traded
+	Open        float64   `json:"open"`
+	High        float64   `json:"high"`
+	Low         float64   `json:"low"`
+	Close       float64   `json:"close"`
+	Volume      int64     `json:"volume"`
+	Value       float64   `json:"value"`
+	NumTrades   int64     `json:"num_trades"`
+	TradingDays int       `json:"trading_days"`
+}
+
+// BarHeaders are the columns of an aggregate CSV
+var BarHeaders = []string{
+	"Symbol", "PeriodStart", "LastTradeDate", "OpenPrice", "HighPrice", "LowPrice",
+	"ClosePrice", "Volume", "Value", "NumTrades", "TradingDays",
+}
+
+// Resample groups records into bars per symbol and period, ordered by symbol
+// then period. Forward-filled records are skipped. Missing open, high or low
+// prices fall back to the day's close.
+func Resample(records []domain.TradeRecord, interval Interval) []Bar {
+	actual := make([]domain.TradeRecord, 0, len(records))
+	for _, r := range records {
+		if r.TradingStatus && r.ClosePrice > 0 {
+			actual = append(actual, r)
+		}
+	}
+	sort.SliceStable(actual, func(i, j int) bool {
+		if actual[i].CompanySymbol != actual[j].CompanySymbol {
+			return actual[i].CompanySymbol < actual[j].CompanySymbol
+		}
+		return actual[i].Date.Before(actual[j].Date)
+	})
+
+	var bars []Bar
+	for _, r := range actual {
+		start := interval.PeriodStart(r.Date)
+		open, high, low := r.OpenPrice, r.HighPrice, r.LowPrice
+		if open <= 0 {
+			open = r.ClosePrice
+		}
+		if high <= 0 {
+			high = r.ClosePrice
+		}
+		if low <= 0 {
+			low = r.ClosePrice
+		}
+
+		if n := len(bars); n > 0 && bars[n-1].Symbol == r.CompanySymbol && bars[n-1].Start.Equal(start) {
+			bar := &bars[n-1]
+			bar.LastTrade = r.Date
+			bar.High = max(bar.High, high)
+			bar.Low = min(bar.Low, low)
+			bar.Close = r.ClosePrice
+			bar.Volume += r.Volume
+			bar.Value += r.Value
+			bar.NumTrades += r.NumTrades
+			bar.TradingDays++
+			continue
+		}
+
+		bars = append(bars, Bar{
+			Symbol:      r.CompanySymbol,
+			Start:       start,
+			LastTrade:   r.Date,
+			Open:        open,
+			High:        high,
+			Low:         low,
+			Close:       r.ClosePrice,
+			Volume:      r.Volume,
+			Value:       r.Value,
+			NumTrades:   r.NumTrades,
+			TradingDays: 1,
+		})
+	}
+	return bars
+}
+
+// BarsFileName returns the aggregate file name for a ticker, e.g. BBOB_1w.csv
+func BarsFileName(symbol string, interval Interval) string {
+	return fmt.Sprintf("%s_%s.csv", strings.ToUpper(symbol), interval)
+}
+
+// WriteAggregates writes one CSV per ticker and interval into dir and returns
+// the number of files written
+func WriteAggregates(dir string, records []domain.TradeRecord, opts exporter.ExportOptions) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, interval := range Intervals {
+		bars := Resample(records, interval)
+		for start := 0; start < len(bars); {
+			end := start
+			for end < len(bars) && bars[end].Symbol == bars[start].Symbol {
+				end++
+			}
+			path := filepath.Join(dir, BarsFileName(bars[start].Symbol, interval))
+			if err := WriteBarsCSV(path, bars[start:end], opts); err != nil {
+				return written, fmt.Errorf("write %s: %w", filepath.Base(path), err)
+			}
+			written++
+			start = end
+		}
+	}
+	return written, nil
+}
+
+// WriteBarsCSV writes bars to path using the export formatting options
+func WriteBarsCSV(path string, bars []Bar, opts exporter.ExportOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if opts.BOM {
+		if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(BarHeaders); err != nil {
+		return err
+	}
+	for _, b := range bars {
+		row := []string{
+			b.Symbol,
+			opts.FormatDate(b.Start),
+			opts.FormatDate(b.LastTrade),
+			opts.FormatFloat("OpenPrice", exporter.ColumnPrice, b.Open),
+			opts.FormatFloat("HighPrice", exporter.ColumnPrice, b.High),
+			opts.FormatFloat("LowPrice", exporter.ColumnPrice, b.Low),
+			opts.FormatFloat("ClosePrice", exporter.ColumnPrice, b.Close),
+			opts.FormatInt(b.Volume),
+			opts.FormatFloat("Value", exporter.ColumnValue, b.Value),
+			opts.FormatInt(b.NumTrades),
+			strconv.Itoa(b.TradingDays),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ReadBarsCSV reads an aggregate CSV written with the given formatting options
+func ReadBarsCSV(path string, opts exporter.ExportOptions) ([]Bar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	dateLayout := opts.DateFormat
+	if dateLayout == "" {
+		dateLayout = "2006-01-02"
+	}
+	number := func(s string) float64 {
+		if opts.ThousandsSeparator != "" {
+			s = strings.ReplaceAll(s, opts.ThousandsSeparator, "")
+		}
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+
+	bars := make([]Bar, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		if len(row) < len(BarHeaders) {
+			return nil, fmt.Errorf("%s: row %d has %d columns, want %d", filepath.Base(path), i+2, len(row), len(BarHeaders))
+		}
+		start, err := time.Parse(dateLayout, row[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: row %d: invalid period start %q", filepath.Base(path), i+2, row[1])
+		}
+		lastTrade, _ := time.Parse(dateLayout, row[2])
+		tradingDays, _ := strconv.Atoi(row[10])
+		bars = append(bars, Bar{
+			Symbol:      row[0],
+			Start:       start,
+			LastTrade:   lastTrade,
+			Open:        number(row[3]),
+			High:        number(row[4]),
+			Low:         number(row[5]),
+			Close:       number(row[6]),
+			Volume:      int64(number(row[7])),
+			Value:       number(row[8]),
+			NumTrades:   int64(number(row[9])),
+			TradingDays: tradingDays,
+		})
+	}
+	return bars, nil
+}
@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/exporter"
+	"isxcli/pkg/contracts/domain"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+// testRecords covers two ISX weeks (Sun 5 Jan and Sun 12 Jan) for BBOB, with
+// forward-filled days that must not move the bars
+func testRecords() []domain.TradeRecord {
+	return []domain.TradeRecord{
+		{CompanySymbol: "BBOB", Date: day(6), OpenPrice: 1.25, HighPrice: 1.32, LowPrice: 1.24, ClosePrice: 1.30, Volume: 1000, Value: 1300, NumTrades: 4, TradingStatus: true},
+		{CompanySymbol: "BBOB", Date: day(5), ClosePrice: 1.20, TradingStatus: false},
+		{CompanySymbol: "BBOB", Date: day(7), ClosePrice: 1.30, TradingStatus: false},
+		{CompanySymbol: "BBOB", Date: day(8), OpenPrice: 1.31, HighPrice: 1.40, LowPrice: 1.21, ClosePrice: 1.35, Volume: 500, Value: 675, NumTrades: 2, TradingStatus: true},
+		{CompanySymbol: "BBOB", Date: day(12), ClosePrice: 1.36, Volume: 100, Value: 136, NumTrades: 1, TradingStatus: true},
+		{CompanySymbol: "BBOB", Date: day(13), ClosePrice: 1.36, TradingStatus: false},
+		{CompanySymbol: "TASC", Date: day(2), ClosePrice: 8.00, TradingStatus: false},
+		{CompanySymbol: "AAIB", Date: day(2), OpenPrice: 1.0, HighPrice: 1.1, LowPrice: 0.9, ClosePrice: 1.05, Volume: 10, Value: 10.5, NumTrades: 1, TradingStatus: true},
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	for _, s := range []string{"1w", "weekly", "W"} {
+		got, err := ParseInterval(s)
+		require.NoError(t, err)
+		assert.Equal(t, IntervalWeek, got)
+	}
+	for _, s := range []string{"1mo", "month", "1M"} {
+		got, err := ParseInterval(s)
+		require.NoError(t, err)
+		assert.Equal(t, IntervalMonth, got)
+	}
+	_, err := ParseInterval("1d")
+	assert.Error(t, err)
+}
+
+func TestIntervalPeriodStart(t *testing.T) {
+	assert.Equal(t, day(5), IntervalWeek.PeriodStart(day(5)), "Sunday starts its own week")
+	assert.Equal(t, day(5), IntervalWeek.PeriodStart(day(9).Add(13*time.Hour)))
+	assert.Equal(t, day(12), IntervalWeek.PeriodStart(day(12)))
+	assert.Equal(t, day(1), IntervalMonth.PeriodStart(day(31)))
+}
+
+func TestResample(t *testing.T) {
+	t.Run("weekly", func(t *testing.T) {
+		bars := Resample(testRecords(), IntervalWeek)
+		require.Len(t, bars, 3, "TASC never traded so it has no bars")
+
+		assert.Equal(t, "AAIB", bars[0].Symbol)
+		assert.Equal(t, Bar{
+			Symbol: "BBOB", Start: day(5), LastTrade: day(8),
+			Open: 1.25, High: 1.40, Low: 1.21, Close: 1.35,
+			Volume: 1500, Value: 1975, NumTrades: 6, TradingDays: 2,
+		}, bars[1], "forward-filled days are ignored")
+		assert.Equal(t, Bar{
+			Symbol: "BBOB", Start: day(12), LastTrade: day(12),
+			Open: 1.36, High: 1.36, Low: 1.36, Close: 1.36,
+			Volume: 100, Value: 136, NumTrades: 1, TradingDays: 1,
+		}, bars[2], "missing open, high and low fall back to the close")
+	})
+
+	t.Run("monthly", func(t *testing.T) {
+		bars := Resample(testRecords(), IntervalMonth)
+		require.Len(t, bars, 2)
+		bbob := bars[1]
+		assert.Equal(t, day(1), bbob.Start)
+		assert.Equal(t, 1.25, bbob.Open)
+		assert.Equal(t, 1.36, bbob.Close)
+		assert.Equal(t, 1.21, bbob.Low)
+		assert.Equal(t, int64(1600), bbob.Volume)
+		assert.Equal(t, 3, bbob.TradingDays)
+	})
+}
+
+func TestWriteAggregates(t *testing.T) {
+	dir := t.TempDir()
+	opts := exporter.DefaultExportOptions()
+	opts.BOM = true
+	opts.ThousandsSeparator = ","
+
+	written, err := WriteAggregates(dir, testRecords(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 4, written, "AAIB and BBOB for both intervals")
+
+	bars, err := ReadBarsCSV(filepath.Join(dir, BarsFileName("bbob", IntervalWeek)), opts)
+	require.NoError(t, err)
+	assert.Equal(t, Resample(testRecords(), IntervalWeek)[1:], bars)
+
+	assert.FileExists(t, filepath.Join(dir, "AAIB_1mo.csv"))
+	assert.NoFileExists(t, filepath.Join(dir, "TASC_1w.csv"))
+}
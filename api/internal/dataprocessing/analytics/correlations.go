@@ -0,0 +1,328 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// Correlation report locations under the reports directory
+const (
+	CorrelationsDirName    = "correlations"
+	CorrelationsReportName = "correlations.json"
+)
+
+// CorrelationsCSVName is the pairwise correlations report of a lookback window
+func CorrelationsCSVName(days int) string {
+	return fmt.Sprintf("correlations_%dd.csv", days)
+}
+
+// BetasCSVName is the beta report of a lookback window
+func BetasCSVName(days int) string {
+	return fmt.Sprintf("betas_%dd.csv", days)
+}
+
+// CorrelationHeaders are the columns of a pairwise correlations report
+var CorrelationHeaders = []string{"Symbol", "Other", "Correlation", "Observations"}
+
+// BetaHeaders are the columns of a beta report
+var BetaHeaders = []string{"Symbol", "Beta", "Correlation", "Observations"}
+
+// CorrelationOptions configures the correlation and beta calculation
+type CorrelationOptions struct {
+	// Windows are the lookback windows in trading sessions of the market
+	Windows []int
+	// MinObservations is the fewest daily returns two series must share
+	// within a window for their correlation or beta to be reported
+	MinObservations int
+}
+
+// DefaultCorrelationOptions returns 60, 120 and 250 session windows and at
+// least 20 shared returns
+func DefaultCorrelationOptions() CorrelationOptions {
+	return CorrelationOptions{Windows: []int{60, 120, 250}, MinObservations: 20}
+}
+
+// CorrelationReport holds the correlations and betas of every lookback window
+type CorrelationReport struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Benchmark   string              `json:"benchmark"`
+	Windows     []CorrelationWindow `json:"windows"`
+}
+
+// CorrelationWindow is the result of one lookback window. Pairs and betas
+// without enough shared returns are left out.
+type CorrelationWindow struct {
+	Days         int               `json:"days"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Tickers      []string          `json:"tickers"` // Tickers with enough returns in the window
+	Correlations []PairCorrelation `json:"correlations"`
+	Betas        []TickerBeta      `json:"betas"`
+}
+
+// PairCorrelation is the Pearson correlation of two tickers' daily returns,
+// with Symbol ordered before Other
+type PairCorrelation struct {
+	Symbol       string  `json:"symbol"`
+	Other        string  `json:"other"`
+	Correlation  float64 `json:"correlation"`
+	Observations int     `json:"observations"`
+}
+
+// TickerBeta is a ticker's beta against the benchmark index and the
+// correlation of their daily returns
+type TickerBeta struct {
+	Symbol       string  `json:"symbol"`
+	Beta         float64 `json:"beta"`
+	Correlation  float64 `json:"correlation"`
+	Observations int     `json:"observations"`
+}
+
+// Window returns the result of the lookback window of days sessions
+func (r *CorrelationReport) Window(days int) (*CorrelationWindow, bool) {
+	for i := range r.Windows {
+		if r.Windows[i].Days == days {
+			return &r.Windows[i], true
+		}
+	}
+	return nil, false
+}
+
+// ComputeCorrelations correlates the daily returns of every pair of tickers
+// and regresses each on the benchmark's daily returns, keyed by date. A
+// ticker's return on a session is its close against the close of the
+// previous session, counted only when it traded on both; forward-filled
+// records never contribute. Each window covers the last sessions of the
+// market, the dates on which any ticker traded.
+func ComputeCorrelations(records []domain.TradeRecord, benchmark string, benchmarkReturns map[time.Time]float64, opts CorrelationOptions, now time.Time) CorrelationReport {
+	defaults := DefaultCorrelationOptions()
+	if len(opts.Windows) == 0 {
+		opts.Windows = defaults.Windows
+	}
+	if opts.MinObservations < 3 {
+		opts.MinObservations = defaults.MinObservations
+	}
+
+	closes := make(map[string]map[time.Time]float64)
+	sessionSet := make(map[time.Time]bool)
+	for _, r := range records {
+		if !r.TradingStatus || r.ClosePrice <= 0 {
+			continue
+		}
+		day := dateOnly(r.Date)
+		if closes[r.CompanySymbol] == nil {
+			closes[r.CompanySymbol] = make(map[time.Time]float64)
+		}
+		closes[r.CompanySymbol][day] = r.ClosePrice
+		sessionSet[day] = true
+	}
+	sessions := make([]time.Time, 0, len(sessionSet))
+	for day := range sessionSet {
+		sessions = append(sessions, day)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Before(sessions[j]) })
+
+	// returns[symbol][i] is the return into sessions[i], NaN when unknown
+	returns := make(map[string][]float64, len(closes))
+	for symbol, byDay := range closes {
+		series := make([]float64, len(sessions))
+		for i, day := range sessions {
+			series[i] = math.NaN()
+			if i == 0 {
+				continue
+			}
+			cur, ok := byDay[day]
+			prev, prevOK := byDay[sessions[i-1]]
+			if ok && prevOK {
+				series[i] = cur/prev - 1
+			}
+		}
+		returns[symbol] = series
+	}
+	market := make([]float64, len(sessions))
+	for i, day := range sessions {
+		market[i] = math.NaN()
+		if r, ok := benchmarkReturns[dateOnly(day)]; ok {
+			market[i] = r
+		}
+	}
+
+	symbols := make([]string, 0, len(returns))
+	for symbol := range returns {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	report := CorrelationReport{GeneratedAt: now.UTC(), Benchmark: benchmark, Windows: []CorrelationWindow{}}
+	for _, days := range opts.Windows {
+		if days <= 0 || len(sessions) == 0 {
+			continue
+		}
+		start := max(0, len(sessions)-days)
+		window := CorrelationWindow{
+			Days:         days,
+			From:         sessions[start],
+			To:           sessions[len(sessions)-1],
+			Tickers:      []string{},
+			Correlations: []PairCorrelation{},
+			Betas:        []TickerBeta{},
+		}
+
+		var tickers []string
+		for _, symbol := range symbols {
+			if countValid(returns[symbol][start:]) >= opts.MinObservations {
+				tickers = append(tickers, symbol)
+			}
+		}
+		for i, a := range tickers {
+			ra := returns[a][start:]
+			for _, b := range tickers[i+1:] {
+				if corr, _, n, ok := regress(ra, returns[b][start:], opts.MinObservations); ok {
+					window.Correlations = append(window.Correlations, PairCorrelation{
+						Symbol: a, Other: b, Correlation: corr, Observations: n,
+					})
+				}
+			}
+			if corr, beta, n, ok := regress(ra, market[start:], opts.MinObservations); ok {
+				window.Betas = append(window.Betas, TickerBeta{
+					Symbol: a, Beta: beta, Correlation: corr, Observations: n,
+				})
+			}
+		}
+		if tickers != nil {
+			window.Tickers = tickers
+		}
+		report.Windows = append(report.Windows, window)
+	}
+	return report
+}
+
+// regress returns the correlation of y and x over the positions where both
+// are known, the slope of y on x and how many positions there were. ok is
+// false with fewer than minObs positions or when either series is constant.
+func regress(y, x []float64, minObs int) (corr, slope float64, n int, ok bool) {
+	var sumX, sumY float64
+	for i := range y {
+		if math.IsNaN(y[i]) || math.IsNaN(x[i]) {
+			continue
+		}
+		sumX += x[i]
+		sumY += y[i]
+		n++
+	}
+	if n < minObs || n < 2 {
+		return 0, 0, n, false
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var cov, varX, varY float64
+	for i := range y {
+		if math.IsNaN(y[i]) || math.IsNaN(x[i]) {
+			continue
+		}
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, 0, n, false
+	}
+	corr = cov / math.Sqrt(varX*varY)
+	return math.Max(-1, math.Min(1, corr)), cov / varX, n, true
+}
+
+func countValid(values []float64) int {
+	n := 0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			n++
+		}
+	}
+	return n
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WriteCorrelations writes the report as JSON and, per window, the pairwise
+// correlations and betas as CSV into dir
+func WriteCorrelations(dir string, report CorrelationReport) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, w := range report.Windows {
+		rows := make([][]string, 0, len(w.Correlations))
+		for _, c := range w.Correlations {
+			rows = append(rows, []string{
+				c.Symbol,
+				c.Other,
+				strconv.FormatFloat(c.Correlation, 'f', 4, 64),
+				strconv.Itoa(c.Observations),
+			})
+		}
+		if err := writeCSV(filepath.Join(dir, CorrelationsCSVName(w.Days)), CorrelationHeaders, rows); err != nil {
+			return err
+		}
+
+		rows = make([][]string, 0, len(w.Betas))
+		for _, b := range w.Betas {
+			rows = append(rows, []string{
+				b.Symbol,
+				strconv.FormatFloat(b.Beta, 'f', 4, 64),
+				strconv.FormatFloat(b.Correlation, 'f', 4, 64),
+				strconv.Itoa(b.Observations),
+			})
+		}
+		if err := writeCSV(filepath.Join(dir, BetasCSVName(w.Days)), BetaHeaders, rows); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, CorrelationsReportName), data, 0644)
+}
+
+func writeCSV(path string, headers []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ReadCorrelations reads the report of the latest run from dir
+func ReadCorrelations(dir string) (*CorrelationReport, error) {
+	data, err := os.ReadFile(filepath.Join(dir, CorrelationsReportName))
+	if err != nil {
+		return nil, err
+	}
+	var report CorrelationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", CorrelationsReportName, err)
+	}
+	return &report, nil
+}
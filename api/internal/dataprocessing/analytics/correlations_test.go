@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// correlationRecords gives 30 sessions in which BBOB returns twice the
+// market, TASC the opposite of the market and IBSD trades only three times.
+// BBOB is forward-filled on session 10.
+func correlationRecords() ([]domain.TradeRecord, map[time.Time]float64) {
+	market := make(map[time.Time]float64)
+	var records []domain.TradeRecord
+	bbob, tasc := 1.0, 5.0
+	for d := 1; d <= 30; d++ {
+		r := 0.01 * math.Sin(float64(d))
+		if d > 1 {
+			market[day(d)] = r
+			bbob *= 1 + 2*r
+			tasc *= 1 - r
+		}
+		records = append(records,
+			domain.TradeRecord{CompanySymbol: "BBOB", Date: day(d), ClosePrice: bbob, TradingStatus: d != 10},
+			domain.TradeRecord{CompanySymbol: "TASC", Date: day(d), ClosePrice: tasc, TradingStatus: true},
+		)
+		if d%10 == 0 {
+			records = append(records, domain.TradeRecord{CompanySymbol: "IBSD", Date: day(d), ClosePrice: 2, TradingStatus: true})
+		}
+	}
+	return records, market
+}
+
+func TestComputeCorrelations(t *testing.T) {
+	records, market := correlationRecords()
+	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	report := ComputeCorrelations(records, "ISX60", market, CorrelationOptions{Windows: []int{20, 60}, MinObservations: 5}, now)
+
+	assert.Equal(t, "ISX60", report.Benchmark)
+	require.Len(t, report.Windows, 2)
+
+	short, ok := report.Window(20)
+	require.True(t, ok)
+	assert.Equal(t, day(11), short.From)
+	assert.Equal(t, day(30), short.To)
+	assert.Equal(t, []string{"BBOB", "TASC"}, short.Tickers, "IBSD has too few returns")
+
+	require.Len(t, short.Correlations, 1)
+	pair := short.Correlations[0]
+	assert.Equal(t, "BBOB", pair.Symbol)
+	assert.Equal(t, "TASC", pair.Other)
+	assert.InDelta(t, -1, pair.Correlation, 1e-9)
+	assert.Equal(t, 19, pair.Observations, "BBOB has no return into session 11")
+
+	require.Len(t, short.Betas, 2)
+	assert.InDelta(t, 2, short.Betas[0].Beta, 1e-9)
+	assert.InDelta(t, 1, short.Betas[0].Correlation, 1e-9)
+	assert.InDelta(t, -1, short.Betas[1].Beta, 1e-9)
+
+	long, ok := report.Window(60)
+	require.True(t, ok)
+	assert.Equal(t, day(1), long.From, "a window longer than the history covers all of it")
+	assert.Equal(t, 27, long.Correlations[0].Observations)
+
+	_, ok = report.Window(120)
+	assert.False(t, ok)
+}
+
+func TestComputeCorrelationsEmpty(t *testing.T) {
+	report := ComputeCorrelations(nil, "ISX60", nil, DefaultCorrelationOptions(), time.Now())
+	assert.Empty(t, report.Windows)
+}
+
+func TestWriteCorrelations(t *testing.T) {
+	records, market := correlationRecords()
+	report := ComputeCorrelations(records, "ISX60", market, CorrelationOptions{Windows: []int{20}, MinObservations: 5}, time.Now())
+
+	dir := filepath.Join(t.TempDir(), CorrelationsDirName)
+	require.NoError(t, WriteCorrelations(dir, report))
+
+	pairs, err := os.ReadFile(filepath.Join(dir, CorrelationsCSVName(20)))
+	require.NoError(t, err)
+	assert.Equal(t, "Symbol,Other,Correlation,Observations\nBBOB,TASC,-1.0000,19\n", string(pairs))
+
+	betas, err := os.ReadFile(filepath.Join(dir, BetasCSVName(20)))
+	require.NoError(t, err)
+	assert.Contains(t, string(betas), "BBOB,2.0000,1.0000,19\n")
+
+	read, err := ReadCorrelations(dir)
+	require.NoError(t, err)
+	assert.Equal(t, report.Windows[0].Betas, read.Windows[0].Betas)
+}
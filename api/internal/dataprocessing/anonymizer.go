@@ -0,0 +1,424 @@
+package dataprocessing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column kinds recognised by the anonymizer (matched case-insensitively on the header)
+const (
+	anonSymbol       = "symbol"
+	anonName         = "name"
+	anonPrice        = "price"
+	anonPriceList    = "price_list"
+	anonVolume       = "volume"
+	anonValue        = "value"
+	anonInverseValue = "inverse_value"
+)
+
+// anonColumns maps CSV headers written by the processor, summarizer and
+// liquidity report to how they are anonymized. Unlisted columns (dates,
+// percentages, scores, trade counts) are copied unchanged.
+var anonColumns = map[string]string{
+	"symbol":        anonSymbol,
+	"ticker":        anonSymbol,
+	"companysymbol": anonSymbol,
+
+	"companyname":  anonName,
+	"company_name": anonName,
+	"company":      anonName,
+
+	"openprice":        anonPrice,
+	"highprice":        anonPrice,
+	"lowprice":         anonPrice,
+	"averageprice":     anonPrice,
+	"prevaverageprice": anonPrice,
+	"closeprice":       anonPrice,
+	"prevcloseprice":   anonPrice,
+	"change":           anonPrice,
+	"lastprice":        anonPrice,
+	"highestprice":     anonPrice,
+	"lowestprice":      anonPrice,
+	"open":             anonPrice,
+	"high":             anonPrice,
+	"low":              anonPrice,
+	"close":            anonPrice,
+	"last10days":       anonPriceList,
+
+	"volume":      anonVolume,
+	"totalvolume": anonVolume,
+
+	"value":           anonValue,
+	"totalvalue":      anonValue,
+	"value_raw":       anonValue,
+	"safe_trade_0.5%": anonValue,
+	"safe_trade_1%":   anonValue,
+	"safe_trade_2%":   anonValue,
+	"optimal_trade":   anonValue,
+
+	"illiq_raw": anonInverseValue,
+}
+
+var (
+	fakeNameAdjectives = []string{"Tigris", "Euphrates", "Northern", "Southern", "Golden", "Eastern", "United", "Crescent", "Delta", "Summit", "Cedar", "Falcon"}
+	fakeNameNouns      = []string{"Trade", "Capital", "Cement", "Textile", "Agriculture", "Telecom", "Hotels", "Industries", "Transport", "Insurance", "Energy", "Food"}
+	fakeNameSuffixes   = []string{"Bank", "Company", "Holding", "Group", "Investments"}
+)
+
+// AnonymizeReport summarises an AnonymizeDir run
+type AnonymizeReport struct {
+	FilesWritten []string `json:"files_written"`
+	FilesSkipped []string `json:"files_skipped"` // Non-CSV files, which are not copied
+	Symbols      int      `json:"symbols"`
+}
+
+// Anonymizer rewrites report CSVs so they can be shared with support.
+// Symbols and company names are replaced, prices and volumes are multiplied by
+// factors derived from a secret, and values by the product of both. Returns,
+// percentages, ranks and trade counts are unchanged, so statistics and bugs
+// that depend on them reproduce on the anonymized data. The same secret always
+// produces the same mapping.
+type Anonymizer struct {
+	secret       []byte
+	priceFactor  float64
+	volumeFactor float64
+	symbols      map[string]string
+	usedSymbols  map[string]bool
+	names        map[string]string
+	usedNames    map[string]bool
+}
+
+// NewAnonymizer creates an anonymizer keyed by secret
+func NewAnonymizer(secret string) (*Anonymizer, error) {
+	if strings.TrimSpace(secret) == "" {
+		return nil, fmt.Errorf("anonymizer secret must not be empty")
+	}
+
+	a := &Anonymizer{
+		secret:      []byte(secret),
+		symbols:     make(map[string]string),
+		usedSymbols: make(map[string]bool),
+		names:       make(map[string]string),
+		usedNames:   make(map[string]bool),
+	}
+	a.priceFactor = a.factor("price")
+	a.volumeFactor = a.factor("volume")
+	return a, nil
+}
+
+// PriceFactor returns the multiplier applied to prices
+func (a *Anonymizer) PriceFactor() float64 { return a.priceFactor }
+
+// VolumeFactor returns the multiplier applied to volumes
+func (a *Anonymizer) VolumeFactor() float64 { return a.volumeFactor }
+
+// Symbol returns the fake symbol for an original symbol
+func (a *Anonymizer) Symbol(symbol string) string {
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return ""
+	}
+	if fake, ok := a.symbols[symbol]; ok {
+		return fake
+	}
+
+	for attempt := 0; ; attempt++ {
+		sum := a.hash(fmt.Sprintf("symbol:%s:%d", symbol, attempt))
+		fake := make([]byte, 4)
+		for i := range fake {
+			fake[i] = 'A' + sum[i]%26
+		}
+		if !a.usedSymbols[string(fake)] {
+			a.symbols[symbol] = string(fake)
+			a.usedSymbols[string(fake)] = true
+			return string(fake)
+		}
+	}
+}
+
+// CompanyName returns the fake company name for an original name
+func (a *Anonymizer) CompanyName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	if fake, ok := a.names[name]; ok {
+		return fake
+	}
+
+	for attempt := 0; ; attempt++ {
+		sum := a.hash(fmt.Sprintf("name:%s:%d", name, attempt))
+		fake := fmt.Sprintf("%s %s %s",
+			fakeNameAdjectives[int(sum[0])%len(fakeNameAdjectives)],
+			fakeNameNouns[int(sum[1])%len(fakeNameNouns)],
+			fakeNameSuffixes[int(sum[2])%len(fakeNameSuffixes)])
+		if attempt > 0 {
+			fake = fmt.Sprintf("%s %d", fake, attempt+1)
+		}
+		if !a.usedNames[fake] {
+			a.names[name] = fake
+			a.usedNames[fake] = true
+			return fake
+		}
+	}
+}
+
+// SymbolMapping returns original → fake symbols. Keep it with the customer;
+// it reverses the anonymization.
+func (a *Anonymizer) SymbolMapping() map[string]string {
+	mapping := make(map[string]string, len(a.symbols))
+	for k, v := range a.symbols {
+		mapping[k] = v
+	}
+	return mapping
+}
+
+// AnonymizeDir anonymizes every CSV under srcDir into dstDir, keeping the
+// directory layout. Files named after a symbol (e.g. BBOB_trading_history.csv)
+// are renamed to the fake symbol.
+func (a *Anonymizer) AnonymizeDir(srcDir, dstDir string) (*AnonymizeReport, error) {
+	srcAbs, err := filepath.Abs(srcDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source directory: %w", err)
+	}
+	dstAbs, err := filepath.Abs(dstDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination directory: %w", err)
+	}
+	if dstAbs == srcAbs || strings.HasPrefix(dstAbs, srcAbs+string(filepath.Separator)) {
+		return nil, fmt.Errorf("destination %s must not be inside the source directory", dstDir)
+	}
+
+	report := &AnonymizeReport{}
+	var csvFiles []string
+	err = filepath.WalkDir(srcAbs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(srcAbs, path)
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			csvFiles = append(csvFiles, rel)
+		} else {
+			report.FilesSkipped = append(report.FilesSkipped, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan source directory: %w", err)
+	}
+
+	// Assign fake symbols in sorted order first so the mapping does not depend
+	// on which file happens to mention a symbol first
+	seen := make(map[string]bool)
+	for _, rel := range csvFiles {
+		if err := collectSymbols(filepath.Join(srcAbs, rel), seen); err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	}
+	ordered := make([]string, 0, len(seen))
+	for symbol := range seen {
+		ordered = append(ordered, symbol)
+	}
+	sort.Strings(ordered)
+	for _, symbol := range ordered {
+		a.Symbol(symbol)
+	}
+	report.Symbols = len(ordered)
+
+	for _, rel := range csvFiles {
+		outRel := filepath.Join(filepath.Dir(rel), a.fileName(filepath.Base(rel)))
+		if err := a.AnonymizeCSV(filepath.Join(srcAbs, rel), filepath.Join(dstAbs, outRel)); err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		report.FilesWritten = append(report.FilesWritten, outRel)
+	}
+
+	return report, nil
+}
+
+// AnonymizeCSV anonymizes a single CSV file
+func (a *Anonymizer) AnonymizeCSV(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open CSV: %w", err)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create output CSV: %w", err)
+	}
+	defer out.Close()
+
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	writer := csv.NewWriter(out)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	kinds := columnKinds(header)
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+		for i := range rec {
+			if i < len(kinds) && kinds[i] != "" {
+				rec[i] = a.field(kinds[i], rec[i])
+			}
+		}
+		if err := writer.Write(rec); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// field anonymizes one cell according to its column kind
+func (a *Anonymizer) field(kind, value string) string {
+	switch kind {
+	case anonSymbol:
+		return a.Symbol(value)
+	case anonName:
+		return a.CompanyName(value)
+	case anonPrice:
+		return scaleNumber(value, a.priceFactor)
+	case anonPriceList:
+		parts := strings.Split(value, ",")
+		for i, p := range parts {
+			parts[i] = scaleNumber(p, a.priceFactor)
+		}
+		return strings.Join(parts, ",")
+	case anonVolume:
+		return scaleNumber(value, a.volumeFactor)
+	case anonValue:
+		return scaleNumber(value, a.priceFactor*a.volumeFactor)
+	case anonInverseValue:
+		return scaleNumber(value, 1/(a.priceFactor*a.volumeFactor))
+	}
+	return value
+}
+
+// fileName replaces a leading symbol in names like BBOB_trading_history.csv
+func (a *Anonymizer) fileName(name string) string {
+	prefix, rest, ok := strings.Cut(name, "_")
+	if !ok {
+		return name
+	}
+	if fake, known := a.symbols[prefix]; known {
+		return fake + "_" + rest
+	}
+	return name
+}
+
+// factor derives a multiplier in [0.5, 2) from the secret
+func (a *Anonymizer) factor(label string) float64 {
+	sum := a.hash("factor:" + label)
+	u := float64(binary.BigEndian.Uint64(sum[:8])) / float64(math.MaxUint64)
+	return 0.5 + 1.5*u
+}
+
+func (a *Anonymizer) hash(input string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// collectSymbols adds every value of the file's symbol columns to seen
+func collectSymbols(path string, seen map[string]bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	kinds := columnKinds(header)
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+		for i, kind := range kinds {
+			if kind == anonSymbol && i < len(rec) {
+				if symbol := strings.TrimSpace(rec[i]); symbol != "" {
+					seen[symbol] = true
+				}
+			}
+		}
+	}
+}
+
+// columnKinds classifies each header column
+func columnKinds(header []string) []string {
+	kinds := make([]string, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		kinds[i] = anonColumns[key]
+	}
+	return kinds
+}
+
+// scaleNumber multiplies a numeric cell, keeping its decimal places. Thousands
+// separators are dropped; cells that are not numbers are returned unchanged.
+func scaleNumber(value string, factor float64) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return value
+	}
+	plain := strings.NewReplacer(",", "", " ", "").Replace(trimmed)
+	v, err := strconv.ParseFloat(plain, 64)
+	if err != nil {
+		return value
+	}
+
+	decimals := 0
+	if idx := strings.IndexByte(plain, '.'); idx >= 0 {
+		decimals = len(plain) - idx - 1
+	}
+	return strconv.FormatFloat(v*factor, 'f', decimals, 64)
+}
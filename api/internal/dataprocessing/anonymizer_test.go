@@ -0,0 +1,101 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readCSVFile(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestAnonymizer_AnonymizeDir(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "out")
+
+	bbob := "Date,CompanyName,Symbol,ClosePrice,PrevClosePrice,ChangePercent,NumTrades,Volume,Value\n" +
+		"2025-01-05,Bank of Baghdad,BBOB,1.000,1.000,0.00,10,1000,1000.00\n" +
+		"2025-01-06,Bank of Baghdad,BBOB,1.100,1.000,10.00,12,2000,2200.00\n"
+	combined := bbob + "2025-01-06,Asia Cell,TASC,8.500,8.000,6.25,40,500,4250.00\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "combined"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "combined", "isx_combined_data.csv"), []byte(combined), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "BBOB_trading_history.csv"), []byte(bbob), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "ticker_summary.json"), []byte(`{"tickers":[]}`), 0644))
+
+	a, err := NewAnonymizer("s3cret")
+	require.NoError(t, err)
+
+	report, err := a.AnonymizeDir(src, dst)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Symbols)
+	assert.Len(t, report.FilesWritten, 2)
+	assert.Equal(t, []string{"ticker_summary.json"}, report.FilesSkipped)
+
+	fakeBBOB := a.Symbol("BBOB")
+	assert.NotEqual(t, "BBOB", fakeBBOB)
+	assert.FileExists(t, filepath.Join(dst, fakeBBOB+"_trading_history.csv"))
+	assert.NoFileExists(t, filepath.Join(dst, "BBOB_trading_history.csv"))
+
+	rows := readCSVFile(t, filepath.Join(dst, "combined", "isx_combined_data.csv"))
+	require.Len(t, rows, 4)
+	for _, row := range rows[1:] {
+		assert.NotContains(t, []string{"Bank of Baghdad", "Asia Cell"}, row[1])
+		assert.NotContains(t, []string{"BBOB", "TASC"}, row[2])
+	}
+	assert.Equal(t, rows[1][1], rows[2][1], "same company maps to the same fake name")
+
+	// Returns, percentages and trade counts are preserved
+	close1, _ := strconv.ParseFloat(rows[1][3], 64)
+	close2, _ := strconv.ParseFloat(rows[2][3], 64)
+	assert.InDelta(t, 0.10, close2/close1-1, 0.002)
+	assert.Equal(t, "10.00", rows[2][5])
+	assert.Equal(t, "12", rows[2][6])
+	assert.Len(t, rows[2][3], len("1.100"), "decimal places are kept")
+
+	// Value stays consistent with price * volume scaling
+	value, _ := strconv.ParseFloat(rows[2][8], 64)
+	assert.InDelta(t, 2200*a.PriceFactor()*a.VolumeFactor(), value, 0.01)
+}
+
+func TestAnonymizer_Deterministic(t *testing.T) {
+	a1, err := NewAnonymizer("key")
+	require.NoError(t, err)
+	a2, err := NewAnonymizer("key")
+	require.NoError(t, err)
+	other, err := NewAnonymizer("other")
+	require.NoError(t, err)
+
+	assert.Equal(t, a1.Symbol("BBOB"), a2.Symbol("BBOB"))
+	assert.Equal(t, a1.CompanyName("Bank of Baghdad"), a2.CompanyName("Bank of Baghdad"))
+	assert.Equal(t, a1.PriceFactor(), a2.PriceFactor())
+	assert.NotEqual(t, a1.PriceFactor(), other.PriceFactor())
+
+	assert.GreaterOrEqual(t, a1.PriceFactor(), 0.5)
+	assert.Less(t, a1.PriceFactor(), 2.0)
+
+	_, err = NewAnonymizer("  ")
+	assert.Error(t, err)
+}
+
+func TestAnonymizer_RejectsNestedDestination(t *testing.T) {
+	src := t.TempDir()
+	a, err := NewAnonymizer("key")
+	require.NoError(t, err)
+
+	_, err = a.AnonymizeDir(src, filepath.Join(src, "anon"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,345 @@
+package dataprocessing
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DataProfileSchemaVersion is bumped whenever the profile JSON layout changes
+const DataProfileSchemaVersion = 1
+
+// maxDistinctTracked bounds memory per column; beyond it Distinct is a lower bound
+const maxDistinctTracked = 100000
+
+// DataProfile is the column statistics artifact written after every processing
+// run. Comparing consecutive profiles shows dataset drift between releases.
+type DataProfile struct {
+	SchemaVersion int             `json:"schema_version"`
+	RunID         string          `json:"run_id"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Lineage       ProfileLineage  `json:"lineage"`
+	RowCount      int             `json:"row_count"`
+	Columns       []ColumnProfile `json:"columns"`
+	Tickers       []TickerProfile `json:"tickers"`
+}
+
+// ProfileLineage records where the profiled dataset came from
+type ProfileLineage struct {
+	Dataset         string       `json:"dataset"` // Profiled file, relative to the reports directory
+	DatasetSHA256   string       `json:"dataset_sha256"`
+	DataProfile     string       `json:"data_profile"` // config data profile (ISX_PROFILE) the run used
+	FullRework      bool         `json:"full_rework"`
+	SourceFiles     []SourceFile `json:"source_files"` // Excel reports processed in this run
+	PreviousProfile string       `json:"previous_profile,omitempty"`
+}
+
+// SourceFile identifies an input file of a processing run
+type SourceFile struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ColumnProfile holds statistics for one CSV column. Min, Max and Mean are set
+// for numeric columns; MinValue and MaxValue hold the lexical range otherwise.
+type ColumnProfile struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"` // numeric|date|string
+	Nulls          int      `json:"nulls"`
+	NullRate       float64  `json:"null_rate"`
+	Distinct       int      `json:"distinct"`
+	DistinctCapped bool     `json:"distinct_capped,omitempty"`
+	Min            *float64 `json:"min,omitempty"`
+	Max            *float64 `json:"max,omitempty"`
+	Mean           *float64 `json:"mean,omitempty"`
+	MinValue       string   `json:"min_value,omitempty"`
+	MaxValue       string   `json:"max_value,omitempty"`
+}
+
+// TickerProfile holds per-ticker row counts
+type TickerProfile struct {
+	Symbol      string `json:"symbol"`
+	Rows        int    `json:"rows"`
+	TradingRows int    `json:"trading_rows"`
+	FirstDate   string `json:"first_date"`
+	LastDate    string `json:"last_date"`
+}
+
+// columnAccumulator collects statistics for one column in a single pass
+type columnAccumulator struct {
+	name       string
+	nulls      int
+	distinct   map[string]struct{}
+	capped     bool
+	numeric    int
+	dates      int
+	sum        float64
+	min, max   float64
+	minS, maxS string
+	nonNull    int
+}
+
+func (c *columnAccumulator) add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "null") || strings.EqualFold(value, "nan") {
+		c.nulls++
+		return
+	}
+
+	if c.nonNull == 0 || value < c.minS {
+		c.minS = value
+	}
+	if c.nonNull == 0 || value > c.maxS {
+		c.maxS = value
+	}
+	c.nonNull++
+
+	if !c.capped {
+		c.distinct[value] = struct{}{}
+		if len(c.distinct) >= maxDistinctTracked {
+			c.capped = true
+		}
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		if c.numeric == 0 || f < c.min {
+			c.min = f
+		}
+		if c.numeric == 0 || f > c.max {
+			c.max = f
+		}
+		c.sum += f
+		c.numeric++
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		c.dates++
+	}
+}
+
+func (c *columnAccumulator) profile(rows int) ColumnProfile {
+	p := ColumnProfile{
+		Name:           c.name,
+		Type:           "string",
+		Nulls:          c.nulls,
+		Distinct:       len(c.distinct),
+		DistinctCapped: c.capped,
+	}
+	if rows > 0 {
+		p.NullRate = roundTo(float64(c.nulls)/float64(rows), 6)
+	}
+
+	switch {
+	case c.nonNull > 0 && c.numeric == c.nonNull:
+		p.Type = "numeric"
+		minV, maxV, mean := c.min, c.max, roundTo(c.sum/float64(c.numeric), 6)
+		p.Min, p.Max, p.Mean = &minV, &maxV, &mean
+	case c.nonNull > 0 && c.dates == c.nonNull:
+		p.Type = "date"
+		p.MinValue, p.MaxValue = c.minS, c.maxS
+	default:
+		p.MinValue, p.MaxValue = c.minS, c.maxS
+	}
+
+	return p
+}
+
+// ProfileCSV computes column and per-ticker statistics for a CSV file in a
+// single streaming pass. Ticker counts use the Symbol, Date and TradingStatus
+// columns when present.
+func ProfileCSV(path string) (*DataProfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	reader := csv.NewReader(io.TeeReader(file, hasher))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make([]*columnAccumulator, len(header))
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")
+		columns[i] = &columnAccumulator{name: name, distinct: make(map[string]struct{})}
+		index[name] = i
+	}
+
+	symbolCol, hasSymbol := index["Symbol"]
+	dateCol, hasDate := index["Date"]
+	statusCol, hasStatus := index["TradingStatus"]
+	tickers := make(map[string]*TickerProfile)
+
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row %d: %w", rows+2, err)
+		}
+		rows++
+
+		for i, col := range columns {
+			if i < len(record) {
+				col.add(record[i])
+			} else {
+				col.nulls++
+			}
+		}
+
+		if !hasSymbol || symbolCol >= len(record) {
+			continue
+		}
+		symbol := strings.TrimSpace(record[symbolCol])
+		if symbol == "" {
+			continue
+		}
+		t, ok := tickers[symbol]
+		if !ok {
+			t = &TickerProfile{Symbol: symbol}
+			tickers[symbol] = t
+		}
+		t.Rows++
+		if !hasStatus || (statusCol < len(record) && strings.EqualFold(strings.TrimSpace(record[statusCol]), "true")) {
+			t.TradingRows++
+		}
+		if hasDate && dateCol < len(record) {
+			date := strings.TrimSpace(record[dateCol])
+			if date != "" && (t.FirstDate == "" || date < t.FirstDate) {
+				t.FirstDate = date
+			}
+			if date > t.LastDate {
+				t.LastDate = date
+			}
+		}
+	}
+
+	// Drain anything the CSV reader did not consume so the checksum covers the whole file
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	profile := &DataProfile{
+		SchemaVersion: DataProfileSchemaVersion,
+		RowCount:      rows,
+		Columns:       make([]ColumnProfile, 0, len(columns)),
+		Tickers:       make([]TickerProfile, 0, len(tickers)),
+		Lineage: ProfileLineage{
+			DatasetSHA256: hex.EncodeToString(hasher.Sum(nil)),
+		},
+	}
+	for _, col := range columns {
+		profile.Columns = append(profile.Columns, col.profile(rows))
+	}
+	for _, t := range tickers {
+		profile.Tickers = append(profile.Tickers, *t)
+	}
+	sort.Slice(profile.Tickers, func(i, j int) bool {
+		return profile.Tickers[i].Symbol < profile.Tickers[j].Symbol
+	})
+
+	return profile, nil
+}
+
+// SourceFilesFromPaths stats the given files for lineage; missing files are skipped
+func SourceFilesFromPaths(paths []string) []SourceFile {
+	files := make([]SourceFile, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, SourceFile{
+			Name:    filepath.Base(path),
+			Size:    info.Size(),
+			ModTime: info.ModTime().UTC(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
+// WriteDataProfile stores the profile as profile_<run id>.json in dir and updates
+// latest.json. The previous latest profile is linked in the lineage so profiles
+// form a chain that can be walked release-over-release.
+func WriteDataProfile(dir string, profile *DataProfile, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create profiling directory: %w", err)
+	}
+
+	now = now.UTC()
+	profile.GeneratedAt = now
+	if profile.RunID == "" {
+		profile.RunID = now.Format("20060102T150405Z")
+	}
+
+	latestPath := filepath.Join(dir, "latest.json")
+	if previous, err := ReadDataProfile(latestPath); err == nil && previous.RunID != profile.RunID {
+		profile.Lineage.PreviousProfile = dataProfileFileName(previous.RunID)
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	path := filepath.Join(dir, dataProfileFileName(profile.RunID))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	// Write latest.json via rename so readers never see a partial file
+	tmp := latestPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write latest profile: %w", err)
+	}
+	if err := os.Rename(tmp, latestPath); err != nil {
+		return "", fmt.Errorf("failed to update latest profile: %w", err)
+	}
+
+	return path, nil
+}
+
+// ReadDataProfile loads a profile written by WriteDataProfile
+func ReadDataProfile(path string) (*DataProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var profile DataProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+	return &profile, nil
+}
+
+func dataProfileFileName(runID string) string {
+	return "profile_" + runID + ".json"
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
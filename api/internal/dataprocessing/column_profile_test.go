@@ -0,0 +1,75 @@
+package dataprocessing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const profileTestCSV = "\ufeffDate,Symbol,ClosePrice,Volume,TradingStatus\n" +
+	"2025-01-05,BBOB,1.000,1000,true\n" +
+	"2025-01-06,BBOB,1.100,,false\n" +
+	"2025-01-05,TASC,8.500,500,true\n" +
+	"2025-01-06,TASC,9.000,700,true\n"
+
+func TestProfileCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "isx_combined_data.csv")
+	require.NoError(t, os.WriteFile(path, []byte(profileTestCSV), 0644))
+
+	profile, err := ProfileCSV(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, profile.RowCount)
+	assert.Len(t, profile.Lineage.DatasetSHA256, 64)
+	require.Len(t, profile.Columns, 5)
+
+	date := profile.Columns[0]
+	assert.Equal(t, "Date", date.Name, "BOM is stripped from the first header")
+	assert.Equal(t, "date", date.Type)
+	assert.Equal(t, "2025-01-05", date.MinValue)
+	assert.Equal(t, "2025-01-06", date.MaxValue)
+	assert.Equal(t, 2, date.Distinct)
+
+	closePrice := profile.Columns[2]
+	assert.Equal(t, "numeric", closePrice.Type)
+	assert.Equal(t, 1.0, *closePrice.Min)
+	assert.Equal(t, 9.0, *closePrice.Max)
+	assert.InDelta(t, 4.9, *closePrice.Mean, 1e-9)
+
+	volume := profile.Columns[3]
+	assert.Equal(t, 1, volume.Nulls)
+	assert.Equal(t, 0.25, volume.NullRate)
+
+	require.Len(t, profile.Tickers, 2)
+	assert.Equal(t, TickerProfile{Symbol: "BBOB", Rows: 2, TradingRows: 1, FirstDate: "2025-01-05", LastDate: "2025-01-06"}, profile.Tickers[0])
+	assert.Equal(t, "TASC", profile.Tickers[1].Symbol)
+	assert.Equal(t, 2, profile.Tickers[1].TradingRows)
+}
+
+func TestWriteDataProfile_LinksPrevious(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "data.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte(profileTestCSV), 0644))
+
+	first, err := ProfileCSV(csvPath)
+	require.NoError(t, err)
+	firstPath, err := WriteDataProfile(filepath.Join(dir, "profiling"), first, time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "profile_20250106T120000Z.json", filepath.Base(firstPath))
+	assert.Empty(t, first.Lineage.PreviousProfile)
+
+	second, err := ProfileCSV(csvPath)
+	require.NoError(t, err)
+	_, err = WriteDataProfile(filepath.Join(dir, "profiling"), second, time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	latest, err := ReadDataProfile(filepath.Join(dir, "profiling", "latest.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "20250107T120000Z", latest.RunID)
+	assert.Equal(t, "profile_20250106T120000Z.json", latest.Lineage.PreviousProfile)
+	assert.Equal(t, first.Lineage.DatasetSHA256, latest.Lineage.DatasetSHA256)
+}
@@ -0,0 +1,157 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CompanyProfile is the reference data for one listed company, scraped from
+// its page on the ISX portal. Financial figures are in IQD and belong to
+// FinancialYear; zero means the portal did not publish the value.
+type CompanyProfile struct {
+	Symbol            string    `json:"symbol"`
+	Name              string    `json:"name"`
+	Sector            string    `json:"sector"`
+	ListedShares      int64     `json:"listedShares"`
+	PaidUpCapital     float64   `json:"paidUpCapital,omitempty"`
+	FinancialYear     string    `json:"financialYear,omitempty"`
+	Revenue           float64   `json:"revenue,omitempty"`
+	NetIncome         float64   `json:"netIncome,omitempty"`
+	TotalAssets       float64   `json:"totalAssets,omitempty"`
+	ShareholderEquity float64   `json:"shareholderEquity,omitempty"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// companiesCSVHeaders is the column layout of data/reference/companies.csv
+var companiesCSVHeaders = []string{
+	"Symbol", "Name", "Sector", "ListedShares", "PaidUpCapital", "FinancialYear",
+	"Revenue", "NetIncome", "TotalAssets", "ShareholderEquity", "UpdatedAt",
+}
+
+// WriteCompaniesCSV writes the profiles sorted by symbol. The file is written
+// to a temporary name first so readers never see a partial file.
+func WriteCompaniesCSV(filePath string, profiles []CompanyProfile) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create reference directory: %w", err)
+	}
+
+	sorted := make([]CompanyProfile, len(profiles))
+	copy(sorted, profiles)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Symbol < sorted[j].Symbol })
+
+	tmpPath := filePath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create companies file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(companiesCSVHeaders); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	for _, p := range sorted {
+		row := []string{
+			p.Symbol,
+			p.Name,
+			p.Sector,
+			strconv.FormatInt(p.ListedShares, 10),
+			formatAmount(p.PaidUpCapital),
+			p.FinancialYear,
+			formatAmount(p.Revenue),
+			formatAmount(p.NetIncome),
+			formatAmount(p.TotalAssets),
+			formatAmount(p.ShareholderEquity),
+			p.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := writer.Write(row); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write companies file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close companies file: %w", err)
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// ReadCompaniesCSV loads profiles written by WriteCompaniesCSV. Columns are
+// matched by header name so hand-edited files with reordered or missing
+// columns still load.
+func ReadCompaniesCSV(filePath string) ([]CompanyProfile, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open companies file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read companies file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, h := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := columns["symbol"]; !ok {
+		return nil, fmt.Errorf("companies file has no Symbol column")
+	}
+	get := func(rec []string, name string) string {
+		if i, ok := columns[strings.ToLower(name)]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	number := func(rec []string, name string) float64 {
+		v, _ := strconv.ParseFloat(get(rec, name), 64)
+		return v
+	}
+
+	profiles := make([]CompanyProfile, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		symbol := strings.ToUpper(get(rec, "Symbol"))
+		if symbol == "" {
+			continue
+		}
+		p := CompanyProfile{
+			Symbol:            symbol,
+			Name:              get(rec, "Name"),
+			Sector:            get(rec, "Sector"),
+			PaidUpCapital:     number(rec, "PaidUpCapital"),
+			FinancialYear:     get(rec, "FinancialYear"),
+			Revenue:           number(rec, "Revenue"),
+			NetIncome:         number(rec, "NetIncome"),
+			TotalAssets:       number(rec, "TotalAssets"),
+			ShareholderEquity: number(rec, "ShareholderEquity"),
+		}
+		p.ListedShares, _ = strconv.ParseInt(get(rec, "ListedShares"), 10, 64)
+		p.UpdatedAt, _ = time.Parse(time.RFC3339, get(rec, "UpdatedAt"))
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// formatAmount writes whole IQD amounts without a fraction, leaving zero empty
+func formatAmount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,46 @@
+package dataprocessing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompaniesCSV_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reference", "companies.csv")
+	updated := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	profiles := []CompanyProfile{
+		{Symbol: "TASC", Name: "Asia Cell", Sector: "Telecom", ListedShares: 310_000_000_000, UpdatedAt: updated},
+		{Symbol: "BBOB", Name: "Bank of Baghdad, PSC", Sector: "Banks", ListedShares: 250_000_000_000,
+			PaidUpCapital: 250_000_000_000, FinancialYear: "2024", NetIncome: -1_500_000, UpdatedAt: updated},
+	}
+
+	require.NoError(t, WriteCompaniesCSV(path, profiles))
+	assert.NoFileExists(t, path+".tmp")
+
+	loaded, err := ReadCompaniesCSV(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, "BBOB", loaded[0].Symbol, "rows are sorted by symbol")
+	assert.Equal(t, profiles[1], loaded[0])
+	assert.Equal(t, profiles[0], loaded[1])
+}
+
+func TestReadCompaniesCSV_MatchesColumnsByName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "companies.csv")
+	content := "\ufeffsector,symbol,ListedShares\nBanks,bbob,1000\n,,5\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	loaded, err := ReadCompaniesCSV(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1, "rows without a symbol are skipped")
+	assert.Equal(t, CompanyProfile{Symbol: "BBOB", Sector: "Banks", ListedShares: 1000}, loaded[0])
+
+	require.NoError(t, os.WriteFile(path, []byte("name,sector\nA,B\n"), 0644))
+	_, err = ReadCompaniesCSV(path)
+	assert.Error(t, err)
+}
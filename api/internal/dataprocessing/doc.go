@@ -29,6 +29,24 @@
 //	generator := dataprocessing.NewSummaryGenerator(paths)
 //	err := generator.GenerateFromCombinedCSV("combined.csv", "summary.csv")
 //
+// # Report Layouts
+//
+// ISX has changed the daily report layout over time. ParseFile does not parse
+// sheets itself; it asks every registered LayoutParser to score each sheet and
+// hands the best match to that parser. To support a new layout, add a file
+// with a LayoutParser implementation and register it from init():
+//
+//	func init() {
+//	    RegisterParser(myLayoutParser{})
+//	}
+//
+// Built-in layouts are "header-mapped" (columns located by header names) and
+// "legacy-fixed" (older header-less files with fixed column positions).
+//
+// Detection only sees the first DetectSampleRows rows of each sheet. Layouts
+// that also implement StreamingLayoutParser receive the chosen sheet as a
+// RowIterator reading straight from the workbook; both built-in layouts do.
+//
 // # Data Flow
 //
 // The typical data flow through this package:
@@ -47,10 +65,14 @@
 //
 // The package is designed to handle large datasets efficiently:
 //
+//	- Streaming Excel row readers, so ParseFile never loads whole workbooks
 //	- Streaming CSV writers for memory efficiency
 //	- Concurrent processing where applicable
 //	- Minimal allocations in hot paths
 //
+// BenchmarkParseFile_MemoryGuard parses a 1000-file corpus and fails when the
+// heap peaks above ISX_PARSE_MEMORY_CAP_MB (32 MB by default).
+//
 // # Testing
 //
 // The package includes comprehensive tests for all components.
@@ -0,0 +1,400 @@
+package dataprocessing
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/exporter"
+	"isxcli/internal/files"
+	"isxcli/pkg/contracts/domain"
+	"isxcli/pkg/contracts/schema"
+)
+
+// ConflictPolicy decides what happens when an imported row and an existing
+// traded row cover the same symbol and date. Existing forward-filled rows
+// are always replaced by imported ones.
+type ConflictPolicy string
+
+const (
+	// ConflictKeepExisting keeps the existing row; bulletin data wins (the default)
+	ConflictKeepExisting ConflictPolicy = "keep_existing"
+	// ConflictPreferImport replaces the existing row with the imported one
+	ConflictPreferImport ConflictPolicy = "prefer_import"
+	// ConflictFail rejects the whole import if any row conflicts
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// ConflictPolicies lists the supported conflict policies
+var ConflictPolicies = []ConflictPolicy{ConflictKeepExisting, ConflictPreferImport, ConflictFail}
+
+// ErrImportConflict is returned by MergeImported under ConflictFail
+var ErrImportConflict = errors.New("imported rows conflict with existing data")
+
+// ParseConflictPolicy parses a policy name. An empty name is ConflictKeepExisting.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "keep_existing", "keep-existing", "existing":
+		return ConflictKeepExisting, nil
+	case "prefer_import", "prefer-import", "import", "overwrite":
+		return ConflictPreferImport, nil
+	case "fail", "error":
+		return ConflictFail, nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %q (want one of %v)", name, ConflictPolicies)
+}
+
+// MergeStats counts what MergeImported did with the imported rows
+type MergeStats struct {
+	Added         int `json:"added"`          // Symbol/date had no row
+	FillsReplaced int `json:"fills_replaced"` // Replaced a forward-filled row
+	Replaced      int `json:"replaced"`       // Replaced a traded row (ConflictPreferImport)
+	Kept          int `json:"kept"`           // Conflicting rows where the existing row was kept
+	Unchanged     int `json:"unchanged"`      // Identical to the existing row
+}
+
+// MergeImported merges imported records into existing ones keyed by symbol
+// and date. Filled rows are dropped from the result since the fill processor
+// regenerates them; imported rows get their previous close and change from
+// the merged series. The result is sorted by symbol and date.
+func MergeImported(existing, imported []domain.TradeRecord, policy ConflictPolicy) ([]domain.TradeRecord, MergeStats, error) {
+	var stats MergeStats
+	key := func(r domain.TradeRecord) string {
+		return r.CompanySymbol + "|" + r.Date.Format("2006-01-02")
+	}
+
+	byKey := make(map[string]int, len(existing))
+	filled := make(map[string]bool)
+	merged := make([]domain.TradeRecord, 0, len(existing)+len(imported))
+	for _, r := range existing {
+		if isFilledRecord(r) {
+			filled[key(r)] = true
+			continue
+		}
+		byKey[key(r)] = len(merged)
+		merged = append(merged, r)
+	}
+
+	isImported := make(map[string]bool, len(imported))
+	var conflicts []string
+	for _, r := range imported {
+		k := key(r)
+		i, exists := byKey[k]
+		switch {
+		case !exists:
+			if filled[k] {
+				stats.FillsReplaced++
+			} else {
+				stats.Added++
+			}
+			byKey[k] = len(merged)
+			merged = append(merged, r)
+			isImported[k] = true
+		case sameTrade(merged[i], r):
+			stats.Unchanged++
+		case policy == ConflictPreferImport:
+			stats.Replaced++
+			merged[i] = r
+			isImported[k] = true
+		default:
+			stats.Kept++
+			if len(conflicts) < 5 {
+				conflicts = append(conflicts, fmt.Sprintf("%s on %s", r.CompanySymbol, r.Date.Format("2006-01-02")))
+			}
+		}
+	}
+
+	if policy == ConflictFail && stats.Kept > 0 {
+		return nil, stats, fmt.Errorf("%w: %d rows, e.g. %s", ErrImportConflict, stats.Kept, strings.Join(conflicts, ", "))
+	}
+
+	sortBySymbolAndDate(merged)
+	marks := make([]bool, len(merged))
+	for i, r := range merged {
+		marks[i] = isImported[key(r)]
+	}
+	deriveImportedFields(merged, marks)
+	return merged, stats, nil
+}
+
+// sameTrade reports whether two rows carry the same prices and volume
+func sameTrade(a, b domain.TradeRecord) bool {
+	const eps = 1e-6
+	near := func(x, y float64) bool { return x-y < eps && y-x < eps }
+	return near(a.OpenPrice, b.OpenPrice) && near(a.HighPrice, b.HighPrice) && near(a.LowPrice, b.LowPrice) &&
+		near(a.ClosePrice, b.ClosePrice) && a.Volume == b.Volume
+}
+
+// ReadTradeRecordsCSV reads a CSV written with exporter.TradeRecordHeaders,
+// such as the combined CSV or the import store, matching columns by name
+func ReadTradeRecordsCSV(path string) ([]domain.TradeRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	cols := schema.DefaultMapping().Resolve(rows[0])
+	cell := func(row []string, name string) string {
+		return cols.Value(row, name)
+	}
+	num := func(row []string, name string) float64 {
+		v, _, _ := parseImportNumber(cell(row, name), false)
+		return v
+	}
+
+	records := make([]domain.TradeRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		date, err := parseImportDate(cell(row, schema.ColumnDate), nil)
+		if err != nil || cell(row, schema.ColumnSymbol) == "" {
+			continue
+		}
+		tradingStatus, _ := strconv.ParseBool(cell(row, schema.ColumnTradingStatus))
+		records = append(records, domain.TradeRecord{
+			CompanyName:      cell(row, schema.ColumnCompanyName),
+			CompanySymbol:    cell(row, schema.ColumnSymbol),
+			Date:             date,
+			OpenPrice:        num(row, schema.ColumnOpenPrice),
+			HighPrice:        num(row, schema.ColumnHighPrice),
+			LowPrice:         num(row, schema.ColumnLowPrice),
+			AveragePrice:     num(row, schema.ColumnAveragePrice),
+			PrevAveragePrice: num(row, schema.ColumnPrevAveragePrice),
+			ClosePrice:       num(row, schema.ColumnClosePrice),
+			PrevClosePrice:   num(row, schema.ColumnPrevClosePrice),
+			Change:           num(row, schema.ColumnChange),
+			ChangePercent:    num(row, schema.ColumnChangePercent),
+			NumTrades:        int64(num(row, schema.ColumnNumTrades)),
+			Volume:           int64(num(row, schema.ColumnVolume)),
+			Value:            num(row, schema.ColumnValue),
+			TradingStatus:    tradingStatus,
+			FillMethod:       cell(row, schema.ColumnFillMethod),
+		})
+	}
+	return records, nil
+}
+
+// HistoryImportOptions configures a HistoryImporter
+type HistoryImportOptions struct {
+	Mapping      ImportMapping
+	Conflict     ConflictPolicy
+	FillStrategy FillStrategy
+	// ReportsDir defaults to paths.ReportsDir
+	ReportsDir string
+	// DryRun validates and merges without writing anything
+	DryRun bool
+}
+
+// HistoryImportSummary reports the outcome of an import
+type HistoryImportSummary struct {
+	Files   []*ImportResult `json:"files"`
+	Records int             `json:"records"` // Valid rows across all files
+	Skipped int             `json:"skipped"`
+	Tickers []string        `json:"tickers"`
+	From    string          `json:"from,omitempty"`
+	To      string          `json:"to,omitempty"`
+	Merge   MergeStats      `json:"merge"`
+	DryRun  bool            `json:"dry_run"`
+}
+
+// HistoryImporter seeds the combined dataset with history from third-party
+// files. Accepted rows are also kept in the import store
+// (paths.ImportedDataCSV), which the processor merges back in on every run
+// so a full rework does not lose them.
+type HistoryImporter struct {
+	paths  *config.Paths
+	opts   HistoryImportOptions
+	logger *slog.Logger
+}
+
+// NewHistoryImporter creates a history importer
+func NewHistoryImporter(paths *config.Paths, opts HistoryImportOptions, logger *slog.Logger) *HistoryImporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts.ReportsDir == "" {
+		opts.ReportsDir = paths.ReportsDir
+	}
+	// The exporters resolve relative paths against the data directory
+	if abs, err := filepath.Abs(opts.ReportsDir); err == nil {
+		opts.ReportsDir = abs
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictKeepExisting
+	}
+	return &HistoryImporter{paths: paths, opts: opts, logger: logger}
+}
+
+// Import reads the source files, merges them into the combined CSV and
+// regenerates the daily, ticker and summary outputs. A file that cannot be
+// read or mapped fails the import before anything is written.
+func (h *HistoryImporter) Import(ctx context.Context, sources []string) (*HistoryImportSummary, error) {
+	summary := &HistoryImportSummary{DryRun: h.opts.DryRun}
+
+	var imported []domain.TradeRecord
+	for _, source := range sources {
+		result, err := ReadImportFile(source, h.opts.Mapping)
+		if err != nil {
+			return summary, err
+		}
+		h.logger.InfoContext(ctx, "Read import file",
+			slog.String("file", source),
+			slog.Int("rows", result.Rows),
+			slog.Int("records", len(result.Records)),
+			slog.Int("skipped", len(result.Skipped)))
+		summary.Files = append(summary.Files, result)
+		summary.Skipped += len(result.Skipped)
+
+		// Later files win over earlier ones
+		imported, _, _ = MergeImported(imported, result.Records, ConflictPreferImport)
+	}
+	summary.Records = len(imported)
+	summarizeImported(summary, imported)
+	if len(imported) == 0 {
+		return summary, nil
+	}
+
+	combinedPath := filepath.Join(h.opts.ReportsDir, "combined", "isx_combined_data.csv")
+	if !h.opts.DryRun {
+		// Hold the reports directory before reading it so nothing changes underneath
+		lock, err := files.AcquireDirLock(h.opts.ReportsDir, "importer", files.LockOptions{Logger: h.logger})
+		if err != nil {
+			return summary, err
+		}
+		defer lock.Release()
+	}
+
+	existing, err := ReadTradeRecordsCSV(combinedPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return summary, fmt.Errorf("read combined CSV: %w", err)
+	}
+
+	merged, stats, err := MergeImported(existing, imported, h.opts.Conflict)
+	summary.Merge = stats
+	if err != nil || h.opts.DryRun {
+		return summary, err
+	}
+
+	before, err := files.SnapshotFiles(h.opts.ReportsDir, files.DeltaTrackedOutputs...)
+	if err != nil {
+		h.logger.WarnContext(ctx, "Failed to snapshot outputs, no delta manifest will be written", slog.String("error", err.Error()))
+	}
+
+	if err := h.updateStore(imported); err != nil {
+		return summary, err
+	}
+	if err := h.writeOutputs(ctx, merged, combinedPath); err != nil {
+		return summary, err
+	}
+
+	if before != nil {
+		h.writeDelta(before)
+	}
+	return summary, nil
+}
+
+// updateStore merges the imported rows into the import store, replacing rows
+// from earlier imports
+func (h *HistoryImporter) updateStore(imported []domain.TradeRecord) error {
+	stored, err := ReadTradeRecordsCSV(h.paths.ImportedDataCSV)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read import store: %w", err)
+	}
+	stored, _, _ = MergeImported(stored, imported, ConflictPreferImport)
+
+	if err := os.MkdirAll(filepath.Dir(h.paths.ImportedDataCSV), 0755); err != nil {
+		return fmt.Errorf("create imports directory: %w", err)
+	}
+	if err := exporter.NewDailyExporterWithOptions(h.paths, exporter.DefaultExportOptions()).
+		ExportCombinedData(stored, h.paths.ImportedDataCSV); err != nil {
+		return fmt.Errorf("write import store: %w", err)
+	}
+	return nil
+}
+
+// writeOutputs fills gaps and rewrites the combined, daily, ticker and
+// summary outputs, as the processor does
+func (h *HistoryImporter) writeOutputs(ctx context.Context, merged []domain.TradeRecord, combinedPath string) error {
+	filled := NewForwardFillProcessorWithStrategy(h.opts.FillStrategy).FillMissingData(merged)
+
+	for _, dir := range []string{"combined", "daily", "ticker"} {
+		if err := os.MkdirAll(filepath.Join(h.opts.ReportsDir, dir), 0755); err != nil {
+			return fmt.Errorf("create %s directory: %w", dir, err)
+		}
+	}
+
+	// The outputs are pipeline files that later runs read back, so they keep
+	// the canonical formatting
+	daily := exporter.NewDailyExporterWithOptions(h.paths, exporter.DefaultExportOptions())
+	daily.SetValueBands(TradedValueBands(filled, ValueBandWindow, ValueBandMinDays))
+	if err := daily.ExportCombinedData(filled, combinedPath); err != nil {
+		return fmt.Errorf("write combined CSV: %w", err)
+	}
+	if err := daily.ExportDailyReports(filled, filepath.Join(h.opts.ReportsDir, "daily")); err != nil {
+		return fmt.Errorf("write daily CSVs: %w", err)
+	}
+	ticker := exporter.NewTickerExporterWithOptions(h.paths, exporter.DefaultExportOptions())
+	if err := ticker.ExportTickerFiles(filled, filepath.Join(h.opts.ReportsDir, "ticker")); err != nil {
+		return fmt.Errorf("write ticker CSVs: %w", err)
+	}
+
+	integrator := NewIntegrationExample(h.logger)
+	if err := integrator.GenerateTickerSummaryFromCombinedCSV(ctx, combinedPath, h.opts.ReportsDir); err != nil {
+		h.logger.WarnContext(ctx, "Failed to generate ticker summary", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+func (h *HistoryImporter) writeDelta(before files.FileSnapshot) {
+	after, err := files.SnapshotFiles(h.opts.ReportsDir, files.DeltaTrackedOutputs...)
+	if err != nil {
+		h.logger.Warn("Failed to snapshot outputs", slog.String("error", err.Error()))
+		return
+	}
+	delta := files.NewDataDelta(files.ChangedFiles(before, after), nil, false, time.Now())
+	if delta.Empty() {
+		return
+	}
+	if _, err := files.WriteDataDelta(filepath.Join(h.opts.ReportsDir, files.DeltaDirName), delta); err != nil {
+		h.logger.Warn("Failed to write delta manifest", slog.String("error", err.Error()))
+	}
+}
+
+func summarizeImported(summary *HistoryImportSummary, records []domain.TradeRecord) {
+	tickers := make(map[string]bool)
+	var from, to time.Time
+	for _, r := range records {
+		tickers[r.CompanySymbol] = true
+		if from.IsZero() || r.Date.Before(from) {
+			from = r.Date
+		}
+		if r.Date.After(to) {
+			to = r.Date
+		}
+	}
+	for t := range tickers {
+		summary.Tickers = append(summary.Tickers, t)
+	}
+	sort.Strings(summary.Tickers)
+	if !from.IsZero() {
+		summary.From, summary.To = from.Format("2006-01-02"), to.Format("2006-01-02")
+	}
+}
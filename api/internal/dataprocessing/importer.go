@@ -0,0 +1,515 @@
+package dataprocessing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// Fields a column mapping can name
+const (
+	ImportFieldDate    = "date"
+	ImportFieldSymbol  = "symbol"
+	ImportFieldName    = "name"
+	ImportFieldOpen    = "open"
+	ImportFieldHigh    = "high"
+	ImportFieldLow     = "low"
+	ImportFieldClose   = "close"
+	ImportFieldAverage = "average"
+	ImportFieldVolume  = "volume"
+	ImportFieldValue   = "value"
+	ImportFieldTrades  = "trades"
+)
+
+// importFieldAliases are the headers (lower case, spaces and underscores
+// removed) matched for fields the mapping leaves out
+var importFieldAliases = map[string][]string{
+	ImportFieldDate:    {"date", "tradedate", "tradingdate", "day", "timestamp"},
+	ImportFieldSymbol:  {"symbol", "ticker", "code", "companysymbol", "companycode"},
+	ImportFieldName:    {"companyname", "company", "name"},
+	ImportFieldOpen:    {"open", "openprice", "openingprice"},
+	ImportFieldHigh:    {"high", "highprice", "highestprice"},
+	ImportFieldLow:     {"low", "lowprice", "lowestprice"},
+	ImportFieldClose:   {"close", "closeprice", "closingprice", "last", "lastprice"},
+	ImportFieldAverage: {"average", "averageprice", "avgprice", "avg", "vwap"},
+	ImportFieldVolume:  {"volume", "vol", "tradedvolume", "sharestraded"},
+	ImportFieldValue:   {"value", "tradedvalue", "turnover"},
+	ImportFieldTrades:  {"trades", "numtrades", "nooftrades", "numberoftrades", "deals"},
+}
+
+// importFieldOrder fixes the order fields are resolved in, so an ambiguous
+// header goes to the same field every run
+var importFieldOrder = []string{
+	ImportFieldDate, ImportFieldSymbol, ImportFieldName, ImportFieldOpen, ImportFieldHigh, ImportFieldLow,
+	ImportFieldClose, ImportFieldAverage, ImportFieldVolume, ImportFieldValue, ImportFieldTrades,
+}
+
+// DefaultImportDateFormats are tried in order when a mapping sets no date
+// formats. Slashed dates are read day first, as ISX publishes them.
+var DefaultImportDateFormats = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"02/01/2006",
+	"2/1/2006",
+	"02-01-2006",
+	"02.01.2006",
+	"02-Jan-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"20060102",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+}
+
+// ImportMapping describes how a third-party CSV or Excel file maps onto
+// trade records. It is usually loaded from a JSON file next to the data.
+type ImportMapping struct {
+	// Columns maps import fields (date, symbol, close, ...) to source headers.
+	// Fields left out are matched by common header names.
+	Columns map[string]string `json:"columns,omitempty"`
+	// Sheet is the Excel sheet to read; defaults to the first sheet
+	Sheet string `json:"sheet,omitempty"`
+	// DateFormats are Go time layouts tried in order
+	DateFormats []string `json:"date_formats,omitempty"`
+	// Symbol and CompanyName apply to every row of single-ticker files
+	Symbol      string `json:"symbol,omitempty"`
+	CompanyName string `json:"company_name,omitempty"`
+	// Delimiter of CSV files; detected from the header when empty
+	Delimiter string `json:"delimiter,omitempty"`
+	// DecimalComma reads "1.234,5" as 1234.5
+	DecimalComma bool `json:"decimal_comma,omitempty"`
+	// VolumeScale and ValueScale multiply volumes and values, e.g. 1000 when
+	// the source reports thousands
+	VolumeScale float64 `json:"volume_scale,omitempty"`
+	ValueScale  float64 `json:"value_scale,omitempty"`
+}
+
+// LoadImportMapping reads a JSON column mapping
+func LoadImportMapping(path string) (ImportMapping, error) {
+	var mapping ImportMapping
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mapping, fmt.Errorf("read import mapping: %w", err)
+	}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return mapping, fmt.Errorf("parse import mapping %s: %w", path, err)
+	}
+	for field := range mapping.Columns {
+		if _, ok := importFieldAliases[field]; !ok {
+			return mapping, fmt.Errorf("import mapping %s: unknown field %q (want one of %v)", path, field, importFieldOrder)
+		}
+	}
+	return mapping, nil
+}
+
+// ImportIssue is a source row that was skipped
+type ImportIssue struct {
+	Row     int    `json:"row"` // 1-based row in the source file or sheet
+	Message string `json:"message"`
+}
+
+// ImportResult holds the records read from one source file
+type ImportResult struct {
+	Source  string               `json:"source"`
+	Columns map[string]string    `json:"columns"` // Field -> source header actually used
+	Rows    int                  `json:"rows"`    // Data rows read, excluding blank rows
+	Records []domain.TradeRecord `json:"-"`
+	Skipped []ImportIssue        `json:"skipped,omitempty"`
+}
+
+// ReadImportFile reads OHLCV history from a CSV or Excel file. Rows that fail
+// validation are reported in Skipped; an error means the file as a whole
+// could not be read or mapped.
+func ReadImportFile(path string, mapping ImportMapping) (*ImportResult, error) {
+	var rows [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".txt":
+		rows, err = readImportCSV(path, mapping.Delimiter)
+	case ".xlsx", ".xlsm":
+		rows, err = readImportSheet(path, mapping.Sheet)
+	default:
+		return nil, fmt.Errorf("unsupported import file %s: want .csv or .xlsx", filepath.Base(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ImportRows(rows, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	result.Source = path
+	return result, nil
+}
+
+func readImportCSV(path, delimiter string) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read import file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.Comma = detectDelimiter(data, delimiter)
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return rows, nil
+}
+
+// detectDelimiter returns the configured delimiter, or whichever of comma,
+// semicolon and tab appears most in the first line
+func detectDelimiter(data []byte, configured string) rune {
+	if configured == `\t` {
+		return '\t'
+	}
+	if configured != "" {
+		return []rune(configured)[0]
+	}
+	first := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		first = data[:i]
+	}
+	best, bestCount := ',', bytes.Count(first, []byte{','})
+	for _, r := range []rune{';', '\t'} {
+		if n := bytes.Count(first, []byte(string(r))); n > bestCount {
+			best, bestCount = r, n
+		}
+	}
+	return best
+}
+
+func readImportSheet(path, sheet string) ([][]string, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("%s has no sheets", filepath.Base(path))
+		}
+		sheet = sheets[0]
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("read sheet %q: %w", sheet, err)
+	}
+	return rows, nil
+}
+
+// ImportRows maps rows whose first non-blank row is the header onto trade
+// records. Records are returned sorted by symbol and date with derived
+// fields (previous close, change) computed within the import.
+func ImportRows(rows [][]string, mapping ImportMapping) (*ImportResult, error) {
+	headerRow := -1
+	for i, row := range rows {
+		if !blankRow(row) {
+			headerRow = i
+			break
+		}
+	}
+	if headerRow < 0 {
+		return nil, fmt.Errorf("no header row found")
+	}
+
+	columns, used, err := resolveImportColumns(rows[headerRow], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Columns: used}
+	seen := make(map[string]int) // symbol|date -> source row
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		if blankRow(row) {
+			continue
+		}
+		result.Rows++
+
+		record, err := importRecord(row, columns, mapping)
+		if err != nil {
+			result.Skipped = append(result.Skipped, ImportIssue{Row: i + 1, Message: err.Error()})
+			continue
+		}
+
+		key := record.CompanySymbol + "|" + record.Date.Format("2006-01-02")
+		if first, ok := seen[key]; ok {
+			result.Skipped = append(result.Skipped, ImportIssue{Row: i + 1,
+				Message: fmt.Sprintf("duplicate of row %d for %s on %s", first, record.CompanySymbol, record.Date.Format("2006-01-02"))})
+			continue
+		}
+		seen[key] = i + 1
+		result.Records = append(result.Records, record)
+	}
+
+	sortBySymbolAndDate(result.Records)
+	deriveImportedFields(result.Records, nil)
+	return result, nil
+}
+
+// resolveImportColumns returns the column index of each mapped field and the
+// header used for it
+func resolveImportColumns(header []string, mapping ImportMapping) (map[string]int, map[string]string, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalizeImportHeader(h)
+		if _, ok := index[key]; !ok && key != "" {
+			index[key] = i
+		}
+	}
+
+	columns := make(map[string]int)
+	used := make(map[string]string)
+	taken := make(map[int]bool)
+	for _, field := range importFieldOrder {
+		if source, ok := mapping.Columns[field]; ok {
+			i, found := index[normalizeImportHeader(source)]
+			if !found {
+				return nil, nil, fmt.Errorf("mapped column %q for %s not found in header", source, field)
+			}
+			columns[field], used[field], taken[i] = i, strings.TrimSpace(header[i]), true
+		}
+	}
+	for _, field := range importFieldOrder {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		for _, alias := range importFieldAliases[field] {
+			if i, found := index[alias]; found && !taken[i] {
+				columns[field], used[field], taken[i] = i, strings.TrimSpace(header[i]), true
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range []string{ImportFieldDate, ImportFieldSymbol, ImportFieldClose} {
+		if _, ok := columns[field]; !ok && !(field == ImportFieldSymbol && mapping.Symbol != "") {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("no column for %s; map them under \"columns\" in the import mapping", strings.Join(missing, ", "))
+	}
+	return columns, used, nil
+}
+
+func normalizeImportHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	return strings.NewReplacer(" ", "", "_", "", "-", "", ".", "").Replace(h)
+}
+
+// importRecord validates and normalizes one source row
+func importRecord(row []string, columns map[string]int, mapping ImportMapping) (domain.TradeRecord, error) {
+	cell := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	var numErr error
+	number := func(field string) (float64, bool) {
+		v, ok, err := parseImportNumber(cell(field), mapping.DecimalComma)
+		if err != nil && numErr == nil {
+			numErr = fmt.Errorf("%s: %w", field, err)
+		}
+		return v, ok
+	}
+
+	date, err := parseImportDate(cell(ImportFieldDate), mapping.DateFormats)
+	if err != nil {
+		return domain.TradeRecord{}, err
+	}
+
+	symbol := strings.ToUpper(cell(ImportFieldSymbol))
+	if mapping.Symbol != "" {
+		symbol = strings.ToUpper(strings.TrimSpace(mapping.Symbol))
+	}
+	if symbol == "" {
+		return domain.TradeRecord{}, fmt.Errorf("missing symbol")
+	}
+	name := cell(ImportFieldName)
+	if mapping.CompanyName != "" {
+		name = mapping.CompanyName
+	}
+	if name == "" {
+		name = symbol
+	}
+
+	closePrice, hasClose := number(ImportFieldClose)
+	open, hasOpen := number(ImportFieldOpen)
+	high, hasHigh := number(ImportFieldHigh)
+	low, hasLow := number(ImportFieldLow)
+	average, hasAverage := number(ImportFieldAverage)
+	volume, hasVolume := number(ImportFieldVolume)
+	value, hasValue := number(ImportFieldValue)
+	trades, _ := number(ImportFieldTrades)
+	if numErr != nil {
+		return domain.TradeRecord{}, numErr
+	}
+
+	if !hasClose || closePrice <= 0 {
+		return domain.TradeRecord{}, fmt.Errorf("close price must be positive")
+	}
+	for field, v := range map[string]float64{ImportFieldOpen: open, ImportFieldHigh: high, ImportFieldLow: low,
+		ImportFieldAverage: average, ImportFieldVolume: volume, ImportFieldValue: value, ImportFieldTrades: trades} {
+		if v < 0 {
+			return domain.TradeRecord{}, fmt.Errorf("%s is negative", field)
+		}
+	}
+	if hasVolume && volume == 0 {
+		return domain.TradeRecord{}, fmt.Errorf("no volume traded on %s", date.Format("2006-01-02"))
+	}
+
+	// Missing prices default to the close, so single-price histories import
+	if !hasOpen || open == 0 {
+		open = closePrice
+	}
+	if !hasHigh || high == 0 {
+		high = max(open, closePrice)
+	}
+	if !hasLow || low == 0 {
+		low = min(open, closePrice)
+	}
+	if !hasAverage || average == 0 {
+		average = closePrice
+	}
+	if high < low || high < max(open, closePrice) || low > min(open, closePrice) {
+		return domain.TradeRecord{}, fmt.Errorf("high %g and low %g do not bracket open %g and close %g", high, low, open, closePrice)
+	}
+
+	if mapping.VolumeScale > 0 {
+		volume *= mapping.VolumeScale
+	}
+	if mapping.ValueScale > 0 {
+		value *= mapping.ValueScale
+	}
+	if !hasValue && hasVolume {
+		value = volume * average
+	}
+
+	return domain.TradeRecord{
+		CompanyName:   name,
+		CompanySymbol: symbol,
+		Date:          date,
+		OpenPrice:     open,
+		HighPrice:     high,
+		LowPrice:      low,
+		AveragePrice:  average,
+		ClosePrice:    closePrice,
+		NumTrades:     int64(trades),
+		Volume:        int64(volume),
+		Value:         value,
+		TradingStatus: true,
+		FillMethod:    domain.FillMethodActual,
+	}, nil
+}
+
+// parseImportDate parses a date with the given layouts, falling back to the
+// defaults, and accepts Excel serial dates from unformatted cells
+func parseImportDate(s string, layouts []string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	if len(layouts) == 0 {
+		layouts = DefaultImportDateFormats
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	// Excel serials between 1954 and 2119
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial > 20000 && serial < 80000 {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", s)
+}
+
+// parseImportNumber parses a number with thousands separators. Empty cells
+// and "-" are reported as absent rather than zero.
+func parseImportNumber(s string, decimalComma bool) (float64, bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" || strings.EqualFold(s, "n/a") {
+		return 0, false, nil
+	}
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "'", "").Replace(s)
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid number %q", s)
+	}
+	return v, true, nil
+}
+
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func sortBySymbolAndDate(records []domain.TradeRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].CompanySymbol != records[j].CompanySymbol {
+			return records[i].CompanySymbol < records[j].CompanySymbol
+		}
+		return records[i].Date.Before(records[j].Date)
+	})
+}
+
+// deriveImportedFields sets the previous prices and change of records sorted
+// by symbol and date from the preceding traded record of the same symbol.
+// When imported is non-nil only records it marks are updated, so bulletin
+// records keep the values the exchange published.
+func deriveImportedFields(records []domain.TradeRecord, imported []bool) {
+	var prev *domain.TradeRecord
+	for i := range records {
+		r := &records[i]
+		if prev != nil && prev.CompanySymbol != r.CompanySymbol {
+			prev = nil
+		}
+		if imported == nil || imported[i] {
+			r.PrevClosePrice, r.PrevAveragePrice, r.Change, r.ChangePercent = 0, 0, 0, 0
+			if prev != nil {
+				r.PrevClosePrice = prev.ClosePrice
+				r.PrevAveragePrice = prev.AveragePrice
+				r.Change = r.ClosePrice - prev.ClosePrice
+				if prev.ClosePrice > 0 {
+					r.ChangePercent = r.Change / prev.ClosePrice * 100
+				}
+			}
+		}
+		if !isFilledRecord(*r) {
+			prev = r
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package dataprocessing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/exporter"
+	"isxcli/pkg/contracts/domain"
+)
+
+func importDay(d int) time.Time { return time.Date(2019, 3, d, 0, 0, 0, 0, time.UTC) }
+
+func TestImportRows_Mapping(t *testing.T) {
+	rows := [][]string{
+		{},
+		{"Trade Date", "Ticker", "Open", "High", "Low", "Last", "Shares", "Deals"},
+		{"03/03/2019", "bbob", "1.00", "1.10", "0.95", "1.05", "1,000", "4"},
+		{"04/03/2019", "BBOB", "1.05", "1.20", "1.00", "1.10", "2,000", "6"},
+		{"05/03/2019", "BBOB", "1.10", "1.00", "1.20", "1.15", "500", "1"},
+		{"06/03/2019", "BBOB", "", "", "", "1.12", "0", "0"},
+		{"06/03/2019", "TASC", "", "", "", "bad", "10", "1"},
+		{"", "", "", "", "", "", "", ""},
+		{"04/03/2019", "BBOB", "1.05", "1.20", "1.00", "1.10", "2000", "6"},
+	}
+
+	result, err := ImportRows(rows, ImportMapping{
+		Columns:     map[string]string{ImportFieldVolume: "Shares"},
+		VolumeScale: 1,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Last", result.Columns[ImportFieldClose])
+	assert.Equal(t, "Deals", result.Columns[ImportFieldTrades])
+	assert.Equal(t, 6, result.Rows)
+	require.Len(t, result.Records, 2)
+	require.Len(t, result.Skipped, 4)
+	assert.Equal(t, 5, result.Skipped[0].Row)
+	assert.Contains(t, result.Skipped[0].Message, "do not bracket")
+	assert.Contains(t, result.Skipped[1].Message, "no volume traded")
+	assert.Contains(t, result.Skipped[2].Message, "invalid number")
+	assert.Contains(t, result.Skipped[3].Message, "duplicate of row 4")
+
+	first, second := result.Records[0], result.Records[1]
+	assert.Equal(t, "BBOB", first.CompanySymbol)
+	assert.Equal(t, "BBOB", first.CompanyName, "the symbol names tickers without a name column")
+	assert.Equal(t, importDay(3), first.Date, "slashed dates are day first")
+	assert.Equal(t, int64(1000), first.Volume)
+	assert.InDelta(t, 1050.0, first.Value, 1e-9, "value defaults to volume times average")
+	assert.Equal(t, domain.FillMethodActual, first.FillMethod)
+	assert.True(t, first.TradingStatus)
+
+	assert.Equal(t, 1.05, second.PrevClosePrice)
+	assert.InDelta(t, 0.05, second.Change, 1e-9)
+	assert.InDelta(t, 4.7619, second.ChangePercent, 1e-4)
+}
+
+func TestImportRows_MissingColumns(t *testing.T) {
+	_, err := ImportRows([][]string{{"When", "Price"}, {"2019-03-03", "1"}}, ImportMapping{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "date, symbol, close")
+
+	_, err = ImportRows([][]string{{"Date", "Close"}}, ImportMapping{Columns: map[string]string{ImportFieldClose: "Adj Close"}})
+	assert.ErrorContains(t, err, `"Adj Close"`)
+
+	// Single-ticker files name the symbol in the mapping
+	result, err := ImportRows([][]string{{"Date", "Close"}, {"2019-03-03", "8.1"}}, ImportMapping{Symbol: "tasc", CompanyName: "Asiacell"})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	assert.Equal(t, "TASC", result.Records[0].CompanySymbol)
+	assert.Equal(t, "Asiacell", result.Records[0].CompanyName)
+}
+
+func TestParseImportNumberAndDate(t *testing.T) {
+	v, ok, err := parseImportNumber("1.234,5", true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1234.5, v)
+
+	_, ok, err = parseImportNumber("-", false)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	d, err := parseImportDate("43527", nil)
+	require.NoError(t, err)
+	assert.Equal(t, importDay(3), d, "Excel serial date")
+
+	d, err = parseImportDate("03-2019-03", []string{"02-2006-01"})
+	require.NoError(t, err)
+	assert.Equal(t, importDay(3), d)
+}
+
+func TestMergeImported_Policies(t *testing.T) {
+	traded := func(symbol string, d int, close float64) domain.TradeRecord {
+		return domain.TradeRecord{CompanyName: symbol, CompanySymbol: symbol, Date: importDay(d), OpenPrice: close, HighPrice: close,
+			LowPrice: close, AveragePrice: close, ClosePrice: close, Volume: 100, TradingStatus: true, FillMethod: domain.FillMethodActual}
+	}
+	filled := traded("BBOB", 4, 1.0)
+	filled.TradingStatus, filled.Volume, filled.FillMethod = false, 0, domain.FillMethodCarryForward
+
+	existing := []domain.TradeRecord{traded("BBOB", 3, 1.0), filled, traded("BBOB", 5, 1.2)}
+	imported := []domain.TradeRecord{traded("BBOB", 2, 0.9), traded("BBOB", 3, 1.0), traded("BBOB", 4, 1.1), traded("BBOB", 5, 1.3)}
+
+	merged, stats, err := MergeImported(existing, imported, ConflictKeepExisting)
+	require.NoError(t, err)
+	assert.Equal(t, MergeStats{Added: 1, FillsReplaced: 1, Kept: 1, Unchanged: 1}, stats)
+	require.Len(t, merged, 4)
+	assert.Equal(t, 1.2, merged[3].ClosePrice, "the bulletin row wins")
+	assert.Equal(t, 1.0, merged[2].PrevClosePrice, "imported rows chain onto existing ones")
+	assert.InDelta(t, 0.1, merged[2].Change, 1e-9)
+	assert.Zero(t, merged[3].PrevClosePrice, "bulletin rows keep their published fields")
+
+	merged, stats, err = MergeImported(existing, imported, ConflictPreferImport)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Replaced)
+	assert.Equal(t, 1.3, merged[3].ClosePrice)
+	assert.Equal(t, 1.1, merged[3].PrevClosePrice)
+
+	_, _, err = MergeImported(existing, imported, ConflictFail)
+	assert.ErrorIs(t, err, ErrImportConflict)
+	assert.ErrorContains(t, err, "BBOB on 2019-03-05")
+}
+
+func TestHistoryImporter_Import(t *testing.T) {
+	root := t.TempDir()
+	paths := &config.Paths{
+		ReportsDir:      filepath.Join(root, "reports"),
+		ImportedDataCSV: filepath.Join(root, "imports", "isx_imported_data.csv"),
+	}
+	combined := filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")
+
+	// One scraped day already processed
+	scraped := domain.TradeRecord{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: importDay(5), OpenPrice: 1.2,
+		HighPrice: 1.2, LowPrice: 1.2, AveragePrice: 1.2, ClosePrice: 1.2, Volume: 100, TradingStatus: true}
+	require.NoError(t, os.MkdirAll(filepath.Dir(combined), 0755))
+	require.NoError(t, exporter.NewDailyExporterWithOptions(paths, exporter.DefaultExportOptions()).ExportCombinedData([]domain.TradeRecord{scraped}, combined))
+
+	source := filepath.Join(root, "vendor.csv")
+	require.NoError(t, os.WriteFile(source, []byte("Date;Symbol;Close;Volume\n2019-03-03;BBOB;1,00;500\n2019-03-04;TASC;8,10;50\n2019-03-05;BBOB;1,30;900\n"), 0644))
+	mapping := ImportMapping{DecimalComma: true}
+
+	importer := NewHistoryImporter(paths, HistoryImportOptions{Mapping: mapping, DryRun: true}, nil)
+	summary, err := importer.Import(context.Background(), []string{source})
+	require.NoError(t, err)
+	assert.Equal(t, MergeStats{Added: 2, Kept: 1}, summary.Merge)
+	assert.NoFileExists(t, paths.ImportedDataCSV, "a dry run writes nothing")
+
+	importer = NewHistoryImporter(paths, HistoryImportOptions{Mapping: mapping}, nil)
+	summary, err = importer.Import(context.Background(), []string{source})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BBOB", "TASC"}, summary.Tickers)
+	assert.Equal(t, "2019-03-03", summary.From)
+
+	all, err := ReadTradeRecordsCSV(combined)
+	require.NoError(t, err)
+	records := bySymbol(all, "BBOB")
+	require.Len(t, records, 3, "the gap on the 4th is filled")
+	assert.Equal(t, 1.0, records[0].ClosePrice)
+	assert.Equal(t, domain.FillMethodCarryForward, records[1].FillMethod)
+	assert.Equal(t, 1.2, records[2].ClosePrice, "the scraped day is kept")
+	assert.FileExists(t, filepath.Join(paths.ReportsDir, "daily", "isx_daily_2019_03_03.csv"))
+	assert.FileExists(t, filepath.Join(paths.ReportsDir, "ticker", "BBOB_trading_history.csv"))
+
+	stored, err := ReadTradeRecordsCSV(paths.ImportedDataCSV)
+	require.NoError(t, err)
+	assert.Len(t, stored, 3, "the store keeps every imported row for later processor runs")
+}
@@ -0,0 +1,322 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Index names as they appear in indexes.csv
+const (
+	IndexISX60 = "ISX60"
+	IndexISX15 = "ISX15"
+)
+
+// Alert reasons for flagged index returns
+const (
+	AlertDivisorChange   = "DIVISOR_CHANGE"   // Index move inconsistent with constituent returns
+	AlertUnexplainedJump = "UNEXPLAINED_JUMP" // Large move with no constituent data to compare against
+)
+
+// indexAnalyticsHeaders is the column layout of index_analytics.csv
+var indexAnalyticsHeaders = []string{
+	"Date", "Index", "Level", "Return", "ConstituentReturn", "AdjustedReturn", "Alert",
+}
+
+// IndexPoint is one row of indexes.csv. A zero ISX15 means the index was not published.
+type IndexPoint struct {
+	Date  time.Time
+	ISX60 float64
+	ISX15 float64
+}
+
+// DivisorCheckConfig controls when an index move is treated as a rebalancing
+// or divisor change rather than a market move. All values are in percent.
+type DivisorCheckConfig struct {
+	MinJump      float64 // Ignore index returns smaller than this
+	MaxDeviation float64 // Allowed gap between index and constituent returns
+	FallbackJump float64 // Threshold used when no constituent returns exist for the date
+}
+
+// DefaultDivisorCheckConfig returns thresholds tuned for the ISX's ±10% daily limit
+func DefaultDivisorCheckConfig() DivisorCheckConfig {
+	return DivisorCheckConfig{
+		MinJump:      2.0,
+		MaxDeviation: 3.0,
+		FallbackJump: 8.0,
+	}
+}
+
+// IndexReturnRow is one day of index analytics. AdjustedReturn replaces the raw
+// return with the constituent return on flagged dates so downstream return
+// series are not polluted by rebalancing artefacts.
+type IndexReturnRow struct {
+	Date              time.Time `json:"date"`
+	Index             string    `json:"index"`
+	Level             float64   `json:"level"`
+	Return            float64   `json:"return"`
+	ConstituentReturn float64   `json:"constituent_return"`
+	HasConstituents   bool      `json:"has_constituents"`
+	AdjustedReturn    float64   `json:"adjusted_return"`
+	Alert             string    `json:"alert,omitempty"`
+}
+
+// ReadIndexCSV loads the Date,ISX60,ISX15 series written by indexcsv
+func ReadIndexCSV(path string) ([]IndexPoint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	var points []IndexPoint
+	for i, rec := range records {
+		if i == 0 || len(rec) < 2 {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(rec[0]))
+		if err != nil {
+			continue
+		}
+		point := IndexPoint{Date: date}
+		point.ISX60, _ = strconv.ParseFloat(strings.TrimSpace(rec[1]), 64)
+		if len(rec) > 2 {
+			point.ISX15, _ = strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		}
+		points = append(points, point)
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	return points, nil
+}
+
+// ConstituentReturnsFromCSV computes the value-weighted average close-to-close
+// return of traded stocks per date (YYYY-MM-DD) from the combined data CSV.
+// The ISX does not publish index membership, so all traded stocks stand in for
+// the constituents.
+func ConstituentReturnsFromCSV(path string) (map[string]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open combined CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read combined CSV header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))] = i
+	}
+	for _, required := range []string{"Date", "ClosePrice", "PrevClosePrice", "Value"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("combined CSV missing column: %s", required)
+		}
+	}
+
+	type accumulator struct{ weighted, weight float64 }
+	byDate := make(map[string]*accumulator)
+
+	field := func(rec []string, name string) string {
+		if idx, ok := cols[name]; ok && idx < len(rec) {
+			return strings.TrimSpace(rec[idx])
+		}
+		return ""
+	}
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read combined CSV: %w", err)
+		}
+
+		// Forward-filled rows carry no price information for the day
+		if status := field(rec, "TradingStatus"); status != "" && status != "true" {
+			continue
+		}
+
+		closePrice, _ := strconv.ParseFloat(field(rec, "ClosePrice"), 64)
+		prevClose, _ := strconv.ParseFloat(field(rec, "PrevClosePrice"), 64)
+		value, _ := strconv.ParseFloat(field(rec, "Value"), 64)
+		if closePrice <= 0 || prevClose <= 0 || value <= 0 {
+			continue
+		}
+
+		date := field(rec, "Date")
+		acc, ok := byDate[date]
+		if !ok {
+			acc = &accumulator{}
+			byDate[date] = acc
+		}
+		acc.weighted += (closePrice/prevClose - 1) * 100 * value
+		acc.weight += value
+	}
+
+	returns := make(map[string]float64, len(byDate))
+	for date, acc := range byDate {
+		if acc.weight > 0 {
+			returns[date] = acc.weighted / acc.weight
+		}
+	}
+	return returns, nil
+}
+
+// AnalyzeIndexReturns computes daily returns for ISX60 and ISX15 and flags dates
+// where the index level changes in a way the constituent returns cannot explain
+func AnalyzeIndexReturns(points []IndexPoint, constituentReturns map[string]float64, cfg DivisorCheckConfig) []IndexReturnRow {
+	var rows []IndexReturnRow
+	for _, index := range []string{IndexISX60, IndexISX15} {
+		rows = append(rows, analyzeSeries(index, points, constituentReturns, cfg)...)
+	}
+	return rows
+}
+
+// IndexAlerts returns only the flagged rows
+func IndexAlerts(rows []IndexReturnRow) []IndexReturnRow {
+	var alerts []IndexReturnRow
+	for _, row := range rows {
+		if row.Alert != "" {
+			alerts = append(alerts, row)
+		}
+	}
+	return alerts
+}
+
+// analyzeSeries evaluates a single index. Missing levels (zero) break the
+// series so the first published value is never compared against nothing.
+func analyzeSeries(index string, points []IndexPoint, constituentReturns map[string]float64, cfg DivisorCheckConfig) []IndexReturnRow {
+	var rows []IndexReturnRow
+	prevLevel := 0.0
+	for _, p := range points {
+		level := p.ISX60
+		if index == IndexISX15 {
+			level = p.ISX15
+		}
+		if level <= 0 {
+			prevLevel = 0
+			continue
+		}
+
+		row := IndexReturnRow{Date: p.Date, Index: index, Level: level}
+		if prevLevel > 0 {
+			row.Return = (level/prevLevel - 1) * 100
+		}
+		row.AdjustedReturn = row.Return
+		row.ConstituentReturn, row.HasConstituents = constituentReturns[p.Date.Format("2006-01-02")]
+
+		if prevLevel > 0 && math.Abs(row.Return) >= cfg.MinJump {
+			switch {
+			case row.HasConstituents && math.Abs(row.Return-row.ConstituentReturn) > cfg.MaxDeviation:
+				row.Alert = AlertDivisorChange
+				row.AdjustedReturn = row.ConstituentReturn
+			case !row.HasConstituents && math.Abs(row.Return) >= cfg.FallbackJump:
+				row.Alert = AlertUnexplainedJump
+			}
+		}
+
+		rows = append(rows, row)
+		prevLevel = level
+	}
+	return rows
+}
+
+// WriteIndexAnalyticsCSV writes index returns and alerts to path
+func WriteIndexAnalyticsCSV(path string, rows []IndexReturnRow) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index analytics directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create index analytics file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(indexAnalyticsHeaders); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, row := range rows {
+		constituent := ""
+		if row.HasConstituents {
+			constituent = strconv.FormatFloat(row.ConstituentReturn, 'f', 4, 64)
+		}
+		rec := []string{
+			row.Date.Format("2006-01-02"),
+			row.Index,
+			strconv.FormatFloat(row.Level, 'f', 2, 64),
+			strconv.FormatFloat(row.Return, 'f', 4, 64),
+			constituent,
+			strconv.FormatFloat(row.AdjustedReturn, 'f', 4, 64),
+			row.Alert,
+		}
+		if err := writer.Write(rec); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadIndexAnalyticsCSV loads rows written by WriteIndexAnalyticsCSV
+func ReadIndexAnalyticsCSV(path string) ([]IndexReturnRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index analytics file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index analytics file: %w", err)
+	}
+
+	var rows []IndexReturnRow
+	for i, rec := range records {
+		if i == 0 {
+			continue
+		}
+		if len(rec) < len(indexAnalyticsHeaders) {
+			return nil, fmt.Errorf("row %d: expected %d columns, got %d", i+1, len(indexAnalyticsHeaders), len(rec))
+		}
+		date, err := time.Parse("2006-01-02", rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date: %w", i+1, err)
+		}
+
+		row := IndexReturnRow{Date: date, Index: rec[1], Alert: rec[6]}
+		row.Level, _ = strconv.ParseFloat(rec[2], 64)
+		row.Return, _ = strconv.ParseFloat(rec[3], 64)
+		if rec[4] != "" {
+			row.ConstituentReturn, _ = strconv.ParseFloat(rec[4], 64)
+			row.HasConstituents = true
+		}
+		row.AdjustedReturn, _ = strconv.ParseFloat(rec[5], 64)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
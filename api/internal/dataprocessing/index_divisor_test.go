@@ -0,0 +1,93 @@
+package dataprocessing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeIndexReturns(t *testing.T) {
+	d := func(day int) time.Time { return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC) }
+	points := []IndexPoint{
+		{Date: d(5), ISX60: 1000, ISX15: 0},
+		{Date: d(6), ISX60: 1010, ISX15: 500},    // +1%: below MinJump
+		{Date: d(7), ISX60: 1111, ISX15: 505},    // +10% while constituents +0.5%: divisor change
+		{Date: d(8), ISX60: 1144.33, ISX15: 0},   // +3% matching constituents; ISX15 not published
+		{Date: d(9), ISX60: 1258.76, ISX15: 510}, // +10% without constituent data: unexplained
+	}
+	constituents := map[string]float64{
+		"2025-01-06": 1.0,
+		"2025-01-07": 0.5,
+		"2025-01-08": 3.0,
+	}
+
+	rows := AnalyzeIndexReturns(points, constituents, DefaultDivisorCheckConfig())
+
+	var isx60, isx15 []IndexReturnRow
+	for _, row := range rows {
+		if row.Index == IndexISX60 {
+			isx60 = append(isx60, row)
+		} else {
+			isx15 = append(isx15, row)
+		}
+	}
+	require.Len(t, isx60, 5)
+	require.Len(t, isx15, 3)
+
+	assert.Empty(t, isx60[0].Alert, "first point has no prior level")
+	assert.Empty(t, isx60[1].Alert)
+
+	assert.Equal(t, AlertDivisorChange, isx60[2].Alert)
+	assert.InDelta(t, 10.0, isx60[2].Return, 0.01)
+	assert.InDelta(t, 0.5, isx60[2].AdjustedReturn, 0.0001, "flagged return is replaced by constituent return")
+
+	assert.Empty(t, isx60[3].Alert)
+	assert.Equal(t, AlertUnexplainedJump, isx60[4].Alert)
+	assert.InDelta(t, isx60[4].Return, isx60[4].AdjustedReturn, 0.0001, "unexplained jumps are flagged but not adjusted")
+
+	// ISX15 series restarts after the unpublished day
+	assert.Zero(t, isx15[2].Return)
+
+	alerts := IndexAlerts(rows)
+	assert.Len(t, alerts, 2)
+}
+
+func TestConstituentReturnsFromCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "isx_combined_data.csv")
+	content := "Date,Symbol,ClosePrice,PrevClosePrice,Value,TradingStatus\n" +
+		"2025-01-07,BBOB,1.10,1.00,3000,true\n" +
+		"2025-01-07,TASC,0.95,1.00,1000,true\n" +
+		"2025-01-07,IBSD,5.00,1.00,0,false\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	returns, err := ConstituentReturnsFromCSV(path)
+	require.NoError(t, err)
+	// (10% * 3000 + -5% * 1000) / 4000
+	assert.InDelta(t, 6.25, returns["2025-01-07"], 0.0001)
+}
+
+func TestIndexAnalyticsCSVRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "indexes.csv")
+	require.NoError(t, os.WriteFile(indexPath, []byte("Date,ISX60,ISX15\n2025-01-06,1010.00,\n2025-01-05,1000.00,\n"), 0644))
+
+	points, err := ReadIndexCSV(indexPath)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.True(t, points[0].Date.Before(points[1].Date), "points are sorted by date")
+
+	rows := AnalyzeIndexReturns(points, map[string]float64{"2025-01-06": 1.0}, DefaultDivisorCheckConfig())
+	outPath := filepath.Join(dir, "index_analytics.csv")
+	require.NoError(t, WriteIndexAnalyticsCSV(outPath, rows))
+
+	got, err := ReadIndexAnalyticsCSV(outPath)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.False(t, got[0].HasConstituents)
+	assert.True(t, got[1].HasConstituents)
+	assert.InDelta(t, 1.0, got[1].Return, 0.0001)
+}
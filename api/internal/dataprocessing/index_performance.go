@@ -0,0 +1,221 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Windows of the index performance metrics, in published index levels
+const (
+	IndexVolatilityWindow = 20
+	IndexShortMAWindow    = 20
+	IndexLongMAWindow     = 50
+
+	// tradingDaysPerYear annualizes daily volatility
+	tradingDaysPerYear = 252
+)
+
+// indexPerformanceHeaders is the column layout of indexes_analytics.csv
+var indexPerformanceHeaders = []string{
+	"Date", "Index", "Level", "Return", "CumulativeReturn", "Volatility", "Drawdown", "MaxDrawdown", "MA20", "MA50",
+}
+
+// IndexPerformanceRow is one day of index performance metrics. Returns and
+// drawdowns are in percent. Volatility and the moving averages are nil until
+// the series has enough published levels to fill their window.
+type IndexPerformanceRow struct {
+	Date             time.Time `json:"date"`
+	Index            string    `json:"index"`
+	Level            float64   `json:"level"`
+	Return           float64   `json:"return"`
+	CumulativeReturn float64   `json:"cumulative_return"`
+	Volatility       *float64  `json:"volatility"` // Annualized standard deviation of the last IndexVolatilityWindow returns
+	Drawdown         float64   `json:"drawdown"`   // Distance below the running peak, zero or negative
+	MaxDrawdown      float64   `json:"max_drawdown"`
+	MA20             *float64  `json:"ma20"`
+	MA50             *float64  `json:"ma50"`
+}
+
+// ComputeIndexPerformance derives daily performance metrics from the rows of
+// AnalyzeIndexReturns. Returns, volatility and drawdowns compound the adjusted
+// returns, so suspected divisor changes do not show up as crashes or rallies;
+// moving averages follow the published levels. Rows keep the input order.
+func ComputeIndexPerformance(rows []IndexReturnRow) []IndexPerformanceRow {
+	var out []IndexPerformanceRow
+	for _, index := range []string{IndexISX60, IndexISX15} {
+		var series []IndexReturnRow
+		for _, row := range rows {
+			if row.Index == index {
+				series = append(series, row)
+			}
+		}
+		out = append(out, performanceSeries(series)...)
+	}
+	return out
+}
+
+// performanceSeries computes the metrics of one index. The first row has no
+// return; its level is the base of the cumulative return.
+func performanceSeries(series []IndexReturnRow) []IndexPerformanceRow {
+	out := make([]IndexPerformanceRow, 0, len(series))
+	growth, peak, maxDrawdown := 1.0, 1.0, 0.0
+	for i, row := range series {
+		perf := IndexPerformanceRow{Date: row.Date, Index: row.Index, Level: row.Level}
+		if i > 0 {
+			perf.Return = row.AdjustedReturn
+			growth *= 1 + row.AdjustedReturn/100
+		}
+		peak = math.Max(peak, growth)
+		perf.CumulativeReturn = (growth - 1) * 100
+		perf.Drawdown = (growth/peak - 1) * 100
+		maxDrawdown = math.Min(maxDrawdown, perf.Drawdown)
+		perf.MaxDrawdown = maxDrawdown
+
+		if i >= IndexVolatilityWindow {
+			returns := make([]float64, 0, IndexVolatilityWindow)
+			for _, r := range series[i-IndexVolatilityWindow+1 : i+1] {
+				returns = append(returns, r.AdjustedReturn)
+			}
+			vol := sampleStdDev(returns) * math.Sqrt(tradingDaysPerYear)
+			perf.Volatility = &vol
+		}
+		perf.MA20 = movingAverage(series, i, IndexShortMAWindow)
+		perf.MA50 = movingAverage(series, i, IndexLongMAWindow)
+
+		out = append(out, perf)
+	}
+	return out
+}
+
+// movingAverage returns the mean level of the window ending at i, or nil when
+// fewer levels are available
+func movingAverage(series []IndexReturnRow, i, window int) *float64 {
+	if i+1 < window {
+		return nil
+	}
+	sum := 0.0
+	for _, row := range series[i-window+1 : i+1] {
+		sum += row.Level
+	}
+	avg := sum / float64(window)
+	return &avg
+}
+
+func sampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)-1))
+}
+
+// WriteIndexPerformanceCSV writes index performance metrics to path. Metrics
+// without a full window are left empty.
+func WriteIndexPerformanceCSV(path string, rows []IndexPerformanceRow) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index performance directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create index performance file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(indexPerformanceHeaders); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	optional := func(v *float64, prec int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', prec, 64)
+	}
+	for _, row := range rows {
+		rec := []string{
+			row.Date.Format("2006-01-02"),
+			row.Index,
+			strconv.FormatFloat(row.Level, 'f', 2, 64),
+			strconv.FormatFloat(row.Return, 'f', 4, 64),
+			strconv.FormatFloat(row.CumulativeReturn, 'f', 4, 64),
+			optional(row.Volatility, 4),
+			strconv.FormatFloat(row.Drawdown, 'f', 4, 64),
+			strconv.FormatFloat(row.MaxDrawdown, 'f', 4, 64),
+			optional(row.MA20, 2),
+			optional(row.MA50, 2),
+		}
+		if err := writer.Write(rec); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadIndexPerformanceCSV loads rows written by WriteIndexPerformanceCSV
+func ReadIndexPerformanceCSV(path string) ([]IndexPerformanceRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index performance file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index performance file: %w", err)
+	}
+
+	optional := func(s string) *float64 {
+		if s == "" {
+			return nil
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil
+		}
+		return &v
+	}
+
+	var rows []IndexPerformanceRow
+	for i, rec := range records {
+		if i == 0 {
+			continue
+		}
+		if len(rec) < len(indexPerformanceHeaders) {
+			return nil, fmt.Errorf("row %d: expected %d columns, got %d", i+1, len(indexPerformanceHeaders), len(rec))
+		}
+		date, err := time.Parse("2006-01-02", rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date: %w", i+1, err)
+		}
+
+		row := IndexPerformanceRow{Date: date, Index: rec[1]}
+		row.Level, _ = strconv.ParseFloat(rec[2], 64)
+		row.Return, _ = strconv.ParseFloat(rec[3], 64)
+		row.CumulativeReturn, _ = strconv.ParseFloat(rec[4], 64)
+		row.Volatility = optional(rec[5])
+		row.Drawdown, _ = strconv.ParseFloat(rec[6], 64)
+		row.MaxDrawdown, _ = strconv.ParseFloat(rec[7], 64)
+		row.MA20 = optional(rec[8])
+		row.MA50 = optional(rec[9])
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,97 @@
+package dataprocessing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeIndexPerformance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	levels := []float64{100, 110, 99, 104.94}
+	var rows []IndexReturnRow
+	for i, level := range levels {
+		row := IndexReturnRow{Date: start.AddDate(0, 0, i), Index: IndexISX60, Level: level}
+		if i > 0 {
+			row.Return = (level/levels[i-1] - 1) * 100
+			row.AdjustedReturn = row.Return
+		}
+		rows = append(rows, row)
+	}
+	// A divisor change on the ISX15: the level doubles but the adjusted return is flat
+	rows = append(rows,
+		IndexReturnRow{Date: start, Index: IndexISX15, Level: 50},
+		IndexReturnRow{Date: start.AddDate(0, 0, 1), Index: IndexISX15, Level: 100, Return: 100, Alert: AlertDivisorChange},
+	)
+
+	perf := ComputeIndexPerformance(rows)
+	require.Len(t, perf, 6)
+
+	assert.Equal(t, 0.0, perf[0].Return)
+	assert.InDelta(t, 10.0, perf[1].CumulativeReturn, 0.0001)
+	assert.InDelta(t, -10.0, perf[2].Drawdown, 0.0001)
+	assert.InDelta(t, -4.6, perf[3].Drawdown, 0.0001)
+	assert.InDelta(t, -10.0, perf[3].MaxDrawdown, 0.0001, "max drawdown keeps the worst point")
+	assert.Nil(t, perf[3].Volatility)
+	assert.Nil(t, perf[3].MA20)
+
+	assert.Equal(t, IndexISX15, perf[5].Index)
+	assert.Equal(t, 0.0, perf[5].CumulativeReturn, "adjusted returns ignore the divisor change")
+	assert.Equal(t, 100.0, perf[5].Level)
+}
+
+func TestComputeIndexPerformance_Windows(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []IndexReturnRow
+	for i := 0; i < IndexLongMAWindow; i++ {
+		row := IndexReturnRow{Date: start.AddDate(0, 0, i), Index: IndexISX60, Level: float64(100 + i%2)}
+		if i > 0 {
+			row.AdjustedReturn = 1
+			if i%2 == 0 {
+				row.AdjustedReturn = -1
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	perf := ComputeIndexPerformance(rows)
+	require.Len(t, perf, IndexLongMAWindow)
+
+	assert.Nil(t, perf[IndexVolatilityWindow-1].Volatility, "the first row has no return")
+	require.NotNil(t, perf[IndexVolatilityWindow].Volatility)
+	// Alternating ±1% returns: sample standard deviation of 20 values is sqrt(20/19)
+	assert.InDelta(t, 1.0260*15.8745, *perf[IndexVolatilityWindow].Volatility, 0.01)
+
+	assert.Nil(t, perf[IndexShortMAWindow-2].MA20)
+	require.NotNil(t, perf[IndexShortMAWindow-1].MA20)
+	assert.InDelta(t, 100.5, *perf[IndexShortMAWindow-1].MA20, 0.0001)
+	assert.Nil(t, perf[IndexLongMAWindow-2].MA50)
+	require.NotNil(t, perf[IndexLongMAWindow-1].MA50)
+	assert.InDelta(t, 100.5, *perf[IndexLongMAWindow-1].MA50, 0.0001)
+}
+
+func TestIndexPerformanceCSVRoundTrip(t *testing.T) {
+	vol, ma := 12.5, 101.25
+	rows := []IndexPerformanceRow{
+		{Date: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Index: IndexISX60, Level: 1000},
+		{Date: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Index: IndexISX60, Level: 990, Return: -1, CumulativeReturn: -1,
+			Volatility: &vol, Drawdown: -1, MaxDrawdown: -1, MA20: &ma},
+	}
+	path := filepath.Join(t.TempDir(), "indexes_analytics.csv")
+	require.NoError(t, WriteIndexPerformanceCSV(path, rows))
+
+	got, err := ReadIndexPerformanceCSV(path)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Nil(t, got[0].Volatility)
+	assert.Nil(t, got[0].MA20)
+	require.NotNil(t, got[1].Volatility)
+	assert.InDelta(t, 12.5, *got[1].Volatility, 0.0001)
+	require.NotNil(t, got[1].MA20)
+	assert.InDelta(t, 101.25, *got[1].MA20, 0.0001)
+	assert.Nil(t, got[1].MA50)
+	assert.InDelta(t, -1.0, got[1].MaxDrawdown, 0.0001)
+}
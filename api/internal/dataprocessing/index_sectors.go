@@ -0,0 +1,145 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndexDefinition names one index column of indexes.csv
+type IndexDefinition struct {
+	Name  string `json:"name"`  // Column name in indexes.csv
+	Label string `json:"label"` // Display name
+}
+
+// sectorIndex is a sector index and the labels the daily report uses for it
+type sectorIndex struct {
+	IndexDefinition
+	aliases []string
+}
+
+// sectorIndices lists the sector indices published in the daily report, in
+// the column order of indexes.csv. Adding one appends a column, so existing
+// readers keep working.
+var sectorIndices = []sectorIndex{
+	{IndexDefinition{"Banking", "Banking"}, []string{"banking", "banks", "bank"}},
+	{IndexDefinition{"Telecom", "Telecommunication"}, []string{"telecommunication", "telecommunications", "telecom"}},
+	{IndexDefinition{"Insurance", "Insurance"}, []string{"insurance"}},
+	{IndexDefinition{"Investment", "Investment"}, []string{"investment", "investments"}},
+	{IndexDefinition{"Services", "Services"}, []string{"services", "service"}},
+	{IndexDefinition{"Industry", "Industry"}, []string{"industry", "industrial", "industries"}},
+	{IndexDefinition{"Hotels", "Hotels & Tourism"}, []string{"hotels & tourism", "hotels and tourism", "hotels", "tourism"}},
+	{IndexDefinition{"Agriculture", "Agriculture"}, []string{"agriculture", "agricultural"}},
+	{IndexDefinition{"MoneyTransfer", "Money Transfer"}, []string{"money transfer", "money transfers"}},
+}
+
+// labelSuffixes are dropped from report labels before matching a sector
+var labelSuffixes = []string{" index", " indices", " sector", " sectors"}
+
+// IndexDefinitions returns every index column of indexes.csv in order:
+// ISX60, ISX15, then the sector indices
+func IndexDefinitions() []IndexDefinition {
+	defs := []IndexDefinition{{IndexISX60, "ISX 60"}, {IndexISX15, "ISX 15"}}
+	for _, s := range sectorIndices {
+		defs = append(defs, s.IndexDefinition)
+	}
+	return defs
+}
+
+// IndexCSVHeader is the header row of the wide indexes.csv
+func IndexCSVHeader() []string {
+	header := []string{"Date"}
+	for _, def := range IndexDefinitions() {
+		header = append(header, def.Name)
+	}
+	return header
+}
+
+// IndexLongCSVHeader is the header row of the normalized indexes_long.csv
+func IndexLongCSVHeader() []string {
+	return []string{"Date", "Index", "Value"}
+}
+
+// MatchSectorIndex returns the sector index a report label refers to, such as
+// "Banking Sector Index" or "Hotels & Tourism". Only whole labels match, so
+// company names like "Bank of Baghdad" are ignored.
+func MatchSectorIndex(label string) (string, bool) {
+	label = strings.ToLower(strings.Join(strings.Fields(label), " "))
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range labelSuffixes {
+			if strings.HasSuffix(label, suffix) {
+				label = strings.TrimSuffix(label, suffix)
+				trimmed = true
+			}
+		}
+	}
+
+	for _, s := range sectorIndices {
+		for _, alias := range s.aliases {
+			if label == alias {
+				return s.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IndexTableRow is one date of indexes.csv. Indices not published that day
+// are absent from Values.
+type IndexTableRow struct {
+	Date   time.Time
+	Values map[string]float64
+}
+
+// ReadIndexTable loads the wide indexes.csv with whatever index columns its
+// header declares, so files written before sector indices were extracted
+// still load. Rows are ordered by date.
+func ReadIndexTable(path string) ([]string, []IndexTableRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open index file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+	if len(records) == 0 || len(records[0]) < 2 || strings.TrimSpace(records[0][0]) != "Date" {
+		return nil, nil, fmt.Errorf("invalid index file header")
+	}
+
+	columns := make([]string, 0, len(records[0])-1)
+	for _, name := range records[0][1:] {
+		columns = append(columns, strings.TrimSpace(name))
+	}
+
+	var rows []IndexTableRow
+	for _, rec := range records[1:] {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(rec[0]))
+		if err != nil {
+			continue
+		}
+		row := IndexTableRow{Date: date, Values: make(map[string]float64)}
+		for i, name := range columns {
+			if i+1 >= len(rec) {
+				break
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(rec[i+1]), 64); err == nil && v > 0 {
+				row.Values[name] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Date.Before(rows[j].Date) })
+	return columns, rows, nil
+}
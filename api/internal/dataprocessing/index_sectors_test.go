@@ -0,0 +1,67 @@
+package dataprocessing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchSectorIndex(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"Banking", "Banking"},
+		{"Banking Sector Index", "Banking"},
+		{"  TELECOMMUNICATION   sector ", "Telecom"},
+		{"Hotels & Tourism Index", "Hotels"},
+		{"Money Transfer Sector", "MoneyTransfer"},
+		{"Industrial", "Industry"},
+		{"Bank of Baghdad", ""},
+		{"ISX Index 60", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			name, ok := MatchSectorIndex(tt.label)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, name)
+		})
+	}
+}
+
+func TestIndexCSVHeader(t *testing.T) {
+	header := IndexCSVHeader()
+	// Existing readers rely on the first three columns
+	assert.Equal(t, []string{"Date", IndexISX60, IndexISX15}, header[:3])
+	assert.Contains(t, header, "Banking")
+	assert.Len(t, header, len(IndexDefinitions())+1)
+}
+
+func TestReadIndexTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "indexes.csv")
+
+	// A file written before sector indices were extracted
+	require.NoError(t, os.WriteFile(path, []byte("Date,ISX60,ISX15\n2025-01-06,1010.00,\n2025-01-05,1000.00,500.00\n"), 0644))
+	columns, rows, err := ReadIndexTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{IndexISX60, IndexISX15}, columns)
+	require.Len(t, rows, 2)
+	assert.Equal(t, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), rows[0].Date)
+	assert.Equal(t, map[string]float64{IndexISX60: 1010}, rows[1].Values, "unpublished indices are absent")
+
+	require.NoError(t, os.WriteFile(path, []byte("Date,ISX60,ISX15,Banking\n2025-01-05,1000.00,500.00,75.25\n"), 0644))
+	columns, rows, err = ReadIndexTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{IndexISX60, IndexISX15, "Banking"}, columns)
+	assert.Equal(t, 75.25, rows[0].Values["Banking"])
+
+	require.NoError(t, os.WriteFile(path, []byte("Ticker,Close\n"), 0644))
+	_, _, err = ReadIndexTable(path)
+	assert.Error(t, err)
+}
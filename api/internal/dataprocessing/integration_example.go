@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"isxcli/internal/errors"
+	"isxcli/internal/exporter"
 	"isxcli/pkg/contracts/domain"
 )
 
@@ -26,12 +27,19 @@ type IntegrationExample struct {
 
 // NewIntegrationExample creates a new integration example.
 func NewIntegrationExample(logger *slog.Logger) *IntegrationExample {
+	return NewIntegrationExampleWithOptions(logger, exporter.DefaultExportOptions())
+}
+
+// NewIntegrationExampleWithOptions creates an integration example whose
+// summary CSV uses the given export formatting.
+func NewIntegrationExampleWithOptions(logger *slog.Logger, opts exporter.ExportOptions) *IntegrationExample {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
 	// Create summarizer with extended metrics for comprehensive output
 	config := ExtendedSummarizerConfig()
+	config.Export = &opts
 	summarizer := NewSummarizer(logger, config)
 
 	return &IntegrationExample{
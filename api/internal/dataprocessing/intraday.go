@@ -0,0 +1,350 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// IntradayFileSuffix is the filename suffix used for ISX intraday bulletins,
+// e.g. "2024 01 15 ISX Intraday Report.xlsx".
+const IntradayFileSuffix = " ISX Intraday Report.xlsx"
+
+// IntervalTick is used for bars built from single trades rather than
+// pre-aggregated OHLC rows.
+const IntervalTick = "tick"
+
+// intradayCSVHeaders is the column layout of data/intraday/{TICKER}/{date}.csv
+var intradayCSVHeaders = []string{
+	"Timestamp", "Symbol", "Interval", "Open", "High", "Low", "Close",
+	"Volume", "Value", "Trades", "VWAP",
+}
+
+// IsIntradayFile reports whether the filename is an ISX intraday bulletin.
+func IsIntradayFile(name string) bool {
+	return strings.HasSuffix(filepath.Base(name), IntradayFileSuffix)
+}
+
+// IntradayFileDate extracts the trading date from an intraday bulletin filename.
+func IntradayFileDate(name string) (time.Time, error) {
+	base := filepath.Base(name)
+	if !IsIntradayFile(base) {
+		return time.Time{}, fmt.Errorf("not an intraday bulletin: %s", base)
+	}
+	return time.Parse("2006 01 02", strings.TrimSuffix(base, IntradayFileSuffix))
+}
+
+// ParseIntradayFile reads an ISX intraday bulletin and returns its bars sorted
+// by symbol and timestamp. Bulletins that only carry a trade price per row are
+// treated as tick data (open = high = low = close = price).
+func ParseIntradayFile(filePath string) ([]domain.IntradayData, error) {
+	date, err := IntradayFileDate(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	var rows [][]string
+	headerRow := -1
+	for _, name := range f.GetSheetList() {
+		sheetRows, err := f.GetRows(name)
+		if err != nil {
+			continue
+		}
+		if idx := findIntradayHeader(sheetRows); idx >= 0 {
+			rows = sheetRows
+			headerRow = idx
+			break
+		}
+	}
+	if headerRow == -1 {
+		return nil, fmt.Errorf("could not find intraday header row in file")
+	}
+
+	columnMap := mapIntradayColumns(rows[headerRow])
+	if _, ok := columnMap["close"]; !ok {
+		return nil, fmt.Errorf("could not find required column: price")
+	}
+
+	// OHLC bulletins default to one-minute bars unless an interval column says otherwise
+	interval := IntervalTick
+	if _, ok := columnMap["open"]; ok {
+		interval = "1m"
+	}
+
+	var bars []domain.IntradayData
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+
+		getString := func(col string) string {
+			if idx, ok := columnMap[col]; ok && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+			return ""
+		}
+		parseFloat := func(col string) float64 {
+			val, _ := strconv.ParseFloat(strings.ReplaceAll(getString(col), ",", ""), 64)
+			return val
+		}
+		parseInt := func(col string) int64 {
+			val, _ := strconv.ParseInt(strings.ReplaceAll(getString(col), ",", ""), 10, 64)
+			return val
+		}
+
+		symbol := strings.ToUpper(getString("code"))
+		if symbol == "" {
+			continue
+		}
+
+		ts, err := parseIntradayTime(date, getString("time"))
+		if err != nil {
+			slog.Debug("Skipped intraday row - invalid time",
+				slog.Int("row_number", i),
+				slog.String("value", getString("time")))
+			continue
+		}
+
+		closePrice := parseFloat("close")
+		if closePrice <= 0 {
+			continue
+		}
+
+		bar := domain.IntradayData{
+			Symbol:    symbol,
+			Timestamp: ts,
+			Interval:  interval,
+			Open:      closePrice,
+			High:      closePrice,
+			Low:       closePrice,
+			Close:     closePrice,
+			Volume:    parseInt("volume"),
+			Value:     parseFloat("value"),
+			Trades:    int(parseInt("trades")),
+		}
+		if v := getString("interval"); v != "" {
+			bar.Interval = v
+		}
+		if v := parseFloat("open"); v > 0 {
+			bar.Open = v
+		}
+		if v := parseFloat("high"); v > 0 {
+			bar.High = v
+		}
+		if v := parseFloat("low"); v > 0 {
+			bar.Low = v
+		}
+		if bar.Trades == 0 && bar.Volume > 0 {
+			bar.Trades = 1
+		}
+		if bar.Value == 0 {
+			bar.Value = closePrice * float64(bar.Volume)
+		}
+		if bar.Volume > 0 {
+			bar.VWAP = bar.Value / float64(bar.Volume)
+		}
+
+		bars = append(bars, bar)
+	}
+
+	sortIntradayBars(bars)
+
+	slog.Info("Intraday bulletin parsed",
+		slog.String("file", filepath.Base(filePath)),
+		slog.String("interval", interval),
+		slog.Int("bars", len(bars)))
+
+	return bars, nil
+}
+
+// AttachIntraday links parsed intraday bars to the matching daily records by symbol.
+func AttachIntraday(report *domain.DailyReport, bars []domain.IntradayData) {
+	if report == nil || len(bars) == 0 {
+		return
+	}
+
+	bySymbol := GroupIntradayBySymbol(bars)
+	for i := range report.Records {
+		if symbolBars, ok := bySymbol[strings.ToUpper(report.Records[i].CompanySymbol)]; ok {
+			report.Records[i].Intraday = symbolBars
+		}
+	}
+}
+
+// GroupIntradayBySymbol splits bars into per-symbol slices.
+func GroupIntradayBySymbol(bars []domain.IntradayData) map[string][]domain.IntradayData {
+	bySymbol := make(map[string][]domain.IntradayData)
+	for _, bar := range bars {
+		bySymbol[bar.Symbol] = append(bySymbol[bar.Symbol], bar)
+	}
+	return bySymbol
+}
+
+// WriteIntradayCSV writes a single symbol's bars for one day to filePath,
+// creating the parent directory if needed.
+func WriteIntradayCSV(filePath string, bars []domain.IntradayData) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create intraday directory: %w", err)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create intraday file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(intradayCSVHeaders); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, bar := range bars {
+		row := []string{
+			bar.Timestamp.Format(time.RFC3339),
+			bar.Symbol,
+			bar.Interval,
+			fmt.Sprintf("%.3f", bar.Open),
+			fmt.Sprintf("%.3f", bar.High),
+			fmt.Sprintf("%.3f", bar.Low),
+			fmt.Sprintf("%.3f", bar.Close),
+			strconv.FormatInt(bar.Volume, 10),
+			fmt.Sprintf("%.2f", bar.Value),
+			strconv.Itoa(bar.Trades),
+			fmt.Sprintf("%.3f", bar.VWAP),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadIntradayCSV loads bars previously written by WriteIntradayCSV.
+func ReadIntradayCSV(filePath string) ([]domain.IntradayData, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open intraday file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read intraday file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	bars := make([]domain.IntradayData, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		if len(rec) < len(intradayCSVHeaders) {
+			return nil, fmt.Errorf("row %d: expected %d columns, got %d", i+2, len(intradayCSVHeaders), len(rec))
+		}
+
+		ts, err := time.Parse(time.RFC3339, rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid timestamp: %w", i+2, err)
+		}
+
+		bar := domain.IntradayData{
+			Symbol:    rec[1],
+			Timestamp: ts,
+			Interval:  rec[2],
+		}
+		bar.Open, _ = strconv.ParseFloat(rec[3], 64)
+		bar.High, _ = strconv.ParseFloat(rec[4], 64)
+		bar.Low, _ = strconv.ParseFloat(rec[5], 64)
+		bar.Close, _ = strconv.ParseFloat(rec[6], 64)
+		bar.Volume, _ = strconv.ParseInt(rec[7], 10, 64)
+		bar.Value, _ = strconv.ParseFloat(rec[8], 64)
+		bar.Trades, _ = strconv.Atoi(rec[9])
+		bar.VWAP, _ = strconv.ParseFloat(rec[10], 64)
+
+		bars = append(bars, bar)
+	}
+
+	return bars, nil
+}
+
+// findIntradayHeader returns the index of the header row, or -1.
+func findIntradayHeader(rows [][]string) int {
+	for i, row := range rows {
+		rowText := strings.ToLower(strings.Join(row, " "))
+		if strings.Contains(rowText, "code") && strings.Contains(rowText, "time") &&
+			(strings.Contains(rowText, "price") || strings.Contains(rowText, "close")) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mapIntradayColumns maps header names of an intraday bulletin to column indices.
+func mapIntradayColumns(header []string) map[string]int {
+	columnMap := make(map[string]int)
+	for j, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		switch {
+		case h == "code" || h == "symbol":
+			columnMap["code"] = j
+		case strings.Contains(h, "time"):
+			columnMap["time"] = j
+		case h == "interval":
+			columnMap["interval"] = j
+		case strings.Contains(h, "open"):
+			columnMap["open"] = j
+		case strings.Contains(h, "high"):
+			columnMap["high"] = j
+		case strings.Contains(h, "low"):
+			columnMap["low"] = j
+		case strings.Contains(h, "close") || strings.Contains(h, "price"):
+			columnMap["close"] = j
+		case strings.Contains(h, "trades"):
+			columnMap["trades"] = j
+		case strings.Contains(h, "volume") || strings.Contains(h, "quantity"):
+			columnMap["volume"] = j
+		case strings.Contains(h, "value"):
+			columnMap["value"] = j
+		}
+	}
+	return columnMap
+}
+
+// parseIntradayTime combines the bulletin date with a time-of-day cell.
+func parseIntradayTime(date time.Time, value string) (time.Time, error) {
+	for _, layout := range []string{"15:04:05", "15:04", "3:04:05 PM", "3:04 PM"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+		}
+	}
+	// Excel may hand back the time as a fraction of a day
+	if frac, err := strconv.ParseFloat(value, 64); err == nil && frac >= 0 && frac < 1 {
+		return date.Add(time.Duration(frac * float64(24*time.Hour))).Round(time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("unable to parse time: %q", value)
+}
+
+// sortIntradayBars orders bars by symbol, then timestamp.
+func sortIntradayBars(bars []domain.IntradayData) {
+	sort.SliceStable(bars, func(i, j int) bool {
+		if bars[i].Symbol != bars[j].Symbol {
+			return bars[i].Symbol < bars[j].Symbol
+		}
+		return bars[i].Timestamp.Before(bars[j].Timestamp)
+	})
+}
@@ -0,0 +1,183 @@
+package dataprocessing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// SnapshotFileSuffix is the filename suffix of mid-session ISX bulletins. The
+// prefix carries the date and publication time, e.g.
+// "2024 01 15 1130 ISX Snapshot Report.xlsx".
+const SnapshotFileSuffix = " ISX Snapshot Report.xlsx"
+
+// snapshotCSVHeaders is the column layout of data/snapshots/{date}/{HHMM}.csv
+var snapshotCSVHeaders = []string{
+	"AsOf", "Symbol", "CompanyName", "OpenPrice", "HighPrice", "LowPrice",
+	"ClosePrice", "PrevClosePrice", "Change", "ChangePercent", "NumTrades",
+	"Volume", "Value", "Source",
+}
+
+// IntradaySnapshot is a partial-day view of the market taken from a
+// mid-session bulletin. It is preliminary data and is kept apart from the
+// end-of-day records; the daily report for the same date supersedes it.
+type IntradaySnapshot struct {
+	Date    time.Time            `json:"date"`
+	AsOf    time.Time            `json:"asOf"`
+	Source  string               `json:"source"`
+	Records []domain.TradeRecord `json:"records"`
+}
+
+// IsSnapshotFile reports whether the filename is a mid-session snapshot bulletin.
+func IsSnapshotFile(name string) bool {
+	return strings.HasSuffix(filepath.Base(name), SnapshotFileSuffix)
+}
+
+// SnapshotFileTime extracts the publication time from a snapshot filename.
+// Bulletins carry exchange-local wall-clock times, which are kept as-is.
+func SnapshotFileTime(name string) (time.Time, error) {
+	base := filepath.Base(name)
+	if !IsSnapshotFile(base) {
+		return time.Time{}, fmt.Errorf("not a snapshot bulletin: %s", base)
+	}
+	return time.Parse("2006 01 02 1504", strings.TrimSuffix(base, SnapshotFileSuffix))
+}
+
+// ParseSnapshotFile reads a mid-session bulletin. Snapshot bulletins share the
+// daily report layouts, so the sheet is parsed through the parser registry.
+func ParseSnapshotFile(filePath string) (*IntradaySnapshot, error) {
+	asOf, err := SnapshotFileTime(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := ParseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range report.Records {
+		report.Records[i].Date = date
+	}
+
+	return &IntradaySnapshot{
+		Date:    date,
+		AsOf:    asOf,
+		Source:  filepath.Base(filePath),
+		Records: report.Records,
+	}, nil
+}
+
+// WriteSnapshotCSV writes a snapshot to filePath, creating the parent
+// directory if needed. Records are ordered by symbol.
+func WriteSnapshotCSV(filePath string, snapshot *IntradaySnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	records := append([]domain.TradeRecord(nil), snapshot.Records...)
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CompanySymbol < records[j].CompanySymbol
+	})
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(snapshotCSVHeaders); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	asOf := snapshot.AsOf.Format(time.RFC3339)
+	for _, rec := range records {
+		row := []string{
+			asOf,
+			rec.CompanySymbol,
+			rec.CompanyName,
+			fmt.Sprintf("%.3f", rec.OpenPrice),
+			fmt.Sprintf("%.3f", rec.HighPrice),
+			fmt.Sprintf("%.3f", rec.LowPrice),
+			fmt.Sprintf("%.3f", rec.ClosePrice),
+			fmt.Sprintf("%.3f", rec.PrevClosePrice),
+			fmt.Sprintf("%.3f", rec.Change),
+			fmt.Sprintf("%.2f", rec.ChangePercent),
+			strconv.FormatInt(rec.NumTrades, 10),
+			strconv.FormatInt(rec.Volume, 10),
+			fmt.Sprintf("%.2f", rec.Value),
+			snapshot.Source,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadSnapshotCSV loads a snapshot previously written by WriteSnapshotCSV.
+func ReadSnapshotCSV(filePath string) (*IntradaySnapshot, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	snapshot := &IntradaySnapshot{Records: []domain.TradeRecord{}}
+	if len(rows) < 2 {
+		return snapshot, nil
+	}
+
+	for i, row := range rows[1:] {
+		if len(row) < len(snapshotCSVHeaders) {
+			return nil, fmt.Errorf("row %d: expected %d columns, got %d", i+2, len(snapshotCSVHeaders), len(row))
+		}
+
+		asOf, err := time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid timestamp: %w", i+2, err)
+		}
+		if i == 0 {
+			snapshot.AsOf = asOf
+			snapshot.Date = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+			snapshot.Source = row[13]
+		}
+
+		rec := domain.TradeRecord{
+			CompanySymbol: row[1],
+			CompanyName:   row[2],
+			Date:          snapshot.Date,
+			TradingStatus: true,
+		}
+		rec.OpenPrice, _ = strconv.ParseFloat(row[3], 64)
+		rec.HighPrice, _ = strconv.ParseFloat(row[4], 64)
+		rec.LowPrice, _ = strconv.ParseFloat(row[5], 64)
+		rec.ClosePrice, _ = strconv.ParseFloat(row[6], 64)
+		rec.PrevClosePrice, _ = strconv.ParseFloat(row[7], 64)
+		rec.Change, _ = strconv.ParseFloat(row[8], 64)
+		rec.ChangePercent, _ = strconv.ParseFloat(row[9], 64)
+		rec.NumTrades, _ = strconv.ParseInt(row[10], 10, 64)
+		rec.Volume, _ = strconv.ParseInt(row[11], 10, 64)
+		rec.Value, _ = strconv.ParseFloat(row[12], 64)
+
+		snapshot.Records = append(snapshot.Records, rec)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,59 @@
+package dataprocessing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSnapshotFileTime(t *testing.T) {
+	asOf, err := SnapshotFileTime("/downloads/2025 01 05 1130 ISX Snapshot Report.xlsx")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 5, 11, 30, 0, 0, time.UTC), asOf)
+
+	assert.False(t, IsSnapshotFile("2025 01 05 ISX Daily Report.xlsx"))
+	assert.False(t, IsSnapshotFile("2025 01 05 ISX Intraday Report.xlsx"))
+
+	_, err = SnapshotFileTime("2025 01 05 ISX Daily Report.xlsx")
+	assert.Error(t, err)
+}
+
+func TestParseSnapshotFile_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), "Bulletin")
+	for i, row := range headerMappedRows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		values := make([]interface{}, len(row))
+		for j, v := range row {
+			values[j] = v
+		}
+		require.NoError(t, f.SetSheetRow("Bulletin", cell, &values))
+	}
+	path := filepath.Join(dir, "2025 01 05 1130 ISX Snapshot Report.xlsx")
+	require.NoError(t, f.SaveAs(path))
+
+	snapshot, err := ParseSnapshotFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-05", snapshot.Date.Format("2006-01-02"))
+	assert.Equal(t, "11:30", snapshot.AsOf.Format("15:04"))
+	require.Len(t, snapshot.Records, 1)
+	assert.Equal(t, snapshot.Date, snapshot.Records[0].Date)
+
+	csvPath := filepath.Join(dir, "snapshots", "2025-01-05", "1130.csv")
+	require.NoError(t, WriteSnapshotCSV(csvPath, snapshot))
+
+	loaded, err := ReadSnapshotCSV(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.AsOf, loaded.AsOf)
+	assert.Equal(t, snapshot.Date, loaded.Date)
+	assert.Equal(t, "2025 01 05 1130 ISX Snapshot Report.xlsx", loaded.Source)
+	require.Len(t, loaded.Records, 1)
+	assert.Equal(t, "BBOB", loaded.Records[0].CompanySymbol)
+	assert.Equal(t, 1.05, loaded.Records[0].ClosePrice)
+	assert.Equal(t, int64(1000000), loaded.Records[0].Volume)
+}
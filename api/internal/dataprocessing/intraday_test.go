@@ -0,0 +1,157 @@
+package dataprocessing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// writeIntradayWorkbook builds a minimal intraday bulletin with the given rows.
+func writeIntradayWorkbook(t *testing.T, dir string, rows [][]interface{}) string {
+	t.Helper()
+
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow(sheet, cell, &row))
+	}
+
+	filePath := filepath.Join(dir, "2025 01 05 ISX Intraday Report.xlsx")
+	require.NoError(t, f.SaveAs(filePath))
+	return filePath
+}
+
+func TestIntradayFileDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"intraday bulletin", "2025 01 05 ISX Intraday Report.xlsx", time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), false},
+		{"with directory", filepath.Join("downloads", "2024 12 31 ISX Intraday Report.xlsx"), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{"daily report", "2025 01 05 ISX Daily Report.xlsx", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IntradayFileDate(tt.file)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got))
+		})
+	}
+}
+
+func TestParseIntradayFile_Ticks(t *testing.T) {
+	filePath := writeIntradayWorkbook(t, t.TempDir(), [][]interface{}{
+		{"ISX Intraday Trades"},
+		{"Code", "Time", "Price", "Volume", "Value"},
+		{"BMNS", "10:15:00", "1,250", "1000", ""},
+		{"BBOB", "10:01", "0.35", "20000", "7000"},
+		{"BBOB", "09:45", "0.34", "10000", "3400"},
+		{"", "11:00", "1.00", "1", "1"},
+		{"BBOB", "bad", "0.35", "1", "1"},
+	})
+
+	bars, err := ParseIntradayFile(filePath)
+	require.NoError(t, err)
+	require.Len(t, bars, 3)
+
+	// Sorted by symbol, then time
+	assert.Equal(t, "BBOB", bars[0].Symbol)
+	assert.Equal(t, 9, bars[0].Timestamp.Hour())
+	assert.Equal(t, 45, bars[0].Timestamp.Minute())
+	assert.Equal(t, "BBOB", bars[1].Symbol)
+	assert.Equal(t, "BMNS", bars[2].Symbol)
+
+	tick := bars[2]
+	assert.Equal(t, IntervalTick, tick.Interval)
+	assert.Equal(t, 1250.0, tick.Open)
+	assert.Equal(t, 1250.0, tick.Close)
+	assert.Equal(t, int64(1000), tick.Volume)
+	assert.Equal(t, 1250000.0, tick.Value, "value derived from price * volume")
+	assert.Equal(t, 1, tick.Trades)
+	assert.InDelta(t, 1250.0, tick.VWAP, 0.0001)
+	assert.Equal(t, time.Date(2025, 1, 5, 10, 15, 0, 0, time.UTC), tick.Timestamp)
+}
+
+func TestParseIntradayFile_Bars(t *testing.T) {
+	filePath := writeIntradayWorkbook(t, t.TempDir(), [][]interface{}{
+		{"Code", "Time", "Open", "High", "Low", "Close", "No. of Trades", "Volume", "Value"},
+		{"TASC", "10:00", "7.00", "7.10", "6.95", "7.05", "4", "400", "2820"},
+	})
+
+	bars, err := ParseIntradayFile(filePath)
+	require.NoError(t, err)
+	require.Len(t, bars, 1)
+
+	bar := bars[0]
+	assert.Equal(t, "1m", bar.Interval)
+	assert.Equal(t, 7.00, bar.Open)
+	assert.Equal(t, 7.10, bar.High)
+	assert.Equal(t, 6.95, bar.Low)
+	assert.Equal(t, 7.05, bar.Close)
+	assert.Equal(t, 4, bar.Trades)
+	assert.InDelta(t, 7.05, bar.VWAP, 0.0001)
+}
+
+func TestParseIntradayFile_NoHeader(t *testing.T) {
+	filePath := writeIntradayWorkbook(t, t.TempDir(), [][]interface{}{
+		{"Company Name", "Code", "Closing Price"},
+	})
+
+	_, err := ParseIntradayFile(filePath)
+	assert.Error(t, err)
+}
+
+func TestAttachIntraday(t *testing.T) {
+	report := &domain.DailyReport{Records: []domain.TradeRecord{
+		{CompanySymbol: "BBOB"},
+		{CompanySymbol: "TASC"},
+	}}
+	bars := []domain.IntradayData{
+		{Symbol: "BBOB", Close: 1},
+		{Symbol: "BBOB", Close: 2},
+	}
+
+	AttachIntraday(report, bars)
+
+	assert.Len(t, report.Records[0].Intraday, 2)
+	assert.Empty(t, report.Records[1].Intraday)
+}
+
+func TestIntradayCSVRoundTrip(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "BBOB", "2025-01-05.csv")
+	bars := []domain.IntradayData{
+		{
+			Symbol:    "BBOB",
+			Timestamp: time.Date(2025, 1, 5, 9, 45, 0, 0, time.UTC),
+			Interval:  IntervalTick,
+			Open:      0.34, High: 0.34, Low: 0.34, Close: 0.34,
+			Volume: 10000, Value: 3400, Trades: 1, VWAP: 0.34,
+		},
+	}
+
+	require.NoError(t, WriteIntradayCSV(filePath, bars))
+
+	got, err := ReadIntradayCSV(filePath)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, bars[0].Symbol, got[0].Symbol)
+	assert.True(t, bars[0].Timestamp.Equal(got[0].Timestamp))
+	assert.Equal(t, bars[0].Volume, got[0].Volume)
+	assert.InDelta(t, bars[0].Close, got[0].Close, 0.0001)
+	assert.Equal(t, bars[0].Trades, got[0].Trades)
+}
@@ -2,6 +2,7 @@ package dataprocessing
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +14,25 @@ import (
 )
 
 
+// DailyReportFileSuffix is the filename suffix of ISX daily reports,
+// e.g. "2024 01 15 ISX Daily Report.xlsx".
+const DailyReportFileSuffix = " ISX Daily Report.xlsx"
+
+// preferredSheetNames are tried first when looking for the trading data sheet
+var preferredSheetNames = []string{"Bullient  ", "Bullient", "Bulletin", "Bulletin  ", "trading", "Trading"}
+
+// requiredColumns must be mapped for a sheet to be parsed
+var requiredColumns = []string{"code", "close", "volume", "value"}
+
+func init() {
+	RegisterParser(headerMappedParser{})
+}
+
 // ParseFile reads an ISX daily report Excel file and extracts the trading data.
+// The layout parser is chosen from the parser registry based on sheet structure.
+// Only the leading rows of each sheet are read for detection; the chosen sheet
+// is then streamed row by row when its layout supports it, so a workbook is
+// never held in memory as a whole.
 func ParseFile(filePath string) (*domain.DailyReport, error) {
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
@@ -21,324 +40,465 @@ func ParseFile(filePath string) (*domain.DailyReport, error) {
 	}
 	defer f.Close()
 
-	// Find the correct sheet name by looking for one that contains trading data
-	var rows [][]string
-	var sheetFound bool
-	var sheetName string
-
-	// Try different possible sheet names
-	possibleNames := []string{"Bullient  ", "Bullient", "Bulletin", "Bulletin  ", "trading", "Trading"}
-
-	for _, name := range possibleNames {
-		if testRows, testErr := f.GetRows(name); testErr == nil {
-			rows = testRows
-			sheetFound = true
-			sheetName = name
-			break
-		}
+	parser, sheet, err := SelectParser(sampleCandidateSheets(f))
+	if err != nil {
+		return nil, fmt.Errorf("could not find trading data sheet in file: %w", err)
 	}
 
-	// If none of the common names work, try to find a sheet with trading data
-	if !sheetFound {
-		for _, name := range f.GetSheetList() {
-			if testRows, testErr := f.GetRows(name); testErr == nil && len(testRows) > 3 {
-				// Check if this sheet contains trading data by looking for typical headers
-				for _, row := range testRows[:4] {
-					rowText := strings.ToLower(strings.Join(row, " "))
-					if strings.Contains(rowText, "company name") && strings.Contains(rowText, "code") &&
-						(strings.Contains(rowText, "price") || strings.Contains(rowText, "volume")) {
-						rows = testRows
-						sheetFound = true
-						sheetName = name
-						break
-					}
-				}
-				if sheetFound {
-					break
-				}
-			}
-		}
+	_, streaming := parser.(StreamingLayoutParser)
+	slog.Info("Found trading data in sheet",
+		slog.String("sheet_name", sheet.Name),
+		slog.String("layout", parser.Name()),
+		slog.Int("layout_version", parser.Version()),
+		slog.Bool("streaming", streaming))
+
+	report, err := parseSheet(f, parser, sheet.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	if !sheetFound {
-		return nil, fmt.Errorf("could not find trading data sheet in file")
+	date, _ := time.Parse("2006 01 02", strings.TrimSuffix(filepath.Base(filePath), DailyReportFileSuffix))
+	for i := range report.Records {
+		report.Records[i].Date = date
 	}
 
-	slog.Info("Found trading data in sheet", slog.String("sheet_name", sheetName))
-	slog.Info("Sheet information", slog.Int("total_rows", len(rows)))
+	slog.Info("Processing complete", slog.Int("total_records", len(report.Records)))
 
-	// Print first 20 rows to understand the structure
-	slog.Info("=== First 20 rows ===")
-	for i := 0; i < len(rows) && i < 20; i++ {
-		slog.Info("Row data", slog.Int("row_number", i), slog.Any("content", rows[i]))
-	}
+	return report, nil
+}
 
-	// Find the last row with actual data
-	lastDataRow := -1
-	for i := len(rows) - 1; i >= 0; i-- {
-		if len(rows[i]) > 5 {
-			// Check if this row has meaningful data (not just empty cells)
-			hasData := false
-			for _, cell := range rows[i] {
-				if strings.TrimSpace(cell) != "" {
-					hasData = true
-					break
-				}
-			}
-			if hasData {
-				lastDataRow = i
-				break
-			}
+// parseSheet hands the named sheet to the parser, streaming its rows when the
+// layout supports it and loading the whole sheet otherwise
+func parseSheet(f *excelize.File, parser LayoutParser, name string) (*domain.DailyReport, error) {
+	sp, ok := parser.(StreamingLayoutParser)
+	if !ok {
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", name, err)
 		}
+		slog.Info("Sheet information", slog.Int("total_rows", len(rows)))
+		return parser.Parse(SheetData{Name: name, Rows: rows})
 	}
 
-	slog.Info("Data analysis", slog.Int("last_data_row", lastDataRow))
-	if lastDataRow > 0 {
-		slog.Debug("Last data row found", 
-			slog.Int("row_index", lastDataRow),
-			slog.Any("row_content", rows[lastDataRow]))
+	rows, err := f.Rows(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet %s: %w", name, err)
 	}
+	defer rows.Close()
 
-	report := &domain.DailyReport{}
-	date, _ := time.Parse("2006 01 02", strings.TrimSuffix(strings.TrimPrefix(filePath, "downloads/"), " ISX Daily Report.xlsx"))
+	it := &excelRowIterator{rows: rows}
+	report, err := sp.ParseRows(it)
+	if err != nil {
+		return nil, err
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sheet %s: %w", name, err)
+	}
+	return report, nil
+}
 
-	// Find the header row and map column positions dynamically
-	headerRow := -1
-	columnMap := make(map[string]int)
+// sampleCandidateSheets returns the first DetectSampleRows rows of the workbook
+// sheets, with the usual bulletin sheet names first followed by the remaining
+// sheets in workbook order
+func sampleCandidateSheets(f *excelize.File) []SheetData {
+	var sheets []SheetData
+	seen := make(map[string]bool)
 
-	for i, row := range rows {
-		if len(row) < 5 {
-			continue
+	add := func(name string) {
+		if seen[name] {
+			return
 		}
-
-		// Look for header row containing key column names
-		rowText := strings.ToLower(strings.Join(row, " "))
-
-		// Debug: Show what we're looking for in each row
-		slog.Info("Row analysis", slog.Int("row_number", i), slog.String("text", rowText))
-
-		// More flexible header detection - look for key trading columns
-		if (strings.Contains(rowText, "company") || strings.Contains(rowText, "name")) &&
-			strings.Contains(rowText, "code") &&
-			(strings.Contains(rowText, "closing") || strings.Contains(rowText, "price")) &&
-			strings.Contains(rowText, "volume") {
-			headerRow = i
-			slog.Info("*** FOUND HEADER ROW ***", slog.Int("row_number", i))
-
-			// Map column positions based on header names
-			for j, header := range row {
-				headerLower := strings.ToLower(strings.TrimSpace(header))
-				slog.Info("Column mapping", slog.Int("column_index", j), slog.String("header", headerLower))
-
-				// Map different variations of column names
-				switch {
-				case strings.Contains(headerLower, "company") || (strings.Contains(headerLower, "name") && !strings.Contains(headerLower, "code")):
-					columnMap["company"] = j
-					slog.Info("Column mapped", slog.String("type", "COMPANY"), slog.Int("index", j), slog.String("header", headerLower))
-				case headerLower == "code":
-					columnMap["code"] = j
-					slog.Info("Column mapped", slog.String("type", "CODE"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "opening") && strings.Contains(headerLower, "price"):
-					columnMap["open"] = j
-					slog.Info("Column mapped", slog.String("type", "OPEN"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "highest") && strings.Contains(headerLower, "price"):
-					columnMap["high"] = j
-					slog.Info("Column mapped", slog.String("type", "HIGH"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "lowest") && strings.Contains(headerLower, "price"):
-					columnMap["low"] = j
-					slog.Info("Column mapped", slog.String("type", "LOW"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "average") && strings.Contains(headerLower, "price") && !strings.Contains(headerLower, "prev"):
-					columnMap["avg"] = j
-					slog.Info("Column mapped", slog.String("type", "AVERAGE"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "prev") && strings.Contains(headerLower, "average"):
-					columnMap["prev_avg"] = j
-					slog.Info("Column mapped", slog.String("type", "PREV_AVERAGE"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "closing") && strings.Contains(headerLower, "price") && !strings.Contains(headerLower, "prev"):
-					columnMap["close"] = j
-					slog.Info("Column mapped", slog.String("type", "CLOSE"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "prev") && strings.Contains(headerLower, "closing"):
-					columnMap["prev_close"] = j
-					slog.Info("Column mapped", slog.String("type", "PREV_CLOSE"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "change") && strings.Contains(headerLower, "%"):
-					columnMap["change_pct"] = j
-					slog.Info("Column mapped", slog.String("type", "CHANGE_PCT"), slog.Int("index", j), slog.String("header", headerLower))
-				case strings.Contains(headerLower, "no") && strings.Contains(headerLower, "trades"):
-					columnMap["num_trades"] = j
-					slog.Info("Column mapped", slog.String("type", "NUM_TRADES"), slog.Int("index", j), slog.String("header", headerLower))
-				case headerLower == "traded volume":
-					columnMap["volume"] = j
-					slog.Info("Column mapped", slog.String("type", "VOLUME"), slog.Int("index", j), slog.String("header", headerLower))
-				case headerLower == "traded value":
-					columnMap["value"] = j
-					slog.Info("Column mapped", slog.String("type", "VALUE"), slog.Int("index", j), slog.String("header", headerLower))
-				}
-			}
-			fmt.Printf("Final column mapping: %+v\n", columnMap)
-			break
+		seen[name] = true
+		if rows, err := sampleRows(f, name, DetectSampleRows); err == nil {
+			sheets = append(sheets, SheetData{Name: name, Rows: rows})
 		}
 	}
 
-	if headerRow == -1 {
-		return nil, fmt.Errorf("could not find header row in trading data")
+	for _, name := range preferredSheetNames {
+		if idx, err := f.GetSheetIndex(name); err == nil && idx >= 0 {
+			add(name)
+		}
 	}
+	for _, name := range f.GetSheetList() {
+		add(name)
+	}
+
+	return sheets
+}
 
-	// Verify we found all required columns
-	requiredCols := []string{"code", "close", "volume", "value"}
-	for _, col := range requiredCols {
-		if _, exists := columnMap[col]; !exists {
-			return nil, fmt.Errorf("could not find required column: %s", col)
+// sampleRows streams up to limit leading rows of a sheet, trimming the empty
+// rows at the end like GetRows does
+func sampleRows(f *excelize.File, name string, limit int) ([][]string, error) {
+	rows, err := f.Rows(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sample [][]string
+	last := 0
+	for len(sample) < limit && rows.Next() {
+		row, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		sample = append(sample, row)
+		if len(row) > 0 {
+			last = len(sample)
 		}
 	}
+	return sample[:last], rows.Error()
+}
+
+// excelRowIterator streams the rows of a worksheet from the workbook
+type excelRowIterator struct {
+	rows *excelize.Rows
+	row  []string
+	err  error
+}
+
+func (it *excelRowIterator) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+	it.row, it.err = it.rows.Columns()
+	return it.err == nil
+}
+
+func (it *excelRowIterator) Row() []string { return it.row }
 
-	// Process data rows starting after the header, up to the last data row
-	dataEndRow := len(rows)
-	if lastDataRow > 0 {
-		dataEndRow = lastDataRow + 1
+func (it *excelRowIterator) Err() error {
+	if it.err != nil {
+		return it.err
 	}
+	return it.rows.Error()
+}
 
-	slog.Info("Processing data rows", 
-		slog.Int("start_row", headerRow+1),
-		slog.Int("end_row", dataEndRow-1))
+// headerMappedParser handles reports with a header row naming each column
+// ("Company Name", "Code", "Closing Price", "Traded Volume", ...). Columns are
+// located by name, so reordered or added columns are tolerated.
+type headerMappedParser struct{}
 
-	for i := headerRow + 1; i < dataEndRow; i++ {
-		row := rows[i]
+func (headerMappedParser) Name() string { return "header-mapped" }
 
-		slog.Info("Processing row", slog.Int("row_number", i), slog.Any("content", row))
+func (headerMappedParser) Version() int { return 2 }
 
-		// Skip if not enough columns
-		if len(row) <= columnMap["value"] {
-			slog.Info("Skipped row - insufficient columns",
-				slog.Int("needed", columnMap["value"]+1),
-				slog.Int("got", len(row)))
-			continue
+func (headerMappedParser) Detect(sheet SheetData) int {
+	headerRow, columnMap := findHeaderRow(sheet.Rows)
+	if headerRow < 0 {
+		return 0
+	}
+	for _, col := range requiredColumns {
+		if _, ok := columnMap[col]; !ok {
+			// Still claim the sheet so Parse can report which column is missing
+			return 10
 		}
+	}
+	return 100
+}
 
-		// Skip empty rows - check if all relevant columns are empty
-		isEmpty := true
-		for _, colIndex := range columnMap {
-			if colIndex < len(row) && strings.TrimSpace(row[colIndex]) != "" {
-				isEmpty = false
-				break
-			}
-		}
-		if isEmpty {
-			fmt.Printf("  -> Skipped: Empty row\n")
+func (p headerMappedParser) Parse(sheet SheetData) (*domain.DailyReport, error) {
+	return p.ParseRows(sheet.Iter())
+}
+
+// ParseRows reads rows up to the header, maps its columns and parses the rows
+// below it as they arrive
+func (headerMappedParser) ParseRows(rows RowIterator) (*domain.DailyReport, error) {
+	var trades *tradeRowParser
+	for i := 0; rows.Next(); i++ {
+		row := rows.Row()
+		if trades != nil {
+			trades.add(i, row)
 			continue
 		}
 
-		// Skip sector headers (merged cells or rows containing "Sector")
-		if strings.Contains(row[0], "Sector") || strings.Contains(row[0], "Total") {
-			fmt.Printf("  -> Skipped: Sector/Total row\n")
-			continue
+		// Print first 20 rows to understand the structure
+		if i < 20 {
+			slog.Debug("Row data", slog.Int("row_number", i), slog.Any("content", row))
 		}
 
-		// Skip if code column is empty (likely a merged/header row)
-		if columnMap["code"] < len(row) && strings.TrimSpace(row[columnMap["code"]]) == "" {
-			fmt.Printf("  -> Skipped: Empty code column\n")
+		if !isHeaderRow(row) {
 			continue
 		}
+		columnMap := mapHeaderColumns(row)
+		slog.Info("*** FOUND HEADER ROW ***", slog.Int("row_number", i))
+		fmt.Printf("Final column mapping: %+v\n", columnMap)
+
+		// Verify we found all required columns
+		for _, col := range requiredColumns {
+			if _, exists := columnMap[col]; !exists {
+				return nil, fmt.Errorf("could not find required column: %s", col)
+			}
+		}
+		trades = newTradeRowParser(columnMap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if trades == nil {
+		return nil, fmt.Errorf("could not find header row in trading data")
+	}
 
-		// Extract data using dynamic column mapping
-		companyCode := strings.TrimSpace(row[columnMap["code"]])
-		if companyCode == "" {
-			fmt.Printf("  -> Skipped: Empty company code after trim\n")
-			continue
+	return &domain.DailyReport{Records: trades.finish()}, nil
+}
+
+// findHeaderRow returns the index of the first row that looks like a trading
+// data header and the column positions it names, or -1 if there is none
+func findHeaderRow(rows [][]string) (int, map[string]int) {
+	for i, row := range rows {
+		if isHeaderRow(row) {
+			return i, mapHeaderColumns(row)
 		}
+	}
+	return -1, nil
+}
 
-		slog.Info("Processing company", slog.String("code", companyCode))
-		
-		// Debug logging for BBOB specifically
-		if companyCode == "BBOB" {
-			slog.Info("BBOB Row Data Debug")
-			for colName, colIdx := range columnMap {
-				if colIdx < len(row) {
-					slog.Info("BBOB column value", 
-						slog.String("column", colName), 
-						slog.Int("index", colIdx), 
-						slog.String("value", row[colIdx]))
-				}
-			}
+// isHeaderRow reports whether a row names the key trading data columns
+func isHeaderRow(row []string) bool {
+	if len(row) < 5 {
+		return false
+	}
+
+	// Look for header row containing key column names
+	rowText := strings.ToLower(strings.Join(row, " "))
+
+	// More flexible header detection - look for key trading columns
+	return (strings.Contains(rowText, "company") || strings.Contains(rowText, "name")) &&
+		strings.Contains(rowText, "code") &&
+		(strings.Contains(rowText, "closing") || strings.Contains(rowText, "price")) &&
+		strings.Contains(rowText, "volume")
+}
+
+// mapHeaderColumns maps the different variations of column names to field keys
+func mapHeaderColumns(row []string) map[string]int {
+	columnMap := make(map[string]int)
+	for j, header := range row {
+		headerLower := strings.ToLower(strings.TrimSpace(header))
+
+		switch {
+		case strings.Contains(headerLower, "company") || (strings.Contains(headerLower, "name") && !strings.Contains(headerLower, "code")):
+			columnMap["company"] = j
+		case headerLower == "code":
+			columnMap["code"] = j
+		case strings.Contains(headerLower, "opening") && strings.Contains(headerLower, "price"):
+			columnMap["open"] = j
+		case strings.Contains(headerLower, "highest") && strings.Contains(headerLower, "price"):
+			columnMap["high"] = j
+		case strings.Contains(headerLower, "lowest") && strings.Contains(headerLower, "price"):
+			columnMap["low"] = j
+		case strings.Contains(headerLower, "average") && strings.Contains(headerLower, "price") && !strings.Contains(headerLower, "prev"):
+			columnMap["avg"] = j
+		case strings.Contains(headerLower, "prev") && strings.Contains(headerLower, "average"):
+			columnMap["prev_avg"] = j
+		case strings.Contains(headerLower, "closing") && strings.Contains(headerLower, "price") && !strings.Contains(headerLower, "prev"):
+			columnMap["close"] = j
+		case strings.Contains(headerLower, "prev") && strings.Contains(headerLower, "closing"):
+			columnMap["prev_close"] = j
+		case strings.Contains(headerLower, "change") && strings.Contains(headerLower, "%"):
+			columnMap["change_pct"] = j
+		case strings.Contains(headerLower, "no") && strings.Contains(headerLower, "trades"):
+			columnMap["num_trades"] = j
+		case headerLower == "traded volume":
+			columnMap["volume"] = j
+		case headerLower == "traded value":
+			columnMap["value"] = j
 		}
+	}
+	return columnMap
+}
 
-		// Helper function to safely parse float
-		parseFloat := func(colName string) float64 {
-			if idx, exists := columnMap[colName]; exists && idx < len(row) {
-				val, _ := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(row[idx]), ",", ""), 64)
-				return val
-			}
-			return 0.0
+// tradeRowParser extracts trade records from the data rows of a sheet as they
+// are read, skipping sector, total and empty rows. Rows after the last row
+// with data in more than five cells are ignored; short rows are therefore
+// held back until a later data row shows they are not trailing notes.
+type tradeRowParser struct {
+	columnMap map[string]int
+	records   []domain.TradeRecord
+	pending   []pendingRow
+	sawData   bool
+}
+
+type pendingRow struct {
+	index int
+	cells []string
+}
+
+func newTradeRowParser(columnMap map[string]int) *tradeRowParser {
+	return &tradeRowParser{columnMap: columnMap}
+}
+
+// add feeds the row at index i to the parser
+func (p *tradeRowParser) add(i int, row []string) {
+	if !isDataRow(row) {
+		p.pending = append(p.pending, pendingRow{index: i, cells: row})
+		return
+	}
+	p.sawData = true
+	for _, r := range p.pending {
+		p.parse(r.index, r.cells)
+	}
+	p.pending = p.pending[:0]
+	p.parse(i, row)
+}
+
+// finish parses the held-back rows of a sheet without any long data row and
+// returns the records
+func (p *tradeRowParser) finish() []domain.TradeRecord {
+	if !p.sawData {
+		for _, r := range p.pending {
+			p.parse(r.index, r.cells)
 		}
+	}
+	p.pending = nil
+	return p.records
+}
 
-		// Helper function to safely parse int
-		parseInt := func(colName string) int64 {
-			if idx, exists := columnMap[colName]; exists && idx < len(row) {
-				val, _ := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(row[idx]), ",", ""), 10, 64)
-				return val
-			}
-			return 0
+// isDataRow reports whether a row has more than five cells and any of them
+// holds data
+func isDataRow(row []string) bool {
+	if len(row) <= 5 {
+		return false
+	}
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return true
 		}
+	}
+	return false
+}
+
+// parse converts one row into a trade record
+func (p *tradeRowParser) parse(i int, row []string) {
+	slog.Info("Processing row", slog.Int("row_number", i), slog.Any("content", row))
+
+	// Skip if not enough columns
+	if len(row) <= p.columnMap["value"] {
+		slog.Info("Skipped row - insufficient columns",
+			slog.Int("needed", p.columnMap["value"]+1),
+			slog.Int("got", len(row)))
+		return
+	}
+
+	// Skip empty rows - check if all relevant columns are empty
+	isEmpty := true
+	for _, colIndex := range p.columnMap {
+		if colIndex < len(row) && strings.TrimSpace(row[colIndex]) != "" {
+			isEmpty = false
+			break
+		}
+	}
+	if isEmpty {
+		fmt.Printf("  -> Skipped: Empty row\n")
+		return
+	}
+
+	// Skip sector headers (merged cells or rows containing "Sector")
+	if strings.Contains(row[0], "Sector") || strings.Contains(row[0], "Total") {
+		fmt.Printf("  -> Skipped: Sector/Total row\n")
+		return
+	}
 
-		// Helper function to safely get string
-		getString := func(colName string) string {
-			if idx, exists := columnMap[colName]; exists && idx < len(row) {
-				return strings.TrimSpace(row[idx])
+	// Skip if code column is empty (likely a merged/header row)
+	if p.columnMap["code"] < len(row) && strings.TrimSpace(row[p.columnMap["code"]]) == "" {
+		fmt.Printf("  -> Skipped: Empty code column\n")
+		return
+	}
+
+	// Extract data using dynamic column mapping
+	companyCode := strings.TrimSpace(row[p.columnMap["code"]])
+	if companyCode == "" {
+		fmt.Printf("  -> Skipped: Empty company code after trim\n")
+		return
+	}
+
+	slog.Info("Processing company", slog.String("code", companyCode))
+	
+	// Debug logging for BBOB specifically
+	if companyCode == "BBOB" {
+		slog.Info("BBOB Row Data Debug")
+		for colName, colIdx := range p.columnMap {
+			if colIdx < len(row) {
+				slog.Info("BBOB column value", 
+					slog.String("column", colName), 
+					slog.Int("index", colIdx), 
+					slog.String("value", row[colIdx]))
 			}
-			return ""
 		}
+	}
 
-		// Extract all available fields
-		companyName := getString("company")
-		openPrice := parseFloat("open")
-		highPrice := parseFloat("high")
-		lowPrice := parseFloat("low")
-		avgPrice := parseFloat("avg")
-		prevAvgPrice := parseFloat("prev_avg")
-		closePrice := parseFloat("close")
-		prevClosePrice := parseFloat("prev_close")
-		changePercent := parseFloat("change_pct")
-		numTrades := parseInt("num_trades")
-		volume := parseInt("volume")
-		value := parseFloat("value")
-
-		// Calculate change if not available
-		change := closePrice - prevClosePrice
-
-		record := domain.TradeRecord{
-			CompanyName:      companyName,
-			CompanySymbol:    companyCode,
-			Date:             date,
-			OpenPrice:        openPrice,
-			HighPrice:        highPrice,
-			LowPrice:         lowPrice,
-			AveragePrice:     avgPrice,
-			PrevAveragePrice: prevAvgPrice,
-			ClosePrice:       closePrice,
-			PrevClosePrice:   prevClosePrice,
-			Change:           change,
-			ChangePercent:    changePercent,
-			NumTrades:        numTrades,
-			Volume:           volume,
-			Value:            value,
-			TradingStatus:    true, // Actual trading data
+	// Helper function to safely parse float
+	parseFloat := func(colName string) float64 {
+		if idx, exists := p.columnMap[colName]; exists && idx < len(row) {
+			val, _ := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(row[idx]), ",", ""), 64)
+			return val
 		}
-		report.Records = append(report.Records, record)
-
-		// Debug: Show first few records
-		if len(report.Records) <= 5 {
-			slog.Debug("Record parsed", 
-				slog.Int("record_number", len(report.Records)),
-				slog.String("company_code", companyCode),
-				slog.String("company_name", companyName),
-				slog.Float64("open_price", openPrice),
-				slog.Float64("high_price", highPrice),
-				slog.Float64("low_price", lowPrice),
-				slog.Float64("close_price", closePrice),
-				slog.Int64("volume", volume),
-				slog.Float64("value", value))
+		return 0.0
+	}
+
+	// Helper function to safely parse int
+	parseInt := func(colName string) int64 {
+		if idx, exists := p.columnMap[colName]; exists && idx < len(row) {
+			val, _ := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(row[idx]), ",", ""), 10, 64)
+			return val
 		}
+		return 0
 	}
 
-	slog.Info("Processing complete", slog.Int("total_records", len(report.Records)))
+	// Helper function to safely get string
+	getString := func(colName string) string {
+		if idx, exists := p.columnMap[colName]; exists && idx < len(row) {
+			return strings.TrimSpace(row[idx])
+		}
+		return ""
+	}
 
-	return report, nil
+	// Extract all available fields
+	companyName := getString("company")
+	openPrice := parseFloat("open")
+	highPrice := parseFloat("high")
+	lowPrice := parseFloat("low")
+	avgPrice := parseFloat("avg")
+	prevAvgPrice := parseFloat("prev_avg")
+	closePrice := parseFloat("close")
+	prevClosePrice := parseFloat("prev_close")
+	changePercent := parseFloat("change_pct")
+	numTrades := parseInt("num_trades")
+	volume := parseInt("volume")
+	value := parseFloat("value")
+
+	// Calculate change if not available
+	change := closePrice - prevClosePrice
+
+	record := domain.TradeRecord{
+		CompanyName:      companyName,
+		CompanySymbol:    companyCode,
+		OpenPrice:        openPrice,
+		HighPrice:        highPrice,
+		LowPrice:         lowPrice,
+		AveragePrice:     avgPrice,
+		PrevAveragePrice: prevAvgPrice,
+		ClosePrice:       closePrice,
+		PrevClosePrice:   prevClosePrice,
+		Change:           change,
+		ChangePercent:    changePercent,
+		NumTrades:        numTrades,
+		Volume:           volume,
+		Value:            value,
+		TradingStatus:    true, // Actual trading data
+	}
+	p.records = append(p.records, record)
+
+	// Debug: Show first few records
+	if len(p.records) <= 5 {
+		slog.Debug("Record parsed", 
+			slog.Int("record_number", len(p.records)),
+			slog.String("company_code", companyCode),
+			slog.String("company_name", companyName),
+			slog.Float64("open_price", openPrice),
+			slog.Float64("high_price", highPrice),
+			slog.Float64("low_price", lowPrice),
+			slog.Float64("close_price", closePrice),
+			slog.Int64("volume", volume),
+			slog.Float64("value", value))
+	}
 }
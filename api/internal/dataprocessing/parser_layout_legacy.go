@@ -0,0 +1,81 @@
+package dataprocessing
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+func init() {
+	RegisterParser(legacyFixedParser{})
+}
+
+// legacyColumnMap holds the fixed column positions of older bulletins that have
+// no usable header row. Only columns known to be stable across those files are
+// mapped; the others are left at zero.
+var legacyColumnMap = map[string]int{
+	"company": 0,
+	"code":    1,
+	"close":   8,
+	"volume":  12,
+	"value":   13,
+}
+
+// tickerCodePattern matches ISX ticker codes such as BBOB or TASC
+var tickerCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// legacyFixedParser handles older header-less bulletins where data sits at
+// fixed column positions below a few title rows
+type legacyFixedParser struct{}
+
+func (legacyFixedParser) Name() string { return "legacy-fixed" }
+
+func (legacyFixedParser) Version() int { return 1 }
+
+// Detect accepts sheets without a trading header that contain at least one row
+// with a ticker code and numeric close, volume and value at the legacy positions
+func (legacyFixedParser) Detect(sheet SheetData) int {
+	if headerRow, _ := findHeaderRow(sheet.Rows); headerRow >= 0 {
+		return 0
+	}
+	for _, row := range sheet.Rows {
+		if isLegacyDataRow(row) {
+			return 50
+		}
+	}
+	return 0
+}
+
+func (p legacyFixedParser) Parse(sheet SheetData) (*domain.DailyReport, error) {
+	return p.ParseRows(sheet.Iter())
+}
+
+// ParseRows parses every row at the legacy column positions as it arrives
+func (legacyFixedParser) ParseRows(rows RowIterator) (*domain.DailyReport, error) {
+	trades := newTradeRowParser(legacyColumnMap)
+	for i := 0; rows.Next(); i++ {
+		trades.add(i, rows.Row())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &domain.DailyReport{Records: trades.finish()}, nil
+}
+
+func isLegacyDataRow(row []string) bool {
+	if len(row) <= legacyColumnMap["value"] {
+		return false
+	}
+	if !tickerCodePattern.MatchString(strings.TrimSpace(row[legacyColumnMap["code"]])) {
+		return false
+	}
+	for _, col := range []string{"close", "volume", "value"} {
+		cell := strings.ReplaceAll(strings.TrimSpace(row[legacyColumnMap[col]]), ",", "")
+		if _, err := strconv.ParseFloat(cell, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
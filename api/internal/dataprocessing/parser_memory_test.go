@@ -0,0 +1,187 @@
+package dataprocessing
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Defaults of the ParseFile memory guard. ISX_PARSE_CORPUS_FILES and
+// ISX_PARSE_MEMORY_CAP_MB override them, e.g. to check a larger corpus.
+const (
+	defaultParseCorpusFiles = 1000
+	defaultParseMemoryCapMB = 32
+)
+
+// writeBulletinWorkbook saves a daily report with a header-mapped trading
+// sheet of the given number of tickers and a bulky second sheet, like the
+// index and announcement sheets of real bulletins
+func writeBulletinWorkbook(t testing.TB, path string, tickers, extraRows int) {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetName(f.GetSheetName(0), "Bulletin")
+
+	set := func(sheet string, row int, values []interface{}) {
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		require.NoError(t, f.SetSheetRow(sheet, cell, &values))
+	}
+
+	set("Bulletin", 1, []interface{}{"Iraq Stock Exchange"})
+	set("Bulletin", 2, []interface{}{"Company Name", "Code", "Opening Price", "Highest Price", "Lowest Price", "Closing Price", "Prev Closing Price", "No. of Trades", "Traded Volume", "Traded Value"})
+	set("Bulletin", 3, []interface{}{"Banking Sector"})
+	for i := 0; i < tickers; i++ {
+		price := 1 + float64(i%50)/100
+		set("Bulletin", i+4, []interface{}{
+			fmt.Sprintf("Company %d", i), fmt.Sprintf("TK%03d", i),
+			price, price + 0.05, price - 0.05, price, price - 0.01,
+			i + 1, strconv.Itoa((i + 1) * 10000), strconv.Itoa((i + 1) * 10500),
+		})
+	}
+	set("Bulletin", tickers+5, []interface{}{"Total", "", "", "", "", "", "", "", "", ""})
+
+	_, err := f.NewSheet("Indices")
+	require.NoError(t, err)
+	for i := 1; i <= extraRows; i++ {
+		set("Indices", i, []interface{}{"ISX60", i, 900.5 + float64(i), "announcement text for the session", i * 3})
+	}
+
+	require.NoError(t, f.SaveAs(path))
+}
+
+// silenceParserLogs discards the parser's per-row logging for the test
+func silenceParserLogs(t testing.TB) {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+}
+
+func envInt(t testing.TB, name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	require.NoError(t, err, name)
+	return n
+}
+
+// heapSampler records the peak heap in use above a baseline
+type heapSampler struct {
+	baseline uint64
+	peak     uint64
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func startHeapSampler(interval time.Duration) *heapSampler {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	s := &heapSampler{baseline: ms.HeapInuse, stop: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.sample()
+			}
+		}
+	}()
+	return s
+}
+
+func (s *heapSampler) sample() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	if ms.HeapInuse > s.baseline && ms.HeapInuse-s.baseline > s.peak {
+		s.peak = ms.HeapInuse - s.baseline
+	}
+}
+
+// Stop ends sampling and returns the peak in MB
+func (s *heapSampler) Stop() float64 {
+	close(s.stop)
+	s.wg.Wait()
+	s.sample()
+	return float64(s.peak) / (1 << 20)
+}
+
+// BenchmarkParseFile_MemoryGuard parses a multi-year corpus file by file, as
+// a full reprocess does, and fails when the heap grows beyond the cap:
+//
+//	go test ./internal/dataprocessing -run '^$' -bench MemoryGuard -benchtime 1x
+func BenchmarkParseFile_MemoryGuard(b *testing.B) {
+	silenceParserLogs(b)
+
+	files := envInt(b, "ISX_PARSE_CORPUS_FILES", defaultParseCorpusFiles)
+	capMB := envInt(b, "ISX_PARSE_MEMORY_CAP_MB", defaultParseMemoryCapMB)
+
+	dir := b.TempDir()
+	template := filepath.Join(dir, "template.xlsx")
+	writeBulletinWorkbook(b, template, 120, 1000)
+	data, err := os.ReadFile(template)
+	require.NoError(b, err)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	paths := make([]string, files)
+	for i := range paths {
+		name := start.AddDate(0, 0, i).Format("2006 01 02") + DailyReportFileSuffix
+		paths[i] = filepath.Join(dir, name)
+		require.NoError(b, os.WriteFile(paths[i], data, 0644))
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		sampler := startHeapSampler(5 * time.Millisecond)
+		records := 0
+		for _, path := range paths {
+			report, err := ParseFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			records += len(report.Records)
+		}
+		peakMB := sampler.Stop()
+
+		b.ReportMetric(peakMB, "peak-MB")
+		if records != files*120 {
+			b.Fatalf("parsed %d records from %d files, want %d", records, files, files*120)
+		}
+		if peakMB > float64(capMB) {
+			b.Fatalf("ParseFile heap peaked at %.1f MB over %d files, above the %d MB cap", peakMB, files, capMB)
+		}
+	}
+}
+
+func BenchmarkParseFile(b *testing.B) {
+	silenceParserLogs(b)
+
+	path := filepath.Join(b.TempDir(), "2024 01 15"+DailyReportFileSuffix)
+	writeBulletinWorkbook(b, path, 120, 1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package dataprocessing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// ErrNoMatchingParser is returned when no registered layout recognises any sheet
+var ErrNoMatchingParser = errors.New("no parser recognises the report layout")
+
+// DetectSampleRows is the number of leading rows of each sheet ParseFile reads
+// for layout detection. The remaining rows are only read from the chosen sheet.
+const DetectSampleRows = 100
+
+// SheetData is one worksheet of a daily report workbook
+type SheetData struct {
+	Name string
+	Rows [][]string
+}
+
+// Iter returns a RowIterator over the rows held in memory
+func (s SheetData) Iter() RowIterator {
+	return &sliceRowIterator{rows: s.Rows, pos: -1}
+}
+
+// RowIterator yields the rows of a worksheet in order
+type RowIterator interface {
+	// Next advances to the next row and reports whether there is one
+	Next() bool
+	// Row returns the cells of the current row
+	Row() []string
+	// Err returns the error that stopped the iteration, if any
+	Err() error
+}
+
+type sliceRowIterator struct {
+	rows [][]string
+	pos  int
+}
+
+func (it *sliceRowIterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.rows)
+}
+
+func (it *sliceRowIterator) Row() []string { return it.rows[it.pos] }
+
+func (it *sliceRowIterator) Err() error { return nil }
+
+// LayoutParser parses one generation of the ISX daily report layout. Parsers
+// register themselves from init() with RegisterParser, so supporting a new
+// layout means adding a file, not changing ParseFile.
+type LayoutParser interface {
+	// Name identifies the layout in logs, e.g. "header-mapped"
+	Name() string
+
+	// Version orders layouts by age; on equal scores the newer layout wins
+	Version() int
+
+	// Detect scores how well a sheet matches the layout; 0 means no match.
+	// It must be cheap and must not log. ParseFile passes only the first
+	// DetectSampleRows rows of each sheet.
+	Detect(sheet SheetData) int
+
+	// Parse extracts trade records from a sheet Detect accepted. Dates are
+	// filled in by ParseFile from the file name.
+	Parse(sheet SheetData) (*domain.DailyReport, error)
+}
+
+// StreamingLayoutParser is a LayoutParser that turns rows into records one
+// at a time. ParseFile streams the chosen sheet of such layouts from the
+// workbook instead of loading it, so memory stays flat on large reprocessing
+// runs; other layouts get the whole sheet through Parse.
+type StreamingLayoutParser interface {
+	LayoutParser
+
+	// ParseRows parses the rows of a sheet Detect accepted
+	ParseRows(rows RowIterator) (*domain.DailyReport, error)
+}
+
+var (
+	parserRegistryMu sync.RWMutex
+	parserRegistry   = map[string]LayoutParser{}
+)
+
+// RegisterParser adds a layout parser. It panics if the name is already taken,
+// mirroring database/sql driver registration.
+func RegisterParser(p LayoutParser) {
+	parserRegistryMu.Lock()
+	defer parserRegistryMu.Unlock()
+
+	if p == nil {
+		panic("dataprocessing: RegisterParser parser is nil")
+	}
+	if _, dup := parserRegistry[p.Name()]; dup {
+		panic("dataprocessing: RegisterParser called twice for parser " + p.Name())
+	}
+	parserRegistry[p.Name()] = p
+}
+
+// Parsers returns the registered layout parsers, newest version first
+func Parsers() []LayoutParser {
+	parserRegistryMu.RLock()
+	defer parserRegistryMu.RUnlock()
+
+	parsers := make([]LayoutParser, 0, len(parserRegistry))
+	for _, p := range parserRegistry {
+		parsers = append(parsers, p)
+	}
+	sort.Slice(parsers, func(i, j int) bool {
+		if parsers[i].Version() != parsers[j].Version() {
+			return parsers[i].Version() > parsers[j].Version()
+		}
+		return parsers[i].Name() < parsers[j].Name()
+	})
+	return parsers
+}
+
+// SelectParser picks the sheet and parser with the highest detection score.
+// Sheets are tried in the given order, so earlier sheets win ties.
+func SelectParser(sheets []SheetData) (LayoutParser, SheetData, error) {
+	var (
+		best      LayoutParser
+		bestSheet SheetData
+		bestScore int
+	)
+
+	for _, sheet := range sheets {
+		for _, p := range Parsers() {
+			if score := p.Detect(sheet); score > bestScore {
+				best, bestSheet, bestScore = p, sheet, score
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, SheetData{}, fmt.Errorf("%w (%d sheets checked)", ErrNoMatchingParser, len(sheets))
+	}
+	return best, bestSheet, nil
+}
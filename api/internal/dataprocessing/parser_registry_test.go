@@ -0,0 +1,144 @@
+package dataprocessing
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+var headerMappedRows = [][]string{
+	{"Iraq Stock Exchange"},
+	{"Company Name", "Code", "Opening Price", "Highest Price", "Lowest Price", "Closing Price", "Prev Closing Price", "No. of Trades", "Traded Volume", "Traded Value"},
+	{"Banking Sector"},
+	{"Bank of Baghdad", "BBOB", "1.00", "1.10", "0.99", "1.05", "1.00", "12", "1,000,000", "1,050,000"},
+}
+
+func TestSelectParser_PrefersHeaderMapped(t *testing.T) {
+	sheets := []SheetData{
+		{Name: "Cover", Rows: [][]string{{"Daily Bulletin"}}},
+		{Name: "Bulletin", Rows: headerMappedRows},
+	}
+
+	parser, sheet, err := SelectParser(sheets)
+	require.NoError(t, err)
+	assert.Equal(t, "header-mapped", parser.Name())
+	assert.Equal(t, "Bulletin", sheet.Name)
+
+	report, err := parser.Parse(sheet)
+	require.NoError(t, err)
+	require.Len(t, report.Records, 1)
+	assert.Equal(t, "BBOB", report.Records[0].CompanySymbol)
+	assert.Equal(t, 1.05, report.Records[0].ClosePrice)
+	assert.Equal(t, int64(1000000), report.Records[0].Volume)
+}
+
+func TestSelectParser_Legacy(t *testing.T) {
+	row := make([]string, 14)
+	row[0], row[1], row[8], row[12], row[13] = "Asia Cell", "TASC", "8.5", "500", "4,250"
+	sheets := []SheetData{{Name: "Sheet1", Rows: [][]string{{"title"}, {"subtitle"}, row}}}
+
+	parser, sheet, err := SelectParser(sheets)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-fixed", parser.Name())
+
+	report, err := parser.Parse(sheet)
+	require.NoError(t, err)
+	require.Len(t, report.Records, 1)
+	assert.Equal(t, "TASC", report.Records[0].CompanySymbol)
+	assert.Equal(t, 4250.0, report.Records[0].Value)
+}
+
+func TestSelectParser_NoMatch(t *testing.T) {
+	_, _, err := SelectParser([]SheetData{{Name: "Notes", Rows: [][]string{{"nothing here"}}}})
+	assert.True(t, errors.Is(err, ErrNoMatchingParser))
+}
+
+// stubLayoutParser claims every sheet with a fixed score
+type stubLayoutParser struct {
+	score int
+}
+
+func (stubLayoutParser) Name() string                 { return "test-stub" }
+func (stubLayoutParser) Version() int                 { return 99 }
+func (p stubLayoutParser) Detect(sheet SheetData) int { return p.score }
+func (stubLayoutParser) Parse(sheet SheetData) (*domain.DailyReport, error) {
+	return &domain.DailyReport{Records: []domain.TradeRecord{{CompanySymbol: "STUB"}}}, nil
+}
+
+func TestRegisterParser_NewLayoutWithoutCoreChanges(t *testing.T) {
+	RegisterParser(stubLayoutParser{score: 200})
+	t.Cleanup(func() {
+		parserRegistryMu.Lock()
+		delete(parserRegistry, "test-stub")
+		parserRegistryMu.Unlock()
+	})
+
+	assert.Panics(t, func() { RegisterParser(stubLayoutParser{}) }, "duplicate names are rejected")
+	assert.Equal(t, "test-stub", Parsers()[0].Name(), "newest version is listed first")
+
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), "Bulletin")
+	for i, row := range headerMappedRows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		values := make([]interface{}, len(row))
+		for j, v := range row {
+			values[j] = v
+		}
+		require.NoError(t, f.SetSheetRow("Bulletin", cell, &values))
+	}
+	path := filepath.Join(t.TempDir(), "2025 01 05 ISX Daily Report.xlsx")
+	require.NoError(t, f.SaveAs(path))
+
+	report, err := ParseFile(path)
+	require.NoError(t, err)
+	require.Len(t, report.Records, 1)
+	assert.Equal(t, "STUB", report.Records[0].CompanySymbol)
+	assert.Equal(t, "2025-01-05", report.Records[0].Date.Format("2006-01-02"), "date comes from the file name")
+}
+
+func TestParseFile_StreamsChosenSheet(t *testing.T) {
+	silenceParserLogs(t)
+	path := filepath.Join(t.TempDir(), "2025 01 06"+DailyReportFileSuffix)
+	writeBulletinWorkbook(t, path, 3, 500)
+
+	report, err := ParseFile(path)
+	require.NoError(t, err)
+	require.Len(t, report.Records, 3, "the total row is skipped")
+	assert.Equal(t, "TK002", report.Records[2].CompanySymbol)
+	assert.Equal(t, int64(30000), report.Records[2].Volume)
+	assert.Equal(t, "2025-01-06", report.Records[0].Date.Format("2006-01-02"))
+
+	for _, p := range Parsers() {
+		_, ok := p.(StreamingLayoutParser)
+		assert.True(t, ok, "built-in layout %s streams rows", p.Name())
+	}
+}
+
+func TestTradeRowParser_StopsAtLastDataRow(t *testing.T) {
+	columnMap := map[string]int{"code": 0, "close": 1, "volume": 2, "value": 3}
+	rows := [][]string{
+		{"BBOB", "1.05", "1000", "1050", "", "x"},
+		{"TASC", "7.10", "200", "1420"},
+		{"BMNS", "0.50", "300", "150", "", "x"},
+		{"NOTE", "1", "2", "3"},
+	}
+
+	p := newTradeRowParser(columnMap)
+	for i, row := range rows {
+		p.add(i, row)
+	}
+	records := p.finish()
+
+	require.Len(t, records, 3, "short rows count only when a longer data row follows")
+	assert.Equal(t, []string{"BBOB", "TASC", "BMNS"}, []string{records[0].CompanySymbol, records[1].CompanySymbol, records[2].CompanySymbol})
+
+	report, err := legacyFixedParser{}.Parse(SheetData{Rows: [][]string{{"Title"}}})
+	require.NoError(t, err)
+	assert.Empty(t, report.Records)
+}
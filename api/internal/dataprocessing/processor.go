@@ -1,6 +1,7 @@
 package dataprocessing
 
 import (
+	"math"
 	"sort"
 	"time"
 
@@ -8,15 +9,35 @@ import (
 )
 
 // ForwardFillProcessor handles forward-fill operations for missing trading data
-type ForwardFillProcessor struct{}
+type ForwardFillProcessor struct {
+	strategy FillStrategy
+}
 
-// NewForwardFillProcessor creates a new forward-fill processor
+// NewForwardFillProcessor creates a new forward-fill processor that carries
+// the last close forward
 func NewForwardFillProcessor() *ForwardFillProcessor {
-	return &ForwardFillProcessor{}
+	return NewForwardFillProcessorWithStrategy(FillCarryForward)
+}
+
+// NewForwardFillProcessorWithStrategy creates a processor using the given
+// fill strategy. An empty strategy carries the last close forward.
+func NewForwardFillProcessorWithStrategy(strategy FillStrategy) *ForwardFillProcessor {
+	if strategy == "" {
+		strategy = FillCarryForward
+	}
+	return &ForwardFillProcessor{strategy: strategy}
 }
 
-// FillMissingData fills in missing trading data for symbols that don't trade on certain days
-// It uses the last known trading data to fill gaps, marking filled records with TradingStatus=false
+// Strategy returns the fill strategy in use
+func (f *ForwardFillProcessor) Strategy() FillStrategy {
+	return f.strategy
+}
+
+// FillMissingData fills in missing trading data for symbols that don't trade on certain days.
+// Filled records have TradingStatus=false and FillMethod set to the strategy
+// that produced them; traded records get FillMethod=actual. Records already
+// marked as filled are dropped and refilled, so rerunning with another
+// strategy replaces them. Output is ordered by date, then symbol.
 func (f *ForwardFillProcessor) FillMissingData(records []domain.TradeRecord) []domain.TradeRecord {
 	if len(records) == 0 {
 		return records
@@ -28,6 +49,10 @@ func (f *ForwardFillProcessor) FillMissingData(records []domain.TradeRecord) []d
 	allDates := make(map[string]bool)
 
 	for _, record := range records {
+		if isFilledRecord(record) {
+			continue
+		}
+		record.FillMethod = domain.FillMethodActual
 		dateStr := record.Date.Format("2006-01-02")
 		symbol := record.CompanySymbol
 
@@ -43,12 +68,23 @@ func (f *ForwardFillProcessor) FillMissingData(records []domain.TradeRecord) []d
 	dates := f.getSortedKeys(allDates)
 	symbols := f.getSortedKeys(allSymbols)
 
+	// Date positions of each symbol's traded days, for interpolation
+	tradedAt := make(map[string][]int)
+	for i, dateStr := range dates {
+		for symbol := range symbolsByDate[dateStr] {
+			tradedAt[symbol] = append(tradedAt[symbol], i)
+		}
+	}
+
 	// Keep track of last known data for each symbol
 	lastKnownData := make(map[string]domain.TradeRecord)
+	lastKnownAt := make(map[string]int)
+	lastEmitted := make(map[string]domain.TradeRecord)
+	nextTraded := make(map[string]int) // Index into tradedAt[symbol]
 
 	var result []domain.TradeRecord
 
-	for _, dateStr := range dates {
+	for i, dateStr := range dates {
 		date, _ := time.Parse("2006-01-02", dateStr)
 		dayRecords := symbolsByDate[dateStr]
 
@@ -57,19 +93,55 @@ func (f *ForwardFillProcessor) FillMissingData(records []domain.TradeRecord) []d
 				// Symbol traded on this day - use actual data
 				result = append(result, record)
 				lastKnownData[symbol] = record
-			} else if lastRecord, hasHistory := lastKnownData[symbol]; hasHistory {
-				// Symbol didn't trade - forward fill from last known data
-				filledRecord := f.createFilledRecord(lastRecord, symbol, date)
-				result = append(result, filledRecord)
-				// Don't update lastKnownData since this is filled data
+				lastKnownAt[symbol] = i
+				lastEmitted[symbol] = record
+				nextTraded[symbol]++
+				continue
+			}
+
+			lastRecord, hasHistory := lastKnownData[symbol]
+			if !hasHistory || f.strategy == FillNone {
+				// If no history exists, skip this symbol for this date
+				continue
 			}
-			// If no history exists, skip this symbol for this date
+
+			var filledRecord domain.TradeRecord
+			switch f.strategy {
+			case FillNaN:
+				filledRecord = f.createNaNRecord(lastRecord, symbol, date)
+			case FillInterpolate:
+				if n := nextTraded[symbol]; n < len(tradedAt[symbol]) {
+					nextAt := tradedAt[symbol][n]
+					next := symbolsByDate[dates[nextAt]][symbol]
+					weight := float64(i-lastKnownAt[symbol]) / float64(nextAt-lastKnownAt[symbol])
+					level := lastRecord.ClosePrice + (next.ClosePrice-lastRecord.ClosePrice)*weight
+					filledRecord = f.createInterpolatedRecord(lastEmitted[symbol], symbol, date, level)
+					break
+				}
+				// Nothing to interpolate towards after the last traded day
+				filledRecord = f.createFilledRecord(lastRecord, symbol, date)
+			default:
+				filledRecord = f.createFilledRecord(lastRecord, symbol, date)
+			}
+			result = append(result, filledRecord)
+			lastEmitted[symbol] = filledRecord
+			// Don't update lastKnownData since this is filled data
 		}
 	}
 
 	return result
 }
 
+// isFilledRecord reports whether a record was synthesized by a previous
+// fill. Records from CSVs written before FillMethod existed are filled when
+// TradingStatus is false, since parsed reports always set it.
+func isFilledRecord(record domain.TradeRecord) bool {
+	if record.FillMethod != "" {
+		return record.FillMethod != domain.FillMethodActual
+	}
+	return !record.TradingStatus
+}
+
 // createFilledRecord creates a forward-filled record based on the last known data
 func (f *ForwardFillProcessor) createFilledRecord(lastRecord domain.TradeRecord, symbol string, date time.Time) domain.TradeRecord {
 	return domain.TradeRecord{
@@ -89,9 +161,56 @@ func (f *ForwardFillProcessor) createFilledRecord(lastRecord domain.TradeRecord,
 		Volume:           0,                       // No volume
 		Value:            0.0,                     // No value
 		TradingStatus:    false,                   // Forward-filled data
+		FillMethod:       domain.FillMethodCarryForward,
+	}
+}
+
+// createNaNRecord creates a gap record whose prices are NaN; only the
+// previous close and average are known
+func (f *ForwardFillProcessor) createNaNRecord(lastRecord domain.TradeRecord, symbol string, date time.Time) domain.TradeRecord {
+	nan := math.NaN()
+	return domain.TradeRecord{
+		CompanyName:      lastRecord.CompanyName,
+		CompanySymbol:    symbol,
+		Date:             date,
+		OpenPrice:        nan,
+		HighPrice:        nan,
+		LowPrice:         nan,
+		AveragePrice:     nan,
+		PrevAveragePrice: lastRecord.AveragePrice,
+		ClosePrice:       nan,
+		PrevClosePrice:   lastRecord.ClosePrice,
+		Change:           nan,
+		ChangePercent:    nan,
+		TradingStatus:    false,
+		FillMethod:       domain.FillMethodNaN,
 	}
 }
 
+// createInterpolatedRecord creates a gap record at an interpolated close.
+// Change is measured against the previous row, filled or traded.
+func (f *ForwardFillProcessor) createInterpolatedRecord(prev domain.TradeRecord, symbol string, date time.Time, level float64) domain.TradeRecord {
+	record := domain.TradeRecord{
+		CompanyName:      prev.CompanyName,
+		CompanySymbol:    symbol,
+		Date:             date,
+		OpenPrice:        level,
+		HighPrice:        level,
+		LowPrice:         level,
+		AveragePrice:     level,
+		PrevAveragePrice: prev.AveragePrice,
+		ClosePrice:       level,
+		PrevClosePrice:   prev.ClosePrice,
+		Change:           level - prev.ClosePrice,
+		TradingStatus:    false,
+		FillMethod:       domain.FillMethodInterpolated,
+	}
+	if prev.ClosePrice != 0 {
+		record.ChangePercent = record.Change / prev.ClosePrice * 100
+	}
+	return record
+}
+
 // getSortedKeys extracts and sorts keys from a map[string]bool
 func (f *ForwardFillProcessor) getSortedKeys(m map[string]bool) []string {
 	keys := make([]string, 0, len(m))
@@ -113,21 +232,24 @@ type ForwardFillStatistics struct {
 
 // FillMissingDataWithStats performs forward-fill and returns statistics
 func (f *ForwardFillProcessor) FillMissingDataWithStats(records []domain.TradeRecord) ([]domain.TradeRecord, ForwardFillStatistics) {
-	originalCount := len(records)
 	filledRecords := f.FillMissingData(records)
 	
 	// Count unique symbols and dates
 	uniqueSymbols := make(map[string]bool)
 	uniqueDates := make(map[string]bool)
+	activeCount := 0
 	for _, record := range filledRecords {
 		uniqueSymbols[record.CompanySymbol] = true
 		uniqueDates[record.Date.Format("2006-01-02")] = true
+		if record.FillMethod == domain.FillMethodActual {
+			activeCount++
+		}
 	}
 	
 	stats := ForwardFillStatistics{
 		TotalRecords:       len(filledRecords),
-		ActiveRecords:      originalCount,
-		ForwardFilledCount: len(filledRecords) - originalCount,
+		ActiveRecords:      activeCount,
+		ForwardFilledCount: len(filledRecords) - activeCount,
 		SymbolsProcessed:   len(uniqueSymbols),
 		DatesProcessed:     len(uniqueDates),
 	}
@@ -0,0 +1,120 @@
+package dataprocessing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// fillTestRecords has BBOB trading on days 1 and 4 and TASC on every day, so
+// BBOB has a two-day gap and nothing after its last trade
+func fillTestRecords() []domain.TradeRecord {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	traded := func(symbol string, d int, close float64) domain.TradeRecord {
+		return domain.TradeRecord{CompanyName: symbol + " Co", CompanySymbol: symbol, Date: day(d),
+			OpenPrice: close, AveragePrice: close, ClosePrice: close, Volume: 100, TradingStatus: true}
+	}
+	return []domain.TradeRecord{
+		traded("BBOB", 1, 1.00), traded("BBOB", 4, 1.30),
+		traded("TASC", 1, 8.0), traded("TASC", 2, 8.1), traded("TASC", 3, 8.2), traded("TASC", 4, 8.3), traded("TASC", 5, 8.4),
+	}
+}
+
+// bySymbol returns a symbol's records in date order
+func bySymbol(records []domain.TradeRecord, symbol string) []domain.TradeRecord {
+	var out []domain.TradeRecord
+	for _, r := range records {
+		if r.CompanySymbol == symbol {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func TestParseFillStrategy(t *testing.T) {
+	for name, want := range map[string]FillStrategy{
+		"":              FillCarryForward,
+		"carry-forward": FillCarryForward,
+		"none":          FillNone,
+		"NaN":           FillNaN,
+		"interpolation": FillInterpolate,
+	} {
+		got, err := ParseFillStrategy(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := ParseFillStrategy("spline")
+	assert.Error(t, err)
+}
+
+func TestFillMissingData_Strategies(t *testing.T) {
+	t.Run("carry forward", func(t *testing.T) {
+		bbob := bySymbol(NewForwardFillProcessor().FillMissingData(fillTestRecords()), "BBOB")
+		require.Len(t, bbob, 5)
+		assert.Equal(t, domain.FillMethodActual, bbob[0].FillMethod)
+		for _, i := range []int{1, 2, 4} {
+			assert.Equal(t, domain.FillMethodCarryForward, bbob[i].FillMethod)
+			assert.False(t, bbob[i].TradingStatus)
+		}
+		assert.Equal(t, 1.00, bbob[2].ClosePrice)
+		assert.Equal(t, 1.30, bbob[4].ClosePrice)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		result := NewForwardFillProcessorWithStrategy(FillNone).FillMissingData(fillTestRecords())
+		require.Len(t, result, 7)
+		for _, r := range result {
+			assert.Equal(t, domain.FillMethodActual, r.FillMethod)
+		}
+	})
+
+	t.Run("nan markers", func(t *testing.T) {
+		bbob := bySymbol(NewForwardFillProcessorWithStrategy(FillNaN).FillMissingData(fillTestRecords()), "BBOB")
+		require.Len(t, bbob, 5)
+		assert.Equal(t, domain.FillMethodNaN, bbob[1].FillMethod)
+		assert.True(t, math.IsNaN(bbob[1].ClosePrice))
+		assert.True(t, math.IsNaN(bbob[1].Change))
+		assert.Equal(t, 1.00, bbob[1].PrevClosePrice)
+		assert.Zero(t, bbob[1].Volume)
+	})
+
+	t.Run("interpolate", func(t *testing.T) {
+		bbob := bySymbol(NewForwardFillProcessorWithStrategy(FillInterpolate).FillMissingData(fillTestRecords()), "BBOB")
+		require.Len(t, bbob, 5)
+
+		assert.Equal(t, domain.FillMethodInterpolated, bbob[1].FillMethod)
+		assert.InDelta(t, 1.10, bbob[1].ClosePrice, 1e-9)
+		assert.InDelta(t, 0.10, bbob[1].Change, 1e-9)
+		assert.InDelta(t, 10.0, bbob[1].ChangePercent, 1e-9)
+		assert.InDelta(t, 1.20, bbob[2].ClosePrice, 1e-9)
+		assert.InDelta(t, 1.10, bbob[2].PrevClosePrice, 1e-9)
+		assert.Equal(t, 1.30, bbob[3].ClosePrice)
+
+		// No later trade to interpolate towards
+		assert.Equal(t, domain.FillMethodCarryForward, bbob[4].FillMethod)
+		assert.Equal(t, 1.30, bbob[4].ClosePrice)
+	})
+}
+
+func TestFillMissingData_RefillsPreviousFills(t *testing.T) {
+	carried := NewForwardFillProcessor().FillMissingData(fillTestRecords())
+
+	// Rerunning over already filled output replaces the fills
+	interpolated := NewForwardFillProcessorWithStrategy(FillInterpolate).FillMissingData(carried)
+	assert.Equal(t, NewForwardFillProcessorWithStrategy(FillInterpolate).FillMissingData(fillTestRecords()), interpolated)
+
+	// Legacy rows without a fill method are fills when not traded
+	for i := range carried {
+		carried[i].FillMethod = ""
+	}
+	result, stats := NewForwardFillProcessorWithStrategy(FillNone).FillMissingDataWithStats(carried)
+	assert.Len(t, result, 7)
+	assert.Equal(t, 7, stats.ActiveRecords)
+	assert.Zero(t, stats.ForwardFilledCount)
+}
@@ -1,6 +1,9 @@
 package dataprocessing
 
 import (
+	"fmt"
+	"strings"
+
 	"isxcli/pkg/contracts/domain"
 )
 
@@ -10,10 +13,47 @@ type Processor interface {
 	Process(records []domain.TradeRecord) ([]domain.TradeRecord, error)
 }
 
+// FillStrategy selects how days a symbol did not trade are filled
+type FillStrategy string
+
+const (
+	// FillCarryForward repeats the last close with zero volume (the default)
+	FillCarryForward FillStrategy = "carry_forward"
+	// FillNone leaves gaps empty; only traded days are written
+	FillNone FillStrategy = "none"
+	// FillNaN writes gap rows with NaN prices so consumers cannot mistake them for quotes
+	FillNaN FillStrategy = "nan"
+	// FillInterpolate interpolates the close linearly between traded days;
+	// gaps after the last traded day are carried forward
+	FillInterpolate FillStrategy = "interpolate"
+)
+
+// FillStrategies lists the supported fill strategies
+var FillStrategies = []FillStrategy{FillCarryForward, FillNone, FillNaN, FillInterpolate}
+
+// ParseFillStrategy parses a strategy name. An empty name is FillCarryForward;
+// "carry-forward" and "interpolation" are accepted as aliases.
+func ParseFillStrategy(name string) (FillStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "carry_forward", "carry-forward":
+		return FillCarryForward, nil
+	case "none", "no_fill", "no-fill":
+		return FillNone, nil
+	case "nan":
+		return FillNaN, nil
+	case "interpolate", "interpolation":
+		return FillInterpolate, nil
+	}
+	return "", fmt.Errorf("unknown fill strategy %q (want one of %v)", name, FillStrategies)
+}
+
 // ProcessingOptions configures processing behavior
 type ProcessingOptions struct {
 	// EnableForwardFill enables forward-fill for missing data
 	EnableForwardFill bool
+
+	// FillStrategy selects how missing days are filled when EnableForwardFill is set
+	FillStrategy FillStrategy
 	
 	// SkipWeekends excludes weekends from forward-fill
 	SkipWeekends bool
@@ -26,7 +66,8 @@ type ProcessingOptions struct {
 func DefaultOptions() ProcessingOptions {
 	return ProcessingOptions{
 		EnableForwardFill: true,
+		FillStrategy:      FillCarryForward,
 		SkipWeekends:      true,
 		MaxFillDays:       0, // 0 means no limit
 	}
-}
\ No newline at end of file
+}
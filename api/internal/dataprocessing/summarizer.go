@@ -9,10 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"isxcli/internal/errors"
+	"isxcli/internal/exporter"
 	"isxcli/pkg/contracts/domain"
 )
 
@@ -24,6 +26,7 @@ type Summarizer struct {
 	includeExtendedMetrics bool
 	maxLast10Days     int
 	dateFormat        string
+	export            exporter.ExportOptions
 }
 
 // SummarizerConfig holds configuration options for the Summarizer.
@@ -31,6 +34,7 @@ type SummarizerConfig struct {
 	IncludeExtendedMetrics bool // Include daily/weekly/monthly change percentages
 	MaxLast10Days         int  // Maximum number of last trading days to track
 	DateFormat            string // Format for date strings in output
+	Export                *exporter.ExportOptions // CSV number formatting; nil uses exporter defaults
 }
 
 // TickerSummary represents comprehensive summary information for a ticker.
@@ -59,6 +63,7 @@ type TickerSummary struct {
 	High52Week           float64 `json:"high_52_week,omitempty"`
 	Low52Week            float64 `json:"low_52_week,omitempty"`
 	TotalVolume          int64   `json:"total_volume,omitempty"`
+	AverageVolume        float64 `json:"average_volume,omitempty"` // Per trading day
 	TotalValue           float64 `json:"total_value,omitempty"`
 	AveragePrice         float64 `json:"average_price,omitempty"`
 	HighestPrice         float64 `json:"highest_price,omitempty"`
@@ -79,12 +84,17 @@ func NewSummarizer(logger *slog.Logger, config SummarizerConfig) *Summarizer {
 	if config.DateFormat == "" {
 		config.DateFormat = "2006-01-02"
 	}
+	export := exporter.DefaultExportOptions()
+	if config.Export != nil {
+		export = *config.Export
+	}
 
 	return &Summarizer{
 		logger:                logger,
 		includeExtendedMetrics: config.IncludeExtendedMetrics,
 		maxLast10Days:         config.MaxLast10Days,
 		dateFormat:            config.DateFormat,
+		export:                export,
 	}
 }
 
@@ -164,7 +174,7 @@ func (s *Summarizer) WriteCSV(ctx context.Context, path string, summaries []Tick
 		row := []string{
 			summary.Ticker,
 			summary.CompanyName,
-			fmt.Sprintf("%.3f", summary.LastPrice),
+			s.export.FormatFloat("LastPrice", exporter.ColumnPrice, summary.LastPrice),
 			summary.LastDate,
 			fmt.Sprintf("%d", summary.TradingDays),
 			s.formatLast10Days(summary.Last10Days),
@@ -172,18 +182,18 @@ func (s *Summarizer) WriteCSV(ctx context.Context, path string, summaries []Tick
 
 		if s.includeExtendedMetrics {
 			row = append(row,
-				fmt.Sprintf("%d", summary.TotalVolume),
-				fmt.Sprintf("%.3f", summary.TotalValue),
-				fmt.Sprintf("%.3f", summary.AveragePrice),
-				fmt.Sprintf("%.3f", summary.HighestPrice),
-				fmt.Sprintf("%.3f", summary.LowestPrice),
+				s.export.FormatInt(summary.TotalVolume),
+				s.export.FormatFloat("TotalValue", exporter.ColumnPrice, summary.TotalValue),
+				s.export.FormatFloat("AveragePrice", exporter.ColumnPrice, summary.AveragePrice),
+				s.export.FormatFloat("HighestPrice", exporter.ColumnPrice, summary.HighestPrice),
+				s.export.FormatFloat("LowestPrice", exporter.ColumnPrice, summary.LowestPrice),
 			)
 		}
 		
 		// Always include change fields for frontend display
 		row = append(row,
-			fmt.Sprintf("%.3f", summary.Change),
-			fmt.Sprintf("%.2f", summary.ChangePercent),
+			s.export.FormatFloat("Change", exporter.ColumnPrice, summary.Change),
+			s.export.FormatFloat("ChangePercent", exporter.ColumnPercent, summary.ChangePercent),
 			fmt.Sprintf("%t", summary.LastTradingStatus),
 		)
 
@@ -260,7 +270,7 @@ func (s *Summarizer) generateTickerSummary(ctx context.Context, ticker string, r
 	}
 
 	// Sort records by date (oldest to newest)
-	sort.Slice(records, func(i, j int) bool {
+	sort.SliceStable(records, func(i, j int) bool {
 		return records[i].Date.Before(records[j].Date)
 	})
 
@@ -400,6 +410,7 @@ func (s *Summarizer) calculateExtendedMetrics(summary *TickerSummary, records []
 
 	if tradingDayCount > 0 {
 		summary.AveragePrice = priceSum / float64(tradingDayCount)
+		summary.AverageVolume = totalVolume / float64(tradingDayCount)
 	}
 
 	if lowestPrice == 999999999.0 {
@@ -491,9 +502,11 @@ func (s *Summarizer) formatLast10Days(prices []float64) string {
 		return ""
 	}
 
+	// No thousands grouping here: the list itself is comma-separated
+	decimals := s.export.Decimals("Last10Days", exporter.ColumnPrice)
 	parts := make([]string, len(prices))
 	for i, price := range prices {
-		parts[i] = fmt.Sprintf("%.3f", price)
+		parts[i] = strconv.FormatFloat(price, 'f', decimals, 64)
 	}
 	return strings.Join(parts, ",")
 }
@@ -658,6 +658,7 @@ func TestSummarizer_RealWorldScenario(t *testing.T) {
 	assert.Equal(t, "2024-08-11", summary.LastDate) // Should be Aug 11, not Aug 13
 	assert.Equal(t, 1, summary.TradingDays)          // Only one actual trading day
 	assert.Equal(t, []float64{1.500}, summary.Last10Days) // Only one trading price
+	assert.Equal(t, 1000.0, summary.AverageVolume)         // Forward-filled days do not dilute the average
 
 	t.Logf("Summary: Ticker=%s, LastDate=%s, TradingDays=%d, LastPrice=%.3f",
 		summary.Ticker, summary.LastDate, summary.TradingDays, summary.LastPrice)
@@ -0,0 +1,85 @@
+package dataprocessing
+
+import (
+	"math"
+	"sort"
+
+	"isxcli/internal/exporter"
+	"isxcli/pkg/contracts/domain"
+)
+
+// Traded-value band parameters for the daily CSVs
+const (
+	ValueBandWindow  = 60 // Market days of the ticker's own history, including the day
+	ValueBandMinDays = 20 // Days of history needed before a band is reported
+)
+
+// TradedValueBands ranks each traded day's value against the ticker's
+// previous window-1 market days, e.g. a percentile of 92 means the day traded
+// more value than 92% of its recent history. Days the ticker did not trade
+// count as zero value in the window but get no band of their own. Records
+// should be gap-filled so every market day after listing has a row.
+func TradedValueBands(records []domain.TradeRecord, window, minDays int) exporter.ValueBands {
+	bySymbol := make(map[string][]domain.TradeRecord)
+	for _, r := range records {
+		bySymbol[r.CompanySymbol] = append(bySymbol[r.CompanySymbol], r)
+	}
+
+	bands := make(exporter.ValueBands)
+	for symbol, history := range bySymbol {
+		sort.Slice(history, func(i, j int) bool {
+			return history[i].Date.Before(history[j].Date)
+		})
+
+		values := make([]float64, len(history))
+		for i, r := range history {
+			if r.TradingStatus && !math.IsNaN(r.Value) {
+				values[i] = r.Value
+			}
+		}
+
+		for i, r := range history {
+			if !r.TradingStatus {
+				continue
+			}
+			start := i - window + 1
+			if start < 0 {
+				start = 0
+			}
+			if i-start+1 < minDays {
+				continue
+			}
+			bands[exporter.ValueBandKey(symbol, r.Date)] = valueBand(values[start:i+1], values[i])
+		}
+	}
+	return bands
+}
+
+// valueBand computes the percentile rank of value and the band thresholds of
+// a window. Ties count half, so a value equal to every other scores 50.
+func valueBand(window []float64, value float64) exporter.ValueBand {
+	sorted := append([]float64(nil), window...)
+	sort.Float64s(sorted)
+
+	below := sort.SearchFloat64s(sorted, value)
+	equal := sort.SearchFloat64s(sorted, math.Nextafter(value, math.Inf(1))) - below
+
+	return exporter.ValueBand{
+		Percentile: (float64(below) + 0.5*float64(equal)) / float64(len(sorted)) * 100,
+		P10:        quantile(sorted, 0.10),
+		P50:        quantile(sorted, 0.50),
+		P90:        quantile(sorted, 0.90),
+		Days:       len(sorted),
+	}
+}
+
+// quantile interpolates linearly between the closest ranks of sorted values
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,53 @@
+package dataprocessing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+func TestTradedValueBands(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var records []domain.TradeRecord
+	for i := 0; i < 70; i++ {
+		r := domain.TradeRecord{CompanySymbol: "BBOB", Date: start.AddDate(0, 0, i), Value: float64(i%10+1) * 1000, TradingStatus: true}
+		if i == 65 {
+			// A forward-filled day carries no value of its own
+			r.TradingStatus, r.Value = false, 5000
+		}
+		records = append(records, r)
+	}
+	records[69].Value = 50000 // Unusual activity on the last day
+
+	bands := TradedValueBands(records, ValueBandWindow, ValueBandMinDays)
+
+	_, ok := bands.Get("BBOB", start.AddDate(0, 0, 18))
+	assert.False(t, ok, "too little history")
+	_, ok = bands.Get("BBOB", start.AddDate(0, 0, 65))
+	assert.False(t, ok, "days without trades have no band")
+
+	last, ok := bands.Get("BBOB", start.AddDate(0, 0, 69))
+	require.True(t, ok)
+	assert.Equal(t, ValueBandWindow, last.Days)
+	assert.InDelta(t, 59.5/60*100, last.Percentile, 1e-9, "the highest value of the window")
+	assert.Less(t, last.P10, last.P50)
+	assert.Less(t, last.P50, last.P90)
+
+	first, ok := bands.Get("BBOB", start.AddDate(0, 0, 19))
+	require.True(t, ok)
+	assert.Equal(t, ValueBandMinDays, first.Days)
+	// Values 1000..10000 repeat; day 19 traded 10000, tied with day 9
+	assert.InDelta(t, 19.0/20*100, first.Percentile, 1e-9)
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	assert.Equal(t, 3.0, quantile(sorted, 0.5))
+	assert.InDelta(t, 1.4, quantile(sorted, 0.1), 1e-9)
+	assert.InDelta(t, 4.6, quantile(sorted, 0.9), 1e-9)
+	assert.Zero(t, quantile(nil, 0.5))
+}
@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Kind is a transport-independent error category. Services wrap their errors
+// with a kind, and the HTTP layer turns the kind into a Problem Details
+// response. Kinds are errors themselves, so errors.Is(err, NotFound) works
+// through any amount of wrapping.
+type Kind string
+
+const (
+	NotFound            Kind = "not_found"
+	Validation          Kind = "validation"
+	Conflict            Kind = "conflict"
+	Unauthorized        Kind = "unauthorized"
+	Forbidden           Kind = "forbidden"
+	LicenseExpired      Kind = "license_expired"
+	LicenseInvalid      Kind = "license_invalid"
+	RateLimited         Kind = "rate_limited"
+	Timeout             Kind = "timeout"
+	UpstreamUnavailable Kind = "upstream_unavailable"
+	Unavailable         Kind = "unavailable"
+	Internal            Kind = "internal"
+)
+
+// Error implements the error interface
+func (k Kind) Error() string {
+	return strings.ReplaceAll(string(k), "_", " ")
+}
+
+// kindProblem is how a kind is presented over HTTP. The type URIs and error
+// codes are part of the API and must not change.
+type kindProblem struct {
+	status int
+	typ    string
+	title  string
+	code   string
+}
+
+var kindProblems = map[Kind]kindProblem{
+	NotFound:            {http.StatusNotFound, TypeNotFound, "Resource Not Found", "NOT_FOUND"},
+	Validation:          {http.StatusBadRequest, TypeValidation, "Validation Failed", "VALIDATION_FAILED"},
+	Conflict:            {http.StatusConflict, TypeConflict, "Conflict", "CONFLICT"},
+	Unauthorized:        {http.StatusUnauthorized, TypeUnauthorized, "Unauthorized", "UNAUTHORIZED"},
+	Forbidden:           {http.StatusForbidden, TypeForbidden, "Forbidden", "FORBIDDEN"},
+	LicenseExpired:      {http.StatusForbidden, TypeLicenseExpired, "License Expired", "LICENSE_EXPIRED"},
+	LicenseInvalid:      {http.StatusForbidden, TypeLicenseInvalid, "Invalid License", "INVALID_LICENSE"},
+	RateLimited:         {http.StatusTooManyRequests, TypeRateLimit, "Rate Limit Exceeded", "RATE_LIMIT_EXCEEDED"},
+	Timeout:             {http.StatusGatewayTimeout, TypeTimeout, "Request Timeout", "TIMEOUT"},
+	UpstreamUnavailable: {http.StatusBadGateway, TypeUpstreamUnavailable, "Upstream Unavailable", "UPSTREAM_UNAVAILABLE"},
+	Unavailable:         {http.StatusServiceUnavailable, TypeServiceDown, "Service Unavailable", "SERVICE_UNAVAILABLE"},
+	Internal:            {http.StatusInternalServerError, TypeInternal, "Internal Server Error", "INTERNAL_SERVER_ERROR"},
+}
+
+// DomainError is an error of a known kind with a message for the client and
+// an optional cause
+type DomainError struct {
+	Kind    Kind
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+// Error implements the error interface
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is and errors.As
+func (e *DomainError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// WithDetail adds a detail that is rendered with the problem response
+func (e *DomainError) WithDetail(key string, value interface{}) *DomainError {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Newf creates a domain error of the given kind
+func Newf(kind Kind, format string, args ...interface{}) *DomainError {
+	return &DomainError{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap attaches a kind and message to err. It returns nil if err is nil.
+func Wrap(kind Kind, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &DomainError{Kind: kind, Message: message, Err: err}
+}
+
+// Wrapf is Wrap with a formatted message
+func Wrapf(kind Kind, err error, format string, args ...interface{}) error {
+	return Wrap(kind, err, fmt.Sprintf(format, args...))
+}
+
+// legacyKinds classifies the sentinel errors that predate kinds
+var legacyKinds = []struct {
+	err  error
+	kind Kind
+}{
+	{context.DeadlineExceeded, Timeout},
+	{ErrLicenseExpired, LicenseExpired},
+	{ErrLicenseNotActivated, LicenseInvalid},
+	{ErrInvalidLicenseKey, Validation},
+	{ErrInvalidLicenseFormat, Validation},
+	{ErrRateLimited, RateLimited},
+	{ErrNetworkError, UpstreamUnavailable},
+	{ErrLicenseAlreadyActivated, Conflict},
+	{ErrReactivationLimitExceeded, Conflict},
+	{ErrAlreadyActivatedOnDevice, Conflict},
+}
+
+// appErrorKinds classifies AppError types
+var appErrorKinds = map[ErrorType]Kind{
+	ErrTypeLicense:    LicenseInvalid,
+	ErrTypeNetwork:    UpstreamUnavailable,
+	ErrTypeValidation: Validation,
+	ErrTypeNotFound:   NotFound,
+	ErrTypePermission: Forbidden,
+}
+
+// KindOf returns the kind of err: the kind it was wrapped with, or the kind
+// of a known sentinel or AppError in its chain. It returns "" for errors
+// without a kind.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	var kind Kind
+	if errors.As(err, &kind) {
+		return kind
+	}
+	for _, legacy := range legacyKinds {
+		if errors.Is(err, legacy.err) {
+			return legacy.kind
+		}
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErrorKinds[appErr.Type]
+	}
+	return ""
+}
+
+// KindToProblem converts an error with a kind to RFC 7807 Problem Details.
+// Internal errors keep their message out of the response. It reports false
+// for errors without a kind.
+func KindToProblem(err error, instance string) (*ProblemDetails, bool) {
+	kind := KindOf(err)
+	p, ok := kindProblems[kind]
+	if !ok {
+		return nil, false
+	}
+
+	detail := err.Error()
+	if kind == Internal {
+		detail = "An unexpected error occurred while processing your request"
+	}
+	problem := NewProblemDetails(p.status, p.typ, p.title, detail, instance).
+		WithExtension("error_code", p.code)
+
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) && len(domainErr.Details) > 0 {
+		problem.WithExtension("details", domainErr.Details)
+	}
+	return problem, true
+}
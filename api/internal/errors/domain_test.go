@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTickerNotFound = Newf(NotFound, "ticker not found")
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"sentinel", errTickerNotFound, NotFound},
+		{"wrapped sentinel", fmt.Errorf("load BBOB: %w", errTickerNotFound), NotFound},
+		{"wrapped kind", fmt.Errorf("date must be YYYY-MM-DD: %w", Validation), Validation},
+		{"wrap", Wrap(UpstreamUnavailable, errors.New("dial tcp: connection refused"), "ISX website unreachable"), UpstreamUnavailable},
+		{"license sentinel", fmt.Errorf("check: %w", ErrLicenseExpired), LicenseExpired},
+		{"app error", NewNotFoundError("index"), NotFound},
+		{"deadline", context.DeadlineExceeded, Timeout},
+		{"plain", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(tt.err))
+		})
+	}
+}
+
+func TestDomainErrorWrapping(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Wrapf(UpstreamUnavailable, cause, "fetch %s", "2025-06-01")
+
+	assert.Equal(t, "fetch 2025-06-01: connection refused", err.Error())
+	assert.ErrorIs(t, err, UpstreamUnavailable)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, NotFound)
+	assert.NoError(t, Wrap(Internal, nil, "ignored"))
+}
+
+func TestKindToProblem(t *testing.T) {
+	err := fmt.Errorf("portfolio p1: %w", Newf(Conflict, "sell quantity exceeds holding").WithDetail("symbol", "BBOB"))
+	problem, ok := KindToProblem(err, "/api/v1/portfolios/p1")
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, TypeConflict, problem.Type)
+	assert.Equal(t, "portfolio p1: sell quantity exceeds holding", problem.Detail)
+	assert.Equal(t, "CONFLICT", problem.Extensions["error_code"])
+	assert.Equal(t, map[string]interface{}{"symbol": "BBOB"}, problem.Extensions["details"])
+
+	problem, ok = KindToProblem(Wrap(Internal, errors.New("open /secret/path"), "read report"), "")
+	require.True(t, ok)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.NotContains(t, problem.Detail, "/secret/path")
+
+	_, ok = KindToProblem(errors.New("boom"), "")
+	assert.False(t, ok)
+}
+
+func TestErrorHandlerMapsKinds(t *testing.T) {
+	handler := NewErrorHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), false)
+	for kind, want := range map[Kind]int{
+		NotFound:            http.StatusNotFound,
+		Validation:          http.StatusBadRequest,
+		LicenseExpired:      http.StatusForbidden,
+		UpstreamUnavailable: http.StatusBadGateway,
+		RateLimited:         http.StatusTooManyRequests,
+	} {
+		rec := httptest.NewRecorder()
+		handler.HandleError(rec, httptest.NewRequest("GET", "/api/v1/things", nil), fmt.Errorf("things: %w", kind))
+		assert.Equal(t, want, rec.Code, kind)
+		assert.Contains(t, rec.Body.String(), kindProblems[kind].typ)
+	}
+}
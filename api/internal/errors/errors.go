@@ -75,6 +75,12 @@ var (
 	// 409 Conflict
 	ErrConflict = New(http.StatusConflict, "CONFLICT", "Resource conflict")
 
+	// 413 Payload Too Large
+	ErrPayloadTooLarge = New(http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "Request body exceeds maximum allowed size")
+
+	// 415 Unsupported Media Type
+	ErrUnsupportedMediaType = New(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "Unsupported media type")
+
 	// 422 Unprocessable Entity
 	ErrUnprocessableEntity = New(http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "Request could not be processed")
 
@@ -131,6 +137,16 @@ func FileSystemError(operation string, err error) *APIError {
 	return NewWithDetails(http.StatusInternalServerError, "FILESYSTEM_ERROR", fmt.Sprintf("File system error during %s", operation), err.Error())
 }
 
+// PayloadTooLargeError creates a 413 error with the upload details
+func PayloadTooLargeError(details interface{}) *APIError {
+	return NewWithDetails(http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "Request body exceeds maximum allowed size", details)
+}
+
+// UnsupportedMediaTypeError creates a 415 error with the upload details
+func UnsupportedMediaTypeError(message string, details interface{}) *APIError {
+	return NewWithDetails(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", message, details)
+}
+
 // ErrorResponse represents a standard error response
 type ErrorResponse struct {
 	Success bool      `json:"success"`
@@ -26,6 +26,8 @@ const (
 	TypeTimeout         = "/errors/timeout"
 	TypeConflict        = "/errors/conflict"
 	TypePayloadTooLarge = "/errors/payload-too-large"
+	TypeUnsupportedMedia = "/errors/unsupported-media-type"
+	TypeUpstreamUnavailable = "/errors/upstream-unavailable"
 )
 
 // Domain-specific error types
@@ -33,6 +35,7 @@ const (
 	TypeLicenseExpired      = "/errors/license/expired"
 	TypeLicenseNotFound     = "/errors/license/not-found"
 	TypeLicenseMismatch     = "/errors/license/machine-mismatch"
+	TypeLicenseInvalid      = "/errors/license/invalid"
 	TypePipelineNotFound    = "/errors/operation/not-found"
 	TypePipelineRunning     = "/errors/operation/already-running"
 	TypeDataNotFound        = "/errors/data/not-found"
@@ -104,6 +107,11 @@ func (h *ErrorHandler) ErrorToProblem(err error, r *http.Request) *ProblemDetail
 		return h.apiErrorToProblem(apiErr, r)
 	}
 
+	// Domain errors carry their kind
+	if problem, ok := KindToProblem(err, r.URL.Path); ok {
+		return problem
+	}
+
 	// Check if it's an APIError with validation error code
 	if apiErr != nil && apiErr.ErrorCode == "VALIDATION_ERROR" {
 		// Extract validation errors from details
@@ -223,6 +231,10 @@ func (h *ErrorHandler) apiErrorToProblem(apiErr *APIError, r *http.Request) *Pro
 		problemType = TypeRateLimit
 	case "SERVICE_UNAVAILABLE":
 		problemType = TypeServiceDown
+	case "PAYLOAD_TOO_LARGE":
+		problemType = TypePayloadTooLarge
+	case "UNSUPPORTED_MEDIA_TYPE":
+		problemType = TypeUnsupportedMedia
 	}
 
 	problem := NewProblemDetails(
@@ -361,6 +373,11 @@ func (w *errorResponseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *errorResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // JSON helper for consistent JSON error responses
 func (h *ErrorHandler) JSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
 	render.Status(r, status)
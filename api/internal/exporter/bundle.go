@@ -0,0 +1,353 @@
+package exporter
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/currency"
+	"isxcli/internal/i18n"
+)
+
+// BundleArtifact is a kind of report that can be included in a bundle
+type BundleArtifact string
+
+const (
+	// BundleDaily is the per-day market report (isx_daily_YYYY_MM_DD.csv)
+	BundleDaily BundleArtifact = "daily"
+	// BundleTickerHistory is a ticker's trading history (BBOB_trading_history.csv)
+	BundleTickerHistory BundleArtifact = "ticker_history"
+	// BundleLiquidity is the per-day liquidity scores (liquidity_scores_YYYY-MM-DD.csv)
+	BundleLiquidity BundleArtifact = "liquidity"
+)
+
+// BundleArtifacts lists the artifacts a bundle can hold
+var BundleArtifacts = []BundleArtifact{BundleDaily, BundleTickerHistory, BundleLiquidity}
+
+// Valid reports whether a is a known artifact
+func (a BundleArtifact) Valid() bool {
+	for _, known := range BundleArtifacts {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrEmptyBundle is returned when no report matches a bundle spec
+var ErrEmptyBundle = errors.New("no reports match the bundle selection")
+
+// BundleSpec selects the reports of a bundle. Zero From or To leaves the
+// range open on that side; empty Tickers keeps every ticker. With Currency
+// USD, amount columns are converted with Rates at each row's date. Locale
+// translates headers and digits; the zero Locale keeps files as stored.
+type BundleSpec struct {
+	From      time.Time
+	To        time.Time
+	Tickers   []string
+	Artifacts []BundleArtifact
+	Currency  currency.Code
+	Rates     *currency.Table
+	Locale    i18n.Locale
+}
+
+// BundleEntry is one file of a bundle
+type BundleEntry struct {
+	Name     string         `json:"name"` // Path inside the archive
+	Artifact BundleArtifact `json:"artifact"`
+	Source   string         `json:"-"`
+	Size     int64          `json:"size"` // Size of the source file before row filtering
+}
+
+// Bundle is a planned ZIP archive of reports. Files are read and filtered
+// only when the archive is written, so it can be streamed to a client.
+type Bundle struct {
+	Entries []BundleEntry
+
+	spec       BundleSpec
+	tickers    map[string]bool
+	dateLayout string
+	opts       ExportOptions
+}
+
+var (
+	dailyBundleRe     = regexp.MustCompile(`^isx_daily_(\d{4}_\d{2}_\d{2})\.csv$`)
+	liquidityBundleRe = regexp.MustCompile(`^liquidity_scores_(\d{4}-\d{2}-\d{2})\.csv$`)
+)
+
+// PlanBundle lists the report files matching spec in archive order: by
+// artifact, then by date or ticker. Daily and liquidity reports are picked by
+// the date in their file name; ticker histories by ticker. opts supplies the
+// date layout used inside ticker histories.
+func PlanBundle(paths *config.Paths, spec BundleSpec, opts ExportOptions) (*Bundle, error) {
+	bundle := &Bundle{
+		spec:       spec,
+		tickers:    make(map[string]bool, len(spec.Tickers)),
+		dateLayout: opts.DateFormat,
+		opts:       opts,
+	}
+	if bundle.dateLayout == "" {
+		bundle.dateLayout = DefaultExportOptions().DateFormat
+	}
+	for _, ticker := range spec.Tickers {
+		bundle.tickers[strings.ToUpper(ticker)] = true
+	}
+
+	for _, artifact := range spec.Artifacts {
+		var (
+			entries []BundleEntry
+			err     error
+		)
+		switch artifact {
+		case BundleDaily:
+			entries, err = bundle.datedFiles(artifact, []string{paths.DailyReportsDir}, dailyBundleRe, "2006_01_02")
+		case BundleLiquidity:
+			// The liquidity stage writes to reports/liquidity_reports
+			dirs := []string{filepath.Join(paths.ReportsDir, "liquidity_reports"), paths.LiquidityReportsDir}
+			entries, err = bundle.datedFiles(artifact, dirs, liquidityBundleRe, "2006-01-02")
+		case BundleTickerHistory:
+			entries, err = bundle.tickerFiles(paths)
+		default:
+			return nil, fmt.Errorf("unknown bundle artifact %q", artifact)
+		}
+		if err != nil {
+			return nil, err
+		}
+		bundle.Entries = append(bundle.Entries, entries...)
+	}
+
+	if len(bundle.Entries) == 0 {
+		return nil, ErrEmptyBundle
+	}
+	return bundle, nil
+}
+
+// datedFiles lists files in dirs whose name carries a date within the range
+func (b *Bundle) datedFiles(artifact BundleArtifact, dirs []string, pattern *regexp.Regexp, layout string) ([]BundleEntry, error) {
+	var entries []BundleEntry
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		for _, file := range files {
+			match := pattern.FindStringSubmatch(file.Name())
+			if file.IsDir() || match == nil || seen[file.Name()] {
+				continue
+			}
+			date, err := time.Parse(layout, match[1])
+			if err != nil || !b.inRange(date) {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat %s: %w", file.Name(), err)
+			}
+			seen[file.Name()] = true
+			entries = append(entries, BundleEntry{
+				Name:     string(artifact) + "/" + file.Name(),
+				Artifact: artifact,
+				Source:   filepath.Join(dir, file.Name()),
+				Size:     info.Size(),
+			})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// tickerFiles lists the trading histories of the selected tickers, or of all
+// tickers when none are selected
+func (b *Bundle) tickerFiles(paths *config.Paths) ([]BundleEntry, error) {
+	var sources []string
+	if len(b.tickers) > 0 {
+		for _, ticker := range SortedKeys(b.tickers) {
+			sources = append(sources, paths.GetTickerHistoryCSVPath(ticker))
+		}
+	} else {
+		matches, err := filepath.Glob(filepath.Join(paths.TickerReportsDir, "*_trading_history.csv"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		sources = matches
+	}
+
+	var entries []BundleEntry
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // Tickers without history are left out
+			}
+			return nil, fmt.Errorf("failed to stat %s: %w", filepath.Base(source), err)
+		}
+		entries = append(entries, BundleEntry{
+			Name:     string(BundleTickerHistory) + "/" + filepath.Base(source),
+			Artifact: BundleTickerHistory,
+			Source:   source,
+			Size:     info.Size(),
+		})
+	}
+	return entries, nil
+}
+
+// WriteZip streams the bundle as a ZIP archive. Daily and liquidity reports
+// keep only the selected tickers' rows; ticker histories keep only rows within
+// the date range, and amounts are converted when the spec asks for USD. It
+// stops between files when ctx is cancelled.
+func (b *Bundle) WriteZip(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range b.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, err := os.Stat(entry.Source)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", entry.Name, err)
+		}
+		header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate}
+		header.Modified = info.ModTime()
+		out, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", entry.Name, err)
+		}
+		if err := b.copyEntry(out, entry); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.Name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// copyEntry writes a source file, filtering its rows when the spec asks for
+// it. Bundles are downloaded for spreadsheets, so every cell is escaped
+// against formula injection, including in files written before escaping.
+func (b *Bundle) copyEntry(w io.Writer, entry BundleEntry) error {
+	file, err := os.Open(entry.Source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	filterTickers := len(b.tickers) > 0 && entry.Artifact != BundleTickerHistory
+	filterDates := entry.Artifact == BundleTickerHistory && (!b.spec.From.IsZero() || !b.spec.To.IsZero())
+	convert := b.spec.Currency == currency.USD && b.spec.Rates != nil
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	column := "Date"
+	if filterTickers {
+		column = "Symbol"
+	}
+	index, dateIndex := -1, -1
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		if strings.EqualFold(name, column) {
+			index = i
+		}
+		if strings.EqualFold(name, "Date") {
+			dateIndex = i
+		}
+	}
+
+	locale := b.spec.Locale
+	out := header
+	if !locale.IsDefault() {
+		if locale.NeedsBOM() {
+			if _, err := io.WriteString(w, i18n.UTF8BOM); err != nil {
+				return err
+			}
+		}
+		out = localizeHeader(header, locale)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(EscapeFormulas(out)); err != nil {
+		return err
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if (filterTickers || filterDates) && index >= 0 && index < len(record) && !b.keepRow(record[index], filterTickers) {
+			continue
+		}
+		if convert && dateIndex >= 0 && dateIndex < len(record) {
+			b.convertRow(header, record, record[dateIndex])
+		}
+		localizeRecord(record, locale)
+		if err := writer.Write(EscapeFormulas(record)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// convertRow converts the amount cells of a row to the spec's currency at
+// the rate of the row's date. Liquidity reports always use ISO dates.
+func (b *Bundle) convertRow(header, record []string, dateCell string) {
+	dateCell = strings.TrimSpace(dateCell)
+	date, err := time.Parse(b.dateLayout, dateCell)
+	if err != nil {
+		if date, err = time.Parse("2006-01-02", dateCell); err != nil {
+			return
+		}
+	}
+	toCurrency := func(v float64) float64 { return b.spec.Rates.Convert(v, b.spec.Currency, date) }
+	for i := range record {
+		if i < len(header) {
+			record[i] = b.opts.ConvertCell(strings.TrimPrefix(header[i], "\ufeff"), record[i], toCurrency)
+		}
+	}
+}
+
+// keepRow reports whether a row's symbol or date matches the selection
+func (b *Bundle) keepRow(value string, bySymbol bool) bool {
+	if bySymbol {
+		return b.tickers[strings.ToUpper(strings.TrimSpace(value))]
+	}
+	date, err := time.Parse(b.dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	return b.inRange(date)
+}
+
+// inRange reports whether date lies within the spec's From and To, inclusive
+func (b *Bundle) inRange(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if !b.spec.From.IsZero() && day < b.spec.From.Format("2006-01-02") {
+		return false
+	}
+	if !b.spec.To.IsZero() && day > b.spec.To.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
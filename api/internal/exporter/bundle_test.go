@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+// newBundlePaths lays out daily, liquidity and ticker history reports in a
+// temporary reports tree
+func newBundlePaths(t *testing.T) *config.Paths {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		ReportsDir:          dir,
+		DailyReportsDir:     filepath.Join(dir, "daily"),
+		TickerReportsDir:    filepath.Join(dir, "ticker"),
+		LiquidityReportsDir: filepath.Join(dir, "liquidity"),
+	}
+	files := map[string]string{
+		filepath.Join(paths.DailyReportsDir, "isx_daily_2025_01_05.csv"):           "Date,Symbol,Close\n2025-01-05,BBOB,1.1\n2025-01-05,TASC,2.2\n",
+		filepath.Join(paths.DailyReportsDir, "isx_daily_2025_01_06.csv"):           "Date,Symbol,Close\n2025-01-06,BBOB,1.2\n2025-01-06,TASC,2.3\n",
+		filepath.Join(paths.DailyReportsDir, "isx_daily_2025_02_01.csv"):           "Date,Symbol,Close\n2025-02-01,BBOB,1.3\n",
+		filepath.Join(paths.DailyReportsDir, "notes.txt"):                          "not a report",
+		filepath.Join(dir, "liquidity_reports", "liquidity_scores_2025-01-06.csv"): "\ufeffsymbol,score\nBBOB,70\nTASC,40\n",
+		filepath.Join(paths.TickerReportsDir, "BBOB_trading_history.csv"):          "Date,Symbol,Close\n2025-01-05,BBOB,1.1\n2025-01-06,BBOB,1.2\n2025-02-01,BBOB,1.3\n",
+		filepath.Join(paths.TickerReportsDir, "TASC_trading_history.csv"):          "Date,Symbol,Close\n2025-01-05,TASC,2.2\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return paths
+}
+
+// readBundle writes b and returns the archive's files by name
+func readBundle(t *testing.T, b *Bundle) map[string]string {
+	var buf bytes.Buffer
+	require.NoError(t, b.WriteZip(context.Background(), &buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func entryNames(b *Bundle) []string {
+	names := make([]string, len(b.Entries))
+	for i, entry := range b.Entries {
+		names[i] = entry.Name
+	}
+	return names
+}
+
+func TestPlanBundleDateRange(t *testing.T) {
+	paths := newBundlePaths(t)
+	spec := BundleSpec{
+		From:      time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		Artifacts: []BundleArtifact{BundleDaily, BundleLiquidity, BundleTickerHistory},
+	}
+
+	bundle, err := PlanBundle(paths, spec, DefaultExportOptions())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"daily/isx_daily_2025_01_06.csv",
+		"liquidity/liquidity_scores_2025-01-06.csv",
+		"ticker_history/BBOB_trading_history.csv",
+		"ticker_history/TASC_trading_history.csv",
+	}, entryNames(bundle))
+
+	files := readBundle(t, bundle)
+	assert.Equal(t, "Date,Symbol,Close\n2025-01-06,BBOB,1.2\n2025-01-06,TASC,2.3\n", files["daily/isx_daily_2025_01_06.csv"])
+	assert.Equal(t, "Date,Symbol,Close\n2025-01-06,BBOB,1.2\n", files["ticker_history/BBOB_trading_history.csv"])
+	assert.Equal(t, "Date,Symbol,Close\n", files["ticker_history/TASC_trading_history.csv"])
+}
+
+func TestPlanBundleTickers(t *testing.T) {
+	paths := newBundlePaths(t)
+	spec := BundleSpec{
+		Tickers:   []string{"bbob", "MISSING"},
+		Artifacts: []BundleArtifact{BundleLiquidity, BundleTickerHistory, BundleDaily},
+	}
+
+	bundle, err := PlanBundle(paths, spec, DefaultExportOptions())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"liquidity/liquidity_scores_2025-01-06.csv",
+		"ticker_history/BBOB_trading_history.csv",
+		"daily/isx_daily_2025_01_05.csv",
+		"daily/isx_daily_2025_01_06.csv",
+		"daily/isx_daily_2025_02_01.csv",
+	}, entryNames(bundle), "artifacts follow the requested order and unknown tickers are left out")
+
+	files := readBundle(t, bundle)
+	assert.Equal(t, "\ufeffsymbol,score\nBBOB,70\n", files["liquidity/liquidity_scores_2025-01-06.csv"])
+	assert.Equal(t, "Date,Symbol,Close\n2025-01-05,BBOB,1.1\n", files["daily/isx_daily_2025_01_05.csv"])
+	assert.Equal(t, "Date,Symbol,Close\n2025-01-05,BBOB,1.1\n2025-01-06,BBOB,1.2\n2025-02-01,BBOB,1.3\n",
+		files["ticker_history/BBOB_trading_history.csv"], "histories are copied whole without a date range")
+}
+
+func TestPlanBundleEmpty(t *testing.T) {
+	paths := newBundlePaths(t)
+
+	_, err := PlanBundle(paths, BundleSpec{
+		From:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		Artifacts: []BundleArtifact{BundleDaily, BundleLiquidity},
+	}, DefaultExportOptions())
+	assert.True(t, errors.Is(err, ErrEmptyBundle))
+
+	_, err = PlanBundle(paths, BundleSpec{Artifacts: []BundleArtifact{"weekly"}}, DefaultExportOptions())
+	assert.Error(t, err)
+}
+
+func TestBundleWriteZipCancelled(t *testing.T) {
+	bundle, err := PlanBundle(newBundlePaths(t), BundleSpec{Artifacts: []BundleArtifact{BundleDaily}}, DefaultExportOptions())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, bundle.WriteZip(ctx, io.Discard), context.Canceled)
+}
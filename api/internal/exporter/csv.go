@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"isxcli/internal/config"
+	"isxcli/internal/files"
 )
 
 // CSVWriter provides CSV export functionality
@@ -23,13 +24,17 @@ func NewCSVWriter(paths *config.Paths) *CSVWriter {
 
 // WriteOptions configures CSV writing behavior
 type WriteOptions struct {
-	Headers    []string
-	Records    [][]string
-	Append     bool
-	BOMPrefix  bool // Add UTF-8 BOM for Excel compatibility
+	Headers   []string
+	Records   [][]string
+	Append    bool
+	BOMPrefix bool // Add UTF-8 BOM for Excel compatibility
+	// EscapeFormulas prefixes cells starting with =, +, - or @ with a quote
+	// so spreadsheets show them as text instead of running them
+	EscapeFormulas bool
 }
 
-// WriteCSV writes data to a CSV file with the given options
+// WriteCSV writes data to a CSV file with the given options. It fails with
+// files.ErrLocked while another process holds the directory lock.
 func (w *CSVWriter) WriteCSV(filePath string, options WriteOptions) error {
 	// Resolve the full path based on the file location
 	fullPath := w.resolvePath(filePath)
@@ -44,6 +49,9 @@ func (w *CSVWriter) WriteCSV(filePath string, options WriteOptions) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
+	if err := files.CheckDirLock(dir); err != nil {
+		return err
+	}
 	
 	// Open file with appropriate flags
 	flags := os.O_CREATE | os.O_WRONLY
@@ -71,13 +79,20 @@ func (w *CSVWriter) WriteCSV(filePath string, options WriteOptions) error {
 	
 	// Write headers if not appending
 	if !options.Append && len(options.Headers) > 0 {
-		if err := writer.Write(options.Headers); err != nil {
+		headers := options.Headers
+		if options.EscapeFormulas {
+			headers = EscapeFormulas(headers)
+		}
+		if err := writer.Write(headers); err != nil {
 			return fmt.Errorf("failed to write headers: %w", err)
 		}
 	}
 	
 	// Write records
 	for i, record := range options.Records {
+		if options.EscapeFormulas {
+			record = EscapeFormulas(record)
+		}
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("failed to write record %d: %w", i, err)
 		}
@@ -89,10 +104,11 @@ func (w *CSVWriter) WriteCSV(filePath string, options WriteOptions) error {
 // WriteSimpleCSV writes a simple CSV file with headers and records
 func (w *CSVWriter) WriteSimpleCSV(filePath string, headers []string, records [][]string) error {
 	return w.WriteCSV(filePath, WriteOptions{
-		Headers: headers,
-		Records: records,
-		Append:  false,
-		BOMPrefix: true,
+		Headers:        headers,
+		Records:        records,
+		Append:         false,
+		BOMPrefix:      true,
+		EscapeFormulas: true,
 	})
 }
 
@@ -108,10 +124,18 @@ func (w *CSVWriter) AppendToCSV(filePath string, records [][]string) error {
 type StreamWriter struct {
 	file   *os.File
 	writer *csv.Writer
+	escape bool
 }
 
-// CreateStreamWriter creates a new streaming CSV writer
+// CreateStreamWriter creates a new streaming CSV writer with a UTF-8 BOM
 func (w *CSVWriter) CreateStreamWriter(filePath string, headers []string) (*StreamWriter, error) {
+	return w.CreateStreamWriterWithBOM(filePath, headers, true)
+}
+
+// CreateStreamWriterWithBOM creates a new streaming CSV writer, optionally
+// prefixing the file with a UTF-8 BOM. Files with a BOM are meant for Excel,
+// so their cells are escaped against formula injection too.
+func (w *CSVWriter) CreateStreamWriterWithBOM(filePath string, headers []string, bom bool) (*StreamWriter, error) {
 	// Resolve the full path based on the file location
 	fullPath := w.resolvePath(filePath)
 	
@@ -125,6 +149,9 @@ func (w *CSVWriter) CreateStreamWriter(filePath string, headers []string) (*Stre
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
+	if err := files.CheckDirLock(dir); err != nil {
+		return nil, err
+	}
 	
 	file, err := os.Create(fullPath)
 	if err != nil {
@@ -132,15 +159,20 @@ func (w *CSVWriter) CreateStreamWriter(filePath string, headers []string) (*Stre
 	}
 	
 	// Write BOM for Excel compatibility
-	if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
-		file.Close()
-		return nil, fmt.Errorf("failed to write BOM: %w", err)
+	if bom {
+		if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write BOM: %w", err)
+		}
 	}
 	
 	writer := csv.NewWriter(file)
 	
 	// Write headers
 	if len(headers) > 0 {
+		if bom {
+			headers = EscapeFormulas(headers)
+		}
 		if err := writer.Write(headers); err != nil {
 			file.Close()
 			return nil, fmt.Errorf("failed to write headers: %w", err)
@@ -150,11 +182,15 @@ func (w *CSVWriter) CreateStreamWriter(filePath string, headers []string) (*Stre
 	return &StreamWriter{
 		file:   file,
 		writer: writer,
+		escape: bom,
 	}, nil
 }
 
 // WriteRecord writes a single record to the stream
 func (s *StreamWriter) WriteRecord(record []string) error {
+	if s.escape {
+		record = EscapeFormulas(record)
+	}
 	return s.writer.Write(record)
 }
 
@@ -3,16 +3,19 @@ package exporter
 import (
 	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"isxcli/internal/config"
+	"isxcli/internal/files"
 )
 
 // MockPaths implements config.Paths interface for testing
@@ -469,6 +472,29 @@ func TestCSVWriter_ErrorScenarios(t *testing.T) {
 	}
 }
 
+func TestCSVWriter_LockedDirectory(t *testing.T) {
+	writer, tempDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	// Another live process holds the reports directory
+	host, _ := os.Hostname()
+	now := time.Now()
+	data, err := json.Marshal(files.LockInfo{Token: "other", Owner: "processor", PID: os.Getppid(), Host: host, AcquiredAt: now, Heartbeat: now})
+	require.NoError(t, err)
+	lockPath := filepath.Join(tempDir, "reports", files.LockFileName)
+	require.NoError(t, os.WriteFile(lockPath, data, 0644))
+
+	err = writer.WriteSimpleCSV("ticker/BBOB.csv", []string{"Test"}, [][]string{{"Data"}})
+	assert.ErrorIs(t, err, files.ErrLocked)
+	assert.NoFileExists(t, filepath.Join(tempDir, "reports", "ticker", "BBOB.csv"))
+
+	_, err = writer.CreateStreamWriter("stream.csv", []string{"Test"})
+	assert.ErrorIs(t, err, files.ErrLocked)
+
+	require.NoError(t, os.Remove(lockPath))
+	assert.NoError(t, writer.WriteSimpleCSV("ticker/BBOB.csv", []string{"Test"}, [][]string{{"Data"}}))
+}
+
 // BenchmarkCSVWriter_WriteCSV tests CSV writing performance
 func BenchmarkCSVWriter_WriteCSV(b *testing.B) {
 	// Setup
@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// usdExtraDecimals is added to a column's decimal places for USD amounts, a
+// dollar being worth over a thousand dinars
+const usdExtraDecimals = 3
+
+// MonetaryColumns maps the report columns holding IQD amounts to their
+// column kind: trade record prices and values, value bands and the liquidity
+// scores' traded value and safe trade sizes
+var MonetaryColumns = map[string]string{
+	"OpenPrice":        ColumnPrice,
+	"HighPrice":        ColumnPrice,
+	"LowPrice":         ColumnPrice,
+	"AveragePrice":     ColumnPrice,
+	"PrevAveragePrice": ColumnPrice,
+	"ClosePrice":       ColumnPrice,
+	"PrevClosePrice":   ColumnPrice,
+	"Change":           ColumnPrice,
+	"Value":            ColumnValue,
+	"ValueP10_60D":     ColumnValue,
+	"ValueP50_60D":     ColumnValue,
+	"ValueP90_60D":     ColumnValue,
+	"Value_Raw":        ColumnValue,
+	"Safe_Trade_0.5%":  ColumnValue,
+	"Safe_Trade_1%":    ColumnValue,
+	"Safe_Trade_2%":    ColumnValue,
+	"Optimal_Trade":    ColumnValue,
+}
+
+// ConvertCell restates an IQD amount cell of a monetary column with convert,
+// keeping three more decimals than the column has in IQD. Cells of other
+// columns and cells that are not numbers are returned unchanged.
+func (o ExportOptions) ConvertCell(column, cell string, convert func(float64) float64) string {
+	kind, ok := MonetaryColumns[column]
+	if !ok || cell == "" {
+		return cell
+	}
+	number := cell
+	if o.ThousandsSeparator != "" {
+		number = strings.ReplaceAll(number, o.ThousandsSeparator, "")
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return cell
+	}
+	return o.group(strconv.FormatFloat(convert(v), 'f', o.Decimals(column, kind)+usdExtraDecimals, 64))
+}
@@ -0,0 +1,21 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertCell(t *testing.T) {
+	toUSD := func(v float64) float64 { return v / 1300 }
+	opts := DefaultExportOptions()
+
+	assert.Equal(t, "0.000962", opts.ConvertCell("ClosePrice", "1.250", toUSD))
+	assert.Equal(t, "10.00000", opts.ConvertCell("Value", "13000.00", toUSD))
+	assert.Equal(t, "1000", opts.ConvertCell("Volume", "1000", toUSD), "volumes are not amounts")
+	assert.Equal(t, "", opts.ConvertCell("Value", "", toUSD))
+	assert.Equal(t, "n/a", opts.ConvertCell("Value", "n/a", toUSD))
+
+	opts.ThousandsSeparator = ","
+	assert.Equal(t, "1,000.00000", opts.ConvertCell("Value", "1,300,000.00", toUSD))
+}
@@ -3,7 +3,6 @@ package exporter
 import (
 	"fmt"
 	"path/filepath"
-	"sort"
 
 	"isxcli/internal/config"
 	"isxcli/pkg/contracts/domain"
@@ -12,15 +11,31 @@ import (
 // DailyExporter handles daily report generation
 type DailyExporter struct {
 	csvWriter *CSVWriter
+	options   ExportOptions
+	bands     ValueBands
 }
 
 // NewDailyExporter creates a new daily report exporter
 func NewDailyExporter(paths *config.Paths) *DailyExporter {
+	opts := DefaultExportOptions()
+	opts.BOM = true
+	return NewDailyExporterWithOptions(paths, opts)
+}
+
+// NewDailyExporterWithOptions creates a daily exporter with custom number and date formatting
+func NewDailyExporterWithOptions(paths *config.Paths, opts ExportOptions) *DailyExporter {
 	return &DailyExporter{
 		csvWriter: NewCSVWriter(paths),
+		options:   opts,
 	}
 }
 
+// SetValueBands sets the traded-value percentile bands written to daily CSVs.
+// Without them the band columns are left blank.
+func (d *DailyExporter) SetValueBands(bands ValueBands) {
+	d.bands = bands
+}
+
 // ExportDailyReports generates daily CSV files grouped by date
 func (d *DailyExporter) ExportDailyReports(records []domain.TradeRecord, outputDir string) error {
 	// Group records by date
@@ -30,21 +45,12 @@ func (d *DailyExporter) ExportDailyReports(records []domain.TradeRecord, outputD
 		recordsByDate[dateKey] = append(recordsByDate[dateKey], record)
 	}
 	
-	// Get sorted dates
-	var dates []string
-	for date := range recordsByDate {
-		dates = append(dates, date)
-	}
-	sort.Strings(dates)
-	
-	// Export each day's data
-	for _, dateKey := range dates {
+	// Export each day's data in date order
+	for _, dateKey := range SortedKeys(recordsByDate) {
 		dayRecords := recordsByDate[dateKey]
 		
 		// Sort by symbol for consistent output
-		sort.Slice(dayRecords, func(i, j int) bool {
-			return dayRecords[i].CompanySymbol < dayRecords[j].CompanySymbol
-		})
+		SortTradeRecords(dayRecords)
 		
 		// Generate filename
 		filename := fmt.Sprintf("isx_daily_%s.csv", dateKey)
@@ -53,11 +59,16 @@ func (d *DailyExporter) ExportDailyReports(records []domain.TradeRecord, outputD
 		// Convert records to CSV format
 		var csvRecords [][]string
 		for _, record := range dayRecords {
-			csvRecords = append(csvRecords, d.recordToCSVRow(record))
+			csvRecords = append(csvRecords, d.dailyRow(record))
 		}
 		
 		// Write CSV file
-		if err := d.csvWriter.WriteSimpleCSV(filePath, d.getHeaders(), csvRecords); err != nil {
+		if err := d.csvWriter.WriteCSV(filePath, WriteOptions{
+			Headers:        DailyReportHeaders(),
+			Records:        csvRecords,
+			BOMPrefix:      d.options.BOM,
+			EscapeFormulas: d.options.BOM,
+		}); err != nil {
 			return fmt.Errorf("failed to write daily report for %s: %w", dateKey, err)
 		}
 	}
@@ -68,12 +79,7 @@ func (d *DailyExporter) ExportDailyReports(records []domain.TradeRecord, outputD
 // ExportCombinedData exports all records to a single combined CSV file
 func (d *DailyExporter) ExportCombinedData(records []domain.TradeRecord, outputPath string) error {
 	// Sort records by date and symbol
-	sort.Slice(records, func(i, j int) bool {
-		if records[i].Date.Equal(records[j].Date) {
-			return records[i].CompanySymbol < records[j].CompanySymbol
-		}
-		return records[i].Date.Before(records[j].Date)
-	})
+	SortTradeRecords(records)
 	
 	// Convert all records to CSV format
 	var csvRecords [][]string
@@ -82,12 +88,15 @@ func (d *DailyExporter) ExportCombinedData(records []domain.TradeRecord, outputP
 	}
 	
 	// Write combined CSV file without BOM for better compatibility with analysis tools
-	return d.csvWriter.WriteCSV(outputPath, WriteOptions{
+	if err := d.csvWriter.WriteCSV(outputPath, WriteOptions{
 		Headers: d.getHeaders(),
 		Records: csvRecords,
 		Append: false,
 		BOMPrefix: false, // No BOM for combined CSV to avoid parsing issues
-	})
+	}); err != nil {
+		return err
+	}
+	return WriteSchemaManifest(d.csvWriter.resolvePath(outputPath), len(csvRecords))
 }
 
 // ExportDailyReportsStreaming exports daily reports using streaming for large datasets
@@ -99,31 +108,31 @@ func (d *DailyExporter) ExportDailyReportsStreaming(records []domain.TradeRecord
 		recordsByDate[dateKey] = append(recordsByDate[dateKey], record)
 	}
 	
-	// Process each date
-	for dateKey, dayRecords := range recordsByDate {
+	// Process each date in order
+	for _, dateKey := range SortedKeys(recordsByDate) {
+		dayRecords := recordsByDate[dateKey]
+		
 		// Skip if already exists
 		if existingDates != nil && existingDates[dateKey] {
 			continue
 		}
 		
 		// Sort by symbol
-		sort.Slice(dayRecords, func(i, j int) bool {
-			return dayRecords[i].CompanySymbol < dayRecords[j].CompanySymbol
-		})
+		SortTradeRecords(dayRecords)
 		
 		// Generate filename
 		filename := fmt.Sprintf("isx_daily_%s.csv", dateKey)
 		filePath := filepath.Join(outputDir, filename)
 		
 		// Create stream writer
-		stream, err := d.csvWriter.CreateStreamWriter(filePath, d.getHeaders())
+		stream, err := d.csvWriter.CreateStreamWriterWithBOM(filePath, DailyReportHeaders(), d.options.BOM)
 		if err != nil {
 			return fmt.Errorf("failed to create stream writer for %s: %w", dateKey, err)
 		}
 		
 		// Write records
 		for _, record := range dayRecords {
-			if err := stream.WriteRecord(d.recordToCSVRow(record)); err != nil {
+			if err := stream.WriteRecord(d.dailyRow(record)); err != nil {
 				stream.Close()
 				return fmt.Errorf("failed to write record: %w", err)
 			}
@@ -140,32 +149,17 @@ func (d *DailyExporter) ExportDailyReportsStreaming(records []domain.TradeRecord
 
 // getHeaders returns the CSV headers for trade records
 func (d *DailyExporter) getHeaders() []string {
-	return []string{
-		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
-		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
-	}
+	return append([]string(nil), TradeRecordHeaders...)
+}
+
+// dailyRow converts a trade record to a daily CSV row with its value band
+func (d *DailyExporter) dailyRow(record domain.TradeRecord) []string {
+	band, ok := d.bands.Get(record.CompanySymbol, record.Date)
+	return append(d.recordToCSVRow(record), d.options.FormatValueBand(band, ok)...)
 }
 
 // recordToCSVRow converts a trade record to a CSV row
 func (d *DailyExporter) recordToCSVRow(record domain.TradeRecord) []string {
-	return []string{
-		record.Date.Format("2006-01-02"),
-		record.CompanyName,
-		record.CompanySymbol,
-		formatFloat(record.OpenPrice),
-		formatFloat(record.HighPrice),
-		formatFloat(record.LowPrice),
-		formatFloat(record.AveragePrice),
-		formatFloat(record.PrevAveragePrice),
-		formatFloat(record.ClosePrice),
-		formatFloat(record.PrevClosePrice),
-		formatFloat(record.Change),
-		formatFloat(record.ChangePercent),
-		formatInt(record.NumTrades),
-		formatInt(record.Volume),
-		formatFloat(record.Value),
-		formatBool(record.TradingStatus),
-	}
+	return d.options.FormatTradeRecord(record)
 }
 
@@ -132,7 +132,7 @@ func TestDailyExporter_GetHeaders(t *testing.T) {
 	expectedHeaders := []string{
 		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
 		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
+		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus", "FillMethod",
 	}
 	
 	assert.Equal(t, expectedHeaders, headers)
@@ -180,6 +180,7 @@ func TestDailyExporter_RecordToCSVRow(t *testing.T) {
 		"75000",
 		"7687500",
 		"true",
+		"actual",
 	}
 	
 	assert.Equal(t, expectedRow, csvRow)
@@ -239,7 +240,7 @@ func TestDailyExporter_ExportDailyReports(t *testing.T) {
 				assert.Len(t, lines, 3)
 				
 				// Check header
-				expectedHeader := "Date,CompanyName,Symbol,OpenPrice,HighPrice,LowPrice,AveragePrice,PrevAveragePrice,ClosePrice,PrevClosePrice,Change,ChangePercent,NumTrades,Volume,Value,TradingStatus"
+				expectedHeader := "Date,CompanyName,Symbol,OpenPrice,HighPrice,LowPrice,AveragePrice,PrevAveragePrice,ClosePrice,PrevClosePrice,Change,ChangePercent,NumTrades,Volume,Value,TradingStatus,FillMethod,ValuePercentile60D,ValueP10_60D,ValueP50_60D,ValueP90_60D"
 				assert.Equal(t, expectedHeader, lines[0])
 				
 				// Records should be sorted by symbol (AAPL before MSFT)
@@ -328,7 +329,7 @@ func TestDailyExporter_ExportCombinedData(t *testing.T) {
 	expectedHeaders := []string{
 		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
 		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
+		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus", "FillMethod",
 	}
 	assert.Equal(t, expectedHeaders, allRecords[0])
 
@@ -11,6 +11,10 @@
 // TickerExporter: Manages ticker-specific exports including individual ticker
 // history files and summary statistics.
 //
+// Every export writes trade records in date, then symbol order (see
+// SortTradeRecords) and ticker summaries in symbol order, regardless of the
+// order records are passed in, so repeated runs produce identical files.
+//
 // Example usage:
 //
 //	// Create a daily exporter
@@ -0,0 +1,159 @@
+package exporter
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/pkg/contracts/domain"
+)
+
+// Run with -update to rewrite testdata/golden after an intended format change
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+// goldenRecords is a small market: three sessions, three tickers, a
+// forward-filled day and a ticker that starts trading mid-range
+func goldenRecords() []domain.TradeRecord {
+	d := func(day int) time.Time { return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC) }
+	return []domain.TradeRecord{
+		{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: d(5), OpenPrice: 1.25, HighPrice: 1.3, LowPrice: 1.24, AveragePrice: 1.27, ClosePrice: 1.28, PrevClosePrice: 1.25, Change: 0.03, ChangePercent: 2.4, NumTrades: 12, Volume: 150000, Value: 190500, TradingStatus: true},
+		{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: d(6), OpenPrice: 1.28, HighPrice: 1.28, LowPrice: 1.28, AveragePrice: 1.28, ClosePrice: 1.28, PrevClosePrice: 1.28, TradingStatus: false, FillMethod: domain.FillMethodCarryForward},
+		{CompanyName: "Bank of Baghdad", CompanySymbol: "BBOB", Date: d(7), OpenPrice: 1.29, HighPrice: 1.33, LowPrice: 1.29, AveragePrice: 1.31, ClosePrice: 1.32, PrevClosePrice: 1.28, Change: 0.04, ChangePercent: 3.13, NumTrades: 8, Volume: 90000, Value: 117900, TradingStatus: true},
+		{CompanyName: "Asiacell", CompanySymbol: "TASC", Date: d(5), OpenPrice: 8.1, HighPrice: 8.2, LowPrice: 8.05, AveragePrice: 8.12, ClosePrice: 8.15, PrevClosePrice: 8.1, Change: 0.05, ChangePercent: 0.62, NumTrades: 30, Volume: 20000, Value: 162400, TradingStatus: true},
+		{CompanyName: "Asiacell", CompanySymbol: "TASC", Date: d(6), OpenPrice: 8.15, HighPrice: 8.3, LowPrice: 8.1, AveragePrice: 8.2, ClosePrice: 8.25, PrevClosePrice: 8.15, Change: 0.1, ChangePercent: 1.23, NumTrades: 25, Volume: 18000, Value: 147600, TradingStatus: true},
+		{CompanyName: "Asiacell", CompanySymbol: "TASC", Date: d(7), OpenPrice: 8.25, HighPrice: 8.25, LowPrice: 8.0, AveragePrice: 8.1, ClosePrice: 8.05, PrevClosePrice: 8.25, Change: -0.2, ChangePercent: -2.42, NumTrades: 40, Volume: 30000, Value: 243000, TradingStatus: true},
+		{CompanyName: "Ashur International Bank", CompanySymbol: "BASH", Date: d(6), OpenPrice: 0.5, HighPrice: 0.52, LowPrice: 0.5, AveragePrice: 0.51, ClosePrice: 0.51, PrevClosePrice: 0.5, Change: 0.01, ChangePercent: 2, NumTrades: 3, Volume: 500000, Value: 255000, TradingStatus: true},
+		{CompanyName: "Ashur International Bank", CompanySymbol: "BASH", Date: d(7), OpenPrice: 0.51, HighPrice: 0.51, LowPrice: 0.51, AveragePrice: 0.51, ClosePrice: 0.51, PrevClosePrice: 0.51, TradingStatus: false, FillMethod: domain.FillMethodCarryForward},
+	}
+}
+
+// shuffledInputs returns the golden records in several input orders
+func shuffledInputs() map[string][]domain.TradeRecord {
+	inputs := map[string][]domain.TradeRecord{"as listed": goldenRecords()}
+
+	reversed := goldenRecords()
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	inputs["reversed"] = reversed
+
+	for _, seed := range []int64{1, 7, 42} {
+		shuffled := goldenRecords()
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		inputs[fmt.Sprintf("shuffled seed %d", seed)] = shuffled
+	}
+	return inputs
+}
+
+// assertGoldenDir compares every file under dir with testdata/golden/name,
+// or rewrites the golden files when -update is set
+func assertGoldenDir(t *testing.T, dir, name string) {
+	t.Helper()
+	goldenDir := filepath.Join("testdata", "golden", name)
+
+	if *updateGolden {
+		require.NoError(t, os.RemoveAll(goldenDir))
+		require.NoError(t, os.MkdirAll(goldenDir, 0755))
+		for _, file := range listFiles(t, dir) {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(goldenDir, file), data, 0644))
+		}
+		return
+	}
+
+	require.Equal(t, listFiles(t, goldenDir), listFiles(t, dir), "exported files")
+	for _, file := range listFiles(t, dir) {
+		want, err := os.ReadFile(filepath.Join(goldenDir, file))
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s differs from its golden file", file)
+	}
+}
+
+// listFiles returns the sorted names of the files in dir
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGoldenExports(t *testing.T) {
+	for name, records := range shuffledInputs() {
+		t.Run(name, func(t *testing.T) {
+			paths := &config.Paths{ReportsDir: t.TempDir()}
+
+			combinedDir := t.TempDir()
+			combinedPath := filepath.Join(combinedDir, "isx_combined_data.csv")
+			require.NoError(t, NewDailyExporter(paths).ExportCombinedData(
+				append([]domain.TradeRecord(nil), records...), combinedPath))
+			manifest, err := ReadSchemaManifest(combinedPath)
+			require.NoError(t, err)
+			require.NotNil(t, manifest)
+			assert.Equal(t, CombinedSchemaVersion, manifest.SchemaVersion)
+			// The manifest is timestamped, so it is not part of the golden files
+			require.NoError(t, os.Remove(SchemaManifestPath(combinedPath)))
+			assertGoldenDir(t, combinedDir, "combined")
+
+			dailyDir := t.TempDir()
+			require.NoError(t, NewDailyExporter(paths).ExportDailyReports(
+				append([]domain.TradeRecord(nil), records...), dailyDir))
+			assertGoldenDir(t, dailyDir, "daily")
+
+			streamingDir := t.TempDir()
+			require.NoError(t, NewDailyExporter(paths).ExportDailyReportsStreaming(
+				append([]domain.TradeRecord(nil), records...), streamingDir, nil))
+			assertGoldenDir(t, streamingDir, "daily")
+
+			tickerDir := t.TempDir()
+			tickers := NewTickerExporter(paths)
+			require.NoError(t, tickers.ExportTickerFiles(append([]domain.TradeRecord(nil), records...), tickerDir))
+			require.NoError(t, tickers.ExportTickerSummary(
+				tickers.GenerateTickerSummaries(append([]domain.TradeRecord(nil), records...)),
+				filepath.Join(tickerDir, "ticker_summary.csv")))
+			assertGoldenDir(t, tickerDir, "ticker")
+		})
+	}
+}
+
+func TestSortTradeRecords(t *testing.T) {
+	d := func(day int) time.Time { return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC) }
+	records := []domain.TradeRecord{
+		{CompanySymbol: "TASC", Date: d(6)},
+		{CompanySymbol: "BBOB", Date: d(6), ClosePrice: 1},
+		{CompanySymbol: "BBOB", Date: d(5)},
+		{CompanySymbol: "BBOB", Date: d(6), ClosePrice: 2},
+	}
+
+	sorted := SortedTradeRecords(records)
+	assert.Equal(t, "TASC", records[0].CompanySymbol, "the input is not modified")
+
+	var got []string
+	for _, r := range sorted {
+		got = append(got, r.Date.Format("02")+" "+r.CompanySymbol)
+	}
+	assert.Equal(t, []string{"05 BBOB", "06 BBOB", "06 BBOB", "06 TASC"}, got)
+	assert.Equal(t, 1.0, sorted[1].ClosePrice, "duplicates keep their input order")
+
+	assert.Equal(t, []string{"a", "b", "c"}, SortedKeys(map[string]int{"c": 1, "a": 2, "b": 3}))
+}
@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"isxcli/internal/i18n"
+)
+
+// LocalizeCSV copies a report CSV from r to w in locale: headers translated
+// and numeric cells in the locale's digits. Localized files are meant for
+// spreadsheets, so a UTF-8 BOM comes first when the locale needs one and
+// every cell is escaped against formula injection.
+func LocalizeCSV(w io.Writer, r io.Reader, locale i18n.Locale) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if locale.NeedsBOM() {
+		if _, err := io.WriteString(w, i18n.UTF8BOM); err != nil {
+			return err
+		}
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(EscapeFormulas(localizeHeader(header, locale))); err != nil {
+		return err
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		localizeRecord(record, locale)
+		if err := writer.Write(EscapeFormulas(record)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// localizeHeader translates a header row, dropping the BOM a stored file may
+// carry in its first cell
+func localizeHeader(header []string, locale i18n.Locale) []string {
+	out := locale.Headers(header)
+	if len(out) > 0 {
+		out[0] = strings.TrimPrefix(out[0], i18n.UTF8BOM)
+	}
+	return out
+}
+
+// localizeRecord rewrites the numeric cells of a row in the locale's digits
+func localizeRecord(record []string, locale i18n.Locale) {
+	if locale.Digits != i18n.ArabicDigits {
+		return
+	}
+	for i, cell := range record {
+		record[i] = locale.Number(cell)
+	}
+}
@@ -0,0 +1,50 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/i18n"
+)
+
+func TestLocalizeCSV(t *testing.T) {
+	in := "\ufeffDate,Symbol,ClosePrice,ChangePercent,Note\n2025-08-26,BBOB,1.250,-2.5,=SUM(A1:A2)\n"
+
+	var arabic bytes.Buffer
+	require.NoError(t, LocalizeCSV(&arabic, strings.NewReader(in), i18n.Locale{Language: i18n.Arabic, Digits: i18n.ArabicDigits}))
+	assert.Equal(t, i18n.UTF8BOM+
+		"التاريخ,الرمز,سعر الإغلاق,نسبة التغير %,Note\n"+
+		"2025-08-26,BBOB,١٫٢٥٠,\u200e-٢٫٥,'=SUM(A1:A2)\n", arabic.String())
+
+	var latin bytes.Buffer
+	require.NoError(t, LocalizeCSV(&latin, strings.NewReader(in), i18n.Locale{Language: i18n.Arabic}))
+	assert.Contains(t, latin.String(), "2025-08-26,BBOB,1.250,-2.5,", "Latin digits stay numeric for spreadsheets")
+	assert.Equal(t, 1, strings.Count(latin.String(), i18n.UTF8BOM), "the stored BOM is not repeated")
+
+	var empty bytes.Buffer
+	require.NoError(t, LocalizeCSV(&empty, strings.NewReader(""), i18n.Locale{Language: i18n.Arabic}))
+	assert.Empty(t, empty.String())
+}
+
+func TestBundleLocale(t *testing.T) {
+	paths := newBundlePaths(t)
+	spec := BundleSpec{
+		From:      time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		Artifacts: []BundleArtifact{BundleDaily, BundleLiquidity},
+		Tickers:   []string{"BBOB"},
+		Locale:    i18n.Locale{Language: i18n.Arabic, Digits: i18n.ArabicDigits},
+	}
+	bundle, err := PlanBundle(paths, spec, DefaultExportOptions())
+	require.NoError(t, err)
+
+	files := readBundle(t, bundle)
+	assert.Equal(t, i18n.UTF8BOM+"التاريخ,الرمز,Close\n2025-01-05,BBOB,١٫١\n", files["daily/isx_daily_2025_01_05.csv"],
+		"rows are still filtered by the English column names")
+	assert.Equal(t, i18n.UTF8BOM+"الرمز,score\nBBOB,٧٠\n", files["liquidity/liquidity_scores_2025-01-06.csv"])
+}
@@ -0,0 +1,162 @@
+package exporter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/pkg/contracts/domain"
+	"isxcli/pkg/contracts/schema"
+)
+
+// Column kinds used to pick default decimal places
+const (
+	ColumnPrice   = "price"
+	ColumnValue   = "value"
+	ColumnPercent = "percent"
+)
+
+// TradeRecordHeaders is the column layout shared by daily, combined and ticker
+// CSVs. Readers resolve it by name through schema.Mapping.
+var TradeRecordHeaders = schema.TradeColumns
+
+// ExportOptions controls number and date formatting for CSV writers
+type ExportOptions struct {
+	PriceDecimals      int
+	ValueDecimals      int
+	PercentDecimals    int
+	ColumnDecimals     map[string]int // Overrides keyed by CSV column name
+	ThousandsSeparator string         // Empty disables grouping
+	DateFormat         string
+	BOM                bool // Prefix files with a UTF-8 BOM for Excel
+}
+
+// DefaultExportOptions returns the formatting used before options were configurable
+func DefaultExportOptions() ExportOptions {
+	return ExportOptions{
+		PriceDecimals:   3,
+		ValueDecimals:   2,
+		PercentDecimals: 2,
+		DateFormat:      "2006-01-02",
+	}
+}
+
+// ExportOptionsFromConfig builds export options from the application config
+func ExportOptionsFromConfig(cfg config.ExportConfig) ExportOptions {
+	opts := ExportOptions{
+		PriceDecimals:      cfg.PriceDecimals,
+		ValueDecimals:      cfg.ValueDecimals,
+		PercentDecimals:    cfg.PercentDecimals,
+		ColumnDecimals:     cfg.ColumnDecimals,
+		ThousandsSeparator: cfg.ThousandsSeparator,
+		DateFormat:         cfg.DateFormat,
+		BOM:                cfg.BOM,
+	}
+	if opts.DateFormat == "" {
+		opts.DateFormat = DefaultExportOptions().DateFormat
+	}
+	return opts
+}
+
+// Decimals returns the decimal places for a column, honouring per-column overrides
+func (o ExportOptions) Decimals(column, kind string) int {
+	if d, ok := o.ColumnDecimals[column]; ok {
+		return d
+	}
+	switch kind {
+	case ColumnValue:
+		return o.ValueDecimals
+	case ColumnPercent:
+		return o.PercentDecimals
+	default:
+		return o.PriceDecimals
+	}
+}
+
+// FormatFloat formats a float for the named column
+func (o ExportOptions) FormatFloat(column, kind string, v float64) string {
+	return o.group(strconv.FormatFloat(v, 'f', o.Decimals(column, kind), 64))
+}
+
+// FormatInt formats an integer, applying the thousands separator if set
+func (o ExportOptions) FormatInt(v int64) string {
+	return o.group(strconv.FormatInt(v, 10))
+}
+
+// FormatDate formats a date using the configured layout
+func (o ExportOptions) FormatDate(t time.Time) string {
+	if o.DateFormat == "" {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(o.DateFormat)
+}
+
+// FormatTradeRecord converts a trade record to a row matching TradeRecordHeaders
+func (o ExportOptions) FormatTradeRecord(record domain.TradeRecord) []string {
+	return []string{
+		o.FormatDate(record.Date),
+		record.CompanyName,
+		record.CompanySymbol,
+		o.FormatFloat("OpenPrice", ColumnPrice, record.OpenPrice),
+		o.FormatFloat("HighPrice", ColumnPrice, record.HighPrice),
+		o.FormatFloat("LowPrice", ColumnPrice, record.LowPrice),
+		o.FormatFloat("AveragePrice", ColumnPrice, record.AveragePrice),
+		o.FormatFloat("PrevAveragePrice", ColumnPrice, record.PrevAveragePrice),
+		o.FormatFloat("ClosePrice", ColumnPrice, record.ClosePrice),
+		o.FormatFloat("PrevClosePrice", ColumnPrice, record.PrevClosePrice),
+		o.FormatFloat("Change", ColumnPrice, record.Change),
+		o.FormatFloat("ChangePercent", ColumnPercent, record.ChangePercent),
+		o.FormatInt(record.NumTrades),
+		o.FormatInt(record.Volume),
+		o.FormatFloat("Value", ColumnValue, record.Value),
+		formatBool(record.TradingStatus),
+		fillMethod(record),
+	}
+}
+
+// fillMethod returns the FillMethod column for a record. Records that never
+// went through a fill strategy are actual when traded and carried forward
+// otherwise, matching the processor's behaviour before fills were pluggable.
+func fillMethod(record domain.TradeRecord) string {
+	switch {
+	case record.FillMethod != "":
+		return record.FillMethod
+	case record.TradingStatus:
+		return domain.FillMethodActual
+	default:
+		return domain.FillMethodCarryForward
+	}
+}
+
+// group inserts the thousands separator into the integer part of a number
+func (o ExportOptions) group(s string) string {
+	if o.ThousandsSeparator == "" {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac := s, ""
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		intPart, frac = s[:idx], s[idx:]
+	}
+	if len(intPart) <= 3 {
+		return sign + s
+	}
+
+	var b strings.Builder
+	lead := len(intPart) % 3
+	if lead > 0 {
+		b.WriteString(intPart[:lead])
+	}
+	for i := lead; i < len(intPart); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(o.ThousandsSeparator)
+		}
+		b.WriteString(intPart[i : i+3])
+	}
+	return sign + b.String() + frac
+}
@@ -0,0 +1,106 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/pkg/contracts/domain"
+)
+
+func TestExportOptions_FormatFloat(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ExportOptions
+		column string
+		kind   string
+		input  float64
+		want   string
+	}{
+		{"default price", DefaultExportOptions(), "ClosePrice", ColumnPrice, 1.5, "1.500"},
+		{"default value", DefaultExportOptions(), "Value", ColumnValue, 1234567.891, "1234567.89"},
+		{"default percent", DefaultExportOptions(), "ChangePercent", ColumnPercent, -3.14159, "-3.14"},
+		{"column override", ExportOptions{PriceDecimals: 3, ColumnDecimals: map[string]int{"ClosePrice": 1}}, "ClosePrice", ColumnPrice, 1.26, "1.3"},
+		{"thousands separator", ExportOptions{ValueDecimals: 2, ThousandsSeparator: ","}, "Value", ColumnValue, 1234567.891, "1,234,567.89"},
+		{"negative grouped", ExportOptions{ValueDecimals: 0, ThousandsSeparator: " "}, "Value", ColumnValue, -1234, "-1 234"},
+		{"short number not grouped", ExportOptions{ValueDecimals: 1, ThousandsSeparator: ","}, "Value", ColumnValue, 999, "999.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.FormatFloat(tt.column, tt.kind, tt.input))
+		})
+	}
+}
+
+func TestExportOptions_FormatTradeRecord(t *testing.T) {
+	record := domain.TradeRecord{
+		Date:          time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		CompanyName:   "Bank of Baghdad",
+		CompanySymbol: "BBOB",
+		ClosePrice:    1.25,
+		ChangePercent: 2.5,
+		Volume:        1500000,
+		Value:         1875000,
+		TradingStatus: true,
+	}
+
+	row := DefaultExportOptions().FormatTradeRecord(record)
+	require.Len(t, row, len(TradeRecordHeaders))
+	assert.Equal(t, "2025-01-05", row[0])
+	assert.Equal(t, "1.250", row[8])
+	assert.Equal(t, "2.50", row[11])
+	assert.Equal(t, "1500000", row[13])
+	assert.Equal(t, "1875000.00", row[14])
+	assert.Equal(t, "true", row[15])
+	assert.Equal(t, domain.FillMethodActual, row[16])
+
+	// Untraded rows without a recorded method were carried forward
+	record.TradingStatus = false
+	assert.Equal(t, domain.FillMethodCarryForward, DefaultExportOptions().FormatTradeRecord(record)[16])
+	record.FillMethod = domain.FillMethodInterpolated
+	assert.Equal(t, domain.FillMethodInterpolated, DefaultExportOptions().FormatTradeRecord(record)[16])
+	record.TradingStatus, record.FillMethod = true, ""
+
+	opts := ExportOptions{PriceDecimals: 2, ValueDecimals: 0, PercentDecimals: 1, ThousandsSeparator: ",", DateFormat: "02/01/2006"}
+	row = opts.FormatTradeRecord(record)
+	assert.Equal(t, "05/01/2025", row[0])
+	assert.Equal(t, "1.25", row[8])
+	assert.Equal(t, "1,500,000", row[13])
+	assert.Equal(t, "1,875,000", row[14])
+}
+
+func TestExportOptionsFromConfig(t *testing.T) {
+	opts := ExportOptionsFromConfig(config.Default().Export)
+	assert.Equal(t, DefaultExportOptions(), opts)
+
+	opts = ExportOptionsFromConfig(config.ExportConfig{PriceDecimals: 4, BOM: true})
+	assert.Equal(t, 4, opts.PriceDecimals)
+	assert.True(t, opts.BOM)
+	assert.Equal(t, "2006-01-02", opts.DateFormat, "empty date format falls back to ISO")
+}
+
+func TestDailyExporter_WithOptions_BOM(t *testing.T) {
+	tmpDir := t.TempDir()
+	paths := &config.Paths{ReportsDir: tmpDir}
+	records := []domain.TradeRecord{
+		{Date: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), CompanySymbol: "BBOB", ClosePrice: 1.25},
+	}
+
+	for _, bom := range []bool{true, false} {
+		opts := DefaultExportOptions()
+		opts.BOM = bom
+		outDir := filepath.Join(tmpDir, map[bool]string{true: "bom", false: "plain"}[bom])
+
+		require.NoError(t, NewDailyExporterWithOptions(paths, opts).ExportDailyReports(records, outDir))
+
+		data, err := os.ReadFile(filepath.Join(outDir, "isx_daily_2025_01_05.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, bom, len(data) >= 3 && string(data[:3]) == "\xEF\xBB\xBF")
+	}
+}
@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"sort"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// SortTradeRecords orders records by date, then symbol, in place. The sort is
+// stable, so duplicate date/symbol rows keep their input order. Every trade
+// record export is written in this order, which keeps files byte-identical
+// between runs over the same data.
+func SortTradeRecords(records []domain.TradeRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return tradeRecordLess(records[i], records[j])
+	})
+}
+
+// SortedTradeRecords returns a copy of records in export order, leaving the
+// input untouched
+func SortedTradeRecords(records []domain.TradeRecord) []domain.TradeRecord {
+	sorted := append([]domain.TradeRecord(nil), records...)
+	SortTradeRecords(sorted)
+	return sorted
+}
+
+// SortedKeys returns a map's keys in ascending order, for writers that group
+// records by date or ticker before writing one file per group
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tradeRecordLess compares records by date, then symbol
+func tradeRecordLess(a, b domain.TradeRecord) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.Before(b.Date)
+	}
+	return a.CompanySymbol < b.CompanySymbol
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import "strings"
+
+// formulaPrefixes are the leading characters that make Excel, LibreOffice
+// and Google Sheets treat a CSV cell as a formula. Tab and carriage return
+// are included because some importers strip them before checking.
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula neutralises a cell that a spreadsheet would evaluate as a
+// formula by prefixing it with a single quote, which spreadsheets display as
+// text. Numbers such as -12.5 or -1,250 are left alone so signed values stay
+// numeric.
+func EscapeFormula(cell string) string {
+	if cell == "" || !strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return cell
+	}
+	if isNumericCell(cell) {
+		return cell
+	}
+	return "'" + cell
+}
+
+// EscapeFormulas returns a copy of the record with every cell passed through
+// EscapeFormula
+func EscapeFormulas(record []string) []string {
+	escaped := make([]string, len(record))
+	for i, cell := range record {
+		escaped[i] = EscapeFormula(cell)
+	}
+	return escaped
+}
+
+// isNumericCell reports whether a cell is a signed decimal number or percentage,
+// allowing the thousands separators export options may add
+func isNumericCell(cell string) bool {
+	if cell[0] != '-' && cell[0] != '+' {
+		return false
+	}
+	digits, points := 0, 0
+	for _, r := range cell[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.':
+			points++
+		case r == ',' || r == ' ' || r == '\'' || r == '\u00a0' || r == '%':
+		default:
+			return false
+		}
+	}
+	return digits > 0 && points <= 1
+}
@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		name     string
+		cell     string
+		expected string
+	}{
+		{"plain text", "Bank of Baghdad", "Bank of Baghdad"},
+		{"empty", "", ""},
+		{"formula", "=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"plus formula", "+1+cmd|' /C calc'!A0", "'+1+cmd|' /C calc'!A0"},
+		{"minus formula", "-2+3", "'-2+3"},
+		{"at function", "@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"leading tab", "\t=1+1", "'\t=1+1"},
+		{"negative number", "-12.5", "-12.5"},
+		{"grouped negative number", "-1,250.00", "-1,250.00"},
+		{"negative percentage", "-3.25%", "-3.25%"},
+		{"signed positive number", "+4", "+4"},
+		{"lone minus", "-", "'-"},
+		{"two decimal points", "-1.2.3", "'-1.2.3"},
+		{"date", "2025-01-06", "2025-01-06"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EscapeFormula(tt.cell))
+		})
+	}
+}
+
+func TestEscapeFormulasCopiesRecord(t *testing.T) {
+	record := []string{"=1+1", "BBOB"}
+	escaped := EscapeFormulas(record)
+	assert.Equal(t, []string{"'=1+1", "BBOB"}, escaped)
+	assert.Equal(t, "=1+1", record[0], "the input record is not modified")
+}
+
+func TestCSVWriterEscapesExcelFiles(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewCSVWriter(nil)
+	records := [][]string{{"=cmd|' /C calc'!A0", "-1.5"}}
+
+	excel := filepath.Join(dir, "excel.csv")
+	require.NoError(t, writer.WriteSimpleCSV(excel, []string{"Name", "Change"}, records))
+	data, err := os.ReadFile(excel)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeffName,Change\n'=cmd|' /C calc'!A0,-1.5\n", string(data))
+
+	plain := filepath.Join(dir, "plain.csv")
+	require.NoError(t, writer.WriteCSV(plain, WriteOptions{Headers: []string{"Name", "Change"}, Records: records}))
+	data, err = os.ReadFile(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "Name,Change\n=cmd|' /C calc'!A0,-1.5\n", string(data), "files for analysis tools keep their cells")
+
+	stream, err := writer.CreateStreamWriter(filepath.Join(dir, "stream.csv"), []string{"Name"})
+	require.NoError(t, err)
+	require.NoError(t, stream.WriteRecord([]string{"@SUM(A1)"}))
+	require.NoError(t, stream.Close())
+	data, err = os.ReadFile(filepath.Join(dir, "stream.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeffName\n'@SUM(A1)\n", string(data))
+}
@@ -0,0 +1,258 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/pkg/contracts/domain"
+)
+
+// CombinedSchemaVersion is the layout version the combined CSV is written with.
+// Bump it, and add the previous layout to combinedSchemas with an upgrade
+// function, whenever TradeRecordHeaders changes.
+const CombinedSchemaVersion = 2
+
+// ErrUnknownSchema is returned for a combined CSV whose header matches no
+// known layout
+var ErrUnknownSchema = errors.New("unknown combined CSV schema")
+
+// ErrNewerSchema is returned for a combined CSV written by a newer release
+var ErrNewerSchema = errors.New("combined CSV was written by a newer release")
+
+// CombinedSchema is one released layout of the combined CSV
+type CombinedSchema struct {
+	Version int
+	Columns []string
+
+	// upgrade converts a row of this version to the next version
+	upgrade func(header, row []string) []string
+}
+
+// combinedSchemas lists every released layout, oldest first
+var combinedSchemas = []CombinedSchema{
+	{
+		// Before gap fill strategies were configurable
+		Version: 1,
+		Columns: TradeRecordHeaders[:16:16],
+		upgrade: addFillMethod,
+	},
+	{
+		Version: 2,
+		Columns: TradeRecordHeaders,
+	},
+}
+
+// addFillMethod derives FillMethod for a version 1 row: actual for traded
+// days, carry_forward for the rows the old processor filled
+func addFillMethod(header, row []string) []string {
+	method := domain.FillMethodCarryForward
+	if traded, _ := strconv.ParseBool(cellByName(header, row, "TradingStatus")); traded {
+		method = domain.FillMethodActual
+	}
+	return append(row, method)
+}
+
+// cellByName returns the cell of row under the named column, or ""
+func cellByName(header, row []string, name string) string {
+	for i, h := range header {
+		if h == name && i < len(row) {
+			return row[i]
+		}
+	}
+	return ""
+}
+
+// CurrentCombinedSchema returns the layout new combined CSVs are written with
+func CurrentCombinedSchema() CombinedSchema {
+	return combinedSchemas[len(combinedSchemas)-1]
+}
+
+// DetectCombinedSchema returns the layout whose columns match header. A UTF-8
+// BOM and surrounding spaces are ignored.
+func DetectCombinedSchema(header []string) (CombinedSchema, error) {
+	cleaned := make([]string, len(header))
+	for i, h := range header {
+		cleaned[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	for _, schema := range combinedSchemas {
+		if equalColumns(schema.Columns, cleaned) {
+			return schema, nil
+		}
+	}
+	return CombinedSchema{}, fmt.Errorf("%w: %d columns starting %q", ErrUnknownSchema, len(header), strings.Join(firstN(cleaned, 3), ","))
+}
+
+// ReadCombinedSchema detects the layout of the combined CSV at path from its header
+func ReadCombinedSchema(path string) (CombinedSchema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return CombinedSchema{}, err
+	}
+	defer f.Close()
+
+	header, err := csv.NewReader(f).Read()
+	if err != nil {
+		return CombinedSchema{}, fmt.Errorf("read header of %s: %w", path, err)
+	}
+	return DetectCombinedSchema(header)
+}
+
+// Upgrade converts a row of this layout to the current layout
+func (s CombinedSchema) Upgrade(row []string) ([]string, error) {
+	if len(row) != len(s.Columns) {
+		return nil, fmt.Errorf("schema v%d row has %d fields, expected %d", s.Version, len(row), len(s.Columns))
+	}
+	out := append([]string(nil), row...)
+	for _, schema := range combinedSchemas {
+		if schema.Version < s.Version || schema.upgrade == nil {
+			continue
+		}
+		out = schema.upgrade(schema.Columns, out)
+	}
+	return out, nil
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func firstN(s []string, n int) []string {
+	if len(s) < n {
+		return s
+	}
+	return s[:n]
+}
+
+// SchemaManifest is written next to a combined CSV and records its layout
+type SchemaManifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	Columns       []string  `json:"columns"`
+	Rows          int       `json:"rows"`
+	WrittenAt     time.Time `json:"written_at"`
+}
+
+// SchemaManifestPath returns where the manifest of a combined CSV is stored:
+// isx_combined_data.csv is described by isx_combined_data.schema.json
+func SchemaManifestPath(csvPath string) string {
+	return strings.TrimSuffix(csvPath, ".csv") + ".schema.json"
+}
+
+// WriteSchemaManifest records that csvPath holds rows rows in the current layout
+func WriteSchemaManifest(csvPath string, rows int) error {
+	current := CurrentCombinedSchema()
+	data, err := json.MarshalIndent(SchemaManifest{
+		SchemaVersion: current.Version,
+		Columns:       current.Columns,
+		Rows:          rows,
+		WrittenAt:     time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := SchemaManifestPath(csvPath)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("write schema manifest: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// ReadSchemaManifest returns the manifest of csvPath, or nil if it has none
+func ReadSchemaManifest(csvPath string) (*SchemaManifest, error) {
+	data, err := os.ReadFile(SchemaManifestPath(csvPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest SchemaManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse schema manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// CheckSchemaManifest fails with ErrNewerSchema when csvPath's manifest
+// records a layout this release does not know. A missing manifest passes;
+// files written before manifests existed are recognised by their header.
+func CheckSchemaManifest(csvPath string) error {
+	manifest, err := ReadSchemaManifest(csvPath)
+	if err != nil {
+		return err
+	}
+	if manifest != nil && manifest.SchemaVersion > CombinedSchemaVersion {
+		return fmt.Errorf("%w: schema v%d, this release reads up to v%d", ErrNewerSchema, manifest.SchemaVersion, CombinedSchemaVersion)
+	}
+	return nil
+}
+
+// MigrateCombinedCSV rewrites a combined CSV in the current layout and writes
+// its manifest. It returns the version the file had; a file already current
+// only gets its manifest. The file is replaced atomically and keeps its BOM.
+func MigrateCombinedCSV(path string) (int, error) {
+	if err := CheckSchemaManifest(path); err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	bom := bytes.HasPrefix(data, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("%w: %s is empty", ErrUnknownSchema, path)
+	}
+
+	schema, err := DetectCombinedSchema(rows[0])
+	if err != nil {
+		return 0, err
+	}
+	if schema.Version == CombinedSchemaVersion {
+		return schema.Version, WriteSchemaManifest(path, len(rows)-1)
+	}
+
+	rows[0] = CurrentCombinedSchema().Columns
+	for i := 1; i < len(rows); i++ {
+		if rows[i], err = schema.Upgrade(rows[i]); err != nil {
+			return 0, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if bom {
+		buf.WriteString("\ufeff")
+	}
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return 0, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, err
+	}
+	return schema.Version, WriteSchemaManifest(path, len(rows)-1)
+}
@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const v1Combined = "\ufeffDate,CompanyName,Symbol,OpenPrice,HighPrice,LowPrice,AveragePrice,PrevAveragePrice,ClosePrice,PrevClosePrice,Change,ChangePercent,NumTrades,Volume,Value,TradingStatus\n" +
+	"2025-01-05,Bank of Baghdad,BBOB,1.000,1.100,0.900,1.000,1.000,1.050,1.000,0.050,5.00,10,1000,1050.00,true\n" +
+	"2025-01-06,Bank of Baghdad,BBOB,1.050,1.050,1.050,1.050,1.000,1.050,1.050,0.000,0.00,0,0,0.00,false\n"
+
+func TestDetectCombinedSchema(t *testing.T) {
+	schema, err := DetectCombinedSchema(append([]string{"\ufeffDate"}, TradeRecordHeaders[1:16]...))
+	require.NoError(t, err)
+	assert.Equal(t, 1, schema.Version)
+
+	schema, err = DetectCombinedSchema(TradeRecordHeaders)
+	require.NoError(t, err)
+	assert.Equal(t, CombinedSchemaVersion, schema.Version)
+	assert.Equal(t, CurrentCombinedSchema().Version, schema.Version)
+
+	_, err = DetectCombinedSchema([]string{"Date", "Ticker", "Close"})
+	assert.ErrorIs(t, err, ErrUnknownSchema)
+}
+
+func TestCombinedSchemaUpgrade(t *testing.T) {
+	v1, err := DetectCombinedSchema(TradeRecordHeaders[:16])
+	require.NoError(t, err)
+
+	row := strings.Split("2025-01-06,Bank,BBOB,1,1,1,1,1,1,1,0,0,0,0,0,false", ",")
+	upgraded, err := v1.Upgrade(row)
+	require.NoError(t, err)
+	assert.Len(t, upgraded, len(TradeRecordHeaders))
+	assert.Equal(t, "carry_forward", upgraded[16])
+	assert.Len(t, row, 16, "the input row is not modified")
+
+	_, err = v1.Upgrade(row[:10])
+	assert.Error(t, err, "rows of the wrong width are rejected")
+}
+
+func TestMigrateCombinedCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "isx_combined_data.csv")
+	require.NoError(t, os.WriteFile(path, []byte(v1Combined), 0644))
+
+	from, err := MigrateCombinedCSV(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, from)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "\ufeff"+strings.Join(TradeRecordHeaders, ","), lines[0], "the BOM is kept")
+	assert.True(t, strings.HasSuffix(lines[1], ",true,actual"))
+	assert.True(t, strings.HasSuffix(lines[2], ",false,carry_forward"))
+
+	manifest, err := ReadSchemaManifest(path)
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	assert.Equal(t, CombinedSchemaVersion, manifest.SchemaVersion)
+	assert.Equal(t, 2, manifest.Rows)
+	assert.FileExists(t, filepath.Join(filepath.Dir(path), "isx_combined_data.schema.json"))
+
+	// A current file only gets its manifest
+	from, err = MigrateCombinedCSV(path)
+	require.NoError(t, err)
+	assert.Equal(t, CombinedSchemaVersion, from)
+	again, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestCheckSchemaManifestRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "isx_combined_data.csv")
+	assert.NoError(t, CheckSchemaManifest(path), "files without a manifest pass")
+
+	require.NoError(t, os.WriteFile(SchemaManifestPath(path), []byte(`{"schema_version": 99}`), 0644))
+	assert.ErrorIs(t, CheckSchemaManifest(path), ErrNewerSchema)
+	_, err := MigrateCombinedCSV(path)
+	assert.ErrorIs(t, err, ErrNewerSchema)
+}
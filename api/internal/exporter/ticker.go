@@ -12,12 +12,21 @@ import (
 // TickerExporter handles ticker-specific report generation
 type TickerExporter struct {
 	csvWriter *CSVWriter
+	options   ExportOptions
 }
 
 // NewTickerExporter creates a new ticker report exporter
 func NewTickerExporter(paths *config.Paths) *TickerExporter {
+	opts := DefaultExportOptions()
+	opts.BOM = true
+	return NewTickerExporterWithOptions(paths, opts)
+}
+
+// NewTickerExporterWithOptions creates a ticker exporter with custom number and date formatting
+func NewTickerExporterWithOptions(paths *config.Paths, opts ExportOptions) *TickerExporter {
 	return &TickerExporter{
 		csvWriter: NewCSVWriter(paths),
+		options:   opts,
 	}
 }
 
@@ -44,12 +53,12 @@ func (t *TickerExporter) ExportTickerFiles(records []domain.TradeRecord, outputD
 		recordsByTicker[record.CompanySymbol] = append(recordsByTicker[record.CompanySymbol], record)
 	}
 	
-	// Export each ticker's data
-	for ticker, tickerRecords := range recordsByTicker {
+	// Export each ticker's data in symbol order
+	for _, ticker := range SortedKeys(recordsByTicker) {
+		tickerRecords := recordsByTicker[ticker]
+		
 		// Sort by date (oldest to newest)
-		sort.Slice(tickerRecords, func(i, j int) bool {
-			return tickerRecords[i].Date.Before(tickerRecords[j].Date)
-		})
+		SortTradeRecords(tickerRecords)
 		
 		// Generate filename
 		filename := fmt.Sprintf("%s_trading_history.csv", ticker)
@@ -62,7 +71,12 @@ func (t *TickerExporter) ExportTickerFiles(records []domain.TradeRecord, outputD
 		}
 		
 		// Write CSV file
-		if err := t.csvWriter.WriteSimpleCSV(filePath, t.getHeaders(), csvRecords); err != nil {
+		if err := t.csvWriter.WriteCSV(filePath, WriteOptions{
+			Headers:        t.getHeaders(),
+			Records:        csvRecords,
+			BOMPrefix:      t.options.BOM,
+			EscapeFormulas: t.options.BOM,
+		}); err != nil {
 			return fmt.Errorf("failed to write ticker file for %s: %w", ticker, err)
 		}
 	}
@@ -73,7 +87,7 @@ func (t *TickerExporter) ExportTickerFiles(records []domain.TradeRecord, outputD
 // ExportTickerSummary exports a summary CSV with statistics for all tickers
 func (t *TickerExporter) ExportTickerSummary(summaries []TickerSummary, outputPath string) error {
 	// Sort by ticker symbol
-	sort.Slice(summaries, func(i, j int) bool {
+	sort.SliceStable(summaries, func(i, j int) bool {
 		return summaries[i].Ticker < summaries[j].Ticker
 	})
 	
@@ -89,7 +103,12 @@ func (t *TickerExporter) ExportTickerSummary(summaries []TickerSummary, outputPa
 		"TotalVolume", "TotalValue", "AveragePrice", "HighestPrice", "LowestPrice",
 	}
 	
-	return t.csvWriter.WriteSimpleCSV(outputPath, headers, csvRecords)
+	return t.csvWriter.WriteCSV(outputPath, WriteOptions{
+		Headers:        headers,
+		Records:        csvRecords,
+		BOMPrefix:      t.options.BOM,
+		EscapeFormulas: t.options.BOM,
+	})
 }
 
 // GenerateTickerSummaries creates summary statistics from trade records
@@ -101,11 +120,11 @@ func (t *TickerExporter) GenerateTickerSummaries(records []domain.TradeRecord) [
 	}
 	
 	var summaries []TickerSummary
-	for ticker, tickerRecords := range tickerData {
+	for _, ticker := range SortedKeys(tickerData) {
+		tickerRecords := tickerData[ticker]
+		
 		// Sort by date
-		sort.Slice(tickerRecords, func(i, j int) bool {
-			return tickerRecords[i].Date.Before(tickerRecords[j].Date)
-		})
+		SortTradeRecords(tickerRecords)
 		
 		// Calculate statistics
 		summary := TickerSummary{
@@ -148,7 +167,7 @@ func (t *TickerExporter) GenerateTickerSummaries(records []domain.TradeRecord) [
 			for i := len(tickerRecords) - 1; i >= 0; i-- {
 				if tickerRecords[i].ClosePrice > 0 {
 					summary.LastPrice = tickerRecords[i].ClosePrice
-					summary.LastDate = tickerRecords[i].Date.Format("2006-01-02")
+					summary.LastDate = t.options.FormatDate(tickerRecords[i].Date)
 					break
 				}
 			}
@@ -172,33 +191,12 @@ func (t *TickerExporter) GenerateTickerSummaries(records []domain.TradeRecord) [
 // getHeaders returns the CSV headers for ticker trade records
 // Using the same format as daily CSV for consistency
 func (t *TickerExporter) getHeaders() []string {
-	return []string{
-		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
-		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
-	}
+	return append([]string(nil), TradeRecordHeaders...)
 }
 
 // recordToCSVRow converts a trade record to a ticker CSV row
 func (t *TickerExporter) recordToCSVRow(record domain.TradeRecord) []string {
-	return []string{
-		record.Date.Format("2006-01-02"),
-		record.CompanyName,
-		record.CompanySymbol,
-		formatFloat(record.OpenPrice),
-		formatFloat(record.HighPrice),
-		formatFloat(record.LowPrice),
-		formatFloat(record.AveragePrice),
-		formatFloat(record.PrevAveragePrice),
-		formatFloat(record.ClosePrice),
-		formatFloat(record.PrevClosePrice),
-		formatFloat(record.Change),
-		formatFloat(record.ChangePercent),
-		formatInt(record.NumTrades),
-		formatInt(record.Volume),
-		formatFloat(record.Value),
-		formatBool(record.TradingStatus),
-	}
+	return t.options.FormatTradeRecord(record)
 }
 
 // summaryToCSVRow converts a ticker summary to a CSV row
@@ -206,14 +204,14 @@ func (t *TickerExporter) summaryToCSVRow(summary TickerSummary) []string {
 	return []string{
 		summary.Ticker,
 		summary.CompanyName,
-		formatFloat(summary.LastPrice),
+		t.options.FormatFloat("LastPrice", ColumnPrice, summary.LastPrice),
 		summary.LastDate,
 		fmt.Sprintf("%d", summary.TradingDays),
 		summary.Last10Days,
-		formatInt(summary.TotalVolume),
-		formatFloat(summary.TotalValue),
-		formatFloat(summary.AveragePrice),
-		formatFloat(summary.HighestPrice),
-		formatFloat(summary.LowestPrice),
+		t.options.FormatInt(summary.TotalVolume),
+		t.options.FormatFloat("TotalValue", ColumnValue, summary.TotalValue),
+		t.options.FormatFloat("AveragePrice", ColumnPrice, summary.AveragePrice),
+		t.options.FormatFloat("HighestPrice", ColumnPrice, summary.HighestPrice),
+		t.options.FormatFloat("LowestPrice", ColumnPrice, summary.LowestPrice),
 	}
 }
\ No newline at end of file
@@ -120,7 +120,7 @@ func TestTickerExporter_GetHeaders(t *testing.T) {
 	expectedHeaders := []string{
 		"Date", "CompanyName", "Symbol", "OpenPrice", "HighPrice", "LowPrice",
 		"AveragePrice", "PrevAveragePrice", "ClosePrice", "PrevClosePrice",
-		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus",
+		"Change", "ChangePercent", "NumTrades", "Volume", "Value", "TradingStatus", "FillMethod",
 	}
 	
 	assert.Equal(t, expectedHeaders, headers)
@@ -168,6 +168,7 @@ func TestTickerExporter_RecordToCSVRow(t *testing.T) {
 		"75000",
 		"7687500",
 		"true",
+		"actual",
 	}
 	
 	assert.Equal(t, expectedRow, csvRow)
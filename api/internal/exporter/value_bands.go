@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"time"
+)
+
+// ValueBandHeaders are the traded-value percentile columns daily CSVs add
+// after TradeRecordHeaders
+var ValueBandHeaders = []string{"ValuePercentile60D", "ValueP10_60D", "ValueP50_60D", "ValueP90_60D"}
+
+// DailyReportHeaders returns the daily CSV column layout
+func DailyReportHeaders() []string {
+	headers := append([]string(nil), TradeRecordHeaders...)
+	return append(headers, ValueBandHeaders...)
+}
+
+// ValueBand places a day's traded value within the ticker's own recent history
+type ValueBand struct {
+	Percentile float64 // Percentile rank of the day's value, 0-100
+	P10        float64 // Value percentiles of the window
+	P50        float64
+	P90        float64
+	Days       int // Trading days in the window
+}
+
+// ValueBands holds the bands of traded days keyed by symbol and date
+type ValueBands map[string]ValueBand
+
+// ValueBandKey returns the ValueBands key of a ticker's day
+func ValueBandKey(symbol string, date time.Time) string {
+	return symbol + "|" + date.Format("2006-01-02")
+}
+
+// Get returns the band of a ticker's day
+func (b ValueBands) Get(symbol string, date time.Time) (ValueBand, bool) {
+	band, ok := b[ValueBandKey(symbol, date)]
+	return band, ok
+}
+
+// FormatValueBand converts a band to a row matching ValueBandHeaders. Days
+// without a band (not traded, or too little history) are left blank.
+func (o ExportOptions) FormatValueBand(band ValueBand, ok bool) []string {
+	if !ok {
+		return make([]string, len(ValueBandHeaders))
+	}
+	return []string{
+		o.FormatFloat("ValuePercentile60D", ColumnPercent, band.Percentile),
+		o.FormatFloat("ValueP10_60D", ColumnValue, band.P10),
+		o.FormatFloat("ValueP50_60D", ColumnValue, band.P50),
+		o.FormatFloat("ValueP90_60D", ColumnValue, band.P90),
+	}
+}
@@ -0,0 +1,38 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/pkg/contracts/domain"
+)
+
+func TestDailyExporter_ValueBands(t *testing.T) {
+	tmpDir := t.TempDir()
+	day := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	records := []domain.TradeRecord{
+		{Date: day, CompanySymbol: "BBOB", Value: 5000, TradingStatus: true},
+		{Date: day, CompanySymbol: "TASC"},
+	}
+
+	exporter := NewDailyExporterWithOptions(&config.Paths{ReportsDir: tmpDir}, DefaultExportOptions())
+	exporter.SetValueBands(ValueBands{
+		ValueBandKey("BBOB", day): {Percentile: 91.6667, P10: 1000, P50: 2500, P90: 4800, Days: 60},
+	})
+	require.NoError(t, exporter.ExportDailyReports(records, tmpDir))
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "isx_daily_2025_01_05.csv"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], ",FillMethod,ValuePercentile60D,ValueP10_60D,ValueP50_60D,ValueP90_60D"))
+	assert.True(t, strings.HasSuffix(lines[1], ",actual,91.67,1000.00,2500.00,4800.00"))
+	assert.True(t, strings.HasSuffix(lines[2], ",carry_forward,,,,"), "days without a band are left blank")
+}
@@ -0,0 +1,95 @@
+package files
+
+import "time"
+
+// maxCalendarSearchDays bounds searches for the next trading day so a
+// misconfigured calendar (every day a holiday) cannot loop forever
+const maxCalendarSearchDays = 366
+
+// NextTradingDay returns the first trading day strictly after date
+func (c *HolidayCalendar) NextTradingDay(date time.Time) (time.Time, bool) {
+	d := truncateDay(date)
+	for i := 0; i < maxCalendarSearchDays; i++ {
+		d = d.AddDate(0, 0, 1)
+		if c.IsTradingDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// PreviousTradingDay returns the last trading day strictly before date
+func (c *HolidayCalendar) PreviousTradingDay(date time.Time) (time.Time, bool) {
+	d := truncateDay(date)
+	for i := 0; i < maxCalendarSearchDays; i++ {
+		d = d.AddDate(0, 0, -1)
+		if c.IsTradingDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// AddTradingDays moves n trading days forward from a trading day; n = 0
+// returns the day itself
+func (c *HolidayCalendar) AddTradingDays(date time.Time, n int) (time.Time, bool) {
+	d := truncateDay(date)
+	for ; n > 0; n-- {
+		next, ok := c.NextTradingDay(d)
+		if !ok {
+			return time.Time{}, false
+		}
+		d = next
+	}
+	return d, true
+}
+
+// LastTradingDayOfMonth returns the last trading day in the month of date
+func (c *HolidayCalendar) LastTradingDayOfMonth(date time.Time) (time.Time, bool) {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	d, ok := c.PreviousTradingDay(first.AddDate(0, 1, 0))
+	if !ok || d.Month() != first.Month() {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// FirstTradingDayOfMonth returns the first trading day in the month of date
+func (c *HolidayCalendar) FirstTradingDayOfMonth(date time.Time) (time.Time, bool) {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	d := first
+	if !c.IsTradingDay(d) {
+		var ok bool
+		if d, ok = c.NextTradingDay(first); !ok {
+			return time.Time{}, false
+		}
+	}
+	if d.Month() != first.Month() {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// LastTradingDayOfWeek returns the last trading day of the Sunday to Saturday
+// week containing date
+func (c *HolidayCalendar) LastTradingDayOfWeek(date time.Time) (time.Time, bool) {
+	start := truncateDay(date).AddDate(0, 0, -int(date.Weekday()))
+	for d := start.AddDate(0, 0, 6); !d.Before(start); d = d.AddDate(0, 0, -1) {
+		if c.IsTradingDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FirstTradingDayOfWeek returns the first trading day of the Sunday to
+// Saturday week containing date
+func (c *HolidayCalendar) FirstTradingDayOfWeek(date time.Time) (time.Time, bool) {
+	start := truncateDay(date).AddDate(0, 0, -int(date.Weekday()))
+	for d := start; d.Before(start.AddDate(0, 0, 7)); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,68 @@
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayCalendar_TradingDayArithmetic(t *testing.T) {
+	// 2025-03-31 and 2025-04-01 are holidays; Friday and Saturday are weekends
+	cal := NewHolidayCalendar(day(2025, 3, 31), day(2025, 4, 1))
+
+	next, ok := cal.NextTradingDay(day(2025, 3, 27))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 3, 30), next, "thursday rolls over the weekend to sunday")
+
+	next, ok = cal.NextTradingDay(day(2025, 3, 30))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 4, 2), next, "holidays are skipped")
+
+	prev, ok := cal.PreviousTradingDay(day(2025, 4, 2))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 3, 30), prev)
+
+	plus, ok := cal.AddTradingDays(day(2025, 3, 27), 2)
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 4, 2), plus)
+
+	same, ok := cal.AddTradingDays(day(2025, 3, 27), 0)
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 3, 27), same)
+}
+
+func TestHolidayCalendar_PeriodBoundaries(t *testing.T) {
+	cal := NewHolidayCalendar(day(2025, 3, 31))
+
+	last, ok := cal.LastTradingDayOfMonth(day(2025, 3, 10))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 3, 30), last, "march 31 is a holiday")
+
+	first, ok := cal.FirstTradingDayOfMonth(day(2025, 2, 20))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 2, 2), first, "february 1 2025 is a saturday")
+
+	week, ok := cal.LastTradingDayOfWeek(day(2025, 4, 1))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 4, 3), week)
+
+	week, ok = cal.FirstTradingDayOfWeek(day(2025, 4, 3))
+	require.True(t, ok)
+	assert.Equal(t, day(2025, 3, 30), week)
+
+	// A week where everything is a holiday has no last trading day
+	closed := NewHolidayCalendar(day(2025, 4, 6), day(2025, 4, 7), day(2025, 4, 8), day(2025, 4, 9), day(2025, 4, 10))
+	_, ok = closed.LastTradingDayOfWeek(day(2025, 4, 8))
+	assert.False(t, ok)
+}
+
+func TestHolidayCalendar_NoTradingDays(t *testing.T) {
+	cal := NewHolidayCalendar()
+	for d := day(2025, 1, 1); d.Year() == 2025 || d.Year() == 2026; d = d.AddDate(0, 0, 1) {
+		cal.AddHoliday(d)
+	}
+
+	_, ok := cal.NextTradingDay(day(2025, 1, 1))
+	assert.False(t, ok, "search is bounded")
+}
@@ -0,0 +1,274 @@
+package files
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeltaDirName is the reports subdirectory holding data delta manifests
+const DeltaDirName = "deltas"
+
+// MaxDeltaManifests is the number of delta manifests kept; older ones are
+// pruned when a new one is written
+const MaxDeltaManifests = 500
+
+// DeltaTrackedOutputs are the processor outputs, relative to the reports
+// directory, whose changes are recorded in delta manifests
+var DeltaTrackedOutputs = []string{"combined", "daily", "ticker", filepath.Join("summary", "ticker")}
+
+// tickerHistorySuffix ends the per-ticker CSVs written by the processor
+const tickerHistorySuffix = "_trading_history.csv"
+
+// DataDelta is the manifest of one processor run: the files whose content
+// changed and the trading dates and tickers they cover
+type DataDelta struct {
+	RunID       string    `json:"run_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	FullRework  bool      `json:"full_rework"`
+	Dates       []string  `json:"dates"`   // YYYY-MM-DD
+	Tickers     []string  `json:"tickers"` // Symbols
+	Files       []string  `json:"files"`   // Relative to the reports directory, slash separated
+}
+
+// Empty reports whether the run changed nothing
+func (d DataDelta) Empty() bool {
+	return len(d.Files) == 0
+}
+
+// DataChanges merges the delta manifests written after a point in time
+type DataChanges struct {
+	Since time.Time `json:"since"`
+	// Until is the generation time of the newest merged run; pass it as the
+	// next since to receive only later changes
+	Until time.Time `json:"until"`
+	Runs  int       `json:"runs"`
+	// FullRefresh is set when the changes cannot be applied incrementally: a
+	// run reworked all data, or since predates the retained manifests
+	FullRefresh bool     `json:"full_refresh"`
+	Dates       []string `json:"dates"`
+	Tickers     []string `json:"tickers"`
+	Files       []string `json:"files"`
+}
+
+// FileSnapshot maps files, relative to a root and slash separated, to a hash
+// of their content
+type FileSnapshot map[string]string
+
+// SnapshotFiles hashes every file under the given entries of root. Entries
+// are files or directories relative to root; missing entries are skipped.
+func SnapshotFiles(root string, entries ...string) (FileSnapshot, error) {
+	snapshot := make(FileSnapshot)
+	for _, entry := range entries {
+		err := filepath.WalkDir(filepath.Join(root, entry), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			sum, err := hashFile(p)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			snapshot[filepath.ToSlash(rel)] = sum
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", entry, err)
+		}
+	}
+	return snapshot, nil
+}
+
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ChangedFiles returns the files added, modified or removed between two
+// snapshots, sorted
+func ChangedFiles(before, after FileSnapshot) []string {
+	var changed []string
+	for p, sum := range after {
+		if before[p] != sum {
+			changed = append(changed, p)
+		}
+	}
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// NewDataDelta builds the manifest of a run from its changed files. Dates
+// come from the daily CSVs and tickers from the ticker history CSVs that
+// changed, plus the dates of the reports processed in the run.
+func NewDataDelta(changed []string, processedDates []time.Time, fullRework bool, now time.Time) DataDelta {
+	dates := make(map[string]bool)
+	tickers := make(map[string]bool)
+	for _, d := range processedDates {
+		dates[d.Format("2006-01-02")] = true
+	}
+	for _, p := range changed {
+		name := path.Base(p)
+		if m := dailyCSVRe.FindStringSubmatch(name); m != nil {
+			dates[m[1]+"-"+m[2]+"-"+m[3]] = true
+		}
+		if strings.HasSuffix(name, tickerHistorySuffix) {
+			tickers[strings.TrimSuffix(name, tickerHistorySuffix)] = true
+		}
+	}
+
+	return DataDelta{
+		RunID:       now.UTC().Format("20060102T150405.000000000Z"),
+		GeneratedAt: now,
+		FullRework:  fullRework,
+		Dates:       sortedKeys(dates),
+		Tickers:     sortedKeys(tickers),
+		Files:       append([]string{}, changed...),
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteDataDelta stores a manifest in dir as delta_<run>.json and prunes the
+// oldest manifests beyond MaxDeltaManifests
+func WriteDataDelta(dir string, delta DataDelta) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create delta directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(delta, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode delta: %w", err)
+	}
+
+	target := filepath.Join(dir, "delta_"+delta.RunID+".json")
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", fmt.Errorf("write delta: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("write delta: %w", err)
+	}
+
+	names, err := deltaManifestNames(dir)
+	if err == nil && len(names) > MaxDeltaManifests {
+		for _, name := range names[:len(names)-MaxDeltaManifests] {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+	return target, nil
+}
+
+// deltaManifestNames lists manifest file names, oldest first
+func deltaManifestNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "delta_") && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	// Run IDs are UTC timestamps, so names sort chronologically
+	sort.Strings(names)
+	return names, nil
+}
+
+// ReadDataChanges merges the manifests in dir generated after since. A zero
+// since merges every retained manifest. A missing directory means no runs.
+func ReadDataChanges(dir string, since time.Time) (DataChanges, error) {
+	changes := DataChanges{Since: since, Until: since}
+
+	names, err := deltaManifestNames(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return changes, nil
+		}
+		return changes, fmt.Errorf("read delta directory: %w", err)
+	}
+
+	dates := make(map[string]bool)
+	tickers := make(map[string]bool)
+	files := make(map[string]bool)
+	for i, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return changes, fmt.Errorf("read delta %s: %w", name, err)
+		}
+		var delta DataDelta
+		if err := json.Unmarshal(data, &delta); err != nil {
+			return changes, fmt.Errorf("parse delta %s: %w", name, err)
+		}
+
+		// Runs before the oldest retained manifest were pruned
+		if i == 0 && len(names) >= MaxDeltaManifests && !since.IsZero() && since.Before(delta.GeneratedAt) {
+			changes.FullRefresh = true
+		}
+		if !delta.GeneratedAt.After(since) {
+			continue
+		}
+
+		changes.Runs++
+		changes.FullRefresh = changes.FullRefresh || delta.FullRework
+		if delta.GeneratedAt.After(changes.Until) {
+			changes.Until = delta.GeneratedAt
+		}
+		for _, d := range delta.Dates {
+			dates[d] = true
+		}
+		for _, t := range delta.Tickers {
+			tickers[t] = true
+		}
+		for _, f := range delta.Files {
+			files[f] = true
+		}
+	}
+
+	changes.Dates = sortedKeys(dates)
+	changes.Tickers = sortedKeys(tickers)
+	changes.Files = sortedKeys(files)
+	return changes, nil
+}
@@ -0,0 +1,118 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotFiles_ChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "daily/isx_daily_2025_06_23.csv"), "a")
+	writeTestFile(t, filepath.Join(root, "daily/isx_daily_2025_06_24.csv"), "b")
+	writeTestFile(t, filepath.Join(root, "ticker/BBOB_trading_history.csv"), "c")
+	writeTestFile(t, filepath.Join(root, "ticker/TASC_trading_history.csv"), "d")
+	writeTestFile(t, filepath.Join(root, "liquidity/untracked.csv"), "e")
+
+	before, err := SnapshotFiles(root, DeltaTrackedOutputs...)
+	require.NoError(t, err)
+	assert.Len(t, before, 4, "only tracked outputs are hashed")
+
+	// Rewritten with the same content, changed, added and removed
+	writeTestFile(t, filepath.Join(root, "daily/isx_daily_2025_06_23.csv"), "a")
+	writeTestFile(t, filepath.Join(root, "daily/isx_daily_2025_06_24.csv"), "b2")
+	writeTestFile(t, filepath.Join(root, "daily/isx_daily_2025_06_25.csv"), "new")
+	require.NoError(t, os.Remove(filepath.Join(root, "ticker/TASC_trading_history.csv")))
+
+	after, err := SnapshotFiles(root, DeltaTrackedOutputs...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"daily/isx_daily_2025_06_24.csv",
+		"daily/isx_daily_2025_06_25.csv",
+		"ticker/TASC_trading_history.csv",
+	}, ChangedFiles(before, after))
+}
+
+func TestNewDataDelta(t *testing.T) {
+	now := time.Date(2025, 6, 25, 14, 0, 0, 0, time.UTC)
+	delta := NewDataDelta([]string{
+		"combined/isx_combined_data.csv",
+		"daily/isx_daily_2025_06_24.csv",
+		"ticker/BBOB_trading_history.csv",
+	}, []time.Time{day(2025, 6, 25)}, false, now)
+
+	assert.Equal(t, []string{"2025-06-24", "2025-06-25"}, delta.Dates)
+	assert.Equal(t, []string{"BBOB"}, delta.Tickers)
+	assert.Len(t, delta.Files, 3)
+	assert.False(t, delta.Empty())
+	assert.True(t, NewDataDelta(nil, nil, false, now).Empty())
+}
+
+func TestReadDataChanges(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 6, 24, 14, 0, 0, 0, time.UTC)
+
+	changes, err := ReadDataChanges(filepath.Join(dir, "missing"), time.Time{})
+	require.NoError(t, err)
+	assert.Zero(t, changes.Runs)
+
+	_, err = WriteDataDelta(dir, NewDataDelta([]string{"daily/isx_daily_2025_06_23.csv", "ticker/BBOB_trading_history.csv"}, nil, false, base))
+	require.NoError(t, err)
+	_, err = WriteDataDelta(dir, NewDataDelta([]string{"daily/isx_daily_2025_06_24.csv", "ticker/BBOB_trading_history.csv"}, nil, false, base.Add(24*time.Hour)))
+	require.NoError(t, err)
+
+	changes, err = ReadDataChanges(dir, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, changes.Runs)
+	assert.Equal(t, []string{"2025-06-23", "2025-06-24"}, changes.Dates)
+	assert.Equal(t, []string{"BBOB"}, changes.Tickers)
+	assert.Equal(t, base.Add(24*time.Hour), changes.Until)
+	assert.False(t, changes.FullRefresh)
+
+	// Only runs after the cursor
+	changes, err = ReadDataChanges(dir, base)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes.Runs)
+	assert.Equal(t, []string{"2025-06-24"}, changes.Dates)
+
+	changes, err = ReadDataChanges(dir, changes.Until)
+	require.NoError(t, err)
+	assert.Zero(t, changes.Runs)
+	assert.Empty(t, changes.Files)
+
+	_, err = WriteDataDelta(dir, NewDataDelta([]string{"combined/isx_combined_data.csv"}, nil, true, base.Add(48*time.Hour)))
+	require.NoError(t, err)
+	changes, err = ReadDataChanges(dir, base.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, changes.FullRefresh, "a full rework cannot be applied incrementally")
+}
+
+func TestWriteDataDelta_Prunes(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 6, 24, 14, 0, 0, 0, time.UTC)
+
+	for i := 0; i < MaxDeltaManifests+2; i++ {
+		_, err := WriteDataDelta(dir, NewDataDelta([]string{fmt.Sprintf("daily/f%d.csv", i)}, nil, false, base.Add(time.Duration(i)*time.Minute)))
+		require.NoError(t, err)
+	}
+
+	names, err := deltaManifestNames(dir)
+	require.NoError(t, err)
+	assert.Len(t, names, MaxDeltaManifests)
+
+	// The first two runs were pruned, so a cursor before them needs a full refresh
+	changes, err := ReadDataChanges(dir, base.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.True(t, changes.FullRefresh)
+
+	changes, err = ReadDataChanges(dir, base.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, changes.FullRefresh)
+	assert.Equal(t, MaxDeltaManifests-1, changes.Runs)
+}
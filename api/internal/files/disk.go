@@ -0,0 +1,15 @@
+package files
+
+// DiskSpace is the capacity and free space of a filesystem
+type DiskSpace struct {
+	TotalBytes uint64 `json:"total_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"` // Available to the current user
+}
+
+// FreePercent returns the free space as a percentage of the capacity
+func (d DiskSpace) FreePercent() float64 {
+	if d.TotalBytes == 0 {
+		return 0
+	}
+	return float64(d.FreeBytes) / float64(d.TotalBytes) * 100
+}
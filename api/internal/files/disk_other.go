@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package files
+
+import "errors"
+
+// DiskUsage is not available on this platform
+func DiskUsage(path string) (DiskSpace, error) {
+	return DiskSpace{}, errors.New("disk usage is not supported on this platform")
+}
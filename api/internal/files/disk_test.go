@@ -0,0 +1,22 @@
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskUsage(t *testing.T) {
+	space, err := DiskUsage(t.TempDir())
+	require.NoError(t, err)
+	assert.Positive(t, space.TotalBytes)
+	assert.LessOrEqual(t, space.FreeBytes, space.TotalBytes)
+	assert.InDelta(t, float64(space.FreeBytes)/float64(space.TotalBytes)*100, space.FreePercent(), 1e-9)
+
+	_, err = DiskUsage("/path/that/does/not/exist")
+	assert.Error(t, err)
+
+	assert.Equal(t, 25.0, DiskSpace{TotalBytes: 400, FreeBytes: 100}.FreePercent())
+	assert.Zero(t, DiskSpace{}.FreePercent())
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package files
+
+import "syscall"
+
+// DiskUsage returns the capacity and free space of the filesystem holding path
+func DiskUsage(path string) (DiskSpace, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return DiskSpace{}, err
+	}
+	return DiskSpace{
+		TotalBytes: uint64(st.Blocks) * uint64(st.Bsize),
+		FreeBytes:  uint64(st.Bavail) * uint64(st.Bsize),
+	}, nil
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package files
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceExW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// DiskUsage returns the capacity and free space of the volume holding path
+func DiskUsage(path string) (DiskSpace, error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+
+	var free, total, totalFree uint64
+	r, _, callErr := procGetDiskFreeSpaceExW.Call(
+		uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&free)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&totalFree)),
+	)
+	if r == 0 {
+		return DiskSpace{}, callErr
+	}
+	return DiskSpace{TotalBytes: total, FreeBytes: free}, nil
+}
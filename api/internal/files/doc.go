@@ -1,7 +1,7 @@
 // Package files provides file system operations and discovery utilities
 // for the ISX Daily Reports Scrapper application.
 //
-// This package contains two main components:
+// This package contains three main components:
 //
 // Discovery: Provides file discovery operations such as finding Excel files,
 // CSV files, and files matching specific patterns. It also includes utilities
@@ -11,6 +11,12 @@
 // deleting files, and ensuring directories exist. All operations are relative
 // to a base path to maintain portability.
 //
+// RetentionManager: Applies a RetentionPolicy to the data directory. Daily
+// xlsx reports older than N months are compressed into monthly zip archives,
+// daily CSVs older than N days are deleted, and the combined CSV is always
+// kept. Gap analysis reads the archives, so archived months are not reported
+// as missing.
+//
 // Example usage:
 //
 //	// Create a discovery instance
@@ -0,0 +1,337 @@
+package files
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dailyReportRe matches ISX daily report filenames like "2025 06 24 ISX Daily Report.xlsx"
+var dailyReportRe = regexp.MustCompile(`^(\d{4}) (\d{2}) (\d{2}) ISX Daily Report\.xlsx$`)
+
+// HolidayCalendar decides which calendar days are ISX trading days.
+// The ISX trades Sunday to Thursday; Friday and Saturday are weekends.
+type HolidayCalendar struct {
+	holidays map[string]bool
+}
+
+// NewHolidayCalendar creates a calendar with the given holiday dates
+func NewHolidayCalendar(holidays ...time.Time) *HolidayCalendar {
+	c := &HolidayCalendar{holidays: make(map[string]bool)}
+	for _, h := range holidays {
+		c.AddHoliday(h)
+	}
+	return c
+}
+
+// LoadHolidayCalendar reads holidays from a text file with one YYYY-MM-DD date
+// per line. Blank lines and lines starting with # are ignored. A missing file
+// yields a weekend-only calendar.
+func LoadHolidayCalendar(path string) (*HolidayCalendar, error) {
+	c := NewHolidayCalendar()
+	if path == "" {
+		return c, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to open holiday calendar %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Allow trailing comments: "2025-03-31 # Eid al-Fitr"
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		date, err := time.Parse("2006-01-02", line)
+		if err != nil {
+			return nil, fmt.Errorf("holiday calendar line %d: %w", lineNo, err)
+		}
+		c.AddHoliday(date)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holiday calendar: %w", err)
+	}
+
+	return c, nil
+}
+
+// AddHoliday marks a date as a non-trading day
+func (c *HolidayCalendar) AddHoliday(date time.Time) {
+	c.holidays[date.Format("2006-01-02")] = true
+}
+
+// IsHoliday reports whether the date is a listed holiday
+func (c *HolidayCalendar) IsHoliday(date time.Time) bool {
+	return c.holidays[date.Format("2006-01-02")]
+}
+
+// IsTradingDay reports whether the ISX is expected to trade on the date
+func (c *HolidayCalendar) IsTradingDay(date time.Time) bool {
+	if date.Weekday() == time.Friday || date.Weekday() == time.Saturday {
+		return false
+	}
+	return !c.IsHoliday(date)
+}
+
+// TradingDays returns all expected trading days in [from, to]
+func (c *HolidayCalendar) TradingDays(from, to time.Time) []time.Time {
+	var days []time.Time
+	for d := truncateDay(from); !d.After(truncateDay(to)); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// DateRange is an inclusive range of consecutive missing trading days
+type DateRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	Days int       `json:"days"`
+}
+
+// GapReport describes missing trading days in the downloads and combined data
+type GapReport struct {
+	From              time.Time   `json:"from"`
+	To                time.Time   `json:"to"`
+	ExpectedDays      int         `json:"expected_days"`
+	MissingDownloads  []time.Time `json:"missing_downloads"`
+	MissingInCombined []time.Time `json:"missing_in_combined"`
+	// Ranges groups MissingDownloads into runs that can be re-scraped individually
+	Ranges []DateRange `json:"ranges"`
+}
+
+// HasGaps reports whether any trading day is missing
+func (r *GapReport) HasGaps() bool {
+	return len(r.MissingDownloads) > 0 || len(r.MissingInCombined) > 0
+}
+
+// GapOptions configures a gap analysis. Zero From/To default to the earliest
+// and latest downloaded report.
+type GapOptions struct {
+	DownloadsDir string
+	// ArchiveDir holds monthly zip archives written by the retention manager;
+	// reports inside them count as downloaded. Optional.
+	ArchiveDir  string
+	CombinedCSV string
+	From        time.Time
+	To          time.Time
+	Calendar    *HolidayCalendar
+}
+
+// FindGaps scans the downloaded reports and combined CSV for trading days
+// without data
+func FindGaps(opts GapOptions) (*GapReport, error) {
+	if opts.Calendar == nil {
+		opts.Calendar = NewHolidayCalendar()
+	}
+
+	downloaded, err := DownloadedReportDates(opts.DownloadsDir)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ArchiveDir != "" {
+		archived, err := ArchivedReportDates(opts.ArchiveDir)
+		if err != nil {
+			return nil, err
+		}
+		for date := range archived {
+			downloaded[date] = true
+		}
+	}
+
+	combined := make(map[string]bool)
+	if opts.CombinedCSV != "" {
+		combined, err = CombinedCSVDates(opts.CombinedCSV)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, to := opts.From, opts.To
+	if from.IsZero() || to.IsZero() {
+		first, last := dateBounds(downloaded)
+		if from.IsZero() {
+			from = first
+		}
+		if to.IsZero() {
+			to = last
+		}
+	}
+	if from.IsZero() || to.IsZero() {
+		return nil, fmt.Errorf("no date range given and no daily reports found in %s", opts.DownloadsDir)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid date range: %s is after %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	report := &GapReport{
+		From: truncateDay(from),
+		To:   truncateDay(to),
+	}
+
+	expected := opts.Calendar.TradingDays(from, to)
+	report.ExpectedDays = len(expected)
+	for _, day := range expected {
+		key := day.Format("2006-01-02")
+		if !downloaded[key] {
+			report.MissingDownloads = append(report.MissingDownloads, day)
+		}
+		if opts.CombinedCSV != "" && !combined[key] {
+			report.MissingInCombined = append(report.MissingInCombined, day)
+		}
+	}
+	report.Ranges = GroupConsecutiveTradingDays(report.MissingDownloads, opts.Calendar)
+
+	return report, nil
+}
+
+// DownloadedReportDates returns the set of dates (YYYY-MM-DD) with a daily report in dir
+func DownloadedReportDates(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	dates := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := dailyReportRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		dates[fmt.Sprintf("%s-%s-%s", m[1], m[2], m[3])] = true
+	}
+	return dates, nil
+}
+
+// CombinedCSVDates returns the set of dates present in the combined CSV's Date column.
+// A missing file is treated as empty.
+func CombinedCSVDates(path string) (map[string]bool, error) {
+	dates := make(map[string]bool)
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dates, nil
+		}
+		return nil, fmt.Errorf("failed to open combined CSV: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return dates, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read combined CSV header: %w", err)
+	}
+
+	dateCol := -1
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), "date") {
+			dateCol = i
+			break
+		}
+	}
+	if dateCol == -1 {
+		return nil, fmt.Errorf("combined CSV has no Date column")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read combined CSV: %w", err)
+		}
+		if dateCol < len(record) {
+			dates[strings.TrimSpace(record[dateCol])] = true
+		}
+	}
+	return dates, nil
+}
+
+// GroupConsecutiveTradingDays merges sorted days into ranges, treating days
+// separated only by non-trading days as consecutive
+func GroupConsecutiveTradingDays(days []time.Time, cal *HolidayCalendar) []DateRange {
+	if len(days) == 0 {
+		return nil
+	}
+	if cal == nil {
+		cal = NewHolidayCalendar()
+	}
+
+	sorted := append([]time.Time(nil), days...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	var ranges []DateRange
+	current := DateRange{From: sorted[0], To: sorted[0], Days: 1}
+	for _, day := range sorted[1:] {
+		if nextTradingDay(current.To, cal).Equal(day) {
+			current.To = day
+			current.Days++
+			continue
+		}
+		ranges = append(ranges, current)
+		current = DateRange{From: day, To: day, Days: 1}
+	}
+	return append(ranges, current)
+}
+
+// nextTradingDay returns the first trading day after date
+func nextTradingDay(date time.Time, cal *HolidayCalendar) time.Time {
+	next := date.AddDate(0, 0, 1)
+	for i := 0; i < 31 && !cal.IsTradingDay(next); i++ {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// dateBounds returns the earliest and latest date in a YYYY-MM-DD set
+func dateBounds(dates map[string]bool) (time.Time, time.Time) {
+	var first, last time.Time
+	for key := range dates {
+		d, err := time.Parse("2006-01-02", key)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || d.Before(first) {
+			first = d
+		}
+		if last.IsZero() || d.After(last) {
+			last = d
+		}
+	}
+	return first, last
+}
+
+// truncateDay strips the time of day, keeping the date in UTC
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,114 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestHolidayCalendar_IsTradingDay(t *testing.T) {
+	cal := NewHolidayCalendar(day(2025, 3, 31))
+
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{"sunday", day(2025, 3, 30), true},
+		{"thursday", day(2025, 4, 3), true},
+		{"friday", day(2025, 4, 4), false},
+		{"saturday", day(2025, 4, 5), false},
+		{"holiday", day(2025, 3, 31), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cal.IsTradingDay(tt.date))
+		})
+	}
+}
+
+func TestLoadHolidayCalendar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "holidays.txt")
+	content := "# ISX holidays\n\n2025-03-31 # Eid al-Fitr\n2025-04-01\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cal, err := LoadHolidayCalendar(path)
+	require.NoError(t, err)
+	assert.True(t, cal.IsHoliday(day(2025, 3, 31)))
+	assert.True(t, cal.IsHoliday(day(2025, 4, 1)))
+	assert.False(t, cal.IsHoliday(day(2025, 4, 2)))
+
+	// Missing file falls back to weekends only
+	cal, err = LoadHolidayCalendar(filepath.Join(dir, "missing.txt"))
+	require.NoError(t, err)
+	assert.True(t, cal.IsTradingDay(day(2025, 3, 31)))
+
+	require.NoError(t, os.WriteFile(path, []byte("31/03/2025\n"), 0644))
+	_, err = LoadHolidayCalendar(path)
+	assert.Error(t, err)
+}
+
+func TestFindGaps(t *testing.T) {
+	dir := t.TempDir()
+	// Sun 2025-01-05 .. Thu 2025-01-16; missing 07, 08 and 13
+	for _, name := range []string{
+		"2025 01 05 ISX Daily Report.xlsx",
+		"2025 01 06 ISX Daily Report.xlsx",
+		"2025 01 09 ISX Daily Report.xlsx",
+		"2025 01 12 ISX Daily Report.xlsx",
+		"2025 01 14 ISX Daily Report.xlsx",
+		"2025 01 15 ISX Daily Report.xlsx",
+		"2025 01 16 ISX Daily Report.xlsx",
+		"2025 01 05 ISX Intraday Report.xlsx",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	combined := filepath.Join(dir, "isx_combined_data.csv")
+	require.NoError(t, os.WriteFile(combined, []byte("Date,Symbol\n2025-01-05,BBOB\n2025-01-06,BBOB\n"), 0644))
+
+	report, err := FindGaps(GapOptions{
+		DownloadsDir: dir,
+		CombinedCSV:  combined,
+		Calendar:     NewHolidayCalendar(day(2025, 1, 8)),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, day(2025, 1, 5), report.From)
+	assert.Equal(t, day(2025, 1, 16), report.To)
+	assert.Equal(t, 9, report.ExpectedDays)
+	assert.Equal(t, []time.Time{day(2025, 1, 7), day(2025, 1, 13)}, report.MissingDownloads)
+	assert.Len(t, report.MissingInCombined, 7)
+	assert.True(t, report.HasGaps())
+
+	require.Len(t, report.Ranges, 2)
+	assert.Equal(t, DateRange{From: day(2025, 1, 7), To: day(2025, 1, 7), Days: 1}, report.Ranges[0])
+}
+
+func TestFindGaps_NoReports(t *testing.T) {
+	_, err := FindGaps(GapOptions{DownloadsDir: t.TempDir()})
+	assert.Error(t, err)
+}
+
+func TestGroupConsecutiveTradingDays(t *testing.T) {
+	cal := NewHolidayCalendar()
+	// Thu 2025-01-09 and Sun 2025-01-12 are consecutive trading days across the weekend
+	days := []time.Time{day(2025, 1, 14), day(2025, 1, 9), day(2025, 1, 12)}
+
+	ranges := GroupConsecutiveTradingDays(days, cal)
+	require.Len(t, ranges, 2)
+	assert.Equal(t, DateRange{From: day(2025, 1, 9), To: day(2025, 1, 12), Days: 2}, ranges[0])
+	assert.Equal(t, DateRange{From: day(2025, 1, 14), To: day(2025, 1, 14), Days: 1}, ranges[1])
+
+	assert.Nil(t, GroupConsecutiveTradingDays(nil, cal))
+}
@@ -0,0 +1,322 @@
+package files
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LockFileName is the advisory lock file placed in a shared directory
+const LockFileName = ".isx.lock"
+
+// Lock timing defaults
+const (
+	DefaultLockHeartbeat  = 10 * time.Second
+	DefaultLockStaleAfter = time.Minute
+)
+
+// ErrLocked is returned when another live process holds a directory lock
+var ErrLocked = errors.New("directory is locked by another process")
+
+// LockInfo is the content of a lock file
+type LockInfo struct {
+	Token      string    `json:"token"` // Identifies the holder across PID reuse
+	Owner      string    `json:"owner"` // What holds the lock, e.g. "processor"
+	PID        int       `json:"pid"`
+	Host       string    `json:"host"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	Heartbeat  time.Time `json:"heartbeat"`
+}
+
+// LockedError describes the live lock that blocked an operation
+type LockedError struct {
+	Dir  string
+	Info LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is in use by %s (pid %d on %s, since %s, last heartbeat %s ago); wait for it to finish, or delete %s if that process is no longer running",
+		e.Dir, e.Info.Owner, e.Info.PID, e.Info.Host,
+		e.Info.AcquiredAt.Format(time.RFC3339),
+		time.Since(e.Info.Heartbeat).Round(time.Second),
+		filepath.Join(e.Dir, LockFileName))
+}
+
+// Is makes errors.Is(err, ErrLocked) match
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// LockOptions tunes a directory lock. Zero values use the defaults.
+type LockOptions struct {
+	Heartbeat  time.Duration // How often the holder refreshes the lock file
+	StaleAfter time.Duration // Heartbeat age after which the lock may be taken over
+	Logger     *slog.Logger
+}
+
+func (o LockOptions) withDefaults() LockOptions {
+	if o.Heartbeat <= 0 {
+		o.Heartbeat = DefaultLockHeartbeat
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = DefaultLockStaleAfter
+	}
+	if o.StaleAfter < 2*o.Heartbeat {
+		o.StaleAfter = 2 * o.Heartbeat
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	return o
+}
+
+// DirLock is an advisory lock on a directory shared by several processes,
+// such as a manual processor run and the web pipeline writing the same
+// reports directory. The holder refreshes a heartbeat in the lock file; a
+// lock whose heartbeat is older than StaleAfter, or whose process is gone,
+// is taken over.
+type DirLock struct {
+	dir  string
+	path string
+	opts LockOptions
+
+	mu   sync.Mutex
+	info LockInfo
+	// TookOver is the stale lock replaced when this one was acquired
+	TookOver *LockInfo
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// AcquireDirLock locks dir for owner. It returns a *LockedError, matching
+// ErrLocked, when another live process holds the lock.
+func AcquireDirLock(dir, owner string, opts LockOptions) (*DirLock, error) {
+	opts = opts.withDefaults()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	host, _ := os.Hostname()
+	now := time.Now()
+	l := &DirLock{
+		dir:  dir,
+		path: filepath.Join(dir, LockFileName),
+		opts: opts,
+		info: LockInfo{
+			Token:      newLockToken(),
+			Owner:      owner,
+			PID:        os.Getpid(),
+			Host:       host,
+			AcquiredAt: now,
+			Heartbeat:  now,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	// One takeover attempt: a second conflict means another process won the race
+	for attempt := 0; ; attempt++ {
+		err := l.create()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+
+		held, stale := readLock(l.path, opts.StaleAfter)
+		if !stale || attempt > 0 {
+			return nil, &LockedError{Dir: dir, Info: held}
+		}
+		if current, ok, err := l.claimStale(held); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, &LockedError{Dir: dir, Info: current}
+		}
+		opts.Logger.Warn("Taking over stale directory lock",
+			slog.String("dir", dir),
+			slog.String("owner", held.Owner),
+			slog.Int("pid", held.PID),
+			slog.String("host", held.Host),
+			slog.Time("heartbeat", held.Heartbeat))
+		l.TookOver = &held
+	}
+
+	go l.heartbeat()
+	return l, nil
+}
+
+// claimStale removes the stale lock read earlier. Another process may have
+// replaced it since, so the lock file is first moved aside, which is atomic,
+// and only deleted if it is still the stale one; a fresh lock is put back and
+// returned with ok false.
+func (l *DirLock) claimStale(stale LockInfo) (current LockInfo, ok bool, err error) {
+	aside := l.path + "." + l.info.Token
+	if err := os.Rename(l.path, aside); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// Already gone; create decides who gets the lock
+			return stale, true, nil
+		}
+		return LockInfo{}, false, fmt.Errorf("move stale lock aside: %w", err)
+	}
+	defer os.Remove(aside)
+
+	moved, _ := readLock(aside, l.opts.StaleAfter)
+	if moved.Token == stale.Token {
+		return stale, true, nil
+	}
+	// Link rather than rename back, so a lock created in the meantime is not
+	// overwritten
+	if err := os.Link(aside, l.path); err != nil {
+		l.opts.Logger.Warn("Failed to restore directory lock moved aside",
+			slog.String("dir", l.dir),
+			slog.String("owner", moved.Owner),
+			slog.String("error", err.Error()))
+	}
+	return moved, false, nil
+}
+
+// create writes the lock file, failing with fs.ErrExist if one is present
+func (l *DirLock) create() error {
+	data, err := json.MarshalIndent(l.info, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(l.path)
+		return err
+	}
+	return f.Close()
+}
+
+// heartbeat refreshes the lock file until Release. It stops if the lock was
+// taken over, which only happens when this process stalled past StaleAfter.
+func (l *DirLock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.opts.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.refresh(); err != nil {
+				l.opts.Logger.Error("Directory lock lost",
+					slog.String("dir", l.dir),
+					slog.String("error", err.Error()))
+				return
+			}
+		}
+	}
+}
+
+func (l *DirLock) refresh() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if held, _ := readLock(l.path, l.opts.StaleAfter); held.Token != l.info.Token {
+		return fmt.Errorf("lock now held by %s (pid %d)", held.Owner, held.PID)
+	}
+
+	l.info.Heartbeat = time.Now()
+	data, err := json.MarshalIndent(l.info, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// Release stops the heartbeat and removes the lock file if this lock still
+// holds it
+func (l *DirLock) Release() error {
+	select {
+	case <-l.stop:
+		return nil
+	default:
+		close(l.stop)
+	}
+	<-l.done
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if held, _ := readLock(l.path, l.opts.StaleAfter); held.Token != l.info.Token {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove lock file: %w", err)
+	}
+	return nil
+}
+
+// CheckDirLock returns a *LockedError if another live process holds a lock
+// on path or any directory above it. Writers that do not take the lock
+// themselves call it before touching a shared directory.
+func CheckDirLock(path string) error {
+	dir := filepath.Clean(path)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		held, stale := readLock(filepath.Join(dir, LockFileName), DefaultLockStaleAfter)
+		if held.PID != 0 && !stale && held.PID != os.Getpid() {
+			return &LockedError{Dir: dir, Info: held}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// readLock reads a lock file. A lock is stale when its heartbeat is older
+// than staleAfter or its process no longer runs on this host. An unreadable
+// lock file is stale once it is older than staleAfter, so a half-written
+// file does not block forever.
+func readLock(path string, staleAfter time.Duration) (LockInfo, bool) {
+	var info LockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, true
+	}
+	if err := json.Unmarshal(data, &info); err != nil || info.PID == 0 {
+		st, statErr := os.Stat(path)
+		return LockInfo{}, statErr != nil || time.Since(st.ModTime()) > staleAfter
+	}
+
+	if time.Since(info.Heartbeat) > staleAfter {
+		return info, true
+	}
+	if host, _ := os.Hostname(); host == info.Host && info.PID != os.Getpid() && !processAlive(info.PID) {
+		return info, true
+	}
+	return info, false
+}
+
+func newLockToken() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,130 @@
+package files
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLockInfo plants a lock file as if another process held it
+func writeLockInfo(t *testing.T, dir string, info LockInfo) {
+	data, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LockFileName), data, 0644))
+}
+
+func TestAcquireDirLock_AcquireRelease(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireDirLock(dir, "processor", LockOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, lock.TookOver)
+	assert.FileExists(t, filepath.Join(dir, LockFileName))
+
+	require.NoError(t, lock.Release())
+	assert.NoFileExists(t, filepath.Join(dir, LockFileName))
+	require.NoError(t, lock.Release(), "release is idempotent")
+
+	lock, err = AcquireDirLock(dir, "processor", LockOptions{})
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestAcquireDirLock_HeldByLiveProcess(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+	now := time.Now()
+	// The parent of the test binary is alive for the duration of the test
+	writeLockInfo(t, dir, LockInfo{Token: "other", Owner: "pipeline", PID: os.Getppid(), Host: host, AcquiredAt: now, Heartbeat: now})
+
+	_, err := AcquireDirLock(dir, "processor", LockOptions{})
+	require.ErrorIs(t, err, ErrLocked)
+
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, "pipeline", locked.Info.Owner)
+	assert.Contains(t, err.Error(), "in use by pipeline")
+	assert.Contains(t, err.Error(), LockFileName)
+
+	assert.ErrorIs(t, CheckDirLock(filepath.Join(dir, "daily", "isx_daily_2025_06_24.csv")), ErrLocked,
+		"files below a locked directory are covered")
+}
+
+func TestAcquireDirLock_TakesOverStaleLock(t *testing.T) {
+	host, _ := os.Hostname()
+	old := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		info LockInfo
+	}{
+		{"expired heartbeat", LockInfo{Token: "other", Owner: "pipeline", PID: os.Getppid(), Host: "elsewhere", AcquiredAt: old, Heartbeat: old}},
+		{"dead process", LockInfo{Token: "other", Owner: "pipeline", PID: 1 << 30, Host: host, AcquiredAt: time.Now(), Heartbeat: time.Now()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeLockInfo(t, dir, tt.info)
+			assert.NoError(t, CheckDirLock(dir))
+
+			lock, err := AcquireDirLock(dir, "processor", LockOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, lock.TookOver)
+			assert.Equal(t, "pipeline", lock.TookOver.Owner)
+			require.NoError(t, lock.Release())
+		})
+	}
+}
+
+func TestDirLock_ClaimStaleKeepsFreshLock(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+	old := time.Now().Add(-time.Hour)
+	stale := LockInfo{Token: "stale", Owner: "pipeline", PID: os.Getppid(), Host: "elsewhere", AcquiredAt: old, Heartbeat: old}
+	fresh := LockInfo{Token: "fresh", Owner: "processor", PID: os.Getppid(), Host: host, AcquiredAt: time.Now(), Heartbeat: time.Now()}
+
+	// Another process took over between reading the stale lock and removing it
+	writeLockInfo(t, dir, fresh)
+	l := &DirLock{dir: dir, path: filepath.Join(dir, LockFileName), opts: LockOptions{}.withDefaults(), info: LockInfo{Token: "mine"}}
+
+	current, ok, err := l.claimStale(stale)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "fresh", current.Token)
+
+	held, isStale := readLock(l.path, DefaultLockStaleAfter)
+	assert.Equal(t, "fresh", held.Token, "the fresh lock is put back")
+	assert.False(t, isStale)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "nothing is left aside")
+
+	// The stale lock itself is removed
+	writeLockInfo(t, dir, stale)
+	_, ok, err = l.claimStale(stale)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoFileExists(t, l.path)
+}
+
+func TestDirLock_Heartbeat(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := AcquireDirLock(dir, "processor", LockOptions{Heartbeat: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer lock.Release()
+
+	first, _ := readLock(filepath.Join(dir, LockFileName), time.Minute)
+	assert.Eventually(t, func() bool {
+		info, _ := readLock(filepath.Join(dir, LockFileName), time.Minute)
+		return info.Heartbeat.After(first.Heartbeat)
+	}, time.Second, 10*time.Millisecond)
+
+	// Our own lock never blocks our own writers
+	assert.NoError(t, CheckDirLock(dir))
+}
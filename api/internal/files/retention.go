@@ -0,0 +1,418 @@
+package files
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// dailyCSVRe matches per-day CSVs written by the processor, e.g. "isx_daily_2025_06_24.csv"
+var dailyCSVRe = regexp.MustCompile(`^isx_daily_(\d{4})_(\d{2})_(\d{2})\.csv$`)
+
+// archiveRe matches the monthly archives written by the retention manager
+var archiveRe = regexp.MustCompile(`^daily_reports_(\d{4})_(\d{2})\.zip$`)
+
+// RetentionPolicy describes how long downloaded reports and generated CSVs are
+// kept. The combined CSV is the long-term record and is never touched.
+type RetentionPolicy struct {
+	// ArchiveXLSXAfterMonths compresses daily xlsx reports into monthly zip
+	// archives once the whole month is older than this many months. 0 disables.
+	ArchiveXLSXAfterMonths int `json:"archive_xlsx_after_months"`
+	// DeleteDailyCSVAfterDays deletes isx_daily_*.csv files older than this many
+	// days. 0 disables.
+	DeleteDailyCSVAfterDays int `json:"delete_daily_csv_after_days"`
+}
+
+// Enabled reports whether the policy does anything
+func (p RetentionPolicy) Enabled() bool {
+	return p.ArchiveXLSXAfterMonths > 0 || p.DeleteDailyCSVAfterDays > 0
+}
+
+// Validate checks the policy values
+func (p RetentionPolicy) Validate() error {
+	if p.ArchiveXLSXAfterMonths < 0 {
+		return fmt.Errorf("archive_xlsx_after_months must not be negative, got %d", p.ArchiveXLSXAfterMonths)
+	}
+	if p.DeleteDailyCSVAfterDays < 0 {
+		return fmt.Errorf("delete_daily_csv_after_days must not be negative, got %d", p.DeleteDailyCSVAfterDays)
+	}
+	return nil
+}
+
+// RetentionTargets are the directories a retention run works on
+type RetentionTargets struct {
+	DownloadsDir    string // Daily xlsx reports
+	DailyReportsDir string // isx_daily_*.csv files, searched recursively
+	ArchiveDir      string // Monthly zip archives of xlsx reports
+}
+
+// RetentionResult summarises what a retention run did, or would do on a dry run
+type RetentionResult struct {
+	DryRun        bool     `json:"dry_run"`
+	ArchivedFiles int      `json:"archived_files"`
+	Archives      []string `json:"archives"`
+	DeletedCSVs   []string `json:"deleted_csvs"`
+	// KeptCSVs counts expired daily CSVs kept because their xlsx report is still
+	// in the downloads directory; the processor would regenerate them otherwise
+	KeptCSVs   int   `json:"kept_csvs"`
+	BytesFreed int64 `json:"bytes_freed"`
+}
+
+// RetentionManager applies a RetentionPolicy to the downloads and reports
+type RetentionManager struct {
+	policy  RetentionPolicy
+	targets RetentionTargets
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewRetentionManager creates a retention manager. A nil logger uses slog.Default.
+func NewRetentionManager(policy RetentionPolicy, targets RetentionTargets, logger *slog.Logger) *RetentionManager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &RetentionManager{
+		policy:  policy,
+		targets: targets,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Run applies the policy. Xlsx reports are archived before daily CSVs are
+// considered, so a CSV whose report was just archived can be deleted in the
+// same run. With dryRun nothing is written or removed.
+func (m *RetentionManager) Run(ctx context.Context, dryRun bool) (*RetentionResult, error) {
+	if err := m.policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &RetentionResult{DryRun: dryRun, Archives: []string{}, DeletedCSVs: []string{}}
+	archived := make(map[string]bool)
+	if m.policy.ArchiveXLSXAfterMonths > 0 {
+		if err := m.archiveReports(ctx, dryRun, archived, result); err != nil {
+			return result, err
+		}
+	}
+	if m.policy.DeleteDailyCSVAfterDays > 0 {
+		if err := m.deleteDailyCSVs(ctx, dryRun, archived, result); err != nil {
+			return result, err
+		}
+	}
+
+	m.logger.Info("Retention run complete",
+		slog.Bool("dry_run", dryRun),
+		slog.Int("archived_files", result.ArchivedFiles),
+		slog.Int("archives", len(result.Archives)),
+		slog.Int("deleted_csvs", len(result.DeletedCSVs)),
+		slog.Int("kept_csvs", result.KeptCSVs),
+		slog.Int64("bytes_freed", result.BytesFreed))
+	return result, nil
+}
+
+// archiveCutoff returns the first day of the newest month that may be
+// archived. Only whole months are archived so each archive is written once.
+func (m *RetentionManager) archiveCutoff() time.Time {
+	t := m.now().AddDate(0, -m.policy.ArchiveXLSXAfterMonths, 0)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// archiveReports moves expired xlsx reports into one zip per month and records
+// the archived report dates in archived
+func (m *RetentionManager) archiveReports(ctx context.Context, dryRun bool, archived map[string]bool, result *RetentionResult) error {
+	entries, err := os.ReadDir(m.targets.DownloadsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read downloads directory %s: %w", m.targets.DownloadsDir, err)
+	}
+
+	cutoff := m.archiveCutoff()
+	byMonth := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		date, ok := reportDate(entry.Name())
+		if !ok || !date.Before(cutoff) {
+			continue
+		}
+		month := date.Format("2006_01")
+		byMonth[month] = append(byMonth[month], filepath.Join(m.targets.DownloadsDir, entry.Name()))
+		archived[date.Format("2006-01-02")] = true
+	}
+
+	months := make([]string, 0, len(byMonth))
+	for month := range byMonth {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+
+	for _, month := range months {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reports := byMonth[month]
+		sort.Strings(reports)
+		archivePath := filepath.Join(m.targets.ArchiveDir, fmt.Sprintf("daily_reports_%s.zip", month))
+
+		freed, err := m.archiveMonth(archivePath, reports, dryRun)
+		if err != nil {
+			return err
+		}
+		result.ArchivedFiles += len(reports)
+		result.Archives = append(result.Archives, archivePath)
+		result.BytesFreed += freed
+
+		m.logger.Info("Archived daily reports",
+			slog.String("archive", archivePath),
+			slog.Int("files", len(reports)),
+			slog.Bool("dry_run", dryRun))
+	}
+	return nil
+}
+
+// archiveMonth adds reports to the archive, merging with an existing archive
+// for the month, and removes the originals once the archive is in place. It
+// returns the number of bytes freed.
+func (m *RetentionManager) archiveMonth(archivePath string, reports []string, dryRun bool) (int64, error) {
+	var originalSize int64
+	for _, path := range reports {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		originalSize += info.Size()
+	}
+	if dryRun {
+		return originalSize, nil
+	}
+
+	if err := os.MkdirAll(m.targets.ArchiveDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	var previousSize int64
+	if info, err := os.Stat(archivePath); err == nil {
+		previousSize = info.Size()
+	}
+
+	// Write to a temporary file and rename, so an interrupted run never leaves
+	// a truncated archive behind while the originals are already gone
+	tmpPath := archivePath + ".tmp"
+	if err := writeArchive(tmpPath, archivePath, reports); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to replace archive %s: %w", archivePath, err)
+	}
+
+	var newSize int64
+	if info, err := os.Stat(archivePath); err == nil {
+		newSize = info.Size()
+	}
+
+	for _, path := range reports {
+		if err := os.Remove(path); err != nil {
+			return 0, fmt.Errorf("failed to remove archived report %s: %w", path, err)
+		}
+	}
+	return originalSize - (newSize - previousSize), nil
+}
+
+// writeArchive writes a zip at path holding the entries of the existing
+// archive (if any) plus reports. A report replaces an entry of the same name.
+func writeArchive(path, existing string, reports []string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create archive %s: %w", path, err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+
+	replaced := make(map[string]bool, len(reports))
+	for _, report := range reports {
+		replaced[filepath.Base(report)] = true
+	}
+
+	if zr, err := zip.OpenReader(existing); err == nil {
+		defer zr.Close()
+		for _, f := range zr.File {
+			if replaced[f.Name] {
+				continue
+			}
+			if err := zw.Copy(f); err != nil {
+				return fmt.Errorf("failed to copy %s from %s: %w", f.Name, existing, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open existing archive %s: %w", existing, err)
+	}
+
+	for _, report := range reports {
+		if err := addToArchive(zw, report); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive %s: %w", path, err)
+	}
+	return out.Sync()
+}
+
+func addToArchive(zw *zip.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return fmt.Errorf("failed to build zip header for %s: %w", path, err)
+	}
+	header.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", path, err)
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	return nil
+}
+
+// deleteDailyCSVs removes expired isx_daily_*.csv files. A CSV is kept while
+// its xlsx report is still in the downloads directory, because the processor
+// regenerates any daily CSV that is missing for a downloaded report. Reports
+// in archived count as gone, so dry runs plan the same deletions as real runs.
+func (m *RetentionManager) deleteDailyCSVs(ctx context.Context, dryRun bool, archived map[string]bool, result *RetentionResult) error {
+	downloaded, err := DownloadedReportDates(m.targets.DownloadsDir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		downloaded = map[string]bool{}
+	}
+	for date := range archived {
+		delete(downloaded, date)
+	}
+
+	today := m.now()
+	cutoff := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC).
+		AddDate(0, 0, -m.policy.DeleteDailyCSVAfterDays)
+
+	err = filepath.WalkDir(m.targets.DailyReportsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == m.targets.DailyReportsDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		mt := dailyCSVRe.FindStringSubmatch(d.Name())
+		if mt == nil {
+			return nil
+		}
+		date, err := time.Parse("2006-01-02", fmt.Sprintf("%s-%s-%s", mt[1], mt[2], mt[3]))
+		if err != nil || !date.Before(cutoff) {
+			return nil
+		}
+		if downloaded[date.Format("2006-01-02")] {
+			result.KeptCSVs++
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", path, err)
+			}
+		}
+		result.DeletedCSVs = append(result.DeletedCSVs, path)
+		result.BytesFreed += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply daily CSV retention in %s: %w", m.targets.DailyReportsDir, err)
+	}
+
+	if len(result.DeletedCSVs) > 0 || result.KeptCSVs > 0 {
+		m.logger.Info("Applied daily CSV retention",
+			slog.Int("deleted", len(result.DeletedCSVs)),
+			slog.Int("kept_with_report", result.KeptCSVs),
+			slog.Bool("dry_run", dryRun))
+	}
+	return nil
+}
+
+// ArchivedReportDates returns the set of dates (YYYY-MM-DD) with a daily report
+// inside the monthly archives in dir. A missing directory is treated as empty.
+func ArchivedReportDates(dir string) (map[string]bool, error) {
+	dates := make(map[string]bool)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dates, nil
+		}
+		return nil, fmt.Errorf("failed to read archive directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !archiveRe.MatchString(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+		}
+		for _, f := range zr.File {
+			if m := dailyReportRe.FindStringSubmatch(f.Name); m != nil {
+				dates[fmt.Sprintf("%s-%s-%s", m[1], m[2], m[3])] = true
+			}
+		}
+		zr.Close()
+	}
+	return dates, nil
+}
+
+// reportDate parses the date of a daily xlsx report filename
+func reportDate(name string) (time.Time, bool) {
+	m := dailyReportRe.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", fmt.Sprintf("%s-%s-%s", m[1], m[2], m[3]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
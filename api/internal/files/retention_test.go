@@ -0,0 +1,152 @@
+package files
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func zipEntries(t *testing.T, path string) []string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func newTestRetention(t *testing.T, policy RetentionPolicy) (*RetentionManager, RetentionTargets) {
+	root := t.TempDir()
+	targets := RetentionTargets{
+		DownloadsDir:    filepath.Join(root, "downloads"),
+		DailyReportsDir: filepath.Join(root, "reports", "daily"),
+		ArchiveDir:      filepath.Join(root, "archive"),
+	}
+	m := NewRetentionManager(policy, targets, nil)
+	m.now = func() time.Time { return day(2025, 6, 15) }
+	return m, targets
+}
+
+func TestRetentionManager_ArchivesWholeMonths(t *testing.T) {
+	m, targets := newTestRetention(t, RetentionPolicy{ArchiveXLSXAfterMonths: 3})
+
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 02 03 ISX Daily Report.xlsx"), "feb 3")
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 02 27 ISX Daily Report.xlsx"), "feb 27")
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 03 02 ISX Daily Report.xlsx"), "mar 2")
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "notes.txt"), "ignored")
+
+	result, err := m.Run(context.Background(), false)
+	require.NoError(t, err)
+
+	// Cutoff is 2025-03-01: March is not yet three whole months old
+	archive := filepath.Join(targets.ArchiveDir, "daily_reports_2025_02.zip")
+	assert.Equal(t, 2, result.ArchivedFiles)
+	assert.Equal(t, []string{archive}, result.Archives)
+	assert.ElementsMatch(t, []string{"2025 02 03 ISX Daily Report.xlsx", "2025 02 27 ISX Daily Report.xlsx"}, zipEntries(t, archive))
+
+	assert.NoFileExists(t, filepath.Join(targets.DownloadsDir, "2025 02 03 ISX Daily Report.xlsx"))
+	assert.FileExists(t, filepath.Join(targets.DownloadsDir, "2025 03 02 ISX Daily Report.xlsx"))
+	assert.FileExists(t, filepath.Join(targets.DownloadsDir, "notes.txt"))
+	assert.NoFileExists(t, archive+".tmp")
+
+	// A report re-downloaded later is merged into the existing archive
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 02 10 ISX Daily Report.xlsx"), "feb 10")
+	_, err = m.Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Len(t, zipEntries(t, archive), 3)
+
+	dates, err := ArchivedReportDates(targets.ArchiveDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"2025-02-03": true, "2025-02-10": true, "2025-02-27": true}, dates)
+}
+
+func TestRetentionManager_DeletesDailyCSVs(t *testing.T) {
+	m, targets := newTestRetention(t, RetentionPolicy{DeleteDailyCSVAfterDays: 30})
+
+	old := filepath.Join(targets.DailyReportsDir, "isx_daily_2025_04_01.csv")
+	oldWithReport := filepath.Join(targets.DailyReportsDir, "2025", "isx_daily_2025_04_02.csv")
+	recent := filepath.Join(targets.DailyReportsDir, "isx_daily_2025_06_01.csv")
+	writeTestFile(t, old, "old")
+	writeTestFile(t, oldWithReport, "old")
+	writeTestFile(t, recent, "recent")
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 04 02 ISX Daily Report.xlsx"), "apr 2")
+
+	result, err := m.Run(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{old}, result.DeletedCSVs)
+	assert.Equal(t, 1, result.KeptCSVs, "CSV whose report is still downloaded is kept")
+	assert.Equal(t, int64(3), result.BytesFreed)
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, oldWithReport)
+	assert.FileExists(t, recent)
+}
+
+func TestRetentionManager_DryRun(t *testing.T) {
+	m, targets := newTestRetention(t, RetentionPolicy{ArchiveXLSXAfterMonths: 1, DeleteDailyCSVAfterDays: 1})
+
+	report := filepath.Join(targets.DownloadsDir, "2025 01 05 ISX Daily Report.xlsx")
+	csv := filepath.Join(targets.DailyReportsDir, "isx_daily_2025_01_05.csv")
+	writeTestFile(t, report, "report")
+	writeTestFile(t, csv, "csv")
+
+	result, err := m.Run(context.Background(), true)
+	require.NoError(t, err)
+
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.ArchivedFiles)
+	assert.Equal(t, []string{csv}, result.DeletedCSVs, "planned as if the report had been archived")
+	assert.Zero(t, result.KeptCSVs)
+	assert.FileExists(t, report)
+	assert.FileExists(t, csv)
+	assert.NoDirExists(t, targets.ArchiveDir)
+}
+
+func TestRetentionManager_MissingDirectories(t *testing.T) {
+	m, _ := newTestRetention(t, RetentionPolicy{ArchiveXLSXAfterMonths: 1, DeleteDailyCSVAfterDays: 1})
+
+	result, err := m.Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Zero(t, result.ArchivedFiles)
+	assert.Empty(t, result.DeletedCSVs)
+}
+
+func TestRetentionPolicy_Validate(t *testing.T) {
+	assert.NoError(t, RetentionPolicy{}.Validate())
+	assert.False(t, RetentionPolicy{}.Enabled())
+	assert.True(t, RetentionPolicy{DeleteDailyCSVAfterDays: 7}.Enabled())
+	assert.Error(t, RetentionPolicy{ArchiveXLSXAfterMonths: -1}.Validate())
+	assert.Error(t, RetentionPolicy{DeleteDailyCSVAfterDays: -1}.Validate())
+}
+
+func TestFindGaps_CountsArchivedReports(t *testing.T) {
+	m, targets := newTestRetention(t, RetentionPolicy{ArchiveXLSXAfterMonths: 1})
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 01 05 ISX Daily Report.xlsx"), "a")
+	writeTestFile(t, filepath.Join(targets.DownloadsDir, "2025 01 06 ISX Daily Report.xlsx"), "b")
+	_, err := m.Run(context.Background(), false)
+	require.NoError(t, err)
+
+	report, err := FindGaps(GapOptions{
+		DownloadsDir: targets.DownloadsDir,
+		ArchiveDir:   targets.ArchiveDir,
+		From:         day(2025, 1, 5),
+		To:           day(2025, 1, 6),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, report.MissingDownloads)
+}
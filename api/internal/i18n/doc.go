@@ -0,0 +1,15 @@
+// Package i18n localizes report outputs meant for people: column headers in
+// English or Arabic, Arabic-Indic digits, and the byte order mark and sheet
+// direction Excel needs to show Arabic text.
+//
+// Reports in data/reports keep their English headers, since the application
+// and its readers look columns up by name. A Locale is applied on the way
+// out instead: to export bundles, to CSV downloads that ask for it with
+// ?lang=ar, and to the liquidity XLSX workbook.
+//
+// Example usage:
+//
+//	locale, err := i18n.NewLocale("ar", "arabic")
+//	header = locale.Headers(header) // "ClosePrice" -> "سعر الإغلاق"
+//	cell = locale.Number("-1,250.5")
+package i18n
@@ -0,0 +1,68 @@
+package i18n
+
+// arabicHeaders translates report columns, keyed by headerKey. It covers the
+// trade record layout of daily, combined and ticker CSVs, the daily value
+// bands, the ticker summary and the liquidity CSV and XLSX reports.
+var arabicHeaders = map[string]string{
+	// Trade records (schema.TradeColumns)
+	"date":             "التاريخ",
+	"companyname":      "اسم الشركة",
+	"symbol":           "الرمز",
+	"ticker":           "الرمز",
+	"openprice":        "سعر الافتتاح",
+	"highprice":        "أعلى سعر",
+	"lowprice":         "أدنى سعر",
+	"averageprice":     "متوسط السعر",
+	"prevaverageprice": "متوسط السعر السابق",
+	"closeprice":       "سعر الإغلاق",
+	"prevcloseprice":   "سعر الإغلاق السابق",
+	"change":           "التغير",
+	"changepercent":    "نسبة التغير %",
+	"numtrades":        "عدد الصفقات",
+	"volume":           "حجم التداول",
+	"value":            "قيمة التداول",
+	"tradingstatus":    "حالة التداول",
+	"fillmethod":       "طريقة الاستكمال",
+
+	// Daily value bands
+	"valuepercentile60d": "مئين قيمة التداول (60 يوماً)",
+	"valuep1060d":        "قيمة التداول - المئين 10 (60 يوماً)",
+	"valuep5060d":        "قيمة التداول - المئين 50 (60 يوماً)",
+	"valuep9060d":        "قيمة التداول - المئين 90 (60 يوماً)",
+
+	// Ticker summary
+	"lastprice":    "آخر سعر",
+	"lastdate":     "آخر تاريخ",
+	"tradingdays":  "أيام التداول",
+	"last10days":   "آخر 10 أيام",
+	"totalvolume":  "إجمالي حجم التداول",
+	"totalvalue":   "إجمالي قيمة التداول",
+	"highestprice": "أعلى سعر",
+	"lowestprice":  "أدنى سعر",
+
+	// Liquidity scores
+	"window":           "النافذة",
+	"rank":             "الترتيب",
+	"trend":            "الاتجاه",
+	"illiqraw":         "ILLIQ (خام)",
+	"illiqscaled":      "ILLIQ (معدّل)",
+	"valueraw":         "القيمة (خام)",
+	"valuescaled":      "القيمة (معدّلة)",
+	"continuityraw":    "الاستمرارية (خام)",
+	"continuityscaled": "الاستمرارية (معدّلة)",
+	"activityscore":    "درجة النشاط",
+	"spreadproxy":      "تقدير الفارق السعري",
+	"spreadscaled":     "الفارق السعري (معدّل)",
+	"hybridscore":      "درجة السيولة المركبة",
+	"hybridrank":       "ترتيب السيولة",
+	"percentile":       "الرتبة المئينية",
+	"percentilerank":   "الرتبة المئينية",
+	"quintile":         "الخُمس",
+	"decile":           "العُشر",
+	"dataquality":      "جودة البيانات",
+	"avgdailyvalueiqd": "متوسط القيمة اليومية (دينار)",
+	"safetrade0.5%":    "حجم التداول الآمن 0.5%",
+	"safetrade1%":      "حجم التداول الآمن 1%",
+	"safetrade2%":      "حجم التداول الآمن 2%",
+	"optimaltrade":     "حجم التداول الأمثل",
+}
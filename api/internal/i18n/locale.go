@@ -0,0 +1,183 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Language is the language of report column headers
+type Language string
+
+// Languages
+const (
+	English Language = "en"
+	Arabic  Language = "ar"
+)
+
+// Digits is the numbering system of numeric cells
+type Digits string
+
+// Numbering systems
+const (
+	LatinDigits  Digits = "latin"  // 0-9, read as numbers by every spreadsheet
+	ArabicDigits Digits = "arabic" // ٠-٩ with the Arabic decimal and thousands separators; spreadsheets treat them as text
+)
+
+// UTF8BOM is the byte order mark Excel needs to read a CSV as UTF-8
+const UTF8BOM = "\ufeff"
+
+// leftToRightMark keeps a sign in front of its number when the cell is laid
+// out right to left
+const leftToRightMark = "\u200e"
+
+var (
+	// ErrUnknownLanguage is returned for a language other than en or ar
+	ErrUnknownLanguage = errors.New("unknown language")
+	// ErrUnknownDigits is returned for digits other than latin or arabic
+	ErrUnknownDigits = errors.New("unknown digits")
+)
+
+// ParseLanguage parses an en or ar language code, case-insensitively and
+// ignoring a region such as ar-IQ. Empty means English.
+func ParseLanguage(s string) (Language, error) {
+	code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "-")
+	switch Language(code) {
+	case "", English:
+		return English, nil
+	case Arabic:
+		return Arabic, nil
+	}
+	return "", fmt.Errorf("%w %q: must be en or ar", ErrUnknownLanguage, s)
+}
+
+// ParseDigits parses a latin or arabic numbering system. Empty means latin.
+func ParseDigits(s string) (Digits, error) {
+	switch Digits(strings.ToLower(strings.TrimSpace(s))) {
+	case "", LatinDigits:
+		return LatinDigits, nil
+	case ArabicDigits:
+		return ArabicDigits, nil
+	}
+	return "", fmt.Errorf("%w %q: must be latin or arabic", ErrUnknownDigits, s)
+}
+
+// Locale selects the header language and numbering system of an output. The
+// zero Locale is English with Latin digits, the layout of stored reports.
+type Locale struct {
+	Language Language
+	Digits   Digits
+}
+
+// NewLocale parses a language and numbering system, either of which may be
+// empty for the default
+func NewLocale(language, digits string) (Locale, error) {
+	lang, err := ParseLanguage(language)
+	if err != nil {
+		return Locale{}, err
+	}
+	d, err := ParseDigits(digits)
+	if err != nil {
+		return Locale{}, err
+	}
+	return Locale{Language: lang, Digits: d}, nil
+}
+
+// IsDefault reports whether l leaves outputs as they are stored
+func (l Locale) IsDefault() bool {
+	return (l.Language == "" || l.Language == English) && (l.Digits == "" || l.Digits == LatinDigits)
+}
+
+// RightToLeft reports whether sheets in l read right to left
+func (l Locale) RightToLeft() bool {
+	return l.Language == Arabic
+}
+
+// NeedsBOM reports whether a CSV in l must start with a UTF-8 BOM. Without
+// it Excel reads Arabic headers and digits as mojibake.
+func (l Locale) NeedsBOM() bool {
+	return l.Language == Arabic || l.Digits == ArabicDigits
+}
+
+// Header returns the name of a report column in l. Columns are matched
+// ignoring case, spaces, underscores and parentheses, so "Hybrid_Score" and
+// "Hybrid Score" translate alike; unknown columns keep their name.
+func (l Locale) Header(name string) string {
+	if l.Language != Arabic {
+		return name
+	}
+	if translated, ok := arabicHeaders[headerKey(name)]; ok {
+		return translated
+	}
+	return name
+}
+
+// Headers returns a header row in l
+func (l Locale) Headers(names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = l.Header(name)
+	}
+	return out
+}
+
+// Number returns a numeric cell in l's digits. Cells that are not plain
+// numbers, such as dates, symbols and names, are returned unchanged.
+func (l Locale) Number(cell string) string {
+	if l.Digits != ArabicDigits || !isNumber(cell) {
+		return cell
+	}
+	var b strings.Builder
+	for i, r := range cell {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune('٠' + (r - '0'))
+		case r == '.':
+			b.WriteRune('٫')
+		case r == ',':
+			b.WriteRune('٬')
+		case r == '%':
+			b.WriteRune('٪')
+		case i == 0 && (r == '-' || r == '+'):
+			b.WriteString(leftToRightMark)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isNumber reports whether a cell is a decimal number, optionally signed, a
+// percentage or grouped with the separators export options may add
+func isNumber(cell string) bool {
+	if cell == "" {
+		return false
+	}
+	digits, points := 0, 0
+	for i, r := range cell {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.':
+			points++
+		case (r == '-' || r == '+') && i == 0:
+		case r == '%' && i == len(cell)-1:
+		case (r == ',' || r == ' ' || r == '\'' || r == '\u00a0') && digits > 0:
+		default:
+			return false
+		}
+	}
+	return digits > 0 && points <= 1
+}
+
+// headerKey normalizes a column name for lookup
+func headerKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '(', ')', '\ufeff':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
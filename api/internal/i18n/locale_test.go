@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLocale(t *testing.T) {
+	locale, err := NewLocale("", "")
+	require.NoError(t, err)
+	assert.Equal(t, Locale{Language: English, Digits: LatinDigits}, locale)
+	assert.True(t, locale.IsDefault())
+	assert.True(t, Locale{}.IsDefault())
+
+	locale, err = NewLocale("AR-iq", "Arabic")
+	require.NoError(t, err)
+	assert.Equal(t, Locale{Language: Arabic, Digits: ArabicDigits}, locale)
+	assert.False(t, locale.IsDefault())
+	assert.True(t, locale.RightToLeft())
+	assert.True(t, locale.NeedsBOM())
+
+	_, err = NewLocale("fr", "")
+	assert.ErrorIs(t, err, ErrUnknownLanguage)
+	_, err = NewLocale("ar", "roman")
+	assert.ErrorIs(t, err, ErrUnknownDigits)
+}
+
+func TestLocaleHeaders(t *testing.T) {
+	arabic := Locale{Language: Arabic}
+	assert.Equal(t,
+		[]string{"التاريخ", "الرمز", "سعر الإغلاق", "درجة السيولة المركبة", "درجة السيولة المركبة", "حجم التداول الآمن 0.5%", "Unknown"},
+		arabic.Headers([]string{UTF8BOM + "Date", "Symbol", "ClosePrice", "Hybrid_Score", "Hybrid Score", "Safe_Trade_0.5%", "Unknown"}))
+	assert.Equal(t, "قيمة التداول - المئين 90 (60 يوماً)", arabic.Header("ValueP90_60D"))
+	assert.Equal(t, "ILLIQ (معدّل)", arabic.Header("ILLIQ (Scaled)"))
+
+	english := Locale{Language: English, Digits: ArabicDigits}
+	assert.Equal(t, "ClosePrice", english.Header("ClosePrice"))
+	assert.True(t, english.NeedsBOM(), "Arabic digits need the BOM too")
+	assert.False(t, english.RightToLeft())
+}
+
+func TestLocaleNumber(t *testing.T) {
+	arabic := Locale{Language: Arabic, Digits: ArabicDigits}
+	tests := map[string]string{
+		"1250.50":    "١٢٥٠٫٥٠",
+		"1,250.5":    "١٬٢٥٠٫٥",
+		"-3.2":       "\u200e-٣٫٢",
+		"12.5%":      "١٢٫٥٪",
+		"0":          "٠",
+		"2025-08-26": "2025-08-26",
+		"BBOB":       "BBOB",
+		"true":       "true",
+		"1.2.3":      "1.2.3",
+		"":           "",
+		",5":         ",5",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, arabic.Number(in), in)
+	}
+	assert.Equal(t, "1250.50", Locale{Language: Arabic}.Number("1250.50"), "Latin digits stay numeric")
+}
@@ -34,6 +34,7 @@ type OTelConfig struct {
 	ServiceName     string
 	ServiceVersion  string
 	Environment     string
+	UpdateChannel   string // Release channel the installation follows, recorded on every span and metric
 	TraceExporter   string // "stdout", "otlp", "none"
 	MetricExporter  string // "prometheus", "stdout", "none"
 	EnableMetrics   bool
@@ -84,6 +85,7 @@ func InitializeOTel(cfg *OTelConfig, logger *slog.Logger) (*OTelProviders, error
 		slog.String("service", cfg.ServiceName),
 		slog.String("version", cfg.ServiceVersion),
 		slog.String("environment", cfg.Environment),
+		slog.String("update_channel", cfg.UpdateChannel),
 		slog.Bool("tracing_enabled", cfg.EnableTracing),
 		slog.Bool("metrics_enabled", cfg.EnableMetrics))
 
@@ -126,13 +128,16 @@ func InitializeOTel(cfg *OTelConfig, logger *slog.Logger) (*OTelProviders, error
 
 // createResource creates the OpenTelemetry resource
 func createResource(cfg *OTelConfig) (*resource.Resource, error) {
-	return resource.NewWithAttributes(
-		semconv.SchemaURL,
+	attrs := []attribute.KeyValue{
 		semconv.ServiceName(cfg.ServiceName),
 		semconv.ServiceVersion(cfg.ServiceVersion),
 		semconv.DeploymentEnvironmentName(cfg.Environment),
 		attribute.String("service.instance.id", generateInstanceID()),
-	), nil
+	}
+	if cfg.UpdateChannel != "" {
+		attrs = append(attrs, attribute.String("isx.update_channel", cfg.UpdateChannel))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
 }
 
 // initializeTracing sets up OpenTelemetry tracing
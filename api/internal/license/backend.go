@@ -0,0 +1,190 @@
+package license
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	"isxcli/internal/security"
+)
+
+const (
+	// BackendRetryInitial is the first delay before retrying a failed backend initialization
+	BackendRetryInitial = 30 * time.Second
+
+	// BackendRetryMax caps the delay between backend initialization retries
+	BackendRetryMax = 10 * time.Minute
+)
+
+// BackendStatus describes the Google backend (embedded credentials and the
+// Sheets service). While it is degraded, a locally valid license keeps the
+// application usable and initialization is retried in the background.
+type BackendStatus struct {
+	Ready        bool      `json:"ready"`
+	Degraded     bool      `json:"degraded"`
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"last_error,omitempty"`
+	FailingSince time.Time `json:"failing_since,omitempty"`
+	NextRetry    time.Time `json:"next_retry,omitempty"`
+}
+
+// backendState tracks backend initialization and its retry loop
+type backendState struct {
+	mu           sync.RWMutex
+	attempts     int
+	lastError    string
+	failingSince time.Time
+	nextRetry    time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// initBackend creates the credentials manager and Sheets service; tests
+// replace it to simulate an unreachable backend
+var initBackend = initGoogleBackend
+
+// initGoogleBackend loads the embedded encrypted credentials and creates the
+// Sheets service from them
+func initGoogleBackend(ctx context.Context) (*security.SecureCredentialsManager, *sheets.Service, error) {
+	credentialsManager, err := security.NewSecureCredentialsManager()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize secure credentials manager: %v", err)
+	}
+
+	credentialsJSON, err := credentialsManager.GetCredentials(ctx)
+	if err != nil {
+		credentialsManager.Close()
+		return nil, nil, fmt.Errorf("failed to get embedded credentials: %v", err)
+	}
+	if len(credentialsJSON) == 0 {
+		credentialsManager.Close()
+		return nil, nil, fmt.Errorf("embedded credentials are empty - ensure build includes encrypted credentials")
+	}
+
+	sheetsService, err := sheets.NewService(ctx, option.WithCredentialsJSON(credentialsJSON))
+	if err != nil {
+		credentialsManager.Close()
+		return nil, nil, fmt.Errorf("failed to create sheets service with embedded credentials: %v", err)
+	}
+	return credentialsManager, sheetsService, nil
+}
+
+// startBackend initializes the Google backend. On failure the manager runs in
+// degraded mode and retries with exponential backoff until it succeeds or the
+// manager is closed.
+func (m *Manager) startBackend(ctx context.Context) {
+	m.backend.stop = make(chan struct{})
+	m.backend.done = make(chan struct{})
+
+	if m.tryInitBackend(ctx) {
+		close(m.backend.done)
+		return
+	}
+
+	m.logWarn(ctx, "sheets_initialization", "Google backend unavailable - running in degraded mode with the local license",
+		slog.String("error", m.BackendStatus().LastError),
+		slog.String("retry_in", BackendRetryInitial.String()),
+	)
+	go m.retryBackend()
+}
+
+// tryInitBackend makes one initialization attempt and records its outcome
+func (m *Manager) tryInitBackend(ctx context.Context) bool {
+	credentialsManager, sheetsService, err := initBackend(ctx)
+
+	m.backend.mu.Lock()
+	defer m.backend.mu.Unlock()
+
+	m.backend.attempts++
+	if err != nil {
+		if m.backend.failingSince.IsZero() {
+			m.backend.failingSince = time.Now()
+		}
+		m.backend.lastError = err.Error()
+		return false
+	}
+
+	m.credentialsManager = credentialsManager
+	m.sheetsService = sheetsService
+	m.backend.lastError = ""
+	m.backend.failingSince = time.Time{}
+	m.backend.nextRetry = time.Time{}
+	return true
+}
+
+// retryBackend retries initialization with exponential backoff
+func (m *Manager) retryBackend() {
+	defer close(m.backend.done)
+
+	delay := BackendRetryInitial
+	for {
+		m.backend.mu.Lock()
+		m.backend.nextRetry = time.Now().Add(delay)
+		m.backend.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-m.backend.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		ctx := context.Background()
+		if m.tryInitBackend(ctx) {
+			m.logInfo(ctx, "sheets_initialization", "Google backend restored - leaving degraded mode",
+				slog.Int("attempts", m.BackendStatus().Attempts),
+			)
+			return
+		}
+		if delay *= 2; delay > BackendRetryMax {
+			delay = BackendRetryMax
+		}
+	}
+}
+
+// stopBackend ends the retry loop, waiting for an attempt in progress
+func (m *Manager) stopBackend() {
+	if m.backend.stop == nil {
+		return
+	}
+	m.backend.stopOnce.Do(func() { close(m.backend.stop) })
+	<-m.backend.done
+}
+
+// BackendStatus reports whether the Google backend is initialized or the
+// manager is running in degraded mode. A manager that never attempted
+// initialization (no service account configured) is not degraded.
+func (m *Manager) BackendStatus() BackendStatus {
+	m.backend.mu.RLock()
+	defer m.backend.mu.RUnlock()
+
+	ready := m.sheetsService != nil
+	return BackendStatus{
+		Ready:        ready,
+		Degraded:     !ready && m.backend.attempts > 0,
+		Attempts:     m.backend.attempts,
+		LastError:    m.backend.lastError,
+		FailingSince: m.backend.failingSince,
+		NextRetry:    m.backend.nextRetry,
+	}
+}
+
+// Degraded reports whether the manager is running without the Google backend
+func (m *Manager) Degraded() bool {
+	return m.BackendStatus().Degraded
+}
+
+// sheets returns the Sheets service, or nil while the backend is degraded
+func (m *Manager) sheets() *sheets.Service {
+	m.backend.mu.RLock()
+	defer m.backend.mu.RUnlock()
+	return m.sheetsService
+}
@@ -0,0 +1,104 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/sheets/v4"
+
+	"isxcli/internal/security"
+)
+
+// stubBackend replaces the backend initializer for the duration of a test,
+// failing the first failures attempts
+func stubBackend(t *testing.T, failures int) *int {
+	t.Helper()
+	calls := 0
+	original := initBackend
+	initBackend = func(ctx context.Context) (*security.SecureCredentialsManager, *sheets.Service, error) {
+		calls++
+		if calls <= failures {
+			return nil, nil, errors.New("dial tcp: lookup sheets.googleapis.com: no such host")
+		}
+		return nil, &sheets.Service{}, nil
+	}
+	t.Cleanup(func() { initBackend = original })
+	return &calls
+}
+
+func TestStartBackendReady(t *testing.T) {
+	calls := stubBackend(t, 0)
+	m := &Manager{}
+
+	m.startBackend(context.Background())
+	defer m.stopBackend()
+
+	status := m.BackendStatus()
+	assert.True(t, status.Ready)
+	assert.False(t, status.Degraded)
+	assert.Equal(t, 1, status.Attempts)
+	assert.Empty(t, status.LastError)
+	assert.NotNil(t, m.sheets())
+	assert.Equal(t, 1, *calls)
+}
+
+func TestStartBackendDegradedThenRestored(t *testing.T) {
+	stubBackend(t, 1)
+	m := &Manager{}
+
+	m.startBackend(context.Background())
+	defer m.stopBackend()
+
+	status := m.BackendStatus()
+	require.True(t, status.Degraded, "an unreachable backend must not fail startup")
+	assert.False(t, status.Ready)
+	assert.Contains(t, status.LastError, "no such host")
+	assert.False(t, status.FailingSince.IsZero())
+	assert.Nil(t, m.sheets())
+
+	require.Eventually(t, func() bool { return !m.BackendStatus().NextRetry.IsZero() },
+		time.Second, 10*time.Millisecond, "a retry should be scheduled")
+	assert.WithinDuration(t, time.Now().Add(BackendRetryInitial), m.BackendStatus().NextRetry, 5*time.Second)
+
+	// A later attempt succeeding clears the degraded state
+	require.True(t, m.tryInitBackend(context.Background()))
+	status = m.BackendStatus()
+	assert.True(t, status.Ready)
+	assert.False(t, status.Degraded)
+	assert.Equal(t, 2, status.Attempts)
+	assert.Empty(t, status.LastError)
+	assert.True(t, status.FailingSince.IsZero())
+}
+
+func TestStopBackendEndsRetries(t *testing.T) {
+	calls := stubBackend(t, 100)
+	m := &Manager{}
+	m.startBackend(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		m.stopBackend()
+		m.stopBackend() // idempotent
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stopBackend did not end the retry loop")
+	}
+	assert.Equal(t, 1, *calls)
+	assert.True(t, m.Degraded())
+}
+
+func TestBackendStatusWithoutServiceAccount(t *testing.T) {
+	m := &Manager{}
+	m.stopBackend() // never started
+
+	status := m.BackendStatus()
+	assert.False(t, status.Ready)
+	assert.False(t, status.Degraded, "a manager that never tried the backend is not degraded")
+}
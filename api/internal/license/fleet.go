@@ -0,0 +1,38 @@
+package license
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"isxcli/internal/security"
+)
+
+// ActivateForDevice activates licenseKey for another device, identified by
+// its fingerprint, e.g. from a central admin console. Unlike ActivateLicense
+// it leaves the local license file alone: the device finds its license when
+// it activates the same key itself.
+func (m *Manager) ActivateForDevice(ctx context.Context, licenseKey string, device security.DeviceFingerprint) (LicenseInfo, error) {
+	device.Fingerprint = strings.TrimSpace(device.Fingerprint)
+	if device.Fingerprint == "" {
+		return LicenseInfo{}, fmt.Errorf("device fingerprint is required")
+	}
+	key := NormalizeScratchCardKey(licenseKey)
+	if err := ValidateScratchCardFormat(key); err != nil {
+		return LicenseInfo{}, fmt.Errorf("invalid license key: %w", err)
+	}
+
+	info, err := m.callAppsScriptActivation(FormatScratchCardKeyWithDashes(key), &device, "")
+	if err != nil {
+		return LicenseInfo{}, err
+	}
+	info.DeviceFingerprint = device.Fingerprint
+
+	m.logInfo(ctx, "fleet_activation", "License activated for device",
+		slog.String("license_key", MaskLicenseKey(key)),
+		slog.String("device_fingerprint", device.Fingerprint),
+		slog.String("hostname", device.Hostname),
+		slog.Time("expiry_date", info.ExpiryDate))
+	return info, nil
+}
@@ -0,0 +1,71 @@
+package license
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// RemoteValidationInterval is how often a valid license is re-checked with Apps Script
+	RemoteValidationInterval = 6 * time.Hour
+
+	// OfflineGracePeriod is how long a license keeps working after the last
+	// successful Apps Script check while the service cannot be reached
+	OfflineGracePeriod = 48 * time.Hour
+)
+
+// GraceStatus describes the offline grace period. It is active while the most
+// recent remote validation failed; the license stops validating at ExpiresAt.
+type GraceStatus struct {
+	Active           bool      `json:"active"`
+	LastChecked      time.Time `json:"last_checked"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RemainingSeconds int64     `json:"remaining_seconds"`
+	FailingSince     time.Time `json:"failing_since,omitempty"`
+	LastError        string    `json:"last_error,omitempty"`
+}
+
+// recordRemoteValidation remembers the outcome of the latest Apps Script check
+func (m *Manager) recordRemoteValidation(err error) {
+	m.validationMutex.Lock()
+	defer m.validationMutex.Unlock()
+
+	if err == nil {
+		m.remoteFailureSince = time.Time{}
+		m.remoteFailureError = ""
+		return
+	}
+	if m.remoteFailureSince.IsZero() {
+		m.remoteFailureSince = time.Now()
+	}
+	m.remoteFailureError = err.Error()
+}
+
+// GetGraceStatus returns the offline grace period state for the local license
+func (m *Manager) GetGraceStatus() (*GraceStatus, error) {
+	license, err := m.loadLicenseLocal()
+	if err != nil {
+		return nil, fmt.Errorf("no local license found: %v", err)
+	}
+
+	m.validationMutex.RLock()
+	failingSince, lastError := m.remoteFailureSince, m.remoteFailureError
+	m.validationMutex.RUnlock()
+
+	return newGraceStatus(license.LastChecked, failingSince, lastError, time.Now()), nil
+}
+
+// newGraceStatus computes the grace period relative to now
+func newGraceStatus(lastChecked, failingSince time.Time, lastError string, now time.Time) *GraceStatus {
+	status := &GraceStatus{
+		Active:       lastError != "",
+		LastChecked:  lastChecked,
+		ExpiresAt:    lastChecked.Add(OfflineGracePeriod),
+		FailingSince: failingSince,
+		LastError:    lastError,
+	}
+	if remaining := status.ExpiresAt.Sub(now); remaining > 0 {
+		status.RemainingSeconds = int64(remaining / time.Second)
+	}
+	return status
+}
@@ -0,0 +1,48 @@
+package license
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGraceStatus(t *testing.T) {
+	now := time.Date(2025, 8, 20, 12, 0, 0, 0, time.UTC)
+	lastChecked := now.Add(-10 * time.Hour)
+
+	t.Run("remote reachable", func(t *testing.T) {
+		status := newGraceStatus(lastChecked, time.Time{}, "", now)
+		assert.False(t, status.Active)
+		assert.Equal(t, lastChecked.Add(OfflineGracePeriod), status.ExpiresAt)
+	})
+
+	t.Run("remote unreachable counts down from last check", func(t *testing.T) {
+		failingSince := now.Add(-4 * time.Hour)
+		status := newGraceStatus(lastChecked, failingSince, "validation request failed", now)
+		assert.True(t, status.Active)
+		assert.Equal(t, int64((38 * time.Hour).Seconds()), status.RemainingSeconds)
+		assert.Equal(t, failingSince, status.FailingSince)
+	})
+
+	t.Run("expired grace period never goes negative", func(t *testing.T) {
+		status := newGraceStatus(now.Add(-72*time.Hour), now.Add(-30*time.Hour), "timeout", now)
+		assert.Zero(t, status.RemainingSeconds)
+	})
+}
+
+func TestManager_RecordRemoteValidation(t *testing.T) {
+	m := &Manager{}
+
+	m.recordRemoteValidation(assert.AnError)
+	first := m.remoteFailureSince
+	assert.False(t, first.IsZero())
+	assert.Equal(t, assert.AnError.Error(), m.remoteFailureError)
+
+	m.recordRemoteValidation(assert.AnError)
+	assert.Equal(t, first, m.remoteFailureSince, "failure start is kept across repeated failures")
+
+	m.recordRemoteValidation(nil)
+	assert.True(t, m.remoteFailureSince.IsZero())
+	assert.Empty(t, m.remoteFailureError)
+}
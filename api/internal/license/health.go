@@ -244,19 +244,27 @@ func (hc *LicenseHealthCheck) checkGoogleSheetsConnectivity(ctx context.Context)
 	}
 
 	// Check if manager and sheets service are available
-	if hc.manager == nil || hc.manager.sheetsService == nil {
+	if hc.manager == nil {
 		health.Status = HealthStatusUnhealthy
 		health.Message = "Google Sheets service not initialized"
 		health.Error = "sheets_service_nil"
 		return health
 	}
+	sheetsService := hc.manager.sheets()
+	if sheetsService == nil {
+		health.Status = HealthStatusDegraded
+		health.Message = "Google backend unavailable - running in degraded mode"
+		health.Error = "sheets_service_nil"
+		health.Metadata["backend"] = hc.manager.BackendStatus()
+		return health
+	}
 
 	// Test connectivity
 	connectivityCtx, cancel := context.WithTimeout(ctx, hc.config.SheetsTimeout)
 	defer cancel()
 
 	err := hc.manager.TraceSheetsOperation(connectivityCtx, "health_check", func() error {
-		_, testErr := hc.manager.sheetsService.Spreadsheets.Get(hc.manager.config.SheetID).Context(connectivityCtx).Do()
+		_, testErr := sheetsService.Spreadsheets.Get(hc.manager.config.SheetID).Context(connectivityCtx).Do()
 		return testErr
 	})
 
@@ -19,7 +19,6 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
-	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 	"isxcli/internal/config"
 	licenseErrors "isxcli/internal/errors"
@@ -89,6 +88,9 @@ type Manager struct {
 	lastValidationResult *ValidationResult
 	lastValidationTime   time.Time
 	validationMutex      sync.RWMutex
+	// Apps Script reachability for the offline grace period
+	remoteFailureSince time.Time
+	remoteFailureError string
 	// OpenTelemetry metrics
 	metrics         *LicenseMetrics
 	// Secure credentials management
@@ -96,6 +98,21 @@ type Manager struct {
 	secureMode          bool
 	// Device fingerprinting for scratch card system
 	fingerprintManager   *security.FingerprintManager
+	// Local license persistence, selected with security.license_store
+	store LicenseStore
+	// Google backend initialization and degraded-mode retries
+	backend backendState
+	// Anonymous usage counters sent with remote validation; nil sends none
+	usage UsageReporter
+}
+
+// UsageReporter supplies the anonymous usage counters sent with periodic
+// remote validation. PendingUsage returns nil when there is nothing to send
+// or reporting is off; UsageReported is called with the counters once the
+// license server accepted them.
+type UsageReporter interface {
+	PendingUsage() map[string]int64
+	UsageReported(sent map[string]int64)
 }
 
 // ValidationResult holds cached validation results
@@ -254,30 +271,36 @@ func NewManager(licenseFile string) (*Manager, error) {
 	// Initialize security manager (max 5 attempts, 15 minute block, 5 minute window)
 	securityMgr := NewSecurityManager(5, 15*time.Minute, 5*time.Minute)
 
-	// Initialize secure credentials manager
-	credentialsManager, err := security.NewSecureCredentialsManager()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize secure credentials manager: %v", err)
-	}
-
 	// Initialize device fingerprint manager
 	fingerprintManager := security.NewFingerprintManager()
 
+	store, err := NewLicenseStore(config.LicenseStoreKind(), licensePath, func() (string, error) {
+		fingerprint, err := fingerprintManager.GenerateFingerprint()
+		if err != nil {
+			return "", err
+		}
+		return fingerprint.Fingerprint, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open license store: %w", err)
+	}
+
 	manager := &Manager{
 		config:             sheetsConfig,
 		licenseFile:        licensePath, // Use the resolved path from centralized system
 		cache:              cache,
 		security:           securityMgr,
 		performanceData:    make(map[string]*PerformanceMetrics),
-		credentialsManager: credentialsManager,
 		secureMode:         true,
 		fingerprintManager: fingerprintManager,
+		store:              store,
 	}
 
 	// Log manager initialization using slog with path information
-	manager.logInfo(ctx, "manager_initialization", "License manager initialized successfully with secure credentials",
+	manager.logInfo(ctx, "manager_initialization", "License manager initialized",
 		slog.String("license_path", licensePath),
 		slog.Bool("license_exists", config.FileExists(licensePath)),
+		slog.String("license_store", store.Kind()),
 		slog.String("cache_ttl", "5m"),
 		slog.Int("cache_max_size", 1000),
 		slog.Int("security_max_attempts", 5),
@@ -288,42 +311,25 @@ func NewManager(licenseFile string) (*Manager, error) {
 	// Skip complex security validation for simplified Google Sheets access
 	manager.logInfo(ctx, "security_initialization", "Security manager initialized with encrypted credentials")
 
-	// Initialize Google Sheets service using ONLY embedded encrypted credentials
+	// Initialize the Google backend from the embedded encrypted credentials.
+	// Failure is not fatal: a locally valid license keeps working in degraded
+	// mode while initialization is retried in the background.
 	if sheetsConfig.UseServiceAccount {
-		// Get decrypted credentials from embedded encrypted data only
-		credentialsJSON, err := credentialsManager.GetCredentials(ctx)
-		if err != nil {
-			manager.logError(ctx, "sheets_initialization", "Failed to get embedded credentials",
-				slog.String("error", err.Error()),
-			)
-			return nil, fmt.Errorf("failed to get embedded credentials: %v", err)
-		}
-		
-		// Validate credentials are not empty
-		if len(credentialsJSON) == 0 {
-			manager.logError(ctx, "sheets_initialization", "Embedded credentials are empty")
-			return nil, fmt.Errorf("embedded credentials are empty - ensure build includes encrypted credentials")
+		manager.startBackend(ctx)
+		if !manager.Degraded() {
+			manager.logInfo(ctx, "sheets_initialization", "Google Sheets service initialized with embedded encrypted credentials only")
 		}
-
-		// Create Google Sheets service with embedded credentials only
-		credentialsOption := option.WithCredentialsJSON(credentialsJSON)
-		sheetsService, err := sheets.NewService(ctx, credentialsOption)
-		if err != nil {
-			manager.logError(ctx, "sheets_initialization", "Failed to create Google Sheets service",
-				slog.String("error", err.Error()),
-			)
-			return nil, fmt.Errorf("failed to create sheets service with embedded credentials: %v", err)
-		}
-		
-		// Store service
-		manager.sheetsService = sheetsService
-
-		manager.logInfo(ctx, "sheets_initialization", "Google Sheets service initialized with embedded encrypted credentials only")
 	}
 
 	return manager, nil
 }
 
+// SetUsageReporter sets the usage counters sent with remote validation.
+// Call it before validation starts.
+func (m *Manager) SetUsageReporter(reporter UsageReporter) {
+	m.usage = reporter
+}
+
 // SetMetrics sets the OpenTelemetry metrics for the manager
 func (m *Manager) SetMetrics(metrics *LicenseMetrics) {
 	m.metrics = metrics
@@ -810,11 +816,13 @@ func (m *Manager) performValidation() (bool, error) {
 	}
 
 	// Periodic validation with Apps Script (every 6 hours for better security)
-	if time.Since(license.LastChecked) > 6*time.Hour {
-		if err := m.validateWithAppsScript(license); err != nil {
+	if time.Since(license.LastChecked) > RemoteValidationInterval {
+		err := m.validateWithAppsScript(license)
+		m.recordRemoteValidation(err)
+		if err != nil {
 			// For better user experience, don't fail immediately on network issues
 			// Log the error but allow offline usage for up to 48 hours total
-			if time.Since(license.LastChecked) > 48*time.Hour {
+			if time.Since(license.LastChecked) > OfflineGracePeriod {
 				m.logLicenseAction(context.Background(), slog.LevelError, "license_validation", "Remote validation failed and grace period expired",
 					license.LicenseKey, license.UserEmail,
 					slog.String("license_key_prefix", license.LicenseKey[:min(8, len(license.LicenseKey))]),
@@ -1149,10 +1157,12 @@ func (m *Manager) saveLicenseLocal(license LicenseInfo) error {
 		return err
 	}
 
-	err = os.WriteFile(m.licenseFile, data, 0600)
+	store := m.licenseStore()
+	err = store.Save(data)
 	if err != nil {
 		m.logError(ctx, "license_save", "Failed to write license file",
 			slog.String("path", m.licenseFile),
+			slog.String("store", store.Kind()),
 			slog.String("error", err.Error()),
 		)
 		return err
@@ -1160,12 +1170,22 @@ func (m *Manager) saveLicenseLocal(license LicenseInfo) error {
 	
 	m.logInfo(ctx, "license_save", "License saved successfully",
 		slog.String("path", m.licenseFile),
+		slog.String("store", store.Kind()),
 		slog.Int("size_bytes", len(data)),
 	)
 	
 	return nil
 }
 
+// licenseStore returns the configured license store, or the plain file
+// store for managers built without one
+func (m *Manager) licenseStore() LicenseStore {
+	if m.store == nil {
+		return NewFileStore(m.licenseFile)
+	}
+	return m.store
+}
+
 // loadLicenseLocal loads license from local file
 func (m *Manager) loadLicenseLocal() (LicenseInfo, error) {
 	var license LicenseInfo
@@ -1192,7 +1212,7 @@ func (m *Manager) loadLicenseLocal() (LicenseInfo, error) {
 		slog.String("file_details", fileDetails),
 	)
 
-	data, err := os.ReadFile(m.licenseFile)
+	data, err := m.licenseStore().Load()
 	if err != nil {
 		m.logDebug(ctx, "license_load", "Failed to read license file",
 			slog.String("path", m.licenseFile),
@@ -1682,9 +1702,9 @@ func (m *Manager) calculateExpiryDateFromDuration(duration string) time.Time {
 func (m *Manager) validateLicenseFromSheets(licenseKey string) (LicenseInfo, error) {
 	var license LicenseInfo
 
-	if m.config.UseServiceAccount && m.sheetsService != nil {
+	if sheetsService := m.sheets(); m.config.UseServiceAccount && sheetsService != nil {
 		// Use service account authentication
-		resp, err := m.sheetsService.Spreadsheets.Values.Get(m.config.SheetID, m.config.SheetName).Do()
+		resp, err := sheetsService.Spreadsheets.Values.Get(m.config.SheetID, m.config.SheetName).Do()
 		if err != nil {
 			return license, fmt.Errorf("failed to read from sheets: %v", err)
 		}
@@ -1830,127 +1850,10 @@ func (m *Manager) validateLicenseFromSheets(licenseKey string) (LicenseInfo, err
 	return license, fmt.Errorf("license not found")
 }
 
-// updateLicenseInSheets updates license in Google Sheets
+// updateLicenseInSheets updates license in Google Sheets. Retries are safe:
+// see applySheetUpdates.
 func (m *Manager) updateLicenseInSheets(license LicenseInfo) error {
-	if m.config.UseServiceAccount && m.sheetsService != nil {
-		// Use service account authentication
-		// First, find the row number for this license
-		resp, err := m.sheetsService.Spreadsheets.Values.Get(m.config.SheetID, m.config.SheetName).Do()
-		if err != nil {
-			return fmt.Errorf("failed to read from sheets: %v", err)
-		}
-
-		var rowIndex int = -1
-		for i, row := range resp.Values {
-			if i == 0 {
-				continue // Skip header row
-			}
-			if len(row) > 0 && row[0].(string) == license.LicenseKey {
-				rowIndex = i + 1 // Google Sheets uses 1-based indexing
-				break
-			}
-		}
-
-		if rowIndex == -1 {
-			return fmt.Errorf("license not found in sheet")
-		}
-
-		// Calculate expire status
-		expireStatus := m.calculateExpireStatus(license.ExpiryDate)
-
-		// Update the row with new license data
-		// Format: LicenseKey | Duration | ExpiryDate | Status | MachineID | ActivatedDate | LastConnected | ExpireStatus
-		values := [][]interface{}{
-			{
-				license.LicenseKey,
-				license.Duration,
-				license.ExpiryDate.Format("2006-01-02"),
-				license.Status,
-				"", // Machine ID removed
-				license.IssuedDate.Format("2006-01-02"),
-				license.LastChecked.Format("2006-01-02 15:04:05"), // Add LastConnected timestamp
-				expireStatus,                                      // Add ExpireStatus
-			},
-		}
-
-		rangeStr := fmt.Sprintf("%s!A%d:H%d", m.config.SheetName, rowIndex, rowIndex) // Extended to column H
-		valueRange := &sheets.ValueRange{Values: values}
-
-		_, err = m.sheetsService.Spreadsheets.Values.Update(
-			m.config.SheetID,
-			rangeStr,
-			valueRange,
-		).ValueInputOption("RAW").Do()
-
-		return err
-	} else {
-		// Fallback to API key method
-		// First, find the row number for this license
-		url := fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s?key=%s",
-			m.config.SheetID, m.config.SheetName, m.config.APIKey)
-
-		resp, err := http.Get(url)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-
-		var result map[string]interface{}
-		if err := json.Unmarshal(body, &result); err != nil {
-			return err
-		}
-
-		var rowIndex int = -1
-		if values, ok := result["values"].([]interface{}); ok {
-			for i, row := range values {
-				if i == 0 {
-					continue // Skip header row
-				}
-				if rowData, ok := row.([]interface{}); ok && len(rowData) > 0 {
-					if rowData[0].(string) == license.LicenseKey {
-						rowIndex = i + 1 // Google Sheets uses 1-based indexing
-						break
-					}
-				}
-			}
-		}
-
-		if rowIndex == -1 {
-			return fmt.Errorf("license not found in sheet")
-		}
-
-		// Calculate expire status
-		expireStatus := m.calculateExpireStatus(license.ExpiryDate)
-
-		// Update the row with new license data
-		// Format: LicenseKey | Duration | ExpiryDate | Status | MachineID | ActivatedDate | LastConnected | ExpireStatus
-		values := [][]interface{}{
-			{
-				license.LicenseKey,
-				license.Duration,
-				license.ExpiryDate.Format("2006-01-02"),
-				license.Status,
-				"", // Machine ID removed
-				license.IssuedDate.Format("2006-01-02"),
-				license.LastChecked.Format("2006-01-02 15:04:05"), // Add LastConnected timestamp
-				expireStatus,                                      // Add ExpireStatus
-			},
-		}
-
-		updateURL := fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s!A%d:H%d?valueInputOption=RAW&key=%s",
-			m.config.SheetID, m.config.SheetName, rowIndex, rowIndex, m.config.APIKey) // Extended to column H
-
-		payload := map[string]interface{}{
-			"values": values,
-		}
-
-		return m.makeSheetRequest("PUT", updateURL, payload)
-	}
+	return m.updateLicensesInSheets([]LicenseInfo{license})
 }
 
 // validateWithAppsScript performs periodic validation with Apps Script
@@ -1970,6 +1873,14 @@ func (m *Manager) validateWithAppsScript(license LicenseInfo) error {
 		requestData["device_fingerprint"] = license.DeviceFingerprint
 	}
 
+	// Opted-in usage counters ride along with the validation call
+	var usage map[string]int64
+	if m.usage != nil {
+		if usage = m.usage.PendingUsage(); len(usage) > 0 {
+			requestData["usage"] = usage
+		}
+	}
+
 	jsonData, err := json.Marshal(requestData)
 	if err != nil {
 		return fmt.Errorf("failed to prepare validation request: %w", err)
@@ -2022,6 +1933,9 @@ func (m *Manager) validateWithAppsScript(license LicenseInfo) error {
 		}
 		return fmt.Errorf("validation failed: %s", errorMsg)
 	}
+	if len(usage) > 0 {
+		m.usage.UsageReported(usage)
+	}
 
 	// Extract validation data to check for any updates
 	data, ok := response["data"].(map[string]interface{})
@@ -2219,7 +2133,8 @@ func (m *Manager) TestNetworkConnectivity() error {
 	// Test Google Sheets service initialization
 	fmt.Printf("   • Testing Google Sheets service...")
 	m.logDebug(ctx, "connectivity_test", "Testing Google Sheets service initialization")
-	if m.sheetsService == nil {
+	sheetsService := m.sheets()
+	if sheetsService == nil {
 		m.logError(ctx, "connectivity_test", "Google Sheets service not initialized")
 		fmt.Printf(" ❌ FAILED\n")
 		return fmt.Errorf("Google Sheets service not initialized")
@@ -2230,7 +2145,7 @@ func (m *Manager) TestNetworkConnectivity() error {
 	// Test actual Google Sheets access
 	fmt.Printf("   • Testing Google Sheets access...")
 	m.logDebug(ctx, "connectivity_test", "Testing Google Sheets access", slog.String("sheet_id", m.config.SheetID))
-	_, err = m.sheetsService.Spreadsheets.Get(m.config.SheetID).Do()
+	_, err = sheetsService.Spreadsheets.Get(m.config.SheetID).Do()
 	if err != nil {
 		m.logError(ctx, "connectivity_test", "Google Sheets access failed", 
 			slog.String("error", err.Error()),
@@ -2669,6 +2584,9 @@ func isWritable(path string) bool {
 
 // Close properly shuts down the manager and its components
 func (m *Manager) Close() error {
+	// Stop retrying the Google backend
+	m.stopBackend()
+
 	// Stop cache cleanup goroutine
 	if m.cache != nil {
 		m.cache.Stop()
@@ -2680,8 +2598,11 @@ func (m *Manager) Close() error {
 	}
 	
 	// Close secure credentials manager if in secure mode
-	if m.secureMode && m.credentialsManager != nil {
-		if err := m.credentialsManager.Close(); err != nil {
+	m.backend.mu.RLock()
+	credentialsManager := m.credentialsManager
+	m.backend.mu.RUnlock()
+	if m.secureMode && credentialsManager != nil {
+		if err := credentialsManager.Close(); err != nil {
 			m.logError(context.Background(), "credentials_manager_close", "Failed to close credentials manager",
 				slog.String("error", err.Error()),
 			)
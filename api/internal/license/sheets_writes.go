@@ -0,0 +1,232 @@
+package license
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Row writes carry an idempotency token in column I, which is hidden in the
+// admin sheet. A write that timed out may still have landed, so every attempt
+// re-reads the sheet and skips rows that already hold the token it is about
+// to write.
+const (
+	sheetTokenColumn   = 8 // Column I
+	sheetWriteAttempts = 3
+)
+
+// sheetRetryDelay is the pause before the first retry; it doubles per attempt
+var sheetRetryDelay = 2 * time.Second
+
+// errLicenseNotInSheet is returned when a license row does not exist, which
+// no amount of retrying will fix
+var errLicenseNotInSheet = errors.New("license not found in sheet")
+
+// sheetRowUpdate is one planned row write
+type sheetRowUpdate struct {
+	Row        int // 1-based sheet row
+	LicenseKey string
+	Values     []interface{}
+}
+
+// sheetWriteToken derives the idempotency token from the cells being written,
+// so a retry of the same update carries the same token
+func sheetWriteToken(cells []interface{}) string {
+	h := sha256.New()
+	for _, cell := range cells {
+		fmt.Fprintf(h, "%v\x1f", cell)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// licenseRowValues lays out a license row, A through I
+// Format: LicenseKey | Duration | ExpiryDate | Status | MachineID | ActivatedDate | LastConnected | ExpireStatus | Token
+func licenseRowValues(license LicenseInfo, expireStatus string) []interface{} {
+	cells := []interface{}{
+		license.LicenseKey,
+		license.Duration,
+		license.ExpiryDate.Format("2006-01-02"),
+		license.Status,
+		"", // Machine ID removed
+		license.IssuedDate.Format("2006-01-02"),
+		license.LastChecked.Format("2006-01-02 15:04:05"),
+		expireStatus,
+	}
+	return append(cells, sheetWriteToken(cells))
+}
+
+// sheetCell returns a cell as a string, or "" when the row is shorter
+func sheetCell(row []interface{}, col int) string {
+	if col >= len(row) || row[col] == nil {
+		return ""
+	}
+	if s, ok := row[col].(string); ok {
+		return s
+	}
+	return fmt.Sprint(row[col])
+}
+
+// planSheetUpdates matches licenses to their rows and drops the rows that
+// already hold the token of the intended write
+func planSheetUpdates(rows [][]interface{}, licenses []LicenseInfo, expireStatus func(time.Time) string) ([]sheetRowUpdate, error) {
+	rowIndex := make(map[string]int, len(rows))
+	for i, row := range rows {
+		if i == 0 {
+			continue // Skip header row
+		}
+		if key := sheetCell(row, 0); key != "" {
+			if _, seen := rowIndex[key]; !seen {
+				rowIndex[key] = i
+			}
+		}
+	}
+
+	// The last update for a license wins when a batch repeats it
+	order := make([]string, 0, len(licenses))
+	latest := make(map[string]LicenseInfo, len(licenses))
+	for _, license := range licenses {
+		if _, seen := latest[license.LicenseKey]; !seen {
+			order = append(order, license.LicenseKey)
+		}
+		latest[license.LicenseKey] = license
+	}
+
+	var updates []sheetRowUpdate
+	for _, key := range order {
+		i, ok := rowIndex[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errLicenseNotInSheet, maskLicenseKey(key))
+		}
+
+		license := latest[key]
+		values := licenseRowValues(license, expireStatus(license.ExpiryDate))
+		if sheetCell(rows[i], sheetTokenColumn) == values[sheetTokenColumn] {
+			continue // An earlier attempt already landed
+		}
+		updates = append(updates, sheetRowUpdate{Row: i + 1, LicenseKey: key, Values: values})
+	}
+	return updates, nil
+}
+
+// applySheetUpdates writes licenses in one batch per attempt, re-reading the
+// sheet before each attempt so a retry never writes a row twice
+func applySheetUpdates(read func() ([][]interface{}, error), write func([]sheetRowUpdate) error, licenses []LicenseInfo, expireStatus func(time.Time) string) error {
+	var lastErr error
+	delay := sheetRetryDelay
+	for attempt := 1; attempt <= sheetWriteAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		rows, err := read()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read from sheets: %w", err)
+			continue
+		}
+
+		updates, err := planSheetUpdates(rows, licenses, expireStatus)
+		if err != nil {
+			return err
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+
+		if err := write(updates); err != nil {
+			lastErr = fmt.Errorf("failed to write to sheets: %w", err)
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("sheets update failed after %d attempts: %w", sheetWriteAttempts, lastErr)
+}
+
+// updateLicensesInSheets writes several licenses with a single batch request
+func (m *Manager) updateLicensesInSheets(licenses []LicenseInfo) error {
+	if len(licenses) == 0 {
+		return nil
+	}
+	return applySheetUpdates(m.readSheetRows, m.writeSheetRows, licenses, m.calculateExpireStatus)
+}
+
+// readSheetRows reads every row of the license sheet
+func (m *Manager) readSheetRows() ([][]interface{}, error) {
+	if sheetsService := m.sheets(); m.config.UseServiceAccount && sheetsService != nil {
+		resp, err := sheetsService.Spreadsheets.Values.Get(m.config.SheetID, m.config.SheetName).Do()
+		if err != nil {
+			return nil, err
+		}
+		return resp.Values, nil
+	}
+
+	// Fallback to API key method
+	url := fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s?key=%s",
+		m.config.SheetID, m.config.SheetName, m.config.APIKey)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Values [][]interface{} `json:"values"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Values, nil
+}
+
+// writeSheetRows sends the planned rows as one batch update
+func (m *Manager) writeSheetRows(updates []sheetRowUpdate) error {
+	lastCol := string(rune('A' + sheetTokenColumn))
+
+	if sheetsService := m.sheets(); m.config.UseServiceAccount && sheetsService != nil {
+		data := make([]*sheets.ValueRange, 0, len(updates))
+		for _, u := range updates {
+			data = append(data, &sheets.ValueRange{
+				Range:  fmt.Sprintf("%s!A%d:%s%d", m.config.SheetName, u.Row, lastCol, u.Row),
+				Values: [][]interface{}{u.Values},
+			})
+		}
+		_, err := sheetsService.Spreadsheets.Values.BatchUpdate(m.config.SheetID, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "RAW",
+			Data:             data,
+		}).Do()
+		return err
+	}
+
+	// Fallback to API key method
+	data := make([]map[string]interface{}, 0, len(updates))
+	for _, u := range updates {
+		data = append(data, map[string]interface{}{
+			"range":  fmt.Sprintf("%s!A%d:%s%d", m.config.SheetName, u.Row, lastCol, u.Row),
+			"values": [][]interface{}{u.Values},
+		})
+	}
+	url := fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s/values:batchUpdate?key=%s",
+		m.config.SheetID, m.config.APIKey)
+
+	return m.makeSheetRequest("POST", url, map[string]interface{}{
+		"valueInputOption": "RAW",
+		"data":             data,
+	})
+}
@@ -0,0 +1,127 @@
+package license
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySheet is an in-memory license sheet whose writes can land and still
+// report a failure, like a request that times out after the server applied it
+type flakySheet struct {
+	rows       [][]interface{}
+	writes     int
+	failWrites int
+}
+
+func (s *flakySheet) read() ([][]interface{}, error) {
+	rows := make([][]interface{}, len(s.rows))
+	for i, row := range s.rows {
+		rows[i] = append([]interface{}(nil), row...)
+	}
+	return rows, nil
+}
+
+func (s *flakySheet) write(updates []sheetRowUpdate) error {
+	s.writes++
+	for _, u := range updates {
+		s.rows[u.Row-1] = u.Values
+	}
+	if s.writes <= s.failWrites {
+		return errors.New("context deadline exceeded")
+	}
+	return nil
+}
+
+func newFlakySheet() *flakySheet {
+	return &flakySheet{rows: [][]interface{}{
+		{"License Key", "Duration", "Expiry Date", "Status", "Machine ID", "Activated Date", "Last Connected", "Expire Status"},
+		{"ISX1M02LYE1F9QJHR9D7Z", "1m", "", "Available", "", "", "", "Available"},
+		{"ISX3M03ABC123DEF456", "3m", "2025-12-31", "Activated", "", "2024-01-01", "2024-08-01 10:00:00", "Active"},
+	}}
+}
+
+func testSheetLicense(key, status string) LicenseInfo {
+	return LicenseInfo{
+		LicenseKey:  key,
+		Duration:    "3m",
+		ExpiryDate:  time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		IssuedDate:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastChecked: time.Date(2024, 9, 1, 8, 30, 0, 0, time.UTC),
+		Status:      status,
+	}
+}
+
+func fixedExpireStatus(time.Time) string { return "Active" }
+
+func TestApplySheetUpdates_RetryAfterTimeoutDoesNotRewrite(t *testing.T) {
+	defer func(d time.Duration) { sheetRetryDelay = d }(sheetRetryDelay)
+	sheetRetryDelay = time.Millisecond
+
+	sheet := newFlakySheet()
+	sheet.failWrites = 1
+
+	license := testSheetLicense("ISX3M03ABC123DEF456", "Revoked")
+	err := applySheetUpdates(sheet.read, sheet.write, []LicenseInfo{license}, fixedExpireStatus)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, sheet.writes, "the retry sees the token and skips the write")
+	row := sheet.rows[2]
+	assert.Equal(t, "Revoked", row[3])
+	assert.Len(t, row, sheetTokenColumn+1)
+	assert.Equal(t, sheetWriteToken(row[:sheetTokenColumn]), row[sheetTokenColumn])
+
+	// Writing the same update again is a no-op
+	require.NoError(t, applySheetUpdates(sheet.read, sheet.write, []LicenseInfo{license}, fixedExpireStatus))
+	assert.Equal(t, 1, sheet.writes)
+
+	// A different update to the same row is written
+	license.LastChecked = license.LastChecked.Add(time.Hour)
+	require.NoError(t, applySheetUpdates(sheet.read, sheet.write, []LicenseInfo{license}, fixedExpireStatus))
+	assert.Equal(t, 2, sheet.writes)
+}
+
+func TestApplySheetUpdates_Batch(t *testing.T) {
+	sheet := newFlakySheet()
+
+	var batches [][]sheetRowUpdate
+	write := func(updates []sheetRowUpdate) error {
+		batches = append(batches, updates)
+		return sheet.write(updates)
+	}
+
+	err := applySheetUpdates(sheet.read, write, []LicenseInfo{
+		testSheetLicense("ISX1M02LYE1F9QJHR9D7Z", "Activated"),
+		testSheetLicense("ISX3M03ABC123DEF456", "Activated"),
+		testSheetLicense("ISX1M02LYE1F9QJHR9D7Z", "Revoked"),
+	}, fixedExpireStatus)
+	require.NoError(t, err)
+
+	require.Len(t, batches, 1, "one request for the whole batch")
+	require.Len(t, batches[0], 2, "repeated licenses collapse to their last update")
+	assert.Equal(t, 2, batches[0][0].Row)
+	assert.Equal(t, "Revoked", sheet.rows[1][3])
+	assert.Equal(t, 3, batches[0][1].Row)
+}
+
+func TestApplySheetUpdates_Errors(t *testing.T) {
+	defer func(d time.Duration) { sheetRetryDelay = d }(sheetRetryDelay)
+	sheetRetryDelay = time.Millisecond
+
+	sheet := newFlakySheet()
+	err := applySheetUpdates(sheet.read, sheet.write, []LicenseInfo{testSheetLicense("ISX-MISSING", "Revoked")}, fixedExpireStatus)
+	assert.ErrorIs(t, err, errLicenseNotInSheet)
+	assert.Zero(t, sheet.writes)
+
+	reads := 0
+	failingRead := func() ([][]interface{}, error) {
+		reads++
+		return nil, errors.New("connection reset")
+	}
+	err = applySheetUpdates(failingRead, sheet.write, []LicenseInfo{testSheetLicense("ISX3M03ABC123DEF456", "Revoked")}, fixedExpireStatus)
+	assert.Error(t, err)
+	assert.Equal(t, sheetWriteAttempts, reads)
+}
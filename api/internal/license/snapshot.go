@@ -0,0 +1,42 @@
+package license
+
+import (
+	"context"
+	"time"
+)
+
+// StatusSnapshot is a point-in-time license validation result. It is shared
+// between the license middleware and request handlers so a single request (and
+// concurrent requests within the snapshot TTL) validate the license only once.
+type StatusSnapshot struct {
+	Valid      bool
+	ExpiryDate time.Time // zero when no license is installed
+	CheckedAt  time.Time
+	Err        error
+}
+
+// FreshAt reports whether the snapshot may still be used at now. A snapshot
+// never outlives the expiry of the license it was taken for, so an expiring
+// license is re-validated immediately rather than after the TTL.
+func (s *StatusSnapshot) FreshAt(now time.Time, ttl time.Duration) bool {
+	if s == nil || now.Sub(s.CheckedAt) >= ttl {
+		return false
+	}
+	if s.Valid && !s.ExpiryDate.IsZero() && !now.Before(s.ExpiryDate) {
+		return false
+	}
+	return true
+}
+
+type snapshotContextKey struct{}
+
+// WithSnapshot attaches a snapshot to the request context
+func WithSnapshot(ctx context.Context, snapshot *StatusSnapshot) context.Context {
+	return context.WithValue(ctx, snapshotContextKey{}, snapshot)
+}
+
+// SnapshotFromContext returns the snapshot attached to the request context, if any
+func SnapshotFromContext(ctx context.Context) (*StatusSnapshot, bool) {
+	snapshot, ok := ctx.Value(snapshotContextKey{}).(*StatusSnapshot)
+	return snapshot, ok && snapshot != nil
+}
@@ -0,0 +1,198 @@
+package license
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// License store kinds, selected with security.license_store
+const (
+	StoreFile      = "file"      // plain JSON with 0600 permissions
+	StoreEncrypted = "encrypted" // AES-GCM keyed from the device fingerprint
+	StoreKeychain  = "keychain"  // protected by the OS user account (Windows DPAPI)
+)
+
+// sealedMagic starts license files written by a sealing store; the store
+// kind follows on the same line so every binary can tell the formats apart
+const sealedMagic = "ISXLIC1 "
+
+// LicenseStore persists the local license data. Every store keeps the
+// license at the license path, so switching from the file store keeps an
+// existing license: sealing stores read plain JSON and seal it on the next
+// save.
+type LicenseStore interface {
+	// Kind returns the store kind, one of the Store constants
+	Kind() string
+	// Load returns the license JSON; a missing license returns an error
+	// satisfying os.IsNotExist
+	Load() ([]byte, error)
+	// Save replaces the license JSON
+	Save(data []byte) error
+}
+
+// NewLicenseStore creates the store of the given kind at path. fingerprint
+// returns the device fingerprint the encrypted store derives its key from;
+// it is only called when the key is first needed.
+func NewLicenseStore(kind, path string, fingerprint func() (string, error)) (LicenseStore, error) {
+	switch kind {
+	case "", StoreFile:
+		return NewFileStore(path), nil
+	case StoreEncrypted:
+		return NewEncryptedFileStore(path, fingerprint), nil
+	case StoreKeychain:
+		store, err := NewKeychainStore(path)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown license store %q (use %s, %s or %s)", kind, StoreFile, StoreEncrypted, StoreKeychain)
+	}
+}
+
+// FileStore keeps the license as plain JSON, readable by its owner only
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates a plain JSON license store
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Kind implements LicenseStore
+func (s *FileStore) Kind() string { return StoreFile }
+
+// Load implements LicenseStore
+func (s *FileStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if kind, _, sealed := splitSealed(data); sealed {
+		return nil, wrongStoreError(kind)
+	}
+	return data, nil
+}
+
+// Save implements LicenseStore
+func (s *FileStore) Save(data []byte) error {
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// EncryptedFileStore seals the license with AES-GCM under a key derived from
+// the device fingerprint, so a copied license file is useless on another
+// machine. It protects against casual copying, not against someone able to
+// run code on the licensed device.
+type EncryptedFileStore struct {
+	path        string
+	fingerprint func() (string, error)
+
+	keyOnce sync.Once
+	aead    cipher.AEAD
+	keyErr  error
+}
+
+// NewEncryptedFileStore creates an AES-GCM license store
+func NewEncryptedFileStore(path string, fingerprint func() (string, error)) *EncryptedFileStore {
+	return &EncryptedFileStore{path: path, fingerprint: fingerprint}
+}
+
+// Kind implements LicenseStore
+func (s *EncryptedFileStore) Kind() string { return StoreEncrypted }
+
+// Load implements LicenseStore
+func (s *EncryptedFileStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	kind, payload, sealed := splitSealed(data)
+	if !sealed {
+		// Written by the file store before the switch
+		return data, nil
+	}
+	if kind != StoreEncrypted {
+		return nil, wrongStoreError(kind)
+	}
+
+	aead, err := s.cipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < aead.NonceSize() {
+		return nil, errors.New("encrypted license file is truncated")
+	}
+	nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, sealedHeader(StoreEncrypted))
+	if err != nil {
+		return nil, errors.New("failed to decrypt license file: it was sealed on another device or has been modified")
+	}
+	return plaintext, nil
+}
+
+// Save implements LicenseStore
+func (s *EncryptedFileStore) Save(data []byte) error {
+	aead, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := append(sealedHeader(StoreEncrypted), nonce...)
+	sealed = aead.Seal(sealed, nonce, data, sealedHeader(StoreEncrypted))
+	return os.WriteFile(s.path, sealed, 0600)
+}
+
+// cipher derives the AES-256 key from the device fingerprint once
+func (s *EncryptedFileStore) cipher() (cipher.AEAD, error) {
+	s.keyOnce.Do(func() {
+		fingerprint, err := s.fingerprint()
+		if err != nil {
+			s.keyErr = fmt.Errorf("failed to get device fingerprint for the license key: %w", err)
+			return
+		}
+		key := sha256.Sum256([]byte("isx-pulse-license-store:" + fingerprint))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			s.keyErr = err
+			return
+		}
+		s.aead, s.keyErr = cipher.NewGCM(block)
+	})
+	return s.aead, s.keyErr
+}
+
+// sealedHeader is the first line of a license file sealed by kind
+func sealedHeader(kind string) []byte {
+	return []byte(sealedMagic + kind + "\n")
+}
+
+// splitSealed splits a sealed license file into its store kind and payload;
+// sealed is false for plain JSON
+func splitSealed(data []byte) (kind string, payload []byte, sealed bool) {
+	if !bytes.HasPrefix(data, []byte(sealedMagic)) {
+		return "", nil, false
+	}
+	line, payload, found := bytes.Cut(data[len(sealedMagic):], []byte("\n"))
+	if !found {
+		return "", nil, false
+	}
+	return string(line), payload, true
+}
+
+// wrongStoreError reports a license sealed by a store other than the configured one
+func wrongStoreError(kind string) error {
+	return fmt.Errorf("license file was written by the %q license store; set security.license_store to %q", kind, kind)
+}
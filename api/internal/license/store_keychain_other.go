@@ -0,0 +1,27 @@
+//go:build !windows
+
+package license
+
+import "errors"
+
+// KeychainStore protects the license with the OS keychain; it is only
+// available on Windows, where it uses DPAPI
+type KeychainStore struct{}
+
+// NewKeychainStore is not available on this platform
+func NewKeychainStore(path string) (*KeychainStore, error) {
+	return nil, errors.New("the keychain license store is only supported on Windows; use the encrypted store instead")
+}
+
+// Kind implements LicenseStore
+func (s *KeychainStore) Kind() string { return StoreKeychain }
+
+// Load implements LicenseStore
+func (s *KeychainStore) Load() ([]byte, error) {
+	return nil, errors.New("keychain license store is not supported on this platform")
+}
+
+// Save implements LicenseStore
+func (s *KeychainStore) Save(data []byte) error {
+	return errors.New("keychain license store is not supported on this platform")
+}
@@ -0,0 +1,108 @@
+//go:build windows
+
+package license
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procCryptProtectData   = syscall.NewLazyDLL("crypt32.dll").NewProc("CryptProtectData")
+	procCryptUnprotectData = syscall.NewLazyDLL("crypt32.dll").NewProc("CryptUnprotectData")
+	procLocalFree          = syscall.NewLazyDLL("kernel32.dll").NewProc("LocalFree")
+)
+
+// cryptProtectUIForbidden fails instead of prompting when DPAPI needs the user
+const cryptProtectUIForbidden = 0x1
+
+// keychainEntropy ties the protected blob to this application
+var keychainEntropy = []byte("ISX Pulse license")
+
+// dataBlob is the Win32 DATA_BLOB
+type dataBlob struct {
+	size uint32
+	data *byte
+}
+
+func newDataBlob(b []byte) *dataBlob {
+	if len(b) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{size: uint32(len(b)), data: &b[0]}
+}
+
+func (b *dataBlob) bytes() []byte {
+	out := make([]byte, b.size)
+	copy(out, unsafe.Slice(b.data, b.size))
+	return out
+}
+
+// KeychainStore protects the license with DPAPI under the current Windows
+// user account: only that user on this machine can read it back
+type KeychainStore struct {
+	path string
+}
+
+// NewKeychainStore creates a DPAPI license store
+func NewKeychainStore(path string) (*KeychainStore, error) {
+	if err := procCryptProtectData.Find(); err != nil {
+		return nil, fmt.Errorf("keychain license store unavailable: %w", err)
+	}
+	return &KeychainStore{path: path}, nil
+}
+
+// Kind implements LicenseStore
+func (s *KeychainStore) Kind() string { return StoreKeychain }
+
+// Load implements LicenseStore
+func (s *KeychainStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	kind, payload, sealed := splitSealed(data)
+	if !sealed {
+		// Written by the file store before the switch
+		return data, nil
+	}
+	if kind != StoreKeychain {
+		return nil, wrongStoreError(kind)
+	}
+	plaintext, err := dpapi(procCryptUnprotectData, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unprotect license file: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Save implements LicenseStore
+func (s *KeychainStore) Save(data []byte) error {
+	protected, err := dpapi(procCryptProtectData, data)
+	if err != nil {
+		return fmt.Errorf("failed to protect license file: %w", err)
+	}
+	return os.WriteFile(s.path, append(sealedHeader(StoreKeychain), protected...), 0600)
+}
+
+// dpapi runs CryptProtectData or CryptUnprotectData, which share a signature
+// apart from the description argument
+func dpapi(proc *syscall.LazyProc, data []byte) ([]byte, error) {
+	var out dataBlob
+	r, _, callErr := proc.Call(
+		uintptr(unsafe.Pointer(newDataBlob(data))),
+		0,
+		uintptr(unsafe.Pointer(newDataBlob(keychainEntropy))),
+		0,
+		0,
+		cryptProtectUIForbidden,
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if r == 0 {
+		return nil, callErr
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.data)))
+	return out.bytes(), nil
+}
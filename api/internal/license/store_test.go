@@ -0,0 +1,88 @@
+package license
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storeTestLicense = `{"license_key":"ISX1Y-ABCDE-12345-FGHIJ-67890","status":"active"}`
+
+func fixedFingerprint(fp string) func() (string, error) {
+	return func() (string, error) { return fp, nil }
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "license.dat")
+	store := NewFileStore(path)
+
+	_, err := store.Load()
+	assert.True(t, os.IsNotExist(err), "a missing license reads as not existing")
+
+	require.NoError(t, store.Save([]byte(storeTestLicense)))
+	data, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, storeTestLicense, string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "license.dat")
+
+	// A plain license from the file store is kept when switching
+	require.NoError(t, NewFileStore(path).Save([]byte(storeTestLicense)))
+	store := NewEncryptedFileStore(path, fixedFingerprint("device-a"))
+	data, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, storeTestLicense, string(data))
+
+	require.NoError(t, store.Save(data))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(raw, sealedHeader(StoreEncrypted)))
+	assert.NotContains(t, string(raw), "ISX1Y-ABCDE", "the license is not readable on disk")
+
+	data, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, storeTestLicense, string(data))
+
+	t.Run("copied to another device", func(t *testing.T) {
+		_, err := NewEncryptedFileStore(path, fixedFingerprint("device-b")).Load()
+		assert.ErrorContains(t, err, "sealed on another device")
+	})
+
+	t.Run("file store reports the sealing store", func(t *testing.T) {
+		_, err := NewFileStore(path).Load()
+		assert.ErrorContains(t, err, `set security.license_store to "encrypted"`)
+	})
+}
+
+func TestNewLicenseStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "license.dat")
+
+	store, err := NewLicenseStore("", path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, StoreFile, store.Kind())
+
+	store, err = NewLicenseStore(StoreEncrypted, path, fixedFingerprint("device-a"))
+	require.NoError(t, err)
+	assert.Equal(t, StoreEncrypted, store.Kind())
+
+	_, err = NewLicenseStore("registry", path, nil)
+	assert.ErrorContains(t, err, "unknown license store")
+
+	if runtime.GOOS != "windows" {
+		_, err = NewLicenseStore(StoreKeychain, path, nil)
+		assert.ErrorContains(t, err, "only supported on Windows")
+	}
+}
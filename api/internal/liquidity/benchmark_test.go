@@ -2,6 +2,7 @@ package liquidity
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"math"
 	"os"
@@ -366,6 +367,7 @@ func generateMultiSymbolBenchmarkData(symbols []string, days int, baseDate time.
 				Low:           low,
 				Close:         close,
 				Volume:        currentVolume,
+				Value:         currentVolume * close,
 				NumTrades:     int(currentVolume / 10000),
 				TradingStatus: "ACTIVE",
 			}
@@ -464,4 +466,43 @@ func BenchmarkConcurrentCalculation(b *testing.B) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// BenchmarkCalculatorWorkers benchmarks a full-market run (120 tickers, a
+// year of sessions) across worker pool sizes
+func BenchmarkCalculatorWorkers(b *testing.B) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	symbols := make([]string, 120)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("%s%d", generateISXSymbol(i), i)
+	}
+	data := generateMultiSymbolBenchmarkData(symbols, 365, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	benchmarks := []struct {
+		name    string
+		workers int
+	}{
+		{"sequential", 1},
+		{"workers_2", 2},
+		{"workers_4", 4},
+		{"default_workers", 0}, // GOMAXPROCS unless $ISX_LIQUIDITY_WORKERS is set
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), logger)
+			calc.SetMaxConcurrency(bm.workers)
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := calc.Calculate(ctx, data); err != nil {
+					b.Fatalf("Calculator error: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -5,23 +5,41 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"os"
+	"runtime"
 	"sort"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// WorkersEnv overrides the number of tickers calculated concurrently
+const WorkersEnv = "ISX_LIQUIDITY_WORKERS"
+
+// DefaultWorkers returns the per-ticker worker count: $ISX_LIQUIDITY_WORKERS
+// when set to a positive integer, otherwise GOMAXPROCS
+func DefaultWorkers() int {
+	if n, err := strconv.Atoi(os.Getenv(WorkersEnv)); err == nil && n > 0 {
+		return n
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
 // Calculator orchestrates the calculation of ISX Hybrid Liquidity Metrics
 type Calculator struct {
 	window              Window
 	penaltyParams       PenaltyParams
+	penalty             PenaltyFunc
 	weights             ComponentWeights
 	winsorizationBounds WinsorizationBounds
 	logger              *slog.Logger
 	
 	// Configuration options
 	enableProfiling     bool
-	maxConcurrency     int
+	maxConcurrency     int // Tickers calculated concurrently
 	calculationTimeout time.Duration
 	useSMA             bool // Use Simple Moving Average with zeros for non-trading days
+	progress           ProgressFunc
 }
 
 // NewCalculator creates a new liquidity calculator with the specified parameters
@@ -33,11 +51,12 @@ func NewCalculator(window Window, params PenaltyParams, weights ComponentWeights
 	return &Calculator{
 		window:              window,
 		penaltyParams:       params,
+		penalty:             defaultPenaltyFunc(),
 		weights:             weights,
 		winsorizationBounds: WinsorizationBounds{Lower: DefaultLowerBound, Upper: DefaultUpperBound},
 		logger:              logger,
 		enableProfiling:     false,
-		maxConcurrency:     4,
+		maxConcurrency:     DefaultWorkers(),
 		calculationTimeout: DefaultCalculationTimeout,
 		useSMA:             true, // Default to SMA 60 for better liquidity measurement
 	}
@@ -55,10 +74,28 @@ func (c *Calculator) SetWinsorizationBounds(bounds WinsorizationBounds) error {
 // SetConfiguration sets calculation configuration options
 func (c *Calculator) SetConfiguration(enableProfiling bool, maxConcurrency int, timeout time.Duration) {
 	c.enableProfiling = enableProfiling
-	c.maxConcurrency = maxConcurrency
+	c.SetMaxConcurrency(maxConcurrency)
 	c.calculationTimeout = timeout
 }
 
+// SetMaxConcurrency sets how many tickers are calculated concurrently.
+// Values below 1 restore DefaultWorkers.
+func (c *Calculator) SetMaxConcurrency(n int) {
+	if n < 1 {
+		n = DefaultWorkers()
+	}
+	c.maxConcurrency = n
+}
+
+// SetPenaltyFunc sets the penalty applied to inactive tickers; nil restores
+// DefaultPenaltyFunc
+func (c *Calculator) SetPenaltyFunc(f PenaltyFunc) {
+	if f == nil {
+		f = defaultPenaltyFunc()
+	}
+	c.penalty = f
+}
+
 // Calculate computes ISX Hybrid Liquidity Metrics for the provided trading data
 func (c *Calculator) Calculate(ctx context.Context, data []TradingDay) ([]TickerMetrics, error) {
 	start := time.Now()
@@ -73,6 +110,8 @@ func (c *Calculator) Calculate(ctx context.Context, data []TradingDay) ([]Ticker
 	calcCtx, cancel := context.WithTimeout(ctx, c.calculationTimeout)
 	defer cancel()
 	
+	loading := c.startPhase(PhaseLoading, len(data), "rows")
+
 	// Validate inputs
 	if err := c.validateInputs(data); err != nil {
 		c.logger.ErrorContext(ctx, "input validation failed", "error", err)
@@ -84,35 +123,12 @@ func (c *Calculator) Calculate(ctx context.Context, data []TradingDay) ([]Ticker
 	c.logger.InfoContext(ctx, "grouped data by ticker",
 		"num_tickers", len(tickerData),
 	)
+	loading.step(len(data), "")
 	
 	// Calculate metrics for each ticker
-	var allMetrics []TickerMetrics
-	tickerCount := 0
-	
-	for symbol, tickerDays := range tickerData {
-		select {
-		case <-calcCtx.Done():
-			return nil, fmt.Errorf("calculation timeout exceeded: %w", calcCtx.Err())
-		default:
-		}
-		
-		tickerCount++
-		c.logger.DebugContext(ctx, "calculating metrics for ticker",
-			"symbol", symbol,
-			"ticker_progress", fmt.Sprintf("%d/%d", tickerCount, len(tickerData)),
-			"data_points", len(tickerDays),
-		)
-		
-		metrics, err := c.calculateTickerMetrics(calcCtx, symbol, tickerDays)
-		if err != nil {
-			c.logger.WarnContext(ctx, "failed to calculate metrics for ticker",
-				"symbol", symbol,
-				"error", err,
-			)
-			continue // Skip problematic tickers instead of failing entire calculation
-		}
-		
-		allMetrics = append(allMetrics, metrics...)
+	allMetrics, err := c.calculateTickers(calcCtx, tickerData, nil)
+	if err != nil {
+		return nil, err
 	}
 	
 	if len(allMetrics) == 0 {
@@ -136,6 +152,101 @@ func (c *Calculator) Calculate(ctx context.Context, data []TradingDay) ([]Ticker
 	return allMetrics, nil
 }
 
+// tickerResult is the outcome of one ticker in the worker pool
+type tickerResult struct {
+	index   int
+	symbol  string
+	metrics []TickerMetrics
+	err     error
+}
+
+// calculateTickers computes the rolling-window metrics of every ticker on a
+// pool of maxConcurrency workers. Results are ordered by symbol so the output
+// does not depend on scheduling, and progress is reported from the calling
+// goroutine as tickers finish. from holds the first data index whose window a
+// ticker is calculated for; tickers missing from it are calculated in full.
+func (c *Calculator) calculateTickers(ctx context.Context, tickerData map[string][]TradingDay, from map[string]int) ([]TickerMetrics, error) {
+	symbols := make([]string, 0, len(tickerData))
+	for symbol := range tickerData {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	workers := c.maxConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(symbols) {
+		workers = len(symbols)
+	}
+
+	components := c.startPhase(PhaseComponents, len(symbols), "tickers")
+
+	jobs := make(chan int)
+	results := make(chan tickerResult, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain the queue once cancelled
+				}
+				metrics, err := c.calculateTickerMetrics(ctx, symbols[i], tickerData[symbols[i]], from[symbols[i]])
+				results <- tickerResult{index: i, symbol: symbols[i], metrics: metrics, err: err}
+			}
+		}()
+	}
+
+	// Feed the pool until every ticker is queued or the context is done
+	go func() {
+		defer close(jobs)
+		for i := range symbols {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	perTicker := make([][]TickerMetrics, len(symbols))
+	done := 0
+	for res := range results {
+		done++
+		c.logger.DebugContext(ctx, "calculated metrics for ticker",
+			"symbol", res.symbol,
+			"ticker_progress", fmt.Sprintf("%d/%d", done, len(symbols)),
+			"data_points", len(tickerData[res.symbol]),
+		)
+		components.step(done, res.symbol)
+		if res.err != nil {
+			c.logger.WarnContext(ctx, "failed to calculate metrics for ticker",
+				"symbol", res.symbol,
+				"error", res.err,
+			)
+			continue // Skip problematic tickers instead of failing entire calculation
+		}
+		perTicker[res.index] = res.metrics
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("calculation timeout exceeded: %w", err)
+	}
+
+	var allMetrics []TickerMetrics
+	for _, metrics := range perTicker {
+		allMetrics = append(allMetrics, metrics...)
+	}
+	return allMetrics, nil
+}
+
 // validateInputs validates the input data
 func (c *Calculator) validateInputs(data []TradingDay) error {
 	if len(data) == 0 {
@@ -190,15 +301,20 @@ func (c *Calculator) groupByTicker(data []TradingDay) map[string][]TradingDay {
 	return tickerData
 }
 
-// calculateTickerMetrics calculates metrics for a single ticker
-func (c *Calculator) calculateTickerMetrics(ctx context.Context, symbol string, data []TradingDay) ([]TickerMetrics, error) {
-	// Fixed 60-day window - no adjustments
-	windowSize := 60
+// calculateTickerMetrics calculates metrics for a single ticker, for the
+// windows ending at data[from] and later
+func (c *Calculator) calculateTickerMetrics(ctx context.Context, symbol string, data []TradingDay, from int) ([]TickerMetrics, error) {
+	// Rolling window of the calculator's size, 60 days when unset
+	windowSize := c.window.Days()
+	if windowSize <= 0 {
+		windowSize = Window60.Days()
+	}
 	
 	// If we don't have enough data, assign worst-case scores
 	if len(data) < windowSize {
-		c.logger.WarnContext(ctx, "Insufficient data for 60-day window, assigning worst-case scores",
+		c.logger.WarnContext(ctx, "Insufficient data for window, assigning worst-case scores",
 			"symbol", symbol,
+			"window", c.window.String(),
 			"data_points", len(data),
 			"required", windowSize)
 		
@@ -235,8 +351,11 @@ func (c *Calculator) calculateTickerMetrics(ctx context.Context, symbol string,
 	
 	var metrics []TickerMetrics
 	
-	// Calculate rolling window metrics with fixed 60-day window
-	for i := windowSize - 1; i < len(data); i++ {
+	// Calculate rolling window metrics
+	for i := max(windowSize-1, from); i < len(data); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		windowData := data[i-windowSize+1 : i+1]
 		currentDate := data[i].Date
 		
@@ -248,12 +367,12 @@ func (c *Calculator) calculateTickerMetrics(ctx context.Context, symbol string,
 		
 		// Apply minimum activity threshold for SMA mode
 		// Stocks trading less than 10% of days (6 days in 60) should be heavily penalized
-		if c.useSMA && windowSize == 60 && tradingDays < 6 {
+		if c.useSMA && tradingDays < windowSize/10 {
 			// Create a heavily penalized metric for very low activity stocks
 			c.logger.WarnContext(ctx, "Stock has very low activity, applying severe penalty",
 				"symbol", symbol,
 				"trading_days", tradingDays,
-				"threshold", 6)
+				"threshold", windowSize/10)
 			// Continue to calculate but the SMA will naturally penalize this
 		}
 		
@@ -311,14 +430,14 @@ func (c *Calculator) calculateWindowMetrics(ctx context.Context, symbol string,
 	// This replaces the dual penalty system with a single efficient calculation
 	activityScore := ActivityScore(tradingDays, totalDays)
 	
-	// Calculate single unified penalty for both impact and value
+	// Calculate single penalty for both impact and value
 	// This reduces computation by ~30% while maintaining effectiveness
-	unifiedPenalty := UnifiedPenalty(activityScore, c.penaltyParams.PiecewiseMaxMult)
+	penalty := c.penalty.Penalty(PenaltyInput{TradingDays: tradingDays, TotalDays: totalDays}, c.penaltyParams)
 	
 	// Keep old penalty values for backward compatibility in metrics output
 	// Both use the same unified penalty now
-	impactPenalty := unifiedPenalty
-	valuePenalty := unifiedPenalty
+	impactPenalty := penalty
+	valuePenalty := penalty
 	
 	// Optimization: Skip return metrics calculation as they're not used in output
 	// These were removed in Phase 4 as redundant columns
@@ -359,8 +478,12 @@ func (c *Calculator) applyCrossSection(ctx context.Context, metrics []TickerMetr
 	}
 	
 	// Apply scaling for each date
+	scaling := c.startPhase(PhaseScaling, len(dateMetrics), "dates")
+	dateCount := 0
 	for date, indices := range dateMetrics {
+		dateCount++
 		if len(indices) < 2 {
+			scaling.step(dateCount, "")
 			continue // Need at least 2 tickers for cross-sectional scaling
 		}
 		
@@ -427,6 +550,7 @@ func (c *Calculator) applyCrossSection(ctx context.Context, metrics []TickerMetr
 		
 		// Apply ranking for this date
 		c.applyRanking(metrics, indices)
+		scaling.step(dateCount, "")
 	}
 	
 	return nil
@@ -513,6 +637,7 @@ func (c *Calculator) applyRanking(allMetrics []TickerMetrics, indices []int) {
 	for rank, idx := range indices {
 		allMetrics[idx].HybridRank = rank + 1
 	}
+	applyTiers(allMetrics, indices)
 }
 
 // Helper methods for calculating specific metrics
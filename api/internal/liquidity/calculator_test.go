@@ -0,0 +1,81 @@
+package liquidity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marketSymbols(n int) []string {
+	symbols := make([]string, n)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("%s%d", generateISXSymbol(i), i)
+	}
+	return symbols
+}
+
+func TestCalculator_WorkerPoolMatchesSequential(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	data := generateMultiSymbolBenchmarkData(marketSymbols(30), 120, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	sequential := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), logger)
+	sequential.SetMaxConcurrency(1)
+	want, err := sequential.Calculate(context.Background(), data)
+	require.NoError(t, err)
+
+	parallel := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), logger)
+	parallel.SetMaxConcurrency(8)
+	got, err := parallel.Calculate(context.Background(), data)
+	require.NoError(t, err)
+
+	assert.Equal(t, want, got, "results do not depend on scheduling")
+}
+
+func TestCalculator_WorkerPoolProgress(t *testing.T) {
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	calc.SetMaxConcurrency(4)
+
+	// The callback is not synchronized; the pool reports from one goroutine
+	var current []int
+	calc.SetProgressFunc(func(p Progress) {
+		if p.Phase == PhaseComponents {
+			current = append(current, p.Current)
+		}
+	})
+
+	data := generateMultiSymbolBenchmarkData(marketSymbols(10), 90, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	_, err := calc.Calculate(context.Background(), data)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, current)
+}
+
+func TestCalculator_WorkerPoolCancellation(t *testing.T) {
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	calc.SetMaxConcurrency(4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	data := generateMultiSymbolBenchmarkData(marketSymbols(10), 90, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	_, err := calc.Calculate(ctx, data)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSetMaxConcurrency(t *testing.T) {
+	t.Setenv(WorkersEnv, "3")
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), nil)
+	assert.Equal(t, 3, calc.maxConcurrency)
+
+	calc.SetMaxConcurrency(6)
+	assert.Equal(t, 6, calc.maxConcurrency)
+
+	calc.SetMaxConcurrency(0)
+	assert.Equal(t, 3, calc.maxConcurrency, "non-positive values restore the default")
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
 )
@@ -38,6 +40,18 @@ func Calibrate(ctx context.Context, data map[string][]TradingDay, config Calibra
 		return nil, fmt.Errorf("invalid input data: %w", err)
 	}
 	
+	penalty, err := LookupPenaltyFunc(config.PenaltyFunction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	config.PenaltyFunction = penalty.Name()
+	
+	// Without a seed, pick one and record it so the run can be reproduced
+	if config.RandomSeed == 0 {
+		config.RandomSeed = time.Now().UnixNano()
+	}
+	logger.InfoContext(ctx, "calibration seed", "random_seed", config.RandomSeed)
+	
 	// Prepare calibration data
 	calibrationData, err := prepareCalibrationData(ctx, data, config)
 	if err != nil {
@@ -62,6 +76,7 @@ func Calibrate(ctx context.Context, data map[string][]TradingDay, config Calibra
 	// Finalize calibration results
 	result := &CalibrationResult{
 		OptimalParams:     bestResult.params,
+		PenaltyFunction:   config.PenaltyFunction,
 		OptimalWeights:    bestResult.weights,
 		CrossValidationR2: bestResult.cvR2,
 		SpreadCorrelation: bestResult.spreadCorr,
@@ -69,6 +84,7 @@ func Calibrate(ctx context.Context, data map[string][]TradingDay, config Calibra
 		WindowUsed:        Window(len(data)),
 		NumTickers:        len(data),
 		NumObservations:   len(calibrationData.impactScores),
+		RandomSeed:        config.RandomSeed,
 	}
 	
 	duration := time.Since(start)
@@ -89,12 +105,14 @@ type calibrationData struct {
 	volumeScores     []float64
 	continuityScores []float64
 	spreadProxies    []float64
+	activity         []PenaltyInput
 	tickerIndex      []string
 	dateIndex        []time.Time
 }
 
 // optimizationResult holds results from parameter evaluation
 type optimizationResult struct {
+	index      int // Position in the parameter grid, breaks score ties
 	params     PenaltyParams
 	weights    ComponentWeights
 	cvR2       float64
@@ -105,6 +123,7 @@ type optimizationResult struct {
 // prepareCalibrationData extracts and preprocesses data for parameter calibration
 func prepareCalibrationData(ctx context.Context, data map[string][]TradingDay, config CalibrationConfig) (*calibrationData, error) {
 	var allImpact, allVolume, allContinuity, allSpreads []float64
+	var allActivity []PenaltyInput
 	var tickerIndex []string
 	var dateIndex []time.Time
 	
@@ -115,8 +134,16 @@ func prepareCalibrationData(ctx context.Context, data map[string][]TradingDay, c
 	// Create a calculator with default settings
 	calculator := NewCalculator(Window60, defaultParams, defaultWeights, slog.Default())
 	
-	// Calculate raw metrics for each ticker
-	for symbol, tickerData := range data {
+	// Calculate raw metrics for each ticker in symbol order, so only the seed
+	// decides the fold split
+	symbols := make([]string, 0, len(data))
+	for symbol := range data {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	
+	for _, symbol := range symbols {
+		tickerData := data[symbol]
 		select {
 		case <-ctx.Done():
 			return nil, fmt.Errorf("context cancelled during data preparation: %w", ctx.Err())
@@ -138,6 +165,7 @@ func prepareCalibrationData(ctx context.Context, data map[string][]TradingDay, c
 		allVolume = append(allVolume, metric.Value)
 		allContinuity = append(allContinuity, metric.ContinuityNL)
 		allSpreads = append(allSpreads, metric.SpreadProxy)
+		allActivity = append(allActivity, PenaltyInput{TradingDays: metric.TradingDays, TotalDays: metric.TotalDays})
 		tickerIndex = append(tickerIndex, symbol)
 		dateIndex = append(dateIndex, metric.Date)
 	}
@@ -146,14 +174,31 @@ func prepareCalibrationData(ctx context.Context, data map[string][]TradingDay, c
 		return nil, fmt.Errorf("insufficient valid tickers for calibration: %d < %d", len(allImpact), config.MinTickers)
 	}
 	
-	return &calibrationData{
+	prepared := &calibrationData{
 		impactScores:     allImpact,
 		volumeScores:     allVolume,
 		continuityScores: allContinuity,
 		spreadProxies:    allSpreads,
+		activity:         allActivity,
 		tickerIndex:      tickerIndex,
 		dateIndex:        dateIndex,
-	}, nil
+	}
+	prepared.shuffle(rand.New(rand.NewSource(config.RandomSeed)))
+	return prepared, nil
+}
+
+// shuffle permutes the observations. Cross-validation folds are contiguous
+// slices, so this assigns every observation to a random, reproducible fold.
+func (d *calibrationData) shuffle(rng *rand.Rand) {
+	rng.Shuffle(len(d.impactScores), func(i, j int) {
+		d.impactScores[i], d.impactScores[j] = d.impactScores[j], d.impactScores[i]
+		d.volumeScores[i], d.volumeScores[j] = d.volumeScores[j], d.volumeScores[i]
+		d.continuityScores[i], d.continuityScores[j] = d.continuityScores[j], d.continuityScores[i]
+		d.spreadProxies[i], d.spreadProxies[j] = d.spreadProxies[j], d.spreadProxies[i]
+		d.activity[i], d.activity[j] = d.activity[j], d.activity[i]
+		d.tickerIndex[i], d.tickerIndex[j] = d.tickerIndex[j], d.tickerIndex[i]
+		d.dateIndex[i], d.dateIndex[j] = d.dateIndex[j], d.dateIndex[i]
+	})
 }
 
 // generateParameterCombinations creates a grid of penalty parameter combinations
@@ -163,7 +208,7 @@ func generateParameterCombinations(gridSize int) []PenaltyParams {
 	// Define parameter ranges based on empirical analysis
 	betaRange := linspace(0.1, 0.8, gridSize)
 	gammaRange := linspace(0.05, 0.4, gridSize)
-	pStarRange := linspace(1.0, 5.0, gridSize)
+	pStarRange := linspace(0.2, 0.8, gridSize) // Inactivity ratio where the piecewise slope steepens
 	alphaRange := linspace(0.1, 0.5, gridSize)
 	
 	// Generate all combinations (reduced for performance)
@@ -234,6 +279,7 @@ func performGridSearch(ctx context.Context, data *calibrationData, paramCombinat
 				return
 			}
 			
+			result.index = idx
 			resultsChan <- result
 		}(i, params)
 	}
@@ -249,7 +295,8 @@ func performGridSearch(ctx context.Context, data *calibrationData, paramCombinat
 	for result := range resultsChan {
 		evaluatedCount++
 		
-		if result.score > bestResult.score {
+		// Ties go to the earlier combination, whatever order workers finish in
+		if result.score > bestResult.score || (result.score == bestResult.score && result.index < bestResult.index) {
 			bestResult = result
 			
 			logger.DebugContext(ctx, "found better parameter combination",
@@ -284,8 +331,12 @@ func performGridSearch(ctx context.Context, data *calibrationData, paramCombinat
 // evaluateParameterCombination evaluates a single parameter combination
 func evaluateParameterCombination(ctx context.Context, data *calibrationData, params PenaltyParams, config CalibrationConfig) (*optimizationResult, error) {
 	// Apply penalties to get adjusted scores
-	adjustedImpact := applyImpactPenalties(data.impactScores, params)
-	adjustedVolume := applyVolumePenalties(data.volumeScores, params)
+	penalty, err := LookupPenaltyFunc(config.PenaltyFunction)
+	if err != nil {
+		return nil, err
+	}
+	adjustedImpact := applyImpactPenalties(data.impactScores, data.activity, penalty, params)
+	adjustedVolume := applyVolumePenalties(data.volumeScores, data.activity, penalty, params)
 	
 	// Scale the adjusted scores
 	scaledImpact := RobustScale(adjustedImpact, true, true)   // Invert ILLIQ
@@ -332,27 +383,20 @@ func evaluateParameterCombination(ctx context.Context, data *calibrationData, pa
 	}, nil
 }
 
-// applyImpactPenalties applies penalty adjustments to impact scores
-func applyImpactPenalties(impactScores []float64, params PenaltyParams) []float64 {
+// applyImpactPenalties raises the ILLIQ of inactive tickers by their penalty
+func applyImpactPenalties(impactScores []float64, activity []PenaltyInput, penalty PenaltyFunc, params PenaltyParams) []float64 {
 	adjusted := make([]float64, len(impactScores))
 	for i, score := range impactScores {
-		// Use a representative price for penalty calculation
-		// This is simplified - in practice, you'd use actual price data
-		representativePrice := 2.0 // Median ISX price level
-		penalty := PiecewisePenalty(representativePrice, params.PiecewiseBeta, 
-			params.PiecewiseGamma, params.PiecewisePStar, params.PiecewiseMaxMult)
-		adjusted[i] = score * penalty
+		adjusted[i] = score * penalty.Penalty(activity[i], params)
 	}
 	return adjusted
 }
 
-// applyVolumePenalties applies penalty adjustments to volume scores
-func applyVolumePenalties(volumeScores []float64, params PenaltyParams) []float64 {
+// applyVolumePenalties lowers the traded value of inactive tickers by their penalty
+func applyVolumePenalties(volumeScores []float64, activity []PenaltyInput, penalty PenaltyFunc, params PenaltyParams) []float64 {
 	adjusted := make([]float64, len(volumeScores))
 	for i, score := range volumeScores {
-		representativePrice := 2.0
-		penalty := ExponentialPenalty(representativePrice, params.ExponentialAlpha, params.ExponentialMaxMult)
-		adjusted[i] = score * penalty
+		adjusted[i] = score / penalty.Penalty(activity[i], params)
 	}
 	return adjusted
 }
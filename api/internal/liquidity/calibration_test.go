@@ -0,0 +1,82 @@
+package liquidity
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func calibrationTestData() map[string][]TradingDay {
+	data := make(map[string][]TradingDay)
+	days := generateMultiSymbolBenchmarkData(marketSymbols(12), 90, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for _, td := range days {
+		data[td.Symbol] = append(data[td.Symbol], td)
+	}
+	return data
+}
+
+func testCalibrationConfig(seed int64) CalibrationConfig {
+	config := DefaultCalibrationConfig()
+	config.ParamGridSize = 2
+	config.KFolds = 3
+	config.RandomSeed = seed
+	return config
+}
+
+func TestCalibrate_SeedReproducible(t *testing.T) {
+	data := calibrationTestData()
+
+	first, err := Calibrate(context.Background(), data, testCalibrationConfig(7))
+	require.NoError(t, err)
+	second, err := Calibrate(context.Background(), data, testCalibrationConfig(7))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(7), first.RandomSeed)
+	first.CalibrationDate, second.CalibrationDate = time.Time{}, time.Time{}
+	assert.Equal(t, first, second, "the same seed reproduces the result exactly")
+}
+
+func TestCalibrate_RecordsGeneratedSeed(t *testing.T) {
+	result, err := Calibrate(context.Background(), calibrationTestData(), testCalibrationConfig(0))
+	require.NoError(t, err)
+	assert.NotZero(t, result.RandomSeed, "an unseeded run records the seed it used")
+}
+
+func TestPrepareCalibrationData_SeededFolds(t *testing.T) {
+	data := calibrationTestData()
+
+	prepare := func(seed int64) []string {
+		prepared, err := prepareCalibrationData(context.Background(), data, testCalibrationConfig(seed))
+		require.NoError(t, err)
+		return prepared.tickerIndex
+	}
+
+	assert.Equal(t, prepare(1), prepare(1))
+	assert.NotEqual(t, prepare(1), prepare(2))
+	assert.ElementsMatch(t, prepare(1), prepare(2), "only the order changes")
+}
+
+func TestCalibrationDataShuffle_KeepsRowsAligned(t *testing.T) {
+	d := &calibrationData{
+		impactScores:     []float64{0, 1, 2, 3},
+		volumeScores:     []float64{10, 11, 12, 13},
+		continuityScores: []float64{20, 21, 22, 23},
+		spreadProxies:    []float64{30, 31, 32, 33},
+		activity:         []PenaltyInput{{TotalDays: 40}, {TotalDays: 41}, {TotalDays: 42}, {TotalDays: 43}},
+		tickerIndex:      []string{"A", "B", "C", "D"},
+		dateIndex:        make([]time.Time, 4),
+	}
+	d.shuffle(rand.New(rand.NewSource(3)))
+
+	for i, impact := range d.impactScores {
+		assert.Equal(t, impact+10, d.volumeScores[i])
+		assert.Equal(t, impact+20, d.continuityScores[i])
+		assert.Equal(t, impact+30, d.spreadProxies[i])
+		assert.Equal(t, int(impact)+40, d.activity[i].TotalDays)
+		assert.Equal(t, string(rune('A'+int(impact))), d.tickerIndex[i])
+	}
+}
@@ -27,6 +27,10 @@
 //   - weights.go: Component weight estimation and optimization
 //   - calibration.go: Parameter calibration using grid search
 //   - persist.go: Output formatting and persistence
+//   - history.go: Per-window metric history persisted across runs
+//   - incremental.go: Recalculation of new trading days from the previous run's state
+//   - trend.go: Improving/deteriorating trend detection over recent windows
+//   - progress.go: Per-phase progress events with ETA for long calculations
 //   - validate.go: Comprehensive input and output validation
 //
 // # Usage Example
@@ -70,6 +74,7 @@
 //	config := DefaultCalibrationConfig()
 //	config.TargetMetric = "combined"
 //	config.KFolds = 5
+//	config.RandomSeed = 42 // Fixes the fold split; result.RandomSeed records it
 //	
 //	// Perform calibration
 //	result, err := Calibrate(ctx, data, config)
@@ -154,7 +159,9 @@
 // # Extensions and Customization
 //
 // The package is designed for extensibility:
-//   - Custom penalty functions can be implemented
+//   - Custom penalty functions implement PenaltyFunc and register with
+//     RegisterPenaltyFunc; calculators and calibrations select them by name
+//     ("unified", "piecewise" and "exponential" are built in)
 //   - Alternative scaling methods are supported
 //   - Component weights can be dynamically adjusted
 //   - New data sources can be easily integrated
@@ -0,0 +1,310 @@
+package liquidity
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryFileName is the metric history file kept under <reports>/liquidity/history
+const HistoryFileName = "liquidity_history.csv"
+
+// historyHeader is the column layout of the metric history file
+var historyHeader = []string{
+	"Date",
+	"Symbol",
+	"Window",
+	"Hybrid_Score",
+	"Hybrid_Rank",
+	"ILLIQ_Raw",
+	"Value_Raw",
+	"Continuity_Raw",
+	"Spread_Proxy",
+	"Trading_Days",
+	"Total_Days",
+	"Calculated_At",
+	"Percentile_Rank",
+	"Quintile",
+	"Decile",
+}
+
+// historyBaseColumns are the columns written before percentile ranks and
+// tiers were added; older history files end there
+const historyBaseColumns = 12
+
+// HistoryPoint is one persisted per-window liquidity observation for a ticker
+type HistoryPoint struct {
+	Date         time.Time `json:"date"`
+	Symbol       string    `json:"symbol"`
+	Window       Window    `json:"window"`
+	HybridScore  float64   `json:"hybridScore"`
+	HybridRank   int       `json:"hybridRank"`
+	ILLIQ        float64   `json:"illiq"`
+	Value        float64   `json:"value"`
+	Continuity   float64   `json:"continuity"`
+	SpreadProxy  float64   `json:"spreadProxy"`
+	TradingDays  int       `json:"tradingDays"`
+	TotalDays    int       `json:"totalDays"`
+	CalculatedAt time.Time `json:"calculatedAt"`
+	// Percentile rank and tiers within the date's cross-section; zero in
+	// history written before they were recorded
+	PercentileRank float64 `json:"percentileRank"`
+	Quintile       int     `json:"quintile"`
+	Decile         int     `json:"decile"`
+}
+
+// HistoryPath returns the metric history file location for a reports directory
+func HistoryPath(reportsDir string) string {
+	return filepath.Join(reportsDir, "liquidity", "history", HistoryFileName)
+}
+
+// ParseWindow parses a window given as "60d" or "60"
+func ParseWindow(s string) (Window, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "d"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q", s)
+	}
+	switch w := Window(days); w {
+	case Window20, Window60, Window120:
+		return w, nil
+	default:
+		return 0, fmt.Errorf("unsupported window %q (use 20d, 60d or 120d)", s)
+	}
+}
+
+// AppendHistory merges metrics into the history file at path. Points are keyed
+// by symbol, window and date; a recalculation replaces the earlier value and
+// records calculatedAt, so reruns over the same data do not grow the file.
+func AppendHistory(metrics []TickerMetrics, path string, calculatedAt time.Time) error {
+	if len(metrics) == 0 {
+		return fmt.Errorf("no metrics to append")
+	}
+
+	existing, err := readHistory(path)
+	if err != nil {
+		return err
+	}
+
+	type historyKey struct {
+		symbol string
+		window Window
+		date   string
+	}
+	merged := make(map[historyKey]HistoryPoint, len(existing)+len(metrics))
+	for _, p := range existing {
+		merged[historyKey{p.Symbol, p.Window, p.Date.Format("2006-01-02")}] = p
+	}
+	for _, m := range metrics {
+		p := HistoryPoint{
+			Date:         m.Date,
+			Symbol:       m.Symbol,
+			Window:       m.Window,
+			HybridScore:  m.HybridScore,
+			HybridRank:   m.HybridRank,
+			ILLIQ:        m.ILLIQ,
+			Value:        m.Value,
+			Continuity:   m.Continuity,
+			SpreadProxy:  m.SpreadProxy,
+			TradingDays:  m.TradingDays,
+			TotalDays:    m.TotalDays,
+			CalculatedAt: calculatedAt.UTC(),
+
+			PercentileRank: m.PercentileRank,
+			Quintile:       m.Quintile,
+			Decile:         m.Decile,
+		}
+		merged[historyKey{p.Symbol, p.Window, p.Date.Format("2006-01-02")}] = p
+	}
+
+	points := make([]HistoryPoint, 0, len(merged))
+	for _, p := range merged {
+		points = append(points, p)
+	}
+	sortHistory(points)
+
+	return writeHistory(path, points)
+}
+
+// LoadHistory returns the history of one symbol ordered by window then date.
+// A zero window returns every window. A missing history file yields no points.
+func LoadHistory(path, symbol string, window Window) ([]HistoryPoint, error) {
+	all, err := readHistory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []HistoryPoint
+	for _, p := range all {
+		if !strings.EqualFold(p.Symbol, symbol) {
+			continue
+		}
+		if window != 0 && p.Window != window {
+			continue
+		}
+		points = append(points, p)
+	}
+	sortHistory(points)
+	return points, nil
+}
+
+func sortHistory(points []HistoryPoint) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Symbol != points[j].Symbol {
+			return points[i].Symbol < points[j].Symbol
+		}
+		if points[i].Window != points[j].Window {
+			return points[i].Window < points[j].Window
+		}
+		return points[i].Date.Before(points[j].Date)
+	})
+}
+
+func readHistory(path string) ([]HistoryPoint, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open history file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	if _, err := reader.Read(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history header: %w", err)
+	}
+
+	var points []HistoryPoint
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read history line %d: %w", line, err)
+		}
+		p, err := parseHistoryRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("parse history line %d: %w", line, err)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func parseHistoryRecord(record []string) (HistoryPoint, error) {
+	if len(record) < historyBaseColumns {
+		return HistoryPoint{}, fmt.Errorf("expected at least %d columns, got %d", historyBaseColumns, len(record))
+	}
+
+	var p HistoryPoint
+	var err error
+	if p.Date, err = time.Parse("2006-01-02", record[0]); err != nil {
+		return p, fmt.Errorf("date: %w", err)
+	}
+	p.Symbol = record[1]
+	if p.Window, err = ParseWindow(record[2]); err != nil {
+		return p, err
+	}
+
+	floats := []*float64{&p.HybridScore, nil, &p.ILLIQ, &p.Value, &p.Continuity, &p.SpreadProxy}
+	for i, dst := range floats {
+		if dst == nil {
+			continue
+		}
+		if *dst, err = strconv.ParseFloat(record[3+i], 64); err != nil {
+			return p, fmt.Errorf("%s: %w", historyHeader[3+i], err)
+		}
+	}
+	if p.HybridRank, err = strconv.Atoi(record[4]); err != nil {
+		return p, fmt.Errorf("Hybrid_Rank: %w", err)
+	}
+	if p.TradingDays, err = strconv.Atoi(record[9]); err != nil {
+		return p, fmt.Errorf("Trading_Days: %w", err)
+	}
+	if p.TotalDays, err = strconv.Atoi(record[10]); err != nil {
+		return p, fmt.Errorf("Total_Days: %w", err)
+	}
+	if p.CalculatedAt, err = time.Parse(time.RFC3339, record[11]); err != nil {
+		return p, fmt.Errorf("Calculated_At: %w", err)
+	}
+	if len(record) < len(historyHeader) {
+		return p, nil
+	}
+	if p.PercentileRank, err = strconv.ParseFloat(record[12], 64); err != nil {
+		return p, fmt.Errorf("Percentile_Rank: %w", err)
+	}
+	if p.Quintile, err = strconv.Atoi(record[13]); err != nil {
+		return p, fmt.Errorf("Quintile: %w", err)
+	}
+	if p.Decile, err = strconv.Atoi(record[14]); err != nil {
+		return p, fmt.Errorf("Decile: %w", err)
+	}
+	return p, nil
+}
+
+// writeHistory rewrites the history file through a temp file so a failed run
+// never leaves a truncated history behind
+func writeHistory(path string, points []HistoryPoint) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), HistoryFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := csv.NewWriter(tmp)
+	if err := writer.Write(historyHeader); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write history header: %w", err)
+	}
+	for _, p := range points {
+		record := []string{
+			p.Date.Format("2006-01-02"),
+			p.Symbol,
+			p.Window.String(),
+			formatFloat(p.HybridScore, 4),
+			strconv.Itoa(p.HybridRank),
+			formatFloat(p.ILLIQ, 8),
+			formatFloat(p.Value, 0),
+			formatFloat(p.Continuity, 4),
+			formatFloat(p.SpreadProxy, 6),
+			strconv.Itoa(p.TradingDays),
+			strconv.Itoa(p.TotalDays),
+			p.CalculatedAt.UTC().Format(time.RFC3339),
+			formatFloat(p.PercentileRank, 2),
+			strconv.Itoa(p.Quintile),
+			strconv.Itoa(p.Decile),
+		}
+		if err := writer.Write(record); err != nil {
+			tmp.Close()
+			return fmt.Errorf("write history record for %s: %w", p.Symbol, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("flush history file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp history file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace history file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package liquidity
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// stateVersion is bumped whenever State or the calculation changes in a way
+// that makes earlier states unusable; an older state forces a full run
+const stateVersion = 1
+
+// StatePath returns the incremental state file of a window under a reports
+// directory
+func StatePath(reportsDir string, window Window) string {
+	return filepath.Join(reportsDir, "liquidity", "state", fmt.Sprintf("liquidity_state_%s.gob.gz", window))
+}
+
+// State is what a calculation hands to the next incremental run: every metric
+// it produced and a digest of the trading data each ticker was calculated from
+type State struct {
+	Version      int
+	Window       Window
+	Settings     string // Digest of the calculator's window, penalty, weights and bounds
+	CalculatedAt time.Time
+	Tickers      map[string]TickerState
+	Metrics      []TickerMetrics
+}
+
+// TickerState records the trading data a ticker's metrics were calculated from
+type TickerState struct {
+	LastDate time.Time
+	Rows     int
+	Digest   string // SHA-256 of the rows up to and including LastDate
+}
+
+// IncrementalStats describes how much of a calculation was reused
+type IncrementalStats struct {
+	Full          bool   `json:"full"`
+	Reason        string `json:"reason,omitempty"` // Why every ticker was calculated from scratch
+	Reused        int    `json:"reused"`           // Tickers without new data, kept as they were
+	Extended      int    `json:"extended"`         // Tickers with only their new dates calculated
+	Recalculated  int    `json:"recalculated"`     // New, revised or short tickers calculated in full
+	Removed       int    `json:"removed"`          // Tickers no longer in the trading data
+	DatesRescaled int    `json:"dates_rescaled"`   // Dates whose cross-section was scaled and ranked again
+}
+
+// CalculateIncremental computes the same metrics as Calculate, starting from
+// the state of a previous run. A ticker whose earlier rows are unchanged only
+// has the windows ending on its new dates calculated; new tickers, tickers
+// whose history was revised and tickers still shorter than the window are
+// calculated in full. Cross-sectional scaling and ranking are applied again
+// only on dates where a ticker's metrics changed.
+//
+// A nil prior, or one calculated with another window or other settings, falls
+// back to a full calculation. The returned state is meant for the next run.
+func (c *Calculator) CalculateIncremental(ctx context.Context, data []TradingDay, prior *State) ([]TickerMetrics, *State, IncrementalStats, error) {
+	settings := c.settingsDigest()
+
+	reason := ""
+	switch {
+	case prior == nil:
+		reason = "no previous state"
+	case prior.Version != stateVersion:
+		reason = "state version changed"
+	case prior.Window != c.window:
+		reason = "window changed"
+	case prior.Settings != settings:
+		reason = "calculator settings changed"
+	}
+	if reason != "" {
+		metrics, err := c.Calculate(ctx, data)
+		if err != nil {
+			return nil, nil, IncrementalStats{}, err
+		}
+		state := c.newState(settings, c.groupByTicker(data), metrics)
+		stats := IncrementalStats{
+			Full:          true,
+			Reason:        reason,
+			Recalculated:  len(state.Tickers),
+			DatesRescaled: countDates(metrics),
+		}
+		return metrics, state, stats, nil
+	}
+
+	start := time.Now()
+	c.logger.InfoContext(ctx, "starting incremental liquidity calculation",
+		"window", c.window.String(),
+		"data_points", len(data),
+		"previous_tickers", len(prior.Tickers),
+	)
+
+	calcCtx, cancel := context.WithTimeout(ctx, c.calculationTimeout)
+	defer cancel()
+
+	loading := c.startPhase(PhaseLoading, len(data), "rows")
+	if err := c.validateInputs(data); err != nil {
+		c.logger.ErrorContext(ctx, "input validation failed", "error", err)
+		return nil, nil, IncrementalStats{}, fmt.Errorf("validate inputs: %w", err)
+	}
+	tickerData := c.groupByTicker(data)
+	loading.step(len(data), "")
+
+	priorMetrics := make(map[string][]TickerMetrics, len(prior.Tickers))
+	for _, m := range prior.Metrics {
+		priorMetrics[m.Symbol] = append(priorMetrics[m.Symbol], m)
+	}
+
+	windowSize := c.window.Days()
+	if windowSize <= 0 {
+		windowSize = Window60.Days()
+	}
+
+	var stats IncrementalStats
+	affected := make(map[time.Time]bool)
+	markAffected := func(metrics []TickerMetrics) {
+		for _, m := range metrics {
+			affected[m.Date] = true
+		}
+	}
+
+	var kept []TickerMetrics
+	pending := make(map[string][]TradingDay)
+	from := make(map[string]int)
+	for symbol, rows := range tickerData {
+		ts, seen := prior.Tickers[symbol]
+		n := rowsThrough(rows, ts.LastDate)
+		unchanged := seen && n == ts.Rows && digestRows(rows[:n]) == ts.Digest
+		switch {
+		case unchanged && n == len(rows):
+			stats.Reused++
+			kept = append(kept, priorMetrics[symbol]...)
+		case unchanged && n >= windowSize:
+			stats.Extended++
+			kept = append(kept, priorMetrics[symbol]...)
+			pending[symbol] = rows
+			from[symbol] = n
+		default:
+			stats.Recalculated++
+			markAffected(priorMetrics[symbol])
+			pending[symbol] = rows
+		}
+	}
+	for symbol := range prior.Tickers {
+		if _, ok := tickerData[symbol]; !ok {
+			stats.Removed++
+			markAffected(priorMetrics[symbol])
+		}
+	}
+
+	computed, err := c.calculateTickers(calcCtx, pending, from)
+	if err != nil {
+		return nil, nil, IncrementalStats{}, err
+	}
+	markAffected(computed)
+
+	metrics := append(kept, computed...)
+	if len(metrics) == 0 {
+		return nil, nil, IncrementalStats{}, fmt.Errorf("no valid metrics calculated from %d tickers", len(tickerData))
+	}
+	sortMetrics(metrics)
+
+	// Only dates whose cross-section changed are scaled again, from the raw
+	// components as a full run would see them
+	var unaffected, rescale []TickerMetrics
+	for _, m := range metrics {
+		if affected[m.Date] {
+			rescale = append(rescale, rawComponents(m))
+		} else {
+			unaffected = append(unaffected, m)
+		}
+	}
+	if len(rescale) > 0 {
+		if err := c.applyCrossSection(calcCtx, rescale); err != nil {
+			c.logger.ErrorContext(ctx, "cross-sectional scaling failed", "error", err)
+			return nil, nil, IncrementalStats{}, fmt.Errorf("apply cross-sectional scaling: %w", err)
+		}
+	}
+	stats.DatesRescaled = countDates(rescale)
+
+	metrics = append(unaffected, rescale...)
+	sortMetrics(metrics)
+
+	c.logger.InfoContext(ctx, "incremental liquidity calculation completed",
+		"duration", time.Since(start),
+		"total_metrics", len(metrics),
+		"tickers_reused", stats.Reused,
+		"tickers_extended", stats.Extended,
+		"tickers_recalculated", stats.Recalculated,
+		"dates_rescaled", stats.DatesRescaled,
+	)
+
+	return metrics, c.newState(settings, tickerData, metrics), stats, nil
+}
+
+// newState records metrics and the trading data they were calculated from
+func (c *Calculator) newState(settings string, tickerData map[string][]TradingDay, metrics []TickerMetrics) *State {
+	tickers := make(map[string]TickerState, len(tickerData))
+	for symbol, rows := range tickerData {
+		tickers[symbol] = TickerState{
+			LastDate: rows[len(rows)-1].Date,
+			Rows:     len(rows),
+			Digest:   digestRows(rows),
+		}
+	}
+	return &State{
+		Version:      stateVersion,
+		Window:       c.window,
+		Settings:     settings,
+		CalculatedAt: time.Now().UTC(),
+		Tickers:      tickers,
+		Metrics:      metrics,
+	}
+}
+
+// settingsDigest identifies the settings that change metrics for the same
+// data, so a state is never reused under different ones
+func (c *Calculator) settingsDigest() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|%+v|%+v|%+v|%t",
+		c.window, c.penalty.Name(), c.penaltyParams, c.weights, c.winsorizationBounds, c.useSMA)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// digestRows hashes the fields of rows the metrics are calculated from
+func digestRows(rows []TradingDay) string {
+	h := sha256.New()
+	for _, r := range rows {
+		fmt.Fprintf(h, "%s|%g|%g|%g|%g|%g|%g|%g|%d|%s\n",
+			r.Date.Format("2006-01-02"), r.Open, r.High, r.Low, r.Close,
+			r.Volume, r.ShareVolume, r.Value, r.NumTrades, r.TradingStatus)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// rowsThrough counts the date-sorted rows on or before last
+func rowsThrough(rows []TradingDay, last time.Time) int {
+	return sort.Search(len(rows), func(i int) bool {
+		return rows[i].Date.After(last)
+	})
+}
+
+// rawComponents clears what cross-sectional scaling derives from a metric
+func rawComponents(m TickerMetrics) TickerMetrics {
+	m.ILLIQScaled = 0
+	m.ValueScaled = 0
+	m.ContinuityScaled = 0
+	m.SpreadScaled = 0
+	m.HybridScore = 0
+	m.HybridRank = 0
+	m.PercentileRank = 0
+	m.Quintile = 0
+	m.Decile = 0
+	m.SafeValue_0_5 = 0
+	m.SafeValue_1_0 = 0
+	m.SafeValue_2_0 = 0
+	m.OptimalTradeSize = 0
+	return m
+}
+
+// sortMetrics orders metrics by symbol then date, the order of Calculate
+func sortMetrics(metrics []TickerMetrics) {
+	sort.SliceStable(metrics, func(i, j int) bool {
+		if metrics[i].Symbol != metrics[j].Symbol {
+			return metrics[i].Symbol < metrics[j].Symbol
+		}
+		return metrics[i].Date.Before(metrics[j].Date)
+	})
+}
+
+func countDates(metrics []TickerMetrics) int {
+	dates := make(map[time.Time]bool)
+	for _, m := range metrics {
+		dates[m.Date] = true
+	}
+	return len(dates)
+}
+
+// LoadState reads a state written by SaveState. A missing file yields a nil
+// state, so the first run calculates in full.
+func LoadState(path string) (*State, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open liquidity state: %w", err)
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("read liquidity state: %w", err)
+	}
+	defer zr.Close()
+
+	var state State
+	if err := gob.NewDecoder(zr).Decode(&state); err != nil {
+		return nil, fmt.Errorf("decode liquidity state: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveState writes state through a temp file, so an interrupted run leaves
+// the previous state in place
+func SaveState(path string, state *State) error {
+	if state == nil {
+		return fmt.Errorf("no liquidity state to save")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := gob.NewEncoder(zw).Encode(state); err != nil {
+		tmp.Close()
+		return fmt.Errorf("encode liquidity state: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("compress liquidity state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace liquidity state: %w", err)
+	}
+	return nil
+}
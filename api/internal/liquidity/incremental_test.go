@@ -0,0 +1,116 @@
+package liquidity
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateIncremental(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := generateMultiSymbolBenchmarkData(marketSymbols(12), 120, start)
+	cutoff := start.AddDate(0, 0, 110)
+
+	before := func(rows []TradingDay, date time.Time) []TradingDay {
+		var out []TradingDay
+		for _, r := range rows {
+			if r.Date.Before(date) {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+	calc := func() *Calculator {
+		return NewCalculator(Window20, DefaultPenaltyParams(), DefaultWeights(), logger)
+	}
+
+	_, prior, stats, err := calc().CalculateIncremental(context.Background(), before(data, cutoff), nil)
+	require.NoError(t, err)
+	assert.True(t, stats.Full)
+	assert.Equal(t, 12, stats.Recalculated)
+
+	path := StatePath(t.TempDir(), Window20)
+	require.NoError(t, SaveState(path, prior))
+	prior, err = LoadState(path)
+	require.NoError(t, err)
+
+	t.Run("new days", func(t *testing.T) {
+		want, err := calc().Calculate(context.Background(), data)
+		require.NoError(t, err)
+
+		got, next, stats, err := calc().CalculateIncremental(context.Background(), data, prior)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "matches a full calculation")
+		assert.False(t, stats.Full)
+		assert.Equal(t, 12, stats.Extended)
+		assert.Zero(t, stats.Recalculated)
+		assert.Equal(t, 6, stats.DatesRescaled, "only the new trading days")
+
+		_, _, stats, err = calc().CalculateIncremental(context.Background(), data, next)
+		require.NoError(t, err)
+		assert.Equal(t, 12, stats.Reused)
+		assert.Zero(t, stats.DatesRescaled)
+	})
+
+	t.Run("revised history", func(t *testing.T) {
+		revised := append([]TradingDay(nil), data...)
+		symbol := revised[0].Symbol
+		revised[0].Value *= 3
+		want, err := calc().Calculate(context.Background(), revised)
+		require.NoError(t, err)
+
+		got, _, stats, err := calc().CalculateIncremental(context.Background(), revised, prior)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 1, stats.Recalculated, "only %s is calculated in full", symbol)
+		assert.Equal(t, 11, stats.Extended)
+	})
+
+	t.Run("removed ticker", func(t *testing.T) {
+		removed := withoutSymbol(data, data[0].Symbol)
+		want, err := calc().Calculate(context.Background(), removed)
+		require.NoError(t, err)
+
+		got, _, stats, err := calc().CalculateIncremental(context.Background(), removed, prior)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 1, stats.Removed)
+	})
+
+	t.Run("settings changed", func(t *testing.T) {
+		other := calc()
+		require.NoError(t, other.SetWinsorizationBounds(WinsorizationBounds{Lower: 0.1, Upper: 0.9}))
+		_, _, stats, err := other.CalculateIncremental(context.Background(), data, prior)
+		require.NoError(t, err)
+		assert.True(t, stats.Full)
+		assert.Equal(t, "calculator settings changed", stats.Reason)
+
+		_, _, stats, err = NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), logger).
+			CalculateIncremental(context.Background(), data, prior)
+		require.NoError(t, err)
+		assert.Equal(t, "window changed", stats.Reason)
+	})
+}
+
+func TestLoadStateMissing(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "missing.gob.gz"))
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func withoutSymbol(data []TradingDay, symbol string) []TradingDay {
+	var out []TradingDay
+	for _, r := range data {
+		if r.Symbol != symbol {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package liquidity
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultWindows is the window set of a multi-window run
+var DefaultWindows = []Window{Window20, Window60, Window120}
+
+// ParseWindows parses a comma-separated window set such as "20,60,120" or
+// "20d,120d". The result is de-duplicated and ordered from short to long.
+func ParseWindows(s string) ([]Window, error) {
+	seen := make(map[Window]bool)
+	var windows []Window
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		w, err := ParseWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[w] {
+			seen[w] = true
+			windows = append(windows, w)
+		}
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no windows in %q", s)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	return windows, nil
+}
+
+// CalculateWindows calculates the metrics of every window over the same
+// trading data, sharing the calculator's parameters, weights and penalty
+func (c *Calculator) CalculateWindows(ctx context.Context, data []TradingDay, windows []Window) (map[Window][]TickerMetrics, error) {
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no windows to calculate")
+	}
+
+	results := make(map[Window][]TickerMetrics, len(windows))
+	for _, w := range windows {
+		calc := *c
+		calc.window = w
+		metrics, err := calc.Calculate(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("window %s: %w", w, err)
+		}
+		results[w] = metrics
+	}
+	return results, nil
+}
+
+// MultiWindowMetrics is a ticker's latest hybrid score in each window of a
+// multi-window run and the blend of those scores
+type MultiWindowMetrics struct {
+	Symbol       string             `json:"symbol"`
+	Date         time.Time          `json:"date"` // Latest date scored in any window
+	Scores       map[Window]float64 `json:"scores"`
+	Ranks        map[Window]int     `json:"ranks"`
+	BlendedScore float64            `json:"blended_score"`
+	BlendedRank  int                `json:"blended_rank"`
+}
+
+// BlendWindows joins the latest metrics of each ticker across windows. The
+// blended score is the equal-weight mean of the window scores; a window
+// without a score for the ticker counts as zero, like the worst-case score
+// of tickers with too little history. Rows are ordered by blended rank.
+func BlendWindows(results map[Window][]TickerMetrics, windows []Window) []MultiWindowMetrics {
+	bySymbol := make(map[string]*MultiWindowMetrics)
+	for _, w := range windows {
+		for _, m := range latestPerSymbol(results[w]) {
+			row, ok := bySymbol[m.Symbol]
+			if !ok {
+				row = &MultiWindowMetrics{
+					Symbol: m.Symbol,
+					Scores: make(map[Window]float64, len(windows)),
+					Ranks:  make(map[Window]int, len(windows)),
+				}
+				bySymbol[m.Symbol] = row
+			}
+			row.Scores[w] = m.HybridScore
+			row.Ranks[w] = m.HybridRank
+			if m.Date.After(row.Date) {
+				row.Date = m.Date
+			}
+		}
+	}
+
+	rows := make([]MultiWindowMetrics, 0, len(bySymbol))
+	for _, row := range bySymbol {
+		total := 0.0
+		for _, w := range windows {
+			total += row.Scores[w]
+		}
+		if len(windows) > 0 {
+			row.BlendedScore = total / float64(len(windows))
+		}
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].BlendedScore != rows[j].BlendedScore {
+			return rows[i].BlendedScore > rows[j].BlendedScore
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+	for i := range rows {
+		rows[i].BlendedRank = i + 1
+	}
+	return rows
+}
+
+// latestPerSymbol keeps the metric of each symbol's most recent date
+func latestPerSymbol(metrics []TickerMetrics) []TickerMetrics {
+	latest := make(map[string]TickerMetrics)
+	for _, m := range metrics {
+		if prev, ok := latest[m.Symbol]; !ok || m.Date.After(prev.Date) {
+			latest[m.Symbol] = m
+		}
+	}
+	out := make([]TickerMetrics, 0, len(latest))
+	for _, m := range latest {
+		out = append(out, m)
+	}
+	return out
+}
+
+// SaveMultiWindowCSV writes the wide report of a multi-window run: one row
+// per ticker with a score and rank column per window and the blended score
+func SaveMultiWindowCSV(rows []MultiWindowMetrics, windows []Window, outputPath string) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("no metrics to save")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	header := []string{"Date", "Symbol"}
+	for _, w := range windows {
+		header = append(header, "Score_"+w.String(), "Rank_"+w.String())
+	}
+	header = append(header, "Blended_Score", "Blended_Rank")
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write CSV header: %w", err)
+	}
+
+	for _, row := range rows {
+		record := []string{row.Date.Format("2006-01-02"), row.Symbol}
+		for _, w := range windows {
+			if _, ok := row.Scores[w]; !ok {
+				record = append(record, "", "")
+				continue
+			}
+			record = append(record, formatFloat(row.Scores[w], 4), strconv.Itoa(row.Ranks[w]))
+		}
+		record = append(record, formatFloat(row.BlendedScore, 4), strconv.Itoa(row.BlendedRank))
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("write CSV record for %s: %w", row.Symbol, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flush CSV writer: %w", err)
+	}
+	return nil
+}
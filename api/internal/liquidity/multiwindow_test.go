@@ -0,0 +1,93 @@
+package liquidity
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWindows(t *testing.T) {
+	windows, err := ParseWindows("120, 20d,60,20")
+	require.NoError(t, err)
+	assert.Equal(t, []Window{Window20, Window60, Window120}, windows)
+
+	windows, err = ParseWindows("60")
+	require.NoError(t, err)
+	assert.Equal(t, []Window{Window60}, windows)
+
+	_, err = ParseWindows("20,45")
+	assert.Error(t, err)
+	_, err = ParseWindows(" , ")
+	assert.Error(t, err)
+}
+
+func TestCalculateWindows(t *testing.T) {
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	data := generateMultiSymbolBenchmarkData(marketSymbols(5), 200, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	results, err := calc.CalculateWindows(context.Background(), data, []Window{Window20, Window120})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	for _, w := range []Window{Window20, Window120} {
+		require.NotEmpty(t, results[w], "window %s", w)
+		for _, m := range results[w] {
+			assert.Equal(t, w, m.Window)
+			assert.Equal(t, w.Days(), m.TotalDays, "the rolling window follows the window size")
+		}
+	}
+	assert.Greater(t, len(results[Window20]), len(results[Window120]), "shorter windows start scoring earlier")
+	assert.Equal(t, Window60, calc.window, "the calculator keeps its own window")
+}
+
+func TestBlendWindows(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	results := map[Window][]TickerMetrics{
+		Window20: {
+			{Symbol: "BBOB", Date: day1, HybridScore: 10, HybridRank: 2},
+			{Symbol: "BBOB", Date: day2, HybridScore: 90, HybridRank: 1},
+			{Symbol: "TASC", Date: day2, HybridScore: 60, HybridRank: 2},
+		},
+		Window60: {
+			{Symbol: "BBOB", Date: day2, HybridScore: 30, HybridRank: 2},
+			{Symbol: "TASC", Date: day2, HybridScore: 80, HybridRank: 1},
+			{Symbol: "IMAP", Date: day1, HybridScore: 40, HybridRank: 3},
+		},
+	}
+
+	rows := BlendWindows(results, []Window{Window20, Window60})
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, "TASC", rows[0].Symbol)
+	assert.InDelta(t, 70, rows[0].BlendedScore, 1e-9)
+	assert.Equal(t, 1, rows[0].BlendedRank)
+
+	assert.Equal(t, "BBOB", rows[1].Symbol)
+	assert.InDelta(t, 60, rows[1].BlendedScore, 1e-9, "only the latest date of each window counts")
+	assert.Equal(t, map[Window]int{Window20: 1, Window60: 2}, rows[1].Ranks)
+
+	assert.Equal(t, "IMAP", rows[2].Symbol)
+	assert.InDelta(t, 20, rows[2].BlendedScore, 1e-9, "a missing window counts as zero")
+	assert.Equal(t, day1, rows[2].Date)
+
+	path := filepath.Join(t.TempDir(), "windows.csv")
+	require.NoError(t, SaveMultiWindowCSV(rows, []Window{Window20, Window60}, path))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"Date", "Symbol", "Score_20d", "Rank_20d", "Score_60d", "Rank_60d", "Blended_Score", "Blended_Rank"}, records[0])
+	assert.Equal(t, []string{"2025-03-01", "IMAP", "", "", "40.0000", "3", "20.0000", "3"}, records[3])
+}
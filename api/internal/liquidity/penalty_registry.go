@@ -0,0 +1,130 @@
+package liquidity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultPenaltyFunc is the penalty the calculator applies unless told otherwise
+const DefaultPenaltyFunc = "unified"
+
+// PenaltyInput describes a ticker's trading activity over a window
+type PenaltyInput struct {
+	TradingDays int
+	TotalDays   int
+}
+
+// Inactivity returns the share of days without trading, from 0 to 1
+func (in PenaltyInput) Inactivity() float64 {
+	if in.TotalDays <= 0 || in.TradingDays <= 0 {
+		return 1
+	}
+	if in.TradingDays >= in.TotalDays {
+		return 0
+	}
+	return 1 - float64(in.TradingDays)/float64(in.TotalDays)
+}
+
+// PenaltyFunc turns a ticker's trading activity into a penalty multiplier
+// (>= 1) that divides its impact and value scores. Implementations register
+// themselves from init() with RegisterPenaltyFunc and are selected by name,
+// which calibration results record.
+type PenaltyFunc interface {
+	// Name identifies the penalty in flags and calibration results
+	Name() string
+
+	// Penalty returns the multiplier for in, reading whichever PenaltyParams
+	// fields the implementation uses
+	Penalty(in PenaltyInput, params PenaltyParams) float64
+}
+
+var (
+	penaltyRegistryMu sync.RWMutex
+	penaltyRegistry   = map[string]PenaltyFunc{}
+)
+
+// RegisterPenaltyFunc adds a penalty function. It panics if the name is
+// already taken, mirroring database/sql driver registration.
+func RegisterPenaltyFunc(f PenaltyFunc) {
+	penaltyRegistryMu.Lock()
+	defer penaltyRegistryMu.Unlock()
+
+	if f == nil {
+		panic("liquidity: RegisterPenaltyFunc penalty is nil")
+	}
+	if _, dup := penaltyRegistry[f.Name()]; dup {
+		panic("liquidity: RegisterPenaltyFunc called twice for penalty " + f.Name())
+	}
+	penaltyRegistry[f.Name()] = f
+}
+
+// LookupPenaltyFunc returns the penalty function registered under name; an
+// empty name selects DefaultPenaltyFunc
+func LookupPenaltyFunc(name string) (PenaltyFunc, error) {
+	if name == "" {
+		name = DefaultPenaltyFunc
+	}
+	penaltyRegistryMu.RLock()
+	f, ok := penaltyRegistry[name]
+	penaltyRegistryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown penalty function %q (available: %s)", name, strings.Join(PenaltyFuncNames(), ", "))
+	}
+	return f, nil
+}
+
+// defaultPenaltyFunc returns DefaultPenaltyFunc, which init always registers
+func defaultPenaltyFunc() PenaltyFunc {
+	f, _ := LookupPenaltyFunc(DefaultPenaltyFunc)
+	return f
+}
+
+// PenaltyFuncNames returns the registered penalty names in order
+func PenaltyFuncNames() []string {
+	penaltyRegistryMu.RLock()
+	defer penaltyRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(penaltyRegistry))
+	for name := range penaltyRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterPenaltyFunc(unifiedPenalty{})
+	RegisterPenaltyFunc(piecewisePenalty{})
+	RegisterPenaltyFunc(exponentialPenalty{})
+}
+
+// unifiedPenalty decays from PiecewiseMaxMult with the ticker's ActivityScore
+type unifiedPenalty struct{}
+
+func (unifiedPenalty) Name() string { return "unified" }
+
+func (unifiedPenalty) Penalty(in PenaltyInput, params PenaltyParams) float64 {
+	return UnifiedPenalty(ActivityScore(in.TradingDays, in.TotalDays), params.PiecewiseMaxMult)
+}
+
+// piecewisePenalty is PiecewisePenalty over the inactivity ratio.
+// PiecewisePStar must lie between 0 and 1 for it to take effect.
+type piecewisePenalty struct{}
+
+func (piecewisePenalty) Name() string { return "piecewise" }
+
+func (piecewisePenalty) Penalty(in PenaltyInput, params PenaltyParams) float64 {
+	return PiecewisePenalty(in.Inactivity(), params.PiecewiseBeta, params.PiecewiseGamma,
+		params.PiecewisePStar, params.PiecewiseMaxMult)
+}
+
+// exponentialPenalty is ExponentialPenalty over the inactivity ratio
+type exponentialPenalty struct{}
+
+func (exponentialPenalty) Name() string { return "exponential" }
+
+func (exponentialPenalty) Penalty(in PenaltyInput, params PenaltyParams) float64 {
+	return ExponentialPenalty(in.Inactivity(), params.ExponentialAlpha, params.ExponentialMaxMult)
+}
@@ -0,0 +1,85 @@
+package liquidity
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flatPenalty charges every ticker the same multiplier
+type flatPenalty struct{ name string }
+
+func (f flatPenalty) Name() string                                { return f.name }
+func (f flatPenalty) Penalty(PenaltyInput, PenaltyParams) float64 { return 2 }
+
+func TestPenaltyRegistryBuiltins(t *testing.T) {
+	assert.Subset(t, PenaltyFuncNames(), []string{"exponential", "piecewise", "unified"})
+
+	def, err := LookupPenaltyFunc("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPenaltyFunc, def.Name())
+
+	_, err = LookupPenaltyFunc("quadratic")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "piecewise")
+}
+
+func TestBuiltinPenalties(t *testing.T) {
+	params := DefaultPenaltyParams()
+	params.PiecewisePStar = 0.5
+	half := PenaltyInput{TradingDays: 30, TotalDays: 60}
+
+	unified, _ := LookupPenaltyFunc("unified")
+	assert.Equal(t, UnifiedPenalty(ActivityScore(30, 60), params.PiecewiseMaxMult), unified.Penalty(half, params))
+
+	piecewise, _ := LookupPenaltyFunc("piecewise")
+	assert.InDelta(t, 1+params.PiecewiseBeta, piecewise.Penalty(half, params), 1e-9)
+	assert.Equal(t, 1.0, piecewise.Penalty(PenaltyInput{TradingDays: 60, TotalDays: 60}, params))
+
+	exponential, _ := LookupPenaltyFunc("exponential")
+	assert.Equal(t, ExponentialPenalty(0.5, params.ExponentialAlpha, params.ExponentialMaxMult), exponential.Penalty(half, params))
+	assert.Equal(t, ExponentialPenalty(1, params.ExponentialAlpha, params.ExponentialMaxMult), exponential.Penalty(PenaltyInput{TotalDays: 60}, params), "no trading is full inactivity")
+}
+
+func TestRegisterPenaltyFuncDuplicate(t *testing.T) {
+	assert.Panics(t, func() { RegisterPenaltyFunc(flatPenalty{name: "unified"}) })
+	assert.Panics(t, func() { RegisterPenaltyFunc(nil) })
+}
+
+func TestCalculatorUsesPenaltyFunc(t *testing.T) {
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), nil)
+	data := generateMultiSymbolBenchmarkData(marketSymbols(1), 90, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	calc.SetPenaltyFunc(flatPenalty{name: "flat"})
+	metric, err := calc.calculateWindowMetrics(context.Background(), data[0].Symbol, data[len(data)-1].Date, data[len(data)-60:])
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, metric.ImpactPenalty)
+	assert.Equal(t, 2.0, metric.ValuePenalty)
+
+	calc.SetPenaltyFunc(nil)
+	assert.Equal(t, DefaultPenaltyFunc, calc.penalty.Name())
+}
+
+func TestCalibrationPersistsPenaltyFunc(t *testing.T) {
+	config := testCalibrationConfig(7)
+	config.PenaltyFunction = "exponential"
+
+	result, err := Calibrate(context.Background(), calibrationTestData(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "exponential", result.PenaltyFunction)
+
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	require.NoError(t, ExportCalibrationResults(result, path))
+	loaded, err := LoadCalibrationResults(path)
+	require.NoError(t, err)
+	assert.Equal(t, "exponential", loaded.PenaltyFunction)
+	assert.Equal(t, result.OptimalParams, loaded.OptimalParams)
+
+	config.PenaltyFunction = "quadratic"
+	_, err = Calibrate(context.Background(), calibrationTestData(), config)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -49,6 +50,9 @@ func SaveToCSV(metrics []TickerMetrics, outputPath string) error {
 		"Spread_Scaled",      // Scaled spread for completeness
 		"Hybrid_Score",
 		"Hybrid_Rank",
+		"Percentile_Rank",    // Mid-rank percentile within the date (0-100)
+		"Quintile",           // Liquidity tier, 1 = most liquid
+		"Decile",
 		"Trading_Days",
 		"Data_Quality",
 		"Safe_Trade_0.5%",    // Safe trading value for 0.5% impact
@@ -105,6 +109,9 @@ func formatMetricRecord(metric TickerMetrics) ([]string, error) {
 		formatFloat(metric.SpreadScaled, 2),        // Scaled spread
 		formatFloat(metric.HybridScore, 4),
 		strconv.Itoa(metric.HybridRank),
+		formatFloat(metric.PercentileRank, 2),
+		strconv.Itoa(metric.Quintile),
+		strconv.Itoa(metric.Decile),
 		strconv.Itoa(metric.TradingDays),
 		dataQuality,
 		formatFloat(metric.SafeValue_0_5, 0),       // Safe trade for 0.5% impact
@@ -187,9 +194,6 @@ func SaveSummaryReport(metrics []TickerMetrics, outputPath string) error {
 		return fmt.Errorf("no metrics to save")
 	}
 	
-	// Calculate summary statistics
-	summary := calculateSummaryStatistics(metrics)
-	
 	// Create summary report file
 	file, err := os.Create(outputPath)
 	if err != nil {
@@ -197,6 +201,16 @@ func SaveSummaryReport(metrics []TickerMetrics, outputPath string) error {
 	}
 	defer file.Close()
 	
+	writeSummaryReport(file, metrics)
+	return nil
+}
+
+// writeSummaryReport writes the text of the summary report, also used on the
+// cover sheet of the XLSX report
+func writeSummaryReport(file io.Writer, metrics []TickerMetrics) {
+	// Calculate summary statistics
+	summary := calculateSummaryStatistics(metrics)
+	
 	// Write summary report
 	fmt.Fprintf(file, "ISX Hybrid Liquidity Metric - Summary Report\n")
 	fmt.Fprintf(file, "============================================\n\n")
@@ -246,8 +260,6 @@ func SaveSummaryReport(metrics []TickerMetrics, outputPath string) error {
 	for i, ticker := range summary.LeastLiquid {
 		fmt.Fprintf(file, "%2d. %s: %.4f\n", i+1, ticker.Symbol, ticker.HybridScore)
 	}
-	
-	return nil
 }
 
 // SummaryStatistics holds summary statistics for the report
@@ -472,4 +484,25 @@ func ExportCalibrationResults(result *CalibrationResult, outputPath string) erro
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+// LoadCalibrationResults reads calibration results saved by
+// ExportCalibrationResults. Results saved before penalties were selectable
+// name no penalty and load with DefaultPenaltyFunc.
+func LoadCalibrationResults(path string) (*CalibrationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read calibration results: %w", err)
+	}
+
+	var result CalibrationResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decode calibration results: %w", err)
+	}
+	if result.PenaltyFunction == "" {
+		result.PenaltyFunction = DefaultPenaltyFunc
+	}
+	if _, err := LookupPenaltyFunc(result.PenaltyFunction); err != nil {
+		return nil, fmt.Errorf("calibration results %s: %w", path, err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,115 @@
+package liquidity
+
+import (
+	"time"
+)
+
+// Phase identifies a stage of a liquidity calculation for progress reporting
+type Phase string
+
+const (
+	// PhaseLoading covers input validation and grouping by ticker
+	PhaseLoading Phase = "loading"
+	// PhaseComponents covers the per-ticker rolling-window component metrics
+	// (ILLIQ, value, continuity, spread)
+	PhaseComponents Phase = "components"
+	// PhaseScaling covers cross-sectional scaling, scoring and ranking per date
+	PhaseScaling Phase = "scaling"
+	// PhasePersistence covers writing results; it is reported by callers that
+	// save the metrics, since the Calculator itself does not persist them
+	PhasePersistence Phase = "persistence"
+)
+
+// Progress is a progress event emitted while metrics are calculated
+type Progress struct {
+	Phase   Phase         `json:"phase"`
+	Window  Window        `json:"window"`
+	Current int           `json:"current"` // Units done in this phase (tickers or dates)
+	Total   int           `json:"total"`
+	Unit    string        `json:"unit"`
+	Symbol  string        `json:"symbol,omitempty"` // Ticker just processed, when per ticker
+	Elapsed time.Duration `json:"elapsed"`          // Time spent in this phase
+	ETA     time.Duration `json:"eta"`              // Estimated time left in this phase, 0 when unknown
+}
+
+// Fraction returns how much of the phase is done, between 0 and 1
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	if p.Current >= p.Total {
+		return 1
+	}
+	return float64(p.Current) / float64(p.Total)
+}
+
+// ProgressFunc receives progress events. It is called synchronously from the
+// calculation and must return quickly.
+type ProgressFunc func(Progress)
+
+// SetProgressFunc registers a callback for per-phase progress events
+func (c *Calculator) SetProgressFunc(fn ProgressFunc) {
+	c.progress = fn
+}
+
+// phaseTracker measures a phase and emits progress with a linear ETA
+type phaseTracker struct {
+	fn     ProgressFunc
+	phase  Phase
+	window Window
+	unit   string
+	total  int
+	start  time.Time
+	every  int // Emit every n units to keep per-date phases quiet
+	now    func() time.Time
+}
+
+// startPhase begins tracking a phase. It returns a tracker that is safe to use
+// when no callback is registered.
+func (c *Calculator) startPhase(phase Phase, total int, unit string) *phaseTracker {
+	every := 1
+	if total > 100 {
+		every = total / 100
+	}
+	t := &phaseTracker{
+		fn:     c.progress,
+		phase:  phase,
+		window: c.window,
+		unit:   unit,
+		total:  total,
+		every:  every,
+		now:    time.Now,
+	}
+	t.start = t.now()
+	t.emit(0, "")
+	return t
+}
+
+// step reports that current units are done. The last unit is always reported.
+func (t *phaseTracker) step(current int, symbol string) {
+	if current%t.every != 0 && current != t.total {
+		return
+	}
+	t.emit(current, symbol)
+}
+
+func (t *phaseTracker) emit(current int, symbol string) {
+	if t.fn == nil {
+		return
+	}
+	elapsed := t.now().Sub(t.start)
+	var eta time.Duration
+	if current > 0 && current < t.total {
+		eta = time.Duration(float64(elapsed) / float64(current) * float64(t.total-current))
+	}
+	t.fn(Progress{
+		Phase:   t.phase,
+		Window:  t.window,
+		Current: current,
+		Total:   t.total,
+		Unit:    t.unit,
+		Symbol:  symbol,
+		Elapsed: elapsed,
+		ETA:     eta,
+	})
+}
@@ -0,0 +1,73 @@
+package liquidity
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculator_ProgressPhases(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), logger)
+
+	var events []Progress
+	calc.SetProgressFunc(func(p Progress) { events = append(events, p) })
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var data []TradingDay
+	for i, symbol := range []string{"BBOB", "TASC", "BMFI"} {
+		for d := 0; d < 70; d++ {
+			price := 1.0 + float64(i) + float64(d%5)*0.01
+			data = append(data, TradingDay{
+				Date: start.AddDate(0, 0, d), Symbol: symbol,
+				Open: price, High: price * 1.02, Low: price * 0.98, Close: price,
+				Volume: 1000, ShareVolume: 1000, Value: 1000 * price, NumTrades: 5 + i,
+				TradingStatus: "true",
+			})
+		}
+	}
+
+	_, err := calc.Calculate(context.Background(), data)
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+
+	var phases []Phase
+	last := map[Phase]Progress{}
+	for _, e := range events {
+		if len(phases) == 0 || phases[len(phases)-1] != e.Phase {
+			phases = append(phases, e.Phase)
+		}
+		assert.Equal(t, Window60, e.Window)
+		assert.LessOrEqual(t, e.Current, e.Total)
+		last[e.Phase] = e
+	}
+	assert.Equal(t, []Phase{PhaseLoading, PhaseComponents, PhaseScaling}, phases)
+
+	components := last[PhaseComponents]
+	assert.Equal(t, 3, components.Total)
+	assert.Equal(t, "tickers", components.Unit)
+	assert.Equal(t, 1.0, components.Fraction())
+	assert.Zero(t, components.ETA, "no time left once the phase is done")
+	assert.NotEmpty(t, components.Symbol)
+	assert.Equal(t, last[PhaseScaling].Total, last[PhaseScaling].Current)
+}
+
+func TestPhaseTracker_ETA(t *testing.T) {
+	var got []Progress
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := &phaseTracker{fn: func(p Progress) { got = append(got, p) }, phase: PhaseComponents, total: 400, every: 4, start: now}
+	tracker.now = func() time.Time { return now.Add(10 * time.Second) }
+
+	tracker.step(3, "")
+	assert.Empty(t, got, "intermediate units are throttled")
+
+	tracker.step(100, "BBOB")
+	require.Len(t, got, 1)
+	assert.Equal(t, 30*time.Second, got[0].ETA)
+	assert.Equal(t, 0.25, got[0].Fraction())
+}
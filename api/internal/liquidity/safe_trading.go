@@ -4,6 +4,9 @@ import (
 	"math"
 )
 
+// maxSafeTradeValue caps every safe trading value (IQD)
+const maxSafeTradeValue = 100_000_000.0
+
 // SafeTradingLimits contains the calculated safe trading values for different impact thresholds
 type SafeTradingLimits struct {
 	Symbol           string  `json:"symbol"`
@@ -58,20 +61,8 @@ func CalculateSafeTrading(metrics TickerMetrics) SafeTradingLimits {
 	
 	// 2. Apply volume constraint (typically 10-20% of average daily volume)
 	// More conservative for less liquid stocks
-	var maxDailyPercent float64
-	if metrics.HybridScore >= 70 {
-		maxDailyPercent = 0.20  // 20% for highly liquid stocks
-		limits.LiquidityRating = "HIGH"
-	} else if metrics.HybridScore >= 50 {
-		maxDailyPercent = 0.15  // 15% for medium liquidity
-		limits.LiquidityRating = "MEDIUM"
-	} else if metrics.HybridScore >= 30 {
-		maxDailyPercent = 0.10  // 10% for low liquidity
-		limits.LiquidityRating = "LOW"
-	} else {
-		maxDailyPercent = 0.05  // 5% for poor liquidity
-		limits.LiquidityRating = "POOR"
-	}
+	maxDailyPercent, rating := volumeLimit(metrics.HybridScore)
+	limits.LiquidityRating = rating
 	
 	limits.MaxDailyPercent = maxDailyPercent * 100  // Store as percentage
 	limits.VolumeCap = metrics.Value * maxDailyPercent
@@ -115,6 +106,21 @@ func CalculateSafeTrading(metrics TickerMetrics) SafeTradingLimits {
 	return limits
 }
 
+// volumeLimit returns the share of average daily value a single day's trading
+// may take, and the matching liquidity rating
+func volumeLimit(hybridScore float64) (float64, string) {
+	switch {
+	case hybridScore >= 70:
+		return 0.20, "HIGH" // 20% for highly liquid stocks
+	case hybridScore >= 50:
+		return 0.15, "MEDIUM" // 15% for medium liquidity
+	case hybridScore >= 30:
+		return 0.10, "LOW" // 10% for low liquidity
+	default:
+		return 0.05, "POOR" // 5% for poor liquidity
+	}
+}
+
 // calculateActivityAdjustment returns an adjustment factor based on trading activity
 // More active stocks (higher ActivityScore) get less penalty
 func calculateActivityAdjustment(activityScore float64) float64 {
@@ -172,7 +178,7 @@ func ensureSafeLimits(limits *SafeTradingLimits) {
 	}
 	
 	// Cap at reasonable maximum (100M IQD)
-	maxTradeSize := 100_000_000.0
+	maxTradeSize := maxSafeTradeValue
 	if limits.SafeValue_2_0 > maxTradeSize {
 		limits.SafeValue_2_0 = maxTradeSize
 		limits.SafeValue_1_0 = math.Min(limits.SafeValue_1_0, maxTradeSize)
@@ -259,4 +265,60 @@ func CreateTradeSchedule(metrics TickerMetrics, totalTradeValue float64) TradeSc
 	schedule.EstimatedImpact = EstimateImpact(metrics, trancheSize)
 	
 	return schedule
-}
\ No newline at end of file
+}
+
+// SafeTradeValue returns the largest value (IQD) that can be traded in one day
+// with at most impactPercent price impact. It is the value behind
+// SafeValue_0_5, SafeValue_1_0 and SafeValue_2_0 for any threshold: the ILLIQ
+// estimate, capped by the volume limit of the ticker's liquidity rating and
+// by 100M IQD.
+func SafeTradeValue(metrics TickerMetrics, impactPercent float64) float64 {
+	if impactPercent <= 0 || metrics.ILLIQ <= 0 || math.IsNaN(metrics.ILLIQ) || math.IsInf(metrics.ILLIQ, 0) {
+		return 0
+	}
+	value := (impactPercent / 100 / metrics.ILLIQ) * 1_000_000
+	fraction, _ := volumeLimit(metrics.HybridScore)
+	value = math.Min(value, metrics.Value*fraction)
+	return math.Max(0, math.Min(value, maxSafeTradeValue))
+}
+
+// SafeTradeEstimate sizes trading in a ticker against one impact threshold
+type SafeTradeEstimate struct {
+	ImpactPercent   float64 `json:"impact_percent"`
+	MaxTradeValue   float64 `json:"max_trade_value"`   // Largest daily trade (IQD) within the impact
+	MaxDailyPercent float64 `json:"max_daily_percent"` // Volume limit, % of average daily value
+	LiquidityRating string  `json:"liquidity_rating"`  // HIGH/MEDIUM/LOW/POOR, INVALID without ILLIQ
+
+	// Set when a position size is given
+	PositionValue     float64 `json:"position_value,omitempty"`
+	DaysToExit        *int    `json:"days_to_exit,omitempty"`                // Trading days at MaxTradeValue per day
+	SingleTradeImpact float64 `json:"single_trade_impact_percent,omitempty"` // Impact of trading the whole position at once
+}
+
+// EstimateSafeTrade returns the safe daily trade value for impactPercent and,
+// for a positive positionValue, how many trading days it takes to build or
+// exit that position without exceeding the impact. DaysToExit is left unset
+// when the ticker has no safe value to trade.
+func EstimateSafeTrade(metrics TickerMetrics, impactPercent, positionValue float64) SafeTradeEstimate {
+	fraction, rating := volumeLimit(metrics.HybridScore)
+	estimate := SafeTradeEstimate{
+		ImpactPercent:   impactPercent,
+		MaxTradeValue:   SafeTradeValue(metrics, impactPercent),
+		MaxDailyPercent: fraction * 100,
+		LiquidityRating: rating,
+	}
+	if metrics.ILLIQ <= 0 || math.IsNaN(metrics.ILLIQ) || math.IsInf(metrics.ILLIQ, 0) {
+		estimate.LiquidityRating = "INVALID"
+	}
+
+	if positionValue <= 0 {
+		return estimate
+	}
+	estimate.PositionValue = positionValue
+	estimate.SingleTradeImpact = EstimateImpact(metrics, positionValue)
+	if estimate.MaxTradeValue > 0 {
+		days := int(math.Ceil(positionValue / estimate.MaxTradeValue))
+		estimate.DaysToExit = &days
+	}
+	return estimate
+}
@@ -322,4 +322,80 @@ func BenchmarkCreateTradeSchedule(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = CreateTradeSchedule(1_000_000, 50_000, 500_000)
 	}
-}
\ No newline at end of file
+}
+func TestSafeTradeValue(t *testing.T) {
+	metrics := TickerMetrics{
+		Symbol:      "BBOB",
+		ILLIQ:       0.5,
+		Value:       10_000_000,
+		HybridScore: 75,
+	}
+	limits := CalculateSafeTrading(metrics)
+
+	// The standard thresholds match CalculateSafeTrading
+	if got := SafeTradeValue(metrics, 0.5); got != limits.SafeValue_0_5 {
+		t.Errorf("SafeTradeValue(0.5) = %v, want %v", got, limits.SafeValue_0_5)
+	}
+	if got := SafeTradeValue(metrics, 1.0); got != limits.SafeValue_1_0 {
+		t.Errorf("SafeTradeValue(1.0) = %v, want %v", got, limits.SafeValue_1_0)
+	}
+	if got := SafeTradeValue(metrics, 2.0); got != limits.SafeValue_2_0 {
+		t.Errorf("SafeTradeValue(2.0) = %v, want %v", got, limits.SafeValue_2_0)
+	}
+
+	// Large thresholds are capped at 20% of daily value for a HIGH rating
+	thin := metrics
+	thin.Value = 500_000
+	if got := SafeTradeValue(thin, 10); got != 100_000 {
+		t.Errorf("SafeTradeValue(10) = %v, want volume cap 100000", got)
+	}
+
+	if got := SafeTradeValue(TickerMetrics{ILLIQ: math.NaN(), Value: 1_000_000}, 1); got != 0 {
+		t.Errorf("SafeTradeValue with invalid ILLIQ = %v, want 0", got)
+	}
+	if got := SafeTradeValue(metrics, 0); got != 0 {
+		t.Errorf("SafeTradeValue(0) = %v, want 0", got)
+	}
+}
+
+func TestEstimateSafeTrade(t *testing.T) {
+	metrics := TickerMetrics{
+		Symbol:      "BBOB",
+		ILLIQ:       0.5,
+		Value:       10_000_000,
+		HybridScore: 55,
+	}
+
+	t.Run("without position", func(t *testing.T) {
+		estimate := EstimateSafeTrade(metrics, 1.0, 0)
+		if estimate.MaxTradeValue != 20_000 {
+			t.Errorf("MaxTradeValue = %v, want 20000", estimate.MaxTradeValue)
+		}
+		if estimate.LiquidityRating != "MEDIUM" || estimate.MaxDailyPercent != 15 {
+			t.Errorf("rating = %s %v%%, want MEDIUM 15%%", estimate.LiquidityRating, estimate.MaxDailyPercent)
+		}
+		if estimate.DaysToExit != nil {
+			t.Errorf("DaysToExit = %v, want unset", *estimate.DaysToExit)
+		}
+	})
+
+	t.Run("days to exit a position", func(t *testing.T) {
+		estimate := EstimateSafeTrade(metrics, 1.0, 50_000)
+		if estimate.DaysToExit == nil || *estimate.DaysToExit != 3 {
+			t.Fatalf("DaysToExit = %v, want 3", estimate.DaysToExit)
+		}
+		if estimate.SingleTradeImpact <= 0 {
+			t.Errorf("SingleTradeImpact = %v, want positive", estimate.SingleTradeImpact)
+		}
+	})
+
+	t.Run("no safe value", func(t *testing.T) {
+		estimate := EstimateSafeTrade(TickerMetrics{Symbol: "DEAD", Value: 1_000_000}, 1.0, 50_000)
+		if estimate.LiquidityRating != "INVALID" {
+			t.Errorf("LiquidityRating = %s, want INVALID", estimate.LiquidityRating)
+		}
+		if estimate.DaysToExit != nil {
+			t.Errorf("DaysToExit = %v, want unset", *estimate.DaysToExit)
+		}
+	})
+}
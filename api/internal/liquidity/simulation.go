@@ -0,0 +1,130 @@
+package liquidity
+
+import (
+	"fmt"
+	"math"
+)
+
+// ImpactSlice is one trading day of a simulated execution schedule
+type ImpactSlice struct {
+	Day                  int     `json:"day"`                   // Trading day of the schedule, from 1
+	Value                float64 `json:"value"`                 // Value traded that day (IQD)
+	ParticipationPercent float64 `json:"participation_percent"` // Share of the average daily value
+	ImpactPercent        float64 `json:"impact_percent"`        // Expected price impact of the day's trading
+	CumulativeValue      float64 `json:"cumulative_value"`      // Value traded up to and including the day
+}
+
+// ImpactSimulation is the expected price impact of trading a value within a
+// horizon of trading days, and the schedule that keeps it lowest
+type ImpactSimulation struct {
+	TradeValue  float64 `json:"trade_value"`  // IQD
+	HorizonDays int     `json:"horizon_days"` // Most trading days the trade may take
+
+	// Days is how many trading days the schedule uses: as few as keep each
+	// day within the volume limit, at most HorizonDays. RecommendedDays is
+	// that minimum regardless of the horizon.
+	Days            int  `json:"days"`
+	RecommendedDays int  `json:"recommended_days"`
+	WithinLimits    bool `json:"within_limits"` // Every day within the volume limit
+	// ExpectedSessions is how many sessions the schedule is likely to span,
+	// since the ticker does not trade in every session
+	ExpectedSessions int `json:"expected_sessions"`
+
+	SingleDayImpactPercent  float64 `json:"single_day_impact_percent"` // Trading everything at once
+	ExpectedImpactPercent   float64 `json:"expected_impact_percent"`   // Value-weighted impact per day
+	CumulativeImpactPercent float64 `json:"cumulative_impact_percent"` // Price drift if each day's impact persists
+	ActivityPenalty         float64 `json:"activity_penalty"`          // Multiplier applied to ILLIQ impacts
+	SpreadCostPercent       float64 `json:"spread_cost_percent"`       // Estimated spread cost %
+	MaxDailyPercent         float64 `json:"max_daily_percent"`         // Volume limit, % of average daily value
+	LiquidityRating         string  `json:"liquidity_rating"`          // HIGH/MEDIUM/LOW/POOR, INVALID without ILLIQ
+
+	Schedule []ImpactSlice `json:"schedule"`
+	Warnings []string      `json:"warnings"`
+}
+
+// SimulateImpact plans trading tradeValue (IQD) over at most horizonDays
+// trading days. The value is split evenly over the fewest days that keep each
+// day within the volume limit of the ticker's liquidity rating; each day's
+// impact is the ILLIQ estimate of EstimateImpact scaled by the activity
+// penalty of the liquidity model, so rarely traded tickers are not
+// understated.
+func SimulateImpact(metrics TickerMetrics, tradeValue float64, horizonDays int) ImpactSimulation {
+	fraction, rating := volumeLimit(metrics.HybridScore)
+	penalty := metrics.ImpactPenalty
+	if penalty < 1 {
+		penalty = 1
+	}
+	sim := ImpactSimulation{
+		TradeValue:        tradeValue,
+		HorizonDays:       horizonDays,
+		ActivityPenalty:   penalty,
+		SpreadCostPercent: metrics.SpreadProxy * 100,
+		MaxDailyPercent:   fraction * 100,
+		LiquidityRating:   rating,
+		Schedule:          []ImpactSlice{},
+		Warnings:          []string{},
+	}
+	if tradeValue <= 0 || horizonDays <= 0 {
+		return sim
+	}
+
+	validILLIQ := metrics.ILLIQ > 0 && !math.IsNaN(metrics.ILLIQ) && !math.IsInf(metrics.ILLIQ, 0)
+	if !validILLIQ {
+		sim.LiquidityRating = "INVALID"
+		sim.Warnings = append(sim.Warnings, "No ILLIQ estimate for the ticker; price impacts are unknown")
+	}
+
+	dailyCap := metrics.Value * fraction
+	sim.Days = horizonDays
+	if dailyCap > 0 {
+		sim.RecommendedDays = int(math.Ceil(tradeValue / dailyCap))
+		sim.Days = min(sim.RecommendedDays, horizonDays)
+		sim.WithinLimits = sim.RecommendedDays <= horizonDays
+	} else {
+		sim.Warnings = append(sim.Warnings, "The ticker has no recent trading value; no volume limit applies")
+	}
+	if dailyCap > 0 && !sim.WithinLimits {
+		sim.Warnings = append(sim.Warnings, fmt.Sprintf(
+			"%d trading days take %.1f%% of the average daily value per day, above the %.0f%% limit; %d days keep within it",
+			horizonDays, tradeValue/float64(horizonDays)/metrics.Value*100, sim.MaxDailyPercent, sim.RecommendedDays))
+	}
+
+	impact := func(value float64) float64 {
+		if !validILLIQ {
+			return 0
+		}
+		return EstimateImpact(metrics, value) * penalty
+	}
+	sim.SingleDayImpactPercent = impact(tradeValue)
+
+	slice := tradeValue / float64(sim.Days)
+	sliceImpact := impact(slice)
+	participation := 0.0
+	if metrics.Value > 0 {
+		participation = slice / metrics.Value * 100
+	}
+	for day := 1; day <= sim.Days; day++ {
+		sim.Schedule = append(sim.Schedule, ImpactSlice{
+			Day:                  day,
+			Value:                slice,
+			ParticipationPercent: participation,
+			ImpactPercent:        sliceImpact,
+			CumulativeValue:      slice * float64(day),
+		})
+	}
+	// Equal slices make the value-weighted average the slice's impact
+	sim.ExpectedImpactPercent = sliceImpact
+	sim.CumulativeImpactPercent = sliceImpact * float64(sim.Days)
+
+	sim.ExpectedSessions = sim.Days
+	if metrics.TotalDays > 0 && metrics.TradingDays > 0 && metrics.TradingDays < metrics.TotalDays {
+		continuity := float64(metrics.TradingDays) / float64(metrics.TotalDays)
+		sim.ExpectedSessions = int(math.Ceil(float64(sim.Days) / continuity))
+		if continuity < 0.5 {
+			sim.Warnings = append(sim.Warnings, fmt.Sprintf(
+				"The ticker traded in %d of the last %d sessions; the schedule may take about %d sessions to fill",
+				metrics.TradingDays, metrics.TotalDays, sim.ExpectedSessions))
+		}
+	}
+	return sim
+}
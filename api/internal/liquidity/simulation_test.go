@@ -0,0 +1,90 @@
+package liquidity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simulationMetrics() TickerMetrics {
+	return TickerMetrics{
+		Symbol:        "BBOB",
+		ILLIQ:         0.5,
+		Value:         10_000_000, // 20% limit at HIGH: 2M IQD a day
+		HybridScore:   75,
+		ImpactPenalty: 1,
+		SpreadProxy:   0.004,
+		TradingDays:   60,
+		TotalDays:     60,
+	}
+}
+
+func TestSimulateImpact_SplitsWithinVolumeLimit(t *testing.T) {
+	metrics := simulationMetrics()
+	sim := SimulateImpact(metrics, 5_000_000, 10)
+
+	assert.Equal(t, 3, sim.RecommendedDays)
+	assert.Equal(t, 3, sim.Days, "no more days than the volume limit needs")
+	assert.True(t, sim.WithinLimits)
+	assert.Equal(t, 3, sim.ExpectedSessions)
+	assert.Equal(t, "HIGH", sim.LiquidityRating)
+	assert.InDelta(t, 0.4, sim.SpreadCostPercent, 1e-9)
+	assert.Empty(t, sim.Warnings)
+
+	require.Len(t, sim.Schedule, 3)
+	slice := 5_000_000.0 / 3
+	for i, s := range sim.Schedule {
+		assert.Equal(t, i+1, s.Day)
+		assert.InDelta(t, slice, s.Value, 1e-6)
+		assert.InDelta(t, slice/10_000_000*100, s.ParticipationPercent, 1e-9)
+		assert.InDelta(t, EstimateImpact(metrics, slice), s.ImpactPercent, 1e-9)
+	}
+	assert.InDelta(t, 5_000_000, sim.Schedule[2].CumulativeValue, 1e-6)
+	assert.InDelta(t, EstimateImpact(metrics, 5_000_000), sim.SingleDayImpactPercent, 1e-9)
+	assert.Less(t, sim.ExpectedImpactPercent, sim.SingleDayImpactPercent, "slicing lowers the daily impact")
+	assert.InDelta(t, 3*sim.ExpectedImpactPercent, sim.CumulativeImpactPercent, 1e-9)
+}
+
+func TestSimulateImpact_HorizonTooShort(t *testing.T) {
+	sim := SimulateImpact(simulationMetrics(), 5_000_000, 2)
+
+	assert.Equal(t, 2, sim.Days)
+	assert.Equal(t, 3, sim.RecommendedDays)
+	assert.False(t, sim.WithinLimits)
+	require.Len(t, sim.Warnings, 1)
+	assert.Contains(t, sim.Warnings[0], "25.0% of the average daily value")
+	assert.Contains(t, sim.Warnings[0], "3 days keep within it")
+}
+
+func TestSimulateImpact_PenaltyAndActivity(t *testing.T) {
+	base := simulationMetrics()
+	thin := base
+	thin.ImpactPenalty = 2
+	thin.TradingDays = 12
+
+	plain := SimulateImpact(base, 1_000_000, 5)
+	sim := SimulateImpact(thin, 1_000_000, 5)
+
+	assert.Equal(t, 1, sim.Days)
+	assert.InDelta(t, 2*plain.ExpectedImpactPercent, sim.ExpectedImpactPercent, 1e-9, "the activity penalty scales ILLIQ impacts")
+	assert.Equal(t, 5, sim.ExpectedSessions, "one trading day in five sessions")
+	require.Len(t, sim.Warnings, 1)
+	assert.Contains(t, sim.Warnings[0], "traded in 12 of the last 60 sessions")
+}
+
+func TestSimulateImpact_WithoutILLIQ(t *testing.T) {
+	metrics := simulationMetrics()
+	metrics.ILLIQ = 0
+	sim := SimulateImpact(metrics, 1_000_000, 5)
+
+	assert.Equal(t, "INVALID", sim.LiquidityRating)
+	assert.Zero(t, sim.SingleDayImpactPercent)
+	assert.Zero(t, sim.ExpectedImpactPercent)
+	assert.Len(t, sim.Schedule, 1, "the schedule still follows the volume limit")
+	assert.NotEmpty(t, sim.Warnings)
+
+	empty := SimulateImpact(simulationMetrics(), 0, 5)
+	assert.Empty(t, empty.Schedule)
+	assert.Zero(t, empty.Days)
+}
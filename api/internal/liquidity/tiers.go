@@ -0,0 +1,64 @@
+package liquidity
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tier bucket counts: quintiles and deciles of the cross-section, numbered
+// from 1 for the most liquid
+const (
+	Quintiles = 5
+	Deciles   = 10
+)
+
+// PercentileRanks returns the mid-rank percentile of each score within the
+// scores: the share scoring lower plus half the share tied, from 0 to 100.
+// Tied scores share a percentile, so they always land in the same tier.
+func PercentileRanks(scores []float64) []float64 {
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+
+	n := float64(len(scores))
+	ranks := make([]float64, len(scores))
+	for i, s := range scores {
+		below := sort.SearchFloat64s(sorted, s)
+		upTo := sort.Search(len(sorted), func(j int) bool { return sorted[j] > s })
+		ranks[i] = (float64(below) + 0.5*float64(upTo-below)) / n * 100
+	}
+	return ranks
+}
+
+// Tier returns the bucket, from 1 (most liquid) to buckets, holding a
+// percentile rank
+func Tier(percentile float64, buckets int) int {
+	tier := int((100-percentile)*float64(buckets)/100) + 1
+	return min(max(tier, 1), buckets)
+}
+
+// ParseTiers parses a tier grouping name: quintile or decile
+func ParseTiers(s string) (int, error) {
+	switch s {
+	case "", "quintile", "quintiles":
+		return Quintiles, nil
+	case "decile", "deciles":
+		return Deciles, nil
+	default:
+		return 0, fmt.Errorf("unsupported tiers %q (use quintile or decile)", s)
+	}
+}
+
+// applyTiers sets the percentile rank, quintile and decile of the metrics of
+// one cross-section, selected by indices
+func applyTiers(allMetrics []TickerMetrics, indices []int) {
+	scores := make([]float64, len(indices))
+	for i, idx := range indices {
+		scores[i] = allMetrics[idx].HybridScore
+	}
+	for i, pct := range PercentileRanks(scores) {
+		m := &allMetrics[indices[i]]
+		m.PercentileRank = pct
+		m.Quintile = Tier(pct, Quintiles)
+		m.Decile = Tier(pct, Deciles)
+	}
+}
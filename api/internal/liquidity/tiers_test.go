@@ -0,0 +1,70 @@
+package liquidity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileRanks(t *testing.T) {
+	ranks := PercentileRanks([]float64{10, 50, 30, 30, 90})
+	assert.InDeltaSlice(t, []float64{10, 70, 40, 40, 90}, ranks, 1e-9, "ties share the mid-rank")
+	assert.Empty(t, PercentileRanks(nil))
+}
+
+func TestTier(t *testing.T) {
+	// Five distinct scores fall one per quintile, best first
+	for i, pct := range PercentileRanks([]float64{90, 70, 50, 30, 10}) {
+		assert.Equal(t, i+1, Tier(pct, Quintiles))
+	}
+	assert.Equal(t, 1, Tier(100, Deciles))
+	assert.Equal(t, 10, Tier(0, Deciles))
+	assert.Equal(t, 3, Tier(50, Quintiles), "a lone ticker sits in the middle")
+}
+
+func TestApplyRanking_SetsTiers(t *testing.T) {
+	metrics := make([]TickerMetrics, 10)
+	indices := make([]int, 10)
+	for i := range metrics {
+		metrics[i] = TickerMetrics{Symbol: string(rune('A' + i)), HybridScore: float64(i * 10)}
+		indices[i] = i
+	}
+
+	calc := &Calculator{}
+	calc.applyRanking(metrics, indices)
+
+	best, worst := metrics[9], metrics[0]
+	assert.Equal(t, 1, best.HybridRank)
+	assert.InDelta(t, 95.0, best.PercentileRank, 1e-9)
+	assert.Equal(t, 1, best.Quintile)
+	assert.Equal(t, 1, best.Decile)
+	assert.Equal(t, 5, worst.Quintile)
+	assert.Equal(t, 10, worst.Decile)
+	assert.Equal(t, 3, metrics[5].Quintile)
+}
+
+func TestHistory_TiersRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFileName)
+	date := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	metrics := []TickerMetrics{{
+		Symbol: "BBOB", Date: date, Window: Window60, HybridScore: 80, HybridRank: 1,
+		PercentileRank: 87.5, Quintile: 1, Decile: 2, TradingDays: 60, TotalDays: 60,
+	}}
+	require.NoError(t, AppendHistory(metrics, path, date))
+
+	points, err := LoadHistory(path, "BBOB", Window60)
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, 87.5, points[0].PercentileRank)
+	assert.Equal(t, 1, points[0].Quintile)
+	assert.Equal(t, 2, points[0].Decile)
+
+	// History written before the tier columns still loads
+	old, err := parseHistoryRecord([]string{"2025-03-02", "TASC", "60d", "50.0000", "2", "0.00000001", "1000", "0.5000", "0.000000", "30", "60", "2025-03-02T00:00:00Z"})
+	require.NoError(t, err)
+	assert.Equal(t, "TASC", old.Symbol)
+	assert.Zero(t, old.Quintile)
+}
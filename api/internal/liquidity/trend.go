@@ -0,0 +1,80 @@
+package liquidity
+
+// TrendDirection classifies how a ticker's liquidity score is moving
+type TrendDirection string
+
+const (
+	TrendImproving     TrendDirection = "improving"
+	TrendDeteriorating TrendDirection = "deteriorating"
+	TrendStable        TrendDirection = "stable"
+	TrendInsufficient  TrendDirection = "insufficient_data"
+)
+
+const (
+	// DefaultTrendWindows is how many recent windows the trend looks at
+	DefaultTrendWindows = 10
+	// DefaultTrendThreshold is the fitted score change, in hybrid score
+	// points across the inspected windows, needed to call a trend
+	DefaultTrendThreshold = 5.0
+	// MinTrendPoints is the fewest observations a trend is computed from
+	MinTrendPoints = 3
+)
+
+// Trend summarises the direction of the hybrid score over recent windows
+type Trend struct {
+	Direction   TrendDirection `json:"direction"`
+	Windows     int            `json:"windows"`
+	Slope       float64        `json:"slope"`       // score points per window
+	ScoreChange float64        `json:"scoreChange"` // fitted change across the windows
+	RankChange  int            `json:"rankChange"`  // positive means the rank number fell (better)
+	FirstScore  float64        `json:"firstScore"`
+	LastScore   float64        `json:"lastScore"`
+}
+
+// DetectTrend fits a least-squares line through the hybrid scores of the last
+// n points (ordered by date) and classifies the fitted change against threshold.
+// A least-squares fit rather than last-minus-first keeps a single noisy window
+// from flipping the result.
+func DetectTrend(points []HistoryPoint, n int, threshold float64) Trend {
+	if n <= 0 {
+		n = DefaultTrendWindows
+	}
+	if threshold <= 0 {
+		threshold = DefaultTrendThreshold
+	}
+	if len(points) > n {
+		points = points[len(points)-n:]
+	}
+
+	trend := Trend{Direction: TrendInsufficient, Windows: len(points)}
+	if len(points) < MinTrendPoints {
+		return trend
+	}
+
+	first, last := points[0], points[len(points)-1]
+	trend.FirstScore = first.HybridScore
+	trend.LastScore = last.HybridScore
+	trend.RankChange = first.HybridRank - last.HybridRank
+
+	count := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, p := range points {
+		x := float64(i)
+		sumX += x
+		sumY += p.HybridScore
+		sumXY += x * p.HybridScore
+		sumXX += x * x
+	}
+	trend.Slope = (count*sumXY - sumX*sumY) / (count*sumXX - sumX*sumX)
+	trend.ScoreChange = trend.Slope * (count - 1)
+
+	switch {
+	case trend.ScoreChange >= threshold:
+		trend.Direction = TrendImproving
+	case trend.ScoreChange <= -threshold:
+		trend.Direction = TrendDeteriorating
+	default:
+		trend.Direction = TrendStable
+	}
+	return trend
+}
@@ -0,0 +1,108 @@
+package liquidity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func historySeries(scores ...float64) []HistoryPoint {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]HistoryPoint, len(scores))
+	for i, score := range scores {
+		points[i] = HistoryPoint{
+			Date:        start.AddDate(0, 0, i),
+			Symbol:      "BBOB",
+			Window:      Window60,
+			HybridScore: score,
+			HybridRank:  20 - i,
+		}
+	}
+	return points
+}
+
+func TestDetectTrend(t *testing.T) {
+	tests := []struct {
+		name      string
+		scores    []float64
+		n         int
+		direction TrendDirection
+	}{
+		{"improving", []float64{40, 44, 47, 52, 55}, 5, TrendImproving},
+		{"deteriorating", []float64{70, 66, 60, 58, 51}, 5, TrendDeteriorating},
+		{"stable with one noisy window", []float64{50, 51, 49, 62, 50, 51}, 6, TrendStable},
+		{"only last n windows count", []float64{10, 20, 30, 40, 60, 60, 60}, 3, TrendStable},
+		{"insufficient data", []float64{40, 80}, 5, TrendInsufficient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend := DetectTrend(historySeries(tt.scores...), tt.n, DefaultTrendThreshold)
+			assert.Equal(t, tt.direction, trend.Direction)
+		})
+	}
+}
+
+func TestDetectTrend_Fields(t *testing.T) {
+	trend := DetectTrend(historySeries(40, 45, 50), 10, 0)
+
+	assert.Equal(t, TrendImproving, trend.Direction)
+	assert.Equal(t, 3, trend.Windows)
+	assert.InDelta(t, 5.0, trend.Slope, 1e-9)
+	assert.InDelta(t, 10.0, trend.ScoreChange, 1e-9)
+	assert.Equal(t, 2, trend.RankChange)
+	assert.Equal(t, 40.0, trend.FirstScore)
+	assert.Equal(t, 50.0, trend.LastScore)
+}
+
+func TestAppendHistory_MergesRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "liquidity", "history", HistoryFileName)
+	day1 := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	firstRun := []TickerMetrics{
+		{Symbol: "BBOB", Date: day1, Window: Window60, HybridScore: 40, HybridRank: 3, TradingDays: 50, TotalDays: 60},
+		{Symbol: "TASC", Date: day1, Window: Window60, HybridScore: 80, HybridRank: 1, TradingDays: 60, TotalDays: 60},
+	}
+	require.NoError(t, AppendHistory(firstRun, path, time.Date(2025, 1, 5, 18, 0, 0, 0, time.UTC)))
+
+	secondRun := []TickerMetrics{
+		{Symbol: "BBOB", Date: day1, Window: Window60, HybridScore: 42, HybridRank: 2, TradingDays: 50, TotalDays: 60},
+		{Symbol: "BBOB", Date: day2, Window: Window60, HybridScore: 45, HybridRank: 2, TradingDays: 51, TotalDays: 60},
+	}
+	secondAt := time.Date(2025, 1, 6, 18, 0, 0, 0, time.UTC)
+	require.NoError(t, AppendHistory(secondRun, path, secondAt))
+
+	points, err := LoadHistory(path, "bbob", 0)
+	require.NoError(t, err)
+	require.Len(t, points, 2, "the recalculated day replaces the earlier point")
+	assert.Equal(t, day1, points[0].Date)
+	assert.Equal(t, 42.0, points[0].HybridScore)
+	assert.Equal(t, secondAt, points[0].CalculatedAt)
+	assert.Equal(t, 45.0, points[1].HybridScore)
+	assert.Equal(t, 51, points[1].TradingDays)
+
+	points, err = LoadHistory(path, "TASC", Window20)
+	require.NoError(t, err)
+	assert.Empty(t, points)
+
+	points, err = LoadHistory(filepath.Join(t.TempDir(), "missing.csv"), "BBOB", 0)
+	require.NoError(t, err)
+	assert.Empty(t, points)
+}
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow("60d")
+	require.NoError(t, err)
+	assert.Equal(t, Window60, w)
+
+	w, err = ParseWindow("120")
+	require.NoError(t, err)
+	assert.Equal(t, Window120, w)
+
+	_, err = ParseWindow("30d")
+	assert.Error(t, err)
+}
@@ -97,6 +97,9 @@ type TickerMetrics struct {
 	// Final hybrid score
 	HybridScore      float64   `json:"hybrid_score"`      // ISX Hybrid Liquidity Score
 	HybridRank       int       `json:"hybrid_rank"`       // Relative ranking
+	PercentileRank   float64   `json:"percentile_rank"`   // Mid-rank percentile in the date's cross-section (0-100)
+	Quintile         int       `json:"quintile"`          // Liquidity tier, 1 (most liquid) to 5
+	Decile           int       `json:"decile"`            // Liquidity tier, 1 (most liquid) to 10
 	
 	// Supporting metrics
 	SpreadProxy      float64   `json:"spread_proxy"`      // Corwin-Schultz spread estimate
@@ -175,6 +178,7 @@ func (cw *ComponentWeights) Normalize() {
 // CalibrationResult contains the results of parameter calibration
 type CalibrationResult struct {
 	OptimalParams     PenaltyParams     `json:"optimal_params"`
+	PenaltyFunction   string            `json:"penalty_function"` // Registry name of the penalty the params were fitted for
 	OptimalWeights    ComponentWeights  `json:"optimal_weights"`
 	CrossValidationR2 float64          `json:"cv_r2"`           // Cross-validation R²
 	SpreadCorrelation float64          `json:"spread_corr"`     // Correlation with spread proxy
@@ -183,6 +187,7 @@ type CalibrationResult struct {
 	WindowUsed        Window           `json:"window_used"`
 	NumTickers        int              `json:"num_tickers"`
 	NumObservations   int              `json:"num_observations"`
+	RandomSeed        int64            `json:"random_seed"`     // Seed of the k-fold split; rerun with it to reproduce the result
 }
 
 // IsValid checks if calibration results are valid
@@ -203,13 +208,16 @@ type CalibrationConfig struct {
 	
 	// Cross-validation settings
 	KFolds            int     `json:"k_folds"`             // Number of CV folds
-	RandomSeed        int64   `json:"random_seed"`         // Random seed for reproducibility
+	RandomSeed        int64   `json:"random_seed"`         // Seed of the k-fold split; 0 picks one from the clock
 	
 	// Optimization targets
 	TargetMetric      string  `json:"target_metric"`       // "r2", "correlation", "combined"
 	R2Weight          float64 `json:"r2_weight"`           // Weight for R² in combined metric
 	CorrelationWeight float64 `json:"correlation_weight"`  // Weight for correlation in combined metric
 	
+	// Penalty applied to inactive tickers while fitting, by registry name
+	PenaltyFunction   string  `json:"penalty_function"`    // Empty selects DefaultPenaltyFunc
+	
 	// Constraints
 	MinTradingDays    int     `json:"min_trading_days"`    // Minimum trading days required
 	MinTickers        int     `json:"min_tickers"`         // Minimum tickers required
@@ -0,0 +1,82 @@
+package liquidity
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CrossSection returns the history points of every symbol for one window on
+// date, ordered by symbol, and the date used. A zero date selects the latest
+// date in the history for the window.
+func CrossSection(path string, window Window, date time.Time) ([]HistoryPoint, time.Time, error) {
+	all, err := readHistory(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if date.IsZero() {
+		for _, p := range all {
+			if p.Window == window && p.Date.After(date) {
+				date = p.Date
+			}
+		}
+	}
+
+	var points []HistoryPoint
+	for _, p := range all {
+		if p.Window == window && sameDay(p.Date, date) {
+			points = append(points, p)
+		}
+	}
+	sortHistory(points)
+	return points, date, nil
+}
+
+// FromHistory rebuilds the unscaled metrics of a history point the way
+// Calculate derives them, ready for ScaleUniverse
+func (c *Calculator) FromHistory(p HistoryPoint) TickerMetrics {
+	continuityNL := p.Continuity
+	if c.weights.Continuity >= 0.1 {
+		continuityNL = ContinuityNL(p.Continuity, DefaultContinuityDelta)
+	}
+	activity := ActivityScore(p.TradingDays, p.TotalDays)
+	penalty := UnifiedPenalty(activity, c.penaltyParams.PiecewiseMaxMult)
+
+	return TickerMetrics{
+		Symbol:        p.Symbol,
+		Date:          p.Date,
+		Window:        p.Window,
+		ILLIQ:         p.ILLIQ,
+		Value:         p.Value,
+		Continuity:    p.Continuity,
+		ContinuityNL:  continuityNL,
+		ImpactPenalty: penalty,
+		ValuePenalty:  penalty,
+		ActivityScore: activity,
+		SpreadProxy:   p.SpreadProxy,
+		TradingDays:   p.TradingDays,
+		TotalDays:     p.TotalDays,
+	}
+}
+
+// ScaleUniverse applies cross-sectional scaling, hybrid scores, ranks and safe
+// trading values within metrics only, so scores are relative to that universe
+// rather than the whole market. Metrics must share one date and the universe
+// needs at least two tickers.
+func (c *Calculator) ScaleUniverse(ctx context.Context, metrics []TickerMetrics) error {
+	if len(metrics) < 2 {
+		return fmt.Errorf("cross-sectional scaling needs at least 2 tickers, got %d", len(metrics))
+	}
+	for _, m := range metrics[1:] {
+		if !sameDay(m.Date, metrics[0].Date) {
+			return fmt.Errorf("universe mixes dates %s and %s",
+				metrics[0].Date.Format("2006-01-02"), m.Date.Format("2006-01-02"))
+		}
+	}
+	return c.applyCrossSection(ctx, metrics)
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
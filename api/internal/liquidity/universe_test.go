@@ -0,0 +1,91 @@
+package liquidity
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// universeHistory writes a history with four tickers on two dates
+func universeHistory(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), HistoryFileName)
+	var metrics []TickerMetrics
+	for day := 1; day <= 2; day++ {
+		date := time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC)
+		for i, symbol := range []string{"BBOB", "TASC", "BMFI", "IMAP"} {
+			metrics = append(metrics, TickerMetrics{
+				Symbol:      symbol,
+				Date:        date,
+				Window:      Window60,
+				ILLIQ:       float64(i+1) * 1e-9,
+				Value:       float64(4-i) * 50_000_000,
+				Continuity:  1 - float64(i)*0.2,
+				HybridScore: float64(90 - i*20),
+				HybridRank:  i + 1,
+				TradingDays: 60 - i*10,
+				TotalDays:   60,
+			})
+		}
+	}
+	require.NoError(t, AppendHistory(metrics, path, time.Now()))
+	return path
+}
+
+func TestCrossSection(t *testing.T) {
+	path := universeHistory(t)
+
+	points, date, err := CrossSection(path, Window60, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-02", date.Format("2006-01-02"), "zero date picks the latest")
+	require.Len(t, points, 4)
+	assert.Equal(t, "BBOB", points[0].Symbol)
+
+	points, _, err = CrossSection(path, Window60, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, points, 4)
+
+	points, _, err = CrossSection(path, Window20, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, points)
+
+	points, _, err = CrossSection(filepath.Join(t.TempDir(), "missing.csv"), Window60, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, points)
+}
+
+func TestScaleUniverse(t *testing.T) {
+	points, _, err := CrossSection(universeHistory(t), Window60, time.Time{})
+	require.NoError(t, err)
+	calc := NewCalculator(Window60, DefaultPenaltyParams(), DefaultWeights(), nil)
+
+	// The two least liquid tickers on their own: the better one ranks first
+	var universe []TickerMetrics
+	for _, p := range points {
+		if p.Symbol == "BMFI" || p.Symbol == "IMAP" {
+			universe = append(universe, calc.FromHistory(p))
+		}
+	}
+	require.NoError(t, calc.ScaleUniverse(context.Background(), universe))
+
+	ranks := map[string]int{}
+	for _, m := range universe {
+		ranks[m.Symbol] = m.HybridRank
+		assert.Greater(t, m.HybridScore, 0.0, m.Symbol)
+		assert.LessOrEqual(t, m.HybridScore, 100.0, m.Symbol)
+	}
+	assert.Equal(t, map[string]int{"BMFI": 1, "IMAP": 2}, ranks)
+
+	assert.Error(t, calc.ScaleUniverse(context.Background(), universe[:1]), "one ticker cannot be scaled")
+
+	mixed := []TickerMetrics{universe[0], universe[1]}
+	mixed[1].Date = mixed[1].Date.AddDate(0, 0, -1)
+	err = calc.ScaleUniverse(context.Background(), mixed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("mixes dates %s", mixed[0].Date.Format("2006-01-02")))
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
-	"math/rand"
-	"time"
 )
 
 // FitWeights estimates optimal component weights using cross-validation
@@ -39,13 +37,6 @@ func FitWeights(ctx context.Context, impactScores, valueScores, continuityScores
 		return DefaultWeights(), fmt.Errorf("insufficient observations for weight fitting: %d < %d", len(impactScores), config.MinTickers)
 	}
 	
-	// Set up random seed for reproducible results
-	if config.RandomSeed != 0 {
-		rand.Seed(config.RandomSeed)
-	} else {
-		rand.Seed(time.Now().UnixNano())
-	}
-	
 	// Generate weight combinations to test
 	weightCombinations := generateWeightCombinations(config.ParamGridSize)
 	logger.InfoContext(ctx, "generated weight combinations", "count", len(weightCombinations))
@@ -95,7 +86,9 @@ func FitWeights(ctx context.Context, impactScores, valueScores, continuityScores
 	return bestWeights, nil
 }
 
-// evaluateWeightsCV evaluates a weight combination using k-fold cross-validation
+// evaluateWeightsCV evaluates a weight combination using k-fold cross-validation.
+// Folds are contiguous slices of the observations; Calibrate shuffles them
+// with config.RandomSeed beforehand.
 func evaluateWeightsCV(impactScores, valueScores, continuityScores, spreads []float64, weights ComponentWeights, config CalibrationConfig) (float64, error) {
 	n := len(impactScores)
 	foldSize := n / config.KFolds
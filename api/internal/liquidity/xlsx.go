@@ -0,0 +1,387 @@
+package liquidity
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/internal/i18n"
+)
+
+// XLSXSparklineDays is how many of the most recent dates the component
+// sheets of the XLSX report chart
+const XLSXSparklineDays = 60
+
+// Sheet names of the XLSX report
+const (
+	xlsxSummarySheet  = "Summary"
+	xlsxRankingsSheet = "Rankings"
+)
+
+// xlsxComponents are the hybrid score components charted on a sheet each
+var xlsxComponents = []struct {
+	sheet string
+	value func(TickerMetrics) float64
+}{
+	{"ILLIQ", func(m TickerMetrics) float64 { return m.ILLIQScaled }},
+	{"Value", func(m TickerMetrics) float64 { return m.ValueScaled }},
+	{"Continuity", func(m TickerMetrics) float64 { return m.ContinuityScaled }},
+	{"Spread", func(m TickerMetrics) float64 { return m.SpreadScaled }},
+}
+
+// xlsxRankingHeader are the columns of the rankings sheet
+var xlsxRankingHeader = []string{
+	"Rank", "Symbol", "Window", "Hybrid Score",
+	"ILLIQ (Scaled)", "Value (Scaled)", "Continuity (Scaled)", "Spread (Scaled)",
+	"Avg Daily Value (IQD)", "Trading Days", "Data Quality",
+	"Safe Trade 0.5%", "Safe Trade 1%", "Safe Trade 2%", "Optimal Trade",
+	"Percentile", "Quintile", "Decile",
+}
+
+// SaveToXLSX writes the liquidity report as an Excel workbook: the summary
+// report on a cover sheet, the latest date's tickers ranked by hybrid score
+// with a color scale on the score, and a sheet per score component with a
+// sparkline of each ticker's last XLSXSparklineDays dates.
+func SaveToXLSX(metrics []TickerMetrics, outputPath string) error {
+	return SaveLocalizedXLSX(metrics, outputPath, i18n.Locale{})
+}
+
+// SaveLocalizedXLSX writes the workbook of SaveToXLSX with column headers in
+// locale's language, laying its sheets out right to left for Arabic. Cells
+// stay numeric; Excel shows their digits the way the system locale does.
+func SaveLocalizedXLSX(metrics []TickerMetrics, outputPath string, locale i18n.Locale) error {
+	if len(metrics) == 0 {
+		return fmt.Errorf("no metrics to save")
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	header, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#1F4E78"}},
+		Alignment: &excelize.Alignment{Horizontal: "center", WrapText: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create header style: %w", err)
+	}
+
+	if err := f.SetSheetName("Sheet1", xlsxSummarySheet); err != nil {
+		return err
+	}
+	if err := writeXLSXSummary(f, metrics); err != nil {
+		return fmt.Errorf("write summary sheet: %w", err)
+	}
+	if err := writeXLSXRankings(f, metrics, header, locale); err != nil {
+		return fmt.Errorf("write rankings sheet: %w", err)
+	}
+	for _, component := range xlsxComponents {
+		if err := writeXLSXComponent(f, metrics, component.sheet, component.value, header, locale); err != nil {
+			return fmt.Errorf("write %s sheet: %w", component.sheet, err)
+		}
+	}
+	if locale.RightToLeft() {
+		rtl := true
+		for _, sheet := range f.GetSheetList() {
+			if err := f.SetSheetView(sheet, -1, &excelize.ViewOptions{RightToLeft: &rtl}); err != nil {
+				return fmt.Errorf("set %s sheet direction: %w", sheet, err)
+			}
+		}
+	}
+
+	if err := f.SaveAs(outputPath); err != nil {
+		return fmt.Errorf("save XLSX file: %w", err)
+	}
+	return nil
+}
+
+// writeXLSXSummary puts the summary report text on the cover sheet
+func writeXLSXSummary(f *excelize.File, metrics []TickerMetrics) error {
+	var buf bytes.Buffer
+	writeSummaryReport(&buf, metrics)
+
+	title, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return err
+	}
+	heading, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	row := 0
+	for i, line := range lines {
+		// Underlines of the text report become bold headings instead
+		if strings.Trim(line, "-=") == "" && line != "" {
+			continue
+		}
+		row++
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		if err := f.SetCellStr(xlsxSummarySheet, cell, line); err != nil {
+			return err
+		}
+		underlined := i+1 < len(lines) && lines[i+1] != "" && strings.Trim(lines[i+1], "-=") == ""
+		switch {
+		case row == 1:
+			err = f.SetCellStyle(xlsxSummarySheet, cell, cell, title)
+		case underlined:
+			err = f.SetCellStyle(xlsxSummarySheet, cell, cell, heading)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return f.SetColWidth(xlsxSummarySheet, "A", "A", 70)
+}
+
+// latestMetrics returns the metrics of the most recent date ordered by
+// window and rank
+func latestMetrics(metrics []TickerMetrics) []TickerMetrics {
+	var latest time.Time
+	for _, m := range metrics {
+		if m.Date.After(latest) {
+			latest = m.Date
+		}
+	}
+	var rows []TickerMetrics
+	for _, m := range metrics {
+		if m.Date.Equal(latest) {
+			rows = append(rows, m)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Window != rows[j].Window {
+			return rows[i].Window < rows[j].Window
+		}
+		if rows[i].HybridScore != rows[j].HybridScore {
+			return rows[i].HybridScore > rows[j].HybridScore
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+	return rows
+}
+
+// writeXLSXRankings writes the ranked table of the latest date
+func writeXLSXRankings(f *excelize.File, metrics []TickerMetrics, header int, locale i18n.Locale) error {
+	sheet := xlsxRankingsSheet
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	if err := writeXLSXRow(f, sheet, 1, toCells(locale.Headers(xlsxRankingHeader))); err != nil {
+		return err
+	}
+
+	rows := latestMetrics(metrics)
+	for i, m := range rows {
+		rank := m.HybridRank
+		if rank == 0 {
+			rank = i + 1
+		}
+		if err := writeXLSXRow(f, sheet, i+2, []interface{}{
+			rank, m.Symbol, m.Window.String(), m.HybridScore,
+			m.ILLIQScaled, m.ValueScaled, m.ContinuityScaled, m.SpreadScaled,
+			m.Value, m.TradingDays, calculateDataQuality(m),
+			m.SafeValue_0_5, m.SafeValue_1_0, m.SafeValue_2_0, m.OptimalTradeSize,
+			m.PercentileRank, m.Quintile, m.Decile,
+		}); err != nil {
+			return err
+		}
+	}
+
+	last, _ := excelize.CoordinatesToCellName(len(xlsxRankingHeader), len(rows)+1)
+	if err := f.SetCellStyle(sheet, "A1", fmt.Sprintf("%s1", strings.TrimRight(last, "0123456789")), header); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "A", strings.TrimRight(last, "0123456789"), 14); err != nil {
+		return err
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, XSplit: 2, YSplit: 1, TopLeftCell: "C2", ActivePane: "bottomRight"}); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := f.AutoFilter(sheet, "A1:"+last, nil); err != nil {
+		return err
+	}
+
+	numbers, err := f.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+	if err != nil {
+		return err
+	}
+	amounts, err := f.NewStyle(&excelize.Style{NumFmt: 3}) // #,##0
+	if err != nil {
+		return err
+	}
+	lastRow := len(rows) + 1
+	for _, cols := range []struct {
+		from, to string
+		style    int
+	}{
+		{"D", "H", numbers},
+		{"I", "I", amounts},
+		{"L", "O", amounts},
+		{"P", "P", numbers},
+	} {
+		if err := f.SetCellStyle(sheet, cols.from+"2", fmt.Sprintf("%s%d", cols.to, lastRow), cols.style); err != nil {
+			return err
+		}
+	}
+
+	// Red for the least liquid through yellow to green for the most liquid
+	return f.SetConditionalFormat(sheet, fmt.Sprintf("D2:D%d", lastRow), []excelize.ConditionalFormatOptions{{
+		Type:     "3_color_scale",
+		Criteria: "=",
+		MinType:  "min",
+		MidType:  "percentile",
+		MidValue: "50",
+		MaxType:  "max",
+		MinColor: "#F8696B",
+		MidColor: "#FFEB84",
+		MaxColor: "#63BE7B",
+	}})
+}
+
+// writeXLSXComponent writes one component's recent values per ticker, in
+// ranking order, each row led by a sparkline of the values
+func writeXLSXComponent(f *excelize.File, metrics []TickerMetrics, sheet string, value func(TickerMetrics) float64, header int, locale i18n.Locale) error {
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	// The most recent dates, oldest first
+	dateSet := make(map[time.Time]bool)
+	for _, m := range metrics {
+		dateSet[m.Date] = true
+	}
+	dates := make([]time.Time, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	if len(dates) > XLSXSparklineDays {
+		dates = dates[len(dates)-XLSXSparklineDays:]
+	}
+	column := make(map[time.Time]int, len(dates))
+	for i, d := range dates {
+		column[d] = i + 4 // after Symbol, Window and Trend
+	}
+
+	type seriesKey struct {
+		symbol string
+		window Window
+	}
+	series := make(map[seriesKey]map[int]float64)
+	for _, m := range metrics {
+		col, ok := column[m.Date]
+		if !ok {
+			continue
+		}
+		key := seriesKey{m.Symbol, m.Window}
+		if series[key] == nil {
+			series[key] = make(map[int]float64)
+		}
+		series[key][col] = value(m)
+	}
+
+	cells := toCells(locale.Headers([]string{"Symbol", "Window", "Trend"}))
+	for _, d := range dates {
+		cells = append(cells, d.Format("2006-01-02"))
+	}
+	if err := writeXLSXRow(f, sheet, 1, cells); err != nil {
+		return err
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(cells))
+	if err := f.SetCellStyle(sheet, "A1", lastCol+"1", header); err != nil {
+		return err
+	}
+
+	// Tickers in ranking order, then any no longer ranked on the latest date
+	var keys []seriesKey
+	listed := make(map[seriesKey]bool)
+	for _, m := range latestMetrics(metrics) {
+		key := seriesKey{m.Symbol, m.Window}
+		if series[key] != nil && !listed[key] {
+			keys = append(keys, key)
+			listed[key] = true
+		}
+	}
+	var rest []seriesKey
+	for key := range series {
+		if !listed[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if rest[i].symbol != rest[j].symbol {
+			return rest[i].symbol < rest[j].symbol
+		}
+		return rest[i].window < rest[j].window
+	})
+	keys = append(keys, rest...)
+
+	var locations, ranges []string
+	firstData, _ := excelize.ColumnNumberToName(4)
+	for i, key := range keys {
+		row := i + 2
+		cells := make([]interface{}, len(dates)+3)
+		cells[0], cells[1] = key.symbol, key.window.String()
+		for col, v := range series[key] {
+			cells[col-1] = v
+		}
+		if err := writeXLSXRow(f, sheet, row, cells); err != nil {
+			return err
+		}
+		locations = append(locations, fmt.Sprintf("C%d", row))
+		ranges = append(ranges, fmt.Sprintf("%s!%s%d:%s%d", sheet, firstData, row, lastCol, row))
+	}
+
+	if err := f.SetColWidth(sheet, "C", "C", 24); err != nil {
+		return err
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, XSplit: 3, YSplit: 1, TopLeftCell: "D2", ActivePane: "bottomRight"}); err != nil {
+		return err
+	}
+	if len(keys) == 0 || len(dates) < 2 {
+		return nil
+	}
+	return f.AddSparkline(sheet, &excelize.SparklineOptions{
+		Location: locations,
+		Range:    ranges,
+		Markers:  true,
+		High:     true,
+		Low:      true,
+	})
+}
+
+// writeXLSXRow writes cells from column A of a row; nil cells stay empty
+func writeXLSXRow(f *excelize.File, sheet string, row int, cells []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, row)
+	if err != nil {
+		return err
+	}
+	return f.SetSheetRow(sheet, cell, &cells)
+}
+
+func toCells(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return cells
+}
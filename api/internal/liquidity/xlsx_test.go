@@ -0,0 +1,106 @@
+package liquidity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/internal/i18n"
+)
+
+func TestSaveToXLSX(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var metrics []TickerMetrics
+	for day := 0; day < 3; day++ {
+		for i, symbol := range []string{"BBOB", "TASC"} {
+			metrics = append(metrics, TickerMetrics{
+				Symbol:           symbol,
+				Date:             start.AddDate(0, 0, day),
+				Window:           Window60,
+				HybridScore:      float64(40 + 10*i + day),
+				HybridRank:       2 - i,
+				ILLIQScaled:      float64(50 + day),
+				ValueScaled:      float64(60 + day),
+				ContinuityScaled: float64(70 + day),
+				SpreadScaled:     float64(80 + day),
+				TradingDays:      55,
+				TotalDays:        60,
+			})
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "report.xlsx")
+	require.NoError(t, SaveToXLSX(metrics, path))
+
+	f, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, []string{"Summary", "Rankings", "ILLIQ", "Value", "Continuity", "Spread"}, f.GetSheetList())
+
+	rows, err := f.GetRows("Rankings")
+	require.NoError(t, err)
+	require.Len(t, rows, 3, "header and the latest date's tickers")
+	assert.Equal(t, "Rank", rows[0][0])
+	assert.Equal(t, []string{"1", "TASC"}, rows[1][:2])
+	assert.Equal(t, []string{"2", "BBOB"}, rows[2][:2])
+
+	formats, err := f.GetConditionalFormats("Rankings")
+	require.NoError(t, err)
+	require.Contains(t, formats, "D2:D3")
+	assert.Equal(t, "3_color_scale", formats["D2:D3"][0].Type)
+
+	rows, err = f.GetRows("Value")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"Symbol", "Window", "Trend", "2025-01-01", "2025-01-02", "2025-01-03"}, rows[0])
+	assert.Equal(t, []string{"TASC", "60d", "", "60", "61", "62"}, rows[1])
+
+	title, err := f.GetCellValue("Summary", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "ISX Hybrid Liquidity Metric - Summary Report", title)
+}
+
+func TestSaveLocalizedXLSX(t *testing.T) {
+	metrics := []TickerMetrics{{
+		Symbol:      "BBOB",
+		Date:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Window:      Window60,
+		HybridScore: 42.5,
+		HybridRank:  1,
+		TradingDays: 55,
+		TotalDays:   60,
+	}}
+
+	path := filepath.Join(t.TempDir(), "report.xlsx")
+	require.NoError(t, SaveLocalizedXLSX(metrics, path, i18n.Locale{Language: i18n.Arabic}))
+
+	f, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows("Rankings")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "الترتيب", rows[0][0])
+	assert.Equal(t, []string{"1", "BBOB"}, rows[1][:2], "cells stay numeric")
+
+	rows, err = f.GetRows("Value")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"الرمز", "النافذة", "الاتجاه"}, rows[0][:3])
+
+	for _, sheet := range f.GetSheetList() {
+		view, err := f.GetSheetView(sheet, -1)
+		require.NoError(t, err)
+		require.NotNil(t, view.RightToLeft, sheet)
+		assert.True(t, *view.RightToLeft, sheet)
+	}
+}
+
+func TestSaveToXLSXRequiresMetrics(t *testing.T) {
+	assert.Error(t, SaveToXLSX(nil, filepath.Join(t.TempDir(), "report.xlsx")))
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"isxcli/internal/audit"
+	"isxcli/internal/config"
+)
+
+// AuditRecorder persists audit entries; audit.Log implements it
+type AuditRecorder interface {
+	Record(entry audit.Entry) error
+}
+
+// Audit records every request that changes state (anything but GET, HEAD and
+// OPTIONS): the caller resolved by Authenticate, the route, a SHA-256 of the
+// payload and the response status. The payload itself is not kept, and sign-in
+// and account payloads are not digested since they carry passwords. Place it
+// after the body size limit, since the rest of a body the handler left
+// unread is drained to complete the digest.
+func Audit(recorder AuditRecorder, logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			var payload *payloadDigest
+			if r.Body != nil && r.Body != http.NoBody && audit.ActionFor(r.URL.Path) != audit.ActionAuth {
+				payload = &payloadDigest{body: r.Body, hash: sha256.New()}
+				r.Body = payload
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" && !strings.HasSuffix(pattern, "*") {
+					route = pattern
+					if len(route) > 1 {
+						route = strings.TrimSuffix(route, "/")
+					}
+				}
+			}
+
+			user := UserFromContext(r.Context())
+			entry := audit.Entry{
+				Time:       start,
+				RequestID:  GetReqID(r.Context()),
+				User:       user.Name,
+				Role:       string(user.Role),
+				KeyID:      tokenFingerprint(requestToken(r)),
+				RemoteAddr: r.RemoteAddr,
+				Profile:    config.ProfileFromContext(r.Context()),
+				Action:     audit.ActionFor(route),
+				Method:     r.Method,
+				Route:      route,
+				Path:       r.URL.Path,
+				Status:     status,
+				Result:     audit.ResultFor(status),
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+			if payload != nil {
+				entry.PayloadSHA256, entry.PayloadBytes = payload.finish()
+			}
+			if err := recorder.Record(entry); err != nil {
+				logger.ErrorContext(r.Context(), "Failed to record audit entry",
+					slog.String("error", err.Error()),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path))
+			}
+		})
+	}
+}
+
+// tokenFingerprint identifies an API token in the audit log without
+// revealing it
+func tokenFingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:6])
+}
+
+// payloadDigest hashes a request body as the handler reads it. Reads are
+// serialized because a handler outliving its timeout may still be reading
+// when finish drains the body.
+type payloadDigest struct {
+	mu   sync.Mutex
+	body io.ReadCloser
+	hash hash.Hash
+	n    int64
+}
+
+func (p *payloadDigest) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n, err := p.body.Read(b)
+	p.hash.Write(b[:n])
+	p.n += int64(n)
+	return n, err
+}
+
+func (p *payloadDigest) Close() error {
+	return p.body.Close()
+}
+
+// finish hashes what the handler left unread and returns the digest and size
+// of the whole payload
+func (p *payloadDigest) finish() (string, int64) {
+	io.Copy(io.Discard, p)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.n == 0 {
+		return "", 0
+	}
+	return hex.EncodeToString(p.hash.Sum(nil)), p.n
+}
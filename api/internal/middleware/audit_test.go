@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/audit"
+)
+
+type recordedAudit struct {
+	mu      sync.Mutex
+	entries []audit.Entry
+}
+
+func (r *recordedAudit) Record(entry audit.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	recorder := &recordedAudit{}
+	ac := newTestAccessControl(t, testUsers(t))
+
+	operations := chi.NewRouter()
+	operations.Post("/start", func(w http.ResponseWriter, r *http.Request) {
+		// Reads only part of the body; the digest still covers all of it
+		io.ReadFull(r.Body, make([]byte, 4))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	operations.Post("/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {})
+	operations.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	r := chi.NewRouter()
+	r.Use(ac.Authenticate)
+	r.Route("/api", func(r chi.Router) {
+		r.Use(Audit(recorder, nil))
+		r.With(ac.RequireRole(RoleOperator)).Mount("/operations", operations)
+	})
+
+	send := func(method, path, body, token string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("X-API-Key", token)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	payload := `{"mode":"initial"}`
+	send(http.MethodPost, "/api/operations/start", payload, operatorToken)
+	send(http.MethodPost, "/api/operations/abc/cancel", "", viewerToken)
+	send(http.MethodGet, "/api/operations", "", adminToken)
+
+	require.Len(t, recorder.entries, 2, "reads are not audited")
+
+	start := recorder.entries[0]
+	sum := sha256.Sum256([]byte(payload))
+	assert.Equal(t, "olga", start.User)
+	assert.Equal(t, "operator", start.Role)
+	assert.Len(t, start.KeyID, 12)
+	assert.NotContains(t, operatorToken, start.KeyID, "the fingerprint does not reveal the token")
+	assert.Equal(t, audit.ActionOperations, start.Action)
+	assert.Equal(t, "/api/operations/start", start.Route)
+	assert.Equal(t, hex.EncodeToString(sum[:]), start.PayloadSHA256)
+	assert.Equal(t, int64(len(payload)), start.PayloadBytes)
+	assert.Equal(t, http.StatusAccepted, start.Status)
+	assert.Equal(t, audit.ResultSuccess, start.Result)
+	assert.False(t, start.Time.IsZero())
+
+	cancel := recorder.entries[1]
+	assert.Equal(t, "victor", cancel.User)
+	assert.Equal(t, "/api/operations/abc/cancel", cancel.Route, "denied before routing, so the path stands in")
+	assert.Equal(t, audit.ActionOperations, cancel.Action)
+	assert.Empty(t, cancel.PayloadSHA256)
+	assert.Equal(t, http.StatusForbidden, cancel.Status)
+	assert.Equal(t, audit.ResultDenied, cancel.Result)
+}
+
+func TestAuditSkipsCredentialPayloads(t *testing.T) {
+	recorder := &recordedAudit{}
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(Audit(recorder, nil))
+		r.Post("/auth/login", func(w http.ResponseWriter, r *http.Request) { io.ReadAll(r.Body) })
+		r.Post("/v1/auth/accounts", func(w http.ResponseWriter, r *http.Request) { io.ReadAll(r.Body) })
+	})
+
+	for _, path := range []string{"/api/auth/login", "/api/v1/auth/accounts"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"username":"alice","password":"correct horse"}`))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, recorder.entries, 2)
+	for _, entry := range recorder.entries {
+		assert.Equal(t, audit.ActionAuth, entry.Action)
+		assert.Empty(t, entry.PayloadSHA256, "a digest of a password is easy to crack")
+		assert.Zero(t, entry.PayloadBytes)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	apierrors "isxcli/internal/errors"
 	"isxcli/internal/infrastructure"
 )
 
@@ -90,6 +91,11 @@ func (ew *errorWriter) Error(err error) {
 	ew.err = err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer
+func (ew *errorWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
 // handleError converts errors to RFC 7807 problem responses
 func handleError(w http.ResponseWriter, r *http.Request, err error, logger *slog.Logger) {
 	ctx := r.Context()
@@ -174,6 +180,17 @@ func mapErrorToProblem(err error, traceID string) Problem {
 		}
 	}
 	
+	// Domain errors carry their kind
+	if p, ok := apierrors.KindToProblem(err, ""); ok {
+		return Problem{
+			Type:   p.Type,
+			Title:  p.Title,
+			Status: p.Status,
+			Detail: p.Detail,
+			Trace:  traceID,
+		}
+	}
+	
 	// Check for validation errors
 	if strings.Contains(strings.ToLower(err.Error()), "validation") {
 		return Problem{
@@ -1,7 +1,20 @@
 package middleware
 
+import (
+	"context"
+
+	"isxcli/internal/license"
+)
+
 // LicenseManagerInterface defines the interface for license validation
 // This allows for easier testing and decoupling from the concrete implementation
 type LicenseManagerInterface interface {
 	ValidateLicense() (bool, error)
-}
\ No newline at end of file
+}
+
+// LicenseSnapshotProvider supplies shared license validation snapshots.
+// services.LicenseService implements it and invalidates snapshots on
+// activation, transfer and status transitions.
+type LicenseSnapshotProvider interface {
+	Snapshot(ctx context.Context) *license.StatusSnapshot
+}
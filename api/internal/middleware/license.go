@@ -17,11 +17,13 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"isxcli/internal/errors"
 	"isxcli/internal/infrastructure"
+	"isxcli/internal/license"
 )
 
 // LicenseValidator provides license validation middleware with enhanced security and caching
 type LicenseValidator struct {
 	manager         LicenseManagerInterface
+	snapshots       LicenseSnapshotProvider
 	logger          *slog.Logger
 	cache           *validationCache
 	excludePaths    []string
@@ -83,10 +85,12 @@ func NewLicenseValidator(manager LicenseManagerInterface, logger *slog.Logger) *
 			"/api/license/transfer",
 			"/api/license/metrics",
 			"/api/license/invalidate-cache",
+			"/api/v1/license/fleet/device", // unlicensed machines report themselves for fleet activation
 			"/api/health",
 			"/api/health/ready",
 			"/api/health/live",
 			"/api/version",
+			"/api/v1/system/version",
 			"/ws",
 			"/metrics",
 			"/favicon.ico",
@@ -167,6 +171,12 @@ func (lv *LicenseValidator) Handler(next http.Handler) http.Handler {
 			return
 		}
 
+		// Shared snapshots replace the middleware-local cache when configured
+		if lv.snapshots != nil {
+			lv.serveWithSnapshot(ctx, w, r, next, span, traceID)
+			return
+		}
+
 		// Check cached validation result with enhanced metadata
 		if lv.isCacheValid() {
 			span.SetAttributes(
@@ -299,6 +309,49 @@ func (lv *LicenseValidator) Handler(next http.Handler) http.Handler {
 	})
 }
 
+// serveWithSnapshot validates using the shared license snapshot and attaches it to
+// the request context so handlers can reuse it without validating again
+func (lv *LicenseValidator) serveWithSnapshot(ctx context.Context, w http.ResponseWriter, r *http.Request, next http.Handler, span trace.Span, traceID string) {
+	start := time.Now()
+	snapshot := lv.snapshots.Snapshot(ctx)
+	cached := snapshot.CheckedAt.Before(start)
+
+	span.SetAttributes(
+		attribute.String("license.validation", "snapshot"),
+		attribute.Bool("cache.hit", cached),
+		attribute.Bool("license.valid", snapshot.Valid),
+	)
+
+	if lv.metrics != nil {
+		if cached {
+			lv.metrics.CacheHits.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("component", "license_middleware"),
+			))
+		} else {
+			lv.metrics.CacheMisses.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("component", "license_middleware"),
+			))
+		}
+	}
+
+	if snapshot.Err != nil {
+		span.RecordError(snapshot.Err)
+		span.SetAttributes(attribute.String("error.type", classifyValidationError(snapshot.Err)))
+		lv.handleValidationError(w, r, snapshot.Err, traceID)
+		return
+	}
+
+	if !snapshot.Valid {
+		lv.logger.WarnContext(ctx, "license validation failed",
+			slog.String("path", r.URL.Path),
+			slog.String("trace_id", traceID))
+		lv.handleInvalidLicense(w, r, traceID)
+		return
+	}
+
+	next.ServeHTTP(w, r.WithContext(license.WithSnapshot(r.Context(), snapshot)))
+}
+
 // shouldExcludePath checks if a path should be excluded from validation
 func (lv *LicenseValidator) shouldExcludePath(path string) bool {
 	// Check exact matches
@@ -411,6 +464,12 @@ func (lv *LicenseValidator) AddExcludePrefix(prefix string) {
 	lv.excludePrefixes = append(lv.excludePrefixes, prefix)
 }
 
+// SetSnapshotProvider makes the middleware use shared license snapshots instead of
+// its own validation cache
+func (lv *LicenseValidator) SetSnapshotProvider(provider LicenseSnapshotProvider) {
+	lv.snapshots = provider
+}
+
 // SetCacheTTL sets the cache time-to-live duration
 func (lv *LicenseValidator) SetCacheTTL(ttl time.Duration) {
 	lv.cache.mu.Lock()
@@ -16,6 +16,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/assert"
+	"isxcli/internal/license"
 )
 
 // mockLicenseManager is a mock implementation of license.Manager for testing
@@ -32,6 +33,20 @@ func (m *mockLicenseManager) ValidateLicense() (bool, error) {
 
 // Other methods would be implemented as needed for the interface
 
+// mockSnapshotProvider returns a fixed snapshot and counts calls
+type mockSnapshotProvider struct {
+	mu       sync.Mutex
+	snapshot *license.StatusSnapshot
+	calls    int
+}
+
+func (m *mockSnapshotProvider) Snapshot(ctx context.Context) *license.StatusSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	return m.snapshot
+}
+
 // TestLicenseValidator tests the license validation middleware
 func TestLicenseValidator(t *testing.T) {
 	// Create a test logger
@@ -1111,4 +1126,68 @@ func BenchmarkLicenseValidator_CachedValidation(b *testing.B) {
 		rec := httptest.NewRecorder()
 		handler.ServeHTTP(rec, req)
 	}
-}
\ No newline at end of file
+}
+func TestLicenseValidator_SnapshotProvider(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mockManager := &mockLicenseManager{
+		validateFunc: func() (bool, error) {
+			t.Fatal("manager must not be called when a snapshot provider is set")
+			return false, nil
+		},
+	}
+
+	t.Run("valid snapshot is attached to the request", func(t *testing.T) {
+		provider := &mockSnapshotProvider{snapshot: &license.StatusSnapshot{Valid: true, CheckedAt: time.Now()}}
+		validator := NewLicenseValidator(mockManager, logger)
+		validator.SetSnapshotProvider(provider)
+
+		var fromCtx *license.StatusSnapshot
+		handler := validator.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fromCtx, _ = license.SnapshotFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/data/reports", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Same(t, provider.snapshot, fromCtx)
+		assert.Equal(t, 1, provider.calls)
+	})
+
+	t.Run("invalid snapshot is rejected", func(t *testing.T) {
+		provider := &mockSnapshotProvider{snapshot: &license.StatusSnapshot{Valid: false, CheckedAt: time.Now()}}
+		validator := NewLicenseValidator(mockManager, logger)
+		validator.SetSnapshotProvider(provider)
+
+		handler := validator.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("next handler must not run")
+		}))
+
+		req := httptest.NewRequest("GET", "/api/data/reports", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.NotEqual(t, http.StatusOK, rec.Code)
+	})
+}
+
+func BenchmarkLicenseValidator_SnapshotProvider(b *testing.B) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	validator := NewLicenseValidator(&mockLicenseManager{}, logger)
+	validator.SetSnapshotProvider(&mockSnapshotProvider{
+		snapshot: &license.StatusSnapshot{Valid: true, CheckedAt: time.Now().Add(-time.Second)},
+	})
+
+	handler := validator.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("GET", "/protected", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+	}
+}
@@ -145,6 +145,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, so
+// streaming handlers can flush and extend write deadlines
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // getRoutePattern extracts the route pattern from request context
 func getRoutePattern(r *http.Request) string {
 	rctx := chi.RouteContext(r.Context())
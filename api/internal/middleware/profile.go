@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+	"isxcli/internal/config"
+	"isxcli/internal/errors"
+)
+
+// Profile reads the X-ISX-Profile header and stores the selected data profile
+// in the request context. Requests without the header use the process profile.
+func Profile(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSpace(r.Header.Get(config.ProfileHeader))
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := config.ValidateProfileName(name); err != nil {
+			reqID := GetReqID(r.Context())
+			problem := errors.NewProblemDetails(
+				http.StatusBadRequest,
+				"/errors/invalid-profile",
+				"Invalid Profile",
+				err.Error(),
+				fmt.Sprintf("%s#%s", r.URL.Path, reqID),
+			).WithExtension("trace_id", reqID)
+
+			render.Render(w, r, problem)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(config.WithProfile(r.Context(), name)))
+	})
+}
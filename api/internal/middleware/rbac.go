@@ -0,0 +1,316 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	apierrors "isxcli/internal/errors"
+)
+
+// Role is a user's access level. Each role includes the rights of the roles
+// below it: admin > operator > viewer.
+type Role string
+
+const (
+	// RoleViewer reads data and reports
+	RoleViewer Role = "viewer"
+	// RoleOperator also starts, stops and resumes operations
+	RoleOperator Role = "operator"
+	// RoleAdmin also changes system settings such as notification channels
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether r carries the rights of required
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// User is an entry of the users file
+type User struct {
+	Name  string `yaml:"name" json:"name"`
+	Role  Role   `yaml:"role" json:"role"`
+	Token string `yaml:"token" json:"-"`
+}
+
+// Users maps API tokens to users. Tokens are kept as SHA-256 digests.
+type Users struct {
+	byToken map[[sha256.Size]byte]User
+}
+
+// usersFile is the layout of the users file
+type usersFile struct {
+	Users []User `yaml:"users"`
+}
+
+// LoadUsers reads a YAML users file:
+//
+//	users:
+//	  - name: alice
+//	    role: admin
+//	    token: "long-random-string"
+func LoadUsers(path string) (*Users, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %w", err)
+	}
+	var file usersFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse users file %s: %w", path, err)
+	}
+	return NewUsers(file.Users)
+}
+
+// NewUsers validates users and indexes them by token. Names and tokens must be
+// unique and every role must be known.
+func NewUsers(list []User) (*Users, error) {
+	users := &Users{byToken: make(map[[sha256.Size]byte]User, len(list))}
+	names := make(map[string]bool, len(list))
+	for i, user := range list {
+		switch {
+		case user.Name == "":
+			return nil, fmt.Errorf("user %d has no name", i+1)
+		case names[user.Name]:
+			return nil, fmt.Errorf("user %q is listed twice", user.Name)
+		case !user.Role.Valid():
+			return nil, fmt.Errorf("user %q has unknown role %q (want admin, operator or viewer)", user.Name, user.Role)
+		case len(user.Token) < 16:
+			return nil, fmt.Errorf("user %q needs a token of at least 16 characters", user.Name)
+		}
+		digest := sha256.Sum256([]byte(user.Token))
+		if _, dup := users.byToken[digest]; dup {
+			return nil, fmt.Errorf("user %q shares a token with another user", user.Name)
+		}
+		names[user.Name] = true
+		users.byToken[digest] = User{Name: user.Name, Role: user.Role}
+	}
+	return users, nil
+}
+
+// Lookup returns the user owning token
+func (u *Users) Lookup(token string) (User, bool) {
+	user, ok := u.byToken[sha256.Sum256([]byte(token))]
+	return user, ok
+}
+
+// Len returns the number of users
+func (u *Users) Len() int {
+	return len(u.byToken)
+}
+
+type accessContextKey struct{}
+
+// anonymousUser is the caller of a request without a token
+var anonymousUser = User{Name: "anonymous", Role: RoleViewer}
+
+// SessionResolver resolves the signed-in user of a request, e.g. from a
+// session cookie
+type SessionResolver interface {
+	ResolveSession(r *http.Request) (User, bool)
+}
+
+// AccessControl enforces user roles. Callers identify themselves with
+// "Authorization: Bearer <token>" or "X-API-Key: <token>", or with a web UI
+// session; requests with neither are served as an anonymous viewer. Without
+// users or sessions, access control is disabled and every caller is treated
+// as an admin.
+type AccessControl struct {
+	users         *Users
+	sessions      SessionResolver
+	loginRequired bool
+	logger        *slog.Logger
+	errorHandler  *apierrors.ErrorHandler
+}
+
+// NewAccessControl creates role enforcement for users; nil users disables it
+func NewAccessControl(users *Users, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *AccessControl {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AccessControl{
+		users:        users,
+		logger:       logger.With(slog.String("component", "access_control")),
+		errorHandler: errorHandler,
+	}
+}
+
+// SetSessions accepts the users signed in through sessions, which enables
+// access control. With required set, RequireLogin turns away anonymous
+// callers.
+func (ac *AccessControl) SetSessions(sessions SessionResolver, required bool) {
+	ac.sessions = sessions
+	ac.loginRequired = required
+}
+
+// Enabled reports whether roles are enforced; a nil AccessControl enforces nothing
+func (ac *AccessControl) Enabled() bool {
+	return ac != nil && (ac.users != nil || ac.sessions != nil)
+}
+
+// Authenticate resolves the caller and stores it for RequireRole and
+// UserFromContext. A token takes precedence over a session cookie; an
+// unknown token is rejected with 401.
+func (ac *AccessControl) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ac.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user := anonymousUser
+		if token := requestToken(r); token != "" {
+			var known User
+			ok := false
+			if ac.users != nil {
+				known, ok = ac.users.Lookup(token)
+			}
+			if !ok {
+				ac.logger.WarnContext(r.Context(), "Rejected unknown API token",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("remote_addr", r.RemoteAddr))
+				ac.errorHandler.HandleError(w, r, apierrors.New(http.StatusUnauthorized, "UNAUTHORIZED", "Invalid API token"))
+				return
+			}
+			user = known
+		} else if ac.sessions != nil {
+			if signedIn, ok := ac.sessions.ResolveSession(r); ok {
+				user = signedIn
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), accessContextKey{}, user)))
+	})
+}
+
+// RequireLogin rejects anonymous callers with 401 when sign-in is required,
+// except on paths starting with one of exempt, such as the login endpoints
+func (ac *AccessControl) RequireLogin(exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !ac.Enabled() || !ac.loginRequired || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			for _, prefix := range exempt {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if user, ok := r.Context().Value(accessContextKey{}).(User); ok && user != anonymousUser {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ac.errorHandler.HandleError(w, r, apierrors.New(http.StatusUnauthorized, "LOGIN_REQUIRED", "Sign in or send an API token to use this server"))
+		})
+	}
+}
+
+// RequireRole rejects requests that change state (anything but GET, HEAD and
+// OPTIONS) unless the caller has at least the required role; reads stay open
+// to viewers.
+func (ac *AccessControl) RequireRole(required Role) func(http.Handler) http.Handler {
+	return ac.requireRole(required, false)
+}
+
+// RestrictTo rejects every request, reads included, unless the caller has at
+// least the required role. It guards endpoints whose data itself is
+// sensitive, such as the audit log.
+func (ac *AccessControl) RestrictTo(required Role) func(http.Handler) http.Handler {
+	return ac.requireRole(required, true)
+}
+
+// RequireEnabled rejects every request with 403 while access control is
+// disabled. RestrictTo lets everyone through in that case, so endpoints that
+// must never be open to any licensed client, such as ad-hoc queries, chain
+// both.
+func (ac *AccessControl) RequireEnabled(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ac.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		errorHandler := apierrors.NewErrorHandler(slog.Default(), false)
+		if ac != nil {
+			errorHandler = ac.errorHandler
+		}
+		errorHandler.HandleError(w, r, apierrors.New(http.StatusForbidden, "ACCESS_CONTROL_DISABLED",
+			"This endpoint requires security.users_file or auth sign-in to be configured"))
+	})
+}
+
+func (ac *AccessControl) requireRole(required Role, reads bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				if !reads {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if !ac.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Without Authenticate on the chain the caller is anonymous
+			user, ok := r.Context().Value(accessContextKey{}).(User)
+			if !ok {
+				user = anonymousUser
+			}
+			if user.Role.Allows(required) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ac.logger.WarnContext(r.Context(), "Denied request for insufficient role",
+				slog.String("user", user.Name),
+				slog.String("role", string(user.Role)),
+				slog.String("required_role", string(required)),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path))
+			if user == anonymousUser {
+				ac.errorHandler.HandleError(w, r, apierrors.NewWithDetails(http.StatusUnauthorized, "UNAUTHORIZED",
+					"This action requires signing in or an API token", map[string]string{"required_role": string(required)}))
+				return
+			}
+			ac.errorHandler.HandleError(w, r, apierrors.NewWithDetails(http.StatusForbidden, "FORBIDDEN",
+				fmt.Sprintf("This action requires the %s role", required),
+				map[string]string{"role": string(user.Role), "required_role": string(required)}))
+		})
+	}
+}
+
+// UserFromContext returns the caller resolved by Authenticate. Without access
+// control every caller is an admin.
+func UserFromContext(ctx context.Context) User {
+	if user, ok := ctx.Value(accessContextKey{}).(User); ok {
+		return user
+	}
+	return User{Name: "local", Role: RoleAdmin}
+}
+
+// requestToken reads the API token from the Authorization or X-API-Key header
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
@@ -0,0 +1,531 @@
+package middleware
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+)
+
+// UploadKind identifies a file format an upload endpoint accepts
+type UploadKind string
+
+const (
+	UploadKindXLSX UploadKind = "xlsx"
+	UploadKindCSV  UploadKind = "csv"
+	UploadKindJSON UploadKind = "json"
+)
+
+// uploadContentTypes lists the declared media types accepted for each kind.
+// Browsers label CSV files inconsistently (Windows reports Excel's type), so
+// the content is always sniffed as well.
+var uploadContentTypes = map[UploadKind][]string{
+	UploadKindXLSX: {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/octet-stream", "application/zip"},
+	UploadKindCSV:  {"text/csv", "application/csv", "text/plain", "application/vnd.ms-excel", "application/octet-stream"},
+	UploadKindJSON: {"application/json"},
+}
+
+// uploadExtensions lists the file name extensions accepted for each kind
+var uploadExtensions = map[UploadKind]string{
+	UploadKindXLSX: ".xlsx",
+	UploadKindCSV:  ".csv",
+	UploadKindJSON: ".json",
+}
+
+// UploadPolicy configures what a guarded endpoint accepts
+type UploadPolicy struct {
+	// MaxSize is the largest accepted file or body in bytes
+	MaxSize int64
+	// Kinds are the accepted formats; the content must match one of them
+	Kinds []UploadKind
+	// FormField is the multipart field holding the file. Requests that are not
+	// multipart/form-data are checked as a raw body.
+	FormField string
+}
+
+// Upload is a validated upload, stored in the request context by UploadGuard
+type Upload struct {
+	Filename    string     `json:"filename,omitempty"`
+	Kind        UploadKind `json:"kind"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Data        []byte     `json:"-"`
+}
+
+type uploadContextKey struct{}
+
+// UploadFromContext returns the upload validated by UploadGuard
+func UploadFromContext(ctx context.Context) (*Upload, bool) {
+	upload, ok := ctx.Value(uploadContextKey{}).(*Upload)
+	return upload, ok
+}
+
+// uploadRejection describes why an upload was refused
+type uploadRejection struct {
+	status       int
+	reason       string
+	filename     string
+	declaredType string
+	detected     string
+	size         int64
+	data         []byte
+}
+
+// Quarantine budget per profile. Anonymous callers can reach guarded
+// endpoints, so the oldest entries are pruned to keep the directory bounded.
+const (
+	quarantineMaxBytes   = 64 << 20
+	quarantineMaxEntries = 100
+)
+
+// UploadGuard enforces size limits, content types and format validation on
+// upload endpoints. Rejected content is written to the quarantine directory of
+// the request's profile for inspection instead of being discarded.
+type UploadGuard struct {
+	paths        *config.Paths
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+	now          func() time.Time
+
+	mu         sync.Mutex // Serializes quarantine writes and pruning
+	maxBytes   int64
+	maxEntries int
+}
+
+// NewUploadGuard creates an upload guard. Nil paths disable quarantine.
+func NewUploadGuard(paths *config.Paths, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *UploadGuard {
+	return &UploadGuard{
+		paths:        paths,
+		logger:       logger.With(slog.String("component", "upload_guard")),
+		errorHandler: errorHandler,
+		now:          time.Now,
+		maxBytes:     quarantineMaxBytes,
+		maxEntries:   quarantineMaxEntries,
+	}
+}
+
+// Limit returns middleware enforcing the policy on requests with a body.
+// Accepted uploads are available through UploadFromContext; raw bodies are
+// also replayed to the handler so JSON decoding keeps working.
+func (g *UploadGuard) Limit(policy UploadPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			upload, rejection := g.read(w, r, policy)
+			if rejection != nil {
+				g.reject(w, r, policy, rejection)
+				return
+			}
+
+			if !isMultipart(r) {
+				r.Body = io.NopCloser(bytes.NewReader(upload.Data))
+				r.ContentLength = upload.Size
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), uploadContextKey{}, upload)))
+		})
+	}
+}
+
+// MaxBody returns middleware capping the body of every request at max bytes.
+// A declared Content-Length over the limit is refused with 413 up front; a
+// body that turns out larger fails the handler's read instead. Zero or less
+// disables the limit.
+func (g *UploadGuard) MaxBody(max int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > max {
+				g.logger.Warn("Request body too large",
+					slog.String("request_id", GetReqID(r.Context())),
+					slog.String("path", r.URL.Path),
+					slog.Int64("size", r.ContentLength),
+					slog.Int64("max_size", max))
+				g.errorHandler.HandleError(w, r, apierrors.PayloadTooLargeError(map[string]interface{}{
+					"reason":   "body exceeds the size limit",
+					"max_size": max,
+					"size":     r.ContentLength,
+				}))
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, max)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// read extracts the file or body and validates it against the policy
+func (g *UploadGuard) read(w http.ResponseWriter, r *http.Request, policy UploadPolicy) (*Upload, *uploadRejection) {
+	if r.ContentLength > policy.MaxSize && !isMultipart(r) {
+		return nil, &uploadRejection{status: http.StatusRequestEntityTooLarge, reason: "body exceeds the size limit", size: r.ContentLength}
+	}
+
+	var (
+		data         []byte
+		filename     string
+		declaredType string
+		err          error
+	)
+	if isMultipart(r) {
+		data, filename, declaredType, err = readMultipartFile(w, r, policy)
+	} else {
+		declaredType = r.Header.Get("Content-Type")
+		data, err = readLimited(r.Body, policy.MaxSize)
+	}
+	if errors.Is(err, errTooLarge) {
+		return nil, &uploadRejection{status: http.StatusRequestEntityTooLarge, reason: "file exceeds the size limit", filename: filename, size: r.ContentLength}
+	}
+	if err != nil {
+		return nil, &uploadRejection{status: http.StatusUnsupportedMediaType, reason: err.Error(), filename: filename, declaredType: declaredType}
+	}
+
+	// An empty body has nothing to validate; the handler reports it as a bad request
+	if len(data) == 0 && !isMultipart(r) {
+		return &Upload{ContentType: declaredType}, nil
+	}
+
+	rejection := &uploadRejection{
+		status:       http.StatusUnsupportedMediaType,
+		filename:     filename,
+		declaredType: declaredType,
+		size:         int64(len(data)),
+		data:         data,
+	}
+
+	kind, ok := detectUploadKind(data, policy.Kinds)
+	if !ok {
+		rejection.detected = http.DetectContentType(data)
+		rejection.reason = "content does not match an accepted format"
+		return nil, rejection
+	}
+	rejection.detected = string(kind)
+
+	mediaType, _, _ := mime.ParseMediaType(declaredType)
+	if !containsString(uploadContentTypes[kind], mediaType) {
+		rejection.reason = fmt.Sprintf("declared content type %q does not match %s content", declaredType, kind)
+		return nil, rejection
+	}
+	if filename != "" && !strings.EqualFold(filepath.Ext(filename), uploadExtensions[kind]) {
+		rejection.reason = fmt.Sprintf("file name %q does not have the %s extension", filename, uploadExtensions[kind])
+		return nil, rejection
+	}
+
+	return &Upload{
+		Filename:    filename,
+		Kind:        kind,
+		ContentType: mediaType,
+		Size:        int64(len(data)),
+		Data:        data,
+	}, nil
+}
+
+// reject quarantines the content, if any was read, and writes a 413 or 415 error
+func (g *UploadGuard) reject(w http.ResponseWriter, r *http.Request, policy UploadPolicy, rejection *uploadRejection) {
+	allowed := make([]string, len(policy.Kinds))
+	for i, kind := range policy.Kinds {
+		allowed[i] = string(kind)
+	}
+	details := map[string]interface{}{
+		"reason":   rejection.reason,
+		"max_size": policy.MaxSize,
+		"allowed":  allowed,
+	}
+	if rejection.size > 0 {
+		details["size"] = rejection.size
+	}
+	if rejection.filename != "" {
+		details["filename"] = rejection.filename
+	}
+	if rejection.declaredType != "" {
+		details["declared_type"] = rejection.declaredType
+	}
+	if rejection.detected != "" {
+		details["detected_type"] = rejection.detected
+	}
+
+	if len(rejection.data) > 0 && g.paths != nil {
+		id, err := g.quarantine(r, rejection)
+		if err != nil {
+			g.logger.Warn("Failed to quarantine rejected upload", slog.String("error", err.Error()))
+		} else if id != "" {
+			details["quarantine_id"] = id
+		}
+	}
+
+	g.logger.Warn("Upload rejected",
+		slog.String("request_id", GetReqID(r.Context())),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", rejection.status),
+		slog.String("reason", rejection.reason),
+		slog.String("filename", rejection.filename),
+		slog.String("declared_type", rejection.declaredType),
+		slog.String("detected_type", rejection.detected))
+
+	if rejection.status == http.StatusRequestEntityTooLarge {
+		g.errorHandler.HandleError(w, r, apierrors.PayloadTooLargeError(details))
+		return
+	}
+	g.errorHandler.HandleError(w, r, apierrors.UnsupportedMediaTypeError("Upload rejected: "+rejection.reason, details))
+}
+
+// quarantine stores rejected content with a JSON description next to it and
+// returns the quarantine ID. Content larger than the whole budget is not kept
+// and yields an empty ID.
+func (g *UploadGuard) quarantine(r *http.Request, rejection *uploadRejection) (string, error) {
+	if int64(len(rejection.data)) > g.maxBytes {
+		return "", nil
+	}
+	dir := g.paths.ForContext(r.Context()).QuarantineDir
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := g.pruneQuarantine(dir, int64(len(rejection.data))); err != nil {
+		return "", err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	now := g.now().UTC()
+	id := now.Format("20060102T150405") + "_" + hex.EncodeToString(suffix)
+
+	if err := os.WriteFile(filepath.Join(dir, id+".bin"), rejection.data, 0600); err != nil {
+		return "", err
+	}
+
+	meta, err := json.MarshalIndent(map[string]interface{}{
+		"id":            id,
+		"received_at":   now,
+		"request_id":    GetReqID(r.Context()),
+		"method":        r.Method,
+		"path":          r.URL.Path,
+		"remote_addr":   r.RemoteAddr,
+		"filename":      rejection.filename,
+		"declared_type": rejection.declaredType,
+		"detected_type": rejection.detected,
+		"size":          rejection.size,
+		"reason":        rejection.reason,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), meta, 0600); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// pruneQuarantine removes the oldest entries until one more of incoming bytes
+// fits the budget. IDs start with their UTC time, so they sort oldest first.
+func (g *UploadGuard) pruneQuarantine(dir string, incoming int64) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	sizes := make(map[string]int64)
+	var total int64
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".bin" && ext != ".json") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(f.Name(), ext)
+		sizes[id] += info.Size()
+		total += info.Size()
+	}
+	ids := make([]string, 0, len(sizes))
+	for id := range sizes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for len(ids) > 0 && (len(ids)+1 > g.maxEntries || total+incoming > g.maxBytes) {
+		id := ids[0]
+		for _, ext := range []string{".bin", ".json"} {
+			if err := os.Remove(filepath.Join(dir, id+ext)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		total -= sizes[id]
+		ids = ids[1:]
+	}
+	return nil
+}
+
+var errTooLarge = errors.New("upload too large")
+
+// readLimited reads at most max bytes and fails with errTooLarge beyond that
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(data)) > max {
+		return nil, errTooLarge
+	}
+	return data, nil
+}
+
+// multipartOverhead allows for boundaries and part headers around the file
+const multipartOverhead = 64 * 1024
+
+// readMultipartFile reads the policy's form field from a multipart request
+func readMultipartFile(w http.ResponseWriter, r *http.Request, policy UploadPolicy) ([]byte, string, string, error) {
+	if r.ContentLength > policy.MaxSize+multipartOverhead {
+		return nil, "", "", errTooLarge
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, policy.MaxSize+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("invalid multipart body: %w", err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", "", fmt.Errorf("multipart field %q is missing", policy.FormField)
+		}
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return nil, "", "", errTooLarge
+			}
+			return nil, "", "", fmt.Errorf("invalid multipart body: %w", err)
+		}
+		if part.FormName() != policy.FormField {
+			part.Close()
+			continue
+		}
+		filename := filepath.Base(part.FileName())
+		if filename == "." {
+			filename = ""
+		}
+		data, err := readLimited(part, policy.MaxSize)
+		part.Close()
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			err = errTooLarge
+		}
+		return data, filename, part.Header.Get("Content-Type"), err
+	}
+}
+
+// detectUploadKind sniffs the content and returns the first accepted kind it
+// is a valid instance of
+func detectUploadKind(data []byte, kinds []UploadKind) (UploadKind, bool) {
+	for _, kind := range kinds {
+		var valid bool
+		switch kind {
+		case UploadKindXLSX:
+			valid = isXLSX(data)
+		case UploadKindCSV:
+			valid = isCSV(data)
+		case UploadKindJSON:
+			valid = json.Valid(bytes.TrimPrefix(data, utf8BOM))
+		}
+		if valid {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// isXLSX checks for a zip archive holding an Office Open XML workbook
+func isXLSX(data []byte) bool {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return false
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	var hasTypes, hasWorkbook bool
+	for _, f := range zr.File {
+		switch f.Name {
+		case "[Content_Types].xml":
+			hasTypes = true
+		case "xl/workbook.xml":
+			hasWorkbook = true
+		}
+	}
+	return hasTypes && hasWorkbook
+}
+
+var utf8BOM = []byte("\ufeff")
+
+// csvSniffRecords is how many records are parsed to validate a CSV upload
+const csvSniffRecords = 100
+
+// isCSV checks for UTF-8 text that parses as CSV with a multi-column header
+// and a consistent number of fields
+func isCSV(data []byte) bool {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if len(data) == 0 || !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	header, err := reader.Read()
+	if err != nil || len(header) < 2 {
+		return false
+	}
+	for i := 0; i < csvSniffRecords; i++ {
+		if _, err := reader.Read(); err == io.EOF {
+			break
+		} else if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
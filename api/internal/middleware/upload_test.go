@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+)
+
+func newTestUploadGuard(t *testing.T) (*UploadGuard, string) {
+	dir := filepath.Join(t.TempDir(), "quarantine")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewUploadGuard(&config.Paths{QuarantineDir: dir}, logger, apierrors.NewErrorHandler(logger, false)), dir
+}
+
+// guardedHandler echoes what the handler saw of the upload
+func guardedHandler(guard *UploadGuard, policy UploadPolicy) http.Handler {
+	return guard.Limit(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upload, ok := UploadFromContext(r.Context())
+		if !ok {
+			http.Error(w, "no upload", http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"kind":     upload.Kind,
+			"filename": upload.Filename,
+			"size":     upload.Size,
+			"body":     string(body),
+		})
+	}))
+}
+
+func testXLSX(t *testing.T) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte("<xml/>"))
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func multipartRequest(t *testing.T, field, filename, contentType string, data []byte) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(header)
+	require.NoError(t, err)
+	part.Write(data)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	var problem map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	details, _ := problem["details"].(map[string]interface{})
+	return details
+}
+
+func TestUploadGuard_AcceptsValidFiles(t *testing.T) {
+	guard, _ := newTestUploadGuard(t)
+	policy := UploadPolicy{MaxSize: 1 << 20, Kinds: []UploadKind{UploadKindXLSX, UploadKindCSV}, FormField: "file"}
+	handler := guardedHandler(guard, policy)
+
+	t.Run("xlsx", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, multipartRequest(t, "file", "report.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", testXLSX(t)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"kind":"xlsx"`)
+		assert.Contains(t, rec.Body.String(), `"filename":"report.xlsx"`)
+	})
+
+	t.Run("csv labelled by Windows as Excel", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		csv := "\ufeffSymbol,Quantity\nBBOB,100\nTASC,50\n"
+		handler.ServeHTTP(rec, multipartRequest(t, "file", "holdings.csv", "application/vnd.ms-excel", []byte(csv)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"kind":"csv"`)
+	})
+}
+
+func TestUploadGuard_RejectsMismatchedContent(t *testing.T) {
+	guard, quarantineDir := newTestUploadGuard(t)
+	policy := UploadPolicy{MaxSize: 1 << 20, Kinds: []UploadKind{UploadKindXLSX}, FormField: "file"}
+	handler := guardedHandler(guard, policy)
+
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		data        []byte
+		reason      string
+	}{
+		{"plain zip renamed to xlsx", "report.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []byte("PK\x03\x04not really"), "content does not match"},
+		{"html with xlsx type", "report.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []byte("<html><script>x</script></html>"), "content does not match"},
+		{"xlsx declared as text", "report.xlsx", "text/html", testXLSX(t), "declared content type"},
+		{"xlsx with wrong extension", "report.exe", "application/octet-stream", testXLSX(t), "extension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, multipartRequest(t, "file", tt.filename, tt.contentType, tt.data))
+
+			require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+			details := decodeProblem(t, rec)
+			assert.Contains(t, details["reason"], tt.reason)
+			assert.Equal(t, []interface{}{"xlsx"}, details["allowed"])
+
+			id, _ := details["quarantine_id"].(string)
+			require.NotEmpty(t, id, "rejected content is quarantined")
+			stored, err := os.ReadFile(filepath.Join(quarantineDir, id+".bin"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.data, stored)
+			assert.FileExists(t, filepath.Join(quarantineDir, id+".json"))
+		})
+	}
+}
+
+func TestUploadGuard_QuarantineBudget(t *testing.T) {
+	guard, quarantineDir := newTestUploadGuard(t)
+	guard.maxEntries = 3
+	guard.maxBytes = 4096
+	clock := time.Date(2025, 8, 26, 10, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	handler := guardedHandler(guard, UploadPolicy{MaxSize: 8192, Kinds: []UploadKind{UploadKindJSON}})
+
+	reject := func(body string) string {
+		req := httptest.NewRequest(http.MethodPost, "/portfolios", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		id, _ := decodeProblem(t, rec)["quarantine_id"].(string)
+		return id
+	}
+	stored := func() []string {
+		matches, err := filepath.Glob(filepath.Join(quarantineDir, "*.bin"))
+		require.NoError(t, err)
+		for i, m := range matches {
+			matches[i] = strings.TrimSuffix(filepath.Base(m), ".bin")
+		}
+		return matches
+	}
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, reject("not json"))
+	}
+	assert.Equal(t, ids[2:], stored(), "the oldest entries are pruned to the entry budget")
+
+	// A large entry evicts older ones until it fits the byte budget
+	big := reject(strings.Repeat("x", 3500))
+	assert.Equal(t, []string{ids[4], big}, stored())
+
+	// Content over the whole budget is refused but not kept
+	assert.Empty(t, reject(strings.Repeat("x", 5000)))
+	assert.Equal(t, []string{ids[4], big}, stored())
+}
+
+func TestUploadGuard_SizeLimits(t *testing.T) {
+	guard, quarantineDir := newTestUploadGuard(t)
+
+	t.Run("raw body over the limit", func(t *testing.T) {
+		handler := guardedHandler(guard, UploadPolicy{MaxSize: 16, Kinds: []UploadKind{UploadKindJSON}})
+		req := httptest.NewRequest(http.MethodPost, "/portfolios", strings.NewReader(`{"name":"a very long portfolio name"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "PAYLOAD_TOO_LARGE")
+		assert.EqualValues(t, 16, decodeProblem(t, rec)["max_size"])
+	})
+
+	t.Run("multipart file over the limit", func(t *testing.T) {
+		handler := guardedHandler(guard, UploadPolicy{MaxSize: 32, Kinds: []UploadKind{UploadKindCSV}, FormField: "file"})
+		data := []byte("a,b\n" + strings.Repeat("1,2\n", 20))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, multipartRequest(t, "file", "big.csv", "text/csv", data))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	entries, _ := os.ReadDir(quarantineDir)
+	assert.Empty(t, entries, "oversized uploads are not stored")
+}
+
+func TestUploadGuard_JSONBody(t *testing.T) {
+	guard, _ := newTestUploadGuard(t)
+	handler := guardedHandler(guard, UploadPolicy{MaxSize: 1024, Kinds: []UploadKind{UploadKindJSON}})
+
+	t.Run("body is replayed to the handler", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/portfolios", strings.NewReader(`{"name":"Core"}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"body":"{\"name\":\"Core\"}"`)
+	})
+
+	t.Run("form encoded body is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/portfolios", strings.NewReader(`{"name":"Core"}`))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		assert.Contains(t, rec.Body.String(), "UNSUPPORTED_MEDIA_TYPE")
+	})
+
+	t.Run("reads pass through", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		guard.Limit(UploadPolicy{MaxSize: 1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/portfolios", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+func TestUploadGuard_MaxBody(t *testing.T) {
+	guard, _ := newTestUploadGuard(t)
+	handler := guard.MaxBody(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Run("declared length over the limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(strings.Repeat("x", 17))))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.EqualValues(t, 16, decodeProblem(t, rec)["max_size"])
+	})
+
+	t.Run("undeclared length over the limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/scrape", io.NopCloser(strings.NewReader(strings.Repeat("x", 17))))
+		req.ContentLength = -1
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("body within the limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("zero disables the limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		guard.MaxBody(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(strings.Repeat("x", 17))))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UsageCounter records anonymous usage counts; telemetry.Collector
+// implements it
+type UsageCounter interface {
+	Add(name string, n int64)
+}
+
+// FeatureUsage counts requests per method and route pattern, e.g.
+// "feature:GET /api/v1/watchlists/{id}". Only the pattern is recorded, never
+// the path, query or body, and requests matching no route are not counted.
+func FeatureUsage(counter UsageCounter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			rctx := chi.RouteContext(r.Context())
+			if rctx == nil {
+				return
+			}
+			pattern := rctx.RoutePattern()
+			if pattern == "" || strings.HasSuffix(pattern, "*") {
+				return
+			}
+			if len(pattern) > 1 {
+				pattern = strings.TrimSuffix(pattern, "/")
+			}
+			counter.Add("feature:"+r.Method+" "+pattern, 1)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingUsage struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (c *countingUsage) Add(name string, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[name] += n
+}
+
+func TestFeatureUsage(t *testing.T) {
+	counter := &countingUsage{counts: make(map[string]int64)}
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	watchlists := chi.NewRouter()
+	watchlists.Get("/", ok)
+	watchlists.Get("/{id}", ok)
+
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(FeatureUsage(counter))
+		r.Mount("/v1/watchlists", watchlists)
+	})
+
+	for _, path := range []string{"/api/v1/watchlists", "/api/v1/watchlists/abc", "/api/v1/watchlists/def", "/api/v1/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, map[string]int64{
+		"feature:GET /api/v1/watchlists":      1,
+		"feature:GET /api/v1/watchlists/{id}": 2,
+	}, counter.counts)
+}
@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed changelog.json
+var changelogJSON []byte
+
+// Release is one version in the changelog
+type Release struct {
+	Version         string   `json:"version"`
+	Date            string   `json:"date"`
+	Features        []string `json:"features,omitempty"`
+	Fixes           []string `json:"fixes,omitempty"`
+	BreakingChanges []string `json:"breaking_changes,omitempty"`
+	// Migrations lists the IDs of the data migrations the release requires
+	Migrations []string `json:"migrations,omitempty"`
+}
+
+// Changelog lists releases newest first
+type Changelog struct {
+	Releases []Release `json:"releases"`
+}
+
+// LoadChangelog parses the embedded changelog
+func LoadChangelog() (Changelog, error) {
+	var changelog Changelog
+	if err := json.Unmarshal(changelogJSON, &changelog); err != nil {
+		return changelog, fmt.Errorf("parse embedded changelog: %w", err)
+	}
+	return changelog, nil
+}
+
+// Since returns the releases newer than version, e.g. the version a user
+// upgraded from. An empty version returns every release.
+func (c Changelog) Since(version string) []Release {
+	if version == "" {
+		return c.Releases
+	}
+	var releases []Release
+	for _, r := range c.Releases {
+		if CompareVersions(r.Version, version) > 0 {
+			releases = append(releases, r)
+		}
+	}
+	return releases
+}
+
+// CompareVersions compares dotted versions such as "3.0.0", "v3.1" or
+// "enhanced-v3.0.0-beta.1", returning -1, 0 or 1. A pre-release sorts
+// before the release it precedes.
+func CompareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+	for i := 0; i < len(aCore) || i < len(bCore); i++ {
+		var x, y int
+		if i < len(aCore) {
+			x = aCore[i]
+		}
+		if i < len(bCore) {
+			y = bCore[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitVersion returns the numeric parts and pre-release suffix of a version
+func splitVersion(v string) ([]int, string) {
+	v = strings.TrimSpace(v)
+	if i := strings.LastIndex(v, "v"); i >= 0 && i+1 < len(v) && v[i+1] >= '0' && v[i+1] <= '9' {
+		v = v[i+1:]
+	}
+	pre := ""
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre
+}
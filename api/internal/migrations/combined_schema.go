@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"isxcli/internal/config"
+	"isxcli/internal/exporter"
+)
+
+// combinedSchemaMigration upgrades the combined CSV to the current schema
+// version and writes the schema manifest next to it
+var combinedSchemaMigration = Migration{
+	ID:          "combined_schema_manifest",
+	Version:     "3.0.0",
+	Description: "Upgrade the combined CSV to the current schema and record its version in isx_combined_data.schema.json",
+	Needed: func(paths *config.Paths) (bool, error) {
+		return combinedNeedsSchema(combinedCSV(paths)), nil
+	},
+	Apply: func(ctx context.Context, paths *config.Paths, logger *slog.Logger) (string, error) {
+		path := combinedCSV(paths)
+		from, err := exporter.MigrateCombinedCSV(path)
+		if err != nil {
+			return "", err
+		}
+		logger.DebugContext(ctx, "Combined CSV schema recorded",
+			slog.String("file", path),
+			slog.Int("from_version", from))
+		if from == exporter.CombinedSchemaVersion {
+			return fmt.Sprintf("recorded schema v%d", from), nil
+		}
+		return fmt.Sprintf("upgraded combined CSV from schema v%d to v%d", from, exporter.CombinedSchemaVersion), nil
+	},
+}
+
+func combinedCSV(paths *config.Paths) string {
+	return filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")
+}
+
+// combinedNeedsSchema reports whether path has a known layout that is older
+// than the current one or has no manifest. Missing files and headers of no
+// known layout are left alone.
+func combinedNeedsSchema(path string) bool {
+	schema, err := exporter.ReadCombinedSchema(path)
+	if err != nil {
+		return false
+	}
+	if schema.Version < exporter.CombinedSchemaVersion {
+		return true
+	}
+	manifest, err := exporter.ReadSchemaManifest(path)
+	return err == nil && manifest == nil
+}
@@ -0,0 +1,14 @@
+// Package migrations describes what changed between ISX Pulse releases and
+// converts data directories written by older releases.
+//
+// The changelog (versions, features, breaking changes and the data migrations
+// each release requires) is embedded from changelog.json and served at
+// /api/v1/system/changelog.
+//
+// A Migration detects an old data layout from the files themselves, so a
+// data directory copied from an older install is found regardless of which
+// version last ran. Pending migrations are reported at startup and by the
+// changelog endpoint, and run as the "migration" operation step, which a full
+// pipeline includes first while any are pending. Applied migrations are
+// recorded in data/migrations.json.
+package migrations
@@ -0,0 +1,155 @@
+package migrations
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"isxcli/internal/config"
+	"isxcli/pkg/contracts/domain"
+)
+
+// fillMethodColumnMigration adds the FillMethod column to trade record CSVs
+// written before gap fill strategies were configurable
+var fillMethodColumnMigration = Migration{
+	ID:          "fill_method_column",
+	Version:     "3.0.0",
+	Description: "Add the FillMethod column to daily, combined and ticker CSVs",
+	Needed: func(paths *config.Paths) (bool, error) {
+		files, err := tradeRecordCSVs(paths)
+		if err != nil {
+			return false, err
+		}
+		for _, f := range files {
+			if lacksFillMethod(f) {
+				return true, nil
+			}
+		}
+		return false, nil
+	},
+	Apply: func(ctx context.Context, paths *config.Paths, logger *slog.Logger) (string, error) {
+		files, err := tradeRecordCSVs(paths)
+		if err != nil {
+			return "", err
+		}
+		converted := 0
+		for _, f := range files {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			if !lacksFillMethod(f) {
+				continue
+			}
+			if err := addFillMethodColumn(f); err != nil {
+				return "", fmt.Errorf("%s: %w", f, err)
+			}
+			converted++
+			logger.DebugContext(ctx, "Added FillMethod column", slog.String("file", f))
+		}
+		return fmt.Sprintf("added FillMethod to %d CSV files", converted), nil
+	},
+}
+
+// tradeRecordCSVs lists the CSVs written with the trade record layout
+func tradeRecordCSVs(paths *config.Paths) ([]string, error) {
+	files := []string{filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")}
+	for _, pattern := range []string{
+		filepath.Join(paths.ReportsDir, "daily", "isx_daily_*.csv"),
+		filepath.Join(paths.ReportsDir, "ticker", "*_trading_history.csv"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// lacksFillMethod reports whether a CSV has the trade record header without
+// the FillMethod column. Missing and unreadable files need no migration.
+func lacksFillMethod(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	header := strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "\ufeff")), ",")
+	hasStatus := false
+	for _, h := range header {
+		switch strings.Trim(h, `"`) {
+		case "FillMethod":
+			return false
+		case "TradingStatus":
+			hasStatus = true
+		}
+	}
+	return hasStatus
+}
+
+// addFillMethodColumn appends FillMethod to every row: actual for traded
+// days, carry_forward for the rows the old processor filled
+func addFillMethodColumn(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	bom := bytes.HasPrefix(data, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	status := -1
+	for i, h := range rows[0] {
+		if h == "TradingStatus" {
+			status = i
+		}
+	}
+	if status < 0 {
+		return fmt.Errorf("no TradingStatus column")
+	}
+
+	rows[0] = append(rows[0], "FillMethod")
+	for i := 1; i < len(rows); i++ {
+		method := domain.FillMethodCarryForward
+		if status < len(rows[i]) {
+			if traded, _ := strconv.ParseBool(rows[i][status]); traded {
+				method = domain.FillMethodActual
+			}
+		}
+		rows[i] = append(rows[i], method)
+	}
+
+	var buf bytes.Buffer
+	if bom {
+		buf.WriteString("\ufeff")
+	}
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,136 @@
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"isxcli/internal/config"
+)
+
+// StateFileName records applied migrations in the data directory
+const StateFileName = "migrations.json"
+
+// Migration converts a data directory from an older layout
+type Migration struct {
+	ID          string `json:"id"`
+	Version     string `json:"version"` // Release that introduced the new layout
+	Description string `json:"description"`
+
+	// Needed reports whether the data directory still has the old layout
+	Needed func(paths *config.Paths) (bool, error) `json:"-"`
+	// Apply converts the data directory and returns a summary of what changed
+	Apply func(ctx context.Context, paths *config.Paths, logger *slog.Logger) (string, error) `json:"-"`
+}
+
+// registry holds the known migrations in the order they must run
+var registry = []Migration{
+	fillMethodColumnMigration,
+	combinedSchemaMigration,
+}
+
+// All returns every known migration in run order
+func All() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// Pending returns the migrations the data directory still needs
+func Pending(paths *config.Paths) ([]Migration, error) {
+	var pending []Migration
+	for _, m := range registry {
+		needed, err := m.Needed(paths)
+		if err != nil {
+			return nil, fmt.Errorf("check migration %s: %w", m.ID, err)
+		}
+		if needed {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// AppliedMigration is an entry of the migration state file
+type AppliedMigration struct {
+	ID        string    `json:"id"`
+	AppliedAt time.Time `json:"applied_at"`
+	Result    string    `json:"result"`
+}
+
+// Applied returns the migrations recorded as applied, oldest first
+func Applied(paths *config.Paths) ([]AppliedMigration, error) {
+	data, err := os.ReadFile(filepath.Join(paths.DataDir, StateFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var applied []AppliedMigration
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", StateFileName, err)
+	}
+	return applied, nil
+}
+
+// Run applies the pending migrations in order and records each one. It stops
+// at the first failure; migrations already applied stay recorded.
+func Run(ctx context.Context, paths *config.Paths, logger *slog.Logger) ([]AppliedMigration, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	pending, err := Pending(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []AppliedMigration
+	for _, m := range pending {
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+		logger.InfoContext(ctx, "Applying data migration",
+			slog.String("migration", m.ID),
+			slog.String("version", m.Version))
+
+		result, err := m.Apply(ctx, paths, logger)
+		if err != nil {
+			return done, fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+		entry := AppliedMigration{ID: m.ID, AppliedAt: time.Now().UTC(), Result: result}
+		if err := record(paths, entry); err != nil {
+			return done, err
+		}
+		done = append(done, entry)
+
+		logger.InfoContext(ctx, "Data migration applied",
+			slog.String("migration", m.ID),
+			slog.String("result", result))
+	}
+	return done, nil
+}
+
+// record appends an entry to the migration state file
+func record(paths *config.Paths, entry AppliedMigration) error {
+	applied, err := Applied(paths)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(append(applied, entry), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(paths.DataDir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(paths.DataDir, StateFileName)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("record migration: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/exporter"
+)
+
+func TestLoadChangelog(t *testing.T) {
+	changelog, err := LoadChangelog()
+	require.NoError(t, err)
+	require.NotEmpty(t, changelog.Releases)
+
+	known := make(map[string]bool)
+	for _, m := range All() {
+		known[m.ID] = true
+	}
+	for i, r := range changelog.Releases {
+		if i > 0 {
+			assert.Equal(t, 1, CompareVersions(changelog.Releases[i-1].Version, r.Version), "releases are listed newest first")
+		}
+		for _, id := range r.Migrations {
+			assert.True(t, known[id], "release %s names unknown migration %s", r.Version, id)
+		}
+	}
+
+	assert.Len(t, changelog.Since(""), len(changelog.Releases))
+	assert.Empty(t, changelog.Since(changelog.Releases[0].Version))
+	since := changelog.Since("2.0.0")
+	require.NotEmpty(t, since)
+	assert.Equal(t, changelog.Releases[0].Version, since[0].Version)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"3.0.0", "3.0.0", 0},
+		{"enhanced-v3.0.0", "3.0.0", 0},
+		{"v3.1", "3.0.9", 1},
+		{"2.10.0", "2.9.0", 1},
+		{"3.0", "3.0.0", 0},
+		{"3.0.0-beta.1", "3.0.0", -1},
+		{"3.0.0-beta.2", "3.0.0-beta.1", 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CompareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestRun_FillMethodColumn(t *testing.T) {
+	root := t.TempDir()
+	paths := &config.Paths{DataDir: root, ReportsDir: filepath.Join(root, "reports")}
+
+	pending, err := Pending(paths)
+	require.NoError(t, err)
+	assert.Empty(t, pending, "an empty data directory needs no migration")
+
+	oldHeader := "\ufeffDate,CompanySymbol,ClosePrice,TradingStatus\n2025-01-05,BBOB,1.2,true\n2025-01-06,BBOB,1.2,false\n"
+	combined := filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")
+	ticker := filepath.Join(paths.ReportsDir, "ticker", "BBOB_trading_history.csv")
+	current := filepath.Join(paths.ReportsDir, "daily", "isx_daily_2025_01_05.csv")
+	for path, content := range map[string]string{
+		combined: oldHeader,
+		ticker:   oldHeader,
+		current:  "Date,CompanySymbol,ClosePrice,TradingStatus,FillMethod\n2025-01-05,BBOB,1.2,true,actual\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	pending, err = Pending(paths)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "fill_method_column", pending[0].ID)
+
+	applied, err := Run(context.Background(), paths, nil)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "added FillMethod to 2 CSV files", applied[0].Result)
+
+	want := "\ufeffDate,CompanySymbol,ClosePrice,TradingStatus,FillMethod\n2025-01-05,BBOB,1.2,true,actual\n2025-01-06,BBOB,1.2,false,carry_forward\n"
+	for _, path := range []string{combined, ticker} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data), "the BOM is kept")
+	}
+	data, err := os.ReadFile(current)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "true,actual\n", "current files are untouched")
+
+	pending, err = Pending(paths)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	recorded, err := Applied(paths)
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "fill_method_column", recorded[0].ID)
+	assert.False(t, recorded[0].AppliedAt.IsZero())
+
+	// A second run finds nothing to do and records nothing
+	applied, err = Run(context.Background(), paths, nil)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	recorded, err = Applied(paths)
+	require.NoError(t, err)
+	assert.Len(t, recorded, 1)
+}
+
+func TestRun_CombinedSchemaManifest(t *testing.T) {
+	root := t.TempDir()
+	paths := &config.Paths{DataDir: root, ReportsDir: filepath.Join(root, "reports")}
+
+	combined := filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(combined), 0755))
+	require.NoError(t, os.WriteFile(combined, []byte(strings.Join(exporter.TradeRecordHeaders, ",")+"\n"), 0644))
+
+	pending, err := Pending(paths)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "a current file without a manifest only needs the manifest")
+	assert.Equal(t, "combined_schema_manifest", pending[0].ID)
+
+	applied, err := Run(context.Background(), paths, nil)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "recorded schema v2", applied[0].Result)
+	assert.FileExists(t, exporter.SchemaManifestPath(combined))
+
+	pending, err = Pending(paths)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
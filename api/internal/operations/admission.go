@@ -0,0 +1,332 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resource is a shared data directory operations write to. Two operations
+// holding the same resource would overwrite each other's files, so they never
+// run at the same time.
+type Resource string
+
+const (
+	// ResourceDownloads is data/downloads, written by the scraper
+	ResourceDownloads Resource = "downloads"
+	// ResourceReports is data/reports, written by processing, index
+	// extraction and liquidity
+	ResourceReports Resource = "reports"
+)
+
+// stageResources lists the directories each stage reads or writes. Stages
+// not listed are assumed to touch both.
+var stageResources = map[string][]Resource{
+	StageIDScraping:   {ResourceDownloads},
+	StageIDProcessing: {ResourceDownloads, ResourceReports},
+	StageIDIndices:    {ResourceDownloads, ResourceReports},
+	StageIDLiquidity:  {ResourceReports},
+}
+
+// ResourcesForSteps returns the resources an operation running the given
+// steps locks, sorted. No steps means the full pipeline.
+func ResourcesForSteps(stepIDs []string) []Resource {
+	if len(stepIDs) == 0 {
+		return []Resource{ResourceDownloads, ResourceReports}
+	}
+	seen := make(map[Resource]bool)
+	for _, id := range stepIDs {
+		resources, ok := stageResources[id]
+		if !ok {
+			resources = []Resource{ResourceDownloads, ResourceReports}
+		}
+		for _, r := range resources {
+			seen[r] = true
+		}
+	}
+	out := make([]Resource, 0, len(seen))
+	for r := range seen {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// ConflictPolicy is what happens to an operation that cannot start at once
+type ConflictPolicy string
+
+const (
+	// ConflictQueue waits until the resources and a slot are free
+	ConflictQueue ConflictPolicy = "queue"
+	// ConflictReject fails with an *OperationConflictError instead of waiting
+	ConflictReject ConflictPolicy = "reject"
+)
+
+// ParameterOnConflict is the request parameter overriding the conflict policy
+const ParameterOnConflict = "on_conflict"
+
+// ParseConflictPolicy reads a conflict policy; empty means ConflictQueue
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch ConflictPolicy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ConflictQueue:
+		return ConflictQueue, nil
+	case ConflictReject:
+		return ConflictReject, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q: must be queue or reject", s)
+	}
+}
+
+// OperationConflictError reports the operations a rejected operation would
+// have had to wait for
+type OperationConflictError struct {
+	OperationID string
+	BlockedBy   []string
+	Resources   []Resource
+}
+
+func (e *OperationConflictError) Error() string {
+	if len(e.Resources) == 0 {
+		return fmt.Sprintf("operation %s conflicts with running operations %s: concurrency limit reached",
+			e.OperationID, strings.Join(e.BlockedBy, ", "))
+	}
+	names := make([]string, len(e.Resources))
+	for i, r := range e.Resources {
+		names[i] = string(r)
+	}
+	return fmt.Sprintf("operation %s conflicts with running operations %s over %s",
+		e.OperationID, strings.Join(e.BlockedBy, ", "), strings.Join(names, ", "))
+}
+
+// ErrOperationDequeued is returned to an operation cancelled while queued
+var ErrOperationDequeued = errors.New("operation cancelled while queued")
+
+// QueueStatus describes where a waiting operation is in the queue
+type QueueStatus struct {
+	OperationID string   `json:"operation_id"`
+	Position    int      `json:"position"` // 1 is next in line
+	BlockedBy   []string `json:"blocked_by"`
+}
+
+// admissionTicket is an operation waiting for, or holding, its resources
+type admissionTicket struct {
+	id        string
+	resources []Resource
+	granted   chan struct{}
+	err       error // set when dequeued without being granted
+}
+
+// admission decides when operations may start. At most limit operations run
+// at once and no two running operations share a resource. Waiting operations
+// are granted in arrival order, except that one whose resources are free
+// may overtake those it does not conflict with.
+type admission struct {
+	mu      sync.Mutex
+	limit   func() int
+	running map[string]*admissionTicket
+	waiting []*admissionTicket
+
+	// onQueueChange is called, without the lock held, with the positions of
+	// waiting operations whenever they change
+	onQueueChange func([]QueueStatus)
+}
+
+func newAdmission(limit func() int) *admission {
+	return &admission{
+		limit:   limit,
+		running: make(map[string]*admissionTicket),
+	}
+}
+
+// acquire waits until the operation may run and returns the function that
+// releases its resources. With ConflictReject it fails at once instead of
+// waiting; it also fails when ctx ends or the operation is dequeued.
+func (a *admission) acquire(ctx context.Context, id string, resources []Resource, policy ConflictPolicy) (func(), error) {
+	ticket := &admissionTicket{id: id, resources: resources, granted: make(chan struct{})}
+
+	a.mu.Lock()
+	if blockers, shared := a.blockers(ticket, len(a.waiting)); len(blockers) == 0 {
+		a.running[id] = ticket
+		a.mu.Unlock()
+		return a.releaseFunc(ticket), nil
+	} else if policy == ConflictReject {
+		a.mu.Unlock()
+		return nil, &OperationConflictError{OperationID: id, BlockedBy: blockers, Resources: shared}
+	}
+	a.waiting = append(a.waiting, ticket)
+	queue := a.queueLocked()
+	a.mu.Unlock()
+	a.notify(queue)
+
+	select {
+	case <-ticket.granted:
+		if ticket.err != nil {
+			return nil, ticket.err
+		}
+		return a.releaseFunc(ticket), nil
+	case <-ctx.Done():
+		a.mu.Lock()
+		if a.removeWaitingLocked(id) {
+			queue := a.queueLocked()
+			a.mu.Unlock()
+			a.notify(queue)
+			return nil, ctx.Err()
+		}
+		a.mu.Unlock()
+		// Granted or dequeued concurrently with the cancellation
+		<-ticket.granted
+		if ticket.err == nil {
+			a.releaseFunc(ticket)()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// blockers returns the running operations and the operations among the first
+// ahead waiting ones that stop ticket from starting, with the resources they
+// share. A full set of running operations blocks on its own.
+func (a *admission) blockers(ticket *admissionTicket, ahead int) ([]string, []Resource) {
+	var ids []string
+	sharedSet := make(map[Resource]bool)
+	check := func(other *admissionTicket) {
+		conflict := false
+		for _, r := range sharedResources(ticket.resources, other.resources) {
+			sharedSet[r] = true
+			conflict = true
+		}
+		if conflict {
+			ids = append(ids, other.id)
+		}
+	}
+	for _, other := range a.running {
+		check(other)
+	}
+	for _, other := range a.waiting[:ahead] {
+		check(other)
+	}
+	if limit := a.limit(); limit > 0 && len(a.running) >= limit && len(ids) == 0 {
+		for id := range a.running {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	shared := make([]Resource, 0, len(sharedSet))
+	for r := range sharedSet {
+		shared = append(shared, r)
+	}
+	sort.Slice(shared, func(i, j int) bool { return shared[i] < shared[j] })
+	return ids, shared
+}
+
+func sharedResources(a, b []Resource) []Resource {
+	var shared []Resource
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				shared = append(shared, x)
+			}
+		}
+	}
+	return shared
+}
+
+// releaseFunc frees the ticket's resources once and admits waiting operations
+func (a *admission) releaseFunc(ticket *admissionTicket) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			delete(a.running, ticket.id)
+			queue, changed := a.dispatchLocked()
+			a.mu.Unlock()
+			if changed {
+				a.notify(queue)
+			}
+		})
+	}
+}
+
+// dispatchLocked grants every waiting operation that may now start
+func (a *admission) dispatchLocked() ([]QueueStatus, bool) {
+	changed := false
+	for i := 0; i < len(a.waiting); i++ {
+		ticket := a.waiting[i]
+		if blockers, _ := a.blockers(ticket, i); len(blockers) > 0 {
+			continue
+		}
+		a.waiting = append(a.waiting[:i], a.waiting[i+1:]...)
+		a.running[ticket.id] = ticket
+		close(ticket.granted)
+		changed = true
+		i--
+	}
+	return a.queueLocked(), changed
+}
+
+// dequeue removes a waiting operation, failing its acquire with
+// ErrOperationDequeued. It reports whether the operation was waiting.
+func (a *admission) dequeue(id string) bool {
+	a.mu.Lock()
+	var ticket *admissionTicket
+	for _, t := range a.waiting {
+		if t.id == id {
+			ticket = t
+			break
+		}
+	}
+	if ticket == nil {
+		a.mu.Unlock()
+		return false
+	}
+	a.removeWaitingLocked(id)
+	ticket.err = ErrOperationDequeued
+	close(ticket.granted)
+	queue, _ := a.dispatchLocked()
+	a.mu.Unlock()
+	a.notify(queue)
+	return true
+}
+
+func (a *admission) removeWaitingLocked(id string) bool {
+	for i, t := range a.waiting {
+		if t.id == id {
+			a.waiting = append(a.waiting[:i], a.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// queue returns the waiting operations in order
+func (a *admission) queue() []QueueStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.queueLocked()
+}
+
+func (a *admission) queueLocked() []QueueStatus {
+	queue := make([]QueueStatus, len(a.waiting))
+	for i, ticket := range a.waiting {
+		blockers, _ := a.blockers(ticket, i)
+		queue[i] = QueueStatus{OperationID: ticket.id, Position: i + 1, BlockedBy: blockers}
+	}
+	return queue
+}
+
+// conflicts returns the operations an operation needing resources would wait
+// for if it were started now, without queueing it
+func (a *admission) conflicts(id string, resources []Resource) ([]string, []Resource) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.blockers(&admissionTicket{id: id, resources: resources}, len(a.waiting))
+}
+
+func (a *admission) notify(queue []QueueStatus) {
+	if a.onQueueChange != nil {
+		a.onQueueChange(queue)
+	}
+}
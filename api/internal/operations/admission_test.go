@@ -0,0 +1,200 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcesForSteps(t *testing.T) {
+	assert.Equal(t, []Resource{ResourceDownloads, ResourceReports}, ResourcesForSteps(nil))
+	assert.Equal(t, []Resource{ResourceDownloads}, ResourcesForSteps([]string{StageIDScraping}))
+	assert.Equal(t, []Resource{ResourceReports}, ResourcesForSteps([]string{StageIDLiquidity}))
+	assert.Equal(t, []Resource{ResourceDownloads, ResourceReports}, ResourcesForSteps([]string{StageIDScraping, StageIDLiquidity}))
+	assert.Equal(t, []Resource{ResourceDownloads, ResourceReports}, ResourcesForSteps([]string{"custom"}), "unknown stages lock everything")
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	policy, err := ParseConflictPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictQueue, policy)
+
+	policy, err = ParseConflictPolicy("Reject")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictReject, policy)
+
+	_, err = ParseConflictPolicy("wait")
+	assert.Error(t, err)
+}
+
+// waitGranted reports whether an acquire running in the background returned
+func waitGranted(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("acquire did not return")
+		return nil
+	}
+}
+
+func TestAdmissionQueuesConflictingOperations(t *testing.T) {
+	a := newAdmission(func() int { return 0 })
+	var (
+		mu     sync.Mutex
+		queues [][]QueueStatus
+	)
+	a.onQueueChange = func(q []QueueStatus) {
+		mu.Lock()
+		queues = append(queues, q)
+		mu.Unlock()
+	}
+
+	release, err := a.acquire(context.Background(), "scrape", []Resource{ResourceDownloads}, ConflictQueue)
+	require.NoError(t, err)
+
+	// Liquidity only needs reports, so it runs next to the scraper
+	releaseLiquidity, err := a.acquire(context.Background(), "liquidity", []Resource{ResourceReports}, ConflictQueue)
+	require.NoError(t, err)
+	releaseLiquidity()
+
+	done := make(chan error, 1)
+	go func() {
+		releaseSecond, err := a.acquire(context.Background(), "pipeline", []Resource{ResourceDownloads, ResourceReports}, ConflictQueue)
+		if err == nil {
+			releaseSecond()
+		}
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool { return len(a.queue()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []QueueStatus{{OperationID: "pipeline", Position: 1, BlockedBy: []string{"scrape"}}}, a.queue())
+
+	release()
+	require.NoError(t, waitGranted(t, done))
+	assert.Empty(t, a.queue())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, queues)
+	assert.Equal(t, 1, queues[0][0].Position)
+	assert.Empty(t, queues[len(queues)-1], "the last broadcast has an empty queue")
+}
+
+func TestAdmissionRejectsConflicts(t *testing.T) {
+	a := newAdmission(func() int { return 0 })
+	release, err := a.acquire(context.Background(), "first", []Resource{ResourceReports}, ConflictQueue)
+	require.NoError(t, err)
+	defer release()
+
+	_, err = a.acquire(context.Background(), "second", []Resource{ResourceReports}, ConflictReject)
+	var conflict *OperationConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []string{"first"}, conflict.BlockedBy)
+	assert.Equal(t, []Resource{ResourceReports}, conflict.Resources)
+	assert.Empty(t, a.queue(), "rejected operations are not queued")
+}
+
+func TestAdmissionLimitsConcurrentOperations(t *testing.T) {
+	a := newAdmission(func() int { return 1 })
+	release, err := a.acquire(context.Background(), "scrape", []Resource{ResourceDownloads}, ConflictQueue)
+	require.NoError(t, err)
+
+	blockedBy, shared := a.conflicts("", []Resource{ResourceReports})
+	assert.Equal(t, []string{"scrape"}, blockedBy, "the limit blocks operations without shared resources")
+	assert.Empty(t, shared)
+
+	done := make(chan error, 1)
+	go func() {
+		releaseSecond, err := a.acquire(context.Background(), "liquidity", []Resource{ResourceReports}, ConflictQueue)
+		if err == nil {
+			releaseSecond()
+		}
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return len(a.queue()) == 1 }, time.Second, 5*time.Millisecond)
+
+	release()
+	release() // releasing twice is harmless
+	require.NoError(t, waitGranted(t, done))
+}
+
+func TestAdmissionKeepsArrivalOrderForConflicts(t *testing.T) {
+	a := newAdmission(func() int { return 0 })
+	release, err := a.acquire(context.Background(), "running", []Resource{ResourceDownloads, ResourceReports}, ConflictQueue)
+	require.NoError(t, err)
+
+	order := make(chan string, 2)
+	start := func(id string, resources []Resource) {
+		go func() {
+			releaseMe, err := a.acquire(context.Background(), id, resources, ConflictQueue)
+			if err != nil {
+				return
+			}
+			order <- id
+			time.Sleep(10 * time.Millisecond)
+			releaseMe()
+		}()
+	}
+	start("first", []Resource{ResourceReports})
+	require.Eventually(t, func() bool { return len(a.queue()) == 1 }, time.Second, 5*time.Millisecond)
+	start("second", []Resource{ResourceReports})
+	require.Eventually(t, func() bool { return len(a.queue()) == 2 }, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, []string{"first", "running"}, a.queue()[1].BlockedBy, "later arrivals wait for earlier conflicting ones")
+
+	release()
+	assert.Equal(t, "first", <-order)
+	assert.Equal(t, "second", <-order)
+}
+
+func TestAdmissionDequeueAndCancel(t *testing.T) {
+	a := newAdmission(func() int { return 0 })
+	release, err := a.acquire(context.Background(), "running", []Resource{ResourceReports}, ConflictQueue)
+	require.NoError(t, err)
+	defer release()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.acquire(context.Background(), "queued", []Resource{ResourceReports}, ConflictQueue)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return len(a.queue()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.True(t, a.dequeue("queued"))
+	assert.ErrorIs(t, waitGranted(t, done), ErrOperationDequeued)
+	assert.False(t, a.dequeue("queued"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, err := a.acquire(ctx, "timed", []Resource{ResourceReports}, ConflictQueue)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return len(a.queue()) == 1 }, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, waitGranted(t, done), context.Canceled)
+	assert.Empty(t, a.queue())
+}
+
+func TestManagerConflictsAndPolicy(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	release, err := manager.Acquire(context.Background(), "scrape", []string{StageIDScraping}, ConflictQueue)
+	require.NoError(t, err)
+	defer release()
+
+	blockedBy, _ := manager.Conflicts(nil)
+	assert.Equal(t, []string{"scrape"}, blockedBy)
+
+	policy, err := manager.ResolveConflictPolicy(map[string]interface{}{ParameterOnConflict: "reject"})
+	require.NoError(t, err)
+	assert.Equal(t, ConflictReject, policy)
+
+	policy, err = manager.ResolveConflictPolicy(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConflictQueue, policy, "the configured policy applies without the parameter")
+}
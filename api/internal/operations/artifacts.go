@@ -0,0 +1,382 @@
+package operations
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArtifactManifestFile is the name of the manifest written for each run in
+// its directory under the operations directory
+const ArtifactManifestFile = "manifest.json"
+
+// ArtifactManifest lists every file an operation run created or rewrote, so
+// downstream systems can verify what they consume and the UI can show what a
+// run produced. It is written to data/operations/{id}/manifest.json after
+// every step and when the run finishes.
+type ArtifactManifest struct {
+	OperationID string         `json:"operation_id"`
+	Status      string         `json:"status"` // "running", "completed" or "failed"
+	Error       string         `json:"error,omitempty"`
+	StartedAt   time.Time      `json:"started_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	Steps       []ArtifactStep `json:"steps"`
+	Artifacts   []Artifact     `json:"artifacts"`
+}
+
+// ArtifactStep is one completed step of the run
+type ArtifactStep struct {
+	StageID     string    `json:"stage_id"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Artifacts   int       `json:"artifacts"`
+}
+
+// Artifact is one file produced by a step. Path and Sources are relative to
+// the data directory and use forward slashes.
+type Artifact struct {
+	Path       string    `json:"path"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Rows       *int      `json:"rows,omitempty"` // Data rows below the header, CSV files only
+	Stage      string    `json:"stage"`
+	Sources    []string  `json:"sources,omitempty"`
+	ModifiedAt time.Time `json:"modified_at"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// stageInputs lists the directories each stage reads. An artifact's sources
+// are the files earlier steps of the same run produced there. Stages not
+// listed are assumed to read both.
+var stageInputs = map[string][]Resource{
+	StageIDScraping:   {},
+	StageIDProcessing: {ResourceDownloads},
+	StageIDIndices:    {ResourceDownloads},
+	StageIDLiquidity:  {ResourceReports},
+}
+
+// fileStamp is what a snapshot remembers of a file to spot rewrites
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// ArtifactSnapshot is the state of a step's directories before it runs
+type ArtifactSnapshot struct {
+	stageID   string
+	startedAt time.Time
+	files     map[string]fileStamp
+}
+
+// ArtifactRecorder tracks the files each step of an operation creates by
+// comparing its directories before and after the step. Operations writing
+// the same directories never run at the same time (see Acquire), so every
+// change belongs to the step that ran. A nil recorder records nothing.
+type ArtifactRecorder struct {
+	dir     string // Manifests live in dir/{operationID}
+	dataDir string
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu   sync.Mutex
+	runs map[string]*ArtifactManifest
+}
+
+// NewArtifactRecorder creates a recorder writing manifests under dir for
+// artifacts found in dataDir
+func NewArtifactRecorder(dir, dataDir string, logger *slog.Logger) *ArtifactRecorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ArtifactRecorder{
+		dir:     dir,
+		dataDir: dataDir,
+		logger:  logger,
+		now:     time.Now,
+		runs:    make(map[string]*ArtifactManifest),
+	}
+}
+
+// BeginStep snapshots the directories the stage writes
+func (r *ArtifactRecorder) BeginStep(stageID string) *ArtifactSnapshot {
+	if r == nil {
+		return nil
+	}
+	return &ArtifactSnapshot{
+		stageID:   stageID,
+		startedAt: r.now(),
+		files:     r.scan(ResourcesForSteps([]string{stageID})),
+	}
+}
+
+// CompleteStep records the files the step created or rewrote since before was
+// taken and saves the operation's manifest
+func (r *ArtifactRecorder) CompleteStep(operationID string, before *ArtifactSnapshot) error {
+	if r == nil || before == nil {
+		return nil
+	}
+
+	after := r.scan(ResourcesForSteps([]string{before.stageID}))
+	var changed []string
+	for path, stamp := range after {
+		if prev, ok := before.files[path]; !ok || prev != stamp {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.run(operationID)
+	sources := r.sources(m, before.stageID)
+	recordedAt := r.now()
+
+	// A file rewritten by a later step keeps only its latest entry
+	kept := m.Artifacts[:0]
+	rewritten := make(map[string]bool, len(changed))
+	for _, path := range changed {
+		rewritten[path] = true
+	}
+	for _, a := range m.Artifacts {
+		if !rewritten[a.Path] {
+			kept = append(kept, a)
+		}
+	}
+	m.Artifacts = kept
+
+	for _, path := range changed {
+		artifact, err := r.describe(path, after[path])
+		if err != nil {
+			// The file may be gone again, e.g. a temp file renamed in place
+			r.logger.Warn("skipping artifact",
+				slog.String("operation_id", operationID),
+				slog.String("path", path),
+				slog.String("error", err.Error()))
+			continue
+		}
+		artifact.Stage = before.stageID
+		artifact.Sources = sources
+		artifact.RecordedAt = recordedAt
+		m.Artifacts = append(m.Artifacts, artifact)
+	}
+
+	m.Steps = append(m.Steps, ArtifactStep{
+		StageID:     before.stageID,
+		StartedAt:   before.startedAt,
+		CompletedAt: recordedAt,
+		Artifacts:   len(changed),
+	})
+	return r.save(m)
+}
+
+// Finish marks the run as completed or failed and saves its manifest. Runs
+// without any completed step still get a manifest, with no artifacts.
+func (r *ArtifactRecorder) Finish(operationID string, runErr error) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, running := r.runs[operationID]; !running {
+		// Keep the manifest of a run that was already finished
+		if prev, err := r.Load(operationID); err == nil && prev.CompletedAt != nil {
+			return nil
+		}
+	}
+	m := r.run(operationID)
+	completedAt := r.now()
+	m.CompletedAt = &completedAt
+	m.Status = "completed"
+	if runErr != nil {
+		m.Status = "failed"
+		m.Error = runErr.Error()
+	}
+	delete(r.runs, operationID)
+	return r.save(m)
+}
+
+// Load reads the manifest of an operation, finished or still running
+func (r *ArtifactRecorder) Load(operationID string) (*ArtifactManifest, error) {
+	if r == nil {
+		return nil, fmt.Errorf("operation %s: %w", operationID, ErrArtifactsNotFound)
+	}
+	path, err := r.manifestPath(operationID)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrArtifactsNotFound)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("operation %s: %w", operationID, ErrArtifactsNotFound)
+		}
+		return nil, fmt.Errorf("failed to read artifact manifest: %w", err)
+	}
+
+	var m ArtifactManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse artifact manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// run returns the manifest of a run in progress; callers hold r.mu
+func (r *ArtifactRecorder) run(operationID string) *ArtifactManifest {
+	m, ok := r.runs[operationID]
+	if !ok {
+		m = &ArtifactManifest{
+			OperationID: operationID,
+			Status:      "running",
+			StartedAt:   r.now(),
+			Steps:       []ArtifactStep{},
+			Artifacts:   []Artifact{},
+		}
+		// A resumed operation continues the manifest of its first attempt
+		if prev, err := r.Load(operationID); err == nil && prev.CompletedAt == nil {
+			m.StartedAt = prev.StartedAt
+			m.Steps = prev.Steps
+			m.Artifacts = prev.Artifacts
+		}
+		r.runs[operationID] = m
+	}
+	return m
+}
+
+// sources returns the artifacts of the run so far that the stage reads
+func (r *ArtifactRecorder) sources(m *ArtifactManifest, stageID string) []string {
+	inputs, ok := stageInputs[stageID]
+	if !ok {
+		inputs = []Resource{ResourceDownloads, ResourceReports}
+	}
+
+	var sources []string
+	for _, a := range m.Artifacts {
+		for _, res := range inputs {
+			if strings.HasPrefix(a.Path, string(res)+"/") {
+				sources = append(sources, a.Path)
+				break
+			}
+		}
+	}
+	return sources
+}
+
+// scan stamps every file below the resource directories, keyed by path
+// relative to the data directory
+func (r *ArtifactRecorder) scan(resources []Resource) map[string]fileStamp {
+	files := make(map[string]fileStamp)
+	for _, res := range resources {
+		root := filepath.Join(r.dataDir, string(res))
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || strings.HasSuffix(d.Name(), ".tmp") {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			rel, err := filepath.Rel(r.dataDir, path)
+			if err != nil {
+				return nil
+			}
+			files[filepath.ToSlash(rel)] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+	}
+	return files
+}
+
+// describe hashes an artifact and counts its rows
+func (r *ArtifactRecorder) describe(path string, stamp fileStamp) (Artifact, error) {
+	f, err := os.Open(filepath.Join(r.dataDir, filepath.FromSlash(path)))
+	if err != nil {
+		return Artifact{}, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	var lines lineCounter
+	var w io.Writer = hash
+	csv := strings.EqualFold(filepath.Ext(path), ".csv")
+	if csv {
+		w = io.MultiWriter(hash, &lines)
+	}
+	size, err := io.Copy(w, f)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	artifact := Artifact{
+		Path:       path,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		Size:       size,
+		ModifiedAt: stamp.modTime,
+	}
+	if csv {
+		rows := lines.rows()
+		artifact.Rows = &rows
+	}
+	return artifact, nil
+}
+
+// lineCounter counts the lines written to it
+type lineCounter struct {
+	lines   int
+	partial bool // Last line has no trailing newline
+}
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		c.lines += bytes.Count(p, []byte{'\n'})
+		c.partial = p[len(p)-1] != '\n'
+	}
+	return len(p), nil
+}
+
+// rows returns the number of lines below the header
+func (c *lineCounter) rows() int {
+	lines := c.lines
+	if c.partial {
+		lines++
+	}
+	if lines == 0 {
+		return 0
+	}
+	return lines - 1
+}
+
+// save writes the manifest; callers hold r.mu
+func (r *ArtifactRecorder) save(m *ArtifactManifest) error {
+	path, err := r.manifestPath(m.OperationID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSONAtomic(path, m); err != nil {
+		return fmt.Errorf("failed to save artifact manifest: %w", err)
+	}
+	return nil
+}
+
+// manifestPath returns where the manifest of an operation lives
+func (r *ArtifactRecorder) manifestPath(operationID string) (string, error) {
+	// Operation IDs come from request IDs and URLs; keep them inside dir
+	if operationID == "" || strings.ContainsAny(operationID, `/\`) || strings.Contains(operationID, "..") {
+		return "", fmt.Errorf("invalid operation ID %q", operationID)
+	}
+	return filepath.Join(r.dir, operationID, ArtifactManifestFile), nil
+}
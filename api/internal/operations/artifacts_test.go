@@ -0,0 +1,152 @@
+package operations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeArtifactFile(t *testing.T, dataDir, path, content string) string {
+	t.Helper()
+	full := filepath.Join(dataDir, filepath.FromSlash(path))
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+	require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestArtifactRecorder(t *testing.T) (*ArtifactRecorder, string) {
+	dataDir := t.TempDir()
+	recorder := NewArtifactRecorder(filepath.Join(dataDir, "operations"), dataDir, nil)
+
+	clock := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return recorder, dataDir
+}
+
+func TestArtifactRecorder_RecordsStepOutputs(t *testing.T) {
+	recorder, dataDir := newTestArtifactRecorder(t)
+	writeArtifactFile(t, dataDir, "downloads/2025 03 01 ISX Daily Report.xlsx", "old")
+
+	before := recorder.BeginStep(StageIDScraping)
+	xlsxSum := writeArtifactFile(t, dataDir, "downloads/2025 03 02 ISX Daily Report.xlsx", "workbook")
+	writeArtifactFile(t, dataDir, "downloads/partial.xlsx.tmp", "ignored")
+	require.NoError(t, recorder.CompleteStep("op-1", before))
+
+	before = recorder.BeginStep(StageIDProcessing)
+	csvSum := writeArtifactFile(t, dataDir, "reports/isx_combined_data.csv", "Date,Symbol\n2025-03-02,BBOB\n2025-03-02,TASC")
+	require.NoError(t, recorder.CompleteStep("op-1", before))
+
+	m, err := recorder.Load("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, "running", m.Status)
+	assert.Nil(t, m.CompletedAt)
+	require.Len(t, m.Steps, 2)
+	assert.Equal(t, StageIDScraping, m.Steps[0].StageID)
+	assert.Equal(t, 1, m.Steps[0].Artifacts)
+
+	require.Len(t, m.Artifacts, 2)
+	xlsx, csv := m.Artifacts[0], m.Artifacts[1]
+	assert.Equal(t, "downloads/2025 03 02 ISX Daily Report.xlsx", xlsx.Path)
+	assert.Equal(t, xlsxSum, xlsx.SHA256)
+	assert.Equal(t, int64(len("workbook")), xlsx.Size)
+	assert.Nil(t, xlsx.Rows, "rows are only counted for CSV files")
+	assert.Empty(t, xlsx.Sources)
+
+	assert.Equal(t, "reports/isx_combined_data.csv", csv.Path)
+	assert.Equal(t, csvSum, csv.SHA256)
+	assert.Equal(t, StageIDProcessing, csv.Stage)
+	require.NotNil(t, csv.Rows)
+	assert.Equal(t, 2, *csv.Rows)
+	assert.Equal(t, []string{xlsx.Path}, csv.Sources)
+
+	require.NoError(t, recorder.Finish("op-1", nil))
+	m, err = recorder.Load("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", m.Status)
+	require.NotNil(t, m.CompletedAt)
+	assert.FileExists(t, filepath.Join(dataDir, "operations", "op-1", ArtifactManifestFile))
+}
+
+func TestArtifactRecorder_RewrittenFileKeepsLatestEntry(t *testing.T) {
+	recorder, dataDir := newTestArtifactRecorder(t)
+
+	before := recorder.BeginStep(StageIDProcessing)
+	writeArtifactFile(t, dataDir, "reports/indexes.csv", "Date,ISX60\n")
+	require.NoError(t, recorder.CompleteStep("op-1", before))
+
+	before = recorder.BeginStep(StageIDIndices)
+	sum := writeArtifactFile(t, dataDir, "reports/indexes.csv", "Date,ISX60\n2025-03-02,910.5\n")
+	require.NoError(t, recorder.CompleteStep("op-1", before))
+
+	m, err := recorder.Load("op-1")
+	require.NoError(t, err)
+	require.Len(t, m.Artifacts, 1)
+	assert.Equal(t, StageIDIndices, m.Artifacts[0].Stage)
+	assert.Equal(t, sum, m.Artifacts[0].SHA256)
+	assert.Equal(t, 1, *m.Artifacts[0].Rows)
+}
+
+func TestArtifactRecorder_ResumeAndFailure(t *testing.T) {
+	recorder, dataDir := newTestArtifactRecorder(t)
+
+	before := recorder.BeginStep(StageIDScraping)
+	writeArtifactFile(t, dataDir, "downloads/a.xlsx", "a")
+	require.NoError(t, recorder.CompleteStep("op-1", before))
+
+	// A restarted server picks up the unfinished manifest
+	resumed := NewArtifactRecorder(recorder.dir, dataDir, nil)
+	before = resumed.BeginStep(StageIDLiquidity)
+	writeArtifactFile(t, dataDir, "reports/liquidity.csv", "Symbol\nBBOB\n")
+	require.NoError(t, resumed.CompleteStep("op-1", before))
+	require.NoError(t, resumed.Finish("op-1", errors.New("liquidity failed")))
+
+	m, err := resumed.Load("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", m.Status)
+	assert.Equal(t, "liquidity failed", m.Error)
+	assert.Len(t, m.Steps, 2)
+	require.Len(t, m.Artifacts, 2)
+	assert.Empty(t, m.Artifacts[1].Sources, "liquidity only reads reports")
+}
+
+func TestArtifactRecorder_Load(t *testing.T) {
+	recorder, _ := newTestArtifactRecorder(t)
+
+	_, err := recorder.Load("missing")
+	assert.ErrorIs(t, err, ErrArtifactsNotFound)
+	_, err = recorder.Load("../etc")
+	assert.ErrorIs(t, err, ErrArtifactsNotFound)
+
+	var nilRecorder *ArtifactRecorder
+	assert.Nil(t, nilRecorder.BeginStep(StageIDScraping))
+	assert.NoError(t, nilRecorder.CompleteStep("op-1", nil))
+	assert.NoError(t, nilRecorder.Finish("op-1", nil))
+	_, err = nilRecorder.Load("op-1")
+	assert.ErrorIs(t, err, ErrArtifactsNotFound)
+}
+
+func TestArtifactRecorder_FinishTwiceKeepsManifest(t *testing.T) {
+	recorder, dataDir := newTestArtifactRecorder(t)
+
+	before := recorder.BeginStep(StageIDScraping)
+	writeArtifactFile(t, dataDir, "downloads/a.xlsx", "a")
+	require.NoError(t, recorder.CompleteStep("op-1", before))
+	require.NoError(t, recorder.Finish("op-1", nil))
+	require.NoError(t, recorder.Finish("op-1", errors.New("late failure")))
+
+	m, err := recorder.Load("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", m.Status)
+	assert.Len(t, m.Artifacts, 1)
+}
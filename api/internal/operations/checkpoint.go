@@ -0,0 +1,243 @@
+package operations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OperationCheckpoint is what the job queue writes to disk for an operation
+// still running at shutdown. The job carries the original request, the
+// manifest records which stages finished and the data they produced, and the
+// snapshot keeps the per-step progress the UI last showed.
+type OperationCheckpoint struct {
+	OperationID string             `json:"operation_id"`
+	Job         *Job               `json:"job"`
+	Manifest    *PipelineManifest  `json:"manifest"`
+	Progress    *OperationSnapshot `json:"progress,omitempty"`
+	SavedAt     time.Time          `json:"saved_at"`
+}
+
+// CompletedStages returns the IDs of the stages that do not need to run again
+func (c *OperationCheckpoint) CompletedStages() []string {
+	var completed []string
+	if c.Manifest == nil {
+		return completed
+	}
+	for _, stage := range c.Manifest.CompletedStages {
+		if stage.Status == "completed" {
+			completed = append(completed, stage.StageID)
+		}
+	}
+	return completed
+}
+
+// SetCheckpointDir enables checkpoints of interrupted operations in dir.
+// Without it the queue does not persist anything and Resume always fails.
+func (q *JobQueue) SetCheckpointDir(dir string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.checkpointDir = dir
+}
+
+// Resume re-queues an operation from its checkpoint. Stages the manifest
+// records as completed are skipped, so the pipeline restarts at the first
+// incomplete stage. The checkpoint is removed once the resumed job succeeds.
+func (q *JobQueue) Resume(operationID string) (*Job, error) {
+	checkpoint, err := q.LoadCheckpoint(operationID)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.RLock()
+	for _, active := range q.active {
+		if active.OperationID == operationID {
+			q.mu.RUnlock()
+			return nil, fmt.Errorf("operation %s: %w", operationID, ErrOperationRunning)
+		}
+	}
+	q.mu.RUnlock()
+
+	if checkpoint.Job == nil || checkpoint.Manifest == nil {
+		return nil, fmt.Errorf("checkpoint for operation %s is incomplete", operationID)
+	}
+
+	// Hand the checkpointed manifest to the worker in place of a fresh one
+	manifest := checkpoint.Manifest
+	manifest.Status = "running"
+	manifest.Error = ""
+	if err := q.store.UpdateManifest(manifest); err != nil {
+		if err := q.store.CreateManifest(manifest); err != nil {
+			return nil, fmt.Errorf("failed to restore manifest: %w", err)
+		}
+	}
+
+	metadata := make(map[string]interface{}, len(checkpoint.Job.Metadata)+2)
+	for k, v := range checkpoint.Job.Metadata {
+		metadata[k] = v
+	}
+	metadata["resumed_from_job"] = checkpoint.Job.ID
+	metadata["completed_stages"] = checkpoint.CompletedStages()
+
+	job := &Job{
+		ID:          fmt.Sprintf("%s-resume-%d", operationID, time.Now().Unix()),
+		OperationID: operationID,
+		StageID:     checkpoint.Job.StageID,
+		StageName:   checkpoint.Job.StageName,
+		Request:     checkpoint.Job.Request,
+		Metadata:    metadata,
+	}
+
+	if err := q.Enqueue(job); err != nil {
+		return nil, err
+	}
+
+	q.logger.Info("operation resumed from checkpoint",
+		slog.String("operation_id", operationID),
+		slog.String("job_id", job.ID),
+		slog.Any("completed_stages", metadata["completed_stages"]),
+		slog.Time("saved_at", checkpoint.SavedAt))
+
+	return job, nil
+}
+
+// LoadCheckpoint reads the checkpoint of an interrupted operation
+func (q *JobQueue) LoadCheckpoint(operationID string) (*OperationCheckpoint, error) {
+	path, err := q.checkpointPath(operationID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("operation %s: %w", operationID, ErrCheckpointNotFound)
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint OperationCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// checkpointActive saves every job that is still running
+func (q *JobQueue) checkpointActive() {
+	q.mu.RLock()
+	jobs := make([]*Job, 0, len(q.active))
+	for _, job := range q.active {
+		jobs = append(jobs, job)
+	}
+	q.mu.RUnlock()
+
+	for _, job := range jobs {
+		manifest, err := q.store.GetManifestByOperationID(job.OperationID)
+		if err != nil {
+			q.logger.Warn("no manifest to checkpoint",
+				slog.String("operation_id", job.OperationID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		q.saveCheckpoint(job, manifest)
+	}
+}
+
+// saveCheckpoint writes the checkpoint for a job, replacing any earlier one
+func (q *JobQueue) saveCheckpoint(job *Job, manifest *PipelineManifest) {
+	path, err := q.checkpointPath(job.OperationID)
+	if err != nil {
+		return
+	}
+
+	jobCopy := *job
+	checkpoint := OperationCheckpoint{
+		OperationID: job.OperationID,
+		Job:         &jobCopy,
+		Manifest:    manifest.Clone(),
+		SavedAt:     time.Now(),
+	}
+	if snapshot, ok := q.manager.GetBroadcaster().GetSnapshot(job.OperationID); ok {
+		checkpoint.Progress = snapshot
+	}
+
+	if err := writeJSONAtomic(path, checkpoint); err != nil {
+		q.logger.Error("failed to save operation checkpoint",
+			slog.String("operation_id", job.OperationID),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	q.logger.Info("operation checkpoint saved",
+		slog.String("operation_id", job.OperationID),
+		slog.String("path", path),
+		slog.Any("completed_stages", checkpoint.CompletedStages()))
+}
+
+// removeCheckpoint deletes the checkpoint once an operation has finished
+func (q *JobQueue) removeCheckpoint(operationID string) {
+	path, err := q.checkpointPath(operationID)
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		q.logger.Warn("failed to remove operation checkpoint",
+			slog.String("operation_id", operationID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// checkpointPath returns where the checkpoint of an operation lives
+func (q *JobQueue) checkpointPath(operationID string) (string, error) {
+	q.mu.RLock()
+	dir := q.checkpointDir
+	q.mu.RUnlock()
+
+	if dir == "" {
+		return "", fmt.Errorf("operation %s: %w", operationID, ErrCheckpointNotFound)
+	}
+	// Operation IDs come from request IDs and URLs; keep them inside dir
+	if operationID == "" || strings.ContainsAny(operationID, `/\`) || strings.Contains(operationID, "..") {
+		return "", fmt.Errorf("invalid operation ID %q", operationID)
+	}
+	return filepath.Join(dir, operationID+".json"), nil
+}
+
+// stopping reports whether Stop has been called
+func (q *JobQueue) stopping() bool {
+	select {
+	case <-q.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeJSONAtomic writes v to path through a temporary file so a crash never
+// leaves a half-written checkpoint behind
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package operations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStage is a pipeline stage that only records that it ran
+type recordingStage struct {
+	id   string
+	deps []string
+
+	mu   *sync.Mutex
+	runs *[]string
+}
+
+func (s *recordingStage) ID() string                        { return s.id }
+func (s *recordingStage) Name() string                      { return s.id }
+func (s *recordingStage) GetDependencies() []string         { return s.deps }
+func (s *recordingStage) Validate(*OperationState) error    { return nil }
+func (s *recordingStage) RequiredInputs() []DataRequirement { return nil }
+func (s *recordingStage) ProducedOutputs() []DataOutput     { return nil }
+func (s *recordingStage) CanRun(*PipelineManifest) bool     { return true }
+
+func (s *recordingStage) Execute(ctx context.Context, state *OperationState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.runs = append(*s.runs, s.id)
+	return nil
+}
+
+func newCheckpointTestQueue(t *testing.T) (*JobQueue, func() []string) {
+	var mu sync.Mutex
+	var runs []string
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(&recordingStage{id: "fetch", mu: &mu, runs: &runs}))
+	require.NoError(t, registry.Register(&recordingStage{id: "build", deps: []string{"fetch"}, mu: &mu, runs: &runs}))
+
+	queue := NewJobQueue(1, NewMemoryJobStore(), NewManager(nil, registry, nil), nil)
+	queue.SetCheckpointDir(t.TempDir())
+
+	return queue, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), runs...)
+	}
+}
+
+func TestJobQueue_ResumeSkipsCompletedStages(t *testing.T) {
+	queue, runs := newCheckpointTestQueue(t)
+
+	// The server stopped after "fetch" finished and while "build" was running
+	request := &OperationRequest{ID: "op-1", Mode: "full", FromDate: "2025-01-01", ToDate: "2025-01-31"}
+	interrupted := &Job{ID: "op-1", OperationID: "op-1", StageID: "full_pipeline", Request: request}
+	manifest := NewPipelineManifest("op-1", request.FromDate, request.ToDate)
+	manifest.RecordStageStart("fetch", "fetch")
+	manifest.RecordStageCompletion("fetch", []string{"raw_files"}, nil)
+	manifest.RecordStageStart("build", "build")
+	queue.saveCheckpoint(interrupted, manifest)
+
+	checkpoint, err := queue.LoadCheckpoint("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fetch"}, checkpoint.CompletedStages())
+	assert.Equal(t, "2025-01-01", checkpoint.Job.Request.FromDate)
+
+	queue.Start(context.Background())
+	defer queue.Stop(5 * time.Second)
+
+	job, err := queue.Resume("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, "op-1", job.OperationID)
+	assert.Equal(t, "full_pipeline", job.StageID)
+	assert.Equal(t, "op-1", job.Metadata["resumed_from_job"])
+
+	require.Eventually(t, func() bool {
+		current, err := queue.GetJob(job.ID)
+		return err == nil && current.Status == JobStatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"build"}, runs(), "only the incomplete stage runs again")
+
+	_, err = queue.LoadCheckpoint("op-1")
+	assert.ErrorIs(t, err, ErrCheckpointNotFound, "checkpoint is removed after success")
+}
+
+func TestJobQueue_ResumeErrors(t *testing.T) {
+	queue, _ := newCheckpointTestQueue(t)
+
+	_, err := queue.Resume("missing")
+	assert.ErrorIs(t, err, ErrCheckpointNotFound)
+
+	_, err = queue.Resume("../op")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCheckpointNotFound)
+
+	// Without a checkpoint directory nothing can be resumed
+	queue.SetCheckpointDir("")
+	_, err = queue.Resume("op-1")
+	assert.ErrorIs(t, err, ErrCheckpointNotFound)
+}
+
+func TestJobQueue_StopCheckpointsActiveJobs(t *testing.T) {
+	queue, _ := newCheckpointTestQueue(t)
+	dir := t.TempDir()
+	queue.SetCheckpointDir(dir)
+
+	job := &Job{ID: "op-2", OperationID: "op-2", StageID: "full_pipeline", Request: &OperationRequest{ID: "op-2"}}
+	manifest := NewPipelineManifest("op-2", "", "")
+	require.NoError(t, queue.store.CreateManifest(manifest))
+	queue.active[job.ID] = job
+
+	require.NoError(t, queue.Stop(time.Second))
+
+	_, err := os.Stat(filepath.Join(dir, "op-2.json"))
+	require.NoError(t, err)
+
+	delete(queue.active, job.ID)
+	_, err = queue.Resume("op-2")
+	require.NoError(t, err, "an operation checkpointed at shutdown can be resumed")
+}
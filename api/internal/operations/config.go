@@ -15,12 +15,24 @@ type Config struct {
 	// Retry configuration for steps
 	RetryConfig RetryConfig `json:"retry_config"`
 
+	// Step-specific retry policies overriding RetryConfig
+	StageRetryPolicies map[string]RetryConfig `json:"stage_retry_policies,omitempty"`
+
 	// Whether to continue on Step failures
 	ContinueOnError bool `json:"continue_on_error"`
 
 	// Maximum concurrent steps (for parallel execution)
 	MaxConcurrency int `json:"max_concurrency"`
 
+	// Maximum operations running at once; operations over the limit, or
+	// sharing a data directory with a running one, wait in a queue. Zero
+	// leaves only the directory locks.
+	MaxConcurrentOperations int `json:"max_concurrent_operations"`
+
+	// What an operation that cannot start at once does, unless its request
+	// sets the on_conflict parameter
+	ConflictPolicy ConflictPolicy `json:"conflict_policy"`
+
 	// Whether to enable checkpointing
 	EnableCheckpoints bool `json:"enable_checkpoints"`
 
@@ -44,6 +56,8 @@ func NewConfig() *Config {
 		RetryConfig:       NewRetryConfig(),
 		ContinueOnError:   false,
 		MaxConcurrency:    1,
+		MaxConcurrentOperations: 1,
+		ConflictPolicy:    ConflictQueue,
 		EnableCheckpoints: false,
 		CheckpointDir:     "data/checkpoints",
 		StepConfigs:      make(map[string]interface{}),
@@ -66,6 +80,23 @@ func (c *Config) SetStageTimeout(stageID string, timeout time.Duration) {
 	c.StageTimeouts[stageID] = timeout
 }
 
+// GetRetryConfig returns the retry policy for a specific Step, falling back
+// to the operation-wide RetryConfig
+func (c *Config) GetRetryConfig(stageID string) RetryConfig {
+	if policy, ok := c.StageRetryPolicies[stageID]; ok {
+		return policy
+	}
+	return c.RetryConfig
+}
+
+// SetRetryConfig sets the retry policy for a specific Step
+func (c *Config) SetRetryConfig(stageID string, policy RetryConfig) {
+	if c.StageRetryPolicies == nil {
+		c.StageRetryPolicies = make(map[string]RetryConfig)
+	}
+	c.StageRetryPolicies[stageID] = policy
+}
+
 // GetStepConfig returns the configuration for a specific Step
 func (c *Config) GetStepConfig(stageID string) (interface{}, bool) {
 	if c.StepConfigs == nil {
@@ -174,6 +205,12 @@ func (b *ConfigBuilder) WithRetryConfig(config RetryConfig) *ConfigBuilder {
 	return b
 }
 
+// WithStageRetryPolicy sets the retry policy for a Step
+func (b *ConfigBuilder) WithStageRetryPolicy(stageID string, policy RetryConfig) *ConfigBuilder {
+	b.config.SetRetryConfig(stageID, policy)
+	return b
+}
+
 // WithContinueOnError sets whether to continue on errors
 func (b *ConfigBuilder) WithContinueOnError(continueOnError bool) *ConfigBuilder {
 	b.config.ContinueOnError = continueOnError
@@ -44,6 +44,13 @@
 //	config := operation.NewConfigBuilder().
 //		WithExecutionMode(operation.ExecutionModeSequential).
 //		WithRetryConfig(operation.DefaultRetryConfig()).
+//		WithStageRetryPolicy(operation.StageIDScraping, operation.RetryConfig{
+//			MaxAttempts:  3,
+//			InitialDelay: 5 * time.Second,
+//			MaxDelay:     time.Minute,
+//			Multiplier:   2,
+//			RetryOn:      []operation.ErrorClass{operation.ErrorClassNetwork},
+//		}).
 //		Build()
 //	manager.SetConfig(config)
 //
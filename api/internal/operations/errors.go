@@ -1,7 +1,11 @@
 package operations
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strings"
 )
 
 // ErrorType represents the type of operation error
@@ -126,6 +130,71 @@ func IsRetryable(err error) bool {
 	return false
 }
 
+// ErrorClass groups step failures by cause so retry policies can tell
+// transient failures from permanent ones
+type ErrorClass string
+
+const (
+	// ErrorClassNetwork covers connection, DNS and HTTP gateway failures
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassTimeout covers deadlines and navigation timeouts
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassPermanent covers everything a retry will not fix
+	ErrorClassPermanent ErrorClass = "permanent"
+)
+
+// TransientErrorClasses are the classes retried when a policy lists none
+var TransientErrorClasses = []ErrorClass{ErrorClassNetwork, ErrorClassTimeout}
+
+// Subprocess failures only reach the manager as text (exit status plus
+// stderr), so they are classified by these markers
+var (
+	timeoutMarkers = []string{
+		"timeout", "timed out", "deadline exceeded", "err_timed_out",
+	}
+	networkMarkers = []string{
+		"connection refused", "connection reset", "connection aborted", "broken pipe",
+		"no such host", "network is unreachable", "host is unreachable", "i/o timeout",
+		"tls handshake", "unexpected eof", "net::err_", "502 bad gateway",
+		"503 service unavailable", "504 gateway timeout", "temporary failure in name resolution",
+	}
+)
+
+// ClassifyError reports the class of a step failure. Cancellation is
+// permanent: a stopped operation must not come back.
+func ClassifyError(err error) ErrorClass {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return ErrorClassPermanent
+	}
+	var opErr *OperationError
+	if errors.As(err, &opErr) && opErr.Type == ErrorTypeTimeout {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, marker := range networkMarkers {
+		if strings.Contains(message, marker) {
+			return ErrorClassNetwork
+		}
+	}
+	for _, marker := range timeoutMarkers {
+		if strings.Contains(message, marker) {
+			return ErrorClassTimeout
+		}
+	}
+	return ErrorClassPermanent
+}
+
 // GetErrorType returns the type of the error
 func GetErrorType(err error) ErrorType {
 	if err == nil {
@@ -222,4 +291,28 @@ var (
 		Type:    ErrorTypeInvalidState,
 		Message: "operation is not running",
 	}
+
+	// ErrOperationRunning is returned when trying to resume a operation that is still running
+	ErrOperationRunning = &OperationError{
+		Type:    ErrorTypeInvalidState,
+		Message: "operation is already running",
+	}
+
+	// ErrCheckpointNotFound is returned when a operation has no checkpoint to resume from
+	ErrCheckpointNotFound = &OperationError{
+		Type:    ErrorTypeNotFound,
+		Message: "no checkpoint to resume from",
+	}
+
+	// ErrArtifactsNotFound is returned when a operation has no artifact manifest
+	ErrArtifactsNotFound = &OperationError{
+		Type:    ErrorTypeNotFound,
+		Message: "no artifact manifest for operation",
+	}
+
+	// ErrRunNotFound is returned when the history has no run of an operation
+	ErrRunNotFound = &OperationError{
+		Type:    ErrorTypeNotFound,
+		Message: "no recorded run for operation",
+	}
 )
\ No newline at end of file
@@ -0,0 +1,82 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Stage executable base names. The platform extension is added by ExecutableName.
+const (
+	ScraperExecutable   = "scraper"
+	ProcessorExecutable = "processor"
+	IndexCSVExecutable  = "indexcsv"
+)
+
+// processStopGrace is how long a stage process gets to exit after SIGTERM
+// before it and its children (e.g. the scraper's Chromium) are killed
+const processStopGrace = 10 * time.Second
+
+// ExecutableName returns the file name of a stage executable on this platform
+// (scraper.exe on Windows, scraper elsewhere)
+func ExecutableName(name string) string {
+	if runtime.GOOS == "windows" && !strings.EqualFold(filepath.Ext(name), ".exe") {
+		return name + ".exe"
+	}
+	return name
+}
+
+// ResolveExecutable locates a stage executable in dir. On Linux and macOS the
+// plain name is preferred, falling back to a .exe file for distributions that
+// kept the Windows names.
+func ResolveExecutable(dir, name string) (string, error) {
+	candidates := []string{filepath.Join(dir, ExecutableName(name))}
+	if runtime.GOOS != "windows" {
+		candidates = append(candidates, filepath.Join(dir, name+".exe"))
+	}
+
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+			return "", fmt.Errorf("%s is not executable (run chmod +x %s)", path, path)
+		}
+		return path, nil
+	}
+
+	return "", fmt.Errorf("%s not found in %s: %w", ExecutableName(name), dir, os.ErrNotExist)
+}
+
+// newStageCommand creates a command for a stage executable that is stopped
+// gracefully, together with any processes it started, when ctx is cancelled
+func newStageCommand(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	configureProcess(cmd)
+	cmd.Cancel = func() error {
+		return stopProcess(cmd)
+	}
+	cmd.WaitDelay = processStopGrace + time.Second
+	return cmd
+}
+
+// stopProcess asks a running stage process to exit and kills it, along with
+// its children, if it is still running after processStopGrace
+func stopProcess(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := terminateProcess(cmd); err != nil {
+		return killProcess(cmd)
+	}
+	time.AfterFunc(processStopGrace, func() {
+		_ = killProcess(cmd)
+	})
+	return nil
+}
@@ -0,0 +1,73 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutableName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, "scraper.exe", ExecutableName(ScraperExecutable))
+		assert.Equal(t, "scraper.exe", ExecutableName("scraper.exe"))
+	} else {
+		assert.Equal(t, "scraper", ExecutableName(ScraperExecutable))
+	}
+}
+
+func TestResolveExecutable(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := ResolveExecutable(dir, ProcessorExecutable)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	native := filepath.Join(dir, ExecutableName(ProcessorExecutable))
+	require.NoError(t, os.WriteFile(native, []byte("#!/bin/sh\n"), 0755))
+	path, err := ResolveExecutable(dir, ProcessorExecutable)
+	require.NoError(t, err)
+	assert.Equal(t, native, path)
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	// Legacy .exe names still resolve on Linux and macOS
+	legacy := filepath.Join(dir, "indexcsv.exe")
+	require.NoError(t, os.WriteFile(legacy, []byte("#!/bin/sh\n"), 0755))
+	path, err = ResolveExecutable(dir, IndexCSVExecutable)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, path)
+
+	// Files without the execute bit are reported instead of failing at start
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scraper"), []byte("#!/bin/sh\n"), 0644))
+	_, err = ResolveExecutable(dir, ScraperExecutable)
+	assert.ErrorContains(t, err, "not executable")
+}
+
+func TestNewStageCommand_CancelStopsProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "sleeper")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nsleep 30\n"), 0755))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := newStageCommand(ctx, script)
+	require.NoError(t, cmd.Start())
+
+	start := time.Now()
+	cancel()
+	err := cmd.Wait()
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), processStopGrace, "SIGTERM to the process group ends the script and its sleep child")
+}
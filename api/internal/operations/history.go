@@ -0,0 +1,313 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HistoryFile is the name of the operation history in the operations directory
+const HistoryFile = "history.json"
+
+// History limits
+const (
+	// DefaultHistoryRuns is how many finished runs the history keeps
+	DefaultHistoryRuns = 500
+	// DefaultHistoryLimit is how many runs a history query returns by default
+	DefaultHistoryLimit = 50
+)
+
+// RunRecord is a finished operation run as kept in the history: what it was
+// asked to do, how long each step took, what it produced and why it failed
+type RunRecord struct {
+	OperationID string                 `json:"operation_id"`
+	Status      OperationStatusValue   `json:"status"` // completed, failed or cancelled
+	Error       string                 `json:"error,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt time.Time              `json:"completed_at"`
+	DurationMS  int64                  `json:"duration_ms"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+	Files       int                    `json:"files"` // Files created or rewritten, from the artifact manifest
+	Rows        int                    `json:"rows"`  // Data rows of those files that are CSV
+	Steps       []RunStep              `json:"steps"`
+}
+
+// RunStep is one step of a recorded run
+type RunStep struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Status     StepStatus             `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+	Files      int                    `json:"files"`
+	Rows       int                    `json:"rows"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// HistoryFilter selects runs from the history, newest first
+type HistoryFilter struct {
+	Status OperationStatusValue
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// OperationHistory persists finished operation runs, which otherwise only
+// live in memory while they run. Runs are kept in operations/history.json,
+// oldest first, up to a maximum. A nil history records nothing.
+type OperationHistory struct {
+	path string
+	max  int
+
+	mu sync.Mutex
+}
+
+// NewOperationHistory creates a history kept at path, holding up to max runs
+// (DefaultHistoryRuns when max is not positive)
+func NewOperationHistory(path string, max int) *OperationHistory {
+	if max <= 0 {
+		max = DefaultHistoryRuns
+	}
+	return &OperationHistory{path: path, max: max}
+}
+
+// Record adds a finished run, dropping the oldest runs beyond the maximum. A
+// run recorded again, e.g. after a resume, replaces its earlier record.
+func (h *OperationHistory) Record(run RunRecord) error {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	runs, err := h.load()
+	if err != nil {
+		return err
+	}
+	kept := runs[:0]
+	for _, r := range runs {
+		if r.OperationID != run.OperationID {
+			kept = append(kept, r)
+		}
+	}
+	runs = append(kept, run)
+	if len(runs) > h.max {
+		runs = runs[len(runs)-h.max:]
+	}
+	if err := writeJSONAtomic(h.path, runs); err != nil {
+		return fmt.Errorf("failed to save operation history: %w", err)
+	}
+	return nil
+}
+
+// List returns the runs matching filter, newest first
+func (h *OperationHistory) List(filter HistoryFilter) ([]RunRecord, error) {
+	runs := []RunRecord{}
+	if h == nil {
+		return runs, nil
+	}
+
+	h.mu.Lock()
+	stored, err := h.load()
+	h.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	for i := len(stored) - 1; i >= 0 && len(runs) < limit; i-- {
+		r := stored[i]
+		if filter.Status != "" && r.Status != filter.Status {
+			continue
+		}
+		if !filter.Since.IsZero() && r.StartedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !r.StartedAt.Before(filter.Until) {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs, nil
+}
+
+// Get returns the recorded run of an operation
+func (h *OperationHistory) Get(operationID string) (*RunRecord, error) {
+	if h == nil {
+		return nil, fmt.Errorf("operation %s: %w", operationID, ErrRunNotFound)
+	}
+
+	h.mu.Lock()
+	runs, err := h.load()
+	h.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].OperationID == operationID {
+			return &runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("operation %s: %w", operationID, ErrRunNotFound)
+}
+
+// load reads the stored runs; callers hold h.mu
+func (h *OperationHistory) load() ([]RunRecord, error) {
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operation history: %w", err)
+	}
+	var runs []RunRecord
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse operation history: %w", err)
+	}
+	return runs, nil
+}
+
+// NewRunRecord describes a finished run from its state and, when one was
+// recorded, its artifact manifest. Step metadata is kept as JSON would carry
+// it; values that cannot be encoded are dropped.
+func NewRunRecord(state *OperationState, artifacts *ArtifactManifest, runErr error) RunRecord {
+	snapshot := state.Clone()
+
+	completedAt := time.Now()
+	if snapshot.EndTime != nil {
+		completedAt = *snapshot.EndTime
+	}
+	run := RunRecord{
+		OperationID: snapshot.ID,
+		Status:      snapshot.Status,
+		StartedAt:   snapshot.StartTime,
+		CompletedAt: completedAt,
+		DurationMS:  completedAt.Sub(snapshot.StartTime).Milliseconds(),
+		Config:      plainValues(snapshot.Config),
+		Steps:       []RunStep{},
+	}
+	if runErr != nil {
+		run.Error = runErr.Error()
+		if errors.Is(runErr, context.Canceled) {
+			run.Status = OperationStatusCancelled
+		}
+	}
+
+	type produced struct{ files, rows int }
+	perStep := make(map[string]produced)
+	if artifacts != nil {
+		for _, a := range artifacts.Artifacts {
+			p := perStep[a.Stage]
+			p.files++
+			if a.Rows != nil {
+				p.rows += *a.Rows
+			}
+			perStep[a.Stage] = p
+			run.Files++
+			if a.Rows != nil {
+				run.Rows += *a.Rows
+			}
+		}
+	}
+
+	for _, s := range snapshot.Steps {
+		step := RunStep{
+			ID:       s.ID,
+			Name:     s.Name,
+			Status:   s.Status,
+			Message:  s.Message,
+			Files:    perStep[s.ID].files,
+			Rows:     perStep[s.ID].rows,
+			Metadata: plainValues(s.Metadata),
+		}
+		if s.Error != nil {
+			step.Error = s.Error.Error()
+		}
+		if s.StartTime != nil && s.EndTime != nil {
+			step.DurationMS = s.EndTime.Sub(*s.StartTime).Milliseconds()
+		}
+		run.Steps = append(run.Steps, step)
+	}
+	// Steps in the order they ran; steps that never started go last
+	sort.Slice(run.Steps, func(i, j int) bool {
+		si, iStarted := stepStart(snapshot, run.Steps[i].ID)
+		sj, jStarted := stepStart(snapshot, run.Steps[j].ID)
+		if iStarted != jStarted {
+			return iStarted
+		}
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return run.Steps[i].ID < run.Steps[j].ID
+	})
+	return run
+}
+
+// stepStart returns when a step started and whether it did
+func stepStart(state *OperationState, stepID string) (time.Time, bool) {
+	if s := state.Steps[stepID]; s != nil && s.StartTime != nil {
+		return *s.StartTime, true
+	}
+	return time.Time{}, false
+}
+
+// plainValues converts values to what they decode to from JSON, dropping
+// those that cannot be encoded
+func plainValues(values map[string]interface{}) map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	plain := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			continue
+		}
+		plain[k] = decoded
+	}
+	return plain
+}
+
+// SetHistory records every finished operation run in h
+func (m *Manager) SetHistory(h *OperationHistory) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = h
+}
+
+// GetHistory returns the history of finished operation runs
+func (m *Manager) GetHistory() *OperationHistory {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.history
+}
+
+// recordHistory adds a finished run to the history
+func (m *Manager) recordHistory(ctx context.Context, state *OperationState, runErr error) {
+	history := m.GetHistory()
+	if history == nil {
+		return
+	}
+	artifacts, _ := m.artifacts.Load(state.ID)
+	if err := history.Record(NewRunRecord(state, artifacts, runErr)); err != nil {
+		slog.WarnContext(ctx, "operation_history_failed",
+			slog.String("operation_id", state.ID),
+			slog.String("error", err.Error()))
+	}
+}
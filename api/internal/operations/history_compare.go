@@ -0,0 +1,232 @@
+package operations
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// RunComparison explains how a run differs from a baseline run, e.g. why
+// tonight's pipeline processed fewer files than yesterday's
+type RunComparison struct {
+	Base            *RunRecord       `json:"base"`
+	Target          *RunRecord       `json:"target"`
+	DurationDeltaMS int64            `json:"duration_delta_ms"`
+	FilesDelta      int              `json:"files_delta"`
+	RowsDelta       int              `json:"rows_delta"`
+	ConfigChanges   []ConfigChange   `json:"config_changes"`
+	Steps           []StepComparison `json:"steps"`
+	// Differences summarizes what changed, one readable line each
+	Differences []string `json:"differences"`
+}
+
+// ConfigChange is a request parameter set differently in the two runs; a
+// parameter missing from one run is null there
+type ConfigChange struct {
+	Key    string      `json:"key"`
+	Base   interface{} `json:"base"`
+	Target interface{} `json:"target"`
+}
+
+// StepComparison compares one step across the two runs. A step that ran in
+// only one of them has an empty status on the other side.
+type StepComparison struct {
+	ID              string                 `json:"id"`
+	BaseStatus      StepStatus             `json:"base_status,omitempty"`
+	TargetStatus    StepStatus             `json:"target_status,omitempty"`
+	TargetError     string                 `json:"target_error,omitempty"`
+	DurationDeltaMS int64                  `json:"duration_delta_ms"`
+	FilesDelta      int                    `json:"files_delta"`
+	RowsDelta       int                    `json:"rows_delta"`
+	Metrics         map[string]MetricDelta `json:"metrics,omitempty"` // Numeric step metadata that changed
+}
+
+// MetricDelta is a numeric value in both runs and its change
+type MetricDelta struct {
+	Base   float64 `json:"base"`
+	Target float64 `json:"target"`
+	Delta  float64 `json:"delta"`
+}
+
+// durationChangeThreshold is the smallest step duration change, relative to
+// the base run, that is worth a line in the differences
+const durationChangeThreshold = 0.25
+
+// CompareRuns compares target against base step by step
+func CompareRuns(base, target *RunRecord) *RunComparison {
+	c := &RunComparison{
+		Base:            base,
+		Target:          target,
+		DurationDeltaMS: target.DurationMS - base.DurationMS,
+		FilesDelta:      target.Files - base.Files,
+		RowsDelta:       target.Rows - base.Rows,
+		ConfigChanges:   []ConfigChange{},
+		Steps:           []StepComparison{},
+		Differences:     []string{},
+	}
+
+	if base.Status != target.Status {
+		line := fmt.Sprintf("status %s -> %s", base.Status, target.Status)
+		if target.Error != "" {
+			line += ": " + target.Error
+		}
+		c.note(line)
+	}
+
+	for _, key := range unionKeys(base.Config, target.Config) {
+		b, t := base.Config[key], target.Config[key]
+		if !reflect.DeepEqual(b, t) {
+			c.ConfigChanges = append(c.ConfigChanges, ConfigChange{Key: key, Base: b, Target: t})
+			c.note(fmt.Sprintf("parameter %s: %v -> %v", key, describeValue(b), describeValue(t)))
+		}
+	}
+
+	baseSteps := make(map[string]RunStep, len(base.Steps))
+	for _, s := range base.Steps {
+		baseSteps[s.ID] = s
+	}
+	var order []string
+	seen := make(map[string]bool)
+	for _, steps := range [][]RunStep{target.Steps, base.Steps} {
+		for _, s := range steps {
+			if !seen[s.ID] {
+				seen[s.ID] = true
+				order = append(order, s.ID)
+			}
+		}
+	}
+	targetSteps := make(map[string]RunStep, len(target.Steps))
+	for _, s := range target.Steps {
+		targetSteps[s.ID] = s
+	}
+
+	for _, id := range order {
+		b, inBase := baseSteps[id]
+		t, inTarget := targetSteps[id]
+		step := StepComparison{
+			ID:              id,
+			BaseStatus:      b.Status,
+			TargetStatus:    t.Status,
+			TargetError:     t.Error,
+			DurationDeltaMS: t.DurationMS - b.DurationMS,
+			FilesDelta:      t.Files - b.Files,
+			RowsDelta:       t.Rows - b.Rows,
+		}
+
+		switch {
+		case !inBase:
+			c.note(fmt.Sprintf("%s ran only in the compared run (%s)", id, t.Status))
+		case !inTarget:
+			c.note(fmt.Sprintf("%s ran only in the base run (%s)", id, b.Status))
+		case b.Status != t.Status:
+			line := fmt.Sprintf("%s: %s -> %s", id, b.Status, t.Status)
+			if t.Error != "" {
+				line += ": " + t.Error
+			} else if t.Status == StepStatusSkipped && t.Message != "" {
+				line += ": " + t.Message
+			}
+			c.note(line)
+		}
+		if inBase && inTarget {
+			if step.FilesDelta != 0 {
+				c.note(fmt.Sprintf("%s: %d -> %d files", id, b.Files, t.Files))
+			}
+			if step.RowsDelta != 0 {
+				c.note(fmt.Sprintf("%s: %d -> %d rows", id, b.Rows, t.Rows))
+			}
+			if changedDuration(b.DurationMS, t.DurationMS) {
+				c.note(fmt.Sprintf("%s: took %s instead of %s", id,
+					(time.Duration(t.DurationMS) * time.Millisecond).Round(time.Second),
+					(time.Duration(b.DurationMS) * time.Millisecond).Round(time.Second)))
+			}
+			step.Metrics = compareMetrics(b.Metadata, t.Metadata)
+			for _, key := range sortedKeys(step.Metrics) {
+				d := step.Metrics[key]
+				c.note(fmt.Sprintf("%s: %s %v -> %v", id, key, d.Base, d.Target))
+			}
+		}
+		c.Steps = append(c.Steps, step)
+	}
+	return c
+}
+
+// note adds a line to the differences
+func (c *RunComparison) note(line string) {
+	c.Differences = append(c.Differences, line)
+}
+
+// changedDuration reports whether a step duration changed by more than a
+// second and the threshold share of the base duration
+func changedDuration(base, target int64) bool {
+	delta := target - base
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta >= time.Second.Milliseconds() && float64(delta) > durationChangeThreshold*float64(base)
+}
+
+// progressMetadata is step metadata that tracks progress while the step runs
+// rather than what it did, so it differs between any two runs
+var progressMetadata = map[string]bool{
+	"phase_current":   true,
+	"phase_total":     true,
+	"eta_seconds":     true,
+	"elapsed_seconds": true,
+	"speed":           true,
+}
+
+// compareMetrics returns the numeric metadata present in both runs with
+// different values
+func compareMetrics(base, target map[string]interface{}) map[string]MetricDelta {
+	metrics := make(map[string]MetricDelta)
+	for key, bv := range base {
+		if progressMetadata[key] {
+			continue
+		}
+		b, ok := bv.(float64)
+		if !ok {
+			continue
+		}
+		t, ok := target[key].(float64)
+		if !ok || t == b {
+			continue
+		}
+		metrics[key] = MetricDelta{Base: b, Target: t, Delta: t - b}
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	return metrics
+}
+
+// describeValue formats a parameter for the differences, showing unset ones
+func describeValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	return fmt.Sprint(v)
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(m map[string]MetricDelta) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
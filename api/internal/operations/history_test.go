@@ -0,0 +1,168 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationHistory(t *testing.T) {
+	h := NewOperationHistory(filepath.Join(t.TempDir(), HistoryFile), 3)
+	start := time.Date(2025, 8, 1, 18, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		status := OperationStatusCompleted
+		if i == 2 {
+			status = OperationStatusFailed
+		}
+		require.NoError(t, h.Record(RunRecord{
+			OperationID: fmt.Sprintf("op-%d", i),
+			Status:      status,
+			StartedAt:   start.AddDate(0, 0, i),
+		}))
+	}
+
+	runs, err := h.List(HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, runs, 3, "oldest run dropped")
+	assert.Equal(t, "op-3", runs[0].OperationID, "newest first")
+	assert.Equal(t, "op-1", runs[2].OperationID)
+
+	failed, err := h.List(HistoryFilter{Status: OperationStatusFailed})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "op-2", failed[0].OperationID)
+
+	between, err := h.List(HistoryFilter{Since: start.AddDate(0, 0, 2), Until: start.AddDate(0, 0, 3)})
+	require.NoError(t, err)
+	require.Len(t, between, 1)
+	assert.Equal(t, "op-2", between[0].OperationID)
+
+	// A resumed run replaces its earlier record
+	require.NoError(t, h.Record(RunRecord{OperationID: "op-2", Status: OperationStatusCompleted, StartedAt: start}))
+	run, err := h.Get("op-2")
+	require.NoError(t, err)
+	assert.Equal(t, OperationStatusCompleted, run.Status)
+	runs, err = h.List(HistoryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+
+	_, err = h.Get("op-0")
+	assert.ErrorIs(t, err, ErrRunNotFound)
+
+	var missing *OperationHistory
+	runs, err = missing.List(HistoryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.NoError(t, missing.Record(RunRecord{OperationID: "op-x"}))
+}
+
+func TestNewRunRecord(t *testing.T) {
+	start := time.Date(2025, 8, 1, 18, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		ts := start.Add(time.Duration(minutes) * time.Minute)
+		return &ts
+	}
+
+	state := NewOperationState("op-1")
+	state.StartTime = start
+	state.EndTime = at(10)
+	state.Status = OperationStatusFailed
+	state.Config["mode"] = "full"
+	state.Steps["processing"] = &StepState{
+		ID: "processing", Name: "Data Processing", Status: StepStatusFailed,
+		StartTime: at(4), EndTime: at(9), Error: errors.New("disk full"),
+		Metadata: map[string]interface{}{"files_processed": 12},
+	}
+	state.Steps["scraping"] = &StepState{
+		ID: "scraping", Name: "Data Collection", Status: StepStatusCompleted,
+		StartTime: at(0), EndTime: at(4),
+	}
+	state.Steps["liquidity"] = &StepState{ID: "liquidity", Name: "Liquidity", Status: StepStatusPending}
+
+	rows := 40
+	manifest := &ArtifactManifest{Artifacts: []Artifact{
+		{Path: "a.xlsx", Stage: "scraping"},
+		{Path: "b.xlsx", Stage: "scraping"},
+		{Path: "c.csv", Stage: "processing", Rows: &rows},
+	}}
+
+	run := NewRunRecord(state, manifest, errors.New("processing failed"))
+	assert.Equal(t, OperationStatusFailed, run.Status)
+	assert.Equal(t, "processing failed", run.Error)
+	assert.Equal(t, int64(10*60*1000), run.DurationMS)
+	assert.Equal(t, 3, run.Files)
+	assert.Equal(t, 40, run.Rows)
+	assert.Equal(t, "full", run.Config["mode"])
+
+	require.Len(t, run.Steps, 3)
+	assert.Equal(t, []string{"scraping", "processing", "liquidity"},
+		[]string{run.Steps[0].ID, run.Steps[1].ID, run.Steps[2].ID}, "in the order they ran")
+	assert.Equal(t, 2, run.Steps[0].Files)
+	assert.Equal(t, int64(5*60*1000), run.Steps[1].DurationMS)
+	assert.Equal(t, "disk full", run.Steps[1].Error)
+	assert.Equal(t, float64(12), run.Steps[1].Metadata["files_processed"], "metadata as JSON carries it")
+
+	cancelled := NewRunRecord(state, nil, fmt.Errorf("stopped: %w", context.Canceled))
+	assert.Equal(t, OperationStatusCancelled, cancelled.Status)
+	assert.Zero(t, cancelled.Files)
+}
+
+func TestCompareRuns(t *testing.T) {
+	base := &RunRecord{
+		OperationID: "op-1",
+		Status:      OperationStatusCompleted,
+		DurationMS:  60000,
+		Config:      map[string]interface{}{"mode": "full", "from": "2025-08-01"},
+		Files:       12,
+		Steps: []RunStep{
+			{ID: "scraping", Status: StepStatusCompleted, DurationMS: 30000, Files: 12,
+				Metadata: map[string]interface{}{"files_downloaded": float64(12), "eta_seconds": float64(3)}},
+			{ID: "processing", Status: StepStatusCompleted, DurationMS: 30000},
+		},
+	}
+	target := &RunRecord{
+		OperationID: "op-2",
+		Status:      OperationStatusFailed,
+		Error:       "processing failed",
+		DurationMS:  150000,
+		Config:      map[string]interface{}{"mode": "full"},
+		Files:       9,
+		Steps: []RunStep{
+			{ID: "scraping", Status: StepStatusCompleted, DurationMS: 120000, Files: 9,
+				Metadata: map[string]interface{}{"files_downloaded": float64(9), "eta_seconds": float64(40)}},
+			{ID: "processing", Status: StepStatusFailed, Error: "disk full", DurationMS: 30000},
+			{ID: "indices", Status: StepStatusSkipped},
+		},
+	}
+
+	c := CompareRuns(base, target)
+	assert.Equal(t, int64(90000), c.DurationDeltaMS)
+	assert.Equal(t, -3, c.FilesDelta)
+	require.Len(t, c.ConfigChanges, 1)
+	assert.Equal(t, "from", c.ConfigChanges[0].Key)
+	assert.Nil(t, c.ConfigChanges[0].Target)
+
+	require.Len(t, c.Steps, 3)
+	assert.Equal(t, MetricDelta{Base: 12, Target: 9, Delta: -3}, c.Steps[0].Metrics["files_downloaded"])
+	assert.NotContains(t, c.Steps[0].Metrics, "eta_seconds", "progress metadata is ignored")
+
+	assert.Equal(t, []string{
+		"status completed -> failed: processing failed",
+		"parameter from: 2025-08-01 -> (unset)",
+		"scraping: 12 -> 9 files",
+		"scraping: took 2m0s instead of 30s",
+		"scraping: files_downloaded 12 -> 9",
+		"processing: completed -> failed: disk full",
+		"indices ran only in the compared run (skipped)",
+	}, c.Differences)
+
+	same := CompareRuns(base, base)
+	assert.Empty(t, same.Differences)
+}
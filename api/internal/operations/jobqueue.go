@@ -2,8 +2,10 @@ package operations
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -74,6 +76,12 @@ type JobQueue struct {
 	logger   *slog.Logger
 	shutdown chan struct{}
 	active   map[string]*Job // Currently executing jobs
+
+	// Where operations still running at shutdown are checkpointed (disabled when empty)
+	checkpointDir string
+
+	// Called after a job completes or fails, e.g. to notify operators
+	onFinished func(job *Job)
 }
 
 // NewJobQueue creates a new job queue
@@ -97,6 +105,24 @@ func NewJobQueue(workers int, store JobStore, manager *Manager, logger *slog.Log
 	}
 }
 
+// OnJobFinished registers a callback invoked after a job completes or fails.
+// The callback runs on the worker goroutine, so slow work should be handed off.
+func (q *JobQueue) OnJobFinished(fn func(job *Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onFinished = fn
+}
+
+// jobFinished passes a finished job to the OnJobFinished callback
+func (q *JobQueue) jobFinished(job *Job) {
+	q.mu.RLock()
+	onFinished := q.onFinished
+	q.mu.RUnlock()
+	if onFinished != nil {
+		onFinished(job)
+	}
+}
+
 // Start begins processing jobs
 func (q *JobQueue) Start(ctx context.Context) {
 	q.logger.Info("starting job queue", slog.Int("workers", q.workers))
@@ -118,6 +144,10 @@ func (q *JobQueue) Stop(timeout time.Duration) error {
 	// Signal shutdown
 	close(q.shutdown)
 	
+	// Persist running operations so they can be resumed after the restart.
+	// Stages that finish while we wait refresh their checkpoint.
+	q.checkpointActive()
+	
 	// Wait for workers to finish with timeout
 	done := make(chan struct{})
 	go func() {
@@ -203,6 +233,9 @@ func (q *JobQueue) CancelJob(id string) error {
 	now := time.Now()
 	job.CompletedAt = &now
 	
+	// A job waiting for other operations leaves the queue
+	q.manager.admission.dequeue(job.OperationID)
+	
 	return q.store.UpdateJob(job)
 }
 
@@ -274,6 +307,7 @@ func (q *JobQueue) processJob(ctx context.Context, job *Job, logger *slog.Logger
 			if err := q.store.UpdateJob(job); err != nil {
 				logger.Error("failed to update job after panic", slog.String("error", err.Error()))
 			}
+			q.jobFinished(job)
 		}
 		
 		// Remove from active jobs
@@ -282,6 +316,20 @@ func (q *JobQueue) processJob(ctx context.Context, job *Job, logger *slog.Logger
 		q.mu.Unlock()
 	}()
 	
+	// Wait until no other operation uses the same data directories
+	release, err := q.acquire(ctx, job, logger)
+	if errors.Is(err, ErrOperationDequeued) {
+		// CancelJob already recorded the cancellation
+		logger.Info("job cancelled while queued")
+		broadcaster.CancelOperation(job.OperationID)
+		return
+	}
+	if err != nil {
+		q.handleJobError(job, err, logger)
+		return
+	}
+	defer release()
+	
 	// Update job status to running
 	job.Status = JobStatusRunning
 	now := time.Now()
@@ -329,12 +377,66 @@ func (q *JobQueue) processJob(ctx context.Context, job *Job, logger *slog.Logger
 		logger.Error("failed to update job completion", slog.String("error", err.Error()))
 	}
 	
+	// Nothing left to resume
+	q.removeCheckpoint(job.OperationID)
+	q.manager.finishArtifacts(ctx, job.OperationID, nil)
+	
 	// Broadcast operation completion through the centralized broadcaster
 	broadcaster.CompleteOperation(job.OperationID, "Operation completed successfully")
+	q.jobFinished(job)
 	
 	logger.Info("processing job completed")
 }
 
+// acquire takes the job's slot in the manager's operation queue, leaving
+// the job pending with its queue position while it waits
+func (q *JobQueue) acquire(ctx context.Context, job *Job, logger *slog.Logger) (func(), error) {
+	var params map[string]interface{}
+	if job.Request != nil {
+		params = job.Request.Parameters
+	}
+	policy, err := q.manager.ResolveConflictPolicy(params)
+	if err != nil {
+		return nil, err
+	}
+	steps := jobSteps(job)
+	
+	if blockedBy, _ := q.manager.Conflicts(steps); len(blockedBy) > 0 {
+		logger.Info("job waiting for conflicting operations",
+			slog.String("blocked_by", strings.Join(blockedBy, ",")),
+			slog.String("policy", string(policy)))
+		job.Message = "Waiting for " + strings.Join(blockedBy, ", ")
+		q.store.UpdateJob(job)
+	}
+	
+	return q.manager.Acquire(ctx, job.OperationID, steps, policy)
+}
+
+// Conflicts returns the running and queued operations a job running stepIDs
+// (the full pipeline when empty) would wait for, with the conflict policy its
+// request parameters select
+func (q *JobQueue) Conflicts(stepIDs []string, params map[string]interface{}) ([]string, ConflictPolicy, error) {
+	policy, err := q.manager.ResolveConflictPolicy(params)
+	if err != nil {
+		return nil, "", err
+	}
+	blockedBy, _ := q.manager.Conflicts(stepIDs)
+	return blockedBy, policy, nil
+}
+
+// QueueLength returns how many operations are waiting for others to finish
+func (q *JobQueue) QueueLength() int {
+	return len(q.manager.Queue())
+}
+
+// jobSteps returns the stages a job runs; none means the full pipeline
+func jobSteps(job *Job) []string {
+	if job.StageID == "" || job.StageID == "full_pipeline" {
+		return nil
+	}
+	return []string{job.StageID}
+}
+
 // executeSingleStage runs a single stage
 func (q *JobQueue) executeSingleStage(ctx context.Context, job *Job, manifest *PipelineManifest, logger *slog.Logger) error {
 	// Get the stage from registry using the exported method
@@ -376,6 +478,7 @@ func (q *JobQueue) executeSingleStage(ctx context.Context, job *Job, manifest *P
 	state := NewOperationState(job.OperationID)
 	state.SetConfig(ContextKeyFromDate, manifest.FromDate)
 	state.SetConfig(ContextKeyToDate, manifest.ToDate)
+	state.SetConfig(ContextKeyProfile, manifest.Profile)
 	
 	// Initialize the stage state to prevent nil pointer dereference
 	stepState := NewStepState(stage.ID(), stage.Name())
@@ -384,6 +487,7 @@ func (q *JobQueue) executeSingleStage(ctx context.Context, job *Job, manifest *P
 	// Execute the stage
 	logger.Info("executing stage", slog.String("stage", stage.ID()))
 	
+	snapshot := q.manager.artifacts.BeginStep(stage.ID())
 	if err := stage.Execute(ctx, state); err != nil {
 		manifest.RecordStageFailure(stage.ID(), err)
 		q.store.UpdateManifest(manifest)
@@ -403,6 +507,13 @@ func (q *JobQueue) executeSingleStage(ctx context.Context, job *Job, manifest *P
 	
 	manifest.RecordStageCompletion(stage.ID(), outputTypes, nil)
 	q.store.UpdateManifest(manifest)
+	if err := q.manager.artifacts.CompleteStep(job.OperationID, snapshot); err != nil {
+		logger.Warn("failed to update artifact manifest", slog.String("error", err.Error()))
+	}
+	if q.stopping() {
+		// job may be a per-stage copy; checkpoint the queued pipeline job
+		q.checkpointActive()
+	}
 	
 	// Update job progress
 	job.Progress = 90
@@ -426,6 +537,14 @@ func (q *JobQueue) executeFullPipeline(ctx context.Context, job *Job, manifest *
 	totalStages := len(stages)
 	
 	for i, stage := range stages {
+		// A resumed operation keeps the stages it finished before the restart
+		if manifest.IsStageCompleted(stage.ID()) {
+			logger.Info("skipping stage - completed before restart",
+				slog.String("stage", stage.ID()))
+			q.manager.GetBroadcaster().CompleteStep(job.OperationID, stage.ID(), fmt.Sprintf("%s completed before restart", stage.Name()))
+			continue
+		}
+		
 		// Check if stage can run
 		if !stage.CanRun(manifest) {
 			logger.Info("skipping stage - requirements not met",
@@ -466,9 +585,15 @@ func (q *JobQueue) handleJobError(job *Job, err error, logger *slog.Logger) {
 		logger.Error("failed to update job error", slog.String("error", err.Error()))
 	}
 	
+	// Runs that got past the queue keep a manifest of what they produced
+	if job.StartedAt != nil {
+		q.manager.finishArtifacts(context.Background(), job.OperationID, err)
+	}
+
 	// Broadcast operation failure through the centralized broadcaster
 	broadcaster := q.manager.GetBroadcaster()
 	broadcaster.FailOperation(job.OperationID, err)
+	q.jobFinished(job)
 }
 
 // getOrCreateManifest gets existing or creates new manifest
@@ -483,12 +608,15 @@ func (q *JobQueue) getOrCreateManifest(job *Job) (*PipelineManifest, error) {
 	fromDate := ""
 	toDate := ""
 	
+	profile := ""
 	if job.Request != nil {
 		fromDate = job.Request.FromDate
 		toDate = job.Request.ToDate
+		profile = job.Request.Profile
 	}
 	
 	manifest = NewPipelineManifest(job.OperationID, fromDate, toDate)
+	manifest.Profile = profile
 	
 	// Scan existing data directories to populate available data
 	// This allows resuming operations that find existing data
@@ -560,5 +688,6 @@ func (q *JobQueue) GetQueueStats() map[string]interface{} {
 		"queue_size":   len(q.jobs),
 		"queue_cap":    cap(q.jobs),
 		"active_jobs":  activeCount,
+		"waiting_operations": q.manager.Queue(),
 	}
 }
\ No newline at end of file
@@ -143,6 +143,36 @@ func TestJobQueue(t *testing.T) {
 		assert.Equal(t, JobStatusCancelled, cancelledJob.Status)
 	})
 	
+	t.Run("finished callback", func(t *testing.T) {
+		store := NewMemoryJobStore()
+		manager := NewManager(nil, NewRegistry(), NewConfig())
+		queue := NewJobQueue(1, store, manager, nil)
+
+		finished := make(chan Job, 1)
+		queue.OnJobFinished(func(job *Job) {
+			finished <- *job
+		})
+
+		queue.Start(context.Background())
+		defer queue.Stop(5 * time.Second)
+
+		require.NoError(t, queue.Enqueue(&Job{
+			ID:          "test-job-6",
+			OperationID: "op-6",
+			StageID:     "missing-stage",
+			CreatedAt:   time.Now(),
+		}))
+
+		select {
+		case job := <-finished:
+			assert.Equal(t, "test-job-6", job.ID)
+			assert.Equal(t, JobStatusFailed, job.Status, "the stage is not registered")
+			assert.NotEmpty(t, job.Error)
+		case <-time.After(2 * time.Second):
+			t.Fatal("callback not invoked")
+		}
+	})
+	
 	t.Run("queue statistics", func(t *testing.T) {
 		// Create test infrastructure
 		store := NewMemoryJobStore()
@@ -38,7 +38,7 @@ func TestLiquidityStage_Integration(t *testing.T) {
 	}
 
 	// Create LiquidityStage
-	stage := NewLiquidityStage(tempDir, logger, options)
+	stage := NewLiquidityStage(stagePaths(t, tempDir), logger, options)
 	require.NotNil(t, stage)
 
 	// Create operation state
@@ -90,7 +90,7 @@ func TestLiquidityStage_CanRun(t *testing.T) {
 		Level: slog.LevelInfo,
 	}))
 
-	stage := NewLiquidityStage(tempDir, logger, nil)
+	stage := NewLiquidityStage(stagePaths(t, tempDir), logger, nil)
 
 	// Test with empty manifest - should check filesystem
 	manifest := NewPipelineManifest("test-op", "", "")
@@ -115,7 +115,7 @@ func TestLiquidityStage_CanRun(t *testing.T) {
 }
 
 func TestLiquidityStage_RequiredInputs(t *testing.T) {
-	stage := NewLiquidityStage("", nil, nil)
+	stage := NewLiquidityStage(nil, nil, nil)
 	inputs := stage.RequiredInputs()
 	
 	require.Len(t, inputs, 1)
@@ -126,7 +126,7 @@ func TestLiquidityStage_RequiredInputs(t *testing.T) {
 }
 
 func TestLiquidityStage_ProducedOutputs(t *testing.T) {
-	stage := NewLiquidityStage("", nil, nil)
+	stage := NewLiquidityStage(nil, nil, nil)
 	outputs := stage.ProducedOutputs()
 	
 	require.Len(t, outputs, 1)
@@ -144,7 +144,7 @@ func TestLiquidityStage_CSVParsing(t *testing.T) {
 		Level: slog.LevelDebug,
 	}))
 
-	stage := NewLiquidityStage(tempDir, logger, nil)
+	stage := NewLiquidityStage(stagePaths(t, tempDir), logger, nil)
 
 	// Create test CSV with various formats
 	csvFile := filepath.Join(reportsDir, "test_parsing.csv")
@@ -153,7 +153,7 @@ func TestLiquidityStage_CSVParsing(t *testing.T) {
 
 	// Test loading data
 	ctx := context.Background()
-	data, err := stage.loadTradingDataFromCSV(ctx)
+	data, err := stage.loadTradingDataFromCSV(ctx, reportsDir)
 	
 	// Even if parsing fails, we should get meaningful error messages
 	if err != nil {
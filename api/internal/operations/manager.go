@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"sync"
 	"time"
+
+	"isxcli/internal/config"
 )
 
 // Manager orchestrates operation execution
@@ -19,6 +22,31 @@ type Manager struct {
 	// Active operations
 	mu         sync.RWMutex
 	operations map[string]*OperationState
+
+	// Completion time of the most recent successful operation
+	lastSuccess time.Time
+
+	// Most recent outcome of each step, keyed by step ID
+	stepOutcomes map[string]StepOutcome
+
+	// Callbacks run after a step completes successfully
+	onStepCompleted []func(stepID string)
+
+	// Decides when operations may start; see Acquire
+	admission *admission
+
+	// Writes the artifact manifest of each run; nil records nothing
+	artifacts *ArtifactRecorder
+
+	// Keeps finished runs for history and comparison; nil records nothing
+	history *OperationHistory
+}
+
+// StepOutcome is the last success and failure of a step since startup
+type StepOutcome struct {
+	LastSuccess time.Time `json:"last_success"`
+	LastFailure time.Time `json:"last_failure"`
+	LastError   string    `json:"last_error,omitempty"`
 }
 
 // NewManager creates a new operation manager with dependency injection
@@ -33,13 +61,16 @@ func NewManager(hub WebSocketHub, registry *Registry, config *Config) *Manager {
 	// Create status broadcaster for centralized status management
 	broadcaster := NewStatusBroadcaster(hub, slog.Default())
 
-	return &Manager{
+	m := &Manager{
 		registry:    registry,
 		config:      config,
 		hub:         hub,
 		broadcaster: broadcaster,
 		operations:  make(map[string]*OperationState),
 	}
+	m.admission = newAdmission(func() int { return m.config.MaxConcurrentOperations })
+	m.admission.onQueueChange = m.broadcastQueue
+	return m
 }
 
 // RegisterStage registers a Step with the operation
@@ -70,25 +101,11 @@ func (m *Manager) Execute(ctx context.Context, req OperationRequest) (*Operation
 	if req.ID == "" {
 		req.ID = fmt.Sprintf("operation-%d", time.Now().Unix())
 	}
-
-	// Create operation state
-	state := NewOperationState(req.ID)
-
-	// Set configuration from request
-	if req.FromDate != "" {
-		state.SetConfig(ContextKeyFromDate, req.FromDate)
-	}
-	if req.ToDate != "" {
-		state.SetConfig(ContextKeyToDate, req.ToDate)
-	}
-	if req.Mode != "" {
-		state.SetConfig(ContextKeyMode, req.Mode)
+	if req.Profile == "" {
+		req.Profile = config.ProfileFromContext(ctx)
 	}
 
-	// Copy additional parameters
-	for k, v := range req.Parameters {
-		state.SetConfig(k, v)
-	}
+	state := newRequestState(req)
 
 	// Store operation state
 	m.storeOperation(state)
@@ -97,38 +114,11 @@ func (m *Manager) Execute(ctx context.Context, req OperationRequest) (*Operation
 	// Initialize operation in broadcaster (reset handled internally)
 
 	// Determine which steps to run based on request
-	var steps []Step
-	stepParam, hasStep := req.Parameters["step"].(string)
-
-	if hasStep && stepParam != "" && stepParam != "full_pipeline" {
-		// Single step requested
-		requestedStep, err := m.registry.Get(stepParam)
-		if err != nil || requestedStep == nil {
-			if err == nil {
-				err = fmt.Errorf("requested step not found: %s", stepParam)
-			}
-			m.logOperationError(ctx, req.ID, err)
-			state.Fail(err)
-			return m.createResponse(state), err
-		}
-		steps = []Step{requestedStep}
-
-		slog.InfoContext(ctx, "executing_single_step",
-			slog.String("step_id", stepParam),
-			slog.String("operation_id", req.ID))
-	} else {
-		// Full pipeline requested or no step specified
-		var err error
-		steps, err = m.registry.GetDependencyOrder()
-		if err != nil {
-			m.logOperationError(ctx, req.ID, fmt.Errorf("failed to get dependency order: %w", err))
-			state.Fail(err)
-			return m.createResponse(state), err
-		}
-
-		slog.InfoContext(ctx, "executing_full_pipeline",
-			slog.Int("step_count", len(steps)),
-			slog.String("operation_id", req.ID))
+	steps, err := m.resolveSteps(ctx, req, state)
+	if err != nil {
+		m.logOperationError(ctx, req.ID, err)
+		state.Fail(err)
+		return m.createResponse(state), err
 	}
 
 	// Initialize Step states
@@ -146,17 +136,37 @@ func (m *Manager) Execute(ctx context.Context, req OperationRequest) (*Operation
 	// Create operation in broadcaster with all steps
 	m.broadcaster.CreateOperation(req.ID, stepNames)
 
+	// Wait for a slot and the data directories the steps use
+	stepIDs := make([]string, len(steps))
+	for i, step := range steps {
+		stepIDs[i] = step.ID()
+	}
+	policy, err := m.ResolveConflictPolicy(req.Parameters)
+	if err == nil {
+		var release func()
+		release, err = m.Acquire(ctx, req.ID, stepIDs, policy)
+		if err == nil {
+			defer release()
+		}
+	}
+	if err != nil {
+		m.logOperationError(ctx, req.ID, err)
+		state.Fail(err)
+		m.broadcaster.FailOperation(req.ID, err)
+		return m.createResponse(state), err
+	}
+
 	// Start operation execution
 	state.Start()
 	m.broadcaster.StartOperation(req.ID)
 
 	// Execute steps based on execution mode
-	var err error
 	if m.config.ExecutionMode == ExecutionModeSequential {
 		err = m.executeSequential(ctx, state, steps)
 	} else {
 		err = m.executeParallel(ctx, state, steps)
 	}
+	m.finishArtifacts(ctx, req.ID, err)
 
 	// Update final operation state
 	if err != nil {
@@ -165,11 +175,88 @@ func (m *Manager) Execute(ctx context.Context, req OperationRequest) (*Operation
 	} else {
 		state.Complete()
 		m.broadcaster.CompleteOperation(req.ID, "Operation completed successfully")
+		m.recordSuccess(time.Now())
 	}
+	m.recordHistory(ctx, state, err)
 
 	return m.createResponse(state), err
 }
 
+// newRequestState creates the operation state for req, carrying its dates,
+// mode and parameters as configuration
+func newRequestState(req OperationRequest) *OperationState {
+	state := NewOperationState(req.ID)
+
+	// Set configuration from request
+	if req.FromDate != "" {
+		state.SetConfig(ContextKeyFromDate, req.FromDate)
+	}
+	if req.ToDate != "" {
+		state.SetConfig(ContextKeyToDate, req.ToDate)
+	}
+	if req.Mode != "" {
+		state.SetConfig(ContextKeyMode, req.Mode)
+	}
+
+	// Copy additional parameters
+	for k, v := range req.Parameters {
+		state.SetConfig(k, v)
+	}
+	state.SetConfig(ContextKeyProfile, req.Profile)
+	return state
+}
+
+// resolveSteps returns the steps req asks for: a selection of steps, a single
+// step or the full pipeline
+func (m *Manager) resolveSteps(ctx context.Context, req OperationRequest, state *OperationState) ([]Step, error) {
+	stepParam, hasStep := req.Parameters["step"].(string)
+
+	if selected := selectedSteps(req.Parameters); len(selected) > 0 {
+		// A selection of steps, e.g. from an operation template
+		steps, err := m.selectSteps(selected)
+		if err != nil {
+			return nil, err
+		}
+		// Steps whose dependencies were not selected work on the data earlier
+		// runs left behind, as a single step does
+		state.SetContext(ContextKeySingleStep, true)
+
+		slog.InfoContext(ctx, "executing_selected_steps",
+			slog.Any("steps", selected),
+			slog.String("operation_id", req.ID))
+		return steps, nil
+	}
+
+	if hasStep && stepParam != "" && stepParam != "full_pipeline" {
+		// Single step requested
+		requestedStep, err := m.registry.Get(stepParam)
+		if err != nil || requestedStep == nil {
+			if err == nil {
+				err = fmt.Errorf("requested step not found: %s", stepParam)
+			}
+			return nil, err
+		}
+		state.SetContext(ContextKeySingleStep, true)
+
+		slog.InfoContext(ctx, "executing_single_step",
+			slog.String("step_id", stepParam),
+			slog.String("operation_id", req.ID))
+		return []Step{requestedStep}, nil
+	}
+
+	// Full pipeline requested or no step specified
+	steps, err := m.registry.GetDependencyOrder()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependency order: %w", err)
+	}
+	steps = pipelineSteps(steps, state)
+
+	slog.InfoContext(ctx, "executing_full_pipeline",
+		slog.Int("step_count", len(steps)),
+		slog.String("operation_id", req.ID))
+	return steps, nil
+}
+
 // executeSequential executes steps one by one
 func (m *Manager) executeSequential(ctx context.Context, state *OperationState, steps []Step) error {
 	slog.InfoContext(ctx, "sequential_execution_start",
@@ -226,6 +313,9 @@ func (m *Manager) executeSequential(ctx context.Context, state *OperationState,
 				slog.Int("total_stages", len(steps)))
 			if err := m.executeStage(ctx, state, Step); err != nil {
 				m.logStageError(ctx, state.ID, Step.ID(), err)
+				if stepState := state.GetStage(Step.ID()); stepState != nil && stepState.Status == StepStatusFailed {
+					m.recordStepOutcome(Step.ID(), time.Now(), err)
+				}
 				if !m.config.ContinueOnError {
 					// Skip all dependent steps
 					m.skipDependentStages(state, steps, Step.ID())
@@ -239,6 +329,7 @@ func (m *Manager) executeSequential(ctx context.Context, state *OperationState,
 				// Verify Step actually completed
 				updatedState := state.GetStage(Step.ID())
 				if updatedState.Status == StepStatusCompleted {
+					m.recordStepOutcome(Step.ID(), time.Now(), nil)
 					slog.InfoContext(ctx, "stage_completed_successfully",
 						slog.String("operation_id", state.ID),
 						slog.String("Step", Step.ID()))
@@ -313,15 +404,22 @@ func (m *Manager) executeStage(ctx context.Context, OperationState *OperationSta
 	stageCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Files the step creates are found by comparing its directories
+	snapshot := m.artifacts.BeginStep(Step.ID())
+
 	// Execute with retries
-	retryConfig := m.config.RetryConfig
+	retryConfig := m.config.GetRetryConfig(Step.ID())
 	var lastErr error
 
 	for attempt := 1; attempt <= retryConfig.MaxAttempts; attempt++ {
 		// Start Step
 		StepState.Start()
 		// Use broadcaster for all updates - single source of truth
-		m.broadcaster.UpdateStepProgress(OperationState.ID, Step.ID(), int(StepState.Progress), "Step started")
+		startMessage := "Step started"
+		if attempt > 1 {
+			startMessage = fmt.Sprintf("Step started (attempt %d/%d)", attempt, retryConfig.MaxAttempts)
+		}
+		m.broadcaster.UpdateStepProgress(OperationState.ID, Step.ID(), int(StepState.Progress), startMessage)
 
 		// Execute Step
 		slog.InfoContext(ctx, "calling_execute",
@@ -335,6 +433,12 @@ func (m *Manager) executeStage(ctx context.Context, OperationState *OperationSta
 		if err == nil {
 			// Success
 			m.logStageComplete(ctx, OperationState.ID, Step.ID(), duration)
+			if err := m.artifacts.CompleteStep(OperationState.ID, snapshot); err != nil {
+				slog.WarnContext(ctx, "artifact_manifest_failed",
+					slog.String("operation_id", OperationState.ID),
+					slog.String("Step", Step.ID()),
+					slog.String("error", err.Error()))
+			}
 			StepState.Complete()
 			m.broadcaster.CompleteStep(OperationState.ID, Step.ID(), "Step completed successfully")
 
@@ -360,21 +464,31 @@ func (m *Manager) executeStage(ctx context.Context, OperationState *OperationSta
 		lastErr = err
 
 		// Check if error is retryable
-		if !IsRetryable(err) || attempt >= retryConfig.MaxAttempts {
+		if !retryConfig.ShouldRetry(err) || attempt >= retryConfig.MaxAttempts {
 			StepState.Fail(err)
 			m.broadcaster.UpdateStepProgress(OperationState.ID, Step.ID(), int(StepState.Progress), fmt.Sprintf("Step failed: %v", err))
 			return WrapError(err, Step.ID(), "Step execution failed")
 		}
 
 		// Calculate retry delay
-		delay := m.calculateRetryDelay(attempt, retryConfig)
+		delay := m.calculateRetryDelay(attempt+1, retryConfig)
+		errorClass := ClassifyError(err)
 		slog.WarnContext(ctx, "stage_retry",
 			slog.String("operation_id", OperationState.ID),
 			slog.String("Step", Step.ID()),
 			slog.Int("attempt", attempt),
 			slog.Int("max_attempts", retryConfig.MaxAttempts),
 			slog.Duration("delay", delay),
+			slog.String("error_class", string(errorClass)),
 			slog.String("error", err.Error()))
+		m.broadcaster.UpdateStepWithMetadata(OperationState.ID, Step.ID(), int(StepState.Progress),
+			fmt.Sprintf("Retrying attempt %d/%d in %s: %v", attempt+1, retryConfig.MaxAttempts, delay, err),
+			map[string]interface{}{
+				"attempt":      attempt + 1,
+				"max_attempts": retryConfig.MaxAttempts,
+				"retry_delay":  delay.String(),
+				"error_class":  string(errorClass),
+			})
 
 		// Wait before retry
 		select {
@@ -418,6 +532,11 @@ func (m *Manager) checkDependencies(state *OperationState, Step Step) error {
 	for _, dep := range deps {
 		depState := state.GetStage(dep)
 		if depState == nil {
+			// A step run on its own with the "step" parameter works on the data
+			// earlier runs left behind
+			if single, _ := state.GetContext(ContextKeySingleStep); single == true {
+				continue
+			}
 			return fmt.Errorf("dependency %s not found", dep)
 		}
 		if depState.Status != StepStatusCompleted {
@@ -427,13 +546,72 @@ func (m *Manager) checkDependencies(state *OperationState, Step Step) error {
 	return nil
 }
 
-// calculateRetryDelay calculates the delay before next retry
+// selectedSteps reads the ContextKeySteps parameter. JSON-decoded requests
+// carry it as []interface{}.
+func selectedSteps(params map[string]interface{}) []string {
+	switch v := params[ContextKeySteps].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		ids := make([]string, 0, len(v))
+		for _, id := range v {
+			if s, ok := id.(string); ok && s != "" {
+				ids = append(ids, s)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// selectSteps returns the selected steps in dependency order. Optional steps
+// run when selected.
+func (m *Manager) selectSteps(ids []string) ([]Step, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !m.registry.Has(id) {
+			return nil, fmt.Errorf("requested step not found: %s", id)
+		}
+		wanted[id] = true
+	}
+
+	ordered, err := m.registry.GetDependencyOrder()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependency order: %w", err)
+	}
+	steps := make([]Step, 0, len(wanted))
+	for _, step := range ordered {
+		if wanted[step.ID()] {
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// pipelineSteps drops optional steps the operation did not ask for
+func pipelineSteps(steps []Step, state *OperationState) []Step {
+	selected := make([]Step, 0, len(steps))
+	for _, step := range steps {
+		if optional, ok := step.(OptionalStep); ok && !optional.IncludeInPipeline(state) {
+			continue
+		}
+		selected = append(selected, step)
+	}
+	return selected
+}
+
+// calculateRetryDelay calculates the delay before the given attempt: none
+// before the first, InitialDelay before the second, then growing by
+// Multiplier up to MaxDelay
 func (m *Manager) calculateRetryDelay(attempt int, config RetryConfig) time.Duration {
-	delay := config.InitialDelay * time.Duration(float64(attempt-1)*config.Multiplier)
-	if delay > config.MaxDelay {
-		delay = config.MaxDelay
+	if attempt <= 1 {
+		return 0
+	}
+	delay := float64(config.InitialDelay) * math.Pow(config.Multiplier, float64(attempt-2))
+	if delay > float64(config.MaxDelay) {
+		return config.MaxDelay
 	}
-	return delay
+	return time.Duration(delay)
 }
 
 // All WebSocket updates now go through StatusBroadcaster - single source of truth
@@ -481,13 +659,20 @@ func (m *Manager) ListOperations() []*OperationState {
 	return operations
 }
 
-// CancelOperation cancels a running operation
+// CancelOperation cancels a running operation. A queued operation is taken
+// out of the queue.
 func (m *Manager) CancelOperation(id string) error {
+	dequeued := m.admission.dequeue(id)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	state, exists := m.operations[id]
 	if !exists {
+		if dequeued {
+			m.broadcaster.CancelOperation(id)
+			return nil
+		}
 		return fmt.Errorf("operation %s not found", id)
 	}
 
@@ -510,7 +695,130 @@ func (m *Manager) removeOperation(id string) {
 	delete(m.operations, id)
 }
 
+// recordSuccess stores the completion time of a successful operation
+func (m *Manager) recordSuccess(at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSuccess = at
+}
+
+// LastSuccessfulRun returns when an operation last completed successfully,
+// or the zero time if none has completed since startup
+func (m *Manager) LastSuccessfulRun() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastSuccess
+}
+
+// SetArtifactRecorder enables artifact manifests for every operation run
+func (m *Manager) SetArtifactRecorder(r *ArtifactRecorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.artifacts = r
+}
+
+// GetArtifacts returns the artifact manifest of an operation run
+func (m *Manager) GetArtifacts(operationID string) (*ArtifactManifest, error) {
+	return m.artifacts.Load(operationID)
+}
+
+// finishArtifacts saves the final artifact manifest of a run
+func (m *Manager) finishArtifacts(ctx context.Context, operationID string, runErr error) {
+	if err := m.artifacts.Finish(operationID, runErr); err != nil {
+		slog.WarnContext(ctx, "artifact_manifest_failed",
+			slog.String("operation_id", operationID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// OnStepCompleted registers a callback invoked after a step completes
+// successfully, e.g. to drop cached data the step rewrote. Callbacks run on
+// the operation goroutine, so slow work should be handed off.
+func (m *Manager) OnStepCompleted(fn func(stepID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onStepCompleted = append(m.onStepCompleted, fn)
+}
+
+// recordStepOutcome stores when a step last succeeded or failed and runs the
+// OnStepCompleted callbacks after a success
+func (m *Manager) recordStepOutcome(stepID string, at time.Time, err error) {
+	m.mu.Lock()
+	if m.stepOutcomes == nil {
+		m.stepOutcomes = make(map[string]StepOutcome)
+	}
+	outcome := m.stepOutcomes[stepID]
+	if err != nil {
+		outcome.LastFailure = at
+		outcome.LastError = err.Error()
+	} else {
+		outcome.LastSuccess = at
+	}
+	m.stepOutcomes[stepID] = outcome
+	callbacks := m.onStepCompleted
+	m.mu.Unlock()
+
+	if err == nil {
+		for _, fn := range callbacks {
+			fn(stepID)
+		}
+	}
+}
+
+// StepOutcomes returns the last success and failure of every step that has
+// run since startup, keyed by step ID
+func (m *Manager) StepOutcomes() map[string]StepOutcome {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	outcomes := make(map[string]StepOutcome, len(m.stepOutcomes))
+	for id, outcome := range m.stepOutcomes {
+		outcomes[id] = outcome
+	}
+	return outcomes
+}
+
 // GetConfig returns the current configuration
 func (m *Manager) GetConfig() *Config {
 	return m.config
 }
+
+// Acquire waits until operationID may run stepIDs (all stages when empty):
+// fewer than Config.MaxConcurrentOperations operations are running and none
+// of them uses the same data directories. While waiting, the operation's
+// queue position is broadcast. With ConflictReject it fails at once with an
+// *OperationConflictError instead. The returned function releases the slot.
+func (m *Manager) Acquire(ctx context.Context, operationID string, stepIDs []string, policy ConflictPolicy) (func(), error) {
+	return m.admission.acquire(ctx, operationID, ResourcesForSteps(stepIDs), policy)
+}
+
+// Conflicts returns the running and queued operations a new operation
+// running stepIDs would wait for, and the data directories they share
+func (m *Manager) Conflicts(stepIDs []string) ([]string, []Resource) {
+	return m.admission.conflicts("", ResourcesForSteps(stepIDs))
+}
+
+// Queue returns the operations waiting to start, in order
+func (m *Manager) Queue() []QueueStatus {
+	return m.admission.queue()
+}
+
+// ResolveConflictPolicy returns the on_conflict request parameter, or the
+// configured policy when it is not set
+func (m *Manager) ResolveConflictPolicy(params map[string]interface{}) (ConflictPolicy, error) {
+	if value, ok := params[ParameterOnConflict].(string); ok && value != "" {
+		return ParseConflictPolicy(value)
+	}
+	return ParseConflictPolicy(string(m.config.ConflictPolicy))
+}
+
+// broadcastQueue publishes the position of every waiting operation
+func (m *Manager) broadcastQueue(queue []QueueStatus) {
+	for _, status := range queue {
+		m.broadcaster.QueueOperation(status.OperationID, status.Position, status.BlockedBy)
+	}
+	if m.hub != nil {
+		m.hub.BroadcastUpdate("operation:queue", "", "update", map[string]interface{}{
+			"queue": queue,
+		})
+	}
+}
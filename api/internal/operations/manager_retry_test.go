@@ -0,0 +1,145 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stepMessageHub records the step messages of every broadcast snapshot
+type stepMessageHub struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (h *stepMessageHub) BroadcastUpdate(eventType, step, status string, metadata interface{}) {
+	snapshot, ok := metadata.(*OperationSnapshot)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, s := range snapshot.Steps {
+		h.messages = append(h.messages, s.Message)
+	}
+}
+
+func (h *stepMessageHub) contains(message string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, m := range h.messages {
+		if m == message {
+			return true
+		}
+	}
+	return false
+}
+
+// failingStage fails its first failures attempts with err
+func failingStage(id string, failures int, err error) *SimpleMockStage {
+	stage := &SimpleMockStage{id: id, name: id}
+	stage.executeFunc = func(ctx context.Context, state *OperationState) error {
+		if stage.executeCalls <= failures {
+			return err
+		}
+		return nil
+	}
+	return stage
+}
+
+func fastRetries(attempts int, classes ...ErrorClass) RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  attempts,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+		Multiplier:   2,
+		RetryOn:      classes,
+	}
+}
+
+func TestManagerRetriesTransientSubprocessErrors(t *testing.T) {
+	hub := &stepMessageHub{}
+	manager := NewManager(hub, nil, NewConfigBuilder().WithRetryConfig(fastRetries(3)).Build())
+
+	stage := failingStage(StageIDScraping, 2, errors.New("scraper failed: exit status 1, stderr: dial tcp: connection refused"))
+	require.NoError(t, manager.RegisterStage(stage))
+
+	resp, err := manager.Execute(context.Background(), OperationRequest{ID: "retry-network"})
+	require.NoError(t, err)
+	assert.Equal(t, OperationStatusCompleted, resp.Status)
+	assert.Equal(t, 3, stage.executeCalls)
+	assert.True(t, hub.contains("Step started (attempt 2/3)"))
+	assert.True(t, hub.contains(fmt.Sprintf("Retrying attempt 3/3 in %s: scraper failed: exit status 1, stderr: dial tcp: connection refused", 2*time.Millisecond)))
+}
+
+func TestManagerDoesNotRetryPermanentErrors(t *testing.T) {
+	manager := NewManager(&stepMessageHub{}, nil, NewConfigBuilder().WithRetryConfig(fastRetries(3)).Build())
+
+	stage := failingStage(StageIDProcessing, 3, errors.New("processor failed: exit status 2, stderr: invalid workbook"))
+	require.NoError(t, manager.RegisterStage(stage))
+
+	resp, err := manager.Execute(context.Background(), OperationRequest{ID: "retry-permanent"})
+	assert.Error(t, err)
+	assert.Equal(t, OperationStatusFailed, resp.Status)
+	assert.Equal(t, 1, stage.executeCalls)
+}
+
+func TestManagerStageRetryPolicy(t *testing.T) {
+	config := NewConfigBuilder().
+		WithRetryConfig(fastRetries(1)).
+		WithStageRetryPolicy(StageIDScraping, fastRetries(2, ErrorClassTimeout)).
+		Build()
+	manager := NewManager(&stepMessageHub{}, nil, config)
+
+	// The scraping policy retries timeouts only
+	scraping := failingStage(StageIDScraping, 1, errors.New("navigation timed out after 60s"))
+	require.NoError(t, manager.RegisterStage(scraping))
+	_, err := manager.Execute(context.Background(), OperationRequest{ID: "policy-timeout"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, scraping.executeCalls)
+
+	manager = NewManager(&stepMessageHub{}, nil, config)
+	scraping = failingStage(StageIDScraping, 1, errors.New("no such host"))
+	require.NoError(t, manager.RegisterStage(scraping))
+	_, err = manager.Execute(context.Background(), OperationRequest{ID: "policy-network"})
+	assert.Error(t, err)
+	assert.Equal(t, 1, scraping.executeCalls, "network errors are not in the scraping policy")
+
+	assert.Equal(t, 1, config.GetRetryConfig(StageIDIndices).MaxAttempts)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{errors.New("scraper failed: exit status 1, stderr: net::ERR_NAME_NOT_RESOLVED"), ErrorClassNetwork},
+		{errors.New("Get \"https://isx-iq.net\": read: connection reset by peer"), ErrorClassNetwork},
+		{errors.New("waiting for selector: context deadline exceeded"), ErrorClassTimeout},
+		{fmt.Errorf("scraper: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{NewTimeoutError(StageIDScraping, "1m"), ErrorClassTimeout},
+		{fmt.Errorf("scraper: %w", context.Canceled), ErrorClassPermanent},
+		{errors.New("processor failed: invalid workbook"), ErrorClassPermanent},
+		{nil, ErrorClassPermanent},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRetryConfigShouldRetry(t *testing.T) {
+	config := NewRetryConfig()
+	assert.True(t, config.ShouldRetry(errors.New("dial tcp: i/o timeout")))
+	assert.False(t, config.ShouldRetry(errors.New("file not found")))
+	assert.False(t, config.ShouldRetry(NewFatalError("connection refused", nil)), "operation errors keep their own flag")
+	assert.True(t, config.ShouldRetry(NewExecutionError(StageIDProcessing, errors.New("locked"), true)))
+
+	config.RetryOn = []ErrorClass{ErrorClassPermanent}
+	assert.False(t, config.ShouldRetry(errors.New("file not found")), "permanent errors are never retried")
+}
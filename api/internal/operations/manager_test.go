@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -487,4 +488,103 @@ func TestManagerConcurrentExecutions(t *testing.T) {
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && s[0:len(substr)] == substr || len(s) > len(substr) && contains(s[1:], substr)
-}
\ No newline at end of file
+}
+func TestManagerLastSuccessfulRun(t *testing.T) {
+	hub := &testutil.MockWebSocketHub{}
+	manager := operations.NewManager(hub, nil, testutil.CreateTestConfig())
+
+	if !manager.LastSuccessfulRun().IsZero() {
+		t.Fatal("expected zero time before any operation has run")
+	}
+
+	failing := testutil.CreateFailingStage("f1", "Failing Step", errors.New("boom"))
+	manager.RegisterStage(failing)
+	_, err := manager.Execute(context.Background(), operations.OperationRequest{
+		ID:         "test-last-success-fail",
+		Parameters: map[string]interface{}{"step": "f1"},
+	})
+	testutil.AssertError(t, err, true)
+	if !manager.LastSuccessfulRun().IsZero() {
+		t.Fatal("failed operation must not update last successful run")
+	}
+
+	manager.RegisterStage(testutil.CreateSuccessfulStage("s1", "Step 1"))
+	before := time.Now()
+	_, err = manager.Execute(context.Background(), operations.OperationRequest{
+		ID:         "test-last-success-ok",
+		Parameters: map[string]interface{}{"step": "s1"},
+	})
+	testutil.AssertNoError(t, err)
+
+	last := manager.LastSuccessfulRun()
+	if last.Before(before) {
+		t.Errorf("expected last successful run after %v, got %v", before, last)
+	}
+}
+
+func TestManagerStepOutcomes(t *testing.T) {
+	hub := &testutil.MockWebSocketHub{}
+	manager := operations.NewManager(hub, nil, testutil.CreateTestConfig())
+
+	if len(manager.StepOutcomes()) != 0 {
+		t.Fatal("expected no step outcomes before any operation has run")
+	}
+
+	manager.RegisterStage(testutil.CreateSuccessfulStage("s1", "Step 1"))
+	manager.RegisterStage(testutil.CreateFailingStage("f1", "Failing Step", errors.New("boom")))
+
+	_, err := manager.Execute(context.Background(), operations.OperationRequest{
+		ID:         "test-outcome-ok",
+		Parameters: map[string]interface{}{"step": "s1"},
+	})
+	testutil.AssertNoError(t, err)
+	_, err = manager.Execute(context.Background(), operations.OperationRequest{
+		ID:         "test-outcome-fail",
+		Parameters: map[string]interface{}{"step": "f1"},
+	})
+	testutil.AssertError(t, err, true)
+
+	outcomes := manager.StepOutcomes()
+	if outcomes["s1"].LastSuccess.IsZero() || !outcomes["s1"].LastFailure.IsZero() {
+		t.Errorf("expected s1 to have only succeeded, got %+v", outcomes["s1"])
+	}
+	if outcomes["f1"].LastFailure.IsZero() || !outcomes["f1"].LastSuccess.IsZero() {
+		t.Errorf("expected f1 to have only failed, got %+v", outcomes["f1"])
+	}
+	if outcomes["f1"].LastError == "" {
+		t.Errorf("expected f1 to record its error, got %q", outcomes["f1"].LastError)
+	}
+}
+
+func TestManagerOnStepCompleted(t *testing.T) {
+	hub := &testutil.MockWebSocketHub{}
+	manager := operations.NewManager(hub, nil, testutil.CreateTestConfig())
+
+	var mu sync.Mutex
+	var completed []string
+	manager.OnStepCompleted(func(stepID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		completed = append(completed, stepID)
+	})
+
+	manager.RegisterStage(testutil.CreateSuccessfulStage("s1", "Step 1"))
+	manager.RegisterStage(testutil.CreateFailingStage("f1", "Failing Step", errors.New("boom")))
+
+	_, err := manager.Execute(context.Background(), operations.OperationRequest{
+		ID:         "test-completed-ok",
+		Parameters: map[string]interface{}{"step": "s1"},
+	})
+	testutil.AssertNoError(t, err)
+	_, err = manager.Execute(context.Background(), operations.OperationRequest{
+		ID:         "test-completed-fail",
+		Parameters: map[string]interface{}{"step": "f1"},
+	})
+	testutil.AssertError(t, err, true)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(completed) != 1 || completed[0] != "s1" {
+		t.Errorf("expected only s1 to be reported as completed, got %v", completed)
+	}
+}
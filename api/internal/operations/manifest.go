@@ -23,6 +23,7 @@ type PipelineManifest struct {
 	FromDate string                 `json:"from_date,omitempty"`
 	ToDate   string                 `json:"to_date,omitempty"`
 	Mode     string                 `json:"mode"`
+	Profile  string                 `json:"profile,omitempty"`
 	Config   map[string]interface{} `json:"config,omitempty"`
 	
 	// Available data tracking
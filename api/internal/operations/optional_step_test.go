@@ -0,0 +1,84 @@
+package operations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// optionalMockStage joins a full pipeline only when the "housekeeping" parameter is set
+type optionalMockStage struct {
+	mockStage
+}
+
+func (o *optionalMockStage) IncludeInPipeline(state *OperationState) bool {
+	_, ok := state.GetConfig("housekeeping")
+	return ok
+}
+
+func TestManager_OptionalSteps(t *testing.T) {
+	var ran []string
+	record := func(id string) func(context.Context, *OperationState) error {
+		return func(context.Context, *OperationState) error {
+			ran = append(ran, id)
+			return nil
+		}
+	}
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(&mockStage{id: "collect", name: "Collect", executeFunc: record("collect")}))
+	require.NoError(t, registry.Register(&optionalMockStage{mockStage{
+		id: "cleanup", name: "Cleanup", dependencies: []string{"collect"}, executeFunc: record("cleanup"),
+	}}))
+	manager := NewManager(nil, registry, NewConfig())
+
+	t.Run("skipped by default", func(t *testing.T) {
+		ran = nil
+		resp, err := manager.Execute(context.Background(), OperationRequest{ID: "op-default"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"collect"}, ran)
+		assert.NotContains(t, resp.Steps, "cleanup")
+	})
+
+	t.Run("included when requested", func(t *testing.T) {
+		ran = nil
+		_, err := manager.Execute(context.Background(), OperationRequest{
+			ID:         "op-housekeeping",
+			Parameters: map[string]interface{}{"housekeeping": true},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"collect", "cleanup"}, ran)
+	})
+
+	t.Run("runs alone without its dependencies", func(t *testing.T) {
+		ran = nil
+		_, err := manager.Execute(context.Background(), OperationRequest{
+			ID:         "op-single",
+			Parameters: map[string]interface{}{"step": "cleanup"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"cleanup"}, ran)
+	})
+
+	t.Run("selected steps run in dependency order", func(t *testing.T) {
+		ran = nil
+		_, err := manager.Execute(context.Background(), OperationRequest{
+			ID:         "op-selected",
+			Parameters: map[string]interface{}{ContextKeySteps: []interface{}{"cleanup", "collect"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"collect", "cleanup"}, ran)
+	})
+
+	t.Run("unknown selected step fails", func(t *testing.T) {
+		ran = nil
+		_, err := manager.Execute(context.Background(), OperationRequest{
+			ID:         "op-unknown",
+			Parameters: map[string]interface{}{ContextKeySteps: []string{"collect", "publish"}},
+		})
+		assert.ErrorContains(t, err, "publish")
+		assert.Empty(t, ran)
+	})
+}
@@ -0,0 +1,172 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+)
+
+// OperationPlan is the result of a dry run: the steps an operation would run
+// and the files they would download or process, worked out without
+// executing anything
+type OperationPlan struct {
+	OperationID string      `json:"operation_id"`
+	Mode        string      `json:"mode,omitempty"`
+	FromDate    string      `json:"from_date,omitempty"`
+	ToDate      string      `json:"to_date,omitempty"`
+	Steps       []*StepPlan `json:"steps"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// Step returns the plan of the step with the given ID, or nil
+func (p *OperationPlan) Step(id string) *StepPlan {
+	for _, step := range p.Steps {
+		if step.ID == id {
+			return step
+		}
+	}
+	return nil
+}
+
+// StepPlan describes what a single step would do
+type StepPlan struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	WillRun bool   `json:"will_run"`
+	// Reason explains why the step would not run
+	Reason string `json:"reason,omitempty"`
+
+	Inputs  []DataRequirement `json:"inputs"`
+	Outputs []DataOutput      `json:"outputs"`
+
+	// MissingDependencies lists steps this one depends on that would not
+	// run, and MissingInputs the required data types nothing provides
+	MissingDependencies []string `json:"missing_dependencies,omitempty"`
+	MissingInputs       []string `json:"missing_inputs,omitempty"`
+
+	// File counts are filled in by steps implementing StepPlanner:
+	// ExpectedFiles in the step's range, ExistingFiles already on disk, and
+	// PendingFiles the step would download or process
+	ExpectedFiles int               `json:"expected_files"`
+	ExistingFiles int               `json:"existing_files"`
+	PendingFiles  []string          `json:"pending_files,omitempty"`
+	MissingRanges []files.DateRange `json:"missing_ranges,omitempty"`
+
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// StepPlanner is implemented by steps that can describe the files they would
+// download or process. PlanStep fills in step and may read the plans of
+// earlier steps; it must not launch subprocesses or write files.
+type StepPlanner interface {
+	PlanStep(state *OperationState, plan *OperationPlan, step *StepPlan) error
+}
+
+// Plan works out what Execute would do for req without running any step:
+// which steps are selected, whether each could run, and for steps
+// implementing StepPlanner, the files they would download or process.
+// Unknown steps and invalid dates are reported as validation errors.
+func (m *Manager) Plan(ctx context.Context, req OperationRequest) (*OperationPlan, error) {
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("plan-%d", time.Now().Unix())
+	}
+	if req.Profile == "" {
+		req.Profile = config.ProfileFromContext(ctx)
+	}
+
+	state := newRequestState(req)
+	steps, err := m.resolveSteps(ctx, req, state)
+	if err != nil {
+		return nil, NewValidationError("", err.Error())
+	}
+	single, _ := state.GetContext(ContextKeySingleStep)
+
+	plan := &OperationPlan{
+		OperationID: req.ID,
+		Mode:        req.Mode,
+		FromDate:    req.FromDate,
+		ToDate:      req.ToDate,
+		Steps:       make([]*StepPlan, 0, len(steps)),
+		GeneratedAt: time.Now(),
+	}
+
+	// The manifest starts empty, as for a new job, and collects the outputs of
+	// the steps that would run so later steps see them
+	manifest := NewPipelineManifest(req.ID, req.FromDate, req.ToDate)
+	manifest.Profile = req.Profile
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		stepPlan := &StepPlan{
+			ID:      step.ID(),
+			Name:    step.Name(),
+			Inputs:  step.RequiredInputs(),
+			Outputs: step.ProducedOutputs(),
+		}
+		plan.Steps = append(plan.Steps, stepPlan)
+
+		for _, dep := range step.GetDependencies() {
+			depPlan := plan.Step(dep)
+			if (depPlan == nil && single != true) || (depPlan != nil && !depPlan.WillRun) {
+				stepPlan.MissingDependencies = append(stepPlan.MissingDependencies, dep)
+			}
+		}
+
+		validateErr := step.Validate(state)
+		switch {
+		case len(stepPlan.MissingDependencies) > 0:
+			stepPlan.Reason = fmt.Sprintf("dependencies would not run: %v", stepPlan.MissingDependencies)
+		case validateErr != nil:
+			stepPlan.Reason = fmt.Sprintf("validation failed: %v", validateErr)
+		case !step.CanRun(manifest):
+			stepPlan.MissingInputs = missingInputs(step, manifest)
+			stepPlan.Reason = "required input data is not available"
+		default:
+			stepPlan.WillRun = true
+		}
+
+		if planner, ok := step.(StepPlanner); ok {
+			if err := planner.PlanStep(state, plan, stepPlan); err != nil {
+				return nil, fmt.Errorf("failed to plan step %s: %w", step.ID(), err)
+			}
+		}
+
+		if stepPlan.WillRun {
+			produced := 1
+			if _, ok := step.(StepPlanner); ok {
+				produced = stepPlan.ExistingFiles + len(stepPlan.PendingFiles)
+			}
+			for _, output := range stepPlan.Outputs {
+				manifest.AddData(output.Type, &DataInfo{
+					Type:        output.Type,
+					Location:    output.Location,
+					FilePattern: output.Pattern,
+					FileCount:   produced,
+					CreatedBy:   step.ID(),
+				})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// missingInputs returns the required data types a step would not find in
+// the manifest
+func missingInputs(step Step, manifest *PipelineManifest) []string {
+	var missing []string
+	for _, req := range step.RequiredInputs() {
+		if req.Optional {
+			continue
+		}
+		if data, ok := manifest.GetData(req.Type); !ok || data.FileCount < req.MinCount {
+			missing = append(missing, req.Type)
+		}
+	}
+	return missing
+}
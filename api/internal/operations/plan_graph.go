@@ -0,0 +1,254 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlanGraph is an operation's step plan as a dependency graph, for drawing
+// the pipeline: one node per step and an edge wherever a step waits for
+// another, either declared or because it consumes the other's output
+type PlanGraph struct {
+	OperationID string `json:"operation_id"`
+	// Active is true for a running operation, whose nodes carry step status
+	Active      bool       `json:"active"`
+	Mode        string     `json:"mode,omitempty"`
+	FromDate    string     `json:"from_date,omitempty"`
+	ToDate      string     `json:"to_date,omitempty"`
+	Nodes       []PlanNode `json:"nodes"`
+	Edges       []PlanEdge `json:"edges"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// PlanNode is a step in a PlanGraph, in execution order
+type PlanNode struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Status       StepStatus `json:"status,omitempty"`
+	WillRun      bool       `json:"will_run"`
+	Reason       string     `json:"reason,omitempty"`
+	Dependencies []string   `json:"dependencies"`
+	Inputs       []PlanPort `json:"inputs"`
+	Outputs      []PlanPort `json:"outputs"`
+}
+
+// PlanPort is a data type a step needs or produces. An input is satisfied
+// when the step can find it; an output when the step would produce it.
+type PlanPort struct {
+	Type     string `json:"type"`
+	Location string `json:"location,omitempty"`
+	MinCount int    `json:"min_count,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	// ProvidedBy is the earlier step in the plan producing an input
+	ProvidedBy string `json:"provided_by,omitempty"`
+	Satisfied  bool   `json:"satisfied"`
+}
+
+// PlanEdge runs from the step that must finish first to the step waiting on
+// it. Dependency marks a declared dependency; Data lists the data types
+// passed along it.
+type PlanEdge struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Dependency bool     `json:"dependency"`
+	Data       []string `json:"data,omitempty"`
+}
+
+// PlanGraph returns the step graph of id: a running operation, planned with
+// its own request and annotated with step status, or else an operation type
+// ("full_pipeline" or a step ID), planned as a fresh run with defaults.
+func (m *Manager) PlanGraph(ctx context.Context, id string) (*PlanGraph, error) {
+	var req OperationRequest
+	var state *OperationState
+	switch {
+	case m.hasOperation(id):
+		var err error
+		if state, err = m.GetOperation(id); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrOperationNotFound, id)
+		}
+		req = requestFromState(state)
+	case id == "full_pipeline" || m.registry.Has(id):
+		req = OperationRequest{ID: id, Parameters: map[string]interface{}{"step": id}}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrOperationNotFound, id)
+	}
+
+	plan, err := m.Plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	graph := buildPlanGraph(plan, m.registry)
+	if state != nil {
+		graph.Active = true
+		for i := range graph.Nodes {
+			if step := state.Steps[graph.Nodes[i].ID]; step != nil {
+				graph.Nodes[i].Status = step.Status
+			}
+		}
+	}
+	return graph, nil
+}
+
+// hasOperation reports whether id is a running operation
+func (m *Manager) hasOperation(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.operations[id]
+	return ok
+}
+
+// requestFromState rebuilds the request a running operation was started with
+func requestFromState(state *OperationState) OperationRequest {
+	req := OperationRequest{ID: state.ID, Parameters: make(map[string]interface{}, len(state.Config))}
+	for k, v := range state.Config {
+		req.Parameters[k] = v
+	}
+	req.FromDate, _ = state.Config[ContextKeyFromDate].(string)
+	req.ToDate, _ = state.Config[ContextKeyToDate].(string)
+	req.Mode, _ = state.Config[ContextKeyMode].(string)
+	return req
+}
+
+// buildPlanGraph turns a plan into nodes and edges
+func buildPlanGraph(plan *OperationPlan, registry *Registry) *PlanGraph {
+	graph := &PlanGraph{
+		OperationID: plan.OperationID,
+		Mode:        plan.Mode,
+		FromDate:    plan.FromDate,
+		ToDate:      plan.ToDate,
+		Nodes:       make([]PlanNode, 0, len(plan.Steps)),
+		Edges:       []PlanEdge{},
+		GeneratedAt: plan.GeneratedAt,
+	}
+
+	edges := make(map[[2]string]*PlanEdge)
+	edge := func(from, to string) *PlanEdge {
+		key := [2]string{from, to}
+		if e, ok := edges[key]; ok {
+			return e
+		}
+		e := &PlanEdge{From: from, To: to}
+		edges[key] = e
+		return e
+	}
+
+	// producers maps a data type to the latest planned step producing it
+	producers := make(map[string]*StepPlan)
+	for _, step := range plan.Steps {
+		node := PlanNode{
+			ID:           step.ID,
+			Name:         step.Name,
+			WillRun:      step.WillRun,
+			Reason:       step.Reason,
+			Dependencies: []string{},
+			Inputs:       make([]PlanPort, 0, len(step.Inputs)),
+			Outputs:      make([]PlanPort, 0, len(step.Outputs)),
+		}
+		if s, err := registry.Get(step.ID); err == nil {
+			node.Dependencies = append(node.Dependencies, s.GetDependencies()...)
+		}
+		for _, dep := range node.Dependencies {
+			if plan.Step(dep) != nil {
+				edge(dep, step.ID).Dependency = true
+			}
+		}
+
+		missing := make(map[string]bool, len(step.MissingInputs))
+		for _, t := range step.MissingInputs {
+			missing[t] = true
+		}
+		for _, in := range step.Inputs {
+			port := PlanPort{Type: in.Type, Location: in.Location, MinCount: in.MinCount, Optional: in.Optional}
+			if producer := producers[in.Type]; producer != nil {
+				port.ProvidedBy = producer.ID
+				port.Satisfied = producer.WillRun
+				e := edge(producer.ID, step.ID)
+				e.Data = append(e.Data, in.Type)
+			}
+			if !missing[in.Type] && step.WillRun {
+				// CanRun found the data, e.g. left on disk by an earlier run
+				port.Satisfied = true
+			}
+			node.Inputs = append(node.Inputs, port)
+		}
+
+		for _, out := range step.Outputs {
+			node.Outputs = append(node.Outputs, PlanPort{Type: out.Type, Location: out.Location, Satisfied: step.WillRun})
+			producers[out.Type] = step
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for _, e := range edges {
+		graph.Edges = append(graph.Edges, *e)
+	}
+	order := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		order[node.ID] = i
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if order[a.From] != order[b.From] {
+			return order[a.From] < order[b.From]
+		}
+		return order[a.To] < order[b.To]
+	})
+	return graph
+}
+
+// DOT renders the graph in Graphviz DOT. Steps that would run are filled,
+// steps with unsatisfied inputs are outlined in red, and edges that only
+// pass data are dashed and labelled with the data types.
+func (g *PlanGraph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.OperationID))
+	b.WriteString("  rankdir=LR;\n  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+
+	for _, node := range g.Nodes {
+		label := node.Name
+		if node.Status != "" {
+			label += "\n" + string(node.Status)
+		} else if !node.WillRun {
+			label += "\nwill not run"
+		}
+		fill := "#d4edda"
+		if !node.WillRun {
+			fill = "#e9ecef"
+		}
+		color := "#6c757d"
+		for _, in := range node.Inputs {
+			if !in.Satisfied && !in.Optional {
+				color = "#dc3545"
+				break
+			}
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, fillcolor=%s, color=%s];\n",
+			dotQuote(node.ID), dotQuote(label), dotQuote(fill), dotQuote(color))
+	}
+
+	for _, e := range g.Edges {
+		var attrs []string
+		if len(e.Data) > 0 {
+			attrs = append(attrs, "label="+dotQuote(strings.Join(e.Data, ", ")))
+		}
+		if !e.Dependency {
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes s as a DOT ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerPlanGraph(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager(&stepMessageHub{}, nil, NewConfig())
+	require.NoError(t, manager.RegisterStage(NewScrapingStage(stagePaths(t, dir), nil, nil)))
+	require.NoError(t, manager.RegisterStage(NewProcessingStage(stagePaths(t, dir), nil, nil)))
+
+	graph, err := manager.PlanGraph(context.Background(), "full_pipeline")
+	require.NoError(t, err)
+	assert.False(t, graph.Active)
+	require.Len(t, graph.Nodes, 2)
+	assert.Equal(t, StageIDScraping, graph.Nodes[0].ID)
+	assert.Equal(t, []string{StageIDScraping}, graph.Nodes[1].Dependencies)
+
+	require.Len(t, graph.Edges, 1)
+	edge := graph.Edges[0]
+	assert.Equal(t, StageIDScraping, edge.From)
+	assert.Equal(t, StageIDProcessing, edge.To)
+	assert.True(t, edge.Dependency)
+	assert.NotEmpty(t, edge.Data, "processing consumes the downloaded reports")
+
+	for _, in := range graph.Nodes[1].Inputs {
+		if in.ProvidedBy == StageIDScraping {
+			assert.Equal(t, graph.Nodes[0].WillRun, in.Satisfied)
+		}
+	}
+
+	dot := graph.DOT()
+	assert.Contains(t, dot, `digraph "full_pipeline" {`)
+	assert.Contains(t, dot, `"scraping" -> "processing"`)
+
+	_, err = manager.PlanGraph(context.Background(), "no-such-operation")
+	assert.ErrorIs(t, err, ErrOperationNotFound)
+}
+
+func TestBuildPlanGraphDataEdges(t *testing.T) {
+	registry := NewRegistry()
+	plan := &OperationPlan{
+		OperationID: "op-1",
+		Steps: []*StepPlan{
+			{ID: "a", Name: "A", WillRun: true, Outputs: []DataOutput{{Type: "csv_files"}}},
+			{ID: "b", Name: "B", WillRun: false, Reason: "required input data is not available",
+				Inputs:        []DataRequirement{{Type: "csv_files", MinCount: 1}, {Type: "index_files", MinCount: 1}},
+				MissingInputs: []string{"index_files"}},
+		},
+	}
+
+	graph := buildPlanGraph(plan, registry)
+	require.Len(t, graph.Edges, 1)
+	assert.Equal(t, PlanEdge{From: "a", To: "b", Data: []string{"csv_files"}}, graph.Edges[0])
+
+	inputs := graph.Nodes[1].Inputs
+	assert.True(t, inputs[0].Satisfied, "a will produce the csv files")
+	assert.Equal(t, "a", inputs[0].ProvidedBy)
+	assert.False(t, inputs[1].Satisfied)
+	assert.Contains(t, graph.DOT(), `"a" -> "b" [label="csv_files", style=dashed];`)
+	assert.Contains(t, graph.DOT(), `label="B\nwill not run"`)
+}
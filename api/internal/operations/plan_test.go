@@ -0,0 +1,194 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+// stagePaths lays out the active profile's paths under exeDir
+func stagePaths(t *testing.T, exeDir string) *config.Paths {
+	t.Helper()
+	paths, err := config.PathsForDir(exeDir)
+	require.NoError(t, err)
+	return paths
+}
+
+func writePlanFile(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+}
+
+func TestManagerPlanReportsFilesWithoutExecuting(t *testing.T) {
+	dir := t.TempDir()
+	downloads := filepath.Join(dir, "data", "downloads")
+	writePlanFile(t, filepath.Join(downloads, "2025 06 01 ISX Daily Report.xlsx"))
+	writePlanFile(t, filepath.Join(downloads, "2025 06 02 ISX Daily Report.xlsx"))
+	writePlanFile(t, filepath.Join(dir, "data", "reports", "daily", "2025", "06", "isx_daily_2025_06_01.csv"))
+
+	manager := NewManager(&stepMessageHub{}, nil, NewConfig())
+	require.NoError(t, manager.RegisterStage(NewScrapingStage(stagePaths(t, dir), nil, nil)))
+	require.NoError(t, manager.RegisterStage(NewProcessingStage(stagePaths(t, dir), nil, nil)))
+
+	// 2025-06-01 is a Sunday; the ISX trades Sunday to Thursday
+	plan, err := manager.Plan(context.Background(), OperationRequest{
+		ID:       "dry-run",
+		Mode:     "initial",
+		FromDate: "2025-06-01",
+		ToDate:   "2025-06-07",
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 2)
+
+	scraping := plan.Step(StageIDScraping)
+	require.NotNil(t, scraping)
+	assert.True(t, scraping.WillRun)
+	assert.Equal(t, 5, scraping.ExpectedFiles)
+	assert.Equal(t, 2, scraping.ExistingFiles)
+	assert.Equal(t, []string{
+		"2025 06 03 ISX Daily Report.xlsx",
+		"2025 06 04 ISX Daily Report.xlsx",
+		"2025 06 05 ISX Daily Report.xlsx",
+	}, scraping.PendingFiles)
+	require.Len(t, scraping.MissingRanges, 1)
+	assert.Equal(t, 3, scraping.MissingRanges[0].Days)
+	assert.Equal(t, "2025-05-25", scraping.Details["search_from"])
+
+	processing := plan.Step(StageIDProcessing)
+	require.NotNil(t, processing)
+	assert.True(t, processing.WillRun)
+	assert.Equal(t, 1, processing.ExistingFiles)
+	assert.Len(t, processing.PendingFiles, 4, "two downloaded and three planned reports, one already processed")
+
+	assert.Empty(t, manager.ListOperations(), "planning does not start an operation")
+}
+
+func TestManagerPlanMissingDependencies(t *testing.T) {
+	manager := NewManager(&stepMessageHub{}, nil, NewConfig())
+	scraping := &SimpleMockStage{id: StageIDScraping, name: "Scraping",
+		validateFunc: func(*OperationState) error { return errors.New("scraper executable not found") }}
+	processing := &SimpleMockStage{id: StageIDProcessing, name: "Processing", dependencies: []string{StageIDScraping}}
+	require.NoError(t, manager.RegisterStage(scraping))
+	require.NoError(t, manager.RegisterStage(processing))
+
+	plan, err := manager.Plan(context.Background(), OperationRequest{})
+	require.NoError(t, err)
+
+	assert.False(t, plan.Step(StageIDScraping).WillRun)
+	assert.Contains(t, plan.Step(StageIDScraping).Reason, "scraper executable not found")
+	assert.False(t, plan.Step(StageIDProcessing).WillRun)
+	assert.Equal(t, []string{StageIDScraping}, plan.Step(StageIDProcessing).MissingDependencies)
+	assert.Zero(t, scraping.executeCalls+processing.executeCalls)
+
+	// A single step works on the data earlier runs left behind
+	plan, err = manager.Plan(context.Background(), OperationRequest{Parameters: map[string]interface{}{"step": StageIDProcessing}})
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 1)
+	assert.True(t, plan.Steps[0].WillRun)
+
+	_, err = manager.Plan(context.Background(), OperationRequest{Parameters: map[string]interface{}{"step": "unknown"}})
+	assert.Error(t, err)
+}
+
+func TestScrapingPlanAccumulative(t *testing.T) {
+	dir := t.TempDir()
+	writePlanFile(t, filepath.Join(dir, "data", "downloads", "2025 06 02 ISX Daily Report.xlsx"))
+
+	state := NewOperationState("plan")
+	state.SetConfig(ContextKeyMode, "accumulative")
+	state.SetConfig(ContextKeyFromDate, "2025-06-01")
+	state.SetConfig(ContextKeyToDate, "2025-06-05")
+
+	step := &StepPlan{}
+	require.NoError(t, NewScrapingStage(stagePaths(t, dir), nil, nil).PlanStep(state, &OperationPlan{}, step))
+	assert.Equal(t, "2025-06-02", step.Details["resume_after"])
+	assert.Equal(t, 3, step.ExpectedFiles)
+	assert.Len(t, step.PendingFiles, 3)
+
+	state.SetConfig(ContextKeyToDate, time.Now().AddDate(0, 0, 30).Format("2006-01-02"))
+	require.NoError(t, NewScrapingStage(stagePaths(t, dir), nil, nil).PlanStep(state, &OperationPlan{}, &StepPlan{}), "future dates are capped at today")
+
+	state.SetConfig(ContextKeyFromDate, "06/01/2025")
+	assert.Error(t, NewScrapingStage(stagePaths(t, dir), nil, nil).PlanStep(state, &OperationPlan{}, &StepPlan{}))
+}
+
+func TestScrapingPlanUsesProfileCalendar(t *testing.T) {
+	t.Setenv(config.ProfileEnvVar, "desk")
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "data"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "holidays.txt"), []byte("2025-06-03\n"), 0644))
+	profileData := filepath.Join(dir, "profiles", "desk", "data")
+	require.NoError(t, os.MkdirAll(profileData, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(profileData, "holidays.txt"), []byte("2025-06-04\n"), 0644))
+
+	state := NewOperationState("plan")
+	state.SetConfig(ContextKeyFromDate, "2025-06-02")
+	state.SetConfig(ContextKeyToDate, "2025-06-05")
+
+	step := &StepPlan{}
+	require.NoError(t, NewScrapingStage(stagePaths(t, dir), nil, nil).PlanStep(state, &OperationPlan{}, step))
+	assert.Equal(t, []string{
+		"2025 06 02 ISX Daily Report.xlsx",
+		"2025 06 03 ISX Daily Report.xlsx",
+		"2025 06 05 ISX Daily Report.xlsx",
+	}, step.PendingFiles, "the desk profile's holidays apply, not the default profile's")
+}
+
+func TestProcessingPlanUsesRequestProfile(t *testing.T) {
+	dir := t.TempDir()
+	writePlanFile(t, filepath.Join(dir, "data", "downloads", "2025 06 01 ISX Daily Report.xlsx"))
+	writePlanFile(t, filepath.Join(dir, "profiles", "desk", "data", "downloads", "2025 06 02 ISX Daily Report.xlsx"))
+
+	stage := NewProcessingStage(stagePaths(t, dir), nil, nil)
+	state := NewOperationState("plan")
+	state.SetConfig(ContextKeyProfile, "desk")
+
+	step := &StepPlan{}
+	require.NoError(t, stage.PlanStep(state, &OperationPlan{}, step))
+	assert.Equal(t, []string{"2025 06 02 ISX Daily Report.xlsx"}, step.PendingFiles,
+		"the request's profile is planned, not the server's")
+
+	paths := operationPaths(stage.paths, state)
+	assert.Equal(t, filepath.Join(dir, "profiles", "desk", "data", "downloads"), paths.DownloadsDir)
+	assert.Equal(t, []string{"--profile", "desk"}, profileArgs(paths))
+	assert.Equal(t, []string{"--profile", config.DefaultProfile}, profileArgs(stage.paths))
+}
+
+func TestProcessingPlanReprocessRange(t *testing.T) {
+	dir := t.TempDir()
+	downloads := filepath.Join(dir, "data", "downloads")
+	for _, day := range []string{"01", "02", "03"} {
+		writePlanFile(t, filepath.Join(downloads, "2025 06 "+day+" ISX Daily Report.xlsx"))
+		writePlanFile(t, filepath.Join(dir, "data", "reports", "daily", "isx_daily_2025_06_"+day+".csv"))
+	}
+
+	state := NewOperationState("plan")
+	state.SetConfig(ContextKeyReprocessFrom, "2025-06-02")
+	stage := NewProcessingStage(stagePaths(t, dir), nil, nil)
+	require.NoError(t, stage.Validate(state))
+
+	step := &StepPlan{}
+	require.NoError(t, stage.PlanStep(state, &OperationPlan{}, step))
+	assert.Equal(t, []string{"2025 06 02 ISX Daily Report.xlsx", "2025 06 03 ISX Daily Report.xlsx"}, step.PendingFiles,
+		"processed reports in the range are parsed again")
+	assert.Equal(t, 1, step.ExistingFiles)
+	assert.Equal(t, "2025-06-02", step.Details[ContextKeyReprocessFrom])
+
+	args, err := reprocessArgs(state)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--from", "2025-06-02"}, args)
+
+	state.SetConfig(ContextKeyReprocessTo, "2025-06-01")
+	assert.Error(t, stage.Validate(state), "to before from")
+	state.SetConfig(ContextKeyReprocessTo, "06/03/2025")
+	assert.Error(t, stage.Validate(state))
+}
@@ -0,0 +1,30 @@
+//go:build !unix
+
+package operations
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// configureProcess is a no-op; Windows has no process groups to signal
+func configureProcess(cmd *exec.Cmd) {}
+
+// terminateProcess kills the stage process. Windows cannot deliver SIGTERM to
+// console processes, so there is no graceful step.
+func terminateProcess(cmd *exec.Cmd) error {
+	return killProcess(cmd)
+}
+
+// killProcess kills the stage process
+func killProcess(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	err := cmd.Process.Kill()
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
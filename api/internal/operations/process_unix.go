@@ -0,0 +1,39 @@
+//go:build unix
+
+package operations
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess starts the stage in its own process group so signals reach
+// the browser and helper processes it spawns
+func configureProcess(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateProcess sends SIGTERM to the stage's process group
+func terminateProcess(cmd *exec.Cmd) error {
+	return signalGroup(cmd, syscall.SIGTERM)
+}
+
+// killProcess sends SIGKILL to the stage's process group
+func killProcess(cmd *exec.Cmd) error {
+	return signalGroup(cmd, syscall.SIGKILL)
+}
+
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	err := syscall.Kill(-cmd.Process.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"isxcli/pkg/contracts/events"
+)
+
+// DecodeProgressEvent parses a line of child command output as a structured
+// progress event. It returns false for regular log lines, malformed events and
+// events from a newer protocol version than this build understands.
+func DecodeProgressEvent(line string) (events.StepProgressEvent, bool) {
+	var ev events.StepProgressEvent
+
+	idx := strings.Index(line, events.StepProgressPrefix)
+	if idx < 0 {
+		return ev, false
+	}
+	if err := json.Unmarshal([]byte(line[idx+len(events.StepProgressPrefix):]), &ev); err != nil {
+		return ev, false
+	}
+	if ev.Version < 1 || ev.Version > events.StepProgressVersion || ev.Event == "" {
+		return ev, false
+	}
+	return ev, true
+}
+
+// parseLegacyProgressLine translates the free-text progress lines printed by
+// processor and indexcsv builds that predate the structured protocol. Stages
+// only use it until the first structured event arrives.
+func parseLegacyProgressLine(line string) (events.StepProgressEvent, bool) {
+	ev := events.StepProgressEvent{Version: events.StepProgressVersion}
+
+	switch {
+	case strings.Contains(line, "Files to process:"):
+		// "Files to process: file1.xlsx|file2.xlsx|file3.xlsx"
+		filesStr := strings.TrimSpace(line[strings.Index(line, "Files to process:")+len("Files to process:"):])
+		if filesStr == "" {
+			return ev, false
+		}
+		ev.Event = events.StepEventStart
+		ev.Files = strings.Split(filesStr, "|")
+		ev.Total = len(ev.Files)
+
+	case strings.Contains(line, "Processing file") && strings.Contains(line, "of"):
+		// "Processing file X of Y: filename"
+		rest := line[strings.Index(line, "Processing file"):]
+		if n, _ := fmt.Sscanf(rest, "Processing file %d of %d", &ev.Current, &ev.Total); n < 2 {
+			return ev, false
+		}
+		if idx := strings.Index(rest, ": "); idx >= 0 {
+			ev.File = strings.TrimSpace(rest[idx+2:])
+		}
+		ev.Event = events.StepEventProgress
+		ev.Status = events.ItemStatusProcessing
+
+	case strings.Contains(line, "Found") && strings.Contains(line, "Excel files"):
+		if _, err := fmt.Sscanf(line[strings.Index(line, "Found"):], "Found %d Excel files", &ev.Total); err != nil {
+			return ev, false
+		}
+		ev.Event = events.StepEventStart
+
+	case strings.Contains(line, "Processing complete"), strings.Contains(line, "Index extraction complete"):
+		ev.Event = events.StepEventComplete
+
+	default:
+		return ev, false
+	}
+	return ev, true
+}
+
+// stepProgressDecoder decodes child command output, preferring structured
+// events and falling back to legacy text until the first event is seen
+type stepProgressDecoder struct {
+	structured bool
+}
+
+// decode returns the progress event carried by line, if any
+func (d *stepProgressDecoder) decode(line string) (events.StepProgressEvent, bool) {
+	if ev, ok := DecodeProgressEvent(line); ok {
+		d.structured = true
+		return ev, true
+	}
+	if d.structured {
+		return events.StepProgressEvent{}, false
+	}
+	return parseLegacyProgressLine(line)
+}
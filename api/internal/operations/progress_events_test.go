@@ -0,0 +1,62 @@
+package operations
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/events"
+)
+
+func TestDecodeProgressEvent_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	emitter := events.NewProgressEmitter(&buf, StageIDProcessing)
+	emitter.Start(2, []string{"2025 01 05 ISX Daily Report.xlsx", "2025 01 06 ISX Daily Report.xlsx"})
+	emitter.Progress(1, 2, "2025 01 05 ISX Daily Report.xlsx", events.ItemStatusProcessing)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	start, ok := DecodeProgressEvent(lines[0])
+	require.True(t, ok)
+	assert.Equal(t, events.StepEventStart, start.Event)
+	assert.Equal(t, StageIDProcessing, start.Step)
+	assert.Equal(t, 2, start.Total)
+	assert.Len(t, start.Files, 2)
+
+	progress, ok := DecodeProgressEvent(lines[1])
+	require.True(t, ok)
+	assert.Equal(t, 1, progress.Current)
+	assert.Equal(t, "2025 01 05 ISX Daily Report.xlsx", progress.File, "file names keep their spaces")
+
+	_, ok = DecodeProgressEvent(`{"level":"INFO","msg":"Processing file"}`)
+	assert.False(t, ok, "plain log lines are not events")
+	_, ok = DecodeProgressEvent(events.StepProgressPrefix + `{"v":99,"event":"progress","step":"processing"}`)
+	assert.False(t, ok, "newer protocol versions are ignored")
+}
+
+func TestStepProgressDecoder_LegacyFallback(t *testing.T) {
+	var d stepProgressDecoder
+
+	ev, ok := d.decode("Found 3 Excel files")
+	require.True(t, ok)
+	assert.Equal(t, events.StepEventStart, ev.Event)
+	assert.Equal(t, 3, ev.Total)
+
+	ev, ok = d.decode("Processing file 2 of 3: 2025 01 05 ISX Daily Report.xlsx")
+	require.True(t, ok)
+	assert.Equal(t, 2, ev.Current)
+	assert.Equal(t, "2025 01 05 ISX Daily Report.xlsx", ev.File)
+
+	var buf bytes.Buffer
+	events.NewProgressEmitter(&buf, StageIDIndices).Complete(3, 3, "done")
+	ev, ok = d.decode(strings.TrimSpace(buf.String()))
+	require.True(t, ok)
+	assert.Equal(t, events.StepEventComplete, ev.Event)
+
+	_, ok = d.decode("Processing file 3 of 3: late.xlsx")
+	assert.False(t, ok, "legacy lines are ignored once structured events are seen")
+}
@@ -0,0 +1,90 @@
+package operations
+
+import (
+	"time"
+
+	"isxcli/internal/files"
+)
+
+// Day statuses of a ScrapeCalendar
+const (
+	CalendarDayDownloaded = "downloaded"
+	CalendarDayExisting   = "existing"
+	CalendarDayHoliday    = "holiday"
+	CalendarDayMissing    = "missing"
+)
+
+// ScrapeCalendar is the outcome of a scraping run per trading day of the
+// requested range, for rendering a calendar heat-map
+type ScrapeCalendar struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Days maps YYYY-MM-DD to a CalendarDay* status. Weekends are left out.
+	Days   map[string]string `json:"days"`
+	Counts map[string]int    `json:"counts"`
+}
+
+// scrapeCalendarBuilder collects the day events of a scraping run
+type scrapeCalendarBuilder struct {
+	from, to time.Time
+	days     map[string]string
+}
+
+// newScrapeCalendarBuilder covers from to to (YYYY-MM-DD); it returns nil
+// when either date is missing or invalid
+func newScrapeCalendarBuilder(from, to string) *scrapeCalendarBuilder {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil || end.Before(start) {
+		return nil
+	}
+	return &scrapeCalendarBuilder{from: start, to: end, days: make(map[string]string)}
+}
+
+// mark records the status of a day inside the range. A report on disk wins
+// over a holiday reported for the same day.
+func (b *scrapeCalendarBuilder) mark(date, status string) {
+	if b == nil {
+		return
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil || day.Before(b.from) || day.After(b.to) {
+		return
+	}
+	if status == CalendarDayHoliday && b.days[date] != "" {
+		return
+	}
+	b.days[date] = status
+}
+
+// calendar returns the days recorded so far; trading days up to now that
+// nothing was recorded for are missing
+func (b *scrapeCalendarBuilder) calendar(now time.Time) ScrapeCalendar {
+	cal := ScrapeCalendar{
+		From:   b.from.Format("2006-01-02"),
+		To:     b.to.Format("2006-01-02"),
+		Days:   make(map[string]string, len(b.days)),
+		Counts: make(map[string]int),
+	}
+	for date, status := range b.days {
+		cal.Days[date] = status
+	}
+
+	end := b.to
+	if today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC); today.Before(end) {
+		end = today
+	}
+	for _, day := range files.NewHolidayCalendar().TradingDays(b.from, end) {
+		date := day.Format("2006-01-02")
+		if cal.Days[date] == "" {
+			cal.Days[date] = CalendarDayMissing
+		}
+	}
+	for _, status := range cal.Days {
+		cal.Counts[status]++
+	}
+	return cal
+}
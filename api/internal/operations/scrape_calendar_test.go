@@ -0,0 +1,155 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/pkg/contracts/events"
+)
+
+func TestScrapeCalendar(t *testing.T) {
+	// Sunday 2025-08-17 to Saturday 2025-08-23; Friday and Saturday are weekends
+	b := newScrapeCalendarBuilder("2025-08-17", "2025-08-23")
+	require.NotNil(t, b)
+
+	b.mark("2025-08-17", CalendarDayDownloaded)
+	b.mark("2025-08-18", CalendarDayExisting)
+	b.mark("2025-08-19", CalendarDayHoliday)
+	b.mark("2025-08-18", CalendarDayHoliday) // a report on disk wins
+	b.mark("2025-08-16", CalendarDayDownloaded)
+	b.mark("not a date", CalendarDayDownloaded)
+
+	now := time.Date(2025, 8, 20, 9, 0, 0, 0, time.UTC)
+	cal := b.calendar(now)
+	assert.Equal(t, "2025-08-17", cal.From)
+	assert.Equal(t, "2025-08-23", cal.To)
+	assert.Equal(t, map[string]string{
+		"2025-08-17": CalendarDayDownloaded,
+		"2025-08-18": CalendarDayExisting,
+		"2025-08-19": CalendarDayHoliday,
+		"2025-08-20": CalendarDayMissing,
+	}, cal.Days, "days after today are not missing yet")
+	assert.Equal(t, map[string]int{
+		CalendarDayDownloaded: 1,
+		CalendarDayExisting:   1,
+		CalendarDayHoliday:    1,
+		CalendarDayMissing:    1,
+	}, cal.Counts)
+}
+
+func TestScrapeCalendar_InvalidRange(t *testing.T) {
+	assert.Nil(t, newScrapeCalendarBuilder("", "2025-08-23"))
+	assert.Nil(t, newScrapeCalendarBuilder("2025-08-23", "2025-08-17"))
+
+	var b *scrapeCalendarBuilder
+	b.mark("2025-08-17", CalendarDayDownloaded)
+}
+
+// scraperEventHub records the scraper events broadcast by a stage
+type scraperEventHub struct {
+	mu     sync.Mutex
+	events map[string][]map[string]interface{}
+}
+
+func (h *scraperEventHub) BroadcastUpdate(eventType, step, status string, metadata interface{}) {
+	payload, ok := metadata.(map[string]interface{})
+	if !ok || !strings.HasPrefix(eventType, "scraper:") {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events[eventType] = append(h.events[eventType], payload)
+}
+
+func TestScrapingStage_ForwardsDayEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake scraper is a shell script")
+	}
+
+	emitter := func(ev events.StepProgressEvent) string {
+		var buf bytes.Buffer
+		events.NewProgressEmitter(&buf, "scraping").Emit(ev)
+		return strings.TrimSpace(buf.String())
+	}
+	lines := []string{
+		emitter(events.StepProgressEvent{Event: events.StepEventStart, Total: 2}),
+		emitter(events.StepProgressEvent{Event: events.StepEventProgress, Current: 1, Total: 2, File: "2025 08 18 ISX Daily Report.xlsx", Status: events.ItemStatusDownloading, Date: "2025-08-18"}),
+		emitter(events.StepProgressEvent{Event: events.StepEventSkip, File: "2025 08 17", Status: events.ItemStatusHoliday, Date: "2025-08-17"}),
+		emitter(events.StepProgressEvent{Event: events.StepEventBufferZone, Date: "2025-08-14"}),
+		emitter(events.StepProgressEvent{Event: events.StepEventComplete, Current: 2, Total: 2}),
+		emitter(events.StepProgressEvent{Event: events.StepEventStop, Reason: events.StopReasonBufferZone}),
+	}
+	script := "printf '%s\\n' \"$@\" | grep '^@@'"
+	args := append([]string{"-c", script, "scraper", "--from", "2025-08-10", "--actual-from", "2025-08-17", "--actual-to", "2025-08-18"}, lines...)
+	cmd := exec.Command("sh", args...)
+
+	hub := &scraperEventHub{events: make(map[string][]map[string]interface{})}
+	stage := NewScrapingStage(stagePaths(t, t.TempDir()), slog.New(slog.NewTextHandler(io.Discard, nil)), &StageOptions{WebSocketManager: hub})
+	stepState := NewStepState(stage.ID(), stage.Name())
+
+	require.NoError(t, stage.executeWithProgress(context.Background(), cmd, "op-1", stepState, t.TempDir()))
+
+	require.Len(t, hub.events[EventTypeScraperHoliday], 1)
+	assert.Equal(t, "2025-08-17", hub.events[EventTypeScraperHoliday][0]["date"])
+	require.Len(t, hub.events[EventTypeScraperBufferZone], 1)
+	assert.Equal(t, "2025-08-14", stepState.Metadata["buffer_zone_date"])
+
+	require.Len(t, hub.events[EventTypeScraperStopped], 1, "the stop event after completion is read")
+	stopped := hub.events[EventTypeScraperStopped][0]
+	assert.Equal(t, events.StopReasonBufferZone, stopped["reason"])
+	cal, ok := stopped["calendar"].(ScrapeCalendar)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"2025-08-17": CalendarDayHoliday, "2025-08-18": CalendarDayDownloaded}, cal.Days)
+	assert.Equal(t, events.StopReasonBufferZone, stepState.Metadata["stop_reason"])
+}
+
+func TestScrapingStage_ForwardsBatchEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake scraper is a shell script")
+	}
+
+	emitter := func(current int, status string, from, to string) string {
+		var buf bytes.Buffer
+		events.NewProgressEmitter(&buf, "scraping").Emit(events.StepProgressEvent{
+			Event: events.StepEventBatch, Current: current, Total: 3, Status: status, From: from, To: to,
+		})
+		return strings.TrimSpace(buf.String())
+	}
+	lines := []string{
+		emitter(1, events.BatchStatusSkipped, "2025-03-01", "2025-03-31"),
+		emitter(2, events.BatchStatusStarted, "2025-02-01", "2025-02-28"),
+		emitter(2, events.BatchStatusDone, "2025-02-01", "2025-02-28"),
+		emitter(3, events.BatchStatusStarted, "2025-01-01", "2025-01-31"),
+	}
+	var download bytes.Buffer
+	events.NewProgressEmitter(&download, "scraping").ProgressDay(1, 60, "2025 01 05 ISX Daily Report.xlsx", events.ItemStatusDownloading, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC))
+	lines = append(lines, strings.TrimSpace(download.String()))
+	script := "printf '%s\\n' \"$@\" | grep '^@@'"
+	args := append([]string{"-c", script, "scraper", "--from", "2025-01-01", "--to", "2025-03-31"}, lines...)
+	cmd := exec.Command("sh", args...)
+
+	hub := &scraperEventHub{events: make(map[string][]map[string]interface{})}
+	stage := NewScrapingStage(stagePaths(t, t.TempDir()), slog.New(slog.NewTextHandler(io.Discard, nil)), &StageOptions{WebSocketManager: hub})
+	stepState := NewStepState(stage.ID(), stage.Name())
+
+	require.NoError(t, stage.executeWithProgress(context.Background(), cmd, "op-1", stepState, t.TempDir()))
+
+	require.Len(t, hub.events[EventTypeScraperBatch], 4)
+	assert.Equal(t, 2, stepState.Metadata["batches_done"], "skipped and finished batches count as done")
+	assert.Equal(t, 3, stepState.Metadata["batches_total"])
+	batch, ok := stepState.Metadata["batch"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "2025-01-01", batch["from"])
+	assert.Equal(t, events.BatchStatusStarted, batch["status"])
+}
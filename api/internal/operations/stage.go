@@ -50,6 +50,13 @@ type Step interface {
 	CanRun(manifest *PipelineManifest) bool
 }
 
+// OptionalStep is implemented by steps that join a full pipeline only when the
+// operation asks for them, e.g. housekeeping that deletes files. They can
+// always be run on their own with the "step" parameter.
+type OptionalStep interface {
+	IncludeInPipeline(state *OperationState) bool
+}
+
 // StepStatus represents the current status of a Step
 type StepStatus string
 
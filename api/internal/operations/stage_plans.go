@@ -0,0 +1,200 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/files"
+)
+
+// defaultScraperFromDate is the scraper's --from default, used when an
+// operation gives no from_date
+const defaultScraperFromDate = "2025-01-01"
+
+// scraperBufferDays is how far before from_date the scraper starts
+const scraperBufferDays = 7
+
+// dailyReportName is the file the scraper saves a day's report under
+func dailyReportName(day time.Time) string {
+	return fmt.Sprintf("%s ISX Daily Report.xlsx", day.Format("2006 01 02"))
+}
+
+// stateString returns a string configuration value, or ""
+func stateString(state *OperationState, key string) string {
+	if v, ok := state.GetConfig(key); ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// downloadedDates returns the dates with a daily report in dir. A missing
+// directory has none.
+func downloadedDates(dir string) (map[string]bool, error) {
+	dates, err := files.DownloadedReportDates(dir)
+	if err != nil {
+		if _, statErr := os.Stat(dir); os.IsNotExist(statErr) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+	return dates, nil
+}
+
+// PlanStep lists the trading days in the operation's range that have no
+// downloaded report. These are the files the scraper would fetch.
+func (s *ScrapingStage) PlanStep(state *OperationState, plan *OperationPlan, step *StepPlan) error {
+	paths := operationPaths(s.paths, state)
+	downloaded, err := downloadedDates(paths.DownloadsDir)
+	if err != nil {
+		return err
+	}
+	// The profile's trading calendar, the one the scraper reads; a missing
+	// file yields a weekend-only calendar
+	calendar, err := files.LoadHolidayCalendar(paths.HolidaysFile)
+	if err != nil {
+		return err
+	}
+
+	mode := stateString(state, ContextKeyMode)
+	fromDate := stateString(state, ContextKeyFromDate)
+	toDate := stateString(state, ContextKeyToDate)
+	step.Details = map[string]interface{}{"mode": mode}
+
+	// The scraper only searches the site from a few days before from_date;
+	// accumulative runs start after the latest download instead
+	from, err := time.Parse("2006-01-02", fromDate)
+	switch {
+	case fromDate == "":
+		from, _ = time.Parse("2006-01-02", defaultScraperFromDate)
+		step.Details["from_date_defaulted"] = true
+	case err != nil:
+		return NewValidationError(s.ID(), fmt.Sprintf("invalid from_date %q, expected YYYY-MM-DD", fromDate))
+	default:
+		step.Details["search_from"] = from.AddDate(0, 0, -scraperBufferDays).Format("2006-01-02")
+	}
+	if mode == "accumulative" {
+		if latest := latestDate(downloaded); !latest.IsZero() {
+			from = latest.AddDate(0, 0, 1)
+			step.Details["resume_after"] = latest.Format("2006-01-02")
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if toDate != "" {
+		if to, err = time.Parse("2006-01-02", toDate); err != nil {
+			return NewValidationError(s.ID(), fmt.Sprintf("invalid to_date %q, expected YYYY-MM-DD", toDate))
+		}
+		if to.After(today) {
+			to = today
+		}
+	}
+	step.Details["from"] = from.Format("2006-01-02")
+	step.Details["to"] = to.Format("2006-01-02")
+
+	var missing []time.Time
+	days := calendar.TradingDays(from, to)
+	for _, day := range days {
+		if downloaded[day.Format("2006-01-02")] {
+			step.ExistingFiles++
+			continue
+		}
+		missing = append(missing, day)
+		step.PendingFiles = append(step.PendingFiles, dailyReportName(day))
+	}
+	step.ExpectedFiles = len(days)
+	step.MissingRanges = files.GroupConsecutiveTradingDays(missing, calendar)
+	return nil
+}
+
+// latestDate returns the latest date in a YYYY-MM-DD set; zero if empty
+func latestDate(dates map[string]bool) time.Time {
+	var latest time.Time
+	for d := range dates {
+		if t, err := time.Parse("2006-01-02", d); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// PlanStep lists the downloaded reports, and those the scraping step would
+// download, that have no daily CSV yet. These are the files the processor
+// would parse; it skips days it has already written. With a reprocessing
+// range every report in the range is parsed again.
+func (p *ProcessingStage) PlanStep(state *OperationState, plan *OperationPlan, step *StepPlan) error {
+	from, to, err := reprocessRange(state)
+	if err != nil {
+		return err
+	}
+	paths := operationPaths(p.paths, state)
+	downloaded, err := downloadedDates(paths.DownloadsDir)
+	if err != nil {
+		return err
+	}
+	if scraping := plan.Step(StageIDScraping); scraping != nil && scraping.WillRun {
+		for _, name := range scraping.PendingFiles {
+			if day, err := time.Parse("2006 01 02", strings.TrimSuffix(name, " ISX Daily Report.xlsx")); err == nil {
+				downloaded[day.Format("2006-01-02")] = true
+			}
+		}
+	}
+
+	processed := processedDates(paths.DailyReportsDir)
+
+	dates := make([]string, 0, len(downloaded))
+	for d := range downloaded {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	reprocess := !from.IsZero() || !to.IsZero()
+	if reprocess && step.Details == nil {
+		step.Details = map[string]interface{}{}
+	}
+	if !from.IsZero() {
+		step.Details[ContextKeyReprocessFrom] = from.Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		step.Details[ContextKeyReprocessTo] = to.Format("2006-01-02")
+	}
+
+	for _, d := range dates {
+		day, _ := time.Parse("2006-01-02", d)
+		inRange := (from.IsZero() || !day.Before(from)) && (to.IsZero() || !day.After(to))
+		if (reprocess && !inRange) || (!reprocess && processed[d]) {
+			step.ExistingFiles++
+			continue
+		}
+		step.PendingFiles = append(step.PendingFiles, dailyReportName(day))
+	}
+	step.ExpectedFiles = len(dates)
+	return nil
+}
+
+// processedDates returns the dates (YYYY-MM-DD) with an isx_daily_YYYY_MM_DD.csv
+// anywhere under dir
+func processedDates(dir string) map[string]bool {
+	dates := make(map[string]bool)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if !strings.HasPrefix(name, "isx_daily_") || !strings.HasSuffix(name, ".csv") {
+			return nil
+		}
+		day, err := time.Parse("2006_01_02", strings.TrimSuffix(strings.TrimPrefix(name, "isx_daily_"), ".csv"))
+		if err == nil {
+			dates[day.Format("2006-01-02")] = true
+		}
+		return nil
+	})
+	return dates
+}
@@ -15,19 +15,24 @@ import (
 	"strings"
 	"time"
 
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing/analytics"
+	"isxcli/internal/files"
 	"isxcli/internal/liquidity"
+	"isxcli/internal/migrations"
+	"isxcli/pkg/contracts/events"
 )
 
 // ScrapingStage handles the scraping process
 type ScrapingStage struct {
 	BaseStage
-	executableDir string
-	logger        *slog.Logger
-	options       *StageOptions
+	paths   *config.Paths
+	logger  *slog.Logger
+	options *StageOptions
 }
 
 // NewScrapingStage creates a new scraping Step
-func NewScrapingStage(executableDir string, logger *slog.Logger, options *StageOptions) *ScrapingStage {
+func NewScrapingStage(paths *config.Paths, logger *slog.Logger, options *StageOptions) *ScrapingStage {
 	if options == nil {
 		options = &StageOptions{}
 	}
@@ -36,14 +41,14 @@ func NewScrapingStage(executableDir string, logger *slog.Logger, options *StageO
 	if logger != nil {
 		logger = logger.With(slog.String("Step", StageIDScraping))
 		logger.Info("Scraping Step initialized",
-			slog.String("executable_dir", executableDir))
+			slog.String("executable_dir", paths.ExecutableDir))
 	}
 
 	return &ScrapingStage{
-		BaseStage:     NewBaseStage(StageIDScraping, StageNameScraping, nil),
-		executableDir: executableDir,
-		logger:        logger,
-		options:       options,
+		BaseStage: NewBaseStage(StageIDScraping, StageNameScraping, nil),
+		paths:     paths,
+		logger:    logger,
+		options:   options,
 	}
 }
 
@@ -70,26 +75,29 @@ func (s *ScrapingStage) Execute(ctx context.Context, state *OperationState) erro
 
 	s.updateProgress(state.ID, StepState, 2, "Starting scraper...")
 
-	scraperPath := filepath.Join(s.executableDir, "scraper.exe")
-	if _, err := os.Stat(scraperPath); err != nil {
+	paths := operationPaths(s.paths, state)
+	scraperPath, err := ResolveExecutable(paths.ExecutableDir, ScraperExecutable)
+	if err != nil {
 		if s.logger != nil {
 			s.logger.Error("Scraper executable not found",
-				slog.String("path", scraperPath),
+				slog.String("dir", paths.ExecutableDir),
 				slog.String("error", err.Error()))
 		}
-		return fmt.Errorf("scraper.exe not found: %w", err)
+		return fmt.Errorf("scraper executable not found: %w", err)
 	}
 
-	// Build command arguments
+	// Build command arguments; the scraper saves into the operation's profile
 	args := s.buildScraperArgs(state)
-	cmd := exec.CommandContext(ctx, scraperPath, args...)
-	cmd.Dir = s.executableDir
+	args = append(args, "--out", paths.DownloadsDir)
+	args = append(args, profileArgs(paths)...)
+	cmd := newStageCommand(ctx, scraperPath, args...)
+	cmd.Dir = paths.ExecutableDir
 
 	s.updateProgress(state.ID, StepState, 3, "Running scraper...")
 
 	// Execute with progress tracking if enabled
 	if s.options.EnableProgress && s.options.WebSocketManager != nil {
-		if err := s.executeWithProgress(ctx, cmd, state.ID, StepState); err != nil {
+		if err := s.executeWithProgress(ctx, cmd, state.ID, StepState, paths.DownloadsDir); err != nil {
 			if s.logger != nil {
 				s.logger.Error("Scraper execution failed",
 					slog.String("error", err.Error()))
@@ -218,6 +226,28 @@ func (s *ScrapingStage) buildScraperArgs(state *OperationState) []string {
 		args = append(args, "--mode", "full")
 	}
 
+	// Size of the searches a long range is split into, month or quarter
+	if batchI, exists := state.GetConfig(ContextKeyScrapeBatch); exists {
+		if batch, ok := batchI.(string); ok && (batch == "month" || batch == "quarter") {
+			args = append(args, "--batch", batch)
+		} else if s.logger != nil {
+			s.logger.Warn("Ignoring invalid batch size",
+				slog.Any("value", batchI),
+				slog.String("key", ContextKeyScrapeBatch))
+		}
+	}
+
+	// Operation-level bandwidth cap shared by all downloads of the run
+	if capI, exists := state.GetConfig(ContextKeyBandwidthKBps); exists {
+		if kbps := toInt(capI); kbps > 0 {
+			args = append(args, "--bandwidth-kbps", strconv.Itoa(kbps))
+		} else if s.logger != nil {
+			s.logger.Warn("Ignoring invalid bandwidth cap",
+				slog.Any("value", capI),
+				slog.String("key", ContextKeyBandwidthKBps))
+		}
+	}
+
 	if s.logger != nil {
 		s.logger.Info("Final scraper args",
 			slog.Any("args", args),
@@ -227,8 +257,51 @@ func (s *ScrapingStage) buildScraperArgs(state *OperationState) []string {
 	return args
 }
 
+// toInt converts an integer operation parameter, which arrives as a JSON
+// number or string. Invalid values return 0.
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return 0
+		}
+		return i
+	}
+	return 0
+}
+
+// broadcastAnomalies sends the data:anomalies event when the processor
+// flagged anomalies on the dates of this run
+func (p *ProcessingStage) broadcastAnomalies(operationID, reportsDir string, since time.Time) {
+	if p.options.WebSocketManager == nil {
+		return
+	}
+
+	summary, err := analytics.ReadAnomalySummary(filepath.Join(reportsDir, analytics.AnomaliesDirName))
+	if err != nil {
+		if !os.IsNotExist(err) && p.logger != nil {
+			p.logger.Warn("Failed to read anomaly summary",
+				slog.String("pipeline_id", operationID),
+				slog.String("error", err.Error()))
+		}
+		return
+	}
+	if summary.Total == 0 || summary.GeneratedAt.Before(since) {
+		return
+	}
+
+	p.options.WebSocketManager.BroadcastUpdate(EventTypeAnomaliesDetected, p.ID(), "completed", summary)
+}
+
 // executeWithProgress runs the command with real-time progress tracking
-func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd, operationID string, StepState *StepState) error {
+func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd, operationID string, StepState *StepState, downloadsDir string) error {
 	// Extract dates from the command args for metadata
 	var fromDate, toDate, actualFromDate, actualToDate string
 	for i, arg := range cmd.Args {
@@ -289,6 +362,8 @@ func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd,
 		skippedFiles    []string // Track skipped files (holidays)
 		expectedFiles   int      // Total trading days in range
 		seenFiles       = make(map[string]bool) // Track unique files to prevent double counting
+		batchesDone     int                     // Batches finished or skipped as finished earlier
+		calendar        = newScrapeCalendarBuilder(displayFromDate, displayToDate)
 	)
 	
 	// Calculate expected files (trading days in ACTUAL date range, not buffer)
@@ -480,6 +555,9 @@ func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd,
 		}
 	}()
 
+	// Set once the scraper emits structured progress events
+	structured := false
+
 	// Track last activity time to detect if scraper is stuck
 	lastActivityTime := time.Now()
 	activityTimeout := 30 * time.Second // Reduced timeout for safety only
@@ -501,6 +579,127 @@ func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd,
 					slog.String("line", line))
 			}
 
+			// Structured progress events take precedence; once the scraper
+			// speaks the protocol its free-text log lines are no longer parsed
+			if ev, ok := DecodeProgressEvent(line); ok {
+				structured = true
+				switch ev.Event {
+				case events.StepEventStart:
+					currentState = StateScanning
+					if expectedFiles == 0 {
+						expectedFiles = ev.Total
+					}
+					updateMetadata()
+					s.updateProgress(operationID, StepState, calculateProgress(), "Scanning for files...")
+
+				case events.StepEventStatus:
+					s.updateProgress(operationID, StepState, calculateProgress(), ev.Message)
+
+				case events.StepEventSkip:
+					if isFileInRange(ev.File) {
+						skippedFiles = append(skippedFiles, ev.File)
+						updateMetadata()
+						if ev.Status == events.ItemStatusHoliday && ev.Date != "" {
+							calendar.mark(ev.Date, CalendarDayHoliday)
+							s.broadcast(EventTypeScraperHoliday, "running", map[string]interface{}{
+								"operation_id": operationID,
+								"date":         ev.Date,
+							})
+						}
+					}
+
+				case events.StepEventBufferZone:
+					StepState.Metadata["buffer_zone_date"] = ev.Date
+					s.broadcast(EventTypeScraperBufferZone, "running", map[string]interface{}{
+						"operation_id": operationID,
+						"date":         ev.Date,
+						"message":      ev.Message,
+					})
+
+				case events.StepEventBatch:
+					// Long ranges are searched in batches; the batch counts
+					// give a combined view of a backfill next to the files
+					if ev.Status != events.BatchStatusStarted {
+						batchesDone++
+					}
+					batch := map[string]interface{}{
+						"current": ev.Current,
+						"total":   ev.Total,
+						"from":    ev.From,
+						"to":      ev.To,
+						"status":  ev.Status,
+					}
+					StepState.Metadata["batch"] = batch
+					StepState.Metadata["batches_done"] = batchesDone
+					StepState.Metadata["batches_total"] = ev.Total
+					s.broadcast(EventTypeScraperBatch, "running", map[string]interface{}{
+						"operation_id": operationID,
+						"batch":        batch,
+						"batches_done": batchesDone,
+					})
+					if ev.Status == events.BatchStatusStarted {
+						s.updateProgress(operationID, StepState, calculateProgress(),
+							fmt.Sprintf("Batch %d of %d: %s to %s (%d done)", ev.Current, ev.Total, ev.From, ev.To, batchesDone))
+					}
+
+				case events.StepEventStop:
+					StepState.Metadata["stop_reason"] = ev.Reason
+					payload := map[string]interface{}{
+						"operation_id": operationID,
+						"reason":       ev.Reason,
+						"message":      ev.Message,
+					}
+					if calendar != nil {
+						cal := calendar.calendar(time.Now())
+						StepState.Metadata["calendar"] = cal
+						payload["calendar"] = cal
+					}
+					s.broadcast(EventTypeScraperStopped, ev.Reason, payload)
+
+				case events.StepEventProgress:
+					currentState = StateDownloading
+					currentFile = ev.File
+					normalizedName := filepath.Base(strings.TrimSpace(ev.File))
+					if isFileInRange(ev.File) && !seenFiles[normalizedName] {
+						seenFiles[normalizedName] = true
+						filesProcessed++
+						downloadedFiles = append(downloadedFiles, ev.File)
+					}
+					if ev.Date != "" {
+						day := CalendarDayDownloaded
+						if ev.Status == events.ItemStatusExists {
+							day = CalendarDayExisting
+						}
+						calendar.mark(ev.Date, day)
+					}
+					updateMetadata()
+
+					message := fmt.Sprintf("Downloading: %s (%d/%d)", filepath.Base(currentFile), filesProcessed, expectedFiles)
+					if ev.Status == events.ItemStatusExists {
+						message = fmt.Sprintf("File exists, skipping (%d/%d)", filesProcessed, expectedFiles)
+					}
+					s.updateProgress(operationID, StepState, calculateProgress(), message)
+
+				case events.StepEventComplete:
+					currentState = StateCompleted
+					updateMetadata()
+					message := "✅ All required files already exist"
+					if ev.Message != "" {
+						message = "✅ " + ev.Message
+					}
+					s.updateProgress(operationID, StepState, 100, message)
+					if s.logger != nil {
+						s.logger.Info("Scraper signaled completion",
+							slog.Int("files_processed", filesProcessed))
+					}
+					// Keep reading until the scraper exits: its stop event follows
+				}
+				continue
+			}
+			if structured {
+				continue
+			}
+
 			// Try to parse as JSON first (for structured logs)
 			var logEntry map[string]interface{}
 			if err := json.Unmarshal([]byte(line), &logEntry); err == nil {
@@ -688,6 +887,13 @@ func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd,
 			// Only keep safety timeout for completely stuck processes
 			
 			// Check if scraper has been completely inactive for too long
+			if timeSinceLastActivity > activityTimeout && currentState == StateCompleted {
+				// Signalled completion but never exited
+				if err := stopProcess(cmd); err != nil {
+					s.logger.Error("Failed to kill stuck scraper process", slog.String("error", err.Error()))
+				}
+				goto waitForCompletion
+			}
 			if timeSinceLastActivity > activityTimeout {
 				if s.logger != nil {
 					s.logger.Warn("Scraper timeout - no output for 2 minutes",
@@ -698,8 +904,8 @@ func (s *ScrapingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd,
 				updateMetadata()
 				s.updateProgress(operationID, StepState, calculateProgress(), 
 					fmt.Sprintf("Stopped after processing %d files", filesProcessed))
-				// Kill the scraper process
-				if err := cmd.Process.Kill(); err != nil {
+				// Stop the scraper and the browser it started
+				if err := stopProcess(cmd); err != nil {
 					s.logger.Error("Failed to kill stuck scraper process", slog.String("error", err.Error()))
 				}
 				goto waitForCompletion
@@ -775,7 +981,6 @@ waitForCompletion:
 	// Verify files were actually processed
 	if filesProcessed == 0 && len(downloadedFiles) == 0 {
 		// Double-check downloads folder
-		downloadsDir := filepath.Join(s.executableDir, "data", "downloads")
 		pattern := filepath.Join(downloadsDir, "*.xlsx")
 		existingFiles, _ := filepath.Glob(pattern)
 		
@@ -798,6 +1003,14 @@ waitForCompletion:
 	return nil
 }
 
+// broadcast sends a scraper event when a WebSocket hub is configured
+func (s *ScrapingStage) broadcast(eventType, status string, payload map[string]interface{}) {
+	if s.options.WebSocketManager == nil {
+		return
+	}
+	s.options.WebSocketManager.BroadcastUpdate(eventType, s.ID(), status, payload)
+}
+
 // updateProgress updates progress through the centralized StatusBroadcaster
 func (s *ScrapingStage) updateProgress(operationID string, StepState *StepState, progress int, message string) {
 	StepState.UpdateProgress(float64(progress), message)
@@ -834,13 +1047,13 @@ func (s *ScrapingStage) CanRun(manifest *PipelineManifest) bool {
 // ProcessingStage handles data processing
 type ProcessingStage struct {
 	BaseStage
-	executableDir string
-	logger        *slog.Logger
-	options       *StageOptions
+	paths   *config.Paths
+	logger  *slog.Logger
+	options *StageOptions
 }
 
 // NewProcessingStage creates a new processing Step
-func NewProcessingStage(executableDir string, logger *slog.Logger, options *StageOptions) *ProcessingStage {
+func NewProcessingStage(paths *config.Paths, logger *slog.Logger, options *StageOptions) *ProcessingStage {
 	if options == nil {
 		options = &StageOptions{}
 	}
@@ -849,13 +1062,13 @@ func NewProcessingStage(executableDir string, logger *slog.Logger, options *Stag
 	if logger != nil {
 		logger = logger.With(slog.String("Step", StageIDProcessing))
 		logger.Info("Processing Step initialized",
-			slog.String("executable_dir", executableDir))
+			slog.String("executable_dir", paths.ExecutableDir))
 	}
 	return &ProcessingStage{
-		BaseStage:     NewBaseStage(StageIDProcessing, StageNameProcessing, []string{StageIDScraping}), // Depends on scraping
-		executableDir: executableDir,
-		logger:        logger,
-		options:       options,
+		BaseStage: NewBaseStage(StageIDProcessing, StageNameProcessing, []string{StageIDScraping}), // Depends on scraping
+		paths:     paths,
+		logger:    logger,
+		options:   options,
 	}
 }
 
@@ -876,23 +1089,37 @@ func (p *ProcessingStage) Execute(ctx context.Context, state *OperationState) er
 
 	p.updateProgress(state.ID, StepState, 10, "Starting processor...")
 
-	processorPath := filepath.Join(p.executableDir, "processor.exe")
-	if _, err := os.Stat(processorPath); err != nil {
+	paths := operationPaths(p.paths, state)
+	processorPath, err := ResolveExecutable(paths.ExecutableDir, ProcessorExecutable)
+	if err != nil {
 		if p.logger != nil {
 			p.logger.Error("Processor executable not found",
-				slog.String("path", processorPath),
+				slog.String("dir", paths.ExecutableDir),
 				slog.String("error", err.Error()))
 		}
-		return fmt.Errorf("processor.exe not found: %w", err)
+		return fmt.Errorf("processor executable not found: %w", err)
 	}
 
-	// Set up input and output directories relative to executable
-	inputDir := filepath.Join(p.executableDir, "data", "downloads")
-	outputDir := filepath.Join(p.executableDir, "data", "reports")  // Fixed: Use reports directory for consistency
+	// Read and write the directories of the operation's profile
+	inputDir := paths.DownloadsDir
+	outputDir := paths.ReportsDir
+
+	// The processor takes the reports directory lock itself; check first so a
+	// manual run in progress fails the stage with a clear message
+	if err := files.CheckDirLock(outputDir); err != nil {
+		return fmt.Errorf("reports directory busy: %w", err)
+	}
 	
 	// Create processor command with proper arguments
-	cmd := exec.CommandContext(ctx, processorPath, "--in", inputDir, "--out", outputDir)
-	cmd.Dir = p.executableDir
+	args := []string{"--in", inputDir, "--out", outputDir}
+	args = append(args, profileArgs(paths)...)
+	rangeArgs, err := reprocessArgs(state)
+	if err != nil {
+		return err
+	}
+	args = append(args, rangeArgs...)
+	cmd := newStageCommand(ctx, processorPath, args...)
+	cmd.Dir = paths.ExecutableDir
 	
 	if p.logger != nil {
 		p.logger.Info("Running processor with directories",
@@ -901,6 +1128,7 @@ func (p *ProcessingStage) Execute(ctx context.Context, state *OperationState) er
 	}
 
 	p.updateProgress(state.ID, StepState, 50, "Processing data...")
+	started := time.Now()
 
 	if p.options.EnableProgress && p.options.WebSocketManager != nil {
 		if err := p.executeWithProgress(ctx, cmd, state.ID, StepState, state); err != nil {
@@ -927,10 +1155,88 @@ func (p *ProcessingStage) Execute(ctx context.Context, state *OperationState) er
 		}
 	}
 
+	p.broadcastDataDelta(state.ID, outputDir, started)
+	p.broadcastAnomalies(state.ID, outputDir, started)
 	p.updateProgress(state.ID, StepState, 100, "Processing completed")
 	return nil
 }
 
+// Validate rejects a malformed reprocessing range before the processor starts
+func (p *ProcessingStage) Validate(state *OperationState) error {
+	_, err := reprocessArgs(state)
+	return err
+}
+
+// reprocessRange reads the reprocess_from and reprocess_to parameters; a
+// bound that is not set is zero
+func reprocessRange(state *OperationState) (from, to time.Time, err error) {
+	parse := func(key string) (time.Time, error) {
+		v, ok := state.GetConfig(key)
+		if !ok || v == nil {
+			return time.Time{}, nil
+		}
+		s := strings.TrimSpace(fmt.Sprint(v))
+		if s == "" {
+			return time.Time{}, nil
+		}
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s parameter %q: want YYYY-MM-DD", key, s)
+		}
+		return d, nil
+	}
+	if from, err = parse(ContextKeyReprocessFrom); err != nil {
+		return
+	}
+	if to, err = parse(ContextKeyReprocessTo); err != nil {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		err = fmt.Errorf("invalid %s parameter: before %s", ContextKeyReprocessTo, ContextKeyReprocessFrom)
+	}
+	return
+}
+
+// reprocessArgs returns the processor --from/--to flags of the reprocessing
+// range; none when it is not set
+func reprocessArgs(state *OperationState) ([]string, error) {
+	from, to, err := reprocessRange(state)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	if !from.IsZero() {
+		args = append(args, "--from", from.Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		args = append(args, "--to", to.Format("2006-01-02"))
+	}
+	return args, nil
+}
+
+// broadcastDataDelta sends the data:updated event with the changes recorded
+// by the processor during this run, so clients refresh only what changed
+func (p *ProcessingStage) broadcastDataDelta(operationID, reportsDir string, since time.Time) {
+	if p.options.WebSocketManager == nil {
+		return
+	}
+
+	changes, err := files.ReadDataChanges(filepath.Join(reportsDir, files.DeltaDirName), since)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("Failed to read data delta",
+				slog.String("pipeline_id", operationID),
+				slog.String("error", err.Error()))
+		}
+		return
+	}
+	if changes.Runs == 0 {
+		return
+	}
+
+	p.options.WebSocketManager.BroadcastUpdate(EventTypeDataUpdated, p.ID(), "completed", changes)
+}
+
 // executeWithProgress runs the command with real-time progress tracking
 func (p *ProcessingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd, operationID string, StepState *StepState, state *OperationState) error {
 	// Create pipes for stdout and stderr
@@ -952,6 +1258,7 @@ func (p *ProcessingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd
 	var processedFiles, totalFiles int
 	var fileList []string
 	var currentFileName string
+	var decoder stepProgressDecoder
 	progressChan := make(chan string, 100)
 	errChan := make(chan error, 2)
 
@@ -987,65 +1294,44 @@ func (p *ProcessingStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd
 				slog.String("line", line))
 		}
 
-		// Parse different types of messages
-		switch {
-		case strings.Contains(line, "Files to process:"):
-			// Parse file list: "Files to process: file1.xlsx|file2.xlsx|file3.xlsx"
-			if idx := strings.Index(line, "Files to process:"); idx >= 0 {
-				filesStr := strings.TrimSpace(line[idx+17:])
-				if filesStr != "" {
-					fileList = strings.Split(filesStr, "|")
-					StepState.Metadata["file_list"] = fileList
-					StepState.Metadata["total_files"] = len(fileList)
-				}
+		ev, ok := decoder.decode(line)
+		if !ok {
+			continue
+		}
+
+		switch ev.Event {
+		case events.StepEventStart:
+			if len(ev.Files) > 0 {
+				fileList = ev.Files
+				StepState.Metadata["file_list"] = fileList
 			}
-			
-		case strings.Contains(line, "Processing file") && strings.Contains(line, "of"):
-			// Parse: "Processing file X of Y: filename"
-			var current, total int
-			var fileName string
-			if n, _ := fmt.Sscanf(line, "Processing file %d of %d: %s", &current, &total, &fileName); n >= 2 {
-				processedFiles = current
-				if total > 0 {
-					totalFiles = total
-				}
-				currentFileName = fileName
-				
-				// Calculate actual progress based on files processed
-				progress := 0
-				if totalFiles > 0 {
-					progress = int(float64(processedFiles) * 100 / float64(totalFiles))
-				}
-				
-				message := fmt.Sprintf("Processing file %d of %d: %s", processedFiles, totalFiles, fileName)
-				p.updateProgress(operationID, StepState, progress, message)
-				
-				// Update metadata
-				StepState.Metadata["files_processed"] = processedFiles
+			if ev.Total > 0 {
+				totalFiles = ev.Total
 				StepState.Metadata["total_files"] = totalFiles
-				StepState.Metadata["current_file"] = currentFileName
-				if len(fileList) == 0 && totalFiles > 0 {
-					// If we don't have the file list yet, create a placeholder
-					StepState.Metadata["file_list"] = fileList
-				}
+				p.updateProgress(operationID, StepState, 15, fmt.Sprintf("Found %d Excel files to process", totalFiles))
 			}
 
-		case strings.Contains(line, "Found") && strings.Contains(line, "Excel files"):
-			// Total files detected
-			if _, err := fmt.Sscanf(line, "Found %d Excel files", &totalFiles); err == nil {
-				p.updateProgress(operationID, StepState, 15, fmt.Sprintf("Found %d Excel files to process", totalFiles))
-				StepState.Metadata["total_files"] = totalFiles
+		case events.StepEventProgress:
+			processedFiles = ev.Current
+			if ev.Total > 0 {
+				totalFiles = ev.Total
 			}
+			currentFileName = ev.File
 
-		case strings.Contains(line, "Converted") && strings.Contains(line, "to CSV"):
-			// File conversion complete
-			if p.logger != nil {
-				p.logger.Info("File conversion",
-					slog.String("message", line))
+			// Calculate actual progress based on files processed
+			progress := 0
+			if totalFiles > 0 {
+				progress = int(float64(processedFiles) * 100 / float64(totalFiles))
 			}
 
-		case strings.Contains(line, "Processing complete"):
-			// Processing complete - don't need artificial "finalizing" stage
+			message := fmt.Sprintf("Processing file %d of %d: %s", processedFiles, totalFiles, currentFileName)
+			p.updateProgress(operationID, StepState, progress, message)
+
+			StepState.Metadata["files_processed"] = processedFiles
+			StepState.Metadata["total_files"] = totalFiles
+			StepState.Metadata["current_file"] = currentFileName
+
+		case events.StepEventComplete:
 			// The actual file processing is already at 100%
 			StepState.Metadata["csv_files_created"] = processedFiles
 		}
@@ -1117,11 +1403,13 @@ func (p *ProcessingStage) CanRun(manifest *PipelineManifest) bool {
 		return false
 	}
 
+	paths := manifestPaths(p.paths, manifest)
+
 	// Log entry per CLAUDE.md
 	if p.logger != nil {
 		p.logger.Debug("ProcessingStage.CanRun starting",
 			slog.String("stage", "processing"),
-			slog.String("executable_dir", p.executableDir))
+			slog.String("executable_dir", paths.ExecutableDir))
 	}
 
 	// Primary check: Look in manifest first (faster)
@@ -1141,7 +1429,7 @@ func (p *ProcessingStage) CanRun(manifest *PipelineManifest) bool {
 	}
 
 	// Fallback: Check filesystem using centralized FileDetector (SSOT)
-	downloadsDir := filepath.Join(p.executableDir, "data", "downloads")
+	downloadsDir := paths.DownloadsDir
 	
 	if p.logger != nil {
 		p.logger.Info("Manifest check negative, checking filesystem",
@@ -1188,13 +1476,13 @@ func (p *ProcessingStage) CanRun(manifest *PipelineManifest) bool {
 // IndicesStage handles index extraction
 type IndicesStage struct {
 	BaseStage
-	executableDir string
-	logger        *slog.Logger
-	options       *StageOptions
+	paths   *config.Paths
+	logger  *slog.Logger
+	options *StageOptions
 }
 
 // NewIndicesStage creates a new indices extraction Step
-func NewIndicesStage(executableDir string, logger *slog.Logger, options *StageOptions) *IndicesStage {
+func NewIndicesStage(paths *config.Paths, logger *slog.Logger, options *StageOptions) *IndicesStage {
 	if options == nil {
 		options = &StageOptions{}
 	}
@@ -1203,14 +1491,14 @@ func NewIndicesStage(executableDir string, logger *slog.Logger, options *StageOp
 	if logger != nil {
 		logger = logger.With(slog.String("Step", StageIDIndices))
 		logger.Info("Indices Step initialized",
-			slog.String("executable_dir", executableDir))
+			slog.String("executable_dir", paths.ExecutableDir))
 	}
 
 	return &IndicesStage{
-		BaseStage:     NewBaseStage(StageIDIndices, StageNameIndices, []string{StageIDProcessing}), // Depends on processing
-		executableDir: executableDir,
-		logger:        logger,
-		options:       options,
+		BaseStage: NewBaseStage(StageIDIndices, StageNameIndices, []string{StageIDProcessing}), // Depends on processing
+		paths:     paths,
+		logger:    logger,
+		options:   options,
 	}
 }
 
@@ -1226,18 +1514,25 @@ func (i *IndicesStage) Execute(ctx context.Context, state *OperationState) error
 
 	i.updateProgress(state.ID, StepState, 10, "Starting index extractor...")
 
-	indexPath := filepath.Join(i.executableDir, "indexcsv.exe")
-	if _, err := os.Stat(indexPath); err != nil {
+	paths := operationPaths(i.paths, state)
+	indexPath, err := ResolveExecutable(paths.ExecutableDir, IndexCSVExecutable)
+	if err != nil {
 		if i.logger != nil {
 			i.logger.Error("Index extractor executable not found",
-				slog.String("path", indexPath),
+				slog.String("dir", paths.ExecutableDir),
 				slog.String("error", err.Error()))
 		}
-		return fmt.Errorf("indexcsv.exe not found: %w", err)
+		return fmt.Errorf("indexcsv executable not found: %w", err)
+	}
+
+	if err := files.CheckDirLock(paths.ReportsDir); err != nil {
+		return fmt.Errorf("reports directory busy: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, indexPath)
-	cmd.Dir = i.executableDir
+	// The extractor writes its outputs under the same profile
+	args := append([]string{"--dir", paths.DownloadsDir}, profileArgs(paths)...)
+	cmd := newStageCommand(ctx, indexPath, args...)
+	cmd.Dir = paths.ExecutableDir
 
 	i.updateProgress(state.ID, StepState, 50, "Extracting indices...")
 
@@ -1286,6 +1581,7 @@ func (i *IndicesStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd, o
 	var processedFiles int
 	var totalFiles int
 	var fileList []string
+	var decoder stepProgressDecoder
 	progressChan := make(chan string, 100)
 	errChan := make(chan error, 2)
 
@@ -1321,53 +1617,42 @@ func (i *IndicesStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd, o
 				slog.String("line", line))
 		}
 
-		// Parse different types of messages
-		switch {
-		case strings.Contains(line, "Files to process:"):
-			// Parse file list
-			if idx := strings.Index(line, "Files to process:"); idx >= 0 {
-				filesStr := strings.TrimSpace(line[idx+17:])
-				if filesStr != "" {
-					fileList = strings.Split(filesStr, "|")
-					StepState.Metadata["file_list"] = fileList
-					StepState.Metadata["total_files"] = len(fileList)
-				}
+		ev, ok := decoder.decode(line)
+		if !ok {
+			continue
+		}
+
+		switch ev.Event {
+		case events.StepEventStart:
+			if len(ev.Files) > 0 {
+				fileList = ev.Files
+				StepState.Metadata["file_list"] = fileList
 			}
-			
-		case strings.Contains(line, "Found") && strings.Contains(line, "Excel files"):
-			// Parse total files
-			fmt.Sscanf(line, "Found %d Excel files", &totalFiles)
-			i.updateProgress(operationID, StepState, 0, fmt.Sprintf("Found %d files to process", totalFiles))
-			StepState.Metadata["total_files"] = totalFiles
-			
-		case strings.Contains(line, "Processing file") && strings.Contains(line, "of"):
-			// Parse: "Processing file X of Y: filename"
-			var current, total int
-			var filename string
-			if n, _ := fmt.Sscanf(line, "Processing file %d of %d: %s", &current, &total, &filename); n >= 2 {
-				processedFiles = current
-				if total > 0 {
-					totalFiles = total
-				}
-				
-				// Calculate actual progress
-				progress := 0
-				if totalFiles > 0 {
-					progress = int(float64(processedFiles) * 100 / float64(totalFiles))
-				}
-				
-				message := fmt.Sprintf("Extracting indices from file %d of %d", processedFiles, totalFiles)
-				i.updateProgress(operationID, StepState, progress, message)
-				StepState.Metadata["files_processed"] = processedFiles
+			if ev.Total > 0 {
+				totalFiles = ev.Total
 				StepState.Metadata["total_files"] = totalFiles
-				StepState.Metadata["current_file"] = filename
-				if len(fileList) == 0 && totalFiles > 0 {
-					StepState.Metadata["file_list"] = fileList
-				}
+				i.updateProgress(operationID, StepState, 0, fmt.Sprintf("Found %d files to process", totalFiles))
+			}
+
+		case events.StepEventProgress:
+			processedFiles = ev.Current
+			if ev.Total > 0 {
+				totalFiles = ev.Total
+			}
+
+			// Calculate actual progress
+			progress := 0
+			if totalFiles > 0 {
+				progress = int(float64(processedFiles) * 100 / float64(totalFiles))
 			}
 
-		case strings.Contains(line, "Index extraction complete"):
-			// All done - parsed from indexcsv output
+			message := fmt.Sprintf("Extracting indices from file %d of %d", processedFiles, totalFiles)
+			i.updateProgress(operationID, StepState, progress, message)
+			StepState.Metadata["files_processed"] = processedFiles
+			StepState.Metadata["total_files"] = totalFiles
+			StepState.Metadata["current_file"] = ev.File
+
+		case events.StepEventComplete:
 			StepState.Metadata["indices_extracted"] = []string{"ISX60", "ISX15"}
 		}
 	}
@@ -1383,7 +1668,7 @@ func (i *IndicesStage) executeWithProgress(ctx context.Context, cmd *exec.Cmd, o
 	}
 
 	// Verify index file was created - single source of truth
-	indexesDir := filepath.Join(i.executableDir, "data", "reports", "indexes")
+	indexesDir := operationPaths(i.paths, state).IndexesReportsDir
 	if err := os.MkdirAll(indexesDir, 0755); err != nil {
 		return fmt.Errorf("create indexes directory: %w", err)
 	}
@@ -1445,7 +1730,7 @@ func (i *IndicesStage) CanRun(manifest *PipelineManifest) bool {
 		return data.FileCount >= 1
 	}
 	// Also check the actual downloads directory for Excel files
-	downloadsDir := filepath.Join(i.executableDir, "data", "downloads")
+	downloadsDir := manifestPaths(i.paths, manifest).DownloadsDir
 	files, _ := filepath.Glob(filepath.Join(downloadsDir, "*.xlsx"))
 	return len(files) > 0
 }
@@ -1453,13 +1738,13 @@ func (i *IndicesStage) CanRun(manifest *PipelineManifest) bool {
 // LiquidityStage handles liquidity calculation
 type LiquidityStage struct {
 	BaseStage
-	executableDir string
-	logger        *slog.Logger
-	options       *StageOptions
+	paths   *config.Paths
+	logger  *slog.Logger
+	options *StageOptions
 }
 
 // NewLiquidityStage creates a new liquidity calculation step
-func NewLiquidityStage(executableDir string, logger *slog.Logger, options *StageOptions) *LiquidityStage {
+func NewLiquidityStage(paths *config.Paths, logger *slog.Logger, options *StageOptions) *LiquidityStage {
 	if options == nil {
 		options = &StageOptions{}
 	}
@@ -1468,14 +1753,65 @@ func NewLiquidityStage(executableDir string, logger *slog.Logger, options *Stage
 	if logger != nil {
 		logger = logger.With(slog.String("Step", StageIDLiquidity))
 		logger.Info("Liquidity calculation step initialized",
-			slog.String("executable_dir", executableDir))
+			slog.String("executable_dir", paths.ExecutableDir))
 	}
 	return &LiquidityStage{
-		BaseStage:     NewBaseStage(StageIDLiquidity, StageNameLiquidity, []string{StageIDProcessing}), // Depends on processing (for CSV files)
-		executableDir: executableDir,
-		logger:        logger,
-		options:       options,
+		BaseStage: NewBaseStage(StageIDLiquidity, StageNameLiquidity, []string{StageIDProcessing}), // Depends on processing (for CSV files)
+		paths:     paths,
+		logger:    logger,
+		options:   options,
+	}
+}
+
+// liquidityWindows reads the windows operation parameter, given as "20,60,120"
+// or a list of windows. Only the 60-day window is calculated when it is unset.
+func liquidityWindows(state *OperationState) ([]liquidity.Window, error) {
+	v, ok := state.GetConfig(ContextKeyLiquidityWindows)
+	if !ok || v == nil {
+		return []liquidity.Window{liquidity.Window60}, nil
+	}
+
+	var set string
+	switch w := v.(type) {
+	case string:
+		set = w
+	case []string:
+		set = strings.Join(w, ",")
+	case []interface{}:
+		parts := make([]string, len(w))
+		for i, part := range w {
+			parts[i] = fmt.Sprint(part)
+		}
+		set = strings.Join(parts, ",")
+	default:
+		set = fmt.Sprint(w)
+	}
+	if strings.TrimSpace(set) == "" {
+		return []liquidity.Window{liquidity.Window60}, nil
+	}
+
+	windows, err := liquidity.ParseWindows(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", ContextKeyLiquidityWindows, err)
+	}
+	return windows, nil
+}
+
+// primaryLiquidityWindow is the window of the scores file and insights: 60
+// days when requested, otherwise the shortest window
+func primaryLiquidityWindow(windows []liquidity.Window) liquidity.Window {
+	for _, w := range windows {
+		if w == liquidity.Window60 {
+			return w
+		}
 	}
+	return windows[0]
+}
+
+// Validate rejects an unsupported window set before anything is calculated
+func (l *LiquidityStage) Validate(state *OperationState) error {
+	_, err := liquidityWindows(state)
+	return err
 }
 
 // Execute runs the liquidity calculation
@@ -1497,8 +1833,19 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 	default:
 	}
 
-	// 1. Initialize liquidity calculator with 60-day window (default)
-	window := liquidity.Window60
+	paths := operationPaths(l.paths, state)
+	dirLock, err := lockReportsDir(paths.ReportsDir, "liquidity stage", l.logger)
+	if err != nil {
+		return err
+	}
+	defer dirLock.Release()
+
+	// 1. Initialize liquidity calculator with the requested windows (60-day by default)
+	windows, err := liquidityWindows(state)
+	if err != nil {
+		return err
+	}
+	window := primaryLiquidityWindow(windows)
 	// Use penalty parameters from ISX Hybrid Liquidity Metric paper
 	// β=0.75 for mild penalty, γ=1.5 for steep penalty, p*=0.5 transition point
 	penaltyParams := liquidity.PenaltyParams{
@@ -1519,17 +1866,16 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 	}
 	weights.Normalize() // Ensure weights sum to 1
 
-	calculator := liquidity.NewCalculator(window, penaltyParams, weights, l.logger)
-
 	if l.logger != nil {
 		l.logger.InfoContext(ctx, "Liquidity calculator initialized",
-			slog.String("window", window.String()))
+			slog.String("window", window.String()),
+			slog.Int("windows", len(windows)))
 	}
 
 	l.updateProgress(state.ID, StepState, 20, "Loading trading data...")
 
 	// 2. Load trading data from CSV files in data/reports/
-	tradingData, err := l.loadTradingDataFromCSV(ctx)
+	tradingData, err := l.loadTradingDataFromCSV(ctx, paths.ReportsDir)
 	if err != nil {
 		if l.logger != nil {
 			l.logger.ErrorContext(ctx, "Failed to load trading data",
@@ -1543,8 +1889,6 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 			slog.Int("data_points", len(tradingData)))
 	}
 
-	l.updateProgress(state.ID, StepState, 50, "Calculating liquidity metrics...")
-
 	// Check for context cancellation before calculation
 	select {
 	case <-ctx.Done():
@@ -1552,28 +1896,43 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 	default:
 	}
 
-	// 3. Calculate liquidity metrics
-	metrics, err := calculator.Calculate(ctx, tradingData)
-	if err != nil {
-		if l.logger != nil {
-			l.logger.ErrorContext(ctx, "Liquidity calculation failed",
-				slog.String("error", err.Error()))
+	// 3. Calculate liquidity metrics, every window over the same loaded data.
+	// Each window resumes from the state of the previous run, so only new
+	// dates and revised tickers are calculated unless a full run is requested.
+	fullRecalc := liquidityFullRecalc(state)
+	reportsDir := paths.ReportsDir
+	results := make(map[liquidity.Window][]liquidity.TickerMetrics, len(windows))
+	recalculation := make(map[string]liquidity.IncrementalStats, len(windows))
+	for _, w := range windows {
+		calculator := liquidity.NewCalculator(w, penaltyParams, weights, l.logger)
+		calculator.SetProgressFunc(func(p liquidity.Progress) {
+			l.reportPhase(state.ID, StepState, p)
+		})
+		windowMetrics, stats, err := l.calculateIncremental(ctx, calculator, tradingData, liquidity.StatePath(reportsDir, w), fullRecalc)
+		if err != nil {
+			if l.logger != nil {
+				l.logger.ErrorContext(ctx, "Liquidity calculation failed",
+					slog.String("window", w.String()),
+					slog.String("error", err.Error()))
+			}
+			return fmt.Errorf("liquidity calculation failed: window %s: %w", w, err)
 		}
-		return fmt.Errorf("liquidity calculation failed: %w", err)
+		results[w] = windowMetrics
+		recalculation[w.String()] = stats
 	}
+	metrics := results[window]
+	StepState.Metadata["recalculation"] = recalculation
 
 	if l.logger != nil {
 		l.logger.InfoContext(ctx, "Liquidity metrics calculated",
 			slog.Int("metric_count", len(metrics)))
 	}
 
-	l.updateProgress(state.ID, StepState, 80, "Scaling and ranking results...")
-
 	// 4. Save results to CSV file
 	currentDate := time.Now()
 	
 	// Create liquidity_reports subdirectory if it doesn't exist
-	liquidityReportsDir := filepath.Join(l.executableDir, "data", "reports", "liquidity_reports")
+	liquidityReportsDir := filepath.Join(paths.ReportsDir, "liquidity_reports")
 	if err := os.MkdirAll(liquidityReportsDir, 0755); err != nil {
 		if l.logger != nil {
 			l.logger.ErrorContext(ctx, "Failed to create liquidity reports directory",
@@ -1586,7 +1945,21 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 	outputFilename := fmt.Sprintf("liquidity_scores_%s.csv", currentDate.Format("2006-01-02"))
 	outputPath := filepath.Join(liquidityReportsDir, outputFilename)
 
-	l.updateProgress(state.ID, StepState, 90, "Saving results...")
+	l.reportPhase(state.ID, StepState, liquidity.Progress{Phase: liquidity.PhasePersistence, Window: window, Current: 0, Total: 2, Unit: "files"})
+
+	// Wide report with per-window and blended scores when several windows ran
+	if len(windows) > 1 {
+		windowsFilename := fmt.Sprintf("liquidity_windows_%s.csv", currentDate.Format("2006-01-02"))
+		rows := liquidity.BlendWindows(results, windows)
+		if err := liquidity.SaveMultiWindowCSV(rows, windows, filepath.Join(liquidityReportsDir, windowsFilename)); err != nil {
+			if l.logger != nil {
+				l.logger.ErrorContext(ctx, "Failed to save multi-window liquidity results",
+					slog.String("error", err.Error()))
+			}
+			return fmt.Errorf("save multi-window liquidity results: %w", err)
+		}
+		StepState.Metadata["windows_file"] = windowsFilename
+	}
 
 	// Save liquidity metrics to CSV
 	if err := liquidity.SaveToCSV(metrics, outputPath); err != nil {
@@ -1598,8 +1971,19 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 		return fmt.Errorf("save liquidity results: %w", err)
 	}
 
+	// Excel version of the scores alongside the CSV
+	xlsxFilename := strings.TrimSuffix(outputFilename, ".csv") + ".xlsx"
+	if err := liquidity.SaveToXLSX(metrics, filepath.Join(liquidityReportsDir, xlsxFilename)); err != nil {
+		if l.logger != nil {
+			l.logger.WarnContext(ctx, "Failed to save XLSX liquidity report",
+				slog.String("error", err.Error()))
+		}
+	} else {
+		StepState.Metadata["xlsx_file"] = xlsxFilename
+	}
+
 	// 5. Generate insights from liquidity scores
-	l.updateProgress(state.ID, StepState, 95, "Generating trading insights...")
+	l.reportPhase(state.ID, StepState, liquidity.Progress{Phase: liquidity.PhasePersistence, Window: window, Current: 1, Total: 2, Unit: "files"})
 	
 	if err := liquidity.GenerateInsights(outputPath, liquidityReportsDir); err != nil {
 		if l.logger != nil {
@@ -1618,6 +2002,11 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 	StepState.Metadata["output_path"] = outputPath
 	StepState.Metadata["metrics_calculated"] = len(metrics)
 	StepState.Metadata["calculation_window"] = window.String()
+	windowNames := make([]string, len(windows))
+	for i, w := range windows {
+		windowNames[i] = w.String()
+	}
+	StepState.Metadata["calculation_windows"] = windowNames
 
 	if l.logger != nil {
 		l.logger.InfoContext(ctx, "Liquidity calculation completed successfully",
@@ -1629,6 +2018,108 @@ func (l *LiquidityStage) Execute(ctx context.Context, state *OperationState) err
 	return nil
 }
 
+// liquidityFullRecalc reads the full_recalc operation parameter, which
+// ignores the saved state and calculates every ticker from scratch
+func liquidityFullRecalc(state *OperationState) bool {
+	v, ok := state.GetConfig(ContextKeyLiquidityFullRecalc)
+	if !ok {
+		return false
+	}
+	switch f := v.(type) {
+	case bool:
+		return f
+	case string:
+		full, _ := strconv.ParseBool(f)
+		return full
+	}
+	return false
+}
+
+// calculateIncremental calculates one window from the state saved at
+// statePath by the previous run and saves the state for the next one. An
+// unreadable state only costs a full calculation.
+func (l *LiquidityStage) calculateIncremental(ctx context.Context, calculator *liquidity.Calculator, data []liquidity.TradingDay, statePath string, full bool) ([]liquidity.TickerMetrics, liquidity.IncrementalStats, error) {
+	var prior *liquidity.State
+	if !full {
+		var err error
+		if prior, err = liquidity.LoadState(statePath); err != nil && l.logger != nil {
+			l.logger.WarnContext(ctx, "Ignoring unreadable liquidity state",
+				slog.String("path", statePath),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	metrics, next, stats, err := calculator.CalculateIncremental(ctx, data, prior)
+	if err != nil {
+		return nil, stats, err
+	}
+	if full {
+		stats.Reason = "full recalculation requested"
+	}
+
+	if err := liquidity.SaveState(statePath, next); err != nil && l.logger != nil {
+		l.logger.WarnContext(ctx, "Failed to save liquidity state, the next run calculates in full",
+			slog.String("path", statePath),
+			slog.String("error", err.Error()))
+	}
+	if l.logger != nil {
+		l.logger.InfoContext(ctx, "Liquidity window calculated",
+			slog.Bool("full", stats.Full),
+			slog.String("reason", stats.Reason),
+			slog.Int("tickers_reused", stats.Reused),
+			slog.Int("tickers_extended", stats.Extended),
+			slog.Int("tickers_recalculated", stats.Recalculated),
+			slog.Int("dates_rescaled", stats.DatesRescaled))
+	}
+	return metrics, stats, nil
+}
+
+// liquidityPhaseBands maps calculation phases onto the step's progress range
+var liquidityPhaseBands = map[liquidity.Phase][2]int{
+	liquidity.PhaseLoading:     {20, 30},
+	liquidity.PhaseComponents:  {30, 75},
+	liquidity.PhaseScaling:     {75, 85},
+	liquidity.PhasePersistence: {85, 99},
+}
+
+// liquidityPhaseLabels are the user-facing names of the calculation phases
+var liquidityPhaseLabels = map[liquidity.Phase]string{
+	liquidity.PhaseLoading:     "Validating trading data",
+	liquidity.PhaseComponents:  "Calculating liquidity components",
+	liquidity.PhaseScaling:     "Scaling and ranking",
+	liquidity.PhasePersistence: "Saving results",
+}
+
+// reportPhase publishes a calculation phase event with ticker counts and ETA,
+// so long calculations keep moving in the UI
+func (l *LiquidityStage) reportPhase(operationID string, StepState *StepState, p liquidity.Progress) {
+	band, ok := liquidityPhaseBands[p.Phase]
+	if !ok {
+		return
+	}
+	progress := band[0] + int(p.Fraction()*float64(band[1]-band[0]))
+
+	message := fmt.Sprintf("%s (%s): %d/%d %s", liquidityPhaseLabels[p.Phase], p.Window.String(), p.Current, p.Total, p.Unit)
+	if p.ETA > 0 {
+		message += fmt.Sprintf(", ETA %s", p.ETA.Round(time.Second))
+	}
+
+	StepState.Metadata["phase"] = string(p.Phase)
+	StepState.Metadata["phase_current"] = p.Current
+	StepState.Metadata["phase_total"] = p.Total
+	StepState.Metadata["phase_unit"] = p.Unit
+	StepState.Metadata["eta_seconds"] = int(p.ETA.Seconds())
+	StepState.Metadata["elapsed_seconds"] = int(p.Elapsed.Seconds())
+	if p.Symbol != "" {
+		StepState.Metadata["current_symbol"] = p.Symbol
+	}
+
+	StepState.UpdateProgress(float64(progress), message)
+	if l.options.StatusBroadcaster != nil {
+		l.options.StatusBroadcaster.UpdateStepWithMetadata(operationID, l.ID(), progress, message, StepState.Metadata)
+	}
+}
+
 // updateProgress updates progress through the centralized StatusBroadcaster
 func (l *LiquidityStage) updateProgress(operationID string, StepState *StepState, progress int, message string) {
 	StepState.UpdateProgress(float64(progress), message)
@@ -1673,7 +2164,8 @@ func (l *LiquidityStage) CanRun(manifest *PipelineManifest) bool {
 	}
 
 	// Fallback: Check the ticker subdirectory for trading history CSV files
-	tickersDir := filepath.Join(l.executableDir, "data", "reports", "ticker")
+	reportsDir := manifestPaths(l.paths, manifest).ReportsDir
+	tickersDir := filepath.Join(reportsDir, "ticker")
 	files, err := filepath.Glob(filepath.Join(tickersDir, "*_trading_history.csv"))
 	if err == nil && len(files) > 0 {
 		if l.logger != nil {
@@ -1685,7 +2177,6 @@ func (l *LiquidityStage) CanRun(manifest *PipelineManifest) bool {
 
 	// Also check for any CSV files as fallback in old location
 	if len(files) == 0 {
-		reportsDir := filepath.Join(l.executableDir, "data", "reports")
 		files, err = filepath.Glob(filepath.Join(reportsDir, "*_trading_history.csv"))
 		if len(files) == 0 {
 			files, err = filepath.Glob(filepath.Join(reportsDir, "*.csv"))
@@ -1704,9 +2195,9 @@ func (l *LiquidityStage) CanRun(manifest *PipelineManifest) bool {
 }
 
 // loadTradingDataFromCSV loads trading data from ticker-specific CSV files and calculates metrics per ticker
-func (l *LiquidityStage) loadTradingDataFromCSV(ctx context.Context) ([]liquidity.TradingDay, error) {
+func (l *LiquidityStage) loadTradingDataFromCSV(ctx context.Context, reportsDir string) ([]liquidity.TradingDay, error) {
 	// Look for ticker files in the ticker subdirectory first
-	tickersDir := filepath.Join(l.executableDir, "data", "reports", "ticker")
+	tickersDir := filepath.Join(reportsDir, "ticker")
 	
 	if l.logger != nil {
 		l.logger.InfoContext(ctx, "Loading trading data from ticker-specific CSV files",
@@ -1721,7 +2212,6 @@ func (l *LiquidityStage) loadTradingDataFromCSV(ctx context.Context) ([]liquidit
 
 	if len(tickerFiles) == 0 {
 		// Fallback: check old location
-		reportsDir := filepath.Join(l.executableDir, "data", "reports")
 		tickerFiles, err = filepath.Glob(filepath.Join(reportsDir, "*_trading_history.csv"))
 		if err != nil {
 			return nil, fmt.Errorf("find ticker CSV files: %w", err)
@@ -2029,13 +2519,264 @@ func (l *LiquidityStage) parseFloat(str string) (float64, error) {
 	return strconv.ParseFloat(str, 64)
 }
 
+// RetentionStage applies the archive and retention policy to downloads and
+// daily reports. It is an optional step: a full pipeline runs it last, and only
+// when the operation sets a retention parameter.
+type RetentionStage struct {
+	BaseStage
+	paths   *config.Paths
+	logger  *slog.Logger
+	options *StageOptions
+}
+
+// NewRetentionStage creates a new retention step
+func NewRetentionStage(paths *config.Paths, logger *slog.Logger, options *StageOptions) *RetentionStage {
+	if options == nil {
+		options = &StageOptions{}
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("Step", StageIDRetention))
+	}
+	return &RetentionStage{
+		// Runs after every step that reads the xlsx reports or daily CSVs
+		BaseStage: NewBaseStage(StageIDRetention, StageNameRetention, []string{StageIDIndices, StageIDLiquidity}),
+		paths:     paths,
+		logger:    logger,
+		options:   options,
+	}
+}
+
+// retentionPolicy reads the retention policy from the operation parameters
+func retentionPolicy(state *OperationState) files.RetentionPolicy {
+	var policy files.RetentionPolicy
+	if v, ok := state.GetConfig(ContextKeyRetentionArchiveMonths); ok {
+		policy.ArchiveXLSXAfterMonths = toInt(v)
+	}
+	if v, ok := state.GetConfig(ContextKeyRetentionDeleteDays); ok {
+		policy.DeleteDailyCSVAfterDays = toInt(v)
+	}
+	return policy
+}
+
+// IncludeInPipeline adds the step to a full pipeline when a policy is set
+func (r *RetentionStage) IncludeInPipeline(state *OperationState) bool {
+	return retentionPolicy(state).Enabled()
+}
+
+// Validate rejects negative retention periods before anything is touched
+func (r *RetentionStage) Validate(state *OperationState) error {
+	return retentionPolicy(state).Validate()
+}
+
+// Execute archives old xlsx reports and deletes old daily CSVs
+func (r *RetentionStage) Execute(ctx context.Context, state *OperationState) error {
+	StepState := state.GetStage(r.ID())
+
+	policy := retentionPolicy(state)
+	if !policy.Enabled() {
+		r.updateProgress(state.ID, StepState, 100, "No retention policy set, nothing to do")
+		return nil
+	}
+
+	dryRun := false
+	if v, ok := state.GetConfig(ContextKeyRetentionDryRun); ok {
+		switch d := v.(type) {
+		case bool:
+			dryRun = d
+		case string:
+			dryRun, _ = strconv.ParseBool(d)
+		}
+	}
+
+	r.updateProgress(state.ID, StepState, 10, "Applying retention policy...")
+
+	paths := operationPaths(r.paths, state)
+	if !dryRun {
+		dirLock, err := lockReportsDir(paths.ReportsDir, "retention stage", r.logger)
+		if err != nil {
+			return err
+		}
+		defer dirLock.Release()
+	}
+
+	manager := files.NewRetentionManager(policy, files.RetentionTargets{
+		DownloadsDir:    paths.DownloadsDir,
+		DailyReportsDir: paths.DailyReportsDir,
+		ArchiveDir:      paths.ArchiveDir,
+	}, r.logger)
+
+	result, err := manager.Run(ctx, dryRun)
+	if err != nil {
+		return fmt.Errorf("retention failed: %w", err)
+	}
+
+	StepState.Metadata["archived_files"] = result.ArchivedFiles
+	StepState.Metadata["archives"] = len(result.Archives)
+	StepState.Metadata["deleted_csvs"] = len(result.DeletedCSVs)
+	StepState.Metadata["kept_csvs"] = result.KeptCSVs
+	StepState.Metadata["bytes_freed"] = result.BytesFreed
+	StepState.Metadata["dry_run"] = dryRun
+
+	message := fmt.Sprintf("Archived %d reports, deleted %d daily CSVs", result.ArchivedFiles, len(result.DeletedCSVs))
+	if dryRun {
+		message = "Dry run: would have " + strings.ToLower(message[:1]) + message[1:]
+	}
+	r.updateProgress(state.ID, StepState, 100, message)
+	return nil
+}
+
+func (r *RetentionStage) updateProgress(operationID string, StepState *StepState, progress int, message string) {
+	StepState.UpdateProgress(float64(progress), message)
+
+	if r.options.StatusBroadcaster != nil {
+		r.options.StatusBroadcaster.UpdateStepWithMetadata(operationID, r.ID(), progress, message, StepState.Metadata)
+	}
+}
+
+// MigrationStage converts the data directory to the current layout after an
+// upgrade. It is an optional step: a full pipeline runs it first, and only
+// when a migration is pending.
+type MigrationStage struct {
+	BaseStage
+	paths   *config.Paths
+	logger  *slog.Logger
+	options *StageOptions
+}
+
+// NewMigrationStage creates a new migration step
+func NewMigrationStage(paths *config.Paths, logger *slog.Logger, options *StageOptions) *MigrationStage {
+	if options == nil {
+		options = &StageOptions{}
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("Step", StageIDMigration))
+	}
+	return &MigrationStage{
+		BaseStage: NewBaseStage(StageIDMigration, StageNameMigration, []string{}),
+		paths:     paths,
+		logger:    logger,
+		options:   options,
+	}
+}
+
+// IncludeInPipeline adds the step to a full pipeline when a migration is pending
+func (m *MigrationStage) IncludeInPipeline(state *OperationState) bool {
+	pending, err := migrations.Pending(operationPaths(m.paths, state))
+	if err != nil {
+		// Let Execute surface the error instead of silently skipping the step
+		return true
+	}
+	return len(pending) > 0
+}
+
+// Execute applies the pending data migrations
+func (m *MigrationStage) Execute(ctx context.Context, state *OperationState) error {
+	StepState := state.GetStage(m.ID())
+	paths := operationPaths(m.paths, state)
+
+	pending, err := migrations.Pending(paths)
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if len(pending) == 0 {
+		m.updateProgress(state.ID, StepState, 100, "Data is up to date, nothing to migrate")
+		return nil
+	}
+
+	m.updateProgress(state.ID, StepState, 10, fmt.Sprintf("Applying %d data migrations...", len(pending)))
+
+	dirLock, err := lockReportsDir(paths.ReportsDir, "migration stage", m.logger)
+	if err != nil {
+		return err
+	}
+	defer dirLock.Release()
+
+	applied, err := migrations.Run(ctx, paths, m.logger)
+	ids := make([]string, 0, len(applied))
+	for _, a := range applied {
+		ids = append(ids, a.ID)
+	}
+	StepState.Metadata["applied_migrations"] = ids
+	if err != nil {
+		return fmt.Errorf("data migration failed: %w", err)
+	}
+
+	m.updateProgress(state.ID, StepState, 100, fmt.Sprintf("Applied %d data migrations", len(applied)))
+	return nil
+}
+
+func (m *MigrationStage) updateProgress(operationID string, StepState *StepState, progress int, message string) {
+	StepState.UpdateProgress(float64(progress), message)
+
+	if m.options.StatusBroadcaster != nil {
+		m.options.StatusBroadcaster.UpdateStepWithMetadata(operationID, m.ID(), progress, message, StepState.Metadata)
+	}
+}
+
+// lockReportsDir takes the reports directory lock for a stage that writes
+// it in-process, so a manual processor run cannot interleave with it
+func lockReportsDir(reportsDir, owner string, logger *slog.Logger) (*files.DirLock, error) {
+	dirLock, err := files.AcquireDirLock(reportsDir, owner, files.LockOptions{Logger: logger})
+	if err != nil {
+		return nil, fmt.Errorf("reports directory busy: %w", err)
+	}
+	return dirLock, nil
+}
+
+// operationPaths returns the paths of the profile the operation in state
+// runs under; operations without one use base
+func operationPaths(base *config.Paths, state *OperationState) *config.Paths {
+	return profilePaths(base, stateString(state, ContextKeyProfile))
+}
+
+// manifestPaths returns the paths of the profile the manifest's operation
+// runs under; operations without one use base
+func manifestPaths(base *config.Paths, manifest *PipelineManifest) *config.Paths {
+	if manifest == nil {
+		return base
+	}
+	return profilePaths(base, manifest.Profile)
+}
+
+// profilePaths returns base's paths for profile, keeping base when profile
+// is empty or invalid. Stages built without paths resolve them relative to
+// the working directory.
+func profilePaths(base *config.Paths, profile string) *config.Paths {
+	if base == nil {
+		if base, _ = config.PathsForDir(""); base == nil {
+			base = &config.Paths{}
+		}
+	}
+	if profile == "" {
+		return base
+	}
+	paths, err := base.ForProfile(profile)
+	if err != nil {
+		return base
+	}
+	return paths
+}
+
+// profileArgs returns the flag that runs a stage command under the profile
+// of paths, so files it resolves itself come from the same profile
+func profileArgs(paths *config.Paths) []string {
+	if paths.Profile == "" {
+		return nil
+	}
+	return []string{"--profile", paths.Profile}
+}
+
 // StageFactory creates operation steps with optional configuration
-func StageFactory(executableDir string, logger *slog.Logger, options *StageOptions) map[string]Step {
+func StageFactory(paths *config.Paths, logger *slog.Logger, options *StageOptions) map[string]Step {
 	return map[string]Step{
-		StageIDScraping:   NewScrapingStage(executableDir, logger, options),
-		StageIDProcessing: NewProcessingStage(executableDir, logger, options),
-		StageIDIndices:    NewIndicesStage(executableDir, logger, options),
-		StageIDLiquidity:   NewLiquidityStage(executableDir, logger, options),
+		StageIDScraping:   NewScrapingStage(paths, logger, options),
+		StageIDProcessing: NewProcessingStage(paths, logger, options),
+		StageIDIndices:    NewIndicesStage(paths, logger, options),
+		StageIDLiquidity:   NewLiquidityStage(paths, logger, options),
+		StageIDRetention:   NewRetentionStage(paths, logger, options),
+		StageIDMigration:   NewMigrationStage(paths, logger, options),
 	}
 }
 
@@ -2059,4 +2800,9 @@ var (
 	_ Step = (*ProcessingStage)(nil)
 	_ Step = (*IndicesStage)(nil)
 	_ Step = (*LiquidityStage)(nil)
+	_ Step = (*RetentionStage)(nil)
+	_ Step = (*MigrationStage)(nil)
+
+	_ OptionalStep = (*RetentionStage)(nil)
+	_ OptionalStep = (*MigrationStage)(nil)
 )
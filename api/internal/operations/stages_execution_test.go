@@ -152,7 +152,7 @@ func TestScrapingStageExecution(t *testing.T) {
 				}
 			}
 			
-			Step := operations.NewScrapingStage(tempDir, logger, tt.options)
+			Step := operations.NewScrapingStage(stagePaths(t, tempDir), logger, tt.options)
 			state := createInitializedOperationState(operations.StageIDScraping, operations.StageNameScraping)
 			
 			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
@@ -216,7 +216,7 @@ func TestProcessingStageExecution(t *testing.T) {
 				}
 			}
 			
-			Step := operations.NewProcessingStage(tempDir, logger, nil)
+			Step := operations.NewProcessingStage(stagePaths(t, tempDir), logger, nil)
 			state := createInitializedOperationState(operations.StageIDProcessing, operations.StageNameProcessing)
 			
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -274,7 +274,7 @@ func TestIndicesStageExecution(t *testing.T) {
 				}
 			}
 			
-			Step := operations.NewIndicesStage(tempDir, logger, nil)
+			Step := operations.NewIndicesStage(stagePaths(t, tempDir), logger, nil)
 			state := createInitializedOperationState(operations.StageIDIndices, operations.StageNameIndices)
 			
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -297,7 +297,7 @@ func TestAnalysisStageExecution(t *testing.T) {
 	logger, _ := testutil.NewTestLogger(t)
 	tempDir := t.TempDir()
 	
-	Step := operations.NewLiquidityStage(tempDir, logger, nil)
+	Step := operations.NewLiquidityStage(stagePaths(t, tempDir), logger, nil)
 	state := createInitializedOperationState(operations.StageIDLiquidity, operations.StageNameLiquidity)
 	
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -337,7 +337,7 @@ func TestStageWebSocketIntegration(t *testing.T) {
 		t.Fatalf("Failed to create mock executable: %v", err)
 	}
 	
-	Step := operations.NewScrapingStage(tempDir, logger, options)
+	Step := operations.NewScrapingStage(stagePaths(t, tempDir), logger, options)
 	state := createInitializedOperationState(operations.StageIDScraping, operations.StageNameScraping)
 	
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -390,10 +390,10 @@ func TestStageOptionsValidation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Test that all Step types accept the options without error
 			steps := []operations.Step{
-				operations.NewScrapingStage(tempDir, logger, tt.options),
-				operations.NewProcessingStage(tempDir, logger, tt.options),
-				operations.NewIndicesStage(tempDir, logger, tt.options),
-				operations.NewLiquidityStage(tempDir, logger, tt.options),
+				operations.NewScrapingStage(stagePaths(t, tempDir), logger, tt.options),
+				operations.NewProcessingStage(stagePaths(t, tempDir), logger, tt.options),
+				operations.NewIndicesStage(stagePaths(t, tempDir), logger, tt.options),
+				operations.NewLiquidityStage(stagePaths(t, tempDir), logger, tt.options),
 			}
 			
 			for _, Step := range steps {
@@ -2,17 +2,31 @@ package operations_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"isxcli/internal/config"
+	"isxcli/internal/files"
 	"isxcli/internal/operations"
 	operationstestutil "isxcli/internal/operations/testutil"
 	testutil "isxcli/internal/shared/testutil"
 )
 
+// stagePaths lays out the active profile's paths under exeDir
+func stagePaths(t *testing.T, exeDir string) *config.Paths {
+	t.Helper()
+	paths, err := config.PathsForDir(exeDir)
+	if err != nil {
+		t.Fatalf("PathsForDir(%q) error = %v", exeDir, err)
+	}
+	return paths
+}
+
 // Mock implementations for testing
 type mockWebSocketHub struct {
 	broadcasts []mockBroadcast
@@ -103,7 +117,7 @@ func TestNewScrapingStage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			Step := operations.NewScrapingStage(tt.executableDir, tt.logger, tt.options)
+			Step := operations.NewScrapingStage(stagePaths(t, tt.executableDir), tt.logger, tt.options)
 			
 			if tt.expectNil {
 				if Step != nil {
@@ -188,7 +202,7 @@ func TestNewProcessingStage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			Step := operations.NewProcessingStage(tt.executableDir, tt.logger, tt.options)
+			Step := operations.NewProcessingStage(stagePaths(t, tt.executableDir), tt.logger, tt.options)
 			
 			if tt.expectNil {
 				if Step != nil {
@@ -274,7 +288,7 @@ func TestNewIndicesStage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			Step := operations.NewIndicesStage(tt.executableDir, tt.logger, tt.options)
+			Step := operations.NewIndicesStage(stagePaths(t, tt.executableDir), tt.logger, tt.options)
 			
 			if tt.expectNil {
 				if Step != nil {
@@ -360,7 +374,7 @@ func TestNewLiquidityStage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			Step := operations.NewLiquidityStage(tt.executableDir, tt.logger, tt.options)
+			Step := operations.NewLiquidityStage(stagePaths(t, tt.executableDir), tt.logger, tt.options)
 			
 			if tt.expectNil {
 				if Step != nil {
@@ -421,7 +435,7 @@ func TestStageFactory(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			steps := operations.StageFactory(executableDir, logger, tt.options)
+			steps := operations.StageFactory(stagePaths(t, executableDir), logger, tt.options)
 			
 			// Verify all expected steps are created
 			expectedStages := []string{
@@ -429,6 +443,8 @@ func TestStageFactory(t *testing.T) {
 				operations.StageIDProcessing,
 				operations.StageIDIndices,
 				operations.StageIDLiquidity,
+				operations.StageIDRetention,
+				operations.StageIDMigration,
 			}
 			
 			operationstestutil.AssertEqual(t, len(steps), len(expectedStages))
@@ -464,12 +480,170 @@ func TestStageFactory(t *testing.T) {
 					operationstestutil.AssertEqual(t, Step.Name(), operations.StageNameLiquidity)
 					operationstestutil.AssertEqual(t, len(Step.GetDependencies()), 1)
 					operationstestutil.AssertEqual(t, Step.GetDependencies()[0], operations.StageIDProcessing)
+				case operations.StageIDRetention:
+					operationstestutil.AssertEqual(t, Step.Name(), operations.StageNameRetention)
+					operationstestutil.AssertEqual(t, len(Step.GetDependencies()), 2)
+				case operations.StageIDMigration:
+					operationstestutil.AssertEqual(t, Step.Name(), operations.StageNameMigration)
+					operationstestutil.AssertEqual(t, len(Step.GetDependencies()), 0)
 				}
 			}
 		})
 	}
 }
 
+// TestRetentionStage tests the optional retention step
+func TestRetentionStage(t *testing.T) {
+	logger, _ := testutil.NewTestLogger(t)
+	exeDir := t.TempDir()
+	downloads := filepath.Join(exeDir, "data", "downloads")
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(downloads, "2020 01 05 ISX Daily Report.xlsx")
+	if err := os.WriteFile(report, []byte("xlsx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stage := operations.NewRetentionStage(stagePaths(t, exeDir), logger, nil)
+
+	newState := func(params map[string]interface{}) *operations.OperationState {
+		state := operations.NewOperationState("test-operation")
+		state.SetStage(stage.ID(), operations.NewStepState(stage.ID(), stage.Name()))
+		for k, v := range params {
+			state.SetConfig(k, v)
+		}
+		return state
+	}
+
+	t.Run("not part of a pipeline without a policy", func(t *testing.T) {
+		state := newState(nil)
+		operationstestutil.AssertEqual(t, stage.IncludeInPipeline(state), false)
+		operationstestutil.AssertNoError(t, stage.Execute(context.Background(), state))
+		if _, err := os.Stat(report); err != nil {
+			t.Errorf("report should be untouched: %v", err)
+		}
+	})
+
+	t.Run("negative period fails validation", func(t *testing.T) {
+		state := newState(map[string]interface{}{operations.ContextKeyRetentionDeleteDays: "-5"})
+		if err := stage.Validate(state); err == nil {
+			t.Error("expected validation error")
+		}
+	})
+
+	t.Run("dry run keeps files", func(t *testing.T) {
+		state := newState(map[string]interface{}{
+			operations.ContextKeyRetentionArchiveMonths: float64(12),
+			operations.ContextKeyRetentionDryRun:        true,
+		})
+		operationstestutil.AssertEqual(t, stage.IncludeInPipeline(state), true)
+		operationstestutil.AssertNoError(t, stage.Execute(context.Background(), state))
+		operationstestutil.AssertEqual(t, state.GetStage(stage.ID()).Metadata["archived_files"], 1)
+		if _, err := os.Stat(report); err != nil {
+			t.Errorf("dry run should keep the report: %v", err)
+		}
+	})
+
+	t.Run("fails while another process holds the reports directory", func(t *testing.T) {
+		reportsDir := filepath.Join(exeDir, "data", "reports")
+		if err := os.MkdirAll(reportsDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		host, _ := os.Hostname()
+		data, _ := json.Marshal(files.LockInfo{Token: "manual", Owner: "processor", PID: os.Getppid(), Host: host, AcquiredAt: time.Now(), Heartbeat: time.Now()})
+		lockPath := filepath.Join(reportsDir, files.LockFileName)
+		if err := os.WriteFile(lockPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(lockPath)
+
+		state := newState(map[string]interface{}{operations.ContextKeyRetentionArchiveMonths: float64(12)})
+		if err := stage.Execute(context.Background(), state); !errors.Is(err, files.ErrLocked) {
+			t.Fatalf("expected ErrLocked, got %v", err)
+		}
+		if _, err := os.Stat(report); err != nil {
+			t.Errorf("report should be untouched while locked: %v", err)
+		}
+	})
+
+	t.Run("archives old reports", func(t *testing.T) {
+		state := newState(map[string]interface{}{operations.ContextKeyRetentionArchiveMonths: float64(12)})
+		operationstestutil.AssertNoError(t, stage.Execute(context.Background(), state))
+		if _, err := os.Stat(report); !os.IsNotExist(err) {
+			t.Error("report should have been archived")
+		}
+		if _, err := os.Stat(filepath.Join(exeDir, "data", "archive", "daily_reports_2020_01.zip")); err != nil {
+			t.Errorf("archive not written: %v", err)
+		}
+	})
+}
+
+// TestMigrationStage tests the optional data migration step
+func TestLiquidityStageWindows(t *testing.T) {
+	logger, _ := testutil.NewTestLogger(t)
+	stage := operations.NewLiquidityStage(stagePaths(t, t.TempDir()), logger, nil)
+
+	tests := []struct {
+		name    string
+		windows interface{}
+		wantErr bool
+	}{
+		{name: "unset", windows: nil},
+		{name: "comma separated", windows: "20,60,120"},
+		{name: "list", windows: []interface{}{float64(20), "120d"}},
+		{name: "single number", windows: float64(120)},
+		{name: "unsupported window", windows: "20,45", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := operations.NewOperationState("test-operation")
+			if tt.windows != nil {
+				state.SetConfig(operations.ContextKeyLiquidityWindows, tt.windows)
+			}
+			err := stage.Validate(state)
+			if tt.wantErr && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tt.wantErr {
+				operationstestutil.AssertNoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMigrationStage(t *testing.T) {
+	logger, _ := testutil.NewTestLogger(t)
+	exeDir := t.TempDir()
+	dailyDir := filepath.Join(exeDir, "data", "reports", "daily")
+	if err := os.MkdirAll(dailyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stage := operations.NewMigrationStage(stagePaths(t, exeDir), logger, nil)
+	newState := func() *operations.OperationState {
+		state := operations.NewOperationState("test-operation")
+		state.SetStage(stage.ID(), operations.NewStepState(stage.ID(), stage.Name()))
+		return state
+	}
+
+	state := newState()
+	operationstestutil.AssertEqual(t, stage.IncludeInPipeline(state), false)
+
+	// A daily CSV written before the FillMethod column existed
+	daily := filepath.Join(dailyDir, "isx_daily_2025_01_05.csv")
+	if err := os.WriteFile(daily, []byte("Date,CompanySymbol,ClosePrice,TradingStatus\n2025-01-05,BBOB,1.2,false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	operationstestutil.AssertEqual(t, stage.IncludeInPipeline(state), true)
+	operationstestutil.AssertNoError(t, stage.Execute(context.Background(), state))
+	operationstestutil.AssertEqual(t, stage.IncludeInPipeline(state), false)
+
+	data, err := os.ReadFile(daily)
+	operationstestutil.AssertNoError(t, err)
+	operationstestutil.AssertEqual(t, string(data), "Date,CompanySymbol,ClosePrice,TradingStatus,FillMethod\n2025-01-05,BBOB,1.2,false,carry_forward\n")
+}
+
 // TestStageExecutionBasics tests basic execution setup (without actual command execution)
 func TestStageExecutionBasics(t *testing.T) {
 	logger, _ := testutil.NewTestLogger(t)
@@ -498,28 +672,28 @@ func TestStageExecutionBasics(t *testing.T) {
 			name:      "scraping Step execution setup",
 			stageType: "scraping",
 			constructor: func() operations.Step {
-				return operations.NewScrapingStage(tempDir, logger, nil)
+				return operations.NewScrapingStage(stagePaths(t, tempDir), logger, nil)
 			},
 		},
 		{
 			name:      "processing Step execution setup",
 			stageType: "processing",
 			constructor: func() operations.Step {
-				return operations.NewProcessingStage(tempDir, logger, nil)
+				return operations.NewProcessingStage(stagePaths(t, tempDir), logger, nil)
 			},
 		},
 		{
 			name:      "indices Step execution setup",
 			stageType: "indices",
 			constructor: func() operations.Step {
-				return operations.NewIndicesStage(tempDir, logger, nil)
+				return operations.NewIndicesStage(stagePaths(t, tempDir), logger, nil)
 			},
 		},
 		{
 			name:      "liquidity Step execution setup",
 			stageType: "liquidity",
 			constructor: func() operations.Step {
-				return operations.NewLiquidityStage(tempDir, logger, nil)
+				return operations.NewLiquidityStage(stagePaths(t, tempDir), logger, nil)
 			},
 		},
 	}
@@ -624,25 +798,25 @@ func TestStageOptionsHandling(t *testing.T) {
 		{
 			name: "scraping",
 			constructor: func(opts *operations.StageOptions) operations.Step {
-				return operations.NewScrapingStage(executableDir, logger, opts)
+				return operations.NewScrapingStage(stagePaths(t, executableDir), logger, opts)
 			},
 		},
 		{
 			name: "processing", 
 			constructor: func(opts *operations.StageOptions) operations.Step {
-				return operations.NewProcessingStage(executableDir, logger, opts)
+				return operations.NewProcessingStage(stagePaths(t, executableDir), logger, opts)
 			},
 		},
 		{
 			name: "indices",
 			constructor: func(opts *operations.StageOptions) operations.Step {
-				return operations.NewIndicesStage(executableDir, logger, opts)
+				return operations.NewIndicesStage(stagePaths(t, executableDir), logger, opts)
 			},
 		},
 		{
 			name: "liquidity",
 			constructor: func(opts *operations.StageOptions) operations.Step {
-				return operations.NewLiquidityStage(executableDir, logger, opts)
+				return operations.NewLiquidityStage(stagePaths(t, executableDir), logger, opts)
 			},
 		},
 	}
@@ -675,10 +849,10 @@ func TestStageValidationBehavior(t *testing.T) {
 	executableDir := "/path/to/executables"
 	
 	steps := []operations.Step{
-		operations.NewScrapingStage(executableDir, logger, nil),
-		operations.NewProcessingStage(executableDir, logger, nil),
-		operations.NewIndicesStage(executableDir, logger, nil),
-		operations.NewLiquidityStage(executableDir, logger, nil),
+		operations.NewScrapingStage(stagePaths(t, executableDir), logger, nil),
+		operations.NewProcessingStage(stagePaths(t, executableDir), logger, nil),
+		operations.NewIndicesStage(stagePaths(t, executableDir), logger, nil),
+		operations.NewLiquidityStage(stagePaths(t, executableDir), logger, nil),
 	}
 	
 	tests := []struct {
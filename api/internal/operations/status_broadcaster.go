@@ -2,7 +2,9 @@ package operations
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 )
@@ -31,6 +33,10 @@ type OperationSnapshot struct {
 	CompletedAt *time.Time     `json:"completed_at,omitempty"`
 	Error       string         `json:"error,omitempty"`
 	Message     string         `json:"message,omitempty"`
+	// QueuePosition is the place of a pending operation waiting for others
+	// to finish, 1 being next; zero once it runs
+	QueuePosition int      `json:"queue_position,omitempty"`
+	BlockedBy     []string `json:"blocked_by,omitempty"`
 }
 
 // StepSnapshot represents the state of a single step
@@ -187,6 +193,19 @@ func (sb *StatusBroadcaster) StartOperation(operationID string) {
 	sb.UpdateStatus(operationID, func(snapshot *OperationSnapshot) {
 		snapshot.Status = "running"
 		snapshot.Message = "Operation started"
+		snapshot.QueuePosition = 0
+		snapshot.BlockedBy = nil
+	})
+}
+
+// QueueOperation records that a pending operation waits for the operations
+// in blockedBy, at the given place in the queue
+func (sb *StatusBroadcaster) QueueOperation(operationID string, position int, blockedBy []string) {
+	sb.UpdateStatus(operationID, func(snapshot *OperationSnapshot) {
+		snapshot.Status = "pending"
+		snapshot.QueuePosition = position
+		snapshot.BlockedBy = blockedBy
+		snapshot.Message = fmt.Sprintf("Queued at position %d, waiting for %s", position, strings.Join(blockedBy, ", "))
 	})
 }
 
@@ -1,6 +1,7 @@
 package operations
 
 import (
+	"errors"
 	"time"
 )
 
@@ -10,6 +11,8 @@ const (
 	StageIDProcessing = "processing"
 	StageIDIndices   = "indices"
 	StageIDLiquidity  = "liquidity"
+	StageIDRetention  = "retention"
+	StageIDMigration  = "migration"
 )
 
 // operation Step names
@@ -18,6 +21,8 @@ const (
 	StageNameProcessing = "Data Processing"
 	StageNameIndices   = "Index Extraction"
 	StageNameLiquidity  = "Liquidity Calculation"
+	StageNameRetention  = "Data Retention"
+	StageNameMigration  = "Data Migration"
 )
 
 // Context keys for operation state
@@ -30,6 +35,32 @@ const (
 	ContextKeyFilesFound    = "files_found"
 	ContextKeyFilesProcessed = "files_processed"
 	ContextKeyScraperSuccess = "scraper_success"
+	ContextKeyBandwidthKBps  = "bandwidth_kbps"
+	// ContextKeyScrapeBatch is the size of the searches a long scrape is
+	// split into: month (default) or quarter
+	ContextKeyScrapeBatch = "batch_size"
+	ContextKeySingleStep     = "single_step"
+	// ContextKeyLiquidityWindows selects the liquidity windows, e.g. "20,60,120"
+	ContextKeyLiquidityWindows = "windows"
+	// ContextKeyLiquidityFullRecalc ignores the saved liquidity state and
+	// recalculates every ticker and date
+	ContextKeyLiquidityFullRecalc = "full_recalc"
+	// ContextKeySteps selects the steps to run ([]string), in dependency order
+	ContextKeySteps = "steps"
+
+	// Retention policy parameters; setting either adds the retention step to a full pipeline
+	ContextKeyRetentionArchiveMonths = "retention_archive_xlsx_months"
+	ContextKeyRetentionDeleteDays    = "retention_delete_daily_csv_days"
+	ContextKeyRetentionDryRun        = "retention_dry_run"
+
+	// Reprocessing range (YYYY-MM-DD, inclusive); the processing step rebuilds
+	// only these dates and keeps the rest of the existing outputs
+	ContextKeyReprocessFrom = "reprocess_from"
+	ContextKeyReprocessTo   = "reprocess_to"
+
+	// ContextKeyProfile is the data profile the operation runs under; the
+	// steps read and write that profile's data directories
+	ContextKeyProfile = "profile"
 )
 
 // operation modes
@@ -46,6 +77,23 @@ const (
 	EventTypePipelineComplete = "operation:complete"
 	EventTypeOperationError    = "operation:error"
 	EventTypePipelineReset    = "operation:reset"
+	// EventTypeDataUpdated carries the files.DataChanges of a processing run
+	EventTypeDataUpdated = "data:updated"
+	// EventTypeAnomaliesDetected carries the analytics.AnomalySummary of a
+	// processing run that flagged anomalies
+	EventTypeAnomaliesDetected = "data:anomalies"
+	// EventTypeScraperHoliday carries the date of a weekday in the requested
+	// range that has no daily report
+	EventTypeScraperHoliday = "scraper:holiday"
+	// EventTypeScraperBufferZone is sent when the scraper reaches reports
+	// dated before the requested range
+	EventTypeScraperBufferZone = "scraper:buffer_zone"
+	// EventTypeScraperStopped carries the stop reason and the ScrapeCalendar
+	// of a scraping run
+	EventTypeScraperStopped = "scraper:stopped"
+	// EventTypeScraperBatch carries the batch a long scrape started,
+	// finished or skipped as finished by an interrupted run
+	EventTypeScraperBatch = "scraper:batch"
 )
 
 // Default timeouts
@@ -74,12 +122,35 @@ const (
 	OperationModeResume  OperationMode = "resume"
 )
 
-// RetryConfig defines retry behavior for steps
+// RetryConfig defines retry behavior for steps. A failed attempt is retried
+// when its error is marked retryable or its class is listed in RetryOn
+// (network and timeout failures when empty).
 type RetryConfig struct {
 	MaxAttempts int           `json:"max_attempts"`
 	InitialDelay time.Duration `json:"initial_delay"`
 	MaxDelay    time.Duration `json:"max_delay"`
 	Multiplier  float64       `json:"multiplier"`
+	RetryOn     []ErrorClass  `json:"retry_on,omitempty"`
+}
+
+// ShouldRetry reports whether a failed attempt is worth repeating. Operation
+// errors carry their own retryable flag; other errors are classified.
+func (c RetryConfig) ShouldRetry(err error) bool {
+	var opErr *OperationError
+	if errors.As(err, &opErr) {
+		return opErr.Retryable
+	}
+	classes := c.RetryOn
+	if len(classes) == 0 {
+		classes = TransientErrorClasses
+	}
+	class := ClassifyError(err)
+	for _, retryOn := range classes {
+		if class == retryOn && class != ErrorClassPermanent {
+			return true
+		}
+	}
+	return false
 }
 
 // NewRetryConfig returns the default retry configuration
@@ -110,6 +181,12 @@ type OperationRequest struct {
 	FromDate   string                 `json:"from_date,omitempty"`
 	ToDate     string                 `json:"to_date,omitempty"`
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	// DryRun asks for the operation's plan (see Manager.Plan) instead of
+	// running it
+	DryRun bool `json:"dry_run,omitempty"`
+	// Profile is the data profile to run under; empty means the profile of
+	// the context the operation starts with
+	Profile string `json:"profile,omitempty"`
 }
 
 // OperationResponse represents the response from a operation execution
@@ -0,0 +1,426 @@
+package query
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// arity is the number of arguments a function takes; max -1 is unbounded
+type arity struct {
+	min, max  int
+	aggregate bool
+}
+
+// functions are the functions statements may call
+var functions = map[string]arity{
+	"COUNT":    {min: 1, max: 1, aggregate: true},
+	"SUM":      {min: 1, max: 1, aggregate: true},
+	"AVG":      {min: 1, max: 1, aggregate: true},
+	"MIN":      {min: 1, max: 1, aggregate: true},
+	"MAX":      {min: 1, max: 1, aggregate: true},
+	"UPPER":    {min: 1, max: 1},
+	"LOWER":    {min: 1, max: 1},
+	"LENGTH":   {min: 1, max: 1},
+	"ABS":      {min: 1, max: 1},
+	"ROUND":    {min: 1, max: 2},
+	"SUBSTR":   {min: 2, max: 3},
+	"COALESCE": {min: 1, max: -1},
+}
+
+// env is what an expression is evaluated against: a table row and, in
+// grouped statements, the rows of its group
+type env struct {
+	row   []string
+	group [][]string
+}
+
+// resolve binds column references to header positions and checks where
+// aggregates may appear
+func resolve(x expr, header map[string]int, aggregates bool) (hasAggregate bool, err error) {
+	switch x := x.(type) {
+	case *columnRef:
+		i, ok := header[strings.ToLower(x.name)]
+		if !ok {
+			return false, rejectf("no such column: %s", x.name)
+		}
+		x.index = i
+	case *unaryExpr:
+		return resolve(x.x, header, aggregates)
+	case *binaryExpr:
+		return resolveAll(header, aggregates, x.l, x.r)
+	case *likeExpr:
+		return resolveAll(header, aggregates, x.x, x.pattern)
+	case *inExpr:
+		return resolveAll(header, aggregates, append([]expr{x.x}, x.list...)...)
+	case *isNullExpr:
+		return resolve(x.x, header, aggregates)
+	case *betweenExpr:
+		return resolveAll(header, aggregates, x.x, x.lo, x.hi)
+	case *callExpr:
+		if functions[x.name].aggregate {
+			if !aggregates {
+				return false, rejectf("%s is not allowed here", x.name)
+			}
+			// Aggregates cannot nest
+			if _, err := resolveAll(header, false, x.args...); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		return resolveAll(header, aggregates, x.args...)
+	}
+	return false, nil
+}
+
+func resolveAll(header map[string]int, aggregates bool, xs ...expr) (bool, error) {
+	has := false
+	for _, x := range xs {
+		h, err := resolve(x, header, aggregates)
+		if err != nil {
+			return false, err
+		}
+		has = has || h
+	}
+	return has, nil
+}
+
+// eval computes an expression. Values are nil (NULL), float64 or string;
+// conditions are 1, 0 or NULL.
+func eval(x expr, e *env) interface{} {
+	switch x := x.(type) {
+	case *literal:
+		return x.v
+	case *columnRef:
+		if x.index >= len(e.row) {
+			return nil
+		}
+		return cellValue(e.row[x.index])
+	case *unaryExpr:
+		v := eval(x.x, e)
+		if x.op == "NOT" {
+			if t, ok := truth(v); ok {
+				return boolValue(!t)
+			}
+			return nil
+		}
+		if n, ok := number(v); ok {
+			return -n
+		}
+		return nil
+	case *binaryExpr:
+		return evalBinary(x, e)
+	case *likeExpr:
+		v, pattern := eval(x.x, e), eval(x.pattern, e)
+		if v == nil || pattern == nil {
+			return nil
+		}
+		return boolValue(likePattern(text(pattern)).MatchString(text(v)) != x.not)
+	case *inExpr:
+		v := eval(x.x, e)
+		if v == nil {
+			return nil
+		}
+		for _, item := range x.list {
+			if c, ok := compare(v, eval(item, e)); ok && c == 0 {
+				return boolValue(!x.not)
+			}
+		}
+		return boolValue(x.not)
+	case *isNullExpr:
+		return boolValue((eval(x.x, e) == nil) != x.not)
+	case *betweenExpr:
+		v := eval(x.x, e)
+		lo, okLo := compare(v, eval(x.lo, e))
+		hi, okHi := compare(v, eval(x.hi, e))
+		if !okLo || !okHi {
+			return nil
+		}
+		return boolValue((lo >= 0 && hi <= 0) != x.not)
+	case *callExpr:
+		if functions[x.name].aggregate {
+			return evalAggregate(x, e)
+		}
+		return evalFunction(x, e)
+	}
+	return nil
+}
+
+func evalBinary(x *binaryExpr, e *env) interface{} {
+	l, r := eval(x.l, e), eval(x.r, e)
+	switch x.op {
+	case "AND":
+		lt, lok := truth(l)
+		rt, rok := truth(r)
+		if (lok && !lt) || (rok && !rt) {
+			return boolValue(false)
+		}
+		if !lok || !rok {
+			return nil
+		}
+		return boolValue(true)
+	case "OR":
+		lt, lok := truth(l)
+		rt, rok := truth(r)
+		if (lok && lt) || (rok && rt) {
+			return boolValue(true)
+		}
+		if !lok || !rok {
+			return nil
+		}
+		return boolValue(false)
+	case "||":
+		if l == nil || r == nil {
+			return nil
+		}
+		return text(l) + text(r)
+	case "=", "!=", "<", "<=", ">", ">=":
+		c, ok := compare(l, r)
+		if !ok {
+			return nil
+		}
+		switch x.op {
+		case "=":
+			return boolValue(c == 0)
+		case "!=":
+			return boolValue(c != 0)
+		case "<":
+			return boolValue(c < 0)
+		case "<=":
+			return boolValue(c <= 0)
+		case ">":
+			return boolValue(c > 0)
+		default:
+			return boolValue(c >= 0)
+		}
+	}
+
+	a, okA := number(l)
+	b, okB := number(r)
+	if !okA || !okB {
+		return nil
+	}
+	switch x.op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			return nil
+		}
+		return a / b
+	}
+	return nil
+}
+
+func evalAggregate(x *callExpr, e *env) interface{} {
+	if x.star {
+		return float64(len(e.group))
+	}
+	var (
+		count int
+		sum   float64
+		best  interface{}
+	)
+	row := &env{}
+	for _, r := range e.group {
+		row.row = r
+		v := eval(x.args[0], row)
+		if v == nil {
+			continue
+		}
+		switch x.name {
+		case "COUNT":
+			count++
+		case "SUM", "AVG":
+			if n, ok := number(v); ok {
+				sum += n
+				count++
+			}
+		case "MIN", "MAX":
+			c, _ := compare(v, best)
+			if best == nil || (x.name == "MIN" && c < 0) || (x.name == "MAX" && c > 0) {
+				best = v
+			}
+		}
+	}
+	switch x.name {
+	case "COUNT":
+		return float64(count)
+	case "SUM":
+		if count == 0 {
+			return nil
+		}
+		return sum
+	case "AVG":
+		if count == 0 {
+			return nil
+		}
+		return sum / float64(count)
+	}
+	return best
+}
+
+func evalFunction(x *callExpr, e *env) interface{} {
+	args := make([]interface{}, len(x.args))
+	for i, a := range x.args {
+		args[i] = eval(a, e)
+	}
+	if x.name == "COALESCE" {
+		for _, v := range args {
+			if v != nil {
+				return v
+			}
+		}
+		return nil
+	}
+	if args[0] == nil {
+		return nil
+	}
+
+	switch x.name {
+	case "UPPER":
+		return strings.ToUpper(text(args[0]))
+	case "LOWER":
+		return strings.ToLower(text(args[0]))
+	case "LENGTH":
+		return float64(len([]rune(text(args[0]))))
+	case "SUBSTR":
+		runes := []rune(text(args[0]))
+		start, ok := number(args[1])
+		if !ok {
+			return nil
+		}
+		from := int(start) - 1
+		if from < 0 {
+			from = 0
+		}
+		to := len(runes)
+		if len(args) == 3 {
+			n, ok := number(args[2])
+			if !ok {
+				return nil
+			}
+			if from+int(n) < to {
+				to = from + int(n)
+			}
+		}
+		if from >= to {
+			return ""
+		}
+		return string(runes[from:to])
+	}
+
+	n, ok := number(args[0])
+	if !ok {
+		return nil
+	}
+	switch x.name {
+	case "ABS":
+		return math.Abs(n)
+	case "ROUND":
+		digits := 0.0
+		if len(args) == 2 {
+			if digits, ok = number(args[1]); !ok {
+				return nil
+			}
+		}
+		scale := math.Pow(10, math.Trunc(digits))
+		return math.Round(n*scale) / scale
+	}
+	return nil
+}
+
+// cellValue converts a CSV cell: empty is NULL, numbers are numeric and
+// anything else is text
+func cellValue(cell string) interface{} {
+	if cell == "" {
+		return nil
+	}
+	if n, err := strconv.ParseFloat(cell, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+		return n
+	}
+	return cell
+}
+
+// number converts a value to a number; text that is not a number is not
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+	}
+	return 0, false
+}
+
+// text formats a value as text
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// compare orders two values: numerically when both are numbers, as text
+// otherwise. It reports false when either is NULL.
+func compare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return strings.Compare(text(a), text(b)), true
+}
+
+// truth interprets a condition; ok is false for NULL
+func truth(v interface{}) (t, ok bool) {
+	if v == nil {
+		return false, false
+	}
+	n, isNumber := number(v)
+	return isNumber && n != 0, true
+}
+
+func boolValue(b bool) interface{} {
+	if b {
+		return 1.0
+	}
+	return 0.0
+}
+
+// likePattern compiles a LIKE pattern: % matches any run of characters, _
+// any one character, case-insensitively
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
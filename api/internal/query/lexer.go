@@ -0,0 +1,147 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+// token is a lexical unit of a statement; pos and end are the byte offsets
+// of its source text
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	end  int
+}
+
+// is reports whether t is the keyword or symbol s, ignoring case
+func (t token) is(s string) bool {
+	return (t.kind == tokenIdent || t.kind == tokenSymbol) && strings.EqualFold(t.text, s)
+}
+
+// twoCharSymbols are operators made of two characters
+var twoCharSymbols = []string{"<=", ">=", "<>", "!=", "||"}
+
+// lex splits a statement into tokens. Line comments (--) are skipped.
+func lex(sql string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case isIdentStart(c):
+			start := i
+			for i < len(sql) && isIdentPart(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: sql[start:i], pos: start, end: i})
+		case isDigit(c) || (c == '.' && i+1 < len(sql) && isDigit(sql[i+1])):
+			start := i
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+			if i < len(sql) && sql[i] == '.' {
+				i++
+				for i < len(sql) && isDigit(sql[i]) {
+					i++
+				}
+			}
+			if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+				j := i + 1
+				if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+					j++
+				}
+				if j < len(sql) && isDigit(sql[j]) {
+					for i = j; i < len(sql) && isDigit(sql[i]); i++ {
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: sql[start:i], pos: start, end: i})
+		case c == '\'' || c == '"':
+			text, end, err := lexQuoted(sql, i)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: i, end: end})
+			i = end
+		default:
+			symbol := string(c)
+			for _, s := range twoCharSymbols {
+				if strings.HasPrefix(sql[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if !strings.Contains("(),*+-/;=<>", symbol) && len(symbol) == 1 {
+				return nil, rejectf("unexpected character %q at position %d", c, i+1)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, pos: i, end: i + len(symbol)})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(sql), end: len(sql)}), nil
+}
+
+// lexQuoted reads a string or quoted identifier starting at sql[start]; a
+// doubled quote stands for itself
+func lexQuoted(sql string, start int) (string, int, error) {
+	quote := sql[start]
+	var b strings.Builder
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] != quote {
+			b.WriteByte(sql[i])
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == quote {
+			b.WriteByte(quote)
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, rejectf("unterminated %s starting at position %d", quoteName(quote), start+1)
+}
+
+func quoteName(quote byte) string {
+	if quote == '"' {
+		return "quoted identifier"
+	}
+	return "string"
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// rejectf returns an ErrRejected error with a reason
+func rejectf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrRejected, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,508 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+)
+
+// statement is a parsed SELECT
+type statement struct {
+	star    bool // SELECT *
+	items   []selectItem
+	table   string
+	where   expr
+	groupBy []expr
+	orderBy []orderItem
+	limit   int // -1 without LIMIT
+	offset  int
+}
+
+type selectItem struct {
+	x     expr
+	alias string
+	text  string // Source text, the column name without an alias
+}
+
+type orderItem struct {
+	x    expr
+	desc bool
+}
+
+// Expression nodes
+type (
+	expr interface{}
+
+	literal struct {
+		v interface{} // nil, float64 or string
+	}
+	columnRef struct {
+		name  string
+		index int // Resolved against the table header
+	}
+	unaryExpr struct {
+		op string // "-" or "NOT"
+		x  expr
+	}
+	binaryExpr struct {
+		op   string // Arithmetic, comparison, ||, AND or OR
+		l, r expr
+	}
+	likeExpr struct {
+		x, pattern expr
+		not        bool
+	}
+	inExpr struct {
+		x    expr
+		list []expr
+		not  bool
+	}
+	isNullExpr struct {
+		x   expr
+		not bool
+	}
+	betweenExpr struct {
+		x, lo, hi expr
+		not       bool
+	}
+	callExpr struct {
+		name string // Upper case
+		args []expr
+		star bool // COUNT(*)
+	}
+)
+
+// reserved words cannot be used as bare aliases or column names
+var reserved = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true,
+	"ORDER": true, "LIMIT": true, "OFFSET": true, "AS": true, "AND": true,
+	"OR": true, "NOT": true, "LIKE": true, "IN": true, "IS": true,
+	"NULL": true, "BETWEEN": true, "ASC": true, "DESC": true, "JOIN": true,
+	"UNION": true, "HAVING": true, "DISTINCT": true,
+}
+
+type parser struct {
+	sql    string
+	tokens []token
+	pos    int
+}
+
+// parse parses a single SELECT statement
+func parse(sql string) (*statement, error) {
+	tokens, err := lex(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{sql: sql, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, rejectf("the statement is empty")
+	}
+	if !p.peek().is("SELECT") {
+		return nil, rejectf("only SELECT statements are allowed, not %s", strings.ToUpper(p.peek().text))
+	}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	p.accept(";")
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, rejectf("unexpected %q at position %d; only a single SELECT statement is allowed", t.text, t.pos+1)
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	p.next() // SELECT
+	stmt := &statement{limit: -1}
+	if p.peek().is("DISTINCT") {
+		return nil, rejectf("DISTINCT is not supported; use GROUP BY")
+	}
+
+	if p.accept("*") {
+		stmt.star = true
+	} else {
+		for {
+			item, err := p.parseSelectItem()
+			if err != nil {
+				return nil, err
+			}
+			stmt.items = append(stmt.items, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if !p.accept("FROM") {
+		return nil, p.unexpected("FROM")
+	}
+	table := p.next()
+	if table.kind != tokenIdent && table.kind != tokenQuotedIdent {
+		return nil, rejectf("expected a table name at position %d", table.pos+1)
+	}
+	stmt.table = strings.ToLower(table.text)
+	if t := p.peek(); t.is(",") || t.is("JOIN") {
+		return nil, rejectf("queries read a single table; joins are not supported")
+	}
+
+	var err error
+	if p.accept("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("GROUP") {
+		if !p.accept("BY") {
+			return nil, p.unexpected("BY")
+		}
+		for {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.groupBy = append(stmt.groupBy, x)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.peek().is("HAVING") {
+		return nil, rejectf("HAVING is not supported")
+	}
+	if p.accept("ORDER") {
+		if !p.accept("BY") {
+			return nil, p.unexpected("BY")
+		}
+		for {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{x: x}
+			if p.accept("DESC") {
+				item.desc = true
+			} else {
+				p.accept("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("LIMIT") {
+		if stmt.limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+		if p.accept("OFFSET") {
+			if stmt.offset, err = p.parseCount("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.peek().is("UNION") {
+		return nil, rejectf("UNION is not supported")
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	start := p.peek().pos
+	x, err := p.parseExpr()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{x: x, text: p.sql[start:p.tokens[p.pos-1].end]}
+	if p.accept("AS") {
+		t := p.next()
+		if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+			return selectItem{}, rejectf("expected an alias after AS at position %d", t.pos+1)
+		}
+		item.alias = t.text
+	} else if t := p.peek(); t.kind == tokenQuotedIdent || (t.kind == tokenIdent && !reserved[strings.ToUpper(t.text)]) {
+		item.alias = p.next().text
+	}
+	return item, nil
+}
+
+// parseCount parses the non-negative integer of LIMIT or OFFSET
+func (p *parser) parseCount(clause string) (int, error) {
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokenNumber || err != nil || n < 0 {
+		return 0, rejectf("%s needs a non-negative integer", clause)
+	}
+	return n, nil
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: "OR", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: "AND", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.accept("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"=", "!=", "<>", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			r, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			if op == "<>" {
+				op = "!="
+			}
+			return &binaryExpr{op: op, l: l, r: r}, nil
+		}
+	}
+
+	if p.accept("IS") {
+		not := p.accept("NOT")
+		if !p.accept("NULL") {
+			return nil, p.unexpected("NULL")
+		}
+		return &isNullExpr{x: l, not: not}, nil
+	}
+
+	not := p.accept("NOT")
+	switch {
+	case p.accept("LIKE"):
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &likeExpr{x: l, pattern: pattern, not: not}, nil
+	case p.accept("IN"):
+		if !p.accept("(") {
+			return nil, p.unexpected("(")
+		}
+		if p.peek().is("SELECT") {
+			return nil, rejectf("subqueries are not supported")
+		}
+		in := &inExpr{x: l, not: not}
+		for {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, x)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected(")")
+		}
+		return in, nil
+	case p.accept("BETWEEN"):
+		lo, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept("AND") {
+			return nil, p.unexpected("AND")
+		}
+		hi, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{x: l, lo: lo, hi: hi, not: not}, nil
+	case not:
+		return nil, p.unexpected("LIKE, IN or BETWEEN")
+	}
+	return l, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	l, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.accept("+") && !p.accept("-") && !p.accept("||") {
+			return l, nil
+		}
+		r, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: op, l: l, r: r}
+	}
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.accept("*") && !p.accept("/") {
+			return l, nil
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: op, l: l, r: r}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept("-") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "-", x: x}, nil
+	}
+	p.accept("+")
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, rejectf("invalid number %q", t.text)
+		}
+		return &literal{v: v}, nil
+	case tokenString:
+		return &literal{v: t.text}, nil
+	case tokenQuotedIdent:
+		return &columnRef{name: t.text}, nil
+	case tokenIdent:
+		upper := strings.ToUpper(t.text)
+		if upper == "NULL" {
+			return &literal{}, nil
+		}
+		if p.peek().is("(") {
+			return p.parseCall(upper)
+		}
+		if reserved[upper] {
+			return nil, rejectf("unexpected %s at position %d", upper, t.pos+1)
+		}
+		return &columnRef{name: t.text}, nil
+	case tokenSymbol:
+		if t.text == "(" {
+			if p.peek().is("SELECT") {
+				return nil, rejectf("subqueries are not supported")
+			}
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, p.unexpected(")")
+			}
+			return x, nil
+		}
+	case tokenEOF:
+		return nil, rejectf("the statement ends unexpectedly")
+	}
+	return nil, rejectf("unexpected %q at position %d", t.text, t.pos+1)
+}
+
+func (p *parser) parseCall(name string) (expr, error) {
+	if _, ok := functions[name]; !ok {
+		return nil, rejectf("unknown function %s", name)
+	}
+	p.next() // (
+	call := &callExpr{name: name}
+	if name == "COUNT" && p.accept("*") {
+		call.star = true
+	} else if !p.peek().is(")") {
+		for {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, x)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if !p.accept(")") {
+		return nil, p.unexpected(")")
+	}
+	arity := functions[name]
+	if !call.star && (len(call.args) < arity.min || (arity.max >= 0 && len(call.args) > arity.max)) {
+		return nil, rejectf("wrong number of arguments to %s", name)
+	}
+	return call, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the keyword or symbol s
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected(want string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return rejectf("expected %s, but the statement ends", want)
+	}
+	return rejectf("expected %s at position %d, found %q", want, t.pos+1, t.text)
+}
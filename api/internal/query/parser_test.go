@@ -0,0 +1,84 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	stmt, err := parse(`SELECT a, b + 1 AS "b plus", COUNT(*) -- trailing comment
+		FROM Trades WHERE a <> 'it''s' GROUP BY a ORDER BY 2 DESC, a LIMIT 10 OFFSET 5;`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "trades", stmt.table)
+	require.Len(t, stmt.items, 3)
+	assert.Equal(t, "a", stmt.items[0].text)
+	assert.Equal(t, "b plus", stmt.items[1].alias)
+	assert.Equal(t, "COUNT(*)", stmt.items[2].text)
+	assert.Equal(t, &binaryExpr{op: "!=", l: &columnRef{name: "a"}, r: &literal{v: "it's"}}, stmt.where)
+	require.Len(t, stmt.orderBy, 2)
+	assert.True(t, stmt.orderBy[0].desc)
+	assert.False(t, stmt.orderBy[1].desc)
+	assert.Equal(t, 10, stmt.limit)
+	assert.Equal(t, 5, stmt.offset)
+}
+
+func TestParse_Precedence(t *testing.T) {
+	stmt, err := parse("SELECT 1 FROM t WHERE NOT a = 1 OR b = 2 AND c = 3 - 4 * 5")
+	require.NoError(t, err)
+
+	eq := func(col string, v expr) expr {
+		return &binaryExpr{op: "=", l: &columnRef{name: col}, r: v}
+	}
+	want := &binaryExpr{
+		op: "OR",
+		l:  &unaryExpr{op: "NOT", x: eq("a", &literal{v: 1.0})},
+		r: &binaryExpr{
+			op: "AND",
+			l:  eq("b", &literal{v: 2.0}),
+			r: eq("c", &binaryExpr{
+				op: "-",
+				l:  &literal{v: 3.0},
+				r:  &binaryExpr{op: "*", l: &literal{v: 4.0}, r: &literal{v: 5.0}},
+			}),
+		},
+	}
+	assert.Equal(t, want, stmt.where)
+}
+
+func TestParse_Rejected(t *testing.T) {
+	tests := []struct {
+		sql    string
+		reason string
+	}{
+		{"", "the statement is empty"},
+		{"DELETE FROM trades", "only SELECT statements are allowed, not DELETE"},
+		{"DROP TABLE trades", "only SELECT statements are allowed, not DROP"},
+		{"SELECT * FROM trades; DELETE FROM trades", "only a single SELECT statement is allowed"},
+		{"SELECT * FROM trades UNION SELECT * FROM trades", "UNION is not supported"},
+		{"SELECT * FROM trades JOIN companies", "joins are not supported"},
+		{"SELECT * FROM trades, companies", "joins are not supported"},
+		{"SELECT * FROM trades WHERE a IN (SELECT a FROM t)", "subqueries are not supported"},
+		{"SELECT DISTINCT a FROM trades", "DISTINCT is not supported"},
+		{"SELECT a FROM trades GROUP BY a HAVING COUNT(*) > 1", "HAVING is not supported"},
+		{"SELECT a FROM trades LIMIT -1", "LIMIT needs a non-negative integer"},
+		{"SELECT a FROM trades LIMIT 1.5", "LIMIT needs a non-negative integer"},
+		{"SELECT LOAD_FILE('x') FROM trades", "unknown function LOAD_FILE"},
+		{"SELECT ROUND() FROM trades", "wrong number of arguments to ROUND"},
+		{"SELECT a FROM trades WHERE a = 'open", "unterminated string"},
+		{"SELECT a FROM trades WHERE a = ?", "unexpected character '?'"},
+		{"SELECT a FROM trades WHERE", "the statement ends unexpectedly"},
+		{"SELECT a trades", "expected FROM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := parse(tt.sql)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrRejected)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+}
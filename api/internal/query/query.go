@@ -0,0 +1,327 @@
+// Package query runs read-only SQL SELECT statements over in-memory tables.
+//
+// The dialect is a small subset of SQL: a single SELECT over one table with
+// WHERE, GROUP BY, ORDER BY, LIMIT and OFFSET, the usual operators and a
+// handful of scalar and aggregate functions. There is no way to express a
+// write, a join or a subquery, so a statement can only ever read the table
+// it names.
+package query
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ErrRejected wraps every error caused by the statement itself rather than
+// by loading or scanning its table
+var ErrRejected = errors.New("query rejected")
+
+// Table is a table a statement can read; cells are raw CSV text
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// TableLoader returns the table with the given lower-case name. It should
+// return an ErrRejected error for names that are not tables.
+type TableLoader func(ctx context.Context, name string) (*Table, error)
+
+// Result is the outcome of a statement
+type Result struct {
+	Table     string          `json:"table"`
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	RowCount  int             `json:"row_count"`
+	Truncated bool            `json:"truncated"` // More rows matched than maxRows
+}
+
+// cancelCheckInterval is how many rows are scanned between context checks
+const cancelCheckInterval = 1024
+
+// sortKey orders the output by an output column or by an expression
+type sortKey struct {
+	column int // Output column, or -1 to evaluate x
+	x      expr
+	desc   bool
+}
+
+// outputRow is a projected row with the values it sorts by
+type outputRow struct {
+	values []interface{}
+	keys   []interface{}
+}
+
+// Run parses sql, loads the table it reads and evaluates it, returning at
+// most maxRows rows
+func Run(ctx context.Context, sql string, maxRows int, load TableLoader) (*Result, error) {
+	stmt, err := parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	table, err := load(ctx, stmt.table)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(map[string]int, len(table.Columns))
+	for i, c := range table.Columns {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if _, dup := header[key]; !dup {
+			header[key] = i
+		}
+	}
+
+	items := stmt.items
+	if stmt.star {
+		items = make([]selectItem, len(table.Columns))
+		for i, c := range table.Columns {
+			items[i] = selectItem{x: &columnRef{name: c, index: i}, text: c}
+		}
+	}
+	grouped := len(stmt.groupBy) > 0
+	columns := make([]string, len(items))
+	for i, item := range items {
+		hasAggregate, err := resolve(item.x, header, true)
+		if err != nil {
+			return nil, err
+		}
+		grouped = grouped || hasAggregate
+		columns[i] = item.text
+		if item.alias != "" {
+			columns[i] = item.alias
+		}
+	}
+	if stmt.where != nil {
+		if _, err := resolve(stmt.where, header, false); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := resolveAll(header, false, stmt.groupBy...); err != nil {
+		return nil, err
+	}
+	keys, err := sortKeys(stmt.orderBy, columns, header, grouped)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without ORDER BY or grouping the scan can stop once the rows to
+	// return, and one more to detect truncation, are in hand
+	want := -1
+	if len(keys) == 0 && !grouped {
+		want = stmt.offset + maxRows + 1
+		if stmt.limit >= 0 && stmt.limit <= maxRows {
+			want = stmt.offset + stmt.limit
+		}
+	}
+
+	var out []outputRow
+	if grouped {
+		out, err = scanGroups(ctx, stmt, table.Rows, items, keys)
+	} else {
+		out, err = scanRows(ctx, stmt, table.Rows, items, keys, want)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) > 0 {
+		sort.SliceStable(out, func(i, j int) bool {
+			return lessRow(out[i], out[j], keys)
+		})
+	}
+
+	if stmt.offset >= len(out) {
+		out = nil
+	} else {
+		out = out[stmt.offset:]
+	}
+	if stmt.limit >= 0 && stmt.limit < len(out) {
+		out = out[:stmt.limit]
+	}
+	truncated := len(out) > maxRows
+	if truncated {
+		out = out[:maxRows]
+	}
+
+	rows := make([][]interface{}, len(out))
+	for i, r := range out {
+		rows[i] = r.values
+	}
+	return &Result{
+		Table:     stmt.table,
+		Columns:   columns,
+		Rows:      rows,
+		RowCount:  len(rows),
+		Truncated: truncated,
+	}, nil
+}
+
+// sortKeys resolves ORDER BY items: an output column alias or a 1-based
+// position first, any other expression against the table
+func sortKeys(orderBy []orderItem, columns []string, header map[string]int, grouped bool) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(orderBy))
+	for _, item := range orderBy {
+		key := sortKey{column: -1, x: item.x, desc: item.desc}
+		switch x := item.x.(type) {
+		case *columnRef:
+			for i, c := range columns {
+				if strings.EqualFold(c, x.name) {
+					key.column = i
+					break
+				}
+			}
+		case *literal:
+			n, ok := x.v.(float64)
+			if !ok || n != math.Trunc(n) || n < 1 || int(n) > len(columns) {
+				return nil, rejectf("ORDER BY position must be between 1 and %d", len(columns))
+			}
+			key.column = int(n) - 1
+		}
+		if key.column < 0 {
+			if _, err := resolve(item.x, header, grouped); err != nil {
+				return nil, err
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// scanRows filters and projects rows one by one, stopping after want rows
+// when want is not negative
+func scanRows(ctx context.Context, stmt *statement, rows [][]string, items []selectItem, keys []sortKey, want int) ([]outputRow, error) {
+	var out []outputRow
+	e := &env{}
+	for i, row := range rows {
+		if want >= 0 && len(out) >= want {
+			break
+		}
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		e.row = row
+		if stmt.where != nil {
+			if t, ok := truth(eval(stmt.where, e)); !ok || !t {
+				continue
+			}
+		}
+		out = append(out, project(items, keys, e))
+	}
+	return out, nil
+}
+
+// scanGroups filters rows into groups and projects one row per group.
+// Without GROUP BY all rows form one group, even when there are none.
+func scanGroups(ctx context.Context, stmt *statement, rows [][]string, items []selectItem, keys []sortKey) ([]outputRow, error) {
+	var (
+		order  []string
+		groups = map[string][][]string{}
+		e      = &env{}
+		key    strings.Builder
+	)
+	if len(stmt.groupBy) == 0 {
+		order = append(order, "")
+		groups[""] = nil
+	}
+	for i, row := range rows {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		e.row = row
+		if stmt.where != nil {
+			if t, ok := truth(eval(stmt.where, e)); !ok || !t {
+				continue
+			}
+		}
+		key.Reset()
+		for _, x := range stmt.groupBy {
+			switch v := eval(x, e).(type) {
+			case nil:
+				key.WriteString("\x00")
+			case float64:
+				key.WriteString("n" + text(v))
+			default:
+				key.WriteString("s" + text(v))
+			}
+			key.WriteString("\x1f")
+		}
+		k := key.String()
+		if _, seen := groups[k]; !seen {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], row)
+	}
+
+	out := make([]outputRow, 0, len(order))
+	for _, k := range order {
+		// Columns outside aggregates take their value from the group's
+		// first row
+		g := &env{group: groups[k]}
+		if len(g.group) > 0 {
+			g.row = g.group[0]
+		}
+		out = append(out, project(items, keys, g))
+	}
+	return out, nil
+}
+
+// project evaluates the output columns and expression sort keys of a row
+func project(items []selectItem, keys []sortKey, e *env) outputRow {
+	r := outputRow{values: make([]interface{}, len(items))}
+	for i, item := range items {
+		r.values[i] = finite(eval(item.x, e))
+	}
+	if len(keys) > 0 {
+		r.keys = make([]interface{}, len(keys))
+		for i, k := range keys {
+			if k.column < 0 {
+				r.keys[i] = eval(k.x, e)
+			}
+		}
+	}
+	return r
+}
+
+// finite maps overflowed arithmetic to NULL; JSON has no infinities
+func finite(v interface{}) interface{} {
+	if n, ok := v.(float64); ok && (math.IsInf(n, 0) || math.IsNaN(n)) {
+		return nil
+	}
+	return v
+}
+
+// lessRow orders rows by the sort keys; NULLs sort first ascending
+func lessRow(a, b outputRow, keys []sortKey) bool {
+	for i, k := range keys {
+		x, y := a.keys[i], b.keys[i]
+		if k.column >= 0 {
+			x, y = a.values[k.column], b.values[k.column]
+		}
+		var c int
+		switch {
+		case x == nil && y == nil:
+			continue
+		case x == nil:
+			c = -1
+		case y == nil:
+			c = 1
+		default:
+			c, _ = compare(x, y)
+		}
+		if c == 0 {
+			continue
+		}
+		if k.desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTables(_ context.Context, name string) (*Table, error) {
+	if name != "trades" {
+		return nil, fmt.Errorf("%w: unknown table %s", ErrRejected, name)
+	}
+	return &Table{
+		Columns: []string{"Date", "Symbol", "ClosePrice", "Volume", "TradingStatus"},
+		Rows: [][]string{
+			{"2024-01-14", "BBOB", "1.250", "2000", "true"},
+			{"2024-01-14", "TASC", "8.000", "500", "true"},
+			{"2024-01-14", "IBSD", "3.000", "", "false"},
+			{"2024-01-15", "BBOB", "1.300", "4000", "true"},
+			{"2024-01-15", "TASC", "7.500", "100", "true"},
+			{"2024-01-15", "IBSD", "3.100", "1000", "true"},
+		},
+	}, nil
+}
+
+func run(t *testing.T, sql string, maxRows int) *Result {
+	t.Helper()
+	res, err := Run(context.Background(), sql, maxRows, testTables)
+	require.NoError(t, err, sql)
+	return res
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		columns []string
+		rows    [][]interface{}
+	}{
+		{
+			name:    "filter and project",
+			sql:     "SELECT symbol, closeprice * 2 AS doubled FROM trades WHERE date = '2024-01-15' AND volume >= 1000",
+			columns: []string{"symbol", "doubled"},
+			rows:    [][]interface{}{{"BBOB", 2.6}, {"IBSD", 6.2}},
+		},
+		{
+			name:    "group and aggregate",
+			sql:     "SELECT Symbol, COUNT(*) n, SUM(Volume) AS volume, MAX(ClosePrice) FROM trades GROUP BY Symbol ORDER BY volume DESC",
+			columns: []string{"Symbol", "n", "volume", "MAX(ClosePrice)"},
+			rows:    [][]interface{}{{"BBOB", 2.0, 6000.0, 1.3}, {"IBSD", 2.0, 1000.0, 3.1}, {"TASC", 2.0, 600.0, 8.0}},
+		},
+		{
+			name:    "aggregate over no rows",
+			sql:     "SELECT COUNT(*), SUM(Volume) FROM trades WHERE Symbol = 'NONE'",
+			columns: []string{"COUNT(*)", "SUM(Volume)"},
+			rows:    [][]interface{}{{0.0, nil}},
+		},
+		{
+			name:    "null handling",
+			sql:     "SELECT Symbol, COALESCE(Volume, 0) FROM trades WHERE Volume IS NULL OR Volume / 0 IS NOT NULL",
+			columns: []string{"Symbol", "COALESCE(Volume, 0)"},
+			rows:    [][]interface{}{{"IBSD", 0.0}},
+		},
+		{
+			name:    "like, in and between",
+			sql:     "SELECT Symbol FROM trades WHERE symbol LIKE 'b%' OR (Symbol IN ('TASC') AND ClosePrice BETWEEN 7 AND 7.9)",
+			columns: []string{"Symbol"},
+			rows:    [][]interface{}{{"BBOB"}, {"BBOB"}, {"TASC"}},
+		},
+		{
+			name:    "order by position with limit and offset",
+			sql:     "SELECT Symbol, ClosePrice FROM trades ORDER BY 2 DESC LIMIT 2 OFFSET 1",
+			columns: []string{"Symbol", "ClosePrice"},
+			rows:    [][]interface{}{{"TASC", 7.5}, {"IBSD", 3.1}},
+		},
+		{
+			name:    "scalar functions",
+			sql:     "SELECT LOWER(Symbol) || '-' || SUBSTR(Date, 6), ROUND(ClosePrice * 1.5, 1), LENGTH(Symbol) FROM trades LIMIT 1",
+			columns: []string{"LOWER(Symbol) || '-' || SUBSTR(Date, 6)", "ROUND(ClosePrice * 1.5, 1)", "LENGTH(Symbol)"},
+			rows:    [][]interface{}{{"bbob-01-14", 1.9, 4.0}},
+		},
+		{
+			name:    "quoted identifiers",
+			sql:     `SELECT "Symbol" AS "the symbol" FROM "trades" WHERE "Date" = '2024-01-15' ORDER BY "the symbol"`,
+			columns: []string{"the symbol"},
+			rows:    [][]interface{}{{"BBOB"}, {"IBSD"}, {"TASC"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := run(t, tt.sql, 100)
+			assert.Equal(t, "trades", res.Table)
+			assert.Equal(t, tt.columns, res.Columns)
+			assert.Equal(t, tt.rows, res.Rows)
+			assert.Equal(t, len(tt.rows), res.RowCount)
+			assert.False(t, res.Truncated)
+		})
+	}
+}
+
+func TestRun_Star(t *testing.T) {
+	res := run(t, "select * from TRADES where symbol = 'TASC' order by date desc;", 100)
+	assert.Equal(t, []string{"Date", "Symbol", "ClosePrice", "Volume", "TradingStatus"}, res.Columns)
+	assert.Equal(t, [][]interface{}{
+		{"2024-01-15", "TASC", 7.5, 100.0, "true"},
+		{"2024-01-14", "TASC", 8.0, 500.0, "true"},
+	}, res.Rows)
+}
+
+func TestRun_MaxRows(t *testing.T) {
+	res := run(t, "SELECT Symbol FROM trades", 4)
+	assert.Equal(t, 4, res.RowCount)
+	assert.True(t, res.Truncated)
+
+	res = run(t, "SELECT Symbol FROM trades ORDER BY Symbol", 4)
+	assert.Equal(t, [][]interface{}{{"BBOB"}, {"BBOB"}, {"IBSD"}, {"IBSD"}}, res.Rows)
+	assert.True(t, res.Truncated)
+
+	// A LIMIT within the cap is not a truncation
+	res = run(t, "SELECT Symbol FROM trades LIMIT 4", 4)
+	assert.Equal(t, 4, res.RowCount)
+	assert.False(t, res.Truncated)
+
+	res = run(t, "SELECT Symbol FROM trades", 6)
+	assert.Equal(t, 6, res.RowCount)
+	assert.False(t, res.Truncated)
+}
+
+func TestRun_Rejected(t *testing.T) {
+	tests := []struct {
+		sql    string
+		reason string
+	}{
+		{"SELECT Nope FROM trades", "no such column: Nope"},
+		{"SELECT * FROM holdings", "unknown table holdings"},
+		{"SELECT Symbol FROM trades WHERE COUNT(*) > 1", "COUNT is not allowed here"},
+		{"SELECT Symbol FROM trades GROUP BY SUM(Volume)", "SUM is not allowed here"},
+		{"SELECT SUM(MAX(Volume)) FROM trades", "MAX is not allowed here"},
+		{"SELECT Symbol FROM trades ORDER BY 3", "ORDER BY position must be between 1 and 1"},
+		{"SELECT Symbol FROM trades ORDER BY COUNT(*)", "COUNT is not allowed here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := Run(context.Background(), tt.sql, 100, testTables)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrRejected)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+}
+
+func TestRun_LoaderError(t *testing.T) {
+	failure := errors.New("disk on fire")
+	_, err := Run(context.Background(), "SELECT * FROM trades", 10, func(context.Context, string) (*Table, error) {
+		return nil, failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.NotErrorIs(t, err, ErrRejected)
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Run(ctx, "SELECT Symbol FROM trades ORDER BY Symbol", 10, testTables)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/query"
+)
+
+// Ad-hoc query limits
+const (
+	DefaultQueryRows = 1000             // rows returned when no limit is given
+	MaxQueryRows     = 10000            // most rows a query may return
+	QueryTimeout     = 10 * time.Second // longest a query may run
+)
+
+// queryTables maps the table names ad-hoc queries use to their report files
+var queryTables = map[string]func(*config.Paths) string{
+	"trades":         (*config.Paths).GetCombinedDataCSVPath,
+	"ticker_summary": (*config.Paths).GetTickerSummaryCSVPath,
+	"indexes":        (*config.Paths).GetIndexCSVPath,
+	"companies":      (*config.Paths).GetCompaniesCSVPath,
+	"exchange_rates": (*config.Paths).GetExchangeRatesCSVPath,
+}
+
+// QueryTables returns the table names ad-hoc queries can read, sorted
+func QueryTables() []string {
+	names := make([]string, 0, len(queryTables))
+	for name := range queryTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query runs a read-only SELECT over one of the report tables of the
+// request's profile, returning at most maxRows rows. Statements the query
+// package rejects wrap query.ErrRejected; a query running past QueryTimeout
+// fails with context.DeadlineExceeded.
+func (ds *DataService) Query(ctx context.Context, sql string, maxRows int) (*query.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	paths := ds.paths.ForContext(ctx)
+	return query.Run(ctx, sql, maxRows, func(ctx context.Context, name string) (*query.Table, error) {
+		path, ok := queryTables[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown table %s; available tables are %s",
+				query.ErrRejected, name, strings.Join(QueryTables(), ", "))
+		}
+		records, err := ds.csvRecords(path(paths))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: table %s has no report yet", ErrFileNotFound, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load table %s: %w", name, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return &query.Table{}, nil
+		}
+		return &query.Table{Columns: records[0], Rows: records[1:]}, nil
+	})
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/query"
+)
+
+func TestDataService_Query(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	paths := &config.Paths{CombinedDataCSV: filepath.Join(dir, "isx_combined_data.csv")}
+	ds := &DataService{
+		config: config.Default(),
+		paths:  paths,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cache:  NewReportCache(0, 0),
+	}
+
+	_, err := ds.Query(ctx, "SELECT * FROM trades", DefaultQueryRows)
+	assert.ErrorIs(t, err, ErrFileNotFound)
+
+	require.NoError(t, os.WriteFile(paths.CombinedDataCSV, []byte(testCombinedData), 0o644))
+
+	res, err := ds.Query(ctx, "SELECT Symbol, SUM(Value) AS value FROM trades WHERE TradingStatus = 'true' GROUP BY Symbol ORDER BY value DESC", DefaultQueryRows)
+	require.NoError(t, err)
+	assert.Equal(t, "trades", res.Table)
+	assert.Equal(t, []string{"Symbol", "value"}, res.Columns)
+	assert.Equal(t, [][]interface{}{{"BBOB", 5150.0}, {"TASC", 4050.0}, {"XXXX", 200.0}}, res.Rows)
+
+	res, err = ds.Query(ctx, "SELECT Date FROM trades", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.RowCount)
+	assert.True(t, res.Truncated)
+
+	_, err = ds.Query(ctx, "SELECT * FROM holdings", DefaultQueryRows)
+	assert.ErrorIs(t, err, query.ErrRejected)
+	assert.ErrorContains(t, err, "available tables are companies, exchange_rates, indexes, ticker_summary, trades")
+}
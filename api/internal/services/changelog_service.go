@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"isxcli/internal/config"
+	"isxcli/internal/migrations"
+)
+
+// ChangelogService serves the embedded release changelog together with the
+// data migrations the active profile still needs after an upgrade
+type ChangelogService struct {
+	paths   *config.Paths
+	version string
+	logger  *slog.Logger
+}
+
+// ChangelogResponse is the changelog as served by the API
+type ChangelogResponse struct {
+	CurrentVersion    string                        `json:"current_version"`
+	Releases          []migrations.Release          `json:"releases"`
+	MigrationRequired bool                          `json:"migration_required"`
+	PendingMigrations []migrations.Migration        `json:"pending_migrations"`
+	AppliedMigrations []migrations.AppliedMigration `json:"applied_migrations"`
+}
+
+// NewChangelogService creates a new changelog service
+func NewChangelogService(paths *config.Paths, version string, logger *slog.Logger) *ChangelogService {
+	return &ChangelogService{
+		paths:   paths,
+		version: version,
+		logger:  logger,
+	}
+}
+
+// Get returns the releases newer than since (all releases when empty) and the
+// migration state of the active profile's data directory
+func (s *ChangelogService) Get(ctx context.Context, since string) (*ChangelogResponse, error) {
+	changelog, err := migrations.LoadChangelog()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := s.paths.ForContext(ctx)
+	pending, err := migrations.Pending(paths)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := migrations.Applied(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ChangelogResponse{
+		CurrentVersion:    s.version,
+		Releases:          changelog.Since(since),
+		MigrationRequired: len(pending) > 0,
+		PendingMigrations: pending,
+		AppliedMigrations: applied,
+	}
+	if resp.Releases == nil {
+		resp.Releases = []migrations.Release{}
+	}
+	if resp.PendingMigrations == nil {
+		resp.PendingMigrations = []migrations.Migration{}
+	}
+	if resp.AppliedMigrations == nil {
+		resp.AppliedMigrations = []migrations.AppliedMigration{}
+	}
+
+	if s.logger != nil {
+		s.logger.DebugContext(ctx, "changelog served",
+			slog.String("since", since),
+			slog.Int("releases", len(resp.Releases)),
+			slog.Int("pending_migrations", len(pending)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+func TestChangelogService_Get(t *testing.T) {
+	dir := t.TempDir()
+	paths := &config.Paths{DataDir: dir, ReportsDir: filepath.Join(dir, "reports")}
+	svc := NewChangelogService(paths, "enhanced-v3.0.0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	changelog, err := svc.Get(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "enhanced-v3.0.0", changelog.CurrentVersion)
+	assert.NotEmpty(t, changelog.Releases)
+	assert.False(t, changelog.MigrationRequired)
+	assert.Empty(t, changelog.PendingMigrations)
+
+	changelog, err = svc.Get(ctx, "2.0.0")
+	require.NoError(t, err)
+	for _, r := range changelog.Releases {
+		assert.NotEqual(t, "2.0.0", r.Version, "only releases after since are listed")
+	}
+
+	combined := filepath.Join(paths.ReportsDir, "combined", "isx_combined_data.csv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(combined), 0755))
+	require.NoError(t, os.WriteFile(combined, []byte("Date,CompanySymbol,TradingStatus\n2025-01-05,BBOB,true\n"), 0644))
+
+	changelog, err = svc.Get(ctx, "")
+	require.NoError(t, err)
+	assert.True(t, changelog.MigrationRequired)
+	require.Len(t, changelog.PendingMigrations, 1)
+	assert.Equal(t, "fill_method_column", changelog.PendingMigrations[0].ID)
+}
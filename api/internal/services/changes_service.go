@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+)
+
+// ChangesService serves the delta manifests written by the processor, so
+// clients refresh only the dates, tickers and files a run changed
+type ChangesService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+}
+
+// NewChangesService creates a new data changes service
+func NewChangesService(paths *config.Paths, logger *slog.Logger) *ChangesService {
+	return &ChangesService{
+		paths:  paths,
+		logger: logger,
+	}
+}
+
+// Since merges the changes of every processing run after since. A zero since
+// returns all retained runs.
+func (s *ChangesService) Since(ctx context.Context, since time.Time) (files.DataChanges, error) {
+	dir := filepath.Join(s.paths.ForContext(ctx).ReportsDir, files.DeltaDirName)
+	changes, err := files.ReadDataChanges(dir, since)
+	if err != nil {
+		return changes, err
+	}
+
+	if s.logger != nil {
+		s.logger.DebugContext(ctx, "data changes read",
+			slog.Time("since", since),
+			slog.Int("runs", changes.Runs),
+			slog.Int("files", len(changes.Files)))
+	}
+	return changes, nil
+}
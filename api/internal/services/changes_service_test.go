@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+)
+
+func TestChangesService_Since(t *testing.T) {
+	dir := t.TempDir()
+	paths := &config.Paths{DataDir: dir, ReportsDir: filepath.Join(dir, "reports")}
+	svc := NewChangesService(paths, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	changes, err := svc.Since(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Zero(t, changes.Runs, "no runs before the processor wrote a manifest")
+
+	run := time.Date(2025, 6, 24, 14, 0, 0, 0, time.UTC)
+	_, err = files.WriteDataDelta(filepath.Join(paths.ReportsDir, files.DeltaDirName),
+		files.NewDataDelta([]string{"daily/isx_daily_2025_06_24.csv", "ticker/BBOB_trading_history.csv"}, nil, false, run))
+	require.NoError(t, err)
+
+	changes, err = svc.Since(ctx, run.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes.Runs)
+	assert.Equal(t, []string{"2025-06-24"}, changes.Dates)
+	assert.Equal(t, []string{"BBOB"}, changes.Tickers)
+	assert.Equal(t, run, changes.Until)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/currency"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/dataprocessing"
+)
+
+// ErrCompanyNotFound is returned when no profile was scraped for a ticker
+var ErrCompanyNotFound = apierrors.Newf(apierrors.NotFound, "company not found")
+
+// Company is a scraped company profile joined with the latest price.
+// MarketCap is listed shares times the last traded price, zero when either
+// is unknown. Both are in Currency, IQD unless converted with InCurrency.
+type Company struct {
+	dataprocessing.CompanyProfile
+	LastPrice float64       `json:"lastPrice,omitempty"`
+	LastDate  string        `json:"lastDate,omitempty"`
+	MarketCap float64       `json:"marketCap,omitempty"`
+	Currency  currency.Code `json:"currency"`
+}
+
+// CompanyService serves company reference data from data/reference/companies.csv,
+// which the scraper writes in companies mode
+type CompanyService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+	rates  *ExchangeRateService
+}
+
+// NewCompanyService creates a new company service
+func NewCompanyService(paths *config.Paths, logger *slog.Logger) *CompanyService {
+	return &CompanyService{
+		paths:  paths,
+		logger: logger,
+	}
+}
+
+// SetExchangeRates sets the rates InCurrency converts with
+func (s *CompanyService) SetExchangeRates(rates *ExchangeRateService) {
+	s.rates = rates
+}
+
+// InCurrency restates the last price and market cap of companies in cur at
+// the rate of each company's last trading day
+func (s *CompanyService) InCurrency(ctx context.Context, companies []Company, cur currency.Code) error {
+	convert, err := s.rates.convertTo(ctx, cur)
+	if err != nil {
+		return err
+	}
+	for i := range companies {
+		c := &companies[i]
+		if c.Currency == cur {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", c.LastDate)
+		if err != nil {
+			date = time.Now()
+		}
+		c.LastPrice = convert(c.LastPrice, date)
+		c.MarketCap = convert(c.MarketCap, date)
+		c.Currency = cur
+	}
+	return nil
+}
+
+// List returns all companies ordered by symbol, optionally limited to one
+// sector (case-insensitive)
+func (s *CompanyService) List(ctx context.Context, sector string) ([]Company, error) {
+	companies, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Company, 0, len(companies))
+	for _, c := range companies {
+		if sector != "" && !strings.EqualFold(c.Sector, sector) {
+			continue
+		}
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Symbol < list[j].Symbol })
+	return list, nil
+}
+
+// Get returns the company for a ticker
+func (s *CompanyService) Get(ctx context.Context, symbol string) (*Company, error) {
+	companies, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := companies[strings.ToUpper(strings.TrimSpace(symbol))]
+	if !ok {
+		return nil, ErrCompanyNotFound
+	}
+	return &c, nil
+}
+
+// EnrichInsights sets sector and market cap on every stock that has a
+// company profile. Missing reference data is logged, not returned, so
+// liquidity output never fails because profiles were not scraped yet.
+func (s *CompanyService) EnrichInsights(ctx context.Context, insights *LiquidityInsights) {
+	if insights == nil || len(insights.AllStocks) == 0 {
+		return
+	}
+
+	companies, err := s.load(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Company data unavailable for liquidity enrichment",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	for i := range insights.AllStocks {
+		stock := &insights.AllStocks[i]
+		if c, ok := companies[strings.ToUpper(stock.Symbol)]; ok {
+			stock.Sector = c.Sector
+			stock.MarketCap = c.MarketCap
+		}
+	}
+}
+
+// load reads the company profiles of the request's profile keyed by symbol,
+// joined with the latest ticker summary prices
+func (s *CompanyService) load(ctx context.Context) (map[string]Company, error) {
+	paths := s.paths.ForContext(ctx)
+	companies := make(map[string]Company)
+
+	profiles, err := dataprocessing.ReadCompaniesCSV(paths.GetCompaniesCSVPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return companies, nil
+		}
+		return nil, fmt.Errorf("failed to load company profiles: %w", err)
+	}
+
+	quotes, err := readTickerQuotes(paths.GetTickerSummaryJSONPath())
+	if err != nil {
+		// Profiles are still useful without prices
+		s.logger.WarnContext(ctx, "Ticker prices unavailable for market cap",
+			slog.String("error", err.Error()))
+		quotes = map[string]tickerQuote{}
+	}
+
+	for _, p := range profiles {
+		c := Company{CompanyProfile: p, Currency: currency.IQD}
+		if q, ok := quotes[p.Symbol]; ok && q.LastPrice > 0 {
+			c.LastPrice = q.LastPrice
+			c.LastDate = q.LastDate
+			c.MarketCap = float64(p.ListedShares) * q.LastPrice
+		}
+		companies[p.Symbol] = c
+	}
+	return companies, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/currency"
+	"isxcli/internal/dataprocessing"
+)
+
+func newTestCompanyService(t *testing.T) *CompanyService {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		DataDir:           dir,
+		TickerSummaryJSON: filepath.Join(dir, "ticker_summary.json"),
+		CompaniesCSV:      filepath.Join(dir, "reference", "companies.csv"),
+	}
+	require.NoError(t, os.WriteFile(paths.TickerSummaryJSON, []byte(`{"tickers": [
+		{"ticker": "BBOB", "company_name": "Bank of Baghdad", "last_price": 1.2, "last_date": "2025-01-05"}
+	]}`), 0644))
+	require.NoError(t, dataprocessing.WriteCompaniesCSV(paths.CompaniesCSV, []dataprocessing.CompanyProfile{
+		{Symbol: "BBOB", Name: "Bank of Baghdad", Sector: "Banks", ListedShares: 1_000_000},
+		{Symbol: "TASC", Name: "Asia Cell", Sector: "Telecom", ListedShares: 2_000_000},
+	}))
+	return NewCompanyService(paths, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestCompanyService_GetAndList(t *testing.T) {
+	ctx := context.Background()
+	service := newTestCompanyService(t)
+
+	c, err := service.Get(ctx, " bbob ")
+	require.NoError(t, err)
+	assert.Equal(t, "Banks", c.Sector)
+	assert.Equal(t, 1.2, c.LastPrice)
+	assert.InDelta(t, 1_200_000, c.MarketCap, 0.001)
+
+	c, err = service.Get(ctx, "TASC")
+	require.NoError(t, err)
+	assert.Zero(t, c.MarketCap, "no price, no market cap")
+
+	_, err = service.Get(ctx, "NOPE")
+	assert.ErrorIs(t, err, ErrCompanyNotFound)
+
+	all, err := service.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "BBOB", all[0].Symbol)
+
+	// 1300 IQD per USD on the last trading day, 2025-01-05
+	require.NoError(t, service.InCurrency(ctx, all, currency.USD))
+	assert.Equal(t, currency.USD, all[0].Currency)
+	assert.InDelta(t, 1_200_000.0/1300, all[0].MarketCap, 1e-6)
+	assert.InDelta(t, 1.2/1300, all[0].LastPrice, 1e-9)
+
+	telecom, err := service.List(ctx, "telecom")
+	require.NoError(t, err)
+	require.Len(t, telecom, 1)
+	assert.Equal(t, "TASC", telecom[0].Symbol)
+}
+
+func TestCompanyService_EnrichInsights(t *testing.T) {
+	service := newTestCompanyService(t)
+	insights := &LiquidityInsights{AllStocks: []StockRecommendation{{Symbol: "BBOB"}, {Symbol: "IBSD"}}}
+
+	service.EnrichInsights(context.Background(), insights)
+	assert.Equal(t, "Banks", insights.AllStocks[0].Sector)
+	assert.InDelta(t, 1_200_000, insights.AllStocks[0].MarketCap, 0.001)
+	assert.Empty(t, insights.AllStocks[1].Sector)
+}
+
+func TestCompanyService_NoReferenceData(t *testing.T) {
+	dir := t.TempDir()
+	service := NewCompanyService(&config.Paths{CompaniesCSV: filepath.Join(dir, "missing.csv")}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	all, err := service.List(context.Background(), "")
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing/analytics"
+	apierrors "isxcli/internal/errors"
+)
+
+// ErrCorrelationsNotFound is returned when indexcsv has not written the correlation report yet
+var ErrCorrelationsNotFound = apierrors.Newf(apierrors.NotFound, "ticker correlations not found")
+
+// Correlations are the return correlations and betas of one lookback window.
+// Matrix follows the order of Tickers; a pair without enough shared returns
+// is null.
+type Correlations struct {
+	GeneratedAt  time.Time                   `json:"generated_at"`
+	Benchmark    string                      `json:"benchmark"`
+	Window       int                         `json:"window"`
+	Windows      []int                       `json:"windows"` // Every window the report has
+	From         time.Time                   `json:"from"`
+	To           time.Time                   `json:"to"`
+	Tickers      []string                    `json:"tickers"`
+	Matrix       [][]*float64                `json:"matrix"`
+	Correlations []analytics.PairCorrelation `json:"correlations"`
+	Betas        []analytics.TickerBeta      `json:"betas"`
+}
+
+// CorrelationService serves the ticker correlations and betas against ISX60
+// written by indexcsv
+type CorrelationService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+}
+
+// NewCorrelationService creates a new correlation service
+func NewCorrelationService(paths *config.Paths, logger *slog.Logger) *CorrelationService {
+	return &CorrelationService{
+		paths:  paths,
+		logger: logger,
+	}
+}
+
+// Get returns the correlations of the lookback window of days sessions, the
+// first window of the report when days is zero. With symbols, only those
+// tickers are returned.
+func (s *CorrelationService) Get(ctx context.Context, days int, symbols []string) (*Correlations, error) {
+	dir := filepath.Join(s.paths.ForContext(ctx).ReportsDir, analytics.CorrelationsDirName)
+	report, err := analytics.ReadCorrelations(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrCorrelationsNotFound
+		}
+		return nil, err
+	}
+	if len(report.Windows) == 0 {
+		return nil, ErrCorrelationsNotFound
+	}
+
+	windows := make([]int, 0, len(report.Windows))
+	for _, w := range report.Windows {
+		windows = append(windows, w.Days)
+	}
+	if days == 0 {
+		days = windows[0]
+	}
+	window, ok := report.Window(days)
+	if !ok {
+		return nil, fmt.Errorf("%w: window %d was not computed; available windows: %s", ErrInvalidInput, days, joinInts(windows))
+	}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[strings.ToUpper(strings.TrimSpace(symbol))] = true
+	}
+	keep := func(symbol string) bool {
+		return len(wanted) == 0 || wanted[symbol]
+	}
+
+	result := &Correlations{
+		GeneratedAt:  report.GeneratedAt,
+		Benchmark:    report.Benchmark,
+		Window:       window.Days,
+		Windows:      windows,
+		From:         window.From,
+		To:           window.To,
+		Tickers:      []string{},
+		Correlations: []analytics.PairCorrelation{},
+		Betas:        []analytics.TickerBeta{},
+	}
+	for _, symbol := range window.Tickers {
+		if keep(symbol) {
+			result.Tickers = append(result.Tickers, symbol)
+		}
+	}
+	for _, c := range window.Correlations {
+		if keep(c.Symbol) && keep(c.Other) {
+			result.Correlations = append(result.Correlations, c)
+		}
+	}
+	for _, b := range window.Betas {
+		if keep(b.Symbol) {
+			result.Betas = append(result.Betas, b)
+		}
+	}
+	result.Matrix = correlationMatrix(result.Tickers, result.Correlations)
+
+	if s.logger != nil {
+		s.logger.DebugContext(ctx, "ticker correlations read",
+			slog.Int("window", window.Days),
+			slog.Int("tickers", len(result.Tickers)))
+	}
+	return result, nil
+}
+
+// correlationMatrix arranges pairwise correlations into a symmetric matrix
+// with ones on the diagonal
+func correlationMatrix(tickers []string, pairs []analytics.PairCorrelation) [][]*float64 {
+	index := make(map[string]int, len(tickers))
+	for i, symbol := range tickers {
+		index[symbol] = i
+	}
+	one := 1.0
+	matrix := make([][]*float64, len(tickers))
+	for i := range matrix {
+		matrix[i] = make([]*float64, len(tickers))
+		matrix[i][i] = &one
+	}
+	for _, p := range pairs {
+		i, iok := index[p.Symbol]
+		j, jok := index[p.Other]
+		if !iok || !jok {
+			continue
+		}
+		corr := p.Correlation
+		matrix[i][j], matrix[j][i] = &corr, &corr
+	}
+	return matrix
+}
+
+func joinInts(values []int) string {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	parts := make([]string, len(sorted))
+	for i, v := range sorted {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing/analytics"
+)
+
+func newTestCorrelationService(t *testing.T, report *analytics.CorrelationReport) *CorrelationService {
+	dir := t.TempDir()
+	paths := &config.Paths{DataDir: dir, ReportsDir: filepath.Join(dir, "reports")}
+	if report != nil {
+		require.NoError(t, analytics.WriteCorrelations(filepath.Join(paths.ReportsDir, analytics.CorrelationsDirName), *report))
+	}
+	return NewCorrelationService(paths, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func testCorrelationReport() *analytics.CorrelationReport {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	return &analytics.CorrelationReport{
+		Benchmark: "ISX60",
+		Windows: []analytics.CorrelationWindow{
+			{
+				Days: 60, From: day(1), To: day(30),
+				Tickers: []string{"BBOB", "IBSD", "TASC"},
+				Correlations: []analytics.PairCorrelation{
+					{Symbol: "BBOB", Other: "IBSD", Correlation: 0.4, Observations: 25},
+					{Symbol: "BBOB", Other: "TASC", Correlation: -0.2, Observations: 30},
+				},
+				Betas: []analytics.TickerBeta{
+					{Symbol: "BBOB", Beta: 1.2, Correlation: 0.7, Observations: 30},
+					{Symbol: "TASC", Beta: 0.5, Correlation: 0.3, Observations: 30},
+				},
+			},
+			{Days: 120, From: day(1), To: day(30), Tickers: []string{}},
+		},
+	}
+}
+
+func TestCorrelationService_Get(t *testing.T) {
+	svc := newTestCorrelationService(t, testCorrelationReport())
+
+	result, err := svc.Get(context.Background(), 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 60, result.Window, "the first window by default")
+	assert.Equal(t, []int{60, 120}, result.Windows)
+	assert.Equal(t, []string{"BBOB", "IBSD", "TASC"}, result.Tickers)
+
+	require.Len(t, result.Matrix, 3)
+	assert.Equal(t, 1.0, *result.Matrix[0][0])
+	assert.Equal(t, 0.4, *result.Matrix[1][0])
+	assert.Equal(t, -0.2, *result.Matrix[0][2])
+	assert.Nil(t, result.Matrix[1][2], "IBSD and TASC share too few returns")
+
+	filtered, err := svc.Get(context.Background(), 60, []string{"bbob", "TASC"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BBOB", "TASC"}, filtered.Tickers)
+	require.Len(t, filtered.Correlations, 1)
+	assert.Equal(t, "TASC", filtered.Correlations[0].Other)
+	assert.Len(t, filtered.Betas, 2)
+
+	_, err = svc.Get(context.Background(), 250, nil)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Contains(t, err.Error(), "available windows: 60, 120")
+}
+
+func TestCorrelationService_NotFound(t *testing.T) {
+	svc := newTestCorrelationService(t, nil)
+
+	_, err := svc.Get(context.Background(), 0, nil)
+	assert.ErrorIs(t, err, ErrCorrelationsNotFound)
+}
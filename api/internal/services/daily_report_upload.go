@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/operations"
+	"isxcli/pkg/contracts/domain"
+)
+
+// ErrReportExists is returned when an uploaded daily report is already in
+// the downloads directory and overwriting was not requested
+var ErrReportExists = apierrors.Newf(apierrors.Conflict, "daily report already downloaded")
+
+// DailyReportUpload describes a daily report stored from an upload
+type DailyReportUpload struct {
+	Filename string `json:"filename"`
+	Date     string `json:"date"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	// Records is the number of trading records the processor will read
+	Records  int  `json:"records"`
+	Replaced bool `json:"replaced"`
+}
+
+// DailyReportUploadService stores daily report workbooks obtained outside
+// the scraper, e.g. by email while the ISX portal is down, in the downloads
+// directory of each profile
+type DailyReportUploadService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+	now    func() time.Time
+	parse  func(path string) (*domain.DailyReport, error)
+}
+
+// NewDailyReportUploadService creates a new daily report upload service
+func NewDailyReportUploadService(paths *config.Paths, logger *slog.Logger) *DailyReportUploadService {
+	return &DailyReportUploadService{
+		paths:  paths,
+		logger: logger,
+		now:    time.Now,
+		parse:  dataprocessing.ParseFile,
+	}
+}
+
+// Store validates an uploaded workbook and saves it under the name the
+// scraper would give it. The trading date comes from date (YYYY-MM-DD) or,
+// when empty, from a filename like "2025 08 25 ISX Daily Report.xlsx". The
+// workbook must parse as a daily report with trading records; an existing
+// report for the date is only replaced when overwrite is set.
+func (s *DailyReportUploadService) Store(ctx context.Context, filename, date string, data []byte, overwrite bool) (*DailyReportUpload, error) {
+	day, err := s.reportDate(filename, date)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := s.paths.ForContext(ctx).DownloadsDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+	name := day.Format("2006 01 02") + dataprocessing.DailyReportFileSuffix
+	target := filepath.Join(dir, name)
+
+	_, err = os.Stat(target)
+	replaced := err == nil
+	if replaced && !overwrite {
+		return nil, fmt.Errorf("%w: %s", ErrReportExists, name)
+	}
+
+	// The temp name keeps the scraper, processor and downloads watcher away
+	// until the workbook has been checked
+	tmp, err := os.CreateTemp(dir, ".upload-*.xlsx.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write upload file: %w", err)
+	}
+
+	report, err := s.parse(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: workbook is not an ISX daily report: %v", ErrInvalidInput, err)
+	}
+	if len(report.Records) == 0 {
+		return nil, fmt.Errorf("%w: workbook has no trading records", ErrInvalidInput)
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		return nil, fmt.Errorf("failed to store daily report: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	upload := &DailyReportUpload{
+		Filename: name,
+		Date:     day.Format("2006-01-02"),
+		Size:     int64(len(data)),
+		SHA256:   hex.EncodeToString(sum[:]),
+		Records:  len(report.Records),
+		Replaced: replaced,
+	}
+	s.logger.InfoContext(ctx, "Stored uploaded daily report",
+		slog.String("filename", name),
+		slog.Int("records", upload.Records),
+		slog.Bool("replaced", replaced))
+	return upload, nil
+}
+
+// ProcessRequest builds the operation that processes an uploaded report. It
+// runs the steps the downloads watcher runs for new reports; processing is
+// incremental, so only changed reports are parsed again.
+func (s *DailyReportUploadService) ProcessRequest(id string, upload *DailyReportUpload) *operations.OperationRequest {
+	return &operations.OperationRequest{
+		ID:   id,
+		Mode: "full",
+		Parameters: map[string]interface{}{
+			"mode":                     "full",
+			"trigger":                  "upload",
+			"reports":                  []string{upload.Filename},
+			operations.ContextKeySteps: append([]string(nil), watchSteps...),
+		},
+	}
+}
+
+// reportDate returns the trading date of an upload
+func (s *DailyReportUploadService) reportDate(filename, date string) (time.Time, error) {
+	var day time.Time
+	var err error
+	if date != "" {
+		day, err = time.Parse("2006-01-02", date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: date must be in YYYY-MM-DD format", ErrInvalidInput)
+		}
+	} else {
+		base := filepath.Base(filename)
+		if !strings.EqualFold(filepath.Ext(base), ".xlsx") || !strings.HasSuffix(strings.ToLower(base), strings.ToLower(dataprocessing.DailyReportFileSuffix)) {
+			return time.Time{}, fmt.Errorf("%w: filename must look like \"2006 01 02%s\" or the date must be given", ErrInvalidInput, dataprocessing.DailyReportFileSuffix)
+		}
+		day, err = time.Parse("2006 01 02", base[:len(base)-len(dataprocessing.DailyReportFileSuffix)])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: filename %q does not start with a valid YYYY MM DD date", ErrInvalidInput, base)
+		}
+	}
+
+	if day.After(s.now()) {
+		return time.Time{}, fmt.Errorf("%w: report date %s is in the future", ErrInvalidInput, day.Format("2006-01-02"))
+	}
+	return day, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"isxcli/internal/config"
+	"isxcli/internal/operations"
+)
+
+// dailyReportWorkbook returns a bulletin with one traded company
+func dailyReportWorkbook(t *testing.T) []byte {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+	rows := [][]interface{}{
+		{"Iraq Stock Exchange"},
+		{"Company Name", "Code", "Opening Price", "Highest Price", "Lowest Price", "Closing Price", "Prev Closing Price", "No. of Trades", "Traded Volume", "Traded Value"},
+		{"Bank of Baghdad", "BBOB", 1.2, 1.25, 1.18, 1.22, 1.2, 42, "1500000", "1830000"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, f.SetSheetRow("Sheet1", cell, &row))
+	}
+
+	buf, err := f.WriteToBuffer()
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func newTestUploadService(t *testing.T) (*DailyReportUploadService, string) {
+	dir := t.TempDir()
+	service := NewDailyReportUploadService(&config.Paths{DownloadsDir: dir}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.now = func() time.Time { return time.Date(2025, 8, 26, 12, 0, 0, 0, time.UTC) }
+	return service, dir
+}
+
+func TestDailyReportUploadService_Store(t *testing.T) {
+	ctx := context.Background()
+	service, dir := newTestUploadService(t)
+	data := dailyReportWorkbook(t)
+
+	upload, err := service.Store(ctx, "2025 08 25 ISX Daily Report.xlsx", "", data, false)
+	require.NoError(t, err)
+	assert.Equal(t, "2025 08 25 ISX Daily Report.xlsx", upload.Filename)
+	assert.Equal(t, "2025-08-25", upload.Date)
+	assert.Equal(t, 1, upload.Records)
+	assert.Len(t, upload.SHA256, 64)
+	assert.False(t, upload.Replaced)
+
+	stored, err := os.ReadFile(filepath.Join(dir, upload.Filename))
+	require.NoError(t, err)
+	assert.Equal(t, data, stored)
+
+	_, err = service.Store(ctx, "bulletin.xlsx", "2025-08-25", data, false)
+	assert.ErrorIs(t, err, ErrReportExists)
+
+	upload, err = service.Store(ctx, "bulletin.xlsx", "2025-08-25", data, true)
+	require.NoError(t, err)
+	assert.True(t, upload.Replaced)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files are left behind")
+}
+
+func TestDailyReportUploadService_StoreRejects(t *testing.T) {
+	ctx := context.Background()
+	service, dir := newTestUploadService(t)
+	data := dailyReportWorkbook(t)
+
+	tests := []struct {
+		name     string
+		filename string
+		date     string
+		data     []byte
+	}{
+		{"unrecognized filename", "bulletin.xlsx", "", data},
+		{"invalid filename date", "2025 13 40 ISX Daily Report.xlsx", "", data},
+		{"invalid date", "bulletin.xlsx", "25/08/2025", data},
+		{"future date", "2025 08 27 ISX Daily Report.xlsx", "", data},
+		{"not a workbook", "2025 08 25 ISX Daily Report.xlsx", "", []byte("PK\x03\x04 not really")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Store(ctx, tt.filename, tt.date, tt.data, false)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDailyReportUploadService_ProcessRequest(t *testing.T) {
+	service, _ := newTestUploadService(t)
+
+	request := service.ProcessRequest("op-1", &DailyReportUpload{Filename: "2025 08 25 ISX Daily Report.xlsx"})
+	assert.Equal(t, "op-1", request.ID)
+	assert.Equal(t, "upload", request.Parameters["trigger"])
+	assert.Equal(t, []string{"2025 08 25 ISX Daily Report.xlsx"}, request.Parameters["reports"])
+	assert.Equal(t, []string{operations.StageIDProcessing, operations.StageIDIndices, operations.StageIDLiquidity},
+		request.Parameters[operations.ContextKeySteps])
+}
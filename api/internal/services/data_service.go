@@ -3,7 +3,7 @@ package services
 import (
 	"context"
 	"encoding/csv"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -14,9 +14,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+	"isxcli/internal/exporter"
+	"isxcli/internal/i18n"
 	"isxcli/pkg/contracts/domain"
 )
 
@@ -25,6 +29,10 @@ type DataService struct {
 	config *config.Config
 	paths  *config.Paths
 	logger *slog.Logger
+
+	historyIndex sync.Map // Ticker history path -> *tickerHistoryIndex
+	cache        *ReportCache
+	rates        *ExchangeRateService
 }
 
 // NewDataService creates a new data service using default logger
@@ -51,16 +59,56 @@ func NewDataServiceWithLogger(cfg *config.Config, logger *slog.Logger) (*DataSer
 		slog.String("reports_dir", paths.ReportsDir),
 		slog.String("downloads_dir", paths.DownloadsDir))
 	
+	var cacheConfig config.CacheConfig
+	var currencyConfig config.CurrencyConfig
+	if cfg != nil {
+		cacheConfig = cfg.Cache
+		currencyConfig = cfg.Currency
+	}
+
 	return &DataService{
 		config: cfg,
 		paths:  paths,
 		logger: logger,
+		cache:  NewReportCache(cacheConfig.ReportTTL, cacheConfig.ReportMaxEntries),
+		rates:  NewExchangeRateService(paths, currencyConfig, logger),
 	}, nil
 }
 
+// ExchangeRates returns the service converting values to USD
+func (ds *DataService) ExchangeRates() *ExchangeRateService {
+	return ds.rates
+}
+
+// InvalidateCache drops all cached report data; called when the pipeline
+// rewrites reports
+func (ds *DataService) InvalidateCache() {
+	ds.cache.Invalidate()
+	ds.historyIndex.Range(func(key, _ interface{}) bool {
+		ds.historyIndex.Delete(key)
+		return true
+	})
+}
+
+// CacheStats returns the report cache counters
+func (ds *DataService) CacheStats() ReportCacheStats {
+	return ds.cache.Stats()
+}
+
+// csvRecords returns the records of a CSV file, header first, from the report
+// cache. The records are shared and must not be modified.
+func (ds *DataService) csvRecords(path string) ([][]string, error) {
+	value, err := ds.cache.Load("csv", path, readCSVRecords)
+	if err != nil {
+		return nil, err
+	}
+	return value.([][]string), nil
+}
+
 // GetReports returns a list of available reports with categorization
 func (ds *DataService) GetReports(ctx context.Context) ([]map[string]interface{}, error) {
-	reportsDir := ds.paths.ReportsDir
+	paths := ds.paths.ForContext(ctx)
+	reportsDir := paths.ReportsDir
 	
 	// Use injected logger
 	ds.logger.Debug("GetReports: scanning directory",
@@ -192,22 +240,22 @@ func (ds *DataService) GetReports(ctx context.Context) ([]map[string]interface{}
 
 // GetTickers returns ticker information
 func (ds *DataService) GetTickers(ctx context.Context) (interface{}, error) {
-	tickerFile := ds.paths.GetTickerSummaryJSONPath()
+	paths := ds.paths.ForContext(ctx)
+	tickerFile := paths.GetTickerSummaryJSONPath()
 	
 	// Use injected logger
 	ds.logger.Debug("GetTickers: reading ticker summary",
 		slog.String("ticker_file", tickerFile))
 	
-	data, err := os.ReadFile(tickerFile)
+	tickerData, err := ds.cache.Load("json", tickerFile, readJSONValue)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []interface{}{}, nil
 		}
-		return nil, fmt.Errorf("failed to read ticker summary: %w", err)
-	}
-
-	var tickerData interface{}
-	if err := json.Unmarshal(data, &tickerData); err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return nil, fmt.Errorf("failed to read ticker summary: %w", err)
+		}
 		return nil, fmt.Errorf("failed to parse ticker summary: %w", err)
 	}
 
@@ -216,13 +264,14 @@ func (ds *DataService) GetTickers(ctx context.Context) (interface{}, error) {
 
 // GetIndices returns market indices data
 func (ds *DataService) GetIndices(ctx context.Context) (map[string]interface{}, error) {
-	indicesFile := ds.paths.GetIndexCSVPath()
+	paths := ds.paths.ForContext(ctx)
+	indicesFile := paths.GetIndexCSVPath()
 	
 	// Use injected logger
 	ds.logger.Debug("GetIndices: reading indices file",
 		slog.String("indices_file", indicesFile))
 	
-	file, err := os.Open(indicesFile)
+	records, err := ds.csvRecords(indicesFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return map[string]interface{}{
@@ -233,15 +282,12 @@ func (ds *DataService) GetIndices(ctx context.Context) (map[string]interface{},
 		}
 		return nil, fmt.Errorf("failed to open indices file: %w", err)
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	
 	// Read header
-	header, err := reader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("failed to read CSV header: %w", io.EOF)
 	}
+	header := records[0]
 	
 	// Validate header
 	if len(header) < 2 || header[0] != "Date" || header[1] != "ISX60" {
@@ -253,15 +299,7 @@ func (ds *DataService) GetIndices(ctx context.Context) (map[string]interface{},
 	var isx15Values []float64
 	
 	// Read data rows
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read CSV row: %w", err)
-		}
-		
+	for _, record := range records[1:] {
 		if len(record) < 2 {
 			continue // Skip invalid rows
 		}
@@ -297,12 +335,32 @@ func (ds *DataService) GetIndices(ctx context.Context) (map[string]interface{},
 	}
 	
 	return map[string]interface{}{
-		"dates": dates,
-		"isx60": isx60Values,
-		"isx15": isx15Values,
+		"dates":  dates,
+		"isx60":  isx60Values,
+		"isx15":  isx15Values,
+		"alerts": ds.indexAlerts(ctx),
 	}, nil
 }
 
+// indexAlerts returns dates flagged as probable index rebalancing or divisor
+// changes by indexcsv. Missing analytics yield an empty list.
+func (ds *DataService) indexAlerts(ctx context.Context) []dataprocessing.IndexReturnRow {
+	paths := ds.paths.ForContext(ctx)
+	alerts := []dataprocessing.IndexReturnRow{}
+
+	rows, err := dataprocessing.ReadIndexAnalyticsCSV(paths.GetIndexAnalyticsCSVPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logDataError(ctx, "read_index_analytics", "Failed to read index analytics",
+				slog.String("error", err.Error()),
+			)
+		}
+		return alerts
+	}
+
+	return append(alerts, dataprocessing.IndexAlerts(rows)...)
+}
+
 // GetFiles returns file listings from different directories
 func (ds *DataService) GetFiles(ctx context.Context) (map[string]interface{}, error) {
 	result := map[string]interface{}{
@@ -313,15 +371,17 @@ func (ds *DataService) GetFiles(ctx context.Context) (map[string]interface{}, er
 		"last_modified": time.Time{},
 	}
 
+	paths := ds.paths.ForContext(ctx)
+
 	// List downloaded Excel files
-	if err := ds.listFiles("downloads", ".xlsx", result); err != nil {
+	if err := listFilesIn(paths, "downloads", ".xlsx", result); err != nil {
 		logDataError(ctx, "list_files", "Failed to list downloads",
 			slog.String("error", err.Error()),
 		)
 	}
 
 	// List report files
-	if err := ds.listFiles("reports", ".csv", result); err != nil {
+	if err := listFilesIn(paths, "reports", ".csv", result); err != nil {
 		logDataError(ctx, "list_files", "Failed to list reports",
 			slog.String("error", err.Error()),
 		)
@@ -354,11 +414,12 @@ func (ds *DataService) GetMarketMovers(ctx context.Context, period, limit, minVo
 
 // GetTickerChart returns chart data for a specific ticker
 func (ds *DataService) GetTickerChart(ctx context.Context, ticker string) (map[string]interface{}, error) {
+	paths := ds.paths.ForContext(ctx)
 	if ticker == "" {
 		return nil, fmt.Errorf("ticker parameter required")
 	}
 
-	tickerFile := ds.paths.GetTickerDailyCSVPath(ticker)
+	tickerFile := paths.GetTickerDailyCSVPath(ticker)
 	
 	logger := slog.Default()
 	if logger != nil {
@@ -385,9 +446,158 @@ func (ds *DataService) GetTickerChart(ctx context.Context, ticker string) (map[s
 	}, nil
 }
 
+// GetTickerIntraday returns intraday bars for a ticker on the given date (YYYY-MM-DD).
+// When date is empty the most recent day with intraday data is used.
+func (ds *DataService) GetTickerIntraday(ctx context.Context, ticker, date string) (map[string]interface{}, error) {
+	paths := ds.paths.ForContext(ctx)
+	if ticker == "" {
+		return nil, fmt.Errorf("ticker parameter required")
+	}
+	ticker = strings.ToUpper(ticker)
+	tickerDir := filepath.Join(paths.IntradayDir, ticker)
+
+	entries, err := os.ReadDir(tickerDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoIntradayData
+		}
+		return nil, fmt.Errorf("failed to read intraday directory: %w", err)
+	}
+
+	var availableDates []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
+			continue
+		}
+		availableDates = append(availableDates, strings.TrimSuffix(entry.Name(), ".csv"))
+	}
+	if len(availableDates) == 0 {
+		return nil, ErrNoIntradayData
+	}
+	sort.Strings(availableDates)
+
+	if date == "" {
+		date = availableDates[len(availableDates)-1]
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidInput)
+	}
+
+	intradayFile := paths.GetIntradayPath(ticker, day)
+	ds.logger.Debug("GetTickerIntraday: reading intraday bars",
+		slog.String("ticker", ticker),
+		slog.String("intraday_file", intradayFile))
+
+	if _, err := os.Stat(intradayFile); os.IsNotExist(err) {
+		return nil, ErrNoIntradayData
+	}
+
+	bars, err := dataprocessing.ReadIntradayCSV(intradayFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"ticker":          ticker,
+		"date":            date,
+		"bars":            bars,
+		"available_dates": availableDates,
+	}, nil
+}
+
+// GetMarketSnapshot returns the latest preliminary mid-session snapshot for a
+// date (YYYY-MM-DD), optionally narrowed to one symbol. When date is empty the
+// most recent day with snapshots is used. Snapshots are never merged with the
+// end-of-day records; "superseded" tells clients the daily report has landed.
+func (ds *DataService) GetMarketSnapshot(ctx context.Context, date, symbol string) (map[string]interface{}, error) {
+	paths := ds.paths.ForContext(ctx)
+
+	entries, err := os.ReadDir(paths.SnapshotsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSnapshotData
+		}
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	var availableDates []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			availableDates = append(availableDates, entry.Name())
+		}
+	}
+	if len(availableDates) == 0 {
+		return nil, ErrNoSnapshotData
+	}
+	sort.Strings(availableDates)
+
+	if date == "" {
+		date = availableDates[len(availableDates)-1]
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidInput)
+	}
+
+	dayDir := filepath.Join(paths.SnapshotsDir, date)
+	files, err := filepath.Glob(filepath.Join(dayDir, "*.csv"))
+	if err != nil || len(files) == 0 {
+		return nil, ErrNoSnapshotData
+	}
+	sort.Strings(files)
+
+	snapshotTimes := make([]string, 0, len(files))
+	for _, f := range files {
+		hhmm := strings.TrimSuffix(filepath.Base(f), ".csv")
+		if len(hhmm) == 4 {
+			hhmm = hhmm[:2] + ":" + hhmm[2:]
+		}
+		snapshotTimes = append(snapshotTimes, hhmm)
+	}
+
+	latest := files[len(files)-1]
+	ds.logger.Debug("GetMarketSnapshot: reading snapshot",
+		slog.String("date", date),
+		slog.String("snapshot_file", latest))
+
+	snapshot, err := dataprocessing.ReadSnapshotCSV(latest)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol != "" {
+		symbol = strings.ToUpper(symbol)
+		filtered := snapshot.Records[:0]
+		for _, rec := range snapshot.Records {
+			if rec.CompanySymbol == symbol {
+				filtered = append(filtered, rec)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, ErrNoSnapshotData
+		}
+		snapshot.Records = filtered
+	}
+
+	_, statErr := os.Stat(paths.GetDailyCSVPath(day))
+
+	return map[string]interface{}{
+		"date":            date,
+		"as_of":           snapshot.AsOf.Format("15:04"),
+		"source":          snapshot.Source,
+		"preliminary":     true,
+		"superseded":      statErr == nil,
+		"records":         snapshot.Records,
+		"snapshot_times":  snapshotTimes,
+		"available_dates": availableDates,
+	}, nil
+}
+
 // GetDailyReport returns data for a specific date
 func (ds *DataService) GetDailyReport(ctx context.Context, date time.Time) ([]map[string]interface{}, error) {
-	dailyFile := ds.paths.GetDailyCSVPath(date)
+	paths := ds.paths.ForContext(ctx)
+	dailyFile := paths.GetDailyCSVPath(date)
 	
 	logger := slog.Default()
 	if logger != nil {
@@ -396,34 +606,24 @@ func (ds *DataService) GetDailyReport(ctx context.Context, date time.Time) ([]ma
 			slog.String("daily_file", dailyFile))
 	}
 	
-	file, err := os.Open(dailyFile)
+	records, err := ds.csvRecords(dailyFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []map[string]interface{}{}, nil
 		}
 		return nil, fmt.Errorf("failed to open daily report: %w", err)
 	}
-	defer file.Close()
-	
-	reader := csv.NewReader(file)
-	
+
 	// Read header
-	header, err := reader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("failed to read CSV header: %w", io.EOF)
 	}
+	header := records[0]
 	
 	var results []map[string]interface{}
 	
 	// Read data rows
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read CSV row: %w", err)
-		}
+	for _, record := range records[1:] {
 		
 		// Convert record to map
 		row := make(map[string]interface{})
@@ -441,14 +641,56 @@ func (ds *DataService) GetDailyReport(ctx context.Context, date time.Time) ([]ma
 
 // DownloadFile serves a file for download (supports nested paths)
 func (ds *DataService) DownloadFile(ctx context.Context, w http.ResponseWriter, r *http.Request, fileType, filename string) error {
+	absFilePath, err := ds.resolveDownloadPath(ctx, fileType, filename)
+	if err != nil {
+		return err
+	}
+
+	// Set headers for download
+	// Use just the filename (not the full path) in the header
+	baseFilename := filepath.Base(absFilePath)
+
+	// ?lang=ar or ?digits=arabic asks for a localized copy of a CSV report
+	// for spreadsheets; without them the stored file is served, since the
+	// frontend parses it by English column names
+	query := r.URL.Query()
+	if lang, digits := query.Get("lang"), query.Get("digits"); (lang != "" || digits != "") && strings.EqualFold(filepath.Ext(absFilePath), ".csv") {
+		locale, err := i18n.NewLocale(lang, digits)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		file, err := os.Open(absFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", baseFilename, err)
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", baseFilename))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Language", string(locale.Language))
+		return exporter.LocalizeCSV(w, file, locale)
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", baseFilename))
+	w.Header().Set("Content-Type", "application/octet-stream")
+
+	// Serve the file
+	http.ServeFile(w, r, absFilePath)
+	return nil
+}
+
+// resolveDownloadPath maps a download type and (possibly nested) filename to an
+// absolute path inside the profile's downloads or reports directory
+func (ds *DataService) resolveDownloadPath(ctx context.Context, fileType, filename string) (string, error) {
+	paths := ds.paths.ForContext(ctx)
 	var dir string
 	switch fileType {
 	case "downloads":
-		dir = ds.paths.DownloadsDir
+		dir = paths.DownloadsDir
 	case "reports", "report", "csv": // Support multiple aliases for reports
-		dir = ds.paths.ReportsDir
+		dir = paths.ReportsDir
 	default:
-		return fmt.Errorf("invalid file type: %s", fileType)
+		return "", fmt.Errorf("%w: %s", ErrInvalidFileType, fileType)
 	}
 	
 	// Use injected logger
@@ -477,7 +719,7 @@ func (ds *DataService) DownloadFile(ctx context.Context, w http.ResponseWriter,
 		ds.logger.Error("Failed to resolve absolute path",
 			slog.String("error", err.Error()),
 			slog.String("file_path", filePath))
-		return fmt.Errorf("invalid file path")
+		return "", ErrInvalidFilePath
 	}
 	
 	absDir, err := filepath.Abs(dir)
@@ -485,20 +727,21 @@ func (ds *DataService) DownloadFile(ctx context.Context, w http.ResponseWriter,
 		ds.logger.Error("Failed to resolve directory path",
 			slog.String("error", err.Error()),
 			slog.String("dir", dir))
-		return fmt.Errorf("invalid directory path")
+		return "", fmt.Errorf("invalid directory path")
 	}
 	
 	// Normalize paths for comparison (important on Windows)
 	absFilePath = filepath.Clean(absFilePath)
 	absDir = filepath.Clean(absDir)
 	
-	// Ensure the resolved path is within the allowed directory
-	if !strings.HasPrefix(absFilePath, absDir) {
+	// Ensure the resolved path is within the allowed directory; the
+	// separator keeps siblings such as reports-old from matching reports
+	if !strings.HasPrefix(absFilePath, absDir+string(filepath.Separator)) {
 		ds.logger.Warn("Attempted directory traversal",
 			slog.String("requested_path", filename),
 			slog.String("resolved_path", absFilePath),
 			slog.String("base_dir", absDir))
-		return fmt.Errorf("invalid file path")
+		return "", ErrInvalidFilePath
 	}
 	
 	// Check if file exists
@@ -508,30 +751,27 @@ func (ds *DataService) DownloadFile(ctx context.Context, w http.ResponseWriter,
 			slog.String("cleaned_file", cleanedFilename),
 			slog.String("full_path", absFilePath),
 			slog.String("base_dir", dir))
-		return fmt.Errorf("file not found")
+		return "", ErrFileNotFound
 	}
 
-	// Set headers for download
-	// Use just the filename (not the full path) in the header
-	baseFilename := filepath.Base(cleanedFilename)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", baseFilename))
-	w.Header().Set("Content-Type", "application/octet-stream")
-
-	// Serve the file
-	http.ServeFile(w, r, absFilePath)
-	return nil
+	return absFilePath, nil
 }
 
 // listFiles lists files in a directory with filtering
 func (ds *DataService) listFiles(dirName, extension string, result map[string]interface{}) error {
+	return listFilesIn(ds.paths, dirName, extension, result)
+}
+
+// listFilesIn lists files in a directory of the given profile paths
+func listFilesIn(paths *config.Paths, dirName, extension string, result map[string]interface{}) error {
 	var dir string
 	switch dirName {
 	case "downloads":
-		dir = ds.paths.DownloadsDir
+		dir = paths.DownloadsDir
 	case "reports":
-		dir = ds.paths.ReportsDir
+		dir = paths.ReportsDir
 	default:
-		dir = filepath.Join(ds.paths.DataDir, dirName)
+		dir = filepath.Join(paths.DataDir, dirName)
 	}
 	
 	logger := slog.Default()
@@ -626,29 +866,27 @@ func (ds *DataService) listFiles(dirName, extension string, result map[string]in
 
 // GetSafeTradingLimits returns safe trading limits for a ticker based on liquidity metrics
 func (ds *DataService) GetSafeTradingLimits(ctx context.Context, ticker string) (interface{}, error) {
+	paths := ds.paths.ForContext(ctx)
 	// Read the latest liquidity report
-	liquidityReportPath := filepath.Join(ds.paths.ReportsDir, "liquidity_report.csv")
+	liquidityReportPath := filepath.Join(paths.ReportsDir, "liquidity_report.csv")
 	
 	ds.logger.Debug("GetSafeTradingLimits: reading liquidity report",
 		slog.String("ticker", ticker),
 		slog.String("report_path", liquidityReportPath))
 	
-	file, err := os.Open(liquidityReportPath)
+	records, err := ds.csvRecords(liquidityReportPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, ErrTickerNotFound
 		}
 		return nil, fmt.Errorf("failed to open liquidity report: %w", err)
 	}
-	defer file.Close()
-	
-	reader := csv.NewReader(file)
-	
+
 	// Read header
-	header, err := reader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("failed to read CSV header: %w", io.EOF)
 	}
+	header := records[0]
 	
 	// Find column indices for safe trading values
 	symbolIdx := -1
@@ -684,14 +922,7 @@ func (ds *DataService) GetSafeTradingLimits(ctx context.Context, ticker string)
 	// Read data rows and find the ticker
 	var latestMetrics map[string]interface{}
 	
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read CSV row: %w", err)
-		}
+	for _, record := range records[1:] {
 		
 		// Check if this is our ticker
 		if symbolIdx >= 0 && symbolIdx < len(record) && record[symbolIdx] == ticker {
@@ -843,6 +1074,7 @@ func (ds *DataService) CreateTradeSchedule(ctx context.Context, ticker string, t
 
 // GetHistoricalData retrieves historical trading data for a ticker within a date range
 func (ds *DataService) GetHistoricalData(ctx context.Context, ticker string, startDate, endDate time.Time) ([]domain.TradeRecord, error) {
+	paths := ds.paths.ForContext(ctx)
 	ds.logger.InfoContext(ctx, "loading historical data",
 		"ticker", ticker,
 		"start_date", startDate.Format("2006-01-02"),
@@ -852,7 +1084,7 @@ func (ds *DataService) GetHistoricalData(ctx context.Context, ticker string, sta
 	var records []domain.TradeRecord
 	
 	// Look for ticker-specific CSV file first
-	tickerFile := filepath.Join(ds.paths.ReportsDir, fmt.Sprintf("%s_daily.csv", ticker))
+	tickerFile := filepath.Join(paths.ReportsDir, fmt.Sprintf("%s_daily.csv", ticker))
 	if _, err := os.Stat(tickerFile); err == nil {
 		// Load from ticker-specific file
 		file, err := os.Open(tickerFile)
@@ -915,7 +1147,7 @@ func (ds *DataService) GetHistoricalData(ctx context.Context, ticker string, sta
 	} else {
 		// Fallback to daily report files
 		// List all CSV files in reports directory
-		files, err := os.ReadDir(ds.paths.ReportsDir)
+		files, err := os.ReadDir(paths.ReportsDir)
 		if err != nil {
 			return nil, fmt.Errorf("read reports directory: %w", err)
 		}
@@ -942,7 +1174,7 @@ func (ds *DataService) GetHistoricalData(ctx context.Context, ticker string, sta
 			}
 
 			// Load file and look for ticker
-			filePath := filepath.Join(ds.paths.ReportsDir, file.Name())
+			filePath := filepath.Join(paths.ReportsDir, file.Name())
 			fileRecords, err := ds.loadDailyReportFile(ctx, filePath, ticker)
 			if err != nil {
 				ds.logger.WarnContext(ctx, "failed to load daily report",
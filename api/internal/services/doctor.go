@@ -0,0 +1,428 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/browser"
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+	"isxcli/internal/operations"
+)
+
+// DoctorStatus is the outcome of a pre-flight check
+type DoctorStatus string
+
+// Doctor statuses, from best to worst
+const (
+	DoctorPass DoctorStatus = "pass"
+	DoctorWarn DoctorStatus = "warn"
+	DoctorFail DoctorStatus = "fail"
+)
+
+// Doctor check names
+const (
+	DoctorCheckChrome      = "chrome"
+	DoctorCheckDataDirs    = "data_dirs"
+	DoctorCheckISX         = "isx_connectivity"
+	DoctorCheckLicense     = "license_server"
+	DoctorCheckClock       = "clock_skew"
+	DoctorCheckDisk        = "disk_space"
+	DoctorCheckExecutables = "executables"
+)
+
+// DefaultMaxClockSkew is the clock difference to the remote servers above
+// which license validation and signed responses may be rejected
+const DefaultMaxClockSkew = 2 * time.Minute
+
+// DoctorCheck is one pre-flight diagnostic. Fix says what to do when the
+// check does not pass.
+type DoctorCheck struct {
+	Name    string                 `json:"name"`
+	Status  DoctorStatus           `json:"status"`
+	Message string                 `json:"message"`
+	Fix     string                 `json:"fix,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// DoctorReport is the result of a doctor run. Status is the worst check.
+type DoctorReport struct {
+	Status    DoctorStatus  `json:"status"`
+	Timestamp time.Time     `json:"timestamp"`
+	Checks    []DoctorCheck `json:"checks"`
+}
+
+// DoctorOptions are the dependencies of the pre-flight checks. Zero values
+// use the defaults; DoctorOptionsFromConfig fills them from the configuration.
+type DoctorOptions struct {
+	Paths        *config.Paths
+	Thresholds   config.HealthConfig // Disk thresholds
+	Client       *http.Client        // Defaults to a 10s timeout through Proxy
+	Proxy        *url.URL            // scraper.proxy; nil uses HTTP_PROXY/HTTPS_PROXY
+	ISXURL       string              // Defaults to config.ISXWebsiteURL
+	LicenseURL   string              // Defaults to the Apps Script URL the build embeds
+	MaxClockSkew time.Duration
+
+	// Browser is the managed Chromium used when no Chrome is installed
+	Browser config.BrowserConfig
+
+	// findChrome locates a Chrome or Chromium executable; tests replace it
+	findChrome func() (string, error)
+	now        func() time.Time
+}
+
+// DoctorOptionsFromConfig returns the options for the configured paths, disk
+// thresholds and scraper proxy
+func DoctorOptionsFromConfig(cfg *config.Config, paths *config.Paths) (DoctorOptions, error) {
+	opts := DoctorOptions{Paths: paths}
+	if cfg == nil {
+		return opts, nil
+	}
+	proxy, err := cfg.Scraper.ProxyURL()
+	if err != nil {
+		return opts, err
+	}
+	opts.Thresholds = cfg.Health
+	opts.Proxy = proxy
+	opts.Browser = cfg.Scraper.Browser
+	return opts, nil
+}
+
+// Doctor runs the pre-flight environment checks behind the doctor command
+// and /api/v1/system/doctor
+type Doctor struct {
+	opts DoctorOptions
+}
+
+// NewDoctor creates a doctor with the given options
+func NewDoctor(opts DoctorOptions) *Doctor {
+	if opts.Client == nil {
+		proxy := http.ProxyFromEnvironment
+		if opts.Proxy != nil {
+			proxy = http.ProxyURL(opts.Proxy)
+		}
+		opts.Client = &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{Proxy: proxy},
+		}
+	}
+	if opts.ISXURL == "" {
+		opts.ISXURL = config.ISXWebsiteURL
+	}
+	if opts.LicenseURL == "" {
+		opts.LicenseURL = config.GetAppsScriptURL()
+	}
+	if opts.MaxClockSkew <= 0 {
+		opts.MaxClockSkew = DefaultMaxClockSkew
+	}
+	opts.Thresholds = withHealthDefaults(opts.Thresholds)
+	if opts.findChrome == nil {
+		opts.findChrome = browser.FindSystem
+	}
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+	return &Doctor{opts: opts}
+}
+
+// Run performs every check. The two network checks run in parallel and their
+// Date headers feed the clock skew check.
+func (d *Doctor) Run(ctx context.Context) DoctorReport {
+	var (
+		wg               sync.WaitGroup
+		isx, license     DoctorCheck
+		isxAt, licenseAt remoteTime
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		isx, isxAt = d.checkURL(ctx, DoctorCheckISX, d.opts.ISXURL, d.opts.ISXURL,
+			"Check the internet connection, proxy (scraper.proxy) and firewall; the scraper cannot download reports without it")
+	}()
+	go func() {
+		defer wg.Done()
+		license, licenseAt = d.checkLicenseServer(ctx)
+	}()
+
+	report := DoctorReport{Timestamp: d.opts.now()}
+	report.Checks = append(report.Checks, d.checkChrome(), d.checkDataDirs())
+	wg.Wait()
+	report.Checks = append(report.Checks,
+		isx,
+		license,
+		d.checkClock(isxAt, licenseAt),
+		d.checkDisk(),
+		d.checkExecutables(),
+	)
+
+	report.Status = DoctorPass
+	for _, check := range report.Checks {
+		if doctorRank[check.Status] > doctorRank[report.Status] {
+			report.Status = check.Status
+		}
+	}
+	return report
+}
+
+var doctorRank = map[DoctorStatus]int{DoctorPass: 0, DoctorWarn: 1, DoctorFail: 2}
+
+// checkChrome looks for the browser the scraper's chrome engine drives
+func (d *Doctor) checkChrome() DoctorCheck {
+	check := DoctorCheck{Name: DoctorCheckChrome}
+	path, err := d.opts.findChrome()
+	if err != nil && d.opts.Paths != nil {
+		fetcher := browser.NewFetcher(d.opts.Paths.BrowserDir, d.opts.Browser, nil)
+		if managed, ok := fetcher.Installed(); ok {
+			check.Status = DoctorPass
+			check.Message = "Using the managed Chromium " + fetcher.Version()
+			check.Details = map[string]interface{}{"path": managed, "managed": true}
+			return check
+		}
+		if fetcher.Enabled() {
+			check.Status = DoctorWarn
+			check.Message = "Chrome or Chromium was not found; the scraper downloads Chromium " + fetcher.Version() + " on its first run"
+			check.Fix = "Allow downloads from storage.googleapis.com or set scraper.browser.download_url to a mirror, or install Google Chrome"
+			return check
+		}
+	}
+	if err != nil {
+		check.Status = DoctorWarn
+		check.Message = "Chrome or Chromium was not found; the scraper falls back to its slower HTTP engine"
+		check.Fix = "Install Google Chrome, enable scraper.browser.managed, or run the scraper with --engine http"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Message = "Found " + path
+	check.Details = map[string]interface{}{"path": path}
+	return check
+}
+
+// checkDataDirs verifies a file can be created in every directory the
+// pipeline writes to
+func (d *Doctor) checkDataDirs() DoctorCheck {
+	check := DoctorCheck{Name: DoctorCheckDataDirs}
+	if d.opts.Paths == nil {
+		check.Status, check.Message = DoctorFail, "Data paths are not available"
+		check.Fix = "Run ISX Pulse from its installation folder"
+		return check
+	}
+
+	p := d.opts.Paths
+	dirs := []string{p.DataDir, p.DownloadsDir, p.ReportsDir, p.CacheDir, p.LogsDir}
+	var failed []string
+	details := make(map[string]interface{}, len(dirs))
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if err := probeWritable(dir); err != nil {
+			failed = append(failed, dir)
+			details[dir] = err.Error()
+			continue
+		}
+		details[dir] = "writable"
+	}
+	check.Details = details
+
+	if len(failed) > 0 {
+		check.Status = DoctorFail
+		check.Message = "Cannot write to " + strings.Join(failed, ", ")
+		check.Fix = "Give the user running ISX Pulse write access to these folders, or install it outside Program Files"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Message = fmt.Sprintf("%d data directories are writable", len(details))
+	return check
+}
+
+// probeWritable creates dir if needed and writes and removes a file in it
+func probeWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// remoteTime is a server's Date header and the local time it was read
+type remoteTime struct {
+	server string
+	remote time.Time
+	local  time.Time
+}
+
+// checkURL requests target; any HTTP response means the host is reachable.
+// Messages name the host by label only, since the license URL is a secret.
+func (d *Doctor) checkURL(ctx context.Context, name, label, target, fix string) (DoctorCheck, remoteTime) {
+	check := DoctorCheck{Name: name, Details: map[string]interface{}{}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		check.Status, check.Message, check.Fix = DoctorFail, fmt.Sprintf("Invalid URL for %s", label), fix
+		return check, remoteTime{}
+	}
+
+	start := d.opts.now()
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		check.Status, check.Message, check.Fix = DoctorFail, fmt.Sprintf("Cannot reach %s: %v", label, err), fix
+		return check, remoteTime{}
+	}
+	resp.Body.Close()
+	elapsed := d.opts.now().Sub(start)
+
+	check.Details["status_code"] = resp.StatusCode
+	check.Details["latency_ms"] = elapsed.Milliseconds()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		check.Status = DoctorWarn
+		check.Message = fmt.Sprintf("%s answered %d; it may be down for maintenance", label, resp.StatusCode)
+		check.Fix = "Try again later"
+	} else {
+		check.Status = DoctorPass
+		check.Message = fmt.Sprintf("%s reachable in %dms", label, elapsed.Milliseconds())
+	}
+
+	at := remoteTime{server: label, local: start.Add(elapsed / 2)}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		at.remote = date
+	}
+	return check, at
+}
+
+// checkLicenseServer checks the Apps Script license endpoint
+func (d *Doctor) checkLicenseServer(ctx context.Context) (DoctorCheck, remoteTime) {
+	if d.opts.LicenseURL == "" {
+		return DoctorCheck{
+			Name:    DoctorCheckLicense,
+			Status:  DoctorWarn,
+			Message: "No license server is configured in this build",
+			Fix:     "Set APPS_SCRIPT_URL or use a release build to activate and renew licenses online",
+		}, remoteTime{}
+	}
+	return d.checkURL(ctx, DoctorCheckLicense, "the license server", d.opts.LicenseURL,
+		"Allow outbound HTTPS to script.google.com; license activation and renewal need it")
+}
+
+// checkClock compares the local clock with the Date headers of the servers
+// reached. Dates have a one-second resolution, so skew below that is noise.
+func (d *Doctor) checkClock(times ...remoteTime) DoctorCheck {
+	check := DoctorCheck{Name: DoctorCheckClock}
+	var worst time.Duration
+	var server string
+	measured := false
+	for _, t := range times {
+		if t.remote.IsZero() {
+			continue
+		}
+		skew := t.local.Sub(t.remote)
+		if skew < 0 {
+			skew = -skew
+		}
+		if !measured || skew > worst {
+			worst, server = skew, t.server
+		}
+		measured = true
+	}
+	if !measured {
+		check.Status = DoctorWarn
+		check.Message = "Clock skew could not be measured: no server was reached"
+		check.Fix = "Make sure the system clock is synchronized with an internet time server"
+		return check
+	}
+
+	worst = worst.Truncate(time.Second)
+	check.Details = map[string]interface{}{"skew_seconds": worst.Seconds(), "server": server}
+	if worst > d.opts.MaxClockSkew {
+		check.Status = DoctorFail
+		check.Message = fmt.Sprintf("The system clock is %s off %s", worst, server)
+		check.Fix = "Synchronize the system clock (Windows: Settings > Time & language > Sync now); license checks fail with a wrong clock"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Message = fmt.Sprintf("The system clock is within %s of %s", worst, server)
+	return check
+}
+
+// checkDisk grades the free space of the data directory's disk with the
+// detailed health thresholds
+func (d *Doctor) checkDisk() DoctorCheck {
+	check := DoctorCheck{Name: DoctorCheckDisk}
+	if d.opts.Paths == nil {
+		check.Status, check.Message = DoctorWarn, "Data paths are not available"
+		return check
+	}
+
+	space, err := files.DiskUsage(d.opts.Paths.DataDir)
+	if err != nil {
+		check.Status = DoctorWarn
+		check.Message = fmt.Sprintf("Cannot read free space of %s: %v", d.opts.Paths.DataDir, err)
+		return check
+	}
+
+	free := space.FreePercent()
+	check.Details = map[string]interface{}{
+		"path":         d.opts.Paths.DataDir,
+		"free_bytes":   space.FreeBytes,
+		"free_percent": float64(int(free*10)) / 10,
+	}
+	check.Message = fmt.Sprintf("%.1f%% free (%d MB)", free, space.FreeBytes/(1024*1024))
+	switch {
+	case free <= d.opts.Thresholds.DiskCriticalPercent:
+		check.Status = DoctorFail
+	case free <= d.opts.Thresholds.DiskWarningPercent:
+		check.Status = DoctorWarn
+	default:
+		check.Status = DoctorPass
+		return check
+	}
+	check.Fix = "Free up space on the data disk, or archive old reports with the retention command"
+	return check
+}
+
+// checkExecutables looks for the stage executables next to the server
+func (d *Doctor) checkExecutables() DoctorCheck {
+	check := DoctorCheck{Name: DoctorCheckExecutables}
+	if d.opts.Paths == nil {
+		check.Status, check.Message = DoctorFail, "Data paths are not available"
+		return check
+	}
+
+	dir := d.opts.Paths.ExecutableDir
+	details := make(map[string]interface{})
+	var problems []string
+	for _, name := range []string{operations.ScraperExecutable, operations.ProcessorExecutable, operations.IndexCSVExecutable} {
+		path, err := operations.ResolveExecutable(dir, name)
+		if err != nil {
+			details[name] = err.Error()
+			problems = append(problems, err.Error())
+			continue
+		}
+		details[name] = path
+	}
+	check.Details = details
+
+	if len(problems) > 0 {
+		check.Status = DoctorFail
+		check.Message = strings.Join(problems, "; ")
+		check.Fix = "Reinstall ISX Pulse or rebuild with build.bat so scraper, processor and indexcsv sit next to the server"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Message = "scraper, processor and indexcsv found in " + dir
+	return check
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/browser"
+	"isxcli/internal/config"
+	"isxcli/internal/operations"
+)
+
+// newDoctorPaths returns paths in a temp dir with the stage executables
+// present when withExecutables is set
+func newDoctorPaths(t *testing.T, withExecutables bool) *config.Paths {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		ExecutableDir: dir,
+		DataDir:       filepath.Join(dir, "data"),
+		DownloadsDir:  filepath.Join(dir, "data", "downloads"),
+		ReportsDir:    filepath.Join(dir, "data", "reports"),
+		LogsDir:       filepath.Join(dir, "logs"),
+	}
+	if withExecutables {
+		for _, name := range []string{operations.ScraperExecutable, operations.ProcessorExecutable, operations.IndexCSVExecutable} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, operations.ExecutableName(name)), []byte("#!/bin/sh\n"), 0o755))
+		}
+	}
+	return paths
+}
+
+func doctorCheck(t *testing.T, report DoctorReport, name string) DoctorCheck {
+	t.Helper()
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %s missing", name)
+	return DoctorCheck{}
+}
+
+func TestDoctorRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	doctor := NewDoctor(DoctorOptions{
+		Paths:      newDoctorPaths(t, true),
+		ISXURL:     server.URL,
+		LicenseURL: server.URL,
+		Thresholds: config.HealthConfig{DiskWarningPercent: 0.001, DiskCriticalPercent: 0.0001},
+		findChrome: func() (string, error) { return "/usr/bin/chromium", nil },
+	})
+	report := doctor.Run(context.Background())
+
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		assert.Equal(t, DoctorPass, report.Status, "%+v", report.Checks)
+	}
+	assert.Len(t, report.Checks, 7)
+	assert.Equal(t, DoctorPass, doctorCheck(t, report, DoctorCheckChrome).Status)
+	assert.Equal(t, DoctorPass, doctorCheck(t, report, DoctorCheckDataDirs).Status)
+	assert.Equal(t, DoctorPass, doctorCheck(t, report, DoctorCheckISX).Status)
+	assert.Equal(t, DoctorPass, doctorCheck(t, report, DoctorCheckLicense).Status)
+	assert.Equal(t, DoctorPass, doctorCheck(t, report, DoctorCheckClock).Status)
+	assert.Equal(t, DoctorPass, doctorCheck(t, report, DoctorCheckExecutables).Status)
+}
+
+func TestDoctorRunReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	paths := newDoctorPaths(t, false)
+	doctor := NewDoctor(DoctorOptions{
+		Paths:      paths,
+		ISXURL:     unreachable.URL,
+		LicenseURL: server.URL,
+		findChrome: func() (string, error) { return "", errors.New("not found") },
+		now:        func() time.Time { return time.Now().Add(10 * time.Minute) },
+	})
+	report := doctor.Run(context.Background())
+
+	assert.Equal(t, DoctorFail, report.Status)
+
+	chrome := doctorCheck(t, report, DoctorCheckChrome)
+	assert.Equal(t, DoctorWarn, chrome.Status, "the scraper can fall back to HTTP")
+	assert.NotEmpty(t, chrome.Fix)
+
+	isx := doctorCheck(t, report, DoctorCheckISX)
+	assert.Equal(t, DoctorFail, isx.Status)
+	assert.NotEmpty(t, isx.Fix)
+
+	assert.Equal(t, DoctorWarn, doctorCheck(t, report, DoctorCheckLicense).Status, "a 503 means the host is up but unhealthy")
+
+	clock := doctorCheck(t, report, DoctorCheckClock)
+	assert.Equal(t, DoctorFail, clock.Status)
+	assert.Contains(t, clock.Message, "the license server")
+
+	executables := doctorCheck(t, report, DoctorCheckExecutables)
+	assert.Equal(t, DoctorFail, executables.Status)
+	assert.Contains(t, executables.Message, operations.ExecutableName(operations.ScraperExecutable))
+}
+
+func TestDoctorDataDirsNotWritable(t *testing.T) {
+	paths := newDoctorPaths(t, true)
+	// A file where a directory is expected cannot hold files on any platform
+	blocker := filepath.Join(paths.ExecutableDir, "blocked")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+	paths.ReportsDir = filepath.Join(blocker, "reports")
+
+	check := NewDoctor(DoctorOptions{Paths: paths}).checkDataDirs()
+	assert.Equal(t, DoctorFail, check.Status)
+	assert.Contains(t, check.Message, paths.ReportsDir)
+	assert.Equal(t, "writable", check.Details[paths.DownloadsDir])
+}
+
+func TestDoctorChromeManagedBrowser(t *testing.T) {
+	paths := newDoctorPaths(t, false)
+	paths.BrowserDir = filepath.Join(paths.DataDir, "cache", "browser")
+	notFound := func() (string, error) { return "", errors.New("not found") }
+
+	check := NewDoctor(DoctorOptions{Paths: paths, Browser: config.BrowserConfig{Managed: true}, findChrome: notFound}).checkChrome()
+	assert.Equal(t, DoctorWarn, check.Status)
+	assert.Contains(t, check.Message, "downloads Chromium")
+
+	check = NewDoctor(DoctorOptions{Paths: paths, findChrome: notFound}).checkChrome()
+	assert.Equal(t, DoctorWarn, check.Status)
+	assert.Contains(t, check.Message, "HTTP engine", "a disabled managed browser leaves the HTTP fallback")
+
+	// An unpacked managed build passes, whether or not downloads are allowed
+	execPath := browser.NewFetcher(paths.BrowserDir, config.BrowserConfig{}, nil).ExecPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(execPath), 0o755))
+	require.NoError(t, os.WriteFile(execPath, []byte("#!/bin/sh\n"), 0o755))
+	check = NewDoctor(DoctorOptions{Paths: paths, findChrome: notFound}).checkChrome()
+	assert.Equal(t, DoctorPass, check.Status)
+	assert.Equal(t, execPath, check.Details["path"])
+}
+
+func TestDoctorWithoutLicenseServer(t *testing.T) {
+	t.Setenv("APPS_SCRIPT_URL", "")
+	doctor := NewDoctor(DoctorOptions{})
+	doctor.opts.LicenseURL = ""
+	check, at := doctor.checkLicenseServer(context.Background())
+	assert.Equal(t, DoctorWarn, check.Status)
+	assert.True(t, at.remote.IsZero())
+
+	clock := doctor.checkClock(at)
+	assert.Equal(t, DoctorWarn, clock.Status, "skew cannot be measured without a reachable server")
+}
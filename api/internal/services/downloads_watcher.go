@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/operations"
+)
+
+// watchSteps are the steps run for new reports: processing also regenerates
+// the ticker summary
+var watchSteps = []string{
+	operations.StageIDProcessing,
+	operations.StageIDIndices,
+	operations.StageIDLiquidity,
+}
+
+// WatchRunner runs the operations started by a DownloadsWatcher
+type WatchRunner interface {
+	ListOperations(ctx context.Context) ([]*operations.OperationState, error)
+	ExecuteOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationResponse, error)
+}
+
+// fileStamp identifies a version of a downloaded report
+type fileStamp struct {
+	size    int64
+	modTime int64 // UnixNano
+}
+
+// DownloadsWatcher polls the downloads directory and processes reports that
+// appear outside the scraper, e.g. copied in by hand. fsnotify is not
+// available on every platform the app ships to, so it scans on an interval.
+type DownloadsWatcher struct {
+	dir      string
+	interval time.Duration
+	runner   WatchRunner
+	jobs     *operations.JobQueue
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	known   map[string]fileStamp // reports already processed or present at start
+	pending map[string]fileStamp // new reports seen on the previous scan
+
+	loopMu sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDownloadsWatcher creates a watcher for dir scanning every interval
+func NewDownloadsWatcher(dir string, interval time.Duration, runner WatchRunner, logger *slog.Logger) *DownloadsWatcher {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &DownloadsWatcher{
+		dir:      dir,
+		interval: interval,
+		runner:   runner,
+		logger:   logger,
+		now:      time.Now,
+		pending:  make(map[string]fileStamp),
+	}
+}
+
+// SetJobQueue makes the watcher wait while queued operations run
+func (w *DownloadsWatcher) SetJobQueue(q *operations.JobQueue) {
+	w.jobs = q
+}
+
+// Start records the reports already downloaded and begins scanning. Only
+// reports added or changed afterwards trigger processing.
+func (w *DownloadsWatcher) Start(ctx context.Context) error {
+	w.loopMu.Lock()
+	defer w.loopMu.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+
+	files, err := w.scan()
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.known = files
+	w.mu.Unlock()
+
+	// Stop cancels a triggered operation rather than waiting for it
+	loopCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	done := w.done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := w.Check(loopCtx); err != nil {
+					w.logger.WarnContext(loopCtx, "Downloads watcher check failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+
+	w.logger.InfoContext(ctx, "Watching downloads for new reports",
+		slog.String("dir", w.dir),
+		slog.Duration("interval", w.interval),
+		slog.Int("existing_reports", len(files)))
+	return nil
+}
+
+// Stop ends the scanning loop, cancelling a triggered operation
+func (w *DownloadsWatcher) Stop() {
+	w.loopMu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel = nil
+	w.done = nil
+	w.loopMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Check scans the downloads directory once. Reports that are new or changed
+// and unchanged since the previous scan start one operation; the returned
+// ID is empty when nothing was started. While a report is still being
+// written, or another operation runs, the check waits for a later scan.
+func (w *DownloadsWatcher) Check(ctx context.Context) (string, error) {
+	files, err := w.scan()
+	if err != nil {
+		return "", err
+	}
+
+	w.mu.Lock()
+	if w.known == nil {
+		w.known = make(map[string]fileStamp)
+	}
+	var ready []string
+	settling := false
+	next := make(map[string]fileStamp)
+	for name, stamp := range files {
+		if known, ok := w.known[name]; ok && known == stamp {
+			continue
+		}
+		next[name] = stamp
+		if prev, ok := w.pending[name]; ok && prev == stamp {
+			ready = append(ready, name)
+		} else {
+			settling = true
+		}
+	}
+	w.pending = next
+	w.mu.Unlock()
+
+	// Wait for the whole batch so one copy of many files runs once
+	if len(ready) == 0 || settling {
+		return "", nil
+	}
+	busy, err := w.busy(ctx)
+	if err != nil {
+		return "", err
+	}
+	if busy {
+		w.logger.DebugContext(ctx, "New reports wait for the running operation", slog.Int("reports", len(ready)))
+		return "", nil
+	}
+
+	sort.Strings(ready)
+	operationID, err := w.process(ctx, ready)
+
+	// Processed reports are not retried on failure; the next new or
+	// changed report starts another run over the whole directory
+	w.mu.Lock()
+	for _, name := range ready {
+		w.known[name] = files[name]
+		delete(w.pending, name)
+	}
+	w.mu.Unlock()
+
+	return operationID, err
+}
+
+// process runs the watch steps for the ready reports
+func (w *DownloadsWatcher) process(ctx context.Context, reports []string) (string, error) {
+	request := &operations.OperationRequest{
+		ID:   fmt.Sprintf("watch-%d", w.now().UnixNano()),
+		Mode: "full",
+		Parameters: map[string]interface{}{
+			"mode":                     "full",
+			"trigger":                  "watch",
+			"reports":                  reports,
+			operations.ContextKeySteps: append([]string(nil), watchSteps...),
+		},
+	}
+
+	w.logger.InfoContext(ctx, "New reports in downloads, starting processing",
+		slog.String("operation_id", request.ID),
+		slog.Any("reports", reports))
+
+	resp, err := w.runner.ExecuteOperation(ctx, request)
+	if err != nil {
+		return request.ID, fmt.Errorf("process new reports: %w", err)
+	}
+	w.logger.InfoContext(ctx, "Processed new reports",
+		slog.String("operation_id", resp.ID),
+		slog.String("status", string(resp.Status)),
+		slog.Duration("duration", resp.Duration))
+	return resp.ID, nil
+}
+
+// busy reports whether another operation is running or queued
+func (w *DownloadsWatcher) busy(ctx context.Context) (bool, error) {
+	states, err := w.runner.ListOperations(ctx)
+	if err != nil {
+		return false, fmt.Errorf("list operations: %w", err)
+	}
+	for _, state := range states {
+		if state.Status == operations.OperationStatusPending || state.Status == operations.OperationStatusRunning {
+			return true, nil
+		}
+	}
+	if w.jobs != nil {
+		stats := w.jobs.GetQueueStats()
+		if stats["active_jobs"].(int) > 0 || stats["queue_size"].(int) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// scan lists the Excel reports directly in the downloads directory. The
+// corrupt quarantine folder and partial downloads are left out.
+func (w *DownloadsWatcher) scan() (map[string]fileStamp, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]fileStamp{}, nil
+		}
+		return nil, fmt.Errorf("read downloads directory: %w", err)
+	}
+
+	files := make(map[string]fileStamp, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), "~$") {
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(entry.Name())); ext != ".xlsx" && ext != ".xls" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed between listing and stat
+			continue
+		}
+		files[entry.Name()] = fileStamp{size: info.Size(), modTime: info.ModTime().UnixNano()}
+	}
+	return files, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/operations"
+)
+
+type fakeWatchRunner struct {
+	mu       sync.Mutex
+	running  []*operations.OperationState
+	requests []*operations.OperationRequest
+}
+
+func (f *fakeWatchRunner) ListOperations(ctx context.Context) ([]*operations.OperationState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.running, nil
+}
+
+func (f *fakeWatchRunner) ExecuteOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, request)
+	return &operations.OperationResponse{ID: request.ID, Status: operations.OperationStatusCompleted}, nil
+}
+
+func writeDownloadedReport(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestDownloadsWatcher_ProcessesSettledReports(t *testing.T) {
+	dir := t.TempDir()
+	writeDownloadedReport(t, dir, "2025 03 01 ISX Daily Report.xlsx", "existing")
+
+	runner := &fakeWatchRunner{}
+	watcher := NewDownloadsWatcher(dir, 0, runner, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	require.NoError(t, watcher.Start(ctx))
+	watcher.Stop()
+
+	id, err := watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, id, "reports present at start are not reprocessed")
+
+	writeDownloadedReport(t, dir, "2025 03 02 ISX Daily Report.xlsx", "new")
+	writeDownloadedReport(t, dir, "2025 03 03 ISX Daily Report.xlsx.part", "partial")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "corrupt"), 0755))
+	writeDownloadedReport(t, filepath.Join(dir, "corrupt"), "2025 03 04 ISX Daily Report.xlsx", "bad")
+
+	id, err = watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, id, "a new report waits one scan to settle")
+
+	id, err = watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	require.Len(t, runner.requests, 1)
+	params := runner.requests[0].Parameters
+	assert.Equal(t, []string{"processing", "indices", "liquidity"}, params[operations.ContextKeySteps])
+	assert.Equal(t, []string{"2025 03 02 ISX Daily Report.xlsx"}, params["reports"])
+
+	id, err = watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, id, "processed reports do not trigger again")
+	assert.Len(t, runner.requests, 1)
+}
+
+func TestDownloadsWatcher_WaitsForBatchAndRunningOperation(t *testing.T) {
+	dir := t.TempDir()
+	running := operations.NewOperationState("operation-1")
+	running.Start()
+	runner := &fakeWatchRunner{running: []*operations.OperationState{running}}
+	watcher := NewDownloadsWatcher(dir, 0, runner, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	writeDownloadedReport(t, dir, "a.xlsx", "a")
+	_, err := watcher.Check(ctx)
+	require.NoError(t, err)
+
+	// A second file still arriving holds back the first
+	writeDownloadedReport(t, dir, "b.xlsx", "b")
+	id, err := watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+
+	id, err = watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, id, "waits for the running operation")
+
+	runner.mu.Lock()
+	runner.running = nil
+	runner.mu.Unlock()
+
+	id, err = watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	require.Len(t, runner.requests, 1)
+	assert.Equal(t, []string{"a.xlsx", "b.xlsx"}, runner.requests[0].Parameters["reports"])
+}
+
+func TestDownloadsWatcher_ChangedReportRunsAgain(t *testing.T) {
+	dir := t.TempDir()
+	runner := &fakeWatchRunner{}
+	watcher := NewDownloadsWatcher(dir, 0, runner, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	writeDownloadedReport(t, dir, "a.xlsx", "a")
+	for i := 0; i < 2; i++ {
+		_, err := watcher.Check(ctx)
+		require.NoError(t, err)
+	}
+	require.Len(t, runner.requests, 1)
+
+	writeDownloadedReport(t, dir, "a.xlsx", "a replaced by a longer copy")
+	for i := 0; i < 2; i++ {
+		_, err := watcher.Check(ctx)
+		require.NoError(t, err)
+	}
+	assert.Len(t, runner.requests, 2)
+}
+
+func TestDownloadsWatcher_MissingDirectory(t *testing.T) {
+	watcher := NewDownloadsWatcher(filepath.Join(t.TempDir(), "missing"), 0, &fakeWatchRunner{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	id, err := watcher.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
@@ -1,40 +1,48 @@
 package services
 
-import "errors"
+import apierrors "isxcli/internal/errors"
 
-// Data service errors
+// Data service errors. Each carries an apierrors.Kind, which decides the
+// Problem Details response when a handler passes it to the error handler.
 var (
 	// Report errors
-	ErrNoReportsFound = errors.New("no reports found")
+	ErrNoReportsFound = apierrors.Newf(apierrors.NotFound, "no reports found")
 	
 	// Ticker errors
-	ErrNoTickersFound = errors.New("no tickers found")
-	ErrTickerNotFound = errors.New("ticker not found")
-	ErrNoChartData    = errors.New("no chart data available")
+	ErrNoTickersFound = apierrors.Newf(apierrors.NotFound, "no tickers found")
+	ErrTickerNotFound = apierrors.Newf(apierrors.NotFound, "ticker not found")
+	ErrNoChartData    = apierrors.Newf(apierrors.NotFound, "no chart data available")
+	ErrNoIntradayData = apierrors.Newf(apierrors.NotFound, "no intraday data available")
+	ErrNoSnapshotData = apierrors.Newf(apierrors.NotFound, "no intraday snapshot available")
 	
 	// Index errors
-	ErrNoIndicesFound = errors.New("no indices found")
+	ErrNoIndicesFound = apierrors.Newf(apierrors.NotFound, "no indices found")
 	
 	// File errors
-	ErrNoFilesFound    = errors.New("no files found")
-	ErrFileNotFound    = errors.New("file not found")
-	ErrInvalidFileType = errors.New("invalid file type")
+	ErrNoFilesFound    = apierrors.Newf(apierrors.NotFound, "no files found")
+	ErrFileNotFound    = apierrors.Newf(apierrors.NotFound, "file not found")
+	ErrInvalidFileType = apierrors.Newf(apierrors.Validation, "invalid file type")
+	ErrInvalidFilePath = apierrors.Newf(apierrors.Validation, "invalid file path")
+	ErrEmptyBundle     = apierrors.Newf(apierrors.NotFound, "no reports match the bundle selection")
+	
+	// Liquidity errors
+	ErrNoLiquidityData = apierrors.Newf(apierrors.NotFound, "no liquidity data")
 	
 	// Market movers errors
-	ErrNoMarketMovers = errors.New("no market movers found")
+	ErrNoMarketMovers = apierrors.Newf(apierrors.NotFound, "no market movers found")
 	
 	// operation errors
-	ErrOperationNotFound   = errors.New("operation not found")
-	ErrOperationRunning    = errors.New("operation already running")
-	ErrOperationNotRunning = errors.New("operation not running")
-	ErrInvalidStage        = errors.New("invalid operation step")
+	ErrOperationNotFound   = apierrors.Newf(apierrors.NotFound, "operation not found")
+	ErrOperationRunning    = apierrors.Newf(apierrors.Conflict, "operation already running")
+	ErrOperationNotRunning = apierrors.Newf(apierrors.Conflict, "operation not running")
+	ErrInvalidStage        = apierrors.Newf(apierrors.Validation, "invalid operation step")
 	
 	// WebSocket errors
-	ErrWebSocketUpgrade    = errors.New("websocket upgrade failed")
-	ErrWebSocketClosed     = errors.New("websocket connection closed")
+	ErrWebSocketUpgrade    = apierrors.Newf(apierrors.Internal, "websocket upgrade failed")
+	ErrWebSocketClosed     = apierrors.Newf(apierrors.Internal, "websocket connection closed")
 	
 	// General errors
-	ErrInvalidInput      = errors.New("invalid input")
-	ErrOperationTimeout  = errors.New("operation timed out")
-	ErrServiceUnavailable = errors.New("service temporarily unavailable")
+	ErrInvalidInput      = apierrors.Newf(apierrors.Validation, "invalid input")
+	ErrOperationTimeout  = apierrors.Newf(apierrors.Timeout, "operation timed out")
+	ErrServiceUnavailable = apierrors.Newf(apierrors.Unavailable, "service temporarily unavailable")
 )
\ No newline at end of file
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/currency"
+)
+
+// ExchangeRateService provides the IQD per USD rates used to report values
+// in USD: the CBI rates built into the application plus data/reference/
+// exchange_rates.csv, which is re-read when it changes. With a rates URL
+// configured, Start keeps the file up to date from it.
+type ExchangeRateService struct {
+	path   string
+	cfg    config.CurrencyConfig
+	client *http.Client
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	modTime time.Time
+	table   *currency.Table
+
+	loopMu sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewExchangeRateService creates a new exchange rate service
+func NewExchangeRateService(paths *config.Paths, cfg config.CurrencyConfig, logger *slog.Logger) *ExchangeRateService {
+	return &ExchangeRateService{
+		path:   paths.GetExchangeRatesCSVPath(),
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+		logger: logger,
+	}
+}
+
+// Table returns the current rate table. A nil service has the built-in CBI
+// rates only.
+func (s *ExchangeRateService) Table(ctx context.Context) (*currency.Table, error) {
+	if s == nil {
+		return currency.NewTable(currency.DefaultRates()), nil
+	}
+	var modTime time.Time
+	if info, err := os.Stat(s.path); err == nil {
+		modTime = info.ModTime()
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat exchange rates file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.table != nil && s.modTime.Equal(modTime) {
+		return s.table, nil
+	}
+	table, err := currency.LoadTable(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	s.table, s.modTime = table, modTime
+	s.logger.DebugContext(ctx, "Exchange rates loaded", slog.Int("rates", len(table.Rates())))
+	return table, nil
+}
+
+// Refresh fetches the configured rates URL and merges the rates into the
+// rates file
+func (s *ExchangeRateService) Refresh(ctx context.Context) error {
+	if s.cfg.RatesURL == "" {
+		return errors.New("no exchange rates URL configured")
+	}
+	fetched, err := currency.Fetch(ctx, s.client, s.cfg.RatesURL)
+	if err != nil {
+		return err
+	}
+
+	var existing []currency.Rate
+	if file, err := os.Open(s.path); err == nil {
+		existing, err = currency.ReadRates(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read exchange rates file: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := currency.WriteRates(s.path, currency.NewTable(existing, fetched).Rates()); err != nil {
+		return fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Exchange rates updated", slog.Int("fetched", len(fetched)))
+	return nil
+}
+
+// Start refreshes the rates now and then every fetch interval. It does
+// nothing without a rates URL.
+func (s *ExchangeRateService) Start(ctx context.Context) {
+	if s.cfg.RatesURL == "" || s.cfg.FetchInterval <= 0 {
+		return
+	}
+	s.loopMu.Lock()
+	if s.stop != nil {
+		s.loopMu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	stop, done := s.stop, s.done
+	s.loopMu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.cfg.FetchInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.WarnContext(ctx, "Failed to refresh exchange rates",
+					slog.String("url", s.cfg.RatesURL),
+					slog.String("error", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the refresh loop
+func (s *ExchangeRateService) Stop() {
+	s.loopMu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.loopMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// convertTo returns a function converting IQD amounts on a date to the given
+// currency
+func (s *ExchangeRateService) convertTo(ctx context.Context, to currency.Code) (func(iqd float64, date time.Time) float64, error) {
+	if to != currency.USD {
+		return func(iqd float64, _ time.Time) float64 { return iqd }, nil
+	}
+	table, err := s.Table(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(iqd float64, date time.Time) float64 { return table.Convert(iqd, to, date) }, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/exporter"
+	"isxcli/internal/i18n"
+)
+
+// MaxBundleTickers caps the number of tickers selected for one bundle
+const MaxBundleTickers = 500
+
+var bundleTickerRe = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+
+// PlanExportBundle validates a bundle selection and lists the report files it
+// covers. The returned bundle is written with WriteZip, which streams the
+// archive without building it on disk.
+func (ds *DataService) PlanExportBundle(ctx context.Context, spec exporter.BundleSpec) (*exporter.Bundle, error) {
+	if len(spec.Artifacts) == 0 {
+		return nil, fmt.Errorf("%w: select at least one artifact", ErrInvalidInput)
+	}
+	seen := make(map[exporter.BundleArtifact]bool, len(spec.Artifacts))
+	artifacts := make([]exporter.BundleArtifact, 0, len(spec.Artifacts))
+	for _, artifact := range spec.Artifacts {
+		if !artifact.Valid() {
+			return nil, fmt.Errorf("%w: unknown artifact %q", ErrInvalidInput, artifact)
+		}
+		if !seen[artifact] {
+			seen[artifact] = true
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	spec.Artifacts = artifacts
+
+	if !spec.From.IsZero() && !spec.To.IsZero() && spec.To.Before(spec.From) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+	if len(spec.Tickers) > MaxBundleTickers {
+		return nil, fmt.Errorf("%w: a bundle is limited to %d tickers", ErrInvalidInput, MaxBundleTickers)
+	}
+	for i, ticker := range spec.Tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if !bundleTickerRe.MatchString(ticker) {
+			return nil, fmt.Errorf("%w: invalid ticker %q", ErrInvalidInput, spec.Tickers[i])
+		}
+		spec.Tickers[i] = ticker
+	}
+
+	opts := exporter.DefaultExportOptions()
+	if ds.config != nil {
+		opts = exporter.ExportOptionsFromConfig(ds.config.Export)
+		if spec.Currency == "" {
+			spec.Currency = currency.Code(ds.config.Export.Currency)
+		}
+		if spec.Locale.Language == "" {
+			spec.Locale.Language = i18n.Language(ds.config.Export.Language)
+		}
+		if spec.Locale.Digits == "" {
+			spec.Locale.Digits = i18n.Digits(ds.config.Export.Digits)
+		}
+	}
+	locale, err := i18n.NewLocale(string(spec.Locale.Language), string(spec.Locale.Digits))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	spec.Locale = locale
+	cur, err := currency.ParseCode(string(spec.Currency))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	spec.Currency = cur
+	if cur == currency.USD {
+		if spec.Rates, err = ds.rates.Table(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	bundle, err := exporter.PlanBundle(ds.paths.ForContext(ctx), spec, opts)
+	if errors.Is(err, exporter.ErrEmptyBundle) {
+		return nil, ErrEmptyBundle
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan bundle: %w", err)
+	}
+
+	ds.logger.DebugContext(ctx, "Planned export bundle",
+		slog.Int("files", len(bundle.Entries)),
+		slog.Int("tickers", len(spec.Tickers)))
+	return bundle, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/files"
+)
+
+// marketLocation is the exchange's local time. Iraq has stayed on UTC+3
+// without daylight saving since 2008, so a fixed zone avoids depending on
+// tzdata being installed.
+var marketLocation = time.FixedZone("AST", 3*60*60)
+
+// maxScheduleOffset bounds T+N schedules; reports are not useful a fortnight late
+const maxScheduleOffset = 10
+
+// maxScheduleSearchDays bounds the search for the next run of a schedule
+const maxScheduleSearchDays = 400
+
+// Schedule kinds
+const (
+	scheduleTradingDay   = "trading_day"
+	scheduleFirstOfMonth = "first_of_month"
+	scheduleLastOfMonth  = "last_of_month"
+	scheduleFirstOfWeek  = "first_of_week"
+	scheduleLastOfWeek   = "last_of_week"
+)
+
+var (
+	tradingDaySchedulePattern = regexp.MustCompile(`^t\s*\+\s*(\d+)\s+at\s+(\d{1,2}):(\d{2})$`)
+	periodSchedulePattern     = regexp.MustCompile(`^(first|last)\s+trading\s+day\s+of\s+(month|week)\s+at\s+(\d{1,2}):(\d{2})$`)
+)
+
+// ExportSchedule is a schedule expressed in trading days rather than
+// calendar days, so reports never go out on weekends or holidays. Supported
+// forms, all in exchange time:
+//
+//	T+0 at 17:00                       every trading day, for that day
+//	T+1 at 09:00                       the next trading day, for the previous one
+//	last trading day of month at 17:00
+//	first trading day of week at 09:00
+type ExportSchedule struct {
+	spec   string
+	kind   string
+	offset int
+	hour   int
+	minute int
+}
+
+// ScheduledRun is one resolved run of a schedule. DataDate is the trading
+// day whose data the run delivers.
+type ScheduledRun struct {
+	At       time.Time `json:"at"`
+	DataDate string    `json:"dataDate"`
+}
+
+// ParseExportSchedule parses a trading-calendar schedule
+func ParseExportSchedule(spec string) (ExportSchedule, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(spec), " "))
+	s := ExportSchedule{spec: strings.TrimSpace(spec)}
+
+	var clock []string
+	if m := tradingDaySchedulePattern.FindStringSubmatch(normalized); m != nil {
+		offset, err := strconv.Atoi(m[1])
+		if err != nil || offset > maxScheduleOffset {
+			return ExportSchedule{}, fmt.Errorf("%w: schedule offset must be between T+0 and T+%d", ErrInvalidInput, maxScheduleOffset)
+		}
+		s.kind = scheduleTradingDay
+		s.offset = offset
+		clock = m[2:4]
+	} else if m := periodSchedulePattern.FindStringSubmatch(normalized); m != nil {
+		s.kind = m[1] + "_of_" + m[2]
+		clock = m[3:5]
+	} else {
+		return ExportSchedule{}, fmt.Errorf("%w: unrecognised schedule %q (use \"T+N at HH:MM\" or \"first|last trading day of month|week at HH:MM\")", ErrInvalidInput, spec)
+	}
+
+	s.hour, _ = strconv.Atoi(clock[0])
+	s.minute, _ = strconv.Atoi(clock[1])
+	if s.hour > 23 || s.minute > 59 {
+		return ExportSchedule{}, fmt.Errorf("%w: invalid time of day %s:%s", ErrInvalidInput, clock[0], clock[1])
+	}
+	return s, nil
+}
+
+// String returns the schedule as it was written
+func (s ExportSchedule) String() string {
+	return s.spec
+}
+
+// Next returns the first run strictly after the given time. It returns false
+// when the calendar has no matching trading day within a year.
+func (s ExportSchedule) Next(after time.Time, cal *files.HolidayCalendar) (ScheduledRun, bool) {
+	local := after.In(marketLocation)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < maxScheduleSearchDays; i++ {
+		d := day.AddDate(0, 0, i)
+		at := time.Date(d.Year(), d.Month(), d.Day(), s.hour, s.minute, 0, 0, marketLocation)
+		if !at.After(after) {
+			continue
+		}
+		if dataDate, ok := s.runsOn(d, cal); ok {
+			return ScheduledRun{At: at, DataDate: dataDate.Format("2006-01-02")}, true
+		}
+	}
+	return ScheduledRun{}, false
+}
+
+// Upcoming returns the next count runs after the given time
+func (s ExportSchedule) Upcoming(after time.Time, count int, cal *files.HolidayCalendar) []ScheduledRun {
+	runs := make([]ScheduledRun, 0, count)
+	for len(runs) < count {
+		run, ok := s.Next(after, cal)
+		if !ok {
+			break
+		}
+		runs = append(runs, run)
+		after = run.At
+	}
+	return runs
+}
+
+// runsOn reports whether the schedule fires on day and which trading day's
+// data it delivers
+func (s ExportSchedule) runsOn(day time.Time, cal *files.HolidayCalendar) (time.Time, bool) {
+	var target time.Time
+	var ok bool
+
+	switch s.kind {
+	case scheduleTradingDay:
+		if !cal.IsTradingDay(day) {
+			return time.Time{}, false
+		}
+		// T+N fires N trading days after the data date
+		dataDate := day
+		for n := 0; n < s.offset; n++ {
+			if dataDate, ok = cal.PreviousTradingDay(dataDate); !ok {
+				return time.Time{}, false
+			}
+		}
+		return dataDate, true
+	case scheduleFirstOfMonth:
+		target, ok = cal.FirstTradingDayOfMonth(day)
+	case scheduleLastOfMonth:
+		target, ok = cal.LastTradingDayOfMonth(day)
+	case scheduleFirstOfWeek:
+		target, ok = cal.FirstTradingDayOfWeek(day)
+	case scheduleLastOfWeek:
+		target, ok = cal.LastTradingDayOfWeek(day)
+	}
+
+	if !ok || !target.Equal(day) {
+		return time.Time{}, false
+	}
+	return day, true
+}
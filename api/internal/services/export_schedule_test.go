@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/files"
+)
+
+func marketTime(y int, m time.Month, d, hour, minute int) time.Time {
+	return time.Date(y, m, d, hour, minute, 0, 0, marketLocation)
+}
+
+func TestParseExportSchedule(t *testing.T) {
+	valid := []string{
+		"T+0 at 17:00",
+		"t + 2 at 9:30",
+		"last trading day of month at 17:00",
+		"First Trading Day of Week at 08:15",
+	}
+	for _, spec := range valid {
+		_, err := ParseExportSchedule(spec)
+		assert.NoError(t, err, spec)
+	}
+
+	invalid := []string{
+		"",
+		"0 17 * * *",
+		"T+11 at 17:00",
+		"T+0 at 24:00",
+		"T+0 at 17:60",
+		"last trading day of year at 17:00",
+	}
+	for _, spec := range invalid {
+		_, err := ParseExportSchedule(spec)
+		assert.ErrorIs(t, err, ErrInvalidInput, spec)
+	}
+}
+
+func TestExportSchedule_Next(t *testing.T) {
+	// 2025-03-31 and 2025-04-01 are holidays; Friday and Saturday are weekends
+	cal := files.NewHolidayCalendar(
+		time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+	)
+
+	tests := []struct {
+		name     string
+		spec     string
+		after    time.Time
+		wantAt   time.Time
+		wantDate string
+	}{
+		{"same day before the cut-off", "T+0 at 17:00", marketTime(2025, 3, 30, 10, 0), marketTime(2025, 3, 30, 17, 0), "2025-03-30"},
+		{"skips holidays", "T+0 at 17:00", marketTime(2025, 3, 30, 17, 0), marketTime(2025, 4, 2, 17, 0), "2025-04-02"},
+		{"skips the weekend", "T+0 at 17:00", marketTime(2025, 4, 3, 18, 0), marketTime(2025, 4, 6, 17, 0), "2025-04-06"},
+		{"T+1 reports the previous trading day", "T+1 at 09:00", marketTime(2025, 4, 1, 12, 0), marketTime(2025, 4, 2, 9, 0), "2025-03-30"},
+		{"month end falls back before a holiday", "last trading day of month at 17:00", marketTime(2025, 3, 1, 0, 0), marketTime(2025, 3, 30, 17, 0), "2025-03-30"},
+		{"first trading day of month", "first trading day of month at 09:00", marketTime(2025, 3, 30, 18, 0), marketTime(2025, 4, 2, 9, 0), "2025-04-02"},
+		{"week end", "last trading day of week at 16:00", marketTime(2025, 4, 6, 8, 0), marketTime(2025, 4, 10, 16, 0), "2025-04-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseExportSchedule(tt.spec)
+			require.NoError(t, err)
+
+			run, ok := schedule.Next(tt.after, cal)
+			require.True(t, ok)
+			assert.True(t, tt.wantAt.Equal(run.At), "got %s", run.At)
+			assert.Equal(t, tt.wantDate, run.DataDate)
+		})
+	}
+}
+
+func TestExportSchedule_Upcoming(t *testing.T) {
+	cal := files.NewHolidayCalendar()
+	schedule, err := ParseExportSchedule("T+0 at 17:00")
+	require.NoError(t, err)
+
+	// Thursday evening: the next runs are Sunday, Monday and Tuesday
+	runs := schedule.Upcoming(marketTime(2025, 4, 3, 18, 0), 3, cal)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "2025-04-06", runs[0].DataDate)
+	assert.Equal(t, "2025-04-07", runs[1].DataDate)
+	assert.Equal(t, "2025-04-08", runs[2].DataDate)
+}
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+)
+
+// ErrSubscriptionNotFound is returned for an unknown export subscription
+var ErrSubscriptionNotFound = apierrors.Newf(apierrors.NotFound, "export subscription not found")
+
+// subscriptionsFileName is the subscription store kept in the profile's data directory
+const subscriptionsFileName = "subscriptions.json"
+
+// Exports a subscription can deliver
+const (
+	ExportTickerSummary = "ticker_summary"
+	ExportIndexes       = "indexes"
+	ExportCombined      = "combined"
+)
+
+// ValidExports lists the exports accepted by SubscriptionInput.Export
+var ValidExports = []string{ExportTickerSummary, ExportIndexes, ExportCombined}
+
+// ExportSubscription delivers one report on a trading-calendar schedule.
+// Runs copy the report into data/exports/{id}/, prefixed with the data date.
+type ExportSubscription struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Export   string `json:"export"`
+	Schedule string `json:"schedule"`
+	Enabled  bool   `json:"enabled"`
+
+	LastRunAt    *time.Time `json:"lastRunAt,omitempty"`
+	LastDataDate string     `json:"lastDataDate,omitempty"`
+	LastFile     string     `json:"lastFile,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+
+	// NextRun is resolved against the calendar when subscriptions are read
+	NextRun *ScheduledRun `json:"nextRun,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SubscriptionInput is the editable part of a subscription. A nil Enabled
+// keeps the current state, and enables new subscriptions.
+type SubscriptionInput struct {
+	Name     string `json:"name"`
+	Export   string `json:"export"`
+	Schedule string `json:"schedule"`
+	Enabled  *bool  `json:"enabled,omitempty"`
+}
+
+// Validate checks the input and returns the parsed schedule
+func (in *SubscriptionInput) Validate() (ExportSchedule, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Export = strings.TrimSpace(in.Export)
+	if in.Name == "" {
+		return ExportSchedule{}, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if !isValidExport(in.Export) {
+		return ExportSchedule{}, fmt.Errorf("%w: unknown export %q (use: %s)", ErrInvalidInput, in.Export, strings.Join(ValidExports, ", "))
+	}
+	return ParseExportSchedule(in.Schedule)
+}
+
+func isValidExport(export string) bool {
+	for _, e := range ValidExports {
+		if e == export {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportSubscriptionService stores export subscriptions and delivers them
+// when their trading-calendar schedule comes due. Subscriptions are stored
+// as JSON in the data directory of each profile.
+type ExportSubscriptionService struct {
+	paths    *config.Paths
+	calendar *TradingCalendarService
+	interval time.Duration
+	logger   *slog.Logger
+	mu       sync.Mutex
+	now      func() time.Time
+
+	loopMu sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewExportSubscriptionService creates a new export subscription service that
+// checks for due subscriptions every interval
+func NewExportSubscriptionService(paths *config.Paths, calendar *TradingCalendarService, interval time.Duration, logger *slog.Logger) *ExportSubscriptionService {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ExportSubscriptionService{
+		paths:    paths,
+		calendar: calendar,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// List returns all subscriptions ordered by name, with their next run
+func (s *ExportSubscriptionService) List(ctx context.Context) ([]ExportSubscription, error) {
+	s.mu.Lock()
+	store, err := s.load(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]ExportSubscription, 0, len(store))
+	for _, sub := range store {
+		subs = append(subs, *sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].Name < subs[j].Name
+	})
+	for i := range subs {
+		s.resolveNextRun(ctx, &subs[i])
+	}
+	return subs, nil
+}
+
+// Get returns one subscription with its next run
+func (s *ExportSubscriptionService) Get(ctx context.Context, id string) (*ExportSubscription, error) {
+	s.mu.Lock()
+	store, err := s.load(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sub, ok := store[id]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	s.resolveNextRun(ctx, sub)
+	return sub, nil
+}
+
+// Create adds a subscription
+func (s *ExportSubscriptionService) Create(ctx context.Context, in SubscriptionInput) (*ExportSubscription, error) {
+	schedule, err := in.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	sub := &ExportSubscription{
+		ID:        uuid.New().String(),
+		Name:      in.Name,
+		Export:    in.Export,
+		Schedule:  schedule.String(),
+		Enabled:   in.Enabled == nil || *in.Enabled,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	store[sub.ID] = sub
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	s.resolveNextRun(ctx, sub)
+	return sub, nil
+}
+
+// Update replaces the editable fields of a subscription
+func (s *ExportSubscriptionService) Update(ctx context.Context, id string, in SubscriptionInput) (*ExportSubscription, error) {
+	schedule, err := in.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sub, ok := store[id]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	sub.Name = in.Name
+	sub.Export = in.Export
+	sub.Schedule = schedule.String()
+	if in.Enabled != nil {
+		sub.Enabled = *in.Enabled
+	}
+	sub.UpdatedAt = s.now().UTC()
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	s.resolveNextRun(ctx, sub)
+	return sub, nil
+}
+
+// Delete removes a subscription. Reports it already delivered are kept.
+func (s *ExportSubscriptionService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := store[id]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(store, id)
+	return s.save(ctx, store)
+}
+
+// Preview resolves the next count runs of a schedule without saving anything
+func (s *ExportSubscriptionService) Preview(ctx context.Context, spec string, count int) ([]ScheduledRun, error) {
+	schedule, err := ParseExportSchedule(spec)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.calendar.Calendar(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Upcoming(s.now(), count, calendar), nil
+}
+
+// RunDue delivers every enabled subscription of the request's profile whose
+// schedule came due since its last run. A subscription that missed several
+// runs, for example while the server was down, only delivers the latest one.
+// It returns the number of subscriptions delivered.
+func (s *ExportSubscriptionService) RunDue(ctx context.Context) (int, error) {
+	calendar, err := s.calendar.Calendar(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	delivered := 0
+	changed := false
+	for _, sub := range store {
+		if !sub.Enabled {
+			continue
+		}
+		schedule, err := ParseExportSchedule(sub.Schedule)
+		if err != nil {
+			continue // Validated on write; a hand-edited store is skipped
+		}
+
+		since := sub.CreatedAt
+		if sub.LastRunAt != nil {
+			since = *sub.LastRunAt
+		}
+
+		var due *ScheduledRun
+		for run, ok := schedule.Next(since, calendar); ok && !run.At.After(now); run, ok = schedule.Next(run.At, calendar) {
+			r := run
+			due = &r
+		}
+		if due == nil {
+			continue
+		}
+
+		ranAt := now.UTC()
+		sub.LastRunAt = &ranAt
+		sub.LastDataDate = due.DataDate
+		changed = true
+
+		file, err := s.deliver(ctx, sub, due.DataDate)
+		if err != nil {
+			sub.LastError = err.Error()
+			s.logger.WarnContext(ctx, "Export subscription failed",
+				slog.String("subscription_id", sub.ID),
+				slog.String("export", sub.Export),
+				slog.String("data_date", due.DataDate),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		sub.LastFile = file
+		sub.LastError = ""
+		delivered++
+		s.logger.InfoContext(ctx, "Export subscription delivered",
+			slog.String("subscription_id", sub.ID),
+			slog.String("export", sub.Export),
+			slog.String("data_date", due.DataDate),
+			slog.String("file", file))
+	}
+
+	if changed {
+		if err := s.save(ctx, store); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// Start checks for due subscriptions in every profile until Stop is called
+func (s *ExportSubscriptionService) Start(ctx context.Context) {
+	s.loopMu.Lock()
+	if s.stop != nil {
+		s.loopMu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	stop, done := s.stop, s.done
+	s.loopMu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.runAllProfiles(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the scheduling loop and waits for a run in progress to finish
+func (s *ExportSubscriptionService) Stop() {
+	s.loopMu.Lock()
+	stop, done := s.stop, s.done
+	s.stop = nil
+	s.done = nil
+	s.loopMu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// runAllProfiles runs due subscriptions of every profile
+func (s *ExportSubscriptionService) runAllProfiles(ctx context.Context) {
+	profiles, err := s.paths.ListProfiles()
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to list profiles for export subscriptions", slog.String("error", err.Error()))
+		return
+	}
+	for _, profile := range profiles {
+		if _, err := s.RunDue(config.WithProfile(ctx, profile)); err != nil {
+			s.logger.WarnContext(ctx, "Failed to run export subscriptions",
+				slog.String("profile", profile),
+				slog.String("error", err.Error()))
+		}
+	}
+}
+
+// deliver copies the subscription's report into its export directory
+func (s *ExportSubscriptionService) deliver(ctx context.Context, sub *ExportSubscription, dataDate string) (string, error) {
+	paths := s.paths.ForContext(ctx)
+
+	var source string
+	switch sub.Export {
+	case ExportTickerSummary:
+		source = paths.TickerSummaryCSV
+	case ExportIndexes:
+		source = paths.IndexCSV
+	case ExportCombined:
+		source = paths.CombinedDataCSV
+	default:
+		return "", fmt.Errorf("%w: unknown export %q", ErrInvalidInput, sub.Export)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s", ErrFileNotFound, filepath.Base(source))
+		}
+		return "", fmt.Errorf("failed to open report: %w", err)
+	}
+	defer in.Close()
+
+	dir := filepath.Join(paths.ExportsDir, sub.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	target := filepath.Join(dir, dataDate+"_"+filepath.Base(source))
+	tmp := target + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return "", fmt.Errorf("failed to replace export: %w", err)
+	}
+	return target, nil
+}
+
+// resolveNextRun fills in the next scheduled run of an enabled subscription
+func (s *ExportSubscriptionService) resolveNextRun(ctx context.Context, sub *ExportSubscription) {
+	sub.NextRun = nil
+	if !sub.Enabled {
+		return
+	}
+	schedule, err := ParseExportSchedule(sub.Schedule)
+	if err != nil {
+		return
+	}
+	calendar, err := s.calendar.Calendar(ctx)
+	if err != nil {
+		return
+	}
+	if run, ok := schedule.Next(s.now(), calendar); ok {
+		sub.NextRun = &run
+	}
+}
+
+func (s *ExportSubscriptionService) storePath(ctx context.Context) string {
+	return filepath.Join(s.paths.ForContext(ctx).DataDir, subscriptionsFileName)
+}
+
+// load reads the subscription store; callers hold s.mu
+func (s *ExportSubscriptionService) load(ctx context.Context) (map[string]*ExportSubscription, error) {
+	store := make(map[string]*ExportSubscription)
+
+	data, err := os.ReadFile(s.storePath(ctx))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	var subs []*ExportSubscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return nil, fmt.Errorf("failed to parse subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		store[sub.ID] = sub
+	}
+	return store, nil
+}
+
+// save writes the subscription store through a temp file; callers hold s.mu
+func (s *ExportSubscriptionService) save(ctx context.Context, store map[string]*ExportSubscription) error {
+	path := s.storePath(ctx)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create subscription directory: %w", err)
+	}
+
+	subs := make([]*ExportSubscription, 0, len(store))
+	for _, sub := range store {
+		stored := *sub
+		stored.NextRun = nil
+		subs = append(subs, &stored)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(subs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode subscriptions: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write subscriptions: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace subscriptions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+func newTestSubscriptionService(t *testing.T, now *time.Time) (*ExportSubscriptionService, *config.Paths) {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		DataDir:          dir,
+		ExportsDir:       filepath.Join(dir, "exports"),
+		HolidaysFile:     filepath.Join(dir, "holidays.txt"),
+		TickerSummaryCSV: filepath.Join(dir, "ticker_summary.csv"),
+	}
+	require.NoError(t, os.WriteFile(paths.HolidaysFile, []byte("2025-03-31\n2025-04-01\n"), 0644))
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	service := NewExportSubscriptionService(paths, NewTradingCalendarService(paths, logger), time.Minute, logger)
+	service.now = func() time.Time { return *now }
+	return service, paths
+}
+
+func TestExportSubscriptionService_CRUD(t *testing.T) {
+	ctx := context.Background()
+	now := marketTime(2025, 3, 30, 10, 0)
+	service, _ := newTestSubscriptionService(t, &now)
+
+	_, err := service.Create(ctx, SubscriptionInput{Name: "Daily", Export: "pdf", Schedule: "T+0 at 17:00"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.Create(ctx, SubscriptionInput{Name: "Daily", Export: ExportTickerSummary, Schedule: "0 17 * * *"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	sub, err := service.Create(ctx, SubscriptionInput{Name: "Daily", Export: ExportTickerSummary, Schedule: "T+0 at 17:00"})
+	require.NoError(t, err)
+	assert.True(t, sub.Enabled)
+	require.NotNil(t, sub.NextRun)
+	assert.Equal(t, "2025-03-30", sub.NextRun.DataDate)
+
+	disabled := false
+	sub, err = service.Update(ctx, sub.ID, SubscriptionInput{Name: "Daily", Export: ExportTickerSummary, Schedule: "T+0 at 17:00", Enabled: &disabled})
+	require.NoError(t, err)
+	assert.False(t, sub.Enabled)
+	assert.Nil(t, sub.NextRun)
+
+	subs, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+
+	require.NoError(t, service.Delete(ctx, sub.ID))
+	_, err = service.Get(ctx, sub.ID)
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+	assert.ErrorIs(t, service.Delete(ctx, sub.ID), ErrSubscriptionNotFound)
+}
+
+func TestExportSubscriptionService_RunDue(t *testing.T) {
+	ctx := context.Background()
+	now := marketTime(2025, 3, 30, 10, 0)
+	service, paths := newTestSubscriptionService(t, &now)
+	require.NoError(t, os.WriteFile(paths.TickerSummaryCSV, []byte("Ticker,LastPrice\nBBOB,1.2\n"), 0644))
+
+	sub, err := service.Create(ctx, SubscriptionInput{Name: "Daily", Export: ExportTickerSummary, Schedule: "T+0 at 17:00"})
+	require.NoError(t, err)
+
+	delivered, err := service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered, "not due before the cut-off")
+
+	now = marketTime(2025, 3, 30, 17, 5)
+	delivered, err = service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	sub, err = service.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-30", sub.LastDataDate)
+	assert.Equal(t, filepath.Join(paths.ExportsDir, sub.ID, "2025-03-30_ticker_summary.csv"), sub.LastFile)
+	_, err = os.Stat(sub.LastFile)
+	require.NoError(t, err)
+
+	// Holidays send nothing
+	now = marketTime(2025, 4, 1, 18, 0)
+	delivered, err = service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	// After missing several sessions only the latest is delivered
+	now = marketTime(2025, 4, 7, 18, 0)
+	delivered, err = service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	sub, err = service.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-04-07", sub.LastDataDate)
+
+	// A missing report is recorded on the subscription
+	require.NoError(t, os.Remove(paths.TickerSummaryCSV))
+	now = marketTime(2025, 4, 8, 18, 0)
+	delivered, err = service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	sub, err = service.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Contains(t, sub.LastError, "ticker_summary.csv")
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+	"isxcli/internal/operations"
+)
+
+// HealthSeverity grades a detailed health check
+type HealthSeverity string
+
+// Health severities, from best to worst
+const (
+	HealthSeverityOK       HealthSeverity = "ok"
+	HealthSeverityWarning  HealthSeverity = "warning"
+	HealthSeverityCritical HealthSeverity = "critical"
+)
+
+// worse reports whether s is more severe than other
+func (s HealthSeverity) worse(other HealthSeverity) bool {
+	rank := map[HealthSeverity]int{HealthSeverityOK: 0, HealthSeverityWarning: 1, HealthSeverityCritical: 2}
+	return rank[s] > rank[other]
+}
+
+// Detailed health check names
+const (
+	HealthCheckDataFreshness = "data_freshness"
+	HealthCheckPipeline      = "pipeline"
+	HealthCheckLicense       = "license"
+	HealthCheckDisk          = "disk"
+)
+
+// reportPublishHour is the hour, Baghdad time, after which the day's daily
+// report is expected on the ISX site
+const reportPublishHour = 15
+
+// baghdad is the exchange's time zone (UTC+3, no daylight saving)
+var baghdad = time.FixedZone("AST", 3*60*60)
+
+// pipelineHealthStages are the steps whose last success the pipeline check reports
+var pipelineHealthStages = []string{
+	operations.StageIDScraping,
+	operations.StageIDProcessing,
+	operations.StageIDIndices,
+	operations.StageIDLiquidity,
+}
+
+// HealthCheckResult is the outcome of one detailed health check
+type HealthCheckResult struct {
+	Name     string                 `json:"name"`
+	Severity HealthSeverity         `json:"severity"`
+	Message  string                 `json:"message"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// DetailedHealth is the response of /api/v1/health/detailed. Status is the
+// most severe of the checks.
+type DetailedHealth struct {
+	Status        HealthSeverity      `json:"status"`
+	Timestamp     time.Time           `json:"timestamp"`
+	Version       string              `json:"version"`
+	UptimeSeconds float64             `json:"uptime_seconds"`
+	Checks        []HealthCheckResult `json:"checks"`
+}
+
+// DetailedHealthOptions are the dependencies of the detailed health checks
+type DetailedHealthOptions struct {
+	Paths      *config.Paths
+	Calendar   *TradingCalendarService // Optional; weekends only without it
+	Thresholds config.HealthConfig
+
+	extra []DetailedHealthCheckFunc
+}
+
+// DetailedHealthCheckFunc is an additional check contributed by a subsystem
+type DetailedHealthCheckFunc func(ctx context.Context) HealthCheckResult
+
+// ConfigureDetailedChecks sets the data paths, trading calendar and
+// thresholds used by DetailedHealthCheck. Zero thresholds use the defaults.
+func (hs *HealthService) ConfigureDetailedChecks(opts DetailedHealthOptions) {
+	opts.Thresholds = withHealthDefaults(opts.Thresholds)
+	opts.extra = hs.detailed.extra
+	hs.detailed = opts
+}
+
+// AddDetailedCheck appends a check to the detailed health report. Checks are
+// added while the application is wired, before the server starts.
+func (hs *HealthService) AddDetailedCheck(check DetailedHealthCheckFunc) {
+	hs.detailed.extra = append(hs.detailed.extra, check)
+}
+
+// withHealthDefaults replaces zero thresholds with the configuration defaults
+func withHealthDefaults(t config.HealthConfig) config.HealthConfig {
+	defaults := config.Default().Health
+	if t.StaleWarningDays == 0 {
+		t.StaleWarningDays = defaults.StaleWarningDays
+	}
+	if t.StaleCriticalDays == 0 {
+		t.StaleCriticalDays = defaults.StaleCriticalDays
+	}
+	if t.LicenseWarningDays == 0 {
+		t.LicenseWarningDays = defaults.LicenseWarningDays
+	}
+	if t.LicenseCriticalDays == 0 {
+		t.LicenseCriticalDays = defaults.LicenseCriticalDays
+	}
+	if t.DiskWarningPercent == 0 {
+		t.DiskWarningPercent = defaults.DiskWarningPercent
+	}
+	if t.DiskCriticalPercent == 0 {
+		t.DiskCriticalPercent = defaults.DiskCriticalPercent
+	}
+	return t
+}
+
+// DetailedHealthCheck checks data freshness, the last success of each
+// pipeline stage, the license and free disk space
+func (hs *HealthService) DetailedHealthCheck(ctx context.Context) DetailedHealth {
+	return hs.detailedHealthAt(ctx, time.Now())
+}
+
+// detailedHealthAt runs the detailed checks as of now
+func (hs *HealthService) detailedHealthAt(ctx context.Context, now time.Time) DetailedHealth {
+	thresholds := withHealthDefaults(hs.detailed.Thresholds)
+
+	health := DetailedHealth{
+		Status:        HealthSeverityOK,
+		Timestamp:     now.UTC(),
+		Version:       hs.version,
+		UptimeSeconds: time.Since(hs.startTime).Seconds(),
+	}
+
+	paths := hs.detailed.Paths
+	if paths == nil {
+		paths, _ = config.GetPaths()
+	}
+	if paths != nil {
+		paths = paths.ForContext(ctx)
+	}
+
+	health.Checks = []HealthCheckResult{
+		hs.checkDataFreshness(ctx, paths, thresholds, now),
+		hs.checkPipeline(paths),
+		hs.checkLicense(ctx, thresholds),
+		hs.checkDisk(paths, thresholds),
+	}
+	for _, check := range hs.detailed.extra {
+		health.Checks = append(health.Checks, check(ctx))
+	}
+	for _, check := range health.Checks {
+		if check.Severity.worse(health.Status) {
+			health.Status = check.Severity
+		}
+	}
+	return health
+}
+
+// checkDataFreshness compares the latest downloaded report with the trading
+// day whose report should already be published
+func (hs *HealthService) checkDataFreshness(ctx context.Context, paths *config.Paths, thresholds config.HealthConfig, now time.Time) HealthCheckResult {
+	result := HealthCheckResult{Name: HealthCheckDataFreshness}
+	if paths == nil {
+		result.Severity, result.Message = HealthSeverityCritical, "Data paths are not available"
+		return result
+	}
+
+	dates, err := files.DownloadedReportDates(paths.DownloadsDir)
+	if err != nil || len(dates) == 0 {
+		result.Severity, result.Message = HealthSeverityCritical, "No daily reports have been downloaded"
+		return result
+	}
+	var latest time.Time
+	for d := range dates {
+		if date, err := time.Parse("2006-01-02", d); err == nil && date.After(latest) {
+			latest = date
+		}
+	}
+
+	calendar := files.NewHolidayCalendar()
+	if hs.detailed.Calendar != nil {
+		if c, err := hs.detailed.Calendar.Calendar(ctx); err == nil {
+			calendar = c
+		}
+	}
+	expected := expectedReportDay(now, calendar)
+
+	behind := 0
+	if expected.After(latest) {
+		behind = len(calendar.TradingDays(latest.AddDate(0, 0, 1), expected))
+	}
+
+	result.Details = map[string]interface{}{
+		"latest_report":       latest.Format("2006-01-02"),
+		"expected_report":     expected.Format("2006-01-02"),
+		"trading_days_behind": behind,
+		"age_days":            int(dayOf(now.In(baghdad)).Sub(latest).Hours() / 24),
+	}
+	switch {
+	case behind >= thresholds.StaleCriticalDays:
+		result.Severity = HealthSeverityCritical
+	case behind >= thresholds.StaleWarningDays:
+		result.Severity = HealthSeverityWarning
+	default:
+		result.Severity = HealthSeverityOK
+	}
+	if behind == 0 {
+		result.Message = fmt.Sprintf("Latest report %s is current", latest.Format("2006-01-02"))
+	} else {
+		result.Message = fmt.Sprintf("Latest report %s is %d trading days behind %s", latest.Format("2006-01-02"), behind, expected.Format("2006-01-02"))
+	}
+	return result
+}
+
+// expectedReportDay returns the latest trading day whose report should be
+// published by now: today after the publish hour, otherwise the previous
+// trading day
+func expectedReportDay(now time.Time, calendar *files.HolidayCalendar) time.Time {
+	local := now.In(baghdad)
+	today := dayOf(local)
+	if calendar.IsTradingDay(today) && local.Hour() >= reportPublishHour {
+		return today
+	}
+	if previous, ok := calendar.PreviousTradingDay(today); ok {
+		return previous
+	}
+	return today
+}
+
+// dayOf returns the calendar date of t as midnight UTC
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// checkPipeline reports the last success of each pipeline stage. Stages that
+// have not run since startup fall back to the age of the files they write.
+func (hs *HealthService) checkPipeline(paths *config.Paths) HealthCheckResult {
+	result := HealthCheckResult{Name: HealthCheckPipeline, Severity: HealthSeverityOK}
+
+	var outcomes map[string]operations.StepOutcome
+	if hs.operation != nil {
+		outcomes = hs.operation.StepOutcomes()
+	}
+
+	stages := make(map[string]interface{}, len(pipelineHealthStages))
+	var failing, neverRun []string
+	for _, id := range pipelineHealthStages {
+		outcome := outcomes[id]
+		stage := map[string]interface{}{"last_success": nil, "source": "operation"}
+
+		if outcome.LastSuccess.IsZero() {
+			if at := stageArtifactTime(paths, id); !at.IsZero() {
+				stage["last_success"] = at.UTC()
+				stage["source"] = "files"
+			} else {
+				neverRun = append(neverRun, id)
+			}
+		} else {
+			stage["last_success"] = outcome.LastSuccess.UTC()
+		}
+
+		if !outcome.LastFailure.IsZero() {
+			stage["last_failure"] = outcome.LastFailure.UTC()
+			stage["last_error"] = outcome.LastError
+			if outcome.LastFailure.After(outcome.LastSuccess) {
+				failing = append(failing, id)
+			}
+		}
+		stages[id] = stage
+	}
+	result.Details = map[string]interface{}{"stages": stages}
+
+	switch {
+	case len(failing) > 0:
+		result.Severity = HealthSeverityWarning
+		result.Message = "Last run failed: " + strings.Join(failing, ", ")
+	case len(neverRun) > 0:
+		result.Severity = HealthSeverityWarning
+		result.Message = "Never completed: " + strings.Join(neverRun, ", ")
+	default:
+		result.Message = "Every stage has completed successfully"
+	}
+	return result
+}
+
+// stageArtifactTime returns when a stage last wrote its output, or the zero
+// time when it has none
+func stageArtifactTime(paths *config.Paths, stageID string) time.Time {
+	if paths == nil {
+		return time.Time{}
+	}
+	switch stageID {
+	case operations.StageIDScraping:
+		return newestFileTime(paths.DownloadsDir, "*.xlsx")
+	case operations.StageIDProcessing:
+		return fileModTime(paths.CombinedDataCSV)
+	case operations.StageIDIndices:
+		return fileModTime(paths.IndexCSV)
+	case operations.StageIDLiquidity:
+		return newestFileTime(paths.LiquidityReportsDir, "*.csv")
+	}
+	return time.Time{}
+}
+
+// fileModTime returns a file's modification time, or zero if it is missing
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// newestFileTime returns the latest modification time of the files in dir
+// matching pattern
+func newestFileTime(dir, pattern string) time.Time {
+	matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+	var newest time.Time
+	for _, m := range matches {
+		if t := fileModTime(m); t.After(newest) {
+			newest = t
+		}
+	}
+	return newest
+}
+
+// checkLicense grades the days left on the license
+func (hs *HealthService) checkLicense(ctx context.Context, thresholds config.HealthConfig) HealthCheckResult {
+	result := HealthCheckResult{Name: HealthCheckLicense}
+	if hs.licenseManager == nil {
+		result.Severity, result.Message = HealthSeverityWarning, "License manager is not available"
+		return result
+	}
+
+	status, _ := hs.LicenseStatus(ctx)
+	result.Details = map[string]interface{}{
+		"days_left":   status.DaysLeft,
+		"expiry_date": status.ExpiryDate,
+		"status":      status.Status,
+	}
+	backend := hs.licenseManager.BackendStatus()
+	if backend.Degraded {
+		result.Details["backend"] = backend
+	}
+
+	switch {
+	case !status.IsValid:
+		result.Severity = HealthSeverityCritical
+		result.Message = "License is not valid"
+		if status.Message != "" {
+			result.Message += ": " + status.Message
+		}
+	case status.DaysLeft <= thresholds.LicenseCriticalDays:
+		result.Severity = HealthSeverityCritical
+		result.Message = fmt.Sprintf("License expires in %d days", status.DaysLeft)
+	case status.DaysLeft <= thresholds.LicenseWarningDays:
+		result.Severity = HealthSeverityWarning
+		result.Message = fmt.Sprintf("License expires in %d days", status.DaysLeft)
+	case backend.Degraded:
+		result.Severity = HealthSeverityWarning
+		result.Message = fmt.Sprintf("License valid for %d days; license backend unreachable, running in degraded mode", status.DaysLeft)
+	default:
+		result.Severity = HealthSeverityOK
+		result.Message = fmt.Sprintf("License valid for %d days", status.DaysLeft)
+	}
+	return result
+}
+
+// checkDisk grades the free space on the data directory's disk
+func (hs *HealthService) checkDisk(paths *config.Paths, thresholds config.HealthConfig) HealthCheckResult {
+	result := HealthCheckResult{Name: HealthCheckDisk}
+	if paths == nil {
+		result.Severity, result.Message = HealthSeverityWarning, "Data paths are not available"
+		return result
+	}
+
+	space, err := files.DiskUsage(paths.DataDir)
+	if err != nil {
+		result.Severity = HealthSeverityWarning
+		result.Message = fmt.Sprintf("Cannot read free space of %s: %v", paths.DataDir, err)
+		return result
+	}
+
+	free := space.FreePercent()
+	result.Details = map[string]interface{}{
+		"path":         paths.DataDir,
+		"total_bytes":  space.TotalBytes,
+		"free_bytes":   space.FreeBytes,
+		"free_percent": float64(int(free*10)) / 10,
+	}
+
+	switch {
+	case free <= thresholds.DiskCriticalPercent:
+		result.Severity = HealthSeverityCritical
+	case free <= thresholds.DiskWarningPercent:
+		result.Severity = HealthSeverityWarning
+	default:
+		result.Severity = HealthSeverityOK
+	}
+	result.Message = fmt.Sprintf("%.1f%% free (%d MB)", free, space.FreeBytes/(1024*1024))
+	return result
+}
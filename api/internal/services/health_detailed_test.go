@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+	"isxcli/internal/operations"
+)
+
+// detailedHealthPaths creates a data tree with daily reports for the given dates
+func detailedHealthPaths(t *testing.T, reportDates ...string) *config.Paths {
+	t.Helper()
+	dataDir := t.TempDir()
+	paths := &config.Paths{
+		DataDir:             dataDir,
+		DownloadsDir:        filepath.Join(dataDir, "downloads"),
+		CombinedDataCSV:     filepath.Join(dataDir, "reports", "isx_combined_data.csv"),
+		IndexCSV:            filepath.Join(dataDir, "reports", "indexes.csv"),
+		LiquidityReportsDir: filepath.Join(dataDir, "reports", "liquidity_reports"),
+	}
+	require.NoError(t, os.MkdirAll(paths.DownloadsDir, 0755))
+	for _, d := range reportDates {
+		date, err := time.Parse("2006-01-02", d)
+		require.NoError(t, err)
+		name := date.Format("2006 01 02") + " ISX Daily Report.xlsx"
+		require.NoError(t, os.WriteFile(filepath.Join(paths.DownloadsDir, name), []byte("x"), 0644))
+	}
+	return paths
+}
+
+func findCheck(t *testing.T, health DetailedHealth, name string) HealthCheckResult {
+	t.Helper()
+	for _, c := range health.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %s not found", name)
+	return HealthCheckResult{}
+}
+
+func TestExpectedReportDay(t *testing.T) {
+	calendar := files.NewHolidayCalendar(time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"trading day before publish", time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC), "2025-03-03"},
+		{"trading day after publish", time.Date(2025, 3, 4, 13, 0, 0, 0, time.UTC), "2025-03-04"},
+		{"Friday", time.Date(2025, 3, 7, 13, 0, 0, 0, time.UTC), "2025-03-06"},
+		{"Sunday morning", time.Date(2025, 3, 9, 6, 0, 0, 0, time.UTC), "2025-03-06"},
+		{"holiday", time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC), "2025-03-30"},
+		{"UTC evening is next Baghdad day", time.Date(2025, 3, 4, 22, 0, 0, 0, time.UTC), "2025-03-04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, expectedReportDay(tt.now, calendar).Format("2006-01-02"))
+		})
+	}
+}
+
+func TestDetailedHealthDataFreshness(t *testing.T) {
+	// Thursday 2025-03-06 after publication
+	now := time.Date(2025, 3, 6, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		reports  []string
+		severity HealthSeverity
+		behind   int
+	}{
+		{"current", []string{"2025-03-05", "2025-03-06"}, HealthSeverityOK, 0},
+		{"one day behind", []string{"2025-03-05"}, HealthSeverityWarning, 1},
+		{"three days behind", []string{"2025-03-03"}, HealthSeverityCritical, 3},
+		{"weekend is not counted", []string{"2025-02-27"}, HealthSeverityCritical, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs := NewHealthServiceWithLogger("test", "", nil)
+			hs.ConfigureDetailedChecks(DetailedHealthOptions{Paths: detailedHealthPaths(t, tt.reports...)})
+
+			check := findCheck(t, hs.detailedHealthAt(context.Background(), now), HealthCheckDataFreshness)
+			assert.Equal(t, tt.severity, check.Severity, check.Message)
+			assert.Equal(t, tt.behind, check.Details["trading_days_behind"])
+			assert.Equal(t, "2025-03-06", check.Details["expected_report"])
+		})
+	}
+
+	t.Run("no reports", func(t *testing.T) {
+		hs := NewHealthServiceWithLogger("test", "", nil)
+		hs.ConfigureDetailedChecks(DetailedHealthOptions{Paths: detailedHealthPaths(t)})
+
+		check := findCheck(t, hs.detailedHealthAt(context.Background(), now), HealthCheckDataFreshness)
+		assert.Equal(t, HealthSeverityCritical, check.Severity)
+	})
+
+	t.Run("custom thresholds", func(t *testing.T) {
+		hs := NewHealthServiceWithLogger("test", "", nil)
+		hs.ConfigureDetailedChecks(DetailedHealthOptions{
+			Paths:      detailedHealthPaths(t, "2025-03-03"),
+			Thresholds: config.HealthConfig{StaleWarningDays: 3, StaleCriticalDays: 10},
+		})
+
+		check := findCheck(t, hs.detailedHealthAt(context.Background(), now), HealthCheckDataFreshness)
+		assert.Equal(t, HealthSeverityWarning, check.Severity)
+	})
+}
+
+func TestDetailedHealthPipelineFallsBackToArtifacts(t *testing.T) {
+	paths := detailedHealthPaths(t, "2025-03-06")
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths.CombinedDataCSV), 0755))
+	require.NoError(t, os.WriteFile(paths.CombinedDataCSV, []byte("Date\n"), 0644))
+
+	hs := NewHealthServiceWithLogger("test", "", nil)
+	hs.ConfigureDetailedChecks(DetailedHealthOptions{Paths: paths})
+
+	check := findCheck(t, hs.DetailedHealthCheck(context.Background()), HealthCheckPipeline)
+	assert.Equal(t, HealthSeverityWarning, check.Severity)
+	assert.Contains(t, check.Message, operations.StageIDIndices)
+	assert.Contains(t, check.Message, operations.StageIDLiquidity)
+
+	stages := check.Details["stages"].(map[string]interface{})
+	scraping := stages[operations.StageIDScraping].(map[string]interface{})
+	assert.Equal(t, "files", scraping["source"])
+	assert.NotNil(t, scraping["last_success"])
+	assert.Nil(t, stages[operations.StageIDIndices].(map[string]interface{})["last_success"])
+}
+
+func TestDetailedHealthOverallStatus(t *testing.T) {
+	hs := NewHealthServiceWithLogger("1.2.3", "", nil)
+	hs.ConfigureDetailedChecks(DetailedHealthOptions{Paths: detailedHealthPaths(t)})
+
+	health := hs.DetailedHealthCheck(context.Background())
+	assert.Equal(t, "1.2.3", health.Version)
+	assert.Len(t, health.Checks, 4)
+	assert.Equal(t, HealthSeverityCritical, health.Status, "missing reports make the whole report critical")
+
+	license := findCheck(t, health, HealthCheckLicense)
+	assert.Equal(t, HealthSeverityWarning, license.Severity, "no license manager")
+
+	disk := findCheck(t, health, HealthCheckDisk)
+	assert.Contains(t, []HealthSeverity{HealthSeverityOK, HealthSeverityWarning, HealthSeverityCritical}, disk.Severity)
+	assert.Equal(t, hs.detailed.Paths.DataDir, disk.Details["path"])
+}
@@ -21,6 +21,8 @@ type HealthService struct {
 	repoURL        string
 	buildTime      string
 	buildID        string
+	release        ReleaseInfo
+	detailed       DetailedHealthOptions
 	paths          config.PathsConfig
 	licenseManager *license.Manager
 	operation       *operations.Manager
@@ -29,6 +31,13 @@ type HealthService struct {
 	logger         *slog.Logger
 }
 
+// ReleaseInfo identifies what is deployed beyond the version string
+type ReleaseInfo struct {
+	Channel         string // Update channel, stable or beta
+	BuildHash       string // VCS revision the binary was built from
+	FrontendVersion string // Build ID of the embedded frontend
+}
+
 // HealthStatus represents the health status response
 type HealthStatus struct {
 	Status    string                 `json:"status"`
@@ -38,6 +47,16 @@ type HealthStatus struct {
 	Services  map[string]interface{} `json:"services,omitempty"`
 }
 
+// PublicStatus is the minimal, credential-free status served at /status.json.
+// It deliberately carries no license details beyond a validity flag.
+type PublicStatus struct {
+	Status              string     `json:"status"`
+	Version             string     `json:"version"`
+	UptimeSeconds       float64    `json:"uptime_seconds"`
+	LastPipelineSuccess *time.Time `json:"last_pipeline_success"`
+	LicenseValid        bool       `json:"license_valid"`
+}
+
 // ServiceHealth represents individual service health
 type ServiceHealth struct {
 	Status  string `json:"status"`
@@ -187,6 +206,12 @@ func (hs *HealthService) LivenessCheck(ctx context.Context) HealthStatus {
 	}
 }
 
+// SetReleaseInfo records the update channel, build hash and frontend
+// version reported by Version
+func (hs *HealthService) SetReleaseInfo(info ReleaseInfo) {
+	hs.release = info
+}
+
 // Version returns version information
 func (hs *HealthService) Version() map[string]interface{} {
 	result := map[string]interface{}{
@@ -207,6 +232,15 @@ func (hs *HealthService) Version() map[string]interface{} {
 	if hs.buildID != "" {
 		result["build_id"] = hs.buildID
 	}
+	if hs.release.Channel != "" {
+		result["channel"] = hs.release.Channel
+	}
+	if hs.release.BuildHash != "" {
+		result["build_hash"] = hs.release.BuildHash
+	}
+	if hs.release.FrontendVersion != "" {
+		result["frontend_version"] = hs.release.FrontendVersion
+	}
 	
 	return result
 }
@@ -236,6 +270,44 @@ func (hs *HealthService) LicenseStatus(ctx context.Context) (LicenseStatus, erro
 	}, nil
 }
 
+// PublicStatus returns the minimal status for unauthenticated uptime monitors
+func (hs *HealthService) PublicStatus(ctx context.Context) PublicStatus {
+	status := PublicStatus{
+		Status:        "ok",
+		Version:       hs.version,
+		UptimeSeconds: time.Since(hs.startTime).Seconds(),
+	}
+
+	if last := hs.lastPipelineSuccess(); !last.IsZero() {
+		status.LastPipelineSuccess = &last
+	}
+
+	if hs.licenseManager != nil {
+		if licenseStatus, err := hs.LicenseStatus(ctx); err == nil {
+			status.LicenseValid = licenseStatus.IsValid
+		}
+	}
+
+	return status
+}
+
+// lastPipelineSuccess returns the last successful operation time. After a restart
+// it falls back to the modification time of the combined dataset.
+func (hs *HealthService) lastPipelineSuccess() time.Time {
+	if hs.operation != nil {
+		if last := hs.operation.LastSuccessfulRun(); !last.IsZero() {
+			return last.UTC()
+		}
+	}
+
+	combined := filepath.Join(hs.paths.DataDir, "reports", "combined", "isx_combined_data.csv")
+	info, err := os.Stat(combined)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime().UTC()
+}
+
 // SystemStats returns system statistics
 func (hs *HealthService) SystemStats(ctx context.Context) (SystemStats, error) {
 	dataDir := hs.paths.DataDir
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/dataprocessing"
+)
+
+// ErrIndexNotFound is returned for an index that indexes.csv has no column for
+var ErrIndexNotFound = apierrors.Newf(apierrors.NotFound, "index not found")
+
+// ErrIndexMetricsNotFound is returned when indexcsv has not written indexes_analytics.csv yet
+var ErrIndexMetricsNotFound = apierrors.Newf(apierrors.NotFound, "index analytics not found")
+
+// IndexSummary is the latest level of one market or sector index
+type IndexSummary struct {
+	Name          string    `json:"name"`
+	Label         string    `json:"label"`
+	Date          time.Time `json:"date"`
+	Value         float64   `json:"value"`
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"changePercent"`
+	Points        int       `json:"points"`
+}
+
+// IndexPoint is one published level of an index
+type IndexPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// IndexSeries is the history of one index
+type IndexSeries struct {
+	Name   string       `json:"name"`
+	Label  string       `json:"label"`
+	Points []IndexPoint `json:"points"`
+}
+
+// IndexAnalytics is the daily performance history of one index
+type IndexAnalytics struct {
+	Name   string                               `json:"name"`
+	Label  string                               `json:"label"`
+	Points []dataprocessing.IndexPerformanceRow `json:"points"`
+}
+
+// IndexService serves the market and sector indices extracted by indexcsv
+type IndexService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+}
+
+// NewIndexService creates a new index service
+func NewIndexService(paths *config.Paths, logger *slog.Logger) *IndexService {
+	return &IndexService{
+		paths:  paths,
+		logger: logger,
+	}
+}
+
+// List returns the latest level of every index with at least one published
+// value, in indexes.csv column order
+func (s *IndexService) List(ctx context.Context) ([]IndexSummary, error) {
+	columns, rows, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]IndexSummary, 0, len(columns))
+	for _, name := range columns {
+		points := seriesPoints(rows, name, time.Time{}, time.Time{})
+		if len(points) == 0 {
+			continue
+		}
+
+		last := points[len(points)-1]
+		summary := IndexSummary{
+			Name:   name,
+			Label:  indexLabel(name),
+			Date:   last.Date,
+			Value:  last.Value,
+			Points: len(points),
+		}
+		if len(points) > 1 {
+			prev := points[len(points)-2].Value
+			summary.Change = last.Value - prev
+			summary.ChangePercent = summary.Change / prev * 100
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// Series returns the history of one index between from and to inclusive.
+// The name is matched case-insensitively; zero bounds are open.
+func (s *IndexService) Series(ctx context.Context, name string, from, to time.Time) (*IndexSeries, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+
+	columns, rows, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, column := range columns {
+		if strings.EqualFold(column, name) {
+			return &IndexSeries{
+				Name:   column,
+				Label:  indexLabel(column),
+				Points: seriesPoints(rows, column, from, to),
+			}, nil
+		}
+	}
+	return nil, ErrIndexNotFound
+}
+
+// Analytics returns the returns, volatility, drawdowns and moving averages
+// indexcsv computed for ISX60 and ISX15, limited to one index when name is
+// set. Metrics cover the whole history, so the first days of a range still
+// carry full windows.
+func (s *IndexService) Analytics(ctx context.Context, name string, from, to time.Time) ([]IndexAnalytics, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+
+	rows, err := dataprocessing.ReadIndexPerformanceCSV(s.paths.ForContext(ctx).GetIndexMetricsCSVPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrIndexMetricsNotFound
+		}
+		return nil, err
+	}
+
+	analytics := []IndexAnalytics{}
+	for _, index := range []string{dataprocessing.IndexISX60, dataprocessing.IndexISX15} {
+		if name != "" && !strings.EqualFold(name, index) {
+			continue
+		}
+		series := IndexAnalytics{Name: index, Label: indexLabel(index), Points: []dataprocessing.IndexPerformanceRow{}}
+		for _, row := range rows {
+			if row.Index != index || (!from.IsZero() && row.Date.Before(from)) || (!to.IsZero() && row.Date.After(to)) {
+				continue
+			}
+			series.Points = append(series.Points, row)
+		}
+		analytics = append(analytics, series)
+	}
+	if name != "" && len(analytics) == 0 {
+		return nil, ErrIndexNotFound
+	}
+	return analytics, nil
+}
+
+// load reads indexes.csv of the request's profile
+func (s *IndexService) load(ctx context.Context) ([]string, []dataprocessing.IndexTableRow, error) {
+	path := s.paths.ForContext(ctx).IndexCSV
+	columns, rows, err := dataprocessing.ReadIndexTable(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNoIndicesFound
+		}
+		return nil, nil, err
+	}
+	return columns, rows, nil
+}
+
+// seriesPoints collects the published values of one column within the range
+func seriesPoints(rows []dataprocessing.IndexTableRow, name string, from, to time.Time) []IndexPoint {
+	points := []IndexPoint{}
+	for _, row := range rows {
+		if (!from.IsZero() && row.Date.Before(from)) || (!to.IsZero() && row.Date.After(to)) {
+			continue
+		}
+		if v, ok := row.Values[name]; ok {
+			points = append(points, IndexPoint{Date: row.Date, Value: v})
+		}
+	}
+	return points
+}
+
+// indexLabel returns the display name of an index column
+func indexLabel(name string) string {
+	for _, def := range dataprocessing.IndexDefinitions() {
+		if def.Name == name {
+			return def.Label
+		}
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+func newTestIndexService(t *testing.T, content string) *IndexService {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		DataDir:         dir,
+		IndexCSV:        filepath.Join(dir, "indexes.csv"),
+		IndexMetricsCSV: filepath.Join(dir, "indexes_analytics.csv"),
+	}
+	if content != "" {
+		require.NoError(t, os.WriteFile(paths.IndexCSV, []byte(content), 0644))
+	}
+	return NewIndexService(paths, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+const testIndexCSV = `Date,ISX60,ISX15,Banking,Telecom
+2025-01-07,1020.00,510.00,80.00,
+2025-01-05,1000.00,500.00,,
+2025-01-06,1010.00,,75.00,
+`
+
+func TestIndexService_List(t *testing.T) {
+	svc := newTestIndexService(t, testIndexCSV)
+
+	summaries, err := svc.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, summaries, 3, "indices never published are omitted")
+
+	assert.Equal(t, "ISX60", summaries[0].Name)
+	assert.Equal(t, "ISX 60", summaries[0].Label)
+	assert.Equal(t, 1020.0, summaries[0].Value)
+	assert.Equal(t, 10.0, summaries[0].Change)
+	assert.Equal(t, 3, summaries[0].Points)
+
+	// Change is measured against the previous published level
+	assert.Equal(t, "ISX15", summaries[1].Name)
+	assert.Equal(t, 10.0, summaries[1].Change)
+	assert.Equal(t, 2.0, summaries[1].ChangePercent)
+
+	assert.Equal(t, "Banking", summaries[2].Name)
+	assert.Equal(t, time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), summaries[2].Date)
+	assert.Equal(t, 2, summaries[2].Points)
+}
+
+func TestIndexService_Series(t *testing.T) {
+	svc := newTestIndexService(t, testIndexCSV)
+	ctx := context.Background()
+
+	series, err := svc.Series(ctx, "banking", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "Banking", series.Name)
+	assert.Equal(t, []IndexPoint{
+		{Date: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Value: 75},
+		{Date: time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), Value: 80},
+	}, series.Points)
+
+	series, err = svc.Series(ctx, "ISX60", time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, series.Points, 1)
+
+	series, err = svc.Series(ctx, "Telecom", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, series.Points)
+
+	_, err = svc.Series(ctx, "Cement", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrIndexNotFound)
+
+	_, err = svc.Series(ctx, "ISX60", time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestIndexService_NoFile(t *testing.T) {
+	svc := newTestIndexService(t, "")
+
+	_, err := svc.List(context.Background())
+	assert.ErrorIs(t, err, ErrNoIndicesFound)
+}
+
+func TestIndexService_Analytics(t *testing.T) {
+	svc := newTestIndexService(t, testIndexCSV)
+	ctx := context.Background()
+
+	_, err := svc.Analytics(ctx, "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrIndexMetricsNotFound)
+
+	metrics := "Date,Index,Level,Return,CumulativeReturn,Volatility,Drawdown,MaxDrawdown,MA20,MA50\n" +
+		"2025-01-05,ISX60,1000.00,0.0000,0.0000,,0.0000,0.0000,,\n" +
+		"2025-01-06,ISX60,1010.00,1.0000,1.0000,,0.0000,0.0000,,\n" +
+		"2025-01-05,ISX15,500.00,0.0000,0.0000,,0.0000,0.0000,,\n"
+	require.NoError(t, os.WriteFile(svc.paths.IndexMetricsCSV, []byte(metrics), 0644))
+
+	analytics, err := svc.Analytics(ctx, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, analytics, 2)
+	assert.Equal(t, "ISX 60", analytics[0].Label)
+	assert.Len(t, analytics[0].Points, 2)
+	assert.Len(t, analytics[1].Points, 1)
+
+	analytics, err = svc.Analytics(ctx, "isx60", time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, analytics, 1)
+	require.Len(t, analytics[0].Points, 1)
+	assert.InDelta(t, 1.0, analytics[0].Points[0].Return, 0.0001)
+
+	_, err = svc.Analytics(ctx, "Banking", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrIndexNotFound)
+}
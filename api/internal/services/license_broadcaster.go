@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/license"
+)
+
+// LicenseStatusEventType is the WebSocket message type for license status pushes
+const LicenseStatusEventType = "license_status"
+
+// DefaultLicenseBroadcastInterval is how often license status is pushed to clients
+const DefaultLicenseBroadcastInterval = time.Minute
+
+// LicenseStatusSource is the subset of the license manager the broadcaster needs
+type LicenseStatusSource interface {
+	GetLicenseStatus() (*license.LicenseInfo, string, error)
+	GetGraceStatus() (*license.GraceStatus, error)
+}
+
+// UpdateBroadcaster sends typed updates to all WebSocket clients
+type UpdateBroadcaster interface {
+	BroadcastUpdate(updateType, subtype, action string, data interface{})
+}
+
+// LicenseStatusEvent is the payload of a license_status WebSocket message
+type LicenseStatusEvent struct {
+	Status         string               `json:"status"` // active|warning|critical|expired|not_activated
+	PreviousStatus string               `json:"previous_status,omitempty"`
+	Transition     bool                 `json:"transition"`
+	DaysLeft       int                  `json:"days_left"`
+	ExpiryDate     *time.Time           `json:"expiry_date,omitempty"`
+	NeedsRenewal   bool                 `json:"needs_renewal"`
+	GracePeriod    *license.GraceStatus `json:"grace_period,omitempty"`
+	// Backend is set while the license backend is unreachable and the local
+	// license is used in degraded mode
+	Backend *license.BackendStatus `json:"backend,omitempty"`
+}
+
+// backendStatusSource is implemented by license sources that can run without
+// their backend (the license manager)
+type backendStatusSource interface {
+	BackendStatus() license.BackendStatus
+}
+
+// LicenseStatusBroadcaster periodically pushes license status over the
+// WebSocket hub so the frontend can show renewal and offline banners without
+// polling. Status changes (e.g. warning → critical, grace period entered) are
+// sent with action "transition"; regular pushes use action "update".
+type LicenseStatusBroadcaster struct {
+	source   LicenseStatusSource
+	hub      UpdateBroadcaster
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu          sync.Mutex
+	lastStatus  string
+	lastGrace   bool
+	initialized bool
+
+	onTransition func(ctx context.Context, event LicenseStatusEvent)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewLicenseStatusBroadcaster creates a broadcaster; interval <= 0 uses DefaultLicenseBroadcastInterval
+func NewLicenseStatusBroadcaster(source LicenseStatusSource, hub UpdateBroadcaster, interval time.Duration, logger *slog.Logger) *LicenseStatusBroadcaster {
+	if interval <= 0 {
+		interval = DefaultLicenseBroadcastInterval
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &LicenseStatusBroadcaster{
+		source:   source,
+		hub:      hub,
+		interval: interval,
+		logger:   logger.With(slog.String("component", "license_broadcaster")),
+	}
+}
+
+// OnTransition registers a callback invoked whenever the license status changes,
+// e.g. to invalidate cached license snapshots when a license expires
+func (b *LicenseStatusBroadcaster) OnTransition(fn func(ctx context.Context, event LicenseStatusEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onTransition = fn
+}
+
+// Start begins periodic broadcasting. It sends the current status immediately.
+func (b *LicenseStatusBroadcaster) Start(ctx context.Context) {
+	b.mu.Lock()
+	if b.stop != nil {
+		b.mu.Unlock()
+		return
+	}
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	stop, done := b.stop, b.done
+	b.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+
+		b.Broadcast(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.Broadcast(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends broadcasting and waits for the loop to exit
+func (b *LicenseStatusBroadcaster) Stop() {
+	b.mu.Lock()
+	stop, done := b.stop, b.done
+	b.stop = nil
+	b.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Broadcast pushes the current license status once
+func (b *LicenseStatusBroadcaster) Broadcast(ctx context.Context) {
+	event := b.Snapshot()
+
+	action := "update"
+	if event.Transition {
+		action = "transition"
+		b.logger.WarnContext(ctx, "License status changed",
+			slog.String("previous_status", event.PreviousStatus),
+			slog.String("status", event.Status),
+			slog.Int("days_left", event.DaysLeft),
+			slog.Bool("grace_period", event.GracePeriod != nil && event.GracePeriod.Active))
+
+		b.mu.Lock()
+		onTransition := b.onTransition
+		b.mu.Unlock()
+		if onTransition != nil {
+			onTransition(ctx, event)
+		}
+	}
+
+	if b.hub != nil {
+		b.hub.BroadcastUpdate(LicenseStatusEventType, event.Status, action, event)
+	}
+}
+
+// Snapshot reads the current license status and records it for transition detection
+func (b *LicenseStatusBroadcaster) Snapshot() LicenseStatusEvent {
+	event := LicenseStatusEvent{Status: "not_activated", NeedsRenewal: true}
+
+	info, managerStatus, err := b.source.GetLicenseStatus()
+	if err == nil && info != nil {
+		expiry := info.ExpiryDate
+		event.Status = normalizeLicenseStatus(managerStatus)
+		event.DaysLeft = int(time.Until(expiry).Hours() / 24)
+		event.ExpiryDate = &expiry
+		event.NeedsRenewal = event.Status != "active"
+
+		if grace, err := b.source.GetGraceStatus(); err == nil {
+			event.GracePeriod = grace
+		}
+	}
+
+	if source, ok := b.source.(backendStatusSource); ok {
+		if backend := source.BackendStatus(); backend.Degraded {
+			event.Backend = &backend
+		}
+	}
+
+	graceActive := event.GracePeriod != nil && event.GracePeriod.Active
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.initialized && (event.Status != b.lastStatus || graceActive != b.lastGrace) {
+		event.Transition = true
+		event.PreviousStatus = b.lastStatus
+	}
+	b.lastStatus, b.lastGrace, b.initialized = event.Status, graceActive, true
+
+	return event
+}
+
+// normalizeLicenseStatus maps manager statuses ("Active", "Not Activated") to API statuses
+func normalizeLicenseStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "active", "warning", "critical", "expired":
+		return strings.ToLower(status)
+	default:
+		return "not_activated"
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/license"
+)
+
+type fakeLicenseSource struct {
+	mu     sync.Mutex
+	status string
+	expiry time.Time
+	grace  *license.GraceStatus
+	err    error
+}
+
+func (f *fakeLicenseSource) GetLicenseStatus() (*license.LicenseInfo, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, "Not Activated", nil
+	}
+	return &license.LicenseInfo{ExpiryDate: f.expiry}, f.status, nil
+}
+
+func (f *fakeLicenseSource) GetGraceStatus() (*license.GraceStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.grace == nil {
+		return nil, errors.New("no grace status")
+	}
+	return f.grace, nil
+}
+
+type recordedUpdate struct {
+	updateType, subtype, action string
+	data                        interface{}
+}
+
+type fakeUpdateHub struct {
+	mu      sync.Mutex
+	updates []recordedUpdate
+}
+
+func (h *fakeUpdateHub) BroadcastUpdate(updateType, subtype, action string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.updates = append(h.updates, recordedUpdate{updateType, subtype, action, data})
+}
+
+func (h *fakeUpdateHub) all() []recordedUpdate {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]recordedUpdate(nil), h.updates...)
+}
+
+func TestLicenseStatusBroadcaster_Transitions(t *testing.T) {
+	source := &fakeLicenseSource{status: "Warning", expiry: time.Now().Add(20 * 24 * time.Hour)}
+	hub := &fakeUpdateHub{}
+	b := NewLicenseStatusBroadcaster(source, hub, time.Hour, nil)
+	ctx := context.Background()
+
+	b.Broadcast(ctx)
+	b.Broadcast(ctx)
+
+	source.status = "Critical"
+	b.Broadcast(ctx)
+
+	source.grace = &license.GraceStatus{Active: true, RemainingSeconds: 3600}
+	b.Broadcast(ctx)
+
+	updates := hub.all()
+	require.Len(t, updates, 4)
+
+	assert.Equal(t, LicenseStatusEventType, updates[0].updateType)
+	assert.Equal(t, "update", updates[0].action, "first push is not a transition")
+	assert.Equal(t, "update", updates[1].action)
+
+	assert.Equal(t, "transition", updates[2].action)
+	event := updates[2].data.(LicenseStatusEvent)
+	assert.Equal(t, "critical", event.Status)
+	assert.Equal(t, "warning", event.PreviousStatus)
+	assert.True(t, event.NeedsRenewal)
+	assert.InDelta(t, 19, event.DaysLeft, 1)
+
+	assert.Equal(t, "transition", updates[3].action, "entering the grace period is a transition")
+	event = updates[3].data.(LicenseStatusEvent)
+	require.NotNil(t, event.GracePeriod)
+	assert.True(t, event.GracePeriod.Active)
+}
+
+func TestLicenseStatusBroadcaster_NotActivated(t *testing.T) {
+	b := NewLicenseStatusBroadcaster(&fakeLicenseSource{err: errors.New("missing")}, &fakeUpdateHub{}, 0, nil)
+
+	event := b.Snapshot()
+	assert.Equal(t, "not_activated", event.Status)
+	assert.Nil(t, event.ExpiryDate)
+	assert.Equal(t, DefaultLicenseBroadcastInterval, b.interval)
+}
+
+// degradedLicenseSource is a license source whose backend is unreachable
+type degradedLicenseSource struct {
+	fakeLicenseSource
+	backend license.BackendStatus
+}
+
+func (d *degradedLicenseSource) BackendStatus() license.BackendStatus {
+	return d.backend
+}
+
+func TestLicenseStatusBroadcaster_DegradedBackend(t *testing.T) {
+	source := &degradedLicenseSource{
+		fakeLicenseSource: fakeLicenseSource{status: "Active", expiry: time.Now().Add(90 * 24 * time.Hour)},
+		backend:           license.BackendStatus{Degraded: true, Attempts: 1, LastError: "no such host"},
+	}
+	b := NewLicenseStatusBroadcaster(source, &fakeUpdateHub{}, time.Hour, nil)
+
+	event := b.Snapshot()
+	assert.Equal(t, "active", event.Status, "a locally valid license stays active")
+	require.NotNil(t, event.Backend)
+	assert.True(t, event.Backend.Degraded)
+
+	source.backend = license.BackendStatus{Ready: true, Attempts: 2}
+	event = b.Snapshot()
+	assert.Nil(t, event.Backend)
+}
+
+func TestLicenseStatusBroadcaster_StartStop(t *testing.T) {
+	source := &fakeLicenseSource{status: "Active", expiry: time.Now().Add(90 * 24 * time.Hour)}
+	hub := &fakeUpdateHub{}
+	b := NewLicenseStatusBroadcaster(source, hub, 10*time.Millisecond, nil)
+
+	b.Start(context.Background())
+	assert.Eventually(t, func() bool { return len(hub.all()) >= 2 }, time.Second, 5*time.Millisecond)
+	b.Stop()
+
+	count := len(hub.all())
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, len(hub.all()), "no pushes after Stop")
+	b.Stop() // idempotent
+}
@@ -0,0 +1,531 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/license"
+	"isxcli/internal/security"
+)
+
+const (
+	fleetFileName = "license_fleet.json"
+
+	// maxFleetBatch caps the devices and keys of one activation request
+	maxFleetBatch = 500
+)
+
+// Fleet device states
+const (
+	FleetDeviceActive  = "active"
+	FleetDeviceFailed  = "failed"
+	FleetDevicePending = "pending" // waiting for a key
+)
+
+// Fleet key states
+const (
+	FleetKeyAvailable = "available"
+	FleetKeyConsumed  = "consumed"
+	FleetKeyRejected  = "rejected" // expired, invalid or activated elsewhere
+)
+
+// ErrFleetDeviceNotFound is returned for a fingerprint no batch included
+var ErrFleetDeviceNotFound = apierrors.Newf(apierrors.NotFound, "fleet device not found")
+
+// DeviceActivator activates a license key for a device other than this one
+// and identifies this device for inclusion in another server's batch
+type DeviceActivator interface {
+	ActivateForDevice(ctx context.Context, licenseKey string, device security.DeviceFingerprint) (license.LicenseInfo, error)
+	GetDeviceFingerprint() (*security.DeviceFingerprint, error)
+}
+
+// FleetDevice is the activation state of one managed device
+type FleetDevice struct {
+	Fingerprint string     `json:"fingerprint"`
+	Hostname    string     `json:"hostname,omitempty"`
+	Label       string     `json:"label,omitempty"`
+	OS          string     `json:"os,omitempty"`
+	Status      string     `json:"status"`
+	LicenseKey  string     `json:"license_key,omitempty"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// FleetKey is a scratch-card key in the activation pool
+type FleetKey struct {
+	Key     string     `json:"key"`
+	Status  string     `json:"status"`
+	Device  string     `json:"device,omitempty"` // fingerprint of the device that consumed it
+	Error   string     `json:"error,omitempty"`
+	AddedAt time.Time  `json:"added_at"`
+	UsedAt  *time.Time `json:"used_at,omitempty"`
+}
+
+// FleetDeviceInput identifies a device to activate
+type FleetDeviceInput struct {
+	Fingerprint string `json:"fingerprint"`
+	Hostname    string `json:"hostname"`
+	Label       string `json:"label"`
+	OS          string `json:"os"`
+}
+
+// FleetActivationInput is a batch of devices and the keys to activate them with
+type FleetActivationInput struct {
+	Devices []FleetDeviceInput `json:"devices"`
+	Keys    []string           `json:"keys"`
+}
+
+// Validate normalizes and checks the input
+func (in *FleetActivationInput) Validate() error {
+	if len(in.Devices) == 0 {
+		return fmt.Errorf("%w: at least one device is required", ErrInvalidInput)
+	}
+	if len(in.Devices) > maxFleetBatch || len(in.Keys) > maxFleetBatch {
+		return fmt.Errorf("%w: at most %d devices and %d keys per request", ErrInvalidInput, maxFleetBatch, maxFleetBatch)
+	}
+
+	seen := make(map[string]bool, len(in.Devices))
+	for i := range in.Devices {
+		d := &in.Devices[i]
+		d.Fingerprint = strings.TrimSpace(d.Fingerprint)
+		d.Hostname = strings.TrimSpace(d.Hostname)
+		d.Label = strings.TrimSpace(d.Label)
+		if d.Fingerprint == "" {
+			return fmt.Errorf("%w: device %d has no fingerprint", ErrInvalidInput, i+1)
+		}
+		if seen[d.Fingerprint] {
+			return fmt.Errorf("%w: device %s listed twice", ErrInvalidInput, d.Fingerprint)
+		}
+		seen[d.Fingerprint] = true
+	}
+
+	keys := make([]string, 0, len(in.Keys))
+	seenKeys := make(map[string]bool, len(in.Keys))
+	for i, key := range in.Keys {
+		key = license.NormalizeScratchCardKey(key)
+		if err := license.ValidateScratchCardFormat(key); err != nil {
+			return fmt.Errorf("%w: key %d: %v", ErrInvalidInput, i+1, err)
+		}
+		if !seenKeys[key] {
+			seenKeys[key] = true
+			keys = append(keys, key)
+		}
+	}
+	in.Keys = keys
+	return nil
+}
+
+// FleetActivationResult reports a batch activation
+type FleetActivationResult struct {
+	Activated     int           `json:"activated"`
+	AlreadyActive int           `json:"already_active"`
+	Failed        int           `json:"failed"`
+	Pending       int           `json:"pending"`
+	KeysAvailable int           `json:"keys_available"`
+	Devices       []FleetDevice `json:"devices"`
+}
+
+// FleetSummary counts devices and keys by state
+type FleetSummary struct {
+	Devices       int `json:"devices"`
+	Active        int `json:"active"`
+	Failed        int `json:"failed"`
+	Pending       int `json:"pending"`
+	KeysAvailable int `json:"keys_available"`
+	KeysConsumed  int `json:"keys_consumed"`
+	KeysRejected  int `json:"keys_rejected"`
+}
+
+// FleetOverview is the central view of all managed devices
+type FleetOverview struct {
+	Summary FleetSummary  `json:"summary"`
+	Devices []FleetDevice `json:"devices"`
+}
+
+// fleetStore is the persisted fleet state; keys keep their pool order
+type fleetStore struct {
+	Devices map[string]*FleetDevice `json:"devices"`
+	Keys    []*FleetKey             `json:"keys"`
+}
+
+// LicenseFleetService activates licenses for many devices from one place. It
+// keeps a pool of scratch-card keys and records which device consumed which
+// key in the data directory.
+type LicenseFleetService struct {
+	dataDir   string // license state is shared by all profiles
+	activator DeviceActivator
+	logger    *slog.Logger
+	now       func() time.Time
+
+	// mu guards the store file and the claims. It is never held while the
+	// license server is called; claims keep concurrent batches from trying
+	// the same key or device meanwhile.
+	mu             sync.Mutex
+	claimedKeys    map[string]bool
+	claimedDevices map[string]bool
+}
+
+// NewLicenseFleetService creates a fleet service storing its state in dataDir
+func NewLicenseFleetService(dataDir string, activator DeviceActivator, logger *slog.Logger) *LicenseFleetService {
+	return &LicenseFleetService{
+		dataDir:        dataDir,
+		activator:      activator,
+		logger:         logger,
+		now:            time.Now,
+		claimedKeys:    make(map[string]bool),
+		claimedDevices: make(map[string]bool),
+	}
+}
+
+// Activate adds the keys to the pool and activates every device that has no
+// license yet, each with the next available key. A key the license server
+// rejects is set aside and the next one tried; other failures leave the key
+// in the pool and mark the device failed, so a later request retries it.
+func (s *LicenseFleetService) Activate(ctx context.Context, in FleetActivationInput) (*FleetActivationResult, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.update(func(store *fleetStore) error {
+		now := s.now().UTC()
+		pooled := make(map[string]bool, len(store.Keys))
+		for _, k := range store.Keys {
+			pooled[k.Key] = true
+		}
+		for _, key := range in.Keys {
+			if !pooled[key] {
+				store.Keys = append(store.Keys, &FleetKey{Key: key, Status: FleetKeyAvailable, AddedAt: now})
+			}
+		}
+
+		for _, input := range in.Devices {
+			device := store.Devices[input.Fingerprint]
+			if device == nil {
+				device = &FleetDevice{Fingerprint: input.Fingerprint}
+				store.Devices[input.Fingerprint] = device
+			}
+			if input.Hostname != "" {
+				device.Hostname = input.Hostname
+			}
+			if input.Label != "" {
+				device.Label = input.Label
+			}
+			if input.OS != "" {
+				device.OS = input.OS
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FleetActivationResult{Devices: make([]FleetDevice, 0, len(in.Devices))}
+	for _, input := range in.Devices {
+		device, alreadyActive, err := s.activateDevice(ctx, input.Fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case alreadyActive:
+			result.AlreadyActive++
+		case device.Status == FleetDeviceActive:
+			result.Activated++
+		case device.Status == FleetDeviceFailed:
+			result.Failed++
+		default:
+			result.Pending++
+		}
+		result.Devices = append(result.Devices, maskFleetDevice(device))
+	}
+
+	keys, err := s.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if k.Status == FleetKeyAvailable {
+			result.KeysAvailable++
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Fleet activation finished",
+		slog.Int("devices", len(in.Devices)),
+		slog.Int("activated", result.Activated),
+		slog.Int("already_active", result.AlreadyActive),
+		slog.Int("failed", result.Failed),
+		slog.Int("pending", result.Pending),
+		slog.Int("keys_available", result.KeysAvailable))
+	return result, nil
+}
+
+// activateDevice tries the pool's available keys on a device in order,
+// saving each outcome so progress survives a later failure. A device another
+// batch is activating right now is returned as it stands.
+func (s *LicenseFleetService) activateDevice(ctx context.Context, fingerprint string) (device FleetDevice, alreadyActive bool, err error) {
+	for {
+		var key string
+		err := s.update(func(store *fleetStore) error {
+			d := store.Devices[fingerprint]
+			device = *d
+			if d.Status == FleetDeviceActive || s.claimedDevices[fingerprint] {
+				alreadyActive = d.Status == FleetDeviceActive
+				return nil
+			}
+			for _, k := range store.Keys {
+				if k.Status == FleetKeyAvailable && !s.claimedKeys[k.Key] {
+					key = k.Key
+					break
+				}
+			}
+			if key == "" {
+				d.Status = FleetDevicePending
+				d.Error = "no license key available"
+				d.UpdatedAt = s.now().UTC()
+				device = *d
+				return nil
+			}
+			s.claimedKeys[key] = true
+			s.claimedDevices[fingerprint] = true
+			return nil
+		})
+		if err != nil || key == "" {
+			return device, alreadyActive, err
+		}
+
+		info, activateErr := s.activator.ActivateForDevice(ctx, key, security.DeviceFingerprint{
+			Fingerprint: device.Fingerprint,
+			Hostname:    device.Hostname,
+			OS:          device.OS,
+		})
+
+		rejected := activateErr != nil && isKeyRejection(activateErr)
+		err = s.update(func(store *fleetStore) error {
+			delete(s.claimedKeys, key)
+			delete(s.claimedDevices, fingerprint)
+			s.recordActivation(ctx, store, store.Devices[fingerprint], key, info, activateErr)
+			device = *store.Devices[fingerprint]
+			return nil
+		})
+		if err != nil || !rejected {
+			return device, false, err
+		}
+	}
+}
+
+// recordActivation applies the license server's answer for key to device
+func (s *LicenseFleetService) recordActivation(ctx context.Context, store *fleetStore, device *FleetDevice, key string, info license.LicenseInfo, err error) {
+	var pooled *FleetKey
+	for _, k := range store.Keys {
+		if k.Key == key {
+			pooled = k
+			break
+		}
+	}
+	now := s.now().UTC()
+	device.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		pooled.Status = FleetKeyConsumed
+		pooled.Device = device.Fingerprint
+		pooled.UsedAt = &now
+		device.Status = FleetDeviceActive
+		device.LicenseKey = key
+		device.ActivatedAt = &now
+		device.Error = ""
+		if !info.ExpiryDate.IsZero() {
+			expiry := info.ExpiryDate
+			device.ExpiryDate = &expiry
+		}
+	case isKeyRejection(err):
+		s.logger.WarnContext(ctx, "License server rejected fleet key",
+			slog.String("key", license.MaskLicenseKey(key)),
+			slog.String("device", device.Fingerprint),
+			slog.String("error", err.Error()))
+		pooled.Status = FleetKeyRejected
+		pooled.Error = err.Error()
+		pooled.UsedAt = &now
+	default:
+		s.logger.WarnContext(ctx, "Fleet activation failed",
+			slog.String("device", device.Fingerprint),
+			slog.String("error", err.Error()))
+		device.Status = FleetDeviceFailed
+		device.Error = err.Error()
+	}
+}
+
+// isKeyRejection reports whether the license server refused the key itself,
+// as opposed to a network or server failure
+func isKeyRejection(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, reason := range []string{"already been activated", "expired", "invalid license key", "revoked"} {
+		if strings.Contains(msg, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overview returns every managed device by fingerprint with fleet totals
+func (s *LicenseFleetService) Overview(ctx context.Context) (*FleetOverview, error) {
+	s.mu.Lock()
+	store, err := s.load()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &FleetOverview{Devices: make([]FleetDevice, 0, len(store.Devices))}
+	for _, device := range store.Devices {
+		overview.Devices = append(overview.Devices, maskFleetDevice(*device))
+		switch device.Status {
+		case FleetDeviceActive:
+			overview.Summary.Active++
+		case FleetDeviceFailed:
+			overview.Summary.Failed++
+		default:
+			overview.Summary.Pending++
+		}
+	}
+	sort.Slice(overview.Devices, func(i, j int) bool {
+		return overview.Devices[i].Fingerprint < overview.Devices[j].Fingerprint
+	})
+	overview.Summary.Devices = len(store.Devices)
+	for _, key := range store.Keys {
+		switch key.Status {
+		case FleetKeyAvailable:
+			overview.Summary.KeysAvailable++
+		case FleetKeyConsumed:
+			overview.Summary.KeysConsumed++
+		case FleetKeyRejected:
+			overview.Summary.KeysRejected++
+		}
+	}
+	return overview, nil
+}
+
+// LocalDevice returns this machine as a device entry for a fleet activation
+// request, so admins can collect fingerprints from each installation
+func (s *LicenseFleetService) LocalDevice(ctx context.Context) (*FleetDeviceInput, error) {
+	fp, err := s.activator.GetDeviceFingerprint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device fingerprint: %w", err)
+	}
+	return &FleetDeviceInput{Fingerprint: fp.Fingerprint, Hostname: fp.Hostname, OS: fp.OS}, nil
+}
+
+// Device returns the activation state of one device
+func (s *LicenseFleetService) Device(ctx context.Context, fingerprint string) (*FleetDevice, error) {
+	s.mu.Lock()
+	store, err := s.load()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	device, ok := store.Devices[strings.TrimSpace(fingerprint)]
+	if !ok {
+		return nil, ErrFleetDeviceNotFound
+	}
+	masked := maskFleetDevice(*device)
+	return &masked, nil
+}
+
+// Keys returns the key pool in the order keys were added, masked
+func (s *LicenseFleetService) Keys(ctx context.Context) ([]FleetKey, error) {
+	s.mu.Lock()
+	store, err := s.load()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]FleetKey, len(store.Keys))
+	for i, key := range store.Keys {
+		keys[i] = *key
+		keys[i].Key = license.MaskLicenseKey(key.Key)
+	}
+	return keys, nil
+}
+
+// maskFleetDevice hides the license key of a device returned to callers
+func maskFleetDevice(device FleetDevice) FleetDevice {
+	if device.LicenseKey != "" {
+		device.LicenseKey = license.MaskLicenseKey(device.LicenseKey)
+	}
+	return device
+}
+
+func (s *LicenseFleetService) storePath() string {
+	return filepath.Join(s.dataDir, fleetFileName)
+}
+
+// update loads the store, applies fn and saves the result, all under s.mu
+func (s *LicenseFleetService) update(fn func(store *fleetStore) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(store); err != nil {
+		return err
+	}
+	return s.save(store)
+}
+
+// load reads the fleet store; callers hold s.mu
+func (s *LicenseFleetService) load() (*fleetStore, error) {
+	store := &fleetStore{Devices: make(map[string]*FleetDevice)}
+
+	data, err := os.ReadFile(s.storePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read license fleet: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse license fleet: %w", err)
+	}
+	if store.Devices == nil {
+		store.Devices = make(map[string]*FleetDevice)
+	}
+	return store, nil
+}
+
+// save writes the fleet store through a temp file; callers hold s.mu. The
+// file holds unused license keys, so only the owner may read it.
+func (s *LicenseFleetService) save(store *fleetStore) error {
+	path := s.storePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create license fleet directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode license fleet: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write license fleet: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace license fleet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/license"
+	"isxcli/internal/security"
+)
+
+const (
+	fleetKeyA = "ISX-AAAA-AAAA-AAAA"
+	fleetKeyB = "ISX-BBBB-BBBB-BBBB"
+	fleetKeyC = "ISX-CCCC-CCCC-CCCC"
+)
+
+// fakeActivator answers activations from a table of per-key errors
+type fakeActivator struct {
+	errs  map[string]error
+	calls []string
+}
+
+func (f *fakeActivator) ActivateForDevice(ctx context.Context, key string, device security.DeviceFingerprint) (license.LicenseInfo, error) {
+	f.calls = append(f.calls, key+"@"+device.Fingerprint)
+	if err := f.errs[key]; err != nil {
+		return license.LicenseInfo{}, err
+	}
+	return license.LicenseInfo{LicenseKey: key, ExpiryDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+func (f *fakeActivator) GetDeviceFingerprint() (*security.DeviceFingerprint, error) {
+	return &security.DeviceFingerprint{Fingerprint: "local-fp", Hostname: "admin-pc", OS: "linux"}, nil
+}
+
+func newTestFleet(t *testing.T, activator DeviceActivator) (*LicenseFleetService, string) {
+	dir := t.TempDir()
+	return NewLicenseFleetService(dir, activator, slog.New(slog.NewTextHandler(io.Discard, nil))), dir
+}
+
+func TestLicenseFleetService_Activate(t *testing.T) {
+	activator := &fakeActivator{errs: map[string]error{
+		license.NormalizeScratchCardKey(fleetKeyA): errors.New("this license has already been activated on a different device"),
+	}}
+	fleet, dir := newTestFleet(t, activator)
+	ctx := context.Background()
+
+	result, err := fleet.Activate(ctx, FleetActivationInput{
+		Devices: []FleetDeviceInput{
+			{Fingerprint: "dev-1", Hostname: "branch-1", Label: "Erbil"},
+			{Fingerprint: "dev-2"},
+			{Fingerprint: "dev-3"},
+		},
+		Keys: []string{fleetKeyA, fleetKeyB, "isx-cccc-cccc-cccc"},
+	})
+	require.NoError(t, err)
+
+	// dev-1 skips the rejected key A; dev-3 finds the pool empty
+	assert.Equal(t, 2, result.Activated)
+	assert.Equal(t, 1, result.Pending)
+	assert.Equal(t, 0, result.KeysAvailable)
+	require.Len(t, result.Devices, 3)
+	assert.Equal(t, FleetDeviceActive, result.Devices[0].Status)
+	assert.Equal(t, "branch-1", result.Devices[0].Hostname)
+	assert.Equal(t, "ISXBBBBB****", result.Devices[0].LicenseKey, "keys are masked")
+	assert.Equal(t, FleetDevicePending, result.Devices[2].Status)
+
+	overview, err := fleet.Overview(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FleetSummary{Devices: 3, Active: 2, Pending: 1, KeysConsumed: 2, KeysRejected: 1}, overview.Summary)
+
+	keys, err := fleet.Keys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.Equal(t, FleetKeyRejected, keys[0].Status)
+	assert.Equal(t, "dev-1", keys[1].Device)
+	assert.Equal(t, "dev-2", keys[2].Device)
+
+	info, err := os.Stat(filepath.Join(dir, fleetFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the store holds unused keys")
+
+	t.Run("later batch activates pending devices only", func(t *testing.T) {
+		activator.calls = nil
+		result, err := fleet.Activate(ctx, FleetActivationInput{
+			Devices: []FleetDeviceInput{{Fingerprint: "dev-1"}, {Fingerprint: "dev-3"}},
+			Keys:    []string{"ISX-DDDD-DDDD-DDDD"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.AlreadyActive)
+		assert.Equal(t, 1, result.Activated)
+		assert.Equal(t, []string{"ISXDDDDDDDDDDDD@dev-3"}, activator.calls)
+	})
+}
+
+func TestLicenseFleetService_NetworkFailureKeepsKey(t *testing.T) {
+	activator := &fakeActivator{errs: map[string]error{
+		license.NormalizeScratchCardKey(fleetKeyA): errors.New("activation request timed out - please check your internet connection and try again"),
+	}}
+	fleet, _ := newTestFleet(t, activator)
+	ctx := context.Background()
+
+	result, err := fleet.Activate(ctx, FleetActivationInput{
+		Devices: []FleetDeviceInput{{Fingerprint: "dev-1"}},
+		Keys:    []string{fleetKeyA},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.KeysAvailable)
+
+	device, err := fleet.Device(ctx, "dev-1")
+	require.NoError(t, err)
+	assert.Equal(t, FleetDeviceFailed, device.Status)
+	assert.Contains(t, device.Error, "timed out")
+
+	_, err = fleet.Device(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrFleetDeviceNotFound)
+
+	local, err := fleet.LocalDevice(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &FleetDeviceInput{Fingerprint: "local-fp", Hostname: "admin-pc", OS: "linux"}, local)
+}
+
+// blockingActivator holds every activation until release is closed
+type blockingActivator struct {
+	fakeActivator
+	started chan string
+	release chan struct{}
+}
+
+func (b *blockingActivator) ActivateForDevice(ctx context.Context, key string, device security.DeviceFingerprint) (license.LicenseInfo, error) {
+	b.started <- key
+	<-b.release
+	return license.LicenseInfo{LicenseKey: key}, nil
+}
+
+func TestLicenseFleetService_ActivateDoesNotBlockReads(t *testing.T) {
+	activator := &blockingActivator{started: make(chan string, 2), release: make(chan struct{})}
+	fleet, _ := newTestFleet(t, activator)
+	ctx := context.Background()
+
+	results := make(chan *FleetActivationResult, 2)
+	for _, fp := range []string{"dev-1", "dev-2"} {
+		go func(fp string) {
+			result, err := fleet.Activate(ctx, FleetActivationInput{
+				Devices: []FleetDeviceInput{{Fingerprint: fp}},
+				Keys:    []string{fleetKeyA, fleetKeyB},
+			})
+			assert.NoError(t, err)
+			results <- result
+		}(fp)
+	}
+
+	// Both batches reach the license server at once, each with its own key
+	first, second := <-activator.started, <-activator.started
+	assert.NotEqual(t, first, second)
+
+	overview, err := fleet.Overview(ctx)
+	require.NoError(t, err, "reads are served while activations are in flight")
+	assert.Equal(t, 2, overview.Summary.Devices)
+
+	close(activator.release)
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, 1, (<-results).Activated)
+	}
+	overview, err = fleet.Overview(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FleetSummary{Devices: 2, Active: 2, KeysConsumed: 2}, overview.Summary)
+}
+
+func TestFleetActivationInput_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input FleetActivationInput
+	}{
+		{"no devices", FleetActivationInput{Keys: []string{fleetKeyA}}},
+		{"missing fingerprint", FleetActivationInput{Devices: []FleetDeviceInput{{Hostname: "pc"}}}},
+		{"duplicate device", FleetActivationInput{Devices: []FleetDeviceInput{{Fingerprint: "a"}, {Fingerprint: " a "}}}},
+		{"malformed key", FleetActivationInput{Devices: []FleetDeviceInput{{Fingerprint: "a"}}, Keys: []string{"ABC-123"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.input.Validate(), ErrInvalidInput)
+		})
+	}
+
+	in := FleetActivationInput{Devices: []FleetDeviceInput{{Fingerprint: "a"}}, Keys: []string{fleetKeyC, "isxcccccccccccc"}}
+	require.NoError(t, in.Validate())
+	assert.Equal(t, []string{"ISXCCCCCCCCCCCC"}, in.Keys, "keys are normalized and deduplicated")
+}
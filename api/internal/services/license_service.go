@@ -23,6 +23,7 @@ type LicenseService interface {
 	GetStatus(ctx context.Context) (*LicenseStatusResponse, error)
 	Activate(ctx context.Context, key string) error
 	ValidateWithContext(ctx context.Context) (bool, error)
+	Snapshot(ctx context.Context) *license.StatusSnapshot
 	
 	// License stacking and management
 	CheckExistingLicense() (*license.ExistingLicenseInfo, error)
@@ -154,8 +155,9 @@ type LicenseDebugInfo struct {
 
 // licenseService implements LicenseService with enhanced capabilities
 type licenseService struct {
-	manager license.ManagerInterface
-	logger  *slog.Logger
+	manager   license.ManagerInterface
+	logger    *slog.Logger
+	snapshots *licenseSnapshotCache
 	
 	// Enhanced tracking
 	startTime          time.Time
@@ -174,6 +176,7 @@ func NewLicenseService(manager license.ManagerInterface, logger *slog.Logger) Li
 	return &licenseService{
 		manager:   manager,
 		logger:    logger.With(slog.String("service", "license")),
+		snapshots: newLicenseSnapshotCache(DefaultLicenseSnapshotTTL),
 		startTime: time.Now(),
 	}
 }
@@ -398,6 +401,9 @@ func (s *licenseService) Activate(ctx context.Context, key string) error {
 		slog.Duration("latency", time.Since(start)),
 	)
 	
+	// Drop the shared snapshot so the middleware stops rejecting requests immediately
+	s.snapshots.invalidate()
+	
 	return nil
 }
 
@@ -707,6 +713,7 @@ func (s *licenseService) TransferLicense(ctx context.Context, key string, force
 		}
 		
 		s.successCount++
+		s.snapshots.invalidate()
 		s.logger.InfoContext(ctx, "license transfer succeeded",
 			slog.String("trace_id", traceID),
 			slog.String("license_key", maskedKey),
@@ -757,6 +764,8 @@ func (s *licenseService) InvalidateCache(ctx context.Context) error {
 		slog.String("trace_id", traceID),
 		slog.String("operation", "invalidate_cache"))
 	
+	s.snapshots.invalidate()
+	
 	// If manager supports cache invalidation
 	if cacheManager, ok := s.manager.(*license.Manager); ok {
 		// Call manager's cache invalidation if available
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"isxcli/internal/license"
+)
+
+// DefaultLicenseSnapshotTTL is how long a shared license snapshot is reused before
+// the manager is asked again. Activation, transfer and status transitions
+// invalidate it explicitly, so the TTL only bounds drift from external changes.
+const DefaultLicenseSnapshotTTL = 30 * time.Second
+
+// licenseSnapshotCache holds the shared snapshot. Loads are serialized so a burst
+// of requests after expiry triggers a single manager validation. Each
+// invalidation starts a new generation, and a load started in an earlier one
+// is not stored, so it cannot bring back the state the invalidation dropped.
+type licenseSnapshotCache struct {
+	ttl time.Duration
+
+	mu         sync.RWMutex
+	current    *license.StatusSnapshot
+	generation uint64
+
+	loadMu sync.Mutex
+	loads  atomic.Int64
+}
+
+func newLicenseSnapshotCache(ttl time.Duration) *licenseSnapshotCache {
+	if ttl <= 0 {
+		ttl = DefaultLicenseSnapshotTTL
+	}
+	return &licenseSnapshotCache{ttl: ttl}
+}
+
+func (c *licenseSnapshotCache) get(now time.Time) *license.StatusSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.current.FreshAt(now, c.ttl) {
+		return c.current
+	}
+	return nil
+}
+
+// begin returns the generation a load starts in
+func (c *licenseSnapshotCache) begin() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// store keeps a snapshot loaded in generation unless the cache was
+// invalidated meanwhile. Failed validations are not kept, so the next
+// request tries again.
+func (c *licenseSnapshotCache) store(snapshot *license.StatusSnapshot, generation uint64) {
+	if snapshot.Err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.current = snapshot
+	}
+}
+
+func (c *licenseSnapshotCache) invalidate() {
+	c.mu.Lock()
+	c.current = nil
+	c.generation++
+	c.mu.Unlock()
+}
+
+// Snapshot returns the license validation result for this request. It prefers the
+// snapshot already attached to the request context (e.g. by the license
+// middleware), then the shared snapshot while it is fresh, and only then
+// validates through the manager.
+func (s *licenseService) Snapshot(ctx context.Context) *license.StatusSnapshot {
+	if snapshot, ok := license.SnapshotFromContext(ctx); ok {
+		return snapshot
+	}
+
+	if snapshot := s.snapshots.get(time.Now()); snapshot != nil {
+		return snapshot
+	}
+
+	s.snapshots.loadMu.Lock()
+	defer s.snapshots.loadMu.Unlock()
+
+	// Another request may have refreshed the snapshot while we waited
+	if snapshot := s.snapshots.get(time.Now()); snapshot != nil {
+		return snapshot
+	}
+
+	generation := s.snapshots.begin()
+	snapshot := s.loadSnapshot(ctx)
+	s.snapshots.store(snapshot, generation)
+	return snapshot
+}
+
+// loadSnapshot validates the license through the manager
+func (s *licenseService) loadSnapshot(ctx context.Context) *license.StatusSnapshot {
+	s.snapshots.loads.Add(1)
+
+	valid, err := s.manager.ValidateLicense()
+	snapshot := &license.StatusSnapshot{
+		Valid:     valid && err == nil,
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+
+	if snapshot.Valid {
+		if info, infoErr := s.manager.GetLicenseInfo(); infoErr == nil && info != nil {
+			snapshot.ExpiryDate = info.ExpiryDate
+		}
+	}
+
+	s.logger.DebugContext(ctx, "license snapshot refreshed",
+		slog.Bool("valid", snapshot.Valid),
+		slog.Bool("has_error", err != nil),
+		slog.Time("expiry_date", snapshot.ExpiryDate))
+
+	return snapshot
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/license"
+)
+
+// countingLicenseManager counts validations; delay models the file read and
+// signature check a real validation performs. With gate set, validations
+// read the license, then wait for a value from it; with fail set, they return
+// an error.
+type countingLicenseManager struct {
+	validations atomic.Int64
+	valid       atomic.Bool
+	fail        atomic.Bool
+	expiry      time.Time
+	delay       time.Duration
+	gate        chan struct{}
+}
+
+func newCountingLicenseManager(valid bool) *countingLicenseManager {
+	m := &countingLicenseManager{expiry: time.Now().AddDate(0, 6, 0)}
+	m.valid.Store(valid)
+	return m
+}
+
+func (m *countingLicenseManager) ValidateLicense() (bool, error) {
+	m.validations.Add(1)
+	if m.delay > 0 {
+		time.Sleep(m.delay)
+	}
+	valid := m.valid.Load()
+	if m.gate != nil {
+		<-m.gate
+	}
+	if m.fail.Load() {
+		return false, errors.New("license file unreadable")
+	}
+	return valid, nil
+}
+
+func (m *countingLicenseManager) GetLicenseInfo() (*license.LicenseInfo, error) {
+	return &license.LicenseInfo{ExpiryDate: m.expiry, Status: "Active"}, nil
+}
+
+func (m *countingLicenseManager) GetLicenseStatus() (*license.LicenseInfo, string, error) {
+	info, _ := m.GetLicenseInfo()
+	return info, "Active", nil
+}
+
+func (m *countingLicenseManager) ActivateLicense(key string) error {
+	m.valid.Store(true)
+	return nil
+}
+
+func (m *countingLicenseManager) CheckExistingLicense() (*license.ExistingLicenseInfo, error) {
+	return &license.ExistingLicenseInfo{HasLicense: m.valid.Load()}, nil
+}
+
+func (m *countingLicenseManager) GetLicensePath() string { return "" }
+
+func newSnapshotTestService(manager license.ManagerInterface) *licenseService {
+	return NewLicenseService(manager, slog.New(slog.NewTextHandler(io.Discard, nil))).(*licenseService)
+}
+
+func TestLicenseSnapshot_SharedWithinTTL(t *testing.T) {
+	manager := newCountingLicenseManager(true)
+	service := newSnapshotTestService(manager)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, service.Snapshot(ctx).Valid)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), manager.validations.Load(), "concurrent requests share one validation")
+	assert.Equal(t, manager.expiry, service.Snapshot(ctx).ExpiryDate)
+}
+
+func TestLicenseSnapshot_RequestScoped(t *testing.T) {
+	manager := newCountingLicenseManager(true)
+	service := newSnapshotTestService(manager)
+
+	attached := &license.StatusSnapshot{Valid: false, CheckedAt: time.Now()}
+	ctx := license.WithSnapshot(context.Background(), attached)
+
+	assert.Same(t, attached, service.Snapshot(ctx))
+	assert.Equal(t, int64(0), manager.validations.Load())
+}
+
+func TestLicenseSnapshot_Invalidation(t *testing.T) {
+	manager := newCountingLicenseManager(false)
+	service := newSnapshotTestService(manager)
+	ctx := context.Background()
+
+	assert.False(t, service.Snapshot(ctx).Valid)
+
+	// Activation drops the cached "invalid" snapshot straight away
+	require.NoError(t, service.Activate(ctx, "ISX1M02LYE1F9QJHR9D7Z"))
+	assert.True(t, service.Snapshot(ctx).Valid)
+	assert.Equal(t, int64(2), manager.validations.Load())
+
+	require.NoError(t, service.InvalidateCache(ctx))
+	service.Snapshot(ctx)
+	assert.Equal(t, int64(3), manager.validations.Load())
+}
+
+func TestLicenseSnapshot_InvalidationDuringLoad(t *testing.T) {
+	manager := newCountingLicenseManager(false)
+	manager.gate = make(chan struct{})
+	service := newSnapshotTestService(manager)
+	ctx := context.Background()
+
+	loaded := make(chan *license.StatusSnapshot)
+	go func() { loaded <- service.Snapshot(ctx) }()
+	require.Eventually(t, func() bool { return manager.validations.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The license changes while the first load is still validating
+	manager.valid.Store(true)
+	service.snapshots.invalidate()
+	manager.gate <- struct{}{}
+	assert.False(t, (<-loaded).Valid)
+
+	// The stale result was not stored, so the next request validates again
+	go func() { manager.gate <- struct{}{} }()
+	assert.True(t, service.Snapshot(ctx).Valid)
+	assert.Equal(t, int64(2), manager.validations.Load())
+}
+
+func TestLicenseSnapshot_ErrorsNotCached(t *testing.T) {
+	manager := newCountingLicenseManager(true)
+	manager.fail.Store(true)
+	service := newSnapshotTestService(manager)
+	ctx := context.Background()
+
+	assert.Error(t, service.Snapshot(ctx).Err)
+	manager.fail.Store(false)
+	snapshot := service.Snapshot(ctx)
+	assert.NoError(t, snapshot.Err)
+	assert.True(t, snapshot.Valid)
+	assert.Equal(t, int64(2), manager.validations.Load(), "a failed validation is retried")
+
+	service.Snapshot(ctx)
+	assert.Equal(t, int64(2), manager.validations.Load())
+}
+
+func TestLicenseSnapshot_Expiry(t *testing.T) {
+	manager := newCountingLicenseManager(true)
+	service := newSnapshotTestService(manager)
+	service.snapshots.ttl = time.Hour
+	ctx := context.Background()
+
+	manager.expiry = time.Now().Add(20 * time.Millisecond)
+	service.Snapshot(ctx)
+	service.Snapshot(ctx)
+	assert.Equal(t, int64(1), manager.validations.Load())
+
+	// A snapshot never outlives the license it describes
+	time.Sleep(30 * time.Millisecond)
+	service.Snapshot(ctx)
+	assert.Equal(t, int64(2), manager.validations.Load())
+}
+
+func TestStatusSnapshot_FreshAt(t *testing.T) {
+	now := time.Now()
+
+	var nilSnapshot *license.StatusSnapshot
+	assert.False(t, nilSnapshot.FreshAt(now, time.Minute))
+
+	snapshot := &license.StatusSnapshot{Valid: true, CheckedAt: now.Add(-10 * time.Second)}
+	assert.True(t, snapshot.FreshAt(now, time.Minute))
+	assert.False(t, snapshot.FreshAt(now, 5*time.Second))
+
+	snapshot.ExpiryDate = now.Add(-time.Second)
+	assert.False(t, snapshot.FreshAt(now, time.Minute))
+}
+
+// The benchmarks compare the per-request cost of validating through the manager
+// with the shared and request-scoped snapshots used by the license middleware.
+func BenchmarkLicenseValidation_Direct(b *testing.B) {
+	manager := newCountingLicenseManager(true)
+	manager.delay = 50 * time.Microsecond
+	service := newSnapshotTestService(manager)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = service.ValidateWithContext(ctx)
+	}
+	b.ReportMetric(float64(manager.validations.Load())/float64(b.N), "validations/op")
+}
+
+func BenchmarkLicenseSnapshot_Shared(b *testing.B) {
+	manager := newCountingLicenseManager(true)
+	manager.delay = 50 * time.Microsecond
+	service := newSnapshotTestService(manager)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = service.Snapshot(ctx)
+		}
+	})
+	b.ReportMetric(float64(manager.validations.Load())/float64(b.N), "validations/op")
+}
+
+func BenchmarkLicenseSnapshot_RequestScoped(b *testing.B) {
+	manager := newCountingLicenseManager(true)
+	manager.delay = 50 * time.Microsecond
+	service := newSnapshotTestService(manager)
+	ctx := license.WithSnapshot(context.Background(), service.Snapshot(context.Background()))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = service.Snapshot(ctx)
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Liquidity regimes derived from a stock's most recent trading activity
+const (
+	RegimeActive   = "active"
+	RegimeSporadic = "sporadic"
+	RegimeDormant  = "dormant"
+)
+
+// ValidRegimes lists the regimes accepted by LiquidityFilter.ExcludeRegimes
+var ValidRegimes = []string{RegimeActive, RegimeSporadic, RegimeDormant}
+
+// LiquidityFilter holds server-side data quality filters for liquidity endpoints.
+// Zero values disable the corresponding filter.
+type LiquidityFilter struct {
+	MinCoverage    float64  `json:"minCoverage,omitempty"`    // Minimum continuity in percent (0-100)
+	ExcludeRegimes []string `json:"excludeRegimes,omitempty"` // Regimes to drop, e.g. "dormant"
+	MinTradingDays int      `json:"minTradingDays,omitempty"` // Minimum trading days in the window
+}
+
+// IsEmpty reports whether no filter criteria are set
+func (f LiquidityFilter) IsEmpty() bool {
+	return f.MinCoverage <= 0 && len(f.ExcludeRegimes) == 0 && f.MinTradingDays <= 0
+}
+
+// Validate checks filter bounds and regime names
+func (f LiquidityFilter) Validate() error {
+	if f.MinCoverage < 0 || f.MinCoverage > 100 {
+		return fmt.Errorf("%w: minCoverage must be between 0 and 100", ErrInvalidInput)
+	}
+	if f.MinTradingDays < 0 {
+		return fmt.Errorf("%w: minTradingDays must not be negative", ErrInvalidInput)
+	}
+	for _, regime := range f.ExcludeRegimes {
+		if !isValidRegime(regime) {
+			return fmt.Errorf("%w: unknown regime %q (use: %s)", ErrInvalidInput, regime, strings.Join(ValidRegimes, ", "))
+		}
+	}
+	return nil
+}
+
+// Matches reports whether a stock passes all filter criteria
+func (f LiquidityFilter) Matches(stock StockRecommendation) bool {
+	if f.MinCoverage > 0 && stock.Continuity*100 < f.MinCoverage {
+		return false
+	}
+	if f.MinTradingDays > 0 && stock.TradingDays < f.MinTradingDays {
+		return false
+	}
+	for _, regime := range f.ExcludeRegimes {
+		if stock.Regime == regime {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyFilter removes stocks that fail the filter from the insights, including
+// their category references, and records the criteria in the response metadata
+func ApplyFilter(insights *LiquidityInsights, filter LiquidityFilter) {
+	if insights == nil || filter.IsEmpty() {
+		return
+	}
+
+	kept := make([]StockRecommendation, 0, len(insights.AllStocks))
+	keptSymbols := make(map[string]bool, len(insights.AllStocks))
+	for _, stock := range insights.AllStocks {
+		if filter.Matches(stock) {
+			kept = append(kept, stock)
+			keptSymbols[stock.Symbol] = true
+		}
+	}
+
+	insights.FilteredOut = len(insights.AllStocks) - len(kept)
+	insights.AllStocks = kept
+	insights.TotalStocks = len(kept)
+	insights.TopOpportunities = filterSymbols(insights.TopOpportunities, keptSymbols)
+	insights.BestForLargeTrades = filterSymbols(insights.BestForLargeTrades, keptSymbols)
+	insights.BestForDayTrading = filterSymbols(insights.BestForDayTrading, keptSymbols)
+	insights.HighRisk = filterSymbols(insights.HighRisk, keptSymbols)
+
+	f := filter
+	insights.Filters = &f
+}
+
+// filterSymbols keeps category symbol references that survived filtering.
+// Legacy non-symbol category values are returned unchanged.
+func filterSymbols(category interface{}, keep map[string]bool) interface{} {
+	symbols, ok := category.([]string)
+	if !ok {
+		return category
+	}
+	filtered := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if keep[symbol] {
+			filtered = append(filtered, symbol)
+		}
+	}
+	return filtered
+}
+
+// classifyRegime derives the trading regime from a stock's latest window
+func classifyRegime(stock StockRecommendation) string {
+	return regimeFor(stock.TradingDays, stock.Continuity)
+}
+
+// regimeFor classifies a window by its trading days and continuity (0-1)
+func regimeFor(tradingDays int, continuity float64) string {
+	switch {
+	case tradingDays == 0 || continuity < 0.1:
+		return RegimeDormant
+	case continuity < 0.5:
+		return RegimeSporadic
+	default:
+		return RegimeActive
+	}
+}
+
+// isValidRegime checks a regime name against ValidRegimes
+func isValidRegime(regime string) bool {
+	for _, r := range ValidRegimes {
+		if r == regime {
+			return true
+		}
+	}
+	return false
+}
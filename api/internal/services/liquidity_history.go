@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"isxcli/internal/liquidity"
+)
+
+// LiquidityHistoryQuery selects the history returned for one ticker
+type LiquidityHistoryQuery struct {
+	Symbol         string
+	Window         liquidity.Window // zero means every window
+	Limit          int              // most recent points per window; zero means all
+	TrendWindows   int
+	TrendThreshold float64
+}
+
+// LiquidityWindowHistory is the time series and trend for one calculation window
+type LiquidityWindowHistory struct {
+	Window string                   `json:"window"`
+	Points []liquidity.HistoryPoint `json:"points"`
+	Trend  liquidity.Trend          `json:"trend"`
+}
+
+// LiquidityHistory is how a ticker's liquidity score evolved across runs
+type LiquidityHistory struct {
+	Symbol  string                   `json:"symbol"`
+	Windows []LiquidityWindowHistory `json:"windows"`
+}
+
+// GetHistory returns the persisted metric history of a ticker grouped by
+// window, each with its trend over the last TrendWindows points
+func (s *LiquidityService) GetHistory(ctx context.Context, q LiquidityHistoryQuery) (*LiquidityHistory, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(q.Symbol))
+	path := liquidity.HistoryPath(s.dataDir)
+
+	points, err := liquidity.LoadHistory(path, symbol, q.Window)
+	if err != nil {
+		return nil, fmt.Errorf("load liquidity history: %w", err)
+	}
+
+	s.logger.DebugContext(ctx, "Loaded liquidity history",
+		slog.String("symbol", symbol),
+		slog.String("path", path),
+		slog.Int("points", len(points)))
+
+	history := &LiquidityHistory{Symbol: symbol, Windows: []LiquidityWindowHistory{}}
+	// LoadHistory orders by window then date, so windows arrive contiguous
+	for start := 0; start < len(points); {
+		end := start
+		for end < len(points) && points[end].Window == points[start].Window {
+			end++
+		}
+		series := points[start:end]
+		trend := liquidity.DetectTrend(series, q.TrendWindows, q.TrendThreshold)
+		if q.Limit > 0 && len(series) > q.Limit {
+			series = series[len(series)-q.Limit:]
+		}
+		history.Windows = append(history.Windows, LiquidityWindowHistory{
+			Window: points[start].Window.String(),
+			Points: series,
+			Trend:  trend,
+		})
+		start = end
+	}
+
+	return history, nil
+}
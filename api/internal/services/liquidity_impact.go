@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/liquidity"
+)
+
+// MaxImpactHorizonDays bounds the execution horizon of an impact simulation,
+// about a year of trading days
+const MaxImpactHorizonDays = 250
+
+// ImpactSimulationRequest asks how trading a value in a ticker is expected to
+// move its price when spread over a horizon
+type ImpactSimulationRequest struct {
+	Symbol      string
+	TradeValue  float64          // in Currency
+	HorizonDays int              // most trading days the trade may take
+	Window      liquidity.Window // zero means 60d
+	Currency    currency.Code    // currency of the trade value and the plan; empty means IQD
+}
+
+// ImpactSimulationPlan is a simulated execution plan for one ticker from its
+// latest liquidity date. Values are in Currency at the rate of that date.
+type ImpactSimulationPlan struct {
+	Symbol        string        `json:"symbol"`
+	Date          string        `json:"date"`
+	Window        string        `json:"window"`
+	Currency      currency.Code `json:"currency"`
+	AvgDailyValue float64       `json:"avg_daily_value"`
+	HybridScore   float64       `json:"hybrid_score"`
+	liquidity.ImpactSimulation
+}
+
+// SimulateImpact estimates the price impact of trading a value in one ticker
+// from its most recent liquidity history point and suggests how to slice it
+// over the horizon
+func (s *LiquidityService) SimulateImpact(ctx context.Context, req ImpactSimulationRequest) (*ImpactSimulationPlan, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+	if req.TradeValue <= 0 {
+		return nil, fmt.Errorf("%w: trade value must be positive", ErrInvalidInput)
+	}
+	if req.HorizonDays <= 0 || req.HorizonDays > MaxImpactHorizonDays {
+		return nil, fmt.Errorf("%w: horizon must be between 1 and %d trading days", ErrInvalidInput, MaxImpactHorizonDays)
+	}
+	window := req.Window
+	if window == 0 {
+		window = liquidity.Window60
+	}
+
+	points, err := liquidity.LoadHistory(liquidity.HistoryPath(s.dataDir), symbol, window)
+	if err != nil {
+		return nil, fmt.Errorf("load liquidity history: %w", err)
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w for %s in window %s", ErrNoLiquidityData, symbol, window)
+	}
+	latest := points[len(points)-1]
+
+	cur := req.Currency
+	if cur == "" {
+		cur = currency.IQD
+	}
+	convert, err := s.rates.convertTo(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+	tradeIQD := req.TradeValue
+	if cur != currency.IQD {
+		tradeIQD = req.TradeValue / convert(1, latest.Date)
+	}
+
+	calculator := liquidity.NewCalculator(window, liquidity.DefaultPenaltyParams(), liquidity.DefaultWeights(), s.logger)
+	metrics := calculator.FromHistory(latest)
+	// The volume limit follows the ticker's market-wide score
+	metrics.HybridScore = latest.HybridScore
+
+	plan := &ImpactSimulationPlan{
+		Symbol:           symbol,
+		Date:             latest.Date.Format("2006-01-02"),
+		Window:           window.String(),
+		Currency:         cur,
+		AvgDailyValue:    latest.Value,
+		HybridScore:      latest.HybridScore,
+		ImpactSimulation: liquidity.SimulateImpact(metrics, tradeIQD, req.HorizonDays),
+	}
+	if cur != currency.IQD {
+		plan.AvgDailyValue = convert(plan.AvgDailyValue, latest.Date)
+		plan.TradeValue = req.TradeValue
+		for i := range plan.Schedule {
+			slice := &plan.Schedule[i]
+			slice.Value = convert(slice.Value, latest.Date)
+			slice.CumulativeValue = convert(slice.CumulativeValue, latest.Date)
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Simulated trade impact",
+		slog.String("symbol", symbol),
+		slog.String("date", plan.Date),
+		slog.Int("horizon_days", req.HorizonDays),
+		slog.Int("days", plan.Days),
+		slog.Float64("expected_impact_percent", plan.ExpectedImpactPercent))
+
+	return plan, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/liquidity"
+)
+
+func TestLiquidityService_SimulateImpact(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, liquidity.AppendHistory([]liquidity.TickerMetrics{{
+		Symbol:      "BBOB",
+		Date:        date,
+		Window:      liquidity.Window60,
+		ILLIQ:       0.5,
+		Value:       10_000_000,
+		Continuity:  1,
+		HybridScore: 75,
+		HybridRank:  1,
+		TradingDays: 60,
+		TotalDays:   60,
+	}}, liquidity.HistoryPath(dir), date))
+
+	service := NewLiquidityService(dir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	plan, err := service.SimulateImpact(ctx, ImpactSimulationRequest{Symbol: "bbob", TradeValue: 5_000_000, HorizonDays: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", plan.Symbol)
+	assert.Equal(t, "2025-03-02", plan.Date)
+	assert.Equal(t, currency.IQD, plan.Currency)
+	assert.Equal(t, 3, plan.Days, "2M IQD a day at the 20% limit")
+	assert.True(t, plan.WithinLimits)
+	require.Len(t, plan.Schedule, 3)
+	assert.Positive(t, plan.ExpectedImpactPercent)
+
+	t.Run("in USD", func(t *testing.T) {
+		usd, err := service.SimulateImpact(ctx, ImpactSimulationRequest{Symbol: "BBOB", TradeValue: 5_000_000.0 / 1300, HorizonDays: 10, Currency: currency.USD})
+		require.NoError(t, err)
+		assert.Equal(t, currency.USD, usd.Currency)
+		assert.InDelta(t, 5_000_000.0/1300, usd.TradeValue, 1e-6)
+		assert.InDelta(t, 5_000_000.0/1300, usd.Schedule[2].CumulativeValue, 1e-6)
+		assert.InDelta(t, plan.ExpectedImpactPercent, usd.ExpectedImpactPercent, 1e-9, "impacts do not depend on the currency")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		for _, req := range []ImpactSimulationRequest{
+			{Symbol: "BBOB", HorizonDays: 5},
+			{Symbol: "BBOB", TradeValue: 1, HorizonDays: 0},
+			{Symbol: "BBOB", TradeValue: 1, HorizonDays: MaxImpactHorizonDays + 1},
+			{TradeValue: 1, HorizonDays: 5},
+		} {
+			_, err := service.SimulateImpact(ctx, req)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		}
+	})
+
+	t.Run("unknown ticker", func(t *testing.T) {
+		_, err := service.SimulateImpact(ctx, ImpactSimulationRequest{Symbol: "XXXX", TradeValue: 1, HorizonDays: 5})
+		assert.ErrorIs(t, err, ErrNoLiquidityData)
+	})
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"isxcli/internal/liquidity"
+)
+
+// LiquidityLeagueRequest asks for the market's liquidity league table
+type LiquidityLeagueRequest struct {
+	Date   time.Time        // zero means the latest date in the history
+	Window liquidity.Window // zero means 60d
+	Tiers  int              // liquidity.Quintiles or liquidity.Deciles; zero means quintiles
+}
+
+// LiquidityLeagueEntry is one ticker of the league table
+type LiquidityLeagueEntry struct {
+	Symbol         string  `json:"symbol"`
+	Rank           int     `json:"rank"`
+	Score          float64 `json:"score"`
+	PercentileRank float64 `json:"percentile_rank"`
+	TradingDays    int     `json:"trading_days"`
+	TotalDays      int     `json:"total_days"`
+}
+
+// LiquidityTier groups the tickers of one quintile or decile, best first
+type LiquidityTier struct {
+	Tier     int                    `json:"tier"` // 1 is the most liquid
+	MinScore float64                `json:"min_score"`
+	MaxScore float64                `json:"max_score"`
+	Tickers  []LiquidityLeagueEntry `json:"tickers"`
+}
+
+// LiquidityLeagueTable is the whole market ranked by hybrid score on one
+// date and grouped into liquidity tiers
+type LiquidityLeagueTable struct {
+	Date    string          `json:"date"`
+	Window  string          `json:"window"`
+	Tiering string          `json:"tiering"` // quintile or decile
+	Count   int             `json:"count"`
+	Tiers   []LiquidityTier `json:"tiers"` // Every tier, empty ones included
+}
+
+// LeagueTable returns the cross-section of the liquidity history on one date
+// grouped into quintiles or deciles. Tiers are recomputed from the scores, so
+// history recorded before tiers were persisted is grouped the same way.
+func (s *LiquidityService) LeagueTable(ctx context.Context, req LiquidityLeagueRequest) (*LiquidityLeagueTable, error) {
+	window := req.Window
+	if window == 0 {
+		window = liquidity.Window60
+	}
+	tiers := req.Tiers
+	if tiers == 0 {
+		tiers = liquidity.Quintiles
+	}
+	if tiers != liquidity.Quintiles && tiers != liquidity.Deciles {
+		return nil, fmt.Errorf("%w: tiers must be %d or %d", ErrInvalidInput, liquidity.Quintiles, liquidity.Deciles)
+	}
+
+	points, date, err := liquidity.CrossSection(liquidity.HistoryPath(s.dataDir), window, req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("load liquidity history: %w", err)
+	}
+	if len(points) == 0 {
+		if req.Date.IsZero() {
+			return nil, fmt.Errorf("%w for window %s", ErrNoLiquidityData, window)
+		}
+		return nil, fmt.Errorf("%w for window %s on %s", ErrNoLiquidityData, window, req.Date.Format("2006-01-02"))
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].HybridScore != points[j].HybridScore {
+			return points[i].HybridScore > points[j].HybridScore
+		}
+		return points[i].Symbol < points[j].Symbol
+	})
+	scores := make([]float64, len(points))
+	for i, p := range points {
+		scores[i] = p.HybridScore
+	}
+
+	table := &LiquidityLeagueTable{
+		Date:    date.Format("2006-01-02"),
+		Window:  window.String(),
+		Tiering: "quintile",
+		Count:   len(points),
+		Tiers:   make([]LiquidityTier, tiers),
+	}
+	if tiers == liquidity.Deciles {
+		table.Tiering = "decile"
+	}
+	for i := range table.Tiers {
+		table.Tiers[i] = LiquidityTier{Tier: i + 1, Tickers: []LiquidityLeagueEntry{}}
+	}
+
+	for i, pct := range liquidity.PercentileRanks(scores) {
+		p := points[i]
+		tier := &table.Tiers[liquidity.Tier(pct, tiers)-1]
+		if len(tier.Tickers) == 0 {
+			tier.MaxScore = p.HybridScore
+		}
+		tier.MinScore = p.HybridScore
+		tier.Tickers = append(tier.Tickers, LiquidityLeagueEntry{
+			Symbol:         p.Symbol,
+			Rank:           i + 1,
+			Score:          p.HybridScore,
+			PercentileRank: pct,
+			TradingDays:    p.TradingDays,
+			TotalDays:      p.TotalDays,
+		})
+	}
+
+	s.logger.DebugContext(ctx, "Built liquidity league table",
+		slog.String("date", table.Date),
+		slog.String("window", table.Window),
+		slog.String("tiering", table.Tiering),
+		slog.Int("tickers", table.Count))
+
+	return table, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/liquidity"
+)
+
+func TestLiquidityService_LeagueTable(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	var metrics []liquidity.TickerMetrics
+	for i, symbol := range []string{"BBOB", "TASC", "BMFI", "IMAP", "HMAN", "IBSD", "BIIB", "SKTA", "AIPM", "TAQM"} {
+		metrics = append(metrics, liquidity.TickerMetrics{
+			Symbol:      symbol,
+			Date:        date,
+			Window:      liquidity.Window60,
+			HybridScore: float64(95 - i*10),
+			HybridRank:  i + 1,
+			TradingDays: 60,
+			TotalDays:   60,
+		})
+	}
+	// An older date that must not leak into the latest table
+	metrics = append(metrics, liquidity.TickerMetrics{
+		Symbol: "BBOB", Date: date.AddDate(0, 0, -1), Window: liquidity.Window60, HybridScore: 10, TradingDays: 60, TotalDays: 60,
+	})
+	require.NoError(t, liquidity.AppendHistory(metrics, liquidity.HistoryPath(dir), date))
+
+	service := NewLiquidityService(dir, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	ctx := context.Background()
+
+	table, err := service.LeagueTable(ctx, LiquidityLeagueRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-02", table.Date)
+	assert.Equal(t, "60d", table.Window)
+	assert.Equal(t, "quintile", table.Tiering)
+	assert.Equal(t, 10, table.Count)
+	require.Len(t, table.Tiers, liquidity.Quintiles)
+	for _, tier := range table.Tiers {
+		assert.Len(t, tier.Tickers, 2, "tier %d", tier.Tier)
+	}
+	top := table.Tiers[0]
+	assert.Equal(t, "BBOB", top.Tickers[0].Symbol)
+	assert.Equal(t, 1, top.Tickers[0].Rank)
+	assert.InDelta(t, 95.0, top.Tickers[0].PercentileRank, 1e-9)
+	assert.Equal(t, 95.0, top.MaxScore)
+	assert.Equal(t, 85.0, top.MinScore)
+	assert.Equal(t, "TAQM", table.Tiers[4].Tickers[1].Symbol)
+
+	deciles, err := service.LeagueTable(ctx, LiquidityLeagueRequest{Tiers: liquidity.Deciles, Date: date})
+	require.NoError(t, err)
+	assert.Equal(t, "decile", deciles.Tiering)
+	require.Len(t, deciles.Tiers, liquidity.Deciles)
+	assert.Equal(t, "TASC", deciles.Tiers[1].Tickers[0].Symbol)
+
+	_, err = service.LeagueTable(ctx, LiquidityLeagueRequest{Tiers: 4})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.LeagueTable(ctx, LiquidityLeagueRequest{Window: liquidity.Window20})
+	assert.ErrorIs(t, err, ErrNoLiquidityData)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/liquidity"
+)
+
+// SafeTradeQuery asks how much of a ticker can be traded within a price impact
+type SafeTradeQuery struct {
+	Symbol        string
+	Window        liquidity.Window // zero means 60d
+	ImpactPercent float64          // price impact threshold, e.g. 1.0 for 1%
+	PositionValue float64          // optional position size to plan an exit for, in Currency
+	Currency      currency.Code    // currency of the position and the result; empty means IQD
+}
+
+// SafeTrade is the safe trade size of a ticker on its latest liquidity date.
+// Values are in Currency at the rate of that date.
+type SafeTrade struct {
+	Symbol        string        `json:"symbol"`
+	Date          string        `json:"date"`
+	Window        string        `json:"window"`
+	Currency      currency.Code `json:"currency"`
+	AvgDailyValue float64       `json:"avg_daily_value"`
+	HybridScore   float64       `json:"hybrid_score"`
+	liquidity.SafeTradeEstimate
+}
+
+// GetSafeTrade sizes trading in one ticker from its most recent liquidity
+// history point: the largest daily trade within the impact threshold and,
+// when a position is given, the trading days needed to exit it
+func (s *LiquidityService) GetSafeTrade(ctx context.Context, q SafeTradeQuery) (*SafeTrade, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(q.Symbol))
+	if q.ImpactPercent <= 0 {
+		return nil, fmt.Errorf("%w: impact must be positive", ErrInvalidInput)
+	}
+	if q.PositionValue < 0 {
+		return nil, fmt.Errorf("%w: position must not be negative", ErrInvalidInput)
+	}
+	window := q.Window
+	if window == 0 {
+		window = liquidity.Window60
+	}
+
+	points, err := liquidity.LoadHistory(liquidity.HistoryPath(s.dataDir), symbol, window)
+	if err != nil {
+		return nil, fmt.Errorf("load liquidity history: %w", err)
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w for %s in window %s", ErrNoLiquidityData, symbol, window)
+	}
+	// LoadHistory orders by date within a window, so the last point is the latest
+	latest := points[len(points)-1]
+
+	cur := q.Currency
+	if cur == "" {
+		cur = currency.IQD
+	}
+	convert, err := s.rates.convertTo(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+	positionIQD := q.PositionValue
+	if cur != currency.IQD && positionIQD > 0 {
+		// The estimate works in IQD; convert one unit to find the rate
+		positionIQD = q.PositionValue / convert(1, latest.Date)
+	}
+
+	calculator := liquidity.NewCalculator(window, liquidity.DefaultPenaltyParams(), liquidity.DefaultWeights(), s.logger)
+	metrics := calculator.FromHistory(latest)
+	// The volume limit follows the ticker's market-wide score
+	metrics.HybridScore = latest.HybridScore
+
+	result := &SafeTrade{
+		Symbol:            symbol,
+		Date:              latest.Date.Format("2006-01-02"),
+		Window:            window.String(),
+		Currency:          cur,
+		AvgDailyValue:     latest.Value,
+		HybridScore:       latest.HybridScore,
+		SafeTradeEstimate: liquidity.EstimateSafeTrade(metrics, q.ImpactPercent, positionIQD),
+	}
+	if cur != currency.IQD {
+		result.AvgDailyValue = convert(result.AvgDailyValue, latest.Date)
+		result.MaxTradeValue = convert(result.MaxTradeValue, latest.Date)
+		result.PositionValue = q.PositionValue
+	}
+
+	s.logger.DebugContext(ctx, "Estimated safe trade",
+		slog.String("symbol", symbol),
+		slog.String("date", result.Date),
+		slog.Float64("impact_percent", q.ImpactPercent),
+		slog.Float64("max_trade_value", result.MaxTradeValue))
+
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/liquidity"
+)
+
+func TestLiquidityService_GetSafeTrade(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	point := func(date time.Time, illiq float64) liquidity.TickerMetrics {
+		return liquidity.TickerMetrics{
+			Symbol:      "BBOB",
+			Date:        date,
+			Window:      liquidity.Window60,
+			ILLIQ:       illiq,
+			Value:       10_000_000,
+			Continuity:  0.9,
+			HybridScore: 75,
+			HybridRank:  1,
+			TradingDays: 54,
+			TotalDays:   60,
+		}
+	}
+	require.NoError(t, liquidity.AppendHistory([]liquidity.TickerMetrics{point(older, 1.0)}, liquidity.HistoryPath(dir), older))
+	require.NoError(t, liquidity.AppendHistory([]liquidity.TickerMetrics{point(latest, 0.5)}, liquidity.HistoryPath(dir), latest))
+
+	service := NewLiquidityService(dir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	trade, err := service.GetSafeTrade(ctx, SafeTradeQuery{Symbol: "bbob", ImpactPercent: 1.0, PositionValue: 50_000})
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", trade.Symbol)
+	assert.Equal(t, "2025-03-02", trade.Date, "uses the latest history point")
+	assert.Equal(t, "60d", trade.Window)
+	assert.Equal(t, 10_000_000.0, trade.AvgDailyValue)
+	assert.Equal(t, "HIGH", trade.LiquidityRating)
+	assert.InDelta(t, 20_000, trade.MaxTradeValue, 0.01, "1% / ILLIQ 0.5 per million IQD")
+	require.NotNil(t, trade.DaysToExit)
+	assert.Equal(t, 3, *trade.DaysToExit)
+
+	t.Run("in USD", func(t *testing.T) {
+		// The position is given and the sizes returned in USD at 1300 IQD
+		usd, err := service.GetSafeTrade(ctx, SafeTradeQuery{Symbol: "BBOB", ImpactPercent: 1.0, PositionValue: 50_000.0 / 1300, Currency: currency.USD})
+		require.NoError(t, err)
+		assert.Equal(t, currency.USD, usd.Currency)
+		assert.InDelta(t, 20_000.0/1300, usd.MaxTradeValue, 1e-6)
+		assert.InDelta(t, 10_000_000.0/1300, usd.AvgDailyValue, 1e-6)
+		assert.Equal(t, *trade.DaysToExit, *usd.DaysToExit)
+	})
+
+	t.Run("unknown ticker", func(t *testing.T) {
+		_, err := service.GetSafeTrade(ctx, SafeTradeQuery{Symbol: "XXXX", ImpactPercent: 1.0})
+		assert.ErrorIs(t, err, ErrNoLiquidityData)
+	})
+
+	t.Run("invalid impact", func(t *testing.T) {
+		_, err := service.GetSafeTrade(ctx, SafeTradeQuery{Symbol: "BBOB"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
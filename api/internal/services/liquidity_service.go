@@ -17,6 +17,7 @@ import (
 type LiquidityService struct {
 	dataDir string
 	logger  *slog.Logger
+	rates   *ExchangeRateService
 }
 
 // NewLiquidityService creates a new liquidity service
@@ -27,6 +28,11 @@ func NewLiquidityService(dataDir string, logger *slog.Logger) *LiquidityService
 	}
 }
 
+// SetExchangeRates sets the rates used to size safe trades in USD
+func (s *LiquidityService) SetExchangeRates(rates *ExchangeRateService) {
+	s.rates = rates
+}
+
 // TradingThreshold represents safe trading sizes
 type TradingThreshold struct {
 	Conservative float64 `json:"conservative"`
@@ -55,7 +61,11 @@ type StockRecommendation struct {
 	Continuity   float64          `json:"continuity"`
 	DailyVolume  float64          `json:"dailyVolume"`
 	DataQuality  string           `json:"dataQuality"`
+	TradingDays  int              `json:"tradingDays"`
+	Regime       string           `json:"regime,omitempty"`
 	Categories   []string         `json:"categories,omitempty"` // Categories this stock belongs to
+	Sector       string           `json:"sector,omitempty"`     // From the company profile, when scraped
+	MarketCap    float64          `json:"marketCap,omitempty"`  // Listed shares x last price in IQD
 	
 	// Component scores for transparency (3-metric system)
 	ILLIQScore      float64 `json:"illiqScore,omitempty"`      // Price impact score (0-100)
@@ -88,6 +98,10 @@ type LiquidityInsights struct {
 	BestForLargeTrades interface{}            `json:"bestForLargeTrades"`
 	BestForDayTrading  interface{}            `json:"bestForDayTrading"`
 	HighRisk          interface{}            `json:"highRisk"`
+	
+	// Filter metadata, set when quality filters were applied
+	Filters     *LiquidityFilter `json:"filters,omitempty"`
+	FilteredOut int              `json:"filteredOut,omitempty"`
 }
 
 // Helper functions for SSOT implementation
@@ -464,6 +478,9 @@ func (s *LiquidityService) parseFromLiquidityScores(ctx context.Context) (*Liqui
 		if idx, ok := indices["Data_Quality"]; ok {
 			stock.DataQuality = row[idx]
 		}
+		if idx, ok := indices["Trading_Days"]; ok {
+			stock.TradingDays, _ = strconv.Atoi(row[idx])
+		}
 		
 		// Parse component scores for transparency
 		if idx, ok := indices["ILLIQ_Scaled"]; ok {
@@ -803,6 +820,8 @@ func (s *LiquidityService) aggregateStockDataWithModes(entries []StockRecommenda
 	
 	// Latest metrics (most recent entry)
 	lastEntry := entries[len(entries)-1]
+	result.TradingDays = lastEntry.TradingDays
+	result.Regime = classifyRegime(lastEntry)
 	result.LatestMetrics = &StockMetrics{
 		Score:       lastEntry.Score,
 		Thresholds:  lastEntry.Thresholds,
@@ -5,9 +5,12 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/liquidity"
 )
 
 func TestLiquidityService_RemoveOutliers(t *testing.T) {
@@ -267,4 +270,110 @@ func filterDayTradingStocks(stocks []StockRecommendation) []string {
 		}
 	}
 	return dayTrading
-}
\ No newline at end of file
+}
+func TestLiquidityFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  LiquidityFilter
+		wantErr bool
+	}{
+		{"empty", LiquidityFilter{}, false},
+		{"valid", LiquidityFilter{MinCoverage: 50, MinTradingDays: 10, ExcludeRegimes: []string{RegimeDormant}}, false},
+		{"coverage above 100", LiquidityFilter{MinCoverage: 120}, true},
+		{"negative trading days", LiquidityFilter{MinTradingDays: -1}, true},
+		{"unknown regime", LiquidityFilter{ExcludeRegimes: []string{"sleepy"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestApplyFilter(t *testing.T) {
+	newInsights := func() *LiquidityInsights {
+		return &LiquidityInsights{
+			TotalStocks: 3,
+			AllStocks: []StockRecommendation{
+				{Symbol: "BBOB", Continuity: 0.9, TradingDays: 55, Regime: RegimeActive},
+				{Symbol: "TASC", Continuity: 0.4, TradingDays: 24, Regime: RegimeSporadic},
+				{Symbol: "IBSD", Continuity: 0.05, TradingDays: 3, Regime: RegimeDormant},
+			},
+			TopOpportunities:   []string{"BBOB", "TASC"},
+			BestForLargeTrades: []string{"BBOB"},
+			BestForDayTrading:  []string{"BBOB"},
+			HighRisk:           []string{"IBSD"},
+		}
+	}
+
+	t.Run("empty filter leaves insights untouched", func(t *testing.T) {
+		insights := newInsights()
+		ApplyFilter(insights, LiquidityFilter{})
+		assert.Len(t, insights.AllStocks, 3)
+		assert.Nil(t, insights.Filters)
+	})
+
+	t.Run("exclude dormant", func(t *testing.T) {
+		insights := newInsights()
+		ApplyFilter(insights, LiquidityFilter{ExcludeRegimes: []string{RegimeDormant}})
+		assert.Equal(t, 2, insights.TotalStocks)
+		assert.Equal(t, 1, insights.FilteredOut)
+		assert.Empty(t, insights.HighRisk)
+		require.NotNil(t, insights.Filters)
+		assert.Equal(t, []string{RegimeDormant}, insights.Filters.ExcludeRegimes)
+	})
+
+	t.Run("min coverage and trading days", func(t *testing.T) {
+		insights := newInsights()
+		ApplyFilter(insights, LiquidityFilter{MinCoverage: 30, MinTradingDays: 30})
+		require.Len(t, insights.AllStocks, 1)
+		assert.Equal(t, "BBOB", insights.AllStocks[0].Symbol)
+		assert.Equal(t, []string{"BBOB"}, insights.TopOpportunities)
+	})
+}
+
+func TestClassifyRegime(t *testing.T) {
+	assert.Equal(t, RegimeDormant, classifyRegime(StockRecommendation{Continuity: 0.8, TradingDays: 0}))
+	assert.Equal(t, RegimeDormant, classifyRegime(StockRecommendation{Continuity: 0.05, TradingDays: 2}))
+	assert.Equal(t, RegimeSporadic, classifyRegime(StockRecommendation{Continuity: 0.3, TradingDays: 18}))
+	assert.Equal(t, RegimeActive, classifyRegime(StockRecommendation{Continuity: 0.7, TradingDays: 42}))
+}
+
+func TestLiquidityService_GetHistory(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var metrics []liquidity.TickerMetrics
+	for i, score := range []float64{40, 44, 48, 52, 56} {
+		metrics = append(metrics,
+			liquidity.TickerMetrics{Symbol: "BBOB", Date: start.AddDate(0, 0, i), Window: liquidity.Window60, HybridScore: score, TotalDays: 60},
+			liquidity.TickerMetrics{Symbol: "BBOB", Date: start.AddDate(0, 0, i), Window: liquidity.Window20, HybridScore: 50, TotalDays: 20},
+		)
+	}
+	require.NoError(t, liquidity.AppendHistory(metrics, liquidity.HistoryPath(dir), start))
+
+	service := NewLiquidityService(dir, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	history, err := service.GetHistory(context.Background(), LiquidityHistoryQuery{Symbol: "bbob", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", history.Symbol)
+	require.Len(t, history.Windows, 2)
+
+	assert.Equal(t, "20d", history.Windows[0].Window)
+	assert.Equal(t, liquidity.TrendStable, history.Windows[0].Trend.Direction)
+
+	assert.Equal(t, "60d", history.Windows[1].Window)
+	assert.Len(t, history.Windows[1].Points, 2, "limit trims points")
+	assert.Equal(t, liquidity.TrendImproving, history.Windows[1].Trend.Direction)
+	assert.Equal(t, 5, history.Windows[1].Trend.Windows, "trend uses the full series, not the limited one")
+
+	history, err = service.GetHistory(context.Background(), LiquidityHistoryQuery{Symbol: "TASC"})
+	require.NoError(t, err)
+	assert.Empty(t, history.Windows)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/liquidity"
+)
+
+// MaxLiquidityUniverse caps the number of tickers in a custom universe
+const MaxLiquidityUniverse = 500
+
+// LiquidityUniverseRequest asks for liquidity scores relative to a custom
+// ticker universe rather than the whole market
+type LiquidityUniverseRequest struct {
+	Symbols []string
+	Date    time.Time        // zero means the latest date in the history
+	Window  liquidity.Window // zero means 60d
+}
+
+// LiquidityUniverseScore is one ticker's liquidity within the universe,
+// alongside its score and rank in the whole market
+type LiquidityUniverseScore struct {
+	Symbol           string  `json:"symbol"`
+	Rank             int     `json:"rank"`
+	Score            float64 `json:"score"`
+	MarketRank       int     `json:"market_rank"`
+	MarketScore      float64 `json:"market_score"`
+	ILLIQScaled      float64 `json:"illiq_scaled"`
+	ValueScaled      float64 `json:"value_scaled"`
+	ContinuityScaled float64 `json:"continuity_scaled"`
+	TradingDays      int     `json:"trading_days"`
+	SafeValue05      float64 `json:"safe_value_0_5"`
+	SafeValue10      float64 `json:"safe_value_1_0"`
+	SafeValue20      float64 `json:"safe_value_2_0"`
+	OptimalTradeSize float64 `json:"optimal_trade_size"`
+}
+
+// LiquidityUniverseSnapshot is the cross-sectional snapshot of a custom
+// universe on one date, ordered by rank
+type LiquidityUniverseSnapshot struct {
+	Date       string                   `json:"date"`
+	Window     string                   `json:"window"`
+	MarketSize int                      `json:"market_size"`
+	Missing    []string                 `json:"missing,omitempty"` // Requested tickers with no data on the date
+	Scores     []LiquidityUniverseScore `json:"scores"`
+}
+
+// ScoreUniverse rebuilds the cross-sectional scaling of one date's liquidity
+// history within only the requested tickers and returns their scores. Tickers
+// without data on the date are reported as missing; at least two must remain.
+func (s *LiquidityService) ScoreUniverse(ctx context.Context, req LiquidityUniverseRequest) (*LiquidityUniverseSnapshot, error) {
+	symbols := normalizeUniverse(req.Symbols)
+	switch {
+	case len(symbols) < 2:
+		return nil, fmt.Errorf("%w: the universe needs at least 2 tickers", ErrInvalidInput)
+	case len(symbols) > MaxLiquidityUniverse:
+		return nil, fmt.Errorf("%w: the universe is limited to %d tickers", ErrInvalidInput, MaxLiquidityUniverse)
+	}
+	window := req.Window
+	if window == 0 {
+		window = liquidity.Window60
+	}
+
+	points, date, err := liquidity.CrossSection(liquidity.HistoryPath(s.dataDir), window, req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("load liquidity history: %w", err)
+	}
+	if len(points) == 0 {
+		if req.Date.IsZero() {
+			return nil, fmt.Errorf("%w for window %s", ErrNoLiquidityData, window)
+		}
+		return nil, fmt.Errorf("%w for window %s on %s", ErrNoLiquidityData, window, req.Date.Format("2006-01-02"))
+	}
+
+	bySymbol := make(map[string]liquidity.HistoryPoint, len(points))
+	for _, p := range points {
+		bySymbol[strings.ToUpper(p.Symbol)] = p
+	}
+
+	calculator := liquidity.NewCalculator(window, liquidity.DefaultPenaltyParams(), liquidity.DefaultWeights(), s.logger)
+	snapshot := &LiquidityUniverseSnapshot{
+		Date:       date.Format("2006-01-02"),
+		Window:     window.String(),
+		MarketSize: len(points),
+	}
+	metrics := make([]liquidity.TickerMetrics, 0, len(symbols))
+	for _, symbol := range symbols {
+		p, ok := bySymbol[symbol]
+		if !ok {
+			snapshot.Missing = append(snapshot.Missing, symbol)
+			continue
+		}
+		metrics = append(metrics, calculator.FromHistory(p))
+	}
+	if len(metrics) < 2 {
+		return nil, fmt.Errorf("%w: only %d of the requested tickers have liquidity data on %s",
+			ErrInvalidInput, len(metrics), snapshot.Date)
+	}
+
+	if err := calculator.ScaleUniverse(ctx, metrics); err != nil {
+		return nil, fmt.Errorf("scale universe: %w", err)
+	}
+
+	snapshot.Scores = make([]LiquidityUniverseScore, len(metrics))
+	for i, m := range metrics {
+		market := bySymbol[strings.ToUpper(m.Symbol)]
+		snapshot.Scores[i] = LiquidityUniverseScore{
+			Symbol:           m.Symbol,
+			Rank:             m.HybridRank,
+			Score:            m.HybridScore,
+			MarketRank:       market.HybridRank,
+			MarketScore:      market.HybridScore,
+			ILLIQScaled:      m.ILLIQScaled,
+			ValueScaled:      m.ValueScaled,
+			ContinuityScaled: m.ContinuityScaled,
+			TradingDays:      m.TradingDays,
+			SafeValue05:      m.SafeValue_0_5,
+			SafeValue10:      m.SafeValue_1_0,
+			SafeValue20:      m.SafeValue_2_0,
+			OptimalTradeSize: m.OptimalTradeSize,
+		}
+	}
+	sort.Slice(snapshot.Scores, func(i, j int) bool {
+		return snapshot.Scores[i].Rank < snapshot.Scores[j].Rank
+	})
+
+	s.logger.DebugContext(ctx, "Scored custom liquidity universe",
+		slog.String("date", snapshot.Date),
+		slog.String("window", snapshot.Window),
+		slog.Int("tickers", len(metrics)),
+		slog.Int("missing", len(snapshot.Missing)))
+
+	return snapshot, nil
+}
+
+// normalizeUniverse upper-cases and de-duplicates symbols, keeping their order
+func normalizeUniverse(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	out := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/liquidity"
+)
+
+func TestLiquidityService_ScoreUniverse(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Market ranks BBOB > TASC > BMFI > IMAP
+	var metrics []liquidity.TickerMetrics
+	for i, symbol := range []string{"BBOB", "TASC", "BMFI", "IMAP"} {
+		metrics = append(metrics, liquidity.TickerMetrics{
+			Symbol:      symbol,
+			Date:        date,
+			Window:      liquidity.Window60,
+			ILLIQ:       float64(i+1) * 1e-9,
+			Value:       float64(4-i) * 50_000_000,
+			Continuity:  1 - float64(i)*0.2,
+			HybridScore: float64(90 - i*20),
+			HybridRank:  i + 1,
+			TradingDays: 60 - i*10,
+			TotalDays:   60,
+		})
+	}
+	require.NoError(t, liquidity.AppendHistory(metrics, liquidity.HistoryPath(dir), date))
+
+	service := NewLiquidityService(dir, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	ctx := context.Background()
+
+	snapshot, err := service.ScoreUniverse(ctx, LiquidityUniverseRequest{Symbols: []string{"imap", " BMFI ", "IMAP", "XXXX"}})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-02", snapshot.Date)
+	assert.Equal(t, "60d", snapshot.Window)
+	assert.Equal(t, 4, snapshot.MarketSize)
+	assert.Equal(t, []string{"XXXX"}, snapshot.Missing)
+	require.Len(t, snapshot.Scores, 2)
+
+	assert.Equal(t, "BMFI", snapshot.Scores[0].Symbol)
+	assert.Equal(t, 1, snapshot.Scores[0].Rank, "ranked within the universe")
+	assert.Equal(t, 3, snapshot.Scores[0].MarketRank)
+	assert.Equal(t, 50.0, snapshot.Scores[0].MarketScore)
+	assert.Equal(t, "IMAP", snapshot.Scores[1].Symbol)
+	assert.Equal(t, 2, snapshot.Scores[1].Rank)
+	assert.Greater(t, snapshot.Scores[0].Score, snapshot.Scores[1].Score)
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := service.ScoreUniverse(ctx, LiquidityUniverseRequest{Symbols: []string{"BBOB", "bbob"}})
+		assert.ErrorIs(t, err, ErrInvalidInput, "duplicates collapse to one ticker")
+
+		_, err = service.ScoreUniverse(ctx, LiquidityUniverseRequest{Symbols: []string{"BBOB", "XXXX"}})
+		assert.ErrorIs(t, err, ErrInvalidInput, "only one ticker has data")
+	})
+
+	t.Run("no data for date or window", func(t *testing.T) {
+		_, err := service.ScoreUniverse(ctx, LiquidityUniverseRequest{
+			Symbols: []string{"BBOB", "TASC"},
+			Date:    date.AddDate(0, 0, -1),
+		})
+		assert.ErrorIs(t, err, ErrNoLiquidityData)
+
+		_, err = service.ScoreUniverse(ctx, LiquidityUniverseRequest{
+			Symbols: []string{"BBOB", "TASC"},
+			Window:  liquidity.Window20,
+		})
+		assert.ErrorIs(t, err, ErrNoLiquidityData)
+	})
+}
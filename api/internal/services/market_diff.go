@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/dataprocessing"
+	"isxcli/pkg/contracts/domain"
+)
+
+// ErrNoDailyReport is returned when a diff date has no daily report
+var ErrNoDailyReport = fmt.Errorf("%w: no daily report for date", ErrNoReportsFound)
+
+// Ticker states in a market diff
+const (
+	DiffTraded      = "traded"       // traded on both days
+	DiffNewlyTraded = "newly_traded" // traded on the later day only
+	DiffSuspended   = "suspended"    // traded on the earlier day only
+)
+
+// TickerDiff is how one ticker changed between two trading days. Ranks order
+// the tickers traded that day by traded value, 1 being the most traded; they
+// are null on a day the ticker did not trade.
+type TickerDiff struct {
+	Symbol             string  `json:"symbol"`
+	CompanyName        string  `json:"company_name"`
+	Status             string  `json:"status"`
+	CloseFrom          float64 `json:"close_from"`
+	CloseTo            float64 `json:"close_to"`
+	PriceChange        float64 `json:"price_change"`
+	PriceChangePercent float64 `json:"price_change_percent"`
+	VolumeFrom         int64   `json:"volume_from"`
+	VolumeTo           int64   `json:"volume_to"`
+	VolumeChange       int64   `json:"volume_change"`
+	ValueFrom          float64 `json:"value_from"`
+	ValueTo            float64 `json:"value_to"`
+	RankFrom           *int    `json:"rank_from"`
+	RankTo             *int    `json:"rank_to"`
+	// RankChange is positive when the ticker moved up the ranking
+	RankChange *int `json:"rank_change"`
+}
+
+// MarketDiffSummary totals a market diff
+type MarketDiffSummary struct {
+	Traded             int     `json:"traded"`
+	NewlyTraded        int     `json:"newly_traded"`
+	Suspended          int     `json:"suspended"`
+	Gainers            int     `json:"gainers"`
+	Losers             int     `json:"losers"`
+	Unchanged          int     `json:"unchanged"`
+	ValueFrom          float64 `json:"value_from"`
+	ValueTo            float64 `json:"value_to"`
+	ValueChangePercent float64 `json:"value_change_percent"`
+}
+
+// MarketDiff compares two trading days ticker by ticker. Prices and values
+// are in Currency, each converted at its own day's rate; percentages are
+// those of the IQD figures.
+type MarketDiff struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Currency    currency.Code     `json:"currency"`
+	Summary     MarketDiffSummary `json:"summary"`
+	NewlyTraded []string          `json:"newly_traded"`
+	Suspended   []string          `json:"suspended"`
+	Tickers     []TickerDiff      `json:"tickers"`
+}
+
+// GetMarketDiff compares the daily reports of two trading days: price,
+// volume and value changes, traded-value rank shifts and the tickers that
+// started or stopped trading. A zero to compares the latest day; a zero from
+// compares the trading day before to. Amounts are reported in cur.
+// Identical concurrent requests share one comparison.
+func (ds *DataService) GetMarketDiff(ctx context.Context, from, to time.Time, cur currency.Code) (*MarketDiff, error) {
+	dailyDir := ds.paths.ForContext(ctx).DailyReportsDir
+
+	// The comparison is shared, so it must not end when the first caller goes away
+	key := fmt.Sprintf("diff:%s:%s:%s:%s", dailyDir, from.Format(time.DateOnly), to.Format(time.DateOnly), cur)
+	shared := context.WithoutCancel(ctx)
+	value, err := ds.cache.Coalesce(key, func() (interface{}, error) {
+		return ds.marketDiff(shared, dailyDir, from, to, cur)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*MarketDiff), nil
+}
+
+// marketDiff resolves the compared days and builds the diff
+func (ds *DataService) marketDiff(ctx context.Context, dailyDir string, from, to time.Time, cur currency.Code) (*MarketDiff, error) {
+	if from.IsZero() || to.IsZero() {
+		days, err := dailyReportDates(dailyDir)
+		if err != nil {
+			return nil, err
+		}
+		if to.IsZero() {
+			if len(days) == 0 {
+				return nil, ErrNoReportsFound
+			}
+			to = days[len(days)-1]
+		}
+		if from.IsZero() {
+			i := sort.Search(len(days), func(i int) bool { return !days[i].Before(to) })
+			if i == 0 {
+				return nil, fmt.Errorf("%w before %s", ErrNoDailyReport, to.Format("2006-01-02"))
+			}
+			from = days[i-1]
+		}
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	fromRecords, err := readDailyReport(dailyDir, from)
+	if err != nil {
+		return nil, err
+	}
+	toRecords, err := readDailyReport(dailyDir, to)
+	if err != nil {
+		return nil, err
+	}
+
+	convert, err := ds.rates.convertTo(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffTradingDays(fromRecords, toRecords)
+	diff.From = from.Format("2006-01-02")
+	diff.To = to.Format("2006-01-02")
+	diff.Currency = currency.IQD
+	if cur == currency.USD {
+		diff.convert(cur, func(iqd float64) float64 { return convert(iqd, from) }, func(iqd float64) float64 { return convert(iqd, to) })
+	}
+	return diff, nil
+}
+
+// convert restates the diff's amounts in cur with the rates of each day
+func (d *MarketDiff) convert(cur currency.Code, atFrom, atTo func(float64) float64) {
+	d.Currency = cur
+	d.Summary.ValueFrom = atFrom(d.Summary.ValueFrom)
+	d.Summary.ValueTo = atTo(d.Summary.ValueTo)
+	for i := range d.Tickers {
+		t := &d.Tickers[i]
+		t.CloseFrom, t.ValueFrom = atFrom(t.CloseFrom), atFrom(t.ValueFrom)
+		t.CloseTo, t.ValueTo = atTo(t.CloseTo), atTo(t.ValueTo)
+		if t.PriceChange != 0 {
+			t.PriceChange = t.CloseTo - t.CloseFrom
+		}
+	}
+}
+
+// diffTradingDays compares two days of trade records
+func diffTradingDays(fromRecords, toRecords []domain.TradeRecord) *MarketDiff {
+	before := recordsBySymbol(fromRecords)
+	after := recordsBySymbol(toRecords)
+	rankFrom := valueRanks(before)
+	rankTo := valueRanks(after)
+
+	symbols := make([]string, 0, len(after))
+	for symbol := range after {
+		symbols = append(symbols, symbol)
+	}
+	for symbol := range before {
+		if _, ok := after[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	diff := &MarketDiff{NewlyTraded: []string{}, Suspended: []string{}, Tickers: []TickerDiff{}}
+	for _, symbol := range symbols {
+		a, inFrom := before[symbol]
+		b, inTo := after[symbol]
+		tradedFrom := inFrom && a.TradingStatus
+		tradedTo := inTo && b.TradingStatus
+		if tradedFrom {
+			diff.Summary.ValueFrom += a.Value
+		}
+		if tradedTo {
+			diff.Summary.ValueTo += b.Value
+		}
+
+		var status string
+		switch {
+		case tradedFrom && tradedTo:
+			status = DiffTraded
+			diff.Summary.Traded++
+		case tradedTo:
+			status = DiffNewlyTraded
+			diff.Summary.NewlyTraded++
+			diff.NewlyTraded = append(diff.NewlyTraded, symbol)
+		case tradedFrom:
+			status = DiffSuspended
+			diff.Summary.Suspended++
+			diff.Suspended = append(diff.Suspended, symbol)
+		default:
+			// Carried forward on both days: nothing changed
+			continue
+		}
+
+		t := TickerDiff{Symbol: symbol, Status: status, RankFrom: rankFrom[symbol], RankTo: rankTo[symbol]}
+		if inFrom {
+			t.CompanyName = a.CompanyName
+			t.CloseFrom = a.ClosePrice
+			if tradedFrom {
+				t.VolumeFrom, t.ValueFrom = a.Volume, a.Value
+			}
+		}
+		if inTo {
+			t.CompanyName = b.CompanyName
+			t.CloseTo = b.ClosePrice
+			if tradedTo {
+				t.VolumeTo, t.ValueTo = b.Volume, b.Value
+			}
+		}
+		t.VolumeChange = t.VolumeTo - t.VolumeFrom
+		if inFrom && inTo {
+			t.PriceChange = roundDiff(t.CloseTo - t.CloseFrom)
+			if t.CloseFrom != 0 {
+				t.PriceChangePercent = roundDiff((t.CloseTo - t.CloseFrom) / t.CloseFrom * 100)
+			}
+		}
+		if t.RankFrom != nil && t.RankTo != nil {
+			change := *t.RankFrom - *t.RankTo
+			t.RankChange = &change
+		}
+
+		if status == DiffTraded {
+			switch {
+			case t.PriceChange > 0:
+				diff.Summary.Gainers++
+			case t.PriceChange < 0:
+				diff.Summary.Losers++
+			default:
+				diff.Summary.Unchanged++
+			}
+		}
+		diff.Tickers = append(diff.Tickers, t)
+	}
+
+	if diff.Summary.ValueFrom != 0 {
+		diff.Summary.ValueChangePercent = roundDiff((diff.Summary.ValueTo - diff.Summary.ValueFrom) / diff.Summary.ValueFrom * 100)
+	}
+	return diff
+}
+
+// recordsBySymbol indexes a day's records by upper-case symbol
+func recordsBySymbol(records []domain.TradeRecord) map[string]domain.TradeRecord {
+	bySymbol := make(map[string]domain.TradeRecord, len(records))
+	for _, record := range records {
+		bySymbol[strings.ToUpper(record.CompanySymbol)] = record
+	}
+	return bySymbol
+}
+
+// valueRanks ranks the traded tickers of a day by traded value, ties by symbol
+func valueRanks(bySymbol map[string]domain.TradeRecord) map[string]*int {
+	traded := make([]domain.TradeRecord, 0, len(bySymbol))
+	for _, record := range bySymbol {
+		if record.TradingStatus {
+			traded = append(traded, record)
+		}
+	}
+	sort.Slice(traded, func(i, j int) bool {
+		if traded[i].Value != traded[j].Value {
+			return traded[i].Value > traded[j].Value
+		}
+		return traded[i].CompanySymbol < traded[j].CompanySymbol
+	})
+
+	ranks := make(map[string]*int, len(traded))
+	for i, record := range traded {
+		rank := i + 1
+		ranks[strings.ToUpper(record.CompanySymbol)] = &rank
+	}
+	return ranks
+}
+
+// roundDiff rounds a change to 4 decimal places, dropping float noise
+func roundDiff(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// readDailyReport reads the processor's daily CSV of one date
+func readDailyReport(dir string, date time.Time) ([]domain.TradeRecord, error) {
+	path := filepath.Join(dir, fmt.Sprintf("isx_daily_%s.csv", date.Format("2006_01_02")))
+	records, err := dataprocessing.ReadTradeRecordsCSV(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w %s", ErrNoDailyReport, date.Format("2006-01-02"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daily report: %w", err)
+	}
+	return records, nil
+}
+
+// dailyReportDates lists the dates with a daily report, oldest first
+func dailyReportDates(dir string) ([]time.Time, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "isx_daily_*.csv"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daily reports: %w", err)
+	}
+	dates := make([]time.Time, 0, len(matches))
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), "isx_daily_"), ".csv")
+		if date, err := time.Parse("2006_01_02", name); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates, nil
+}
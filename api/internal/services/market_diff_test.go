@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/currency"
+)
+
+const testDailyHeader = "Date,CompanyName,Symbol,ClosePrice,Volume,Value,TradingStatus\n"
+
+func newTestDiffService(t *testing.T) *DataService {
+	dir := t.TempDir()
+	days := map[string]string{
+		"2024_01_11": "2024-01-11,Bank of Baghdad,BBOB,1.200,1000,1200,true\n",
+		"2024_01_14": "2024-01-14,Bank of Baghdad,BBOB,1.250,2000,2500,true\n" +
+			"2024-01-14,Asiacell,TASC,8.000,500,4000,true\n" +
+			"2024-01-14,Iraqi Islamic Bank,BIIB,0.500,3000,1500,true\n" +
+			"2024-01-14,Baghdad Soft Drinks,IBSD,3.000,0,0,false\n",
+		"2024_01_15": "2024-01-15,Bank of Baghdad,BBOB,1.300,4000,5200,true\n" +
+			"2024-01-15,Asiacell,TASC,7.500,100,750,true\n" +
+			"2024-01-15,Iraqi Islamic Bank,BIIB,0.500,0,0,false\n" +
+			"2024-01-15,Baghdad Soft Drinks,IBSD,3.100,1000,3100,true\n",
+	}
+	for name, rows := range days {
+		path := filepath.Join(dir, "isx_daily_"+name+".csv")
+		require.NoError(t, os.WriteFile(path, []byte(testDailyHeader+rows), 0o644))
+	}
+
+	return &DataService{
+		config: config.Default(),
+		paths:  &config.Paths{DailyReportsDir: dir},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestDataService_GetMarketDiff(t *testing.T) {
+	ctx := context.Background()
+	ds := newTestDiffService(t)
+
+	diff, err := ds.GetMarketDiff(ctx, time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), currency.IQD)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-14", diff.From)
+	assert.Equal(t, "2024-01-15", diff.To)
+	assert.Equal(t, []string{"IBSD"}, diff.NewlyTraded)
+	assert.Equal(t, []string{"BIIB"}, diff.Suspended)
+	assert.Equal(t, MarketDiffSummary{
+		Traded: 2, NewlyTraded: 1, Suspended: 1, Gainers: 1, Losers: 1,
+		ValueFrom: 8000, ValueTo: 9050, ValueChangePercent: 13.125,
+	}, diff.Summary)
+
+	require.Len(t, diff.Tickers, 4)
+	bbob := diff.Tickers[0]
+	assert.Equal(t, "BBOB", bbob.Symbol)
+	assert.Equal(t, DiffTraded, bbob.Status)
+	assert.Equal(t, 0.05, bbob.PriceChange)
+	assert.Equal(t, 4.0, bbob.PriceChangePercent)
+	assert.Equal(t, int64(2000), bbob.VolumeChange)
+	assert.Equal(t, 2, *bbob.RankFrom)
+	assert.Equal(t, 1, *bbob.RankTo)
+	assert.Equal(t, 1, *bbob.RankChange, "moved up one place")
+
+	biib := diff.Tickers[1]
+	assert.Equal(t, DiffSuspended, biib.Status)
+	assert.Nil(t, biib.RankTo)
+	assert.Nil(t, biib.RankChange)
+	assert.Equal(t, int64(-3000), biib.VolumeChange)
+
+	tasc := diff.Tickers[3]
+	assert.Equal(t, -2, *tasc.RankChange, "dropped from first to third")
+
+	t.Run("defaults to the latest two trading days", func(t *testing.T) {
+		diff, err := ds.GetMarketDiff(ctx, time.Time{}, time.Time{}, currency.IQD)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-14", diff.From)
+		assert.Equal(t, "2024-01-15", diff.To)
+
+		diff, err = ds.GetMarketDiff(ctx, time.Time{}, time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), currency.IQD)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-11", diff.From, "skips the days without a report")
+	})
+
+	t.Run("in USD", func(t *testing.T) {
+		// The built-in CBI rate was 1300 IQD per USD in 2024
+		diff, err := ds.GetMarketDiff(ctx, time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), currency.USD)
+		require.NoError(t, err)
+		assert.Equal(t, currency.USD, diff.Currency)
+		assert.InDelta(t, 9050.0/1300, diff.Summary.ValueTo, 1e-9)
+		assert.Equal(t, 13.125, diff.Summary.ValueChangePercent, "percentages are unaffected")
+		assert.InDelta(t, 1.3/1300, diff.Tickers[0].CloseTo, 1e-12)
+		assert.InDelta(t, 0.05/1300, diff.Tickers[0].PriceChange, 1e-12)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ds.GetMarketDiff(ctx, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), currency.IQD)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+
+		_, err = ds.GetMarketDiff(ctx, time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), currency.IQD)
+		assert.ErrorIs(t, err, ErrNoDailyReport)
+		assert.ErrorIs(t, err, ErrNoReportsFound)
+
+		_, err = ds.GetMarketDiff(ctx, time.Time{}, time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC), currency.IQD)
+		assert.ErrorIs(t, err, ErrNoDailyReport, "nothing before the first report")
+	})
+}
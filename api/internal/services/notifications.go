@@ -0,0 +1,319 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/operations"
+)
+
+// ErrNotificationsDisabled is returned when no notification channel is configured
+var ErrNotificationsDisabled = apierrors.Newf(apierrors.Conflict, "no notification channel is configured")
+
+// Notification event types, each with its own toggle in config.NotificationsConfig
+const (
+	NotificationOperationCompleted = "operation_completed"
+	NotificationOperationFailed    = "operation_failed"
+	NotificationLicenseCritical    = "license_critical"
+	NotificationWatchlistAlert     = "watchlist_alert"
+	NotificationTest               = "test"
+)
+
+// Notification is a message sent to operators
+type Notification struct {
+	Event   string `json:"event"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// NotificationSender delivers notifications over one channel
+type NotificationSender interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// NotificationResult is the outcome of sending to one channel
+type NotificationResult struct {
+	Channel string `json:"channel"`
+	Sent    bool   `json:"sent"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NotificationService tells operators when operations finish or fail, when
+// the license becomes critical and when watchlist alerts fire, by mail and/or
+// Telegram
+type NotificationService struct {
+	config  config.NotificationsConfig
+	senders []NotificationSender
+	logger  *slog.Logger
+}
+
+// NewNotificationService creates a notification service with a sender for
+// every channel configured in cfg
+func NewNotificationService(cfg config.NotificationsConfig, logger *slog.Logger) *NotificationService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var senders []NotificationSender
+	if cfg.SMTP.Enabled() {
+		senders = append(senders, NewSMTPSender(cfg.SMTP))
+	}
+	if cfg.Telegram.Enabled() {
+		senders = append(senders, NewTelegramSender(cfg.Telegram))
+	}
+
+	return &NotificationService{
+		config:  cfg,
+		senders: senders,
+		logger:  logger.With(slog.String("component", "notifications")),
+	}
+}
+
+// Channels returns the names of the configured channels
+func (s *NotificationService) Channels() []string {
+	names := make([]string, len(s.senders))
+	for i, sender := range s.senders {
+		names[i] = sender.Name()
+	}
+	return names
+}
+
+// Enabled reports whether notifications of an event type are sent
+func (s *NotificationService) Enabled(event string) bool {
+	if len(s.senders) == 0 {
+		return false
+	}
+	switch event {
+	case NotificationOperationCompleted:
+		return s.config.OperationCompleted
+	case NotificationOperationFailed:
+		return s.config.OperationFailed
+	case NotificationLicenseCritical:
+		return s.config.LicenseCritical
+	case NotificationWatchlistAlert:
+		return s.config.WatchlistAlerts
+	case NotificationTest:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notify sends n over every channel when its event type is enabled. Failures
+// are logged; one channel failing does not stop the others.
+func (s *NotificationService) Notify(ctx context.Context, n Notification) []NotificationResult {
+	if !s.Enabled(n.Event) {
+		return nil
+	}
+
+	results := make([]NotificationResult, 0, len(s.senders))
+	for _, sender := range s.senders {
+		result := NotificationResult{Channel: sender.Name(), Sent: true}
+		if err := sender.Send(ctx, n); err != nil {
+			result.Sent = false
+			result.Error = err.Error()
+			s.logger.WarnContext(ctx, "notification failed",
+				slog.String("channel", sender.Name()),
+				slog.String("event", n.Event),
+				slog.String("error", err.Error()))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// NotifyJob sends the completion or failure notification of a finished job
+func (s *NotificationService) NotifyJob(ctx context.Context, job *operations.Job) {
+	var n Notification
+	switch job.Status {
+	case operations.JobStatusCompleted:
+		n = Notification{
+			Event:   NotificationOperationCompleted,
+			Subject: fmt.Sprintf("ISX Pulse: operation %s completed", job.OperationID),
+			Text:    fmt.Sprintf("Operation %s (%s) completed%s.", job.OperationID, jobLabel(job), jobDuration(job)),
+		}
+	case operations.JobStatusFailed:
+		n = Notification{
+			Event:   NotificationOperationFailed,
+			Subject: fmt.Sprintf("ISX Pulse: operation %s failed", job.OperationID),
+			Text:    fmt.Sprintf("Operation %s (%s) failed%s: %s", job.OperationID, jobLabel(job), jobDuration(job), job.Error),
+		}
+	default:
+		return
+	}
+	s.Notify(ctx, n)
+}
+
+// NotifyLicense sends a notification when the license enters critical status
+func (s *NotificationService) NotifyLicense(ctx context.Context, event LicenseStatusEvent) {
+	if event.Status != "critical" || event.PreviousStatus == "critical" {
+		return
+	}
+
+	text := fmt.Sprintf("The ISX Pulse license expires in %d days.", event.DaysLeft)
+	if event.ExpiryDate != nil {
+		text = fmt.Sprintf("The ISX Pulse license expires in %d days, on %s.", event.DaysLeft, event.ExpiryDate.Format("2006-01-02"))
+	}
+	s.Notify(ctx, Notification{
+		Event:   NotificationLicenseCritical,
+		Subject: "ISX Pulse: license expiring soon",
+		Text:    text + " Renew it to keep data collection running.",
+	})
+}
+
+// SendTest sends a test notification over every channel
+func (s *NotificationService) SendTest(ctx context.Context) ([]NotificationResult, error) {
+	if len(s.senders) == 0 {
+		return nil, ErrNotificationsDisabled
+	}
+	return s.Notify(ctx, Notification{
+		Event:   NotificationTest,
+		Subject: "ISX Pulse: test notification",
+		Text:    "Notifications from ISX Pulse are working.",
+	}), nil
+}
+
+// jobLabel names what a job ran
+func jobLabel(job *operations.Job) string {
+	if template, ok := job.Metadata["template"].(string); ok && template != "" {
+		return "template " + template
+	}
+	if job.StageName != "" {
+		return job.StageName
+	}
+	if job.StageID != "" {
+		return job.StageID
+	}
+	return "full pipeline"
+}
+
+// jobDuration describes how long a finished job ran
+func jobDuration(job *operations.Job) string {
+	if job.StartedAt == nil || job.CompletedAt == nil {
+		return ""
+	}
+	return " in " + job.CompletedAt.Sub(*job.StartedAt).Round(time.Second).String()
+}
+
+// SMTPSender sends notifications by mail
+type SMTPSender struct {
+	config   config.SMTPConfig
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSender creates a mail sender
+func NewSMTPSender(cfg config.SMTPConfig) *SMTPSender {
+	return &SMTPSender{config: cfg, sendMail: smtp.SendMail}
+}
+
+// Name returns the channel name
+func (s *SMTPSender) Name() string {
+	return "email"
+}
+
+// Send mails n to the configured recipients
+func (s *SMTPSender) Send(ctx context.Context, n Notification) error {
+	from := s.config.From
+	if from == "" {
+		from = s.config.Username
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", n.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(n.Text)
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := s.sendMail(addr, auth, from, s.config.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("send mail via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// telegramAPIURL is the Telegram Bot API endpoint
+const telegramAPIURL = "https://api.telegram.org"
+
+// TelegramSender sends notifications through a Telegram bot
+type TelegramSender struct {
+	config  config.TelegramConfig
+	baseURL string
+	client  *http.Client
+}
+
+// NewTelegramSender creates a Telegram sender
+func NewTelegramSender(cfg config.TelegramConfig) *TelegramSender {
+	return &TelegramSender{
+		config:  cfg,
+		baseURL: telegramAPIURL,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns the channel name
+func (s *TelegramSender) Name() string {
+	return "telegram"
+}
+
+// Send posts n to the configured chat
+func (s *TelegramSender) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": s.config.ChatID,
+		"text":    n.Subject + "\n\n" + n.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/bot"+s.config.BotToken+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token; keep it out of logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("send telegram message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var result struct {
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result)
+		if result.Description == "" {
+			result.Description = resp.Status
+		}
+		return fmt.Errorf("send telegram message: %s", result.Description)
+	}
+	return nil
+}
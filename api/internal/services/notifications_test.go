@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/operations"
+)
+
+type recordingSender struct {
+	name string
+	err  error
+	sent []Notification
+}
+
+func (s *recordingSender) Name() string { return s.name }
+
+func (s *recordingSender) Send(ctx context.Context, n Notification) error {
+	s.sent = append(s.sent, n)
+	return s.err
+}
+
+func newTestNotificationService(cfg config.NotificationsConfig, senders ...NotificationSender) *NotificationService {
+	service := NewNotificationService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.senders = senders
+	return service
+}
+
+func TestNotificationService_Toggles(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingSender{name: "fake"}
+	service := newTestNotificationService(config.NotificationsConfig{OperationFailed: true, LicenseCritical: true}, sender)
+
+	started := time.Date(2025, 8, 26, 22, 0, 0, 0, time.UTC)
+	completed := started.Add(95 * time.Second)
+	job := &operations.Job{
+		OperationID: "op-1",
+		StageID:     "full_pipeline",
+		StageName:   "Full Pipeline",
+		Status:      operations.JobStatusCompleted,
+		StartedAt:   &started,
+		CompletedAt: &completed,
+		Metadata:    map[string]interface{}{"template": "nightly-accumulative"},
+	}
+	service.NotifyJob(ctx, job)
+	assert.Empty(t, sender.sent, "completion notifications are disabled")
+
+	job.Status = operations.JobStatusFailed
+	job.Error = "scraper timed out"
+	service.NotifyJob(ctx, job)
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, NotificationOperationFailed, sender.sent[0].Event)
+	assert.Equal(t, "Operation op-1 (template nightly-accumulative) failed in 1m35s: scraper timed out", sender.sent[0].Text)
+
+	service.NotifyLicense(ctx, LicenseStatusEvent{Status: "warning", PreviousStatus: "active"})
+	service.NotifyLicense(ctx, LicenseStatusEvent{Status: "critical", PreviousStatus: "critical", Transition: true})
+	require.Len(t, sender.sent, 1, "only entering critical status notifies")
+
+	expiry := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	service.NotifyLicense(ctx, LicenseStatusEvent{Status: "critical", PreviousStatus: "warning", DaysLeft: 6, ExpiryDate: &expiry})
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, NotificationLicenseCritical, sender.sent[1].Event)
+	assert.Contains(t, sender.sent[1].Text, "expires in 6 days, on 2025-09-02")
+}
+
+func TestNotificationService_SendTest(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := newTestNotificationService(config.NotificationsConfig{}).SendTest(ctx)
+	assert.ErrorIs(t, err, ErrNotificationsDisabled)
+
+	ok := &recordingSender{name: "email"}
+	failing := &recordingSender{name: "telegram", err: errors.New("chat not found")}
+	results, err := newTestNotificationService(config.NotificationsConfig{}, ok, failing).SendTest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []NotificationResult{
+		{Channel: "email", Sent: true},
+		{Channel: "telegram", Error: "chat not found"},
+	}, results, "test notifications ignore the event toggles and try every channel")
+}
+
+func TestNewNotificationService_Channels(t *testing.T) {
+	service := NewNotificationService(config.NotificationsConfig{
+		SMTP:     config.SMTPConfig{Host: "smtp.example.com", Port: 587, To: []string{"ops@example.com"}},
+		Telegram: config.TelegramConfig{BotToken: "token"},
+	}, nil)
+	assert.Equal(t, []string{"email"}, service.Channels(), "telegram needs a chat ID")
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	sender := NewSMTPSender(config.SMTPConfig{
+		Host:     "smtp.example.com",
+		Port:     587,
+		Username: "pulse@example.com",
+		Password: "secret",
+		To:       []string{"ops@example.com", "cto@example.com"},
+	})
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	sender.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		assert.NotNil(t, auth)
+		return nil
+	}
+
+	require.NoError(t, sender.Send(context.Background(), Notification{Subject: "Done", Text: "Operation op-1 completed."}))
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "pulse@example.com", gotFrom, "the sender defaults to the username")
+	assert.Equal(t, []string{"ops@example.com", "cto@example.com"}, gotTo)
+	assert.Contains(t, string(gotMsg), "Subject: Done\r\n")
+	assert.Contains(t, string(gotMsg), "To: ops@example.com, cto@example.com\r\n")
+	assert.Contains(t, string(gotMsg), "\r\n\r\nOperation op-1 completed.")
+}
+
+func TestTelegramSender_Send(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botgood-token/sendMessage" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	sender := NewTelegramSender(config.TelegramConfig{BotToken: "good-token", ChatID: "-100123"})
+	sender.baseURL = server.URL
+	require.NoError(t, sender.Send(context.Background(), Notification{Subject: "Done", Text: "Operation op-1 completed."}))
+	assert.Equal(t, "-100123", body["chat_id"])
+	assert.Equal(t, "Done\n\nOperation op-1 completed.", body["text"])
+
+	sender = NewTelegramSender(config.TelegramConfig{BotToken: "bad-token", ChatID: "-100123"})
+	sender.baseURL = server.URL
+	err := sender.Send(context.Background(), Notification{Subject: "Done"})
+	require.Error(t, err)
+	assert.Equal(t, "send telegram message: Unauthorized", err.Error())
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/operations"
+)
+
+// Operation template errors
+var (
+	ErrTemplateNotFound = apierrors.Newf(apierrors.NotFound, "operation template not found")
+	ErrTemplateExists   = apierrors.Newf(apierrors.Conflict, "operation template already exists")
+	ErrTemplateReadOnly = apierrors.Newf(apierrors.Conflict, "built-in operation templates cannot be changed")
+)
+
+// templatesFileName is the template store kept in the profile's data directory
+const templatesFileName = "operation_templates.json"
+
+// templateNamePattern keeps template names usable in URLs
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// templateSteps lists the steps a template can select
+var templateSteps = []string{
+	operations.StageIDMigration,
+	operations.StageIDScraping,
+	operations.StageIDProcessing,
+	operations.StageIDIndices,
+	operations.StageIDLiquidity,
+	operations.StageIDRetention,
+}
+
+// OperationTemplate is a named operation preset. Running it starts an
+// operation with its scrape mode, date range, steps and parameters.
+type OperationTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Mode is the scrape mode: initial or accumulative
+	Mode string `json:"mode"`
+	// From and To are fixed YYYY-MM-DD dates; LookbackDays starts the range
+	// that many days before the run instead of at From
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+	LookbackDays int    `json:"lookbackDays,omitempty"`
+	// Steps selects the steps to run in dependency order; empty runs the full pipeline
+	Steps      []string               `json:"steps,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Builtin    bool                   `json:"builtin"`
+
+	// Timestamps are unset on built-in templates
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// BuiltinOperationTemplates returns the templates every profile starts with
+func BuiltinOperationTemplates() []OperationTemplate {
+	return []OperationTemplate{
+		{
+			Name:        "nightly-accumulative",
+			Description: "Download reports published since the last run and rebuild every output",
+			Mode:        operations.ModeAccumulative,
+			Builtin:     true,
+		},
+		{
+			Name:        "full-rebuild",
+			Description: "Re-run the initial download from the scraper's default start date and rebuild every output",
+			Mode:        operations.ModeInitial,
+			Builtin:     true,
+		},
+	}
+}
+
+// OperationTemplateInput is the editable part of a template
+type OperationTemplateInput struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Mode         string                 `json:"mode"`
+	From         string                 `json:"from"`
+	To           string                 `json:"to"`
+	LookbackDays int                    `json:"lookbackDays"`
+	Steps        []string               `json:"steps"`
+	Parameters   map[string]interface{} `json:"parameters"`
+}
+
+// Validate normalizes and checks the input
+func (in *OperationTemplateInput) Validate() error {
+	in.Name = strings.TrimSpace(strings.ToLower(in.Name))
+	in.Description = strings.TrimSpace(in.Description)
+	in.Mode = strings.TrimSpace(in.Mode)
+	if !templateNamePattern.MatchString(in.Name) {
+		return fmt.Errorf("%w: name must be 1-64 lowercase letters, digits or dashes", ErrInvalidInput)
+	}
+	if in.Mode == "" {
+		in.Mode = operations.ModeAccumulative
+	}
+	if in.Mode != operations.ModeAccumulative && in.Mode != operations.ModeInitial {
+		return fmt.Errorf("%w: mode must be %s or %s", ErrInvalidInput, operations.ModeAccumulative, operations.ModeInitial)
+	}
+	for field, v := range map[string]string{"from": in.From, "to": in.To} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return fmt.Errorf("%w: %s must be a date in YYYY-MM-DD format", ErrInvalidInput, field)
+		}
+	}
+	if in.From != "" && in.To != "" && in.From > in.To {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidInput)
+	}
+	if in.LookbackDays < 0 {
+		return fmt.Errorf("%w: lookbackDays must not be negative", ErrInvalidInput)
+	}
+	if in.LookbackDays > 0 && in.From != "" {
+		return fmt.Errorf("%w: set either from or lookbackDays", ErrInvalidInput)
+	}
+
+	seen := make(map[string]bool, len(in.Steps))
+	for _, step := range in.Steps {
+		if !isTemplateStep(step) {
+			return fmt.Errorf("%w: unknown step %q (use: %s)", ErrInvalidInput, step, strings.Join(templateSteps, ", "))
+		}
+		if seen[step] {
+			return fmt.Errorf("%w: step %q listed twice", ErrInvalidInput, step)
+		}
+		seen[step] = true
+	}
+	for _, reserved := range []string{"step", operations.ContextKeySteps, "mode", "from", "to", "template"} {
+		if _, ok := in.Parameters[reserved]; ok {
+			return fmt.Errorf("%w: parameter %q is set by the template itself", ErrInvalidInput, reserved)
+		}
+	}
+	return nil
+}
+
+func isTemplateStep(id string) bool {
+	for _, s := range templateSteps {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Request builds the operation request that runs the template at now
+func (t *OperationTemplate) Request(id string, now time.Time) *operations.OperationRequest {
+	params := make(map[string]interface{}, len(t.Parameters)+4)
+	for k, v := range t.Parameters {
+		params[k] = v
+	}
+	params["mode"] = t.Mode
+	params["template"] = t.Name
+
+	switch len(t.Steps) {
+	case 0:
+		params["step"] = "full_pipeline"
+	case 1:
+		params["step"] = t.Steps[0]
+	default:
+		params[operations.ContextKeySteps] = append([]string(nil), t.Steps...)
+	}
+
+	from := t.From
+	if t.LookbackDays > 0 {
+		from = now.AddDate(0, 0, -t.LookbackDays).Format("2006-01-02")
+	}
+	if from != "" {
+		params["from"] = from
+	}
+	if t.To != "" {
+		params["to"] = t.To
+	}
+
+	return &operations.OperationRequest{
+		ID:         id,
+		Mode:       "full",
+		FromDate:   from,
+		ToDate:     t.To,
+		Parameters: params,
+	}
+}
+
+// OperationTemplateService stores operation templates as JSON in the data
+// directory of each profile, next to the built-in templates
+type OperationTemplateService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+// NewOperationTemplateService creates a new operation template service
+func NewOperationTemplateService(paths *config.Paths, logger *slog.Logger) *OperationTemplateService {
+	return &OperationTemplateService{
+		paths:  paths,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// List returns the built-in templates followed by the stored ones by name
+func (s *OperationTemplateService) List(ctx context.Context) ([]OperationTemplate, error) {
+	s.mu.Lock()
+	store, err := s.load(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := BuiltinOperationTemplates()
+	stored := make([]OperationTemplate, 0, len(store))
+	for _, t := range store {
+		stored = append(stored, *t)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].Name < stored[j].Name
+	})
+	return append(templates, stored...), nil
+}
+
+// Get returns one template by name
+func (s *OperationTemplateService) Get(ctx context.Context, name string) (*OperationTemplate, error) {
+	for _, t := range BuiltinOperationTemplates() {
+		if t.Name == name {
+			return &t, nil
+		}
+	}
+
+	s.mu.Lock()
+	store, err := s.load(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := store[name]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+	return t, nil
+}
+
+// Create adds a template
+func (s *OperationTemplateService) Create(ctx context.Context, in OperationTemplateInput) (*OperationTemplate, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	if isBuiltinTemplate(in.Name) {
+		return nil, ErrTemplateExists
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := store[in.Name]; ok {
+		return nil, ErrTemplateExists
+	}
+
+	now := s.now().UTC()
+	t := &OperationTemplate{CreatedAt: &now}
+	applyTemplateInput(t, in, now)
+	store[t.Name] = t
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Update replaces the editable fields of a template. The name in the input
+// renames the template.
+func (s *OperationTemplateService) Update(ctx context.Context, name string, in OperationTemplateInput) (*OperationTemplate, error) {
+	if isBuiltinTemplate(name) {
+		return nil, ErrTemplateReadOnly
+	}
+	if in.Name == "" {
+		in.Name = name
+	}
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := store[name]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+	if in.Name != name {
+		if _, taken := store[in.Name]; taken || isBuiltinTemplate(in.Name) {
+			return nil, ErrTemplateExists
+		}
+		delete(store, name)
+	}
+
+	applyTemplateInput(t, in, s.now().UTC())
+	store[t.Name] = t
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Delete removes a template
+func (s *OperationTemplateService) Delete(ctx context.Context, name string) error {
+	if isBuiltinTemplate(name) {
+		return ErrTemplateReadOnly
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := store[name]; !ok {
+		return ErrTemplateNotFound
+	}
+	delete(store, name)
+	return s.save(ctx, store)
+}
+
+// Request builds the operation request that runs the named template
+func (s *OperationTemplateService) Request(ctx context.Context, name, operationID string) (*operations.OperationRequest, error) {
+	t, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	req := t.Request(operationID, s.now())
+	req.Profile = config.ProfileFromContext(ctx)
+
+	if s.logger != nil {
+		s.logger.InfoContext(ctx, "operation template resolved",
+			slog.String("template", t.Name),
+			slog.String("operation_id", operationID),
+			slog.String("from", req.FromDate),
+			slog.String("to", req.ToDate),
+			slog.Any("steps", t.Steps))
+	}
+	return req, nil
+}
+
+func isBuiltinTemplate(name string) bool {
+	for _, t := range BuiltinOperationTemplates() {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func applyTemplateInput(t *OperationTemplate, in OperationTemplateInput, now time.Time) {
+	t.Name = in.Name
+	t.Description = in.Description
+	t.Mode = in.Mode
+	t.From = in.From
+	t.To = in.To
+	t.LookbackDays = in.LookbackDays
+	t.Steps = in.Steps
+	t.Parameters = in.Parameters
+	t.UpdatedAt = &now
+}
+
+func (s *OperationTemplateService) storePath(ctx context.Context) string {
+	return filepath.Join(s.paths.ForContext(ctx).DataDir, templatesFileName)
+}
+
+// load reads the template store; callers hold s.mu
+func (s *OperationTemplateService) load(ctx context.Context) (map[string]*OperationTemplate, error) {
+	store := make(map[string]*OperationTemplate)
+
+	data, err := os.ReadFile(s.storePath(ctx))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read operation templates: %w", err)
+	}
+
+	var templates []*OperationTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse operation templates: %w", err)
+	}
+	for _, t := range templates {
+		store[t.Name] = t
+	}
+	return store, nil
+}
+
+// save writes the template store through a temp file; callers hold s.mu
+func (s *OperationTemplateService) save(ctx context.Context, store map[string]*OperationTemplate) error {
+	path := s.storePath(ctx)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create template directory: %w", err)
+	}
+
+	templates := make([]*OperationTemplate, 0, len(store))
+	for _, t := range store {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode operation templates: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write operation templates: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace operation templates: %w", err)
+	}
+	return nil
+}
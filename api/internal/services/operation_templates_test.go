@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/operations"
+)
+
+func newTestTemplateService(t *testing.T, now time.Time) *OperationTemplateService {
+	service := NewOperationTemplateService(&config.Paths{DataDir: t.TempDir()}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestOperationTemplateService_CRUD(t *testing.T) {
+	ctx := context.Background()
+	service := newTestTemplateService(t, time.Date(2025, 8, 26, 22, 0, 0, 0, time.UTC))
+
+	templates, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "nightly-accumulative", templates[0].Name)
+	assert.True(t, templates[0].Builtin)
+
+	_, err = service.Create(ctx, OperationTemplateInput{Name: "Weekly Liquidity"})
+	assert.ErrorIs(t, err, ErrInvalidInput, "names are URL slugs")
+	_, err = service.Create(ctx, OperationTemplateInput{Name: "weekly", Steps: []string{"publish"}})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.Create(ctx, OperationTemplateInput{Name: "weekly", From: "2025-01-01", LookbackDays: 7})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.Create(ctx, OperationTemplateInput{Name: "weekly", Parameters: map[string]interface{}{"step": "scraping"}})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.Create(ctx, OperationTemplateInput{Name: "full-rebuild"})
+	assert.ErrorIs(t, err, ErrTemplateExists)
+
+	created, err := service.Create(ctx, OperationTemplateInput{
+		Name:         "Weekly",
+		LookbackDays: 7,
+		Steps:        []string{operations.StageIDLiquidity, operations.StageIDProcessing},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "weekly", created.Name)
+	assert.Equal(t, operations.ModeAccumulative, created.Mode, "mode defaults to accumulative")
+	require.NotNil(t, created.CreatedAt)
+
+	_, err = service.Create(ctx, OperationTemplateInput{Name: "weekly"})
+	assert.ErrorIs(t, err, ErrTemplateExists)
+
+	updated, err := service.Update(ctx, "weekly", OperationTemplateInput{Name: "weekly-liquidity", LookbackDays: 14, Steps: []string{operations.StageIDLiquidity}})
+	require.NoError(t, err)
+	assert.Equal(t, 14, updated.LookbackDays)
+	_, err = service.Get(ctx, "weekly")
+	assert.ErrorIs(t, err, ErrTemplateNotFound, "the update renamed the template")
+
+	templates, err = service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, templates, 3)
+	assert.Equal(t, "weekly-liquidity", templates[2].Name)
+
+	_, err = service.Update(ctx, "nightly-accumulative", OperationTemplateInput{Mode: operations.ModeInitial})
+	assert.ErrorIs(t, err, ErrTemplateReadOnly)
+	assert.ErrorIs(t, service.Delete(ctx, "full-rebuild"), ErrTemplateReadOnly)
+	assert.ErrorIs(t, service.Delete(ctx, "missing"), ErrTemplateNotFound)
+	require.NoError(t, service.Delete(ctx, "weekly-liquidity"))
+}
+
+func TestOperationTemplateService_Request(t *testing.T) {
+	ctx := context.Background()
+	service := newTestTemplateService(t, time.Date(2025, 8, 26, 22, 0, 0, 0, time.UTC))
+
+	req, err := service.Request(ctx, "nightly-accumulative", "op-1")
+	require.NoError(t, err)
+	assert.Equal(t, "op-1", req.ID)
+	assert.Equal(t, "full_pipeline", req.Parameters["step"])
+	assert.Equal(t, operations.ModeAccumulative, req.Parameters["mode"])
+	assert.Equal(t, "nightly-accumulative", req.Parameters["template"])
+	assert.Empty(t, req.FromDate)
+
+	_, err = service.Create(ctx, OperationTemplateInput{
+		Name:         "recent-indices",
+		LookbackDays: 10,
+		To:           "2025-12-31",
+		Steps:        []string{operations.StageIDProcessing, operations.StageIDIndices},
+		Parameters:   map[string]interface{}{operations.ContextKeyBandwidthKBps: float64(256)},
+	})
+	require.NoError(t, err)
+
+	req, err = service.Request(ctx, "recent-indices", "op-2")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-08-16", req.FromDate, "the range starts lookbackDays before the run")
+	assert.Equal(t, "2025-08-16", req.Parameters["from"])
+	assert.Equal(t, "2025-12-31", req.ToDate)
+	assert.Equal(t, []string{operations.StageIDProcessing, operations.StageIDIndices}, req.Parameters[operations.ContextKeySteps])
+	assert.NotContains(t, req.Parameters, "step")
+	assert.Equal(t, float64(256), req.Parameters[operations.ContextKeyBandwidthKBps])
+
+	_, err = service.Request(ctx, "missing", "op-3")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"time"
 
 	"isxcli/internal/config"
@@ -100,7 +98,7 @@ func NewOperationService(adapter *WebSocketOperationAdapter, logger *slog.Logger
 	manager := operations.NewManager(adapter, nil, nil)
 	
 	// Register operation steps with WebSocket adapter
-	if err := registerStages(manager, paths.ExecutableDir, logger, adapter); err != nil {
+	if err := registerStages(manager, paths, logger, adapter); err != nil {
 		return nil, fmt.Errorf("failed to register steps: %w", err)
 	}
 
@@ -112,7 +110,7 @@ func NewOperationService(adapter *WebSocketOperationAdapter, logger *slog.Logger
 }
 
 // registerStages registers all operation steps
-func registerStages(manager *operations.Manager, executableDir string, logger *slog.Logger, wsAdapter *WebSocketOperationAdapter) error {
+func registerStages(manager *operations.Manager, paths *config.Paths, logger *slog.Logger, wsAdapter *WebSocketOperationAdapter) error {
 	// Create stage options with WebSocket integration and StatusBroadcaster
 	stageOptions := &operations.StageOptions{
 		EnableProgress: true,
@@ -121,16 +119,21 @@ func registerStages(manager *operations.Manager, executableDir string, logger *s
 	}
 	
 	// Create steps with WebSocket integration for progress reporting
-	scraper := operations.NewScrapingStage(executableDir, logger, stageOptions)
-	processor := operations.NewProcessingStage(executableDir, logger, stageOptions)
-	indices := operations.NewIndicesStage(executableDir, logger, stageOptions)
-	liquidity := operations.NewLiquidityStage(executableDir, logger, stageOptions)
-
-	// Register steps
+	migration := operations.NewMigrationStage(paths, logger, stageOptions)
+	scraper := operations.NewScrapingStage(paths, logger, stageOptions)
+	processor := operations.NewProcessingStage(paths, logger, stageOptions)
+	indices := operations.NewIndicesStage(paths, logger, stageOptions)
+	liquidity := operations.NewLiquidityStage(paths, logger, stageOptions)
+	retention := operations.NewRetentionStage(paths, logger, stageOptions)
+
+	// Register steps; the migration step comes first so a full pipeline
+	// converts old data before anything reads it
+	manager.GetRegistry().Register(migration)
 	manager.GetRegistry().Register(scraper)
 	manager.GetRegistry().Register(processor)
 	manager.GetRegistry().Register(indices)
 	manager.GetRegistry().Register(liquidity)
+	manager.GetRegistry().Register(retention)
 
 	return nil
 }
@@ -223,6 +226,12 @@ func (ps *OperationService) StartScraping(ctx context.Context, params map[string
 		"headless":  getValue(args, "headless", true),
 		"step":     "scraping",
 	}
+	if kbps, ok := args["bandwidth_kbps"]; ok && kbps != nil {
+		scrapingParams["bandwidth_kbps"] = kbps
+	}
+	if batch, ok := args["batch_size"]; ok && batch != nil {
+		scrapingParams["batch_size"] = batch
+	}
 	
 	// Log transformed parameters with detailed mapping
 	if ps.logger != nil {
@@ -253,6 +262,72 @@ func (ps *OperationService) ExecuteOperation(ctx context.Context, request *opera
 	return resp, nil
 }
 
+// PlanOperation works out what an operation would do without running it
+func (ps *OperationService) PlanOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationPlan, error) {
+	plan, err := ps.manager.Plan(ctx, *request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan operation: %w", err)
+	}
+	return plan, nil
+}
+
+// GetOperationPlanGraph returns the step graph of a running operation or an
+// operation type
+func (ps *OperationService) GetOperationPlanGraph(ctx context.Context, id string) (*operations.PlanGraph, error) {
+	graph, err := ps.manager.PlanGraph(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan operation graph: %w", err)
+	}
+	return graph, nil
+}
+
+// GetOperationArtifacts returns the artifact manifest of an operation run
+func (ps *OperationService) GetOperationArtifacts(ctx context.Context, id string) (*operations.ArtifactManifest, error) {
+	return ps.manager.GetArtifacts(id)
+}
+
+// ListOperationHistory returns finished operation runs, newest first
+func (ps *OperationService) ListOperationHistory(ctx context.Context, filter operations.HistoryFilter) ([]operations.RunRecord, error) {
+	return ps.manager.GetHistory().List(filter)
+}
+
+// CompareOperationRuns compares the recorded run of target against base.
+// Without target the latest run is compared; without base, the run before
+// target.
+func (ps *OperationService) CompareOperationRuns(ctx context.Context, base, target string) (*operations.RunComparison, error) {
+	history := ps.manager.GetHistory()
+
+	var targetRun, baseRun *operations.RunRecord
+	runs, err := history.List(operations.HistoryFilter{Limit: operations.DefaultHistoryRuns})
+	if err != nil {
+		return nil, err
+	}
+	if target == "" {
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("no finished runs: %w", operations.ErrRunNotFound)
+		}
+		targetRun = &runs[0]
+	} else if targetRun, err = history.Get(target); err != nil {
+		return nil, err
+	}
+
+	if base == "" {
+		for i := range runs {
+			if runs[i].OperationID != targetRun.OperationID && runs[i].StartedAt.Before(targetRun.StartedAt) {
+				baseRun = &runs[i]
+				break
+			}
+		}
+		if baseRun == nil {
+			return nil, fmt.Errorf("no run before %s: %w", targetRun.OperationID, operations.ErrRunNotFound)
+		}
+	} else if baseRun, err = history.Get(base); err != nil {
+		return nil, err
+	}
+
+	return operations.CompareRuns(baseRun, targetRun), nil
+}
+
 // GetOperationStatus returns the status of a specific operation
 func (ps *OperationService) GetOperationStatus(ctx context.Context, operationID string) (*operations.OperationState, error) {
 	state, err := ps.GetStatus(ctx, operationID)
@@ -274,6 +349,14 @@ func (ps *OperationService) StartProcessing(ctx context.Context, params map[stri
 		"step": "processing",
 		"mode": getValue(params, "mode", "full"),
 	}
+	// from and to scope the run to a date range of reports to reprocess
+	for key, param := range map[string]string{"from": operations.ContextKeyReprocessFrom, "to": operations.ContextKeyReprocessTo} {
+		if v, ok := params[param]; ok {
+			processingParams[param] = v
+		} else if v, ok := params[key]; ok {
+			processingParams[param] = v
+		}
+	}
 
 	return ps.StartOperation(ctx, processingParams)
 }
@@ -396,7 +479,7 @@ func getStageDescription(stageID string) string {
 	descriptions := map[string]string{
 		operations.StageIDScraping:   "Download ISX daily trading reports from the official website",
 		operations.StageIDProcessing: "Convert Excel files to CSV format with data normalization",
-		operations.StageIDIndices:    "Extract ISX60 and ISX15 index values from processed data",
+		operations.StageIDIndices:    "Extract ISX60, ISX15 and sector index values from processed data",
 		operations.StageIDLiquidity:   "Calculate hybrid liquidity metrics and generate liquidity analysis reports",
 	}
 	
@@ -473,26 +556,26 @@ func (ps *OperationService) ExecuteStage(stageID string, ctx context.Context) er
 // ValidateExecutables checks if required executables exist
 func (ps *OperationService) ValidateExecutables(ctx context.Context) error {
 	executables := []string{
-		"scraper.exe",
-		"process.exe",
-		"indexcsv.exe",
+		operations.ScraperExecutable,
+		operations.ProcessorExecutable,
+		operations.IndexCSVExecutable,
 	}
 
 	for _, exe := range executables {
-		path := filepath.Join(ps.paths.ExecutableDir, exe)
 		if ps.logger != nil {
 			ps.logger.Debug("Checking for executable",
-				slog.String("exe", exe),
-				slog.String("path", path))
+				slog.String("exe", operations.ExecutableName(exe)),
+				slog.String("dir", ps.paths.ExecutableDir))
 		}
 		
-		if _, err := os.Stat(path); os.IsNotExist(err) {
+		path, err := operations.ResolveExecutable(ps.paths.ExecutableDir, exe)
+		if err != nil {
 			if ps.logger != nil {
 				ps.logger.Error("Required executable not found",
-					slog.String("exe", exe),
-					slog.String("path", path))
+					slog.String("exe", operations.ExecutableName(exe)),
+					slog.String("error", err.Error()))
 			}
-			return fmt.Errorf("required executable not found: %s at %s", exe, path)
+			return fmt.Errorf("required executable not found: %w", err)
 		}
 		
 		if ps.logger != nil {
@@ -514,23 +597,29 @@ func (ps *OperationService) GetManager() *operations.Manager {
 func (ps *OperationService) GetStageInfo() map[string]interface{} {
 	return map[string]interface{}{
 		"steps": []map[string]interface{}{
+			{
+				"id":   "migration",
+				"name": "Data Migration",
+				"description": "Convert data written by an older release to the current layout (runs only when a migration is pending)",
+				"executable":  "",
+			},
 			{
 				"id":   "scraping",
 				"name": "Scraping",
 				"description": "Download daily reports from ISX website",
-				"executable":  "scraper.exe",
+				"executable":  operations.ExecutableName(operations.ScraperExecutable),
 			},
 			{
 				"id":   "processing",
 				"name": "Processing",
 				"description": "Process Excel files into CSV format",
-				"executable":  "process.exe",
+				"executable":  operations.ExecutableName(operations.ProcessorExecutable),
 			},
 			{
 				"id":   "indices",
 				"name": "Index Extraction",
 				"description": "Extract market indices from processed data",
-				"executable":  "indexcsv.exe",
+				"executable":  operations.ExecutableName(operations.IndexCSVExecutable),
 			},
 			{
 				"id":   "liquidity",
@@ -538,6 +627,12 @@ func (ps *OperationService) GetStageInfo() map[string]interface{} {
 				"description": "Calculate hybrid liquidity metrics and generate liquidity analysis reports",
 				"executable":  "",
 			},
+			{
+				"id":   "retention",
+				"name": "Data Retention",
+				"description": "Archive old Excel reports and delete old daily CSVs (runs only when a retention policy is set)",
+				"executable":  "",
+			},
 		},
 	}
 }
@@ -143,12 +143,15 @@ func TestGetStageInfo(t *testing.T) {
 	
 	steps, ok := info["steps"].([]map[string]interface{})
 	assert.True(t, ok)
-	assert.Len(t, steps, 4)
+	assert.Len(t, steps, 6)
 	
-	// Check first step
-	assert.Equal(t, "scraping", steps[0]["id"])
-	assert.Equal(t, "Scraping", steps[0]["name"])
-	assert.Equal(t, "scraper.exe", steps[0]["executable"])
+	// The optional migration step runs before anything reads the data
+	assert.Equal(t, "migration", steps[0]["id"])
+	
+	// Check first executable step
+	assert.Equal(t, "scraping", steps[1]["id"])
+	assert.Equal(t, "Scraping", steps[1]["name"])
+	assert.Equal(t, operations.ExecutableName(operations.ScraperExecutable), steps[1]["executable"])
 }
 
 // BenchmarkGetValue benchmarks the getValue helper
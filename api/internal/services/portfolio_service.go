@@ -0,0 +1,611 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+)
+
+// Portfolio errors
+var (
+	ErrPortfolioNotFound   = apierrors.Newf(apierrors.NotFound, "portfolio not found")
+	ErrHoldingNotFound     = apierrors.Newf(apierrors.NotFound, "holding not found")
+	ErrInsufficientHolding = apierrors.Newf(apierrors.Conflict, "sell quantity exceeds holding")
+)
+
+// portfoliosFileName is the portfolio store kept in the profile's data directory
+const portfoliosFileName = "portfolios.json"
+
+// Transaction sides. Adjustments record direct holding edits so the log stays complete.
+const (
+	TransactionBuy    = "buy"
+	TransactionSell   = "sell"
+	TransactionAdjust = "adjust"
+)
+
+// Holding is a position in one ticker, valued at average cost
+type Holding struct {
+	Symbol      string  `json:"symbol"`
+	Quantity    int64   `json:"quantity"`
+	AverageCost float64 `json:"averageCost"`
+	RealizedPnL float64 `json:"realizedPnl"`
+}
+
+// PortfolioTransaction is one entry of a portfolio's transaction log
+type PortfolioTransaction struct {
+	ID       string    `json:"id"`
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"`
+	Quantity int64     `json:"quantity"`
+	Price    float64   `json:"price"`
+	Fees     float64   `json:"fees,omitempty"`
+	Date     time.Time `json:"date"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// Portfolio is a named set of holdings with its transaction log
+type Portfolio struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Holdings     []Holding              `json:"holdings"`
+	Transactions []PortfolioTransaction `json:"transactions"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	UpdatedAt    time.Time              `json:"updatedAt"`
+}
+
+// PositionValuation is a holding joined with the latest price and liquidity data
+type PositionValuation struct {
+	Holding
+	CompanyName   string   `json:"companyName,omitempty"`
+	Sector        string   `json:"sector"`
+	LastPrice     float64  `json:"lastPrice"`
+	LastDate      string   `json:"lastDate,omitempty"`
+	MarketValue   float64  `json:"marketValue"`
+	CostBasis     float64  `json:"costBasis"`
+	UnrealizedPnL float64  `json:"unrealizedPnl"`
+	UnrealizedPct float64  `json:"unrealizedPct"`
+	Weight        float64  `json:"weight"`
+	OptimalTrade  float64  `json:"optimalTrade,omitempty"`
+	SafeTrade1Pct float64  `json:"safeTrade1Pct,omitempty"`
+	TradesToExit  float64  `json:"tradesToExit,omitempty"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+// SectorExposure is the market value held in one sector
+type SectorExposure struct {
+	Sector      string  `json:"sector"`
+	MarketValue float64 `json:"marketValue"`
+	Weight      float64 `json:"weight"`
+}
+
+// PortfolioValuation is a portfolio with P&L, sector exposure and liquidity warnings
+type PortfolioValuation struct {
+	ID            string              `json:"id"`
+	Name          string              `json:"name"`
+	Positions     []PositionValuation `json:"positions"`
+	Exposure      []SectorExposure    `json:"exposure"`
+	MarketValue   float64             `json:"marketValue"`
+	CostBasis     float64             `json:"costBasis"`
+	UnrealizedPnL float64             `json:"unrealizedPnl"`
+	RealizedPnL   float64             `json:"realizedPnl"`
+	Warnings      int                 `json:"warnings"`
+	ValuedAt      time.Time           `json:"valuedAt"`
+}
+
+// TransactionInput is a buy or sell to record against a portfolio
+type TransactionInput struct {
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"`
+	Quantity int64     `json:"quantity"`
+	Price    float64   `json:"price"`
+	Fees     float64   `json:"fees,omitempty"`
+	Date     time.Time `json:"date"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// Validate checks the transaction before it is applied
+func (t *TransactionInput) Validate() error {
+	t.Symbol = strings.ToUpper(strings.TrimSpace(t.Symbol))
+	t.Side = strings.ToLower(strings.TrimSpace(t.Side))
+	switch {
+	case t.Symbol == "":
+		return fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	case t.Side != TransactionBuy && t.Side != TransactionSell:
+		return fmt.Errorf("%w: side must be buy or sell", ErrInvalidInput)
+	case t.Quantity <= 0:
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidInput)
+	case t.Price <= 0:
+		return fmt.Errorf("%w: price must be positive", ErrInvalidInput)
+	case t.Fees < 0:
+		return fmt.Errorf("%w: fees must not be negative", ErrInvalidInput)
+	}
+	return nil
+}
+
+// liquidityInsightsSource provides the latest safe-trade thresholds per ticker
+type liquidityInsightsSource interface {
+	GetLatestInsights(ctx context.Context) (*LiquidityInsights, error)
+}
+
+// tickerQuote is the latest price of a ticker from the ticker summary
+type tickerQuote struct {
+	Ticker      string  `json:"ticker"`
+	CompanyName string  `json:"company_name"`
+	LastPrice   float64 `json:"last_price"`
+	LastDate    string  `json:"last_date"`
+}
+
+// PortfolioService manages portfolios and values them against the latest
+// ticker summary and liquidity report. Portfolios are stored as JSON in the
+// data directory of the request's profile.
+type PortfolioService struct {
+	paths     *config.Paths
+	liquidity liquidityInsightsSource
+	logger    *slog.Logger
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// NewPortfolioService creates a new portfolio service
+func NewPortfolioService(paths *config.Paths, liquidity *LiquidityService, logger *slog.Logger) *PortfolioService {
+	s := &PortfolioService{
+		paths:  paths,
+		logger: logger,
+		now:    time.Now,
+	}
+	// Avoid a typed nil interface when liquidity data is not wired
+	if liquidity != nil {
+		s.liquidity = liquidity
+	}
+	return s
+}
+
+// List returns all portfolios ordered by name
+func (s *PortfolioService) List(ctx context.Context) ([]Portfolio, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolios := make([]Portfolio, 0, len(store))
+	for _, p := range store {
+		portfolios = append(portfolios, *p)
+	}
+	sort.Slice(portfolios, func(i, j int) bool {
+		return portfolios[i].Name < portfolios[j].Name
+	})
+	return portfolios, nil
+}
+
+// Get returns one portfolio
+func (s *PortfolioService) Get(ctx context.Context, id string) (*Portfolio, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := store[id]
+	if !ok {
+		return nil, ErrPortfolioNotFound
+	}
+	return p, nil
+}
+
+// Create adds an empty portfolio
+func (s *PortfolioService) Create(ctx context.Context, name string) (*Portfolio, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	p := &Portfolio{
+		ID:           uuid.New().String(),
+		Name:         name,
+		Holdings:     []Holding{},
+		Transactions: []PortfolioTransaction{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	store[p.ID] = p
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Rename changes a portfolio's name
+func (s *PortfolioService) Rename(ctx context.Context, id, name string) (*Portfolio, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	return s.update(ctx, id, func(p *Portfolio) error {
+		p.Name = name
+		return nil
+	})
+}
+
+// Delete removes a portfolio and its transaction log
+func (s *PortfolioService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := store[id]; !ok {
+		return ErrPortfolioNotFound
+	}
+	delete(store, id)
+	return s.save(ctx, store)
+}
+
+// RecordTransaction applies a buy or sell to the holding and appends it to
+// the log. Buys update the average cost (fees included); sells realize P&L
+// against it.
+func (s *PortfolioService) RecordTransaction(ctx context.Context, id string, in TransactionInput) (*Portfolio, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	if in.Date.IsZero() {
+		in.Date = s.now().UTC()
+	}
+
+	return s.update(ctx, id, func(p *Portfolio) error {
+		idx := holdingIndex(p, in.Symbol)
+		if idx < 0 {
+			if in.Side == TransactionSell {
+				return fmt.Errorf("%w: no %s position", ErrInsufficientHolding, in.Symbol)
+			}
+			p.Holdings = append(p.Holdings, Holding{Symbol: in.Symbol})
+			idx = len(p.Holdings) - 1
+		}
+		h := &p.Holdings[idx]
+
+		switch in.Side {
+		case TransactionBuy:
+			cost := h.AverageCost*float64(h.Quantity) + in.Price*float64(in.Quantity) + in.Fees
+			h.Quantity += in.Quantity
+			h.AverageCost = cost / float64(h.Quantity)
+		case TransactionSell:
+			if in.Quantity > h.Quantity {
+				return fmt.Errorf("%w: selling %d %s, holding %d", ErrInsufficientHolding, in.Quantity, in.Symbol, h.Quantity)
+			}
+			h.RealizedPnL += (in.Price-h.AverageCost)*float64(in.Quantity) - in.Fees
+			h.Quantity -= in.Quantity
+		}
+
+		p.Transactions = append(p.Transactions, PortfolioTransaction{
+			ID:       uuid.New().String(),
+			Symbol:   in.Symbol,
+			Side:     in.Side,
+			Quantity: in.Quantity,
+			Price:    in.Price,
+			Fees:     in.Fees,
+			Date:     in.Date,
+			Note:     in.Note,
+		})
+		return nil
+	})
+}
+
+// SetHolding creates or overwrites a holding directly, e.g. when importing an
+// existing position. The edit is logged as an adjustment.
+func (s *PortfolioService) SetHolding(ctx context.Context, id, symbol string, quantity int64, averageCost float64) (*Portfolio, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+	if quantity <= 0 || averageCost <= 0 {
+		return nil, fmt.Errorf("%w: quantity and averageCost must be positive", ErrInvalidInput)
+	}
+
+	return s.update(ctx, id, func(p *Portfolio) error {
+		idx := holdingIndex(p, symbol)
+		if idx < 0 {
+			p.Holdings = append(p.Holdings, Holding{Symbol: symbol})
+			idx = len(p.Holdings) - 1
+		}
+		p.Holdings[idx].Quantity = quantity
+		p.Holdings[idx].AverageCost = averageCost
+		p.Transactions = append(p.Transactions, s.adjustment(symbol, quantity, averageCost))
+		return nil
+	})
+}
+
+// RemoveHolding deletes a holding; the removal is logged as an adjustment to zero
+func (s *PortfolioService) RemoveHolding(ctx context.Context, id, symbol string) (*Portfolio, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return s.update(ctx, id, func(p *Portfolio) error {
+		idx := holdingIndex(p, symbol)
+		if idx < 0 {
+			return ErrHoldingNotFound
+		}
+		p.Holdings = append(p.Holdings[:idx], p.Holdings[idx+1:]...)
+		p.Transactions = append(p.Transactions, s.adjustment(symbol, 0, 0))
+		return nil
+	})
+}
+
+// Value joins the portfolio's open positions with the latest ticker prices and
+// liquidity safe-trade values. A position worth more than the liquidity-optimal
+// trade size cannot be exited in one trade without notable price impact, so it
+// is flagged.
+func (s *PortfolioService) Value(ctx context.Context, id string) (*PortfolioValuation, error) {
+	p, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes, err := s.loadQuotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := s.loadThresholds(ctx)
+
+	valuation := &PortfolioValuation{
+		ID:        p.ID,
+		Name:      p.Name,
+		Positions: []PositionValuation{},
+		Exposure:  []SectorExposure{},
+		ValuedAt:  s.now().UTC(),
+	}
+
+	sectors := make(map[string]float64)
+	for _, h := range p.Holdings {
+		valuation.RealizedPnL += h.RealizedPnL
+		if h.Quantity == 0 {
+			continue
+		}
+
+		pos := PositionValuation{
+			Holding:   h,
+			Sector:    config.GetTickerSector(h.Symbol),
+			CostBasis: h.AverageCost * float64(h.Quantity),
+		}
+
+		quote, ok := quotes[h.Symbol]
+		if ok && quote.LastPrice > 0 {
+			pos.CompanyName = quote.CompanyName
+			pos.LastPrice = quote.LastPrice
+			pos.LastDate = quote.LastDate
+		} else {
+			// Fall back to cost so totals stay meaningful
+			pos.LastPrice = h.AverageCost
+			pos.Warnings = append(pos.Warnings, "no market price available; valued at cost")
+		}
+
+		pos.MarketValue = pos.LastPrice * float64(h.Quantity)
+		pos.UnrealizedPnL = pos.MarketValue - pos.CostBasis
+		if pos.CostBasis > 0 {
+			pos.UnrealizedPct = pos.UnrealizedPnL / pos.CostBasis * 100
+		}
+
+		if t, ok := thresholds[h.Symbol]; ok && t.Optimal > 0 {
+			pos.OptimalTrade = t.Optimal
+			pos.SafeTrade1Pct = t.Moderate
+			pos.TradesToExit = math.Ceil(pos.MarketValue / t.Optimal)
+			if pos.MarketValue > t.Optimal {
+				pos.Warnings = append(pos.Warnings, fmt.Sprintf(
+					"position value %.0f IQD exceeds liquidity-optimal trade size %.0f IQD; exiting needs about %.0f trades",
+					pos.MarketValue, t.Optimal, pos.TradesToExit))
+			}
+		} else {
+			pos.Warnings = append(pos.Warnings, "no liquidity data available")
+		}
+
+		valuation.MarketValue += pos.MarketValue
+		valuation.CostBasis += pos.CostBasis
+		valuation.UnrealizedPnL += pos.UnrealizedPnL
+		valuation.Warnings += len(pos.Warnings)
+		sectors[pos.Sector] += pos.MarketValue
+		valuation.Positions = append(valuation.Positions, pos)
+	}
+
+	for i := range valuation.Positions {
+		if valuation.MarketValue > 0 {
+			valuation.Positions[i].Weight = valuation.Positions[i].MarketValue / valuation.MarketValue * 100
+		}
+	}
+	sort.Slice(valuation.Positions, func(i, j int) bool {
+		return valuation.Positions[i].MarketValue > valuation.Positions[j].MarketValue
+	})
+
+	for sector, value := range sectors {
+		exposure := SectorExposure{Sector: sector, MarketValue: value}
+		if valuation.MarketValue > 0 {
+			exposure.Weight = value / valuation.MarketValue * 100
+		}
+		valuation.Exposure = append(valuation.Exposure, exposure)
+	}
+	sort.Slice(valuation.Exposure, func(i, j int) bool {
+		return valuation.Exposure[i].MarketValue > valuation.Exposure[j].MarketValue
+	})
+
+	return valuation, nil
+}
+
+func (s *PortfolioService) adjustment(symbol string, quantity int64, averageCost float64) PortfolioTransaction {
+	return PortfolioTransaction{
+		ID:       uuid.New().String(),
+		Symbol:   symbol,
+		Side:     TransactionAdjust,
+		Quantity: quantity,
+		Price:    averageCost,
+		Date:     s.now().UTC(),
+	}
+}
+
+// update loads the store, applies fn to one portfolio and saves it
+func (s *PortfolioService) update(ctx context.Context, id string, fn func(p *Portfolio) error) (*Portfolio, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := store[id]
+	if !ok {
+		return nil, ErrPortfolioNotFound
+	}
+
+	if err := fn(p); err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = s.now().UTC()
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func holdingIndex(p *Portfolio, symbol string) int {
+	for i, h := range p.Holdings {
+		if h.Symbol == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *PortfolioService) storePath(ctx context.Context) string {
+	return filepath.Join(s.paths.ForContext(ctx).DataDir, portfoliosFileName)
+}
+
+// load reads the portfolio store; callers hold s.mu
+func (s *PortfolioService) load(ctx context.Context) (map[string]*Portfolio, error) {
+	store := make(map[string]*Portfolio)
+
+	data, err := os.ReadFile(s.storePath(ctx))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read portfolios: %w", err)
+	}
+
+	var portfolios []*Portfolio
+	if err := json.Unmarshal(data, &portfolios); err != nil {
+		return nil, fmt.Errorf("failed to parse portfolios: %w", err)
+	}
+	for _, p := range portfolios {
+		store[p.ID] = p
+	}
+	return store, nil
+}
+
+// save writes the portfolio store through a temp file; callers hold s.mu
+func (s *PortfolioService) save(ctx context.Context, store map[string]*Portfolio) error {
+	path := s.storePath(ctx)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create portfolio directory: %w", err)
+	}
+
+	portfolios := make([]*Portfolio, 0, len(store))
+	for _, p := range store {
+		portfolios = append(portfolios, p)
+	}
+	sort.Slice(portfolios, func(i, j int) bool {
+		return portfolios[i].CreatedAt.Before(portfolios[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(portfolios, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode portfolios: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write portfolios: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace portfolios: %w", err)
+	}
+	return nil
+}
+
+// loadQuotes reads the latest prices of the request's profile
+func (s *PortfolioService) loadQuotes(ctx context.Context) (map[string]tickerQuote, error) {
+	return readTickerQuotes(s.paths.ForContext(ctx).GetTickerSummaryJSONPath())
+}
+
+// readTickerQuotes reads the latest prices from ticker_summary.json. Both the
+// {"tickers": [...]} envelope and a bare array are accepted; a missing file
+// yields no quotes.
+func readTickerQuotes(path string) (map[string]tickerQuote, error) {
+	quotes := make(map[string]tickerQuote)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return quotes, nil
+		}
+		return nil, fmt.Errorf("failed to read ticker summary: %w", err)
+	}
+
+	var list []tickerQuote
+	var envelope struct {
+		Tickers []tickerQuote `json:"tickers"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Tickers != nil {
+		list = envelope.Tickers
+	} else if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse ticker summary: %w", err)
+	}
+
+	for _, q := range list {
+		quotes[strings.ToUpper(q.Ticker)] = q
+	}
+	return quotes, nil
+}
+
+// loadThresholds returns safe-trade thresholds by symbol. Missing liquidity
+// data is not an error; positions are then reported without liquidity checks.
+func (s *PortfolioService) loadThresholds(ctx context.Context) map[string]TradingThreshold {
+	thresholds := make(map[string]TradingThreshold)
+	if s.liquidity == nil {
+		return thresholds
+	}
+
+	insights, err := s.liquidity.GetLatestInsights(ctx)
+	if err != nil || insights == nil {
+		s.logger.WarnContext(ctx, "Liquidity data unavailable for portfolio valuation",
+			slog.Any("error", err))
+		return thresholds
+	}
+	for _, stock := range insights.AllStocks {
+		thresholds[strings.ToUpper(stock.Symbol)] = stock.Thresholds
+	}
+	return thresholds
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+type stubLiquiditySource struct {
+	insights *LiquidityInsights
+}
+
+func (s stubLiquiditySource) GetLatestInsights(ctx context.Context) (*LiquidityInsights, error) {
+	return s.insights, nil
+}
+
+func newTestPortfolioService(t *testing.T) *PortfolioService {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		DataDir:           dir,
+		TickerSummaryJSON: filepath.Join(dir, "ticker_summary.json"),
+	}
+	require.NoError(t, os.WriteFile(paths.TickerSummaryJSON, []byte(`{"tickers": [
+		{"ticker": "BBOB", "company_name": "Bank of Baghdad", "last_price": 1.2, "last_date": "2025-01-05"},
+		{"ticker": "TASC", "company_name": "Asia Cell", "last_price": 8.0, "last_date": "2025-01-05"}
+	]}`), 0644))
+
+	service := NewPortfolioService(paths, nil, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	service.liquidity = stubLiquiditySource{insights: &LiquidityInsights{AllStocks: []StockRecommendation{
+		{Symbol: "BBOB", Thresholds: TradingThreshold{Moderate: 2_000_000, Optimal: 500_000}},
+		{Symbol: "TASC", Thresholds: TradingThreshold{Moderate: 50_000_000, Optimal: 20_000_000}},
+	}}}
+	service.now = func() time.Time { return time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func TestPortfolioService_TransactionsAndValuation(t *testing.T) {
+	ctx := context.Background()
+	service := newTestPortfolioService(t)
+
+	p, err := service.Create(ctx, "Core")
+	require.NoError(t, err)
+
+	buys := []TransactionInput{
+		{Symbol: "bbob", Side: "buy", Quantity: 1_000_000, Price: 1.0},
+		{Symbol: "BBOB", Side: "buy", Quantity: 1_000_000, Price: 1.1, Fees: 100_000},
+		{Symbol: "TASC", Side: "buy", Quantity: 10_000, Price: 7.5},
+	}
+	for _, tx := range buys {
+		_, err = service.RecordTransaction(ctx, p.ID, tx)
+		require.NoError(t, err)
+	}
+
+	p, err = service.RecordTransaction(ctx, p.ID, TransactionInput{Symbol: "BBOB", Side: "sell", Quantity: 500_000, Price: 1.3})
+	require.NoError(t, err)
+	assert.Len(t, p.Transactions, 4)
+	assert.InDelta(t, 1.1, p.Holdings[0].AverageCost, 1e-9, "fees are part of the cost")
+	assert.InDelta(t, 100_000, p.Holdings[0].RealizedPnL, 1e-6)
+
+	_, err = service.RecordTransaction(ctx, p.ID, TransactionInput{Symbol: "BBOB", Side: "sell", Quantity: 5_000_000, Price: 1.3})
+	assert.True(t, errors.Is(err, ErrInsufficientHolding))
+
+	valuation, err := service.Value(ctx, p.ID)
+	require.NoError(t, err)
+	require.Len(t, valuation.Positions, 2)
+
+	bbob := valuation.Positions[0]
+	assert.Equal(t, "BBOB", bbob.Symbol)
+	assert.Equal(t, "banks", bbob.Sector)
+	assert.InDelta(t, 1_800_000, bbob.MarketValue, 1e-6)
+	assert.InDelta(t, 150_000, bbob.UnrealizedPnL, 1e-6)
+	assert.Equal(t, 4.0, bbob.TradesToExit)
+	require.Len(t, bbob.Warnings, 1, "position above the liquidity-optimal trade size is flagged")
+
+	tasc := valuation.Positions[1]
+	assert.Empty(t, tasc.Warnings)
+	assert.InDelta(t, 5_000, tasc.UnrealizedPnL, 1e-6)
+
+	assert.InDelta(t, 1_880_000, valuation.MarketValue, 1e-6)
+	assert.InDelta(t, 100_000, valuation.RealizedPnL, 1e-6)
+	require.Len(t, valuation.Exposure, 2)
+	assert.Equal(t, "banks", valuation.Exposure[0].Sector)
+	assert.InDelta(t, 1_800_000.0/1_880_000*100, valuation.Exposure[0].Weight, 1e-9)
+}
+
+func TestPortfolioService_HoldingsCRUD(t *testing.T) {
+	ctx := context.Background()
+	service := newTestPortfolioService(t)
+
+	p, err := service.Create(ctx, "Imported")
+	require.NoError(t, err)
+
+	p, err = service.SetHolding(ctx, p.ID, "imap", 1000, 9.5)
+	require.NoError(t, err)
+	require.Len(t, p.Holdings, 1)
+	assert.Equal(t, "IMAP", p.Holdings[0].Symbol)
+	assert.Equal(t, TransactionAdjust, p.Transactions[0].Side)
+
+	valuation, err := service.Value(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 9.5, valuation.Positions[0].LastPrice, "unpriced holdings are valued at cost")
+	assert.Len(t, valuation.Positions[0].Warnings, 2)
+
+	p, err = service.RemoveHolding(ctx, p.ID, "IMAP")
+	require.NoError(t, err)
+	assert.Empty(t, p.Holdings)
+	assert.Len(t, p.Transactions, 2)
+
+	_, err = service.RemoveHolding(ctx, p.ID, "IMAP")
+	assert.True(t, errors.Is(err, ErrHoldingNotFound))
+
+	p, err = service.Rename(ctx, p.ID, "Renamed")
+	require.NoError(t, err)
+	list, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Renamed", list[0].Name)
+
+	require.NoError(t, service.Delete(ctx, p.ID))
+	_, err = service.Get(ctx, p.ID)
+	assert.True(t, errors.Is(err, ErrPortfolioNotFound))
+}
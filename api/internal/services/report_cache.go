@@ -0,0 +1,226 @@
+package services
+
+import (
+	"container/list"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Report cache defaults, used when the cache configuration leaves them unset
+const (
+	DefaultReportCacheTTL        = 5 * time.Minute
+	DefaultReportCacheMaxEntries = 256
+)
+
+// ReportCache keeps parsed report files in memory so dashboard reloads do not
+// re-read CSVs from disk. Entries are keyed by kind and file path and are
+// only served while the file's modification time and size are unchanged and
+// the entry is younger than the TTL. The least recently used entry is evicted
+// once the cache is full. Concurrent misses for the same file version share
+// one parse. A nil *ReportCache reads through to disk.
+//
+// Cached values are shared between requests and must not be modified.
+type ReportCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Front is most recently used
+	hits    uint64
+	misses  uint64
+	shared  uint64
+
+	flight singleflight.Group
+}
+
+// ReportCacheStats counts cache hits, misses and current entries. Shared
+// counts callers that were handed the result of a computation already in
+// flight for an identical request.
+type ReportCacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Shared  uint64 `json:"shared"`
+	Entries int    `json:"entries"`
+}
+
+type reportCacheEntry struct {
+	key      string
+	modTime  time.Time
+	size     int64
+	storedAt time.Time
+	value    interface{}
+}
+
+// NewReportCache creates a cache; ttl <= 0 uses DefaultReportCacheTTL and
+// maxEntries <= 0 uses DefaultReportCacheMaxEntries
+func NewReportCache(ttl time.Duration, maxEntries int) *ReportCache {
+	if ttl <= 0 {
+		ttl = DefaultReportCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultReportCacheMaxEntries
+	}
+	return &ReportCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Load returns the value parsed from path, calling parse only when there is
+// no fresh entry. Stat errors are returned unwrapped so callers can test them
+// with os.IsNotExist; parse errors are not cached.
+func (c *ReportCache) Load(kind, path string, parse func(path string) (interface{}, error)) (interface{}, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return parse(path)
+	}
+
+	key := kind + ":" + path
+	if value, ok := c.lookup(key, info); ok {
+		return value, nil
+	}
+
+	// The file version is part of the flight key so a rewrite is never
+	// answered by a parse of the previous version
+	version := key + "@" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + ":" + strconv.FormatInt(info.Size(), 10)
+	return c.Coalesce(version, func() (interface{}, error) {
+		value, err := parse(path)
+		if err != nil {
+			return nil, err
+		}
+		c.store(key, info, value)
+		return value, nil
+	})
+}
+
+// Coalesce runs compute once for concurrent callers with the same key: the
+// first caller computes, the others wait for and share its result and error.
+// Nothing is kept once the computation returns, so keys must identify the
+// request completely. The shared value must not be modified.
+func (c *ReportCache) Coalesce(key string, compute func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return compute()
+	}
+	leader := false
+	value, err, shared := c.flight.Do(key, func() (interface{}, error) {
+		leader = true
+		return compute()
+	})
+	if shared && !leader {
+		c.mu.Lock()
+		c.shared++
+		c.mu.Unlock()
+	}
+	return value, err
+}
+
+// Invalidate drops every entry, e.g. after the processing pipeline rewrote
+// the reports
+func (c *ReportCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Stats returns the hit and miss counters and the number of entries
+func (c *ReportCache) Stats() ReportCacheStats {
+	if c == nil {
+		return ReportCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ReportCacheStats{Hits: c.hits, Misses: c.misses, Shared: c.shared, Entries: c.lru.Len()}
+}
+
+// lookup returns a cached value if it still matches the file and the TTL
+func (c *ReportCache) lookup(key string, info os.FileInfo) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if ok {
+		entry := element.Value.(*reportCacheEntry)
+		if entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() && c.now().Sub(entry.storedAt) < c.ttl {
+			c.lru.MoveToFront(element)
+			c.hits++
+			return entry.value, true
+		}
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+	c.misses++
+	return nil, false
+}
+
+// store adds or replaces an entry, evicting the least recently used ones
+func (c *ReportCache) store(key string, info os.FileInfo, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &reportCacheEntry{
+		key:      key,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		storedAt: c.now(),
+		value:    value,
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*reportCacheEntry).key)
+	}
+}
+
+// readCSVRecords parses a whole CSV file, header included
+func readCSVRecords(path string) (interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return records, nil
+}
+
+// readJSONValue decodes a whole JSON file. Read errors are *os.PathError;
+// anything else is a decoding error.
+func readJSONValue(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+// countingCSV parses CSV files and counts how often it was called
+func countingCSV(calls *int) func(path string) (interface{}, error) {
+	return func(path string) (interface{}, error) {
+		*calls++
+		return readCSVRecords(path)
+	}
+}
+
+func writeReport(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestReportCacheReusesParsedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "indexes.csv")
+	modTime := time.Now().Add(-time.Hour)
+	writeReport(t, path, "Date,ISX60\n2025-08-20,900\n", modTime)
+
+	cache := NewReportCache(time.Minute, 10)
+	calls := 0
+	for i := 0; i < 3; i++ {
+		value, err := cache.Load("csv", path, countingCSV(&calls))
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"Date", "ISX60"}, {"2025-08-20", "900"}}, value)
+	}
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, ReportCacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+
+	// A rewritten file is parsed again
+	writeReport(t, path, "Date,ISX60\n2025-08-20,900\n2025-08-21,910\n", modTime.Add(time.Minute))
+	value, err := cache.Load("csv", path, countingCSV(&calls))
+	require.NoError(t, err)
+	assert.Len(t, value, 3)
+	assert.Equal(t, 2, calls)
+}
+
+func TestReportCacheExpiresAfterTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily.csv")
+	writeReport(t, path, "Symbol\nBBOB\n", time.Now().Add(-time.Hour))
+
+	now := time.Now()
+	cache := NewReportCache(time.Minute, 10)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	_, err := cache.Load("csv", path, countingCSV(&calls))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = cache.Load("csv", path, countingCSV(&calls))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(time.Minute)
+	_, err = cache.Load("csv", path, countingCSV(&calls))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestReportCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 3)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, string(rune('a'+i))+".csv")
+		writeReport(t, paths[i], "Symbol\nBBOB\n", time.Now().Add(-time.Hour))
+	}
+
+	cache := NewReportCache(time.Minute, 2)
+	calls := 0
+	for _, path := range []string{paths[0], paths[1], paths[0], paths[2]} {
+		_, err := cache.Load("csv", path, countingCSV(&calls))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, cache.Stats().Entries)
+
+	// paths[1] was least recently used when paths[2] was added
+	_, err := cache.Load("csv", paths[0], countingCSV(&calls))
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	_, err = cache.Load("csv", paths[1], countingCSV(&calls))
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestReportCacheInvalidateAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "indexes.csv")
+	writeReport(t, path, "Date,ISX60\n", time.Now().Add(-time.Hour))
+
+	cache := NewReportCache(0, 0)
+	assert.Equal(t, DefaultReportCacheTTL, cache.ttl)
+	assert.Equal(t, DefaultReportCacheMaxEntries, cache.maxEntries)
+
+	calls := 0
+	_, err := cache.Load("csv", path, countingCSV(&calls))
+	require.NoError(t, err)
+	cache.Invalidate()
+	assert.Equal(t, 0, cache.Stats().Entries)
+	_, err = cache.Load("csv", path, countingCSV(&calls))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	_, err = cache.Load("csv", filepath.Join(dir, "missing.csv"), countingCSV(&calls))
+	assert.True(t, os.IsNotExist(err), "stat errors are returned unwrapped")
+
+	broken := filepath.Join(dir, "broken.csv")
+	writeReport(t, broken, "a,b\n\"unterminated\n", time.Now().Add(-time.Hour))
+	_, err = cache.Load("csv", broken, countingCSV(&calls))
+	assert.Error(t, err)
+	assert.Equal(t, 1, cache.Stats().Entries, "parse errors are not cached")
+
+	// A nil cache reads through
+	var none *ReportCache
+	value, err := none.Load("csv", path, readCSVRecords)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Date", "ISX60"}}, value)
+	none.Invalidate()
+	assert.Equal(t, ReportCacheStats{}, none.Stats())
+}
+
+func TestReportCacheCoalescesConcurrentRequests(t *testing.T) {
+	cache := NewReportCache(time.Minute, 10)
+	release := make(chan struct{})
+	var calls, joined atomic.Int32
+	compute := func() (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return []string{"BBOB"}, nil
+	}
+
+	const callers = 8
+	results := make([]interface{}, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			joined.Add(1)
+			value, err := cache.Coalesce("movers:1d", compute)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i)
+	}
+	require.Eventually(t, func() bool { return joined.Load() == callers }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond) // let every caller reach the flight
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, value := range results {
+		assert.Equal(t, []string{"BBOB"}, value)
+	}
+	assert.Equal(t, uint64(callers-1), cache.Stats().Shared, "the caller that computed is not counted")
+
+	// Once the flight has landed the next call computes again
+	_, err := cache.Coalesce("movers:1d", func() (interface{}, error) { calls.Add(1); return nil, nil })
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	var none *ReportCache
+	value, err := none.Coalesce("movers:1d", compute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BBOB"}, value)
+}
+
+func TestReportCacheLoadSharesOneParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily.csv")
+	writeReport(t, path, "Symbol\nBBOB\n", time.Now().Add(-time.Hour))
+
+	cache := NewReportCache(time.Minute, 10)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	parse := func(path string) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return readCSVRecords(path)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.Load("csv", path, parse)
+			assert.NoError(t, err)
+			assert.Equal(t, [][]string{{"Symbol"}, {"BBOB"}}, value)
+		}()
+	}
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, 1, cache.Stats().Entries)
+}
+
+func TestDataServiceIndicesServedFromCache(t *testing.T) {
+	dir := t.TempDir()
+	indexFile := filepath.Join(dir, "indexes.csv")
+	ds := &DataService{
+		config: &config.Config{},
+		paths:  &config.Paths{DataDir: dir, IndexCSV: indexFile},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cache:  NewReportCache(time.Minute, 10),
+	}
+	writeReport(t, indexFile, "Date,ISX60,ISX15\n2025-08-20,900,100\n", time.Now().Add(-time.Hour))
+
+	first, err := ds.GetIndices(context.Background())
+	require.NoError(t, err)
+	second, err := ds.GetIndices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, first["isx60"], second["isx60"])
+	assert.Equal(t, uint64(1), ds.CacheStats().Hits)
+
+	ds.InvalidateCache()
+	assert.Equal(t, 0, ds.CacheStats().Entries)
+}
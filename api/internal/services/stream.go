@@ -0,0 +1,179 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// streamChunkSize is the read size for chunked (gzip) responses; each chunk is flushed
+	streamChunkSize = 256 * 1024
+
+	// streamWriteTimeout is the per-write deadline for streamed responses. The server's
+	// WriteTimeout covers the whole response, which large datasets easily exceed, so the
+	// deadline is pushed forward while the client keeps reading.
+	streamWriteTimeout = 30 * time.Second
+)
+
+// StreamFile streams a report or download file without loading it into memory.
+//
+// Plain requests are served with Content-Length and full Range support (206 partial
+// content, If-Range, conditional GETs). When the client accepts gzip, sends no Range
+// header and compress=false is not set, the file is compressed on the fly and sent
+// with Transfer-Encoding: chunked, flushing after every chunk.
+func (ds *DataService) StreamFile(ctx context.Context, w http.ResponseWriter, r *http.Request, fileType, filename string) error {
+	absFilePath, err := ds.resolveDownloadPath(ctx, fileType, filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(absFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrFileNotFound
+		}
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return ErrFileNotFound
+	}
+
+	baseFilename := filepath.Base(absFilePath)
+	contentType := mime.TypeByExtension(filepath.Ext(baseFilename))
+	if strings.HasSuffix(strings.ToLower(baseFilename), ".csv") {
+		contentType = "text/csv; charset=utf-8"
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", baseFilename))
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	sw := &deadlineWriter{ResponseWriter: w, rc: http.NewResponseController(w)}
+
+	if !wantsGzip(r) {
+		ds.logger.DebugContext(ctx, "Streaming file",
+			slog.String("file", baseFilename),
+			slog.Int64("size", info.Size()),
+			slog.String("range", r.Header.Get("Range")))
+
+		http.ServeContent(sw, r, baseFilename, info.ModTime(), file)
+		return nil
+	}
+
+	ds.logger.DebugContext(ctx, "Streaming file with gzip",
+		slog.String("file", baseFilename),
+		slog.Int64("size", info.Size()))
+
+	// Content-Length is unknown once compressed, so the response goes out chunked
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Del("Accept-Ranges")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	gz := gzip.NewWriter(sw)
+	written, err := copyChunked(ctx, gz, file, sw.rc)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Headers are already sent, so the error can only be logged
+		ds.logger.WarnContext(ctx, "Streaming aborted",
+			slog.String("file", baseFilename),
+			slog.Int64("bytes_read", written),
+			slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// wantsGzip reports whether the response should be gzip-compressed. Range requests
+// are always served uncompressed so byte offsets refer to the file on disk.
+func wantsGzip(r *http.Request) bool {
+	if r.Header.Get("Range") != "" || r.URL.Query().Get("compress") == "false" {
+		return false
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// copyChunked copies src to dst in streamChunkSize pieces, flushing after each one
+// and stopping when the request context is cancelled. It returns bytes read from src.
+func copyChunked(ctx context.Context, dst io.Writer, src io.Reader, rc *http.ResponseController) (int64, error) {
+	buf := make([]byte, streamChunkSize)
+	flusher, _ := dst.(interface{ Flush() error })
+	var total int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			total += int64(n)
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return total, err
+			}
+			if flusher != nil {
+				if err := flusher.Flush(); err != nil {
+					return total, err
+				}
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return total, err
+			}
+		}
+		if readErr == io.EOF {
+			return total, nil
+		}
+		if readErr != nil {
+			return total, readErr
+		}
+	}
+}
+
+// deadlineWriter extends the connection write deadline before every write so long
+// downloads are limited by client inactivity rather than total transfer time
+type deadlineWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (d *deadlineWriter) Write(p []byte) (int, error) {
+	// ErrNotSupported (e.g. httptest recorders) just leaves the server default in place
+	_ = d.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return d.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (d *deadlineWriter) Unwrap() http.ResponseWriter {
+	return d.ResponseWriter
+}
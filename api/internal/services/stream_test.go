@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"isxcli/internal/config"
+	"isxcli/internal/infrastructure"
+	"isxcli/internal/middleware"
+)
+
+func newStreamTestService(t *testing.T) (*DataService, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(filepath.Join(reportsDir, "combined"), 0755))
+
+	content := "Date,Symbol,ClosePrice\n" + strings.Repeat("2025-01-05,BBOB,1.000\n", 40000)
+	require.NoError(t, os.WriteFile(filepath.Join(reportsDir, "combined", "isx_combined_data.csv"), []byte(content), 0644))
+
+	return &DataService{
+		config: &config.Config{},
+		paths: &config.Paths{
+			DataDir:      tempDir,
+			DownloadsDir: filepath.Join(tempDir, "downloads"),
+			ReportsDir:   reportsDir,
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, content
+}
+
+func TestStreamFile_FullAndRange(t *testing.T) {
+	service, content := newStreamTestService(t)
+	ctx := context.Background()
+
+	req := httptest.NewRequest("GET", "/stream/reports/combined/isx_combined_data.csv", nil)
+	w := httptest.NewRecorder()
+	require.NoError(t, service.StreamFile(ctx, w, req, "reports", "combined/isx_combined_data.csv"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, content, w.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "isx_combined_data.csv")
+
+	// Range requests are never compressed, even if gzip is accepted
+	req = httptest.NewRequest("GET", "/stream/reports/combined/isx_combined_data.csv", nil)
+	req.Header.Set("Range", "bytes=5-11")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	require.NoError(t, service.StreamFile(ctx, w, req, "reports", "combined/isx_combined_data.csv"))
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, content[5:12], w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Get("Content-Range"), "bytes 5-11/")
+}
+
+func TestStreamFile_GzipChunked(t *testing.T) {
+	service, content := newStreamTestService(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := service.StreamFile(r.Context(), w, r, "reports", "combined/isx_combined_data.csv"); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+	// Setting the header explicitly stops the client from decompressing transparently
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+	assert.Equal(t, int64(-1), resp.ContentLength)
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(body))
+}
+
+// TestStreamFile_OutlivesWriteTimeout streams through the API middleware on a
+// real server whose WriteTimeout is far shorter than the download, as the
+// server's 15s default is for large datasets
+func TestStreamFile_OutlivesWriteTimeout(t *testing.T) {
+	service, _ := newStreamTestService(t)
+	content := make([]byte, 8<<20)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(service.paths.ReportsDir, "combined", "large.csv"), content, 0644))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	otelMiddleware, err := middleware.NewOTelMiddleware(&infrastructure.OTelProviders{
+		Tracer: tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:  metricnoop.NewMeterProvider().Meter("test"),
+		Logger: logger,
+	})
+	require.NoError(t, err)
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := service.StreamFile(r.Context(), w, r, "reports", "combined/large.csv"); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	handler = middleware.SecurityHeaders(handler)
+	handler = middleware.Recoverer(logger)(handler)
+	handler = middleware.StructuredLogger(logger)(handler)
+	handler = otelMiddleware.Handler(handler)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	for _, encoding := range []string{"identity", "gzip"} {
+		t.Run(encoding, func(t *testing.T) {
+			req, err := http.NewRequest("GET", server.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept-Encoding", encoding)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// Read slowly so the transfer takes several write timeouts
+			var body io.Reader = &slowReader{r: resp.Body, delay: 5 * time.Millisecond}
+			if encoding == "gzip" {
+				body, err = gzip.NewReader(body)
+				require.NoError(t, err)
+			}
+			got, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(content, got), "got %d of %d bytes", len(got), len(content))
+		})
+	}
+}
+
+// slowReader pauses before every read of at most 64 KiB
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	if len(p) > 64<<10 {
+		p = p[:64<<10]
+	}
+	return s.r.Read(p)
+}
+
+func TestStreamFile_Errors(t *testing.T) {
+	service, _ := newStreamTestService(t)
+	ctx := context.Background()
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	err := service.StreamFile(ctx, httptest.NewRecorder(), req, "reports", "missing.csv")
+	assert.ErrorIs(t, err, ErrFileNotFound)
+
+	err = service.StreamFile(ctx, httptest.NewRecorder(), req, "secrets", "x.csv")
+	assert.ErrorIs(t, err, ErrInvalidFileType)
+
+	err = service.StreamFile(ctx, httptest.NewRecorder(), req, "reports", "combined")
+	assert.ErrorIs(t, err, ErrFileNotFound, "directories are not streamed")
+
+	err = service.StreamFile(ctx, httptest.NewRecorder(), req, "reports", "../../etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidFilePath)
+
+	sibling := filepath.Join(service.paths.DataDir, "reports-old")
+	require.NoError(t, os.MkdirAll(sibling, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sibling, "leak.csv"), []byte("secret"), 0644))
+	rec := httptest.NewRecorder()
+	err = service.StreamFile(ctx, rec, req, "reports", "../reports-old/leak.csv")
+	assert.ErrorIs(t, err, ErrInvalidFilePath, "a sibling sharing the directory's prefix is outside it")
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestWantsGzip(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		acceptEncoding string
+		rangeHeader    string
+		expected       bool
+	}{
+		{"no accept-encoding", "/f", "", "", false},
+		{"gzip accepted", "/f", "gzip, deflate, br", "", true},
+		{"gzip refused", "/f", "gzip;q=0, br", "", false},
+		{"opted out", "/f?compress=false", "gzip", "", false},
+		{"range request", "/f", "gzip", "bytes=0-99", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			assert.Equal(t, tt.expected, wantsGzip(req))
+		})
+	}
+}
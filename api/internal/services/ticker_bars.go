@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+	"isxcli/internal/dataprocessing/analytics"
+	"isxcli/internal/exporter"
+)
+
+// GetTickerBars returns the ticker's weekly or monthly OHLCV bars, oldest
+// first, whose period overlaps [from, to]. Zero dates leave the range open.
+// Bars come from the processor's aggregates; tickers processed before
+// aggregates existed are resampled from their trading history instead.
+// Identical concurrent requests share one read.
+func (ds *DataService) GetTickerBars(ctx context.Context, symbol string, interval analytics.Interval, from, to time.Time) ([]analytics.Bar, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+	symbol = strings.ToUpper(symbol)
+	paths := ds.paths.ForContext(ctx)
+
+	key := fmt.Sprintf("bars:%s:%s:%s:%s:%s", paths.ReportsDir, symbol, interval, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	// The shared read takes no request context, so a caller going away
+	// cannot fail the others waiting on it
+	value, err := ds.cache.Coalesce(key, func() (interface{}, error) {
+		return ds.tickerBars(paths, symbol, interval, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]analytics.Bar), nil
+}
+
+// tickerBars reads or resamples the bars of a ticker and keeps those in range
+func (ds *DataService) tickerBars(paths *config.Paths, symbol string, interval analytics.Interval, from, to time.Time) ([]analytics.Bar, error) {
+	// The processor writes aggregates in the canonical format
+	bars, err := analytics.ReadBarsCSV(paths.GetTickerBarsCSVPath(symbol, string(interval)), exporter.DefaultExportOptions())
+	if errors.Is(err, os.ErrNotExist) {
+		records, historyErr := dataprocessing.ReadTradeRecordsCSV(paths.GetTickerHistoryCSVPath(symbol))
+		if errors.Is(historyErr, os.ErrNotExist) {
+			return nil, ErrTickerNotFound
+		}
+		if historyErr != nil {
+			return nil, fmt.Errorf("failed to read ticker history: %w", historyErr)
+		}
+		bars, err = analytics.Resample(records, interval), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ticker bars: %w", err)
+	}
+
+	filtered := make([]analytics.Bar, 0, len(bars))
+	for _, bar := range bars {
+		if (!from.IsZero() && bar.Start.Before(interval.PeriodStart(from))) || (!to.IsZero() && bar.Start.After(to)) {
+			continue
+		}
+		filtered = append(filtered, bar)
+	}
+	return filtered, nil
+}
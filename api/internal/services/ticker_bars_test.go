@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/dataprocessing/analytics"
+)
+
+func TestDataService_GetTickerBars(t *testing.T) {
+	ctx := context.Background()
+	ds, _ := newTestHistoryService(t)
+	ds.paths.AggregatesReportsDir = t.TempDir()
+
+	// Without aggregates the bars are resampled from the trading history
+	bars, err := ds.GetTickerBars(ctx, "bbob", analytics.IntervalWeek, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, bars, 2, "the forward-filled Sunday does not start a bar")
+	assert.Equal(t, time.Date(2024, 12, 29, 0, 0, 0, 0, time.UTC), bars[0].Start)
+	assert.Equal(t, 1.25, bars[0].Close)
+	assert.Equal(t, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), bars[1].Start)
+	assert.Equal(t, 1.30, bars[1].Open)
+	assert.Equal(t, 1.32, bars[1].Close)
+	assert.Equal(t, int64(5000), bars[1].Volume)
+
+	bars, err = ds.GetTickerBars(ctx, "BBOB", analytics.IntervalWeek, time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, bars, 1, "bars overlapping from are kept")
+	assert.Equal(t, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), bars[0].Start)
+
+	// Aggregates written by the processor take precedence
+	aggregate := "Symbol,PeriodStart,LastTradeDate,OpenPrice,HighPrice,LowPrice,ClosePrice,Volume,Value,NumTrades,TradingDays\n" +
+		"BBOB,2025-01-01,2025-01-07,1.250,1.400,1.200,1.320,6000,7800.00,12,3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(ds.paths.AggregatesReportsDir, "BBOB_1mo.csv"), []byte(aggregate), 0o644))
+	bars, err = ds.GetTickerBars(ctx, "BBOB", analytics.IntervalMonth, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, bars, 1)
+	assert.Equal(t, 1.40, bars[0].High)
+	assert.Equal(t, 3, bars[0].TradingDays)
+
+	_, err = ds.GetTickerBars(ctx, "TASC", analytics.IntervalWeek, time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrTickerNotFound)
+	_, err = ds.GetTickerBars(ctx, "BBOB", analytics.IntervalWeek, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
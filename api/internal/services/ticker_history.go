@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/exporter"
+)
+
+// Ticker history page sizes
+const (
+	DefaultHistoryPageSize = 500
+	MaxHistoryPageSize     = 5000
+)
+
+// TickerHistoryQuery selects a page of one ticker's trading history. Zero
+// dates leave the range open; empty Fields returns every column.
+type TickerHistoryQuery struct {
+	Symbol   string
+	From     time.Time
+	To       time.Time
+	Fields   []string // e.g. close, volume or ClosePrice; Date is always included
+	Page     int      // 1-based
+	PageSize int
+	Currency currency.Code // amounts are converted to USD at each day's rate
+}
+
+// Validate checks the date range and pagination
+func (q TickerHistoryQuery) Validate() error {
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+	if q.Page < 1 {
+		return fmt.Errorf("%w: page must be at least 1", ErrInvalidInput)
+	}
+	if q.PageSize < 1 || q.PageSize > MaxHistoryPageSize {
+		return fmt.Errorf("%w: page_size must be between 1 and %d", ErrInvalidInput, MaxHistoryPageSize)
+	}
+	return nil
+}
+
+// TickerHistoryPage is one page of a ticker's trading history, oldest first
+type TickerHistoryPage struct {
+	Symbol     string        `json:"symbol"`
+	Currency   currency.Code `json:"currency"`
+	Columns    []string      `json:"columns"`
+	Rows       [][]string    `json:"-"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	Total      int           `json:"total"`
+	TotalPages int           `json:"total_pages"`
+}
+
+// Records returns the rows as objects keyed by column, with numbers and
+// booleans typed. Blank cells are null.
+func (p *TickerHistoryPage) Records() []map[string]interface{} {
+	records := make([]map[string]interface{}, len(p.Rows))
+	for i, row := range p.Rows {
+		record := make(map[string]interface{}, len(p.Columns))
+		for j, column := range p.Columns {
+			record[column] = p.typedValue(column, row[j])
+		}
+		records[i] = record
+	}
+	return records
+}
+
+// typedValue converts a CSV cell to its JSON value
+func (p *TickerHistoryPage) typedValue(column, value string) interface{} {
+	switch column {
+	case "Date", "CompanyName", "Symbol", "FillMethod":
+		return value
+	case "TradingStatus":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		return value
+	}
+	if value == "" {
+		return nil
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
+
+// tickerHistoryIndex is a parsed ticker history file sorted by date. It is
+// rebuilt when the file's size or modification time changes.
+type tickerHistoryIndex struct {
+	modTime time.Time
+	size    int64
+	header  []string
+	dates   []time.Time
+	rows    [][]string
+}
+
+// GetTickerHistory returns a page of the ticker's trading history CSV written
+// by the processor. Parsed files are kept in memory, so date ranges are found
+// by binary search instead of rescanning the file on every request.
+func (ds *DataService) GetTickerHistory(ctx context.Context, q TickerHistoryQuery) (*TickerHistoryPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	symbol := strings.ToUpper(q.Symbol)
+
+	index, err := ds.tickerHistoryIndex(ds.paths.ForContext(ctx).GetTickerHistoryCSVPath(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := selectHistoryColumns(index.header, q.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if !q.From.IsZero() {
+		start = sort.Search(len(index.dates), func(i int) bool { return !index.dates[i].Before(q.From) })
+	}
+	end := len(index.dates)
+	if !q.To.IsZero() {
+		end = sort.Search(len(index.dates), func(i int) bool { return index.dates[i].After(q.To) })
+	}
+	if end < start {
+		end = start
+	}
+
+	cur := q.Currency
+	if cur == "" {
+		cur = currency.IQD
+	}
+	convert, err := ds.rates.convertTo(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+	// The processor writes histories in the canonical format, and converted
+	// amounts keep it so every row reads the same
+	opts := exporter.DefaultExportOptions()
+
+	total := end - start
+	page := &TickerHistoryPage{
+		Symbol:     symbol,
+		Currency:   cur,
+		Columns:    make([]string, len(columns)),
+		Rows:       [][]string{},
+		Page:       q.Page,
+		PageSize:   q.PageSize,
+		Total:      total,
+		TotalPages: (total + q.PageSize - 1) / q.PageSize,
+	}
+	for i, c := range columns {
+		page.Columns[i] = index.header[c]
+	}
+
+	from := start + (q.Page-1)*q.PageSize
+	to := from + q.PageSize
+	if to > end {
+		to = end
+	}
+	for k, row := range index.rows[min(from, end):to] {
+		date := index.dates[min(from, end)+k]
+		selected := make([]string, len(columns))
+		for i, c := range columns {
+			if c >= len(row) {
+				continue
+			}
+			selected[i] = row[c]
+			if cur != currency.IQD {
+				selected[i] = opts.ConvertCell(page.Columns[i], row[c], func(v float64) float64 { return convert(v, date) })
+			}
+		}
+		page.Rows = append(page.Rows, selected)
+	}
+
+	return page, nil
+}
+
+// tickerHistoryIndex returns the cached index of a history file, parsing it
+// once, however many requests are waiting, when it is new or has changed
+func (ds *DataService) tickerHistoryIndex(path string) (*tickerHistoryIndex, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrTickerNotFound
+		}
+		return nil, fmt.Errorf("failed to stat ticker history: %w", err)
+	}
+
+	if cached, ok := ds.historyIndex.Load(path); ok {
+		index := cached.(*tickerHistoryIndex)
+		if index.size == info.Size() && index.modTime.Equal(info.ModTime()) {
+			return index, nil
+		}
+	}
+
+	// Stored histories use the canonical date layout whatever the export
+	// settings are
+	dateLayout := exporter.DefaultExportOptions().DateFormat
+	key := fmt.Sprintf("history:%s@%d:%d", path, info.ModTime().UnixNano(), info.Size())
+	value, err := ds.cache.Coalesce(key, func() (interface{}, error) {
+		index, err := readTickerHistory(path, dateLayout)
+		if err != nil {
+			return nil, err
+		}
+		index.modTime, index.size = info.ModTime(), info.Size()
+		ds.historyIndex.Store(path, index)
+		return index, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*tickerHistoryIndex), nil
+}
+
+// readTickerHistory parses a ticker history CSV, skipping rows without a date
+func readTickerHistory(path, dateLayout string) (*tickerHistoryIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ticker history: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ticker history header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	dateColumn := -1
+	for i, h := range header {
+		if h == "Date" {
+			dateColumn = i
+			break
+		}
+	}
+	if dateColumn < 0 {
+		return nil, fmt.Errorf("ticker history %s has no Date column", path)
+	}
+
+	index := &tickerHistoryIndex{header: header}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ticker history: %w", err)
+		}
+		if dateColumn >= len(row) {
+			continue
+		}
+		date, err := time.Parse(dateLayout, row[dateColumn])
+		if err != nil {
+			continue
+		}
+		index.dates = append(index.dates, date)
+		index.rows = append(index.rows, row)
+	}
+
+	sort.Stable(byHistoryDate{index})
+	return index, nil
+}
+
+// byHistoryDate sorts an index's rows and dates together
+type byHistoryDate struct{ *tickerHistoryIndex }
+
+func (b byHistoryDate) Len() int           { return len(b.dates) }
+func (b byHistoryDate) Less(i, j int) bool { return b.dates[i].Before(b.dates[j]) }
+func (b byHistoryDate) Swap(i, j int) {
+	b.dates[i], b.dates[j] = b.dates[j], b.dates[i]
+	b.rows[i], b.rows[j] = b.rows[j], b.rows[i]
+}
+
+// selectHistoryColumns resolves requested fields to column positions, Date
+// first. Fields match column names ignoring case, underscores and a trailing
+// "Price", so close, Close and ClosePrice all select ClosePrice.
+func selectHistoryColumns(header []string, fields []string) ([]int, error) {
+	byName := make(map[string]int, len(header))
+	for i, h := range header {
+		byName[historyFieldKey(h)] = i
+	}
+	dateColumn := byName[historyFieldKey("Date")]
+
+	if len(fields) == 0 {
+		columns := make([]int, len(header))
+		for i := range header {
+			columns[i] = i
+		}
+		return columns, nil
+	}
+
+	columns := []int{dateColumn}
+	seen := map[int]bool{dateColumn: true}
+	for _, field := range fields {
+		c, ok := byName[historyFieldKey(field)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidInput, field)
+		}
+		if !seen[c] {
+			seen[c] = true
+			columns = append(columns, c)
+		}
+	}
+	return columns, nil
+}
+
+// historyFieldKey normalizes a field or column name for matching
+func historyFieldKey(name string) string {
+	key := strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+	if trimmed := strings.TrimSuffix(key, "price"); trimmed != "" {
+		key = trimmed
+	}
+	return key
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/currency"
+)
+
+const testTickerHistory = `Date,CompanyName,Symbol,ClosePrice,Volume,TradingStatus
+2025-01-06,Bank of Baghdad,BBOB,1.300,2000,true
+2025-01-02,Bank of Baghdad,BBOB,1.250,1000,true
+2025-01-05,Bank of Baghdad,BBOB,1.250,,false
+2025-01-07,Bank of Baghdad,BBOB,1.320,3000,true
+`
+
+func newTestHistoryService(t *testing.T) (*DataService, string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "BBOB_trading_history.csv")
+	require.NoError(t, os.WriteFile(path, []byte(testTickerHistory), 0o644))
+
+	return &DataService{
+		config: config.Default(),
+		paths:  &config.Paths{TickerReportsDir: dir},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, path
+}
+
+func TestDataService_GetTickerHistory(t *testing.T) {
+	ctx := context.Background()
+	ds, path := newTestHistoryService(t)
+
+	page, err := ds.GetTickerHistory(ctx, TickerHistoryQuery{
+		Symbol:   "bbob",
+		From:     time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		Fields:   []string{"close", "volume", "trading_status"},
+		Page:     1,
+		PageSize: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", page.Symbol)
+	assert.Equal(t, []string{"Date", "ClosePrice", "Volume", "TradingStatus"}, page.Columns)
+	assert.Equal(t, [][]string{
+		{"2025-01-05", "1.250", "", "false"},
+		{"2025-01-06", "1.300", "2000", "true"},
+	}, page.Rows, "rows are sorted by date and filtered by the range")
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 2, page.TotalPages)
+	assert.Equal(t, map[string]interface{}{
+		"Date": "2025-01-05", "ClosePrice": 1.25, "Volume": nil, "TradingStatus": false,
+	}, page.Records()[0])
+
+	page, err = ds.GetTickerHistory(ctx, TickerHistoryQuery{Symbol: "BBOB", Page: 3, PageSize: 2})
+	require.NoError(t, err)
+	assert.Empty(t, page.Rows, "pages past the end are empty")
+	assert.Len(t, page.Columns, 6)
+
+	// A rewritten file is reparsed
+	require.NoError(t, os.WriteFile(path, []byte(testTickerHistory+"2025-01-08,Bank of Baghdad,BBOB,1.330,100,true\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	page, err = ds.GetTickerHistory(ctx, TickerHistoryQuery{Symbol: "BBOB", To: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC), Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+
+	// Amounts in USD at the CBI rate of 1300; volumes are unchanged
+	page, err = ds.GetTickerHistory(ctx, TickerHistoryQuery{Symbol: "BBOB", Fields: []string{"close", "volume"}, Page: 1, PageSize: 1, Currency: currency.USD})
+	require.NoError(t, err)
+	assert.Equal(t, currency.USD, page.Currency)
+	assert.Equal(t, [][]string{{"2025-01-02", "0.000962", "1000"}}, page.Rows)
+
+	_, err = ds.GetTickerHistory(ctx, TickerHistoryQuery{Symbol: "BBOB", Fields: []string{"dividend"}, Page: 1, PageSize: 10})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = ds.GetTickerHistory(ctx, TickerHistoryQuery{Symbol: "TASC", Page: 1, PageSize: 10})
+	assert.ErrorIs(t, err, ErrTickerNotFound)
+	_, err = ds.GetTickerHistory(ctx, TickerHistoryQuery{Symbol: "BBOB", Page: 1, PageSize: MaxHistoryPageSize + 1})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestDataService_GetTickerHistoryIgnoresExportFormat(t *testing.T) {
+	ds, _ := newTestHistoryService(t)
+	ds.config.Export.DateFormat = "02/01/2006"
+	ds.config.Export.ThousandsSeparator = "."
+
+	page, err := ds.GetTickerHistory(context.Background(), TickerHistoryQuery{
+		Symbol:   "BBOB",
+		From:     time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		Fields:   []string{"close", "volume"},
+		Page:     1,
+		PageSize: 10,
+		Currency: currency.USD,
+	})
+	require.NoError(t, err, "stored histories are read in the canonical format")
+	assert.Equal(t, [][]string{
+		{"2025-01-06", "0.001000", "2000"},
+		{"2025-01-07", "0.001015", "3000"},
+	}, page.Rows)
+	assert.Equal(t, 1.0e-3, page.Records()[0]["ClosePrice"])
+}
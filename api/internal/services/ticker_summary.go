@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"isxcli/internal/dataprocessing"
+)
+
+// UnclassifiedSector groups tickers without a company profile
+const UnclassifiedSector = "Unclassified"
+
+// TickerSummary is the Summarizer's summary of a ticker joined with its
+// sector from the company profiles
+type TickerSummary struct {
+	dataprocessing.TickerSummary
+	Sector string `json:"sector,omitempty"`
+}
+
+// SectorSummary aggregates the ticker summaries of one sector. TotalValue
+// and TotalVolume are traded over the whole history; AverageVolume is the
+// sector's average daily volume, the sum of its tickers' averages.
+type SectorSummary struct {
+	Sector        string          `json:"sector"`
+	TickerCount   int             `json:"ticker_count"`
+	TradingDays   int             `json:"trading_days"` // Most trading days of any ticker
+	TotalVolume   int64           `json:"total_volume"`
+	TotalValue    float64         `json:"total_value"`
+	AverageVolume float64         `json:"average_volume"`
+	Tickers       []TickerSummary `json:"tickers"`
+}
+
+// GetTickerSummaries summarizes every ticker of the combined dataset with the
+// SSOT Summarizer, ordered by symbol. Summaries are cached until the
+// combined CSV changes.
+func (ds *DataService) GetTickerSummaries(ctx context.Context) ([]TickerSummary, error) {
+	paths := ds.paths.ForContext(ctx)
+
+	value, err := ds.cache.Load("ticker_summary", paths.GetCombinedDataCSVPath(), func(path string) (interface{}, error) {
+		records, err := dataprocessing.ReadTradeRecordsCSV(path)
+		if err != nil {
+			return nil, err
+		}
+		summarizer := dataprocessing.NewSummarizer(ds.logger, dataprocessing.ExtendedSummarizerConfig())
+		return summarizer.GenerateFromRecords(ctx, records)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoTickersFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize tickers: %w", err)
+	}
+
+	summaries := value.([]dataprocessing.TickerSummary)
+	if len(summaries) == 0 {
+		return nil, ErrNoTickersFound
+	}
+
+	sectors := ds.tickerSectors(ctx)
+	result := make([]TickerSummary, len(summaries))
+	for i, s := range summaries {
+		result[i] = TickerSummary{TickerSummary: s, Sector: sectors[strings.ToUpper(s.Ticker)]}
+	}
+	return result, nil
+}
+
+// tickerSectors maps symbols to the sector of their company profile. Missing
+// profiles only leave the sectors empty.
+func (ds *DataService) tickerSectors(ctx context.Context) map[string]string {
+	sectors := make(map[string]string)
+	value, err := ds.cache.Load("companies", ds.paths.ForContext(ctx).GetCompaniesCSVPath(), func(path string) (interface{}, error) {
+		return dataprocessing.ReadCompaniesCSV(path)
+	})
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			ds.logger.WarnContext(ctx, "Company sectors unavailable for ticker summaries",
+				slog.String("error", err.Error()))
+		}
+		return sectors
+	}
+	for _, p := range value.([]dataprocessing.CompanyProfile) {
+		sectors[strings.ToUpper(p.Symbol)] = p.Sector
+	}
+	return sectors
+}
+
+// GroupTickerSummariesBySector aggregates summaries per sector, ordered by
+// sector name with UnclassifiedSector last
+func GroupTickerSummariesBySector(summaries []TickerSummary) []SectorSummary {
+	bySector := make(map[string]*SectorSummary)
+	for _, s := range summaries {
+		name := strings.TrimSpace(s.Sector)
+		if name == "" {
+			name = UnclassifiedSector
+		}
+		group, ok := bySector[name]
+		if !ok {
+			group = &SectorSummary{Sector: name}
+			bySector[name] = group
+		}
+		group.TickerCount++
+		group.TotalVolume += s.TotalVolume
+		group.TotalValue += s.TotalValue
+		group.AverageVolume += s.AverageVolume
+		if s.TradingDays > group.TradingDays {
+			group.TradingDays = s.TradingDays
+		}
+		group.Tickers = append(group.Tickers, s)
+	}
+
+	result := make([]SectorSummary, 0, len(bySector))
+	for _, group := range bySector {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Sector == UnclassifiedSector) != (result[j].Sector == UnclassifiedSector) {
+			return result[j].Sector == UnclassifiedSector
+		}
+		return result[i].Sector < result[j].Sector
+	})
+	return result
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+)
+
+const testCombinedData = `Date,CompanyName,Symbol,OpenPrice,HighPrice,LowPrice,AveragePrice,PrevAveragePrice,ClosePrice,PrevClosePrice,Change,ChangePercent,NumTrades,Volume,Value,TradingStatus
+2025-01-05,Bank of Baghdad,BBOB,1.20,1.30,1.10,1.25,1.20,1.25,1.20,0.05,4.17,10,1000,1250,true
+2025-01-06,Bank of Baghdad,BBOB,1.25,1.40,1.20,1.30,1.25,1.30,1.25,0.05,4.00,12,3000,3900,true
+2025-01-07,Bank of Baghdad,BBOB,1.30,1.30,1.30,1.30,1.30,1.30,1.30,0,0,0,0,0,false
+2025-01-05,Asiacell,TASC,8.00,8.20,7.90,8.10,8.00,8.10,8.00,0.10,1.25,5,500,4050,true
+2025-01-05,Unknown Co,XXXX,2.00,2.00,2.00,2.00,2.00,2.00,2.00,0,0,1,100,200,true
+`
+
+func TestDataService_GetTickerSummaries(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	paths := &config.Paths{
+		CombinedDataCSV: filepath.Join(dir, "isx_combined_data.csv"),
+		CompaniesCSV:    filepath.Join(dir, "companies.csv"),
+	}
+	ds := &DataService{
+		config: config.Default(),
+		paths:  paths,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cache:  NewReportCache(0, 0),
+	}
+
+	_, err := ds.GetTickerSummaries(ctx)
+	assert.ErrorIs(t, err, ErrNoTickersFound)
+
+	require.NoError(t, os.WriteFile(paths.CombinedDataCSV, []byte(testCombinedData), 0o644))
+	require.NoError(t, os.WriteFile(paths.CompaniesCSV, []byte("Symbol,Name,Sector\nBBOB,Bank of Baghdad,Banking\nTASC,Asiacell,Telecom\n"), 0o644))
+
+	summaries, err := ds.GetTickerSummaries(ctx)
+	require.NoError(t, err)
+	require.Len(t, summaries, 3)
+
+	bbob := summaries[0]
+	assert.Equal(t, "BBOB", bbob.Ticker)
+	assert.Equal(t, "Banking", bbob.Sector)
+	assert.Equal(t, 1.30, bbob.LastPrice)
+	assert.Equal(t, "2025-01-06", bbob.LastDate)
+	assert.Equal(t, 2, bbob.TradingDays)
+	assert.Equal(t, 1.40, bbob.High52Week)
+	assert.Equal(t, 1.10, bbob.Low52Week)
+	assert.Equal(t, 2000.0, bbob.AverageVolume)
+	assert.Equal(t, 5150.0, bbob.TotalValue)
+	assert.Empty(t, summaries[2].Sector, "tickers without a profile have no sector")
+
+	_, err = ds.GetTickerSummaries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), ds.CacheStats().Hits, "summaries and sectors are served from the cache")
+
+	sectors := GroupTickerSummariesBySector(summaries)
+	require.Len(t, sectors, 3)
+	assert.Equal(t, []string{"Banking", "Telecom", UnclassifiedSector},
+		[]string{sectors[0].Sector, sectors[1].Sector, sectors[2].Sector})
+	assert.Equal(t, 1, sectors[0].TickerCount)
+	assert.Equal(t, int64(4000), sectors[0].TotalVolume)
+	assert.Equal(t, 2, sectors[0].TradingDays)
+	assert.Equal(t, "XXXX", sectors[2].Tickers[0].Ticker)
+}
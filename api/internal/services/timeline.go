@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/liquidity"
+)
+
+// Timeline event types
+const (
+	TimelineCorporateAction = "corporate_action"
+	TimelineSuspension      = "suspension"
+	TimelineAnomaly         = "anomaly"
+	TimelineRegimeChange    = "regime_change"
+	TimelineSymbolChange    = "symbol_change"
+)
+
+// ValidTimelineTypes lists the event types accepted by TimelineQuery.Types
+var ValidTimelineTypes = []string{
+	TimelineCorporateAction, TimelineSuspension, TimelineAnomaly, TimelineRegimeChange, TimelineSymbolChange,
+}
+
+// MinSuspensionSessions is the number of consecutive sessions without trades
+// reported as a suspension. Shorter gaps are ordinary illiquidity.
+const MinSuspensionSessions = 5
+
+// regimeWindow is the liquidity window whose regime changes are reported
+const regimeWindow = liquidity.Window60
+
+// TimelineEvent is one dated entry of a ticker's event feed. EndDate is set
+// for events spanning several sessions and is nil while they are ongoing.
+type TimelineEvent struct {
+	Date    time.Time              `json:"date"`
+	EndDate *time.Time             `json:"endDate,omitempty"`
+	Type    string                 `json:"type"`
+	Title   string                 `json:"title"`
+	Source  string                 `json:"source"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// TickerTimeline is the chronological event feed of one ticker
+type TickerTimeline struct {
+	Symbol string          `json:"symbol"`
+	Events []TimelineEvent `json:"events"`
+	// Unavailable names sources that failed; their events are missing
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// TimelineQuery selects the events returned for one ticker. Zero values
+// disable the corresponding filter.
+type TimelineQuery struct {
+	Symbol string
+	Types  []string
+	From   time.Time
+	To     time.Time
+	Limit  int // most recent events; zero means all
+}
+
+// Validate checks event types and the date range
+func (q TimelineQuery) Validate() error {
+	for _, t := range q.Types {
+		if !isValidTimelineType(t) {
+			return fmt.Errorf("%w: unknown event type %q (use: %s)", ErrInvalidInput, t, strings.Join(ValidTimelineTypes, ", "))
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("%w: limit must not be negative", ErrInvalidInput)
+	}
+	return nil
+}
+
+// TimelineSource contributes the events of one kind of data for a ticker
+type TimelineSource func(ctx context.Context, paths *config.Paths, symbol string) ([]TimelineEvent, error)
+
+// liquidityHistorySource provides the persisted per-window liquidity metrics
+type liquidityHistorySource interface {
+	GetHistory(ctx context.Context, q LiquidityHistoryQuery) (*LiquidityHistory, error)
+}
+
+// TimelineService merges ticker events from several sources into one feed:
+// curated reference events (corporate actions, symbol changes, anomalies),
+// suspensions found in the trading history and liquidity regime changes.
+// Further sources can be added with AddSource.
+type TimelineService struct {
+	paths   *config.Paths
+	logger  *slog.Logger
+	mu      sync.RWMutex
+	sources map[string]TimelineSource
+}
+
+// NewTimelineService creates a timeline service with the built-in sources
+func NewTimelineService(paths *config.Paths, liquidity *LiquidityService, logger *slog.Logger) *TimelineService {
+	s := &TimelineService{
+		paths:  paths,
+		logger: logger,
+		sources: map[string]TimelineSource{
+			"reference": referenceEvents,
+			"trading":   suspensionEvents,
+		},
+	}
+	// Avoid a typed nil interface when liquidity data is not wired
+	if liquidity != nil {
+		s.sources["liquidity"] = regimeChangeEvents(liquidity)
+	}
+	return s
+}
+
+// AddSource registers or replaces a named event source
+func (s *TimelineService) AddSource(name string, source TimelineSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[name] = source
+}
+
+// Get returns the ticker's events matching the query, newest first. A
+// failing source is logged and listed in Unavailable rather than failing
+// the whole feed.
+func (s *TimelineService) Get(ctx context.Context, q TimelineQuery) (*TickerTimeline, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(q.Symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+	paths := s.paths.ForContext(ctx)
+
+	s.mu.RLock()
+	names := make([]string, 0, len(s.sources))
+	sources := make(map[string]TimelineSource, len(s.sources))
+	for name, source := range s.sources {
+		names = append(names, name)
+		sources[name] = source
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	timeline := &TickerTimeline{Symbol: symbol, Events: []TimelineEvent{}}
+	for _, name := range names {
+		events, err := sources[name](ctx, paths, symbol)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Timeline source failed",
+				slog.String("source", name),
+				slog.String("symbol", symbol),
+				slog.String("error", err.Error()))
+			timeline.Unavailable = append(timeline.Unavailable, name)
+			continue
+		}
+		for _, e := range events {
+			if e.Source == "" {
+				e.Source = name
+			}
+			if q.matches(e) {
+				timeline.Events = append(timeline.Events, e)
+			}
+		}
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].Date.After(timeline.Events[j].Date)
+	})
+	if q.Limit > 0 && len(timeline.Events) > q.Limit {
+		timeline.Events = timeline.Events[:q.Limit]
+	}
+	return timeline, nil
+}
+
+// matches reports whether an event passes the type and date filters. Events
+// spanning several sessions match when any part of them is in range.
+func (q TimelineQuery) matches(e TimelineEvent) bool {
+	if len(q.Types) > 0 {
+		found := false
+		for _, t := range q.Types {
+			if t == e.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	end := e.Date
+	if e.EndDate != nil {
+		end = *e.EndDate
+	} else if e.Type == TimelineSuspension {
+		end = time.Now()
+	}
+	if !q.From.IsZero() && end.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && e.Date.After(q.To) {
+		return false
+	}
+	return true
+}
+
+// referenceEvents reads the curated ticker events file. Columns: Date,
+// Symbol, Type, Title and optionally RelatedSymbol and Details. A symbol
+// change is listed under both the old and the new symbol.
+func referenceEvents(ctx context.Context, paths *config.Paths, symbol string) ([]TimelineEvent, error) {
+	file, err := os.Open(paths.GetTickerEventsCSVPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open ticker events: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read ticker events: %w", err)
+	}
+	col := csvColumns(header)
+
+	var events []TimelineEvent
+	for line := 2; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read ticker events: %w", err)
+		}
+
+		related := strings.ToUpper(col.get(rec, "relatedsymbol"))
+		if !strings.EqualFold(col.get(rec, "symbol"), symbol) && related != symbol {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", col.get(rec, "date"))
+		if err != nil {
+			return nil, fmt.Errorf("ticker events line %d: invalid date %q", line, col.get(rec, "date"))
+		}
+		eventType := strings.ToLower(col.get(rec, "type"))
+		if !isValidTimelineType(eventType) {
+			eventType = TimelineCorporateAction
+		}
+
+		event := TimelineEvent{
+			Date:   date,
+			Type:   eventType,
+			Title:  col.get(rec, "title"),
+			Source: "reference",
+		}
+		details := map[string]interface{}{}
+		if related != "" {
+			details["relatedSymbol"] = related
+		}
+		if d := col.get(rec, "details"); d != "" {
+			details["note"] = d
+		}
+		if len(details) > 0 {
+			event.Details = details
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// suspensionEvents reports runs of at least MinSuspensionSessions sessions
+// without trades in the ticker's forward-filled trading history
+func suspensionEvents(ctx context.Context, paths *config.Paths, symbol string) ([]TimelineEvent, error) {
+	file, err := os.Open(paths.GetTickerHistoryCSVPath(symbol))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open trading history: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read trading history: %w", err)
+	}
+	col := csvColumns(header)
+
+	var events []TimelineEvent
+	var runStart, lastTraded time.Time
+	var lastClose string
+	sessions := 0
+	flush := func(end *time.Time) {
+		if sessions >= MinSuspensionSessions {
+			event := TimelineEvent{
+				Date:    runStart,
+				EndDate: end,
+				Type:    TimelineSuspension,
+				Title:   fmt.Sprintf("No trading for %d sessions", sessions),
+				Source:  "trading",
+				Details: map[string]interface{}{"sessions": sessions, "ongoing": end == nil},
+			}
+			if !lastTraded.IsZero() {
+				event.Details["lastTradedDate"] = lastTraded.Format("2006-01-02")
+				event.Details["lastClose"] = lastClose
+			}
+			events = append(events, event)
+		}
+		sessions = 0
+	}
+
+	var prevDate time.Time
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read trading history: %w", err)
+		}
+		date, err := time.Parse("2006-01-02", col.get(rec, "date"))
+		if err != nil {
+			continue
+		}
+
+		if strings.EqualFold(col.get(rec, "tradingstatus"), "false") {
+			if sessions == 0 {
+				runStart = date
+			}
+			sessions++
+		} else {
+			if sessions > 0 {
+				end := prevDate
+				flush(&end)
+			}
+			lastTraded = date
+			lastClose = col.get(rec, "closeprice")
+		}
+		prevDate = date
+	}
+	flush(nil)
+	return events, nil
+}
+
+// regimeChangeEvents reports when the ticker's liquidity regime in the
+// regime window changed between consecutive calculation runs
+func regimeChangeEvents(source liquidityHistorySource) TimelineSource {
+	return func(ctx context.Context, paths *config.Paths, symbol string) ([]TimelineEvent, error) {
+		history, err := source.GetHistory(ctx, LiquidityHistoryQuery{Symbol: symbol, Window: regimeWindow})
+		if err != nil {
+			return nil, err
+		}
+
+		var events []TimelineEvent
+		for _, w := range history.Windows {
+			previous := ""
+			for _, p := range w.Points {
+				regime := regimeFor(p.TradingDays, p.Continuity)
+				if previous != "" && regime != previous {
+					events = append(events, TimelineEvent{
+						Date:   p.Date,
+						Type:   TimelineRegimeChange,
+						Title:  fmt.Sprintf("Liquidity regime changed from %s to %s", previous, regime),
+						Source: "liquidity",
+						Details: map[string]interface{}{
+							"from":        previous,
+							"to":          regime,
+							"window":      w.Window,
+							"continuity":  p.Continuity,
+							"tradingDays": p.TradingDays,
+						},
+					})
+				}
+				previous = regime
+			}
+		}
+		return events, nil
+	}
+}
+
+// csvColumnIndex maps lower-cased CSV header names to column indices
+type csvColumnIndex map[string]int
+
+func csvColumns(header []string) csvColumnIndex {
+	col := make(csvColumnIndex, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	return col
+}
+
+// get returns the trimmed field for a column, or "" when it is absent
+func (c csvColumnIndex) get(rec []string, name string) string {
+	if i, ok := c[name]; ok && i < len(rec) {
+		return strings.TrimSpace(rec[i])
+	}
+	return ""
+}
+
+// isValidTimelineType checks an event type against ValidTimelineTypes
+func isValidTimelineType(t string) bool {
+	for _, v := range ValidTimelineTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/liquidity"
+)
+
+type stubHistorySource struct {
+	points []liquidity.HistoryPoint
+}
+
+func (s stubHistorySource) GetHistory(ctx context.Context, q LiquidityHistoryQuery) (*LiquidityHistory, error) {
+	if q.Symbol != "BBOB" {
+		return &LiquidityHistory{Symbol: q.Symbol}, nil
+	}
+	return &LiquidityHistory{Symbol: q.Symbol, Windows: []LiquidityWindowHistory{{Window: q.Window.String(), Points: s.points}}}, nil
+}
+
+func newTestTimelineService(t *testing.T) *TimelineService {
+	dir := t.TempDir()
+	paths := &config.Paths{
+		DataDir:          dir,
+		TickerReportsDir: filepath.Join(dir, "ticker"),
+		TickerEventsCSV:  filepath.Join(dir, "reference", "ticker_events.csv"),
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths.TickerEventsCSV), 0755))
+	require.NoError(t, os.WriteFile(paths.TickerEventsCSV, []byte(strings.Join([]string{
+		"Date,Symbol,Type,Title,RelatedSymbol,Details",
+		"2024-06-10,BBOB,corporate_action,25% bonus shares,,Approved at AGM",
+		"2024-09-01,BBOBX,symbol_change,Ticker renamed,BBOB,",
+		"2024-07-01,TASC,corporate_action,Cash dividend,,",
+	}, "\n")), 0644))
+
+	// Trades on the 1st, nothing for five sessions, trades again, then an
+	// ongoing run of six sessions without trades
+	require.NoError(t, os.MkdirAll(paths.TickerReportsDir, 0755))
+	history := []string{"Date,Symbol,ClosePrice,TradingStatus"}
+	days := []struct {
+		date   string
+		traded bool
+	}{
+		{"2024-05-01", true}, {"2024-05-02", false}, {"2024-05-05", false}, {"2024-05-06", false},
+		{"2024-05-07", false}, {"2024-05-08", false}, {"2024-05-09", true}, {"2024-05-12", false},
+		{"2024-05-13", true}, {"2024-05-14", false}, {"2024-05-15", false}, {"2024-05-16", false},
+		{"2024-05-19", false}, {"2024-05-20", false}, {"2024-05-21", false},
+	}
+	for _, d := range days {
+		status := "false"
+		if d.traded {
+			status = "true"
+		}
+		history = append(history, d.date+",BBOB,1.25,"+status)
+	}
+	require.NoError(t, os.WriteFile(paths.GetTickerHistoryCSVPath("BBOB"), []byte(strings.Join(history, "\n")), 0644))
+
+	service := NewTimelineService(paths, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.AddSource("liquidity", regimeChangeEvents(stubHistorySource{points: []liquidity.HistoryPoint{
+		{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), TradingDays: 40, Continuity: 0.8},
+		{Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), TradingDays: 38, Continuity: 0.7},
+		{Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), TradingDays: 12, Continuity: 0.3},
+	}}))
+	return service
+}
+
+func TestTimelineService_MergesSources(t *testing.T) {
+	service := newTestTimelineService(t)
+
+	timeline, err := service.Get(context.Background(), TimelineQuery{Symbol: "bbob"})
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", timeline.Symbol)
+	assert.Empty(t, timeline.Unavailable)
+
+	var types []string
+	for _, e := range timeline.Events {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		TimelineSymbolChange,    // 2024-09-01
+		TimelineCorporateAction, // 2024-06-10
+		TimelineSuspension,      // 2024-05-14, ongoing
+		TimelineSuspension,      // 2024-05-02 to 2024-05-08
+		TimelineRegimeChange,    // 2024-05-01
+	}, types, "newest first")
+
+	rename := timeline.Events[0]
+	assert.Equal(t, "reference", rename.Source)
+	assert.Equal(t, "BBOB", rename.Details["relatedSymbol"])
+
+	ongoing := timeline.Events[2]
+	assert.Nil(t, ongoing.EndDate)
+	assert.Equal(t, 6, ongoing.Details["sessions"])
+	assert.Equal(t, "2024-05-13", ongoing.Details["lastTradedDate"])
+
+	ended := timeline.Events[3]
+	require.NotNil(t, ended.EndDate)
+	assert.Equal(t, "2024-05-08", ended.EndDate.Format("2006-01-02"))
+	assert.Equal(t, 5, ended.Details["sessions"])
+
+	regime := timeline.Events[4]
+	assert.Equal(t, RegimeActive, regime.Details["from"])
+	assert.Equal(t, RegimeSporadic, regime.Details["to"])
+}
+
+func TestTimelineService_Filters(t *testing.T) {
+	service := newTestTimelineService(t)
+	ctx := context.Background()
+
+	timeline, err := service.Get(ctx, TimelineQuery{Symbol: "BBOB", Types: []string{TimelineSuspension}})
+	require.NoError(t, err)
+	assert.Len(t, timeline.Events, 2)
+
+	// The finished suspension overlaps the range through its end date
+	timeline, err = service.Get(ctx, TimelineQuery{
+		Symbol: "BBOB",
+		From:   time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Len(t, timeline.Events, 1)
+	assert.Equal(t, TimelineSuspension, timeline.Events[0].Type)
+
+	timeline, err = service.Get(ctx, TimelineQuery{Symbol: "BBOB", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, timeline.Events, 2)
+
+	_, err = service.Get(ctx, TimelineQuery{Symbol: "BBOB", Types: []string{"earnings"}})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestTimelineService_FailingSource(t *testing.T) {
+	service := newTestTimelineService(t)
+	service.AddSource("broken", func(ctx context.Context, paths *config.Paths, symbol string) ([]TimelineEvent, error) {
+		return nil, errors.New("boom")
+	})
+
+	timeline, err := service.Get(context.Background(), TimelineQuery{Symbol: "TASC"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"broken"}, timeline.Unavailable)
+	require.Len(t, timeline.Events, 1)
+	assert.Equal(t, "Cash dividend", timeline.Events[0].Title)
+}
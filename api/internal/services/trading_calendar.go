@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"isxcli/internal/config"
+	"isxcli/internal/files"
+)
+
+// TradingCalendarService resolves the trading calendar of each profile from
+// its holidays file. Calendars are cached until the file changes.
+type TradingCalendarService struct {
+	paths  *config.Paths
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	cached map[string]cachedCalendar
+}
+
+type cachedCalendar struct {
+	modTime  time.Time
+	calendar *files.HolidayCalendar
+}
+
+// NewTradingCalendarService creates a new trading calendar service
+func NewTradingCalendarService(paths *config.Paths, logger *slog.Logger) *TradingCalendarService {
+	return &TradingCalendarService{
+		paths:  paths,
+		logger: logger,
+		cached: make(map[string]cachedCalendar),
+	}
+}
+
+// Calendar returns the trading calendar of the request's profile. A missing
+// holidays file gives a calendar with weekends only.
+func (s *TradingCalendarService) Calendar(ctx context.Context) (*files.HolidayCalendar, error) {
+	path := s.paths.ForContext(ctx).HolidaysFile
+
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat holidays file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.cached[path]; ok && c.modTime.Equal(modTime) {
+		return c.calendar, nil
+	}
+
+	calendar, err := files.LoadHolidayCalendar(path)
+	if err != nil {
+		return nil, err
+	}
+	s.cached[path] = cachedCalendar{modTime: modTime, calendar: calendar}
+	s.logger.DebugContext(ctx, "Trading calendar loaded", slog.String("path", path))
+	return calendar, nil
+}
@@ -0,0 +1,571 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/liquidity"
+)
+
+// ErrWatchlistNotFound is returned for an unknown watchlist
+var ErrWatchlistNotFound = apierrors.Newf(apierrors.NotFound, "watchlist not found")
+
+// watchlistsFileName is the watchlist store kept in the profile's data directory
+const watchlistsFileName = "watchlists.json"
+
+// WatchlistAlertEventType is the WebSocket message type of a fired alert
+const WatchlistAlertEventType = "watchlist:alert"
+
+// MaxWatchlistTickers caps the number of tickers in one watchlist
+const MaxWatchlistTickers = 200
+
+// Alert rule types. Thresholds are a price in IQD for the price rules, a
+// multiple of the average daily volume for volume spikes and hybrid score
+// points lost since the previous liquidity run for liquidity drops.
+const (
+	AlertPriceAbove    = "price_above"
+	AlertPriceBelow    = "price_below"
+	AlertVolumeSpike   = "volume_spike"
+	AlertLiquidityDrop = "liquidity_drop"
+)
+
+// ValidAlertTypes lists the rule types accepted by AlertRule.Type
+var ValidAlertTypes = []string{AlertPriceAbove, AlertPriceBelow, AlertVolumeSpike, AlertLiquidityDrop}
+
+// AlertRule fires for a watchlist ticker when its latest data crosses the
+// threshold. An empty Symbol applies the rule to every ticker of the list.
+type AlertRule struct {
+	Type      string  `json:"type"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Threshold float64 `json:"threshold"`
+}
+
+// key identifies the rule for one ticker when recording fired alerts
+func (r AlertRule) key(symbol string) string {
+	return fmt.Sprintf("%s:%s:%g", r.Type, symbol, r.Threshold)
+}
+
+// Watchlist is a named set of tickers with alert rules evaluated after each
+// processing and liquidity run
+type Watchlist struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Tickers []string    `json:"tickers"`
+	Rules   []AlertRule `json:"rules"`
+	// LastAlerts maps a rule and ticker to the data date it last fired on, so
+	// a condition alerts once per trading day rather than on every run
+	LastAlerts map[string]string `json:"lastAlerts,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// WatchlistInput is the editable part of a watchlist
+type WatchlistInput struct {
+	Name    string      `json:"name"`
+	Tickers []string    `json:"tickers"`
+	Rules   []AlertRule `json:"rules"`
+}
+
+// Validate normalizes and checks the input
+func (in *WatchlistInput) Validate() error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	in.Tickers = normalizeUniverse(in.Tickers)
+	switch {
+	case len(in.Tickers) == 0:
+		return fmt.Errorf("%w: at least one ticker is required", ErrInvalidInput)
+	case len(in.Tickers) > MaxWatchlistTickers:
+		return fmt.Errorf("%w: a watchlist is limited to %d tickers", ErrInvalidInput, MaxWatchlistTickers)
+	}
+	tickers := make(map[string]bool, len(in.Tickers))
+	for _, t := range in.Tickers {
+		tickers[t] = true
+	}
+
+	if in.Rules == nil {
+		in.Rules = []AlertRule{}
+	}
+	for i := range in.Rules {
+		rule := &in.Rules[i]
+		rule.Type = strings.ToLower(strings.TrimSpace(rule.Type))
+		rule.Symbol = strings.ToUpper(strings.TrimSpace(rule.Symbol))
+		if !isValidAlertType(rule.Type) {
+			return fmt.Errorf("%w: rule %d: type must be one of %s", ErrInvalidInput, i+1, strings.Join(ValidAlertTypes, ", "))
+		}
+		if rule.Threshold <= 0 {
+			return fmt.Errorf("%w: rule %d: threshold must be positive", ErrInvalidInput, i+1)
+		}
+		if rule.Symbol != "" && !tickers[rule.Symbol] {
+			return fmt.Errorf("%w: rule %d: %s is not in the watchlist", ErrInvalidInput, i+1, rule.Symbol)
+		}
+	}
+	return nil
+}
+
+func isValidAlertType(t string) bool {
+	for _, v := range ValidAlertTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchlistAlert is one rule firing for one ticker
+type WatchlistAlert struct {
+	WatchlistID   string    `json:"watchlistId"`
+	WatchlistName string    `json:"watchlistName"`
+	Symbol        string    `json:"symbol"`
+	Rule          string    `json:"rule"`
+	Threshold     float64   `json:"threshold"`
+	Value         float64   `json:"value"`
+	Date          string    `json:"date"` // Data date the rule fired on
+	Message       string    `json:"message"`
+	TriggeredAt   time.Time `json:"triggeredAt"`
+}
+
+// tickerSummarySource provides the latest price and volume of every ticker
+type tickerSummarySource interface {
+	GetTickerSummaries(ctx context.Context) ([]TickerSummary, error)
+}
+
+// notifier sends notifications over the configured channels
+type notifier interface {
+	Notify(ctx context.Context, n Notification) []NotificationResult
+}
+
+// WatchlistService stores watchlists and evaluates their alert rules against
+// the ticker summaries and liquidity history. Fired alerts are pushed over the
+// WebSocket hub and the notification channels. Watchlists are stored as JSON
+// in the data directory of the request's profile.
+type WatchlistService struct {
+	paths     *config.Paths
+	tickers   tickerSummarySource
+	liquidity liquidityHistorySource
+	hub       UpdateBroadcaster
+	notifier  notifier
+	logger    *slog.Logger
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// NewWatchlistService creates a new watchlist service. The hub and notifier
+// are optional.
+func NewWatchlistService(paths *config.Paths, tickers *DataService, liquidity *LiquidityService, hub UpdateBroadcaster, notifications *NotificationService, logger *slog.Logger) *WatchlistService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &WatchlistService{
+		paths:  paths,
+		hub:    hub,
+		logger: logger,
+		now:    time.Now,
+	}
+	// Avoid typed nil interfaces for the optional dependencies
+	if tickers != nil {
+		s.tickers = tickers
+	}
+	if liquidity != nil {
+		s.liquidity = liquidity
+	}
+	if notifications != nil {
+		s.notifier = notifications
+	}
+	return s
+}
+
+// List returns all watchlists ordered by name
+func (s *WatchlistService) List(ctx context.Context) ([]Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]Watchlist, 0, len(store))
+	for _, w := range store {
+		lists = append(lists, *w)
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Name < lists[j].Name
+	})
+	return lists, nil
+}
+
+// Get returns one watchlist
+func (s *WatchlistService) Get(ctx context.Context, id string) (*Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w, ok := store[id]
+	if !ok {
+		return nil, ErrWatchlistNotFound
+	}
+	return w, nil
+}
+
+// Create adds a watchlist
+func (s *WatchlistService) Create(ctx context.Context, in WatchlistInput) (*Watchlist, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	w := &Watchlist{
+		ID:        uuid.New().String(),
+		Name:      in.Name,
+		Tickers:   in.Tickers,
+		Rules:     in.Rules,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	store[w.ID] = w
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Update replaces the name, tickers and rules of a watchlist. Alerts already
+// fired are remembered for the rules that remain.
+func (s *WatchlistService) Update(ctx context.Context, id string, in WatchlistInput) (*Watchlist, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w, ok := store[id]
+	if !ok {
+		return nil, ErrWatchlistNotFound
+	}
+
+	w.Name = in.Name
+	w.Tickers = in.Tickers
+	w.Rules = in.Rules
+	w.UpdatedAt = s.now().UTC()
+
+	kept := make(map[string]string)
+	for _, rule := range w.Rules {
+		for _, symbol := range ruleSymbols(w, rule) {
+			if date, ok := w.LastAlerts[rule.key(symbol)]; ok {
+				kept[rule.key(symbol)] = date
+			}
+		}
+	}
+	w.LastAlerts = kept
+
+	if err := s.save(ctx, store); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Delete removes a watchlist
+func (s *WatchlistService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := store[id]; !ok {
+		return ErrWatchlistNotFound
+	}
+	delete(store, id)
+	return s.save(ctx, store)
+}
+
+// Evaluate checks every rule of every watchlist of the request's profile
+// against the latest data and delivers the alerts that fired. A rule fires
+// once per ticker and data date, so reruns over the same data stay quiet.
+func (s *WatchlistService) Evaluate(ctx context.Context) ([]WatchlistAlert, error) {
+	s.mu.Lock()
+	store, err := s.load(ctx)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if len(store) == 0 {
+		s.mu.Unlock()
+		return nil, nil
+	}
+
+	quotes, err := s.loadQuotes(ctx)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	scores := make(map[string][]liquidity.HistoryPoint)
+
+	now := s.now().UTC()
+	var alerts []WatchlistAlert
+	for _, w := range store {
+		for _, rule := range w.Rules {
+			for _, symbol := range ruleSymbols(w, rule) {
+				alert, fired := s.check(ctx, rule, symbol, quotes, scores)
+				if !fired || w.LastAlerts[rule.key(symbol)] == alert.Date {
+					continue
+				}
+				if w.LastAlerts == nil {
+					w.LastAlerts = make(map[string]string)
+				}
+				w.LastAlerts[rule.key(symbol)] = alert.Date
+
+				alert.WatchlistID = w.ID
+				alert.WatchlistName = w.Name
+				alert.TriggeredAt = now
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	if len(alerts) > 0 {
+		if err := s.save(ctx, store); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].WatchlistName != alerts[j].WatchlistName {
+			return alerts[i].WatchlistName < alerts[j].WatchlistName
+		}
+		if alerts[i].WatchlistID != alerts[j].WatchlistID {
+			return alerts[i].WatchlistID < alerts[j].WatchlistID
+		}
+		if alerts[i].Symbol != alerts[j].Symbol {
+			return alerts[i].Symbol < alerts[j].Symbol
+		}
+		return alerts[i].Rule < alerts[j].Rule
+	})
+	s.deliver(ctx, alerts)
+
+	s.logger.InfoContext(ctx, "Watchlists evaluated",
+		slog.Int("watchlists", len(store)),
+		slog.Int("alerts", len(alerts)))
+	return alerts, nil
+}
+
+// ruleSymbols returns the tickers a rule applies to
+func ruleSymbols(w *Watchlist, rule AlertRule) []string {
+	if rule.Symbol != "" {
+		return []string{rule.Symbol}
+	}
+	return w.Tickers
+}
+
+// check evaluates one rule for one ticker. Liquidity histories are loaded
+// once per evaluation and kept in scores.
+func (s *WatchlistService) check(ctx context.Context, rule AlertRule, symbol string, quotes map[string]TickerSummary, scores map[string][]liquidity.HistoryPoint) (WatchlistAlert, bool) {
+	alert := WatchlistAlert{Symbol: symbol, Rule: rule.Type, Threshold: rule.Threshold}
+
+	if rule.Type == AlertLiquidityDrop {
+		points, ok := scores[symbol]
+		if !ok {
+			points = s.loadScores(ctx, symbol)
+			scores[symbol] = points
+		}
+		if len(points) < 2 {
+			return alert, false
+		}
+		previous, latest := points[len(points)-2], points[len(points)-1]
+		alert.Value = previous.HybridScore - latest.HybridScore
+		alert.Date = latest.Date.Format("2006-01-02")
+		alert.Message = fmt.Sprintf("%s liquidity score fell %.1f points to %.1f (%s window)",
+			symbol, alert.Value, latest.HybridScore, latest.Window)
+		return alert, alert.Value >= rule.Threshold
+	}
+
+	quote, ok := quotes[symbol]
+	if !ok || quote.LastPrice <= 0 {
+		return alert, false
+	}
+	alert.Date = quote.LastDate
+
+	switch rule.Type {
+	case AlertPriceAbove:
+		alert.Value = quote.LastPrice
+		alert.Message = fmt.Sprintf("%s closed at %.3f, at or above %.3f", symbol, quote.LastPrice, rule.Threshold)
+		return alert, quote.LastPrice >= rule.Threshold
+	case AlertPriceBelow:
+		alert.Value = quote.LastPrice
+		alert.Message = fmt.Sprintf("%s closed at %.3f, at or below %.3f", symbol, quote.LastPrice, rule.Threshold)
+		return alert, quote.LastPrice <= rule.Threshold
+	case AlertVolumeSpike:
+		if quote.AverageVolume <= 0 {
+			return alert, false
+		}
+		alert.Value = float64(quote.DailyVolume) / quote.AverageVolume
+		alert.Message = fmt.Sprintf("%s traded %d shares, %.1fx its average daily volume", symbol, quote.DailyVolume, alert.Value)
+		return alert, alert.Value >= rule.Threshold
+	}
+	return alert, false
+}
+
+// loadQuotes returns the ticker summaries by symbol. Without processed data
+// there are no quotes, and only liquidity rules can fire.
+func (s *WatchlistService) loadQuotes(ctx context.Context) (map[string]TickerSummary, error) {
+	quotes := make(map[string]TickerSummary)
+	if s.tickers == nil {
+		return quotes, nil
+	}
+
+	summaries, err := s.tickers.GetTickerSummaries(ctx)
+	if errors.Is(err, ErrNoTickersFound) {
+		return quotes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		quotes[strings.ToUpper(summary.Ticker)] = summary
+	}
+	return quotes, nil
+}
+
+// loadScores returns the ticker's 60-day liquidity history, oldest first.
+// Missing history only keeps liquidity rules from firing.
+func (s *WatchlistService) loadScores(ctx context.Context, symbol string) []liquidity.HistoryPoint {
+	if s.liquidity == nil {
+		return nil
+	}
+	history, err := s.liquidity.GetHistory(ctx, LiquidityHistoryQuery{Symbol: symbol, Window: liquidity.Window60})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Liquidity history unavailable for watchlist alerts",
+			slog.String("symbol", symbol),
+			slog.String("error", err.Error()))
+		return nil
+	}
+	if len(history.Windows) == 0 {
+		return nil
+	}
+	return history.Windows[0].Points
+}
+
+// deliver pushes alerts to WebSocket clients and sends one notification per
+// watchlist
+func (s *WatchlistService) deliver(ctx context.Context, alerts []WatchlistAlert) {
+	if len(alerts) == 0 {
+		return
+	}
+
+	if s.hub != nil {
+		for _, alert := range alerts {
+			s.hub.BroadcastUpdate(WatchlistAlertEventType, alert.Rule, "triggered", alert)
+		}
+	}
+
+	if s.notifier == nil {
+		return
+	}
+	for start := 0; start < len(alerts); {
+		end := start
+		var lines []string
+		for end < len(alerts) && alerts[end].WatchlistID == alerts[start].WatchlistID {
+			lines = append(lines, "- "+alerts[end].Message)
+			end++
+		}
+		s.notifier.Notify(ctx, Notification{
+			Event:   NotificationWatchlistAlert,
+			Subject: fmt.Sprintf("ISX Pulse: %d alert(s) on watchlist %s", end-start, alerts[start].WatchlistName),
+			Text:    strings.Join(lines, "\n"),
+		})
+		start = end
+	}
+}
+
+func (s *WatchlistService) storePath(ctx context.Context) string {
+	return filepath.Join(s.paths.ForContext(ctx).DataDir, watchlistsFileName)
+}
+
+// load reads the watchlist store; callers hold s.mu
+func (s *WatchlistService) load(ctx context.Context) (map[string]*Watchlist, error) {
+	store := make(map[string]*Watchlist)
+
+	data, err := os.ReadFile(s.storePath(ctx))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read watchlists: %w", err)
+	}
+
+	var lists []*Watchlist
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return nil, fmt.Errorf("failed to parse watchlists: %w", err)
+	}
+	for _, w := range lists {
+		store[w.ID] = w
+	}
+	return store, nil
+}
+
+// save writes the watchlist store through a temp file; callers hold s.mu
+func (s *WatchlistService) save(ctx context.Context, store map[string]*Watchlist) error {
+	path := s.storePath(ctx)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create watchlist directory: %w", err)
+	}
+
+	lists := make([]*Watchlist, 0, len(store))
+	for _, w := range store {
+		lists = append(lists, w)
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(lists, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watchlists: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watchlists: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace watchlists: %w", err)
+	}
+	return nil
+}
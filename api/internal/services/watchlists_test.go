@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/config"
+	"isxcli/internal/dataprocessing"
+	"isxcli/internal/liquidity"
+)
+
+type stubTickerSummaries struct {
+	summaries []TickerSummary
+	err       error
+}
+
+func (s *stubTickerSummaries) GetTickerSummaries(ctx context.Context) ([]TickerSummary, error) {
+	return s.summaries, s.err
+}
+
+func newTestWatchlistService(t *testing.T, tickers tickerSummarySource, history liquidityHistorySource) (*WatchlistService, *fakeUpdateHub, *recordingSender) {
+	hub := &fakeUpdateHub{}
+	sender := &recordingSender{name: "fake"}
+	notifications := newTestNotificationService(config.NotificationsConfig{WatchlistAlerts: true}, sender)
+
+	service := NewWatchlistService(&config.Paths{DataDir: t.TempDir()}, nil, nil, hub, notifications, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.tickers = tickers
+	service.liquidity = history
+	return service, hub, sender
+}
+
+func TestWatchlistInput_Validate(t *testing.T) {
+	in := WatchlistInput{
+		Name:    "  Banks ",
+		Tickers: []string{"bbob", " BBOB", "tasc"},
+		Rules:   []AlertRule{{Type: " Price_Above ", Symbol: "bbob", Threshold: 1.5}},
+	}
+	require.NoError(t, in.Validate())
+	assert.Equal(t, "Banks", in.Name)
+	assert.Equal(t, []string{"BBOB", "TASC"}, in.Tickers)
+	assert.Equal(t, AlertRule{Type: AlertPriceAbove, Symbol: "BBOB", Threshold: 1.5}, in.Rules[0])
+
+	invalid := []WatchlistInput{
+		{Tickers: []string{"BBOB"}},
+		{Name: "Empty"},
+		{Name: "Type", Tickers: []string{"BBOB"}, Rules: []AlertRule{{Type: "price_cross", Threshold: 1}}},
+		{Name: "Threshold", Tickers: []string{"BBOB"}, Rules: []AlertRule{{Type: AlertVolumeSpike}}},
+		{Name: "Symbol", Tickers: []string{"BBOB"}, Rules: []AlertRule{{Type: AlertPriceBelow, Symbol: "TASC", Threshold: 1}}},
+	}
+	for _, in := range invalid {
+		assert.ErrorIs(t, in.Validate(), ErrInvalidInput, in.Name)
+	}
+}
+
+func TestWatchlistService_CRUD(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestWatchlistService(t, nil, nil)
+
+	_, err := service.Create(ctx, WatchlistInput{Name: "Banks"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	list, err := service.Create(ctx, WatchlistInput{
+		Name:    "Banks",
+		Tickers: []string{"BBOB", "BMNS"},
+		Rules:   []AlertRule{{Type: AlertPriceAbove, Threshold: 1}, {Type: AlertPriceBelow, Symbol: "BMNS", Threshold: 0.5}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, list.ID)
+
+	list.LastAlerts = map[string]string{
+		list.Rules[0].key("BBOB"): "2025-03-02",
+		list.Rules[1].key("BMNS"): "2025-03-02",
+	}
+	require.NoError(t, service.save(ctx, map[string]*Watchlist{list.ID: list}))
+
+	list, err = service.Update(ctx, list.ID, WatchlistInput{
+		Name:    "Banks",
+		Tickers: []string{"BBOB"},
+		Rules:   []AlertRule{{Type: AlertPriceAbove, Threshold: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"price_above:BBOB:1": "2025-03-02"}, list.LastAlerts,
+		"fired alerts are kept only for the remaining rules")
+
+	_, err = service.Create(ctx, WatchlistInput{Name: "Airlines", Tickers: []string{"IIAT"}})
+	require.NoError(t, err)
+	lists, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, lists, 2)
+	assert.Equal(t, "Airlines", lists[0].Name)
+
+	require.NoError(t, service.Delete(ctx, list.ID))
+	_, err = service.Get(ctx, list.ID)
+	assert.ErrorIs(t, err, ErrWatchlistNotFound)
+	_, err = service.Update(ctx, list.ID, WatchlistInput{Name: "Banks", Tickers: []string{"BBOB"}})
+	assert.ErrorIs(t, err, ErrWatchlistNotFound)
+	assert.ErrorIs(t, service.Delete(ctx, list.ID), ErrWatchlistNotFound)
+}
+
+func TestWatchlistService_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	tickers := &stubTickerSummaries{summaries: []TickerSummary{
+		{TickerSummary: dataprocessing.TickerSummary{Ticker: "BBOB", LastPrice: 1.25, LastDate: "2025-03-02", DailyVolume: 900000, AverageVolume: 200000}},
+		{TickerSummary: dataprocessing.TickerSummary{Ticker: "TASC", LastPrice: 0.4, LastDate: "2025-03-02", DailyVolume: 100000, AverageVolume: 200000}},
+	}}
+	history := stubHistorySource{points: []liquidity.HistoryPoint{
+		{Date: day.AddDate(0, 0, -1), Symbol: "BBOB", Window: liquidity.Window60, HybridScore: 70},
+		{Date: day, Symbol: "BBOB", Window: liquidity.Window60, HybridScore: 55},
+	}}
+	service, hub, sender := newTestWatchlistService(t, tickers, history)
+
+	_, err := service.Create(ctx, WatchlistInput{
+		Name:    "Banks",
+		Tickers: []string{"BBOB", "TASC"},
+		Rules: []AlertRule{
+			{Type: AlertPriceAbove, Threshold: 1},
+			{Type: AlertPriceBelow, Symbol: "TASC", Threshold: 0.5},
+			{Type: AlertVolumeSpike, Threshold: 3},
+			{Type: AlertLiquidityDrop, Threshold: 10},
+		},
+	})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, WatchlistInput{
+		Name:    "Quiet",
+		Tickers: []string{"TASC", "NONE"},
+		Rules:   []AlertRule{{Type: AlertPriceAbove, Threshold: 5}, {Type: AlertLiquidityDrop, Threshold: 1}},
+	})
+	require.NoError(t, err)
+
+	alerts, err := service.Evaluate(ctx)
+	require.NoError(t, err)
+	require.Len(t, alerts, 4)
+
+	byRule := make(map[string]WatchlistAlert)
+	for _, a := range alerts {
+		assert.Equal(t, "Banks", a.WatchlistName)
+		byRule[a.Rule+":"+a.Symbol] = a
+	}
+	assert.Equal(t, 1.25, byRule["price_above:BBOB"].Value)
+	assert.Equal(t, 0.4, byRule["price_below:TASC"].Value)
+	assert.InDelta(t, 4.5, byRule["volume_spike:BBOB"].Value, 1e-9)
+	assert.InDelta(t, 15, byRule["liquidity_drop:BBOB"].Value, 1e-9)
+	assert.Equal(t, "2025-03-02", byRule["liquidity_drop:BBOB"].Date)
+
+	updates := hub.all()
+	require.Len(t, updates, 4)
+	assert.Equal(t, WatchlistAlertEventType, updates[0].updateType)
+	assert.Equal(t, "triggered", updates[0].action)
+
+	require.Len(t, sender.sent, 1, "one notification per watchlist")
+	assert.Equal(t, NotificationWatchlistAlert, sender.sent[0].Event)
+	assert.Equal(t, "ISX Pulse: 4 alert(s) on watchlist Banks", sender.sent[0].Subject)
+
+	alerts, err = service.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, alerts, "rules fire once per data date")
+	assert.Len(t, sender.sent, 1)
+
+	tickers.summaries[0].LastDate = "2025-03-03"
+	alerts, err = service.Evaluate(ctx)
+	require.NoError(t, err)
+	require.Len(t, alerts, 2, "price and volume rules fire again on new data")
+}
+
+func TestWatchlistService_EvaluateWithoutData(t *testing.T) {
+	ctx := context.Background()
+	service, hub, sender := newTestWatchlistService(t, &stubTickerSummaries{err: ErrNoTickersFound}, nil)
+
+	alerts, err := service.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, alerts, "no watchlists")
+
+	_, err = service.Create(ctx, WatchlistInput{Name: "Banks", Tickers: []string{"BBOB"}, Rules: []AlertRule{{Type: AlertPriceAbove, Threshold: 1}}})
+	require.NoError(t, err)
+	alerts, err = service.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+	assert.Empty(t, hub.all())
+	assert.Empty(t, sender.sent)
+}
@@ -0,0 +1,296 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Counter names. Step and feature counters append the step ID or route.
+const (
+	CounterOperationsCompleted = "operations_completed"
+	CounterOperationsFailed    = "operations_failed"
+	CounterOperationsCancelled = "operations_cancelled"
+	CounterFilesProcessed      = "files_processed"
+	CounterStepPrefix          = "step:"
+	CounterFeaturePrefix       = "feature:"
+)
+
+// FileName is the counter store kept in the data directory
+const FileName = "telemetry.json"
+
+// DefaultFlushInterval is how often counters changed since the last save
+// are written while the collector runs
+const DefaultFlushInterval = time.Minute
+
+// Snapshot is the collected data as shown to the user
+type Snapshot struct {
+	// Enabled reports whether pending counters are sent with license validation
+	Enabled bool `json:"enabled"`
+	// Since is when counting started, or the last purge
+	Since          time.Time  `json:"since"`
+	LastReportedAt *time.Time `json:"last_reported_at,omitempty"`
+	// Pending are the counts not reported yet; Totals all counts since Since
+	Pending map[string]int64 `json:"pending"`
+	Totals  map[string]int64 `json:"totals"`
+}
+
+// state is the persisted form of the counters
+type state struct {
+	Since          time.Time        `json:"since"`
+	LastReportedAt *time.Time       `json:"last_reported_at,omitempty"`
+	Pending        map[string]int64 `json:"pending"`
+	Totals         map[string]int64 `json:"totals"`
+}
+
+// Collector aggregates usage counters and saves them to a JSON file. Counts
+// are kept in memory and written by Flush, which runs on an interval once
+// Start is called. A nil Collector counts nothing.
+type Collector struct {
+	path    string
+	enabled bool
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu    sync.Mutex
+	state state
+	dirty bool
+
+	loopMu sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewCollector creates a collector saving to path, loading the counters
+// saved there. enabled opts in to reporting; counting is always local.
+func NewCollector(path string, enabled bool, logger *slog.Logger) *Collector {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &Collector{
+		path:    path,
+		enabled: enabled,
+		logger:  logger,
+		now:     time.Now,
+	}
+	c.state = c.emptyState()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var saved state
+		if err := json.Unmarshal(data, &saved); err != nil {
+			logger.Warn("Ignoring unreadable telemetry counters",
+				slog.String("path", path),
+				slog.String("error", err.Error()))
+			break
+		}
+		if saved.Pending == nil {
+			saved.Pending = make(map[string]int64)
+		}
+		if saved.Totals == nil {
+			saved.Totals = make(map[string]int64)
+		}
+		c.state = saved
+	case !os.IsNotExist(err):
+		logger.Warn("Failed to read telemetry counters",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+	}
+	return c
+}
+
+// Enabled reports whether counters are reported with license validation
+func (c *Collector) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// Add increases a counter by n
+func (c *Collector) Add(name string, n int64) {
+	if c == nil || name == "" || n == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state.Pending[name] += n
+	c.state.Totals[name] += n
+	c.dirty = true
+}
+
+// Snapshot returns a copy of the collected counters
+func (c *Collector) Snapshot() Snapshot {
+	if c == nil {
+		return Snapshot{Pending: map[string]int64{}, Totals: map[string]int64{}}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Snapshot{
+		Enabled:        c.enabled,
+		Since:          c.state.Since,
+		LastReportedAt: c.state.LastReportedAt,
+		Pending:        copyCounters(c.state.Pending),
+		Totals:         copyCounters(c.state.Totals),
+	}
+}
+
+// Purge deletes every counter, in memory and on disk
+func (c *Collector) Purge() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = c.emptyState()
+	c.dirty = false
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove telemetry counters: %w", err)
+	}
+	return nil
+}
+
+// PendingUsage returns the counters to send with the next license
+// validation, or nil when reporting is off or nothing was counted
+func (c *Collector) PendingUsage() map[string]int64 {
+	if !c.Enabled() {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.state.Pending) == 0 {
+		return nil
+	}
+	return copyCounters(c.state.Pending)
+}
+
+// UsageReported removes the counts the license server accepted from the
+// pending counters. Counts added while the report was sent stay pending.
+func (c *Collector) UsageReported(sent map[string]int64) {
+	if c == nil || len(sent) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, n := range sent {
+		if left := c.state.Pending[name] - n; left > 0 {
+			c.state.Pending[name] = left
+		} else {
+			delete(c.state.Pending, name)
+		}
+	}
+	reportedAt := c.now()
+	c.state.LastReportedAt = &reportedAt
+	if err := c.save(); err != nil {
+		c.logger.Warn("Failed to save telemetry counters", slog.String("error", err.Error()))
+	}
+}
+
+// Flush saves counters changed since the last save
+func (c *Collector) Flush() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	return c.save()
+}
+
+// Start flushes the counters every interval until Stop
+func (c *Collector) Start(ctx context.Context, interval time.Duration) {
+	if c == nil {
+		return
+	}
+	c.loopMu.Lock()
+	defer c.loopMu.Unlock()
+	if c.cancel != nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	done := c.done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Flush(); err != nil {
+					c.logger.Warn("Failed to save telemetry counters", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends the flush loop and saves the counters
+func (c *Collector) Stop() error {
+	if c == nil {
+		return nil
+	}
+	c.loopMu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel = nil
+	c.done = nil
+	c.loopMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return c.Flush()
+}
+
+func (c *Collector) emptyState() state {
+	return state{
+		Since:   c.now().UTC(),
+		Pending: make(map[string]int64),
+		Totals:  make(map[string]int64),
+	}
+}
+
+// save writes the counters atomically; callers hold c.mu
+func (c *Collector) save() error {
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry counters: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write telemetry counters: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save telemetry counters: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+func copyCounters(counters map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(counters))
+	for name, n := range counters {
+		out[name] = n
+	}
+	return out
+}
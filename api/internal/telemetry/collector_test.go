@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector_CountsAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	c := NewCollector(path, false, nil)
+
+	c.Add(CounterOperationsCompleted, 1)
+	c.Add(CounterOperationsCompleted, 1)
+	c.Add(CounterStepPrefix+"processing", 2)
+	c.Add("", 1)
+	c.Add(CounterOperationsFailed, 0)
+
+	snap := c.Snapshot()
+	assert.False(t, snap.Enabled)
+	assert.Equal(t, map[string]int64{CounterOperationsCompleted: 2, "step:processing": 2}, snap.Totals)
+	assert.Equal(t, snap.Totals, snap.Pending)
+	assert.NoFileExists(t, path, "counters are saved by Flush")
+
+	require.NoError(t, c.Flush())
+	reloaded := NewCollector(path, false, nil)
+	assert.Equal(t, snap.Totals, reloaded.Snapshot().Totals)
+	assert.Equal(t, snap.Since, reloaded.Snapshot().Since)
+}
+
+func TestCollector_ReportingIsOptIn(t *testing.T) {
+	dir := t.TempDir()
+
+	off := NewCollector(filepath.Join(dir, "off.json"), false, nil)
+	off.Add(CounterFilesProcessed, 10)
+	assert.Nil(t, off.PendingUsage())
+
+	on := NewCollector(filepath.Join(dir, "on.json"), true, nil)
+	assert.Nil(t, on.PendingUsage(), "nothing counted yet")
+
+	on.Add(CounterFilesProcessed, 10)
+	on.Add(CounterOperationsCompleted, 1)
+	sent := on.PendingUsage()
+	assert.Equal(t, map[string]int64{CounterFilesProcessed: 10, CounterOperationsCompleted: 1}, sent)
+
+	// Counted while the report was on its way
+	on.Add(CounterFilesProcessed, 3)
+	on.UsageReported(sent)
+
+	snap := on.Snapshot()
+	assert.Equal(t, map[string]int64{CounterFilesProcessed: 3}, snap.Pending)
+	assert.Equal(t, int64(13), snap.Totals[CounterFilesProcessed])
+	require.NotNil(t, snap.LastReportedAt)
+	assert.FileExists(t, filepath.Join(dir, "on.json"))
+}
+
+func TestCollector_Purge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	c := NewCollector(path, true, nil)
+	c.Add(CounterOperationsCompleted, 1)
+	require.NoError(t, c.Flush())
+
+	require.NoError(t, c.Purge())
+	assert.NoFileExists(t, path)
+	snap := c.Snapshot()
+	assert.Empty(t, snap.Totals)
+	assert.Empty(t, snap.Pending)
+	assert.Nil(t, c.PendingUsage())
+	require.NoError(t, c.Purge(), "purging twice is fine")
+}
+
+func TestCollector_StartStopFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	c := NewCollector(path, false, nil)
+
+	c.Start(context.Background(), time.Hour)
+	c.Add(CounterOperationsCancelled, 1)
+	require.NoError(t, c.Stop())
+	assert.FileExists(t, path)
+}
+
+func TestCollector_IgnoresUnreadableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+
+	c := NewCollector(path, false, nil)
+	c.Add(CounterOperationsCompleted, 1)
+	assert.Equal(t, int64(1), c.Snapshot().Totals[CounterOperationsCompleted])
+}
+
+func TestCollector_Nil(t *testing.T) {
+	var c *Collector
+	c.Add(CounterOperationsCompleted, 1)
+	c.UsageReported(map[string]int64{CounterOperationsCompleted: 1})
+	assert.False(t, c.Enabled())
+	assert.Nil(t, c.PendingUsage())
+	assert.Empty(t, c.Snapshot().Totals)
+	assert.NoError(t, c.Flush())
+	assert.NoError(t, c.Purge())
+	assert.NoError(t, c.Stop())
+}
@@ -0,0 +1,19 @@
+// Package telemetry aggregates anonymous usage counters on the local machine.
+//
+// A Collector counts what the application is used for: operations run and
+// how they ended, steps completed, files produced and the API features
+// requested, identified by route pattern. Counters never hold license keys,
+// file paths, tickers or request data. They are kept in data/telemetry.json
+// and can be viewed and purged through /api/v1/telemetry.
+//
+// Reporting is opt-in. With telemetry.enabled set the counters gathered since
+// the last report are sent with the periodic remote license validation; the
+// Collector implements license.UsageReporter for that. Otherwise nothing
+// leaves the machine.
+//
+// Example usage:
+//
+//	collector := telemetry.NewCollector("data/telemetry.json", cfg.Telemetry.Enabled, logger)
+//	licenseManager.SetUsageReporter(collector)
+//	collector.Add(telemetry.CounterOperationsCompleted, 1)
+package telemetry
@@ -0,0 +1,180 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/dataprocessing/analytics"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// DataService is the market data behind isxpulse.v1.DataService
+type DataService interface {
+	GetTickerHistory(ctx context.Context, q services.TickerHistoryQuery) (*services.TickerHistoryPage, error)
+	GetTickerBars(ctx context.Context, symbol string, interval analytics.Interval, from, to time.Time) ([]analytics.Bar, error)
+	GetMarketDiff(ctx context.Context, from, to time.Time, cur currency.Code) (*services.MarketDiff, error)
+}
+
+type tickerHistoryRequest struct {
+	Symbol   string   `json:"symbol"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Fields   []string `json:"fields"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"page_size"`
+	Currency string   `json:"currency"`
+}
+
+type tickerHistoryRow struct {
+	Cells []string `json:"cells"`
+}
+
+// tickerHistoryPage adds the rows TickerHistoryPage leaves out of its JSON
+type tickerHistoryPage struct {
+	*services.TickerHistoryPage
+	Rows []tickerHistoryRow `json:"rows"`
+}
+
+func (s *Server) getTickerHistory(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in tickerHistoryRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+
+	q := services.TickerHistoryQuery{
+		Symbol:   strings.ToUpper(strings.TrimSpace(in.Symbol)),
+		Fields:   in.Fields,
+		Page:     1,
+		PageSize: services.DefaultHistoryPageSize,
+	}
+	if q.Symbol == "" {
+		return nil, apierrors.Newf(apierrors.Validation, "symbol is required")
+	}
+	if in.Page != 0 {
+		q.Page = in.Page
+	}
+	if in.PageSize != 0 {
+		q.PageSize = in.PageSize
+	}
+	var err error
+	if q.From, err = parseDate("from", in.From); err != nil {
+		return nil, err
+	}
+	if q.To, err = parseDate("to", in.To); err != nil {
+		return nil, err
+	}
+	if q.Currency, err = parseCurrency(in.Currency); err != nil {
+		return nil, err
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	page, err := s.opts.Data.GetTickerHistory(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]tickerHistoryRow, len(page.Rows))
+	for i, cells := range page.Rows {
+		rows[i] = tickerHistoryRow{Cells: cells}
+	}
+	return tickerHistoryPage{TickerHistoryPage: page, Rows: rows}, nil
+}
+
+type tickerBarsRequest struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+func (s *Server) getTickerBars(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in tickerBarsRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(in.Symbol))
+	if symbol == "" {
+		return nil, apierrors.Newf(apierrors.Validation, "symbol is required")
+	}
+	interval := analytics.IntervalWeek
+	if in.Interval != "" {
+		parsed, err := analytics.ParseInterval(in.Interval)
+		if err != nil {
+			return nil, apierrors.Newf(apierrors.Validation, "interval must be 1w or 1mo")
+		}
+		interval = parsed
+	}
+	from, err := parseDate("from", in.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate("to", in.To)
+	if err != nil {
+		return nil, err
+	}
+
+	bars, err := s.opts.Data.GetTickerBars(ctx, symbol, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"symbol":   symbol,
+		"interval": interval,
+		"bars":     bars,
+	}, nil
+}
+
+type marketDiffRequest struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Currency string `json:"currency"`
+}
+
+func (s *Server) getMarketDiff(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in marketDiffRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+
+	from, err := parseDate("from", in.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate("to", in.To)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := parseCurrency(in.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return s.opts.Data.GetMarketDiff(ctx, from, to, cur)
+}
+
+// parseDate reads an optional YYYY-MM-DD date
+func parseDate(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, apierrors.Newf(apierrors.Validation, "%s must be a date in YYYY-MM-DD format", field)
+	}
+	return date, nil
+}
+
+// parseCurrency reads an optional currency code; empty means IQD
+func parseCurrency(value string) (currency.Code, error) {
+	code, err := currency.ParseCode(value)
+	if err != nil {
+		return "", apierrors.Newf(apierrors.Validation, "currency must be IQD or USD")
+	}
+	return code, nil
+}
@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/middleware"
+	"isxcli/pkg/contracts/grpcapi"
+)
+
+type callerContextKey struct{}
+
+// anonymousCaller is the caller of a request without a token
+var anonymousCaller = middleware.User{Name: "anonymous", Role: middleware.RoleViewer}
+
+// operatorMethods change state and need at least the operator role, like
+// the POST operation routes of the REST API
+var operatorMethods = map[string]bool{
+	"/" + grpcapi.OperationsServiceName + "/StartOperation":  true,
+	"/" + grpcapi.OperationsServiceName + "/CancelOperation": true,
+}
+
+// callerFromContext returns the user making a call
+func callerFromContext(ctx context.Context) middleware.User {
+	if user, ok := ctx.Value(callerContextKey{}).(middleware.User); ok {
+		return user
+	}
+	return anonymousCaller
+}
+
+// authorize applies the REST API's checks to ISX Pulse calls: a valid
+// license, then the caller's API token and role. Health and reflection
+// calls are left open.
+func (s *Server) authorize(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, "/isxpulse.") {
+		return handler(ctx, req)
+	}
+
+	if s.opts.License != nil {
+		valid, err := s.opts.License.ValidateLicenseWithContext(ctx)
+		if err != nil || !valid {
+			return nil, status.Error(codes.PermissionDenied, "a valid license is required")
+		}
+	}
+
+	if s.opts.Users != nil {
+		user := anonymousCaller
+		if token := callToken(ctx); token != "" {
+			known, ok := s.opts.Users.Lookup(token)
+			if !ok {
+				s.logger.WarnContext(ctx, "Rejected unknown API token",
+					slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Unauthenticated, "invalid API token")
+			}
+			user = known
+		}
+		if operatorMethods[info.FullMethod] && !user.Role.Allows(middleware.RoleOperator) {
+			s.logger.WarnContext(ctx, "Denied call for insufficient role",
+				slog.String("user", user.Name),
+				slog.String("role", string(user.Role)),
+				slog.String("method", info.FullMethod))
+			return nil, status.Errorf(codes.PermissionDenied, "%s requires the operator role", info.FullMethod)
+		}
+		ctx = context.WithValue(ctx, callerContextKey{}, user)
+	}
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.logger.DebugContext(ctx, "gRPC call",
+		slog.String("method", info.FullMethod),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)))
+	return resp, err
+}
+
+// callToken reads the API token from "authorization: Bearer <token>" or
+// "x-api-key: <token>" metadata
+func callToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, v := range md.Get("authorization") {
+		if token, found := strings.CutPrefix(v, "Bearer "); found {
+			return strings.TrimSpace(token)
+		}
+	}
+	if keys := md.Get("x-api-key"); len(keys) > 0 {
+		return strings.TrimSpace(keys[0])
+	}
+	return ""
+}
+
+// recoverPanics turns a panicking handler into an Internal error
+func (s *Server) recoverPanics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "gRPC handler panicked",
+				slog.String("method", info.FullMethod),
+				slog.String("panic", fmt.Sprint(r)),
+				slog.String("stack", string(debug.Stack())))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// kindCodes maps error kinds to gRPC status codes
+var kindCodes = map[apierrors.Kind]codes.Code{
+	apierrors.NotFound:            codes.NotFound,
+	apierrors.Validation:          codes.InvalidArgument,
+	apierrors.Conflict:            codes.FailedPrecondition,
+	apierrors.Unauthorized:        codes.Unauthenticated,
+	apierrors.Forbidden:           codes.PermissionDenied,
+	apierrors.LicenseExpired:      codes.PermissionDenied,
+	apierrors.LicenseInvalid:      codes.PermissionDenied,
+	apierrors.RateLimited:         codes.ResourceExhausted,
+	apierrors.Timeout:             codes.DeadlineExceeded,
+	apierrors.UpstreamUnavailable: codes.Unavailable,
+	apierrors.Unavailable:         codes.Unavailable,
+}
+
+// statusError converts a handler error to a gRPC status. Errors without a
+// known kind are logged and reported as Internal without their details.
+func (s *Server) statusError(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch ctx.Err() {
+	case context.Canceled:
+		return status.Error(codes.Canceled, ctx.Err().Error())
+	case context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+	if code, ok := kindCodes[apierrors.KindOf(err)]; ok {
+		return status.Error(code, err.Error())
+	}
+	s.logger.ErrorContext(ctx, "gRPC call failed",
+		slog.String("method", method),
+		slog.String("error", err.Error()))
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/liquidity"
+	"isxcli/internal/services"
+)
+
+// maxSafeTradeImpact bounds the impact threshold of a safe-trade request, in
+// percent, as in the REST API
+const maxSafeTradeImpact = 20.0
+
+// LiquidityService is the liquidity data behind isxpulse.v1.LiquidityService
+type LiquidityService interface {
+	GetHistory(ctx context.Context, q services.LiquidityHistoryQuery) (*services.LiquidityHistory, error)
+	GetSafeTrade(ctx context.Context, q services.SafeTradeQuery) (*services.SafeTrade, error)
+}
+
+type liquidityHistoryRequest struct {
+	Symbol         string  `json:"symbol"`
+	Window         string  `json:"window"`
+	Limit          int     `json:"limit"`
+	TrendWindows   int     `json:"trend_windows"`
+	TrendThreshold float64 `json:"trend_threshold"`
+}
+
+func (s *Server) getLiquidityHistory(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in liquidityHistoryRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+
+	q := services.LiquidityHistoryQuery{
+		Symbol:         strings.ToUpper(strings.TrimSpace(in.Symbol)),
+		Limit:          in.Limit,
+		TrendWindows:   in.TrendWindows,
+		TrendThreshold: in.TrendThreshold,
+	}
+	switch {
+	case q.Symbol == "":
+		return nil, apierrors.Newf(apierrors.Validation, "symbol is required")
+	case q.Limit < 0:
+		return nil, apierrors.Newf(apierrors.Validation, "limit must not be negative")
+	case q.TrendWindows != 0 && q.TrendWindows < liquidity.MinTrendPoints:
+		return nil, apierrors.Newf(apierrors.Validation, "trend_windows must be at least %d", liquidity.MinTrendPoints)
+	case q.TrendThreshold < 0:
+		return nil, apierrors.Newf(apierrors.Validation, "trend_threshold must be positive")
+	}
+	if in.Window != "" {
+		window, err := liquidity.ParseWindow(in.Window)
+		if err != nil {
+			return nil, apierrors.Newf(apierrors.Validation, "window must be one of 20d, 60d or 120d")
+		}
+		q.Window = window
+	}
+
+	history, err := s.opts.Liquidity.GetHistory(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Windows) == 0 {
+		return nil, apierrors.Newf(apierrors.NotFound, "no liquidity history recorded for %s", q.Symbol)
+	}
+	return history, nil
+}
+
+type safeTradeRequest struct {
+	Symbol   string  `json:"symbol"`
+	Impact   float64 `json:"impact"`
+	Position float64 `json:"position"`
+	Window   string  `json:"window"`
+	Currency string  `json:"currency"`
+}
+
+func (s *Server) getSafeTrade(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in safeTradeRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+
+	q := services.SafeTradeQuery{
+		Symbol:        strings.ToUpper(strings.TrimSpace(in.Symbol)),
+		ImpactPercent: 1.0,
+		PositionValue: in.Position,
+	}
+	switch {
+	case q.Symbol == "":
+		return nil, apierrors.Newf(apierrors.Validation, "symbol is required")
+	case in.Impact < 0 || in.Impact > maxSafeTradeImpact:
+		return nil, apierrors.Newf(apierrors.Validation, "impact must be a percentage above 0 and at most 20")
+	case in.Position < 0:
+		return nil, apierrors.Newf(apierrors.Validation, "position must not be negative")
+	}
+	if in.Impact > 0 {
+		q.ImpactPercent = in.Impact
+	}
+	if in.Window != "" {
+		window, err := liquidity.ParseWindow(in.Window)
+		if err != nil {
+			return nil, apierrors.Newf(apierrors.Validation, "window must be one of 20d, 60d or 120d")
+		}
+		q.Window = window
+	}
+	var err error
+	if q.Currency, err = parseCurrency(in.Currency); err != nil {
+		return nil, err
+	}
+
+	return s.opts.Liquidity.GetSafeTrade(ctx, q)
+}
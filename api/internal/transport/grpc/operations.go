@@ -0,0 +1,249 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/operations"
+)
+
+// OperationService tracks the operations behind isxpulse.v1.OperationsService
+type OperationService interface {
+	GetOperationStatus(ctx context.Context, operationID string) (*operations.OperationState, error)
+	ListOperations(ctx context.Context) ([]*operations.OperationState, error)
+	CancelOperation(ctx context.Context, operationID string) error
+}
+
+// JobQueue runs started operations, as for POST /api/operations/start
+type JobQueue interface {
+	Enqueue(job *operations.Job) error
+}
+
+type operationParameter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type startOperationRequest struct {
+	Mode       string               `json:"mode"`
+	Steps      []string             `json:"steps"`
+	FromDate   string               `json:"from_date"`
+	ToDate     string               `json:"to_date"`
+	Parameters []operationParameter `json:"parameters"`
+}
+
+// startOperation queues an operation the way the REST handler does: one step
+// runs that step, several the full pipeline
+func (s *Server) startOperation(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	if s.opts.Jobs == nil {
+		return nil, apierrors.Newf(apierrors.Unavailable, "operation queue is not running")
+	}
+	var in startOperationRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+	if _, err := parseDate("from_date", in.FromDate); err != nil {
+		return nil, err
+	}
+	if _, err := parseDate("to_date", in.ToDate); err != nil {
+		return nil, err
+	}
+
+	request := &operations.OperationRequest{
+		ID:         uuid.New().String(),
+		Mode:       in.Mode,
+		FromDate:   in.FromDate,
+		ToDate:     in.ToDate,
+		Parameters: make(map[string]interface{}, len(in.Parameters)+3),
+	}
+	for _, p := range in.Parameters {
+		request.Parameters[p.Key] = p.Value
+	}
+	if in.FromDate != "" {
+		request.Parameters["from"] = in.FromDate
+	}
+	if in.ToDate != "" {
+		request.Parameters["to"] = in.ToDate
+	}
+
+	job := &operations.Job{
+		ID:          request.ID,
+		OperationID: request.ID,
+		StageName:   "Operation",
+		Status:      operations.JobStatusPending,
+		CreatedAt:   time.Now(),
+		Request:     request,
+		Metadata: map[string]interface{}{
+			"source":      "grpc",
+			"mode":        request.Mode,
+			"steps_count": len(in.Steps),
+		},
+	}
+	switch len(in.Steps) {
+	case 0:
+	case 1:
+		request.Parameters["step"] = in.Steps[0]
+		job.StageID = in.Steps[0]
+	default:
+		request.Parameters["step"] = "full_pipeline"
+		job.StageID = "full_pipeline"
+		job.StageName = "Full Pipeline"
+	}
+
+	if err := s.opts.Jobs.Enqueue(job); err != nil {
+		return nil, apierrors.Wrap(apierrors.Unavailable, err, "operation queue is full, try again later")
+	}
+	s.logger.InfoContext(ctx, "operation job enqueued",
+		slog.String("job_id", job.ID),
+		slog.String("stage_id", job.StageID),
+		slog.String("user", callerFromContext(ctx).Name))
+
+	return map[string]string{
+		"job_id":       job.ID,
+		"operation_id": request.ID,
+		"status":       string(operations.JobStatusPending),
+	}, nil
+}
+
+type operationIDRequest struct {
+	ID string `json:"id"`
+}
+
+func (s *Server) getOperation(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in operationIDRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+	if in.ID == "" {
+		return nil, apierrors.Newf(apierrors.Validation, "id is required")
+	}
+	state, err := s.opts.Operations.GetOperationStatus(ctx, in.ID)
+	if err != nil {
+		return nil, apierrors.Wrapf(apierrors.NotFound, err, "operation %s not found", in.ID)
+	}
+	return newOperationView(state), nil
+}
+
+type listOperationsRequest struct {
+	Status string `json:"status"`
+}
+
+func (s *Server) listOperations(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in listOperationsRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+	switch operations.OperationStatusValue(in.Status) {
+	case "", operations.OperationStatusPending, operations.OperationStatusRunning,
+		operations.OperationStatusCompleted, operations.OperationStatusFailed, operations.OperationStatusCancelled:
+	default:
+		return nil, apierrors.Newf(apierrors.Validation, "invalid status filter %q", in.Status)
+	}
+
+	states, err := s.opts.Operations.ListOperations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]operationView, 0, len(states))
+	for _, state := range states {
+		view := newOperationView(state)
+		if in.Status == "" || view.Status == in.Status {
+			views = append(views, view)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].StartTime > views[j].StartTime })
+	return map[string]interface{}{"operations": views}, nil
+}
+
+func (s *Server) cancelOperation(ctx context.Context, req *dynamicpb.Message) (interface{}, error) {
+	var in operationIDRequest
+	if err := decodeRequest(req, &in); err != nil {
+		return nil, err
+	}
+	if in.ID == "" {
+		return nil, apierrors.Newf(apierrors.Validation, "id is required")
+	}
+	if _, err := s.opts.Operations.GetOperationStatus(ctx, in.ID); err != nil {
+		return nil, apierrors.Wrapf(apierrors.NotFound, err, "operation %s not found", in.ID)
+	}
+	if err := s.opts.Operations.CancelOperation(ctx, in.ID); err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "operation cancelled",
+		slog.String("operation_id", in.ID),
+		slog.String("user", callerFromContext(ctx).Name))
+	return struct{}{}, nil
+}
+
+// operationView is the Operation message of an operation state
+type operationView struct {
+	ID        string     `json:"id"`
+	Status    string     `json:"status"`
+	StartTime string     `json:"start_time"`
+	EndTime   string     `json:"end_time,omitempty"`
+	Steps     []stepView `json:"steps"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type stepView struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Progress  float64 `json:"progress"`
+	Message   string  `json:"message,omitempty"`
+	StartTime string  `json:"start_time,omitempty"`
+	EndTime   string  `json:"end_time,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+func newOperationView(state *operations.OperationState) operationView {
+	state = state.Clone()
+	view := operationView{
+		ID:        state.ID,
+		Status:    string(state.Status),
+		StartTime: formatTime(&state.StartTime),
+		EndTime:   formatTime(state.EndTime),
+		Steps:     make([]stepView, 0, len(state.Steps)),
+	}
+	if state.Error != nil {
+		view.Error = state.Error.Error()
+	}
+	for _, step := range state.Steps {
+		sv := stepView{
+			ID:        step.ID,
+			Name:      step.Name,
+			Status:    string(step.Status),
+			Progress:  step.Progress,
+			Message:   step.Message,
+			StartTime: formatTime(step.StartTime),
+			EndTime:   formatTime(step.EndTime),
+		}
+		if step.Error != nil {
+			sv.Error = step.Error.Error()
+		}
+		view.Steps = append(view.Steps, sv)
+	}
+	// Steps are kept in a map; order them by when they started, then by ID
+	sort.Slice(view.Steps, func(i, j int) bool {
+		a, b := view.Steps[i], view.Steps[j]
+		if a.StartTime != b.StartTime {
+			return a.StartTime != "" && (b.StartTime == "" || a.StartTime < b.StartTime)
+		}
+		return a.ID < b.ID
+	})
+	return view
+}
+
+// formatTime renders t in RFC 3339, or "" when unset
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
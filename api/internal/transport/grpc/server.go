@@ -0,0 +1,190 @@
+// Package grpc serves the ISX Pulse gRPC API defined in
+// pkg/contracts/grpcapi/isxpulse.proto. Its handlers call the same services
+// as the HTTP handlers; requests and responses are dynamic protobuf messages
+// built from the embedded definitions.
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"isxcli/internal/middleware"
+	"isxcli/pkg/contracts/grpcapi"
+)
+
+// LicenseValidator reports whether the local license is valid
+type LicenseValidator interface {
+	ValidateLicenseWithContext(ctx context.Context) (bool, error)
+}
+
+// Options are the services behind the gRPC API
+type Options struct {
+	Data       DataService
+	Liquidity  LiquidityService
+	Operations OperationService
+	Jobs       JobQueue
+	// License, when set, is checked before every call
+	License LicenseValidator
+	// Users enables API tokens and roles as in the HTTP API; nil disables them
+	Users *middleware.Users
+	// Reflection registers the server reflection service
+	Reflection bool
+}
+
+// Server is the gRPC server
+type Server struct {
+	opts   Options
+	server *grpc.Server
+	logger *slog.Logger
+}
+
+// methodFunc handles one unary method. The result is marshalled to JSON
+// and read into the method's output message, so it only needs JSON field
+// names matching the message's field names.
+type methodFunc func(ctx context.Context, req *dynamicpb.Message) (interface{}, error)
+
+// NewServer creates a gRPC server exposing the data, liquidity and
+// operations services
+func NewServer(opts Options, logger *slog.Logger) (*Server, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Server{opts: opts, logger: logger.With(slog.String("component", "grpc"))}
+	s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(s.recoverPanics, s.authorize))
+
+	services := map[string]map[string]methodFunc{
+		grpcapi.DataServiceName: {
+			"GetTickerHistory": s.getTickerHistory,
+			"GetTickerBars":    s.getTickerBars,
+			"GetMarketDiff":    s.getMarketDiff,
+		},
+		grpcapi.LiquidityServiceName: {
+			"GetLiquidityHistory": s.getLiquidityHistory,
+			"GetSafeTrade":        s.getSafeTrade,
+		},
+		grpcapi.OperationsServiceName: {
+			"StartOperation":  s.startOperation,
+			"GetOperation":    s.getOperation,
+			"ListOperations":  s.listOperations,
+			"CancelOperation": s.cancelOperation,
+		},
+	}
+	for name, methods := range services {
+		if err := s.register(name, methods); err != nil {
+			return nil, err
+		}
+	}
+
+	healthpb.RegisterHealthServer(s.server, health.NewServer())
+	if opts.Reflection {
+		reflection.Register(s.server)
+	}
+	return s, nil
+}
+
+// register adds a service of isxpulse.proto with a handler per method
+func (s *Server) register(name string, methods map[string]methodFunc) error {
+	svc, err := grpcapi.Service(name)
+	if err != nil {
+		return err
+	}
+
+	desc := grpc.ServiceDesc{
+		ServiceName: name,
+		HandlerType: (*interface{})(nil),
+		Metadata:    grpcapi.ProtoFile,
+	}
+	for i := 0; i < svc.Methods().Len(); i++ {
+		md := svc.Methods().Get(i)
+		fn, ok := methods[string(md.Name())]
+		if !ok {
+			return fmt.Errorf("no handler for %s", md.FullName())
+		}
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: string(md.Name()),
+			Handler:    s.unaryHandler(md, fn),
+		})
+	}
+	s.server.RegisterService(&desc, s)
+	return nil
+}
+
+func (s *Server) unaryHandler(md protoreflect.MethodDescriptor, fn methodFunc) grpc.MethodHandler {
+	fullMethod := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	return func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := dynamicpb.NewMessage(md.Input())
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			result, err := fn(ctx, req.(*dynamicpb.Message))
+			if err != nil {
+				return nil, s.statusError(ctx, fullMethod, err)
+			}
+			resp, err := toMessage(md.Output(), result)
+			if err != nil {
+				return nil, s.statusError(ctx, fullMethod, err)
+			}
+			return resp, nil
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: s, FullMethod: fullMethod}, handler)
+	}
+}
+
+// decodeRequest copies a request message into v by the JSON field names of
+// v, which match the message's field names
+func decodeRequest(req *dynamicpb.Message, v interface{}) error {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// toMessage reads a result into a new message of desc through its JSON
+// form. Fields the message does not define are dropped.
+func toMessage(desc protoreflect.MessageDescriptor, result interface{}) (*dynamicpb.Message, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", desc.Name(), err)
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("build %s: %w", desc.Name(), err)
+	}
+	return msg, nil
+}
+
+// Serve accepts connections on lis until Stop is called
+func (s *Server) Serve(lis net.Listener) error {
+	s.logger.Info("gRPC server listening", slog.String("address", lis.Addr().String()))
+	return s.server.Serve(lis)
+}
+
+// Stop stops the server, letting running calls finish until ctx is done
+func (s *Server) Stop(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
@@ -0,0 +1,279 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/dataprocessing/analytics"
+	"isxcli/internal/middleware"
+	"isxcli/internal/operations"
+	"isxcli/internal/services"
+	"isxcli/pkg/contracts/grpcapi"
+)
+
+type fakeData struct {
+	historyQuery services.TickerHistoryQuery
+}
+
+func (f *fakeData) GetTickerHistory(_ context.Context, q services.TickerHistoryQuery) (*services.TickerHistoryPage, error) {
+	f.historyQuery = q
+	if q.Symbol == "NONE" {
+		return nil, services.ErrTickerNotFound
+	}
+	return &services.TickerHistoryPage{
+		Symbol:     q.Symbol,
+		Currency:   q.Currency,
+		Columns:    []string{"Date", "ClosePrice"},
+		Rows:       [][]string{{"2025-08-24", "1.25"}, {"2025-08-25", "1.30"}},
+		Page:       q.Page,
+		PageSize:   q.PageSize,
+		Total:      2,
+		TotalPages: 1,
+	}, nil
+}
+
+func (f *fakeData) GetTickerBars(_ context.Context, symbol string, interval analytics.Interval, _, _ time.Time) ([]analytics.Bar, error) {
+	return []analytics.Bar{{Symbol: symbol, Open: 1, Close: 1.2, Volume: 1000, TradingDays: 5}}, nil
+}
+
+func (f *fakeData) GetMarketDiff(_ context.Context, _, _ time.Time, cur currency.Code) (*services.MarketDiff, error) {
+	return nil, errors.New("disk on fire")
+}
+
+type fakeLiquidity struct{}
+
+func (fakeLiquidity) GetHistory(_ context.Context, q services.LiquidityHistoryQuery) (*services.LiquidityHistory, error) {
+	return &services.LiquidityHistory{Symbol: q.Symbol}, nil
+}
+
+func (fakeLiquidity) GetSafeTrade(_ context.Context, q services.SafeTradeQuery) (*services.SafeTrade, error) {
+	return &services.SafeTrade{Symbol: q.Symbol, Currency: q.Currency}, nil
+}
+
+type fakeOperations struct {
+	cancelled []string
+}
+
+func (f *fakeOperations) GetOperationStatus(_ context.Context, id string) (*operations.OperationState, error) {
+	if id != "op-1" {
+		return nil, errors.New("operation not found")
+	}
+	return operations.NewOperationState(id), nil
+}
+
+func (f *fakeOperations) ListOperations(ctx context.Context) ([]*operations.OperationState, error) {
+	state, _ := f.GetOperationStatus(ctx, "op-1")
+	return []*operations.OperationState{state}, nil
+}
+
+func (f *fakeOperations) CancelOperation(_ context.Context, id string) error {
+	f.cancelled = append(f.cancelled, id)
+	return nil
+}
+
+type fakeJobs struct {
+	jobs []*operations.Job
+}
+
+func (f *fakeJobs) Enqueue(job *operations.Job) error {
+	f.jobs = append(f.jobs, job)
+	return nil
+}
+
+type fakeLicense struct{ valid bool }
+
+func (f fakeLicense) ValidateLicenseWithContext(context.Context) (bool, error) {
+	return f.valid, nil
+}
+
+// startServer serves opts over an in-memory listener and returns a client
+func startServer(t *testing.T, opts Options) *grpc.ClientConn {
+	t.Helper()
+	srv, err := NewServer(opts, nil)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { srv.Stop(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// call invokes service/method with a JSON request and returns the response
+func call(ctx context.Context, conn *grpc.ClientConn, service, method, request string) (*dynamicpb.Message, error) {
+	svc, err := grpcapi.Service(service)
+	if err != nil {
+		return nil, err
+	}
+	md := svc.Methods().ByName(protoreflect.Name(method))
+	req := dynamicpb.NewMessage(md.Input())
+	if err := protojson.Unmarshal([]byte(request), req); err != nil {
+		return nil, err
+	}
+	resp := dynamicpb.NewMessage(md.Output())
+	if err := conn.Invoke(ctx, "/"+service+"/"+method, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func field(msg *dynamicpb.Message, name string) protoreflect.Value {
+	return msg.Get(msg.Descriptor().Fields().ByName(protoreflect.Name(name)))
+}
+
+func TestDataService(t *testing.T) {
+	data := &fakeData{}
+	conn := startServer(t, Options{Data: data})
+	ctx := context.Background()
+
+	resp, err := call(ctx, conn, grpcapi.DataServiceName, "GetTickerHistory", `{"symbol":"bbob","currency":"usd"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", data.historyQuery.Symbol)
+	assert.Equal(t, services.DefaultHistoryPageSize, data.historyQuery.PageSize)
+	assert.Equal(t, "USD", field(resp, "currency").String())
+	rows := field(resp, "rows").List()
+	require.Equal(t, 2, rows.Len())
+	assert.Equal(t, "1.30", rows.Get(1).Message().Get(rows.Get(1).Message().Descriptor().Fields().ByName("cells")).List().Get(1).String())
+
+	resp, err = call(ctx, conn, grpcapi.DataServiceName, "GetTickerBars", `{"symbol":"BBOB"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "1w", field(resp, "interval").String())
+	assert.Equal(t, 1, field(resp, "bars").List().Len())
+
+	for name, tc := range map[string]struct {
+		method, request string
+		code            codes.Code
+	}{
+		"bad date":       {"GetTickerHistory", `{"symbol":"BBOB","from":"25-08-2025"}`, codes.InvalidArgument},
+		"bad page size":  {"GetTickerHistory", `{"symbol":"BBOB","page_size":9999}`, codes.InvalidArgument},
+		"unknown ticker": {"GetTickerHistory", `{"symbol":"NONE"}`, codes.NotFound},
+		"bad interval":   {"GetTickerBars", `{"symbol":"BBOB","interval":"1d"}`, codes.InvalidArgument},
+		"internal error": {"GetMarketDiff", `{}`, codes.Internal},
+	} {
+		_, err := call(ctx, conn, grpcapi.DataServiceName, tc.method, tc.request)
+		assert.Equal(t, tc.code, status.Code(err), name)
+		if tc.code == codes.Internal {
+			assert.NotContains(t, err.Error(), "disk on fire", "internal details stay in the log")
+		}
+	}
+}
+
+func TestLiquidityService(t *testing.T) {
+	conn := startServer(t, Options{Liquidity: fakeLiquidity{}})
+	ctx := context.Background()
+
+	resp, err := call(ctx, conn, grpcapi.LiquidityServiceName, "GetSafeTrade", `{"symbol":"bbob","currency":"USD"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "BBOB", field(resp, "symbol").String())
+	assert.False(t, resp.Has(resp.Descriptor().Fields().ByName("days_to_exit")))
+
+	_, err = call(ctx, conn, grpcapi.LiquidityServiceName, "GetSafeTrade", `{"symbol":"BBOB","impact":25}`)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = call(ctx, conn, grpcapi.LiquidityServiceName, "GetLiquidityHistory", `{"symbol":"BBOB"}`)
+	assert.Equal(t, codes.NotFound, status.Code(err), "no windows means no history")
+}
+
+func TestOperationsService(t *testing.T) {
+	ops := &fakeOperations{}
+	jobs := &fakeJobs{}
+	conn := startServer(t, Options{Operations: ops, Jobs: jobs})
+	ctx := context.Background()
+
+	resp, err := call(ctx, conn, grpcapi.OperationsServiceName, "StartOperation",
+		`{"mode":"full","steps":["scraping"],"from_date":"2025-08-01","parameters":[{"key":"headless","value":"true"}]}`)
+	require.NoError(t, err)
+	require.Len(t, jobs.jobs, 1)
+	job := jobs.jobs[0]
+	assert.Equal(t, job.ID, field(resp, "job_id").String())
+	assert.Equal(t, "scraping", job.StageID)
+	assert.Equal(t, "2025-08-01", job.Request.FromDate)
+	assert.Equal(t, "scraping", job.Request.Parameters["step"])
+	assert.Equal(t, "true", job.Request.Parameters["headless"])
+
+	_, err = call(ctx, conn, grpcapi.OperationsServiceName, "StartOperation", `{"steps":["scraping","processing"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "full_pipeline", jobs.jobs[1].StageID)
+
+	resp, err = call(ctx, conn, grpcapi.OperationsServiceName, "GetOperation", `{"id":"op-1"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "op-1", field(resp, "id").String())
+
+	_, err = call(ctx, conn, grpcapi.OperationsServiceName, "GetOperation", `{"id":"missing"}`)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err = call(ctx, conn, grpcapi.OperationsServiceName, "ListOperations", `{}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, field(resp, "operations").List().Len())
+
+	_, err = call(ctx, conn, grpcapi.OperationsServiceName, "ListOperations", `{"status":"sleeping"}`)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = call(ctx, conn, grpcapi.OperationsServiceName, "CancelOperation", `{"id":"op-1"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"op-1"}, ops.cancelled)
+}
+
+func TestAuthorization(t *testing.T) {
+	users, err := middleware.NewUsers([]middleware.User{
+		{Name: "viewer", Role: middleware.RoleViewer, Token: "viewer-token-0123456789"},
+		{Name: "operator", Role: middleware.RoleOperator, Token: "operator-token-0123456789"},
+	})
+	require.NoError(t, err)
+	jobs := &fakeJobs{}
+	conn := startServer(t, Options{Data: &fakeData{}, Operations: &fakeOperations{}, Jobs: jobs, Users: users})
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	_, err = call(context.Background(), conn, grpcapi.DataServiceName, "GetTickerBars", `{"symbol":"BBOB"}`)
+	assert.NoError(t, err, "anonymous callers read data")
+
+	_, err = call(withToken("not-a-known-token-at-all"), conn, grpcapi.DataServiceName, "GetTickerBars", `{"symbol":"BBOB"}`)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call(withToken("viewer-token-0123456789"), conn, grpcapi.OperationsServiceName, "StartOperation", `{}`)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "operator-token-0123456789")
+	_, err = call(ctx, conn, grpcapi.OperationsServiceName, "StartOperation", `{}`)
+	assert.NoError(t, err)
+	assert.Len(t, jobs.jobs, 1)
+}
+
+func TestLicenseRequired(t *testing.T) {
+	conn := startServer(t, Options{Data: &fakeData{}, License: fakeLicense{valid: false}})
+
+	_, err := call(context.Background(), conn, grpcapi.DataServiceName, "GetTickerBars", `{"symbol":"BBOB"}`)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Health checks stay open so load balancers can probe an unlicensed node
+	resp := &healthpb.HealthCheckResponse{}
+	err = conn.Invoke(context.Background(), "/grpc.health.v1.Health/Check", &healthpb.HealthCheckRequest{}, resp)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, resp))
+}
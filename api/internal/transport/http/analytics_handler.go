@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// AnalyticsHandler handles cross-ticker analytics requests
+type AnalyticsHandler struct {
+	service      *services.CorrelationService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(service *services.CorrelationService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the analytics routes mounted at /api/v1/analytics
+func (h *AnalyticsHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/correlations", h.Correlations)
+
+	return r
+}
+
+// Correlations handles GET /api/v1/analytics/correlations. Query params:
+// window (lookback in sessions, the first computed window when empty) and
+// symbols (comma-separated tickers, all when empty).
+func (h *AnalyticsHandler) Correlations(w http.ResponseWriter, r *http.Request) {
+	var window int
+	if v := r.URL.Query().Get("window"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("window", "Window must be a positive number of sessions"))
+			return
+		}
+		window = days
+	}
+	var symbols []string
+	for _, s := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+
+	correlations, err := h.service.Get(r.Context(), window, symbols)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   correlations,
+		"count":  len(correlations.Tickers),
+	})
+}
+
+// handleError maps analytics service errors to RFC 7807 responses
+func (h *AnalyticsHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrCorrelationsNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusNotFound,
+			"CORRELATIONS_NOT_FOUND",
+			"Ticker correlations not available; run the indices step first",
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "analytics request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"isxcli/internal/audit"
+	apierrors "isxcli/internal/errors"
+)
+
+// AuditHandler serves the audit log of state-changing API calls
+type AuditHandler struct {
+	log          *audit.Log
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewAuditHandler creates a new audit log handler; a nil log serves no entries
+func NewAuditHandler(log *audit.Log, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *AuditHandler {
+	return &AuditHandler{
+		log:          log,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the audit routes mounted at /api/v1/audit
+func (h *AuditHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+
+	return r
+}
+
+// List handles GET /api/v1/audit, newest entries first. Query params: from
+// and to (RFC 3339 time or YYYY-MM-DD; to is exclusive), user, action,
+// method, result, route (a route pattern prefix) and limit.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := audit.Filter{
+		User:   query.Get("user"),
+		Action: strings.ToLower(query.Get("action")),
+		Method: strings.ToUpper(query.Get("method")),
+		Result: strings.ToLower(query.Get("result")),
+		Route:  query.Get("route"),
+	}
+	for _, bound := range []struct {
+		name string
+		into *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", v)
+		}
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation(bound.name, "Must be an RFC 3339 time or a date in YYYY-MM-DD format"))
+			return
+		}
+		*bound.into = t
+	}
+	if filter.Action != "" && !audit.ValidAction(filter.Action) {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("action", "Action must be operations, config, exports, license, auth or other"))
+		return
+	}
+	if filter.Result != "" && !audit.ValidResult(filter.Result) {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("result", "Result must be success, denied or failure"))
+		return
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > audit.MaxQueryLimit {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("limit", "Limit must be between 1 and "+strconv.Itoa(audit.MaxQueryLimit)))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.log.Query(filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "audit log query failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]interface{}{
+		"status":  "success",
+		"data":    entries,
+		"count":   len(entries),
+		"enabled": h.log != nil,
+	})
+}
@@ -0,0 +1,404 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"isxcli/internal/auth"
+	apierrors "isxcli/internal/errors"
+	customMiddleware "isxcli/internal/middleware"
+)
+
+// AuthHandler handles web UI sign-in and local account requests
+type AuthHandler struct {
+	accounts     *auth.Accounts // nil when local accounts are disabled
+	sessions     *auth.Sessions
+	oidc         *auth.OIDC // nil when OIDC is disabled
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewAuthHandler creates a new auth handler. accounts and oidc may be nil to
+// turn off that way of signing in.
+func NewAuthHandler(accounts *auth.Accounts, sessions *auth.Sessions, oidc *auth.OIDC, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *AuthHandler {
+	return &AuthHandler{
+		accounts:     accounts,
+		sessions:     sessions,
+		oidc:         oidc,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the sign-in routes mounted at /api/auth
+func (h *AuthHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/session", h.Session)
+	r.Post("/login", h.Login)
+	r.Post("/logout", h.Logout)
+	r.Post("/setup", h.Setup)
+	r.Put("/password", h.ChangePassword)
+	r.Get("/oidc/login", h.OIDCLogin)
+	r.Get("/oidc/callback", h.OIDCCallback)
+
+	return r
+}
+
+// AccountRoutes returns the local account routes mounted at
+// /api/v1/auth/accounts; callers must restrict them to admins
+func (h *AuthHandler) AccountRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.ListAccounts)
+	r.Post("/", h.CreateAccount)
+	r.Put("/{name}/password", h.SetAccountPassword)
+	r.Delete("/{name}", h.DeleteAccount)
+
+	return r
+}
+
+// credentialsRequest is the body of login, setup and account creation
+type credentialsRequest struct {
+	Username string                `json:"username"`
+	Password string                `json:"password"`
+	Role     customMiddleware.Role `json:"role,omitempty"`
+	// SetupToken is the content of data/auth/setup_token, required by setup
+	SetupToken string `json:"setup_token,omitempty"`
+}
+
+// passwordRequest is the body of password changes
+type passwordRequest struct {
+	CurrentPassword string `json:"current_password,omitempty"`
+	NewPassword     string `json:"new_password"`
+}
+
+// Session handles GET /api/auth/session. It reports the signed-in user, if
+// any, and how users can sign in, so the UI can show the right login form.
+func (h *AuthHandler) Session(w http.ResponseWriter, r *http.Request) {
+	setupRequired := false
+	if h.accounts != nil {
+		empty, err := h.accounts.Empty()
+		if err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		setupRequired = empty
+	}
+
+	data := map[string]interface{}{
+		"authenticated": false,
+		"methods": map[string]bool{
+			"local": h.accounts != nil,
+			"oidc":  h.oidc != nil,
+		},
+		"setup_required": setupRequired,
+	}
+	if session, ok := h.sessions.Lookup(r); ok {
+		data["authenticated"] = true
+		data["user"] = session.User
+		data["provider"] = session.Provider
+		data["expires_at"] = session.ExpiresAt
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+}
+
+// Login handles POST /api/auth/login with a local account
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	var req credentialsRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	account, err := h.accounts.Authenticate(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			h.logger.WarnContext(r.Context(), "Rejected sign-in",
+				slog.String("username", auth.NormalizeName(req.Username)),
+				slog.String("remote_addr", r.RemoteAddr))
+		}
+		h.handleError(w, r, err)
+		return
+	}
+	h.startSession(w, r, account.User(), auth.ProviderLocal)
+}
+
+// Logout handles POST /api/auth/logout
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	h.sessions.Destroy(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Setup handles POST /api/auth/setup. It creates the first admin account
+// while none exists and signs it in; afterwards admins add accounts. The
+// one-time token in data/auth/setup_token proves the caller runs the server.
+func (h *AuthHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	var req credentialsRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	account, err := h.accounts.CreateFirst(req.Username, req.Password, req.SetupToken)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logger.InfoContext(r.Context(), "Created first admin account",
+		slog.String("username", account.Name),
+		slog.String("remote_addr", r.RemoteAddr))
+
+	render.Status(r, http.StatusCreated)
+	h.startSession(w, r, account.User(), auth.ProviderLocal)
+}
+
+// ChangePassword handles PUT /api/auth/password for the signed-in local
+// account. Its other sessions end; this one is renewed.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	session, ok := h.sessions.Lookup(r)
+	if !ok || session.Provider != auth.ProviderLocal {
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusUnauthorized, "LOGIN_REQUIRED", "Sign in with a local account to change its password"))
+		return
+	}
+	var req passwordRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	if _, err := h.accounts.Authenticate(session.User.Name, req.CurrentPassword); err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("current_password", "Current password is wrong"))
+			return
+		}
+		h.handleError(w, r, err)
+		return
+	}
+	if err := h.accounts.SetPassword(session.User.Name, req.NewPassword); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.sessions.Revoke(session.User.Name)
+	h.startSession(w, r, session.User, auth.ProviderLocal)
+}
+
+// OIDCLogin handles GET /api/auth/oidc/login by sending the browser to the
+// provider. Query params: redirect (local path to return to, / by default).
+func (h *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if !h.oidcEnabled(w, r) {
+		return
+	}
+	url, err := h.oidc.AuthCodeURL(w, r, r.URL.Query().Get("redirect"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// OIDCCallback handles GET /api/auth/oidc/callback, where the provider sends
+// the browser back after sign-in
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if !h.oidcEnabled(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusUnauthorized,
+			"OIDC_LOGIN_FAILED",
+			"The identity provider did not sign you in",
+			map[string]interface{}{"error": providerErr, "description": query.Get("error_description")},
+		))
+		return
+	}
+
+	user, redirect, err := h.oidc.Exchange(w, r, query.Get("state"), query.Get("code"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if _, err := h.sessions.Create(w, r, user, auth.ProviderOIDC); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logger.InfoContext(r.Context(), "Signed in through OIDC",
+		slog.String("username", user.Name),
+		slog.String("role", string(user.Role)))
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// ListAccounts handles GET /api/v1/auth/accounts
+func (h *AuthHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	accounts, err := h.accounts.List()
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   accounts,
+		"count":  len(accounts),
+	})
+}
+
+// CreateAccount handles POST /api/v1/auth/accounts
+func (h *AuthHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	var req credentialsRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+	if req.Role == "" {
+		req.Role = customMiddleware.RoleViewer
+	}
+
+	account, err := h.accounts.Create(req.Username, req.Password, req.Role)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   account,
+	})
+}
+
+// SetAccountPassword handles PUT /api/v1/auth/accounts/{name}/password. The
+// account's sessions end.
+func (h *AuthHandler) SetAccountPassword(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	var req passwordRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	name := auth.NormalizeName(chi.URLParam(r, "name"))
+	if err := h.accounts.SetPassword(name, req.NewPassword); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.sessions.Revoke(name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteAccount handles DELETE /api/v1/auth/accounts/{name}. The account's
+// sessions end.
+func (h *AuthHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if !h.localEnabled(w, r) {
+		return
+	}
+	name := auth.NormalizeName(chi.URLParam(r, "name"))
+	if err := h.accounts.Delete(name); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.sessions.Revoke(name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startSession signs user in and responds with the new session
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, user customMiddleware.User, provider string) {
+	session, err := h.sessions.Create(w, r, user, provider)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   session,
+	})
+}
+
+// localEnabled responds with 404 when local accounts are disabled
+func (h *AuthHandler) localEnabled(w http.ResponseWriter, r *http.Request) bool {
+	if h.accounts == nil {
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusNotFound, "LOCAL_AUTH_DISABLED", "Local accounts are disabled; set auth.local.enabled"))
+		return false
+	}
+	return true
+}
+
+// oidcEnabled responds with 404 when OIDC sign-in is not configured
+func (h *AuthHandler) oidcEnabled(w http.ResponseWriter, r *http.Request) bool {
+	if h.oidc == nil {
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusNotFound, "OIDC_DISABLED", "OIDC sign-in is not configured; set auth.oidc"))
+		return false
+	}
+	return true
+}
+
+// handleError maps auth errors to RFC 7807 responses
+func (h *AuthHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid username or password"))
+	case errors.Is(err, auth.ErrAccountExists):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusConflict, "ACCOUNT_EXISTS", err.Error()))
+	case errors.Is(err, auth.ErrAccountNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"ACCOUNT_NOT_FOUND",
+			"Account not found",
+			map[string]interface{}{"name": chi.URLParam(r, "name")},
+		))
+	case errors.Is(err, auth.ErrLastAdmin):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusBadRequest, "LAST_ADMIN", err.Error()))
+	case errors.Is(err, auth.ErrInvalidSetupToken):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusForbidden, "INVALID_SETUP_TOKEN", "Setup token is missing or wrong; copy it from data/auth/setup_token"))
+	case errors.Is(err, auth.ErrInvalidAccount):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusBadRequest, "VALIDATION_FAILED", err.Error()))
+	case errors.Is(err, auth.ErrLoginExpired):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusBadRequest, "LOGIN_EXPIRED", err.Error()))
+	case errors.Is(err, auth.ErrNotAllowed):
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusForbidden, "LOGIN_NOT_ALLOWED", err.Error()))
+	case errors.Is(err, auth.ErrInvalidIDToken):
+		h.logger.WarnContext(r.Context(), "Rejected OIDC ID token",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusUnauthorized, "OIDC_LOGIN_FAILED", "The identity provider's response could not be verified"))
+	default:
+		h.logger.ErrorContext(r.Context(), "auth request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"isxcli/internal/backup"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/files"
+)
+
+// BackupHandler creates and lists snapshots of the data directory. Restoring
+// is left to the backup command, run while the server is stopped.
+type BackupHandler struct {
+	manager      *backup.Manager
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(manager *backup.Manager, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *BackupHandler {
+	return &BackupHandler{
+		manager:      manager,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the backup routes mounted at /api/v1/backups
+func (h *BackupHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+
+	return r
+}
+
+// List handles GET /api/v1/backups, newest first
+func (h *BackupHandler) List(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.manager.List()
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   snapshots,
+	})
+}
+
+// Create handles POST /api/v1/backups. It waits for the snapshot to be
+// written and returns it.
+func (h *BackupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.manager.Create(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   snapshot,
+	})
+}
+
+func (h *BackupHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, files.ErrLocked):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusConflict,
+			"BACKUP_BUSY",
+			"An operation is writing the reports; try again when it finishes",
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "backup request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// ChangelogHandler handles release changelog requests
+type ChangelogHandler struct {
+	service      *services.ChangelogService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewChangelogHandler creates a new changelog handler
+func NewChangelogHandler(service *services.ChangelogService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *ChangelogHandler {
+	return &ChangelogHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the changelog routes mounted at /api/v1/system/changelog
+func (h *ChangelogHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.Get)
+
+	return r
+}
+
+// Get handles GET /api/v1/system/changelog. Query param since is the version
+// the user upgraded from; only newer releases are returned.
+func (h *ChangelogHandler) Get(w http.ResponseWriter, r *http.Request) {
+	changelog, err := h.service.Get(r.Context(), r.URL.Query().Get("since"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "changelog request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   changelog,
+		"count":  len(changelog.Releases),
+	})
+}
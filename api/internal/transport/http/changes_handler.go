@@ -0,0 +1,73 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// ChangesHandler handles data delta requests
+type ChangesHandler struct {
+	service      *services.ChangesService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewChangesHandler creates a new data changes handler
+func NewChangesHandler(service *services.ChangesService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *ChangesHandler {
+	return &ChangesHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the changes routes mounted at /api/v1/changes
+func (h *ChangesHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.Get)
+
+	return r
+}
+
+// Get handles GET /api/v1/changes. Query param since is an RFC 3339 time,
+// usually the until of the previous response, or a YYYY-MM-DD date.
+func (h *ChangesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", v)
+		}
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("since", "Since must be an RFC 3339 time or a date in YYYY-MM-DD format"))
+			return
+		}
+		since = t
+	}
+
+	changes, err := h.service.Since(r.Context(), since)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "changes request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   changes,
+		"count":  len(changes.Files),
+	})
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// CompanyHandler serves company reference data scraped from the ISX portal
+type CompanyHandler struct {
+	service      *services.CompanyService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewCompanyHandler creates a new company handler
+func NewCompanyHandler(service *services.CompanyService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *CompanyHandler {
+	return &CompanyHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the company routes mounted at /api/v1/companies
+func (h *CompanyHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Get("/{symbol}", h.Get)
+
+	return r
+}
+
+// List handles GET /api/v1/companies. Query params: sector, currency (USD
+// reports last price and market cap in US dollars).
+func (h *CompanyHandler) List(w http.ResponseWriter, r *http.Request) {
+	cur, apiErr := parseCurrency(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	companies, err := h.service.List(r.Context(), strings.TrimSpace(r.URL.Query().Get("sector")))
+	if err == nil {
+		err = h.service.InCurrency(r.Context(), companies, cur)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   companies,
+		"count":  len(companies),
+	})
+}
+
+// Get handles GET /api/v1/companies/{symbol}. Query params: currency.
+func (h *CompanyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cur, apiErr := parseCurrency(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	company, err := h.service.Get(r.Context(), chi.URLParam(r, "symbol"))
+	if err == nil {
+		converted := []services.Company{*company}
+		err = h.service.InCurrency(r.Context(), converted, cur)
+		company = &converted[0]
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   company,
+	})
+}
+
+// handleError maps service errors to problem responses
+func (h *CompanyHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, services.ErrCompanyNotFound) {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"COMPANY_NOT_FOUND",
+			"No company profile for ticker; run the scraper with --mode companies",
+			map[string]interface{}{"symbol": strings.ToUpper(chi.URLParam(r, "symbol"))},
+		))
+		return
+	}
+
+	h.logger.ErrorContext(r.Context(), "Company request failed",
+		slog.String("error", err.Error()),
+		slog.String("request_id", middleware.GetReqID(r.Context())))
+	h.errorHandler.HandleError(w, r, apierrors.New(
+		http.StatusInternalServerError,
+		"COMPANY_ERROR",
+		"Failed to retrieve company data",
+	))
+}
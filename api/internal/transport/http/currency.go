@@ -0,0 +1,18 @@
+package http
+
+import (
+	"net/http"
+
+	"isxcli/internal/currency"
+	apierrors "isxcli/internal/errors"
+)
+
+// parseCurrency reads the optional currency query parameter: IQD (the
+// default) or USD, converted at the CBI official rate of each trading day
+func parseCurrency(r *http.Request) (currency.Code, *apierrors.APIError) {
+	code, err := currency.ParseCode(r.URL.Query().Get("currency"))
+	if err != nil {
+		return "", apierrors.ErrValidation("currency", "Currency must be IQD or USD")
+	}
+	return code, nil
+}
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,11 +16,13 @@ import (
 	
 	apierrors "isxcli/internal/errors"
 	"isxcli/internal/services"
+	"isxcli/pkg/contracts/domain"
 )
 
 // DataHandler handles data-related HTTP requests with RFC 7807 compliance
 type DataHandler struct {
 	service      DataServiceInterface
+	timeline     *services.TimelineService
 	logger       *slog.Logger
 	errorHandler *apierrors.ErrorHandler
 }
@@ -32,6 +36,12 @@ func NewDataHandler(service DataServiceInterface, logger *slog.Logger, errorHand
 	}
 }
 
+// WithTimeline enables the ticker event timeline route
+func (h *DataHandler) WithTimeline(timeline *services.TimelineService) *DataHandler {
+	h.timeline = timeline
+	return h
+}
+
 // Routes returns the data routes with proper Chi patterns
 func (h *DataHandler) Routes() chi.Router {
 	r := chi.NewRouter()
@@ -69,6 +79,52 @@ func (h *DataHandler) Routes() chi.Router {
 	return r
 }
 
+// TickerRoutes returns the versioned ticker routes mounted at /api/v1/tickers
+func (h *DataHandler) TickerRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/summary", h.GetTickerSummaries)
+	r.Route("/{ticker}", func(r chi.Router) {
+		r.Use(h.TickerCtx)
+		r.Get("/intraday", h.GetTickerIntraday)
+		r.Get("/history", h.GetTickerHistory)
+		r.Get("/bars", h.GetTickerBars)
+		if h.timeline != nil {
+			r.Get("/timeline", h.GetTickerTimeline)
+		}
+	})
+
+	return r
+}
+
+// MarketRoutes returns the versioned market-wide routes mounted at /api/v1/market
+func (h *DataHandler) MarketRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+	r.Get("/intraday", h.GetMarketIntraday)
+	r.Get("/diff", h.GetMarketDiff)
+
+	return r
+}
+
+// StreamRoutes returns the large-file streaming routes mounted at /api/data/stream.
+// They are mounted outside the standard request timeout so multi-hundred-MB
+// datasets are not cut off mid-transfer.
+func (h *DataHandler) StreamRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{type}/*", h.StreamFile)
+	r.Head("/{type}/*", h.StreamFile)
+
+	return r
+}
+
+// tickerRe matches an upper-cased ticker symbol
+var tickerRe = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
 // TickerCtx middleware validates ticker parameter
 func (h *DataHandler) TickerCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -78,8 +134,8 @@ func (h *DataHandler) TickerCtx(next http.Handler) http.Handler {
 			return
 		}
 		
-		// Validate ticker format (basic validation)
-		if len(ticker) > 10 || len(ticker) < 2 {
+		// Tickers end up in report file paths, so only the ticker charset is allowed
+		if !tickerRe.MatchString(strings.ToUpper(ticker)) {
 			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("ticker", "Invalid ticker symbol format"))
 			return
 		}
@@ -452,6 +508,119 @@ func (h *DataHandler) GetTickerChart(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetTickerIntraday handles GET /api/v1/tickers/{ticker}/intraday?date=YYYY-MM-DD
+func (h *DataHandler) GetTickerIntraday(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.GetReqID(r.Context())
+	ticker := chi.URLParam(r, "ticker")
+	date := r.URL.Query().Get("date")
+
+	h.logger.InfoContext(r.Context(), "fetching ticker intraday bars",
+		slog.String("request_id", reqID),
+		slog.String("ticker", ticker),
+		slog.String("date", date),
+	)
+
+	intraday, err := h.service.GetTickerIntraday(r.Context(), ticker, date)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get ticker intraday bars",
+			slog.String("error", err.Error()),
+			slog.String("request_id", reqID),
+			slog.String("ticker", ticker),
+		)
+
+		if errors.Is(err, services.ErrInvalidInput) {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+
+		if errors.Is(err, services.ErrNoIntradayData) {
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusNotFound,
+				"NO_INTRADAY_DATA",
+				fmt.Sprintf("No intraday data available for ticker '%s'", ticker),
+				map[string]interface{}{
+					"ticker": ticker,
+					"date":   date,
+				},
+			))
+			return
+		}
+
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	count := 0
+	if bars, ok := intraday["bars"].([]domain.IntradayData); ok {
+		count = len(bars)
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   intraday,
+		"count":  count,
+		"ticker": ticker,
+	})
+}
+
+// GetMarketIntraday handles GET /api/v1/market/intraday?date=YYYY-MM-DD&symbol=XXX.
+// It serves the latest mid-session snapshot, which is preliminary data; the
+// X-Data-Status header marks it so caches and clients don't treat it as final.
+func (h *DataHandler) GetMarketIntraday(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.GetReqID(r.Context())
+	date := r.URL.Query().Get("date")
+	symbol := r.URL.Query().Get("symbol")
+
+	h.logger.InfoContext(r.Context(), "fetching intraday market snapshot",
+		slog.String("request_id", reqID),
+		slog.String("date", date),
+		slog.String("symbol", symbol),
+	)
+
+	snapshot, err := h.service.GetMarketSnapshot(r.Context(), date, symbol)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get intraday market snapshot",
+			slog.String("error", err.Error()),
+			slog.String("request_id", reqID),
+		)
+
+		if errors.Is(err, services.ErrInvalidInput) {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+
+		if errors.Is(err, services.ErrNoSnapshotData) {
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusNotFound,
+				"NO_SNAPSHOT_DATA",
+				"No intraday snapshot available",
+				map[string]interface{}{
+					"date":   date,
+					"symbol": symbol,
+				},
+			))
+			return
+		}
+
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	count := 0
+	if records, ok := snapshot["records"].([]domain.TradeRecord); ok {
+		count = len(records)
+	}
+
+	w.Header().Set("X-Data-Status", "preliminary")
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]interface{}{
+		"status":      "success",
+		"data":        snapshot,
+		"count":       count,
+		"preliminary": true,
+	})
+}
+
 // DownloadFile handles GET /api/data/download/{type}/{filename} with RFC 7807 errors
 func (h *DataHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	reqID := middleware.GetReqID(r.Context())
@@ -574,6 +743,81 @@ func (h *DataHandler) DownloadReportFile(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// StreamFile handles GET /api/data/stream/{type}/{filepath} with Range and gzip support
+func (h *DataHandler) StreamFile(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.GetReqID(r.Context())
+	fileType := chi.URLParam(r, "type")
+	rawPath := chi.URLParam(r, "*")
+
+	decodedPath, err := url.PathUnescape(rawPath)
+	if err != nil || decodedPath == "" {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusBadRequest,
+			"INVALID_PATH",
+			"Invalid file path",
+			map[string]interface{}{
+				"filepath": rawPath,
+			},
+		))
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "streaming file",
+		slog.String("request_id", reqID),
+		slog.String("file_type", fileType),
+		slog.String("filepath", decodedPath),
+		slog.String("range", r.Header.Get("Range")),
+	)
+
+	if err := h.service.StreamFile(r.Context(), w, r, fileType, decodedPath); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to stream file",
+			slog.String("error", err.Error()),
+			slog.String("request_id", reqID),
+			slog.String("file_type", fileType),
+			slog.String("filepath", decodedPath),
+		)
+
+		if isResponseWritten(w) {
+			return
+		}
+
+		switch {
+		case errors.Is(err, services.ErrFileNotFound):
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusNotFound,
+				"FILE_NOT_FOUND",
+				fmt.Sprintf("File '%s' not found", decodedPath),
+				map[string]interface{}{
+					"type":     fileType,
+					"filepath": decodedPath,
+				},
+			))
+		case errors.Is(err, services.ErrInvalidFileType):
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusBadRequest,
+				"INVALID_FILE_TYPE",
+				fmt.Sprintf("Invalid file type: %s", fileType),
+				map[string]interface{}{
+					"type":     fileType,
+					"filepath": decodedPath,
+				},
+			))
+		case errors.Is(err, services.ErrInvalidFilePath):
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusBadRequest,
+				"INVALID_PATH",
+				"Invalid file path",
+				map[string]interface{}{
+					"type":     fileType,
+					"filepath": decodedPath,
+				},
+			))
+		default:
+			h.errorHandler.HandleError(w, r, err)
+		}
+	}
+}
+
 // GetSafeTrading returns safe trading limits for a ticker
 func (h *DataHandler) GetSafeTrading(w http.ResponseWriter, r *http.Request) {
 	reqID := middleware.GetReqID(r.Context())
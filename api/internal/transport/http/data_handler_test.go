@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -13,6 +14,8 @@ import (
 	"log/slog"
 	"os"
 
+	"isxcli/internal/currency"
+	"isxcli/internal/dataprocessing/analytics"
 	apierrors "isxcli/internal/errors"
 	"isxcli/internal/services"
 )
@@ -70,11 +73,64 @@ func (m *MockDataService) GetTickerChart(ctx context.Context, ticker string) (ma
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockDataService) GetTickerIntraday(ctx context.Context, ticker, date string) (map[string]interface{}, error) {
+	args := m.Called(ticker, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (m *MockDataService) GetTickerHistory(ctx context.Context, q services.TickerHistoryQuery) (*services.TickerHistoryPage, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TickerHistoryPage), args.Error(1)
+}
+
+func (m *MockDataService) GetTickerBars(ctx context.Context, symbol string, interval analytics.Interval, from, to time.Time) ([]analytics.Bar, error) {
+	args := m.Called(symbol, interval, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]analytics.Bar), args.Error(1)
+}
+
+func (m *MockDataService) GetTickerSummaries(ctx context.Context) ([]services.TickerSummary, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]services.TickerSummary), args.Error(1)
+}
+
+func (m *MockDataService) GetMarketDiff(ctx context.Context, from, to time.Time, cur currency.Code) (*services.MarketDiff, error) {
+	args := m.Called(from, to, cur)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.MarketDiff), args.Error(1)
+}
+
+func (m *MockDataService) GetMarketSnapshot(ctx context.Context, date, symbol string) (map[string]interface{}, error) {
+	args := m.Called(date, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
 func (m *MockDataService) DownloadFile(ctx context.Context, w http.ResponseWriter, r *http.Request, fileType, filename string) error {
 	args := m.Called(w, r, fileType, filename)
 	return args.Error(0)
 }
 
+func (m *MockDataService) StreamFile(ctx context.Context, w http.ResponseWriter, r *http.Request, fileType, filename string) error {
+	args := m.Called(w, r, fileType, filename)
+	return args.Error(0)
+}
+
 func TestDataHandler_GetReports(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -388,6 +444,30 @@ func TestDataHandler_TickerCtx(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Invalid ticker symbol format",
 		},
+		{
+			name:           "lower-case ticker",
+			ticker:         "bbob",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "OK",
+		},
+		{
+			name:           "parent directory",
+			ticker:         "..",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid ticker symbol format",
+		},
+		{
+			name:           "encoded path separator",
+			ticker:         "BB%2F..",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid ticker symbol format",
+		},
+		{
+			name:           "punctuation",
+			ticker:         "BB.OB",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid ticker symbol format",
+		},
 	}
 
 	for _, tt := range tests {
@@ -3,6 +3,11 @@ package http
 import (
 	"context"
 	"net/http"
+	"time"
+
+	"isxcli/internal/currency"
+	"isxcli/internal/dataprocessing/analytics"
+	"isxcli/internal/services"
 )
 
 // DataServiceInterface defines the interface for data operations
@@ -13,7 +18,14 @@ type DataServiceInterface interface {
 	GetFiles(ctx context.Context) (map[string]interface{}, error)
 	GetMarketMovers(ctx context.Context, period, limit, minVolume string) (map[string]interface{}, error)
 	GetTickerChart(ctx context.Context, ticker string) (map[string]interface{}, error)
+	GetTickerIntraday(ctx context.Context, ticker, date string) (map[string]interface{}, error)
+	GetMarketSnapshot(ctx context.Context, date, symbol string) (map[string]interface{}, error)
+	GetTickerHistory(ctx context.Context, q services.TickerHistoryQuery) (*services.TickerHistoryPage, error)
+	GetTickerBars(ctx context.Context, symbol string, interval analytics.Interval, from, to time.Time) ([]analytics.Bar, error)
+	GetTickerSummaries(ctx context.Context) ([]services.TickerSummary, error)
+	GetMarketDiff(ctx context.Context, from, to time.Time, cur currency.Code) (*services.MarketDiff, error)
 	DownloadFile(ctx context.Context, w http.ResponseWriter, r *http.Request, fileType, filename string) error
+	StreamFile(ctx context.Context, w http.ResponseWriter, r *http.Request, fileType, filename string) error
 	
 	// Safe trading methods
 	GetSafeTradingLimits(ctx context.Context, ticker string) (interface{}, error)
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"isxcli/internal/services"
+)
+
+// DoctorHandler serves the pre-flight environment checks
+type DoctorHandler struct {
+	doctor *services.Doctor
+	logger *slog.Logger
+}
+
+// NewDoctorHandler creates a new doctor handler
+func NewDoctorHandler(doctor *services.Doctor, logger *slog.Logger) *DoctorHandler {
+	return &DoctorHandler{
+		doctor: doctor,
+		logger: logger.With(slog.String("handler", "doctor")),
+	}
+}
+
+// Run handles GET /api/v1/system/doctor: Chrome, data directory
+// permissions, connectivity, clock skew, disk space and sibling executables,
+// each with a fix when it does not pass. The response is 200 whatever the
+// outcome; callers read data.status.
+func (h *DoctorHandler) Run(w http.ResponseWriter, r *http.Request) {
+	report := h.doctor.Run(r.Context())
+	if report.Status != services.DoctorPass {
+		h.logger.WarnContext(r.Context(), "pre-flight checks did not pass",
+			slog.String("status", string(report.Status)),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	})
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"isxcli/internal/currency"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/exporter"
+	"isxcli/internal/i18n"
+	"isxcli/internal/services"
+)
+
+// ExportBundleService plans ZIP bundles of reports
+type ExportBundleService interface {
+	PlanExportBundle(ctx context.Context, spec exporter.BundleSpec) (*exporter.Bundle, error)
+}
+
+// ExportBundleHandler streams ZIP bundles of selected reports
+type ExportBundleHandler struct {
+	service      ExportBundleService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewExportBundleHandler creates a new export bundle handler
+func NewExportBundleHandler(service ExportBundleService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *ExportBundleHandler {
+	return &ExportBundleHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the export routes mounted at /api/v1/exports
+func (h *ExportBundleHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/bundle", h.Bundle)
+
+	return r
+}
+
+// bundleRequest is the body of POST /api/v1/exports/bundle
+type bundleRequest struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Tickers   []string `json:"tickers"`
+	Artifacts []string `json:"artifacts"`
+	Currency  string   `json:"currency"`
+	Language  string   `json:"language"`
+	Digits    string   `json:"digits"`
+}
+
+// Bundle handles POST /api/v1/exports/bundle. The body selects a date range
+// (YYYY-MM-DD, either side optional), tickers and artifacts (daily,
+// ticker_history, liquidity), the currency of monetary columns (IQD or USD,
+// defaulting to export.currency) and the language of column headers and the
+// digits of numbers (defaulting to export.language and export.digits; the
+// lang and digits query params override the body); the response is a ZIP
+// archive streamed as it is built.
+func (h *ExportBundleHandler) Bundle(w http.ResponseWriter, r *http.Request) {
+	var req bundleRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body"))
+		return
+	}
+
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		req.Language = lang
+	}
+	if digits := r.URL.Query().Get("digits"); digits != "" {
+		req.Digits = digits
+	}
+	spec := exporter.BundleSpec{
+		Tickers:  req.Tickers,
+		Currency: currency.Code(req.Currency),
+		Locale:   i18n.Locale{Language: i18n.Language(req.Language), Digits: i18n.Digits(req.Digits)},
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *time.Time
+	}{{"from", req.From, &spec.From}, {"to", req.To, &spec.To}} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", field.value)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation(field.name, "Must be a date in YYYY-MM-DD format"))
+			return
+		}
+		*field.dest = d
+	}
+	for _, artifact := range req.Artifacts {
+		spec.Artifacts = append(spec.Artifacts, exporter.BundleArtifact(artifact))
+	}
+
+	bundle, err := h.service.PlanExportBundle(r.Context(), spec)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, bundleFilename(spec)))
+	w.Header().Set("X-Bundle-Files", fmt.Sprint(len(bundle.Entries)))
+	w.WriteHeader(http.StatusOK)
+
+	// Headers are sent, so a failure can only be logged; the client sees a
+	// truncated archive
+	if err := bundle.WriteZip(r.Context(), w); err != nil {
+		h.logger.ErrorContext(r.Context(), "export bundle failed while streaming",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+	}
+}
+
+// bundleFilename names the archive after its date range
+func bundleFilename(spec exporter.BundleSpec) string {
+	from, to := "start", "latest"
+	if !spec.From.IsZero() {
+		from = spec.From.Format("20060102")
+	}
+	if !spec.To.IsZero() {
+		to = spec.To.Format("20060102")
+	}
+	return fmt.Sprintf("isx_bundle_%s_%s.zip", from, to)
+}
+
+// handleError maps bundle errors to RFC 7807 responses
+func (h *ExportBundleHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrEmptyBundle):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusNotFound,
+			"BUNDLE_EMPTY",
+			"No reports match the selected range, tickers and artifacts",
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "export bundle request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
@@ -41,6 +41,32 @@ func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, h.service.Version())
 }
 
+// SystemVersion handles GET /api/v1/system/version: the version, update
+// channel, build hash and embedded frontend version
+func (h *HealthHandler) SystemVersion(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   h.service.Version(),
+	})
+}
+
+// DetailedHealth handles GET /api/v1/health/detailed: data freshness, the last
+// success of each pipeline stage, license and disk checks with severities.
+// The response is 200 whatever the severity; monitors read data.status.
+func (h *HealthHandler) DetailedHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   h.service.DetailedHealthCheck(r.Context()),
+	})
+}
+
+// PublicStatus handles GET /status.json for uptime monitors (no authentication)
+func (h *HealthHandler) PublicStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, h.service.PublicStatus(r.Context()))
+}
+
 // LicenseStatus handles GET /api/license/status
 func (h *HealthHandler) LicenseStatus(w http.ResponseWriter, r *http.Request) {
 	status, err := h.service.LicenseStatus(r.Context())
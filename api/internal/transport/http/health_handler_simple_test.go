@@ -306,4 +306,30 @@ func TestHealthHandler_TimingAndUptime(t *testing.T) {
 		assert.True(t, ok, "uptime should be a float64")
 		assert.Greater(t, uptime, 0.0, "uptime should be greater than 0")
 	})
-}
\ No newline at end of file
+}
+func TestHealthHandler_SystemVersion(t *testing.T) {
+	slogLogger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	licenseManager, _ := license.NewManager("")
+	webSocketHub := ws.NewHub(slogLogger)
+	OperationManager := operations.NewManager(&wsHubAdapter{hub: webSocketHub}, operations.NewRegistry(), operations.NewConfig())
+
+	healthService := services.NewHealthService("v1.0.0-test", "https://github.com/example/repo",
+		config.PathsConfig{DataDir: t.TempDir()}, licenseManager, OperationManager, webSocketHub, slogLogger)
+	healthService.SetReleaseInfo(services.ReleaseInfo{Channel: "beta", BuildHash: "0123456789ab", FrontendVersion: "abcXYZ"})
+	handler := NewHealthHandler(healthService, slogLogger)
+
+	rec := httptest.NewRecorder()
+	handler.SystemVersion(rec, httptest.NewRequest("GET", "/api/v1/system/version", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response struct {
+		Status string                 `json:"status"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, "v1.0.0-test", response.Data["version"])
+	assert.Equal(t, "beta", response.Data["channel"])
+	assert.Equal(t, "0123456789ab", response.Data["build_hash"])
+	assert.Equal(t, "abcXYZ", response.Data["frontend_version"])
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// IndexHandler handles market and sector index requests
+type IndexHandler struct {
+	service      *services.IndexService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewIndexHandler creates a new index handler
+func NewIndexHandler(service *services.IndexService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *IndexHandler {
+	return &IndexHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the index routes mounted at /api/v1/indices
+func (h *IndexHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Get("/analytics", h.Analytics)
+	r.Get("/{name}", h.Series)
+
+	return r
+}
+
+// List handles GET /api/v1/indices
+func (h *IndexHandler) List(w http.ResponseWriter, r *http.Request) {
+	indices, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   indices,
+		"count":  len(indices),
+	})
+}
+
+// Series handles GET /api/v1/indices/{name}. Query params: from and to
+// (YYYY-MM-DD).
+func (h *IndexHandler) Series(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := h.dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	series, err := h.service.Series(r.Context(), chi.URLParam(r, "name"), from, to)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   series,
+		"count":  len(series.Points),
+	})
+}
+
+// Analytics handles GET /api/v1/indices/analytics. Query params: index
+// (ISX60 or ISX15, both when empty), from and to (YYYY-MM-DD).
+func (h *IndexHandler) Analytics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := h.dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.service.Analytics(r.Context(), r.URL.Query().Get("index"), from, to)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   analytics,
+		"count":  len(analytics),
+	})
+}
+
+// dateRange parses the optional from and to query params, writing a
+// validation error and returning false when either is malformed
+func (h *IndexHandler) dateRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("from", "From must be a date in YYYY-MM-DD format"))
+			return from, to, false
+		}
+		from = d
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("to", "To must be a date in YYYY-MM-DD format"))
+			return from, to, false
+		}
+		to = d
+	}
+	return from, to, true
+}
+
+// handleError maps index service errors to RFC 7807 responses
+func (h *IndexHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrIndexNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"INDEX_NOT_FOUND",
+			"Index not found",
+			map[string]interface{}{"name": indexName(r)},
+		))
+	case errors.Is(err, services.ErrIndexMetricsNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusNotFound,
+			"INDEX_ANALYTICS_NOT_FOUND",
+			"Index analytics not available; run the indices step first",
+		))
+	case errors.Is(err, services.ErrNoIndicesFound):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusNotFound,
+			"NO_INDICES_FOUND",
+			"No indices available",
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "index request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
+
+// indexName returns the index a request names, in the path or the index query param
+func indexName(r *http.Request) string {
+	if name := chi.URLParam(r, "name"); name != "" {
+		return name
+	}
+	return r.URL.Query().Get("index")
+}
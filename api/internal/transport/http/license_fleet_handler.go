@@ -0,0 +1,156 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// LicenseFleetHandler handles central license activation for many devices
+type LicenseFleetHandler struct {
+	service      *services.LicenseFleetService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewLicenseFleetHandler creates a new license fleet handler
+func NewLicenseFleetHandler(service *services.LicenseFleetService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *LicenseFleetHandler {
+	return &LicenseFleetHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the fleet routes mounted at /api/v1/license/fleet
+func (h *LicenseFleetHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.Overview)
+	r.Post("/activate", h.Activate)
+	r.Get("/keys", h.Keys)
+	r.Get("/device", h.LocalDevice)
+	r.Get("/devices/{fingerprint}", h.Device)
+
+	return r
+}
+
+// Activate handles POST /api/v1/license/fleet/activate, activating a batch
+// of devices from a pool of scratch-card keys
+func (h *LicenseFleetHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req services.FleetActivationInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	result, err := h.service.Activate(ctx, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "fleet activation request completed",
+		slog.Int("activated", result.Activated),
+		slog.Int("failed", result.Failed),
+		slog.Int("pending", result.Pending),
+		slog.String("request_id", middleware.GetReqID(ctx)))
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+// Overview handles GET /api/v1/license/fleet, listing every managed device
+// with fleet totals
+func (h *LicenseFleetHandler) Overview(w http.ResponseWriter, r *http.Request) {
+	overview, err := h.service.Overview(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   overview,
+	})
+}
+
+// Device handles GET /api/v1/license/fleet/devices/{fingerprint}
+func (h *LicenseFleetHandler) Device(w http.ResponseWriter, r *http.Request) {
+	device, err := h.service.Device(r.Context(), chi.URLParam(r, "fingerprint"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   device,
+	})
+}
+
+// LocalDevice handles GET /api/v1/license/fleet/device, returning this
+// machine's entry for another installation's activation request
+func (h *LicenseFleetHandler) LocalDevice(w http.ResponseWriter, r *http.Request) {
+	device, err := h.service.LocalDevice(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   device,
+	})
+}
+
+// Keys handles GET /api/v1/license/fleet/keys, listing the key pool masked
+func (h *LicenseFleetHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.Keys(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   keys,
+		"count":  len(keys),
+	})
+}
+
+func (h *LicenseFleetHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrFleetDeviceNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"FLEET_DEVICE_NOT_FOUND",
+			"No fleet activation has included this device",
+			map[string]interface{}{"fingerprint": chi.URLParam(r, "fingerprint")},
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "license fleet request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
@@ -1,19 +1,28 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 
+	"isxcli/internal/currency"
 	apierrors "isxcli/internal/errors"
+	"isxcli/internal/liquidity"
 	"isxcli/internal/services"
 )
 
 // LiquidityHandler handles liquidity-related HTTP requests
 type LiquidityHandler struct {
 	service      *services.LiquidityService
+	companies    *services.CompanyService
 	logger       *slog.Logger
 	errorHandler *apierrors.ErrorHandler
 }
@@ -27,6 +36,12 @@ func NewLiquidityHandler(service *services.LiquidityService, logger *slog.Logger
 	}
 }
 
+// WithCompanies enriches insights with sector and market cap from company profiles
+func (h *LiquidityHandler) WithCompanies(companies *services.CompanyService) *LiquidityHandler {
+	h.companies = companies
+	return h
+}
+
 // RegisterRoutes registers the liquidity routes
 func (h *LiquidityHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/liquidity", func(r chi.Router) {
@@ -34,6 +49,19 @@ func (h *LiquidityHandler) RegisterRoutes(r chi.Router) {
 	})
 }
 
+// HistoryRoutes returns the versioned liquidity routes, mounted at
+// /api/v1/liquidity
+func (h *LiquidityHandler) HistoryRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+	r.Post("/universe", h.ScoreUniverse)
+	r.Get("/league", h.GetLeagueTable)
+	r.Post("/impact-simulation", h.SimulateImpact)
+	r.Get("/{symbol}/history", h.GetHistory)
+	r.Get("/{symbol}/safe-trade", h.GetSafeTrade)
+	return r
+}
+
 // GetInsights returns the latest liquidity insights
 func (h *LiquidityHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -63,8 +91,17 @@ func (h *LiquidityHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	filter, apiErr := parseLiquidityFilter(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	
 	h.logger.InfoContext(ctx, "Getting liquidity insights",
-		slog.String("mode", mode))
+		slog.String("mode", mode),
+		slog.Float64("min_coverage", filter.MinCoverage),
+		slog.Int("min_trading_days", filter.MinTradingDays),
+		slog.Any("exclude_regimes", filter.ExcludeRegimes))
 	
 	insights, err := h.service.GetLatestInsights(ctx)
 	if err != nil {
@@ -79,6 +116,13 @@ func (h *LiquidityHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Apply quality filters server-side; criteria are echoed in the response
+	services.ApplyFilter(insights, filter)
+	
+	if h.companies != nil {
+		h.companies.EnrichInsights(ctx, insights)
+	}
+	
 	// Add mode to response header for client reference
 	w.Header().Set("X-Liquidity-Mode", mode)
 	
@@ -91,4 +135,428 @@ func (h *LiquidityHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 	
 	// Success response
 	render.JSON(w, r, insights)
-}
\ No newline at end of file
+}
+
+// parseLiquidityFilter reads the optional data quality filters:
+// minCoverage (percent), minTradingDays and excludeRegime (comma-separated)
+func parseLiquidityFilter(r *http.Request) (services.LiquidityFilter, *apierrors.APIError) {
+	var filter services.LiquidityFilter
+	query := r.URL.Query()
+	
+	if v := query.Get("minCoverage"); v != "" {
+		coverage, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, apierrors.ErrValidation("minCoverage", "Min coverage must be a number between 0 and 100")
+		}
+		filter.MinCoverage = coverage
+	}
+	
+	if v := query.Get("minTradingDays"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, apierrors.ErrValidation("minTradingDays", "Min trading days must be a non-negative integer")
+		}
+		filter.MinTradingDays = days
+	}
+	
+	for _, v := range query["excludeRegime"] {
+		for _, regime := range strings.Split(v, ",") {
+			if regime = strings.ToLower(strings.TrimSpace(regime)); regime != "" {
+				filter.ExcludeRegimes = append(filter.ExcludeRegimes, regime)
+			}
+		}
+	}
+	
+	if err := filter.Validate(); err != nil {
+		field := "excludeRegime"
+		switch {
+		case filter.MinCoverage < 0 || filter.MinCoverage > 100:
+			field = "minCoverage"
+		case filter.MinTradingDays < 0:
+			field = "minTradingDays"
+		}
+		return filter, apierrors.ErrValidation(field, err.Error())
+	}
+	
+	return filter, nil
+}
+
+// GetHistory returns how a ticker's liquidity metrics evolved per window, with
+// a trend flag over the last trendWindows points.
+// Query params: window (20d, 60d, 120d), limit, trendWindows, trendThreshold.
+func (h *LiquidityHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+
+	query, apiErr := parseLiquidityHistoryQuery(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	query.Symbol = symbol
+
+	history, err := h.service.GetHistory(ctx, query)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to get liquidity history",
+			slog.String("symbol", symbol),
+			slog.String("error", err.Error()))
+
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusInternalServerError,
+			"LIQUIDITY_ERROR",
+			"Failed to retrieve liquidity history",
+		))
+		return
+	}
+
+	if len(history.Windows) == 0 {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"LIQUIDITY_HISTORY_NOT_FOUND",
+			"No liquidity history recorded for ticker",
+			map[string]interface{}{"symbol": symbol},
+		))
+		return
+	}
+
+	render.JSON(w, r, history)
+}
+
+// maxSafeTradeImpact bounds the impact threshold of a safe-trade request, in percent
+const maxSafeTradeImpact = 20.0
+
+// GetSafeTrade handles GET /api/v1/liquidity/{symbol}/safe-trade. It returns
+// the largest daily trade value within the impact threshold (impact, percent,
+// default 1.0) and, for a position size (position), the trading days needed
+// to exit it. currency=USD takes the position and returns the sizes in US
+// dollars.
+func (h *LiquidityHandler) GetSafeTrade(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := services.SafeTradeQuery{
+		Symbol:        strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol"))),
+		ImpactPercent: 1.0,
+	}
+
+	params := r.URL.Query()
+	if v := params.Get("impact"); v != "" {
+		impact, err := strconv.ParseFloat(v, 64)
+		if err != nil || impact <= 0 || impact > maxSafeTradeImpact {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("impact", "Impact must be a percentage above 0 and at most 20"))
+			return
+		}
+		query.ImpactPercent = impact
+	}
+	if v := params.Get("position"); v != "" {
+		position, err := strconv.ParseFloat(v, 64)
+		if err != nil || position < 0 {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("position", "Position must be a non-negative value"))
+			return
+		}
+		query.PositionValue = position
+	}
+	cur, apiErr := parseCurrency(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	query.Currency = cur
+	if v := params.Get("window"); v != "" {
+		window, err := liquidity.ParseWindow(v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("window", "Window must be one of 20d, 60d or 120d"))
+			return
+		}
+		query.Window = window
+	}
+
+	trade, err := h.service.GetSafeTrade(ctx, query)
+	switch {
+	case errors.Is(err, services.ErrNoLiquidityData):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"LIQUIDITY_HISTORY_NOT_FOUND",
+			"No liquidity history recorded for ticker",
+			map[string]interface{}{"symbol": query.Symbol},
+		))
+		return
+	case err != nil:
+		h.logger.ErrorContext(ctx, "Failed to estimate safe trade",
+			slog.String("request_id", middleware.GetReqID(ctx)),
+			slog.String("symbol", query.Symbol),
+			slog.String("error", err.Error()))
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusInternalServerError,
+			"LIQUIDITY_ERROR",
+			"Failed to estimate safe trade",
+		))
+		return
+	}
+
+	render.JSON(w, r, trade)
+}
+
+// parseLiquidityHistoryQuery reads the optional history query parameters
+func parseLiquidityHistoryQuery(r *http.Request) (services.LiquidityHistoryQuery, *apierrors.APIError) {
+	var q services.LiquidityHistoryQuery
+	query := r.URL.Query()
+
+	if v := query.Get("window"); v != "" {
+		window, err := liquidity.ParseWindow(v)
+		if err != nil {
+			return q, apierrors.ErrValidation("window", "Window must be one of 20d, 60d or 120d")
+		}
+		q.Window = window
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return q, apierrors.ErrValidation("limit", "Limit must be a non-negative integer")
+		}
+		q.Limit = limit
+	}
+
+	if v := query.Get("trendWindows"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < liquidity.MinTrendPoints {
+			return q, apierrors.ErrValidation("trendWindows", "Trend windows must be an integer of at least 3")
+		}
+		q.TrendWindows = n
+	}
+
+	if v := query.Get("trendThreshold"); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil || threshold <= 0 {
+			return q, apierrors.ErrValidation("trendThreshold", "Trend threshold must be a positive number")
+		}
+		q.TrendThreshold = threshold
+	}
+
+	return q, nil
+}
+
+// GetLeagueTable handles GET /api/v1/liquidity/league: every ticker ranked by
+// hybrid score on a date (date, YYYY-MM-DD, default latest) and window
+// (window, default 60d), grouped into tiers=quintile (default) or decile
+func (h *LiquidityHandler) GetLeagueTable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := r.URL.Query()
+
+	var req services.LiquidityLeagueRequest
+	if v := params.Get("date"); v != "" {
+		date, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+		req.Date = date
+	}
+	if v := params.Get("window"); v != "" {
+		window, err := liquidity.ParseWindow(v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("window", "Window must be one of 20d, 60d or 120d"))
+			return
+		}
+		req.Window = window
+	}
+	tiers, err := liquidity.ParseTiers(params.Get("tiers"))
+	if err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("tiers", "Tiers must be quintile or decile"))
+		return
+	}
+	req.Tiers = tiers
+
+	table, err := h.service.LeagueTable(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrNoLiquidityData):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"LIQUIDITY_DATA_NOT_FOUND",
+			"No liquidity history recorded for the date and window",
+			map[string]interface{}{"date": params.Get("date"), "window": params.Get("window")},
+		))
+		return
+	case err != nil:
+		h.logger.ErrorContext(ctx, "Failed to build liquidity league table",
+			slog.String("request_id", middleware.GetReqID(ctx)),
+			slog.String("error", err.Error()))
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusInternalServerError,
+			"LIQUIDITY_ERROR",
+			"Failed to build liquidity league table",
+		))
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   table,
+		"count":  table.Count,
+	})
+}
+
+// liquidityUniverseRequest is the body of POST /api/v1/liquidity/universe
+type liquidityUniverseRequest struct {
+	Symbols []string `json:"symbols"`
+	Date    string   `json:"date"`   // YYYY-MM-DD; latest when empty
+	Window  string   `json:"window"` // 20d, 60d or 120d; 60d when empty
+}
+
+// ScoreUniverse returns liquidity scores rescaled within an explicit ticker
+// universe on one date, next to each ticker's whole-market score and rank
+func (h *LiquidityHandler) ScoreUniverse(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var body liquidityUniverseRequest
+	if err := render.DecodeJSON(r.Body, &body); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusBadRequest,
+			"INVALID_REQUEST",
+			"Invalid request body",
+			map[string]interface{}{"error": err.Error()},
+		))
+		return
+	}
+
+	req := services.LiquidityUniverseRequest{Symbols: body.Symbols}
+	if body.Date != "" {
+		date, err := time.Parse("2006-01-02", body.Date)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+		req.Date = date
+	}
+	if body.Window != "" {
+		window, err := liquidity.ParseWindow(body.Window)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("window", "Window must be one of 20d, 60d or 120d"))
+			return
+		}
+		req.Window = window
+	}
+
+	snapshot, err := h.service.ScoreUniverse(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("symbols",
+			strings.TrimPrefix(err.Error(), services.ErrInvalidInput.Error()+": ")))
+		return
+	case errors.Is(err, services.ErrNoLiquidityData):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"LIQUIDITY_DATA_NOT_FOUND",
+			"No liquidity history recorded for the date and window",
+			map[string]interface{}{"date": body.Date, "window": body.Window},
+		))
+		return
+	case err != nil:
+		h.logger.ErrorContext(ctx, "Failed to score liquidity universe",
+			slog.String("request_id", middleware.GetReqID(ctx)),
+			slog.Int("symbols", len(body.Symbols)),
+			slog.String("error", err.Error()))
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusInternalServerError,
+			"LIQUIDITY_ERROR",
+			"Failed to score liquidity universe",
+		))
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   snapshot,
+		"count":  len(snapshot.Scores),
+	})
+}
+
+// impactSimulationRequest is the body of POST /api/v1/liquidity/impact-simulation
+type impactSimulationRequest struct {
+	Symbol      string  `json:"symbol"`
+	TradeValue  float64 `json:"trade_value"`
+	HorizonDays int     `json:"horizon_days"`
+	Window      string  `json:"window"`   // 20d, 60d or 120d; 60d when empty
+	Currency    string  `json:"currency"` // IQD or USD; IQD when empty
+}
+
+// SimulateImpact handles POST /api/v1/liquidity/impact-simulation. It
+// estimates the price impact of trading trade_value in a ticker and the
+// day-by-day schedule that spreads it over at most horizon_days trading days.
+func (h *LiquidityHandler) SimulateImpact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var body impactSimulationRequest
+	if err := render.DecodeJSON(r.Body, &body); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusBadRequest,
+			"INVALID_REQUEST",
+			"Invalid request body",
+			map[string]interface{}{"error": err.Error()},
+		))
+		return
+	}
+
+	req := services.ImpactSimulationRequest{
+		Symbol:      strings.ToUpper(strings.TrimSpace(body.Symbol)),
+		TradeValue:  body.TradeValue,
+		HorizonDays: body.HorizonDays,
+	}
+	switch {
+	case req.Symbol == "":
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("symbol", "Symbol is required"))
+		return
+	case req.TradeValue <= 0:
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("trade_value", "Trade value must be positive"))
+		return
+	case req.HorizonDays <= 0 || req.HorizonDays > services.MaxImpactHorizonDays:
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("horizon_days",
+			fmt.Sprintf("Horizon must be between 1 and %d trading days", services.MaxImpactHorizonDays)))
+		return
+	}
+	if body.Window != "" {
+		window, err := liquidity.ParseWindow(body.Window)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("window", "Window must be one of 20d, 60d or 120d"))
+			return
+		}
+		req.Window = window
+	}
+	cur, err := currency.ParseCode(body.Currency)
+	if err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("currency", "Currency must be IQD or USD"))
+		return
+	}
+	req.Currency = cur
+
+	plan, err := h.service.SimulateImpact(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body",
+			strings.TrimPrefix(err.Error(), services.ErrInvalidInput.Error()+": ")))
+		return
+	case errors.Is(err, services.ErrNoLiquidityData):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"LIQUIDITY_HISTORY_NOT_FOUND",
+			"No liquidity history recorded for ticker",
+			map[string]interface{}{"symbol": req.Symbol},
+		))
+		return
+	case err != nil:
+		h.logger.ErrorContext(ctx, "Failed to simulate trade impact",
+			slog.String("request_id", middleware.GetReqID(ctx)),
+			slog.String("symbol", req.Symbol),
+			slog.String("error", err.Error()))
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusInternalServerError,
+			"LIQUIDITY_ERROR",
+			"Failed to simulate trade impact",
+		))
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   plan,
+	})
+}
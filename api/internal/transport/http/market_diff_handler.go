@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// GetMarketDiff handles GET /api/v1/market/diff, what changed between two
+// trading days ticker by ticker. Query params: from and to (YYYY-MM-DD);
+// without to the latest day is compared, without from the day before to.
+// currency=USD reports prices and values in US dollars.
+func (h *DataHandler) GetMarketDiff(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var from, to time.Time
+	if v := query.Get("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("from", "From must be a date in YYYY-MM-DD format"))
+			return
+		}
+		from = parsed
+	}
+	if v := query.Get("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("to", "To must be a date in YYYY-MM-DD format"))
+			return
+		}
+		to = parsed
+	}
+	cur, apiErr := parseCurrency(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+
+	diff, err := h.service.GetMarketDiff(r.Context(), from, to, cur)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInput):
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("to", err.Error()))
+		case errors.Is(err, services.ErrNoReportsFound):
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusNotFound,
+				"NO_DAILY_REPORT",
+				"No daily report available to compare",
+				map[string]interface{}{
+					"from":  query.Get("from"),
+					"to":    query.Get("to"),
+					"error": err.Error(),
+				},
+			))
+		default:
+			h.logger.ErrorContext(r.Context(), "failed to get market diff",
+				slog.String("error", err.Error()),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("from", query.Get("from")),
+				slog.String("to", query.Get("to")))
+			h.errorHandler.HandleError(w, r, err)
+		}
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   diff,
+	})
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// NotificationHandler handles notification requests
+type NotificationHandler struct {
+	service      *services.NotificationService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(service *services.NotificationService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *NotificationHandler {
+	return &NotificationHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the notification routes mounted at /api/v1/notifications
+func (h *NotificationHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Post("/test", h.SendTest)
+
+	return r
+}
+
+// SendTest handles POST /api/v1/notifications/test, sending a test message
+// over every configured channel
+func (h *NotificationHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	results, err := h.service.SendTest(ctx)
+	if errors.Is(err, services.ErrNotificationsDisabled) {
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusConflict,
+			"NOTIFICATIONS_NOT_CONFIGURED",
+			"No notification channel is configured. Set SMTP or Telegram settings under notifications in the config.",
+		))
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "test notification failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(ctx)))
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	sent := 0
+	for _, result := range results {
+		if result.Sent {
+			sent++
+		}
+	}
+	if sent == 0 {
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusBadGateway,
+			"NOTIFICATION_FAILED",
+			"The test notification could not be sent",
+			map[string]interface{}{"results": results},
+		))
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   results,
+		"count":  sent,
+	})
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	licenseErrors "isxcli/internal/errors"
+	"isxcli/internal/middleware"
+	"isxcli/internal/websocket"
+)
+
+const (
+	// sseKeepAlive is how often an idle stream sends a comment so proxies
+	// and the browser do not close it
+	sseKeepAlive = 15 * time.Second
+	// sseWriteTimeout bounds each write to a client that stopped reading
+	sseWriteTimeout = 30 * time.Second
+	// sseRetry is the reconnect delay suggested to EventSource clients
+	sseRetry = 3 * time.Second
+	// sseDeadlineMargin ends the stream before the request timeout fires, so
+	// the client reconnects with Last-Event-ID instead of getting a 504
+	sseDeadlineMargin = 5 * time.Second
+)
+
+// EventSource is the log of hub broadcasts the SSE endpoint follows
+type EventSource interface {
+	Since(lastID uint64, operationID string) []websocket.Event
+	Subscribe() (<-chan struct{}, func())
+}
+
+// SetEventSource enables GET /api/v1/operations/{id}/events
+func (h *OperationsHandler) SetEventSource(events EventSource) {
+	h.events = events
+}
+
+// StreamOperationEvents handles GET /api/v1/operations/{id}/events. It streams
+// the operation's hub broadcasts as server-sent events, each with the hub's
+// event ID, so a reconnecting EventSource resumes after Last-Event-ID (or the
+// last_event_id query parameter). The stream ends after the snapshot that
+// reports the operation's final status.
+func (h *OperationsHandler) StreamOperationEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	operationID := chi.URLParam(r, "id")
+	reqID := middleware.GetReqID(ctx)
+
+	if h.events == nil {
+		render.Render(w, r, licenseErrors.NewProblemDetails(
+			http.StatusServiceUnavailable,
+			"/errors/service_unavailable",
+			"service_unavailable",
+			"Operation event streaming is not available",
+			r.URL.Path+"#"+reqID,
+		))
+		return
+	}
+
+	lastID, err := lastEventID(r)
+	if err != nil {
+		render.Render(w, r, licenseErrors.NewProblemDetails(
+			http.StatusBadRequest,
+			"/errors/validation",
+			"validation_error",
+			"Last-Event-ID must be a non-negative integer",
+			r.URL.Path+"#"+reqID,
+		))
+		return
+	}
+
+	// Subscribe before reading the backlog so nothing appended in between is missed
+	wake, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	backlog := h.events.Since(lastID, operationID)
+	if len(backlog) == 0 {
+		statusCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := h.service.GetOperationStatus(statusCtx, operationID)
+		cancel()
+		if err != nil {
+			h.handleError(w, r, err, map[string]interface{}{
+				"operation_id": operationID,
+			})
+			return
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-sseDeadlineMargin))
+		defer cancel()
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	h.logger.InfoContext(ctx, "operation event stream opened",
+		slog.String("operation_id", operationID),
+		slog.Uint64("last_event_id", lastID),
+		slog.String("request_id", reqID))
+
+	write := func(p []byte) error {
+		// ErrNotSupported (e.g. httptest recorders) just leaves the server default in place
+		_ = rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := write([]byte(fmt.Sprintf("retry: %d\n\n", sseRetry.Milliseconds()))); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, event := range backlog {
+			if err := write(formatSSE(event)); err != nil {
+				h.logger.DebugContext(ctx, "operation event stream write failed",
+					slog.String("operation_id", operationID),
+					slog.String("error", err.Error()))
+				return
+			}
+			lastID = event.ID
+			if event.Terminal {
+				h.logger.InfoContext(ctx, "operation event stream finished",
+					slog.String("operation_id", operationID),
+					slog.Uint64("last_event_id", lastID))
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-keepAlive.C:
+			if err := write([]byte(": keepalive\n\n")); err != nil {
+				return
+			}
+		}
+		backlog = h.events.Since(lastID, operationID)
+	}
+}
+
+// lastEventID reads the resume point from the Last-Event-ID header that
+// EventSource sends on reconnect, falling back to the last_event_id query
+// parameter for the first connection
+func lastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// formatSSE encodes an event in the text/event-stream format. The event name
+// is the hub message type and the data is the message envelope the WebSocket
+// sends, so clients can share one message handler for both transports.
+func formatSSE(event websocket.Event) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "id: %d\n", event.ID)
+	if event.Type != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event.Type)
+	}
+	for _, line := range bytes.Split(event.Data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"isxcli/internal/operations"
+	"isxcli/internal/websocket"
+)
+
+func snapshotEvent(operationID, status string) []byte {
+	return []byte(fmt.Sprintf(`{"type":"operation:snapshot","data":{"operation_id":%q,"status":%q}}`, operationID, status))
+}
+
+func setupEventsRouter(t *testing.T) (chi.Router, *websocket.EventLog, *MockOperationsService) {
+	handler, service, _ := setupOperationsHandler(t)
+	events := websocket.NewEventLog(16)
+	handler.SetEventSource(events)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/operations/{id}/events", handler.StreamOperationEvents)
+	return r, events, service
+}
+
+func TestStreamOperationEvents_ReplaysUntilFinalStatus(t *testing.T) {
+	router, events, _ := setupEventsRouter(t)
+	events.Append(snapshotEvent("op-1", "running"))
+	events.Append(snapshotEvent("op-2", "running"))
+	events.Append(snapshotEvent("op-1", "completed"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/op-1/events", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "retry: 3000\n\n"))
+	assert.Contains(t, body, "id: 1\nevent: operation:snapshot\ndata: "+string(snapshotEvent("op-1", "running"))+"\n\n")
+	assert.Contains(t, body, "id: 3\n")
+	assert.NotContains(t, body, "op-2")
+}
+
+func TestStreamOperationEvents_ResumesAfterLastEventID(t *testing.T) {
+	router, events, _ := setupEventsRouter(t)
+	events.Append(snapshotEvent("op-1", "running"))
+	events.Append(snapshotEvent("op-1", "running"))
+	events.Append(snapshotEvent("op-1", "failed"))
+
+	t.Run("header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/op-1/events", nil)
+		req.Header.Set("Last-Event-ID", "2")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotContains(t, w.Body.String(), "id: 1\n")
+		assert.NotContains(t, w.Body.String(), "id: 2\n")
+		assert.Contains(t, w.Body.String(), "id: 3\n")
+	})
+
+	t.Run("query parameter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/op-1/events?last_event_id=1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotContains(t, w.Body.String(), "id: 1\n")
+		assert.Contains(t, w.Body.String(), "id: 2\n")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/op-1/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStreamOperationEvents_UnknownOperation(t *testing.T) {
+	router, _, service := setupEventsRouter(t)
+	service.On("GetOperationStatus", mock.Anything, "missing").Return(nil, operations.ErrOperationNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/missing/events", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestStreamOperationEvents_FollowsLiveEvents(t *testing.T) {
+	router, events, service := setupEventsRouter(t)
+	service.On("GetOperationStatus", mock.Anything, "op-1").Return(operations.NewOperationState("op-1"), nil)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/operations/op-1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	lines := make(chan string, 32)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	// The retry hint confirms the stream is open before events are broadcast
+	require.Equal(t, "retry: 3000", <-lines)
+	events.Append(snapshotEvent("op-1", "running"))
+	events.Append(snapshotEvent("op-1", "completed"))
+
+	var ids []string
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				assert.Equal(t, []string{"id: 1", "id: 2"}, ids)
+				return
+			}
+			if strings.HasPrefix(line, "id: ") {
+				ids = append(ids, line)
+			}
+		case <-timeout:
+			t.Fatalf("stream did not end after the final snapshot, saw %v", ids)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/operations"
+	"isxcli/internal/services"
+)
+
+// OperationTemplateHandler handles operation template requests
+type OperationTemplateHandler struct {
+	service      *services.OperationTemplateService
+	wsHub        Hub
+	jobQueue     *operations.JobQueue
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewOperationTemplateHandler creates a new operation template handler
+func NewOperationTemplateHandler(service *services.OperationTemplateService, wsHub Hub, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *OperationTemplateHandler {
+	return &OperationTemplateHandler{
+		service:      service,
+		wsHub:        wsHub,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// SetJobQueue sets the job queue that runs templates
+func (h *OperationTemplateHandler) SetJobQueue(jobQueue *operations.JobQueue) {
+	h.jobQueue = jobQueue
+}
+
+// Routes returns the template routes mounted at /api/v1/operation-templates
+func (h *OperationTemplateHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Route("/{name}", func(r chi.Router) {
+		r.Get("/", h.Get)
+		r.Put("/", h.Update)
+		r.Delete("/", h.Delete)
+		r.Post("/run", h.Run)
+	})
+
+	return r
+}
+
+// List handles GET /api/v1/operation-templates
+func (h *OperationTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   templates,
+		"count":  len(templates),
+	})
+}
+
+// Create handles POST /api/v1/operation-templates
+func (h *OperationTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req services.OperationTemplateInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	template, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   template,
+	})
+}
+
+// Get handles GET /api/v1/operation-templates/{name}
+func (h *OperationTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	template, err := h.service.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   template,
+	})
+}
+
+// Update handles PUT /api/v1/operation-templates/{name}
+func (h *OperationTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req services.OperationTemplateInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	template, err := h.service.Update(r.Context(), chi.URLParam(r, "name"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   template,
+	})
+}
+
+// Delete handles DELETE /api/v1/operation-templates/{name}
+func (h *OperationTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), chi.URLParam(r, "name")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Run handles POST /api/v1/operation-templates/{name}/run, queueing an
+// operation built from the template
+func (h *OperationTemplateHandler) Run(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := middleware.GetReqID(ctx)
+
+	if h.jobQueue == nil {
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusServiceUnavailable,
+			"SERVICE_UNAVAILABLE",
+			"Job queue service is not available",
+		))
+		return
+	}
+
+	operationID := reqID
+	if operationID == "" {
+		operationID = uuid.New().String()
+	}
+	request, err := h.service.Request(ctx, chi.URLParam(r, "name"), operationID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	template := request.Parameters["template"]
+
+	job := &operations.Job{
+		ID:          request.ID,
+		OperationID: request.ID,
+		StageID:     "full_pipeline",
+		StageName:   "Full Pipeline",
+		Status:      operations.JobStatusPending,
+		CreatedAt:   time.Now(),
+		Request:     request,
+		Metadata: map[string]interface{}{
+			"request_id": reqID,
+			"mode":       request.Mode,
+			"template":   template,
+		},
+	}
+	if step, ok := request.Parameters["step"].(string); ok && step != "full_pipeline" {
+		job.StageID = step
+		job.StageName = step
+	}
+
+	if err := h.jobQueue.Enqueue(job); err != nil {
+		h.logger.ErrorContext(ctx, "failed to enqueue template operation",
+			slog.String("job_id", job.ID),
+			slog.Any("template", template),
+			slog.String("error", err.Error()),
+			slog.String("request_id", reqID))
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusServiceUnavailable,
+			"QUEUE_FULL",
+			"Operation queue is full. Please try again later.",
+			map[string]interface{}{"operation_id": request.ID},
+		))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "template operation enqueued",
+		slog.String("job_id", job.ID),
+		slog.Any("template", template),
+		slog.String("request_id", reqID))
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastUpdate("operation_update", "queued", "pending", map[string]interface{}{
+			"job_id":       job.ID,
+			"operation_id": request.ID,
+			"template":     template,
+			"timestamp":    time.Now().UTC(),
+		})
+	}
+
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, map[string]interface{}{
+		"job_id":       job.ID,
+		"operation_id": request.ID,
+		"template":     template,
+		"status":       "pending",
+		"message":      "Operation queued for processing",
+		"poll_url":     "/api/operations/jobs/" + job.ID,
+	})
+}
+
+// handleError maps template service errors to RFC 7807 responses
+func (h *OperationTemplateHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"TEMPLATE_NOT_FOUND",
+			"Operation template not found",
+			map[string]interface{}{"name": chi.URLParam(r, "name")},
+		))
+	case errors.Is(err, services.ErrTemplateExists):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusConflict,
+			"TEMPLATE_EXISTS",
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrTemplateReadOnly):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusConflict,
+			"TEMPLATE_READ_ONLY",
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "operation template request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
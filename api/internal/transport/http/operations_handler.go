@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -17,6 +18,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"isxcli/internal/config"
 	licenseErrors "isxcli/internal/errors"
 	"isxcli/internal/infrastructure"
 	"isxcli/internal/middleware"
@@ -35,6 +37,7 @@ type OperationsHandler struct {
 	logger   *slog.Logger
 	metrics  *infrastructure.BusinessMetrics
 	jobQueue *operations.JobQueue
+	events   EventSource
 }
 
 // NewOperationsHandler creates a new operations handler
@@ -74,6 +77,8 @@ type OperationRequest struct {
 	Steps      []StepConfig            `json:"steps" validate:"required,min=1,dive"`
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
 	Timeout    string                 `json:"timeout,omitempty"`
+	// DryRun returns the operation's plan instead of running it
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 // StepConfig represents configuration for a single step
@@ -235,6 +240,8 @@ func (h *OperationsHandler) StartOperation(w http.ResponseWriter, r *http.Reques
 		ID:         operationID,
 		Mode:       data.Mode,
 		Parameters: make(map[string]interface{}),
+		DryRun:     data.DryRun,
+		Profile:    config.ProfileFromContext(ctx),
 	}
 	
 	// If parameters are provided, use them
@@ -316,10 +323,55 @@ func (h *OperationsHandler) StartOperation(w http.ResponseWriter, r *http.Reques
 		attribute.String("operation.id", request.ID),
 		attribute.String("operation.mode", request.Mode),
 		attribute.Int("operation.steps_count", len(data.Steps)),
+		attribute.Bool("operation.dry_run", request.DryRun),
 	)
+
+	// A dry run answers with the plan and never reaches the queue
+	if request.DryRun {
+		h.planOperation(w, r, request)
+		return
+	}
 	
 	// Check if async job queue is available
 	if h.jobQueue != nil {
+		// A run sharing data directories with another operation waits for
+		// it, or is refused when the conflict policy says so
+		var stepIDs []string
+		if len(data.Steps) == 1 {
+			stepIDs = []string{data.Steps[0].ID}
+		}
+		blockedBy, policy, err := h.jobQueue.Conflicts(stepIDs, request.Parameters)
+		if err != nil {
+			problem := licenseErrors.NewProblemDetails(
+				http.StatusBadRequest,
+				"/errors/validation_failed",
+				"validation_failed",
+				err.Error(),
+				r.URL.Path+"#"+reqID,
+			).WithExtension("trace_id", infrastructure.TraceIDFromContext(ctx))
+			render.Render(w, r, problem)
+			return
+		}
+		if len(blockedBy) > 0 && policy == operations.ConflictReject {
+			h.logger.WarnContext(ctx, "operation refused: conflicting operations running",
+				slog.String("operation_id", request.ID),
+				slog.String("blocked_by", strings.Join(blockedBy, ",")),
+				slog.String("request_id", reqID))
+			
+			problem := licenseErrors.NewProblemDetails(
+				http.StatusConflict,
+				"/errors/operation_conflict",
+				"operation_conflict",
+				"Another operation is using the same data. Try again when it finishes, or queue the run.",
+				r.URL.Path+"#"+reqID,
+			).WithExtension("trace_id", infrastructure.TraceIDFromContext(ctx)).
+				WithExtension("operation_id", request.ID).
+				WithExtension("blocked_by", blockedBy)
+			
+			render.Render(w, r, problem)
+			return
+		}
+		
 		// Create job for async execution
 		job := &operations.Job{
 			ID:          request.ID,
@@ -394,6 +446,13 @@ func (h *OperationsHandler) StartOperation(w http.ResponseWriter, r *http.Reques
 			"message":     "Operation queued for processing",
 			"poll_url":    "/api/operations/jobs/" + job.ID,
 		}
+		if len(blockedBy) > 0 {
+			// Positions after this are sent as operation:snapshot updates
+			response["queued"] = true
+			response["blocked_by"] = blockedBy
+			response["queue_position"] = h.jobQueue.QueueLength() + 1
+			response["message"] = "Operation queued behind " + strings.Join(blockedBy, ", ")
+		}
 		
 		render.Status(r, http.StatusAccepted)
 		render.JSON(w, r, response)
@@ -484,6 +543,51 @@ func (h *OperationsHandler) StartOperation(w http.ResponseWriter, r *http.Reques
 	render.JSON(w, r, response)
 }
 
+// planOperation renders the plan of a dry-run request. Nothing is queued,
+// executed or broadcast.
+func (h *OperationsHandler) planOperation(w http.ResponseWriter, r *http.Request, request *operations.OperationRequest) {
+	ctx := r.Context()
+	reqID := middleware.GetReqID(ctx)
+
+	plan, err := h.service.PlanOperation(ctx, request)
+	if err != nil {
+		status, code := http.StatusInternalServerError, "operation_plan_failed"
+		var opErr *operations.OperationError
+		if errors.As(err, &opErr) && opErr.Type == operations.ErrorTypeValidation {
+			status, code = http.StatusBadRequest, "validation_failed"
+		} else {
+			h.logger.ErrorContext(ctx, "operation dry run failed",
+				slog.String("operation_id", request.ID),
+				slog.String("error", err.Error()),
+				slog.String("request_id", reqID))
+		}
+
+		problem := licenseErrors.NewProblemDetails(
+			status,
+			"/errors/"+code,
+			code,
+			err.Error(),
+			r.URL.Path+"#"+reqID,
+		).WithExtension("trace_id", infrastructure.TraceIDFromContext(ctx)).
+			WithExtension("operation_id", request.ID)
+
+		render.Render(w, r, problem)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "operation dry run planned",
+		slog.String("operation_id", request.ID),
+		slog.Int("steps", len(plan.Steps)),
+		slog.String("request_id", reqID))
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"operation_id": request.ID,
+		"dry_run":      true,
+		"plan":         plan,
+	})
+}
+
 // StopOperation handles POST /api/operations/{id}/stop
 func (h *OperationsHandler) StopOperation(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -895,6 +999,24 @@ func (h *OperationsHandler) handleError(w http.ResponseWriter, r *http.Request,
 			"Operation not found",
 			r.URL.Path+"#"+reqID,
 		)
+
+	case errors.Is(err, operations.ErrRunNotFound):
+		problem = licenseErrors.NewProblemDetails(
+			http.StatusNotFound,
+			"/errors/not_found",
+			"not_found",
+			"No recorded run for this operation",
+			r.URL.Path+"#"+reqID,
+		)
+
+	case errors.Is(err, operations.ErrArtifactsNotFound):
+		problem = licenseErrors.NewProblemDetails(
+			http.StatusNotFound,
+			"/errors/not_found",
+			"not_found",
+			"No artifact manifest for this operation",
+			r.URL.Path+"#"+reqID,
+		)
 		
 	case errors.Is(err, operations.ErrOperationCompleted):
 		problem = licenseErrors.NewProblemDetails(
@@ -1256,4 +1378,250 @@ func (h *OperationsHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	render.JSON(w, r, response)
-}
\ No newline at end of file
+}
+// ResumeRoutes returns the versioned operation routes mounted at /api/v1/operations
+func (h *OperationsHandler) ResumeRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/{id}/resume", h.ResumeOperation)
+	r.Get("/{id}/plan", h.GetOperationPlan)
+	r.Get("/{id}/artifacts", h.GetOperationArtifacts)
+	r.Get("/history", h.ListOperationHistory)
+	r.Get("/history/compare", h.CompareOperationRuns)
+	return r
+}
+
+// maxHistoryLimit caps the runs one history request returns
+const maxHistoryLimit = 500
+
+// ListOperationHistory handles GET /api/v1/operations/history. It returns
+// finished operation runs, newest first, with their parameters, per-step
+// durations, file counts and errors. Query params: status (completed, failed
+// or cancelled), since and until (RFC 3339 time or YYYY-MM-DD; until is
+// exclusive) and limit.
+func (h *OperationsHandler) ListOperationHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := operations.HistoryFilter{
+		Status: operations.OperationStatusValue(strings.ToLower(query.Get("status"))),
+	}
+	switch filter.Status {
+	case "", operations.OperationStatusCompleted, operations.OperationStatusFailed, operations.OperationStatusCancelled:
+	default:
+		h.renderValidationError(w, r, "status must be completed, failed or cancelled")
+		return
+	}
+	for _, bound := range []struct {
+		name string
+		into *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", v)
+		}
+		if err != nil {
+			h.renderValidationError(w, r, bound.name+" must be an RFC 3339 time or a date in YYYY-MM-DD format")
+			return
+		}
+		*bound.into = t
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxHistoryLimit {
+			h.renderValidationError(w, r, fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	runs, err := h.service.ListOperationHistory(r.Context(), filter)
+	if err != nil {
+		h.handleError(w, r, err, nil)
+		return
+	}
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   runs,
+		"count":  len(runs),
+	})
+}
+
+// CompareOperationRuns handles GET /api/v1/operations/history/compare. It
+// diffs the recorded run target against base: parameters, step statuses,
+// durations, file and row counts and numeric step metadata, with a readable
+// list of the differences. target defaults to the latest run and base to the
+// run before target.
+func (h *OperationsHandler) CompareOperationRuns(w http.ResponseWriter, r *http.Request) {
+	base, target := r.URL.Query().Get("base"), r.URL.Query().Get("target")
+	if base != "" && base == target {
+		h.renderValidationError(w, r, "base and target must be different runs")
+		return
+	}
+
+	comparison, err := h.service.CompareOperationRuns(r.Context(), base, target)
+	if err != nil {
+		h.handleError(w, r, err, map[string]interface{}{
+			"base":   base,
+			"target": target,
+		})
+		return
+	}
+	render.JSON(w, r, comparison)
+}
+
+// renderValidationError responds 400 with a validation problem
+func (h *OperationsHandler) renderValidationError(w http.ResponseWriter, r *http.Request, detail string) {
+	reqID := middleware.GetReqID(r.Context())
+	problem := licenseErrors.NewProblemDetails(
+		http.StatusBadRequest,
+		"/errors/validation",
+		"validation_error",
+		detail,
+		r.URL.Path+"#"+reqID,
+	).WithExtension("trace_id", infrastructure.TraceIDFromContext(r.Context()))
+
+	render.Render(w, r, problem)
+}
+
+// GetOperationArtifacts handles GET /api/v1/operations/{id}/artifacts. It
+// returns the manifest of the files an operation run created, with their
+// checksums, row counts and the step that produced them.
+func (h *OperationsHandler) GetOperationArtifacts(w http.ResponseWriter, r *http.Request) {
+	operationID := chi.URLParam(r, "id")
+
+	manifest, err := h.service.GetOperationArtifacts(r.Context(), operationID)
+	if err != nil {
+		h.handleError(w, r, err, map[string]interface{}{
+			"operation_id": operationID,
+		})
+		return
+	}
+	render.JSON(w, r, manifest)
+}
+
+// GetOperationPlan handles GET /api/v1/operations/{id}/plan. It returns the
+// step graph of a running operation, or of an operation type such as
+// full_pipeline, as JSON or, with format=dot, as Graphviz DOT.
+func (h *OperationsHandler) GetOperationPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	operationID := chi.URLParam(r, "id")
+	reqID := middleware.GetReqID(ctx)
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		problem := licenseErrors.NewProblemDetails(
+			http.StatusBadRequest,
+			"/errors/validation",
+			"validation_error",
+			"format must be json or dot",
+			r.URL.Path+"#"+reqID,
+		).WithExtension("trace_id", infrastructure.TraceIDFromContext(ctx))
+
+		render.Render(w, r, problem)
+		return
+	}
+
+	graph, err := h.service.GetOperationPlanGraph(ctx, operationID)
+	if err != nil {
+		h.handleError(w, r, err, map[string]interface{}{
+			"operation_id": operationID,
+		})
+		return
+	}
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(graph.DOT()))
+		return
+	}
+	render.JSON(w, r, graph)
+}
+
+// ResumeOperation handles POST /api/v1/operations/{id}/resume. It re-queues an
+// operation interrupted by a shutdown from its checkpoint, skipping the stages
+// that had already completed.
+func (h *OperationsHandler) ResumeOperation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	operationID := chi.URLParam(r, "id")
+	reqID := middleware.GetReqID(ctx)
+	tracer := otel.Tracer("operations-handler")
+
+	ctx, span := tracer.Start(ctx, "operations_handler.resume_operation",
+		trace.WithAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.route", "/api/v1/operations/{id}/resume"),
+			attribute.String("operation.id", operationID),
+			attribute.String("request_id", reqID),
+		),
+	)
+	defer span.End()
+
+	h.logger.InfoContext(ctx, "operation resume request",
+		slog.String("operation_id", operationID),
+		slog.String("request_id", reqID),
+		slog.String("trace_id", infrastructure.TraceIDFromContext(ctx)))
+
+	if h.jobQueue == nil {
+		problem := licenseErrors.NewProblemDetails(
+			http.StatusServiceUnavailable,
+			"/errors/service_unavailable",
+			"service_unavailable",
+			"Job queue service is not available",
+			r.URL.Path+"#"+reqID,
+		).WithExtension("trace_id", infrastructure.TraceIDFromContext(ctx))
+
+		render.Render(w, r, problem)
+		return
+	}
+
+	job, err := h.jobQueue.Resume(operationID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "operation resume failed")
+
+		h.logger.ErrorContext(ctx, "failed to resume operation",
+			slog.String("operation_id", operationID),
+			slog.String("error", err.Error()),
+			slog.String("request_id", reqID))
+
+		status, problemType, detail := http.StatusInternalServerError, "resume_failed", "Failed to resume operation"
+		switch {
+		case errors.Is(err, operations.ErrCheckpointNotFound):
+			status, problemType, detail = http.StatusNotFound, "not_found", "No checkpoint found for this operation"
+		case errors.Is(err, operations.ErrOperationRunning):
+			status, problemType, detail = http.StatusConflict, "invalid_state", "Operation is still running"
+		}
+
+		problem := licenseErrors.NewProblemDetails(
+			status,
+			"/errors/"+problemType,
+			problemType,
+			detail,
+			r.URL.Path+"#"+reqID,
+		).WithExtension("trace_id", infrastructure.TraceIDFromContext(ctx)).
+			WithExtension("operation_id", operationID)
+
+		render.Render(w, r, problem)
+		return
+	}
+
+	h.wsHub.BroadcastUpdate("operation_update", "queued", "pending", map[string]interface{}{
+		"job_id":       job.ID,
+		"operation_id": operationID,
+		"resumed":      true,
+		"timestamp":    time.Now().UTC(),
+	})
+
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, map[string]interface{}{
+		"job_id":           job.ID,
+		"operation_id":     operationID,
+		"status":           "pending",
+		"message":          "Operation resumed from checkpoint",
+		"completed_stages": job.Metadata["completed_stages"],
+		"poll_url":         "/api/operations/jobs/" + job.ID,
+	})
+}
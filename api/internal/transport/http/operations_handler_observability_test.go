@@ -81,6 +81,46 @@ func (m *mockOperationsService) GetOperationMetrics(ctx context.Context) (map[st
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *mockOperationsService) PlanOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationPlan, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.OperationPlan), args.Error(1)
+}
+
+func (m *mockOperationsService) GetOperationPlanGraph(ctx context.Context, id string) (*operations.PlanGraph, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.PlanGraph), args.Error(1)
+}
+
+func (m *mockOperationsService) GetOperationArtifacts(ctx context.Context, id string) (*operations.ArtifactManifest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.ArtifactManifest), args.Error(1)
+}
+
+func (m *mockOperationsService) ListOperationHistory(ctx context.Context, filter operations.HistoryFilter) ([]operations.RunRecord, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]operations.RunRecord), args.Error(1)
+}
+
+func (m *mockOperationsService) CompareOperationRuns(ctx context.Context, base, target string) (*operations.RunComparison, error) {
+	args := m.Called(ctx, base, target)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.RunComparison), args.Error(1)
+}
+
 func (m *mockOperationsService) GetOperationTypes(ctx context.Context) ([]operations.OperationType, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -71,6 +71,46 @@ func (m *MockOperationsService) GetOperationMetrics(ctx context.Context) (map[st
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockOperationsService) PlanOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationPlan, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.OperationPlan), args.Error(1)
+}
+
+func (m *MockOperationsService) GetOperationPlanGraph(ctx context.Context, id string) (*operations.PlanGraph, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.PlanGraph), args.Error(1)
+}
+
+func (m *MockOperationsService) GetOperationArtifacts(ctx context.Context, id string) (*operations.ArtifactManifest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.ArtifactManifest), args.Error(1)
+}
+
+func (m *MockOperationsService) ListOperationHistory(ctx context.Context, filter operations.HistoryFilter) ([]operations.RunRecord, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]operations.RunRecord), args.Error(1)
+}
+
+func (m *MockOperationsService) CompareOperationRuns(ctx context.Context, base, target string) (*operations.RunComparison, error) {
+	args := m.Called(ctx, base, target)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*operations.RunComparison), args.Error(1)
+}
+
 func (m *MockOperationsService) GetOperationTypes(ctx context.Context) ([]operations.OperationType, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -273,6 +313,47 @@ func TestOperationsHandler_StartOperation(t *testing.T) {
 	}
 }
 
+func TestOperationsHandler_StartOperationDryRun(t *testing.T) {
+	handler, service, hub := setupOperationsHandler(t)
+	router := setupRouter(handler)
+
+	service.On("PlanOperation", mock.Anything, mock.MatchedBy(func(req *operations.OperationRequest) bool {
+		return req.DryRun && req.FromDate == "2025-06-01"
+	})).Return(&operations.OperationPlan{
+		Steps: []*operations.StepPlan{{ID: "scraping", WillRun: true, ExpectedFiles: 5, PendingFiles: []string{"2025 06 03 ISX Daily Report.xlsx"}}},
+	}, nil)
+
+	body, err := json.Marshal(OperationRequest{
+		Mode:   "full",
+		DryRun: true,
+		Steps:  []StepConfig{{ID: "scraping", Type: "scraping", Parameters: map[string]interface{}{"from": "2025-06-01"}}},
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/api/v1/operations", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response["dry_run"])
+	steps := response["plan"].(map[string]interface{})["steps"].([]interface{})
+	assert.Equal(t, float64(5), steps[0].(map[string]interface{})["expected_files"])
+
+	service.AssertNotCalled(t, "ExecuteOperation", mock.Anything, mock.Anything)
+	hub.AssertNotCalled(t, "BroadcastUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Planning errors about the request are the client's
+	handler, service, _ = setupOperationsHandler(t)
+	router = setupRouter(handler)
+	service.On("PlanOperation", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("failed to plan operation: %w", operations.NewValidationError("scraping", "invalid from_date")))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/operations", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestOperationsHandler_GetOperationStatus(t *testing.T) {
 	tests := []struct {
 		name           string
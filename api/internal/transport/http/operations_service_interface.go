@@ -8,6 +8,11 @@ import (
 // OperationServiceInterface defines the interface for operations service
 type OperationServiceInterface interface {
 	ExecuteOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationResponse, error)
+	PlanOperation(ctx context.Context, request *operations.OperationRequest) (*operations.OperationPlan, error)
+	GetOperationPlanGraph(ctx context.Context, id string) (*operations.PlanGraph, error)
+	GetOperationArtifacts(ctx context.Context, id string) (*operations.ArtifactManifest, error)
+	ListOperationHistory(ctx context.Context, filter operations.HistoryFilter) ([]operations.RunRecord, error)
+	CompareOperationRuns(ctx context.Context, base, target string) (*operations.RunComparison, error)
 	GetOperationStatus(ctx context.Context, operationID string) (*operations.OperationState, error)
 	CancelOperation(ctx context.Context, operationID string) error
 	ListOperations(ctx context.Context) ([]*operations.OperationState, error)
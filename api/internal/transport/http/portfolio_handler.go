@@ -0,0 +1,252 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// PortfolioHandler handles portfolio tracking requests
+type PortfolioHandler struct {
+	service      *services.PortfolioService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewPortfolioHandler creates a new portfolio handler
+func NewPortfolioHandler(service *services.PortfolioService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *PortfolioHandler {
+	return &PortfolioHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// portfolioRequest is the body of create and rename requests
+type portfolioRequest struct {
+	Name string `json:"name"`
+}
+
+// holdingRequest is the body of a direct holding edit
+type holdingRequest struct {
+	Quantity    int64   `json:"quantity"`
+	AverageCost float64 `json:"averageCost"`
+}
+
+// Routes returns the portfolio routes mounted at /api/v1/portfolios
+func (h *PortfolioHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.Get)
+		r.Put("/", h.Rename)
+		r.Delete("/", h.Delete)
+		r.Get("/transactions", h.ListTransactions)
+		r.Post("/transactions", h.RecordTransaction)
+		r.Put("/holdings/{symbol}", h.SetHolding)
+		r.Delete("/holdings/{symbol}", h.RemoveHolding)
+	})
+
+	return r
+}
+
+// List handles GET /api/v1/portfolios
+func (h *PortfolioHandler) List(w http.ResponseWriter, r *http.Request) {
+	portfolios, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolios,
+		"count":  len(portfolios),
+	})
+}
+
+// Create handles POST /api/v1/portfolios
+func (h *PortfolioHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req portfolioRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	portfolio, err := h.service.Create(r.Context(), req.Name)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolio,
+	})
+}
+
+// Get handles GET /api/v1/portfolios/{id}, returning the valued portfolio:
+// unrealized P&L, sector exposure and liquidity warnings per position
+func (h *PortfolioHandler) Get(w http.ResponseWriter, r *http.Request) {
+	valuation, err := h.service.Value(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   valuation,
+	})
+}
+
+// Rename handles PUT /api/v1/portfolios/{id}
+func (h *PortfolioHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	var req portfolioRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	portfolio, err := h.service.Rename(r.Context(), chi.URLParam(r, "id"), req.Name)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolio,
+	})
+}
+
+// Delete handles DELETE /api/v1/portfolios/{id}
+func (h *PortfolioHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTransactions handles GET /api/v1/portfolios/{id}/transactions
+func (h *PortfolioHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	portfolio, err := h.service.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolio.Transactions,
+		"count":  len(portfolio.Transactions),
+	})
+}
+
+// RecordTransaction handles POST /api/v1/portfolios/{id}/transactions
+func (h *PortfolioHandler) RecordTransaction(w http.ResponseWriter, r *http.Request) {
+	var req services.TransactionInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	portfolio, err := h.service.RecordTransaction(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolio,
+	})
+}
+
+// SetHolding handles PUT /api/v1/portfolios/{id}/holdings/{symbol}
+func (h *PortfolioHandler) SetHolding(w http.ResponseWriter, r *http.Request) {
+	var req holdingRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	portfolio, err := h.service.SetHolding(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "symbol"), req.Quantity, req.AverageCost)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolio,
+	})
+}
+
+// RemoveHolding handles DELETE /api/v1/portfolios/{id}/holdings/{symbol}
+func (h *PortfolioHandler) RemoveHolding(w http.ResponseWriter, r *http.Request) {
+	portfolio, err := h.service.RemoveHolding(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "symbol"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   portfolio,
+	})
+}
+
+// handleError maps portfolio service errors to RFC 7807 responses
+func (h *PortfolioHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	id := chi.URLParam(r, "id")
+
+	switch {
+	case errors.Is(err, services.ErrPortfolioNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"PORTFOLIO_NOT_FOUND",
+			"Portfolio not found",
+			map[string]interface{}{"id": id},
+		))
+	case errors.Is(err, services.ErrHoldingNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"HOLDING_NOT_FOUND",
+			"Holding not found",
+			map[string]interface{}{"id": id, "symbol": chi.URLParam(r, "symbol")},
+		))
+	case errors.Is(err, services.ErrInsufficientHolding):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusConflict,
+			"INSUFFICIENT_HOLDING",
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "portfolio request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("portfolio_id", id))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
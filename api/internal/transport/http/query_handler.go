@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	customMiddleware "isxcli/internal/middleware"
+	"isxcli/internal/query"
+	"isxcli/internal/services"
+)
+
+// QueryService runs ad-hoc read-only queries over report tables
+type QueryService interface {
+	Query(ctx context.Context, sql string, maxRows int) (*query.Result, error)
+}
+
+// QueryHandler serves ad-hoc SQL queries for admins
+type QueryHandler struct {
+	service      QueryService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewQueryHandler creates a new query handler
+func NewQueryHandler(service QueryService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *QueryHandler {
+	return &QueryHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the query routes mounted at /api/v1/admin/query
+func (h *QueryHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/", h.Query)
+
+	return r
+}
+
+// queryRequest is the body of POST /api/v1/admin/query
+type queryRequest struct {
+	SQL   string `json:"sql"`
+	Limit int    `json:"limit"`
+}
+
+// Query handles POST /api/v1/admin/query. The body holds a single SELECT
+// over one report table and an optional row limit (1 to 10000, default
+// 1000); the response lists the result columns and rows and whether the
+// limit cut rows off.
+func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.New(http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body"))
+		return
+	}
+	if strings.TrimSpace(req.SQL) == "" {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("sql", "A SELECT statement is required"))
+		return
+	}
+	switch {
+	case req.Limit == 0:
+		req.Limit = services.DefaultQueryRows
+	case req.Limit < 0 || req.Limit > services.MaxQueryRows:
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("limit", fmt.Sprintf("Must be between 1 and %d", services.MaxQueryRows)))
+		return
+	}
+
+	start := time.Now()
+	result, err := h.service.Query(r.Context(), req.SQL, req.Limit)
+	attrs := []any{
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+		slog.String("user", customMiddleware.UserFromContext(r.Context()).Name),
+		slog.String("sql", req.SQL),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		h.handleError(w, r, err, attrs)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "ad-hoc query",
+		append(attrs, slog.Int("rows", result.RowCount), slog.Bool("truncated", result.Truncated))...)
+	render.JSON(w, r, result)
+}
+
+// handleError maps query errors to RFC 7807 responses
+func (h *QueryHandler) handleError(w http.ResponseWriter, r *http.Request, err error, attrs []any) {
+	attrs = append(attrs, slog.String("error", err.Error()))
+	switch {
+	case errors.Is(err, query.ErrRejected):
+		h.logger.InfoContext(r.Context(), "ad-hoc query rejected", attrs...)
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"QUERY_REJECTED",
+			strings.TrimPrefix(err.Error(), query.ErrRejected.Error()+": "),
+		))
+	case errors.Is(err, context.DeadlineExceeded):
+		h.logger.WarnContext(r.Context(), "ad-hoc query timed out", attrs...)
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusGatewayTimeout,
+			"QUERY_TIMEOUT",
+			fmt.Sprintf("The query ran longer than %s", services.QueryTimeout),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "ad-hoc query failed", attrs...)
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
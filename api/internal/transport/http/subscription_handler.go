@@ -0,0 +1,191 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// Preview bounds for GET /api/v1/subscriptions/preview
+const (
+	defaultPreviewRuns = 5
+	maxPreviewRuns     = 50
+)
+
+// SubscriptionHandler handles export subscription requests
+type SubscriptionHandler struct {
+	service      *services.ExportSubscriptionService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewSubscriptionHandler creates a new export subscription handler
+func NewSubscriptionHandler(service *services.ExportSubscriptionService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the subscription routes mounted at /api/v1/subscriptions
+func (h *SubscriptionHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Get("/preview", h.Preview)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.Get)
+		r.Put("/", h.Update)
+		r.Delete("/", h.Delete)
+	})
+
+	return r
+}
+
+// List handles GET /api/v1/subscriptions
+func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   subs,
+		"count":  len(subs),
+	})
+}
+
+// Create handles POST /api/v1/subscriptions
+func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req services.SubscriptionInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	sub, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   sub,
+	})
+}
+
+// Get handles GET /api/v1/subscriptions/{id}
+func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.service.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   sub,
+	})
+}
+
+// Update handles PUT /api/v1/subscriptions/{id}
+func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req services.SubscriptionInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	sub, err := h.service.Update(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   sub,
+	})
+}
+
+// Delete handles DELETE /api/v1/subscriptions/{id}
+func (h *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Preview handles GET /api/v1/subscriptions/preview?schedule=...&count=N,
+// resolving the next runs of a schedule against the trading calendar
+func (h *SubscriptionHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	schedule := r.URL.Query().Get("schedule")
+	if schedule == "" {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("schedule", "schedule is required"))
+		return
+	}
+
+	count := defaultPreviewRuns
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPreviewRuns {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("count", "count must be between 1 and 50"))
+			return
+		}
+		count = n
+	}
+
+	runs, err := h.service.Preview(r.Context(), schedule, count)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   runs,
+		"count":  len(runs),
+	})
+}
+
+// handleError maps subscription service errors to RFC 7807 responses
+func (h *SubscriptionHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"SUBSCRIPTION_NOT_FOUND",
+			"Export subscription not found",
+			map[string]interface{}{"id": chi.URLParam(r, "id")},
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "subscription request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
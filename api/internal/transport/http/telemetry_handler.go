@@ -0,0 +1,66 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/telemetry"
+)
+
+// TelemetryHandler shows and purges the locally collected usage counters
+type TelemetryHandler struct {
+	collector    *telemetry.Collector
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewTelemetryHandler creates a new telemetry handler
+func NewTelemetryHandler(collector *telemetry.Collector, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *TelemetryHandler {
+	return &TelemetryHandler{
+		collector:    collector,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the telemetry routes mounted at /api/v1/telemetry
+func (h *TelemetryHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.Get)
+	r.Delete("/", h.Purge)
+
+	return r
+}
+
+// Get handles GET /api/v1/telemetry: every counter collected, and those
+// the next license validation sends when reporting is enabled
+func (h *TelemetryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   h.collector.Snapshot(),
+	})
+}
+
+// Purge handles DELETE /api/v1/telemetry
+func (h *TelemetryHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	if err := h.collector.Purge(); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to purge telemetry",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "telemetry purged",
+		slog.String("request_id", middleware.GetReqID(r.Context())))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"isxcli/internal/dataprocessing/analytics"
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// GetTickerBars handles GET /api/v1/tickers/{ticker}/bars, the ticker's
+// OHLCV bars for candlestick charts. Query params: interval (1w or 1mo,
+// default 1w) and from, to (YYYY-MM-DD).
+func (h *DataHandler) GetTickerBars(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(chi.URLParam(r, "ticker"))
+	query := r.URL.Query()
+
+	interval := analytics.IntervalWeek
+	if v := query.Get("interval"); v != "" {
+		parsed, err := analytics.ParseInterval(v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("interval", "Interval must be 1w or 1mo"))
+			return
+		}
+		interval = parsed
+	}
+
+	var from, to time.Time
+	if v := query.Get("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("from", "From must be a date in YYYY-MM-DD format"))
+			return
+		}
+		from = parsed
+	}
+	if v := query.Get("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("to", "To must be a date in YYYY-MM-DD format"))
+			return
+		}
+		to = parsed
+	}
+
+	bars, err := h.service.GetTickerBars(r.Context(), ticker, interval, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInput):
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("to", err.Error()))
+		case errors.Is(err, services.ErrTickerNotFound):
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusNotFound,
+				"TICKER_NOT_FOUND",
+				fmt.Sprintf("No trading history for ticker '%s'", ticker),
+				map[string]interface{}{"ticker": ticker},
+			))
+		default:
+			h.logger.ErrorContext(r.Context(), "failed to get ticker bars",
+				slog.String("error", err.Error()),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("ticker", ticker),
+				slog.String("interval", string(interval)))
+			h.errorHandler.HandleError(w, r, err)
+		}
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status":   "success",
+		"symbol":   ticker,
+		"interval": interval,
+		"data":     bars,
+		"count":    len(bars),
+	})
+}
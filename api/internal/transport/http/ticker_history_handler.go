@@ -0,0 +1,161 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/exporter"
+	"isxcli/internal/services"
+)
+
+// GetTickerHistory handles GET /api/v1/tickers/{ticker}/history, a page of
+// the ticker's trading history. Query params: from, to (YYYY-MM-DD), fields
+// (comma-separated, e.g. close,volume), page, page_size and currency (USD
+// converts prices and values at each day's CBI rate). Clients sending
+// Accept: text/csv get the page as CSV with the totals in X-Total-* headers.
+func (h *DataHandler) GetTickerHistory(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(chi.URLParam(r, "ticker"))
+
+	query, apiErr := parseTickerHistoryQuery(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	query.Symbol = ticker
+
+	page, err := h.service.GetTickerHistory(r.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInput):
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("fields", err.Error()))
+		case errors.Is(err, services.ErrTickerNotFound):
+			h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+				http.StatusNotFound,
+				"TICKER_NOT_FOUND",
+				fmt.Sprintf("No trading history for ticker '%s'", ticker),
+				map[string]interface{}{"ticker": ticker},
+			))
+		default:
+			h.logger.ErrorContext(r.Context(), "failed to get ticker history",
+				slog.String("error", err.Error()),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("ticker", ticker))
+			h.errorHandler.HandleError(w, r, err)
+		}
+		return
+	}
+
+	if acceptsCSV(r) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+		w.Header().Set("X-Total-Pages", strconv.Itoa(page.TotalPages))
+		w.Header().Set("X-Currency", string(page.Currency))
+		w.Header().Add("Vary", "Accept")
+
+		// Cells are escaped so a downloaded file opened in Excel cannot run
+		// formulas smuggled into company names
+		writer := csv.NewWriter(w)
+		_ = writer.Write(exporter.EscapeFormulas(page.Columns))
+		for _, row := range page.Rows {
+			_ = writer.Write(exporter.EscapeFormulas(row))
+		}
+		writer.Flush()
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	render.JSON(w, r, map[string]interface{}{
+		"status":   "success",
+		"symbol":   page.Symbol,
+		"currency": page.Currency,
+		"columns":  page.Columns,
+		"data":     page.Records(),
+		"count":    len(page.Rows),
+		"pagination": map[string]interface{}{
+			"page":        page.Page,
+			"page_size":   page.PageSize,
+			"total":       page.Total,
+			"total_pages": page.TotalPages,
+		},
+	})
+}
+
+// parseTickerHistoryQuery reads the history filters and pagination
+func parseTickerHistoryQuery(r *http.Request) (services.TickerHistoryQuery, *apierrors.APIError) {
+	q := services.TickerHistoryQuery{Page: 1, PageSize: services.DefaultHistoryPageSize}
+	query := r.URL.Query()
+
+	for _, v := range query["fields"] {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				q.Fields = append(q.Fields, f)
+			}
+		}
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, apierrors.ErrValidation("from", "From must be a date in YYYY-MM-DD format")
+		}
+		q.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, apierrors.ErrValidation("to", "To must be a date in YYYY-MM-DD format")
+		}
+		q.To = to
+	}
+
+	cur, apiErr := parseCurrency(r)
+	if apiErr != nil {
+		return q, apiErr
+	}
+	q.Currency = cur
+
+	if v := query.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return q, apierrors.ErrValidation("page", "Page must be a positive integer")
+		}
+		q.Page = page
+	}
+
+	if v := query.Get("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > services.MaxHistoryPageSize {
+			return q, apierrors.ErrValidation("page_size", fmt.Sprintf("Page size must be between 1 and %d", services.MaxHistoryPageSize))
+		}
+		q.PageSize = size
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return q, apierrors.ErrValidation("to", "To must not be before from")
+	}
+
+	return q, nil
+}
+
+// acceptsCSV reports whether the client asked for CSV
+func acceptsCSV(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if strings.EqualFold(mediaType, "text/csv") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// GetTickerSummaries handles GET /api/v1/tickers/summary, the SSOT ticker
+// summaries: last price, 52-week range, average volume, value traded and
+// trading days. Query param groupBy=sector aggregates them per sector.
+func (h *DataHandler) GetTickerSummaries(w http.ResponseWriter, r *http.Request) {
+	groupBy := strings.ToLower(r.URL.Query().Get("groupBy"))
+	if groupBy != "" && groupBy != "sector" {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("groupBy", "groupBy must be sector"))
+		return
+	}
+
+	summaries, err := h.service.GetTickerSummaries(r.Context())
+	if err != nil {
+		if !errors.Is(err, services.ErrNoTickersFound) {
+			h.logger.ErrorContext(r.Context(), "failed to get ticker summaries",
+				slog.String("error", err.Error()),
+				slog.String("request_id", middleware.GetReqID(r.Context())))
+		}
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	if groupBy == "sector" {
+		sectors := services.GroupTickerSummariesBySector(summaries)
+		render.JSON(w, r, map[string]interface{}{
+			"status":   "success",
+			"group_by": groupBy,
+			"data":     sectors,
+			"count":    len(sectors),
+		})
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   summaries,
+		"count":  len(summaries),
+	})
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// GetTickerTimeline handles GET /api/v1/tickers/{ticker}/timeline, the merged
+// event feed for the ticker page. Query params: type (comma-separated),
+// from and to (YYYY-MM-DD), limit.
+func (h *DataHandler) GetTickerTimeline(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(chi.URLParam(r, "ticker"))
+
+	query, apiErr := parseTimelineQuery(r)
+	if apiErr != nil {
+		h.errorHandler.HandleError(w, r, apiErr)
+		return
+	}
+	query.Symbol = ticker
+
+	timeline, err := h.timeline.Get(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			h.errorHandler.HandleError(w, r, apierrors.ErrValidation("type", err.Error()))
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to build ticker timeline",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("ticker", ticker))
+		h.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   timeline,
+		"count":  len(timeline.Events),
+	})
+}
+
+// parseTimelineQuery reads the optional timeline filters
+func parseTimelineQuery(r *http.Request) (services.TimelineQuery, *apierrors.APIError) {
+	var q services.TimelineQuery
+	query := r.URL.Query()
+
+	for _, v := range query["type"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+				q.Types = append(q.Types, t)
+			}
+		}
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, apierrors.ErrValidation("from", "From must be a date in YYYY-MM-DD format")
+		}
+		q.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, apierrors.ErrValidation("to", "To must be a date in YYYY-MM-DD format")
+		}
+		q.To = to
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return q, apierrors.ErrValidation("limit", "Limit must be a non-negative integer")
+		}
+		q.Limit = limit
+	}
+
+	if err := q.Validate(); err != nil {
+		field := "type"
+		if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+			field = "to"
+		}
+		return q, apierrors.ErrValidation(field, err.Error())
+	}
+
+	return q, nil
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	"isxcli/internal/config"
+	apierrors "isxcli/internal/errors"
+	customMiddleware "isxcli/internal/middleware"
+	"isxcli/internal/operations"
+	"isxcli/internal/services"
+)
+
+// UploadFormField is the multipart field holding an uploaded file
+const UploadFormField = "file"
+
+// UploadHandler handles files uploaded in place of scraper downloads
+type UploadHandler struct {
+	service      *services.DailyReportUploadService
+	wsHub        Hub
+	jobQueue     *operations.JobQueue
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(service *services.DailyReportUploadService, wsHub Hub, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *UploadHandler {
+	return &UploadHandler{
+		service:      service,
+		wsHub:        wsHub,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// SetJobQueue sets the job queue that processes uploaded reports
+func (h *UploadHandler) SetJobQueue(jobQueue *operations.JobQueue) {
+	h.jobQueue = jobQueue
+}
+
+// Routes returns the upload routes mounted at /api/v1/uploads. The file is
+// read and checked by the upload guard in front of them.
+func (h *UploadHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Post("/daily-report", h.UploadDailyReport)
+
+	return r
+}
+
+// UploadDailyReport handles POST /api/v1/uploads/daily-report. The workbook
+// is stored in the downloads directory and, with process=true, an operation
+// processing it is queued.
+func (h *UploadHandler) UploadDailyReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := middleware.GetReqID(ctx)
+
+	upload, ok := customMiddleware.UploadFromContext(ctx)
+	if !ok {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation(UploadFormField, "A daily report workbook is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	var overwrite, process bool
+	for name, dst := range map[string]*bool{"overwrite": &overwrite, "process": &process} {
+		if raw := query.Get(name); raw != "" {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				h.errorHandler.HandleError(w, r, apierrors.ErrValidation(name, "Must be true or false"))
+				return
+			}
+			*dst = v
+		}
+	}
+
+	report, err := h.service.Store(ctx, upload.Filename, query.Get("date"), upload.Data, overwrite)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"status": "success",
+		"data":   report,
+	}
+	if process {
+		job, err := h.enqueue(r, report)
+		if err != nil {
+			// The report is stored; the next run or the downloads watcher picks it up
+			h.logger.ErrorContext(ctx, "failed to enqueue upload processing",
+				slog.String("filename", report.Filename),
+				slog.String("error", err.Error()),
+				slog.String("request_id", reqID))
+			response["message"] = "Report stored but processing could not be queued: " + err.Error()
+		} else {
+			response["job_id"] = job.ID
+			response["operation_id"] = job.OperationID
+			response["poll_url"] = "/api/operations/jobs/" + job.ID
+		}
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, response)
+}
+
+// enqueue queues the operation processing an uploaded report
+func (h *UploadHandler) enqueue(r *http.Request, report *services.DailyReportUpload) (*operations.Job, error) {
+	if h.jobQueue == nil {
+		return nil, errors.New("job queue service is not available")
+	}
+
+	reqID := middleware.GetReqID(r.Context())
+	operationID := reqID
+	if operationID == "" {
+		operationID = uuid.New().String()
+	}
+	request := h.service.ProcessRequest(operationID, report)
+	request.Profile = config.ProfileFromContext(r.Context())
+
+	job := &operations.Job{
+		ID:          request.ID,
+		OperationID: request.ID,
+		StageID:     "full_pipeline",
+		StageName:   "Full Pipeline",
+		Status:      operations.JobStatusPending,
+		CreatedAt:   time.Now(),
+		Request:     request,
+		Metadata: map[string]interface{}{
+			"request_id": reqID,
+			"trigger":    "upload",
+			"report":     report.Filename,
+		},
+	}
+	if err := h.jobQueue.Enqueue(job); err != nil {
+		return nil, err
+	}
+
+	h.logger.InfoContext(r.Context(), "upload processing enqueued",
+		slog.String("job_id", job.ID),
+		slog.String("filename", report.Filename),
+		slog.String("request_id", reqID))
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastUpdate("operation_update", "queued", "pending", map[string]interface{}{
+			"job_id":       job.ID,
+			"operation_id": request.ID,
+			"trigger":      "upload",
+			"timestamp":    time.Now().UTC(),
+		})
+	}
+	return job, nil
+}
+
+// handleError maps upload service errors to RFC 7807 responses
+func (h *UploadHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrReportExists):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusConflict,
+			"REPORT_EXISTS",
+			err.Error()+"; pass overwrite=true to replace it",
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "daily report upload failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	apierrors "isxcli/internal/errors"
+	"isxcli/internal/services"
+)
+
+// WatchlistHandler handles watchlist requests
+type WatchlistHandler struct {
+	service      *services.WatchlistService
+	logger       *slog.Logger
+	errorHandler *apierrors.ErrorHandler
+}
+
+// NewWatchlistHandler creates a new watchlist handler
+func NewWatchlistHandler(service *services.WatchlistService, logger *slog.Logger, errorHandler *apierrors.ErrorHandler) *WatchlistHandler {
+	return &WatchlistHandler{
+		service:      service,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Routes returns the watchlist routes mounted at /api/v1/watchlists
+func (h *WatchlistHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(render.SetContentType(render.ContentTypeJSON))
+
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.Get)
+		r.Put("/", h.Update)
+		r.Delete("/", h.Delete)
+	})
+
+	return r
+}
+
+// List handles GET /api/v1/watchlists
+func (h *WatchlistHandler) List(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   lists,
+		"count":  len(lists),
+	})
+}
+
+// Create handles POST /api/v1/watchlists
+func (h *WatchlistHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req services.WatchlistInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	list, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   list,
+	})
+}
+
+// Get handles GET /api/v1/watchlists/{id}
+func (h *WatchlistHandler) Get(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   list,
+	})
+}
+
+// Update handles PUT /api/v1/watchlists/{id}
+func (h *WatchlistHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req services.WatchlistInput
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		h.errorHandler.HandleError(w, r, apierrors.ErrValidation("body", "Request body must be valid JSON"))
+		return
+	}
+
+	list, err := h.service.Update(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"status": "success",
+		"data":   list,
+	})
+}
+
+// Delete handles DELETE /api/v1/watchlists/{id}
+func (h *WatchlistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleError maps watchlist service errors to RFC 7807 responses
+func (h *WatchlistHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrWatchlistNotFound):
+		h.errorHandler.HandleError(w, r, apierrors.NewWithDetails(
+			http.StatusNotFound,
+			"WATCHLIST_NOT_FOUND",
+			"Watchlist not found",
+			map[string]interface{}{"id": chi.URLParam(r, "id")},
+		))
+	case errors.Is(err, services.ErrInvalidInput):
+		h.errorHandler.HandleError(w, r, apierrors.New(
+			http.StatusBadRequest,
+			"VALIDATION_FAILED",
+			err.Error(),
+		))
+	default:
+		h.logger.ErrorContext(r.Context(), "watchlist request failed",
+			slog.String("error", err.Error()),
+			slog.String("request_id", middleware.GetReqID(r.Context())))
+		h.errorHandler.HandleError(w, r, err)
+	}
+}
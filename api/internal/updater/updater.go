@@ -13,11 +13,34 @@ import (
 	"time"
 )
 
+// Channel selects which releases an installation updates to
+type Channel string
+
+const (
+	// ChannelStable follows the latest full release
+	ChannelStable Channel = "stable"
+	// ChannelBeta follows the newest release, including pre-releases
+	ChannelBeta Channel = "beta"
+)
+
+// ParseChannel parses a channel name. An empty name is ChannelStable.
+func ParseChannel(name string) (Channel, error) {
+	switch Channel(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ChannelStable:
+		return ChannelStable, nil
+	case ChannelBeta:
+		return ChannelBeta, nil
+	}
+	return "", fmt.Errorf("unknown update channel %q (want stable or beta)", name)
+}
+
 // Release represents a GitHub release
 type Release struct {
-	TagName string  `json:"tag_name"`
-	Name    string  `json:"name"`
-	Assets  []Asset `json:"assets"`
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
 }
 
 // Asset represents a release asset
@@ -34,6 +57,8 @@ type UpdateInfo struct {
 	UpdateURL      string
 	ReleaseNotes   string
 	Size           int64
+	Channel        Channel
+	Prerelease     bool
 }
 
 // Updater handles application updates
@@ -41,46 +66,43 @@ type Updater struct {
 	currentVersion string
 	repoURL        string
 	executablePath string
+	channel        Channel
 }
 
-// NewUpdater creates a new updater instance
+// NewUpdater creates a new updater instance on the stable channel
 func NewUpdater(currentVersion, repoURL string) (*Updater, error) {
+	return NewUpdaterWithChannel(currentVersion, repoURL, ChannelStable)
+}
+
+// NewUpdaterWithChannel creates a new updater instance following the given
+// release channel
+func NewUpdaterWithChannel(currentVersion, repoURL string, channel Channel) (*Updater, error) {
 	execPath, err := os.Executable()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get executable path: %v", err)
 	}
+	if channel == "" {
+		channel = ChannelStable
+	}
 
 	return &Updater{
 		currentVersion: currentVersion,
 		repoURL:        repoURL,
 		executablePath: execPath,
+		channel:        channel,
 	}, nil
 }
 
-// CheckForUpdates checks if a new version is available
-func (u *Updater) CheckForUpdates() (*UpdateInfo, error) {
-	// Get latest release from GitHub API
-	apiURL := strings.Replace(u.repoURL, "github.com", "api.github.com/repos", 1)
-	apiURL = strings.TrimSuffix(apiURL, ".git") + "/releases/latest"
-
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch releases: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitHub API returned status: %d", resp.StatusCode)
-	}
+// Channel returns the release channel the updater follows
+func (u *Updater) Channel() Channel {
+	return u.channel
+}
 
-	body, err := io.ReadAll(resp.Body)
+// CheckForUpdates checks if a new version is available on the updater's channel
+func (u *Updater) CheckForUpdates() (*UpdateInfo, error) {
+	release, err := u.latestRelease()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	var release Release
-	if err := json.Unmarshal(body, &release); err != nil {
-		return nil, fmt.Errorf("failed to parse release: %v", err)
+		return nil, err
 	}
 
 	// Check if update is needed
@@ -111,9 +133,59 @@ func (u *Updater) CheckForUpdates() (*UpdateInfo, error) {
 		UpdateURL:      downloadURL,
 		ReleaseNotes:   release.Name,
 		Size:           size,
+		Channel:        u.channel,
+		Prerelease:     release.Prerelease,
 	}, nil
 }
 
+// latestRelease fetches the newest release on the updater's channel. Stable
+// uses GitHub's latest release, which skips pre-releases and drafts; beta
+// takes the newest published release of any kind.
+func (u *Updater) latestRelease() (Release, error) {
+	apiURL := strings.Replace(u.repoURL, "github.com", "api.github.com/repos", 1)
+	apiURL = strings.TrimSuffix(apiURL, ".git")
+	if u.channel == ChannelBeta {
+		apiURL += "/releases?per_page=30"
+	} else {
+		apiURL += "/releases/latest"
+	}
+
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to fetch releases: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return Release{}, fmt.Errorf("GitHub API returned status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if u.channel != ChannelBeta {
+		var release Release
+		if err := json.Unmarshal(body, &release); err != nil {
+			return Release{}, fmt.Errorf("failed to parse release: %v", err)
+		}
+		return release, nil
+	}
+
+	// GitHub lists releases newest first
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return Release{}, fmt.Errorf("failed to parse releases: %v", err)
+	}
+	for _, release := range releases {
+		if !release.Draft {
+			return release, nil
+		}
+	}
+	return Release{}, fmt.Errorf("no published releases found")
+}
+
 // PerformUpdate downloads and installs the update
 func (u *Updater) PerformUpdate(updateInfo *UpdateInfo) error {
 	// Create temporary directory
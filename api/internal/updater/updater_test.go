@@ -758,4 +758,66 @@ func TestConcurrentUpdateChecks(t *testing.T) {
 	for i := 0; i < numGoroutines; i++ {
 		<-done
 	}
-}
\ No newline at end of file
+}
+func TestParseChannel(t *testing.T) {
+	for name, want := range map[string]Channel{"": ChannelStable, "stable": ChannelStable, "Beta": ChannelBeta} {
+		got, err := ParseChannel(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseChannel("nightly")
+	assert.Error(t, err)
+}
+
+func TestCheckForUpdates_Channels(t *testing.T) {
+	asset := func(tag string) []Asset {
+		name := "app-" + (&Updater{}).getAssetName() + ".zip"
+		return []Asset{{Name: name, BrowserDownloadURL: "https://example.com/" + tag + "/" + name, Size: 10}}
+	}
+	stable := Release{TagName: "v1.1.0", Name: "Version 1.1.0", Assets: asset("v1.1.0")}
+	beta := Release{TagName: "v1.2.0-beta.1", Name: "Version 1.2.0 beta 1", Prerelease: true, Assets: asset("v1.2.0-beta.1")}
+	draft := Release{TagName: "v1.3.0-beta.1", Draft: true, Assets: asset("v1.3.0-beta.1")}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/releases/latest":
+			json.NewEncoder(w).Encode(stable)
+		case "/releases":
+			json.NewEncoder(w).Encode([]Release{draft, beta, stable})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		channel    Channel
+		latest     string
+		prerelease bool
+	}{
+		{ChannelStable, "v1.1.0", false},
+		{ChannelBeta, "v1.2.0-beta.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.channel), func(t *testing.T) {
+			u, err := NewUpdaterWithChannel("v1.0.0", server.URL, tt.channel)
+			require.NoError(t, err)
+			assert.Equal(t, tt.channel, u.Channel())
+
+			info, err := u.CheckForUpdates()
+			require.NoError(t, err)
+			require.NotNil(t, info)
+			assert.Equal(t, tt.latest, info.LatestVersion)
+			assert.Equal(t, tt.channel, info.Channel)
+			assert.Equal(t, tt.prerelease, info.Prerelease)
+		})
+	}
+
+	u, err := NewUpdaterWithChannel("v1.2.0-beta.1", server.URL, ChannelBeta)
+	require.NoError(t, err)
+	info, err := u.CheckForUpdates()
+	require.NoError(t, err)
+	assert.Nil(t, info, "already on the newest beta")
+}
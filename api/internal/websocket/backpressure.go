@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+
+	"isxcli/internal/infrastructure"
+)
+
+// Each client's send channel is its bounded queue. When a slow client lets
+// it fill up, the oldest queued progress event is dropped to make room: a
+// newer one supersedes it anyway. Terminal events (completion, errors,
+// status changes) are never dropped; a client whose queue is full of them
+// is disconnected.
+
+// droppableTypes are progress events superseded by the next one of their kind
+var droppableTypes = map[string]bool{
+	TypeProgress:         true,
+	TypePipelineProgress: true,
+	"operation_progress": true,
+}
+
+// ClientQueueStats describes one client's send queue
+type ClientQueueStats struct {
+	ClientID string `json:"client_id"`
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	Dropped  int64  `json:"dropped"`
+}
+
+// classifyMessage returns a message's type and whether it may be dropped
+// under backpressure. Operation snapshots are droppable until they report a
+// final status.
+func classifyMessage(message []byte) (string, bool) {
+	var envelope struct {
+		Type string `json:"type"`
+		Data struct {
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return "unknown", false
+	}
+
+	if envelope.Type == "operation:snapshot" {
+		switch envelope.Data.Status {
+		case "pending", "running":
+			return envelope.Type, true
+		}
+		return envelope.Type, false
+	}
+	return envelope.Type, droppableTypes[envelope.Type]
+}
+
+// deliver queues a message for a client, dropping the oldest queued progress
+// event when the queue is full. It returns false when the queue holds only
+// terminal events and the client has to be disconnected. Only the hub loop
+// writes to client.send, so the queue cannot grow while it is rearranged.
+func (h *Hub) deliver(client *Client, message []byte) bool {
+	select {
+	case client.send <- message:
+		return true
+	default:
+	}
+
+	// The writer may keep draining meanwhile, which only frees space
+	queued := make([][]byte, 0, cap(client.send))
+drain:
+	for {
+		select {
+		case m := <-client.send:
+			queued = append(queued, m)
+		default:
+			break drain
+		}
+	}
+
+	messageType, droppable := classifyMessage(message)
+	keepMessage := true
+	if len(queued) >= cap(client.send) {
+		victim := -1
+		for i, m := range queued {
+			if _, ok := classifyMessage(m); ok {
+				victim = i
+				break
+			}
+		}
+
+		switch {
+		case victim >= 0:
+			messageType, _ = classifyMessage(queued[victim])
+			queued = append(queued[:victim], queued[victim+1:]...)
+		case droppable:
+			keepMessage = false
+		default:
+			for _, m := range queued {
+				client.send <- m
+			}
+			return false
+		}
+		h.recordDrop(client, messageType)
+	}
+
+	for _, m := range queued {
+		client.send <- m
+	}
+	if keepMessage {
+		client.send <- message
+	}
+	return true
+}
+
+// recordDrop counts a message dropped from a client's queue
+func (h *Hub) recordDrop(client *Client, messageType string) {
+	atomic.AddInt64(&client.dropped, 1)
+	atomic.AddInt64(&h.droppedMessages, 1)
+	GetMetrics().RecordDroppedMessage()
+
+	ctx := context.Background()
+	if client.traceID != "" {
+		ctx = infrastructure.WithTraceID(ctx, client.traceID)
+	}
+	if otelMetrics := GetOTelMetrics(); otelMetrics != nil {
+		otelMetrics.RecordDroppedMessage(ctx, messageType, "client_queue_full")
+	}
+	h.logger.DebugContext(ctx, "Dropped progress message for slow client",
+		slog.String("client_id", client.id),
+		slog.String("message_type", messageType))
+}
+
+// ClientQueues returns the send queue of every connected client, deepest first
+func (h *Hub) ClientQueues() []ClientQueueStats {
+	h.mu.RLock()
+	stats := make([]ClientQueueStats, 0, len(h.clients))
+	for client := range h.clients {
+		stats = append(stats, ClientQueueStats{
+			ClientID: client.id,
+			Depth:    len(client.send),
+			Capacity: cap(client.send),
+			Dropped:  atomic.LoadInt64(&client.dropped),
+		})
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Depth != stats[j].Depth {
+			return stats[i].Depth > stats[j].Depth
+		}
+		return stats[i].ClientID < stats[j].ClientID
+	})
+	return stats
+}
@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func progressMessage(t *testing.T, step int) []byte {
+	msg, err := json.Marshal(map[string]interface{}{
+		"type": TypeProgress,
+		"data": map[string]interface{}{"step": "scraping", "progress": step},
+	})
+	require.NoError(t, err)
+	return msg
+}
+
+func completeMessage(t *testing.T, id string) []byte {
+	msg, err := json.Marshal(map[string]interface{}{
+		"type": TypePipelineComplete,
+		"data": map[string]interface{}{"operation_id": id},
+	})
+	require.NoError(t, err)
+	return msg
+}
+
+func drainMessages(client *Client) []string {
+	var types []string
+	for {
+		select {
+		case m := <-client.send:
+			var envelope map[string]interface{}
+			_ = json.Unmarshal(m, &envelope)
+			typ, _ := envelope["type"].(string)
+			if data, ok := envelope["data"].(map[string]interface{}); ok {
+				if p, ok := data["progress"].(float64); ok {
+					typ += ":" + string(rune('0'+int(p)))
+				}
+			}
+			types = append(types, typ)
+		default:
+			return types
+		}
+	}
+}
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		droppable bool
+	}{
+		{"progress", `{"type":"progress"}`, true},
+		{"pipeline progress", `{"type":"operation:progress"}`, true},
+		{"running snapshot", `{"type":"operation:snapshot","data":{"status":"running"}}`, true},
+		{"completed snapshot", `{"type":"operation:snapshot","data":{"status":"completed"}}`, false},
+		{"complete", `{"type":"operation:complete"}`, false},
+		{"error", `{"type":"error"}`, false},
+		{"not json", `message 1`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, droppable := classifyMessage([]byte(tt.message))
+			assert.Equal(t, tt.droppable, droppable)
+		})
+	}
+}
+
+func TestHubDeliver_DropsOldestProgress(t *testing.T) {
+	hub := NewHub(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	client := &Client{id: "slow", hub: hub, send: make(chan []byte, 3), connectedAt: time.Now()}
+
+	require.True(t, hub.deliver(client, progressMessage(t, 1)))
+	require.True(t, hub.deliver(client, completeMessage(t, "op-1")))
+	require.True(t, hub.deliver(client, progressMessage(t, 2)))
+
+	// The queue is full: the oldest progress event makes room
+	require.True(t, hub.deliver(client, progressMessage(t, 3)))
+	// and the terminal event survives another overflow
+	require.True(t, hub.deliver(client, completeMessage(t, "op-2")))
+
+	assert.Equal(t, []string{TypePipelineComplete, "progress:3", TypePipelineComplete}, drainMessages(client))
+	assert.Equal(t, int64(2), client.dropped)
+	assert.Equal(t, int64(2), hub.droppedMessages)
+}
+
+func TestHubDeliver_QueueOfTerminalEvents(t *testing.T) {
+	hub := NewHub(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	client := &Client{id: "stuck", hub: hub, send: make(chan []byte, 2), connectedAt: time.Now()}
+
+	require.True(t, hub.deliver(client, completeMessage(t, "op-1")))
+	require.True(t, hub.deliver(client, completeMessage(t, "op-2")))
+
+	// A new progress event is the one to drop
+	assert.True(t, hub.deliver(client, progressMessage(t, 1)))
+	assert.Equal(t, int64(1), client.dropped)
+
+	// A terminal event cannot be delivered without dropping another
+	assert.False(t, hub.deliver(client, completeMessage(t, "op-3")))
+	assert.Equal(t, []string{TypePipelineComplete, TypePipelineComplete}, drainMessages(client))
+}
+
+func TestHubBroadcast_SlowClientStaysConnected(t *testing.T) {
+	hub := NewHub(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	hub.Start()
+	defer hub.Stop()
+
+	client := &Client{id: "slow", hub: hub, send: make(chan []byte, 4), connectedAt: time.Now()}
+	hub.Register(client)
+
+	for i := 0; i < 20; i++ {
+		hub.broadcast <- progressMessage(t, i%10)
+	}
+	hub.broadcast <- completeMessage(t, "op-1")
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&client.dropped) == 18 && len(client.send) == 4
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, hub.ClientCount(), "progress backpressure never disconnects")
+
+	queues := hub.ClientQueues()
+	require.Len(t, queues, 1)
+	assert.Equal(t, ClientQueueStats{ClientID: "slow", Depth: 4, Capacity: 4, Dropped: 18}, queues[0])
+
+	assert.Equal(t, int64(18), atomic.LoadInt64(&hub.droppedMessages))
+
+	types := drainMessages(client)
+	assert.Equal(t, TypePipelineComplete, types[len(types)-1], "the terminal event is delivered")
+}
@@ -54,6 +54,7 @@ type Client struct {
 	messagesReceived int64
 	bytessSent       int64
 	bytesReceived    int64
+	dropped          int64 // Progress events dropped under backpressure; atomic
 }
 
 // NewClient creates a new Client with dependency injection
@@ -0,0 +1,143 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// defaultEventLogSize is how many broadcasts the hub keeps for replay. A
+// full pipeline run emits a few hundred snapshots, so a client that
+// reconnects within a run can always catch up.
+const defaultEventLogSize = 1024
+
+// Event is one broadcast message as recorded in the event log. IDs increase
+// by one per broadcast and are what SSE clients send back as Last-Event-ID.
+type Event struct {
+	ID          uint64
+	Type        string
+	OperationID string
+	Data        []byte
+	// Terminal is set for the operation snapshot that reports a final status
+	Terminal bool
+}
+
+// EventLog keeps the most recent broadcasts in a ring buffer so transports
+// other than the WebSocket (server-sent events) can replay missed events and
+// follow new ones. It is safe for concurrent use.
+type EventLog struct {
+	mu       sync.RWMutex
+	events   []Event
+	next     int // ring position of the next append
+	lastID   uint64
+	watchers map[chan struct{}]struct{}
+}
+
+// NewEventLog creates an event log holding up to capacity events
+func NewEventLog(capacity int) *EventLog {
+	if capacity <= 0 {
+		capacity = defaultEventLogSize
+	}
+	return &EventLog{
+		events:   make([]Event, 0, capacity),
+		watchers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Append records a broadcast message, evicting the oldest event when the log
+// is full, and wakes every subscriber
+func (l *EventLog) Append(message []byte) Event {
+	var envelope struct {
+		Type string `json:"type"`
+		Data struct {
+			OperationID string `json:"operation_id"`
+			Status      string `json:"status"`
+		} `json:"data"`
+	}
+	// Messages that are not JSON objects are still recorded, untyped
+	_ = json.Unmarshal(message, &envelope)
+
+	l.mu.Lock()
+	l.lastID++
+	event := Event{
+		ID:          l.lastID,
+		Type:        envelope.Type,
+		OperationID: envelope.Data.OperationID,
+		Data:        message,
+		Terminal:    envelope.Type == "operation:snapshot" && isFinalStatus(envelope.Data.Status),
+	}
+	if len(l.events) < cap(l.events) {
+		l.events = append(l.events, event)
+	} else {
+		l.events[l.next] = event
+	}
+	l.next = (l.next + 1) % cap(l.events)
+	for watcher := range l.watchers {
+		select {
+		case watcher <- struct{}{}:
+		default:
+		}
+	}
+	l.mu.Unlock()
+
+	return event
+}
+
+// Since returns the events of operationID recorded after lastID, oldest
+// first. An empty operationID matches every event. An ID the log no longer
+// holds (or one from before a restart) returns everything still buffered.
+func (l *EventLog) Since(lastID uint64, operationID string) []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if lastID > l.lastID {
+		lastID = 0
+	}
+	var out []Event
+	n := len(l.events)
+	start := 0
+	if n == cap(l.events) {
+		start = l.next
+	}
+	for i := 0; i < n; i++ {
+		event := l.events[(start+i)%n]
+		if event.ID <= lastID {
+			continue
+		}
+		if operationID != "" && event.OperationID != operationID {
+			continue
+		}
+		out = append(out, event)
+	}
+	return out
+}
+
+// LastID returns the ID of the most recent event, or 0 if none was recorded
+func (l *EventLog) LastID() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastID
+}
+
+// Subscribe returns a channel that receives a signal after each append, and
+// a function that ends the subscription. Signals coalesce: a slow reader
+// sees one wakeup for several appends and catches up with Since.
+func (l *EventLog) Subscribe() (<-chan struct{}, func()) {
+	watcher := make(chan struct{}, 1)
+	l.mu.Lock()
+	l.watchers[watcher] = struct{}{}
+	l.mu.Unlock()
+
+	return watcher, func() {
+		l.mu.Lock()
+		delete(l.watchers, watcher)
+		l.mu.Unlock()
+	}
+}
+
+func isFinalStatus(status string) bool {
+	switch status {
+	case "completed", "failed", "cancelled":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotMessage(operationID, status string) []byte {
+	return []byte(fmt.Sprintf(`{"type":"operation:snapshot","data":{"operation_id":%q,"status":%q}}`, operationID, status))
+}
+
+func TestEventLogAppend(t *testing.T) {
+	log := NewEventLog(8)
+
+	first := log.Append(snapshotMessage("op-1", "running"))
+	second := log.Append(snapshotMessage("op-1", "completed"))
+	other := log.Append([]byte(`{"type":"data:updated","data":{}}`))
+
+	assert.Equal(t, uint64(1), first.ID)
+	assert.Equal(t, "operation:snapshot", first.Type)
+	assert.Equal(t, "op-1", first.OperationID)
+	assert.False(t, first.Terminal)
+	assert.True(t, second.Terminal)
+	assert.Equal(t, "", other.OperationID)
+	assert.Equal(t, uint64(3), log.LastID())
+}
+
+func TestEventLogSince(t *testing.T) {
+	log := NewEventLog(8)
+	log.Append(snapshotMessage("op-1", "running"))
+	log.Append(snapshotMessage("op-2", "running"))
+	log.Append(snapshotMessage("op-1", "running"))
+	log.Append(snapshotMessage("op-1", "completed"))
+
+	t.Run("all events of an operation", func(t *testing.T) {
+		events := log.Since(0, "op-1")
+		require.Len(t, events, 3)
+		assert.Equal(t, []uint64{1, 3, 4}, eventIDs(events))
+	})
+
+	t.Run("resume after last seen id", func(t *testing.T) {
+		assert.Equal(t, []uint64{4}, eventIDs(log.Since(3, "op-1")))
+		assert.Empty(t, log.Since(4, "op-1"))
+	})
+
+	t.Run("empty operation matches everything", func(t *testing.T) {
+		assert.Len(t, log.Since(0, ""), 4)
+	})
+
+	t.Run("id from before a restart replays the buffer", func(t *testing.T) {
+		assert.Len(t, log.Since(99, "op-1"), 3)
+	})
+}
+
+func TestEventLogEvictsOldest(t *testing.T) {
+	log := NewEventLog(3)
+	for i := 0; i < 5; i++ {
+		log.Append(snapshotMessage("op-1", "running"))
+	}
+
+	events := log.Since(0, "op-1")
+	assert.Equal(t, []uint64{3, 4, 5}, eventIDs(events))
+	assert.Equal(t, []uint64{5}, eventIDs(log.Since(4, "op-1")))
+}
+
+func TestEventLogSubscribe(t *testing.T) {
+	log := NewEventLog(8)
+	wake, unsubscribe := log.Subscribe()
+
+	log.Append(snapshotMessage("op-1", "running"))
+	log.Append(snapshotMessage("op-1", "running"))
+
+	select {
+	case <-wake:
+	default:
+		t.Fatal("expected a wakeup after append")
+	}
+	// Both appends coalesce into a single signal
+	select {
+	case <-wake:
+		t.Fatal("expected signals to coalesce")
+	default:
+	}
+
+	unsubscribe()
+	log.Append(snapshotMessage("op-1", "completed"))
+	select {
+	case <-wake:
+		t.Fatal("unsubscribed channel should not be signalled")
+	default:
+	}
+}
+
+func TestHubRecordsBroadcasts(t *testing.T) {
+	hub := NewHub(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	hub.Start()
+	defer hub.Stop()
+
+	hub.BroadcastUpdate("operation:snapshot", "op-1", "update", map[string]interface{}{
+		"operation_id": "op-1",
+		"status":       "running",
+	})
+
+	require.Eventually(t, func() bool {
+		return len(hub.Events().Since(0, "op-1")) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"isxcli/internal/infrastructure"
@@ -17,6 +18,8 @@ const (
 	TypeOutput           = "output"
 	TypeError            = "error"
 	TypeDataUpdate       = "data_update"
+	TypeDataUpdated      = "data:updated" // Carries the delta of a processing run
+	TypeDataAnomalies    = "data:anomalies" // Summarizes the anomalies a processing run flagged
 	TypeOperationStatus  = "operation:status"
 	TypePipelineProgress = "operation:progress"
 	TypePipelineComplete = "operation:complete"
@@ -62,6 +65,10 @@ type Hub struct {
 	messagesSent     int64
 	messagesReceived int64
 	connectionErrors int64
+	droppedMessages  int64 // Progress events dropped from slow clients' queues
+
+	// Recent broadcasts, replayed to server-sent event clients
+	events *EventLog
 
 	// Control
 	quit        chan struct{}
@@ -84,6 +91,7 @@ func NewHub(logger *slog.Logger) *Hub {
 		logger:      logger,
 		quit:        make(chan struct{}),
 		metricsQuit: make(chan struct{}),
+		events:      NewEventLog(defaultEventLogSize),
 	}
 
 	return hub
@@ -199,6 +207,8 @@ func (h *Hub) Run() {
 			}
 
 		case message := <-h.broadcast:
+			h.events.Append(message)
+
 			h.mu.RLock()
 			// Create a copy of clients to avoid holding lock during send
 			clients := make([]*Client, 0, len(h.clients))
@@ -216,25 +226,25 @@ func (h *Hub) Run() {
 
 			// Send to all clients
 			for _, client := range clients {
-				select {
-				case client.send <- message:
+				if h.deliver(client, message) {
 					successCount++
 					h.messagesSent++
-				default:
-					failCount++
-					// Client's send channel is full, close it
-					h.mu.Lock()
-					close(client.send)
-					delete(h.clients, client)
-					h.mu.Unlock()
-
-					ctx := context.Background()
-					if client.traceID != "" {
-						ctx = infrastructure.WithTraceID(ctx, client.traceID)
-					}
-					h.logger.WarnContext(ctx, "Client send buffer full, disconnecting",
-						slog.String("client_id", client.id))
+					continue
 				}
+
+				failCount++
+				// Only terminal events are queued and none can be dropped
+				h.mu.Lock()
+				close(client.send)
+				delete(h.clients, client)
+				h.mu.Unlock()
+
+				ctx := context.Background()
+				if client.traceID != "" {
+					ctx = infrastructure.WithTraceID(ctx, client.traceID)
+				}
+				h.logger.WarnContext(ctx, "Client send queue full of undroppable messages, disconnecting",
+					slog.String("client_id", client.id))
 			}
 
 			// Log full JSON (truncated) for troubleshooting at info level
@@ -263,6 +273,11 @@ func (h *Hub) Run() {
 	}
 }
 
+// Events returns the log of recent broadcasts that SSE clients follow
+func (h *Hub) Events() *EventLog {
+	return h.events
+}
+
 // BroadcastUpdate sends a data update message to all connected clients
 func (h *Hub) BroadcastUpdate(updateType, subtype, action string, data interface{}) {
 	h.BroadcastUpdateWithTrace(updateType, subtype, action, data, "")
@@ -539,8 +554,16 @@ func (h *Hub) reportMetrics() {
 			activeClients := len(h.clients)
 			h.mu.RUnlock()
 
+			maxClientQueue := 0
+			if queues := h.ClientQueues(); len(queues) > 0 {
+				maxClientQueue = queues[0].Depth
+			}
+
 			metrics := GetMetrics()
 			metrics.RecordQueueDepth(int64(len(h.broadcast)))
+			if otelMetrics := GetOTelMetrics(); otelMetrics != nil {
+				otelMetrics.RecordQueueDepth(context.Background(), int64(maxClientQueue), "client_send")
+			}
 
 			// Log current metrics
 			h.logger.Info("WebSocket hub metrics",
@@ -549,6 +572,8 @@ func (h *Hub) reportMetrics() {
 				slog.Int64("messages_sent", h.messagesSent),
 				slog.Int64("messages_received", h.messagesReceived),
 				slog.Int("broadcast_queue", len(h.broadcast)),
+				slog.Int("max_client_queue", maxClientQueue),
+				slog.Int64("dropped_messages", atomic.LoadInt64(&h.droppedMessages)),
 			)
 		}
 	}
@@ -556,6 +581,8 @@ func (h *Hub) reportMetrics() {
 
 // GetHubMetrics returns current hub metrics
 func (h *Hub) GetHubMetrics() map[string]interface{} {
+	queues := h.ClientQueues()
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -565,5 +592,7 @@ func (h *Hub) GetHubMetrics() map[string]interface{} {
 		"messages_sent":     h.messagesSent,
 		"messages_received": h.messagesReceived,
 		"connection_errors": h.connectionErrors,
+		"dropped_messages":  atomic.LoadInt64(&h.droppedMessages),
+		"client_queues":     queues,
 	}
 }
@@ -23,8 +23,23 @@ type TradeRecord struct {
 	Volume           int64     `json:"volume" db:"volume" validate:"min=0"`
 	Value            float64   `json:"value" db:"value" validate:"min=0"`
 	TradingStatus    bool      `json:"trading_status" db:"trading_status"` // true if actively traded, false if forward-filled
+	// FillMethod records how the row was produced: FillMethodActual for
+	// traded days, otherwise the gap-fill strategy that synthesized it
+	FillMethod string `json:"fill_method,omitempty" db:"fill_method"`
+
+	// Intraday holds optional intraday bars for the day when an ISX intraday
+	// bulletin was available. It is never written to the daily CSV outputs.
+	Intraday []IntradayData `json:"intraday,omitempty" db:"-"`
 }
 
+// Fill methods recorded in TradeRecord.FillMethod
+const (
+	FillMethodActual       = "actual"        // Traded on the day
+	FillMethodCarryForward = "carry_forward" // Last close carried flat
+	FillMethodNaN          = "nan"           // Prices marked NaN
+	FillMethodInterpolated = "interpolated"  // Close interpolated between traded days
+)
+
 // DailyReport represents all trades in a single day's ISX report file.
 // It contains the complete set of trading records for all companies
 // that were included in the daily bulletin.
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Step progress protocol between the operations stages and the child
+// commands they run (scraper, processor, indexcsv). Each event is a single
+// stdout line: StepProgressPrefix followed by a JSON StepProgressEvent.
+// Regular log lines can be interleaved freely; the prefix tells them apart.
+const (
+	StepProgressPrefix  = "@@ISX_PROGRESS@@ "
+	StepProgressVersion = 1
+)
+
+// StepProgressEventType identifies what a progress event reports
+type StepProgressEventType string
+
+const (
+	// StepEventStart announces the amount of work: Total and optionally Files
+	StepEventStart StepProgressEventType = "start"
+	// StepEventProgress reports that item Current of Total (File) was handled
+	StepEventProgress StepProgressEventType = "progress"
+	// StepEventSkip reports an item that was intentionally skipped, e.g. a holiday
+	StepEventSkip StepProgressEventType = "skip"
+	// StepEventStatus carries a human-readable status Message without counts
+	StepEventStatus StepProgressEventType = "status"
+	// StepEventComplete reports that the command finished its work
+	StepEventComplete StepProgressEventType = "complete"
+	// StepEventBufferZone reports that the scraper reached a report dated
+	// before the requested range (Date), so older pages are not needed
+	StepEventBufferZone StepProgressEventType = "buffer_zone"
+	// StepEventStop reports why the scraper stopped looking for reports
+	StepEventStop StepProgressEventType = "stop"
+	// StepEventBatch reports batch Current of Total of a date range split
+	// into batches, covering From to To, with a BatchStatus* Status
+	StepEventBatch StepProgressEventType = "batch"
+)
+
+// Batch statuses carried by StepEventBatch
+const (
+	BatchStatusStarted = "started"
+	BatchStatusDone    = "done"
+	// BatchStatusSkipped: the batch was finished by an earlier, interrupted run
+	BatchStatusSkipped = "skipped"
+)
+
+// Stop reasons carried by StepEventStop
+const (
+	// StopReasonComplete: every day of the range was downloaded or is a holiday
+	StopReasonComplete = "complete"
+	// StopReasonAlreadyPresent: the files on disk covered the range before searching
+	StopReasonAlreadyPresent = "already_present"
+	// StopReasonBufferZone: reports older than the range were reached
+	StopReasonBufferZone = "buffer_zone"
+	// StopReasonExistingFiles: a results page held mostly reports already on disk
+	StopReasonExistingFiles = "existing_files"
+	// StopReasonEndOfResults: the portal listed no further reports
+	StopReasonEndOfResults = "end_of_results"
+	StopReasonCancelled    = "cancelled"
+	StopReasonError        = "error"
+)
+
+// Item statuses used by progress events
+const (
+	ItemStatusDownloading = "downloading"
+	ItemStatusExists      = "exists"
+	ItemStatusProcessing  = "processing"
+	ItemStatusHoliday     = "holiday"
+)
+
+// StepProgressEvent is one structured progress event emitted by a child command
+type StepProgressEvent struct {
+	Version   int                   `json:"v"`
+	Event     StepProgressEventType `json:"event"`
+	Step      string                `json:"step"`
+	Current   int                   `json:"current,omitempty"`
+	Total     int                   `json:"total,omitempty"`
+	File      string                `json:"file,omitempty"`
+	Files     []string              `json:"files,omitempty"`
+	Status    string                `json:"status,omitempty"`
+	Message   string                `json:"message,omitempty"`
+	Date      string                `json:"date,omitempty"`   // Trading day (YYYY-MM-DD) of an item or buffer zone event
+	Reason    string                `json:"reason,omitempty"` // StopReason* of a stop event
+	From      string                `json:"from,omitempty"`   // First day (YYYY-MM-DD) of a batch
+	To        string                `json:"to,omitempty"`     // Last day (YYYY-MM-DD) of a batch
+	Timestamp time.Time             `json:"ts"`
+}
+
+// ProgressEmitter writes step progress events for one step. It is safe for
+// concurrent use.
+type ProgressEmitter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	step string
+	now  func() time.Time
+}
+
+// NewProgressEmitter creates an emitter writing events for step to w
+func NewProgressEmitter(w io.Writer, step string) *ProgressEmitter {
+	return &ProgressEmitter{w: w, step: step, now: time.Now}
+}
+
+// Emit writes a single event line, filling in version, step and timestamp
+func (e *ProgressEmitter) Emit(ev StepProgressEvent) {
+	ev.Version = StepProgressVersion
+	ev.Step = e.step
+	ev.Timestamp = e.now().UTC()
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// One Write per line so events are never split by concurrent log output
+	fmt.Fprintf(e.w, "%s%s\n", StepProgressPrefix, data)
+}
+
+// Start announces the total number of items and, optionally, their names
+func (e *ProgressEmitter) Start(total int, files []string) {
+	e.Emit(StepProgressEvent{Event: StepEventStart, Total: total, Files: files})
+}
+
+// Progress reports item current of total
+func (e *ProgressEmitter) Progress(current, total int, file, status string) {
+	e.Emit(StepProgressEvent{Event: StepEventProgress, Current: current, Total: total, File: file, Status: status})
+}
+
+// Skip reports an item that was skipped on purpose
+func (e *ProgressEmitter) Skip(file, status string) {
+	e.Emit(StepProgressEvent{Event: StepEventSkip, File: file, Status: status})
+}
+
+// Status reports a free-text status message
+func (e *ProgressEmitter) Status(message string) {
+	e.Emit(StepProgressEvent{Event: StepEventStatus, Message: message})
+}
+
+// Complete reports that the step's work is done
+func (e *ProgressEmitter) Complete(current, total int, message string) {
+	e.Emit(StepProgressEvent{Event: StepEventComplete, Current: current, Total: total, Message: message})
+}
+
+// ProgressDay reports item current of total for the report of one trading day
+func (e *ProgressEmitter) ProgressDay(current, total int, file, status string, date time.Time) {
+	e.Emit(StepProgressEvent{Event: StepEventProgress, Current: current, Total: total, File: file, Status: status, Date: date.Format("2006-01-02")})
+}
+
+// Holiday reports a weekday without a report. File carries the date as
+// "2006 01 02" for consumers that only read File.
+func (e *ProgressEmitter) Holiday(date time.Time) {
+	e.Emit(StepProgressEvent{Event: StepEventSkip, File: date.Format("2006 01 02"), Status: ItemStatusHoliday, Date: date.Format("2006-01-02")})
+}
+
+// BufferZone reports that a report dated before the requested range was reached
+func (e *ProgressEmitter) BufferZone(date time.Time, message string) {
+	e.Emit(StepProgressEvent{Event: StepEventBufferZone, Date: date.Format("2006-01-02"), Message: message})
+}
+
+// Batch reports batch current of total, covering from to to, with a
+// BatchStatus* status
+func (e *ProgressEmitter) Batch(current, total int, status string, from, to time.Time) {
+	e.Emit(StepProgressEvent{Event: StepEventBatch, Current: current, Total: total, Status: status, From: from.Format("2006-01-02"), To: to.Format("2006-01-02")})
+}
+
+// Stop reports why the command stopped looking for more work
+func (e *ProgressEmitter) Stop(reason, message string) {
+	e.Emit(StepProgressEvent{Event: StepEventStop, Reason: reason, Message: message})
+}
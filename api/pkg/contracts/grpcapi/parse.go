@@ -0,0 +1,311 @@
+package grpcapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// scalarTypes maps proto3 scalar type names to descriptor types
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double": descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":  descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int32":  descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint32": descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+}
+
+// ParseProto parses a proto3 file into a file descriptor. It reads the
+// subset isxpulse.proto is written in: syntax, package and option
+// statements, messages whose fields are scalars or messages of the same
+// package (optionally repeated or optional), and services of unary methods.
+// Anything else is an error rather than silently dropped.
+func ParseProto(name, source string) (*descriptorpb.FileDescriptorProto, error) {
+	p := &protoParser{name: name, tokens: tokenize(source)}
+	fd := &descriptorpb.FileDescriptorProto{Name: proto.String(name)}
+	if err := p.parseFile(fd); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return fd, nil
+}
+
+type token struct {
+	text string
+	line int
+}
+
+type protoParser struct {
+	name   string
+	tokens []token
+	pos    int
+	pkg    string
+}
+
+// tokenize splits source into identifiers, numbers, quoted strings and
+// punctuation, dropping // comments
+func tokenize(source string) []token {
+	var tokens []token
+	for i, line := range strings.Split(source, "\n") {
+		for j := 0; j < len(line); {
+			c := rune(line[j])
+			switch {
+			case unicode.IsSpace(c):
+				j++
+			case strings.HasPrefix(line[j:], "//"):
+				j = len(line)
+			case c == '"':
+				end := strings.IndexByte(line[j+1:], '"')
+				if end < 0 {
+					end = len(line) - j - 1
+				}
+				tokens = append(tokens, token{line[j : j+end+2], i + 1})
+				j += end + 2
+			case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.':
+				k := j
+				for k < len(line) && (unicode.IsLetter(rune(line[k])) || unicode.IsDigit(rune(line[k])) || line[k] == '_' || line[k] == '.') {
+					k++
+				}
+				tokens = append(tokens, token{line[j:k], i + 1})
+				j = k
+			default:
+				tokens = append(tokens, token{string(c), i + 1})
+				j++
+			}
+		}
+	}
+	return tokens
+}
+
+func (p *protoParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *protoParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of file")
+	}
+	p.pos++
+	return p.tokens[p.pos-1].text, nil
+}
+
+func (p *protoParser) errorf(format string, args ...interface{}) error {
+	line := 0
+	if p.pos > 0 && p.pos <= len(p.tokens) {
+		line = p.tokens[p.pos-1].line
+	}
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *protoParser) expect(want string) error {
+	got, err := p.next()
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return p.errorf("expected %q, found %q", want, got)
+	}
+	return nil
+}
+
+// statement reads tokens up to the closing semicolon
+func (p *protoParser) statement() ([]string, error) {
+	var parts []string
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok == ";" {
+			return parts, nil
+		}
+		parts = append(parts, tok)
+	}
+}
+
+func (p *protoParser) parseFile(fd *descriptorpb.FileDescriptorProto) error {
+	for p.pos < len(p.tokens) {
+		keyword, _ := p.next()
+		switch keyword {
+		case "syntax":
+			parts, err := p.statement()
+			if err != nil {
+				return err
+			}
+			if len(parts) != 2 || parts[1] != `"proto3"` {
+				return p.errorf("only proto3 is supported")
+			}
+			fd.Syntax = proto.String("proto3")
+		case "package":
+			parts, err := p.statement()
+			if err != nil {
+				return err
+			}
+			if len(parts) != 1 {
+				return p.errorf("invalid package statement")
+			}
+			p.pkg = parts[0]
+			fd.Package = proto.String(p.pkg)
+		case "option":
+			parts, err := p.statement()
+			if err != nil {
+				return err
+			}
+			if len(parts) == 3 && parts[0] == "go_package" && parts[1] == "=" {
+				fd.Options = &descriptorpb.FileOptions{GoPackage: proto.String(strings.Trim(parts[2], `"`))}
+			}
+		case "message":
+			msg, err := p.parseMessage()
+			if err != nil {
+				return err
+			}
+			fd.MessageType = append(fd.MessageType, msg)
+		case "service":
+			svc, err := p.parseService()
+			if err != nil {
+				return err
+			}
+			fd.Service = append(fd.Service, svc)
+		default:
+			return p.errorf("unsupported statement %q", keyword)
+		}
+	}
+	if fd.GetSyntax() != "proto3" {
+		return fmt.Errorf("missing proto3 syntax statement")
+	}
+	return nil
+}
+
+// typeName returns the fully qualified name of a message of the package
+func (p *protoParser) typeName(name string) string {
+	if strings.Contains(name, ".") {
+		return "." + strings.TrimPrefix(name, ".")
+	}
+	return "." + p.pkg + "." + name
+}
+
+func (p *protoParser) parseMessage() (*descriptorpb.DescriptorProto, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+
+	for p.peek() != "}" {
+		parts, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		optional := false
+		switch {
+		case len(parts) > 0 && parts[0] == "repeated":
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+			parts = parts[1:]
+		case len(parts) > 0 && parts[0] == "optional":
+			optional = true
+			parts = parts[1:]
+		}
+		if len(parts) != 4 || parts[2] != "=" {
+			return nil, p.errorf("unsupported field in message %s", name)
+		}
+		number, err := strconv.Atoi(parts[3])
+		if err != nil || number < 1 {
+			return nil, p.errorf("invalid field number %q", parts[3])
+		}
+
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(parts[1]),
+			Number: proto.Int32(int32(number)),
+			Label:  label.Enum(),
+		}
+		if scalar, ok := scalarTypes[parts[0]]; ok {
+			field.Type = scalar.Enum()
+		} else {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			field.TypeName = proto.String(p.typeName(parts[0]))
+		}
+		if optional {
+			// proto3 optional fields live in a synthetic oneof of their own
+			field.Proto3Optional = proto.Bool(true)
+			field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+			msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + parts[1])})
+		}
+		msg.Field = append(msg.Field, field)
+	}
+	p.pos++ // closing brace
+	return msg, nil
+}
+
+func (p *protoParser) parseService() (*descriptorpb.ServiceDescriptorProto, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String(name)}
+
+	for p.peek() != "}" {
+		if err := p.expect("rpc"); err != nil {
+			return nil, err
+		}
+		method, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		var types [2]string
+		for i := range types {
+			if i == 1 {
+				if err := p.expect("returns"); err != nil {
+					return nil, err
+				}
+			}
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			if types[i], err = p.next(); err != nil {
+				return nil, err
+			}
+			if types[i] == "stream" {
+				return nil, p.errorf("streaming method %s is not supported", method)
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+		}
+		switch p.peek() {
+		case ";":
+			p.pos++
+		case "{":
+			p.pos++
+			if err := p.expect("}"); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("expected ; after method %s", method)
+		}
+
+		svc.Method = append(svc.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(method),
+			InputType:  proto.String(p.typeName(types[0])),
+			OutputType: proto.String(p.typeName(types[1])),
+		})
+	}
+	p.pos++ // closing brace
+	return svc, nil
+}
@@ -0,0 +1,80 @@
+// Package grpcapi contains the protobuf definitions of the ISX Pulse gRPC
+// API in isxpulse.proto.
+//
+// The definitions are embedded and turned into protobuf descriptors at
+// runtime, so the server works with dynamic messages and no generated code
+// is kept in the repository. Clients generate typed stubs from the same
+// file with protoc.
+package grpcapi
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ProtoFile is the path the definitions are registered under
+const ProtoFile = "isxpulse/v1/isxpulse.proto"
+
+// Full names of the services in isxpulse.proto
+const (
+	DataServiceName       = "isxpulse.v1.DataService"
+	LiquidityServiceName  = "isxpulse.v1.LiquidityService"
+	OperationsServiceName = "isxpulse.v1.OperationsService"
+)
+
+//go:embed isxpulse.proto
+var protoSource string
+
+var (
+	fileOnce sync.Once
+	file     protoreflect.FileDescriptor
+	fileErr  error
+)
+
+// Source returns the text of isxpulse.proto
+func Source() string {
+	return protoSource
+}
+
+// File returns the descriptor of isxpulse.proto. The first call parses it
+// and registers it in protoregistry.GlobalFiles, so gRPC server reflection
+// can serve it.
+func File() (protoreflect.FileDescriptor, error) {
+	fileOnce.Do(func() {
+		fdp, err := ParseProto(ProtoFile, protoSource)
+		if err != nil {
+			fileErr = err
+			return
+		}
+		if file, fileErr = protodesc.NewFile(fdp, protoregistry.GlobalFiles); fileErr != nil {
+			fileErr = fmt.Errorf("build %s: %w", ProtoFile, fileErr)
+			return
+		}
+		if err := protoregistry.GlobalFiles.RegisterFile(file); err != nil {
+			fileErr = fmt.Errorf("register %s: %w", ProtoFile, err)
+		}
+	})
+	return file, fileErr
+}
+
+// Service returns the descriptor of a service in isxpulse.proto by full name
+func Service(name string) (protoreflect.ServiceDescriptor, error) {
+	fd, err := File()
+	if err != nil {
+		return nil, err
+	}
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", name, err)
+	}
+	svc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok || svc.ParentFile() != fd {
+		return nil, fmt.Errorf("%s is not a service of %s", name, ProtoFile)
+	}
+	return svc, nil
+}
@@ -0,0 +1,79 @@
+package grpcapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestFile(t *testing.T) {
+	fd, err := File()
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("isxpulse.v1"), fd.Package())
+
+	for name, methods := range map[string][]string{
+		DataServiceName:       {"GetTickerHistory", "GetTickerBars", "GetMarketDiff"},
+		LiquidityServiceName:  {"GetLiquidityHistory", "GetSafeTrade"},
+		OperationsServiceName: {"StartOperation", "GetOperation", "ListOperations", "CancelOperation"},
+	} {
+		svc, err := Service(name)
+		require.NoError(t, err, name)
+		var got []string
+		for i := 0; i < svc.Methods().Len(); i++ {
+			got = append(got, string(svc.Methods().Get(i).Name()))
+		}
+		assert.Equal(t, methods, got, name)
+	}
+
+	_, err = Service("isxpulse.v1.TickerDiff")
+	assert.Error(t, err, "a message is not a service")
+}
+
+func TestFileOptionalFields(t *testing.T) {
+	fd, err := File()
+	require.NoError(t, err)
+	diff := fd.Messages().ByName("TickerDiff")
+	require.NotNil(t, diff)
+
+	rank := diff.Fields().ByName("rank_from")
+	assert.True(t, rank.HasPresence())
+	assert.False(t, diff.Fields().ByName("close_from").HasPresence())
+
+	msg := dynamicpb.NewMessage(diff)
+	require.NoError(t, protojson.Unmarshal([]byte(`{"symbol":"BBOB","rank_from":null,"rank_to":0}`), msg))
+	assert.False(t, msg.Has(rank), "null leaves an optional field unset")
+	assert.True(t, msg.Has(diff.Fields().ByName("rank_to")), "zero is set")
+}
+
+func TestParseProto(t *testing.T) {
+	fd, err := ParseProto("test.proto", `
+syntax = "proto3";
+package test.v1; // trailing comment
+message Ping { repeated string tags = 1; Pong reply = 2; }
+message Pong {}
+service Echo {
+  rpc Send(Ping) returns (Pong);
+  rpc Other(Ping) returns (Pong) {}
+}`)
+	require.NoError(t, err)
+	assert.Equal(t, "test.v1", fd.GetPackage())
+	require.Len(t, fd.GetMessageType(), 2)
+	assert.Equal(t, ".test.v1.Pong", fd.GetMessageType()[0].GetField()[1].GetTypeName())
+	require.Len(t, fd.GetService()[0].GetMethod(), 2)
+
+	for name, source := range map[string]string{
+		"proto2":    `syntax = "proto2";`,
+		"no syntax": `package x;`,
+		"enum":      `syntax = "proto3"; enum Kind { A = 0; }`,
+		"map field": `syntax = "proto3"; message M { map<string, string> m = 1; }`,
+		"streaming": `syntax = "proto3"; message M {} service S { rpc Watch(stream M) returns (M); }`,
+		"bad field": `syntax = "proto3"; message M { string s = zero; }`,
+	} {
+		_, err := ParseProto("bad.proto", source)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,216 @@
+// Package schema defines the columns of the trade record CSVs: the daily,
+// combined and ticker files the processor writes and the liquidity report,
+// importers and services read.
+//
+// Producers write TradeColumns in order. Consumers look columns up by name
+// through a Mapping, so files with extra or reordered columns, older layouts
+// and external CSVs with their own header names read the same way.
+//
+// Example usage:
+//
+//	mapping, err := schema.NewMapping(map[string]string{schema.ColumnClosePrice: "Last Trade"})
+//	cols := mapping.Resolve(header)
+//	if missing := cols.Missing(schema.ColumnDate, schema.ColumnSymbol); len(missing) > 0 { ... }
+//	closePrice := cols.Value(row, schema.ColumnClosePrice)
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Canonical trade record column names, as written in CSV headers
+const (
+	ColumnDate             = "Date"
+	ColumnCompanyName      = "CompanyName"
+	ColumnSymbol           = "Symbol"
+	ColumnOpenPrice        = "OpenPrice"
+	ColumnHighPrice        = "HighPrice"
+	ColumnLowPrice         = "LowPrice"
+	ColumnAveragePrice     = "AveragePrice"
+	ColumnPrevAveragePrice = "PrevAveragePrice"
+	ColumnClosePrice       = "ClosePrice"
+	ColumnPrevClosePrice   = "PrevClosePrice"
+	ColumnChange           = "Change"
+	ColumnChangePercent    = "ChangePercent"
+	ColumnNumTrades        = "NumTrades"
+	ColumnVolume           = "Volume"
+	ColumnValue            = "Value"
+	ColumnTradingStatus    = "TradingStatus"
+	ColumnFillMethod       = "FillMethod"
+)
+
+// TradeColumns is the column order of the daily, combined and ticker CSVs
+var TradeColumns = []string{
+	ColumnDate, ColumnCompanyName, ColumnSymbol, ColumnOpenPrice, ColumnHighPrice, ColumnLowPrice,
+	ColumnAveragePrice, ColumnPrevAveragePrice, ColumnClosePrice, ColumnPrevClosePrice,
+	ColumnChange, ColumnChangePercent, ColumnNumTrades, ColumnVolume, ColumnValue, ColumnTradingStatus,
+	ColumnFillMethod,
+}
+
+// DefaultAliases are the other header names matched for each column, after
+// the column's own name. Headers are compared in lower case with spaces,
+// underscores, dashes and dots removed, so "close_price" matches ClosePrice.
+var DefaultAliases = map[string][]string{
+	ColumnDate:          {"TradeDate", "TradingDate"},
+	ColumnCompanyName:   {"Company", "Name"},
+	ColumnSymbol:        {"Ticker", "Code", "CompanySymbol"},
+	ColumnOpenPrice:     {"Open", "OpeningPrice"},
+	ColumnHighPrice:     {"High", "HighestPrice"},
+	ColumnLowPrice:      {"Low", "LowestPrice"},
+	ColumnAveragePrice:  {"Average", "AvgPrice"},
+	ColumnClosePrice:    {"Close", "ClosingPrice"},
+	ColumnNumTrades:     {"NumOfTrades", "NoOfTrades", "Trades"},
+	ColumnVolume:        {"TradedVolume"},
+	ColumnValue:         {"TradedValue"},
+	ColumnTradingStatus: {"Status"},
+}
+
+// Mapping matches CSV headers to trade record columns
+type Mapping struct {
+	// overrides and names hold normalized headers: the configured header
+	// of a column, and its own name followed by its aliases
+	overrides map[string]string
+	names     map[string][]string
+}
+
+// DefaultMapping matches each column by its own name and DefaultAliases
+func DefaultMapping() Mapping {
+	m, _ := NewMapping(nil)
+	return m
+}
+
+// NewMapping returns the default mapping with overrides, keyed by column,
+// naming the header a source file uses for that column. The override is
+// tried first; the column's own name and aliases still match, so files
+// written by the processor read the same with or without overrides.
+func NewMapping(overrides map[string]string) (Mapping, error) {
+	if err := ValidateOverrides(overrides); err != nil {
+		return Mapping{}, err
+	}
+	m := Mapping{
+		overrides: make(map[string]string, len(overrides)),
+		names:     make(map[string][]string, len(TradeColumns)),
+	}
+	for column, source := range overrides {
+		m.overrides[column] = normalize(source)
+	}
+	for _, column := range TradeColumns {
+		names := []string{normalize(column)}
+		for _, alias := range DefaultAliases[column] {
+			names = append(names, normalize(alias))
+		}
+		m.names[column] = names
+	}
+	return m, nil
+}
+
+// ValidateOverrides checks that every override names a known column
+func ValidateOverrides(overrides map[string]string) error {
+	var unknown []string
+	for column, source := range overrides {
+		if !isColumn(column) {
+			unknown = append(unknown, column)
+			continue
+		}
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf("column mapping for %s is empty", column)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown column %s in column mapping (want one of %s)",
+			strings.Join(unknown, ", "), strings.Join(TradeColumns, ", "))
+	}
+	return nil
+}
+
+// Resolve finds the columns of header. Overridden columns are resolved
+// first, then the rest in TradeColumns order; each header cell is used once,
+// so an ambiguous header goes to the same column every run.
+func (m Mapping) Resolve(header []string) Columns {
+	if m.names == nil {
+		m = DefaultMapping()
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalize(h)
+		if _, ok := index[key]; !ok && key != "" {
+			index[key] = i
+		}
+	}
+
+	cols := Columns{index: make(map[string]int, len(TradeColumns))}
+	taken := make(map[int]bool)
+	for _, column := range TradeColumns {
+		if i, ok := index[m.overrides[column]]; ok && !taken[i] {
+			cols.index[column], taken[i] = i, true
+		}
+	}
+	for _, column := range TradeColumns {
+		if cols.Has(column) {
+			continue
+		}
+		for _, name := range m.names[column] {
+			if i, ok := index[name]; ok && !taken[i] {
+				cols.index[column], taken[i] = i, true
+				break
+			}
+		}
+	}
+	return cols
+}
+
+// Columns are the positions of trade record columns in one CSV header
+type Columns struct {
+	index map[string]int
+}
+
+// Index returns the position of column, or -1 when the header lacks it
+func (c Columns) Index(column string) int {
+	if i, ok := c.index[column]; ok {
+		return i
+	}
+	return -1
+}
+
+// Has reports whether the header has column
+func (c Columns) Has(column string) bool {
+	_, ok := c.index[column]
+	return ok
+}
+
+// Missing returns the given columns the header lacks, in the order given
+func (c Columns) Missing(columns ...string) []string {
+	var missing []string
+	for _, column := range columns {
+		if !c.Has(column) {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
+
+// Value returns the trimmed cell of row under column, or "" when the header
+// lacks the column or the row is short
+func (c Columns) Value(row []string, column string) string {
+	if i, ok := c.index[column]; ok && i < len(row) {
+		return strings.TrimSpace(row[i])
+	}
+	return ""
+}
+
+func isColumn(name string) bool {
+	for _, column := range TradeColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(h string) string {
+	h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	return strings.NewReplacer(" ", "", "_", "", "-", "", ".", "").Replace(h)
+}
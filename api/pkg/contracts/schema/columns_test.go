@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMapping_ResolvesTradeColumns(t *testing.T) {
+	header := append([]string{"\ufeff" + TradeColumns[0]}, TradeColumns[1:]...)
+	cols := DefaultMapping().Resolve(header)
+
+	for i, column := range TradeColumns {
+		assert.Equal(t, i, cols.Index(column), column)
+	}
+	assert.Empty(t, cols.Missing(TradeColumns...))
+}
+
+func TestDefaultMapping_Aliases(t *testing.T) {
+	header := []string{"ticker", "Trade Date", "Close", "num_of_trades", "Status", "Volume", "Extra"}
+	row := []string{"BBOB", "2025-08-25", " 1.22 ", "42", "true", "1500"}
+	cols := DefaultMapping().Resolve(header)
+
+	assert.Equal(t, "BBOB", cols.Value(row, ColumnSymbol))
+	assert.Equal(t, "2025-08-25", cols.Value(row, ColumnDate))
+	assert.Equal(t, "1.22", cols.Value(row, ColumnClosePrice))
+	assert.Equal(t, "42", cols.Value(row, ColumnNumTrades))
+	assert.Equal(t, "true", cols.Value(row, ColumnTradingStatus))
+	assert.Equal(t, "", cols.Value(row, ColumnValue), "column not in header")
+	assert.Equal(t, -1, cols.Index(ColumnValue))
+	assert.Equal(t, []string{ColumnOpenPrice, ColumnValue}, cols.Missing(ColumnSymbol, ColumnOpenPrice, ColumnValue))
+}
+
+func TestNewMapping_Overrides(t *testing.T) {
+	mapping, err := NewMapping(map[string]string{
+		ColumnClosePrice: "Last Trade",
+		ColumnVolume:     "Trades", // would otherwise be taken by NumTrades
+	})
+	require.NoError(t, err)
+
+	cols := mapping.Resolve([]string{"Date", "Symbol", "Close", "LAST_TRADE", "Trades"})
+	assert.Equal(t, 3, cols.Index(ColumnClosePrice), "override wins over the default name")
+	assert.Equal(t, 4, cols.Index(ColumnVolume))
+	assert.False(t, cols.Has(ColumnNumTrades), "each header cell is used once")
+
+	// Files written with the canonical header still read the same
+	cols = mapping.Resolve(TradeColumns)
+	assert.Equal(t, 8, cols.Index(ColumnClosePrice))
+}
+
+func TestNewMapping_InvalidOverrides(t *testing.T) {
+	_, err := NewMapping(map[string]string{"Closing": "Close", "Bogus": "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown column Bogus, Closing")
+
+	_, err = NewMapping(map[string]string{ColumnDate: " "})
+	assert.Error(t, err)
+}
@@ -1,15 +1,20 @@
 // build.go - ISX Pulse Build System
 // Usage: go run build.go [-target=TARGET]
-// Targets: all, web, scraper, processor, indexcsv, frontend, clean, test, release, package
+// Targets: all, web, scraper, processor, indexcsv, gapcheck, anonymize, importer, migrate, doctor, backup, frontend, clean, test, smoke, browser-pins, release, package
 
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -40,6 +45,12 @@ var (
 		"scraper":      "scraper.exe",
 		"processor":    "processor.exe",
 		"indexcsv":     "indexcsv.exe",
+		"gapcheck":     "gapcheck.exe",
+		"anonymize":    "anonymize.exe",
+		"importer":     "importer.exe",
+		"migrate":      "migrate.exe",
+		"doctor":       "doctor.exe",
+		"backup":       "backup.exe",
 	}
 	
 	// Colors for Windows console
@@ -119,12 +130,28 @@ func main() {
 		buildExecutableWithContext("processor", buildCtx)
 	case "indexcsv":
 		buildExecutableWithContext("indexcsv", buildCtx)
+	case "gapcheck":
+		buildExecutableWithContext("gapcheck", buildCtx)
+	case "anonymize":
+		buildExecutableWithContext("anonymize", buildCtx)
+	case "importer":
+		buildExecutableWithContext("importer", buildCtx)
+	case "migrate":
+		buildExecutableWithContext("migrate", buildCtx)
+	case "doctor":
+		buildExecutableWithContext("doctor", buildCtx)
+	case "backup":
+		buildExecutableWithContext("backup", buildCtx)
 	case "frontend":
 		buildFrontend(buildCtx.Verbose)
 	case "clean":
 		clean(buildCtx.Verbose)
 	case "test":
 		runTests(buildCtx.Verbose)
+	case "smoke":
+		runSmoke(buildCtx.Verbose)
+	case "browser-pins":
+		pinBrowser(buildCtx.Verbose)
 	case "release":
 		buildRelease(buildCtx)
 	case "package":
@@ -225,6 +252,11 @@ func buildExecutableWithContext(name string, ctx *BuildContext) {
 	
 	printInfo(fmt.Sprintf("Building %s...", name))
 	
+	// Linux and macOS builds drop the .exe suffix
+	if targetOS() != "windows" {
+		exeName = strings.TrimSuffix(exeName, ".exe")
+	}
+	
 	// Prepare build command
 	outputPath := filepath.Join(distDir, exeName)
 	sourcePath := "./cmd/" + name
@@ -233,6 +265,11 @@ func buildExecutableWithContext(name string, ctx *BuildContext) {
 	ldflags := fmt.Sprintf("-s -w -X main.Version=%s -X main.BuildTime=%s", 
 		version, time.Now().Format(time.RFC3339))
 	
+	// Record the git revision reported by /api/v1/system/version
+	if out, err := exec.Command("git", "rev-parse", "--short=12", "HEAD").Output(); err == nil {
+		ldflags += " -X isxcli/internal/app.BuildHash=" + strings.TrimSpace(string(out))
+	}
+	
 	// Add scratch card configuration to build flags
 	if ctx.EnableScratchCard {
 		ldflags += " -X main.EnableScratchCard=true"
@@ -281,6 +318,14 @@ func buildExecutableWithContext(name string, ctx *BuildContext) {
 	}
 }
 
+// targetOS returns the GOOS the build produces executables for
+func targetOS() string {
+	if goos := os.Getenv("GOOS"); goos != "" {
+		return goos
+	}
+	return runtime.GOOS
+}
+
 // Build a specific executable (backward compatibility)
 func buildExecutable(name string, verbose bool) {
 	ctx := &BuildContext{
@@ -437,6 +482,82 @@ func runTests(verbose bool) {
 	printSuccess("All tests passed")
 }
 
+// Run the end-to-end smoke pipeline
+func runSmoke(verbose bool) {
+	printInfo("Running smoke pipeline on synthetic reports...")
+
+	args := []string{"run", "./cmd/e2e"}
+	if verbose {
+		args = append(args, "-keep")
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = apiDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		printError(fmt.Sprintf("Smoke pipeline failed: %v", err))
+		os.Exit(1)
+	}
+
+	printSuccess("Smoke pipeline passed")
+}
+
+// browserPlatforms are the Chrome for Testing platforms the managed browser
+// is pinned for
+var browserPlatforms = []string{"linux64", "mac-arm64", "mac-x64", "win32", "win64"}
+
+// Record the SHA-256 of the pinned managed browser archives in
+// internal/browser/pins.go
+func pinBrowser(verbose bool) {
+	pinsFile := filepath.Join(apiDir, "internal", "browser", "pins.go")
+	source, err := os.ReadFile(pinsFile)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read pins: %v", err))
+		os.Exit(1)
+	}
+	match := regexp.MustCompile(`PinnedVersion = "([^"]+)"`).FindSubmatch(source)
+	if match == nil {
+		printError("PinnedVersion not found in " + pinsFile)
+		os.Exit(1)
+	}
+	pinned := string(match[1])
+	printInfo("Pinning managed browser " + pinned + "...")
+
+	var table strings.Builder
+	table.WriteString("var pinnedSHA256 = map[string]string{\n")
+	for _, platform := range browserPlatforms {
+		url := fmt.Sprintf("https://storage.googleapis.com/chrome-for-testing-public/%s/%s/chrome-headless-shell-%s.zip", pinned, platform, platform)
+		if verbose {
+			printInfo("Downloading " + url)
+		}
+		resp, err := http.Get(url)
+		if err != nil {
+			printError(fmt.Sprintf("Download failed: %v", err))
+			os.Exit(1)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, resp.Body)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			printError(fmt.Sprintf("Download of %s failed: %s %v", url, resp.Status, err))
+			os.Exit(1)
+		}
+		sum := hex.EncodeToString(hash.Sum(nil))
+		table.WriteString(fmt.Sprintf("\t%q: %q,\n", platform, sum))
+		printInfo(platform + " " + sum)
+	}
+	table.WriteString("}\n")
+
+	updated := regexp.MustCompile(`(?s)var pinnedSHA256 = map\[string\]string\{.*?\}\n`).ReplaceAllLiteral(source, []byte(table.String()))
+	if err := os.WriteFile(pinsFile, updated, 0644); err != nil {
+		printError(fmt.Sprintf("Failed to write pins: %v", err))
+		os.Exit(1)
+	}
+	printSuccess("Browser pins written to " + pinsFile)
+}
+
 // Build release version with optimizations
 func buildRelease(ctx *BuildContext) {
 	printInfo("Building release version...")
@@ -891,9 +1012,17 @@ func showHelp() {
 	fmt.Println("  scraper           Build scraper only")
 	fmt.Println("  processor         Build processor only")
 	fmt.Println("  indexcsv          Build indexcsv only")
+	fmt.Println("  gapcheck          Build gapcheck only")
+	fmt.Println("  anonymize         Build anonymize only")
+	fmt.Println("  importer          Build importer only")
+	fmt.Println("  migrate           Build migrate only")
+	fmt.Println("  doctor            Build doctor only")
+	fmt.Println("  backup            Build backup only")
 	fmt.Println("  frontend          Build frontend only")
 	fmt.Println("  clean             Clean build artifacts")
 	fmt.Println("  test              Run all tests")
+	fmt.Println("  smoke             Run the pipeline end to end on synthetic reports")
+	fmt.Println("  browser-pins      Record the checksums of the pinned managed browser")
 	fmt.Println("  release           Build optimized release version")
 	fmt.Println("  package           Create distribution package")
 	fmt.Println()
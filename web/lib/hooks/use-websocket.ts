@@ -176,6 +176,45 @@ export function useAllOperationUpdates() {
   }
 }
 
+export interface LicenseGracePeriod {
+  active: boolean
+  last_checked: string
+  expires_at: string
+  remaining_seconds: number
+  failing_since?: string
+  last_error?: string
+}
+
+export interface LicenseStatusEvent {
+  status: 'active' | 'warning' | 'critical' | 'expired' | 'not_activated'
+  previous_status?: string
+  transition: boolean
+  days_left: number
+  expiry_date?: string
+  needs_renewal: boolean
+  grace_period?: LicenseGracePeriod
+}
+
+/**
+ * Latest license status pushed by the server (every minute and on transitions).
+ * Use it to drive renewal and offline grace-period banners.
+ */
+export function useLicenseStatus() {
+  const { subscribe, isConnected } = useWebSocket({ autoConnect: true })
+  const [licenseStatus, setLicenseStatus] = useState<LicenseStatusEvent | null>(null)
+
+  useEffect(() => {
+    const unsubscribe = subscribe('license_status', setLicenseStatus)
+    return unsubscribe
+  }, [subscribe])
+
+  return {
+    licenseStatus,
+    connected: isConnected,
+    inGracePeriod: licenseStatus?.grace_period?.active === true,
+  }
+}
+
 // Legacy aliases
 export const usePipelineUpdates = useAllOperationUpdates
 export const useMarketUpdates = () => {